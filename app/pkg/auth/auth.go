@@ -52,6 +52,9 @@ const (
 	// Admin access
 	GetUsers int64 = 0x0000000000001000
 	EditUser int64 = 0x0000000000002000
+
+	// Platform super admin (matches SUPER_ADMIN_FLAG in service-client)
+	SuperAdmin int64 = 0x0000000000010000
 )
 
 const UserAccess int64 = GetNotes |
@@ -0,0 +1,594 @@
+package cfbrowser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// ChromeRenderer renders pages with a local headless Chrome via the DevTools
+// protocol, for self-hosted deployments without the Cloudflare worker. It
+// mirrors the worker's extraction logic so both return the same results.
+type ChromeRenderer struct {
+	allocCtx    context.Context
+	cancelAlloc context.CancelFunc
+	browserCtx  context.Context
+	cancel      context.CancelFunc
+	sem         chan struct{}
+	timeout     time.Duration
+	checkURL    func(ctx context.Context, rawURL string) error
+
+	startMu sync.Mutex
+	started bool
+}
+
+// ChromeOption configures a ChromeRenderer.
+type ChromeOption func(*chromeConfig)
+
+type chromeConfig struct {
+	execPath      string
+	maxConcurrent int
+	timeout       time.Duration
+	checkURL      func(ctx context.Context, rawURL string) error
+}
+
+// WithChromePath sets the Chrome/Chromium executable. Default: looked up on PATH.
+func WithChromePath(path string) ChromeOption {
+	return func(c *chromeConfig) {
+		c.execPath = path
+	}
+}
+
+// WithChromeMaxConcurrent sets the maximum number of concurrent tabs. Default: 2.
+func WithChromeMaxConcurrent(n int) ChromeOption {
+	return func(c *chromeConfig) {
+		c.maxConcurrent = n
+	}
+}
+
+// WithChromeTimeout sets the per-page render timeout. Default: 30s.
+func WithChromeTimeout(d time.Duration) ChromeOption {
+	return func(c *chromeConfig) {
+		c.timeout = d
+	}
+}
+
+// WithChromeURLCheck vets every http(s) request a page makes, the page itself
+// included; requests check rejects fail. Use it when pages come from users, as
+// Chrome would otherwise load anything reachable from inside our network.
+func WithChromeURLCheck(check func(ctx context.Context, rawURL string) error) ChromeOption {
+	return func(c *chromeConfig) {
+		c.checkURL = check
+	}
+}
+
+// NewChromeRenderer creates a renderer backed by a local headless Chrome.
+// The browser is started lazily on first use; call Close to shut it down.
+func NewChromeRenderer(opts ...ChromeOption) *ChromeRenderer {
+	cfg := chromeConfig{maxConcurrent: 2, timeout: 30 * time.Second}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	allocOpts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.DisableGPU)
+	if cfg.execPath != "" {
+		allocOpts = append(allocOpts, chromedp.ExecPath(cfg.execPath))
+	}
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), allocOpts...)
+	browserCtx, cancel := chromedp.NewContext(allocCtx)
+
+	return &ChromeRenderer{
+		allocCtx:    allocCtx,
+		cancelAlloc: cancelAlloc,
+		browserCtx:  browserCtx,
+		cancel:      cancel,
+		sem:         make(chan struct{}, cfg.maxConcurrent),
+		timeout:     cfg.timeout,
+		checkURL:    cfg.checkURL,
+	}
+}
+
+// Close shuts down the browser process.
+func (r *ChromeRenderer) Close() {
+	r.cancel()
+	r.cancelAlloc()
+}
+
+// run opens a new tab, applies opts, navigates to targetURL and runs actions,
+// bounded by the renderer's concurrency limit and timeout as well as ctx.
+func (r *ChromeRenderer) run(ctx context.Context, targetURL string, opts PageOptions, actions ...chromedp.Action) error {
+	return r.runTab(ctx, targetURL, opts, chromedp.Navigate(targetURL), actions...)
+}
+
+// runHTML is run for a document with no URL of its own: the tab loads
+// about:blank and replaces its content with html.
+func (r *ChromeRenderer) runHTML(ctx context.Context, html string, opts PageOptions, actions ...chromedp.Action) error {
+	load := chromedp.Tasks{
+		chromedp.Navigate("about:blank"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			tree, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			return page.SetDocumentContent(tree.Frame.ID, html).Do(ctx)
+		}),
+	}
+	return r.runTab(ctx, "about:blank", opts, load, actions...)
+}
+
+// runTab opens a new tab, applies opts, loads the page and runs actions.
+// targetURL is the page's address, for errors and cookie domains.
+func (r *ChromeRenderer) runTab(ctx context.Context, targetURL string, opts PageOptions, load chromedp.Action, actions ...chromedp.Action) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	setup, err := pageSetup(targetURL, opts, r.checkURL != nil)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case r.sem <- struct{}{}:
+		defer func() { <-r.sem }()
+	case <-ctx.Done():
+		return fmt.Errorf("cfbrowser: %w", ctx.Err())
+	}
+
+	if err := r.start(); err != nil {
+		return err
+	}
+
+	tabCtx, cancelTab := chromedp.NewContext(r.browserCtx)
+	defer cancelTab()
+	timeout := r.timeout
+	if opts.TimeoutMS > 0 {
+		timeout = time.Duration(opts.TimeoutMS) * time.Millisecond
+	}
+	tabCtx, cancelTimeout := context.WithTimeout(tabCtx, timeout)
+	defer cancelTimeout()
+	stop := context.AfterFunc(ctx, cancelTab)
+	defer stop()
+	if len(opts.BlockResources) > 0 || r.checkURL != nil {
+		r.interceptRequests(tabCtx, opts.BlockResources)
+	}
+
+	tasks := append(setup, load)
+	if opts.WaitForSelector != "" {
+		tasks = append(tasks, chromedp.WaitReady(opts.WaitForSelector, chromedp.ByQuery))
+	}
+	if err := chromedp.Run(tabCtx, append(tasks, actions...)...); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("cfbrowser: %w", ctx.Err())
+		}
+		return fmt.Errorf("cfbrowser: chrome render %s: %w", targetURL, err)
+	}
+	return nil
+}
+
+// pageSetup returns the actions that apply opts to a new tab before it
+// navigates. WaitUntil isn't mapped: Navigate always waits for the load event.
+// With interceptAll every request is paused for interceptRequests, not just
+// those of blocked resource types.
+func pageSetup(targetURL string, opts PageOptions, interceptAll bool) ([]chromedp.Action, error) {
+	var setup []chromedp.Action
+	if opts.UserAgent != "" {
+		setup = append(setup, emulation.SetUserAgentOverride(opts.UserAgent))
+	}
+	if v := opts.Viewport; v != nil {
+		scale := v.DeviceScaleFactor
+		if scale == 0 {
+			scale = 1
+		}
+		setup = append(setup, emulation.SetDeviceMetricsOverride(int64(v.Width), int64(v.Height), scale, v.IsMobile))
+	}
+	if opts.DisableJavaScript {
+		// Page scripts stop; our own Evaluate calls still run
+		setup = append(setup, emulation.SetScriptExecutionDisabled(true))
+	}
+	if len(opts.Headers) > 0 || len(opts.Cookies) > 0 {
+		setup = append(setup, network.Enable())
+	}
+	if len(opts.Headers) > 0 {
+		headers := make(network.Headers, len(opts.Headers))
+		for k, v := range opts.Headers {
+			headers[k] = v
+		}
+		setup = append(setup, network.SetExtraHTTPHeaders(headers))
+	}
+	if len(opts.Cookies) > 0 {
+		parsed, err := url.Parse(targetURL)
+		if err != nil {
+			return nil, fmt.Errorf("cfbrowser: parse url: %w", err)
+		}
+		for _, c := range opts.Cookies {
+			domain, path := c.Domain, c.Path
+			if domain == "" {
+				domain = parsed.Hostname()
+			}
+			if path == "" {
+				path = "/"
+			}
+			setup = append(setup, network.SetCookie(c.Name, c.Value).WithDomain(domain).WithPath(path))
+		}
+	}
+	switch {
+	case interceptAll:
+		setup = append(setup, fetch.Enable().WithPatterns([]*fetch.RequestPattern{{URLPattern: "*", RequestStage: fetch.RequestStageRequest}}))
+	case len(opts.BlockResources) > 0:
+		patterns := make([]*fetch.RequestPattern, len(opts.BlockResources))
+		for i, rt := range opts.BlockResources {
+			patterns[i] = &fetch.RequestPattern{URLPattern: "*", ResourceType: chromeResourceTypes[rt], RequestStage: fetch.RequestStageRequest}
+		}
+		setup = append(setup, fetch.Enable().WithPatterns(patterns))
+	}
+	return setup, nil
+}
+
+// chromeResourceTypes maps blockableResources to their DevTools names.
+var chromeResourceTypes = map[string]network.ResourceType{
+	"stylesheet":  network.ResourceTypeStylesheet,
+	"image":       network.ResourceTypeImage,
+	"media":       network.ResourceTypeMedia,
+	"font":        network.ResourceTypeFont,
+	"script":      network.ResourceTypeScript,
+	"texttrack":   network.ResourceTypeTextTrack,
+	"xhr":         network.ResourceTypeXHR,
+	"fetch":       network.ResourceTypeFetch,
+	"eventsource": network.ResourceTypeEventSource,
+	"websocket":   network.ResourceTypeWebSocket,
+	"manifest":    network.ResourceTypeManifest,
+	"other":       network.ResourceTypeOther,
+}
+
+// interceptRequests answers the requests the tab's fetch patterns pause:
+// those of a blocked resource type fail, as do those the renderer's URL check
+// rejects, and the rest continue.
+func (r *ChromeRenderer) interceptRequests(tabCtx context.Context, blockResources []string) {
+	blocked := make([]network.ResourceType, len(blockResources))
+	for i, rt := range blockResources {
+		blocked[i] = chromeResourceTypes[rt]
+	}
+	chromedp.ListenTarget(tabCtx, func(ev any) {
+		e, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		// Listeners must not block, so reply from a new goroutine
+		go func() {
+			var reply chromedp.Action = fetch.ContinueRequest(e.RequestID)
+			if slices.Contains(blocked, e.ResourceType) || !r.allowed(tabCtx, e.Request.URL) {
+				reply = fetch.FailRequest(e.RequestID, network.ErrorReasonBlockedByClient)
+			}
+			_ = chromedp.Run(tabCtx, reply)
+		}()
+	})
+}
+
+// allowed reports whether the URL check, if any, lets a page request rawURL.
+// Only http(s) requests reach the network, so other schemes pass.
+func (r *ChromeRenderer) allowed(ctx context.Context, rawURL string) bool {
+	if r.checkURL == nil {
+		return true
+	}
+	if u, err := url.Parse(rawURL); err == nil && u.Scheme != "http" && u.Scheme != "https" {
+		return true
+	}
+	return r.checkURL(ctx, rawURL) == nil
+}
+
+// start launches the browser on first use. The browser is tied to the
+// renderer's lifetime rather than to any single request's context.
+func (r *ChromeRenderer) start() error {
+	r.startMu.Lock()
+	defer r.startMu.Unlock()
+	if r.started {
+		return nil
+	}
+	if err := chromedp.Run(r.browserCtx); err != nil {
+		return fmt.Errorf("cfbrowser: start chrome: %w", err)
+	}
+	r.started = true
+	return nil
+}
+
+// GetMarkdown renders a URL and returns its content as clean, LLM-ready markdown.
+func (r *ChromeRenderer) GetMarkdown(ctx context.Context, targetURL string, opts PageOptions) (*MarkdownResponse, error) {
+	var title, content string
+	if err := r.run(ctx, targetURL, opts,
+		chromedp.Title(&title),
+		chromedp.Evaluate(markdownScript, &content),
+	); err != nil {
+		return nil, err
+	}
+
+	if title != "" {
+		content = "# " + title + "\n\n" + content
+	}
+	return &MarkdownResponse{Content: content, Title: title, URL: targetURL}, nil
+}
+
+// GetLinks renders a URL and returns all extracted links.
+func (r *ChromeRenderer) GetLinks(ctx context.Context, targetURL string, opts PageOptions) (*LinksResponse, error) {
+	base, err := json.Marshal(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("cfbrowser: encode url: %w", err)
+	}
+
+	var links []Link
+	if err := r.run(ctx, targetURL, opts,
+		chromedp.Evaluate(fmt.Sprintf(linksScript, base), &links),
+	); err != nil {
+		return nil, err
+	}
+	return &LinksResponse{Links: links}, nil
+}
+
+// Scrape renders a URL and extracts text from the given CSS selectors.
+// Selectors that match nothing map to "", as with the worker.
+func (r *ChromeRenderer) Scrape(ctx context.Context, targetURL string, selectors map[string]string, opts PageOptions) (*ScrapeResponse, error) {
+	sels, err := json.Marshal(selectors)
+	if err != nil {
+		return nil, fmt.Errorf("cfbrowser: encode selectors: %w", err)
+	}
+
+	var raw map[string]*string
+	if err := r.run(ctx, targetURL, opts,
+		chromedp.Evaluate(fmt.Sprintf(scrapeScript, sels), &raw),
+	); err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]string, len(raw))
+	for k, v := range raw {
+		data[k] = ""
+		if v != nil {
+			data[k] = *v
+		}
+	}
+	return &ScrapeResponse{Data: data, URL: targetURL}, nil
+}
+
+// RenderPDF loads a URL and prints it to PDF, returning the document bytes.
+func (r *ChromeRenderer) RenderPDF(ctx context.Context, targetURL string, opts PDFOptions) ([]byte, error) {
+	printPDF, buf, err := printToPDF(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.run(ctx, targetURL, opts.PageOptions, printPDF); err != nil {
+		return nil, err
+	}
+	return *buf, nil
+}
+
+// RenderHTMLPDF prints an HTML document to PDF. As with the worker, relative
+// links in the document don't resolve.
+func (r *ChromeRenderer) RenderHTMLPDF(ctx context.Context, html string, opts PDFOptions) ([]byte, error) {
+	if html == "" {
+		return nil, fmt.Errorf("cfbrowser: html is required")
+	}
+	printPDF, buf, err := printToPDF(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.runHTML(ctx, html, opts.PageOptions, printPDF); err != nil {
+		return nil, err
+	}
+	return *buf, nil
+}
+
+// pdfPaperSizes are the pdfFormats' width and height in inches.
+var pdfPaperSizes = map[string][2]float64{
+	"A3":      {11.69, 16.54},
+	"A4":      {8.27, 11.69},
+	"A5":      {5.83, 8.27},
+	"Letter":  {8.5, 11},
+	"Legal":   {8.5, 14},
+	"Tabloid": {11, 17},
+}
+
+// printToPDF returns the action printing the tab with opts' layout, and where
+// it stores the document.
+func printToPDF(opts PDFOptions) (chromedp.Action, *[]byte, error) {
+	if err := opts.validate(); err != nil {
+		return nil, nil, err
+	}
+	format := opts.Format
+	if format == "" {
+		format = "A4"
+	}
+	size := pdfPaperSizes[format]
+	params := page.PrintToPDF().
+		WithPaperWidth(size[0]).
+		WithPaperHeight(size[1]).
+		WithLandscape(opts.Landscape).
+		WithPrintBackground(opts.PrintBackground)
+	margins := []struct {
+		css string
+		set func(float64) *page.PrintToPDFParams
+	}{
+		{opts.Margins.Top, params.WithMarginTop},
+		{opts.Margins.Right, params.WithMarginRight},
+		{opts.Margins.Bottom, params.WithMarginBottom},
+		{opts.Margins.Left, params.WithMarginLeft},
+	}
+	for _, m := range margins {
+		if m.css == "" {
+			continue
+		}
+		inches, err := cssInches(m.css)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.set(inches)
+	}
+
+	buf := new([]byte)
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		data, _, err := params.Do(ctx)
+		*buf = data
+		return err
+	}), buf, nil
+}
+
+// cssInchesPerUnit converts the CSS length units Chrome's print dialog
+// accepts to inches.
+var cssInchesPerUnit = map[string]float64{
+	"px": 1.0 / 96,
+	"in": 1,
+	"cm": 1 / 2.54,
+	"mm": 1 / 25.4,
+	"pt": 1.0 / 72,
+	"pc": 1.0 / 6,
+}
+
+// cssInches converts a CSS length such as "2cm" to inches. A bare number is
+// in pixels, as with the worker.
+func cssInches(length string) (float64, error) {
+	length = strings.TrimSpace(length)
+	number, unit := length, "px"
+	if len(length) > 2 {
+		if _, ok := cssInchesPerUnit[length[len(length)-2:]]; ok {
+			number, unit = length[:len(length)-2], length[len(length)-2:]
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("cfbrowser: invalid margin %q", length)
+	}
+	return n * cssInchesPerUnit[unit], nil
+}
+
+// Screenshot loads a URL and captures it as an image, returning the image bytes.
+func (r *ChromeRenderer) Screenshot(ctx context.Context, targetURL string, opts ScreenshotOptions) ([]byte, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	capture := chromedp.ActionFunc(func(ctx context.Context) error {
+		params := page.CaptureScreenshot().WithFormat(page.CaptureScreenshotFormat(screenshotFormat(opts.Format)))
+		if opts.Quality > 0 {
+			params = params.WithQuality(int64(opts.Quality))
+		}
+		if opts.FullPage {
+			_, _, _, _, _, content, err := page.GetLayoutMetrics().Do(ctx)
+			if err != nil {
+				return err
+			}
+			params = params.WithCaptureBeyondViewport(true).
+				WithClip(&page.Viewport{Width: content.Width, Height: content.Height, Scale: 1})
+		}
+		var err error
+		buf, err = params.Do(ctx)
+		return err
+	})
+	if err := r.run(ctx, targetURL, opts.PageOptions, capture); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// The scripts below are ports of the page.evaluate callbacks in
+// workers/browser-rendering/src/index.ts; keep them in sync.
+
+const markdownScript = `(() => {
+	const selectors = [
+		"nav", "header", "footer", "script", "style", "noscript", "iframe",
+		'[role="navigation"]', ".sidebar", "#sidebar", ".menu", ".nav",
+		".cookie-banner", ".advertisement", ".ads",
+	];
+	selectors.forEach((sel) => {
+		document.querySelectorAll(sel).forEach((el) => el.remove());
+	});
+
+	const main =
+		document.querySelector('main, article, [role="main"], .content, #content') ||
+		document.body;
+
+	const lines = [];
+
+	function walk(node) {
+		if (node.nodeType === Node.TEXT_NODE) {
+			const text = node.textContent?.trim();
+			if (text) lines.push(text);
+			return;
+		}
+		if (node.nodeType !== Node.ELEMENT_NODE) return;
+		const tag = node.tagName.toLowerCase();
+		if (["script", "style", "noscript"].includes(tag)) return;
+
+		const headingLevel = ["h1", "h2", "h3", "h4", "h5", "h6"].indexOf(tag);
+		if (headingLevel !== -1) {
+			const text = node.textContent?.trim();
+			if (text) {
+				lines.push("");
+				lines.push("#".repeat(headingLevel + 1) + " " + text);
+				lines.push("");
+			}
+			return;
+		}
+		if (tag === "li") {
+			const text = node.textContent?.trim();
+			if (text) lines.push("- " + text);
+			return;
+		}
+		if (tag === "br") {
+			lines.push("");
+			return;
+		}
+		for (const child of Array.from(node.childNodes)) {
+			walk(child);
+		}
+		if (["p", "div", "section", "blockquote"].includes(tag)) {
+			lines.push("");
+		}
+	}
+
+	walk(main);
+
+	return lines.join("\n").replace(/\n{3,}/g, "\n\n").trim();
+})()`
+
+// linksScript takes the JSON-encoded base URL as its format argument.
+const linksScript = `((baseUrl) => {
+	const seen = new Set();
+	const results = [];
+	for (const a of Array.from(document.querySelectorAll("a[href]"))) {
+		const href = a.getAttribute("href");
+		if (!href) continue;
+		let absolute;
+		try {
+			absolute = new URL(href, baseUrl).toString();
+		} catch {
+			continue;
+		}
+		if (!absolute.startsWith("http://") && !absolute.startsWith("https://")) continue;
+		const noFragment = absolute.split("#")[0];
+		if (seen.has(noFragment)) continue;
+		seen.add(noFragment);
+		results.push({ url: noFragment, text: (a.textContent || "").trim() });
+	}
+	return results;
+})(%s)`
+
+// scrapeScript takes the JSON-encoded selectors map as its format argument.
+const scrapeScript = `((sels) => {
+	const result = {};
+	for (const [key, selector] of Object.entries(sels)) {
+		const el = document.querySelector(selector);
+		result[key] = el ? (el.textContent || "").trim() : null;
+	}
+	return result;
+})(%s)`
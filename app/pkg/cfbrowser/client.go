@@ -16,6 +16,8 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	sem        chan struct{}
+	caps       capabilitiesCache
+	observer   func(RequestInfo)
 }
 
 // Option configures a Client.
@@ -52,6 +54,30 @@ const maxRetries = 3
 
 // doRequest performs a rate-limited HTTP POST with retry on 429 and 5xx.
 func (c *Client) doRequest(ctx context.Context, path string, body any) ([]byte, error) {
+	return c.do(ctx, http.MethodPost, path, body)
+}
+
+// doGet performs a rate-limited HTTP GET with retry on 429 and 5xx.
+func (c *Client) doGet(ctx context.Context, path string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, path, nil)
+}
+
+// do sends a request via send and reports it to the observer, if any.
+func (c *Client) do(ctx context.Context, method, path string, body any) ([]byte, error) {
+	info := RequestInfo{Method: method, Endpoint: path}
+	start := time.Now()
+	respBody, err := c.send(ctx, method, path, body, &info)
+	if c.observer != nil {
+		info.Duration = time.Since(start)
+		info.Err = err
+		c.observer(info)
+	}
+	return respBody, err
+}
+
+// send sends a request with a JSON body (if non-nil), retrying on 429 and 5xx.
+// The HTTP status and retry count are written to info as they happen.
+func (c *Client) send(ctx context.Context, method, path string, body any, info *RequestInfo) ([]byte, error) {
 	// Acquire semaphore slot.
 	select {
 	case c.sem <- struct{}{}:
@@ -60,26 +86,34 @@ func (c *Client) doRequest(ctx context.Context, path string, body any) ([]byte,
 	}
 	defer func() { <-c.sem }()
 
-	payload, err := json.Marshal(body)
-	if err != nil {
-		return nil, fmt.Errorf("cfbrowser: marshal request: %w", err)
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("cfbrowser: marshal request: %w", err)
+		}
 	}
 
 	var lastErr error
 	backoff := 1 * time.Second
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+		info.Retries = attempt
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
 		if err != nil {
 			return nil, fmt.Errorf("cfbrowser: create request: %w", err)
 		}
-		req.Header.Set("Content-Type", "application/json")
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
 			return nil, fmt.Errorf("cfbrowser: execute request: %w", err)
 		}
 
+		info.HTTPStatus = resp.StatusCode
 		respBody, readErr := io.ReadAll(resp.Body)
 		resp.Body.Close()
 		if readErr != nil {
@@ -90,7 +124,7 @@ func (c *Client) doRequest(ctx context.Context, path string, body any) ([]byte,
 			return respBody, nil
 		}
 
-		lastErr = fmt.Errorf("cfbrowser: unexpected status %d: %s", resp.StatusCode, string(respBody))
+		lastErr = &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
 
 		// Rate limited — use Retry-After if present.
 		if resp.StatusCode == http.StatusTooManyRequests {
@@ -123,6 +157,16 @@ func (c *Client) doRequest(ctx context.Context, path string, body any) ([]byte,
 	return nil, fmt.Errorf("cfbrowser: max retries exceeded: %w", lastErr)
 }
 
+// StatusError is returned when the worker answers with a non-2xx status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("cfbrowser: unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
 // sleep waits for the given duration or until the context is cancelled.
 func sleep(ctx context.Context, d time.Duration) error {
 	t := time.NewTimer(d)
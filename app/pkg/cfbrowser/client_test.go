@@ -1,10 +1,15 @@
 package cfbrowser
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -58,7 +63,7 @@ func TestGetMarkdown_Success(t *testing.T) {
 	defer srv.Close()
 
 	client := NewClient(srv.URL)
-	resp, err := client.GetMarkdown(context.Background(), "http://example.com")
+	resp, err := client.GetMarkdown(context.Background(), "http://example.com", PageOptions{})
 
 	require.NoError(t, err)
 	assert.Equal(t, "# Hello World", resp.Content)
@@ -81,12 +86,16 @@ func TestGetMarkdown_ServerError(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	_, err := client.GetMarkdown(ctx, "http://example.com")
+	_, err := client.GetMarkdown(ctx, "http://example.com", PageOptions{})
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "max retries exceeded")
 	assert.Contains(t, err.Error(), "unexpected status 500")
 	assert.Equal(t, int32(maxRetries), atomic.LoadInt32(&attempts))
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
 }
 
 func TestGetMarkdown_InvalidJSON(t *testing.T) {
@@ -97,12 +106,66 @@ func TestGetMarkdown_InvalidJSON(t *testing.T) {
 	defer srv.Close()
 
 	client := NewClient(srv.URL)
-	_, err := client.GetMarkdown(context.Background(), "http://example.com")
+	_, err := client.GetMarkdown(context.Background(), "http://example.com", PageOptions{})
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "decode markdown response")
 }
 
+func TestGetMarkdown_PageOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req MarkdownRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "Mozilla/5.0 (iPhone)", req.UserAgent)
+		assert.Equal(t, &Viewport{Width: 390, Height: 844, DeviceScaleFactor: 3, IsMobile: true}, req.Viewport)
+		assert.Equal(t, []string{"image", "font"}, req.BlockResources)
+		assert.Equal(t, 15000, req.TimeoutMS)
+		assert.True(t, req.DisableJavaScript)
+
+		json.NewEncoder(w).Encode(MarkdownResponse{Content: "# Hi", Title: "Hi", URL: req.URL})
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	_, err := client.GetMarkdown(context.Background(), "http://example.com", PageOptions{
+		UserAgent:         "Mozilla/5.0 (iPhone)",
+		Viewport:          &Viewport{Width: 390, Height: 844, DeviceScaleFactor: 3, IsMobile: true},
+		BlockResources:    []string{"image", "font"},
+		TimeoutMS:         15000,
+		DisableJavaScript: true,
+	})
+	require.NoError(t, err)
+}
+
+func TestPageOptions_Invalid(t *testing.T) {
+	client := NewClient("http://localhost:8787")
+	tests := map[string]struct {
+		opts PageOptions
+		want string
+	}{
+		"zero viewport":     {PageOptions{Viewport: &Viewport{}}, "viewport 0x0 out of range"},
+		"huge viewport":     {PageOptions{Viewport: &Viewport{Width: 10000, Height: 800}}, "out of range"},
+		"negative scale":    {PageOptions{Viewport: &Viewport{Width: 800, Height: 600, DeviceScaleFactor: -1}}, "negative deviceScaleFactor"},
+		"block document":    {PageOptions{BlockResources: []string{"document"}}, `cannot block resource type "document"`},
+		"negative timeout":  {PageOptions{TimeoutMS: -1}, "negative timeoutMs"},
+		"unknown waitUntil": {PageOptions{WaitUntil: "idle"}, "unsupported waitUntil"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.GetLinks(context.Background(), "http://example.com", tt.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestChromeResourceTypes_CoverBlockable(t *testing.T) {
+	for _, rt := range blockableResources {
+		assert.Contains(t, chromeResourceTypes, rt)
+	}
+	assert.Len(t, chromeResourceTypes, len(blockableResources))
+}
+
 // ---------------------------------------------------------------------------
 // GetLinks
 // ---------------------------------------------------------------------------
@@ -126,7 +189,7 @@ func TestGetLinks_Success(t *testing.T) {
 	defer srv.Close()
 
 	client := NewClient(srv.URL)
-	resp, err := client.GetLinks(context.Background(), "http://example.com")
+	resp, err := client.GetLinks(context.Background(), "http://example.com", PageOptions{})
 
 	require.NoError(t, err)
 	require.Len(t, resp.Links, 2)
@@ -143,7 +206,7 @@ func TestGetLinks_EmptyResponse(t *testing.T) {
 	defer srv.Close()
 
 	client := NewClient(srv.URL)
-	resp, err := client.GetLinks(context.Background(), "http://example.com")
+	resp, err := client.GetLinks(context.Background(), "http://example.com", PageOptions{})
 
 	require.NoError(t, err)
 	assert.Empty(t, resp.Links)
@@ -179,7 +242,7 @@ func TestScrape_Success(t *testing.T) {
 		"title":       "h1",
 		"description": "meta[name=description]",
 	}
-	resp, err := client.Scrape(context.Background(), "http://example.com", selectors)
+	resp, err := client.Scrape(context.Background(), "http://example.com", selectors, PageOptions{})
 
 	require.NoError(t, err)
 	assert.Equal(t, "Example Domain", resp.Data["title"])
@@ -187,6 +250,562 @@ func TestScrape_Success(t *testing.T) {
 	assert.Equal(t, "http://example.com", resp.URL)
 }
 
+// ---------------------------------------------------------------------------
+// RenderPDF
+// ---------------------------------------------------------------------------
+
+func TestRenderPDF_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/pdf", r.URL.Path)
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "http://example.com", req["url"])
+		assert.Equal(t, "Letter", req["format"])
+		assert.Equal(t, true, req["landscape"])
+		assert.Equal(t, map[string]any{"top": "1cm", "bottom": "1cm"}, req["margin"])
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7 test"))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	pdf, err := client.RenderPDF(context.Background(), "http://example.com", PDFOptions{
+		Format:    "Letter",
+		Landscape: true,
+		Margins:   PDFMargins{Top: "1cm", Bottom: "1cm"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.7 test", string(pdf))
+}
+
+func TestRenderHTMLPDF_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/pdf", r.URL.Path)
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "<h1>Quiz</h1>", req["html"])
+		assert.NotContains(t, req, "url")
+		assert.Equal(t, "A4", req["format"])
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7 test"))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	pdf, err := client.RenderHTMLPDF(context.Background(), "<h1>Quiz</h1>", PDFOptions{Format: "A4"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.7 test", string(pdf))
+}
+
+func TestRenderHTMLPDF_RequiresHTML(t *testing.T) {
+	client := NewClient("http://localhost:8787")
+	_, err := client.RenderHTMLPDF(context.Background(), "", PDFOptions{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "html is required")
+}
+
+func TestRenderPDF_InvalidFormat(t *testing.T) {
+	client := NewClient("http://localhost:8787")
+	_, err := client.RenderPDF(context.Background(), "http://example.com", PDFOptions{Format: "B5"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported PDF format")
+}
+
+// ---------------------------------------------------------------------------
+// Screenshot
+// ---------------------------------------------------------------------------
+
+func TestScreenshot_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/screenshot", r.URL.Path)
+
+		var req ScreenshotRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "http://example.com", req.URL)
+		assert.Equal(t, ScreenshotJPEG, req.Format)
+		assert.Equal(t, 70, req.Quality)
+		assert.True(t, req.FullPage)
+		assert.Equal(t, &Viewport{Width: 1280, Height: 800}, req.Viewport)
+
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00})
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	img, err := client.Screenshot(context.Background(), "http://example.com", ScreenshotOptions{
+		Format:      ScreenshotJPEG,
+		Quality:     70,
+		FullPage:    true,
+		PageOptions: PageOptions{Viewport: &Viewport{Width: 1280, Height: 800}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00}, img)
+}
+
+func TestScreenshot_NotAnImage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"oops"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	_, err := client.Screenshot(context.Background(), "http://example.com", ScreenshotOptions{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a png image")
+}
+
+func TestScreenshot_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ScreenshotOptions
+		want string
+	}{
+		{name: "unknown format", opts: ScreenshotOptions{Format: "gif"}, want: "unsupported screenshot format"},
+		{name: "png quality", opts: ScreenshotOptions{Quality: 50}, want: "quality applies to jpeg"},
+		{name: "quality out of range", opts: ScreenshotOptions{Format: ScreenshotJPEG, Quality: 101}, want: "out of range"},
+		{name: "bad page options", opts: ScreenshotOptions{PageOptions: PageOptions{WaitUntil: "never"}}, want: "unsupported waitUntil"},
+	}
+	client := NewClient("http://localhost:8787")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Screenshot(context.Background(), "http://example.com", tt.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestGetHTML_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/html", r.URL.Path)
+
+		var req HTMLRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "http://example.com", req.URL)
+		assert.Equal(t, "domcontentloaded", req.WaitUntil)
+		assert.Equal(t, "#app", req.WaitForSelector)
+		assert.Equal(t, map[string]string{"Accept-Language": "en-AU"}, req.Headers)
+		assert.Equal(t, []Cookie{{Name: "session", Value: "abc"}}, req.Cookies)
+
+		json.NewEncoder(w).Encode(HTMLResponse{HTML: "<html><body>Hi</body></html>", Title: "Hi", URL: req.URL})
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	resp, err := client.GetHTML(context.Background(), "http://example.com", PageOptions{
+		WaitUntil:       "domcontentloaded",
+		WaitForSelector: "#app",
+		Headers:         map[string]string{"Accept-Language": "en-AU"},
+		Cookies:         []Cookie{{Name: "session", Value: "abc"}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "<html><body>Hi</body></html>", resp.HTML)
+	assert.Equal(t, "Hi", resp.Title)
+}
+
+func TestGetHTML_InvalidWaitUntil(t *testing.T) {
+	client := NewClient("http://localhost:8787")
+	_, err := client.GetHTML(context.Background(), "http://example.com", PageOptions{WaitUntil: "idle"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported waitUntil")
+}
+
+func TestGetStructured_Success(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","properties":{"price":{"type":"number"}}}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/json", r.URL.Path)
+
+		var req StructuredRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.JSONEq(t, string(schema), string(req.Schema))
+
+		w.Write([]byte(`{"data":{"price":19.5},"url":"http://example.com"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	resp, err := client.GetStructured(context.Background(), "http://example.com", schema, PageOptions{})
+
+	require.NoError(t, err)
+	var data struct {
+		Price float64 `json:"price"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &data))
+	assert.Equal(t, 19.5, data.Price)
+}
+
+func TestGetStructured_InvalidSchema(t *testing.T) {
+	client := NewClient("http://localhost:8787")
+	_, err := client.GetStructured(context.Background(), "http://example.com", json.RawMessage(`["not", "an", "object"]`), PageOptions{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema must be a JSON object")
+}
+
+// ---------------------------------------------------------------------------
+// CrawlSite
+// ---------------------------------------------------------------------------
+
+// crawlWorker fakes the worker for a small site. site maps page URLs to the
+// links on that page; fetched counts markdown requests per URL.
+func crawlWorker(t *testing.T, site map[string][]string, fetched map[string]int) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			URL string `json:"url"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		links, ok := site[req.URL]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"navigation failed"}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/markdown":
+			mu.Lock()
+			fetched[req.URL]++
+			mu.Unlock()
+			json.NewEncoder(w).Encode(MarkdownResponse{Content: "# " + req.URL, Title: req.URL, URL: req.URL})
+		case "/links":
+			resp := LinksResponse{}
+			for _, link := range links {
+				resp.Links = append(resp.Links, Link{URL: link})
+			}
+			json.NewEncoder(w).Encode(resp)
+		}
+	}))
+}
+
+func TestCrawlSite_FollowsSameSiteLinks(t *testing.T) {
+	site := map[string][]string{
+		"https://example.com/": {
+			"https://example.com/about#team",
+			"https://www.example.com/blog",
+			"https://other.com/",
+			"https://example.com/brochure.pdf",
+			"https://example.com/missing",
+		},
+		"https://example.com/about":         {"https://example.com/", "https://example.com/about/history"},
+		"https://www.example.com/blog":      {"https://example.com/blog/post-1"},
+		"https://example.com/blog/post-1":   {"https://example.com/blog/post-2"},
+		"https://example.com/about/history": {},
+	}
+	fetched := make(map[string]int)
+	srv := crawlWorker(t, site, fetched)
+	defer srv.Close()
+
+	var reported int
+	client := NewClient(srv.URL, WithMaxConcurrent(2))
+	result, err := client.CrawlSite(context.Background(), "https://example.com", CrawlOptions{
+		MaxDepth: 2,
+		OnPage:   func(CrawledPage) { reported++ },
+	})
+
+	require.NoError(t, err)
+	var urls []string
+	for _, page := range result.Pages {
+		urls = append(urls, page.URL)
+	}
+	assert.Equal(t, []string{
+		"https://example.com/",
+		"https://example.com/about",
+		"https://www.example.com/blog",
+		"https://example.com/missing",
+		"https://example.com/about/history",
+		"https://example.com/blog/post-1",
+	}, urls)
+	assert.Error(t, result.Pages[3].Err)
+	assert.Empty(t, result.Pages[5].Links, "links aren't fetched at MaxDepth")
+	assert.False(t, result.Truncated)
+	assert.Equal(t, len(result.Pages), reported)
+	for url, n := range fetched {
+		assert.Equal(t, 1, n, url)
+	}
+}
+
+func TestCrawlSite_MaxPages(t *testing.T) {
+	site := map[string][]string{
+		"https://example.com/":  {"https://example.com/a", "https://example.com/b", "https://example.com/c"},
+		"https://example.com/a": {},
+		"https://example.com/b": {},
+		"https://example.com/c": {},
+	}
+	srv := crawlWorker(t, site, make(map[string]int))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	result, err := client.CrawlSite(context.Background(), "https://example.com/", CrawlOptions{MaxPages: 3})
+
+	require.NoError(t, err)
+	assert.Len(t, result.Pages, 3)
+	assert.True(t, result.Truncated)
+}
+
+func TestCrawlSite_InvalidStartURL(t *testing.T) {
+	client := NewClient("http://localhost:8787")
+	_, err := client.CrawlSite(context.Background(), "ftp://example.com", CrawlOptions{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid crawl start URL")
+}
+
+// ---------------------------------------------------------------------------
+// Capabilities
+// ---------------------------------------------------------------------------
+
+func TestCapabilities_Success(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/health", r.URL.Path)
+
+		w.Write([]byte(`{"status":"ok","version":"1.4.0","features":["markdown","links","pdf"],"maxConcurrency":2}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	caps, err := client.Capabilities(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.0", caps.Version)
+	assert.Equal(t, 2, caps.MaxConcurrency)
+	assert.True(t, caps.Supports(FeaturePDF))
+	assert.False(t, caps.Supports(FeatureScrape))
+
+	// Second call is served from cache.
+	_, err = client.Capabilities(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCapabilities_LegacyHealth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	caps, err := client.Capabilities(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, caps.Supports(FeatureMarkdown))
+	assert.True(t, caps.Supports(FeatureScrape))
+	assert.False(t, caps.Supports(FeaturePDF))
+}
+
+// ---------------------------------------------------------------------------
+// Renderer conformance — shared by the worker Client and ChromeRenderer
+// ---------------------------------------------------------------------------
+
+const conformancePage = `<!doctype html>
+<html><head><title>Fixture</title></head>
+<body>
+<nav><a href="/nav">Nav</a></nav>
+<main>
+<h1>Hello</h1>
+<p>World <a href="/about#team">About</a></p>
+<ul><li>One</li></ul>
+</main>
+</body></html>`
+
+// testRendererConformance asserts the results every Renderer must produce for conformancePage.
+func testRendererConformance(t *testing.T, r Renderer, pageURL string) {
+	ctx := context.Background()
+
+	md, err := r.GetMarkdown(ctx, pageURL, PageOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Fixture", md.Title)
+	assert.Equal(t, "# Fixture\n\n# Hello\n\nWorld\nAbout\n\n- One", md.Content)
+	assert.Equal(t, pageURL, md.URL)
+
+	links, err := r.GetLinks(ctx, pageURL, PageOptions{})
+	require.NoError(t, err)
+	base := strings.TrimSuffix(pageURL, "/")
+	assert.Equal(t, []Link{
+		{URL: base + "/nav", Text: "Nav"},
+		{URL: base + "/about", Text: "About"},
+	}, links.Links)
+
+	scraped, err := r.Scrape(ctx, pageURL, map[string]string{"heading": "h1", "missing": ".nope"}, PageOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"heading": "Hello", "missing": ""}, scraped.Data)
+
+	png, err := r.Screenshot(ctx, pageURL, ScreenshotOptions{FullPage: true})
+	require.NoError(t, err)
+	assert.True(t, isImage(png, ScreenshotPNG))
+
+	jpeg, err := r.Screenshot(ctx, pageURL, ScreenshotOptions{Format: ScreenshotJPEG, Quality: 80})
+	require.NoError(t, err)
+	assert.True(t, isImage(jpeg, ScreenshotJPEG))
+
+	pdf, err := r.RenderPDF(ctx, pageURL, PDFOptions{Margins: PDFMargins{Top: "1cm"}})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+
+	pdf, err = r.RenderHTMLPDF(ctx, conformancePage, PDFOptions{Format: "Letter", Landscape: true})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+}
+
+// newFakeWorker returns canned worker responses for conformancePage served at pageURL.
+func newFakeWorker(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			URL    string `json:"url"`
+			Format string `json:"format"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		base := strings.TrimSuffix(body.URL, "/")
+
+		switch r.URL.Path {
+		case "/markdown":
+			json.NewEncoder(w).Encode(map[string]string{
+				"content": "# Fixture\n\n# Hello\n\nWorld\nAbout\n\n- One",
+				"title":   "Fixture",
+				"url":     body.URL,
+			})
+		case "/links":
+			json.NewEncoder(w).Encode(map[string]any{
+				"links": []map[string]string{
+					{"url": base + "/nav", "text": "Nav"},
+					{"url": base + "/about", "text": "About"},
+				},
+				"url": body.URL,
+			})
+		case "/scrape":
+			w.Write([]byte(`{"data":{"heading":"Hello","missing":null},"url":"` + body.URL + `"}`))
+		case "/screenshot":
+			if body.Format == ScreenshotJPEG {
+				w.Write([]byte{0xFF, 0xD8, 0xFF, 0xE0})
+				return
+			}
+			w.Write([]byte("\x89PNG\r\n\x1a\n"))
+		case "/pdf":
+			w.Write([]byte("%PDF-1.7\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRendererConformance_Client(t *testing.T) {
+	srv := newFakeWorker(t)
+	defer srv.Close()
+
+	testRendererConformance(t, NewClient(srv.URL), "https://example.com/")
+}
+
+func TestRendererConformance_Chrome(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping Chrome test in short mode")
+	}
+	var chromePath string
+	for _, name := range []string{"google-chrome", "chromium", "chromium-browser", "headless-shell"} {
+		if p, err := exec.LookPath(name); err == nil {
+			chromePath = p
+			break
+		}
+	}
+	if chromePath == "" {
+		t.Skip("Chrome not found on PATH")
+	}
+
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(conformancePage))
+	}))
+	defer page.Close()
+
+	r := NewChromeRenderer(WithChromePath(chromePath))
+	defer r.Close()
+
+	testRendererConformance(t, r, page.URL+"/")
+
+	guarded := NewChromeRenderer(WithChromePath(chromePath), WithChromeURLCheck(func(context.Context, string) error {
+		return errors.New("not public")
+	}))
+	defer guarded.Close()
+	_, err := guarded.GetMarkdown(context.Background(), page.URL+"/", PageOptions{})
+	assert.Error(t, err)
+}
+
+func TestChromeRenderer_Allowed(t *testing.T) {
+	check := func(_ context.Context, rawURL string) error {
+		if strings.Contains(rawURL, "internal") {
+			return errors.New("not public")
+		}
+		return nil
+	}
+	r := &ChromeRenderer{checkURL: check}
+
+	assert.True(t, r.allowed(context.Background(), "https://example.com/"))
+	assert.False(t, r.allowed(context.Background(), "http://internal.local/"))
+	assert.True(t, r.allowed(context.Background(), "data:text/html,internal"))
+	assert.True(t, (&ChromeRenderer{}).allowed(context.Background(), "http://internal.local/"))
+}
+
+func TestCSSInches(t *testing.T) {
+	tests := []struct {
+		length  string
+		want    float64
+		wantErr bool
+	}{
+		{length: "1in", want: 1},
+		{length: "2.54cm", want: 1},
+		{length: "25.4mm", want: 1},
+		{length: "72pt", want: 1},
+		{length: "96px", want: 1},
+		{length: "48", want: 0.5},
+		{length: "-1cm", wantErr: true},
+		{length: "1em", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.length, func(t *testing.T) {
+			got, err := cssInches(tt.length)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	r, closeFn, err := NewRenderer(RendererConfig{WorkerURL: "http://localhost:8787"})
+	require.NoError(t, err)
+	defer closeFn()
+	assert.IsType(t, &Client{}, r)
+
+	r, closeFn, err = NewRenderer(RendererConfig{Provider: ProviderLocal})
+	require.NoError(t, err)
+	defer closeFn()
+	assert.IsType(t, &ChromeRenderer{}, r)
+
+	_, _, err = NewRenderer(RendererConfig{Provider: ProviderCloudflare})
+	assert.Error(t, err)
+
+	_, _, err = NewRenderer(RendererConfig{Provider: "bogus"})
+	assert.Error(t, err)
+}
+
 // ---------------------------------------------------------------------------
 // doRequest — retry behaviour
 // ---------------------------------------------------------------------------
@@ -210,13 +829,38 @@ func TestDoRequest_Retry429(t *testing.T) {
 	defer srv.Close()
 
 	client := NewClient(srv.URL)
-	resp, err := client.GetMarkdown(context.Background(), "http://example.com")
+	resp, err := client.GetMarkdown(context.Background(), "http://example.com", PageOptions{})
 
 	require.NoError(t, err)
 	assert.Equal(t, "# Success after retries", resp.Content)
 	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
 }
 
+func TestDoRequest_ObserverReportsRetries(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(MarkdownResponse{Content: "# Observed"})
+	}))
+	defer srv.Close()
+
+	var calls []RequestInfo
+	client := NewClient(srv.URL, WithObserver(func(info RequestInfo) { calls = append(calls, info) }))
+	_, err := client.GetMarkdown(context.Background(), "http://example.com", PageOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	assert.Equal(t, http.MethodPost, calls[0].Method)
+	assert.Equal(t, "/markdown", calls[0].Endpoint)
+	assert.Equal(t, http.StatusOK, calls[0].HTTPStatus)
+	assert.Equal(t, 1, calls[0].Retries)
+	assert.NoError(t, calls[0].Err)
+}
+
 func TestDoRequest_ClientError4xx(t *testing.T) {
 	var attempts int32
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -227,7 +871,7 @@ func TestDoRequest_ClientError4xx(t *testing.T) {
 	defer srv.Close()
 
 	client := NewClient(srv.URL)
-	_, err := client.GetMarkdown(context.Background(), "http://example.com")
+	_, err := client.GetMarkdown(context.Background(), "http://example.com", PageOptions{})
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "unexpected status 400")
@@ -247,7 +891,7 @@ func TestDoRequest_ContextCancelled(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately.
 
-	_, err := client.GetMarkdown(ctx, "http://example.com")
+	_, err := client.GetMarkdown(ctx, "http://example.com", PageOptions{})
 
 	require.Error(t, err)
 	assert.ErrorIs(t, err, context.Canceled)
@@ -282,7 +926,7 @@ func TestDoRequest_ConcurrencyLimit(t *testing.T) {
 	for i := 0; i < 3; i++ {
 		go func() {
 			defer func() { done <- struct{}{} }()
-			_, _ = client.GetMarkdown(context.Background(), "http://example.com")
+			_, _ = client.GetMarkdown(context.Background(), "http://example.com", PageOptions{})
 		}()
 	}
 
@@ -0,0 +1,200 @@
+package cfbrowser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Crawl defaults, used when CrawlOptions leaves a limit at zero.
+const (
+	defaultCrawlDepth = 3
+	defaultCrawlPages = 100
+)
+
+// assetExtensions are link targets that aren't pages, so a crawl doesn't follow them.
+var assetExtensions = []string{
+	".pdf", ".jpg", ".jpeg", ".png", ".gif", ".svg", ".webp", ".ico",
+	".zip", ".gz", ".mp3", ".mp4", ".webm", ".css", ".js", ".xml", ".json",
+}
+
+// CrawlOptions limits a site crawl. Zero values use the defaults noted.
+type CrawlOptions struct {
+	// MaxDepth is how many links away from the start URL to follow. Default: 3.
+	MaxDepth int
+	// MaxPages caps the pages fetched, including ones that fail. Default: 100.
+	MaxPages int
+	// IncludeSubdomains also follows links to subdomains of the start URL's host.
+	// "www." is always treated as the same host.
+	IncludeSubdomains bool
+	// OnPage, if set, is called as each page finishes. Calls never overlap.
+	OnPage func(CrawledPage)
+	// Page is applied to every page fetched.
+	Page PageOptions
+}
+
+// CrawledPage is one page of a crawl.
+type CrawledPage struct {
+	URL     string
+	Depth   int // links away from the start URL
+	Title   string
+	Content string // markdown, as from GetMarkdown
+	Links   []Link // same-site page links, normalized; empty at MaxDepth
+	Err     error  // set if the page couldn't be fetched; the crawl carries on
+}
+
+// CrawlResult is the outcome of a crawl, with pages in breadth-first order.
+type CrawlResult struct {
+	Pages []CrawledPage
+	// Truncated is set when MaxPages stopped the crawl with pages left to visit.
+	Truncated bool
+}
+
+// CrawlSite crawls a site breadth-first from startURL, fetching each page's
+// markdown and following its same-site links until MaxDepth or MaxPages is
+// reached. URLs are visited once, ignoring fragments. Pages are fetched in
+// parallel, bounded by the client's WithMaxConcurrent limit.
+//
+// Failed pages are reported in the result rather than stopping the crawl.
+// If ctx is cancelled, the pages crawled so far are returned with the error.
+func (c *Client) CrawlSite(ctx context.Context, startURL string, opts CrawlOptions) (*CrawlResult, error) {
+	start, ok := normalizeCrawlURL(startURL)
+	if !ok {
+		return nil, fmt.Errorf("cfbrowser: invalid crawl start URL %q", startURL)
+	}
+	if err := opts.Page.validate(); err != nil {
+		return nil, err
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = defaultCrawlDepth
+	}
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = defaultCrawlPages
+	}
+
+	result := &CrawlResult{}
+	visited := map[string]bool{start.String(): true}
+	level := []string{start.String()}
+	var onPage sync.Mutex
+	for depth := 0; len(level) > 0; depth++ {
+		if remaining := opts.MaxPages - len(result.Pages); len(level) > remaining {
+			level = level[:remaining]
+			result.Truncated = true
+		}
+
+		pages := make([]CrawledPage, len(level))
+		c.forEachConcurrently(len(level), func(i int) {
+			pages[i] = c.crawlPage(ctx, level[i], depth, depth < opts.MaxDepth, start, opts)
+			if opts.OnPage != nil {
+				onPage.Lock()
+				opts.OnPage(pages[i])
+				onPage.Unlock()
+			}
+		})
+		result.Pages = append(result.Pages, pages...)
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("cfbrowser: %w", err)
+		}
+
+		var next []string
+		for _, page := range pages {
+			for _, link := range page.Links {
+				if !visited[link.URL] {
+					visited[link.URL] = true
+					next = append(next, link.URL)
+				}
+			}
+		}
+		if len(result.Pages) >= opts.MaxPages {
+			result.Truncated = result.Truncated || len(next) > 0
+			break
+		}
+		level = next
+	}
+	return result, nil
+}
+
+// forEachConcurrently calls fn for 0..n-1 from as many goroutines as the
+// client allows concurrent requests.
+func (c *Client) forEachConcurrently(n int, fn func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(cap(c.sem), n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// crawlPage fetches a page's markdown and, when followLinks is set, its
+// same-site links.
+func (c *Client) crawlPage(ctx context.Context, pageURL string, depth int, followLinks bool, start *url.URL, opts CrawlOptions) CrawledPage {
+	page := CrawledPage{URL: pageURL, Depth: depth}
+	md, err := c.GetMarkdown(ctx, pageURL, opts.Page)
+	if err != nil {
+		page.Err = err
+		return page
+	}
+	page.Title, page.Content = md.Title, md.Content
+	if !followLinks {
+		return page
+	}
+
+	links, err := c.GetLinks(ctx, pageURL, opts.Page)
+	if err != nil {
+		page.Err = err
+		return page
+	}
+	seen := make(map[string]bool)
+	for _, link := range links.Links {
+		u, ok := normalizeCrawlURL(link.URL)
+		if !ok || !sameSite(u.Hostname(), start.Hostname(), opts.IncludeSubdomains) || isAssetPath(u.Path) {
+			continue
+		}
+		if key := u.String(); !seen[key] {
+			seen[key] = true
+			page.Links = append(page.Links, Link{URL: key, Text: link.Text})
+		}
+	}
+	return page
+}
+
+// normalizeCrawlURL parses an absolute http(s) URL, dropping the fragment and
+// lower-casing the host so equivalent links dedupe.
+func normalizeCrawlURL(raw string) (*url.URL, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, false
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Host = strings.ToLower(u.Host)
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u, true
+}
+
+// sameSite reports whether host belongs to the site being crawled.
+func sameSite(host, startHost string, subdomains bool) bool {
+	host = strings.TrimPrefix(host, "www.")
+	startHost = strings.TrimPrefix(startHost, "www.")
+	return host == startHost || (subdomains && strings.HasSuffix(host, "."+startHost))
+}
+
+func isAssetPath(p string) bool {
+	return slices.Contains(assetExtensions, strings.ToLower(path.Ext(p)))
+}
@@ -0,0 +1,81 @@
+package cfbrowser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Features a worker deployment may support.
+const (
+	FeatureMarkdown   = "markdown"
+	FeatureLinks      = "links"
+	FeatureScrape     = "scrape"
+	FeaturePDF        = "pdf"
+	FeatureHTML       = "html"
+	FeatureStructured = "json"
+	FeatureScreenshot = "screenshot"
+	// FeaturePageOptions means every endpoint honours all PageOptions. Older
+	// workers silently ignore them, apart from the wait, header and cookie
+	// options on html and json.
+	FeaturePageOptions = "page-options"
+	// FeaturePDFHTML means the pdf endpoint also accepts an inline document.
+	FeaturePDFHTML = "pdf-html"
+)
+
+// baselineFeatures are assumed for workers whose /health predates capability reporting.
+var baselineFeatures = []string{FeatureMarkdown, FeatureLinks, FeatureScrape}
+
+// capabilitiesTTL is how long a Capabilities result is reused before re-querying /health.
+const capabilitiesTTL = 5 * time.Minute
+
+// Capabilities describes what a worker deployment supports.
+type Capabilities struct {
+	Status         string   `json:"status"`
+	Version        string   `json:"version"`
+	Features       []string `json:"features"`
+	MaxConcurrency int      `json:"maxConcurrency"` // 0 = no hint
+}
+
+// Supports reports whether the worker advertises the given feature.
+func (c *Capabilities) Supports(feature string) bool {
+	return slices.Contains(c.Features, feature)
+}
+
+// capabilitiesCache holds the last successful /health result.
+type capabilitiesCache struct {
+	mu        sync.Mutex
+	caps      *Capabilities
+	fetchedAt time.Time
+}
+
+// Capabilities queries the worker's /health endpoint for its version, supported
+// features and concurrency hint. Results are cached for a few minutes so callers
+// can consult it before routing each request.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	c.caps.mu.Lock()
+	defer c.caps.mu.Unlock()
+	if c.caps.caps != nil && time.Since(c.caps.fetchedAt) < capabilitiesTTL {
+		return c.caps.caps, nil
+	}
+
+	data, err := c.doGet(ctx, "/health")
+	if err != nil {
+		return nil, err
+	}
+
+	var caps Capabilities
+	if err := json.Unmarshal(data, &caps); err != nil {
+		return nil, fmt.Errorf("cfbrowser: decode health response: %w", err)
+	}
+	if caps.Features == nil {
+		caps.Features = slices.Clone(baselineFeatures)
+	}
+
+	c.caps.caps = &caps
+	c.caps.fetchedAt = time.Now()
+	return &caps, nil
+}
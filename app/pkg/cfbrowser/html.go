@@ -0,0 +1,39 @@
+package cfbrowser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// HTMLRequest is the request payload for the html endpoint.
+type HTMLRequest struct {
+	URL string `json:"url"`
+	PageOptions
+}
+
+// HTMLResponse is the response from the html endpoint.
+type HTMLResponse struct {
+	HTML  string `json:"html"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// GetHTML loads a URL and returns the rendered page's HTML, after scripts have run.
+// Workers that predate HTML support don't advertise FeatureHTML and reject the request.
+func (c *Client) GetHTML(ctx context.Context, targetURL string, opts PageOptions) (*HTMLResponse, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := c.doRequest(ctx, "/html", HTMLRequest{URL: targetURL, PageOptions: opts})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp HTMLResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("cfbrowser: decode html response: %w", err)
+	}
+	return &resp, nil
+}
@@ -9,6 +9,7 @@ import (
 // LinksRequest is the request payload for the links endpoint.
 type LinksRequest struct {
 	URL string `json:"url"`
+	PageOptions
 }
 
 // Link represents a single extracted link.
@@ -23,8 +24,12 @@ type LinksResponse struct {
 }
 
 // GetLinks fetches a URL and returns all extracted links.
-func (c *Client) GetLinks(ctx context.Context, targetURL string) (*LinksResponse, error) {
-	data, err := c.doRequest(ctx, "/links", LinksRequest{URL: targetURL})
+func (c *Client) GetLinks(ctx context.Context, targetURL string, opts PageOptions) (*LinksResponse, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := c.doRequest(ctx, "/links", LinksRequest{URL: targetURL, PageOptions: opts})
 	if err != nil {
 		return nil, err
 	}
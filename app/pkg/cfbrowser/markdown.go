@@ -9,6 +9,7 @@ import (
 // MarkdownRequest is the request payload for the markdown endpoint.
 type MarkdownRequest struct {
 	URL string `json:"url"`
+	PageOptions
 }
 
 // MarkdownResponse is the response from the markdown endpoint.
@@ -19,8 +20,12 @@ type MarkdownResponse struct {
 }
 
 // GetMarkdown fetches a URL and returns its content as clean, LLM-ready markdown.
-func (c *Client) GetMarkdown(ctx context.Context, targetURL string) (*MarkdownResponse, error) {
-	data, err := c.doRequest(ctx, "/markdown", MarkdownRequest{URL: targetURL})
+func (c *Client) GetMarkdown(ctx context.Context, targetURL string, opts PageOptions) (*MarkdownResponse, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := c.doRequest(ctx, "/markdown", MarkdownRequest{URL: targetURL, PageOptions: opts})
 	if err != nil {
 		return nil, err
 	}
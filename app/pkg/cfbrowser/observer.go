@@ -0,0 +1,25 @@
+package cfbrowser
+
+import "time"
+
+// RequestInfo describes one Worker call, reported to the observer once the
+// call returns. Retries of the same call are folded into a single RequestInfo.
+// The Worker has no status envelope or per-call cost, so unlike the
+// dataforseo client only the HTTP status is reported.
+type RequestInfo struct {
+	Method     string
+	Endpoint   string        // request path, e.g. "/markdown"
+	Duration   time.Duration // wall time including queueing and retries
+	HTTPStatus int           // status of the last HTTP response; 0 if none arrived
+	Retries    int           // attempts after the first
+	Err        error         // error returned to the caller, if any
+}
+
+// WithObserver registers a callback invoked after every Worker call,
+// successful or not, e.g. to export metrics or structured logs. It runs on the
+// calling goroutine, so it must be fast and safe for concurrent use.
+func WithObserver(observe func(RequestInfo)) Option {
+	return func(c *Client) {
+		c.observer = observe
+	}
+}
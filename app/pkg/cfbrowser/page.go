@@ -0,0 +1,75 @@
+package cfbrowser
+
+import (
+	"fmt"
+	"slices"
+)
+
+// waitUntilEvents are the navigation events the worker can wait for.
+var waitUntilEvents = []string{"load", "domcontentloaded", "networkidle0", "networkidle2"}
+
+// blockableResources are the resource types that can be blocked. The page
+// document itself can't be.
+var blockableResources = []string{
+	"stylesheet", "image", "media", "font", "script", "texttrack",
+	"xhr", "fetch", "eventsource", "websocket", "manifest", "other",
+}
+
+// maxViewportSize bounds each viewport dimension, in CSS pixels.
+const maxViewportSize = 8192
+
+// Cookie is set on the page before it loads. Domain defaults to the target URL's host.
+type Cookie struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Domain string `json:"domain,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+// Viewport is the emulated browser window. A zero DeviceScaleFactor means 1.
+type Viewport struct {
+	Width             int     `json:"width"`
+	Height            int     `json:"height"`
+	DeviceScaleFactor float64 `json:"deviceScaleFactor,omitempty"`
+	IsMobile          bool    `json:"isMobile,omitempty"`
+}
+
+// PageOptions controls how a page is loaded before it is read. Every endpoint
+// accepts them, since some sites serve different content to the default
+// headless agent. The zero value loads the page as the worker always has:
+// default user agent and viewport, JavaScript on, waiting for the network to
+// go idle.
+type PageOptions struct {
+	WaitUntil         string            `json:"waitUntil,omitempty"`       // one of load, domcontentloaded, networkidle0, networkidle2
+	WaitForSelector   string            `json:"waitForSelector,omitempty"` // CSS selector that must appear before reading
+	Headers           map[string]string `json:"headers,omitempty"`         // extra request headers
+	Cookies           []Cookie          `json:"cookies,omitempty"`
+	UserAgent         string            `json:"userAgent,omitempty"`
+	Viewport          *Viewport         `json:"viewport,omitempty"`
+	BlockResources    []string          `json:"blockResources,omitempty"`    // resource types to abort, e.g. "image", "font"
+	TimeoutMS         int               `json:"timeoutMs,omitempty"`         // navigation timeout; 0 = worker default
+	DisableJavaScript bool              `json:"disableJavaScript,omitempty"` // read the server-rendered page only
+}
+
+func (o PageOptions) validate() error {
+	if o.WaitUntil != "" && !slices.Contains(waitUntilEvents, o.WaitUntil) {
+		return fmt.Errorf("cfbrowser: unsupported waitUntil %q", o.WaitUntil)
+	}
+	if v := o.Viewport; v != nil {
+		if v.Width <= 0 || v.Height <= 0 || v.Width > maxViewportSize || v.Height > maxViewportSize {
+			return fmt.Errorf("cfbrowser: viewport %dx%d out of range", v.Width, v.Height)
+		}
+		if v.DeviceScaleFactor < 0 {
+			return fmt.Errorf("cfbrowser: negative deviceScaleFactor %v", v.DeviceScaleFactor)
+		}
+	}
+	for _, r := range o.BlockResources {
+		if !slices.Contains(blockableResources, r) {
+			return fmt.Errorf("cfbrowser: cannot block resource type %q", r)
+		}
+	}
+	if o.TimeoutMS < 0 {
+		return fmt.Errorf("cfbrowser: negative timeoutMs %d", o.TimeoutMS)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cfbrowser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// pdfFormats are the paper sizes the worker accepts.
+var pdfFormats = []string{"A3", "A4", "A5", "Letter", "Legal", "Tabloid"}
+
+// PDFMargins are page margins as CSS lengths, e.g. "1cm" or "0.5in".
+type PDFMargins struct {
+	Top    string `json:"top,omitempty"`
+	Right  string `json:"right,omitempty"`
+	Bottom string `json:"bottom,omitempty"`
+	Left   string `json:"left,omitempty"`
+}
+
+// PDFOptions controls page layout, and how the page is loaded through the
+// embedded PageOptions. The zero value renders A4 portrait with the browser's
+// default margins and no background graphics.
+type PDFOptions struct {
+	Format          string     `json:"format,omitempty"` // one of A3, A4, A5, Letter, Legal, Tabloid
+	Landscape       bool       `json:"landscape,omitempty"`
+	Margins         PDFMargins `json:"margin"`
+	PrintBackground bool       `json:"printBackground,omitempty"`
+	PageOptions
+}
+
+// PDFRequest is the request payload for the pdf endpoint. Exactly one of URL
+// and HTML is set.
+type PDFRequest struct {
+	URL  string `json:"url,omitempty"`
+	HTML string `json:"html,omitempty"`
+	PDFOptions
+}
+
+// RenderPDF loads a URL and prints it to PDF, returning the document bytes.
+// Workers that predate PDF support don't advertise FeaturePDF and reject the request.
+func (c *Client) RenderPDF(ctx context.Context, targetURL string, opts PDFOptions) ([]byte, error) {
+	return c.renderPDF(ctx, PDFRequest{URL: targetURL, PDFOptions: opts})
+}
+
+// RenderHTMLPDF prints an HTML document to PDF, for pages generated server-side
+// that have no URL of their own. Relative links in the document don't resolve,
+// so styles and images must be inline. Requires FeaturePDFHTML.
+func (c *Client) RenderHTMLPDF(ctx context.Context, html string, opts PDFOptions) ([]byte, error) {
+	if html == "" {
+		return nil, fmt.Errorf("cfbrowser: html is required")
+	}
+	return c.renderPDF(ctx, PDFRequest{HTML: html, PDFOptions: opts})
+}
+
+func (o PDFOptions) validate() error {
+	if o.Format != "" && !slices.Contains(pdfFormats, o.Format) {
+		return fmt.Errorf("cfbrowser: unsupported PDF format %q", o.Format)
+	}
+	return o.PageOptions.validate()
+}
+
+func (c *Client) renderPDF(ctx context.Context, req PDFRequest) ([]byte, error) {
+	if err := req.PDFOptions.validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := c.doRequest(ctx, "/pdf", req)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, fmt.Errorf("cfbrowser: pdf response is not a PDF document")
+	}
+	return data, nil
+}
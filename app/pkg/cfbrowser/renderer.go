@@ -0,0 +1,70 @@
+package cfbrowser
+
+import (
+	"context"
+	"fmt"
+)
+
+// Renderer is the browser rendering interface shared by the Cloudflare worker
+// Client and the local ChromeRenderer.
+type Renderer interface {
+	GetMarkdown(ctx context.Context, targetURL string, opts PageOptions) (*MarkdownResponse, error)
+	GetLinks(ctx context.Context, targetURL string, opts PageOptions) (*LinksResponse, error)
+	Scrape(ctx context.Context, targetURL string, selectors map[string]string, opts PageOptions) (*ScrapeResponse, error)
+	Screenshot(ctx context.Context, targetURL string, opts ScreenshotOptions) ([]byte, error)
+	RenderPDF(ctx context.Context, targetURL string, opts PDFOptions) ([]byte, error)
+	RenderHTMLPDF(ctx context.Context, html string, opts PDFOptions) ([]byte, error)
+}
+
+var (
+	_ Renderer = (*Client)(nil)
+	_ Renderer = (*ChromeRenderer)(nil)
+)
+
+// Renderer providers.
+const (
+	ProviderCloudflare = "cloudflare"
+	ProviderLocal      = "local"
+)
+
+// RendererConfig selects and configures a Renderer.
+type RendererConfig struct {
+	Provider      string // ProviderCloudflare (default) or ProviderLocal
+	WorkerURL     string // required for ProviderCloudflare
+	ChromePath    string // optional for ProviderLocal; Chrome is looked up on PATH when empty
+	MaxConcurrent int    // 0 = provider default
+	// CheckURL, when set, vets every request local Chrome makes; see
+	// WithChromeURLCheck. The worker runs outside our network and needs none.
+	CheckURL func(ctx context.Context, rawURL string) error
+}
+
+// NewRenderer returns the Renderer for cfg.Provider. The returned close func
+// releases any local browser process and must be called on shutdown.
+func NewRenderer(cfg RendererConfig) (Renderer, func(), error) {
+	switch cfg.Provider {
+	case ProviderLocal:
+		var opts []ChromeOption
+		if cfg.ChromePath != "" {
+			opts = append(opts, WithChromePath(cfg.ChromePath))
+		}
+		if cfg.MaxConcurrent > 0 {
+			opts = append(opts, WithChromeMaxConcurrent(cfg.MaxConcurrent))
+		}
+		if cfg.CheckURL != nil {
+			opts = append(opts, WithChromeURLCheck(cfg.CheckURL))
+		}
+		r := NewChromeRenderer(opts...)
+		return r, r.Close, nil
+	case ProviderCloudflare, "":
+		if cfg.WorkerURL == "" {
+			return nil, nil, fmt.Errorf("cfbrowser: worker URL is required for provider %q", ProviderCloudflare)
+		}
+		var opts []Option
+		if cfg.MaxConcurrent > 0 {
+			opts = append(opts, WithMaxConcurrent(cfg.MaxConcurrent))
+		}
+		return NewClient(cfg.WorkerURL, opts...), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("cfbrowser: unknown provider %q", cfg.Provider)
+	}
+}
@@ -10,6 +10,7 @@ import (
 type ScrapeRequest struct {
 	URL       string            `json:"url"`
 	Selectors map[string]string `json:"selectors"`
+	PageOptions
 }
 
 // ScrapeResponse is the response from the scrape endpoint.
@@ -19,8 +20,12 @@ type ScrapeResponse struct {
 }
 
 // Scrape fetches a URL and extracts text from the given CSS selectors.
-func (c *Client) Scrape(ctx context.Context, targetURL string, selectors map[string]string) (*ScrapeResponse, error) {
-	data, err := c.doRequest(ctx, "/scrape", ScrapeRequest{URL: targetURL, Selectors: selectors})
+func (c *Client) Scrape(ctx context.Context, targetURL string, selectors map[string]string, opts PageOptions) (*ScrapeResponse, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := c.doRequest(ctx, "/scrape", ScrapeRequest{URL: targetURL, Selectors: selectors, PageOptions: opts})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,79 @@
+package cfbrowser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// Screenshot formats.
+const (
+	ScreenshotPNG  = "png"
+	ScreenshotJPEG = "jpeg"
+)
+
+// ScreenshotOptions controls the image format and area, and how the page is
+// loaded through the embedded PageOptions. The zero value captures the
+// viewport as a PNG.
+type ScreenshotOptions struct {
+	Format   string `json:"format,omitempty"`   // ScreenshotPNG (default) or ScreenshotJPEG
+	Quality  int    `json:"quality,omitempty"`  // JPEG only, 1-100; 0 = browser default
+	FullPage bool   `json:"fullPage,omitempty"` // the whole scrollable page instead of the viewport
+	PageOptions
+}
+
+// ScreenshotRequest is the request payload for the screenshot endpoint.
+type ScreenshotRequest struct {
+	URL string `json:"url"`
+	ScreenshotOptions
+}
+
+func (o ScreenshotOptions) validate() error {
+	switch o.Format {
+	case "", ScreenshotPNG:
+		if o.Quality != 0 {
+			return fmt.Errorf("cfbrowser: quality applies to %s screenshots only", ScreenshotJPEG)
+		}
+	case ScreenshotJPEG:
+		if o.Quality < 0 || o.Quality > 100 {
+			return fmt.Errorf("cfbrowser: screenshot quality %d out of range", o.Quality)
+		}
+	default:
+		return fmt.Errorf("cfbrowser: unsupported screenshot format %q", o.Format)
+	}
+	return o.PageOptions.validate()
+}
+
+// Screenshot loads a URL and captures it as an image, returning the image
+// bytes. Workers that predate screenshots don't advertise FeatureScreenshot
+// and reject the request.
+func (c *Client) Screenshot(ctx context.Context, targetURL string, opts ScreenshotOptions) ([]byte, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := c.doRequest(ctx, "/screenshot", ScreenshotRequest{URL: targetURL, ScreenshotOptions: opts})
+	if err != nil {
+		return nil, err
+	}
+	if !isImage(data, opts.Format) {
+		return nil, fmt.Errorf("cfbrowser: screenshot response is not a %s image", screenshotFormat(opts.Format))
+	}
+	return data, nil
+}
+
+// screenshotFormat returns format, defaulting to PNG.
+func screenshotFormat(format string) string {
+	if format == "" {
+		return ScreenshotPNG
+	}
+	return format
+}
+
+// isImage reports whether data starts with the signature of format.
+func isImage(data []byte, format string) bool {
+	if screenshotFormat(format) == ScreenshotJPEG {
+		return bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF})
+	}
+	return bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n"))
+}
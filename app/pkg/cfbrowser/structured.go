@@ -0,0 +1,46 @@
+package cfbrowser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// StructuredRequest is the request payload for the json endpoint.
+type StructuredRequest struct {
+	URL    string          `json:"url"`
+	Schema json.RawMessage `json:"schema"`
+	PageOptions
+}
+
+// StructuredResponse is the response from the json endpoint. Data conforms to
+// the requested schema.
+type StructuredResponse struct {
+	Data json.RawMessage `json:"data"`
+	URL  string          `json:"url"`
+}
+
+// GetStructured loads a URL and has the worker extract its content as JSON
+// matching schema, a JSON Schema object. Decode Data into the type the schema
+// describes. Workers that predate extraction don't advertise FeatureStructured
+// and reject the request.
+func (c *Client) GetStructured(ctx context.Context, targetURL string, schema json.RawMessage, opts PageOptions) (*StructuredResponse, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	var probe map[string]any
+	if err := json.Unmarshal(schema, &probe); err != nil {
+		return nil, fmt.Errorf("cfbrowser: schema must be a JSON object: %w", err)
+	}
+
+	data, err := c.doRequest(ctx, "/json", StructuredRequest{URL: targetURL, Schema: schema, PageOptions: opts})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp StructuredResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("cfbrowser: decode json response: %w", err)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,196 @@
+// Package contentfetch turns a URL into markdown by trying several providers
+// in priority order, so a page can still be read when one renderer is down.
+package contentfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a provider attempt when its Step doesn't set one.
+const DefaultTimeout = 30 * time.Second
+
+// Document is a page converted to markdown.
+type Document struct {
+	Markdown string
+	Title    string // empty when the provider doesn't report one
+	URL      string // the final URL after redirects, when known
+}
+
+// Provider converts a URL to markdown.
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context, targetURL string) (*Document, error)
+}
+
+// Step is a provider in a Chain with the time it gets before the chain moves on.
+type Step struct {
+	Provider Provider
+	Timeout  time.Duration // 0 = DefaultTimeout
+}
+
+// Kind classifies why a provider attempt failed.
+type Kind string
+
+const (
+	// KindTimeout means the provider didn't answer within its step timeout.
+	KindTimeout Kind = "timeout"
+	// KindUnavailable means the provider couldn't be reached, was rate
+	// limited or failed on its side (5xx).
+	KindUnavailable Kind = "unavailable"
+	// KindRejected means the request was refused with a 4xx: the target page
+	// doesn't exist or blocks the provider, or the provider's credentials or
+	// quota were refused.
+	KindRejected Kind = "rejected"
+	// KindEmpty means the page was fetched but had no readable content.
+	KindEmpty Kind = "empty"
+	// KindUnsupported means the provider reported it can't serve the request,
+	// so the page wasn't loaded.
+	KindUnsupported Kind = "unsupported"
+)
+
+// ErrEmpty is returned by providers when a page has no readable content.
+var ErrEmpty = errors.New("contentfetch: no content")
+
+// ErrUnsupported is returned by providers that can't serve a request, such as
+// a browser worker without a feature the fetch needs.
+var ErrUnsupported = errors.New("contentfetch: unsupported by provider")
+
+// ErrNoProviders is returned by Fetch on a Chain without steps.
+var ErrNoProviders = errors.New("contentfetch: no providers configured")
+
+// Attempt records one provider's try at a URL.
+type Attempt struct {
+	Provider string
+	Duration time.Duration
+	Kind     Kind  // empty on success
+	Err      error // nil on success
+}
+
+// Result is a fetched document with the provider that produced it. Attempts
+// lists every provider tried, in order, the last being the successful one.
+type Result struct {
+	Document
+	Provider string
+	Attempts []Attempt
+}
+
+// FetchError is returned when every provider in a chain failed.
+type FetchError struct {
+	URL      string
+	Attempts []Attempt
+}
+
+func (e *FetchError) Error() string {
+	parts := make([]string, len(e.Attempts))
+	for i, a := range e.Attempts {
+		parts[i] = fmt.Sprintf("%s (%s): %v", a.Provider, a.Kind, a.Err)
+	}
+	return fmt.Sprintf("contentfetch: all providers failed for %s: %s", e.URL, strings.Join(parts, "; "))
+}
+
+// Chain tries its providers in order until one returns content.
+type Chain struct {
+	steps []Step
+}
+
+// NewChain creates a Chain that tries steps in the given order.
+func NewChain(steps ...Step) *Chain {
+	return &Chain{steps: steps}
+}
+
+// Providers returns the names of the chain's providers in priority order.
+func (c *Chain) Providers() []string {
+	names := make([]string, len(c.steps))
+	for i, s := range c.steps {
+		names[i] = s.Provider.Name()
+	}
+	return names
+}
+
+// Fetch returns targetURL as markdown from the first provider that succeeds.
+// Each provider runs under its step's timeout; any failure moves on to the
+// next. When all fail the error is a *FetchError listing every attempt. If
+// ctx ends first, Fetch stops and returns ctx's error.
+func (c *Chain) Fetch(ctx context.Context, targetURL string) (*Result, error) {
+	if err := validateURL(targetURL); err != nil {
+		return nil, err
+	}
+	if len(c.steps) == 0 {
+		return nil, ErrNoProviders
+	}
+
+	attempts := make([]Attempt, 0, len(c.steps))
+	for _, step := range c.steps {
+		doc, attempt := c.try(ctx, step, targetURL)
+		attempts = append(attempts, attempt)
+		if attempt.Err == nil {
+			return &Result{Document: *doc, Provider: attempt.Provider, Attempts: attempts}, nil
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("contentfetch: %w", ctx.Err())
+		}
+	}
+	return nil, &FetchError{URL: targetURL, Attempts: attempts}
+}
+
+// try runs one step and classifies its failure, if any.
+func (c *Chain) try(ctx context.Context, step Step, targetURL string) (*Document, Attempt) {
+	timeout := step.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	attempt := Attempt{Provider: step.Provider.Name()}
+	start := time.Now()
+	doc, err := step.Provider.Fetch(stepCtx, targetURL)
+	attempt.Duration = time.Since(start)
+
+	if err == nil && (doc == nil || strings.TrimSpace(doc.Markdown) == "") {
+		err = ErrEmpty
+	}
+	if err != nil {
+		// The step's own deadline, not the caller's
+		if ctx.Err() == nil && stepCtx.Err() != nil && !errors.Is(err, stepCtx.Err()) {
+			err = fmt.Errorf("%w: %w", err, stepCtx.Err())
+		}
+		attempt.Err = err
+		attempt.Kind = Classify(err)
+		return nil, attempt
+	}
+	return doc, attempt
+}
+
+// Classify reports why a provider call failed.
+func Classify(err error) Kind {
+	if errors.Is(err, ErrEmpty) {
+		return KindEmpty
+	}
+	if errors.Is(err, ErrUnsupported) {
+		return KindUnsupported
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+	if code, ok := statusCode(err); ok {
+		if code == 429 || code >= 500 {
+			return KindUnavailable
+		}
+		return KindRejected
+	}
+	return KindUnavailable
+}
+
+func validateURL(targetURL string) error {
+	u, err := url.Parse(targetURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("contentfetch: invalid URL %q", targetURL)
+	}
+	return nil
+}
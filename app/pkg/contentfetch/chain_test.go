@@ -0,0 +1,311 @@
+package contentfetch
+
+import (
+	"app/pkg/cfbrowser"
+	"app/pkg/jina"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider returns a fixed document or error, or blocks until ctx ends.
+type fakeProvider struct {
+	name  string
+	doc   *Document
+	err   error
+	block bool
+	calls int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Fetch(ctx context.Context, targetURL string) (*Document, error) {
+	p.calls++
+	if p.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return p.doc, p.err
+}
+
+// ---------------------------------------------------------------------------
+// Chain
+// ---------------------------------------------------------------------------
+
+func TestChain_FirstProviderSucceeds(t *testing.T) {
+	first := &fakeProvider{name: "first", doc: &Document{Markdown: "# Hello"}}
+	second := &fakeProvider{name: "second", doc: &Document{Markdown: "# Other"}}
+	chain := NewChain(Step{Provider: first}, Step{Provider: second})
+
+	res, err := chain.Fetch(context.Background(), "https://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, "first", res.Provider)
+	assert.Equal(t, "# Hello", res.Markdown)
+	assert.Len(t, res.Attempts, 1)
+	assert.Equal(t, 0, second.calls)
+}
+
+func TestChain_FallsBackAndRecordsAttempts(t *testing.T) {
+	down := &fakeProvider{name: "down", err: &jina.StatusError{StatusCode: http.StatusServiceUnavailable}}
+	empty := &fakeProvider{name: "empty", doc: &Document{Markdown: "  \n"}}
+	ok := &fakeProvider{name: "ok", doc: &Document{Markdown: "content"}}
+	chain := NewChain(Step{Provider: down}, Step{Provider: empty}, Step{Provider: ok})
+
+	res, err := chain.Fetch(context.Background(), "https://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", res.Provider)
+	require.Len(t, res.Attempts, 3)
+	assert.Equal(t, KindUnavailable, res.Attempts[0].Kind)
+	assert.Equal(t, KindEmpty, res.Attempts[1].Kind)
+	assert.NoError(t, res.Attempts[2].Err)
+	assert.Empty(t, res.Attempts[2].Kind)
+}
+
+func TestChain_StepTimeout(t *testing.T) {
+	slow := &fakeProvider{name: "slow", block: true}
+	ok := &fakeProvider{name: "ok", doc: &Document{Markdown: "content"}}
+	chain := NewChain(Step{Provider: slow, Timeout: 20 * time.Millisecond}, Step{Provider: ok})
+
+	res, err := chain.Fetch(context.Background(), "https://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", res.Provider)
+	assert.Equal(t, KindTimeout, res.Attempts[0].Kind)
+}
+
+func TestChain_AllFail(t *testing.T) {
+	chain := NewChain(
+		Step{Provider: &fakeProvider{name: "a", err: &cfbrowser.StatusError{StatusCode: http.StatusNotFound}}},
+		Step{Provider: &fakeProvider{name: "b", err: errors.New("connection refused")}},
+	)
+
+	_, err := chain.Fetch(context.Background(), "https://example.com")
+
+	var fetchErr *FetchError
+	require.ErrorAs(t, err, &fetchErr)
+	require.Len(t, fetchErr.Attempts, 2)
+	assert.Equal(t, KindRejected, fetchErr.Attempts[0].Kind)
+	assert.Equal(t, KindUnavailable, fetchErr.Attempts[1].Kind)
+	assert.Contains(t, err.Error(), "a (rejected)")
+}
+
+func TestChain_StopsWhenCallerCancels(t *testing.T) {
+	slow := &fakeProvider{name: "slow", block: true}
+	next := &fakeProvider{name: "next", doc: &Document{Markdown: "content"}}
+	chain := NewChain(Step{Provider: slow}, Step{Provider: next})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := chain.Fetch(ctx, "https://example.com")
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, next.calls)
+}
+
+func TestChain_InvalidURLAndNoProviders(t *testing.T) {
+	_, err := NewChain(Step{Provider: &fakeProvider{name: "a"}}).Fetch(context.Background(), "ftp://example.com")
+	assert.ErrorContains(t, err, "invalid URL")
+
+	_, err = NewChain().Fetch(context.Background(), "https://example.com")
+	assert.ErrorIs(t, err, ErrNoProviders)
+}
+
+// ---------------------------------------------------------------------------
+// Providers
+// ---------------------------------------------------------------------------
+
+func TestJinaProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/https://example.com", r.URL.Path)
+		w.Write([]byte("# From Jina"))
+	}))
+	defer srv.Close()
+
+	p := Jina(jina.NewClient(jina.WithBaseURL(srv.URL+"/"), jina.WithRetry(0, 0)))
+	doc, err := p.Fetch(context.Background(), "https://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, ProviderJina, p.Name())
+	assert.Equal(t, "# From Jina", doc.Markdown)
+}
+
+func TestBrowserProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			w.Write([]byte(`{"status":"ok","version":"1.4.0","features":["markdown"]}`))
+			return
+		}
+		assert.Equal(t, "/markdown", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"content":"# Rendered","title":"Page","url":"https://example.com/final"}`))
+	}))
+	defer srv.Close()
+
+	p := Browser(cfbrowser.NewClient(srv.URL), cfbrowser.PageOptions{})
+	doc, err := p.Fetch(context.Background(), "https://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, ProviderCFBrowser, p.Name())
+	assert.Equal(t, "# Rendered", doc.Markdown)
+	assert.Equal(t, "Page", doc.Title)
+	assert.Equal(t, "https://example.com/final", doc.URL)
+}
+
+func TestBrowserProvider_ConsultsCapabilities(t *testing.T) {
+	tests := []struct {
+		name    string
+		health  string
+		opts    cfbrowser.PageOptions
+		wantErr bool
+		renders int32
+	}{
+		{name: "markdown supported", health: `{"status":"ok","features":["markdown"]}`, renders: 1},
+		{name: "markdown missing", health: `{"status":"ok","features":["pdf"]}`, wantErr: true},
+		{name: "options without page-options", health: `{"status":"ok","features":["markdown"]}`, opts: cfbrowser.PageOptions{UserAgent: "Bot"}, wantErr: true},
+		{name: "options with page-options", health: `{"status":"ok","features":["markdown","page-options"]}`, opts: cfbrowser.PageOptions{UserAgent: "Bot"}, renders: 1},
+		{name: "probe fails", health: "", renders: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var renders int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/health" {
+					if tt.health == "" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.Write([]byte(tt.health))
+					return
+				}
+				atomic.AddInt32(&renders, 1)
+				w.Write([]byte(`{"content":"# Rendered"}`))
+			}))
+			defer srv.Close()
+
+			p := Browser(cfbrowser.NewClient(srv.URL), tt.opts)
+			_, err := p.Fetch(context.Background(), "https://example.com")
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrUnsupported)
+				assert.Equal(t, KindUnsupported, Classify(err))
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.renders, atomic.LoadInt32(&renders))
+		})
+	}
+}
+
+func TestHTTPProvider_ConvertsHTML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, httpUserAgent, r.Header.Get("User-Agent"))
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><title>Fish &amp; Chips</title><style>p{}</style></head>
+<body><script>var x = "<p>";</script>
+<h1>Menu</h1><p>Fresh <b>daily</b>.</p>
+<ul><li>Cod</li><li><a href="https://example.com/hake">Hake</a></li></ul>
+<!-- hidden --></body></html>`))
+	}))
+	defer srv.Close()
+
+	doc, err := HTTP(nil).Fetch(context.Background(), srv.URL)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Fish & Chips", doc.Title)
+	assert.Equal(t, "# Menu\n\nFresh daily.\n\n- Cod\n- [Hake](https://example.com/hake)", doc.Markdown)
+}
+
+func TestHTTPProvider_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, err := HTTP(nil).Fetch(context.Background(), srv.URL)
+
+	require.Error(t, err)
+	assert.Equal(t, KindRejected, Classify(err))
+}
+
+func TestHTTPProvider_UnsupportedContentType(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4"))
+	}))
+	defer srv.Close()
+
+	_, err := HTTP(nil).Fetch(context.Background(), srv.URL)
+
+	assert.ErrorContains(t, err, "unsupported content type")
+}
+
+// ---------------------------------------------------------------------------
+// Guard
+// ---------------------------------------------------------------------------
+
+func TestCheckPublic(t *testing.T) {
+	orig := lookupIPAddr
+	t.Cleanup(func() { lookupIPAddr = orig })
+	lookupIPAddr = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		switch host {
+		case "example.com":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.215.14")}}, nil
+		case "rebind.example":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.215.14")}, {IP: net.ParseIP("10.0.0.5")}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	ctx := context.Background()
+	assert.NoError(t, CheckPublic(ctx, "https://example.com/page"))
+	assert.NoError(t, CheckPublic(ctx, "http://93.184.215.14/"))
+	for _, u := range []string{
+		"http://127.0.0.1:8080/",
+		"http://10.1.2.3/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/",
+		"http://0.0.0.0/",
+		"https://rebind.example/",
+	} {
+		assert.ErrorIs(t, CheckPublic(ctx, u), ErrPrivateAddress, u)
+	}
+	assert.Error(t, CheckPublic(ctx, "https://unknown.example/"))
+	assert.Error(t, CheckPublic(ctx, "file:///etc/passwd"))
+}
+
+func TestPublicClient_RefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer srv.Close()
+
+	_, err := PublicClient().Get(srv.URL)
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
+
+// ---------------------------------------------------------------------------
+// Links
+// ---------------------------------------------------------------------------
+
+func TestLinks(t *testing.T) {
+	md := "See [the docs](https://example.com/docs \"Docs\") and [home](https://example.com/).\n" +
+		"![logo](https://example.com/logo.png)\n" +
+		"- [docs again](https://example.com/docs)\n" +
+		"- [relative](/about)"
+
+	assert.Equal(t, []Link{
+		{URL: "https://example.com/docs", Text: "the docs"},
+		{URL: "https://example.com/", Text: "home"},
+	}, Links(md))
+	assert.Empty(t, Links("no links here"))
+}
@@ -0,0 +1,85 @@
+package contentfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned when a URL's host resolves to an address
+// that isn't on the public internet.
+var ErrPrivateAddress = errors.New("contentfetch: URL resolves to a private address")
+
+// lookupIPAddr resolves hosts for CheckPublic; tests replace it.
+var lookupIPAddr = net.DefaultResolver.LookupIPAddr
+
+// CheckPublic returns ErrPrivateAddress when any address targetURL's host
+// resolves to is loopback, private, link-local or unspecified. Callers
+// taking URLs from users check them before fetching, so the chain can't be
+// used to read internal services. The answer can change by the time a
+// provider resolves the host again; PublicClient closes that gap for the
+// plain HTTP provider.
+func CheckPublic(ctx context.Context, targetURL string) error {
+	if err := validateURL(targetURL); err != nil {
+		return err
+	}
+	u, _ := url.Parse(targetURL)
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}
+	addrs, err := lookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("contentfetch: resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// PublicClient returns an HTTP client for the plain HTTP provider that
+// refuses to connect to anything but public addresses, redirects included.
+func PublicClient() *http.Client {
+	return &http.Client{Transport: PublicTransport()}
+}
+
+// PublicTransport returns a transport that refuses to connect to anything but
+// public addresses. It checks the address actually dialled, so a host that
+// resolves differently after CheckPublic still can't reach internal
+// services. Other packages sending requests to user-supplied URLs build
+// their clients on it.
+func PublicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return transport
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified())
+}
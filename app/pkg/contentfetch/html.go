@@ -0,0 +1,92 @@
+package contentfetch
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// The plain HTTP provider's conversion is deliberately rough: headings,
+// paragraphs, list items and links survive, everything else becomes text.
+var (
+	reComment   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	reTitle     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	reBody      = regexp.MustCompile(`(?is)<body[^>]*>(.*)</body>`)
+	reLink      = regexp.MustCompile(`(?is)<a\s[^>]*?href\s*=\s*["']([^"']+)["'][^>]*>(.*?)</a>`)
+	reListItem  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	reLineBreak = regexp.MustCompile(`(?i)<br\s*/?>`)
+	reBlock     = regexp.MustCompile(`(?i)</?(p|div|section|article|main|header|footer|nav|aside|ul|ol|table|tr|blockquote|pre|figure|form)(\s[^>]*)?>`)
+	reTag       = regexp.MustCompile(`(?s)<[^>]*>`)
+	reSpaces    = regexp.MustCompile(`[ \t\r\f\v]+`)
+	reBlankRuns = regexp.MustCompile(`\n{3,}`)
+
+	// Elements whose content is never readable text
+	reSkipped = []*regexp.Regexp{
+		regexp.MustCompile(`(?is)<script[^>]*>.*?</script>`),
+		regexp.MustCompile(`(?is)<style[^>]*>.*?</style>`),
+		regexp.MustCompile(`(?is)<noscript[^>]*>.*?</noscript>`),
+		regexp.MustCompile(`(?is)<svg[^>]*>.*?</svg>`),
+		regexp.MustCompile(`(?is)<template[^>]*>.*?</template>`),
+	}
+
+	// Go's regexp has no backreferences, so one per heading level
+	reHeadings = func() []*regexp.Regexp {
+		res := make([]*regexp.Regexp, 6)
+		for i := range res {
+			res[i] = regexp.MustCompile(fmt.Sprintf(`(?is)<h%d[^>]*>(.*?)</h%d>`, i+1, i+1))
+		}
+		return res
+	}()
+)
+
+// htmlToMarkdown returns the page title and a markdown approximation of the
+// body of an HTML document.
+func htmlToMarkdown(doc string) (title, markdown string) {
+	doc = reComment.ReplaceAllString(doc, "")
+	if m := reTitle.FindStringSubmatch(doc); m != nil {
+		title = inlineText(m[1])
+	}
+	if m := reBody.FindStringSubmatch(doc); m != nil {
+		doc = m[1]
+	}
+	for _, re := range reSkipped {
+		doc = re.ReplaceAllString(doc, "")
+	}
+
+	for i, re := range reHeadings {
+		prefix := strings.Repeat("#", i+1) + " "
+		doc = re.ReplaceAllStringFunc(doc, func(s string) string {
+			text := inlineText(re.FindStringSubmatch(s)[1])
+			if text == "" {
+				return ""
+			}
+			return "\n\n" + prefix + text + "\n\n"
+		})
+	}
+	doc = reLink.ReplaceAllStringFunc(doc, func(s string) string {
+		m := reLink.FindStringSubmatch(s)
+		href, text := html.UnescapeString(m[1]), inlineText(m[2])
+		if text == "" || !(strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://")) {
+			return text
+		}
+		return "[" + text + "](" + href + ")"
+	})
+	doc = reListItem.ReplaceAllString(doc, "\n- ")
+	doc = reLineBreak.ReplaceAllString(doc, "\n")
+	doc = reBlock.ReplaceAllString(doc, "\n\n")
+	doc = reTag.ReplaceAllString(doc, "")
+	doc = html.UnescapeString(doc)
+
+	lines := strings.Split(doc, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(reSpaces.ReplaceAllString(line, " "))
+	}
+	markdown = reBlankRuns.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return title, strings.TrimSpace(markdown)
+}
+
+// inlineText strips the tags from an HTML fragment and collapses it to one line.
+func inlineText(fragment string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(reTag.ReplaceAllString(fragment, " "))), " ")
+}
@@ -0,0 +1,35 @@
+package contentfetch
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Link is a link found in a document's markdown.
+type Link struct {
+	URL  string
+	Text string
+}
+
+// reMarkdownLink matches [text](http(s)://url "optional title"). Image
+// links, ![alt](src), are told apart by the character before the match.
+var reMarkdownLink = regexp.MustCompile(`\[([^\[\]]*)\]\((https?://[^)\s]+)(?:\s+"[^"]*")?\)`)
+
+// Links returns the absolute links in markdown, once each, in the order they
+// first appear. Images aren't links.
+func Links(markdown string) []Link {
+	seen := make(map[string]bool)
+	links := make([]Link, 0)
+	for _, m := range reMarkdownLink.FindAllStringSubmatchIndex(markdown, -1) {
+		if m[0] > 0 && markdown[m[0]-1] == '!' {
+			continue
+		}
+		href := markdown[m[4]:m[5]]
+		if seen[href] {
+			continue
+		}
+		seen[href] = true
+		links = append(links, Link{URL: href, Text: strings.TrimSpace(markdown[m[2]:m[3]])})
+	}
+	return links
+}
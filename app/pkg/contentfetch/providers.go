@@ -0,0 +1,182 @@
+package contentfetch
+
+import (
+	"app/pkg/cfbrowser"
+	"app/pkg/jina"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Provider names.
+const (
+	ProviderJina      = "jina"
+	ProviderCFBrowser = "cfbrowser"
+	ProviderHTTP      = "http"
+)
+
+// maxHTTPBody caps how much of a page the plain HTTP provider reads.
+const maxHTTPBody = 5 << 20
+
+// httpUserAgent identifies the plain HTTP provider to the sites it fetches.
+const httpUserAgent = "LeapLearn-ContentFetch/1.0"
+
+type jinaProvider struct {
+	client *jina.Client
+}
+
+// Jina returns a Provider backed by Jina Reader.
+func Jina(client *jina.Client) Provider {
+	return jinaProvider{client: client}
+}
+
+func (p jinaProvider) Name() string { return ProviderJina }
+
+func (p jinaProvider) Fetch(ctx context.Context, targetURL string) (*Document, error) {
+	md, err := p.client.GetMarkdown(ctx, targetURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Markdown: md, URL: targetURL}, nil
+}
+
+// capabilityProber is implemented by renderers that report what they
+// support, like the Cloudflare worker client.
+type capabilityProber interface {
+	Capabilities(ctx context.Context) (*cfbrowser.Capabilities, error)
+}
+
+type browserProvider struct {
+	renderer cfbrowser.Renderer
+	opts     cfbrowser.PageOptions
+}
+
+// Browser returns a Provider backed by a cfbrowser Renderer, either the
+// Cloudflare worker or local Chrome. opts apply to every page it loads.
+func Browser(renderer cfbrowser.Renderer, opts cfbrowser.PageOptions) Provider {
+	return browserProvider{renderer: renderer, opts: opts}
+}
+
+func (p browserProvider) Name() string { return ProviderCFBrowser }
+
+func (p browserProvider) Fetch(ctx context.Context, targetURL string) (*Document, error) {
+	if err := p.supported(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := p.renderer.GetMarkdown(ctx, targetURL, p.opts)
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{Markdown: resp.Content, Title: resp.Title, URL: resp.URL}
+	if doc.URL == "" {
+		doc.URL = targetURL
+	}
+	return doc, nil
+}
+
+// supported checks the features the renderer advertises, when it reports
+// them, against what a fetch needs: markdown, and page options when any are
+// set, since older workers silently ignore them. A failed probe doesn't rule
+// the renderer out; the render itself shows whether it's reachable.
+func (p browserProvider) supported(ctx context.Context) error {
+	prober, ok := p.renderer.(capabilityProber)
+	if !ok {
+		return nil
+	}
+	caps, err := prober.Capabilities(ctx)
+	if err != nil {
+		return nil
+	}
+	if !caps.Supports(cfbrowser.FeatureMarkdown) {
+		return fmt.Errorf("%w: worker %s has no %s", ErrUnsupported, caps.Version, cfbrowser.FeatureMarkdown)
+	}
+	if !reflect.ValueOf(p.opts).IsZero() && !caps.Supports(cfbrowser.FeaturePageOptions) {
+		return fmt.Errorf("%w: worker %s has no %s", ErrUnsupported, caps.Version, cfbrowser.FeaturePageOptions)
+	}
+	return nil
+}
+
+type httpProvider struct {
+	client *http.Client
+}
+
+// HTTP returns a Provider that fetches the page directly and converts its
+// HTML to markdown. It doesn't run scripts, so it's the last resort for
+// client-rendered pages. A nil client uses http.DefaultClient; the chain's
+// step timeout bounds each fetch.
+func HTTP(client *http.Client) Provider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return httpProvider{client: client}
+}
+
+func (p httpProvider) Name() string { return ProviderHTTP }
+
+func (p httpProvider) Fetch(ctx context.Context, targetURL string) (*Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("contentfetch: create request: %w", err)
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("contentfetch: execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBody))
+	if err != nil {
+		return nil, fmt.Errorf("contentfetch: read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	doc := &Document{URL: resp.Request.URL.String()}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "text/plain" || mediaType == "text/markdown":
+		doc.Markdown = strings.TrimSpace(string(body))
+	case mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		doc.Title, doc.Markdown = htmlToMarkdown(string(body))
+	default:
+		return nil, fmt.Errorf("contentfetch: unsupported content type %q", mediaType)
+	}
+	return doc, nil
+}
+
+// StatusError is returned by the plain HTTP provider when the page answers
+// with a non-2xx status.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("contentfetch: unexpected status %d", e.StatusCode)
+}
+
+// statusCode extracts the HTTP status from the errors of the providers'
+// clients.
+func statusCode(err error) (int, bool) {
+	var fetchErr *StatusError
+	if errors.As(err, &fetchErr) {
+		return fetchErr.StatusCode, true
+	}
+	var jinaErr *jina.StatusError
+	if errors.As(err, &jinaErr) {
+		return jinaErr.StatusCode, true
+	}
+	var browserErr *cfbrowser.StatusError
+	if errors.As(err, &browserErr) {
+		return browserErr.StatusCode, true
+	}
+	return 0, false
+}
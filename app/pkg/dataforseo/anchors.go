@@ -0,0 +1,271 @@
+package dataforseo
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Anchor categories, in the order AnalyzeAnchors reports them.
+const (
+	AnchorBranded      = "branded"       // mentions the brand
+	AnchorExactMatch   = "exact_match"   // is one of the target keywords
+	AnchorPartialMatch = "partial_match" // shares words with a target keyword
+	AnchorNakedURL     = "naked_url"     // a bare URL or domain
+	AnchorGeneric      = "generic"       // "click here", "website" and the like
+	AnchorOther        = "other"         // anything else, including image links with no text
+)
+
+var anchorCategories = []string{AnchorBranded, AnchorExactMatch, AnchorPartialMatch, AnchorNakedURL, AnchorGeneric, AnchorOther}
+
+// Anchor profile risk levels.
+const (
+	AnchorRiskLow    = "low"
+	AnchorRiskMedium = "medium" // at least one threshold crossed
+	AnchorRiskHigh   = "high"   // exact match anchors at twice their threshold or more
+)
+
+// DefaultGenericAnchors are the anchors treated as generic when
+// AnchorRules.Generic is nil.
+var DefaultGenericAnchors = []string{
+	"click here", "here", "this", "link", "more", "read more", "learn more",
+	"more info", "find out more", "see more", "view", "visit", "visit site",
+	"visit website", "website", "this website", "site", "this site", "homepage",
+	"home", "source", "go", "continue reading", "official site", "check it out",
+}
+
+// AnchorThresholds are the shares of referring domains, in percent, past
+// which an anchor profile looks over-optimised. A zero field takes its
+// default.
+type AnchorThresholds struct {
+	ExactMatchMax float64 `json:"exactMatchMax"` // exact match anchors; default 10
+	MoneyMax      float64 `json:"moneyMax"`      // exact and partial match anchors together; default 30
+	BrandedMin    float64 `json:"brandedMin"`    // branded and naked URL anchors together; default 20
+}
+
+// DefaultAnchorThresholds are conservative limits drawn from natural link
+// profiles, where most anchors are branded, bare URLs or generic.
+var DefaultAnchorThresholds = AnchorThresholds{ExactMatchMax: 10, MoneyMax: 30, BrandedMin: 20}
+
+func (t AnchorThresholds) withDefaults() AnchorThresholds {
+	if t.ExactMatchMax <= 0 {
+		t.ExactMatchMax = DefaultAnchorThresholds.ExactMatchMax
+	}
+	if t.MoneyMax <= 0 {
+		t.MoneyMax = DefaultAnchorThresholds.MoneyMax
+	}
+	if t.BrandedMin <= 0 {
+		t.BrandedMin = DefaultAnchorThresholds.BrandedMin
+	}
+	return t
+}
+
+// AnchorRules says what counts as which kind of anchor. Matching ignores
+// case and punctuation.
+type AnchorRules struct {
+	Brand      []string // brand names, e.g. "Acme" or "Acme Shoes"
+	Keywords   []string // keywords the target is optimising for
+	Generic    []string // nil uses DefaultGenericAnchors
+	Thresholds AnchorThresholds
+}
+
+// ClassifiedAnchor is an anchor with its category.
+type ClassifiedAnchor struct {
+	Anchor           string `json:"anchor"`
+	Category         string `json:"category"`
+	Backlinks        int64  `json:"backlinks"`
+	ReferringDomains int    `json:"referringDomains"`
+}
+
+// AnchorBucket totals one category.
+type AnchorBucket struct {
+	Category         string  `json:"category"`
+	Anchors          int     `json:"anchors"`
+	Backlinks        int64   `json:"backlinks"`
+	ReferringDomains int     `json:"referringDomains"`
+	Percent          float64 `json:"percent"` // of referring domains, to one decimal place
+}
+
+// AnchorWarning is a threshold the profile crossed.
+type AnchorWarning struct {
+	Rule      string  `json:"rule"` // exact_match, money or branded
+	Percent   float64 `json:"percent"`
+	Threshold float64 `json:"threshold"`
+	Message   string  `json:"message"`
+}
+
+// AnchorAnalysis is the anchor text profile of a target.
+type AnchorAnalysis struct {
+	ReferringDomains int                `json:"referringDomains"` // summed over anchors, so a domain linking with two anchors counts twice
+	Distribution     []AnchorBucket     `json:"distribution"`     // every category, in a fixed order
+	Risk             string             `json:"risk"`
+	Warnings         []AnchorWarning    `json:"warnings"`
+	Anchors          []ClassifiedAnchor `json:"anchors"`
+}
+
+var (
+	schemeURLPattern = regexp.MustCompile(`^(https?://|www\.)\S+$`)
+	domainPattern    = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)*\.[a-z]{2,}(:\d+)?(/\S*)?$`)
+)
+
+// partialMatchStopwords don't count towards a partial match.
+var partialMatchStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "at": true, "by": true, "for": true, "from": true,
+	"in": true, "is": true, "of": true, "on": true, "or": true, "the": true, "to": true, "with": true,
+}
+
+// ClassifyAnchor returns the category of one anchor. The checks run in the
+// order naked URL, branded, exact match, generic, partial match, so an
+// anchor that names both the brand and a keyword counts as branded.
+func ClassifyAnchor(anchor string, rules AnchorRules) string {
+	raw := strings.ToLower(strings.TrimSpace(anchor))
+	words := anchorWords(raw)
+	switch {
+	case len(words) == 0:
+		return AnchorOther
+	case schemeURLPattern.MatchString(raw) || domainPattern.MatchString(raw):
+		return AnchorNakedURL
+	}
+
+	text := strings.Join(words, " ")
+	padded := " " + text + " "
+	for _, brand := range rules.Brand {
+		if b := strings.Join(anchorWords(strings.ToLower(brand)), " "); b != "" && strings.Contains(padded, " "+b+" ") {
+			return AnchorBranded
+		}
+	}
+	for _, keyword := range rules.Keywords {
+		if text == strings.Join(anchorWords(strings.ToLower(keyword)), " ") {
+			return AnchorExactMatch
+		}
+	}
+	generic := rules.Generic
+	if generic == nil {
+		generic = DefaultGenericAnchors
+	}
+	for _, g := range generic {
+		if text == strings.Join(anchorWords(strings.ToLower(g)), " ") {
+			return AnchorGeneric
+		}
+	}
+	for _, keyword := range rules.Keywords {
+		if partialMatch(words, anchorWords(strings.ToLower(keyword))) {
+			return AnchorPartialMatch
+		}
+	}
+	return AnchorOther
+}
+
+// partialMatch reports whether the anchor has at least half of the
+// keyword's significant words.
+func partialMatch(anchor, keyword []string) bool {
+	var significant, found int
+	for _, w := range keyword {
+		if partialMatchStopwords[w] {
+			continue
+		}
+		significant++
+		if slices.Contains(anchor, w) {
+			found++
+		}
+	}
+	return significant > 0 && found > 0 && found*2 >= significant
+}
+
+// anchorWords splits lowercased text into words, dropping punctuation.
+func anchorWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// AnalyzeAnchors classifies anchors, as returned by GetAnchors, and weighs
+// the categories by referring domains, which sitewide links can't skew the
+// way they skew backlink counts. The thresholds in rules decide the
+// warnings.
+func AnalyzeAnchors(anchors []AnchorText, rules AnchorRules) AnchorAnalysis {
+	buckets := make(map[string]*AnchorBucket, len(anchorCategories))
+	a := AnchorAnalysis{
+		Distribution: make([]AnchorBucket, len(anchorCategories)),
+		Risk:         AnchorRiskLow,
+		Warnings:     []AnchorWarning{},
+		Anchors:      make([]ClassifiedAnchor, len(anchors)),
+	}
+	for i, category := range anchorCategories {
+		a.Distribution[i].Category = category
+		buckets[category] = &a.Distribution[i]
+	}
+
+	for i, anchor := range anchors {
+		category := ClassifyAnchor(anchor.Anchor, rules)
+		a.Anchors[i] = ClassifiedAnchor{
+			Anchor:           anchor.Anchor,
+			Category:         category,
+			Backlinks:        anchor.Backlinks,
+			ReferringDomains: anchor.ReferringDomains,
+		}
+		b := buckets[category]
+		b.Anchors++
+		b.Backlinks += anchor.Backlinks
+		b.ReferringDomains += anchor.ReferringDomains
+		a.ReferringDomains += anchor.ReferringDomains
+	}
+	if a.ReferringDomains == 0 {
+		return a
+	}
+
+	share := func(categories ...string) float64 {
+		var n int
+		for _, c := range categories {
+			n += buckets[c].ReferringDomains
+		}
+		return float64(n) * 100 / float64(a.ReferringDomains)
+	}
+	for i := range a.Distribution {
+		a.Distribution[i].Percent = roundPercent(share(a.Distribution[i].Category))
+	}
+
+	t := rules.Thresholds.withDefaults()
+	exact := share(AnchorExactMatch)
+	if exact > t.ExactMatchMax {
+		a.Warnings = append(a.Warnings, AnchorWarning{
+			Rule:      "exact_match",
+			Percent:   roundPercent(exact),
+			Threshold: t.ExactMatchMax,
+			Message:   fmt.Sprintf("%.1f%% of referring domains use exact match anchors, above the %.0f%% limit", exact, t.ExactMatchMax),
+		})
+	}
+	if money := share(AnchorExactMatch, AnchorPartialMatch); money > t.MoneyMax {
+		a.Warnings = append(a.Warnings, AnchorWarning{
+			Rule:      "money",
+			Percent:   roundPercent(money),
+			Threshold: t.MoneyMax,
+			Message:   fmt.Sprintf("%.1f%% of referring domains use keyword anchors, above the %.0f%% limit", money, t.MoneyMax),
+		})
+	}
+	// Only a concern when keywords are used instead; a profile of mostly
+	// generic anchors is unremarkable
+	if branded := share(AnchorBranded, AnchorNakedURL); branded < t.BrandedMin && len(a.Warnings) > 0 {
+		a.Warnings = append(a.Warnings, AnchorWarning{
+			Rule:      "branded",
+			Percent:   roundPercent(branded),
+			Threshold: t.BrandedMin,
+			Message:   fmt.Sprintf("Only %.1f%% of referring domains use branded or URL anchors, below the %.0f%% expected", branded, t.BrandedMin),
+		})
+	}
+
+	switch {
+	case exact >= 2*t.ExactMatchMax:
+		a.Risk = AnchorRiskHigh
+	case len(a.Warnings) > 0:
+		a.Risk = AnchorRiskMedium
+	}
+	return a
+}
+
+func roundPercent(p float64) float64 {
+	return math.Round(p*10) / 10
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
+	"time"
 )
 
 // BacklinksSummary contains the backlink profile summary for a target.
@@ -169,3 +171,353 @@ func (c *Client) GetAnchors(ctx context.Context, target string, limit, offset in
 	}
 	return results[0].Items, nil
 }
+
+// Backlink is a single link pointing at the target.
+type Backlink struct {
+	Type                  string   `json:"type"`
+	DomainFrom            string   `json:"domain_from"`
+	URLFrom               string   `json:"url_from"`
+	URLFromHTTPS          bool     `json:"url_from_https"`
+	DomainTo              string   `json:"domain_to"`
+	URLTo                 string   `json:"url_to"`
+	URLToHTTPS            bool     `json:"url_to_https"`
+	TLDFrom               string   `json:"tld_from"`
+	IsNew                 bool     `json:"is_new"`
+	IsLost                bool     `json:"is_lost"`
+	BacklinkSpamScore     int      `json:"backlink_spam_score"`
+	Rank                  int      `json:"rank"`
+	PageFromRank          int      `json:"page_from_rank"`
+	DomainFromRank        int      `json:"domain_from_rank"`
+	DomainFromCountry     string   `json:"domain_from_country"`
+	PageFromExternalLinks int      `json:"page_from_external_links"`
+	PageFromInternalLinks int      `json:"page_from_internal_links"`
+	PageFromLanguage      string   `json:"page_from_language"`
+	PageFromTitle         string   `json:"page_from_title"`
+	PageFromStatusCode    int      `json:"page_from_status_code"`
+	FirstSeen             string   `json:"first_seen"`
+	PrevSeen              string   `json:"prev_seen"`
+	LastSeen              string   `json:"last_seen"`
+	ItemType              string   `json:"item_type"`
+	Attributes            []string `json:"attributes"`
+	Dofollow              bool     `json:"dofollow"`
+	Original              bool     `json:"original"`
+	Alt                   string   `json:"alt"`
+	ImageURL              string   `json:"image_url"`
+	Anchor                string   `json:"anchor"`
+	TextPre               string   `json:"text_pre"`
+	TextPost              string   `json:"text_post"`
+	SemanticLocation      string   `json:"semantic_location"`
+	LinksCount            int      `json:"links_count"`
+	GroupCount            int      `json:"group_count"`
+	IsBroken              bool     `json:"is_broken"`
+	URLToStatusCode       int      `json:"url_to_status_code"`
+	URLToSpamScore        int      `json:"url_to_spam_score"`
+	URLToRedirectTarget   string   `json:"url_to_redirect_target"`
+	IsIndirectLink        bool     `json:"is_indirect_link"`
+}
+
+// Backlink grouping modes for GetBacklinks.
+const (
+	BacklinksModeAsIs         = "as_is"          // every backlink
+	BacklinksModeOnePerDomain = "one_per_domain" // one backlink per referring domain
+	BacklinksModeOnePerAnchor = "one_per_anchor" // one backlink per anchor
+)
+
+// maxBacklinksPageSize is the most backlinks a single request returns.
+const maxBacklinksPageSize = 1000
+
+// BacklinksOptions selects, sorts and pages the backlinks list. Filter and
+// order fields are relative to the items, e.g. "dofollow" or "rank".
+type BacklinksOptions struct {
+	Mode    string // defaults to BacklinksModeAsIs
+	Limit   int    // up to 1000 per request
+	Offset  int
+	Filter  Filter
+	OrderBy []Order // up to 3 rules
+	// SearchAfterToken continues a previous listing from its BacklinksPage,
+	// which is how results past the API's offset cap are reached. The filter
+	// and order must match the request that issued it.
+	SearchAfterToken string
+}
+
+func (o BacklinksOptions) validate() error {
+	switch o.Mode {
+	case "", BacklinksModeAsIs, BacklinksModeOnePerDomain, BacklinksModeOnePerAnchor:
+	default:
+		return fmt.Errorf("dataforseo: invalid backlinks mode %q", o.Mode)
+	}
+	if o.Limit > maxBacklinksPageSize {
+		return fmt.Errorf("dataforseo: backlinks limit must be at most %d", maxBacklinksPageSize)
+	}
+	return validateQuery(o.Filter, o.OrderBy)
+}
+
+// backlinksRequest is the request body for backlinks/backlinks/live.
+type backlinksRequest struct {
+	Target           string   `json:"target"`
+	Mode             string   `json:"mode,omitempty"`
+	Limit            int      `json:"limit,omitempty"`
+	Offset           int      `json:"offset,omitempty"`
+	Filters          []any    `json:"filters,omitempty"`
+	OrderBy          []string `json:"order_by,omitempty"`
+	SearchAfterToken string   `json:"search_after_token,omitempty"`
+}
+
+// BacklinksPage is one page of the backlinks list.
+type BacklinksPage struct {
+	Target     string     `json:"target"`
+	Mode       string     `json:"mode"`
+	TotalCount int        `json:"total_count"`
+	ItemsCount int        `json:"items_count"`
+	Items      []Backlink `json:"items"`
+	// SearchAfterToken fetches the next page when passed back in BacklinksOptions.
+	SearchAfterToken string `json:"search_after_token"`
+}
+
+// GetBacklinks retrieves one page of raw backlinks pointing at a domain,
+// subdomain or page. Use StreamBacklinks to walk the whole list.
+func (c *Client) GetBacklinks(ctx context.Context, target string, opts BacklinksOptions) (*BacklinksPage, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	payload := []backlinksRequest{{
+		Target:           target,
+		Mode:             opts.Mode,
+		Limit:            opts.Limit,
+		Offset:           opts.Offset,
+		Filters:          opts.Filter.body(),
+		OrderBy:          orderRules(opts.OrderBy),
+		SearchAfterToken: opts.SearchAfterToken,
+	}}
+	resp, err := c.post(ctx, "/backlinks/backlinks/live", payload)
+	if err != nil {
+		return nil, err
+	}
+	var results []BacklinksPage
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return &BacklinksPage{Target: target}, nil
+	}
+	return &results[0], nil
+}
+
+// StreamBacklinks yields every backlink matching opts, fetching pages of
+// opts.Limit (default 1000) on demand and following the search-after token, so
+// large profiles aren't held in memory. Each page goes through the client's
+// concurrency limit, retries and budget like any other request. Stop early by
+// breaking out of the loop; an error is yielded once and ends the stream.
+//
+//	for link, err := range client.StreamBacklinks(ctx, "example.com", opts) {
+//		if err != nil { ... }
+//	}
+func (c *Client) StreamBacklinks(ctx context.Context, target string, opts BacklinksOptions) iter.Seq2[Backlink, error] {
+	return func(yield func(Backlink, error) bool) {
+		if opts.Limit <= 0 {
+			opts.Limit = maxBacklinksPageSize
+		}
+		for {
+			page, err := c.GetBacklinks(ctx, target, opts)
+			if err != nil {
+				yield(Backlink{}, err)
+				return
+			}
+			for _, link := range page.Items {
+				if !yield(link, nil) {
+					return
+				}
+			}
+			if len(page.Items) < opts.Limit || page.SearchAfterToken == "" {
+				return
+			}
+			// The token encodes the position, so the offset applies only to the first page
+			opts.SearchAfterToken = page.SearchAfterToken
+			opts.Offset = 0
+		}
+	}
+}
+
+// BacklinksHistoryPoint is a monthly snapshot of a domain's backlink profile.
+type BacklinksHistoryPoint struct {
+	Type                 string `json:"type"`
+	Date                 string `json:"date"`
+	Rank                 int    `json:"rank"`
+	Backlinks            int64  `json:"backlinks"`
+	NewBacklinks         int64  `json:"new_backlinks"`
+	LostBacklinks        int64  `json:"lost_backlinks"`
+	NewReferringDomains  int    `json:"new_referring_domains"`
+	LostReferringDomains int    `json:"lost_referring_domains"`
+	CrawledPages         int    `json:"crawled_pages"`
+	ReferringDomains     int    `json:"referring_domains"`
+	ReferringMainDomains int    `json:"referring_main_domains"`
+	ReferringIPs         int    `json:"referring_ips"`
+	ReferringSubnets     int    `json:"referring_subnets"`
+	ReferringPages       int    `json:"referring_pages"`
+}
+
+// backlinksDateRangeRequest is the request body for date-ranged backlinks endpoints.
+type backlinksDateRangeRequest struct {
+	Target     string `json:"target"`
+	DateFrom   string `json:"date_from,omitempty"`
+	DateTo     string `json:"date_to,omitempty"`
+	GroupRange string `json:"group_range,omitempty"`
+}
+
+// backlinksHistoryResult wraps the backlinks history response.
+type backlinksHistoryResult struct {
+	Target     string                  `json:"target"`
+	DateFrom   string                  `json:"date_from"`
+	DateTo     string                  `json:"date_to"`
+	TotalCount int                     `json:"total_count"`
+	ItemsCount int                     `json:"items_count"`
+	Items      []BacklinksHistoryPoint `json:"items"`
+}
+
+// GetBacklinksHistory retrieves monthly backlink metrics for a domain between
+// dateFrom and dateTo (yyyy-mm-dd, either may be empty for the API default).
+func (c *Client) GetBacklinksHistory(ctx context.Context, target, dateFrom, dateTo string) ([]BacklinksHistoryPoint, error) {
+	target, err := NormalizeTarget(target, TargetDomain)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDateRange(dateFrom, dateTo); err != nil {
+		return nil, err
+	}
+	payload := []backlinksDateRangeRequest{{
+		Target:   target,
+		DateFrom: dateFrom,
+		DateTo:   dateTo,
+	}}
+	resp, err := c.post(ctx, "/backlinks/history/live", payload)
+	if err != nil {
+		return nil, err
+	}
+	var results []backlinksHistoryResult
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("dataforseo: empty backlinks history result")
+	}
+	return results[0].Items, nil
+}
+
+// Group ranges for the backlinks timeseries endpoints.
+const (
+	GroupDay   = "day"
+	GroupWeek  = "week"
+	GroupMonth = "month"
+	GroupYear  = "year"
+)
+
+// NewLostPoint counts what a target gained and lost in one timeseries period.
+type NewLostPoint struct {
+	Date string `json:"date"`
+	New  int64  `json:"new"`
+	Lost int64  `json:"lost"`
+}
+
+// newLostSummaryItem is a single period from timeseries_new_lost_summary.
+type newLostSummaryItem struct {
+	Type                     string `json:"type"`
+	Date                     string `json:"date"`
+	NewBacklinks             int64  `json:"new_backlinks"`
+	LostBacklinks            int64  `json:"lost_backlinks"`
+	NewReferringDomains      int64  `json:"new_referring_domains"`
+	LostReferringDomains     int64  `json:"lost_referring_domains"`
+	NewReferringMainDomains  int64  `json:"new_referring_main_domains"`
+	LostReferringMainDomains int64  `json:"lost_referring_main_domains"`
+}
+
+// newLostSummaryResult wraps the timeseries new/lost summary response.
+type newLostSummaryResult struct {
+	Target     string               `json:"target"`
+	DateFrom   string               `json:"date_from"`
+	DateTo     string               `json:"date_to"`
+	GroupRange string               `json:"group_range"`
+	ItemsCount int                  `json:"items_count"`
+	Items      []newLostSummaryItem `json:"items"`
+}
+
+// GetNewLostBacklinks retrieves how many backlinks a domain gained and lost
+// per groupRange period (GroupDay, GroupWeek, GroupMonth or GroupYear)
+// between dateFrom and dateTo (yyyy-mm-dd).
+func (c *Client) GetNewLostBacklinks(ctx context.Context, target, dateFrom, dateTo, groupRange string) ([]NewLostPoint, error) {
+	items, err := c.getNewLostSummary(ctx, target, dateFrom, dateTo, groupRange)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]NewLostPoint, len(items))
+	for i, item := range items {
+		points[i] = NewLostPoint{Date: item.Date, New: item.NewBacklinks, Lost: item.LostBacklinks}
+	}
+	return points, nil
+}
+
+// GetNewLostReferringDomains retrieves how many referring domains a domain
+// gained and lost per groupRange period between dateFrom and dateTo (yyyy-mm-dd).
+func (c *Client) GetNewLostReferringDomains(ctx context.Context, target, dateFrom, dateTo, groupRange string) ([]NewLostPoint, error) {
+	items, err := c.getNewLostSummary(ctx, target, dateFrom, dateTo, groupRange)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]NewLostPoint, len(items))
+	for i, item := range items {
+		points[i] = NewLostPoint{Date: item.Date, New: item.NewReferringDomains, Lost: item.LostReferringDomains}
+	}
+	return points, nil
+}
+
+func (c *Client) getNewLostSummary(ctx context.Context, target, dateFrom, dateTo, groupRange string) ([]newLostSummaryItem, error) {
+	target, err := NormalizeTarget(target, TargetDomain)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDateRange(dateFrom, dateTo); err != nil {
+		return nil, err
+	}
+	switch groupRange {
+	case "", GroupDay, GroupWeek, GroupMonth, GroupYear:
+	default:
+		return nil, fmt.Errorf("dataforseo: invalid group range %q", groupRange)
+	}
+	payload := []backlinksDateRangeRequest{{
+		Target:     target,
+		DateFrom:   dateFrom,
+		DateTo:     dateTo,
+		GroupRange: groupRange,
+	}}
+	resp, err := c.post(ctx, "/backlinks/timeseries_new_lost_summary/live", payload)
+	if err != nil {
+		return nil, err
+	}
+	var results []newLostSummaryResult
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("dataforseo: empty new/lost summary result")
+	}
+	return results[0].Items, nil
+}
+
+// validateDateRange checks that the non-empty bounds are yyyy-mm-dd dates in order.
+func validateDateRange(dateFrom, dateTo string) error {
+	var from, to time.Time
+	var err error
+	if dateFrom != "" {
+		if from, err = time.Parse(time.DateOnly, dateFrom); err != nil {
+			return fmt.Errorf("dataforseo: invalid date_from %q (want yyyy-mm-dd)", dateFrom)
+		}
+	}
+	if dateTo != "" {
+		if to, err = time.Parse(time.DateOnly, dateTo); err != nil {
+			return fmt.Errorf("dataforseo: invalid date_to %q (want yyyy-mm-dd)", dateTo)
+		}
+	}
+	if dateFrom != "" && dateTo != "" && to.Before(from) {
+		return fmt.Errorf("dataforseo: date_to %s is before date_from %s", dateTo, dateFrom)
+	}
+	return nil
+}
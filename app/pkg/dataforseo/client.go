@@ -9,6 +9,8 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"reflect"
+	"strings"
 	"time"
 )
 
@@ -24,6 +26,14 @@ type Client struct {
 	authHeader string
 	httpClient *http.Client
 	sem        chan struct{}
+
+	strictDecode bool
+	responseHook ResponseHook
+	pollInterval time.Duration
+	costs        *costTracker
+	lookups      *lookupCache
+	limiter      *rateLimiter
+	observer     func(RequestInfo)
 }
 
 // Option configures the Client.
@@ -43,6 +53,13 @@ func WithTimeout(d time.Duration) Option {
 	}
 }
 
+// WithTransport sets the HTTP transport, e.g. to record calls for debugging.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = rt
+	}
+}
+
 // WithMaxConcurrent sets the maximum number of concurrent requests.
 func WithMaxConcurrent(n int) Option {
 	return func(c *Client) {
@@ -50,6 +67,22 @@ func WithMaxConcurrent(n int) Option {
 	}
 }
 
+// WithStrictDecode enables schema drift detection: each task result is also
+// decoded with DisallowUnknownFields, and any unmodeled field is reported as a
+// warning to the response hook. Decoding itself never fails because of it.
+func WithStrictDecode() Option {
+	return func(c *Client) {
+		c.strictDecode = true
+	}
+}
+
+// WithResponseHook registers a callback invoked after every task result is decoded.
+func WithResponseHook(hook ResponseHook) Option {
+	return func(c *Client) {
+		c.responseHook = hook
+	}
+}
+
 // NewClient creates a new DataForSEO API client.
 func NewClient(login, password string, opts ...Option) *Client {
 	c := &Client{
@@ -57,6 +90,10 @@ func NewClient(login, password string, opts ...Option) *Client {
 		authHeader: "Basic " + base64.StdEncoding.EncodeToString([]byte(login+":"+password)),
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		sem:        make(chan struct{}, 5),
+
+		pollInterval: defaultPollInterval,
+		costs:        newCostTracker(),
+		lookups:      newLookupCache(),
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -89,82 +126,34 @@ type Task struct {
 	Result        json.RawMessage `json:"result"`
 }
 
+// ResponseHook receives a ResponseEvent for each decoded task result.
+type ResponseHook func(ResponseEvent)
+
+// ResponseEvent describes a decoded task result.
+type ResponseEvent struct {
+	Path     string // endpoint path as reported by the API, e.g. "v3/on_page/pages"
+	TaskID   string
+	Cost     float64
+	Warnings []error // schema drift warnings; only populated with WithStrictDecode
+	task     *Task
+}
+
+// RawTask returns the undecoded task, so callers can recover fields the
+// typed results don't model yet.
+func (e ResponseEvent) RawTask() *Task {
+	return e.task
+}
+
 // post sends a POST request with rate limiting, retry on 5xx/429, and response
 // envelope validation. The payload is wrapped in an array as required by the API.
 func (c *Client) post(ctx context.Context, path string, payload any) (*Response, error) {
-	// Acquire semaphore.
-	select {
-	case c.sem <- struct{}{}:
-		defer func() { <-c.sem }()
-	case <-ctx.Done():
-		return nil, fmt.Errorf("dataforseo: %w", ctx.Err())
-	}
-
-	body, err := json.Marshal(payload)
+	resp, err := c.do(ctx, http.MethodPost, path, payload)
 	if err != nil {
-		return nil, fmt.Errorf("dataforseo: marshal payload: %w", err)
-	}
-
-	var resp *Response
-	maxRetries := 3
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
-			select {
-			case <-time.After(backoff):
-			case <-ctx.Done():
-				return nil, fmt.Errorf("dataforseo: %w", ctx.Err())
-			}
-		}
-
-		req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
-		if reqErr != nil {
-			return nil, fmt.Errorf("dataforseo: create request: %w", reqErr)
-		}
-		req.Header.Set("Authorization", c.authHeader)
-		req.Header.Set("Content-Type", "application/json")
-
-		httpResp, doErr := c.httpClient.Do(req)
-		if doErr != nil {
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("dataforseo: request failed: %w", doErr)
-			}
-			continue
-		}
-
-		respBody, readErr := io.ReadAll(httpResp.Body)
-		httpResp.Body.Close()
-		if readErr != nil {
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("dataforseo: read response: %w", readErr)
-			}
-			continue
-		}
-
-		if httpResp.StatusCode == 429 || httpResp.StatusCode >= 500 {
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("dataforseo: HTTP %d: %s", httpResp.StatusCode, string(respBody))
-			}
-			continue
-		}
-
-		if httpResp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("dataforseo: HTTP %d: %s", httpResp.StatusCode, string(respBody))
-		}
-
-		resp = &Response{}
-		if jsonErr := json.Unmarshal(respBody, resp); jsonErr != nil {
-			return nil, fmt.Errorf("dataforseo: unmarshal response: %w", jsonErr)
-		}
-		break
-	}
-
-	if resp == nil {
-		return nil, fmt.Errorf("dataforseo: no response after %d retries", maxRetries)
+		return nil, err
 	}
 
 	if resp.StatusCode != 20000 {
-		return nil, fmt.Errorf("dataforseo: API error %d: %s", resp.StatusCode, resp.StatusMessage)
+		return nil, newAPIError(path, resp)
 	}
 
 	return resp, nil
@@ -174,83 +163,42 @@ func (c *Client) post(ctx context.Context, path string, payload any) (*Response,
 // Use this for endpoints where non-20000 status codes have specific meaning
 // (e.g. 40400 = task not ready on on_page/summary).
 func (c *Client) postRaw(ctx context.Context, path string, payload any) (*Response, error) {
-	select {
-	case c.sem <- struct{}{}:
-		defer func() { <-c.sem }()
-	case <-ctx.Done():
-		return nil, fmt.Errorf("dataforseo: %w", ctx.Err())
-	}
-
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("dataforseo: marshal payload: %w", err)
-	}
-
-	var resp *Response
-	maxRetries := 3
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
-			select {
-			case <-time.After(backoff):
-			case <-ctx.Done():
-				return nil, fmt.Errorf("dataforseo: %w", ctx.Err())
-			}
-		}
-
-		req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
-		if reqErr != nil {
-			return nil, fmt.Errorf("dataforseo: create request: %w", reqErr)
-		}
-		req.Header.Set("Authorization", c.authHeader)
-		req.Header.Set("Content-Type", "application/json")
-
-		httpResp, doErr := c.httpClient.Do(req)
-		if doErr != nil {
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("dataforseo: request failed: %w", doErr)
-			}
-			continue
-		}
-
-		respBody, readErr := io.ReadAll(httpResp.Body)
-		httpResp.Body.Close()
-		if readErr != nil {
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("dataforseo: read response: %w", readErr)
-			}
-			continue
-		}
-
-		if httpResp.StatusCode == 429 || httpResp.StatusCode >= 500 {
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("dataforseo: HTTP %d: %s", httpResp.StatusCode, string(respBody))
-			}
-			continue
-		}
+	// NOTE: intentionally not checking resp.StatusCode — caller handles it
+	return c.do(ctx, http.MethodPost, path, payload)
+}
 
-		if httpResp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("dataforseo: HTTP %d: %s", httpResp.StatusCode, string(respBody))
-		}
+// getRaw sends a GET request with rate limiting and retry. Does NOT check
+// the envelope status code — caller handles it (like postRaw).
+func (c *Client) getRaw(ctx context.Context, path string) (*Response, error) {
+	return c.do(ctx, http.MethodGet, path, nil)
+}
 
-		resp = &Response{}
-		if jsonErr := json.Unmarshal(respBody, resp); jsonErr != nil {
-			return nil, fmt.Errorf("dataforseo: unmarshal response: %w", jsonErr)
+// do sends a request via send and reports it to the observer, if any.
+func (c *Client) do(ctx context.Context, method, path string, payload any) (*Response, error) {
+	info := RequestInfo{Method: method, Endpoint: path}
+	start := time.Now()
+	resp, err := c.send(ctx, method, path, payload, &info)
+	if c.observer != nil {
+		info.Duration = time.Since(start)
+		info.Err = err
+		if resp != nil {
+			info.StatusCode = resp.StatusCode
+			info.Cost = resp.Cost
 		}
-		break
+		c.observer(info)
 	}
+	return resp, err
+}
 
-	if resp == nil {
-		return nil, fmt.Errorf("dataforseo: no response after %d retries", maxRetries)
+// send sends a request with budget enforcement, rate limiting and retry on
+// 5xx/429, and records the cost of the response. A nil payload sends no body.
+// A 429's Retry-After delays the retry and, with WithRateLimit, pauses every caller.
+// The HTTP status and retry count are written to info as they happen.
+func (c *Client) send(ctx context.Context, method, path string, payload any, info *RequestInfo) (*Response, error) {
+	if err := c.costs.checkBudget(ctx); err != nil {
+		return nil, err
 	}
 
-	// NOTE: intentionally not checking resp.StatusCode — caller handles it
-	return resp, nil
-}
-
-// getRaw sends a GET request with rate limiting and retry. Does NOT check
-// the envelope status code — caller handles it (like postRaw).
-func (c *Client) getRaw(ctx context.Context, path string) (*Response, error) {
 	select {
 	case c.sem <- struct{}{}:
 		defer func() { <-c.sem }()
@@ -258,11 +206,22 @@ func (c *Client) getRaw(ctx context.Context, path string) (*Response, error) {
 		return nil, fmt.Errorf("dataforseo: %w", ctx.Err())
 	}
 
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("dataforseo: marshal payload: %w", err)
+		}
+	}
+
 	var resp *Response
+	var retryAfter time.Duration
 	maxRetries := 3
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		info.Retries = attempt
 		if attempt > 0 {
-			backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+			backoff := max(time.Duration(math.Pow(2, float64(attempt)))*time.Second, retryAfter)
 			select {
 			case <-time.After(backoff):
 			case <-ctx.Done():
@@ -270,11 +229,22 @@ func (c *Client) getRaw(ctx context.Context, path string) (*Response, error) {
 			}
 		}
 
-		req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+		var reqBody io.Reader = http.NoBody
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, reqErr := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
 		if reqErr != nil {
 			return nil, fmt.Errorf("dataforseo: create request: %w", reqErr)
 		}
 		req.Header.Set("Authorization", c.authHeader)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
 
 		httpResp, doErr := c.httpClient.Do(req)
 		if doErr != nil {
@@ -284,6 +254,7 @@ func (c *Client) getRaw(ctx context.Context, path string) (*Response, error) {
 			continue
 		}
 
+		info.HTTPStatus = httpResp.StatusCode
 		respBody, readErr := io.ReadAll(httpResp.Body)
 		httpResp.Body.Close()
 		if readErr != nil {
@@ -293,15 +264,19 @@ func (c *Client) getRaw(ctx context.Context, path string) (*Response, error) {
 			continue
 		}
 
+		if httpResp.StatusCode == http.StatusTooManyRequests {
+			retryAfter = parseRetryAfter(httpResp.Header.Get("Retry-After"))
+			c.limiter.pause(retryAfter)
+		}
 		if httpResp.StatusCode == 429 || httpResp.StatusCode >= 500 {
 			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("dataforseo: HTTP %d: %s", httpResp.StatusCode, string(respBody))
+				return nil, &APIError{HTTPStatus: httpResp.StatusCode, Message: string(respBody), Endpoint: path}
 			}
 			continue
 		}
 
 		if httpResp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("dataforseo: HTTP %d: %s", httpResp.StatusCode, string(respBody))
+			return nil, &APIError{HTTPStatus: httpResp.StatusCode, Message: string(respBody), Endpoint: path}
 		}
 
 		resp = &Response{}
@@ -315,6 +290,7 @@ func (c *Client) getRaw(ctx context.Context, path string) (*Response, error) {
 		return nil, fmt.Errorf("dataforseo: no response after %d retries", maxRetries)
 	}
 
+	c.costs.record(ctx, path, resp.Cost)
 	return resp, nil
 }
 
@@ -326,7 +302,7 @@ func (c *Client) firstResult(resp *Response, dst any) error {
 	}
 	task := resp.Tasks[0]
 	if task.StatusCode != 20000 {
-		return fmt.Errorf("dataforseo: task error %d: %s", task.StatusCode, task.StatusMessage)
+		return newTaskError(task)
 	}
 	if task.Result == nil {
 		return fmt.Errorf("dataforseo: empty result")
@@ -334,5 +310,32 @@ func (c *Client) firstResult(resp *Response, dst any) error {
 	if err := json.Unmarshal(task.Result, dst); err != nil {
 		return fmt.Errorf("dataforseo: unmarshal result: %w", err)
 	}
+	if c.responseHook != nil {
+		event := ResponseEvent{
+			Path:   strings.Join(task.Path, "/"),
+			TaskID: task.ID,
+			Cost:   task.Cost,
+			task:   &task,
+		}
+		if c.strictDecode {
+			event.Warnings = detectSchemaDrift(task.Result, dst)
+		}
+		c.responseHook(event)
+	}
+	return nil
+}
+
+// detectSchemaDrift re-decodes raw into a fresh value of dst's type with
+// DisallowUnknownFields and returns the first unknown field, if any.
+func detectSchemaDrift(raw json.RawMessage, dst any) []error {
+	t := reflect.TypeOf(dst)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(reflect.New(t.Elem()).Interface()); err != nil {
+		return []error{fmt.Errorf("dataforseo: schema drift: %w", err)}
+	}
 	return nil
 }
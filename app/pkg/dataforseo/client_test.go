@@ -1,12 +1,18 @@
 package dataforseo
 
 import (
+	"app/pkg/dataforseo/dfsotest"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -21,58 +27,17 @@ import (
 // wrapResponse builds a full DataForSEO JSON response envelope around the
 // given result payload (which is already marshalled into the first task).
 func wrapResponse(result json.RawMessage) []byte {
-	resp := Response{
-		Version:       "0.1",
-		StatusCode:    20000,
-		StatusMessage: "Ok.",
-		Time:          "0.05 sec.",
-		Cost:          0.01,
-		TasksCount:    1,
-		TasksError:    0,
-		Tasks: []Task{{
-			ID:            "01234567-0123-0123-0123-0123456789ab",
-			StatusCode:    20000,
-			StatusMessage: "Ok.",
-			Time:          "0.04 sec.",
-			Cost:          0.01,
-			ResultCount:   1,
-			Path:          []string{"v3", "test"},
-			Data:          json.RawMessage(`{}`),
-			Result:        result,
-		}},
-	}
-	b, _ := json.Marshal(resp)
-	return b
+	return dfsotest.Response(result)
 }
 
 // wrapErrorResponse builds a response with a non-20000 status code.
 func wrapErrorResponse(code int, msg string) []byte {
-	resp := Response{
-		Version:       "0.1",
-		StatusCode:    code,
-		StatusMessage: msg,
-		Time:          "0.01 sec.",
-		TasksCount:    0,
-		TasksError:    0,
-		Tasks:         nil,
-	}
-	b, _ := json.Marshal(resp)
-	return b
+	return dfsotest.ErrorResponse(code, msg)
 }
 
 // wrapEmptyTasksResponse builds a response with StatusCode 20000 but empty Tasks.
 func wrapEmptyTasksResponse() []byte {
-	resp := Response{
-		Version:       "0.1",
-		StatusCode:    20000,
-		StatusMessage: "Ok.",
-		Time:          "0.01 sec.",
-		TasksCount:    0,
-		TasksError:    0,
-		Tasks:         []Task{},
-	}
-	b, _ := json.Marshal(resp)
-	return b
+	return dfsotest.EmptyTasksResponse()
 }
 
 // newTestServer creates an httptest.Server plus a Client pointed at it.
@@ -162,6 +127,122 @@ func TestPost_HTTP4xxError(t *testing.T) {
 	assert.Contains(t, err.Error(), "HTTP 400")
 }
 
+func TestStrictDecode_ReportsUnknownFields(t *testing.T) {
+	result := json.RawMessage(`[{"se_type":"google","items_count":1,"brand_new_field":true,"items":[]}]`)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(wrapResponse(result))
+	})
+
+	var events []ResponseEvent
+	WithStrictDecode()(client)
+	WithResponseHook(func(e ResponseEvent) { events = append(events, e) })(client)
+
+	ctx := context.Background()
+	_, err := client.GetCompetitorDomains(ctx, "example.com", 2840, "en", LabsOptions{Limit: 10})
+	require.NoError(t, err, "unknown fields must not fail decoding")
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "v3/test", events[0].Path)
+	assert.Equal(t, 0.01, events[0].Cost)
+	require.Len(t, events[0].Warnings, 1)
+	assert.Contains(t, events[0].Warnings[0].Error(), `unknown field "brand_new_field"`)
+
+	// The raw task still carries the field for callers that need it.
+	raw := events[0].RawTask()
+	require.NotNil(t, raw)
+	assert.Contains(t, string(raw.Result), "brand_new_field")
+}
+
+func TestResponseHook_NoWarningsWithoutStrictDecode(t *testing.T) {
+	result := json.RawMessage(`[{"se_type":"google","brand_new_field":true,"items":[]}]`)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(wrapResponse(result))
+	})
+
+	var events []ResponseEvent
+	WithResponseHook(func(e ResponseEvent) { events = append(events, e) })(client)
+
+	_, err := client.GetCompetitorDomains(context.Background(), "example.com", 2840, "en", LabsOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Empty(t, events[0].Warnings)
+}
+
+func TestObserver_ReportsEachCall(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.Write(wrapErrorResponse(40501, "Insufficient credits"))
+			return
+		}
+		w.Write(wrapResponse(json.RawMessage(`[]`)))
+	})
+
+	var calls []RequestInfo
+	WithObserver(func(info RequestInfo) { calls = append(calls, info) })(client)
+
+	ctx := context.Background()
+	_, err := client.post(ctx, "/ok", nil)
+	require.NoError(t, err)
+	_, err = client.getRaw(ctx, "/fail")
+	require.NoError(t, err)
+
+	require.Len(t, calls, 2)
+	assert.Equal(t, http.MethodPost, calls[0].Method)
+	assert.Equal(t, "/ok", calls[0].Endpoint)
+	assert.Equal(t, http.StatusOK, calls[0].HTTPStatus)
+	assert.Equal(t, 20000, calls[0].StatusCode)
+	assert.Equal(t, 0.01, calls[0].Cost)
+	assert.Zero(t, calls[0].Retries)
+	assert.Positive(t, calls[0].Duration)
+	assert.NoError(t, calls[0].Err)
+
+	assert.Equal(t, http.MethodGet, calls[1].Method)
+	assert.Equal(t, 40501, calls[1].StatusCode)
+}
+
+// ---------------------------------------------------------------------------
+// Target normalization tests
+// ---------------------------------------------------------------------------
+
+func TestNormalizeTarget(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  TargetKind
+		want  string
+	}{
+		{"example.com", TargetDomain, "example.com"},
+		{"https://www.Example.com/page?x=1", TargetDomain, "example.com"},
+		{"  *.example.com ", TargetDomain, "example.com"},
+		{"blog.example.com.", TargetDomain, "blog.example.com"},
+		{"example.com", TargetWildcard, "*.example.com"},
+		{"http://www.example.com", TargetWildcard, "*.example.com"},
+		{"example.com", TargetURL, "https://example.com/"},
+		{"https://www.Example.com/page?x=1#top", TargetURL, "https://www.example.com/page?x=1"},
+		{"http://example.com:8080/a", TargetURL, "http://example.com:8080/a"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeTarget(tt.input, tt.kind)
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+}
+
+func TestNormalizeTarget_Invalid(t *testing.T) {
+	for _, input := range []string{"", "   ", "localhost", "ftp://example.com", "exa mple.com", "-bad.com", "https://"} {
+		_, err := NormalizeTarget(input, TargetDomain)
+		assert.ErrorIs(t, err, ErrInvalidTarget, input)
+	}
+}
+
+func TestNormalizeKeywords(t *testing.T) {
+	got, err := NormalizeKeywords([]string{"  Learning   Management ", "", "learning management", "h5p quiz", " \t"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Learning Management", "h5p quiz"}, got)
+
+	_, err = NormalizeKeywords([]string{strings.Repeat("k", MaxKeywordLength+1)})
+	assert.ErrorIs(t, err, ErrInvalidKeyword)
+}
+
 // ---------------------------------------------------------------------------
 // OnPage tests
 // ---------------------------------------------------------------------------
@@ -300,6 +381,249 @@ func TestGetOnPagePages_Success(t *testing.T) {
 	assert.Equal(t, "https://example.com/about", pages[1].URL)
 }
 
+func TestGetOnPageResources_Success(t *testing.T) {
+	result := json.RawMessage(`[{"crawl_progress":"finished","items_count":2,"items":[
+		{"resource_type":"image","status_code":200,"url":"https://example.com/a.png","size":1024,"checks":{"is_broken":false}},
+		{"resource_type":"script","status_code":404,"url":"https://example.com/missing.js","checks":{"is_broken":true}}
+	]}]`)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/on_page/resources", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `[{"id":"task-id","limit":50}]`, string(body))
+		w.Write(wrapResponse(result))
+	})
+
+	resources, total, err := client.GetOnPageResources(context.Background(), "task-id", 50, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, resources, 2)
+	assert.Equal(t, "image", resources[0].ResourceType)
+	assert.False(t, resources[0].IsBroken())
+	assert.True(t, resources[1].IsBroken())
+}
+
+func TestGetOnPageLinks_Success(t *testing.T) {
+	result := json.RawMessage(`[{"items_count":1,"items":[
+		{"type":"anchor","page_from":"/","page_to":"/about","link_to":"https://example.com/about","text":"About","direction":"internal","dofollow":true,"is_broken":false,"page_to_status_code":200}
+	]}]`)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/on_page/links", r.URL.Path)
+		w.Write(wrapResponse(result))
+	})
+
+	links, total, err := client.GetOnPageLinks(context.Background(), "task-id", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, links, 1)
+	assert.Equal(t, "internal", links[0].Direction)
+	assert.Equal(t, "About", links[0].Text)
+	assert.True(t, links[0].Dofollow)
+}
+
+func TestGetOnPageDuplicateTags_Success(t *testing.T) {
+	result := json.RawMessage(`[{"items_count":1,"items":[
+		{"accumulator":"Home","total_count":2,"pages":[{"url":"https://example.com/"},{"url":"https://example.com/index.html"}]}
+	]}]`)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/on_page/duplicate_tags", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `[{"id":"task-id","type":"duplicate_title","limit":10}]`, string(body))
+		w.Write(wrapResponse(result))
+	})
+
+	groups, total, err := client.GetOnPageDuplicateTags(context.Background(), "task-id", DuplicateTitle, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "Home", groups[0].Accumulator)
+	assert.Len(t, groups[0].Pages, 2)
+}
+
+func TestGetOnPageNonIndexable_Success(t *testing.T) {
+	result := json.RawMessage(`[{"items_count":1,"items":[{"reason":"robots_txt","url":"https://example.com/private"}]}]`)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/on_page/non_indexable", r.URL.Path)
+		w.Write(wrapResponse(result))
+	})
+
+	pages, total, err := client.GetOnPageNonIndexable(context.Background(), "task-id", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []OnPageNonIndexable{{Reason: "robots_txt", URL: "https://example.com/private"}}, pages)
+}
+
+func TestGetOnPageLighthouse_Success(t *testing.T) {
+	result := json.RawMessage(`[{
+		"lighthouseVersion":"12.0.0","requestedUrl":"https://example.com/","finalUrl":"https://example.com/",
+		"categories":{"performance":{"id":"performance","title":"Performance","score":0.92},"seo":{"id":"seo","title":"SEO","score":null}},
+		"audits":{"largest-contentful-paint":{"id":"largest-contentful-paint","title":"Largest Contentful Paint","score":0.8,"numericValue":2400.5,"numericUnit":"millisecond","displayValue":"2.4 s"}}
+	}]`)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/on_page/lighthouse/live/json", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `[{"url":"https://example.com/","for_mobile":true}]`, string(body))
+		w.Write(wrapResponse(result))
+	})
+
+	report, err := client.GetOnPageLighthouse(context.Background(), "Example.com", true)
+	require.NoError(t, err)
+	assert.Equal(t, "12.0.0", report.LighthouseVersion)
+	require.NotNil(t, report.Categories["performance"].Score)
+	assert.InDelta(t, 0.92, *report.Categories["performance"].Score, 0.001)
+	assert.Nil(t, report.Categories["seo"].Score)
+	assert.Equal(t, "2.4 s", report.Audits["largest-contentful-paint"].DisplayValue)
+}
+
+func TestGetOnPageLighthouse_InvalidURL(t *testing.T) {
+	client := NewClient("login", "password")
+	_, err := client.GetOnPageLighthouse(context.Background(), "ftp://example.com", false)
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+}
+
+func TestGetInstantPage_Success(t *testing.T) {
+	result := json.RawMessage(`[{
+		"crawl_progress":"finished","items_count":1,
+		"items":[{"resource_type":"html","status_code":200,"url":"https://example.com/pricing","onpage_score":91.5,
+			"meta":{"title":"Pricing"},"checks":{"no_title":false,"no_h1_tag":true,"is_https":true}}]
+	}]`)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/on_page/instant_pages", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `[{"url":"https://example.com/pricing","enable_javascript":true}]`, string(body))
+		w.Write(wrapResponse(result))
+	})
+
+	page, err := client.GetInstantPage(context.Background(), InstantPagesRequest{URL: "https://Example.com/pricing#plans", EnableJavascript: true})
+	require.NoError(t, err)
+	assert.Equal(t, 200, page.StatusCode)
+	assert.Equal(t, "Pricing", page.Meta.Title)
+	assert.True(t, page.Checks["no_h1_tag"])
+	assert.False(t, page.Checks["no_title"])
+}
+
+func TestGetInstantPage_Empty(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(wrapResponse(json.RawMessage(`[{"items_count":0,"items":[]}]`)))
+	})
+
+	_, err := client.GetInstantPage(context.Background(), InstantPagesRequest{URL: "https://example.com/"})
+	assert.ErrorContains(t, err, "empty instant pages result")
+}
+
+func TestCreateOnPageTask_PingbackURL(t *testing.T) {
+	pingback, err := PingbackURL("https://app.example.com/api/v1/webhooks/dataforseo", "s3cret")
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.example.com/api/v1/webhooks/dataforseo?id=$id&tag=$tag&token=s3cret", pingback)
+
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var reqs []OnPageTaskPostRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		assert.Equal(t, pingback, reqs[0].PingbackURL)
+
+		resp := Response{StatusCode: 20000, Tasks: []Task{{ID: "task-1", StatusCode: 20100}}}
+		json.NewEncoder(w).Encode(resp)
+	})
+	defer srv.Close()
+
+	id, err := client.CreateOnPageTask(context.Background(), OnPageTaskPostRequest{Target: "example.com", PingbackURL: pingback})
+	require.NoError(t, err)
+	assert.Equal(t, "task-1", id)
+}
+
+// ---------------------------------------------------------------------------
+// Pingback tests
+// ---------------------------------------------------------------------------
+
+func TestPingbackURL_Invalid(t *testing.T) {
+	_, err := PingbackURL("not a url", "")
+	assert.Error(t, err)
+
+	u, err := PingbackURL("https://app.example.com/hook?org=1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.example.com/hook?org=1&id=$id&tag=$tag", u)
+}
+
+func TestPingbackHandler_Get(t *testing.T) {
+	var got Pingback
+	h := PingbackHandler("s3cret", func(ctx context.Context, pb Pingback) error {
+		got = pb
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hook?id=task-1&tag=audit-42&token=s3cret", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, Pingback{TaskID: "task-1", Tag: "audit-42"}, got)
+}
+
+func TestPingbackHandler_InvalidToken(t *testing.T) {
+	called := false
+	h := PingbackHandler("s3cret", func(ctx context.Context, pb Pingback) error {
+		called = true
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hook?id=task-1&token=wrong", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.False(t, called)
+}
+
+func TestPingbackHandler_MissingID(t *testing.T) {
+	h := PingbackHandler("", func(ctx context.Context, pb Pingback) error { return nil })
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hook?id=$id&tag=$tag", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPingbackHandler_PostbackGzip(t *testing.T) {
+	resp := Response{
+		StatusCode: 20000,
+		Tasks: []Task{{
+			ID:         "task-2",
+			StatusCode: 20000,
+			Data:       json.RawMessage(`{"api":"on_page","tag":"audit-7"}`),
+			Result:     json.RawMessage(`[{"crawl_progress":"finished"}]`),
+		}},
+	}
+	raw, _ := json.Marshal(resp)
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(raw)
+	zw.Close()
+
+	var got Pingback
+	h := PingbackHandler("", func(ctx context.Context, pb Pingback) error {
+		got = pb
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hook", &buf))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "task-2", got.TaskID)
+	assert.Equal(t, "audit-7", got.Tag)
+	require.NotNil(t, got.Task)
+	assert.JSONEq(t, `[{"crawl_progress":"finished"}]`, string(got.Task.Result))
+}
+
+func TestPingbackHandler_CallbackError(t *testing.T) {
+	h := PingbackHandler("", func(ctx context.Context, pb Pingback) error {
+		return assert.AnError
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(`{"tasks":[{"id":"task-3"}]}`)))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
 // ---------------------------------------------------------------------------
 // Backlinks tests
 // ---------------------------------------------------------------------------
@@ -435,6 +759,248 @@ func TestGetAnchors_Success(t *testing.T) {
 	assert.Equal(t, "click here", anchors[1].Anchor)
 }
 
+func TestClassifyAnchor(t *testing.T) {
+	rules := AnchorRules{Brand: []string{"Acme"}, Keywords: []string{"running shoes", "trail running shoes"}}
+	tests := []struct {
+		anchor string
+		want   string
+	}{
+		{"Acme", AnchorBranded},
+		{"Acme running shoes", AnchorBranded},
+		{"Running Shoes!", AnchorExactMatch},
+		{"trail running shoes", AnchorExactMatch},
+		{"the best shoes for the road", AnchorPartialMatch},
+		{"https://acme.com/shoes", AnchorNakedURL},
+		{"www.acme.com", AnchorNakedURL},
+		{"acme.com", AnchorNakedURL},
+		{"Click here", AnchorGeneric},
+		{"read more...", AnchorGeneric},
+		{"a great article", AnchorOther},
+		{"", AnchorOther},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyAnchor(tt.anchor, rules), tt.anchor)
+	}
+
+	// Custom generic anchors replace the defaults
+	assert.Equal(t, AnchorGeneric, ClassifyAnchor("hier klicken", AnchorRules{Generic: []string{"hier klicken"}}))
+	assert.Equal(t, AnchorOther, ClassifyAnchor("click here", AnchorRules{Generic: []string{"hier klicken"}}))
+}
+
+func TestAnalyzeAnchors(t *testing.T) {
+	anchors := []AnchorText{
+		{Anchor: "running shoes", Backlinks: 400, ReferringDomains: 30},
+		{Anchor: "cheap running shoes", Backlinks: 50, ReferringDomains: 20},
+		{Anchor: "Acme", Backlinks: 90, ReferringDomains: 10},
+		{Anchor: "click here", Backlinks: 40, ReferringDomains: 25},
+		{Anchor: "acme.com", Backlinks: 5, ReferringDomains: 5},
+		{Anchor: "", Backlinks: 12, ReferringDomains: 10},
+	}
+	a := AnalyzeAnchors(anchors, AnchorRules{Brand: []string{"acme"}, Keywords: []string{"running shoes"}})
+
+	assert.Equal(t, 100, a.ReferringDomains)
+	require.Len(t, a.Distribution, 6)
+	byCategory := make(map[string]AnchorBucket)
+	for _, b := range a.Distribution {
+		byCategory[b.Category] = b
+	}
+	assert.Equal(t, 30.0, byCategory[AnchorExactMatch].Percent)
+	assert.Equal(t, int64(400), byCategory[AnchorExactMatch].Backlinks)
+	assert.Equal(t, 20.0, byCategory[AnchorPartialMatch].Percent)
+	assert.Equal(t, 10.0, byCategory[AnchorBranded].Percent)
+	assert.Equal(t, 5.0, byCategory[AnchorNakedURL].Percent)
+	assert.Equal(t, 25.0, byCategory[AnchorGeneric].Percent)
+	assert.Equal(t, 10.0, byCategory[AnchorOther].Percent)
+	assert.Equal(t, AnchorExactMatch, a.Anchors[0].Category)
+
+	// 30% exact match is three times the default limit
+	assert.Equal(t, AnchorRiskHigh, a.Risk)
+	require.Len(t, a.Warnings, 3)
+	assert.Equal(t, "exact_match", a.Warnings[0].Rule)
+	assert.Equal(t, "money", a.Warnings[1].Rule)
+	assert.Equal(t, 50.0, a.Warnings[1].Percent)
+	assert.Equal(t, "branded", a.Warnings[2].Rule)
+	assert.Equal(t, 15.0, a.Warnings[2].Percent)
+
+	// Looser thresholds clear the warnings
+	a = AnalyzeAnchors(anchors, AnchorRules{
+		Brand:      []string{"acme"},
+		Keywords:   []string{"running shoes"},
+		Thresholds: AnchorThresholds{ExactMatchMax: 40, MoneyMax: 60},
+	})
+	assert.Equal(t, AnchorRiskLow, a.Risk)
+	assert.Empty(t, a.Warnings)
+}
+
+func TestAnalyzeAnchors_Empty(t *testing.T) {
+	a := AnalyzeAnchors(nil, AnchorRules{})
+	assert.Equal(t, AnchorRiskLow, a.Risk)
+	assert.Len(t, a.Distribution, 6)
+	assert.Empty(t, a.Warnings)
+	assert.Empty(t, a.Anchors)
+}
+
+func TestGetBacklinks_Success(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/backlinks/backlinks/live")
+
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `[{
+			"target":"example.com","mode":"one_per_domain","limit":2,
+			"filters":["dofollow","=",true],"order_by":["rank,desc"]
+		}]`, string(body))
+
+		w.Write(wrapResponse(json.RawMessage(`[{"target":"example.com","mode":"one_per_domain","total_count":40,"items_count":2,
+			"search_after_token":"tok-1","items":[
+			{"type":"backlink","domain_from":"blog.example.org","url_from":"https://blog.example.org/post","url_to":"https://example.com/","dofollow":true,"rank":210,"anchor":"example"},
+			{"type":"backlink","domain_from":"news.example.net","url_from":"https://news.example.net/a","url_to":"https://example.com/pricing","dofollow":true,"rank":150}
+		]}]`)))
+	})
+
+	page, err := client.GetBacklinks(context.Background(), "example.com", BacklinksOptions{
+		Mode:    BacklinksModeOnePerDomain,
+		Limit:   2,
+		Filter:  Where("dofollow", OpEq, true),
+		OrderBy: []Order{Desc("rank")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 40, page.TotalCount)
+	assert.Equal(t, "tok-1", page.SearchAfterToken)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "blog.example.org", page.Items[0].DomainFrom)
+	assert.Equal(t, "example", page.Items[0].Anchor)
+	assert.True(t, page.Items[1].Dofollow)
+}
+
+func TestGetBacklinks_InvalidOptions(t *testing.T) {
+	client := NewClient("testlogin", "testpass")
+	ctx := context.Background()
+
+	_, err := client.GetBacklinks(ctx, "example.com", BacklinksOptions{Mode: "all"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid backlinks mode")
+
+	_, err = client.GetBacklinks(ctx, "example.com", BacklinksOptions{Limit: 5000})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at most 1000")
+}
+
+func TestStreamBacklinks_FollowsSearchAfterToken(t *testing.T) {
+	var tokens []string
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var reqs []backlinksRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		assert.Equal(t, 2, reqs[0].Limit)
+		tokens = append(tokens, reqs[0].SearchAfterToken)
+
+		switch reqs[0].SearchAfterToken {
+		case "":
+			assert.Equal(t, 10, reqs[0].Offset)
+			w.Write(wrapResponse(json.RawMessage(`[{"search_after_token":"tok-1","items":[{"url_from":"https://a.com/"},{"url_from":"https://b.com/"}]}]`)))
+		case "tok-1":
+			assert.Zero(t, reqs[0].Offset)
+			w.Write(wrapResponse(json.RawMessage(`[{"search_after_token":"tok-2","items":[{"url_from":"https://c.com/"}]}]`)))
+		default:
+			t.Errorf("unexpected token %q", reqs[0].SearchAfterToken)
+		}
+	})
+
+	var urls []string
+	for link, err := range client.StreamBacklinks(context.Background(), "example.com", BacklinksOptions{Limit: 2, Offset: 10}) {
+		require.NoError(t, err)
+		urls = append(urls, link.URLFrom)
+	}
+	assert.Equal(t, []string{"https://a.com/", "https://b.com/", "https://c.com/"}, urls)
+	assert.Equal(t, []string{"", "tok-1"}, tokens)
+}
+
+func TestStreamBacklinks_StopsEarlyAndYieldsErrors(t *testing.T) {
+	requests := 0
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(wrapResponse(json.RawMessage(`[{"search_after_token":"next","items":[{"url_from":"https://a.com/"},{"url_from":"https://b.com/"}]}]`)))
+	})
+
+	for range client.StreamBacklinks(context.Background(), "example.com", BacklinksOptions{Limit: 2}) {
+		break
+	}
+	assert.Equal(t, 1, requests)
+
+	var errs []error
+	for _, err := range NewClient("testlogin", "testpass").StreamBacklinks(context.Background(), "example.com", BacklinksOptions{Mode: "bogus"}) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "invalid backlinks mode")
+}
+
+func TestGetBacklinksHistory_Success(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/backlinks/history/live")
+
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `[{"target":"example.com","date_from":"2025-01-01","date_to":"2025-06-30"}]`, string(body))
+
+		w.Write(wrapResponse(json.RawMessage(`[{"target":"example.com","items_count":2,"items":[
+			{"type":"backlinks_history","date":"2025-01-01 00:00:00 +00:00","rank":310,"backlinks":1200,"new_backlinks":40,"lost_backlinks":12,"referring_domains":85},
+			{"type":"backlinks_history","date":"2025-02-01 00:00:00 +00:00","rank":315,"backlinks":1228,"new_backlinks":35,"lost_backlinks":7,"referring_domains":88}
+		]}]`)))
+	})
+
+	history, err := client.GetBacklinksHistory(context.Background(), "https://www.example.com/", "2025-01-01", "2025-06-30")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, int64(1200), history[0].Backlinks)
+	assert.Equal(t, int64(12), history[0].LostBacklinks)
+	assert.Equal(t, 88, history[1].ReferringDomains)
+}
+
+func TestGetNewLostBacklinks_Success(t *testing.T) {
+	summary := json.RawMessage(`[{"target":"example.com","group_range":"week","items_count":1,"items":[
+		{"type":"backlinks_timeseries_new_lost_summary","date":"2025-03-03 00:00:00 +00:00","new_backlinks":20,"lost_backlinks":5,"new_referring_domains":3,"lost_referring_domains":1}
+	]}]`)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/backlinks/timeseries_new_lost_summary/live")
+
+		body, _ := io.ReadAll(r.Body)
+		var reqs []backlinksDateRangeRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		assert.Equal(t, "example.com", reqs[0].Target)
+		assert.Equal(t, GroupWeek, reqs[0].GroupRange)
+
+		w.Write(wrapResponse(summary))
+	})
+
+	ctx := context.Background()
+	links, err := client.GetNewLostBacklinks(ctx, "example.com", "2025-03-01", "2025-03-31", GroupWeek)
+	require.NoError(t, err)
+	assert.Equal(t, []NewLostPoint{{Date: "2025-03-03 00:00:00 +00:00", New: 20, Lost: 5}}, links)
+
+	domains, err := client.GetNewLostReferringDomains(ctx, "example.com", "2025-03-01", "2025-03-31", GroupWeek)
+	require.NoError(t, err)
+	assert.Equal(t, []NewLostPoint{{Date: "2025-03-03 00:00:00 +00:00", New: 3, Lost: 1}}, domains)
+}
+
+func TestGetNewLostBacklinks_InvalidParams(t *testing.T) {
+	client := NewClient("testlogin", "testpass")
+	ctx := context.Background()
+
+	_, err := client.GetNewLostBacklinks(ctx, "example.com", "2025-03-01", "2025-03-31", "quarter")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid group range")
+
+	_, err = client.GetNewLostReferringDomains(ctx, "example.com", "2025-03-31", "2025-03-01", GroupDay)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is before date_from")
+
+	_, err = client.GetBacklinksHistory(ctx, "example.com", "03/01/2025", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid date_from")
+}
+
 // ---------------------------------------------------------------------------
 // Keywords tests
 // ---------------------------------------------------------------------------
@@ -505,12 +1071,33 @@ func TestGetSearchVolume_Success(t *testing.T) {
 	assert.Equal(t, 1100, keywords[0].MonthlySearches[0].SearchVolume)
 }
 
-// ---------------------------------------------------------------------------
-// Labs tests
-// ---------------------------------------------------------------------------
+func TestGetSearchVolumeWithCost(t *testing.T) {
+	var calls atomic.Int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write(dfsotest.TasksResponse(dfsotest.Task{ID: "t1", Cost: 0.075, Result: dfsotest.Fixture(dfsotest.FixtureSearchVolume)}))
+	})
 
-func TestGetKeywordSuggestions_Success(t *testing.T) {
-	sv := 500
+	keywords, cost, err := client.GetSearchVolumeWithCost(context.Background(), KeywordSearchVolumeRequest{
+		Keywords:     []string{"running shoes", "trail running shoes"},
+		LocationCode: 2036,
+		LanguageCode: "en",
+	})
+	require.NoError(t, err)
+	assert.Len(t, keywords, 2)
+	assert.Equal(t, 0.075, cost)
+
+	_, _, err = client.GetSearchVolumeWithCost(context.Background(), KeywordSearchVolumeRequest{Keywords: make([]string, MaxSearchVolumeKeywords+1)})
+	assert.ErrorContains(t, err, "exceeds the limit")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// ---------------------------------------------------------------------------
+// Labs tests
+// ---------------------------------------------------------------------------
+
+func TestGetKeywordSuggestions_Success(t *testing.T) {
+	sv := 500
 	comp := 0.65
 	cpc := 1.50
 	kd := 42
@@ -571,7 +1158,7 @@ func TestGetKeywordSuggestions_Success(t *testing.T) {
 	})
 
 	ctx := context.Background()
-	suggestions, err := client.GetKeywordSuggestions(ctx, "seo tools", 2840, "en", 50)
+	suggestions, err := client.GetKeywordSuggestions(ctx, "seo tools", 2840, "en", LabsOptions{Limit: 50})
 	require.NoError(t, err)
 	require.Len(t, suggestions, 2)
 	assert.Equal(t, "seo tools free", suggestions[0].Keyword)
@@ -582,6 +1169,175 @@ func TestGetKeywordSuggestions_Success(t *testing.T) {
 	assert.Equal(t, "best seo tools", suggestions[1].Keyword)
 }
 
+func TestGetKeywordIdeas_Success(t *testing.T) {
+	sv := 1200
+
+	ideasResult := []keywordIdeasResult{{
+		SEType:     "google",
+		Seeds:      []string{"seo tools", "rank tracker"},
+		TotalCount: 10,
+		ItemsCount: 1,
+		Items: []KeywordSuggestion{{
+			SEType:       "google",
+			Keyword:      "backlink checker",
+			LocationCode: 2840,
+			LanguageCode: "en",
+			KeywordInfo: KeywordInfo{
+				SearchVolume:     &sv,
+				CompetitionLevel: "LOW",
+			},
+		}},
+	}}
+
+	result, _ := json.Marshal(ideasResult)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/dataforseo_labs/google/keyword_ideas/live")
+
+		body, _ := io.ReadAll(r.Body)
+		var reqs []keywordIdeasRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		assert.Equal(t, []string{"seo tools", "rank tracker"}, reqs[0].Keywords)
+		assert.Equal(t, 2840, reqs[0].LocationCode)
+		assert.Equal(t, 25, reqs[0].Limit)
+		assert.Equal(t, 50, reqs[0].Offset)
+		assert.Equal(t, []any{"keyword_info.search_volume", ">", float64(100)}, reqs[0].Filters)
+		assert.Equal(t, []string{"keyword_info.search_volume,desc"}, reqs[0].OrderBy)
+
+		w.Write(wrapResponse(result))
+	})
+
+	ctx := context.Background()
+	ideas, err := client.GetKeywordIdeas(ctx, []string{"seo tools", "rank tracker"}, 2840, "en", LabsOptions{
+		Limit:   25,
+		Offset:  50,
+		Filter:  Where("keyword_info.search_volume", OpGreater, 100),
+		OrderBy: []Order{Desc("keyword_info.search_volume")},
+	})
+	require.NoError(t, err)
+	require.Len(t, ideas, 1)
+	assert.Equal(t, "backlink checker", ideas[0].Keyword)
+	assert.Equal(t, 1200, *ideas[0].KeywordInfo.SearchVolume)
+}
+
+func TestGetRelatedKeywords_Success(t *testing.T) {
+	sv := 300
+
+	relatedResult := []relatedKeywordsResult{{
+		SEType:     "google",
+		Seed:       "seo tools",
+		TotalCount: 2,
+		ItemsCount: 2,
+		Items: []relatedKeywordItem{
+			{
+				SEType:          "google",
+				Depth:           0,
+				RelatedKeywords: []string{"free seo tools", "seo audit tool"},
+				KeywordData: &keywordDataNested{
+					Keyword:     "seo tools",
+					KeywordInfo: KeywordInfo{SearchVolume: &sv},
+				},
+			},
+			{
+				SEType:      "google",
+				Depth:       1,
+				KeywordData: &keywordDataNested{Keyword: "free seo tools"},
+			},
+		},
+	}}
+
+	result, _ := json.Marshal(relatedResult)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/dataforseo_labs/google/related_keywords/live")
+
+		body, _ := io.ReadAll(r.Body)
+		var reqs []relatedKeywordsRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		assert.Equal(t, "seo tools", reqs[0].Keyword)
+		assert.Equal(t, 2, reqs[0].Depth)
+
+		w.Write(wrapResponse(result))
+	})
+
+	ctx := context.Background()
+	related, err := client.GetRelatedKeywords(ctx, "seo tools", 2840, "en", 2, LabsOptions{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, related, 2)
+	assert.Equal(t, "seo tools", related[0].Seed)
+	assert.Equal(t, 0, related[0].Depth)
+	assert.Equal(t, []string{"free seo tools", "seo audit tool"}, related[0].RelatedKeywords)
+	assert.Equal(t, 300, *related[0].KeywordInfo.SearchVolume)
+	assert.Equal(t, "free seo tools", related[1].Keyword)
+	assert.Equal(t, 1, related[1].Depth)
+}
+
+func TestGetRelatedKeywords_InvalidDepth(t *testing.T) {
+	client := NewClient("testlogin", "testpass")
+
+	_, err := client.GetRelatedKeywords(context.Background(), "seo tools", 2840, "en", 5, LabsOptions{Limit: 10})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "depth must be between 0 and 4")
+}
+
+func TestGetRelatedKeywords_FilterAndOrder(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `[{
+			"keyword":"seo tools","location_code":2840,"language_code":"en","depth":1,"limit":10,
+			"filters":[["keyword_data.keyword_info.search_volume",">",100],"and",["keyword_data.keyword_properties.keyword_difficulty","<",40]],
+			"order_by":["keyword_data.keyword_info.search_volume,desc"]
+		}]`, string(body))
+		w.Write(wrapResponse(json.RawMessage(`[{"se_type":"google","seed_keyword":"seo tools","items":[]}]`)))
+	})
+
+	related, err := client.GetRelatedKeywords(context.Background(), "seo tools", 2840, "en", 1, LabsOptions{
+		Limit: 10,
+		Filter: And(
+			Where("keyword_data.keyword_info.search_volume", OpGreater, 100),
+			Where("keyword_data.keyword_properties.keyword_difficulty", OpLess, 40),
+		),
+		OrderBy: []Order{Desc("keyword_data.keyword_info.search_volume")},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, related)
+}
+
+func TestLabsOptions_Invalid(t *testing.T) {
+	client := NewClient("testlogin", "testpass")
+	ctx := context.Background()
+
+	_, err := client.GetKeywordIdeas(ctx, []string{"seo"}, 2840, "en", LabsOptions{OrderBy: []Order{Asc("")}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid order_by field")
+
+	_, err = client.GetKeywordIdeas(ctx, []string{"seo"}, 2840, "en", LabsOptions{OrderBy: []Order{Asc("a"), Asc("b"), Asc("c"), Asc("d")}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at most 3 order_by rules")
+
+	var conds []Filter
+	for i := 0; i < 9; i++ {
+		conds = append(conds, Where("keyword_info.search_volume", OpGreater, i))
+	}
+	_, err = client.GetKeywordIdeas(ctx, []string{"seo"}, 2840, "en", LabsOptions{Filter: And(conds...)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at most 8 filter conditions")
+}
+
+func TestFilter_MarshalJSON(t *testing.T) {
+	f := Or(
+		And(Where("a", OpGreater, 1), Filter{}, Where("b", OpIn, []string{"x", "y"})),
+		Where("c", OpLike, "%seo%"),
+	)
+	out, err := json.Marshal(f)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[[["a",">",1],"and",["b","in",["x","y"]]],"or",["c","like","%seo%"]]`, string(out))
+	assert.Equal(t, 3, f.Conditions())
+
+	assert.True(t, And().IsZero())
+	assert.Equal(t, Where("a", OpEq, 1), And(Filter{}, Where("a", OpEq, 1)))
+}
+
 func TestGetDomainRankingKeywords_Success(t *testing.T) {
 	sv := 800
 	kd := 35
@@ -656,7 +1412,7 @@ func TestGetDomainRankingKeywords_Success(t *testing.T) {
 	})
 
 	ctx := context.Background()
-	keywords, total, err := client.GetDomainRankingKeywords(ctx, "example.com", 2840, "en", 10, 0)
+	keywords, total, err := client.GetDomainRankingKeywords(ctx, "example.com", 2840, "en", LabsOptions{Limit: 10})
 	require.NoError(t, err)
 	assert.Equal(t, 250, total)
 	require.Len(t, keywords, 2)
@@ -675,6 +1431,65 @@ func TestGetDomainRankingKeywords_Success(t *testing.T) {
 	assert.Equal(t, "another keyword", keywords[1].Keyword)
 }
 
+func TestGetPageRankingKeywords_Success(t *testing.T) {
+	rankedResult := []domainRankingKeywordsResult{{
+		SEType:     "google",
+		Target:     "example.com",
+		TotalCount: 7,
+		ItemsCount: 1,
+		Items: []domainKeywordItem{{
+			SEType:      "google",
+			KeywordData: &keywordDataNested{Keyword: "seo guide"},
+			RankedSERPElement: &RankedSERPElement{
+				SEType:   "google",
+				SERPItem: SERPItem{RankGroup: 4, URL: "https://www.example.com/blog/seo?ref=1"},
+			},
+		}},
+	}}
+
+	result, _ := json.Marshal(rankedResult)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/dataforseo_labs/google/ranked_keywords/live")
+
+		body, _ := io.ReadAll(r.Body)
+		var reqs []domainRankingKeywordsRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		assert.Equal(t, "example.com", reqs[0].Target)
+		assert.Equal(t, []any{
+			[]any{"ranked_serp_element.serp_item.relative_url", "=", "/blog/seo?ref=1"},
+			"and",
+			[]any{"keyword_data.keyword_properties.keyword_difficulty", "<", float64(40)},
+		}, reqs[0].Filters)
+		assert.Equal(t, []string{"ranked_serp_element.serp_item.etv,desc"}, reqs[0].OrderBy)
+		assert.Equal(t, 20, reqs[0].Limit)
+		assert.Equal(t, 40, reqs[0].Offset)
+
+		w.Write(wrapResponse(result))
+	})
+
+	ctx := context.Background()
+	keywords, total, err := client.GetPageRankingKeywords(ctx, "https://www.example.com/blog/seo?ref=1", 2840, "en", LabsOptions{
+		Limit:   20,
+		Offset:  40,
+		Filter:  Where("keyword_data.keyword_properties.keyword_difficulty", OpLess, 40),
+		OrderBy: []Order{Desc("ranked_serp_element.serp_item.etv")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7, total)
+	require.Len(t, keywords, 1)
+	assert.Equal(t, "seo guide", keywords[0].Keyword)
+	assert.Equal(t, 4, keywords[0].RankedSERPElement.SERPItem.RankGroup)
+}
+
+func TestGetPageRankingKeywords_InvalidURL(t *testing.T) {
+	client := NewClient("testlogin", "testpass")
+
+	_, _, err := client.GetPageRankingKeywords(context.Background(), "ftp://example.com/page", 2840, "en", LabsOptions{Limit: 10})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+}
+
 func TestGetCompetitorDomains_Success(t *testing.T) {
 	competitorResult := []competitorDomainsResult{{
 		SEType:     "google",
@@ -723,7 +1538,7 @@ func TestGetCompetitorDomains_Success(t *testing.T) {
 	})
 
 	ctx := context.Background()
-	competitors, err := client.GetCompetitorDomains(ctx, "example.com", 2840, "en", 20)
+	competitors, err := client.GetCompetitorDomains(ctx, "example.com", 2840, "en", LabsOptions{Limit: 20})
 	require.NoError(t, err)
 	require.Len(t, competitors, 2)
 	assert.Equal(t, "competitor1.com", competitors[0].Domain)
@@ -801,7 +1616,7 @@ func TestGetKeywordGaps_Success(t *testing.T) {
 	})
 
 	ctx := context.Background()
-	gaps, err := client.GetKeywordGaps(ctx, "example.com", "competitor.com", 2840, "en", 10)
+	gaps, err := client.GetKeywordGaps(ctx, "example.com", "competitor.com", 2840, "en", LabsOptions{Limit: 10})
 	require.NoError(t, err)
 	require.Len(t, gaps, 2)
 
@@ -816,6 +1631,550 @@ func TestGetKeywordGaps_Success(t *testing.T) {
 	assert.Equal(t, 20, gaps[1].SecondDomainSERPElement.RankGroup)
 }
 
+func TestGetMissingKeywords_Success(t *testing.T) {
+	sv := 1900
+
+	gapsResult := []domainIntersectionResult{{
+		SEType:     "google",
+		TotalCount: 1,
+		ItemsCount: 1,
+		Items: []KeywordGap{{
+			SEType: "google",
+			KeywordData: &KeywordGapData{
+				Keyword:     "missing keyword",
+				KeywordInfo: KeywordInfo{SearchVolume: &sv},
+			},
+			FirstDomainSERPElement: &SERPItem{
+				RankGroup: 3,
+				URL:       "https://competitor.com/guide",
+			},
+		}},
+	}}
+
+	result, _ := json.Marshal(gapsResult)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/dataforseo_labs/google/domain_intersection/live")
+
+		body, _ := io.ReadAll(r.Body)
+		var reqs []map[string]any
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		// The competitor goes first so only its keywords come back
+		assert.Equal(t, "competitor.com", reqs[0]["target1"])
+		assert.Equal(t, "example.com", reqs[0]["target2"])
+		assert.Equal(t, false, reqs[0]["intersections"])
+
+		w.Write(wrapResponse(result))
+	})
+
+	gaps, err := client.GetMissingKeywords(context.Background(), "example.com", "https://www.competitor.com/", 2840, "en", LabsOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, gaps, 1)
+	assert.Equal(t, "missing keyword", gaps[0].KeywordData.Keyword)
+	assert.Equal(t, 3, gaps[0].FirstDomainSERPElement.RankGroup)
+	assert.Nil(t, gaps[0].SecondDomainSERPElement)
+}
+
+func TestGetKeywordGaps_OmitsIntersections(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.NotContains(t, string(body), "intersections")
+		result, _ := json.Marshal([]domainIntersectionResult{{}})
+		w.Write(wrapResponse(result))
+	})
+
+	_, err := client.GetKeywordGaps(context.Background(), "example.com", "competitor.com", 2840, "en", LabsOptions{})
+	require.NoError(t, err)
+}
+
+func TestGetBulkKeywordDifficulty_Chunks(t *testing.T) {
+	var calls atomic.Int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.Equal(t, "/dataforseo_labs/google/bulk_keyword_difficulty/live", r.URL.Path)
+
+		body, _ := io.ReadAll(r.Body)
+		var reqs []bulkKeywordDifficultyRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		assert.LessOrEqual(t, len(reqs[0].Keywords), 1000)
+		assert.Equal(t, 2036, reqs[0].LocationCode)
+
+		items := make([]KeywordDifficulty, len(reqs[0].Keywords))
+		for i, kw := range reqs[0].Keywords {
+			kd := i % 100
+			items[i] = KeywordDifficulty{Keyword: kw, Difficulty: &kd}
+		}
+		result, _ := json.Marshal([]bulkKeywordDifficultyResult{{Items: items}})
+		w.Write(wrapResponse(result))
+	})
+
+	keywords := make([]string, 1500)
+	for i := range keywords {
+		keywords[i] = fmt.Sprintf("keyword %d", i)
+	}
+
+	difficulties, err := client.GetBulkKeywordDifficulty(context.Background(), keywords, 2036, "en")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+	require.Len(t, difficulties, 1500)
+	assert.Equal(t, "keyword 0", difficulties[0].Keyword)
+	assert.Equal(t, "keyword 1000", difficulties[1000].Keyword)
+	assert.Equal(t, 0, *difficulties[1000].Difficulty)
+	assert.Equal(t, "keyword 1499", difficulties[1499].Keyword)
+}
+
+func TestGetBulkKeywordDifficulty_Empty(t *testing.T) {
+	client := NewClient("testlogin", "testpass")
+	difficulties, err := client.GetBulkKeywordDifficulty(context.Background(), nil, 2840, "en")
+	require.NoError(t, err)
+	assert.Empty(t, difficulties)
+}
+
+func TestGetDomainRankOverview_Success(t *testing.T) {
+	overviewResult := []domainRankOverviewResult{{
+		SEType:     "google",
+		Target:     "example.com",
+		TotalCount: 1,
+		ItemsCount: 1,
+		Items: []DomainRankOverview{{
+			SEType:       "google",
+			LocationCode: 2840,
+			LanguageCode: "en",
+			Metrics: RankOverviewMetrics{
+				Organic: &PositionMetrics{Pos1: 12, Pos2_3: 30, ETV: 15234.5, Count: 870},
+				Paid:    &PositionMetrics{ETV: 120.0, Count: 4},
+			},
+		}},
+	}}
+
+	result, _ := json.Marshal(overviewResult)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/dataforseo_labs/google/domain_rank_overview/live")
+
+		body, _ := io.ReadAll(r.Body)
+		var reqs []domainRankOverviewRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		assert.Equal(t, "example.com", reqs[0].Target)
+		assert.Equal(t, 2840, reqs[0].LocationCode)
+
+		w.Write(wrapResponse(result))
+	})
+
+	ctx := context.Background()
+	overview, err := client.GetDomainRankOverview(ctx, "example.com", 2840, "en")
+	require.NoError(t, err)
+	require.NotNil(t, overview)
+	assert.Equal(t, 12, overview.Metrics.Organic.Pos1)
+	assert.Equal(t, 15234.5, overview.Metrics.Organic.ETV)
+	assert.Equal(t, 870, overview.Metrics.Organic.Count)
+	assert.Equal(t, 4, overview.Metrics.Paid.Count)
+}
+
+func TestGetDomainOverview_Success(t *testing.T) {
+	overviewResult := []domainRankOverviewResult{{
+		SEType: "google",
+		Target: "example.com",
+		Items: []DomainOverview{{
+			SEType:  "google",
+			Metrics: RankOverviewMetrics{Organic: &PositionMetrics{ETV: 15234.5}},
+		}},
+	}}
+
+	result, _ := json.Marshal(overviewResult)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/dataforseo_labs/google/domain_rank_overview/live")
+		w.Write(wrapResponse(result))
+	})
+
+	overview, err := client.GetDomainOverview(context.Background(), "example.com", 2840, "en")
+	require.NoError(t, err)
+	require.NotNil(t, overview)
+	assert.Equal(t, 15234.5, overview.Metrics.Organic.ETV)
+}
+
+func TestGetHistoricalRankOverview_Success(t *testing.T) {
+	historyResult := []historicalRankOverviewResult{{
+		SEType: "google",
+		Target: "example.com",
+		Items: []HistoricalRankPoint{
+			{SEType: "google", Year: 2026, Month: 1, Metrics: RankOverviewMetrics{Organic: &PositionMetrics{ETV: 100}}},
+		},
+	}}
+
+	result, _ := json.Marshal(historyResult)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var reqs []historicalRankOverviewRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		assert.Equal(t, "2026-01-01", reqs[0].DateFrom)
+		// Across all locations and languages
+		assert.Zero(t, reqs[0].LocationCode)
+		assert.Empty(t, reqs[0].LanguageCode)
+		assert.Empty(t, reqs[0].DateTo)
+
+		w.Write(wrapResponse(result))
+	})
+
+	history, err := client.GetHistoricalRankOverview(context.Background(), "example.com", "2026-01-01")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 100.0, history[0].Metrics.Organic.ETV)
+}
+
+func TestGetHistoricalRankOverviewFor_Success(t *testing.T) {
+	historyResult := []historicalRankOverviewResult{{
+		SEType:     "google",
+		Target:     "example.com",
+		TotalCount: 2,
+		ItemsCount: 2,
+		Items: []HistoricalRankPoint{
+			{SEType: "google", Year: 2026, Month: 1, Metrics: RankOverviewMetrics{Organic: &PositionMetrics{ETV: 100}}},
+			{SEType: "google", Year: 2026, Month: 2, Metrics: RankOverviewMetrics{Organic: &PositionMetrics{ETV: 140}}},
+		},
+	}}
+
+	result, _ := json.Marshal(historyResult)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/dataforseo_labs/google/historical_rank_overview/live")
+
+		body, _ := io.ReadAll(r.Body)
+		var reqs []historicalRankOverviewRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		assert.Equal(t, "2026-01-01", reqs[0].DateFrom)
+		assert.Equal(t, "2026-03-01", reqs[0].DateTo)
+		assert.Equal(t, 2036, reqs[0].LocationCode)
+
+		w.Write(wrapResponse(result))
+	})
+
+	ctx := context.Background()
+	history, err := client.GetHistoricalRankOverviewFor(ctx, "example.com", 2036, "en", "2026-01-01", "2026-03-01")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 2, history[1].Month)
+	assert.Equal(t, time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC), history[1].Date())
+	assert.Equal(t, 140.0, history[1].Metrics.Organic.ETV)
+}
+
+func TestGetHistoricalRankOverviewFor_InvalidDateRange(t *testing.T) {
+	client := NewClient("testlogin", "testpass")
+	_, err := client.GetHistoricalRankOverviewFor(context.Background(), "example.com", 0, "", "2026-03-01", "2026-01-01")
+	assert.Error(t, err)
+}
+
+// ---------------------------------------------------------------------------
+// Async task tests
+// ---------------------------------------------------------------------------
+
+func TestPostTask_Created(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/serp/google/organic/task_post", r.URL.Path)
+
+		body, _ := io.ReadAll(r.Body)
+		var reqs []map[string]any
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 1)
+		assert.Equal(t, "seo tools", reqs[0]["keyword"])
+
+		resp := Response{
+			StatusCode: 20000,
+			Tasks:      []Task{{ID: "task-123", StatusCode: 20100, StatusMessage: "Task Created."}},
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	id, err := client.PostTask(context.Background(), "/serp/google/organic/task_post", map[string]any{"keyword": "seo tools"})
+	require.NoError(t, err)
+	assert.Equal(t, "task-123", id)
+}
+
+func TestGetTasksReady_Success(t *testing.T) {
+	result, _ := json.Marshal([]ReadyTask{
+		{ID: "a", Tag: "audit-1", EndpointRegular: "/v3/serp/google/organic/task_get/regular/a"},
+		{ID: "b", Tag: "audit-2"},
+	})
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/serp/google/organic/tasks_ready", r.URL.Path)
+		w.Write(wrapResponse(result))
+	})
+
+	ready, err := client.GetTasksReady(context.Background(), "/serp/google/organic")
+	require.NoError(t, err)
+	require.Len(t, ready, 2)
+	assert.Equal(t, "a", ready[0].ID)
+	assert.Equal(t, "audit-1", ready[0].Tag)
+	assert.Equal(t, "b", ready[1].ID)
+}
+
+func TestGetTaskResult_NotReady(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		resp := Response{
+			StatusCode: 20000,
+			Tasks:      []Task{{ID: "task-123", StatusCode: 40602, StatusMessage: "Task In Queue."}},
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	_, err := GetTaskResult[[]ReadyTask](context.Background(), client, "/serp/google/organic/task_get/regular", "task-123")
+	assert.ErrorIs(t, err, ErrTaskNotReady)
+}
+
+func TestWaitForTask_PollsUntilReady(t *testing.T) {
+	var calls int
+	result, _ := json.Marshal([]competitorDomainsResult{{Items: []CompetitorDomain{{Domain: "rival.com"}}}})
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/dataforseo_labs/task_get/task-123", r.URL.Path)
+		if calls < 3 {
+			w.Write(wrapErrorResponse(40400, "Not Found."))
+			return
+		}
+		w.Write(wrapResponse(result))
+	})
+	WithPollInterval(10 * time.Millisecond)(client)
+
+	var got []competitorDomainsResult
+	err := client.WaitForTask(context.Background(), "task-123", func(ctx context.Context, id string) error {
+		var err error
+		got, err = GetTaskResult[[]competitorDomainsResult](ctx, client, "/dataforseo_labs/task_get", id)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	require.Len(t, got, 1)
+	assert.Equal(t, "rival.com", got[0].Items[0].Domain)
+}
+
+func TestWaitForTask_ContextCancelled(t *testing.T) {
+	client := NewClient("testlogin", "testpass", WithPollInterval(10*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := client.WaitForTask(ctx, "task-123", func(ctx context.Context, id string) error {
+		return ErrTaskNotReady
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPostSERPOrganicTasks_PerTaskErrors(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/serp/google/organic/task_post", r.URL.Path)
+
+		var reqs []SERPOrganicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		require.Len(t, reqs, 2)
+		assert.Equal(t, "h5p quiz", reqs[0].Keyword)
+		assert.Equal(t, 2036, reqs[1].LocationCode)
+
+		resp := Response{
+			StatusCode: 20000,
+			Tasks: []Task{
+				{ID: "task-1", StatusCode: 20100, StatusMessage: "Task Created.", Cost: 0.0006},
+				{ID: "task-2", StatusCode: 40501, StatusMessage: "Invalid Field: 'keyword'."},
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	queued, err := client.PostSERPOrganicTasks(context.Background(), []SERPOrganicRequest{
+		{Keyword: "h5p quiz", LocationCode: 2036, LanguageCode: "en"},
+		{Keyword: "", LocationCode: 2036, LanguageCode: "en"},
+	})
+	require.NoError(t, err)
+	require.Len(t, queued, 2)
+	assert.Equal(t, "task-1", queued[0].ID)
+	assert.Equal(t, 0.0006, queued[0].Cost)
+	assert.NoError(t, queued[0].Err)
+	assert.Empty(t, queued[1].ID)
+	var taskErr *TaskError
+	assert.ErrorAs(t, queued[1].Err, &taskErr)
+}
+
+func TestPostTasks_TooMany(t *testing.T) {
+	client := NewClient("testlogin", "testpass")
+	_, err := client.PostTasks(context.Background(), "/serp/google/organic/task_post", make([]any, MaxTasksPerPost+1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the limit")
+}
+
+func TestGetSERPOrganicTask_FindDomain(t *testing.T) {
+	result, _ := json.Marshal([]SERPOrganicResult{{
+		Keyword:   "h5p quiz",
+		ItemTypes: []string{"featured_snippet", "organic", "people_also_ask"},
+		Items: []SERPResultItem{
+			{Type: "featured_snippet", RankAbsolute: 1, Domain: "www.example.com"},
+			{Type: "organic", RankGroup: 1, RankAbsolute: 2, Domain: "h5p.org"},
+			{Type: "organic", RankGroup: 2, RankAbsolute: 4, Domain: "learn.example.com", URL: "https://learn.example.com/quiz"},
+		},
+	}})
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/serp/google/organic/task_get/regular/task-1", r.URL.Path)
+		w.Write(wrapResponse(result))
+	})
+
+	serp, err := client.GetSERPOrganicTask(context.Background(), "task-1")
+	require.NoError(t, err)
+
+	found := serp.FindDomain("www.Example.com")
+	require.NotNil(t, found)
+	assert.Equal(t, 2, found.RankGroup)
+	assert.Equal(t, "https://learn.example.com/quiz", found.URL)
+	assert.Nil(t, serp.FindDomain("example.org"))
+}
+
+// ---------------------------------------------------------------------------
+// Locations and languages tests
+// ---------------------------------------------------------------------------
+
+func TestListLocations_CachesByCountry(t *testing.T) {
+	var calls int
+	result, _ := json.Marshal([]Location{
+		{LocationCode: 2036, LocationName: "Australia", CountryISOCode: "AU", LocationType: "Country"},
+		{LocationCode: 1000286, LocationName: "Sydney,New South Wales,Australia", CountryISOCode: "AU", LocationType: "City"},
+	})
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/serp/google/locations/au", r.URL.Path)
+		w.Write(wrapResponse(result))
+	})
+
+	ctx := context.Background()
+	locations, err := client.ListLocations(ctx, "AU")
+	require.NoError(t, err)
+	require.Len(t, locations, 2)
+	assert.Equal(t, 2036, locations[0].LocationCode)
+
+	_, err = client.ListLocations(ctx, "au")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestLocationCodeByCountry(t *testing.T) {
+	result, _ := json.Marshal([]Location{
+		{LocationCode: 2036, LocationName: "Australia", CountryISOCode: "AU", LocationType: "Country"},
+		{LocationCode: 2840, LocationName: "United States", CountryISOCode: "US", LocationType: "Country"},
+	})
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/serp/google/locations", r.URL.Path)
+		w.Write(wrapResponse(result))
+	})
+
+	ctx := context.Background()
+	code, err := client.LocationCodeByCountry(ctx, "australia")
+	require.NoError(t, err)
+	assert.Equal(t, 2036, code)
+
+	code, err = client.LocationCodeByCountry(ctx, "US")
+	require.NoError(t, err)
+	assert.Equal(t, 2840, code)
+
+	_, err = client.LocationCodeByCountry(ctx, "Atlantis")
+	assert.ErrorIs(t, err, ErrUnknownLocation)
+}
+
+func TestLanguageCodeByName(t *testing.T) {
+	var calls int
+	result, _ := json.Marshal([]Language{
+		{LanguageName: "English", LanguageCode: "en"},
+		{LanguageName: "German", LanguageCode: "de"},
+	})
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/serp/google/languages", r.URL.Path)
+		w.Write(wrapResponse(result))
+	})
+
+	ctx := context.Background()
+	code, err := client.LanguageCodeByName(ctx, "German")
+	require.NoError(t, err)
+	assert.Equal(t, "de", code)
+
+	code, err = client.LanguageCodeByName(ctx, "EN")
+	require.NoError(t, err)
+	assert.Equal(t, "en", code)
+
+	_, err = client.LanguageCodeByName(ctx, "Klingon")
+	assert.ErrorIs(t, err, ErrUnknownLanguage)
+	assert.Equal(t, 1, calls)
+}
+
+// ---------------------------------------------------------------------------
+// Cost tracking tests
+// ---------------------------------------------------------------------------
+
+func TestCostReport_TracksEndpointsAndAttribution(t *testing.T) {
+	result, _ := json.Marshal([]competitorDomainsResult{{}})
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(wrapResponse(result)) // envelope cost is 0.01
+	})
+
+	var events []CostEvent
+	WithCostHook(func(e CostEvent) { events = append(events, e) })(client)
+
+	ctx := WithCostAttribution(context.Background(), "org-1")
+	_, err := client.GetCompetitorDomains(ctx, "example.com", 2840, "en", LabsOptions{Limit: 10})
+	require.NoError(t, err)
+	_, err = client.GetCompetitorDomains(context.Background(), "example.com", 2840, "en", LabsOptions{Limit: 10})
+	require.NoError(t, err)
+
+	report := client.CostReport()
+	assert.InDelta(t, 0.02, report.Total, 1e-9)
+	assert.Equal(t, 2, report.Calls["/dataforseo_labs/google/competitors_domain/live"])
+	assert.InDelta(t, 0.02, report.ByEndpoint["/dataforseo_labs/google/competitors_domain/live"], 1e-9)
+	assert.InDelta(t, 0.01, report.ByAttribution["org-1"], 1e-9)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "org-1", events[0].Attribution)
+	assert.Equal(t, 0.01, events[0].Cost)
+}
+
+func TestWithBudget_StopsCallsWhenExceeded(t *testing.T) {
+	var calls int
+	result, _ := json.Marshal([]competitorDomainsResult{{}})
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write(wrapResponse(result))
+	})
+	WithBudget(0.015)(client)
+
+	ctx := context.Background()
+	_, err := client.GetCompetitorDomains(ctx, "example.com", 2840, "en", LabsOptions{Limit: 10})
+	require.NoError(t, err)
+	_, err = client.GetCompetitorDomains(ctx, "example.com", 2840, "en", LabsOptions{Limit: 10})
+	require.NoError(t, err) // 0.01 spent < 0.015, allowed
+	_, err = client.GetCompetitorDomains(ctx, "example.com", 2840, "en", LabsOptions{Limit: 10})
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Equal(t, 2, calls, "no request should be sent once over budget")
+}
+
+func TestWithContextBudget(t *testing.T) {
+	result, _ := json.Marshal([]competitorDomainsResult{{}})
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(wrapResponse(result))
+	})
+
+	ctx := WithContextBudget(context.Background(), 0.01)
+	_, err := client.GetCompetitorDomains(ctx, "example.com", 2840, "en", LabsOptions{Limit: 10})
+	require.NoError(t, err)
+	_, err = client.GetCompetitorDomains(ctx, "example.com", 2840, "en", LabsOptions{Limit: 10})
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+
+	// Other contexts are unaffected.
+	_, err = client.GetCompetitorDomains(context.Background(), "example.com", 2840, "en", LabsOptions{Limit: 10})
+	assert.NoError(t, err)
+}
+
+func TestEndpointKey_StripsTaskIDs(t *testing.T) {
+	assert.Equal(t, "/on_page/summary", endpointKey("/on_page/summary/07281559-0695-0216-0000-c0ba0ddb06d1"))
+	assert.Equal(t, "/keywords_data/google_ads/search_volume/live", endpointKey("/keywords_data/google_ads/search_volume/live"))
+}
+
 // ---------------------------------------------------------------------------
 // Error and edge-case tests
 // ---------------------------------------------------------------------------
@@ -875,6 +2234,117 @@ func TestTaskError_Status(t *testing.T) {
 	assert.Contains(t, err.Error(), "Insufficient credits")
 }
 
+func TestAPIError_InsufficientCredits(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(wrapErrorResponse(40210, "Insufficient funds"))
+	})
+
+	_, err := client.post(context.Background(), "/test", nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInsufficientCredits)
+	assert.False(t, IsRetryable(err))
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 40210, apiErr.Code)
+	assert.Equal(t, "Insufficient funds", apiErr.Message)
+	assert.Equal(t, "/test", apiErr.Endpoint)
+}
+
+func TestAPIError_HTTPStatus(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		w.Write([]byte(`payment required`))
+	})
+
+	_, err := client.post(context.Background(), "/test", nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusPaymentRequired, apiErr.HTTPStatus)
+	assert.ErrorIs(t, err, ErrInsufficientCredits)
+	assert.NotErrorIs(t, err, ErrRateLimited)
+}
+
+func TestTaskError_Categories(t *testing.T) {
+	resp := Response{
+		StatusCode: 20000,
+		Tasks: []Task{{
+			ID:            "task-invalid",
+			StatusCode:    40503,
+			StatusMessage: "POST Data Is Invalid",
+			Path:          []string{"v3", "on_page", "task_post"},
+		}},
+	}
+	b, _ := json.Marshal(resp)
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(b)
+	})
+
+	_, err := client.PostTask(context.Background(), "/on_page/task_post", map[string]string{"target": "example.com"})
+	assert.ErrorIs(t, err, ErrInvalidField)
+	assert.False(t, IsRetryable(err))
+
+	var taskErr *TaskError
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, "task-invalid", taskErr.TaskID)
+	assert.Equal(t, "v3/on_page/task_post", taskErr.Endpoint)
+
+	assert.True(t, IsRetryable(&TaskError{Code: 40202}))
+	assert.True(t, IsRetryable(&APIError{HTTPStatus: 503}))
+}
+
+func TestRateLimiter_SpacesRequestsAfterBurst(t *testing.T) {
+	l := newRateLimiter(50)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		require.NoError(t, l.wait(ctx))
+	}
+	assert.Less(t, time.Since(start), 20*time.Millisecond, "burst is not throttled")
+
+	require.NoError(t, l.wait(ctx))
+	require.NoError(t, l.wait(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestRateLimiter_PauseAndCancel(t *testing.T) {
+	l := newRateLimiter(100)
+	l.pause(time.Minute)
+	assert.Greater(t, l.reserve(), 59*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.wait(ctx), context.DeadlineExceeded)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	assert.Zero(t, parseRetryAfter(""))
+	assert.Zero(t, parseRetryAfter("soon"))
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	assert.InDelta(t, time.Minute.Seconds(), parseRetryAfter(date).Seconds(), 2)
+}
+
+func TestWithRateLimit_RetryAfterPausesClient(t *testing.T) {
+	var calls atomic.Int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write(wrapResponse(json.RawMessage(`[]`)))
+	})
+	WithRateLimit(100)(client)
+
+	start := time.Now()
+	_, err := client.post(context.Background(), "/test", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.GreaterOrEqual(t, time.Since(start), 3*time.Second, "retry waits for Retry-After, not the 2s backoff")
+}
+
 func TestContextCancellation(t *testing.T) {
 	// Server that blocks until context is cancelled
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
@@ -976,7 +2446,7 @@ func TestGetDomainRankingKeywords_EmptyResults(t *testing.T) {
 	})
 
 	ctx := context.Background()
-	keywords, total, err := client.GetDomainRankingKeywords(ctx, "example.com", 2840, "en", 10, 0)
+	keywords, total, err := client.GetDomainRankingKeywords(ctx, "example.com", 2840, "en", LabsOptions{Limit: 10})
 	require.NoError(t, err)
 	assert.Nil(t, keywords)
 	assert.Equal(t, 0, total)
@@ -1007,7 +2477,7 @@ func TestGetDomainRankingKeywords_NilKeywordData(t *testing.T) {
 	})
 
 	ctx := context.Background()
-	keywords, total, err := client.GetDomainRankingKeywords(ctx, "example.com", 2840, "en", 10, 0)
+	keywords, total, err := client.GetDomainRankingKeywords(ctx, "example.com", 2840, "en", LabsOptions{Limit: 10})
 	require.NoError(t, err)
 	assert.Equal(t, 1, total)
 	require.Len(t, keywords, 1)
@@ -0,0 +1,182 @@
+package dataforseo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExceeded is returned (wrapped) when a call would exceed the client's
+// monthly budget or the budget attached to the request context.
+var ErrBudgetExceeded = errors.New("dataforseo: budget exceeded")
+
+// WithBudget caps total spend (in USD, as reported by the API's cost field)
+// per calendar month (UTC). Calls fail with ErrBudgetExceeded once reached.
+func WithBudget(monthly float64) Option {
+	return func(c *Client) {
+		c.costs.monthlyBudget = monthly
+	}
+}
+
+// WithCostHook registers a callback invoked after every call with its cost.
+func WithCostHook(hook func(CostEvent)) Option {
+	return func(c *Client) {
+		c.costs.hook = hook
+	}
+}
+
+// CostEvent describes the cost of a single API call.
+type CostEvent struct {
+	Endpoint    string
+	Cost        float64
+	Attribution string // from WithCostAttribution, e.g. an organisation ID
+	Time        time.Time
+}
+
+// CostReport is a snapshot of the client's spend for the current month.
+type CostReport struct {
+	Month         string             `json:"month"` // yyyy-mm (UTC)
+	Total         float64            `json:"total"`
+	Budget        float64            `json:"budget"` // 0 = unlimited
+	Calls         map[string]int     `json:"calls"`
+	ByEndpoint    map[string]float64 `json:"byEndpoint"`
+	ByAttribution map[string]float64 `json:"byAttribution"`
+}
+
+type costContextKey int
+
+const (
+	attributionKey costContextKey = iota
+	contextBudgetKey
+)
+
+// WithCostAttribution tags ctx so the cost of calls made with it is attributed
+// to key (typically an organisation ID) in CostReport and CostEvent.
+func WithCostAttribution(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, attributionKey, key)
+}
+
+// WithContextBudget caps the total spend of all calls made with the returned
+// context, e.g. a single audit run.
+func WithContextBudget(ctx context.Context, limit float64) context.Context {
+	return context.WithValue(ctx, contextBudgetKey, &contextBudget{limit: limit})
+}
+
+type contextBudget struct {
+	mu    sync.Mutex
+	limit float64
+	spent float64
+}
+
+// CostReport returns a snapshot of this month's spend.
+func (c *Client) CostReport() CostReport {
+	t := c.costs
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover(time.Now())
+	return CostReport{
+		Month:         t.month,
+		Total:         t.total,
+		Budget:        t.monthlyBudget,
+		Calls:         maps.Clone(t.calls),
+		ByEndpoint:    maps.Clone(t.byEndpoint),
+		ByAttribution: maps.Clone(t.byAttribution),
+	}
+}
+
+// costTracker keeps running totals for the current calendar month.
+type costTracker struct {
+	mu            sync.Mutex
+	monthlyBudget float64
+	hook          func(CostEvent)
+
+	month         string
+	total         float64
+	calls         map[string]int
+	byEndpoint    map[string]float64
+	byAttribution map[string]float64
+}
+
+func newCostTracker() *costTracker {
+	t := &costTracker{}
+	t.rollover(time.Now())
+	return t
+}
+
+// rollover resets the totals when the month changes. Caller holds mu.
+func (t *costTracker) rollover(now time.Time) {
+	month := now.UTC().Format("2006-01")
+	if month == t.month {
+		return
+	}
+	t.month = month
+	t.total = 0
+	t.calls = make(map[string]int)
+	t.byEndpoint = make(map[string]float64)
+	t.byAttribution = make(map[string]float64)
+}
+
+func (t *costTracker) checkBudget(ctx context.Context) error {
+	if b, ok := ctx.Value(contextBudgetKey).(*contextBudget); ok {
+		b.mu.Lock()
+		spent, limit := b.spent, b.limit
+		b.mu.Unlock()
+		if spent >= limit {
+			return fmt.Errorf("%w: context spent $%.4f of $%.4f", ErrBudgetExceeded, spent, limit)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover(time.Now())
+	if t.monthlyBudget > 0 && t.total >= t.monthlyBudget {
+		return fmt.Errorf("%w: spent $%.4f of $%.4f in %s", ErrBudgetExceeded, t.total, t.monthlyBudget, t.month)
+	}
+	return nil
+}
+
+func (t *costTracker) record(ctx context.Context, path string, cost float64) {
+	now := time.Now()
+	endpoint := endpointKey(path)
+	attribution, _ := ctx.Value(attributionKey).(string)
+
+	if b, ok := ctx.Value(contextBudgetKey).(*contextBudget); ok {
+		b.mu.Lock()
+		b.spent += cost
+		b.mu.Unlock()
+	}
+
+	t.mu.Lock()
+	t.rollover(now)
+	t.total += cost
+	t.calls[endpoint]++
+	t.byEndpoint[endpoint] += cost
+	if attribution != "" {
+		t.byAttribution[attribution] += cost
+	}
+	hook := t.hook
+	t.mu.Unlock()
+
+	if hook != nil {
+		hook(CostEvent{Endpoint: endpoint, Cost: cost, Attribution: attribution, Time: now})
+	}
+}
+
+var taskIDSegment = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// endpointKey strips task IDs from a path so per-task GETs aggregate under one endpoint.
+func endpointKey(path string) string {
+	segments := strings.Split(path, "/")
+	kept := segments[:0]
+	for _, s := range segments {
+		if !taskIDSegment.MatchString(s) {
+			kept = append(kept, s)
+		}
+	}
+	return strings.Join(kept, "/")
+}
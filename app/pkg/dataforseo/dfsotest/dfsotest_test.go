@@ -0,0 +1,130 @@
+package dfsotest_test
+
+import (
+	"app/pkg/dataforseo"
+	"app/pkg/dataforseo/dfsotest"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// strictClient returns a client answered with body that fails the test on
+// any field of a fixture the client's types don't know, so the fixtures keep
+// up with the types.
+func strictClient(t *testing.T, body []byte) *dataforseo.Client {
+	t.Helper()
+	srv := httptest.NewServer(dfsotest.Handler(body))
+	t.Cleanup(srv.Close)
+	return dataforseo.NewClient("login", "pass",
+		dataforseo.WithBaseURL(srv.URL),
+		dataforseo.WithPollInterval(0),
+		dataforseo.WithStrictDecode(),
+		dataforseo.WithResponseHook(func(e dataforseo.ResponseEvent) {
+			assert.Empty(t, e.Warnings, "fixture has fields the client doesn't decode")
+		}),
+	)
+}
+
+func TestFixtures_DecodeStrictly(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run(dfsotest.FixtureSERPOrganic, func(t *testing.T) {
+		r, err := strictClient(t, dfsotest.FixtureResponse(dfsotest.FixtureSERPOrganic)).GetSERPOrganicTask(ctx, dfsotest.DefaultTaskID)
+		require.NoError(t, err)
+		item := r.FindDomain("example.com")
+		require.NotNil(t, item)
+		assert.Equal(t, 2, item.RankGroup)
+	})
+	t.Run(dfsotest.FixtureOnPageSummary, func(t *testing.T) {
+		s, err := strictClient(t, dfsotest.FixtureResponse(dfsotest.FixtureOnPageSummary)).GetOnPageSummary(ctx, "task")
+		require.NoError(t, err)
+		assert.Equal(t, "finished", s.CrawlProgress)
+		assert.Equal(t, 3, s.PageMetrics.TotalPages)
+	})
+	t.Run(dfsotest.FixtureOnPagePages, func(t *testing.T) {
+		pages, _, err := strictClient(t, dfsotest.FixtureResponse(dfsotest.FixtureOnPagePages)).GetOnPagePages(ctx, "task", 100, 0)
+		require.NoError(t, err)
+		require.Len(t, pages, 2)
+		assert.True(t, pages[1].Checks["no_h1_tag"])
+	})
+	t.Run(dfsotest.FixtureInstantPages, func(t *testing.T) {
+		page, err := strictClient(t, dfsotest.FixtureResponse(dfsotest.FixtureInstantPages)).GetInstantPage(ctx, dataforseo.InstantPagesRequest{URL: "https://example.com/pricing"})
+		require.NoError(t, err)
+		assert.Equal(t, "Pricing - Example", page.Meta.Title)
+	})
+	t.Run(dfsotest.FixtureSearchVolume, func(t *testing.T) {
+		kws, err := strictClient(t, dfsotest.FixtureResponse(dfsotest.FixtureSearchVolume)).GetSearchVolume(ctx, dataforseo.KeywordSearchVolumeRequest{Keywords: []string{"running shoes"}, LocationCode: 2036, LanguageCode: "en"})
+		require.NoError(t, err)
+		require.Len(t, kws, 2)
+		assert.Equal(t, 90500, *kws[0].SearchVolume)
+	})
+	t.Run(dfsotest.FixtureKeywordSuggestions, func(t *testing.T) {
+		kws, err := strictClient(t, dfsotest.FixtureResponse(dfsotest.FixtureKeywordSuggestions)).GetKeywordSuggestions(ctx, "running shoes", 2036, "en", dataforseo.LabsOptions{})
+		require.NoError(t, err)
+		assert.Len(t, kws, 2)
+	})
+	t.Run(dfsotest.FixtureRankedKeywords, func(t *testing.T) {
+		kws, total, err := strictClient(t, dfsotest.FixtureResponse(dfsotest.FixtureRankedKeywords)).GetDomainRankingKeywords(ctx, "example.com", 2036, "en", dataforseo.LabsOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 42, total)
+		require.Len(t, kws, 2)
+		assert.Equal(t, "running shoes", kws[0].Keyword)
+	})
+	t.Run(dfsotest.FixtureBacklinksSummary, func(t *testing.T) {
+		s, err := strictClient(t, dfsotest.FixtureResponse(dfsotest.FixtureBacklinksSummary)).GetBacklinksSummary(ctx, "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, 812, s.ReferringDomains)
+	})
+	t.Run(dfsotest.FixtureLocations, func(t *testing.T) {
+		code, err := strictClient(t, dfsotest.FixtureResponse(dfsotest.FixtureLocations)).LocationCodeByCountry(ctx, "AU")
+		require.NoError(t, err)
+		assert.Equal(t, 2036, code)
+	})
+	t.Run(dfsotest.FixtureTasksReady, func(t *testing.T) {
+		ready, err := strictClient(t, dfsotest.FixtureResponse(dfsotest.FixtureTasksReady)).GetTasksReady(ctx, "/serp/google/organic")
+		require.NoError(t, err)
+		require.Len(t, ready, 1)
+		assert.Equal(t, "rank-check", ready[0].Tag)
+	})
+}
+
+func TestTasksResponse_PartialFailure(t *testing.T) {
+	body := dfsotest.TasksResponse(
+		dfsotest.QueuedTask("task-1"),
+		dfsotest.TaskError("task-2", dfsotest.StatusInvalidData, "Invalid Field: 'keyword'."),
+	)
+	client := strictClient(t, body)
+
+	queued, err := client.PostSERPOrganicTasks(context.Background(), []dataforseo.SERPOrganicRequest{{}, {}})
+
+	require.NoError(t, err)
+	require.Len(t, queued, 2)
+	assert.Equal(t, "task-1", queued[0].ID)
+	var taskErr *dataforseo.TaskError
+	require.ErrorAs(t, queued[1].Err, &taskErr)
+	assert.Equal(t, dfsotest.StatusInvalidData, taskErr.Code)
+}
+
+func TestNotReady(t *testing.T) {
+	_, err := strictClient(t, dfsotest.NotReadyResponse()).GetOnPageSummary(context.Background(), "task")
+	assert.ErrorIs(t, err, dataforseo.ErrTaskNotReady)
+
+	_, err = strictClient(t, dfsotest.TasksResponse(dfsotest.NotReadyTask("task"))).GetSERPOrganicTask(context.Background(), "task")
+	assert.ErrorIs(t, err, dataforseo.ErrTaskNotReady)
+}
+
+func TestErrorResponse(t *testing.T) {
+	_, err := strictClient(t, dfsotest.ErrorResponse(40100, "Authorization failed.")).GetBacklinksSummary(context.Background(), "example.com")
+
+	var apiErr *dataforseo.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 40100, apiErr.Code)
+	assert.Equal(t, "Authorization failed.", apiErr.Message)
+}
+
+func TestFixture_Unknown(t *testing.T) {
+	assert.Panics(t, func() { dfsotest.Fixture("nope") })
+}
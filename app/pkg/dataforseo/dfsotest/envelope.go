@@ -0,0 +1,190 @@
+// Package dfsotest builds DataForSEO API responses for tests of code that
+// uses the dataforseo client: the response envelope in its success, error,
+// partial-failure and not-ready shapes, and stable result fixtures for each
+// endpoint family. Point a client at an httptest.Server with
+// dataforseo.WithBaseURL and answer with these.
+package dfsotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Status codes the API uses in envelopes and tasks.
+const (
+	StatusOK          = 20000
+	StatusTaskCreated = 20100
+	StatusNotFound    = 40400 // also what on_page/summary answers while a crawl starts
+	StatusTaskHanded  = 40601
+	StatusTaskInQueue = 40602
+	StatusInvalidData = 40501
+)
+
+// DefaultTaskID is the ID of the task in single-task responses.
+const DefaultTaskID = "01234567-0123-0123-0123-0123456789ab"
+
+// Task is one task of a response. A zero StatusCode means StatusOK.
+type Task struct {
+	ID            string
+	StatusCode    int
+	StatusMessage string
+	Cost          float64
+	Path          []string
+	Result        json.RawMessage // nil encodes as null, as the API sends for failed tasks
+}
+
+// The wire format, mirroring dataforseo.Response and dataforseo.Task; the
+// client's own tests use this package, so it can't import the client.
+type envelope struct {
+	Version       string         `json:"version"`
+	StatusCode    int            `json:"status_code"`
+	StatusMessage string         `json:"status_message"`
+	Time          string         `json:"time"`
+	Cost          float64        `json:"cost"`
+	TasksCount    int            `json:"tasks_count"`
+	TasksError    int            `json:"tasks_error"`
+	Tasks         []envelopeTask `json:"tasks"`
+}
+
+type envelopeTask struct {
+	ID            string          `json:"id"`
+	StatusCode    int             `json:"status_code"`
+	StatusMessage string          `json:"status_message"`
+	Time          string          `json:"time"`
+	Cost          float64         `json:"cost"`
+	ResultCount   int             `json:"result_count"`
+	Path          []string        `json:"path"`
+	Data          json.RawMessage `json:"data"`
+	Result        json.RawMessage `json:"result"`
+}
+
+// Response builds a successful response with one task holding result, the
+// shape every live endpoint answers with.
+func Response(result json.RawMessage) []byte {
+	return TasksResponse(Task{ID: DefaultTaskID, Cost: 0.01, Path: []string{"v3", "test"}, Result: result})
+}
+
+// TasksResponse builds a successful response holding tasks, in order. The
+// envelope's cost and error count are totalled from the tasks, so a mix of
+// OKTask and TaskError is a partially failed call.
+func TasksResponse(tasks ...Task) []byte {
+	env := envelope{
+		Version:       "0.1",
+		StatusCode:    StatusOK,
+		StatusMessage: "Ok.",
+		Time:          "0.05 sec.",
+		TasksCount:    len(tasks),
+		Tasks:         make([]envelopeTask, len(tasks)),
+	}
+	for i, t := range tasks {
+		if t.StatusCode == 0 {
+			t.StatusCode, t.StatusMessage = StatusOK, "Ok."
+		}
+		if t.StatusMessage == "" {
+			t.StatusMessage = statusMessage(t.StatusCode)
+		}
+		if t.Path == nil {
+			t.Path = []string{"v3", "test"}
+		}
+		resultCount := 0
+		if t.Result != nil {
+			var items []json.RawMessage
+			if json.Unmarshal(t.Result, &items) == nil {
+				resultCount = len(items)
+			}
+		}
+		if t.StatusCode >= 40000 {
+			env.TasksError++
+		}
+		env.Cost += t.Cost
+		env.Tasks[i] = envelopeTask{
+			ID:            t.ID,
+			StatusCode:    t.StatusCode,
+			StatusMessage: t.StatusMessage,
+			Time:          "0.04 sec.",
+			Cost:          t.Cost,
+			ResultCount:   resultCount,
+			Path:          t.Path,
+			Data:          json.RawMessage(`{}`),
+			Result:        t.Result,
+		}
+	}
+	return marshal(env)
+}
+
+// OKTask is a successful task with result.
+func OKTask(id string, result json.RawMessage) Task {
+	return Task{ID: id, Cost: 0.01, Result: result}
+}
+
+// QueuedTask is a task accepted by a task_post endpoint.
+func QueuedTask(id string) Task {
+	return Task{ID: id, StatusCode: StatusTaskCreated, StatusMessage: "Task Created.", Cost: 0.0006}
+}
+
+// TaskError is a task the API rejected or failed, e.g. for a bad keyword in
+// an otherwise good batch.
+func TaskError(id string, code int, msg string) Task {
+	return Task{ID: id, StatusCode: code, StatusMessage: msg}
+}
+
+// NotReadyTask is what task_get answers while the task is still queued.
+func NotReadyTask(id string) Task {
+	return Task{ID: id, StatusCode: StatusTaskInQueue, StatusMessage: "Task In Queue."}
+}
+
+// ErrorResponse builds a response whose envelope failed, with no tasks.
+func ErrorResponse(code int, msg string) []byte {
+	return marshal(envelope{
+		Version:       "0.1",
+		StatusCode:    code,
+		StatusMessage: msg,
+		Time:          "0.01 sec.",
+	})
+}
+
+// NotReadyResponse is what on_page/summary answers before the crawl has
+// started: a 40400 envelope.
+func NotReadyResponse() []byte {
+	return ErrorResponse(StatusNotFound, "Not Found.")
+}
+
+// EmptyTasksResponse builds a successful envelope with no tasks.
+func EmptyTasksResponse() []byte {
+	env := envelope{Version: "0.1", StatusCode: StatusOK, StatusMessage: "Ok.", Time: "0.01 sec.", Tasks: []envelopeTask{}}
+	return marshal(env)
+}
+
+// Handler answers every request with body as JSON.
+func Handler(body []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
+
+func statusMessage(code int) string {
+	switch code {
+	case StatusOK:
+		return "Ok."
+	case StatusTaskCreated:
+		return "Task Created."
+	case StatusNotFound:
+		return "Not Found."
+	case StatusTaskHanded:
+		return "Task Handed."
+	case StatusTaskInQueue:
+		return "Task In Queue."
+	default:
+		return fmt.Sprintf("Error %d.", code)
+	}
+}
+
+func marshal(env envelope) []byte {
+	b, err := json.Marshal(env)
+	if err != nil {
+		panic("dfsotest: " + err.Error())
+	}
+	return b
+}
@@ -0,0 +1,38 @@
+package dfsotest
+
+import (
+	"embed"
+	"encoding/json"
+)
+
+// Result fixtures, one per endpoint family, as the task's result array.
+// Their values are stable: tests may assert on them.
+const (
+	FixtureSERPOrganic        = "serp_organic"             // serp/google/organic/task_get/regular: example.com ranks 2nd for "running shoes"
+	FixtureOnPageSummary      = "on_page_summary"          // on_page/summary: finished crawl of example.com, 3 pages
+	FixtureOnPagePages        = "on_page_pages"            // on_page/pages: 2 of 3 pages, one missing its H1
+	FixtureInstantPages       = "on_page_instant_pages"    // on_page/instant_pages: https://example.com/pricing
+	FixtureSearchVolume       = "keywords_search_volume"   // keywords_data/google_ads/search_volume/live: 2 keywords
+	FixtureKeywordSuggestions = "labs_keyword_suggestions" // dataforseo_labs/google/keyword_suggestions/live: 2 for "running shoes"
+	FixtureRankedKeywords     = "labs_ranked_keywords"     // dataforseo_labs/google/ranked_keywords/live: example.com, 2 of 42
+	FixtureBacklinksSummary   = "backlinks_summary"        // backlinks/summary/live: example.com
+	FixtureLocations          = "serp_locations"           // serp/google/locations: Australia and Sydney
+	FixtureTasksReady         = "tasks_ready"              // tasks_ready: one SERP task
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Fixture returns a result fixture by name. It panics on an unknown name.
+func Fixture(name string) json.RawMessage {
+	b, err := fixtures.ReadFile("fixtures/" + name + ".json")
+	if err != nil {
+		panic("dfsotest: unknown fixture " + name)
+	}
+	return b
+}
+
+// FixtureResponse is Response with a fixture as the result.
+func FixtureResponse(name string) []byte {
+	return Response(Fixture(name))
+}
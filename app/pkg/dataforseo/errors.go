@@ -0,0 +1,107 @@
+package dataforseo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error categories, matched with errors.Is against *APIError and *TaskError
+// so callers can branch without knowing individual status codes.
+var (
+	// ErrInsufficientCredits means the account balance can't pay for the request.
+	// Retrying won't help; surface it as a billing warning.
+	ErrInsufficientCredits = errors.New("dataforseo: insufficient credits")
+	// ErrRateLimited means a per-minute or concurrency limit was hit. Retry later.
+	ErrRateLimited = errors.New("dataforseo: rate limited")
+	// ErrInvalidField means the request itself was rejected. Fail fast.
+	ErrInvalidField = errors.New("dataforseo: invalid field")
+)
+
+// statusCategories maps DataForSEO status codes to their error category.
+var statusCategories = map[int]error{
+	40200: ErrInsufficientCredits, // Payment Required
+	40210: ErrInsufficientCredits, // Insufficient funds
+	40501: ErrInsufficientCredits, // Insufficient credits
+	40202: ErrRateLimited,         // Rate limit per minute exceeded
+	40209: ErrRateLimited,         // Too many simultaneous requests
+	40503: ErrInvalidField,        // POST data is invalid
+}
+
+// httpCategories maps HTTP status codes to their error category.
+var httpCategories = map[int]error{
+	402: ErrInsufficientCredits,
+	429: ErrRateLimited,
+}
+
+// APIError is a failed request: either a non-20000 status in the response
+// envelope, or a non-200 HTTP response (HTTPStatus set, Code zero).
+type APIError struct {
+	Code       int    // envelope status_code
+	HTTPStatus int    // set when the request failed at the HTTP level
+	Message    string // status_message, or the HTTP response body
+	Endpoint   string // request path, e.g. "/on_page/summary/abc"
+}
+
+func (e *APIError) Error() string {
+	if e.HTTPStatus != 0 {
+		return fmt.Sprintf("dataforseo: HTTP %d: %s", e.HTTPStatus, e.Message)
+	}
+	return fmt.Sprintf("dataforseo: API error %d: %s", e.Code, e.Message)
+}
+
+// Is matches the error's category sentinel.
+func (e *APIError) Is(target error) bool {
+	if e.HTTPStatus != 0 {
+		return httpCategories[e.HTTPStatus] == target
+	}
+	return statusCategories[e.Code] == target
+}
+
+// TaskError is a task within a successful response that failed.
+type TaskError struct {
+	Code     int    // task status_code
+	Message  string // task status_message
+	Endpoint string // endpoint as reported by the API, e.g. "v3/on_page/task_post"
+	TaskID   string
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("dataforseo: task error %d: %s", e.Code, e.Message)
+}
+
+// Is matches the error's category sentinel.
+func (e *TaskError) Is(target error) bool {
+	return statusCategories[e.Code] == target
+}
+
+// IsRetryable reports whether err is transient: rate limiting or a server-side failure.
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatus >= 500 || apiErr.Code >= 50000
+	}
+	var taskErr *TaskError
+	if errors.As(err, &taskErr) {
+		return taskErr.Code >= 50000
+	}
+	return false
+}
+
+// newAPIError builds an APIError from a response envelope.
+func newAPIError(endpoint string, resp *Response) *APIError {
+	return &APIError{Code: resp.StatusCode, Message: resp.StatusMessage, Endpoint: endpoint}
+}
+
+// newTaskError builds a TaskError from a failed task.
+func newTaskError(task Task) *TaskError {
+	return &TaskError{
+		Code:     task.StatusCode,
+		Message:  task.StatusMessage,
+		Endpoint: strings.Join(task.Path, "/"),
+		TaskID:   task.ID,
+	}
+}
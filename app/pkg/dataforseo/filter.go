@@ -0,0 +1,209 @@
+package dataforseo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Filter operators supported by DataForSEO Labs filters.
+const (
+	OpLess      = "<"
+	OpLessEq    = "<="
+	OpGreater   = ">"
+	OpGreaterEq = ">="
+	OpEq        = "="
+	OpNotEq     = "<>"
+	OpIn        = "in"
+	OpNotIn     = "not_in"
+	OpLike      = "like"
+	OpNotLike   = "not_like"
+	OpILike     = "ilike"
+	OpNotILike  = "not_ilike"
+	OpMatch     = "match"
+	OpNotMatch  = "not_match"
+	OpRegex     = "regex"
+	OpNotRegex  = "not_regex"
+)
+
+// maxFilterConditions is the most conditions a Labs request accepts.
+const maxFilterConditions = 8
+
+// Filter is a type-safe DataForSEO filter expression. Build one with Where,
+// And and Or; it marshals to the API's nested array form, e.g.
+//
+//	And(Where("keyword_info.search_volume", OpGreater, 100), Where("keyword_properties.keyword_difficulty", OpLess, 40))
+//
+// becomes [["keyword_info.search_volume",">",100],"and",["keyword_properties.keyword_difficulty","<",40]].
+// The zero Filter matches everything and is omitted from requests.
+type Filter struct {
+	// Exactly one of cond or (logic, children) is set
+	cond     []any
+	logic    string
+	children []Filter
+}
+
+// Where returns a single condition comparing field with value using op.
+func Where(field, op string, value any) Filter {
+	return Filter{cond: []any{field, op, value}}
+}
+
+// And joins filters so all must match. Zero filters are skipped.
+func And(filters ...Filter) Filter {
+	return join("and", filters)
+}
+
+// Or joins filters so any may match. Zero filters are skipped.
+func Or(filters ...Filter) Filter {
+	return join("or", filters)
+}
+
+func join(logic string, filters []Filter) Filter {
+	var children []Filter
+	for _, f := range filters {
+		if !f.IsZero() {
+			children = append(children, f)
+		}
+	}
+	switch len(children) {
+	case 0:
+		return Filter{}
+	case 1:
+		return children[0]
+	}
+	return Filter{logic: logic, children: children}
+}
+
+// IsZero reports whether the filter has no conditions.
+func (f Filter) IsZero() bool {
+	return f.cond == nil && len(f.children) == 0
+}
+
+// Conditions returns the number of conditions in the expression.
+func (f Filter) Conditions() int {
+	if f.cond != nil {
+		return 1
+	}
+	n := 0
+	for _, c := range f.children {
+		n += c.Conditions()
+	}
+	return n
+}
+
+// expr returns the filter in the API's nested array form.
+func (f Filter) expr() []any {
+	if f.cond != nil {
+		return f.cond
+	}
+	out := make([]any, 0, 2*len(f.children)-1)
+	for i, c := range f.children {
+		if i > 0 {
+			out = append(out, f.logic)
+		}
+		out = append(out, c.expr())
+	}
+	return out
+}
+
+// MarshalJSON encodes the filter as DataForSEO's nested filter array.
+func (f Filter) MarshalJSON() ([]byte, error) {
+	if f.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(f.expr())
+}
+
+// Order is a single sorting rule for Labs results.
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+// Asc sorts by field in ascending order.
+func Asc(field string) Order {
+	return Order{Field: field}
+}
+
+// Desc sorts by field in descending order.
+func Desc(field string) Order {
+	return Order{Field: field, Desc: true}
+}
+
+// String returns the rule in the API's "field,asc" form.
+func (o Order) String() string {
+	if o.Desc {
+		return o.Field + ",desc"
+	}
+	return o.Field + ",asc"
+}
+
+// MarshalJSON encodes the rule as "field,asc" or "field,desc".
+func (o Order) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.String())
+}
+
+// maxOrderByRules is the most sorting rules a Labs request accepts.
+const maxOrderByRules = 3
+
+// LabsOptions narrows, sorts and pages Labs results server-side, so callers
+// don't page through thousands of rows to filter locally. Field paths are
+// relative to the endpoint's result items, e.g. "keyword_info.search_volume"
+// for keyword ideas or "keyword_data.keyword_info.search_volume" for ranked
+// and related keywords.
+type LabsOptions struct {
+	Limit   int
+	Offset  int
+	Filter  Filter
+	OrderBy []Order // up to 3 rules
+}
+
+func (o LabsOptions) validate() error {
+	return validateQuery(o.Filter, o.OrderBy)
+}
+
+// filters returns the filter expression for a request body, or nil when empty.
+func (o LabsOptions) filters() []any {
+	return o.Filter.body()
+}
+
+// orderBy returns the sorting rules for a request body, or nil when empty.
+func (o LabsOptions) orderBy() []string {
+	return orderRules(o.OrderBy)
+}
+
+// validateQuery checks a filter and sorting rules against the API's limits.
+func validateQuery(filter Filter, orderBy []Order) error {
+	if n := filter.Conditions(); n > maxFilterConditions {
+		return fmt.Errorf("dataforseo: at most %d filter conditions are allowed, got %d", maxFilterConditions, n)
+	}
+	if len(orderBy) > maxOrderByRules {
+		return fmt.Errorf("dataforseo: at most %d order_by rules are allowed", maxOrderByRules)
+	}
+	for _, rule := range orderBy {
+		if rule.Field == "" || strings.Contains(rule.Field, ",") {
+			return fmt.Errorf("dataforseo: invalid order_by field %q", rule.Field)
+		}
+	}
+	return nil
+}
+
+// body returns the filter expression for a request body, or nil when empty.
+func (f Filter) body() []any {
+	if f.IsZero() {
+		return nil
+	}
+	return f.expr()
+}
+
+// orderRules returns sorting rules for a request body, or nil when empty.
+func orderRules(orderBy []Order) []string {
+	if len(orderBy) == 0 {
+		return nil
+	}
+	rules := make([]string, len(orderBy))
+	for i, rule := range orderBy {
+		rules[i] = rule.String()
+	}
+	return rules
+}
@@ -44,19 +44,34 @@ type keywordSearchVolumeResult struct {
 	Items      []KeywordData `json:"items"`
 }
 
+// MaxSearchVolumeKeywords is the most keywords one search volume task takes.
+// The task is billed the same however many it holds.
+const MaxSearchVolumeKeywords = 1000
+
 // GetSearchVolume retrieves search volume data for a list of keywords.
 func (c *Client) GetSearchVolume(ctx context.Context, req KeywordSearchVolumeRequest) ([]KeywordData, error) {
+	items, _, err := c.GetSearchVolumeWithCost(ctx, req)
+	return items, err
+}
+
+// GetSearchVolumeWithCost is GetSearchVolume that also returns what the task
+// cost, for callers that share a batch between several parties and split
+// the bill themselves.
+func (c *Client) GetSearchVolumeWithCost(ctx context.Context, req KeywordSearchVolumeRequest) ([]KeywordData, float64, error) {
+	if len(req.Keywords) > MaxSearchVolumeKeywords {
+		return nil, 0, fmt.Errorf("dataforseo: %d keywords exceeds the limit of %d per task", len(req.Keywords), MaxSearchVolumeKeywords)
+	}
 	payload := []KeywordSearchVolumeRequest{req}
 	resp, err := c.post(ctx, "/keywords_data/google_ads/search_volume/live", payload)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	var results []keywordSearchVolumeResult
 	if err := c.firstResult(resp, &results); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if len(results) == 0 {
-		return nil, fmt.Errorf("dataforseo: empty search volume result")
+		return nil, 0, fmt.Errorf("dataforseo: empty search volume result")
 	}
-	return results[0].Items, nil
+	return results[0].Items, resp.Tasks[0].Cost, nil
 }
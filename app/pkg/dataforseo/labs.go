@@ -2,7 +2,11 @@ package dataforseo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"sync"
+	"time"
 )
 
 // KeywordInfo contains core keyword metrics from DataForSEO Labs.
@@ -67,10 +71,13 @@ type KeywordSuggestion struct {
 
 // keywordSuggestionsRequest is the request body for keyword suggestions.
 type keywordSuggestionsRequest struct {
-	Keyword      string `json:"keyword"`
-	LocationCode int    `json:"location_code"`
-	LanguageCode string `json:"language_code"`
-	Limit        int    `json:"limit,omitempty"`
+	Keyword      string   `json:"keyword"`
+	LocationCode int      `json:"location_code"`
+	LanguageCode string   `json:"language_code"`
+	Limit        int      `json:"limit,omitempty"`
+	Offset       int      `json:"offset,omitempty"`
+	Filters      []any    `json:"filters,omitempty"`
+	OrderBy      []string `json:"order_by,omitempty"`
 }
 
 // keywordSuggestionsResult wraps the paginated keyword suggestions response.
@@ -83,12 +90,18 @@ type keywordSuggestionsResult struct {
 }
 
 // GetKeywordSuggestions retrieves keyword suggestions based on a seed keyword.
-func (c *Client) GetKeywordSuggestions(ctx context.Context, keyword string, locationCode int, languageCode string, limit int) ([]KeywordSuggestion, error) {
+func (c *Client) GetKeywordSuggestions(ctx context.Context, keyword string, locationCode int, languageCode string, opts LabsOptions) ([]KeywordSuggestion, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
 	payload := []keywordSuggestionsRequest{{
 		Keyword:      keyword,
 		LocationCode: locationCode,
 		LanguageCode: languageCode,
-		Limit:        limit,
+		Limit:        opts.Limit,
+		Offset:       opts.Offset,
+		Filters:      opts.filters(),
+		OrderBy:      opts.orderBy(),
 	}}
 	resp, err := c.post(ctx, "/dataforseo_labs/google/keyword_suggestions/live", payload)
 	if err != nil {
@@ -104,6 +117,152 @@ func (c *Client) GetKeywordSuggestions(ctx context.Context, keyword string, loca
 	return results[0].Items, nil
 }
 
+// keywordIdeasRequest is the request body for keyword ideas.
+type keywordIdeasRequest struct {
+	Keywords     []string `json:"keywords"`
+	LocationCode int      `json:"location_code"`
+	LanguageCode string   `json:"language_code"`
+	Limit        int      `json:"limit,omitempty"`
+	Offset       int      `json:"offset,omitempty"`
+	Filters      []any    `json:"filters,omitempty"`
+	OrderBy      []string `json:"order_by,omitempty"`
+}
+
+// keywordIdeasResult wraps the paginated keyword ideas response.
+type keywordIdeasResult struct {
+	SEType     string              `json:"se_type"`
+	Seeds      []string            `json:"seed_keywords"`
+	TotalCount int                 `json:"total_count"`
+	ItemsCount int                 `json:"items_count"`
+	Items      []KeywordSuggestion `json:"items"`
+}
+
+// GetKeywordIdeas retrieves keywords from the same product/service categories
+// as the seed keywords. Unlike suggestions, ideas need not contain the seed.
+func (c *Client) GetKeywordIdeas(ctx context.Context, keywords []string, locationCode int, languageCode string, opts LabsOptions) ([]KeywordSuggestion, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	payload := []keywordIdeasRequest{{
+		Keywords:     keywords,
+		LocationCode: locationCode,
+		LanguageCode: languageCode,
+		Limit:        opts.Limit,
+		Offset:       opts.Offset,
+		Filters:      opts.filters(),
+		OrderBy:      opts.orderBy(),
+	}}
+	resp, err := c.post(ctx, "/dataforseo_labs/google/keyword_ideas/live", payload)
+	if err != nil {
+		return nil, err
+	}
+	var results []keywordIdeasResult
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("dataforseo: empty keyword ideas result")
+	}
+	return results[0].Items, nil
+}
+
+// RelatedKeyword represents a keyword from the "searches related to" graph.
+// This is a flattened view assembled from the nested API response.
+type RelatedKeyword struct {
+	SEType            string            `json:"se_type"`
+	Seed              string            `json:"seed_keyword"`
+	Keyword           string            `json:"keyword"`
+	Depth             int               `json:"depth"`
+	RelatedKeywords   []string          `json:"related_keywords"`
+	KeywordInfo       KeywordInfo       `json:"keyword_info"`
+	KeywordProperties KeywordProperties `json:"keyword_properties"`
+	SearchIntentInfo  *SearchIntentInfo `json:"search_intent_info"`
+}
+
+// relatedKeywordsRequest is the request body for related keywords.
+type relatedKeywordsRequest struct {
+	Keyword      string   `json:"keyword"`
+	LocationCode int      `json:"location_code"`
+	LanguageCode string   `json:"language_code"`
+	Depth        int      `json:"depth"`
+	Limit        int      `json:"limit,omitempty"`
+	Offset       int      `json:"offset,omitempty"`
+	Filters      []any    `json:"filters,omitempty"`
+	OrderBy      []string `json:"order_by,omitempty"`
+}
+
+// relatedKeywordsResult wraps the related keywords response.
+type relatedKeywordsResult struct {
+	SEType     string               `json:"se_type"`
+	Seed       string               `json:"seed_keyword"`
+	TotalCount int                  `json:"total_count"`
+	ItemsCount int                  `json:"items_count"`
+	Items      []relatedKeywordItem `json:"items"`
+}
+
+// relatedKeywordItem is the raw item from the related_keywords endpoint.
+type relatedKeywordItem struct {
+	SEType          string             `json:"se_type"`
+	KeywordData     *keywordDataNested `json:"keyword_data"`
+	Depth           int                `json:"depth"`
+	RelatedKeywords []string           `json:"related_keywords"`
+}
+
+// maxRelatedKeywordsDepth is the deepest level the related_keywords endpoint supports.
+const maxRelatedKeywordsDepth = 4
+
+// GetRelatedKeywords walks the "searches related to" graph from a seed keyword
+// up to depth levels (0-4). Depth 0 returns only the seed itself. Filter and
+// order fields are relative to the raw items, e.g. "keyword_data.keyword_info.search_volume".
+func (c *Client) GetRelatedKeywords(ctx context.Context, keyword string, locationCode int, languageCode string, depth int, opts LabsOptions) ([]RelatedKeyword, error) {
+	if depth < 0 || depth > maxRelatedKeywordsDepth {
+		return nil, fmt.Errorf("dataforseo: related keywords depth must be between 0 and %d", maxRelatedKeywordsDepth)
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	payload := []relatedKeywordsRequest{{
+		Keyword:      keyword,
+		LocationCode: locationCode,
+		LanguageCode: languageCode,
+		Depth:        depth,
+		Limit:        opts.Limit,
+		Offset:       opts.Offset,
+		Filters:      opts.filters(),
+		OrderBy:      opts.orderBy(),
+	}}
+	resp, err := c.post(ctx, "/dataforseo_labs/google/related_keywords/live", payload)
+	if err != nil {
+		return nil, err
+	}
+	var results []relatedKeywordsResult
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("dataforseo: empty related keywords result")
+	}
+
+	items := results[0].Items
+	related := make([]RelatedKeyword, 0, len(items))
+	for _, item := range items {
+		rk := RelatedKeyword{
+			SEType:          item.SEType,
+			Seed:            results[0].Seed,
+			Depth:           item.Depth,
+			RelatedKeywords: item.RelatedKeywords,
+		}
+		if item.KeywordData != nil {
+			rk.Keyword = item.KeywordData.Keyword
+			rk.KeywordInfo = item.KeywordData.KeywordInfo
+			rk.KeywordProperties = item.KeywordData.KeywordProperties
+			rk.SearchIntentInfo = item.KeywordData.SearchIntentInfo
+		}
+		related = append(related, rk)
+	}
+	return related, nil
+}
+
 // DomainKeyword represents a keyword that a domain ranks for.
 // This is a flattened view assembled from the nested API response.
 type DomainKeyword struct {
@@ -141,11 +300,13 @@ type SERPItem struct {
 
 // domainRankingKeywordsRequest is the request body for ranked keywords.
 type domainRankingKeywordsRequest struct {
-	Target       string `json:"target"`
-	LocationCode int    `json:"location_code"`
-	LanguageCode string `json:"language_code"`
-	Limit        int    `json:"limit,omitempty"`
-	Offset       int    `json:"offset,omitempty"`
+	Target       string   `json:"target"`
+	LocationCode int      `json:"location_code"`
+	LanguageCode string   `json:"language_code"`
+	Limit        int      `json:"limit,omitempty"`
+	Offset       int      `json:"offset,omitempty"`
+	Filters      []any    `json:"filters,omitempty"`
+	OrderBy      []string `json:"order_by,omitempty"`
 }
 
 // domainRankingKeywordsResult wraps the ranked keywords response.
@@ -175,20 +336,73 @@ type keywordDataNested struct {
 	SERPInfo          *SERPInfo         `json:"serp_info"`
 }
 
-// GetDomainRankingKeywords retrieves keywords a domain ranks for.
+// GetDomainRankingKeywords retrieves keywords a domain ranks for. Filter and
+// order fields are relative to the raw items, e.g. "ranked_serp_element.serp_item.etv".
 // Returns the keywords, total count, and any error.
-func (c *Client) GetDomainRankingKeywords(ctx context.Context, target string, locationCode int, languageCode string, limit, offset int) ([]DomainKeyword, int, error) {
+func (c *Client) GetDomainRankingKeywords(ctx context.Context, target string, locationCode int, languageCode string, opts LabsOptions) ([]DomainKeyword, int, error) {
+	target, err := NormalizeTarget(target, TargetDomain)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := opts.validate(); err != nil {
+		return nil, 0, err
+	}
 	payload := []domainRankingKeywordsRequest{{
 		Target:       target,
 		LocationCode: locationCode,
 		LanguageCode: languageCode,
-		Limit:        limit,
-		Offset:       offset,
+		Limit:        opts.Limit,
+		Offset:       opts.Offset,
+		Filters:      opts.filters(),
+		OrderBy:      opts.orderBy(),
+	}}
+	resp, err := c.post(ctx, "/dataforseo_labs/google/ranked_keywords/live", payload)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.decodeRankingKeywords(resp)
+}
+
+// GetPageRankingKeywords retrieves keywords a single page ranks for.
+// The ranked_keywords endpoint only accepts a domain as target, so the page is
+// matched by filtering on the SERP item's relative URL (path + query), combined
+// with any filter in opts.
+func (c *Client) GetPageRankingKeywords(ctx context.Context, pageURL string, locationCode int, languageCode string, opts LabsOptions) ([]DomainKeyword, int, error) {
+	normalized, err := NormalizeTarget(pageURL, TargetURL)
+	if err != nil {
+		return nil, 0, err
+	}
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return nil, 0, fmt.Errorf("dataforseo: invalid page URL %q", pageURL)
+	}
+	domain, err := NormalizeTarget(u.Hostname(), TargetDomain)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts.Filter = And(Where("ranked_serp_element.serp_item.relative_url", OpEq, u.RequestURI()), opts.Filter)
+	if err := opts.validate(); err != nil {
+		return nil, 0, err
+	}
+	payload := []domainRankingKeywordsRequest{{
+		Target:       domain,
+		LocationCode: locationCode,
+		LanguageCode: languageCode,
+		Limit:        opts.Limit,
+		Offset:       opts.Offset,
+		Filters:      opts.filters(),
+		OrderBy:      opts.orderBy(),
 	}}
 	resp, err := c.post(ctx, "/dataforseo_labs/google/ranked_keywords/live", payload)
 	if err != nil {
 		return nil, 0, err
 	}
+	return c.decodeRankingKeywords(resp)
+}
+
+// decodeRankingKeywords flattens a ranked_keywords response into DomainKeywords.
+// Returns the keywords, total count, and any error.
+func (c *Client) decodeRankingKeywords(resp *Response) ([]DomainKeyword, int, error) {
 	var results []domainRankingKeywordsResult
 	if err := c.firstResult(resp, &results); err != nil {
 		return nil, 0, err
@@ -251,10 +465,13 @@ type PositionMetrics struct {
 
 // competitorDomainsRequest is the request body for competitors_domain.
 type competitorDomainsRequest struct {
-	Target       string `json:"target"`
-	LocationCode int    `json:"location_code"`
-	LanguageCode string `json:"language_code"`
-	Limit        int    `json:"limit,omitempty"`
+	Target       string   `json:"target"`
+	LocationCode int      `json:"location_code"`
+	LanguageCode string   `json:"language_code"`
+	Limit        int      `json:"limit,omitempty"`
+	Offset       int      `json:"offset,omitempty"`
+	Filters      []any    `json:"filters,omitempty"`
+	OrderBy      []string `json:"order_by,omitempty"`
 }
 
 // competitorDomainsResult wraps the competitors domain response.
@@ -265,13 +482,24 @@ type competitorDomainsResult struct {
 	Items      []CompetitorDomain `json:"items"`
 }
 
-// GetCompetitorDomains discovers competitor domains for a target.
-func (c *Client) GetCompetitorDomains(ctx context.Context, target string, locationCode int, languageCode string, limit int) ([]CompetitorDomain, error) {
+// GetCompetitorDomains discovers competitor domains for a target. Filter and
+// order fields are relative to the items, e.g. "intersections" or "metrics.organic.etv".
+func (c *Client) GetCompetitorDomains(ctx context.Context, target string, locationCode int, languageCode string, opts LabsOptions) ([]CompetitorDomain, error) {
+	target, err := NormalizeTarget(target, TargetDomain)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
 	payload := []competitorDomainsRequest{{
 		Target:       target,
 		LocationCode: locationCode,
 		LanguageCode: languageCode,
-		Limit:        limit,
+		Limit:        opts.Limit,
+		Offset:       opts.Offset,
+		Filters:      opts.filters(),
+		OrderBy:      opts.orderBy(),
 	}}
 	resp, err := c.post(ctx, "/dataforseo_labs/google/competitors_domain/live", payload)
 	if err != nil {
@@ -307,11 +535,16 @@ type KeywordGapData struct {
 
 // domainIntersectionRequest is the request body for domain_intersection.
 type domainIntersectionRequest struct {
-	Target1      string `json:"target1"`
-	Target2      string `json:"target2"`
-	LocationCode int    `json:"location_code"`
-	LanguageCode string `json:"language_code"`
-	Limit        int    `json:"limit,omitempty"`
+	Target1      string   `json:"target1"`
+	Target2      string   `json:"target2"`
+	LocationCode int      `json:"location_code"`
+	LanguageCode string   `json:"language_code"`
+	Limit        int      `json:"limit,omitempty"`
+	Offset       int      `json:"offset,omitempty"`
+	Filters      []any    `json:"filters,omitempty"`
+	OrderBy      []string `json:"order_by,omitempty"`
+	// false returns the keywords only target1 ranks for; nil means true
+	Intersections *bool `json:"intersections,omitempty"`
 }
 
 // domainIntersectionResult wraps the domain intersection response.
@@ -322,14 +555,42 @@ type domainIntersectionResult struct {
 	Items      []KeywordGap `json:"items"`
 }
 
-// GetKeywordGaps finds keywords where two domains both rank.
-func (c *Client) GetKeywordGaps(ctx context.Context, target1, target2 string, locationCode int, languageCode string, limit int) ([]KeywordGap, error) {
+// GetKeywordGaps finds keywords where two domains both rank. Filter and order
+// fields are relative to the items, e.g. "first_domain_serp_element.etv".
+func (c *Client) GetKeywordGaps(ctx context.Context, target1, target2 string, locationCode int, languageCode string, opts LabsOptions) ([]KeywordGap, error) {
+	return c.domainIntersection(ctx, target1, target2, locationCode, languageCode, opts, nil)
+}
+
+// GetMissingKeywords finds keywords a competitor ranks for and target
+// doesn't. Each item's FirstDomainSERPElement is the competitor's; the second
+// is nil. Filter and order fields are as for GetKeywordGaps.
+func (c *Client) GetMissingKeywords(ctx context.Context, target, competitor string, locationCode int, languageCode string, opts LabsOptions) ([]KeywordGap, error) {
+	intersections := false
+	return c.domainIntersection(ctx, competitor, target, locationCode, languageCode, opts, &intersections)
+}
+
+func (c *Client) domainIntersection(ctx context.Context, target1, target2 string, locationCode int, languageCode string, opts LabsOptions, intersections *bool) ([]KeywordGap, error) {
+	target1, err := NormalizeTarget(target1, TargetDomain)
+	if err != nil {
+		return nil, err
+	}
+	target2, err = NormalizeTarget(target2, TargetDomain)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
 	payload := []domainIntersectionRequest{{
-		Target1:      target1,
-		Target2:      target2,
-		LocationCode: locationCode,
-		LanguageCode: languageCode,
-		Limit:        limit,
+		Target1:       target1,
+		Target2:       target2,
+		LocationCode:  locationCode,
+		LanguageCode:  languageCode,
+		Limit:         opts.Limit,
+		Offset:        opts.Offset,
+		Filters:       opts.filters(),
+		OrderBy:       opts.orderBy(),
+		Intersections: intersections,
 	}}
 	resp, err := c.post(ctx, "/dataforseo_labs/google/domain_intersection/live", payload)
 	if err != nil {
@@ -344,3 +605,219 @@ func (c *Client) GetKeywordGaps(ctx context.Context, target1, target2 string, lo
 	}
 	return results[0].Items, nil
 }
+
+// RankOverviewMetrics contains organic and paid ranking metrics for a domain.
+type RankOverviewMetrics struct {
+	Organic *PositionMetrics `json:"organic"`
+	Paid    *PositionMetrics `json:"paid"`
+}
+
+// DomainRankOverview contains current traffic and visibility metrics for a domain.
+type DomainRankOverview struct {
+	SEType       string              `json:"se_type"`
+	LocationCode int                 `json:"location_code"`
+	LanguageCode string              `json:"language_code"`
+	Metrics      RankOverviewMetrics `json:"metrics"`
+}
+
+// HistoricalRankPoint contains a domain's ranking metrics for a single month.
+type HistoricalRankPoint struct {
+	SEType  string              `json:"se_type"`
+	Year    int                 `json:"year"`
+	Month   int                 `json:"month"`
+	Metrics RankOverviewMetrics `json:"metrics"`
+}
+
+// Date returns the first day of the point's month in UTC.
+func (p HistoricalRankPoint) Date() time.Time {
+	return time.Date(p.Year, time.Month(p.Month), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// domainRankOverviewRequest is the request body for domain_rank_overview.
+type domainRankOverviewRequest struct {
+	Target       string `json:"target"`
+	LocationCode int    `json:"location_code,omitempty"`
+	LanguageCode string `json:"language_code,omitempty"`
+}
+
+// domainRankOverviewResult wraps the domain rank overview response.
+type domainRankOverviewResult struct {
+	SEType     string               `json:"se_type"`
+	Target     string               `json:"target"`
+	TotalCount int                  `json:"total_count"`
+	ItemsCount int                  `json:"items_count"`
+	Items      []DomainRankOverview `json:"items"`
+}
+
+// GetDomainRankOverview retrieves estimated organic/paid traffic (ETV) and keyword
+// counts by position bucket for a domain. Returns nil if the domain has no data.
+func (c *Client) GetDomainRankOverview(ctx context.Context, target string, locationCode int, languageCode string) (*DomainRankOverview, error) {
+	target, err := NormalizeTarget(target, TargetDomain)
+	if err != nil {
+		return nil, err
+	}
+	payload := []domainRankOverviewRequest{{
+		Target:       target,
+		LocationCode: locationCode,
+		LanguageCode: languageCode,
+	}}
+	resp, err := c.post(ctx, "/dataforseo_labs/google/domain_rank_overview/live", payload)
+	if err != nil {
+		return nil, err
+	}
+	var results []domainRankOverviewResult
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("dataforseo: empty domain overview result")
+	}
+	if len(results[0].Items) == 0 {
+		return nil, nil
+	}
+	return &results[0].Items[0], nil
+}
+
+// DomainOverview is the rank overview GetDomainOverview returns.
+type DomainOverview = DomainRankOverview
+
+// GetDomainOverview retrieves a domain's current traffic and visibility
+// metrics, as GetDomainRankOverview does.
+func (c *Client) GetDomainOverview(ctx context.Context, target string, locationCode int, languageCode string) (*DomainOverview, error) {
+	return c.GetDomainRankOverview(ctx, target, locationCode, languageCode)
+}
+
+// historicalRankOverviewRequest is the request body for historical_rank_overview.
+type historicalRankOverviewRequest struct {
+	Target       string `json:"target"`
+	LocationCode int    `json:"location_code,omitempty"`
+	LanguageCode string `json:"language_code,omitempty"`
+	DateFrom     string `json:"date_from,omitempty"`
+	DateTo       string `json:"date_to,omitempty"`
+}
+
+// historicalRankOverviewResult wraps the historical rank overview response.
+type historicalRankOverviewResult struct {
+	SEType     string                `json:"se_type"`
+	Target     string                `json:"target"`
+	TotalCount int                   `json:"total_count"`
+	ItemsCount int                   `json:"items_count"`
+	Items      []HistoricalRankPoint `json:"items"`
+}
+
+// GetHistoricalRankOverview retrieves monthly ranking metrics for a domain
+// since dateFrom (yyyy-mm-dd), across all locations and languages.
+func (c *Client) GetHistoricalRankOverview(ctx context.Context, target, dateFrom string) ([]HistoricalRankPoint, error) {
+	return c.GetHistoricalRankOverviewFor(ctx, target, 0, "", dateFrom, "")
+}
+
+// GetHistoricalRankOverviewFor retrieves monthly organic and paid ranking
+// metrics for a domain in one location and language between dateFrom and
+// dateTo (yyyy-mm-dd, either may be empty). A zero locationCode and empty
+// languageCode aggregate all locations and languages.
+func (c *Client) GetHistoricalRankOverviewFor(ctx context.Context, target string, locationCode int, languageCode, dateFrom, dateTo string) ([]HistoricalRankPoint, error) {
+	target, err := NormalizeTarget(target, TargetDomain)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDateRange(dateFrom, dateTo); err != nil {
+		return nil, err
+	}
+	payload := []historicalRankOverviewRequest{{
+		Target:       target,
+		LocationCode: locationCode,
+		LanguageCode: languageCode,
+		DateFrom:     dateFrom,
+		DateTo:       dateTo,
+	}}
+	resp, err := c.post(ctx, "/dataforseo_labs/google/historical_rank_overview/live", payload)
+	if err != nil {
+		return nil, err
+	}
+	var results []historicalRankOverviewResult
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("dataforseo: empty historical rank overview result")
+	}
+	return results[0].Items, nil
+}
+
+// maxBulkKeywordDifficulty is the most keywords bulk_keyword_difficulty accepts per task.
+const maxBulkKeywordDifficulty = 1000
+
+// KeywordDifficulty is the ranking difficulty (0-100) of a keyword.
+// Difficulty is nil when DataForSEO has no data for the keyword.
+type KeywordDifficulty struct {
+	Keyword    string `json:"keyword"`
+	Difficulty *int   `json:"keyword_difficulty"`
+}
+
+// bulkKeywordDifficultyRequest is the request body for bulk_keyword_difficulty.
+type bulkKeywordDifficultyRequest struct {
+	Keywords     []string `json:"keywords"`
+	LocationCode int      `json:"location_code"`
+	LanguageCode string   `json:"language_code"`
+}
+
+// bulkKeywordDifficultyResult wraps the bulk keyword difficulty response.
+type bulkKeywordDifficultyResult struct {
+	SEType     string              `json:"se_type"`
+	TotalCount int                 `json:"total_count"`
+	ItemsCount int                 `json:"items_count"`
+	Items      []KeywordDifficulty `json:"items"`
+}
+
+// GetBulkKeywordDifficulty retrieves keyword difficulty for any number of keywords.
+// Lists over 1000 keywords are split into chunks that are requested concurrently
+// (bounded by WithMaxConcurrent); results keep the order of the chunks.
+func (c *Client) GetBulkKeywordDifficulty(ctx context.Context, keywords []string, locationCode int, languageCode string) ([]KeywordDifficulty, error) {
+	var chunks [][]string
+	for start := 0; start < len(keywords); start += maxBulkKeywordDifficulty {
+		end := min(start+maxBulkKeywordDifficulty, len(keywords))
+		chunks = append(chunks, keywords[start:end])
+	}
+
+	results := make([][]KeywordDifficulty, len(chunks))
+	errs := make([]error, len(chunks))
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = c.getKeywordDifficultyChunk(ctx, chunk, locationCode, languageCode)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	difficulties := make([]KeywordDifficulty, 0, len(keywords))
+	for _, r := range results {
+		difficulties = append(difficulties, r...)
+	}
+	return difficulties, nil
+}
+
+// getKeywordDifficultyChunk requests difficulty for at most maxBulkKeywordDifficulty keywords.
+func (c *Client) getKeywordDifficultyChunk(ctx context.Context, keywords []string, locationCode int, languageCode string) ([]KeywordDifficulty, error) {
+	payload := []bulkKeywordDifficultyRequest{{
+		Keywords:     keywords,
+		LocationCode: locationCode,
+		LanguageCode: languageCode,
+	}}
+	resp, err := c.post(ctx, "/dataforseo_labs/google/bulk_keyword_difficulty/live", payload)
+	if err != nil {
+		return nil, err
+	}
+	var results []bulkKeywordDifficultyResult
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("dataforseo: empty keyword difficulty result")
+	}
+	return results[0].Items, nil
+}
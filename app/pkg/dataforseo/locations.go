@@ -0,0 +1,152 @@
+package dataforseo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lookupCacheTTL is how long location and language lists are kept in memory.
+// DataForSEO updates them rarely, and the full location list is large.
+const lookupCacheTTL = 24 * time.Hour
+
+// ErrUnknownLocation is returned (wrapped) when a country name or code has no location.
+var ErrUnknownLocation = errors.New("dataforseo: unknown location")
+
+// ErrUnknownLanguage is returned (wrapped) when a language name or code has no match.
+var ErrUnknownLanguage = errors.New("dataforseo: unknown language")
+
+// Location is a location usable as location_code in SERP, Keywords and Labs requests.
+type Location struct {
+	LocationCode       int    `json:"location_code"`
+	LocationName       string `json:"location_name"`
+	LocationCodeParent *int   `json:"location_code_parent"`
+	CountryISOCode     string `json:"country_iso_code"`
+	LocationType       string `json:"location_type"` // "Country", "State", "City", ...
+}
+
+// Language is a language usable as language_code in requests.
+type Language struct {
+	LanguageName string `json:"language_name"`
+	LanguageCode string `json:"language_code"`
+}
+
+// lookupCache holds location and language lists, keyed by country for locations.
+type lookupCache struct {
+	mu        sync.Mutex
+	locations map[string]cachedLookup[[]Location]
+	languages cachedLookup[[]Language]
+}
+
+type cachedLookup[T any] struct {
+	value   T
+	expires time.Time
+}
+
+func newLookupCache() *lookupCache {
+	return &lookupCache{locations: make(map[string]cachedLookup[[]Location])}
+}
+
+// ListLocations returns the locations within a country, given as an ISO 3166-1
+// alpha-2 code such as "AU", or every location when country is empty.
+// Results are cached in memory for a day.
+func (c *Client) ListLocations(ctx context.Context, country string) ([]Location, error) {
+	key := strings.ToLower(strings.TrimSpace(country))
+
+	c.lookups.mu.Lock()
+	cached, ok := c.lookups.locations[key]
+	c.lookups.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	path := "/serp/google/locations"
+	if key != "" {
+		path += "/" + key
+	}
+	var locations []Location
+	if err := c.getLookup(ctx, path, &locations); err != nil {
+		return nil, err
+	}
+
+	c.lookups.mu.Lock()
+	c.lookups.locations[key] = cachedLookup[[]Location]{value: locations, expires: time.Now().Add(lookupCacheTTL)}
+	c.lookups.mu.Unlock()
+	return locations, nil
+}
+
+// ListLanguages returns every supported language. Results are cached in memory for a day.
+func (c *Client) ListLanguages(ctx context.Context) ([]Language, error) {
+	c.lookups.mu.Lock()
+	cached := c.lookups.languages
+	c.lookups.mu.Unlock()
+	if cached.value != nil && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	var languages []Language
+	if err := c.getLookup(ctx, "/serp/google/languages", &languages); err != nil {
+		return nil, err
+	}
+
+	c.lookups.mu.Lock()
+	c.lookups.languages = cachedLookup[[]Language]{value: languages, expires: time.Now().Add(lookupCacheTTL)}
+	c.lookups.mu.Unlock()
+	return languages, nil
+}
+
+// LocationCodeByCountry resolves a country name ("Australia") or ISO code ("AU"),
+// case-insensitively, to its location code (2036).
+func (c *Client) LocationCodeByCountry(ctx context.Context, country string) (int, error) {
+	name := strings.TrimSpace(country)
+	if name == "" {
+		return 0, fmt.Errorf("%w: empty", ErrUnknownLocation)
+	}
+	locations, err := c.ListLocations(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	for _, loc := range locations {
+		if loc.LocationType != "Country" {
+			continue
+		}
+		if strings.EqualFold(loc.LocationName, name) || strings.EqualFold(loc.CountryISOCode, name) {
+			return loc.LocationCode, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownLocation, country)
+}
+
+// LanguageCodeByName resolves a language name ("English") or code ("en"),
+// case-insensitively, to its language code.
+func (c *Client) LanguageCodeByName(ctx context.Context, language string) (string, error) {
+	name := strings.TrimSpace(language)
+	if name == "" {
+		return "", fmt.Errorf("%w: empty", ErrUnknownLanguage)
+	}
+	languages, err := c.ListLanguages(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, lang := range languages {
+		if strings.EqualFold(lang.LanguageName, name) || strings.EqualFold(lang.LanguageCode, name) {
+			return lang.LanguageCode, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownLanguage, language)
+}
+
+// getLookup fetches a GET lookup endpoint whose task result is the list itself.
+func (c *Client) getLookup(ctx context.Context, path string, dst any) error {
+	resp, err := c.getRaw(ctx, path)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 20000 {
+		return newAPIError(path, resp)
+	}
+	return c.firstResult(resp, dst)
+}
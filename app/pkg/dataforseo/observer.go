@@ -0,0 +1,25 @@
+package dataforseo
+
+import "time"
+
+// RequestInfo describes one API call, reported to the observer once the call
+// returns. Retries of the same call are folded into a single RequestInfo.
+type RequestInfo struct {
+	Method     string
+	Endpoint   string        // request path, e.g. "/on_page/summary/abc"
+	Duration   time.Duration // wall time including queueing, rate limiting and retries
+	HTTPStatus int           // status of the last HTTP response; 0 if none arrived
+	StatusCode int           // envelope status_code; 0 if no envelope was decoded
+	Retries    int           // attempts after the first
+	Cost       float64       // envelope cost in USD
+	Err        error         // error returned to the caller, if any
+}
+
+// WithObserver registers a callback invoked after every API call, successful
+// or not, e.g. to export metrics or structured logs. It runs on the calling
+// goroutine, so it must be fast and safe for concurrent use.
+func WithObserver(observe func(RequestInfo)) Option {
+	return func(c *Client) {
+		c.observer = observe
+	}
+}
@@ -8,16 +8,20 @@ import (
 
 // OnPageTaskPostRequest contains parameters for creating an on-page audit task.
 type OnPageTaskPostRequest struct {
-	Target                string `json:"target"`
-	MaxCrawlPages         int    `json:"max_crawl_pages,omitempty"`
-	StartURL              string `json:"start_url,omitempty"`
-	MaxCrawlDepth         int    `json:"max_crawl_depth,omitempty"`
-	EnableSitemap         bool   `json:"enable_sitemap_checking,omitempty"`
-	EnableJavascript      bool   `json:"enable_javascript,omitempty"`
-	LoadResources         bool   `json:"load_resources,omitempty"`
-	AllowSubdomains       bool   `json:"allow_subdomains,omitempty"`
-	EnableBrowserRendering bool  `json:"enable_browser_rendering,omitempty"`
-	Tag                   string `json:"tag,omitempty"`
+	Target                 string `json:"target"`
+	MaxCrawlPages          int    `json:"max_crawl_pages,omitempty"`
+	StartURL               string `json:"start_url,omitempty"`
+	MaxCrawlDepth          int    `json:"max_crawl_depth,omitempty"`
+	EnableSitemap          bool   `json:"enable_sitemap_checking,omitempty"`
+	EnableJavascript       bool   `json:"enable_javascript,omitempty"`
+	LoadResources          bool   `json:"load_resources,omitempty"`
+	AllowSubdomains        bool   `json:"allow_subdomains,omitempty"`
+	EnableBrowserRendering bool   `json:"enable_browser_rendering,omitempty"`
+	Tag                    string `json:"tag,omitempty"`
+	// PingbackURL is called (GET) when the crawl completes; see PingbackURL and PingbackHandler.
+	PingbackURL string `json:"pingback_url,omitempty"`
+	// PostbackURL receives the completed task (POST) when the crawl completes.
+	PostbackURL string `json:"postback_url,omitempty"`
 }
 
 // OnPageSummary contains the result of an on-page audit summary.
@@ -146,19 +150,12 @@ type OnPagePageTiming struct {
 
 // CreateOnPageTask creates an on-page audit task. Returns the task ID.
 func (c *Client) CreateOnPageTask(ctx context.Context, req OnPageTaskPostRequest) (string, error) {
-	resp, err := c.post(ctx, "/on_page/task_post", []OnPageTaskPostRequest{req})
+	target, err := NormalizeTarget(req.Target, TargetDomain)
 	if err != nil {
 		return "", err
 	}
-	if len(resp.Tasks) == 0 {
-		return "", fmt.Errorf("dataforseo: no tasks in response")
-	}
-	task := resp.Tasks[0]
-	// 20000 = Ok, 20100 = Task Created (async task accepted)
-	if task.StatusCode != 20000 && task.StatusCode != 20100 {
-		return "", fmt.Errorf("dataforseo: task error %d: %s", task.StatusCode, task.StatusMessage)
-	}
-	return task.ID, nil
+	req.Target = target
+	return c.PostTask(ctx, "/on_page/task_post", req)
 }
 
 // GetOnPageSummary retrieves the summary of an on-page audit task.
@@ -174,7 +171,7 @@ func (c *Client) GetOnPageSummary(ctx context.Context, taskID string) (*OnPageSu
 		return nil, ErrTaskNotReady
 	}
 	if resp.StatusCode != 20000 {
-		return nil, fmt.Errorf("dataforseo: API error %d: %s", resp.StatusCode, resp.StatusMessage)
+		return nil, newAPIError("/on_page/summary/"+taskID, resp)
 	}
 	var results []OnPageSummary
 	if err := c.firstResult(resp, &results); err != nil {
@@ -217,3 +214,206 @@ func (c *Client) GetOnPagePages(ctx context.Context, taskID string, limit, offse
 	}
 	return results[0].Items, results[0].ItemsCount, nil
 }
+
+// onPageListRequest is the request body for paginated on_page list endpoints.
+type onPageListRequest struct {
+	ID     string `json:"id"`
+	Limit  int    `json:"limit,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	Type   string `json:"type,omitempty"` // duplicate_tags only
+}
+
+// onPageListResult wraps a paginated on_page list response.
+type onPageListResult[T any] struct {
+	CrawlProgress string `json:"crawl_progress"`
+	ItemsCount    int    `json:"items_count"`
+	Items         []T    `json:"items"`
+}
+
+// getOnPageList posts a list request and returns the items and count of the first result.
+func getOnPageList[T any](ctx context.Context, c *Client, path string, req onPageListRequest) ([]T, int, error) {
+	resp, err := c.post(ctx, path, []onPageListRequest{req})
+	if err != nil {
+		return nil, 0, err
+	}
+	var results []onPageListResult[T]
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, 0, err
+	}
+	if len(results) == 0 {
+		return nil, 0, nil
+	}
+	return results[0].Items, results[0].ItemsCount, nil
+}
+
+// OnPageResource is a non-HTML resource (image, script, stylesheet, ...) found during the crawl.
+type OnPageResource struct {
+	ResourceType      string          `json:"resource_type"`
+	StatusCode        int             `json:"status_code"`
+	URL               string          `json:"url"`
+	Size              int             `json:"size"`
+	EncodedSize       int             `json:"encoded_size"`
+	TotalTransferSize int             `json:"total_transfer_size"`
+	FetchTime         string          `json:"fetch_time"`
+	MediaType         string          `json:"media_type"`
+	ContentEncoding   string          `json:"content_encoding"`
+	Server            string          `json:"server"`
+	Checks            map[string]bool `json:"checks"`
+}
+
+// IsBroken reports whether the resource failed to load (4xx/5xx or a broken check).
+func (r *OnPageResource) IsBroken() bool {
+	return r.StatusCode >= 400 || r.Checks["is_broken"]
+}
+
+// GetOnPageResources retrieves resources found during an on-page audit task.
+// Returns the resources, total count, and any error.
+func (c *Client) GetOnPageResources(ctx context.Context, taskID string, limit, offset int) ([]OnPageResource, int, error) {
+	return getOnPageList[OnPageResource](ctx, c, "/on_page/resources", onPageListRequest{ID: taskID, Limit: limit, Offset: offset})
+}
+
+// OnPageLink is a link between two resources found during the crawl.
+type OnPageLink struct {
+	Type             string `json:"type"` // anchor, image, link, canonical, meta, alternate, redirect
+	DomainFrom       string `json:"domain_from"`
+	DomainTo         string `json:"domain_to"`
+	PageFrom         string `json:"page_from"`
+	PageTo           string `json:"page_to"`
+	LinkFrom         string `json:"link_from"`
+	LinkTo           string `json:"link_to"`
+	Text             string `json:"text"`
+	Direction        string `json:"direction"` // internal or external
+	Dofollow         bool   `json:"dofollow"`
+	IsBroken         bool   `json:"is_broken"`
+	PageToStatusCode int    `json:"page_to_status_code"`
+}
+
+// GetOnPageLinks retrieves links found during an on-page audit task.
+// Returns the links, total count, and any error.
+func (c *Client) GetOnPageLinks(ctx context.Context, taskID string, limit, offset int) ([]OnPageLink, int, error) {
+	return getOnPageList[OnPageLink](ctx, c, "/on_page/links", onPageListRequest{ID: taskID, Limit: limit, Offset: offset})
+}
+
+// DuplicateTagType selects which tag GetOnPageDuplicateTags groups pages by.
+type DuplicateTagType string
+
+const (
+	DuplicateTitle       DuplicateTagType = "duplicate_title"
+	DuplicateDescription DuplicateTagType = "duplicate_description"
+)
+
+// OnPageDuplicateGroup is a set of pages sharing the same title or description.
+type OnPageDuplicateGroup struct {
+	Accumulator string       `json:"accumulator"` // the shared tag value
+	TotalCount  int          `json:"total_count"`
+	Pages       []OnPagePage `json:"pages"`
+}
+
+// GetOnPageDuplicateTags retrieves groups of pages with duplicate titles or descriptions.
+// Returns the groups, total count, and any error.
+func (c *Client) GetOnPageDuplicateTags(ctx context.Context, taskID string, tag DuplicateTagType, limit, offset int) ([]OnPageDuplicateGroup, int, error) {
+	return getOnPageList[OnPageDuplicateGroup](ctx, c, "/on_page/duplicate_tags", onPageListRequest{ID: taskID, Type: string(tag), Limit: limit, Offset: offset})
+}
+
+// OnPageNonIndexable is a page that search engines are prevented from indexing.
+type OnPageNonIndexable struct {
+	Reason string `json:"reason"` // e.g. robots_txt, meta_tag, http_header, canonical
+	URL    string `json:"url"`
+}
+
+// GetOnPageNonIndexable retrieves pages blocked from indexing.
+// Returns the pages, total count, and any error.
+func (c *Client) GetOnPageNonIndexable(ctx context.Context, taskID string, limit, offset int) ([]OnPageNonIndexable, int, error) {
+	return getOnPageList[OnPageNonIndexable](ctx, c, "/on_page/non_indexable", onPageListRequest{ID: taskID, Limit: limit, Offset: offset})
+}
+
+// lighthouseRequest is the request body for the on_page/lighthouse endpoint.
+type lighthouseRequest struct {
+	URL       string `json:"url"`
+	ForMobile bool   `json:"for_mobile,omitempty"`
+}
+
+// LighthouseCategory is a scored Lighthouse category (performance, accessibility, ...).
+type LighthouseCategory struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Score *float64 `json:"score"` // 0-1; nil when the category couldn't be scored
+}
+
+// LighthouseAudit is a single Lighthouse audit result.
+type LighthouseAudit struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Score        *float64 `json:"score"`
+	ScoreDisplay string   `json:"scoreDisplayMode"`
+	NumericValue float64  `json:"numericValue"`
+	NumericUnit  string   `json:"numericUnit"`
+	DisplayValue string   `json:"displayValue"`
+}
+
+// LighthouseResult is the Lighthouse report for a page. Category keys are
+// "performance", "accessibility", "best-practices" and "seo".
+type LighthouseResult struct {
+	LighthouseVersion string                        `json:"lighthouseVersion"`
+	RequestedURL      string                        `json:"requestedUrl"`
+	FinalURL          string                        `json:"finalUrl"`
+	FetchTime         string                        `json:"fetchTime"`
+	Categories        map[string]LighthouseCategory `json:"categories"`
+	Audits            map[string]LighthouseAudit    `json:"audits"`
+}
+
+// GetOnPageLighthouse runs Lighthouse against a page and returns the report.
+func (c *Client) GetOnPageLighthouse(ctx context.Context, pageURL string, forMobile bool) (*LighthouseResult, error) {
+	target, err := NormalizeTarget(pageURL, TargetURL)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.post(ctx, "/on_page/lighthouse/live/json", []lighthouseRequest{{URL: target, ForMobile: forMobile}})
+	if err != nil {
+		return nil, err
+	}
+	var results []LighthouseResult
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("dataforseo: empty lighthouse result")
+	}
+	return &results[0], nil
+}
+
+// InstantPagesRequest checks a single page without starting a crawl.
+type InstantPagesRequest struct {
+	URL                    string `json:"url"`
+	EnableJavascript       bool   `json:"enable_javascript,omitempty"`
+	EnableBrowserRendering bool   `json:"enable_browser_rendering,omitempty"`
+}
+
+// GetInstantPage crawls one page live and returns it with its on-page checks,
+// as the task-based crawl would. Useful for re-verifying a page after a fix.
+func (c *Client) GetInstantPage(ctx context.Context, req InstantPagesRequest) (*OnPagePage, error) {
+	page, _, err := c.GetInstantPageWithRaw(ctx, req)
+	return page, err
+}
+
+// GetInstantPageWithRaw is GetInstantPage that also returns the task's raw
+// result, for callers that keep it for analysis the typed page doesn't cover.
+func (c *Client) GetInstantPageWithRaw(ctx context.Context, req InstantPagesRequest) (*OnPagePage, json.RawMessage, error) {
+	target, err := NormalizeTarget(req.URL, TargetURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.URL = target
+	resp, err := c.post(ctx, "/on_page/instant_pages", []InstantPagesRequest{req})
+	if err != nil {
+		return nil, nil, err
+	}
+	var results []onPagePagesResult
+	if err := c.firstResult(resp, &results); err != nil {
+		return nil, nil, err
+	}
+	if len(results) == 0 || len(results[0].Items) == 0 {
+		return nil, nil, fmt.Errorf("dataforseo: empty instant pages result")
+	}
+	return &results[0].Items[0], resp.Tasks[0].Result, nil
+}
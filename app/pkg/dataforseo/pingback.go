@@ -0,0 +1,129 @@
+package dataforseo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxPostbackSize bounds postback bodies, which carry the full task result.
+const maxPostbackSize = 64 << 20
+
+// Pingback is a completion notification for an async task, received either as
+// a pingback (GET with id and tag) or a postback (POST with the task result).
+type Pingback struct {
+	TaskID string
+	Tag    string
+	Task   *Task // postback only: the completed task including its result
+}
+
+// PingbackURL builds a pingback_url/postback_url for task_post requests from
+// the webhook route's absolute URL. DataForSEO substitutes the $id and $tag
+// placeholders; token is echoed back so PingbackHandler can authenticate the call.
+func PingbackURL(baseURL, token string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("dataforseo: invalid pingback base URL %q", baseURL)
+	}
+	sep := "?"
+	if u.RawQuery != "" {
+		sep = "&"
+	}
+	// Built by hand: url.Values would escape the $ placeholders.
+	s := baseURL + sep + "id=$id&tag=$tag"
+	if token != "" {
+		s += "&token=" + url.QueryEscape(token)
+	}
+	return s, nil
+}
+
+// PingbackHandler returns an http.Handler for a webhook route registered as a
+// task's pingback or postback URL (see PingbackURL). It checks the token query
+// parameter (skipped when token is empty), parses the notification and calls fn.
+// Errors from fn are reported as 500 so they show up in DataForSEO's logs.
+func PingbackHandler(token string, fn func(ctx context.Context, pb Pingback) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(token)) != 1 {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		var pb Pingback
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			pb, err = parsePingback(r)
+		case http.MethodPost:
+			pb, err = parsePostback(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := fn(r.Context(), pb); err != nil {
+			http.Error(w, "pingback handler failed", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func parsePingback(r *http.Request) (Pingback, error) {
+	q := r.URL.Query()
+	id := q.Get("id")
+	if id == "" || strings.HasPrefix(id, "$") {
+		return Pingback{}, fmt.Errorf("dataforseo: pingback missing task id")
+	}
+	tag := q.Get("tag")
+	if tag == "$tag" {
+		tag = ""
+	}
+	return Pingback{TaskID: id, Tag: tag}, nil
+}
+
+// parsePostback decodes a postback body: the same envelope task_get returns,
+// gzip-compressed unless the task was posted with postback_data "json".
+func parsePostback(w http.ResponseWriter, r *http.Request) (Pingback, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPostbackSize))
+	if err != nil {
+		return Pingback{}, fmt.Errorf("dataforseo: read postback: %w", err)
+	}
+	if len(body) >= 2 && body[0] == 0x1f && body[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return Pingback{}, fmt.Errorf("dataforseo: decompress postback: %w", err)
+		}
+		if body, err = io.ReadAll(io.LimitReader(zr, maxPostbackSize)); err != nil {
+			return Pingback{}, fmt.Errorf("dataforseo: decompress postback: %w", err)
+		}
+	}
+
+	var resp Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Pingback{}, fmt.Errorf("dataforseo: decode postback: %w", err)
+	}
+	if len(resp.Tasks) == 0 || resp.Tasks[0].ID == "" {
+		return Pingback{}, fmt.Errorf("dataforseo: postback missing task")
+	}
+
+	task := resp.Tasks[0]
+	var data struct {
+		Tag string `json:"tag"`
+	}
+	if len(task.Data) > 0 {
+		_ = json.Unmarshal(task.Data, &data)
+	}
+	return Pingback{TaskID: task.ID, Tag: data.Tag, Task: &task}, nil
+}
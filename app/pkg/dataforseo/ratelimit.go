@@ -0,0 +1,112 @@
+package dataforseo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WithRateLimit caps requests at rps per second across all endpoints using a
+// token bucket that allows bursts of up to one second's worth of requests.
+// The semaphore set by WithMaxConcurrent still bounds concurrency on top.
+func WithRateLimit(rps float64) Option {
+	return func(c *Client) {
+		if rps > 0 {
+			c.limiter = newRateLimiter(rps)
+		}
+	}
+}
+
+// WithRequestsPerMinute is WithRateLimit expressed as a per-minute cap,
+// e.g. WithRequestsPerMinute(2000) for DataForSEO's account limit.
+func WithRequestsPerMinute(n int) Option {
+	return WithRateLimit(float64(n) / 60)
+}
+
+// rateLimiter is a token bucket shared by every request the client sends.
+// A 429 with Retry-After pauses it so concurrent callers back off together.
+type rateLimiter struct {
+	mu          sync.Mutex
+	rate        float64 // tokens per second
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newRateLimiter(rps float64) *rateLimiter {
+	burst := max(rps, 1)
+	return &rateLimiter{rate: rps, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait blocks until a token is available or ctx is done. A nil limiter never blocks.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("dataforseo: %w", ctx.Err())
+		}
+	}
+}
+
+// reserve takes a token if one is available, else returns how long to wait.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// pause holds every caller for d and empties the bucket, so requests resume
+// at the steady rate rather than in a burst.
+func (l *rateLimiter) pause(d time.Duration) {
+	if l == nil || d <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if until := now.Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.tokens = 0
+	l.last = now.Add(d)
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+// It returns 0 when the header is missing or invalid.
+func parseRetryAfter(h string) time.Duration {
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(h); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,88 @@
+package dataforseo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	serpOrganicTaskPost = "/serp/google/organic/task_post"
+	serpOrganicTaskGet  = "/serp/google/organic/task_get/regular"
+)
+
+// SERPOrganicRequest queues a Google organic SERP lookup for one keyword.
+type SERPOrganicRequest struct {
+	Keyword      string `json:"keyword"`
+	LocationCode int    `json:"location_code"`
+	LanguageCode string `json:"language_code"`
+	Device       string `json:"device,omitempty"` // desktop (default) or mobile
+	Depth        int    `json:"depth,omitempty"`  // results to collect; API default 100, billed per 10
+	Tag          string `json:"tag,omitempty"`    // echoed back in GetTasksReady
+}
+
+// SERPOrganicResult is a collected Google SERP.
+type SERPOrganicResult struct {
+	Keyword        string           `json:"keyword"`
+	LocationCode   int              `json:"location_code"`
+	LanguageCode   string           `json:"language_code"`
+	CheckURL       string           `json:"check_url"`
+	Datetime       string           `json:"datetime"`
+	ItemTypes      []string         `json:"item_types"` // every element type on the page, e.g. "organic", "featured_snippet"
+	SEResultsCount int64            `json:"se_results_count"`
+	ItemsCount     int              `json:"items_count"`
+	Items          []SERPResultItem `json:"items"`
+}
+
+// SERPResultItem is one element of a SERP. Only organic items carry a
+// meaningful RankGroup; RankAbsolute counts every element on the page.
+type SERPResultItem struct {
+	Type         string `json:"type"`
+	RankGroup    int    `json:"rank_group"`
+	RankAbsolute int    `json:"rank_absolute"`
+	Domain       string `json:"domain"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	Description  string `json:"description"`
+}
+
+// PostSERPOrganicTasks queues up to MaxTasksPerPost organic SERP lookups in
+// one call, returning one QueuedTask per request in order.
+func (c *Client) PostSERPOrganicTasks(ctx context.Context, reqs []SERPOrganicRequest) ([]QueuedTask, error) {
+	payloads := make([]any, len(reqs))
+	for i, req := range reqs {
+		payloads[i] = req
+	}
+	return c.PostTasks(ctx, serpOrganicTaskPost, payloads)
+}
+
+// GetSERPOrganicTask fetches the result of a queued SERP lookup. Returns
+// ErrTaskNotReady while the task is still queued or running.
+func (c *Client) GetSERPOrganicTask(ctx context.Context, id string) (*SERPOrganicResult, error) {
+	results, err := GetTaskResult[[]SERPOrganicResult](ctx, c, serpOrganicTaskGet, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("dataforseo: empty SERP result")
+	}
+	return &results[0], nil
+}
+
+// FindDomain returns the highest-ranked organic item on domain or one of its
+// subdomains, or nil when the domain doesn't rank within the collected depth.
+// A leading "www." on either side is ignored.
+func (r *SERPOrganicResult) FindDomain(domain string) *SERPResultItem {
+	domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+	for i := range r.Items {
+		item := &r.Items[i]
+		if item.Type != "organic" {
+			continue
+		}
+		host := strings.TrimPrefix(strings.ToLower(item.Domain), "www.")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return item
+		}
+	}
+	return nil
+}
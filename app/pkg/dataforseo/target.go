@@ -0,0 +1,115 @@
+package dataforseo
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// TargetKind selects the format NormalizeTarget produces.
+type TargetKind int
+
+const (
+	// TargetDomain is a bare registrable host without scheme or "www.", e.g. "example.com".
+	// This is what Labs and OnPage endpoints expect.
+	TargetDomain TargetKind = iota
+	// TargetWildcard matches a domain and all its subdomains, e.g. "*.example.com".
+	TargetWildcard
+	// TargetURL is an absolute http(s) URL with the fragment stripped, e.g. "https://www.example.com/page?x=1".
+	TargetURL
+)
+
+// ErrInvalidTarget is returned (wrapped) when a target can't be normalized.
+var ErrInvalidTarget = errors.New("dataforseo: invalid target")
+
+// NormalizeTarget converts user input such as "https://www.Example.com/page?x=1",
+// "example.com" or "*.example.com" into the format an endpoint expects.
+func NormalizeTarget(input string, kind TargetKind) (string, error) {
+	raw := strings.TrimSpace(input)
+	raw = strings.TrimPrefix(raw, "*.")
+	if raw == "" {
+		return "", fmt.Errorf("%w: empty", ErrInvalidTarget)
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTarget, input)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%w: unsupported scheme %q", ErrInvalidTarget, u.Scheme)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if !validHost(host) {
+		return "", fmt.Errorf("%w: %q is not a valid host", ErrInvalidTarget, input)
+	}
+
+	switch kind {
+	case TargetDomain:
+		return strings.TrimPrefix(host, "www."), nil
+	case TargetWildcard:
+		return "*." + strings.TrimPrefix(host, "www."), nil
+	case TargetURL:
+		if port := u.Port(); port != "" {
+			u.Host = host + ":" + port
+		} else {
+			u.Host = host
+		}
+		if u.Path == "" {
+			u.Path = "/"
+		}
+		u.Fragment = ""
+		u.RawFragment = ""
+		return u.String(), nil
+	default:
+		return "", fmt.Errorf("%w: unknown target kind %d", ErrInvalidTarget, kind)
+	}
+}
+
+// validHost reports whether host looks like a DNS name with at least one dot.
+func validHost(host string) bool {
+	if host == "" || len(host) > 253 || !strings.Contains(host, ".") {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MaxKeywordLength is the longest keyword the API accepts, in bytes.
+const MaxKeywordLength = 700
+
+// ErrInvalidKeyword is returned (wrapped) when a keyword is too long to query.
+var ErrInvalidKeyword = errors.New("dataforseo: invalid keyword")
+
+// NormalizeKeywords collapses the whitespace in each keyword and drops blanks
+// and duplicates. Keywords differing only in case are duplicates, as Google's
+// results don't depend on case; the first spelling is kept.
+func NormalizeKeywords(raw []string) ([]string, error) {
+	seen := make(map[string]bool, len(raw))
+	keywords := make([]string, 0, len(raw))
+	for _, k := range raw {
+		k = strings.Join(strings.Fields(k), " ")
+		if k == "" || seen[strings.ToLower(k)] {
+			continue
+		}
+		if len(k) > MaxKeywordLength {
+			return nil, fmt.Errorf("%w: longer than %d bytes", ErrInvalidKeyword, MaxKeywordLength)
+		}
+		seen[strings.ToLower(k)] = true
+		keywords = append(keywords, k)
+	}
+	return keywords, nil
+}
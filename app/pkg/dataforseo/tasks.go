@@ -0,0 +1,163 @@
+package dataforseo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const defaultPollInterval = 5 * time.Second
+
+// WithPollInterval sets how often WaitForTask checks whether a task is ready.
+func WithPollInterval(d time.Duration) Option {
+	return func(c *Client) {
+		c.pollInterval = d
+	}
+}
+
+// ReadyTask is a completed task returned by a tasks_ready endpoint.
+type ReadyTask struct {
+	ID               string `json:"id"`
+	SE               string `json:"se"`
+	SEType           string `json:"se_type"`
+	DatePosted       string `json:"date_posted"`
+	Tag              string `json:"tag"`
+	EndpointRegular  string `json:"endpoint_regular"`
+	EndpointAdvanced string `json:"endpoint_advanced"`
+	EndpointHTML     string `json:"endpoint_html"`
+}
+
+// notReadyStatusCodes are task status codes meaning the task is still queued or running.
+var notReadyStatusCodes = map[int]bool{
+	40400: true, // Not Found — not yet visible to task_get
+	40601: true, // Task Handed
+	40602: true, // Task In Queue
+}
+
+// PostTask posts a single task to an async task_post endpoint
+// (e.g. "/serp/google/organic/task_post") and returns the task ID.
+// Task endpoints are usually much cheaper than their /live counterparts.
+func (c *Client) PostTask(ctx context.Context, endpoint string, payload any) (string, error) {
+	resp, err := c.post(ctx, endpoint, []any{payload})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Tasks) == 0 {
+		return "", fmt.Errorf("dataforseo: no tasks in response")
+	}
+	task := resp.Tasks[0]
+	// 20000 = Ok, 20100 = Task Created (async task accepted)
+	if task.StatusCode != 20000 && task.StatusCode != 20100 {
+		return "", newTaskError(task)
+	}
+	return task.ID, nil
+}
+
+// MaxTasksPerPost is the most tasks a single task_post call accepts.
+const MaxTasksPerPost = 100
+
+// QueuedTask is the outcome of one task in a PostTasks call: the task ID and
+// what posting it cost, or the error the API rejected that task with.
+type QueuedTask struct {
+	ID   string
+	Cost float64
+	Err  error
+}
+
+// PostTasks posts up to MaxTasksPerPost tasks to a task_post endpoint in one
+// call. Tasks are accepted or rejected individually, so the result has one
+// entry per payload, in order; the error is only for the call as a whole.
+func (c *Client) PostTasks(ctx context.Context, endpoint string, payloads []any) ([]QueuedTask, error) {
+	if len(payloads) == 0 {
+		return nil, nil
+	}
+	if len(payloads) > MaxTasksPerPost {
+		return nil, fmt.Errorf("dataforseo: %d tasks exceeds the limit of %d per call", len(payloads), MaxTasksPerPost)
+	}
+	resp, err := c.post(ctx, endpoint, payloads)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Tasks) != len(payloads) {
+		return nil, fmt.Errorf("dataforseo: posted %d tasks, got %d back", len(payloads), len(resp.Tasks))
+	}
+	queued := make([]QueuedTask, len(resp.Tasks))
+	for i, task := range resp.Tasks {
+		if task.StatusCode != 20000 && task.StatusCode != 20100 {
+			queued[i].Err = newTaskError(task)
+			continue
+		}
+		queued[i].ID = task.ID
+		queued[i].Cost = task.Cost
+	}
+	return queued, nil
+}
+
+// GetTasksReady lists completed tasks that haven't been collected yet for an
+// API family, e.g. "/serp/google/organic" or "/on_page".
+func (c *Client) GetTasksReady(ctx context.Context, api string) ([]ReadyTask, error) {
+	resp, err := c.getRaw(ctx, api+"/tasks_ready")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 20000 {
+		return nil, newAPIError(api+"/tasks_ready", resp)
+	}
+	var ready []ReadyTask
+	for _, task := range resp.Tasks {
+		if task.StatusCode != 20000 || task.Result == nil {
+			continue
+		}
+		var results []ReadyTask
+		if err := c.firstResult(&Response{Tasks: []Task{task}}, &results); err != nil {
+			return nil, err
+		}
+		ready = append(ready, results...)
+	}
+	return ready, nil
+}
+
+// GetTaskResult fetches and decodes the result of an async task from a
+// task_get path (e.g. "/serp/google/organic/task_get/regular").
+// T is the type of the task's result array, typically a slice of result structs.
+// Returns ErrTaskNotReady if the task is still queued or running.
+func GetTaskResult[T any](ctx context.Context, c *Client, taskGetPath, id string) (T, error) {
+	var result T
+	resp, err := c.getRaw(ctx, taskGetPath+"/"+id)
+	if err != nil {
+		return result, err
+	}
+	if notReadyStatusCodes[resp.StatusCode] {
+		return result, ErrTaskNotReady
+	}
+	if resp.StatusCode != 20000 {
+		return result, newAPIError(taskGetPath+"/"+id, resp)
+	}
+	if len(resp.Tasks) > 0 && notReadyStatusCodes[resp.Tasks[0].StatusCode] {
+		return result, ErrTaskNotReady
+	}
+	if err := c.firstResult(resp, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// WaitForTask calls fetch every poll interval until it returns something other
+// than ErrTaskNotReady, or ctx is done. fetch typically wraps GetTaskResult or
+// GetOnPageSummary and stores the result in a captured variable.
+func (c *Client) WaitForTask(ctx context.Context, id string, fetch func(ctx context.Context, id string) error) error {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		err := fetch(ctx, id)
+		if !errors.Is(err, ErrTaskNotReady) {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("dataforseo: wait for task %s: %w", id, ctx.Err())
+		}
+	}
+}
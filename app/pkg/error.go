@@ -3,6 +3,7 @@ package pkg
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 type ValidationError struct {
@@ -66,3 +67,36 @@ type ForbiddenError struct{
 func (e ForbiddenError) Error() string {
 	return e.Err.Error()
 }
+
+// LockedError is returned when a resource is read-only, e.g. content above the
+// free tier limits after a subscription lapses. Reason is a machine-readable code.
+type LockedError struct {
+	Reason string
+}
+
+func (e LockedError) Error() string {
+	return fmt.Sprintf("resource is locked: %s", e.Reason)
+}
+
+// QuotaExceededError is returned when an upload would take an organisation over
+// its tier's storage quota. UsedBytes and LimitBytes let the client show an upgrade prompt.
+type QuotaExceededError struct {
+	Message    string
+	UsedBytes  int64
+	LimitBytes int64
+}
+
+func (e QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: %d of %d bytes used", e.Message, e.UsedBytes, e.LimitBytes)
+}
+
+// RateLimitedError is returned when a caller has used up its request allowance
+// for the current window. RetryAfter is how long until the window resets.
+type RateLimitedError struct {
+	Limit      int
+	RetryAfter time.Duration
+}
+
+func (e RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limit of %d requests exceeded, retry after %s", e.Limit, e.RetryAfter)
+}
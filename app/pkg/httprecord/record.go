@@ -0,0 +1,233 @@
+// Package httprecord keeps sanitized copies of recent outgoing HTTP requests
+// and their responses in a bounded in-memory ring buffer, to diagnose odd
+// responses from external APIs without redeploying with extra logging.
+//
+// Recording is off until Enable is called. Clients wrap their transport with
+// Transport once at construction; the wrapper passes calls straight through
+// while recording is off and starts recording as soon as it's enabled.
+package httprecord
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxBody is the most bytes of each request and response body kept
+// when NewRecorder is given no limit.
+const DefaultMaxBody = 64 << 10
+
+// Exchange is one recorded call. Credentials and personal data are redacted
+// from the URL, headers and bodies before it's stored.
+type Exchange struct {
+	ID                uint64            `json:"id"`
+	Service           string            `json:"service"` // e.g. "dataforseo"
+	StartedAt         time.Time         `json:"startedAt"`
+	DurationMs        int64             `json:"durationMs"`
+	Method            string            `json:"method"`
+	URL               string            `json:"url"`
+	RequestHeaders    map[string]string `json:"requestHeaders"`
+	RequestBody       string            `json:"requestBody"`
+	RequestTruncated  bool              `json:"requestTruncated"`
+	Status            int               `json:"status"` // 0 when no response arrived
+	ResponseHeaders   map[string]string `json:"responseHeaders"`
+	ResponseBody      string            `json:"responseBody"`
+	ResponseTruncated bool              `json:"responseTruncated"`
+	Error             string            `json:"error,omitempty"`
+}
+
+// Recorder is a fixed-size ring buffer of exchanges, safe for concurrent use.
+// Once full, each new exchange replaces the oldest.
+type Recorder struct {
+	maxBody int
+
+	mu    sync.Mutex
+	ring  []Exchange
+	next  int // slot the next exchange goes in
+	count int
+	seq   uint64
+}
+
+// NewRecorder returns a recorder keeping the last size exchanges and the
+// first maxBody bytes of each body (DefaultMaxBody when 0).
+func NewRecorder(size, maxBody int) *Recorder {
+	if maxBody <= 0 {
+		maxBody = DefaultMaxBody
+	}
+	return &Recorder{maxBody: maxBody, ring: make([]Exchange, max(size, 1))}
+}
+
+func (r *Recorder) add(e Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	e.ID = r.seq
+	r.ring[r.next] = e
+	r.next = (r.next + 1) % len(r.ring)
+	r.count = min(r.count+1, len(r.ring))
+}
+
+// List returns up to limit recorded exchanges, newest first, optionally only
+// those of one service. A limit of 0 returns them all.
+func (r *Recorder) List(service string, limit int) []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exchanges := []Exchange{}
+	for i := 1; i <= r.count; i++ {
+		e := r.ring[(r.next-i+len(r.ring))%len(r.ring)]
+		if service != "" && e.Service != service {
+			continue
+		}
+		if limit > 0 && len(exchanges) == limit {
+			break
+		}
+		exchanges = append(exchanges, e)
+	}
+	return exchanges
+}
+
+// Get returns the recorded exchange with the given ID, if it's still in the
+// buffer.
+func (r *Recorder) Get(id uint64) (Exchange, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := 0; i < r.count; i++ {
+		if e := r.ring[i]; e.ID == id {
+			return e, true
+		}
+	}
+	return Exchange{}, false
+}
+
+// Clear drops every recorded exchange.
+func (r *Recorder) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.ring)
+	r.next, r.count = 0, 0
+}
+
+var active atomic.Pointer[Recorder]
+
+// Enable starts recording into r for every wrapped transport; nil stops
+// recording.
+func Enable(r *Recorder) {
+	active.Store(r)
+}
+
+// Active returns the recorder in use, or nil when recording is off.
+func Active() *Recorder {
+	return active.Load()
+}
+
+// Transport wraps base (http.DefaultTransport when nil) so its calls are
+// recorded under service while recording is enabled.
+func Transport(service string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{service: service, base: base}
+}
+
+type transport struct {
+	service string
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := active.Load()
+	if rec == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	e := Exchange{
+		Service:        t.service,
+		StartedAt:      time.Now(),
+		Method:         req.Method,
+		URL:            sanitizeURL(req.URL),
+		RequestHeaders: sanitizeHeaders(req.Header),
+	}
+	req, body, err := peekRequestBody(req)
+	if err != nil {
+		e.Error = err.Error()
+		rec.add(e)
+		return nil, err
+	}
+	e.RequestBody, e.RequestTruncated = sanitizeBody(req.Header, body, rec.maxBody)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		e.DurationMs = time.Since(e.StartedAt).Milliseconds()
+		e.Error = err.Error()
+		rec.add(e)
+		return nil, err
+	}
+	e.Status = resp.StatusCode
+	e.ResponseHeaders = sanitizeHeaders(resp.Header)
+	// The response is recorded once the caller has read and closed it, so
+	// streamed bodies such as package downloads pass through untouched
+	resp.Body = &recordingBody{ReadCloser: resp.Body, rec: rec, exchange: e, header: resp.Header}
+	return resp, nil
+}
+
+// peekRequestBody returns the request body for recording and a request that
+// still has all of it to send. RoundTrip mustn't modify the caller's request,
+// so a body without GetBody is read into a clone.
+func peekRequestBody(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return req, nil, err
+		}
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		return req, data, err
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return req, nil, err
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(data))
+	return clone, data, nil
+}
+
+// recordingBody keeps the first maxBody bytes of a response as it's read and
+// records the exchange when it's closed.
+type recordingBody struct {
+	io.ReadCloser
+	rec      *Recorder
+	exchange Exchange
+	header   http.Header
+	buf      bytes.Buffer
+	once     sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := b.rec.maxBody + 1 - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	if err != nil && err != io.EOF {
+		b.exchange.Error = err.Error()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		e := b.exchange
+		e.DurationMs = time.Since(e.StartedAt).Milliseconds()
+		e.ResponseBody, e.ResponseTruncated = sanitizeBody(b.header, b.buf.Bytes(), b.rec.maxBody)
+		b.rec.add(e)
+	})
+	return err
+}
@@ -0,0 +1,147 @@
+package httprecord
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRecordingClient(t *testing.T, rec *Recorder, handler http.HandlerFunc) (*http.Client, string) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	Enable(rec)
+	t.Cleanup(func() { Enable(nil) })
+	return &http.Client{Transport: Transport("test", nil)}, srv.URL
+}
+
+func TestTransport_RecordsSanitizedExchange(t *testing.T) {
+	rec := NewRecorder(10, 0)
+	client, base := newRecordingClient(t, rec, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		// The server still gets the unredacted request
+		assert.Contains(t, string(body), `"password":"hunter2"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte(`{"status_code":20000,"token":"t0k3n","keyword":"seo tools"}`))
+	})
+
+	req, err := http.NewRequest(http.MethodPost, base+"/v3/labs?key=s3cr3t&q=shoes", strings.NewReader(`{"login":"me","password":"hunter2"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Basic Zm9vOmJhcg==")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Contains(t, string(body), "t0k3n", "the caller still gets the unredacted response")
+
+	exchanges := rec.List("", 0)
+	require.Len(t, exchanges, 1)
+	e := exchanges[0]
+	assert.Equal(t, "test", e.Service)
+	assert.Equal(t, http.MethodPost, e.Method)
+	assert.Equal(t, http.StatusTeapot, e.Status)
+	u, err := url.Parse(e.URL)
+	require.NoError(t, err)
+	assert.Equal(t, redacted, u.Query().Get("key"))
+	assert.Equal(t, "shoes", u.Query().Get("q"))
+	assert.Equal(t, redacted, e.RequestHeaders["Authorization"])
+	assert.Equal(t, `{"login":"me","password":"[REDACTED]"}`, e.RequestBody)
+	assert.Equal(t, redacted, e.ResponseHeaders["Set-Cookie"])
+	assert.Equal(t, `{"status_code":20000,"token":"[REDACTED]","keyword":"seo tools"}`, e.ResponseBody)
+	assert.False(t, e.ResponseTruncated)
+}
+
+func TestTransport_RedactsFormFields(t *testing.T) {
+	rec := NewRecorder(10, 0)
+	client, base := newRecordingClient(t, rec, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	form := url.Values{"card[number]": {"4242424242424242"}, "email": {"a@example.com"}, "currency": {"usd"}}
+	resp, err := client.PostForm(base+"/v1/payment_methods", form)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	values, err := url.ParseQuery(rec.List("", 0)[0].RequestBody)
+	require.NoError(t, err)
+	assert.Equal(t, redacted, values.Get("card[number]"))
+	assert.Equal(t, redacted, values.Get("email"))
+	assert.Equal(t, "usd", values.Get("currency"))
+}
+
+func TestTransport_TruncatesAndDescribesBodies(t *testing.T) {
+	rec := NewRecorder(10, 16)
+	client, base := newRecordingClient(t, rec, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/zip" {
+			w.Header().Set("Content-Type", "application/zip")
+		} else {
+			w.Header().Set("Content-Type", "text/plain")
+		}
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	})
+
+	for _, path := range []string{"/text", "/zip"} {
+		resp, err := client.Get(base + path)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Len(t, body, 100)
+	}
+
+	exchanges := rec.List("", 0)
+	require.Len(t, exchanges, 2)
+	assert.Equal(t, "[application/zip body]", exchanges[0].ResponseBody)
+	assert.Equal(t, strings.Repeat("x", 16), exchanges[1].ResponseBody)
+	assert.True(t, exchanges[1].ResponseTruncated)
+}
+
+func TestTransport_PassesThroughWhenDisabled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	rec := NewRecorder(10, 0)
+	Enable(nil)
+
+	client := &http.Client{Transport: Transport("test", nil)}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Empty(t, rec.List("", 0))
+}
+
+func TestRecorder_RingBuffer(t *testing.T) {
+	rec := NewRecorder(3, 0)
+	for _, service := range []string{"a", "b", "a", "b", "a"} {
+		rec.add(Exchange{Service: service})
+	}
+
+	ids := func(exchanges []Exchange) []uint64 {
+		var ids []uint64
+		for _, e := range exchanges {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []uint64{5, 4, 3}, ids(rec.List("", 0)), "the oldest are dropped, newest first")
+	assert.Equal(t, []uint64{5, 3}, ids(rec.List("a", 0)))
+	assert.Equal(t, []uint64{5}, ids(rec.List("", 1)))
+
+	e, ok := rec.Get(4)
+	require.True(t, ok)
+	assert.Equal(t, "b", e.Service)
+	_, ok = rec.Get(1)
+	assert.False(t, ok)
+
+	rec.Clear()
+	assert.Empty(t, rec.List("", 0))
+}
@@ -0,0 +1,130 @@
+package httprecord
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveNames are header, query, form and JSON field names whose values are
+// never recorded: credentials, payment card details and contact details.
+// Form names are matched on their last bracketed segment, so Stripe's
+// "card[number]" matches "number".
+var sensitiveNames = map[string]bool{
+	"authorization":       true,
+	"proxy_authorization": true,
+	"cookie":              true,
+	"set_cookie":          true,
+	"key":                 true,
+	"api_key":             true,
+	"apikey":              true,
+	"x_api_key":           true,
+	"x_goog_api_key":      true,
+	"password":            true,
+	"secret":              true,
+	"client_secret":       true,
+	"token":               true,
+	"access_token":        true,
+	"refresh_token":       true,
+	"stripe_signature":    true,
+	"number":              true,
+	"cvc":                 true,
+	"cvv":                 true,
+	"exp_month":           true,
+	"exp_year":            true,
+	"email":               true,
+	"phone":               true,
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	if i := strings.LastIndex(name, "["); i >= 0 {
+		name = strings.TrimSuffix(name[i+1:], "]")
+	}
+	name = strings.ReplaceAll(name, "-", "_")
+	return sensitiveNames[name] || strings.Contains(name, "password") || strings.Contains(name, "secret")
+}
+
+func sanitizeURL(u *url.URL) string {
+	safe := *u
+	safe.User = nil
+	if safe.RawQuery != "" {
+		safe.RawQuery = sanitizeValues(safe.Query()).Encode()
+	}
+	return safe.String()
+}
+
+func sanitizeHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for name, values := range h {
+		if isSensitive(name) {
+			headers[name] = redacted
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+func sanitizeValues(values url.Values) url.Values {
+	for name := range values {
+		if isSensitive(name) {
+			values[name] = []string{redacted}
+		}
+	}
+	return values
+}
+
+// jsonField matches a JSON object member whose value is a string or scalar.
+// It works on truncated bodies, which can't be decoded.
+var jsonField = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)("(?:[^"\\]|\\.)*"|-?[0-9][0-9.eE+-]*|true|false|null)`)
+
+// sanitizeBody redacts sensitive fields from a JSON or form body and returns
+// at most limit bytes of it, and whether it was cut short. Bodies of other
+// types are described rather than recorded.
+func sanitizeBody(h http.Header, body []byte, limit int) (string, bool) {
+	if len(body) == 0 {
+		return "", false
+	}
+	truncated := len(body) > limit
+	if truncated {
+		body = body[:limit]
+	}
+	if enc := h.Get("Content-Encoding"); enc != "" && enc != "identity" {
+		return fmt.Sprintf("[%s-encoded body]", enc), truncated
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "[unparseable form body]", truncated
+		}
+		return sanitizeValues(values).Encode(), truncated
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || mediaType == "" && looksLikeJSON(body):
+		return jsonField.ReplaceAllStringFunc(string(body), func(field string) string {
+			m := jsonField.FindStringSubmatch(field)
+			if !isSensitive(m[1]) {
+				return field
+			}
+			return `"` + m[1] + `"` + m[2] + `"` + redacted + `"`
+		}), truncated
+	case strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "xml"):
+		return string(body), truncated
+	case mediaType == "":
+		return "[untyped body]", truncated
+	default:
+		return fmt.Sprintf("[%s body]", mediaType), truncated
+	}
+}
+
+func looksLikeJSON(body []byte) bool {
+	trimmed := strings.TrimSpace(string(body[:min(len(body), 64)]))
+	return strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")
+}
@@ -0,0 +1,34 @@
+package ids_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"app/pkg/ids"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_SortsInCreationOrder(t *testing.T) {
+	prev := ids.New()
+	for i := 0; i < 1000; i++ {
+		id := ids.New()
+		require.Equal(t, uuid.Version(7), id.Version())
+		require.Equal(t, 1, bytes.Compare(id[:], prev[:]), "%s should sort after %s", id, prev)
+		prev = id
+	}
+}
+
+func TestTime(t *testing.T) {
+	start := time.Now().Truncate(time.Millisecond)
+	created, ok := ids.Time(ids.New())
+	require.True(t, ok)
+	assert.False(t, created.Before(start))
+	assert.WithinDuration(t, start, created, time.Second)
+
+	_, ok = ids.Time(uuid.New())
+	assert.False(t, ok, "UUIDv4s carry no time")
+}
@@ -1,19 +1,38 @@
 package jina
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 )
 
-const readerBaseURL = "https://r.jina.ai/"
+const (
+	readerBaseURL = "https://r.jina.ai/"
+	searchBaseURL = "https://s.jina.ai/"
+	groundBaseURL = "https://g.jina.ai/"
+)
+
+// Default retry policy, matching the cfbrowser client: up to two retries on
+// 429 and 5xx, starting at one second and doubling.
+const (
+	defaultMaxRetries = 2
+	defaultBackoff    = 1 * time.Second
+)
 
-// Client communicates with the Jina Reader API.
+// Client communicates with the Jina Reader, Search and Grounding APIs, which
+// share an API key.
 type Client struct {
 	httpClient *http.Client
 	apiKey     string
+	readerURL  string
+	searchURL  string
+	groundURL  string
+	maxRetries int
+	backoff    time.Duration
 }
 
 // Option configures a Client.
@@ -33,10 +52,47 @@ func WithTimeout(d time.Duration) Option {
 	}
 }
 
-// NewClient creates a new Jina Reader client.
+// WithBaseURL points the Reader at another host, e.g. a self-hosted reader or
+// a test server. The target URL is appended to it, so it should end in "/".
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		c.readerURL = u
+	}
+}
+
+// WithSearchURL points Search at another host.
+func WithSearchURL(u string) Option {
+	return func(c *Client) {
+		c.searchURL = u
+	}
+}
+
+// WithGroundURL points Ground at another host.
+func WithGroundURL(u string) Option {
+	return func(c *Client) {
+		c.groundURL = u
+	}
+}
+
+// WithRetry retries requests that fail with 429 or 5xx up to retries times, waiting
+// backoff before the first retry and doubling it after each. A 429 with a
+// Retry-After header waits that long instead. WithRetry(0, 0) disables retries.
+func WithRetry(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = max(retries, 0)
+		c.backoff = backoff
+	}
+}
+
+// NewClient creates a new Jina client.
 func NewClient(opts ...Option) *Client {
 	c := &Client{
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		readerURL:  readerBaseURL,
+		searchURL:  searchBaseURL,
+		groundURL:  groundBaseURL,
+		maxRetries: defaultMaxRetries,
+		backoff:    defaultBackoff,
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -46,30 +102,105 @@ func NewClient(opts ...Option) *Client {
 
 // GetMarkdown converts a URL to clean markdown via Jina Reader.
 func (c *Client) GetMarkdown(ctx context.Context, targetURL string) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, readerBaseURL+targetURL, nil)
+	body, err := c.get(ctx, targetURL, map[string]string{"Accept": "text/markdown"})
 	if err != nil {
-		return "", fmt.Errorf("jina: create request: %w", err)
+		return "", err
 	}
+	return string(body), nil
+}
 
-	req.Header.Set("Accept", "text/markdown")
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	}
+// get fetches targetURL through the reader with the given request headers and
+// returns the response body.
+func (c *Client) get(ctx context.Context, targetURL string, headers map[string]string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, c.readerURL+targetURL, nil, headers)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("jina: execute request: %w", err)
-	}
-	defer resp.Body.Close()
+// do sends a request to a Jina endpoint and returns the response body,
+// retrying on 429 and 5xx. Any other status than 200 is an error.
+func (c *Client) do(ctx context.Context, method, endpoint string, payload []byte, headers map[string]string) ([]byte, error) {
+	var lastErr error
+	backoff := c.backoff
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("jina: read response: %w", err)
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+		if err != nil {
+			return nil, fmt.Errorf("jina: create request: %w", err)
+		}
+
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("jina: execute request: %w", err)
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("jina: read response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			return respBody, nil
+		}
+
+		lastErr = &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+
+		// Client error (4xx except 429) — don't retry.
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return nil, lastErr
+		}
+
+		if attempt == c.maxRetries {
+			break
+		}
+
+		// Rate limited — use Retry-After if present, else exponential backoff.
+		wait := backoff
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				wait = time.Duration(seconds) * time.Second
+			}
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, fmt.Errorf("jina: %w", err)
+		}
+		backoff *= 2
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("jina: unexpected status %d: %s", resp.StatusCode, string(body))
+	if c.maxRetries == 0 {
+		return nil, lastErr
 	}
+	return nil, fmt.Errorf("jina: max retries exceeded: %w", lastErr)
+}
 
-	return string(body), nil
+// StatusError is returned when Jina answers with a status other than 200.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("jina: unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// sleep waits for the given duration or until the context is cancelled.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -2,8 +2,10 @@ package jina
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -34,6 +36,16 @@ func TestNewClient_WithTimeout(t *testing.T) {
 	assert.Equal(t, 60*time.Second, c.httpClient.Timeout)
 }
 
+func TestNewClient_DefaultEndpointsAndRetry(t *testing.T) {
+	c := NewClient()
+
+	assert.Equal(t, "https://r.jina.ai/", c.readerURL)
+	assert.Equal(t, "https://s.jina.ai/", c.searchURL)
+	assert.Equal(t, "https://g.jina.ai/", c.groundURL)
+	assert.Equal(t, 2, c.maxRetries)
+	assert.Equal(t, time.Second, c.backoff)
+}
+
 // ---------------------------------------------------------------------------
 // GetMarkdown
 // ---------------------------------------------------------------------------
@@ -50,21 +62,7 @@ func TestGetMarkdown_Success(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	// Override the base URL by creating a client that talks to our test server.
-	// Since the jina client hardcodes readerBaseURL, we need to use a workaround:
-	// we create a client and replace its httpClient with one that redirects to our server.
-	client := &Client{
-		httpClient: srv.Client(),
-	}
-
-	// The Jina client constructs URL as readerBaseURL + targetURL.
-	// With httptest, we need to point requests to srv.URL instead.
-	// Simplest approach: replace the httpClient transport to rewrite the host.
-	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
-		req.URL.Scheme = "http"
-		req.URL.Host = srv.Listener.Addr().String()
-		return http.DefaultTransport.RoundTrip(req)
-	})
+	client := newTestClient(srv)
 
 	result, err := client.GetMarkdown(context.Background(), "http://example.com")
 
@@ -83,15 +81,7 @@ func TestGetMarkdown_WithAPIKey(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	client := &Client{
-		httpClient: srv.Client(),
-		apiKey:     "my-secret-key",
-	}
-	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
-		req.URL.Scheme = "http"
-		req.URL.Host = srv.Listener.Addr().String()
-		return http.DefaultTransport.RoundTrip(req)
-	})
+	client := newTestClient(srv, WithAPIKey("my-secret-key"))
 
 	result, err := client.GetMarkdown(context.Background(), "http://example.com")
 
@@ -106,12 +96,7 @@ func TestGetMarkdown_ServerError(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	client := &Client{httpClient: srv.Client()}
-	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
-		req.URL.Scheme = "http"
-		req.URL.Host = srv.Listener.Addr().String()
-		return http.DefaultTransport.RoundTrip(req)
-	})
+	client := newTestClient(srv)
 
 	_, err := client.GetMarkdown(context.Background(), "http://example.com")
 
@@ -127,18 +112,17 @@ func TestGetMarkdown_NotFound(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	client := &Client{httpClient: srv.Client()}
-	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
-		req.URL.Scheme = "http"
-		req.URL.Host = srv.Listener.Addr().String()
-		return http.DefaultTransport.RoundTrip(req)
-	})
+	client := newTestClient(srv)
 
 	_, err := client.GetMarkdown(context.Background(), "http://example.com/missing")
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "unexpected status 404")
 	assert.Contains(t, err.Error(), "page not found")
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
 }
 
 func TestGetMarkdown_ContextCancelled(t *testing.T) {
@@ -148,12 +132,7 @@ func TestGetMarkdown_ContextCancelled(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	client := &Client{httpClient: srv.Client()}
-	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
-		req.URL.Scheme = "http"
-		req.URL.Host = srv.Listener.Addr().String()
-		return http.DefaultTransport.RoundTrip(req)
-	})
+	client := newTestClient(srv)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately.
@@ -171,12 +150,7 @@ func TestGetMarkdown_EmptyResponse(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	client := &Client{httpClient: srv.Client()}
-	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
-		req.URL.Scheme = "http"
-		req.URL.Host = srv.Listener.Addr().String()
-		return http.DefaultTransport.RoundTrip(req)
-	})
+	client := newTestClient(srv)
 
 	result, err := client.GetMarkdown(context.Background(), "http://example.com")
 
@@ -185,12 +159,311 @@ func TestGetMarkdown_EmptyResponse(t *testing.T) {
 }
 
 // ---------------------------------------------------------------------------
-// Helper: roundTripFunc lets us use a function as an http.RoundTripper to
-// redirect requests from the hardcoded Jina base URL to our test server.
+// Read
 // ---------------------------------------------------------------------------
 
-type roundTripFunc func(*http.Request) (*http.Response, error)
+func TestRead_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		assert.Equal(t, "article", r.Header.Get("X-Target-Selector"))
+		assert.Equal(t, "#content", r.Header.Get("X-Wait-For-Selector"))
+		assert.Equal(t, "text", r.Header.Get("X-Respond-With"))
+		assert.Equal(t, "true", r.Header.Get("X-With-Links-Summary"))
+		assert.Equal(t, "true", r.Header.Get("X-With-Images-Summary"))
+
+		w.Write([]byte(`{"code":200,"status":20000,"data":{
+			"title":"Hello","description":"A greeting","url":"http://example.com/",
+			"publishedTime":"2025-03-01T09:00:00Z","content":"Hello world",
+			"links":{"Zeta":"http://example.com/z","About":"http://example.com/about"},
+			"images":{"Logo":"http://example.com/logo.png"},
+			"usage":{"tokens":42}}}`))
+	}))
+	defer srv.Close()
+
+	page, err := newTestClient(srv).Read(context.Background(), "http://example.com", ReadOptions{
+		TargetSelector:  "article",
+		WaitForSelector: "#content",
+		RespondWith:     RespondText,
+		WithLinks:       true,
+		WithImages:      true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", page.Title)
+	assert.Equal(t, "A greeting", page.Description)
+	assert.Equal(t, "http://example.com/", page.URL)
+	assert.Equal(t, "2025-03-01T09:00:00Z", page.PublishedTime)
+	assert.Equal(t, "Hello world", page.Content)
+	// Page order is kept, not sorted
+	assert.Equal(t, []Link{
+		{Text: "Zeta", URL: "http://example.com/z"},
+		{Text: "About", URL: "http://example.com/about"},
+	}, page.Links)
+	assert.Equal(t, []Image{{Alt: "Logo", URL: "http://example.com/logo.png"}}, page.Images)
+	assert.Equal(t, 42, page.Tokens)
+}
+
+func TestRead_PairSummaries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Options left unset send no tuning headers
+		assert.Empty(t, r.Header.Get("X-Target-Selector"))
+		assert.Empty(t, r.Header.Get("X-Respond-With"))
+
+		w.Write([]byte(`{"data":{"content":"Hi","links":[["Home","http://example.com/"],["Home","http://example.com/#top"]]}}`))
+	}))
+	defer srv.Close()
 
-func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
-	return f(req)
+	page, err := newTestClient(srv).Read(context.Background(), "http://example.com", ReadOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Link{
+		{Text: "Home", URL: "http://example.com/"},
+		{Text: "Home", URL: "http://example.com/#top"},
+	}, page.Links)
+	assert.Empty(t, page.Images)
+}
+
+func TestRead_InvalidRespondWith(t *testing.T) {
+	_, err := NewClient().Read(context.Background(), "http://example.com", ReadOptions{RespondWith: "pdf"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported RespondWith")
+}
+
+func TestRead_InvalidJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# Not JSON"))
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv).Read(context.Background(), "http://example.com", ReadOptions{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "decode response")
+}
+
+// ---------------------------------------------------------------------------
+// Search
+// ---------------------------------------------------------------------------
+
+func TestSearch_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "h5p branching scenario", r.URL.Query().Get("q"))
+		assert.Equal(t, "2", r.URL.Query().Get("num"))
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		assert.Equal(t, "h5p.org", r.Header.Get("X-Site"))
+		assert.Equal(t, "no-content", r.Header.Get("X-Respond-With"))
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+
+		w.Write([]byte(`{"code":200,"status":20000,"data":[
+			{"title":"Branching Scenario","url":"https://h5p.org/branching-scenario","description":"Create dilemmas","usage":{"tokens":10}},
+			{"title":"Tutorial","url":"https://h5p.org/tutorial","description":"Step by step","date":"Mar 1, 2025","usage":{"tokens":8}}]}`))
+	}))
+	defer srv.Close()
+
+	results, err := newTestClient(srv, WithAPIKey("key")).Search(context.Background(), "h5p branching scenario", SearchOptions{
+		Site:         "h5p.org",
+		Count:        2,
+		SnippetsOnly: true,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	// Rank order is kept
+	assert.Equal(t, SearchResult{
+		Title:       "Branching Scenario",
+		URL:         "https://h5p.org/branching-scenario",
+		Description: "Create dilemmas",
+		Tokens:      10,
+	}, results[0])
+	assert.Equal(t, "Mar 1, 2025", results[1].Date)
+}
+
+func TestSearch_Defaults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.False(t, r.URL.Query().Has("num"))
+		assert.Empty(t, r.Header.Get("X-Site"))
+		assert.Empty(t, r.Header.Get("X-Respond-With"))
+
+		w.Write([]byte(`{"data":[{"title":"A","url":"https://a.example","content":"# A"}]}`))
+	}))
+	defer srv.Close()
+
+	results, err := newTestClient(srv).Search(context.Background(), "a", SearchOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "# A", results[0].Content)
+}
+
+func TestSearch_InvalidOptions(t *testing.T) {
+	_, err := NewClient().Search(context.Background(), "", SearchOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "query is required")
+
+	_, err = NewClient().Search(context.Background(), "a", SearchOptions{Count: -1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid Count")
+}
+
+// ---------------------------------------------------------------------------
+// Ground
+// ---------------------------------------------------------------------------
+
+func TestGround_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "H5P is open source", body["statement"])
+
+		w.Write([]byte(`{"code":200,"status":20000,"data":{
+			"factuality":0.95,"result":true,"reason":"Multiple sources confirm it.",
+			"references":[
+				{"url":"https://h5p.org/","keyQuote":"H5P is a free and open-source content collaboration framework","isSupportive":true},
+				{"url":"https://example.com/","keyQuote":"H5P is proprietary","isSupportive":false}],
+			"usage":{"tokens":12000}}}`))
+	}))
+	defer srv.Close()
+
+	grounding, err := newTestClient(srv).Ground(context.Background(), "H5P is open source")
+
+	require.NoError(t, err)
+	assert.InDelta(t, 0.95, grounding.Factuality, 1e-9)
+	assert.True(t, grounding.Supported)
+	assert.Equal(t, "Multiple sources confirm it.", grounding.Reason)
+	assert.Equal(t, []Reference{
+		{URL: "https://h5p.org/", KeyQuote: "H5P is a free and open-source content collaboration framework", Supportive: true},
+		{URL: "https://example.com/", KeyQuote: "H5P is proprietary", Supportive: false},
+	}, grounding.References)
+	assert.Equal(t, 12000, grounding.Tokens)
+}
+
+func TestGround_EmptyStatement(t *testing.T) {
+	_, err := NewClient().Ground(context.Background(), "")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "statement is required")
+}
+
+func TestGround_ServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		w.Write([]byte("insufficient balance"))
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv).Ground(context.Background(), "anything")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 402")
+}
+
+// ---------------------------------------------------------------------------
+// Retry
+// ---------------------------------------------------------------------------
+
+func TestRetry_ServerErrorThenSuccess(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("# Recovered"))
+	}))
+	defer srv.Close()
+
+	result, err := newTestClient(srv, WithRetry(2, time.Millisecond)).GetMarkdown(context.Background(), "http://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, "# Recovered", result)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRetry_RateLimitedRetriesPOSTBody(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The statement must be resent on every attempt
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "claim", body["statement"])
+
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"data":{"factuality":1,"result":true}}`))
+	}))
+	defer srv.Close()
+
+	grounding, err := newTestClient(srv, WithRetry(1, time.Millisecond)).Ground(context.Background(), "claim")
+
+	require.NoError(t, err)
+	assert.True(t, grounding.Supported)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRetry_MaxRetriesExceeded(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("down"))
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv, WithRetry(2, time.Millisecond)).GetMarkdown(context.Background(), "http://example.com")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max retries exceeded")
+	assert.Contains(t, err.Error(), "unexpected status 503")
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRetry_ClientErrorNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv, WithRetry(2, time.Millisecond)).GetMarkdown(context.Background(), "http://example.com")
+
+	require.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRetry_ContextCancelledDuringBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := newTestClient(srv, WithRetry(2, time.Millisecond)).GetMarkdown(ctx, "http://example.com")
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// ---------------------------------------------------------------------------
+// Helper: newTestClient points every endpoint at a test server, without
+// retries unless the test opts in.
+// ---------------------------------------------------------------------------
+
+func newTestClient(srv *httptest.Server, opts ...Option) *Client {
+	base := []Option{
+		WithBaseURL(srv.URL + "/"),
+		WithSearchURL(srv.URL + "/"),
+		WithGroundURL(srv.URL + "/"),
+		WithRetry(0, 0),
+	}
+	return NewClient(append(base, opts...)...)
 }
@@ -0,0 +1,88 @@
+package jina
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Grounding is Jina's verdict on a statement, with the sources it checked.
+type Grounding struct {
+	// Factuality is a 0–1 score of how well the sources support the statement.
+	Factuality float64
+	// Supported is Jina's overall true/false verdict.
+	Supported  bool
+	Reason     string
+	References []Reference
+	Tokens     int // tokens the check consumed from the API key's quota
+}
+
+// Reference is a source quoted while grounding a statement.
+type Reference struct {
+	URL      string
+	KeyQuote string
+	// Supportive is false when the quote contradicts the statement.
+	Supportive bool
+}
+
+// groundResponse is the Grounding API's JSON envelope.
+type groundResponse struct {
+	Data struct {
+		Factuality float64 `json:"factuality"`
+		Result     bool    `json:"result"`
+		Reason     string  `json:"reason"`
+		References []struct {
+			URL          string `json:"url"`
+			KeyQuote     string `json:"keyQuote"`
+			IsSupportive bool   `json:"isSupportive"`
+		} `json:"references"`
+		Usage struct {
+			Tokens int `json:"tokens"`
+		} `json:"usage"`
+	} `json:"data"`
+}
+
+// Ground fact-checks a statement against web sources via Jina Grounding,
+// returning a verdict and the references to cite. A check searches and reads
+// several pages, so it can take well over the default 30s timeout; callers
+// should use WithTimeout or a context deadline sized for it.
+func (c *Client) Ground(ctx context.Context, statement string) (*Grounding, error) {
+	if statement == "" {
+		return nil, errors.New("jina: statement is required")
+	}
+
+	payload, err := json.Marshal(map[string]string{"statement": statement})
+	if err != nil {
+		return nil, fmt.Errorf("jina: encode request: %w", err)
+	}
+	body, err := c.do(ctx, http.MethodPost, c.groundURL, payload, map[string]string{
+		"Accept":       "application/json",
+		"Content-Type": "application/json",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp groundResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("jina: decode response: %w", err)
+	}
+
+	d := resp.Data
+	grounding := &Grounding{
+		Factuality: d.Factuality,
+		Supported:  d.Result,
+		Reason:     d.Reason,
+		Tokens:     d.Usage.Tokens,
+	}
+	for _, ref := range d.References {
+		grounding.References = append(grounding.References, Reference{
+			URL:        ref.URL,
+			KeyQuote:   ref.KeyQuote,
+			Supportive: ref.IsSupportive,
+		})
+	}
+	return grounding, nil
+}
@@ -0,0 +1,92 @@
+package jina
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// SearchOptions narrows a web search. The zero value returns Jina's default
+// number of results, each with the full page content as markdown.
+type SearchOptions struct {
+	// Site restricts results to one domain, e.g. "docs.h5p.org".
+	Site string
+	// Count is the number of results to return. Zero uses the API default.
+	Count int
+	// SnippetsOnly skips reading each result page, so Content is empty and
+	// only the search snippet in Description is returned. Much cheaper.
+	SnippetsOnly bool
+}
+
+// SearchResult is one ranked search hit.
+type SearchResult struct {
+	Title       string
+	URL         string
+	Description string // the search engine's snippet
+	Content     string // the result page as markdown; empty with SnippetsOnly
+	Date        string // publish date when known; the format varies by site
+	Tokens      int    // tokens this result consumed from the API key's quota
+}
+
+// searchResponse is the Search API's JSON envelope.
+type searchResponse struct {
+	Data []struct {
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Description string `json:"description"`
+		Content     string `json:"content"`
+		Date        string `json:"date"`
+		Usage       struct {
+			Tokens int `json:"tokens"`
+		} `json:"usage"`
+	} `json:"data"`
+}
+
+// Search runs a web search via Jina Search and returns the results in rank order.
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	if query == "" {
+		return nil, errors.New("jina: search query is required")
+	}
+	if opts.Count < 0 {
+		return nil, fmt.Errorf("jina: invalid Count %d", opts.Count)
+	}
+
+	params := url.Values{"q": {query}}
+	if opts.Count > 0 {
+		params.Set("num", strconv.Itoa(opts.Count))
+	}
+	headers := map[string]string{"Accept": "application/json"}
+	if opts.Site != "" {
+		headers["X-Site"] = opts.Site
+	}
+	if opts.SnippetsOnly {
+		headers["X-Respond-With"] = "no-content"
+	}
+
+	body, err := c.do(ctx, http.MethodGet, c.searchURL+"?"+params.Encode(), nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp searchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("jina: decode response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(resp.Data))
+	for _, d := range resp.Data {
+		results = append(results, SearchResult{
+			Title:       d.Title,
+			URL:         d.URL,
+			Description: d.Description,
+			Content:     d.Content,
+			Date:        d.Date,
+			Tokens:      d.Usage.Tokens,
+		})
+	}
+	return results, nil
+}
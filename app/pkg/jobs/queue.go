@@ -0,0 +1,468 @@
+// Package jobs is a durable background work queue backed by a Postgres table.
+//
+// Work that used to run in a fire-and-forget goroutine is enqueued as a job
+// instead, so it survives restarts. A worker leases a job while it runs and
+// keeps renewing the lease; a job whose worker died is picked up again once
+// its lease runs out. Failed jobs are retried with exponential backoff until
+// their attempts run out, then kept as dead jobs for inspection and a manual
+// retry.
+package jobs
+
+import (
+	"app/pkg/ids"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Job statuses
+const (
+	StatusPending   = "pending"   // waiting for run_at
+	StatusRunning   = "running"   // leased by a worker
+	StatusCompleted = "completed" // done; pruned after a while
+	StatusDead      = "dead"      // out of attempts, waiting for a manual retry
+)
+
+const (
+	// DefaultMaxAttempts is how often a job is tried unless its kind says otherwise
+	DefaultMaxAttempts = 5
+	// DefaultTimeout bounds one attempt unless its kind says otherwise
+	DefaultTimeout = 10 * time.Minute
+
+	// lease is how long a worker holds a job without renewing it
+	lease = time.Minute
+	// pollInterval is how often idle workers look for due jobs. Jobs enqueued
+	// on this instance wake them straight away.
+	pollInterval = 5 * time.Second
+	// retryBackoff is the wait after the first failed attempt. It doubles with
+	// each further failure, up to maxRetryBackoff.
+	retryBackoff    = 30 * time.Second
+	maxRetryBackoff = time.Hour
+	// completedRetention is how long completed jobs are kept
+	completedRetention = 7 * 24 * time.Hour
+	pruneInterval      = time.Hour
+)
+
+// ErrNotFound is returned by Retry when there's no dead job with the ID.
+var ErrNotFound = errors.New("jobs: no dead job with that ID")
+
+// Handler runs one attempt of a job. The payload is what Enqueue was given,
+// JSON encoded. Returning an error retries the job later; ctx is cancelled
+// when the attempt times out or the queue stops.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+type kind struct {
+	handler     Handler
+	maxAttempts int
+	timeout     time.Duration
+}
+
+// KindOption configures a registered kind of job.
+type KindOption func(*kind)
+
+// MaxAttempts sets how often a job of the kind is tried before it's dead.
+// Use 1 for work that must not be repeated.
+func MaxAttempts(n int) KindOption {
+	return func(k *kind) { k.maxAttempts = max(n, 1) }
+}
+
+// Timeout bounds one attempt of a job of the kind.
+func Timeout(d time.Duration) KindOption {
+	return func(k *kind) { k.timeout = d }
+}
+
+// EnqueueOption configures one enqueued job.
+type EnqueueOption func(*enqueueOptions)
+
+type enqueueOptions struct {
+	delay time.Duration
+}
+
+// Delay holds the job back for d before its first attempt.
+func Delay(d time.Duration) EnqueueOption {
+	return func(o *enqueueOptions) { o.delay = d }
+}
+
+// Job is a queued job as listed for inspection.
+type Job struct {
+	ID          uuid.UUID       `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	LastError   string          `json:"lastError,omitempty"`
+	RunAt       time.Time       `json:"runAt"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	CompletedAt *time.Time      `json:"completedAt"`
+}
+
+// Queue enqueues jobs and runs the kinds registered with it. Register every
+// kind before Start.
+type Queue struct {
+	db    *sql.DB
+	kinds map[string]kind
+	names []string
+	wake  chan struct{} // nudges an idle worker when a job is enqueued
+
+	quit      chan struct{} // closed by Stop; workers claim no more jobs
+	stopOnce  sync.Once
+	runCtx    context.Context // cancelled when Stop gives up waiting
+	cancelRun context.CancelFunc
+	workers   sync.WaitGroup
+}
+
+// New returns a queue storing its jobs in db's jobs table.
+func New(db *sql.DB) *Queue {
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	return &Queue{
+		db:        db,
+		kinds:     make(map[string]kind),
+		wake:      make(chan struct{}, 1),
+		quit:      make(chan struct{}),
+		runCtx:    runCtx,
+		cancelRun: cancelRun,
+	}
+}
+
+// Register sets the handler for a kind of job. Registering a kind twice
+// panics.
+func (q *Queue) Register(name string, handler Handler, opts ...KindOption) {
+	if _, ok := q.kinds[name]; ok {
+		panic("jobs: kind registered twice: " + name)
+	}
+	k := kind{handler: handler, maxAttempts: DefaultMaxAttempts, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(&k)
+	}
+	q.kinds[name] = k
+	q.names = append(q.names, name)
+}
+
+// Enqueue stores a job of a registered kind and returns its ID. The payload
+// is encoded as JSON for the handler.
+func (q *Queue) Enqueue(ctx context.Context, name string, payload any, opts ...EnqueueOption) (uuid.UUID, error) {
+	k, ok := q.kinds[name]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("jobs: unknown kind %q", name)
+	}
+	var o enqueueOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("jobs: encoding %s payload: %w", name, err)
+	}
+
+	id := ids.New()
+	_, err = q.db.ExecContext(ctx, `INSERT INTO jobs (id, kind, payload, max_attempts, run_at)
+		VALUES ($1, $2, $3, $4, now() + $5 * interval '1 millisecond')`,
+		id, name, body, k.maxAttempts, o.delay.Milliseconds())
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("jobs: enqueueing %s: %w", name, err)
+	}
+	if o.delay == 0 {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+	return id, nil
+}
+
+// Start runs n workers until Stop. Each works through due jobs one at a time.
+func (q *Queue) Start(n int) {
+	for range n {
+		q.workers.Add(1)
+		go func() {
+			defer q.workers.Done()
+			q.work()
+		}()
+	}
+	q.workers.Add(1)
+	go func() {
+		defer q.workers.Done()
+		q.prune()
+	}()
+	slog.Info("Job workers started", "workers", n, "kinds", q.names)
+}
+
+// Stop stops claiming jobs and waits for the running ones until ctx is done.
+// Jobs still running then are cancelled and released to run again without
+// using up an attempt.
+func (q *Queue) Stop(ctx context.Context) {
+	q.stopOnce.Do(func() { close(q.quit) })
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		q.cancelRun()
+		<-done
+	}
+	q.cancelRun()
+}
+
+func (q *Queue) work() {
+	timer := time.NewTimer(pollInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-q.quit:
+			return
+		default:
+		}
+
+		job, err := q.claim()
+		if err != nil {
+			slog.Error("Error claiming job", "error", err)
+		}
+		if job != nil {
+			q.run(job)
+			continue
+		}
+
+		timer.Reset(pollInterval)
+		select {
+		case <-q.quit:
+			return
+		case <-q.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// claimed is a job leased by this worker.
+type claimed struct {
+	id          uuid.UUID
+	kind        string
+	payload     json.RawMessage
+	attempts    int // including this one
+	maxAttempts int
+}
+
+// claim leases the next due job of a registered kind, or a running one whose
+// worker stopped renewing its lease. It returns nil when there's none.
+func (q *Queue) claim() (*claimed, error) {
+	ctx, cancel := context.WithTimeout(q.runCtx, 10*time.Second)
+	defer cancel()
+
+	for {
+		var j claimed
+		err := q.db.QueryRowContext(ctx, `UPDATE jobs
+			SET status = 'running', attempts = attempts + 1, locked_until = now() + $2 * interval '1 millisecond', updated_at = now()
+			WHERE id = (
+				SELECT id FROM jobs
+				WHERE kind = ANY($1)
+					AND (status = 'pending' AND run_at <= now() OR status = 'running' AND locked_until < now())
+				ORDER BY run_at
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, kind, payload, attempts, max_attempts`,
+			pq.Array(q.names), lease.Milliseconds()).Scan(&j.id, &j.kind, &j.payload, &j.attempts, &j.maxAttempts)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if j.attempts <= j.maxAttempts {
+			return &j, nil
+		}
+		// Its worker died during the last attempt, e.g. the job crashes the
+		// process; don't let it take the next worker down too
+		q.finish(&j, StatusDead, errors.New("lease expired during the last attempt"))
+	}
+}
+
+// run makes one attempt at a job, renewing its lease until it returns, and
+// records the outcome.
+func (q *Queue) run(j *claimed) {
+	k := q.kinds[j.kind]
+	ctx, cancel := context.WithTimeout(q.runCtx, k.timeout)
+	defer cancel()
+
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		q.renew(ctx, j.id)
+	}()
+	started := time.Now()
+	err := call(ctx, k.handler, j.payload)
+	cancel()
+	<-renewed
+
+	switch {
+	case err == nil:
+		q.finish(j, StatusCompleted, nil)
+		slog.Debug("Job completed", "job_id", j.id, "kind", j.kind, "duration", time.Since(started))
+	case q.runCtx.Err() != nil:
+		// Stopped by shutdown, not the job's fault
+		q.release(j)
+	case j.attempts >= j.maxAttempts:
+		slog.Error("Job failed for good", "job_id", j.id, "kind", j.kind, "attempts", j.attempts, "error", err)
+		q.finish(j, StatusDead, err)
+	default:
+		slog.Warn("Job failed, retrying", "job_id", j.id, "kind", j.kind, "attempt", j.attempts,
+			"retry_in", backoff(j.attempts), "error", err)
+		q.finish(j, StatusPending, err)
+	}
+}
+
+// call runs the handler, turning a panic into an error so one bad job doesn't
+// take the worker down.
+func call(ctx context.Context, h Handler, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return h(ctx, payload)
+}
+
+// renew extends the job's lease until ctx is done.
+func (q *Queue) renew(ctx context.Context, id uuid.UUID) {
+	ticker := time.NewTicker(lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := q.db.ExecContext(ctx, `UPDATE jobs SET locked_until = now() + $2 * interval '1 millisecond'
+				WHERE id = $1 AND status = 'running'`, id, lease.Milliseconds())
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("Error renewing job lease", "job_id", id, "error", err)
+			}
+		}
+	}
+}
+
+// finish records the outcome of an attempt. A pending job is retried after
+// the backoff for its attempts so far.
+func (q *Queue) finish(j *claimed, status string, cause error) {
+	lastError := ""
+	if cause != nil {
+		lastError = cause.Error()
+	}
+	_, err := q.db.ExecContext(context.Background(), `UPDATE jobs
+		SET status = $2, last_error = $3, locked_until = NULL, updated_at = now(),
+			run_at = CASE WHEN $2 = 'pending' THEN now() + $4 * interval '1 millisecond' ELSE run_at END,
+			completed_at = CASE WHEN $2 = 'completed' THEN now() END
+		WHERE id = $1`, j.id, status, lastError, backoff(j.attempts).Milliseconds())
+	if err != nil {
+		slog.Error("Error recording job outcome", "job_id", j.id, "kind", j.kind, "status", status, "error", err)
+	}
+}
+
+// release hands a job interrupted by shutdown back to the queue, giving back
+// the attempt.
+func (q *Queue) release(j *claimed) {
+	_, err := q.db.ExecContext(context.Background(), `UPDATE jobs
+		SET status = 'pending', attempts = attempts - 1, locked_until = NULL, run_at = now(), updated_at = now()
+		WHERE id = $1`, j.id)
+	if err != nil {
+		slog.Error("Error releasing job", "job_id", j.id, "kind", j.kind, "error", err)
+	}
+}
+
+// backoff returns how long to wait before retrying a job that has failed
+// attempts times.
+func backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return retryBackoff
+	}
+	d := retryBackoff << (attempts - 1)
+	if d <= 0 || d > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return d
+}
+
+// prune deletes completed jobs past their retention until the queue stops.
+func (q *Queue) prune() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.quit:
+			return
+		case <-ticker.C:
+		}
+		res, err := q.db.ExecContext(q.runCtx, `DELETE FROM jobs WHERE status = 'completed' AND completed_at < $1`,
+			time.Now().Add(-completedRetention))
+		if err != nil {
+			slog.Error("Error pruning completed jobs", "error", err)
+			continue
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			slog.Info("Pruned completed jobs", "count", n)
+		}
+	}
+}
+
+// List returns jobs newest first, optionally only those with status and of
+// one kind. Job IDs are time-ordered, so the next page starts before the last
+// ID of this one; uuid.Nil starts from the newest job.
+func (q *Queue) List(ctx context.Context, status, kind string, before uuid.UUID, limit int) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT id, kind, payload, status, attempts, max_attempts, last_error, run_at, created_at, completed_at
+		FROM jobs
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR kind = $2) AND ($3::uuid IS NULL OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4`, status, kind, uuid.NullUUID{UUID: before, Valid: before != uuid.Nil}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: listing: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Job{}
+	for rows.Next() {
+		var j Job
+		var completedAt sql.NullTime
+		if err := rows.Scan(&j.ID, &j.Kind, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.LastError,
+			&j.RunAt, &j.CreatedAt, &completedAt); err != nil {
+			return nil, fmt.Errorf("jobs: listing: %w", err)
+		}
+		if completedAt.Valid {
+			j.CompletedAt = &completedAt.Time
+		}
+		result = append(result, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("jobs: listing: %w", err)
+	}
+	return result, nil
+}
+
+// Retry gives a dead job a fresh set of attempts, starting now.
+func (q *Queue) Retry(ctx context.Context, id uuid.UUID) error {
+	res, err := q.db.ExecContext(ctx, `UPDATE jobs
+		SET status = 'pending', attempts = 0, run_at = now(), updated_at = now()
+		WHERE id = $1 AND status = 'dead'`, id)
+	if err != nil {
+		return fmt.Errorf("jobs: retrying: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
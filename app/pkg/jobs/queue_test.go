@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_DoublesUpToCap(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(1))
+	assert.Equal(t, time.Minute, backoff(2))
+	assert.Equal(t, 4*time.Minute, backoff(4))
+	assert.Equal(t, maxRetryBackoff, backoff(10))
+	// Shifting far enough overflows; it must still be capped
+	assert.Equal(t, maxRetryBackoff, backoff(80))
+}
+
+func TestCall_RecoversPanics(t *testing.T) {
+	err := call(context.Background(), func(context.Context, json.RawMessage) error {
+		panic("boom")
+	}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "panic: boom")
+
+	want := errors.New("failed")
+	err = call(context.Background(), func(context.Context, json.RawMessage) error { return want }, nil)
+	assert.ErrorIs(t, err, want)
+}
+
+func TestRegister_Options(t *testing.T) {
+	q := New(nil)
+	q.Register("a", nil)
+	q.Register("b", nil, MaxAttempts(0), Timeout(time.Second))
+
+	assert.Equal(t, DefaultMaxAttempts, q.kinds["a"].maxAttempts)
+	assert.Equal(t, DefaultTimeout, q.kinds["a"].timeout)
+	assert.Equal(t, 1, q.kinds["b"].maxAttempts)
+	assert.Equal(t, time.Second, q.kinds["b"].timeout)
+	assert.Panics(t, func() { q.Register("a", nil) })
+}
+
+func TestEnqueue_UnknownKind(t *testing.T) {
+	q := New(nil)
+	_, err := q.Enqueue(context.Background(), "missing", nil)
+	assert.ErrorContains(t, err, `unknown kind "missing"`)
+}
@@ -0,0 +1,144 @@
+package pagespeed
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL is how long RunBatch reuses a result. Lighthouse scores
+// vary from run to run anyway, so a recent result is as good as a new one.
+const defaultCacheTTL = 15 * time.Minute
+
+// BatchResult is one URL of a batch. A URL that failed has a nil result and
+// its error message set.
+type BatchResult struct {
+	URL    string  `json:"url"`
+	Result *Result `json:"result"`
+	Error  string  `json:"error,omitempty"`
+	Cached bool    `json:"cached"` // served from the cache rather than a new run
+}
+
+// WithBatchWorkers sets how many runs RunBatch makes at once. Runs still share
+// the client's concurrency limit and quota. Default: 4.
+func WithBatchWorkers(n int) Option {
+	return func(c *Client) {
+		c.workers = max(n, 1)
+	}
+}
+
+// WithCacheTTL sets how long results are reused by RunBatch. Zero or less
+// turns the cache off. Default: 15 minutes.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cache = newResultCache(ttl)
+	}
+}
+
+// RunBatch runs every URL with one strategy over the client's worker pool,
+// serving results from the last TTL from the cache. A URL that fails doesn't
+// stop the others; its error is reported in its result. Results are in the
+// order of urls, and a URL listed twice is run once. When ctx ends, the URLs
+// not yet run report its error.
+func (c *Client) RunBatch(ctx context.Context, urls []string, strategy string) []BatchResult {
+	if strategy == "" {
+		strategy = StrategyMobile
+	}
+	results := make([]BatchResult, len(urls))
+	pending := make(map[string][]int) // URL to its positions in urls
+	var queue []string
+	for i, u := range urls {
+		results[i].URL = u
+		if r, ok := c.cache.get(u, strategy); ok {
+			results[i].Result, results[i].Cached = r, true
+			continue
+		}
+		if _, queued := pending[u]; !queued {
+			queue = append(queue, u)
+		}
+		pending[u] = append(pending[u], i)
+	}
+
+	jobs := make(chan string)
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for range min(c.workers, len(queue)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				r, err := c.Run(ctx, u, strategy)
+				mu.Lock()
+				for _, i := range pending[u] {
+					results[i].Result = r
+					if err != nil {
+						results[i].Error = err.Error()
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, u := range queue {
+		jobs <- u
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// resultCache holds recent results by URL and strategy. A nil cache holds
+// nothing.
+type resultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[cacheKey]cachedResult
+}
+
+type cacheKey struct {
+	url, strategy string
+}
+
+type cachedResult struct {
+	result  *Result
+	expires time.Time
+}
+
+func newResultCache(ttl time.Duration) *resultCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &resultCache{ttl: ttl, entries: make(map[cacheKey]cachedResult)}
+}
+
+func (rc *resultCache) get(url, strategy string) (*Result, bool) {
+	if rc == nil {
+		return nil, false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, ok := rc.entries[cacheKey{url, strategy}]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.result, true
+}
+
+// put stores a result and drops the expired ones, so the cache only grows
+// with the URLs run within a TTL.
+func (rc *resultCache) put(url, strategy string, r *Result) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	now := time.Now()
+	for k, e := range rc.entries {
+		if now.After(e.expires) {
+			delete(rc.entries, k)
+		}
+	}
+	rc.entries[cacheKey{url, strategy}] = cachedResult{result: r, expires: now.Add(rc.ttl)}
+}
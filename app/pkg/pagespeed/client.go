@@ -19,25 +19,80 @@ const apiBaseURL = "https://www.googleapis.com/pagespeedonline/v5/runPagespeed"
 // Client calls the Google PageSpeed Insights API.
 type Client struct {
 	apiKey     string
+	baseURL    string
 	httpClient *http.Client
+	sem        chan struct{}
+	limiter    *rateLimiter // nil when uncapped
+	cache      *resultCache
+	workers    int // RunBatch's worker pool size
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL points the client at another host, e.g. a test server.
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		c.baseURL = u
+	}
+}
+
+// WithMaxConcurrent sets the maximum number of concurrent runs. Default: 4.
+func WithMaxConcurrent(n int) Option {
+	return func(c *Client) {
+		c.sem = make(chan struct{}, n)
+	}
+}
+
+// WithTransport sets the HTTP transport, e.g. to record calls for debugging.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = rt
+	}
 }
 
 // NewClient creates a new PageSpeed client with the given API key.
-func NewClient(apiKey string) *Client {
-	return &Client{
-		apiKey: apiKey,
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		apiKey:  apiKey,
+		baseURL: apiBaseURL,
 		httpClient: &http.Client{
 			Timeout: 90 * time.Second, // PageSpeed can take a while
 		},
+		sem:     make(chan struct{}, 4),
+		limiter: newRateLimiter(DefaultQueriesPerMinute / 60.0),
+		cache:   newResultCache(defaultCacheTTL),
+		workers: 4,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // --- Result types (stored as JSONB in seo_audits.performance_data) ---
 
 // WebVitalMetric is a single Core Web Vital measurement.
 type WebVitalMetric struct {
-	Value    string `json:"value"`    // Formatted: "1.2s", "0.01", "120ms"
-	Category string `json:"category"` // "good", "needs-improvement", "poor"
+	Value    string   `json:"value"`             // Formatted: "1.2s", "0.01", "120ms"
+	Category string   `json:"category"`          // "good", "needs-improvement", "poor"
+	Numeric  *float64 `json:"numeric,omitempty"` // Lighthouse's raw value: ms for timings, unitless for CLS
+}
+
+// FieldMetric is the 75th percentile of a metric over the last 28 days of
+// Chrome UX Report data.
+type FieldMetric struct {
+	Percentile float64 `json:"percentile"` // ms for timings, unitless for CLS
+	Category   string  `json:"category"`   // "good", "needs-improvement", "poor"
+}
+
+// FieldData is real-user data from the Chrome UX Report.
+type FieldData struct {
+	// Origin is true when the page has too little traffic of its own and the
+	// data covers the whole origin instead.
+	Origin          bool                   `json:"origin"`
+	OverallCategory string                 `json:"overallCategory"`
+	Metrics         map[string]FieldMetric `json:"metrics"` // LCP, INP, CLS, FCP, TTFB
 }
 
 // Result is the structured output stored in the database.
@@ -51,13 +106,48 @@ type Result struct {
 	Recs          []string                  `json:"recommendations"`
 	AuditedURL    string                    `json:"auditedUrl"`
 	AuditedAt     string                    `json:"auditedAt"`
+	// Field is nil when the Chrome UX Report has no data for the page or its origin
+	Field *FieldData `json:"field,omitempty"`
+	// Checks holds the pass/fail Lighthouse audits by ID: "is-on-https" and
+	// "viewport". Audits that didn't apply are absent.
+	Checks map[string]bool `json:"checks,omitempty"`
+	// Opportunities are the failed audits with an estimated saving, largest
+	// saving first. Recs holds the titles of the top few, for older clients.
+	Opportunities []Opportunity `json:"opportunities,omitempty"`
+	// Diagnostics are the failed performance audits without a saving
+	// estimate, worst first.
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// Opportunity is a Lighthouse suggestion with the load time and transfer
+// size it's estimated to save.
+type Opportunity struct {
+	ID           string  `json:"id"`
+	Title        string  `json:"title"`
+	Description  string  `json:"description"` // markdown, with a "Learn more" link
+	DisplayValue string  `json:"displayValue,omitempty"`
+	Score        float64 `json:"score"` // 0-1
+	SavingsMs    float64 `json:"savingsMs"`
+	SavingsBytes float64 `json:"savingsBytes"`
+}
+
+// Diagnostic is a failed Lighthouse performance audit that doesn't directly
+// save load time but points at a cause, e.g. a long main-thread task.
+type Diagnostic struct {
+	ID           string  `json:"id"`
+	Title        string  `json:"title"`
+	Description  string  `json:"description"`
+	DisplayValue string  `json:"displayValue,omitempty"`
+	Score        float64 `json:"score"` // 0-1
 }
 
 // --- Google API response types ---
 
 type apiResponse struct {
-	LighthouseResult *lighthouseResult `json:"lighthouseResult"`
-	Error            *apiError         `json:"error"`
+	LighthouseResult        *lighthouseResult  `json:"lighthouseResult"`
+	LoadingExperience       *loadingExperience `json:"loadingExperience"`
+	OriginLoadingExperience *loadingExperience `json:"originLoadingExperience"`
+	Error                   *apiError          `json:"error"`
 }
 
 type apiError struct {
@@ -65,17 +155,35 @@ type apiError struct {
 }
 
 type lighthouseResult struct {
-	Categories map[string]categoryScore  `json:"categories"`
+	Categories map[string]categoryScore   `json:"categories"`
 	Audits     map[string]lighthouseAudit `json:"audits"`
 }
 
+type loadingExperience struct {
+	Metrics         map[string]fieldMetric `json:"metrics"`
+	OverallCategory string                 `json:"overall_category"`
+}
+
+type fieldMetric struct {
+	Percentile float64 `json:"percentile"`
+	Category   string  `json:"category"` // FAST, AVERAGE, SLOW
+}
+
 type categoryScore struct {
-	Score float64 `json:"score"` // 0.0–1.0
+	Score     float64    `json:"score"` // 0.0–1.0
+	AuditRefs []auditRef `json:"auditRefs"`
+}
+
+// auditRef places an audit in a category's group, e.g. "diagnostics".
+type auditRef struct {
+	ID    string `json:"id"`
+	Group string `json:"group"`
 }
 
 type lighthouseAudit struct {
 	ID           string        `json:"id"`
 	Title        string        `json:"title"`
+	Description  string        `json:"description"`
 	Score        *float64      `json:"score"`
 	DisplayValue string        `json:"displayValue"`
 	NumericValue *float64      `json:"numericValue"`
@@ -83,7 +191,9 @@ type lighthouseAudit struct {
 }
 
 type auditDetails struct {
-	Type string `json:"type"`
+	Type                string  `json:"type"`
+	OverallSavingsMs    float64 `json:"overallSavingsMs"`
+	OverallSavingsBytes float64 `json:"overallSavingsBytes"`
 }
 
 // --- Thresholds matching the TS service ---
@@ -101,15 +211,46 @@ var thresholds = map[string]threshold{
 	"speed-index":              {good: 3.4, needsImprovement: 5.8},
 }
 
+// fieldMetrics maps the Chrome UX Report metric keys to our names.
+var fieldMetrics = map[string]string{
+	"LARGEST_CONTENTFUL_PAINT_MS":     "LCP",
+	"INTERACTION_TO_NEXT_PAINT":       "INP",
+	"CUMULATIVE_LAYOUT_SHIFT_SCORE":   "CLS",
+	"FIRST_CONTENTFUL_PAINT_MS":       "FCP",
+	"EXPERIMENTAL_TIME_TO_FIRST_BYTE": "TTFB",
+}
+
+// fieldCategories maps the Chrome UX Report categories to the lab ones.
+var fieldCategories = map[string]string{
+	"FAST":    "good",
+	"AVERAGE": "needs-improvement",
+	"SLOW":    "poor",
+}
+
+// checkAudits are the binary Lighthouse audits reported in Result.Checks.
+var checkAudits = []string{"is-on-https", "viewport"}
+
 // --- Public API ---
 
 // Run fetches PageSpeed data for the given URL using the specified strategy ("mobile" or "desktop").
+// Runs beyond the client's concurrency limit or query quota wait their turn.
+// Run always calls the API; RunBatch serves recent results from the cache.
 func (c *Client) Run(ctx context.Context, targetURL, strategy string) (*Result, error) {
 	if strategy == "" {
 		strategy = "mobile"
 	}
 
-	u, err := url.Parse(apiBaseURL)
+	select {
+	case c.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-c.sem }()
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(c.baseURL)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +280,9 @@ func (c *Client) Run(ctx context.Context, targetURL, strategy string) (*Result,
 		return nil, fmt.Errorf("read response: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.limiter.pause(retryAfter(resp.Header.Get("Retry-After")))
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("pagespeed API returned %d: %s", resp.StatusCode, truncate(string(body), 200))
 	}
@@ -156,11 +300,24 @@ func (c *Client) Run(ctx context.Context, targetURL, strategy string) (*Result,
 		return nil, fmt.Errorf("missing lighthouseResult in response")
 	}
 
-	return parseResult(apiResp.LighthouseResult, targetURL), nil
+	result := parseResponse(&apiResp, targetURL)
+	c.cache.put(targetURL, strategy, result)
+	return result, nil
 }
 
 // --- Parsing ---
 
+// parseResponse combines the Lighthouse (lab) result with the page's field
+// data, falling back to the origin's.
+func parseResponse(resp *apiResponse, targetURL string) *Result {
+	r := parseResult(resp.LighthouseResult, targetURL)
+	r.Field = parseFieldData(resp.LoadingExperience, false)
+	if r.Field == nil {
+		r.Field = parseFieldData(resp.OriginLoadingExperience, true)
+	}
+	return r
+}
+
 func parseResult(lr *lighthouseResult, targetURL string) *Result {
 	r := &Result{
 		Performance:   categoryScoreInt(lr.Categories, "performance"),
@@ -186,10 +343,45 @@ func parseResult(lr *lighthouseResult, targetURL string) *Result {
 
 	// Extract recommendations (opportunities with score < 1)
 	r.Recs = extractRecommendations(lr.Audits, 5)
+	r.Opportunities = extractOpportunities(lr.Audits)
+	r.Diagnostics = extractDiagnostics(lr)
+
+	for _, id := range checkAudits {
+		if audit, ok := lr.Audits[id]; ok && audit.Score != nil {
+			if r.Checks == nil {
+				r.Checks = make(map[string]bool)
+			}
+			r.Checks[id] = *audit.Score == 1
+		}
+	}
 
 	return r
 }
 
+// parseFieldData converts a loading experience, returning nil when it has no
+// metrics. CLS comes multiplied by 100 and is scaled back.
+func parseFieldData(le *loadingExperience, origin bool) *FieldData {
+	if le == nil || len(le.Metrics) == 0 {
+		return nil
+	}
+	fd := &FieldData{
+		Origin:          origin,
+		OverallCategory: fieldCategories[le.OverallCategory],
+		Metrics:         make(map[string]FieldMetric),
+	}
+	for key, m := range le.Metrics {
+		name, ok := fieldMetrics[key]
+		if !ok {
+			continue
+		}
+		if name == "CLS" {
+			m.Percentile /= 100
+		}
+		fd.Metrics[name] = FieldMetric{Percentile: m.Percentile, Category: fieldCategories[m.Category]}
+	}
+	return fd
+}
+
 func categoryScoreInt(cats map[string]categoryScore, key string) int {
 	if cs, ok := cats[key]; ok {
 		return int(math.Round(cs.Score * 100))
@@ -228,6 +420,7 @@ func parseMetric(audits map[string]lighthouseAudit, auditID, unit string, msDivi
 	return WebVitalMetric{
 		Value:    formatted,
 		Category: cat,
+		Numeric:  &rawValue,
 	}
 }
 
@@ -279,6 +472,64 @@ func extractRecommendations(audits map[string]lighthouseAudit, limit int) []stri
 	return result
 }
 
+// extractOpportunities returns the failed opportunity audits, largest time
+// saving first, then largest size saving.
+func extractOpportunities(audits map[string]lighthouseAudit) []Opportunity {
+	var opps []Opportunity
+	for id, audit := range audits {
+		if audit.Details == nil || audit.Details.Type != "opportunity" || audit.Score == nil || *audit.Score >= 1 {
+			continue
+		}
+		opps = append(opps, Opportunity{
+			ID:           id,
+			Title:        audit.Title,
+			Description:  audit.Description,
+			DisplayValue: audit.DisplayValue,
+			Score:        *audit.Score,
+			SavingsMs:    audit.Details.OverallSavingsMs,
+			SavingsBytes: audit.Details.OverallSavingsBytes,
+		})
+	}
+	sort.Slice(opps, func(i, j int) bool {
+		if opps[i].SavingsMs != opps[j].SavingsMs {
+			return opps[i].SavingsMs > opps[j].SavingsMs
+		}
+		if opps[i].SavingsBytes != opps[j].SavingsBytes {
+			return opps[i].SavingsBytes > opps[j].SavingsBytes
+		}
+		return opps[i].ID < opps[j].ID
+	})
+	return opps
+}
+
+// extractDiagnostics returns the failed audits in the performance category's
+// diagnostics group, lowest score first. Opportunities, which newer
+// Lighthouse versions group with the diagnostics, are left out.
+func extractDiagnostics(lr *lighthouseResult) []Diagnostic {
+	var diags []Diagnostic
+	for _, ref := range lr.Categories["performance"].AuditRefs {
+		if ref.Group != "diagnostics" {
+			continue
+		}
+		audit, ok := lr.Audits[ref.ID]
+		if !ok || audit.Score == nil || *audit.Score >= 1 {
+			continue
+		}
+		if audit.Details != nil && audit.Details.Type == "opportunity" {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			ID:           ref.ID,
+			Title:        audit.Title,
+			Description:  audit.Description,
+			DisplayValue: audit.DisplayValue,
+			Score:        *audit.Score,
+		})
+	}
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Score < diags[j].Score })
+	return diags
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -0,0 +1,364 @@
+package pagespeed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleResponse = `{
+	"loadingExperience": {"metrics": {}},
+	"originLoadingExperience": {
+		"overall_category": "AVERAGE",
+		"metrics": {
+			"LARGEST_CONTENTFUL_PAINT_MS": {"percentile": 2100, "category": "FAST"},
+			"INTERACTION_TO_NEXT_PAINT": {"percentile": 310, "category": "AVERAGE"},
+			"CUMULATIVE_LAYOUT_SHIFT_SCORE": {"percentile": 5, "category": "FAST"},
+			"FIRST_INPUT_DELAY_MS": {"percentile": 12, "category": "FAST"}
+		}
+	},
+	"lighthouseResult": {
+		"categories": {"performance": {"score": 0.87}, "seo": {"score": 1}},
+		"audits": {
+			"largest-contentful-paint": {"numericValue": 3120.5},
+			"cumulative-layout-shift": {"numericValue": 0},
+			"total-blocking-time": {"numericValue": 250},
+			"is-on-https": {"score": 1},
+			"viewport": {"score": 0}
+		}
+	}
+}`
+
+func parseSample(t *testing.T) *Result {
+	t.Helper()
+	var resp apiResponse
+	require.NoError(t, json.Unmarshal([]byte(sampleResponse), &resp))
+	return parseResponse(&resp, "https://example.com")
+}
+
+func TestParseResult_LabMetrics(t *testing.T) {
+	r := parseSample(t)
+
+	assert.Equal(t, 87, r.Performance)
+	assert.Equal(t, "3.1s", r.Metrics["LCP"].Value)
+	assert.Equal(t, "needs-improvement", r.Metrics["LCP"].Category)
+	require.NotNil(t, r.Metrics["CLS"].Numeric)
+	assert.Equal(t, 0.0, *r.Metrics["CLS"].Numeric)
+	assert.Equal(t, "good", r.Metrics["CLS"].Category)
+	assert.Equal(t, "N/A", r.Metrics["FCP"].Value)
+	assert.Nil(t, r.Metrics["FCP"].Numeric)
+}
+
+func TestParseResult_Checks(t *testing.T) {
+	r := parseSample(t)
+
+	assert.Equal(t, map[string]bool{"is-on-https": true, "viewport": false}, r.Checks)
+}
+
+func TestParseResult_OpportunitiesAndDiagnostics(t *testing.T) {
+	const response = `{"lighthouseResult": {
+		"categories": {"performance": {"score": 0.6, "auditRefs": [
+			{"id": "render-blocking-resources", "group": "diagnostics"},
+			{"id": "mainthread-work-breakdown", "group": "diagnostics"},
+			{"id": "dom-size", "group": "diagnostics"},
+			{"id": "bootup-time", "group": "diagnostics"},
+			{"id": "largest-contentful-paint", "group": "metrics"}
+		]}},
+		"audits": {
+			"render-blocking-resources": {"title": "Eliminate render-blocking resources", "description": "Resources are blocking the first paint.",
+				"score": 0.3, "displayValue": "Potential savings of 1,200 ms",
+				"details": {"type": "opportunity", "overallSavingsMs": 1200, "overallSavingsBytes": 0}},
+			"unused-javascript": {"title": "Reduce unused JavaScript", "description": "Reduce unused JavaScript.",
+				"score": 0.5, "displayValue": "Potential savings of 150 KiB",
+				"details": {"type": "opportunity", "overallSavingsMs": 300, "overallSavingsBytes": 153600}},
+			"uses-text-compression": {"title": "Enable text compression", "score": 1,
+				"details": {"type": "opportunity", "overallSavingsMs": 0}},
+			"mainthread-work-breakdown": {"title": "Minimize main-thread work", "description": "Consider reducing JS parsing.",
+				"score": 0.2, "displayValue": "4.1 s", "details": {"type": "table"}},
+			"dom-size": {"title": "Avoids an excessive DOM size", "score": 1, "details": {"type": "table"}},
+			"bootup-time": {"title": "Reduce JavaScript execution time", "score": 0.6, "displayValue": "1.8 s", "details": {"type": "table"}}
+		}
+	}}`
+	var resp apiResponse
+	require.NoError(t, json.Unmarshal([]byte(response), &resp))
+	r := parseResponse(&resp, "https://example.com")
+
+	require.Len(t, r.Opportunities, 2)
+	assert.Equal(t, Opportunity{
+		ID:           "render-blocking-resources",
+		Title:        "Eliminate render-blocking resources",
+		Description:  "Resources are blocking the first paint.",
+		DisplayValue: "Potential savings of 1,200 ms",
+		Score:        0.3,
+		SavingsMs:    1200,
+	}, r.Opportunities[0])
+	assert.Equal(t, 153600.0, r.Opportunities[1].SavingsBytes)
+
+	require.Len(t, r.Diagnostics, 2)
+	assert.Equal(t, "mainthread-work-breakdown", r.Diagnostics[0].ID)
+	assert.Equal(t, "4.1 s", r.Diagnostics[0].DisplayValue)
+	assert.Equal(t, "bootup-time", r.Diagnostics[1].ID)
+
+	// The flattened titles are kept for older clients
+	assert.Equal(t, []string{"Eliminate render-blocking resources", "Reduce unused JavaScript"}, r.Recs)
+}
+
+func TestParseFieldData_FallsBackToOrigin(t *testing.T) {
+	r := parseSample(t)
+
+	require.NotNil(t, r.Field)
+	assert.True(t, r.Field.Origin)
+	assert.Equal(t, "needs-improvement", r.Field.OverallCategory)
+	assert.Equal(t, FieldMetric{Percentile: 2100, Category: "good"}, r.Field.Metrics["LCP"])
+	assert.Equal(t, FieldMetric{Percentile: 0.05, Category: "good"}, r.Field.Metrics["CLS"])
+	assert.Equal(t, "needs-improvement", r.Field.Metrics["INP"].Category)
+	assert.NotContains(t, r.Field.Metrics, "FID")
+	assert.Len(t, r.Field.Metrics, 3)
+}
+
+func TestParseFieldData_Empty(t *testing.T) {
+	assert.Nil(t, parseFieldData(nil, false))
+	assert.Nil(t, parseFieldData(&loadingExperience{}, false))
+}
+
+// ---------------------------------------------------------------------------
+// RunBoth
+// ---------------------------------------------------------------------------
+
+// strategyServer answers mobile and desktop runs with different scores. A
+// strategy listed in fail gets a 500.
+func strategyServer(t *testing.T, inFlight, peak *atomic.Int32, fail ...string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		strategy := r.URL.Query().Get("strategy")
+		if slices.Contains(fail, strategy) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		perf, lcp := 0.55, 4800.0
+		if strategy == StrategyDesktop {
+			perf, lcp = 0.92, 1400.0
+		}
+		fmt.Fprintf(w, `{"lighthouseResult": {
+			"categories": {"performance": {"score": %g}, "seo": {"score": 0.9}},
+			"audits": {"largest-contentful-paint": {"numericValue": %g}, "cumulative-layout-shift": {"numericValue": 0.02}}
+		}}`, perf, lcp)
+	}))
+}
+
+func TestRunBoth_Compares(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := strategyServer(t, &inFlight, &peak)
+	defer srv.Close()
+
+	c := NewClient("key", WithBaseURL(srv.URL))
+	r, err := c.RunBoth(context.Background(), "https://example.com")
+
+	require.NoError(t, err)
+	require.NotNil(t, r.Mobile)
+	require.NotNil(t, r.Desktop)
+	assert.Equal(t, int32(2), peak.Load(), "strategies should run concurrently")
+	require.NotNil(t, r.Comparison)
+	assert.Equal(t, ScoreDiff{Mobile: 55, Desktop: 92, Delta: 37}, r.Comparison.Scores["performance"])
+	assert.Equal(t, 0, r.Comparison.Scores["seo"].Delta)
+	assert.InDelta(t, 3400, r.Comparison.Metrics["LCP"].Delta, 0.001)
+	assert.NotContains(t, r.Comparison.Metrics, "FCP")
+	assert.Equal(t, []string{
+		"Performance is 37 points lower on mobile (55 vs 92)",
+		"LCP is poor on mobile but good on desktop (4.8s vs 1.4s)",
+	}, r.Comparison.Summary)
+}
+
+func TestRunBoth_RespectsConcurrencyLimit(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := strategyServer(t, &inFlight, &peak)
+	defer srv.Close()
+
+	c := NewClient("key", WithBaseURL(srv.URL), WithMaxConcurrent(1))
+	_, err := c.RunBoth(context.Background(), "https://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), peak.Load())
+}
+
+func TestRunBoth_PartialFailure(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := strategyServer(t, &inFlight, &peak, StrategyDesktop)
+	defer srv.Close()
+
+	r, err := NewClient("key", WithBaseURL(srv.URL)).RunBoth(context.Background(), "https://example.com")
+
+	require.NoError(t, err)
+	assert.NotNil(t, r.Mobile)
+	assert.Nil(t, r.Desktop)
+	assert.Contains(t, r.DesktopError, "returned 500")
+	assert.Nil(t, r.Comparison)
+}
+
+func TestRunBoth_BothFail(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := strategyServer(t, &inFlight, &peak, StrategyMobile, StrategyDesktop)
+	defer srv.Close()
+
+	_, err := NewClient("key", WithBaseURL(srv.URL)).RunBoth(context.Background(), "https://example.com")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mobile:")
+	assert.Contains(t, err.Error(), "desktop:")
+}
+
+// ---------------------------------------------------------------------------
+// RunBatch
+// ---------------------------------------------------------------------------
+
+// batchServer answers every run with a minimal result, or a 500 for the
+// URLs in fail, and counts the runs per URL.
+func batchServer(t *testing.T, runs *sync.Map, fail ...string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.Query().Get("url")
+		n, _ := runs.LoadOrStore(target, new(atomic.Int32))
+		n.(*atomic.Int32).Add(1)
+		if slices.Contains(fail, target) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"lighthouseResult": {"categories": {"performance": {"score": 0.9}}, "audits": {}}}`))
+	}))
+}
+
+func runCount(runs *sync.Map, target string) int32 {
+	n, ok := runs.Load(target)
+	if !ok {
+		return 0
+	}
+	return n.(*atomic.Int32).Load()
+}
+
+func TestRunBatch_ReportsErrorsPerURL(t *testing.T) {
+	var runs sync.Map
+	srv := batchServer(t, &runs, "https://example.com/b")
+	defer srv.Close()
+
+	c := NewClient("key", WithBaseURL(srv.URL), WithBatchWorkers(2))
+	urls := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c", "https://example.com/a"}
+	results := c.RunBatch(context.Background(), urls, StrategyMobile)
+
+	require.Len(t, results, 4)
+	for i, r := range results {
+		assert.Equal(t, urls[i], r.URL)
+	}
+	assert.Equal(t, 90, results[0].Result.Performance)
+	assert.Nil(t, results[1].Result)
+	assert.Contains(t, results[1].Error, "returned 500")
+	assert.Equal(t, 90, results[2].Result.Performance)
+	assert.Same(t, results[0].Result, results[3].Result)
+	assert.Equal(t, int32(1), runCount(&runs, "https://example.com/a"), "duplicate URLs run once")
+}
+
+func TestRunBatch_ServesCachedResults(t *testing.T) {
+	var runs sync.Map
+	srv := batchServer(t, &runs, "https://example.com/b")
+	defer srv.Close()
+
+	c := NewClient("key", WithBaseURL(srv.URL))
+	urls := []string{"https://example.com/a", "https://example.com/b"}
+	c.RunBatch(context.Background(), urls, StrategyMobile)
+	results := c.RunBatch(context.Background(), urls, StrategyMobile)
+
+	assert.True(t, results[0].Cached)
+	assert.Equal(t, int32(1), runCount(&runs, "https://example.com/a"))
+	assert.False(t, results[1].Cached, "failures aren't cached")
+	assert.Equal(t, int32(2), runCount(&runs, "https://example.com/b"))
+
+	// The cache is per strategy
+	desktop := c.RunBatch(context.Background(), urls[:1], StrategyDesktop)
+	assert.False(t, desktop[0].Cached)
+}
+
+func TestRunBatch_CacheExpiresAndCanBeDisabled(t *testing.T) {
+	var runs sync.Map
+	srv := batchServer(t, &runs)
+	defer srv.Close()
+
+	urls := []string{"https://example.com/a"}
+	c := NewClient("key", WithBaseURL(srv.URL), WithCacheTTL(20*time.Millisecond))
+	c.RunBatch(context.Background(), urls, StrategyMobile)
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, c.RunBatch(context.Background(), urls, StrategyMobile)[0].Cached)
+
+	uncached := NewClient("key", WithBaseURL(srv.URL), WithCacheTTL(0))
+	uncached.RunBatch(context.Background(), urls, StrategyMobile)
+	assert.False(t, uncached.RunBatch(context.Background(), urls, StrategyMobile)[0].Cached)
+	assert.Equal(t, int32(4), runCount(&runs, "https://example.com/a"))
+}
+
+func TestRunBatch_RespectsQuota(t *testing.T) {
+	var runs sync.Map
+	srv := batchServer(t, &runs)
+	defer srv.Close()
+
+	// 600 a minute is 10 a second: a burst of 10, then one every 100ms
+	c := NewClient("key", WithBaseURL(srv.URL), WithQueriesPerMinute(600), WithBatchWorkers(8), WithMaxConcurrent(8))
+	urls := make([]string, 12)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/%d", i)
+	}
+	start := time.Now()
+	results := c.RunBatch(context.Background(), urls, StrategyMobile)
+
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	for _, r := range results {
+		assert.Empty(t, r.Error)
+	}
+}
+
+func TestRunBatch_CancelledContext(t *testing.T) {
+	var runs sync.Map
+	srv := batchServer(t, &runs)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := NewClient("key", WithBaseURL(srv.URL)).RunBatch(ctx, []string{"https://example.com/a"}, StrategyMobile)
+
+	assert.Contains(t, results[0].Error, context.Canceled.Error())
+}
+
+func TestRateLimiter_TooManyRequestsPausesClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", WithBaseURL(srv.URL))
+	_, err := c.Run(context.Background(), "https://example.com", StrategyMobile)
+	require.Error(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.Run(ctx, "https://example.com", StrategyMobile)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, quotaBackoff, retryAfter(""))
+}
@@ -0,0 +1,144 @@
+package pagespeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Strategies PageSpeed can run a page with.
+const (
+	StrategyMobile  = "mobile"
+	StrategyDesktop = "desktop"
+)
+
+// notableScoreGap is the category score gap the comparison summary calls out.
+const notableScoreGap = 10
+
+// DualResult is a page run with both strategies. A strategy that failed has
+// a nil result and its error message set.
+type DualResult struct {
+	URL          string      `json:"url"`
+	Mobile       *Result     `json:"mobile"`
+	Desktop      *Result     `json:"desktop"`
+	MobileError  string      `json:"mobileError,omitempty"`
+	DesktopError string      `json:"desktopError,omitempty"`
+	Comparison   *Comparison `json:"comparison,omitempty"` // nil unless both runs succeeded
+}
+
+// Comparison sets the desktop run against the mobile one.
+type Comparison struct {
+	Scores  map[string]ScoreDiff  `json:"scores"`  // performance, accessibility, bestPractices, seo
+	Metrics map[string]MetricDiff `json:"metrics"` // LCP, CLS, FCP, TBT, SI; metrics missing from either run are left out
+	Summary []string              `json:"summary"` // notable score gaps, then metrics rated differently
+}
+
+// ScoreDiff is a category score on both strategies. Delta is desktop minus
+// mobile, so positive means mobile trails.
+type ScoreDiff struct {
+	Mobile  int `json:"mobile"`
+	Desktop int `json:"desktop"`
+	Delta   int `json:"delta"`
+}
+
+// MetricDiff is a lab metric on both strategies, in Lighthouse's units.
+// Delta is mobile minus desktop, so positive means mobile is slower.
+type MetricDiff struct {
+	Mobile          float64 `json:"mobile"`
+	Desktop         float64 `json:"desktop"`
+	Delta           float64 `json:"delta"`
+	MobileCategory  string  `json:"mobileCategory"`
+	DesktopCategory string  `json:"desktopCategory"`
+}
+
+// RunBoth runs targetURL with the mobile and desktop strategies concurrently,
+// within the client's concurrency limit, and compares them. It only fails
+// when both runs do; a single failure is reported in the result.
+func (c *Client) RunBoth(ctx context.Context, targetURL string) (*DualResult, error) {
+	var (
+		wg                    sync.WaitGroup
+		mobile, desktop       *Result
+		mobileErr, desktopErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		mobile, mobileErr = c.Run(ctx, targetURL, StrategyMobile)
+	}()
+	go func() {
+		defer wg.Done()
+		desktop, desktopErr = c.Run(ctx, targetURL, StrategyDesktop)
+	}()
+	wg.Wait()
+
+	if mobileErr != nil && desktopErr != nil {
+		return nil, errors.Join(fmt.Errorf("mobile: %w", mobileErr), fmt.Errorf("desktop: %w", desktopErr))
+	}
+
+	r := &DualResult{URL: targetURL, Mobile: mobile, Desktop: desktop}
+	if mobileErr != nil {
+		r.MobileError = mobileErr.Error()
+	}
+	if desktopErr != nil {
+		r.DesktopError = desktopErr.Error()
+	}
+	if mobile != nil && desktop != nil {
+		r.Comparison = Compare(mobile, desktop)
+	}
+	return r, nil
+}
+
+// Compare diffs a mobile and a desktop run of the same page.
+func Compare(mobile, desktop *Result) *Comparison {
+	cmp := &Comparison{
+		Scores: map[string]ScoreDiff{
+			"performance":   scoreDiff(mobile.Performance, desktop.Performance),
+			"accessibility": scoreDiff(mobile.Accessibility, desktop.Accessibility),
+			"bestPractices": scoreDiff(mobile.BestPractices, desktop.BestPractices),
+			"seo":           scoreDiff(mobile.SEO, desktop.SEO),
+		},
+		Metrics: make(map[string]MetricDiff),
+		Summary: []string{},
+	}
+
+	// In display order, so the summary reads the same every time
+	categories := []struct{ key, label string }{
+		{"performance", "Performance"},
+		{"accessibility", "Accessibility"},
+		{"bestPractices", "Best practices"},
+		{"seo", "SEO"},
+	}
+	for _, cat := range categories {
+		d := cmp.Scores[cat.key]
+		switch {
+		case d.Delta >= notableScoreGap:
+			cmp.Summary = append(cmp.Summary, fmt.Sprintf("%s is %d points lower on mobile (%d vs %d)", cat.label, d.Delta, d.Mobile, d.Desktop))
+		case d.Delta <= -notableScoreGap:
+			cmp.Summary = append(cmp.Summary, fmt.Sprintf("%s is %d points lower on desktop (%d vs %d)", cat.label, -d.Delta, d.Desktop, d.Mobile))
+		}
+	}
+
+	for _, name := range []string{"LCP", "CLS", "FCP", "TBT", "SI"} {
+		m, okM := mobile.Metrics[name]
+		d, okD := desktop.Metrics[name]
+		if !okM || !okD || m.Numeric == nil || d.Numeric == nil {
+			continue
+		}
+		cmp.Metrics[name] = MetricDiff{
+			Mobile:          *m.Numeric,
+			Desktop:         *d.Numeric,
+			Delta:           *m.Numeric - *d.Numeric,
+			MobileCategory:  m.Category,
+			DesktopCategory: d.Category,
+		}
+		if m.Category != d.Category {
+			cmp.Summary = append(cmp.Summary, fmt.Sprintf("%s is %s on mobile but %s on desktop (%s vs %s)", name, m.Category, d.Category, m.Value, d.Value))
+		}
+	}
+	return cmp
+}
+
+func scoreDiff(mobile, desktop int) ScoreDiff {
+	return ScoreDiff{Mobile: mobile, Desktop: desktop, Delta: desktop - mobile}
+}
@@ -0,0 +1,110 @@
+package pagespeed
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultQueriesPerMinute is the PageSpeed Insights API's default quota per
+// API key.
+const DefaultQueriesPerMinute = 240
+
+// quotaBackoff is how long a 429 without Retry-After pauses the client.
+const quotaBackoff = 10 * time.Second
+
+// WithQueriesPerMinute caps the client's runs at n per minute, for a key with
+// a raised or lowered quota. Zero or less removes the cap.
+// Default: DefaultQueriesPerMinute.
+func WithQueriesPerMinute(n int) Option {
+	return func(c *Client) {
+		c.limiter = nil
+		if n > 0 {
+			c.limiter = newRateLimiter(float64(n) / 60)
+		}
+	}
+}
+
+// rateLimiter is a token bucket shared by every run the client makes. A 429
+// pauses it so concurrent runs back off together.
+type rateLimiter struct {
+	mu          sync.Mutex
+	rate        float64 // tokens per second
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newRateLimiter(rps float64) *rateLimiter {
+	burst := max(rps, 1)
+	return &rateLimiter{rate: rps, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait blocks until a token is available or ctx is done. A nil limiter never blocks.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token if one is available, else returns how long to wait.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// pause holds every caller for d and empties the bucket, so runs resume at
+// the steady rate rather than in a burst.
+func (l *rateLimiter) pause(d time.Duration) {
+	if l == nil || d <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if until := now.Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.tokens = 0
+	l.last = now.Add(d)
+}
+
+// retryAfter reads a 429's Retry-After header, given in seconds or as an
+// HTTP date, falling back to quotaBackoff.
+func retryAfter(h string) time.Duration {
+	if secs, err := strconv.Atoi(h); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil && time.Until(t) > 0 {
+		return time.Until(t)
+	}
+	return quotaBackoff
+}
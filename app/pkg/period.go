@@ -0,0 +1,30 @@
+package pkg
+
+import "time"
+
+// Period is a half-open time range [Start, End).
+type Period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// LoadLocation resolves an IANA timezone name, falling back to UTC when the
+// name is empty or unknown to the zone database.
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// MonthPeriod returns the calendar month containing t, with boundaries at
+// midnight in loc. The returned times are in UTC.
+func MonthPeriod(t time.Time, loc *time.Location) Period {
+	local := t.In(loc)
+	start := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+	return Period{Start: start.UTC(), End: start.AddDate(0, 1, 0).UTC()}
+}
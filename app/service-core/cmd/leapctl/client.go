@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// client calls the core service's REST API as a super admin. It uses the v2
+// envelope, which nests failures under "error".
+type client struct {
+	baseURL   string
+	token     string
+	taskToken string
+	http      *http.Client
+}
+
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Status, e.Message)
+}
+
+// do sends a request to path under /api/v2 and decodes the response data
+// into out, unless out is nil.
+func (c *client) do(ctx context.Context, method, path string, body io.Reader, contentType string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api/v2"+path, body)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Data  json.RawMessage `json:"data"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return &apiError{Status: resp.StatusCode, Message: strings.TrimSpace(string(raw))}
+	}
+	if envelope.Error != nil || resp.StatusCode >= 400 {
+		message := http.StatusText(resp.StatusCode)
+		if envelope.Error != nil {
+			message = envelope.Error.Message
+		}
+		return &apiError{Status: resp.StatusCode, Message: message}
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// postJSON sends body as JSON.
+func (c *client) postJSON(ctx context.Context, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, path, bytes.NewReader(data), "application/json", out)
+}
+
+// runTask triggers a scheduled task the way the scheduler does. Tasks
+// answer with a bare status rather than an envelope.
+func (c *client) runTask(ctx context.Context, name string) error {
+	if c.taskToken == "" {
+		return fmt.Errorf("LEAP_TASK_TOKEN is not set")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/tasks/"+name, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Api-Key", c.taskToken)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return &apiError{Status: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+	return nil
+}
+
+func newClient(baseURL, token, taskToken string) *client {
+	return &client{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		token:     token,
+		taskToken: taskToken,
+		// Installs and imports can take minutes
+		http: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
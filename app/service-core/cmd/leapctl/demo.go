@@ -0,0 +1,45 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"io/fs"
+	"strings"
+)
+
+// demoFS is the organisation seed-demo creates when given no archive: an
+// owner, two learners and two courses, one of them with enrolments. It's laid
+// out as an organisation export and imported like one, so its users are
+// matched by email and seeding again only adds another organisation.
+//
+//go:embed demo
+var demoFS embed.FS
+
+// demoArchive zips the demo organisation for the import endpoint.
+func demoArchive() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	err := fs.WalkDir(demoFS, "demo", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := demoFS.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(strings.TrimPrefix(name, "demo/"))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,257 @@
+// Command leapctl runs common operator tasks against a running core service:
+// installing libraries, refreshing hub metadata, starting audits, replaying
+// webhook deliveries, seeding demo organisations and watching jobs.
+//
+// It talks to the REST API with a super admin's access token from
+// LEAP_TOKEN, at LEAP_API_URL (default http://localhost:4001). The task
+// command uses the scheduler's LEAP_TASK_TOKEN instead.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+)
+
+// errUsage reports a command run with the wrong arguments.
+var errUsage = errors.New("wrong arguments")
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, c *client, args []string) error
+}
+
+var commands = map[string]command{
+	"install":        {"install <machineName>\n\tinstall or update a library from the H5P Hub", runInstall},
+	"hub-refresh":    {"hub-refresh\n\trefetch the H5P Hub content type metadata", runHubRefresh},
+	"audit":          {"audit -org <id> [-strategy mobile|desktop] <url>...\n\tstart a page experience audit", runAudit},
+	"webhook-replay": {"webhook-replay <orgId> <webhookId> <deliveryId>\n\tsend a webhook delivery again", runWebhookReplay},
+	"seed-demo":      {"seed-demo [archive.zip]\n\tcreate the demo organisation, or one from an export archive", runSeedDemo},
+	"jobs":           {"jobs [-status dead] [-kind <kind>] [-limit 20] [-follow] [-interval 5s]\n\tlist background jobs, or watch them change", runJobs},
+	"task":           {"task <name>\n\trun a scheduled task now, e.g. retention or webhook-deliveries", runTask},
+}
+
+var commandOrder = []string{"install", "hub-refresh", "audit", "webhook-replay", "seed-demo", "jobs", "task"}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "leapctl: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	baseURL := os.Getenv("LEAP_API_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:4001"
+	}
+	c := newClient(baseURL, os.Getenv("LEAP_TOKEN"), os.Getenv("LEAP_TASK_TOKEN"))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err := cmd.run(ctx, c, os.Args[2:])
+	switch {
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "usage: leapctl %s\n", cmd.usage)
+		os.Exit(2)
+	case err != nil && !errors.Is(err, context.Canceled):
+		fmt.Fprintln(os.Stderr, "leapctl:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: leapctl <command> [arguments]\n\ncommands:")
+	for _, name := range commandOrder {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+}
+
+// printJSON writes v indented to stdout.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// wantArgs checks a command got exactly n positional arguments.
+func wantArgs(args []string, n int) error {
+	if len(args) != n {
+		return errUsage
+	}
+	return nil
+}
+
+func runInstall(ctx context.Context, c *client, args []string) error {
+	if err := wantArgs(args, 1); err != nil {
+		return err
+	}
+	var lib json.RawMessage
+	if err := c.postJSON(ctx, "/h5p/install", map[string]string{"machineName": args[0]}, &lib); err != nil {
+		return err
+	}
+	return printJSON(lib)
+}
+
+func runHubRefresh(ctx context.Context, c *client, args []string) error {
+	if err := wantArgs(args, 0); err != nil {
+		return err
+	}
+	var metadata json.RawMessage
+	if err := c.do(ctx, http.MethodPost, "/admin/h5p/hub-metadata/refresh", nil, "", &metadata); err != nil {
+		return err
+	}
+	return printJSON(metadata)
+}
+
+func runAudit(ctx context.Context, c *client, args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	org := fs.String("org", "", "organisation ID")
+	strategy := fs.String("strategy", "mobile", "mobile or desktop")
+	fs.Parse(args)
+	if *org == "" || fs.NArg() == 0 {
+		return errUsage
+	}
+
+	type page struct {
+		URL string `json:"url"`
+	}
+	pages := make([]page, fs.NArg())
+	for i, u := range fs.Args() {
+		pages[i] = page{URL: u}
+	}
+	var audit json.RawMessage
+	err := c.postJSON(ctx, "/seo/page-experience", map[string]any{
+		"organisationId": *org,
+		"strategy":       *strategy,
+		"pages":          pages,
+	}, &audit)
+	if err != nil {
+		return err
+	}
+	return printJSON(audit)
+}
+
+func runWebhookReplay(ctx context.Context, c *client, args []string) error {
+	if err := wantArgs(args, 3); err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/organisations/%s/webhooks/%s/deliveries/%s/redeliver",
+		url.PathEscape(args[0]), url.PathEscape(args[1]), url.PathEscape(args[2]))
+	var delivery json.RawMessage
+	if err := c.do(ctx, http.MethodPost, path, nil, "", &delivery); err != nil {
+		return err
+	}
+	return printJSON(delivery)
+}
+
+func runSeedDemo(ctx context.Context, c *client, args []string) error {
+	var archive io.Reader
+	switch len(args) {
+	case 0:
+		data, err := demoArchive()
+		if err != nil {
+			return err
+		}
+		archive = bytes.NewReader(data)
+	case 1:
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		archive = f
+	default:
+		return errUsage
+	}
+
+	var result json.RawMessage
+	if err := c.do(ctx, http.MethodPost, "/admin/organisations/import", archive, "application/zip", &result); err != nil {
+		return err
+	}
+	return printJSON(result)
+}
+
+func runTask(ctx context.Context, c *client, args []string) error {
+	if err := wantArgs(args, 1); err != nil {
+		return err
+	}
+	if err := c.runTask(ctx, args[0]); err != nil {
+		return err
+	}
+	fmt.Println("ok")
+	return nil
+}
+
+type job struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	MaxAttempts int       `json:"maxAttempts"`
+	LastError   string    `json:"lastError"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func runJobs(ctx context.Context, c *client, args []string) error {
+	fs := flag.NewFlagSet("jobs", flag.ExitOnError)
+	status := fs.String("status", "", "pending, running, completed or dead")
+	kind := fs.String("kind", "", "job kind, e.g. seo.rank_check")
+	limit := fs.Int("limit", 20, "jobs to fetch each time")
+	follow := fs.Bool("follow", false, "keep polling and print jobs as they change")
+	interval := fs.Duration("interval", 5*time.Second, "how often to poll with -follow")
+	fs.Parse(args)
+
+	q := url.Values{}
+	q.Set("limit", strconv.Itoa(*limit))
+	if *status != "" {
+		q.Set("status", *status)
+	}
+	if *kind != "" {
+		q.Set("kind", *kind)
+	}
+	path := "/admin/jobs?" + q.Encode()
+
+	// seen is each job's last printed state, so following prints changes only
+	seen := make(map[string]string)
+	for {
+		var list []job
+		if err := c.do(ctx, http.MethodGet, path, nil, "", &list); err != nil {
+			return err
+		}
+		// Oldest first, so the newest lines are at the bottom
+		for i := len(list) - 1; i >= 0; i-- {
+			j := list[i]
+			state := fmt.Sprintf("%s %d", j.Status, j.Attempts)
+			if seen[j.ID] == state {
+				continue
+			}
+			seen[j.ID] = state
+			fmt.Printf("%s  %s  %-28s %-9s %d/%d  %s\n",
+				j.CreatedAt.Local().Format(time.DateTime), j.ID, j.Kind, j.Status, j.Attempts, j.MaxAttempts, j.LastError)
+		}
+		if !*follow {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(*interval):
+		}
+	}
+}
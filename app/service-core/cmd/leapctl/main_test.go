@@ -0,0 +1,199 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDemoArchive(t *testing.T) {
+	data, err := demoArchive()
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	read := func(name string, dst any) {
+		t.Helper()
+		f, err := zr.Open(name)
+		require.NoError(t, err, name)
+		defer f.Close()
+		require.NoError(t, json.NewDecoder(f).Decode(dst), name)
+	}
+
+	var manifest struct {
+		Format         string         `json:"format"`
+		Version        int            `json:"version"`
+		OrganisationID string         `json:"organisationId"`
+		Tables         map[string]int `json:"tables"`
+		Files          []any          `json:"files"`
+	}
+	read("manifest.json", &manifest)
+	assert.Equal(t, "leaplearn-organisation", manifest.Format)
+	assert.Equal(t, 1, manifest.Version)
+	assert.Empty(t, manifest.Files)
+
+	// Every table the manifest counts is in the archive with that many rows,
+	// and there are no others
+	tableFiles := 0
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, "tables/") {
+			tableFiles++
+		}
+	}
+	assert.Equal(t, len(manifest.Tables), tableFiles)
+	rows := make(map[string][]map[string]any)
+	for table, count := range manifest.Tables {
+		var tableRows []map[string]any
+		read("tables/"+table+".json", &tableRows)
+		assert.Len(t, tableRows, count, table)
+		rows[table] = tableRows
+	}
+
+	require.Len(t, rows["organisations"], 1)
+	assert.Equal(t, manifest.OrganisationID, rows["organisations"][0]["id"])
+	users := make(map[any]bool)
+	for _, u := range rows["users"] {
+		users[u["id"]] = true
+		assert.Zero(t, int64(u["access"].(float64))&0x10000, "demo users must not be super admins")
+	}
+	roles := make(map[any]string)
+	for _, m := range rows["organisation_memberships"] {
+		assert.Equal(t, manifest.OrganisationID, m["organisation_id"])
+		assert.True(t, users[m["user_id"]], "membership of unknown user %v", m["user_id"])
+		roles[m["user_id"]] = m["role"].(string)
+	}
+	assert.Equal(t, "owner", roles[rows["courses"][0]["created_by"]])
+	courses := make(map[any]bool)
+	for _, c := range rows["courses"] {
+		courses[c["id"]] = true
+	}
+	for _, e := range rows["enrolments"] {
+		assert.True(t, courses[e["course_id"]], "enrolment in unknown course %v", e["course_id"])
+		assert.True(t, users[e["user_id"]], "enrolment of unknown user %v", e["user_id"])
+	}
+}
+
+func TestRunSeedDemo(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "export.zip")
+	require.NoError(t, os.WriteFile(archivePath, []byte("operator archive"), 0o600))
+	demo, err := demoArchive()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		args    []string
+		status  int
+		reply   string
+		sent    []byte
+		wantErr string
+	}{
+		{
+			name:   "built-in demo",
+			status: http.StatusOK,
+			reply:  `{"data":{"slug":"demo"}}`,
+			sent:   demo,
+		},
+		{
+			name:   "operator archive",
+			args:   []string{archivePath},
+			status: http.StatusOK,
+			reply:  `{"data":{"slug":"acme"}}`,
+			sent:   []byte("operator archive"),
+		},
+		{
+			name:    "rejected archive",
+			args:    []string{archivePath},
+			status:  http.StatusBadRequest,
+			reply:   `{"error":{"message":"Invalid archive"}}`,
+			sent:    []byte("operator archive"),
+			wantErr: "400: Invalid archive",
+		},
+		{
+			name:    "missing archive",
+			args:    []string{filepath.Join(t.TempDir(), "missing.zip")},
+			wantErr: "no such file",
+		},
+		{
+			name:    "too many arguments",
+			args:    []string{archivePath, archivePath},
+			wantErr: errUsage.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/api/v2/admin/organisations/import", r.URL.Path)
+				assert.Equal(t, "Bearer admin-token", r.Header.Get("Authorization"))
+				assert.Equal(t, "application/zip", r.Header.Get("Content-Type"))
+				sent, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.reply)
+			}))
+			defer srv.Close()
+
+			err := runSeedDemo(context.Background(), newClient(srv.URL, "admin-token", ""), tt.args)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.sent, sent)
+		})
+	}
+}
+
+func TestClientDo_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		reply   string
+		wantErr string
+	}{
+		{name: "envelope error", status: http.StatusForbidden, reply: `{"error":{"message":"super admin required"}}`, wantErr: "403: super admin required"},
+		{name: "status without envelope error", status: http.StatusBadGateway, reply: `{}`, wantErr: "502: Bad Gateway"},
+		{name: "not JSON", status: http.StatusBadGateway, reply: "upstream down\n", wantErr: "502: upstream down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.reply)
+			}))
+			defer srv.Close()
+
+			err := newClient(srv.URL, "admin-token", "").do(context.Background(), http.MethodGet, "/admin/jobs", nil, "", nil)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestClientRunTask(t *testing.T) {
+	var key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tasks/retention", r.URL.Path)
+		key = r.Header.Get("X-Api-Key")
+		if key != "task-token" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	require.NoError(t, newClient(srv.URL, "", "task-token").runTask(context.Background(), "retention"))
+	assert.Equal(t, "task-token", key)
+	assert.EqualError(t, newClient(srv.URL, "", "wrong").runTask(context.Background(), "retention"), "403: forbidden")
+	assert.EqualError(t, newClient(srv.URL, "", "").runTask(context.Background(), "retention"), "LEAP_TASK_TOKEN is not set")
+}
@@ -28,6 +28,12 @@ func MustSetEnv(active bool, key string) string {
 	return os.Getenv(key)
 }
 
+// usesFileProvider reports whether the primary or the replica file storage
+// uses provider, so its credentials are needed.
+func usesFileProvider(provider string) bool {
+	return os.Getenv("FILE_PROVIDER") == provider || os.Getenv("FILE_REPLICA_PROVIDER") == provider
+}
+
 type Config struct {
 	// General
 	LogLevel  string
@@ -38,6 +44,18 @@ type Config struct {
 	AdminURL  string
 	ClientURL string
 	TaskToken string
+	// AnonymizationKey keys the pseudonymous actor IDs given to deleted users'
+	// analytics. Changing it breaks the link between a user's old and new rows.
+	AnonymizationKey string
+	// SignedURLKey signs expiring download links that work without an access
+	// token; unset disables them
+	SignedURLKey string
+	// AssetCDNURL is a CDN serving the file bucket. H5P library assets and
+	// content files are redirected there instead of proxied; unset proxies them
+	AssetCDNURL string
+	// AssetCDNSigningKey signs CDN redirects with an expiry for the CDN to
+	// check. Content files are only redirected when it's set
+	AssetCDNSigningKey string
 
 	// Constants
 	MaxFileSize     int64
@@ -83,6 +101,9 @@ type Config struct {
 	StripePriceEnterpriseMonthly string
 	StripePriceEnterpriseYearly  string
 	StripeBillingWebhookSecret   string
+	// StripeCheckoutUIMode is the default Checkout UI: "hosted" (redirect, the
+	// default) or "embedded" (mounted in our page with Stripe.js)
+	StripeCheckoutUIMode string
 
 	// Email
 	EmailProvider string
@@ -120,12 +141,50 @@ type Config struct {
 	// Azure Blob Storage
 	AzblobAccountName string
 	AzblobAccountKey  string
+	// Replica bucket mirroring the primary, read from when the primary
+	// fails; empty FileReplicaProvider turns replication off
+	FileReplicaProvider   string
+	FileReplicaBucketName string
+	FileReplicaRegion     string // overrides S3Region for the replica
+	FileReplicaEndpoint   string // overrides R2Endpoint for the replica
+
+	// Browser rendering
+	BrowserProvider  string
+	BrowserWorkerURL string
+	ChromePath       string
+
+	// Jina Reader (content extraction); works without a key at a lower rate limit
+	JinaAPIKey string
+
+	// DataForSEO (keyword rank checks); unset login disables them
+	DataForSEOLogin    string
+	DataForSEOPassword string
+	// DataForSEOMonthlyBudget caps spend in USD per calendar month, e.g. "50"
+	DataForSEOMonthlyBudget string
+	// PageSpeed Insights (page experience audits); unset disables them
+	PageSpeedAPIKey string
 
 	// H5P
 	H5PHubURL string
+	// H5PUploadLimits overrides per-field-type upload size limits, e.g. "image=5MB,video=2GB"
+	H5PUploadLimits string
+	// DevOfflineMode serves the H5P Hub from embedded fixture packages, so
+	// content types can be installed without network access (local
+	// development and CI). Set DEV_OFFLINE_MODE=true; never in production.
+	DevOfflineMode bool
 
 	// H5P State Save (DO flush)
 	StateServiceToken string
+
+	// DebugRecordCalls keeps the last N external API calls (DataForSEO,
+	// PageSpeed, H5P Hub, Stripe), sanitized, in memory for the admin debug
+	// endpoint, e.g. "200". Unset disables recording; leave it unset in
+	// production except while diagnosing an integration.
+	DebugRecordCalls string
+
+	// JobWorkers is how many background jobs each instance runs at once.
+	// Defaults to 4.
+	JobWorkers string
 }
 
 func LoadConfig() *Config {
@@ -146,6 +205,10 @@ func LoadConfig() *Config {
 		AdminURL:                     MustSetEnv(true, "ADMIN_URL"),
 		ClientURL:                    MustSetEnv(true, "CLIENT_URL"),
 		TaskToken:                    MustSetEnv(true, "TASK_TOKEN"),
+		AnonymizationKey:             MustSetEnv(true, "ANONYMIZATION_KEY"),
+		SignedURLKey:                 os.Getenv("SIGNED_URL_KEY"),
+		AssetCDNURL:                  os.Getenv("ASSET_CDN_URL"),
+		AssetCDNSigningKey:           os.Getenv("ASSET_CDN_SIGNING_KEY"),
 		HTTPTimeout:                  HTTPTimeout,
 		ContextTimeout:               ContextTimeout,
 		AccessTokenExp:               AccessTokenExp,
@@ -180,6 +243,7 @@ func LoadConfig() *Config {
 		StripePriceEnterpriseMonthly: os.Getenv("STRIPE_PRICE_ENTERPRISE_MONTHLY"),
 		StripePriceEnterpriseYearly:  os.Getenv("STRIPE_PRICE_ENTERPRISE_YEARLY"),
 		StripeBillingWebhookSecret:   os.Getenv("STRIPE_BILLING_WEBHOOK_SECRET"),
+		StripeCheckoutUIMode:         os.Getenv("STRIPE_CHECKOUT_UI_MODE"),
 		EmailProvider:                MustSetEnv(true, "EMAIL_PROVIDER"),
 		EmailFrom:                    MustSetEnv(true, "EMAIL_FROM"),
 		SendgridAPIKey:               MustSetEnv(os.Getenv("EMAIL_PROVIDER") == "sendgrid", "SENDGRID_API_KEY"),
@@ -195,17 +259,33 @@ func LoadConfig() *Config {
 		FileProvider:                 MustSetEnv(true, "FILE_PROVIDER"),
 		LocalFileDir:                 MustSetEnv(os.Getenv("FILE_PROVIDER") == "local", "LOCAL_FILE_DIR"),
 		BucketName:                   MustSetEnv(os.Getenv("FILE_PROVIDER") != "local", "BUCKET_NAME"),
-		S3Region:                     MustSetEnv(os.Getenv("FILE_PROVIDER") == "s3", "S3_REGION"),
-		S3AccessKey:                  MustSetEnv(os.Getenv("FILE_PROVIDER") == "s3", "S3_ACCESS_KEY"),
-		S3SecretKey:                  MustSetEnv(os.Getenv("FILE_PROVIDER") == "s3", "S3_SECRET_KEY"),
-		R2AccessKey:                  MustSetEnv(os.Getenv("FILE_PROVIDER") == "r2", "R2_ACCESS_KEY"),
-		R2SecretKey:                  MustSetEnv(os.Getenv("FILE_PROVIDER") == "r2", "R2_SECRET_KEY"),
-		R2Endpoint:                   MustSetEnv(os.Getenv("FILE_PROVIDER") == "r2", "R2_ENDPOINT"),
+		S3Region:                     MustSetEnv(usesFileProvider("s3"), "S3_REGION"),
+		S3AccessKey:                  MustSetEnv(usesFileProvider("s3"), "S3_ACCESS_KEY"),
+		S3SecretKey:                  MustSetEnv(usesFileProvider("s3"), "S3_SECRET_KEY"),
+		R2AccessKey:                  MustSetEnv(usesFileProvider("r2"), "R2_ACCESS_KEY"),
+		R2SecretKey:                  MustSetEnv(usesFileProvider("r2"), "R2_SECRET_KEY"),
+		R2Endpoint:                   MustSetEnv(usesFileProvider("r2"), "R2_ENDPOINT"),
 		GoogleApplicationCredentials: MustSetEnv(os.Getenv("FILE_PROVIDER") == "gcs", "GOOGLE_APPLICATION_CREDENTIALS"),
 		AzblobAccountName:            MustSetEnv(os.Getenv("FILE_PROVIDER") == "azblob", "AZBLOB_ACCOUNT_NAME"),
 		AzblobAccountKey:             MustSetEnv(os.Getenv("FILE_PROVIDER") == "azblob", "AZBLOB_ACCOUNT_KEY"),
+		FileReplicaProvider:          os.Getenv("FILE_REPLICA_PROVIDER"),
+		FileReplicaBucketName:        MustSetEnv(os.Getenv("FILE_REPLICA_PROVIDER") != "", "FILE_REPLICA_BUCKET_NAME"),
+		FileReplicaRegion:            os.Getenv("FILE_REPLICA_REGION"),
+		FileReplicaEndpoint:          os.Getenv("FILE_REPLICA_ENDPOINT"),
+		BrowserProvider:              os.Getenv("BROWSER_PROVIDER"), // "cloudflare" (default) or "local"
+		BrowserWorkerURL:             MustSetEnv(os.Getenv("BROWSER_PROVIDER") != "local", "BROWSER_WORKER_URL"),
+		ChromePath:                   os.Getenv("CHROME_PATH"), // optional, looked up on PATH when empty
+		JinaAPIKey:                   os.Getenv("JINA_API_KEY"),
+		DataForSEOLogin:              os.Getenv("DATAFORSEO_LOGIN"),
+		DataForSEOPassword:           MustSetEnv(os.Getenv("DATAFORSEO_LOGIN") != "", "DATAFORSEO_PASSWORD"),
+		DataForSEOMonthlyBudget:      os.Getenv("DATAFORSEO_MONTHLY_BUDGET"),
+		PageSpeedAPIKey:              os.Getenv("PAGESPEED_API_KEY"),
 		H5PHubURL:                    os.Getenv("H5P_HUB_URL"), // defaults to https://hub-api.h5p.org in service
+		H5PUploadLimits:              os.Getenv("H5P_UPLOAD_LIMITS"),
+		DevOfflineMode:               os.Getenv("DEV_OFFLINE_MODE") == "true",
 		StateServiceToken:            os.Getenv("STATE_SERVICE_TOKEN"),
+		DebugRecordCalls:             os.Getenv("DEBUG_RECORD_CALLS"),
+		JobWorkers:                   os.Getenv("JOB_WORKERS"),
 	}
 }
 
@@ -227,6 +307,8 @@ func LoadTestConfig() *Config {
 		AdminURL:                     "http://localhost:8080",
 		ClientURL:                    "http://localhost:3000",
 		TaskToken:                    "test",
+		AnonymizationKey:             "test-anonymization-key",
+		SignedURLKey:                 "test-signed-url-key",
 		HTTPTimeout:                  HTTPTimeout,
 		ContextTimeout:               ContextTimeout,
 		AccessTokenExp:               AccessTokenExp,
@@ -260,6 +342,7 @@ func LoadTestConfig() *Config {
 		StripePriceEnterpriseMonthly: "price_enterprise_monthly_test",
 		StripePriceEnterpriseYearly:  "price_enterprise_yearly_test",
 		StripeBillingWebhookSecret:   "billing_webhook_secret_test",
+		StripeCheckoutUIMode:         "hosted",
 		EmailProvider:                "sendgrid",
 		EmailFrom:                    "email_from",
 		SendgridAPIKey:               "sendgrid_api_key",
@@ -280,6 +363,8 @@ func LoadTestConfig() *Config {
 		GoogleApplicationCredentials: "google_application_credentials",
 		AzblobAccountName:            "azblob_account_name",
 		AzblobAccountKey:             "azblob_account_key",
+		BrowserProvider:              "cloudflare",
+		BrowserWorkerURL:             "http://localhost:8787",
 		H5PHubURL:                    "https://hub-api.h5p.org",
 		StateServiceToken:            "test-state-service-token",
 	}
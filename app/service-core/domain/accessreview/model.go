@@ -0,0 +1,107 @@
+package accessreview
+
+import (
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Review statuses.
+const (
+	StatusPending = "pending"
+	StatusReady   = "ready"
+	StatusFailed  = "failed"
+)
+
+// Report formats.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Review is a queued or generated access review.
+type Review struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganisationID uuid.UUID  `json:"organisationId"`
+	RequestedBy    *uuid.UUID `json:"requestedBy"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	CompletedAt    *time.Time `json:"completedAt"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+	Error          string     `json:"error,omitempty"`
+}
+
+func newReview(r query.AccessReview) Review {
+	review := Review{
+		ID:             r.ID,
+		OrganisationID: r.OrganisationID,
+		Status:         r.Status,
+		CreatedAt:      r.CreatedAt,
+		Error:          r.Error,
+	}
+	if r.RequestedBy.Valid {
+		review.RequestedBy = &r.RequestedBy.UUID
+	}
+	if r.CompletedAt.Valid {
+		review.CompletedAt = &r.CompletedAt.Time
+	}
+	if r.ExpiresAt.Valid {
+		review.ExpiresAt = &r.ExpiresAt.Time
+	}
+	return review
+}
+
+// Report is the content of an access review.
+type Report struct {
+	OrganisationID   uuid.UUID `json:"organisationId"`
+	OrganisationName string    `json:"organisationName"`
+	GeneratedAt      time.Time `json:"generatedAt"`
+	Members          []Member  `json:"members"`
+	PendingInvites   []Invite  `json:"pendingInvites"`
+	APIKeys          []APIKey  `json:"apiKeys"`
+	Webhooks         []Webhook `json:"webhooks"`
+	// Notes explain gaps an auditor would otherwise ask about
+	Notes []string `json:"notes"`
+}
+
+// Member is an accepted membership.
+type Member struct {
+	UserID           uuid.UUID `json:"userId"`
+	Email            string    `json:"email"`
+	DisplayName      string    `json:"displayName"`
+	Role             string    `json:"role"`
+	Status           string    `json:"status"` // "active" or "suspended" in this organisation
+	AccountSuspended bool      `json:"accountSuspended"`
+	SignInMethod     string    `json:"signInMethod"`
+	JoinedAt         time.Time `json:"joinedAt"`
+	InvitedBy        string    `json:"invitedBy"`
+}
+
+// Invite is a membership whose invitation hasn't been accepted.
+type Invite struct {
+	UserID    uuid.UUID  `json:"userId"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	InvitedAt *time.Time `json:"invitedAt"`
+	InvitedBy string     `json:"invitedBy"`
+}
+
+// APIKey is a member's personal API key. Keys carry the member's own access.
+type APIKey struct {
+	UserID     uuid.UUID  `json:"userId"`
+	Email      string     `json:"email"`
+	Scope      string     `json:"scope"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
+
+// Webhook is an outbound integration that receives organisation events.
+type Webhook struct {
+	ID             uuid.UUID  `json:"id"`
+	URL            string     `json:"url"`
+	Events         []string   `json:"events"` // empty = all events
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastDeliveryAt *time.Time `json:"lastDeliveryAt"`
+}
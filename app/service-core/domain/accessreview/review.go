@@ -0,0 +1,155 @@
+package accessreview
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"service-core/domain/file"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Request queues a review of one organisation. requestedBy is the super
+// admin who asked for it.
+func (s *Service) Request(ctx context.Context, orgID, requestedBy uuid.UUID) (*Review, error) {
+	if _, err := s.store.GetOrganisationBillingInfo(ctx, orgID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Organisation not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error loading organisation", Err: err}
+	}
+	row, err := s.store.CreateAccessReview(ctx, query.CreateAccessReviewParams{
+		OrganisationID: orgID,
+		RequestedBy:    uuid.NullUUID{UUID: requestedBy, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error creating access review", Err: err}
+	}
+	review := newReview(row)
+	return &review, nil
+}
+
+// RequestAll queues a review of every active organisation, e.g. ahead of an
+// audit period. It returns the number of reviews queued.
+func (s *Service) RequestAll(ctx context.Context, requestedBy uuid.UUID) (int64, error) {
+	count, err := s.store.CreateAccessReviewsForActiveOrganisations(ctx, uuid.NullUUID{UUID: requestedBy, Valid: true})
+	if err != nil {
+		return 0, pkg.InternalError{Message: "Error creating access reviews", Err: err}
+	}
+	return count, nil
+}
+
+// List returns an organisation's reviews, newest first.
+func (s *Service) List(ctx context.Context, orgID uuid.UUID) ([]Review, error) {
+	rows, err := s.store.ListAccessReviews(ctx, orgID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing access reviews", Err: err}
+	}
+	reviews := make([]Review, len(rows))
+	for i, row := range rows {
+		reviews[i] = newReview(row)
+	}
+	return reviews, nil
+}
+
+// Download returns a ready review's report in the given format along with
+// its filename.
+func (s *Service) Download(ctx context.Context, id uuid.UUID, format string) ([]byte, string, error) {
+	if format != FormatJSON && format != FormatCSV {
+		return nil, "", pkg.BadRequestError{Message: "format must be json or csv", Err: fmt.Errorf("invalid format %q", format)}
+	}
+	review, err := s.store.GetAccessReview(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", pkg.NotFoundError{Message: "Access review not found", Err: err}
+	}
+	if err != nil {
+		return nil, "", pkg.InternalError{Message: "Error loading access review", Err: err}
+	}
+	if review.Status != StatusReady {
+		return nil, "", pkg.BadRequestError{Message: "Access review is " + review.Status, Err: errors.New("access review not ready")}
+	}
+
+	data, err := s.files.Download(ctx, reportKey(review, format))
+	if err != nil {
+		return nil, "", pkg.InternalError{Message: "Error downloading access review", Err: err}
+	}
+	filename := fmt.Sprintf("access-review-%s-%s.%s", review.OrganisationID, review.CreatedAt.UTC().Format("2006-01-02"), format)
+	return data, filename, nil
+}
+
+// ProcessPending generates queued reviews. A review that can't be generated
+// is marked failed rather than retried, so one bad organisation doesn't block
+// the queue. It returns the number of reviews processed.
+func (s *Service) ProcessPending(ctx context.Context) (int, error) {
+	reviews, err := s.store.ListPendingAccessReviews(ctx, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("listing pending access reviews: %w", err)
+	}
+	for _, review := range reviews {
+		expiresAt := time.Now().Add(retention)
+		if err := s.generate(ctx, review); err != nil {
+			slog.Error("Failed to generate access review", "review", review.ID, "organisation", review.OrganisationID, "error", err)
+			if err := s.store.FailAccessReview(ctx, query.FailAccessReviewParams{ID: review.ID, Error: err.Error(), ExpiresAt: expiresAt}); err != nil {
+				return 0, fmt.Errorf("marking access review %s failed: %w", review.ID, err)
+			}
+			continue
+		}
+		if err := s.store.CompleteAccessReview(ctx, query.CompleteAccessReviewParams{ID: review.ID, ExpiresAt: expiresAt}); err != nil {
+			return 0, fmt.Errorf("completing access review %s: %w", review.ID, err)
+		}
+	}
+	return len(reviews), nil
+}
+
+// generate builds a review's report and stores it in both formats.
+func (s *Service) generate(ctx context.Context, review query.AccessReview) error {
+	report, err := s.buildReport(ctx, review.OrganisationID)
+	if err != nil {
+		return err
+	}
+	jsonData, err := report.marshalJSON()
+	if err != nil {
+		return fmt.Errorf("encoding json: %w", err)
+	}
+	csvData, err := report.marshalCSV()
+	if err != nil {
+		return fmt.Errorf("encoding csv: %w", err)
+	}
+	for _, f := range []*file.File{
+		{Key: reportKey(review, FormatJSON), ContentType: "application/json", Data: jsonData},
+		{Key: reportKey(review, FormatCSV), ContentType: "text/csv", Data: csvData},
+	} {
+		if err := s.files.Upload(ctx, f); err != nil {
+			return fmt.Errorf("uploading %s: %w", f.Key, err)
+		}
+	}
+	return nil
+}
+
+// PurgeExpired deletes reviews past their retention period along with their
+// files. It returns the number of reviews deleted.
+func (s *Service) PurgeExpired(ctx context.Context) (int, error) {
+	reviews, err := s.store.ListExpiredAccessReviews(ctx, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("listing expired access reviews: %w", err)
+	}
+	for _, review := range reviews {
+		if review.Status == StatusReady {
+			for _, format := range []string{FormatJSON, FormatCSV} {
+				if err := s.files.Remove(ctx, reportKey(review, format)); err != nil {
+					return 0, fmt.Errorf("removing access review %s: %w", review.ID, err)
+				}
+			}
+		}
+		if err := s.store.DeleteAccessReview(ctx, review.ID); err != nil {
+			return 0, fmt.Errorf("deleting access review %s: %w", review.ID, err)
+		}
+	}
+	return len(reviews), nil
+}
@@ -0,0 +1,54 @@
+package accessreview
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"service-core/domain/file"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// retention is how long a review's files are kept: long enough that last
+	// year's review is still there for the next annual audit.
+	retention = 400 * 24 * time.Hour
+	// batchSize caps the reviews generated or purged per task run.
+	batchSize = 50
+)
+
+// store defines the database interface for access reviews
+type store interface {
+	CreateAccessReview(ctx context.Context, arg query.CreateAccessReviewParams) (query.AccessReview, error)
+	CreateAccessReviewsForActiveOrganisations(ctx context.Context, requestedBy uuid.NullUUID) (int64, error)
+	GetAccessReview(ctx context.Context, id uuid.UUID) (query.AccessReview, error)
+	ListAccessReviews(ctx context.Context, organisationID uuid.UUID) ([]query.AccessReview, error)
+	ListPendingAccessReviews(ctx context.Context, limit int32) ([]query.AccessReview, error)
+	CompleteAccessReview(ctx context.Context, arg query.CompleteAccessReviewParams) error
+	FailAccessReview(ctx context.Context, arg query.FailAccessReviewParams) error
+	ListExpiredAccessReviews(ctx context.Context, limit int32) ([]query.AccessReview, error)
+	DeleteAccessReview(ctx context.Context, id uuid.UUID) error
+	GetOrganisationBillingInfo(ctx context.Context, id uuid.UUID) (query.GetOrganisationBillingInfoRow, error)
+	ListAccessReviewMembers(ctx context.Context, organisationID uuid.UUID) ([]query.ListAccessReviewMembersRow, error)
+	ListOrganisationWebhooks(ctx context.Context, organisationID uuid.UUID) ([]query.OrganisationWebhook, error)
+}
+
+// Service produces access review reports: who can reach an organisation's
+// data, in what role and by what means. Reviews are queued, then generated by
+// a scheduled task and kept for the retention period.
+type Service struct {
+	store store
+	files file.Provider
+}
+
+// NewService creates a new access review service
+func NewService(store store, files file.Provider) *Service {
+	return &Service{store: store, files: files}
+}
+
+// reportKey is where a review's report is stored in the given format.
+func reportKey(review query.AccessReview, format string) string {
+	return fmt.Sprintf("access-reviews/%s/%s.%s", review.OrganisationID, review.ID, format)
+}
@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// ActorID returns the pseudonymous ID that replaces userID in anonymized
+// analytics. It is stable for a given ANONYMIZATION_KEY, so a user's rows
+// still group together, but can't be reversed without the key.
+func (s *Service) ActorID(userID uuid.UUID) uuid.UUID {
+	mac := hmac.New(sha256.New, []byte(s.cfg.AnonymizationKey))
+	mac.Write(userID[:])
+	var id uuid.UUID
+	copy(id[:], mac.Sum(nil))
+	// Mark as a version 8 (custom) RFC 9562 UUID
+	id[6] = (id[6] & 0x0f) | 0x80
+	id[8] = (id[8] & 0x3f) | 0x80
+	return id
+}
+
+// AnonymizeUser detaches a user's enrolments, progress and xAPI statements
+// from their account so they survive the account's deletion. Rows keep their
+// scores and timings but reference the pseudonymous actor ID instead of the
+// user. xAPI statements also lose their actor details, authority, instructor,
+// team and free-text responses.
+//
+// Each step only touches rows still linked to the user, so a failed run can
+// simply be retried.
+func (s *Service) AnonymizeUser(ctx context.Context, userID uuid.UUID) (*AnonymizationResult, error) {
+	actorID := s.ActorID(userID)
+	actor, err := json.Marshal(map[string]any{
+		"objectType": "Agent",
+		"account": map[string]string{
+			"homePage": s.cfg.ClientURL,
+			"name":     actorID.String(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding anonymous actor: %w", err)
+	}
+
+	result := &AnonymizationResult{UserID: userID, ActorID: actorID}
+	result.XapiStatements, err = s.store.AnonymizeUserXapiStatements(ctx, query.AnonymizeUserXapiStatementsParams{
+		ActorID: actorID,
+		Actor:   actor,
+		UserID:  userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anonymizing xAPI statements: %w", err)
+	}
+	result.ProgressRecords, err = s.store.AnonymizeUserProgressRecords(ctx, query.AnonymizeUserProgressRecordsParams{
+		ActorID: actorID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anonymizing progress records: %w", err)
+	}
+	result.Enrolments, err = s.store.AnonymizeUserEnrolments(ctx, query.AnonymizeUserEnrolmentsParams{
+		ActorID: actorID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anonymizing enrolments: %w", err)
+	}
+	return result, nil
+}
@@ -1 +1,12 @@
 package analytics
+
+import "github.com/google/uuid"
+
+// AnonymizationResult reports what AnonymizeUser detached from a user.
+type AnonymizationResult struct {
+	UserID          uuid.UUID `json:"userId"`
+	ActorID         uuid.UUID `json:"actorId"`
+	Enrolments      int64     `json:"enrolments"`
+	ProgressRecords int64     `json:"progressRecords"`
+	XapiStatements  int64     `json:"xapiStatements"`
+}
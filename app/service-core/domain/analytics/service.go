@@ -1 +1,30 @@
 package analytics
+
+import (
+	"context"
+
+	"service-core/config"
+	"service-core/storage/query"
+)
+
+// store defines the database interface for analytics operations
+type store interface {
+	AnonymizeUserEnrolments(ctx context.Context, arg query.AnonymizeUserEnrolmentsParams) (int64, error)
+	AnonymizeUserProgressRecords(ctx context.Context, arg query.AnonymizeUserProgressRecordsParams) (int64, error)
+	AnonymizeUserXapiStatements(ctx context.Context, arg query.AnonymizeUserXapiStatementsParams) (int64, error)
+	RecordXapiStatement(ctx context.Context, arg query.RecordXapiStatementParams) (int64, error)
+	GetXapiContentSummary(ctx context.Context, arg query.GetXapiContentSummaryParams) (query.GetXapiContentSummaryRow, error)
+	ListXapiContentLearners(ctx context.Context, arg query.ListXapiContentLearnersParams) ([]query.ListXapiContentLearnersRow, error)
+	ListXapiLearnerSummary(ctx context.Context, arg query.ListXapiLearnerSummaryParams) ([]query.ListXapiLearnerSummaryRow, error)
+}
+
+// Service manages learner analytics data
+type Service struct {
+	cfg   *config.Config
+	store store
+}
+
+// NewService creates a new analytics service
+func NewService(cfg *config.Config, store store) *Service {
+	return &Service{cfg: cfg, store: store}
+}
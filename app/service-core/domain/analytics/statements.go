@@ -0,0 +1,253 @@
+package analytics
+
+import (
+	"app/pkg"
+	"app/pkg/ids"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// MaxStatementsPerRequest caps the statements one request can post
+const MaxStatementsPerRequest = 50
+
+// durationPattern matches ISO 8601 durations, e.g. PT1M30.5S
+var durationPattern = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// Seconds in each durationPattern group; years and months are approximate
+var durationUnits = []float64{365 * 86400, 30 * 86400, 7 * 86400, 86400, 3600, 60, 1}
+
+// statement holds the parts of an xAPI statement that are validated and
+// copied into columns. Everything else is kept only in the raw statement.
+type statement struct {
+	ID    string          `json:"id"`
+	Actor json.RawMessage `json:"actor"`
+	Verb  *struct {
+		ID string `json:"id"`
+	} `json:"verb"`
+	Object *struct {
+		ObjectType string `json:"objectType"`
+		ID         string `json:"id"`
+	} `json:"object"`
+	Result *struct {
+		Score *struct {
+			Scaled *float64 `json:"scaled"`
+			Raw    *float64 `json:"raw"`
+			Min    *float64 `json:"min"`
+			Max    *float64 `json:"max"`
+		} `json:"score"`
+		Success    *bool  `json:"success"`
+		Completion *bool  `json:"completion"`
+		Duration   string `json:"duration"`
+	} `json:"result"`
+}
+
+// validate checks the statement's structure against the xAPI spec, as far as
+// the columns it fills depend on it.
+func (st *statement) validate() error {
+	var actor map[string]any
+	if json.Unmarshal(st.Actor, &actor) != nil || actor == nil {
+		return fmt.Errorf("actor must be an object")
+	}
+	if mbox, ok := actor["mbox"].(string); ok && !strings.HasPrefix(mbox, "mailto:") {
+		return fmt.Errorf("actor.mbox must be a mailto: IRI")
+	}
+	if st.Verb == nil || !isIRI(st.Verb.ID) {
+		return fmt.Errorf("verb.id must be an IRI")
+	}
+	if st.Object == nil {
+		return fmt.Errorf("object is required")
+	}
+	switch st.Object.ObjectType {
+	case "", "Activity":
+		if !isIRI(st.Object.ID) {
+			return fmt.Errorf("object.id must be an IRI")
+		}
+	case "Agent", "Group", "SubStatement", "StatementRef":
+	default:
+		return fmt.Errorf("unknown object.objectType %q", st.Object.ObjectType)
+	}
+	if st.ID != "" {
+		if _, err := uuid.Parse(st.ID); err != nil {
+			return fmt.Errorf("id must be a UUID")
+		}
+	}
+
+	if st.Result == nil {
+		return nil
+	}
+	if score := st.Result.Score; score != nil {
+		if score.Scaled != nil && (*score.Scaled < -1 || *score.Scaled > 1) {
+			return fmt.Errorf("result.score.scaled must be between -1 and 1")
+		}
+		if score.Min != nil && score.Max != nil && *score.Min >= *score.Max {
+			return fmt.Errorf("result.score.min must be less than result.score.max")
+		}
+		if score.Raw != nil && ((score.Min != nil && *score.Raw < *score.Min) || (score.Max != nil && *score.Raw > *score.Max)) {
+			return fmt.Errorf("result.score.raw must be between result.score.min and result.score.max")
+		}
+	}
+	if st.Result.Duration != "" {
+		if _, ok := durationSeconds(st.Result.Duration); !ok {
+			return fmt.Errorf("result.duration must be an ISO 8601 duration")
+		}
+	}
+	return nil
+}
+
+func isIRI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != ""
+}
+
+// durationSeconds converts an ISO 8601 duration to whole seconds
+func durationSeconds(iso string) (int32, bool) {
+	m := durationPattern.FindStringSubmatch(iso)
+	if m == nil || iso == "P" || strings.HasSuffix(iso, "T") {
+		return 0, false
+	}
+	var total float64
+	for i, unit := range durationUnits {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(m[i+1], 64)
+		if err != nil {
+			return 0, false
+		}
+		total += n * unit
+	}
+	if total > float64(1<<31-1) {
+		return 0, false
+	}
+	return int32(total), true
+}
+
+// verbName is the last segment of a verb IRI, e.g. "completed"
+func verbName(verbID string) string {
+	name := verbID[strings.LastIndexAny(verbID, "/#")+1:]
+	if name == "" {
+		return verbID
+	}
+	return name
+}
+
+// StoredStatement is a validated statement ready to be recorded. Its raw JSON
+// carries the statement's ID, assigned here if the client didn't set one.
+type StoredStatement struct {
+	ID   uuid.UUID
+	Verb string // the verb's short name
+	Raw  json.RawMessage
+
+	parsed statement
+}
+
+// ParseStatements validates a request body holding one xAPI statement or an
+// array of them.
+func ParseStatements(body json.RawMessage) ([]StoredStatement, error) {
+	var raws []json.RawMessage
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raws); err != nil {
+			return nil, pkg.BadRequestError{Message: "Invalid statements"}
+		}
+	} else {
+		raws = []json.RawMessage{body}
+	}
+	if len(raws) == 0 || len(raws) > MaxStatementsPerRequest {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("Post 1 to %d statements at a time", MaxStatementsPerRequest)}
+	}
+
+	result := make([]StoredStatement, len(raws))
+	for i, raw := range raws {
+		st, err := parseStatement(raw)
+		if err != nil {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("Statement %d: %s", i+1, err)}
+		}
+		result[i] = *st
+	}
+	return result, nil
+}
+
+func parseStatement(raw json.RawMessage) (*StoredStatement, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("must be a JSON object")
+	}
+	var st statement
+	if err := json.Unmarshal(raw, &st); err != nil {
+		return nil, fmt.Errorf("invalid structure: %w", err)
+	}
+	if err := st.validate(); err != nil {
+		return nil, err
+	}
+
+	id := ids.New()
+	if st.ID != "" {
+		id = uuid.MustParse(st.ID)
+	}
+	fields["id"], _ = json.Marshal(id)
+	normalized, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &StoredStatement{ID: id, Verb: verbName(st.Verb.ID), Raw: normalized, parsed: st}, nil
+}
+
+// RecordStatements stores statements a learner posted about content. A
+// statement whose ID was already recorded is skipped, so clients can safely
+// resend a batch.
+func (s *Service) RecordStatements(ctx context.Context, orgID, userID, contentID uuid.UUID, statements []StoredStatement) error {
+	for _, st := range statements {
+		arg := query.RecordXapiStatementParams{
+			OrgID:       orgID,
+			UserID:      uuid.NullUUID{UUID: userID, Valid: true},
+			ContentID:   uuid.NullUUID{UUID: contentID, Valid: true},
+			Verb:        st.Verb,
+			Statement:   st.Raw,
+			StatementID: uuid.NullUUID{UUID: st.ID, Valid: true},
+			VerbID:      sql.NullString{String: st.parsed.Verb.ID, Valid: true},
+			ObjectID:    sql.NullString{String: st.parsed.Object.ID, Valid: st.parsed.Object.ID != ""},
+		}
+		if r := st.parsed.Result; r != nil {
+			if r.Score != nil {
+				arg.ResultScoreRaw = nullFloat(r.Score.Raw)
+				arg.ResultScoreMax = nullFloat(r.Score.Max)
+				arg.ResultScoreScaled = nullFloat(r.Score.Scaled)
+				// H5P reports raw and max but rarely scaled
+				if r.Score.Scaled == nil && r.Score.Raw != nil && r.Score.Max != nil && *r.Score.Max > 0 && *r.Score.Raw >= 0 && *r.Score.Raw <= *r.Score.Max {
+					arg.ResultScoreScaled = sql.NullFloat64{Float64: *r.Score.Raw / *r.Score.Max, Valid: true}
+				}
+			}
+			if r.Success != nil {
+				arg.ResultSuccess = sql.NullBool{Bool: *r.Success, Valid: true}
+			}
+			if r.Completion != nil {
+				arg.ResultCompletion = sql.NullBool{Bool: *r.Completion, Valid: true}
+			}
+			if seconds, ok := durationSeconds(r.Duration); ok {
+				arg.ResultDuration = sql.NullInt32{Int32: seconds, Valid: true}
+			}
+		}
+		if _, err := s.store.RecordXapiStatement(ctx, arg); err != nil {
+			return pkg.InternalError{Message: "Error recording xAPI statement", Err: err}
+		}
+	}
+	return nil
+}
+
+func nullFloat(f *float64) sql.NullFloat64 {
+	if f == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *f, Valid: true}
+}
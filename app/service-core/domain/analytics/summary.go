@@ -0,0 +1,135 @@
+package analytics
+
+import (
+	"app/pkg"
+	"context"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// ContentSummary is how learners did on one piece of content, from the xAPI
+// statements they posted. A learner completed the content once any statement
+// reported completion, and passed it once any reported success.
+type ContentSummary struct {
+	ContentID  uuid.UUID `json:"contentId"`
+	Learners   int64     `json:"learners"`
+	Statements int64     `json:"statements"`
+	Completed  int64     `json:"completed"`
+	Passed     int64     `json:"passed"`
+	// AverageBestScore averages each scored learner's best scaled score (0-1);
+	// nil if nobody was scored
+	AverageBestScore *float64        `json:"averageBestScore"`
+	LearnerResults   []LearnerResult `json:"learnerResults"`
+}
+
+// LearnerResult is one learner's results on one piece of content. Anonymized
+// learners have no user ID or email.
+type LearnerResult struct {
+	LearnerID      uuid.UUID  `json:"learnerId"`
+	UserID         *uuid.UUID `json:"userId"`
+	Email          string     `json:"email,omitempty"`
+	Statements     int64      `json:"statements"`
+	Completed      bool       `json:"completed"`
+	Passed         bool       `json:"passed"`
+	BestScore      *float64   `json:"bestScore"` // scaled, 0-1
+	LastActivityAt time.Time  `json:"lastActivityAt"`
+}
+
+// ContentResult is a learner's results on one piece of content
+type ContentResult struct {
+	ContentID      uuid.UUID `json:"contentId"`
+	Title          string    `json:"title"`
+	Statements     int64     `json:"statements"`
+	Completed      bool      `json:"completed"`
+	Passed         bool      `json:"passed"`
+	BestScore      *float64  `json:"bestScore"` // scaled, 0-1
+	LastActivityAt time.Time `json:"lastActivityAt"`
+}
+
+// scorePtr returns a best score, or nil when none of the statements were scored
+func scorePtr(scored int64, score float64) *float64 {
+	if scored == 0 {
+		return nil
+	}
+	return &score
+}
+
+// ContentSummary summarises completion and scores on content, with a page of
+// per-learner results, most recently active first.
+func (s *Service) ContentSummary(ctx context.Context, orgID, contentID uuid.UUID, limit, offset int32) (*ContentSummary, error) {
+	content := uuid.NullUUID{UUID: contentID, Valid: true}
+	totals, err := s.store.GetXapiContentSummary(ctx, query.GetXapiContentSummaryParams{
+		OrgID:     orgID,
+		ContentID: content,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error summarising xAPI statements", Err: err}
+	}
+	rows, err := s.store.ListXapiContentLearners(ctx, query.ListXapiContentLearnersParams{
+		OrgID:     orgID,
+		ContentID: content,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing learner results", Err: err}
+	}
+
+	summary := &ContentSummary{
+		ContentID:      contentID,
+		Learners:       totals.Learners,
+		Statements:     totals.Statements,
+		Completed:      totals.Completed,
+		Passed:         totals.Passed,
+		LearnerResults: make([]LearnerResult, len(rows)),
+	}
+	if totals.Scored > 0 {
+		summary.AverageBestScore = &totals.AverageBestScore
+	}
+	for i, row := range rows {
+		result := LearnerResult{
+			LearnerID:      row.LearnerID,
+			Email:          row.Email.String,
+			Statements:     row.Statements,
+			Completed:      row.Completed,
+			Passed:         row.Passed,
+			BestScore:      scorePtr(row.Scored, row.BestScore),
+			LastActivityAt: row.LastActivityAt,
+		}
+		if row.UserID.Valid {
+			result.UserID = &row.UserID.UUID
+		}
+		summary.LearnerResults[i] = result
+	}
+	return summary, nil
+}
+
+// LearnerSummary lists a learner's results on each piece of content they
+// posted statements about, most recently active first.
+func (s *Service) LearnerSummary(ctx context.Context, orgID, userID uuid.UUID, limit, offset int32) ([]ContentResult, error) {
+	rows, err := s.store.ListXapiLearnerSummary(ctx, query.ListXapiLearnerSummaryParams{
+		OrgID:  orgID,
+		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error summarising learner results", Err: err}
+	}
+	results := make([]ContentResult, len(rows))
+	for i, row := range rows {
+		results[i] = ContentResult{
+			ContentID:      row.ContentID,
+			Title:          row.Title,
+			Statements:     row.Statements,
+			Completed:      row.Completed,
+			Passed:         row.Passed,
+			BestScore:      scorePtr(row.Scored, row.BestScore),
+			LastActivityAt: row.LastActivityAt,
+		}
+	}
+	return results, nil
+}
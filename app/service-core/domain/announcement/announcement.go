@@ -0,0 +1,221 @@
+package announcement
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxTitleLength = 200
+	maxBodyLength  = 20000
+)
+
+// Create adds an announcement on behalf of a super admin.
+func (s *Service) Create(ctx context.Context, adminID uuid.UUID, req Request) (*Announcement, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+	row, err := s.store.CreateAnnouncement(ctx, query.CreateAnnouncementParams{
+		Title:       strings.TrimSpace(req.Title),
+		Body:        req.Body,
+		LinkUrl:     req.LinkURL,
+		Tiers:       orEmpty(req.Tiers),
+		Flags:       orEmpty(req.Flags),
+		PublishedAt: nullTime(req.PublishedAt),
+		ExpiresAt:   nullTime(req.ExpiresAt),
+		CreatedBy:   uuid.NullUUID{UUID: adminID, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error creating announcement", Err: err}
+	}
+	announcement := newAnnouncement(row)
+	return &announcement, nil
+}
+
+// Update replaces an announcement's content, audience and schedule. Users who
+// already read it aren't shown it again.
+func (s *Service) Update(ctx context.Context, id uuid.UUID, req Request) (*Announcement, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+	row, err := s.store.UpdateAnnouncement(ctx, query.UpdateAnnouncementParams{
+		ID:          id,
+		Title:       strings.TrimSpace(req.Title),
+		Body:        req.Body,
+		LinkUrl:     req.LinkURL,
+		Tiers:       orEmpty(req.Tiers),
+		Flags:       orEmpty(req.Flags),
+		PublishedAt: nullTime(req.PublishedAt),
+		ExpiresAt:   nullTime(req.ExpiresAt),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Announcement not found", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating announcement", Err: err}
+	}
+	announcement := newAnnouncement(row)
+	return &announcement, nil
+}
+
+// Delete removes an announcement and its read receipts.
+func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.store.DeleteAnnouncement(ctx, id)
+	if err != nil {
+		return pkg.InternalError{Message: "Error deleting announcement", Err: err}
+	}
+	if deleted == 0 {
+		return pkg.NotFoundError{Message: "Announcement not found", Err: sql.ErrNoRows}
+	}
+	return nil
+}
+
+// List returns every announcement, including drafts, newest first.
+func (s *Service) List(ctx context.Context) ([]Announcement, error) {
+	rows, err := s.store.ListAnnouncements(ctx)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing announcements", Err: err}
+	}
+	announcements := make([]Announcement, len(rows))
+	for i, row := range rows {
+		announcements[i] = newAnnouncement(row)
+	}
+	return announcements, nil
+}
+
+// Unread returns the published announcements the user hasn't read that target
+// them as a member of the given organisation, newest first.
+func (s *Service) Unread(ctx context.Context, userID, orgID uuid.UUID) ([]Announcement, error) {
+	v, err := s.viewer(ctx, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.store.ListUnreadAnnouncements(ctx, userID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing announcements", Err: err}
+	}
+	announcements := []Announcement{}
+	for _, row := range rows {
+		if targets(row, v) {
+			announcements = append(announcements, newAnnouncement(row))
+		}
+	}
+	return announcements, nil
+}
+
+// MarkRead records that the user has read an announcement. Marking one twice,
+// or one that isn't published, is a no-op.
+func (s *Service) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	err := s.store.MarkAnnouncementRead(ctx, query.MarkAnnouncementReadParams{UserID: userID, AnnouncementID: id})
+	if err != nil {
+		return pkg.InternalError{Message: "Error marking announcement read", Err: err}
+	}
+	return nil
+}
+
+// viewer loads the attributes targeting matches against. The user must be a
+// member of the organisation.
+func (s *Service) viewer(ctx context.Context, userID, orgID uuid.UUID) (viewer, error) {
+	role, err := s.store.GetOrgMembershipRole(ctx, query.GetOrgMembershipRoleParams{UserID: userID, OrganisationID: orgID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return viewer{}, pkg.ForbiddenError{Err: errors.New("not a member of this organisation")}
+	}
+	if err != nil {
+		return viewer{}, pkg.InternalError{Message: "Error loading membership", Err: err}
+	}
+	org, err := s.store.GetOrganisationBillingInfo(ctx, orgID)
+	if err != nil {
+		return viewer{}, pkg.InternalError{Message: "Error loading organisation", Err: err}
+	}
+	freemium := org.IsFreemium && (!org.FreemiumExpiresAt.Valid || s.now().Before(org.FreemiumExpiresAt.Time))
+	return viewer{tier: org.SubscriptionTier, freemium: freemium, role: role}, nil
+}
+
+// targets reports whether an announcement's audience includes the viewer.
+func targets(a query.Announcement, v viewer) bool {
+	if len(a.Tiers) > 0 && !slices.Contains(a.Tiers, v.tier) {
+		return false
+	}
+	for _, flag := range a.Flags {
+		switch flag {
+		case FlagFreemium:
+			if !v.freemium {
+				return false
+			}
+		case FlagOrgAdmin:
+			if v.role != "owner" && v.role != "admin" {
+				return false
+			}
+		case FlagOwner:
+			if v.role != "owner" {
+				return false
+			}
+		default:
+			// A flag this build doesn't know can't be matched
+			return false
+		}
+	}
+	return true
+}
+
+func validateRequest(req Request) error {
+	title := strings.TrimSpace(req.Title)
+	if title == "" || utf8.RuneCountInString(title) > maxTitleLength {
+		return pkg.BadRequestError{Message: fmt.Sprintf("title must be between 1 and %d characters", maxTitleLength)}
+	}
+	if utf8.RuneCountInString(req.Body) > maxBodyLength {
+		return pkg.BadRequestError{Message: fmt.Sprintf("body must be at most %d characters", maxBodyLength)}
+	}
+	if req.LinkURL != "" && !validLink(req.LinkURL) {
+		return pkg.BadRequestError{Message: "linkUrl must be an app path starting with / or an https URL"}
+	}
+	for _, tier := range req.Tiers {
+		if !slices.Contains(knownTiers, tier) {
+			return pkg.BadRequestError{Message: fmt.Sprintf("unknown tier %q", tier)}
+		}
+	}
+	for _, flag := range req.Flags {
+		if !slices.Contains(knownFlags, flag) {
+			return pkg.BadRequestError{Message: fmt.Sprintf("unknown flag %q", flag)}
+		}
+	}
+	if req.PublishedAt != nil && req.ExpiresAt != nil && !req.ExpiresAt.After(*req.PublishedAt) {
+		return pkg.BadRequestError{Message: "expiresAt must be after publishedAt"}
+	}
+	return nil
+}
+
+// validLink accepts in-app paths and absolute https URLs.
+func validLink(link string) bool {
+	if strings.HasPrefix(link, "/") {
+		return !strings.HasPrefix(link, "//")
+	}
+	u, err := url.Parse(link)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
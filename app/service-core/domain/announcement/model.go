@@ -0,0 +1,85 @@
+package announcement
+
+import (
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Audience flags narrow an announcement beyond subscription tier. A viewer
+// must match every flag the announcement lists.
+const (
+	// FlagFreemium targets organisations with an active freemium grant.
+	FlagFreemium = "freemium"
+	// FlagOrgAdmin targets organisation owners and admins.
+	FlagOrgAdmin = "org-admin"
+	// FlagOwner targets organisation owners.
+	FlagOwner = "owner"
+)
+
+var knownFlags = []string{FlagFreemium, FlagOrgAdmin, FlagOwner}
+
+// knownTiers are the subscription tiers an announcement can target.
+var knownTiers = []string{"free", "starter", "growth", "enterprise"}
+
+// Announcement is a platform announcement. Body is markdown; LinkURL, when
+// set, is where the notification center sends the user for details.
+type Announcement struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	LinkURL     string     `json:"linkUrl"`
+	Tiers       []string   `json:"tiers"`
+	Flags       []string   `json:"flags"`
+	PublishedAt *time.Time `json:"publishedAt"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func newAnnouncement(a query.Announcement) Announcement {
+	announcement := Announcement{
+		ID:        a.ID,
+		Title:     a.Title,
+		Body:      a.Body,
+		LinkURL:   a.LinkUrl,
+		Tiers:     a.Tiers,
+		Flags:     a.Flags,
+		CreatedAt: a.CreatedAt,
+		UpdatedAt: a.UpdatedAt,
+	}
+	if announcement.Tiers == nil {
+		announcement.Tiers = []string{}
+	}
+	if announcement.Flags == nil {
+		announcement.Flags = []string{}
+	}
+	if a.PublishedAt.Valid {
+		announcement.PublishedAt = &a.PublishedAt.Time
+	}
+	if a.ExpiresAt.Valid {
+		announcement.ExpiresAt = &a.ExpiresAt.Time
+	}
+	return announcement
+}
+
+// Request creates or replaces an announcement. A nil PublishedAt keeps it as
+// a draft; a future one schedules it. Empty Tiers or Flags target everyone.
+type Request struct {
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	LinkURL     string     `json:"linkUrl"`
+	Tiers       []string   `json:"tiers"`
+	Flags       []string   `json:"flags"`
+	PublishedAt *time.Time `json:"publishedAt"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+// viewer is what audience targeting matches against.
+type viewer struct {
+	tier     string
+	freemium bool
+	role     string
+}
@@ -0,0 +1,33 @@
+package announcement
+
+import (
+	"context"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// store defines the database interface for announcements
+type store interface {
+	CreateAnnouncement(ctx context.Context, arg query.CreateAnnouncementParams) (query.Announcement, error)
+	UpdateAnnouncement(ctx context.Context, arg query.UpdateAnnouncementParams) (query.Announcement, error)
+	DeleteAnnouncement(ctx context.Context, id uuid.UUID) (int64, error)
+	ListAnnouncements(ctx context.Context) ([]query.Announcement, error)
+	ListUnreadAnnouncements(ctx context.Context, userID uuid.UUID) ([]query.Announcement, error)
+	MarkAnnouncementRead(ctx context.Context, arg query.MarkAnnouncementReadParams) error
+	GetOrgMembershipRole(ctx context.Context, arg query.GetOrgMembershipRoleParams) (string, error)
+	GetOrganisationBillingInfo(ctx context.Context, id uuid.UUID) (query.GetOrganisationBillingInfoRow, error)
+}
+
+// Service publishes platform announcements and tracks which users have read them
+type Service struct {
+	store store
+	now   func() time.Time
+}
+
+// NewService creates a new announcement service
+func NewService(store store) *Service {
+	return &Service{store: store, now: time.Now}
+}
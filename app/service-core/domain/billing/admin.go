@@ -0,0 +1,316 @@
+package billing
+
+import (
+	"app/pkg"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/customerbalancetransaction"
+	"github.com/stripe/stripe-go/v82/paymentintent"
+	"github.com/stripe/stripe-go/v82/refund"
+)
+
+// ReasonCode classifies why support issued a refund or credit. It is required
+// on every adjustment and recorded in Stripe metadata and the activity log.
+type ReasonCode string
+
+const (
+	ReasonDuplicateCharge ReasonCode = "duplicate_charge"
+	ReasonBillingError    ReasonCode = "billing_error"
+	ReasonServiceIssue    ReasonCode = "service_issue"
+	ReasonCancellation    ReasonCode = "cancellation"
+	ReasonGoodwill        ReasonCode = "goodwill"
+)
+
+var reasonCodes = map[ReasonCode]bool{
+	ReasonDuplicateCharge: true,
+	ReasonBillingError:    true,
+	ReasonServiceIssue:    true,
+	ReasonCancellation:    true,
+	ReasonGoodwill:        true,
+}
+
+// Activity log actions for billing adjustments
+const (
+	ActionRefundIssued = "billing.refund_issued"
+	ActionCreditIssued = "billing.credit_issued"
+)
+
+const maxAdjustmentNoteLength = 500
+
+var currencyPattern = regexp.MustCompile(`^[a-z]{3}$`)
+
+// mailer sends confirmation emails to an organisation's billing contact
+type mailer interface {
+	SendEmail(ctx context.Context, emailTo string, emailSubject string, emailBody string) error
+}
+
+// RefundRequest describes a refund of an organisation's payment.
+// A zero Amount refunds the full remaining amount.
+type RefundRequest struct {
+	PaymentIntentID string     `json:"paymentIntentId"`
+	Amount          int64      `json:"amount"` // in the smallest currency unit
+	ReasonCode      ReasonCode `json:"reasonCode"`
+	Note            string     `json:"note"`
+}
+
+// CreditRequest describes a credit applied to an organisation's Stripe
+// customer balance, which is consumed by upcoming invoices.
+type CreditRequest struct {
+	Amount     int64      `json:"amount"` // in the smallest currency unit
+	Currency   string     `json:"currency"`
+	ReasonCode ReasonCode `json:"reasonCode"`
+	Note       string     `json:"note"`
+}
+
+// Adjustment is the result of a refund or credit
+type Adjustment struct {
+	ID               string     `json:"id"`
+	Type             string     `json:"type"` // "refund" or "credit"
+	OrganisationID   uuid.UUID  `json:"organisationId"`
+	Amount           int64      `json:"amount"`
+	Currency         string     `json:"currency"`
+	Status           string     `json:"status"`
+	ReasonCode       ReasonCode `json:"reasonCode"`
+	ConfirmationSent bool       `json:"confirmationSent"`
+}
+
+func validateReason(code ReasonCode, note string) error {
+	if !reasonCodes[code] {
+		return pkg.BadRequestError{Message: fmt.Sprintf("Invalid reasonCode %q", code)}
+	}
+	if len(note) > maxAdjustmentNoteLength {
+		return pkg.BadRequestError{Message: fmt.Sprintf("note must be at most %d characters", maxAdjustmentNoteLength)}
+	}
+	return nil
+}
+
+// stripeCustomer returns the organisation's Stripe customer ID
+func (s *Service) stripeCustomer(ctx context.Context, organisationID uuid.UUID) (string, error) {
+	info, err := s.store.GetOrganisationBillingInfo(ctx, organisationID)
+	if err != nil {
+		return "", pkg.NotFoundError{Message: "Organisation not found"}
+	}
+	if info.StripeCustomerID == "" {
+		return "", pkg.BadRequestError{Message: "Organisation has no Stripe customer"}
+	}
+	return info.StripeCustomerID, nil
+}
+
+// IssueRefund refunds a payment made by the organisation. The payment must
+// belong to the organisation's Stripe customer.
+func (s *Service) IssueRefund(ctx context.Context, organisationID, issuedBy uuid.UUID, req RefundRequest) (*Adjustment, error) {
+	if err := validateReason(req.ReasonCode, req.Note); err != nil {
+		return nil, err
+	}
+	if req.PaymentIntentID == "" {
+		return nil, pkg.BadRequestError{Message: "paymentIntentId is required"}
+	}
+	if req.Amount < 0 {
+		return nil, pkg.BadRequestError{Message: "amount must not be negative"}
+	}
+
+	customerID, err := s.stripeCustomer(ctx, organisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	stripe.Key = s.cfg.StripeAPIKey
+
+	pi, err := paymentintent.Get(req.PaymentIntentID, &stripe.PaymentIntentParams{Params: stripe.Params{Context: ctx}})
+	if err != nil || pi.Customer == nil || pi.Customer.ID != customerID {
+		return nil, pkg.NotFoundError{Message: "Payment not found for this organisation"}
+	}
+	if req.Amount > pi.AmountReceived {
+		return nil, pkg.BadRequestError{Message: "amount exceeds the amount paid"}
+	}
+
+	reason := stripe.RefundReasonRequestedByCustomer
+	if req.ReasonCode == ReasonDuplicateCharge {
+		reason = stripe.RefundReasonDuplicate
+	}
+	params := &stripe.RefundParams{
+		Params:        stripe.Params{Context: ctx},
+		PaymentIntent: stripe.String(pi.ID),
+		Reason:        stripe.String(string(reason)),
+		Metadata:      adjustmentMetadata(organisationID, issuedBy, req.ReasonCode),
+	}
+	if req.Amount > 0 {
+		params.Amount = stripe.Int64(req.Amount)
+	}
+
+	re, err := refund.New(params)
+	if err != nil {
+		return nil, stripeError("Error issuing refund", err)
+	}
+
+	adj := &Adjustment{
+		ID:             re.ID,
+		Type:           "refund",
+		OrganisationID: organisationID,
+		Amount:         re.Amount,
+		Currency:       string(re.Currency),
+		Status:         string(re.Status),
+		ReasonCode:     req.ReasonCode,
+	}
+	s.recordAdjustment(ctx, ActionRefundIssued, issuedBy, adj, req.Note, map[string]any{"paymentIntentId": pi.ID})
+	adj.ConfirmationSent = s.sendAdjustmentConfirmation(ctx, adj)
+	return adj, nil
+}
+
+// IssueCredit applies a credit to the organisation's Stripe customer balance
+func (s *Service) IssueCredit(ctx context.Context, organisationID, issuedBy uuid.UUID, req CreditRequest) (*Adjustment, error) {
+	if err := validateReason(req.ReasonCode, req.Note); err != nil {
+		return nil, err
+	}
+	if req.Amount <= 0 {
+		return nil, pkg.BadRequestError{Message: "amount must be positive"}
+	}
+	currency := strings.ToLower(req.Currency)
+	if !currencyPattern.MatchString(currency) {
+		return nil, pkg.BadRequestError{Message: "currency must be a three-letter ISO code"}
+	}
+
+	customerID, err := s.stripeCustomer(ctx, organisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	stripe.Key = s.cfg.StripeAPIKey
+
+	// Negative amounts credit the balance; positive amounts would be a debit
+	txn, err := customerbalancetransaction.New(&stripe.CustomerBalanceTransactionParams{
+		Params:      stripe.Params{Context: ctx},
+		Customer:    stripe.String(customerID),
+		Amount:      stripe.Int64(-req.Amount),
+		Currency:    stripe.String(currency),
+		Description: stripe.String("Account credit: " + strings.ReplaceAll(string(req.ReasonCode), "_", " ")),
+		Metadata:    adjustmentMetadata(organisationID, issuedBy, req.ReasonCode),
+	})
+	if err != nil {
+		return nil, stripeError("Error issuing credit", err)
+	}
+
+	adj := &Adjustment{
+		ID:             txn.ID,
+		Type:           "credit",
+		OrganisationID: organisationID,
+		Amount:         -txn.Amount,
+		Currency:       string(txn.Currency),
+		Status:         "succeeded",
+		ReasonCode:     req.ReasonCode,
+	}
+	s.recordAdjustment(ctx, ActionCreditIssued, issuedBy, adj, req.Note, map[string]any{"endingBalance": txn.EndingBalance})
+	adj.ConfirmationSent = s.sendAdjustmentConfirmation(ctx, adj)
+	return adj, nil
+}
+
+func adjustmentMetadata(organisationID, issuedBy uuid.UUID, code ReasonCode) map[string]string {
+	return map[string]string{
+		"organisation_id": organisationID.String(),
+		"reason_code":     string(code),
+		"issued_by":       issuedBy.String(),
+	}
+}
+
+// stripeError surfaces Stripe's message for invalid requests (e.g. an already
+// refunded charge) as a bad request; anything else is internal.
+func stripeError(message string, err error) error {
+	var se *stripe.Error
+	if errors.As(err, &se) && se.Type == stripe.ErrorTypeInvalidRequest {
+		return pkg.BadRequestError{Message: se.Msg}
+	}
+	return pkg.InternalError{Message: message, Err: err}
+}
+
+// recordAdjustment writes the audit entry. The money has already moved, so a
+// failure here is logged rather than returned.
+func (s *Service) recordAdjustment(ctx context.Context, action string, issuedBy uuid.UUID, adj *Adjustment, note string, extra map[string]any) {
+	values := map[string]any{
+		"id":       adj.ID,
+		"amount":   adj.Amount,
+		"currency": adj.Currency,
+		"status":   adj.Status,
+	}
+	for k, v := range extra {
+		values[k] = v
+	}
+	newValues, _ := json.Marshal(values)
+	metadata, _ := json.Marshal(map[string]any{
+		"source":     "super_admin",
+		"reasonCode": adj.ReasonCode,
+		"note":       note,
+	})
+
+	err := s.store.InsertOrganisationActivity(ctx, query.InsertOrganisationActivityParams{
+		OrganisationID: adj.OrganisationID,
+		UserID:         uuid.NullUUID{UUID: issuedBy, Valid: true},
+		Action:         action,
+		EntityType:     "organisation",
+		EntityID:       uuid.NullUUID{UUID: adj.OrganisationID, Valid: true},
+		NewValues:      pqtype.NullRawMessage{RawMessage: newValues, Valid: true},
+		Metadata:       metadata,
+	})
+	if err != nil {
+		slog.Error("Error recording billing adjustment", "error", err, "action", action, "id", adj.ID)
+	}
+}
+
+// sendAdjustmentConfirmation emails the organisation's billing contact and
+// reports whether the email was sent.
+func (s *Service) sendAdjustmentConfirmation(ctx context.Context, adj *Adjustment) bool {
+	if s.mailer == nil {
+		return false
+	}
+	contact, err := s.store.GetOrganisationBillingContact(ctx, adj.OrganisationID)
+	if err != nil || contact.Email == "" {
+		slog.Warn("No billing contact for adjustment confirmation", "organisation_id", adj.OrganisationID, "id", adj.ID)
+		return false
+	}
+
+	amount := formatAmount(adj.Amount, adj.Currency)
+	subject := "Refund issued to your account"
+	detail := fmt.Sprintf("A refund of %s has been issued to your original payment method. It usually appears within 5–10 business days.", amount)
+	if adj.Type == "credit" {
+		subject = "Credit added to your account"
+		detail = fmt.Sprintf("A credit of %s has been added to your account and will be applied to your next invoices.", amount)
+	}
+
+	body := `<!DOCTYPE html>
+<html>
+<body style="margin: 0; padding: 24px; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; background-color: #f4f4f5;">
+    <p style="font-size: 16px; line-height: 24px; color: #18181b;">Hi ` + html.EscapeString(contact.Name) + `,</p>
+    <p style="font-size: 16px; line-height: 24px; color: #52525b;">` + html.EscapeString(detail) + `</p>
+    <p style="font-size: 14px; line-height: 20px; color: #71717a;">Reference: ` + html.EscapeString(adj.ID) + `</p>
+    <p style="font-size: 14px; line-height: 20px; color: #71717a;">If you have any questions, reply to this email and our support team will help.</p>
+</body>
+</html>`
+
+	if err := s.mailer.SendEmail(ctx, contact.Email, subject, body); err != nil {
+		slog.Error("Error sending adjustment confirmation", "error", err, "organisation_id", adj.OrganisationID, "id", adj.ID)
+		return false
+	}
+	return true
+}
+
+// zeroDecimalCurrencies are charged in whole units rather than cents
+var zeroDecimalCurrencies = map[string]bool{"jpy": true, "krw": true, "vnd": true, "clp": true, "isk": true}
+
+// formatAmount renders an amount in the smallest currency unit, e.g. 1250 usd -> "12.50 USD"
+func formatAmount(amount int64, currency string) string {
+	if zeroDecimalCurrencies[currency] {
+		return fmt.Sprintf("%d %s", amount, strings.ToUpper(currency))
+	}
+	return fmt.Sprintf("%d.%02d %s", amount/100, amount%100, strings.ToUpper(currency))
+}
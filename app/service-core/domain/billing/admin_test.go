@@ -0,0 +1,392 @@
+package billing
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"service-core/config"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v82"
+)
+
+const testCustomerID = "cus_org"
+
+// fakeStore serves one organisation with a Stripe customer and a billing
+// contact, and records activity.
+type fakeStore struct {
+	store
+
+	orgID    uuid.UUID
+	customer string
+	contact  query.GetOrganisationBillingContactRow
+	activity []query.InsertOrganisationActivityParams
+}
+
+func (s *fakeStore) GetOrganisationBillingInfo(_ context.Context, id uuid.UUID) (query.GetOrganisationBillingInfoRow, error) {
+	if id != s.orgID {
+		return query.GetOrganisationBillingInfoRow{}, sql.ErrNoRows
+	}
+	return query.GetOrganisationBillingInfoRow{ID: id, StripeCustomerID: s.customer}, nil
+}
+
+func (s *fakeStore) GetOrganisationBillingContact(context.Context, uuid.UUID) (query.GetOrganisationBillingContactRow, error) {
+	return s.contact, nil
+}
+
+func (s *fakeStore) InsertOrganisationActivity(_ context.Context, arg query.InsertOrganisationActivityParams) error {
+	s.activity = append(s.activity, arg)
+	return nil
+}
+
+type sentEmail struct {
+	to, subject, body string
+}
+
+type fakeMailer struct {
+	sent []sentEmail
+}
+
+func (m *fakeMailer) SendEmail(_ context.Context, to, subject, body string) error {
+	m.sent = append(m.sent, sentEmail{to, subject, body})
+	return nil
+}
+
+// fakeStripe serves the Stripe endpoints refunds and credits use. pi_paid
+// was paid by testCustomerID, pi_other by another customer, and refunding
+// pi_refunded fails as already refunded. Each request's form is recorded.
+type fakeStripe struct {
+	requests []url.Values
+}
+
+func (f *fakeStripe) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	f.requests = append(f.requests, r.PostForm)
+
+	reply := func(status int, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+	invalid := func(status int, message string) {
+		reply(status, map[string]any{"error": map[string]any{"type": "invalid_request_error", "message": message}})
+	}
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/payment_intents/"):
+		id := strings.TrimPrefix(r.URL.Path, "/v1/payment_intents/")
+		customer := map[string]string{"pi_paid": testCustomerID, "pi_refunded": testCustomerID, "pi_other": "cus_other"}[id]
+		if customer == "" {
+			invalid(http.StatusNotFound, "No such payment_intent: '"+id+"'")
+			return
+		}
+		reply(http.StatusOK, map[string]any{"id": id, "object": "payment_intent", "customer": customer, "amount_received": 5000, "currency": "usd"})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/refunds":
+		if r.PostForm.Get("payment_intent") == "pi_refunded" {
+			invalid(http.StatusBadRequest, "Charge has already been refunded.")
+			return
+		}
+		amount := int64(5000)
+		if v := r.PostForm.Get("amount"); v != "" {
+			amount, _ = strconv.ParseInt(v, 10, 64)
+		}
+		reply(http.StatusOK, map[string]any{"id": "re_1", "object": "refund", "amount": amount, "currency": "usd", "status": "succeeded"})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/customers/"+testCustomerID+"/balance_transactions":
+		amount, _ := strconv.ParseInt(r.PostForm.Get("amount"), 10, 64)
+		reply(http.StatusOK, map[string]any{"id": "cbtxn_1", "object": "customer_balance_transaction", "amount": amount, "currency": r.PostForm.Get("currency"), "ending_balance": amount})
+	default:
+		invalid(http.StatusNotFound, "Unrecognized request URL")
+	}
+}
+
+// useFakeStripe points the Stripe client at a fakeStripe for the test.
+func useFakeStripe(t *testing.T) *fakeStripe {
+	t.Helper()
+	fake := &fakeStripe{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	original := stripe.GetBackend(stripe.APIBackend)
+	stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		URL:               stripe.String(srv.URL),
+		HTTPClient:        srv.Client(),
+		MaxNetworkRetries: stripe.Int64(0),
+		LeveledLogger:     &stripe.LeveledLogger{Level: stripe.LevelNull},
+	}))
+	t.Cleanup(func() { stripe.SetBackend(stripe.APIBackend, original) })
+	return fake
+}
+
+func newAdminTestService(t *testing.T) (*Service, *fakeStore, *fakeMailer, *fakeStripe) {
+	t.Helper()
+	store := &fakeStore{
+		orgID:    uuid.New(),
+		customer: testCustomerID,
+		contact:  query.GetOrganisationBillingContactRow{Name: "Ada", Email: "billing@example.com"},
+	}
+	mailer := &fakeMailer{}
+	return NewService(config.LoadTestConfig(), store, nil, mailer), store, mailer, useFakeStripe(t)
+}
+
+func TestIssueRefund(t *testing.T) {
+	issuedBy := uuid.New()
+
+	tests := []struct {
+		name        string
+		req         RefundRequest
+		noCustomer  bool
+		otherOrg    bool
+		wantErr     error
+		wantMessage string
+		wantStripe  int // requests made to Stripe
+		wantAmount  int64
+		wantReason  stripe.RefundReason
+	}{
+		{
+			name:        "unknown reason code",
+			req:         RefundRequest{PaymentIntentID: "pi_paid", ReasonCode: "because"},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: `Invalid reasonCode "because"`,
+		},
+		{
+			name:        "note too long",
+			req:         RefundRequest{PaymentIntentID: "pi_paid", ReasonCode: ReasonGoodwill, Note: strings.Repeat("x", maxAdjustmentNoteLength+1)},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "note must be at most 500 characters",
+		},
+		{
+			name:        "no payment",
+			req:         RefundRequest{ReasonCode: ReasonGoodwill},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "paymentIntentId is required",
+		},
+		{
+			name:        "negative amount",
+			req:         RefundRequest{PaymentIntentID: "pi_paid", Amount: -1, ReasonCode: ReasonGoodwill},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "amount must not be negative",
+		},
+		{
+			name:        "unknown organisation",
+			req:         RefundRequest{PaymentIntentID: "pi_paid", ReasonCode: ReasonGoodwill},
+			otherOrg:    true,
+			wantErr:     pkg.NotFoundError{},
+			wantMessage: "Organisation not found",
+		},
+		{
+			name:        "no Stripe customer",
+			req:         RefundRequest{PaymentIntentID: "pi_paid", ReasonCode: ReasonGoodwill},
+			noCustomer:  true,
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "Organisation has no Stripe customer",
+		},
+		{
+			// Another customer's payment looks the same as a missing one
+			name:        "another customer's payment",
+			req:         RefundRequest{PaymentIntentID: "pi_other", ReasonCode: ReasonGoodwill},
+			wantErr:     pkg.NotFoundError{},
+			wantMessage: "Payment not found for this organisation",
+			wantStripe:  1,
+		},
+		{
+			name:        "missing payment",
+			req:         RefundRequest{PaymentIntentID: "pi_missing", ReasonCode: ReasonGoodwill},
+			wantErr:     pkg.NotFoundError{},
+			wantMessage: "Payment not found for this organisation",
+			wantStripe:  1,
+		},
+		{
+			name:        "more than was paid",
+			req:         RefundRequest{PaymentIntentID: "pi_paid", Amount: 5001, ReasonCode: ReasonGoodwill},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "amount exceeds the amount paid",
+			wantStripe:  1,
+		},
+		{
+			name:        "rejected by Stripe",
+			req:         RefundRequest{PaymentIntentID: "pi_refunded", ReasonCode: ReasonGoodwill},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "Charge has already been refunded.",
+			wantStripe:  2,
+		},
+		{
+			name:       "full refund",
+			req:        RefundRequest{PaymentIntentID: "pi_paid", ReasonCode: ReasonServiceIssue, Note: "Outage on 3 May"},
+			wantStripe: 2,
+			wantAmount: 5000,
+			wantReason: stripe.RefundReasonRequestedByCustomer,
+		},
+		{
+			name:       "partial duplicate charge",
+			req:        RefundRequest{PaymentIntentID: "pi_paid", Amount: 1250, ReasonCode: ReasonDuplicateCharge},
+			wantStripe: 2,
+			wantAmount: 1250,
+			wantReason: stripe.RefundReasonDuplicate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, mailer, stripeAPI := newAdminTestService(t)
+			if tt.noCustomer {
+				store.customer = ""
+			}
+			orgID := store.orgID
+			if tt.otherOrg {
+				orgID = uuid.New()
+			}
+
+			adj, err := s.IssueRefund(context.Background(), orgID, issuedBy, tt.req)
+			assert.Len(t, stripeAPI.requests, tt.wantStripe)
+			if tt.wantErr != nil {
+				assert.IsType(t, tt.wantErr, err)
+				assert.Contains(t, err.Error(), tt.wantMessage)
+				assert.Nil(t, adj)
+				assert.Empty(t, store.activity)
+				assert.Empty(t, mailer.sent)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &Adjustment{
+				ID:               "re_1",
+				Type:             "refund",
+				OrganisationID:   orgID,
+				Amount:           tt.wantAmount,
+				Currency:         "usd",
+				Status:           "succeeded",
+				ReasonCode:       tt.req.ReasonCode,
+				ConfirmationSent: true,
+			}, adj)
+
+			sent := stripeAPI.requests[1]
+			assert.Equal(t, "pi_paid", sent.Get("payment_intent"))
+			assert.Equal(t, string(tt.wantReason), sent.Get("reason"))
+			if tt.req.Amount == 0 {
+				assert.Empty(t, sent.Get("amount"))
+			}
+			assert.Equal(t, string(tt.req.ReasonCode), sent.Get("metadata[reason_code]"))
+			assert.Equal(t, issuedBy.String(), sent.Get("metadata[issued_by]"))
+			assert.Equal(t, orgID.String(), sent.Get("metadata[organisation_id]"))
+
+			require.Len(t, store.activity, 1)
+			activity := store.activity[0]
+			assert.Equal(t, ActionRefundIssued, activity.Action)
+			assert.Equal(t, uuid.NullUUID{UUID: issuedBy, Valid: true}, activity.UserID)
+			var metadata map[string]any
+			require.NoError(t, json.Unmarshal(activity.Metadata, &metadata))
+			assert.Equal(t, map[string]any{"source": "super_admin", "reasonCode": string(tt.req.ReasonCode), "note": tt.req.Note}, metadata)
+
+			require.Len(t, mailer.sent, 1)
+			assert.Equal(t, "billing@example.com", mailer.sent[0].to)
+			assert.Equal(t, "Refund issued to your account", mailer.sent[0].subject)
+			assert.Contains(t, mailer.sent[0].body, formatAmount(tt.wantAmount, "usd"))
+		})
+	}
+}
+
+func TestIssueCredit(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          CreditRequest
+		wantErr      error
+		wantMessage  string
+		wantCurrency string
+	}{
+		{
+			name:        "unknown reason code",
+			req:         CreditRequest{Amount: 500, Currency: "usd"},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: `Invalid reasonCode ""`,
+		},
+		{
+			name:        "zero amount",
+			req:         CreditRequest{Currency: "usd", ReasonCode: ReasonGoodwill},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "amount must be positive",
+		},
+		{
+			// A negative credit would debit the customer's balance
+			name:        "negative amount",
+			req:         CreditRequest{Amount: -500, Currency: "usd", ReasonCode: ReasonGoodwill},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "amount must be positive",
+		},
+		{
+			name:        "not a currency",
+			req:         CreditRequest{Amount: 500, Currency: "dollars", ReasonCode: ReasonGoodwill},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "currency must be a three-letter ISO code",
+		},
+		{
+			name:         "credit",
+			req:          CreditRequest{Amount: 500, Currency: "usd", ReasonCode: ReasonBillingError},
+			wantCurrency: "usd",
+		},
+		{
+			name:         "upper-case currency",
+			req:          CreditRequest{Amount: 500, Currency: "EUR", ReasonCode: ReasonGoodwill},
+			wantCurrency: "eur",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, mailer, stripeAPI := newAdminTestService(t)
+
+			adj, err := s.IssueCredit(context.Background(), store.orgID, uuid.New(), tt.req)
+			if tt.wantErr != nil {
+				assert.IsType(t, tt.wantErr, err)
+				assert.Contains(t, err.Error(), tt.wantMessage)
+				assert.Empty(t, stripeAPI.requests)
+				assert.Empty(t, store.activity)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &Adjustment{
+				ID:               "cbtxn_1",
+				Type:             "credit",
+				OrganisationID:   store.orgID,
+				Amount:           tt.req.Amount,
+				Currency:         tt.wantCurrency,
+				Status:           "succeeded",
+				ReasonCode:       tt.req.ReasonCode,
+				ConfirmationSent: true,
+			}, adj)
+
+			// Stripe credits a balance with a negative amount
+			require.Len(t, stripeAPI.requests, 1)
+			assert.Equal(t, strconv.FormatInt(-tt.req.Amount, 10), stripeAPI.requests[0].Get("amount"))
+			assert.Equal(t, tt.wantCurrency, stripeAPI.requests[0].Get("currency"))
+
+			require.Len(t, store.activity, 1)
+			assert.Equal(t, ActionCreditIssued, store.activity[0].Action)
+			require.Len(t, mailer.sent, 1)
+			assert.Equal(t, "Credit added to your account", mailer.sent[0].subject)
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		want     string
+	}{
+		{1250, "usd", "12.50 USD"},
+		{5, "eur", "0.05 EUR"},
+		{100000, "gbp", "1000.00 GBP"},
+		{1250, "jpy", "1250 JPY"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatAmount(tt.amount, tt.currency))
+	}
+}
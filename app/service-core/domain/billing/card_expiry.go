@@ -0,0 +1,251 @@
+package billing
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/customer"
+	"github.com/stripe/stripe-go/v82/subscription"
+)
+
+// cardExpiryReminderDays are the reminder stages, in days before the card
+// expires, from latest to earliest.
+var cardExpiryReminderDays = []int{7, 30}
+
+// paymentMethodUpdatePath is the billing page deep link that opens the Stripe
+// portal straight on the payment method update flow.
+const paymentMethodUpdatePath = "/settings/billing?action=update-payment-method"
+
+// cardDetails is the subset of a card needed for expiry reminders
+type cardDetails struct {
+	ID       string
+	Brand    string
+	Last4    string
+	ExpMonth int64
+	ExpYear  int64
+}
+
+// cardExpiresAt returns the moment a card stops working: cards are valid
+// through the last day of their expiry month.
+func cardExpiresAt(month, year int64) time.Time {
+	return time.Date(int(year), time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// handlePaymentMethodEvent re-syncs the default payment method of the customer
+// referenced by a card or payment method event.
+func (s *Service) handlePaymentMethodEvent(ctx context.Context, event stripe.Event) error {
+	var obj struct {
+		Customer json.RawMessage `json:"customer"`
+	}
+	if err := json.Unmarshal(event.Data.Raw, &obj); err != nil {
+		return pkg.InternalError{Message: "Error parsing payment method event", Err: err}
+	}
+	// customer is an ID string unless expanded
+	var customerID string
+	if err := json.Unmarshal(obj.Customer, &customerID); err != nil {
+		var expanded struct {
+			ID string `json:"id"`
+		}
+		_ = json.Unmarshal(obj.Customer, &expanded)
+		customerID = expanded.ID
+	}
+	if customerID == "" {
+		return nil // Detached payment method
+	}
+	return s.syncPaymentMethod(ctx, customerID)
+}
+
+// syncPaymentMethod stores the expiry of the customer's default card for the
+// organisation, or clears it when the default isn't a card.
+func (s *Service) syncPaymentMethod(ctx context.Context, customerID string) error {
+	organisation, err := s.store.GetOrganisationByStripeCustomer(ctx, customerID)
+	if err != nil {
+		return nil // Not an organisation customer
+	}
+
+	stripe.Key = s.cfg.StripeAPIKey
+
+	card, err := s.defaultCard(ctx, customerID, organisation.SubscriptionID)
+	if err != nil {
+		return pkg.InternalError{Message: "Error getting default payment method", Err: err}
+	}
+	if card == nil {
+		if err := s.store.DeleteOrganisationPaymentMethod(ctx, organisation.ID); err != nil {
+			return pkg.InternalError{Message: "Error clearing organisation payment method", Err: err}
+		}
+		return nil
+	}
+
+	err = s.store.UpsertOrganisationPaymentMethod(ctx, query.UpsertOrganisationPaymentMethodParams{
+		OrganisationID:  organisation.ID,
+		PaymentMethodID: card.ID,
+		Brand:           card.Brand,
+		Last4:           card.Last4,
+		ExpMonth:        int32(card.ExpMonth),
+		ExpYear:         int32(card.ExpYear),
+		ExpiresAt:       cardExpiresAt(card.ExpMonth, card.ExpYear),
+	})
+	if err != nil {
+		return pkg.InternalError{Message: "Error updating organisation payment method", Err: err}
+	}
+	return nil
+}
+
+// defaultCard resolves the card Stripe will charge at renewal: the
+// subscription's default payment method, then the customer's invoice default,
+// then the customer's legacy default source. Returns nil if none is a card.
+func (s *Service) defaultCard(ctx context.Context, customerID, subscriptionID string) (*cardDetails, error) {
+	if subscriptionID != "" {
+		params := &stripe.SubscriptionParams{Params: stripe.Params{Context: ctx}}
+		params.AddExpand("default_payment_method")
+		sub, err := subscription.Get(subscriptionID, params)
+		if err != nil {
+			return nil, err
+		}
+		if card := paymentMethodCard(sub.DefaultPaymentMethod); card != nil {
+			return card, nil
+		}
+	}
+
+	params := &stripe.CustomerParams{Params: stripe.Params{Context: ctx}}
+	params.AddExpand("invoice_settings.default_payment_method")
+	params.AddExpand("default_source")
+	cust, err := customer.Get(customerID, params)
+	if err != nil {
+		return nil, err
+	}
+	if cust.InvoiceSettings != nil {
+		if card := paymentMethodCard(cust.InvoiceSettings.DefaultPaymentMethod); card != nil {
+			return card, nil
+		}
+	}
+	if cust.DefaultSource != nil && cust.DefaultSource.Card != nil {
+		c := cust.DefaultSource.Card
+		return &cardDetails{ID: c.ID, Brand: string(c.Brand), Last4: c.Last4, ExpMonth: c.ExpMonth, ExpYear: c.ExpYear}, nil
+	}
+	return nil, nil
+}
+
+func paymentMethodCard(pm *stripe.PaymentMethod) *cardDetails {
+	if pm == nil || pm.Card == nil {
+		return nil
+	}
+	return &cardDetails{ID: pm.ID, Brand: string(pm.Card.Brand), Last4: pm.Card.Last4, ExpMonth: pm.Card.ExpMonth, ExpYear: pm.Card.ExpYear}
+}
+
+// reminderStage returns the reminder stage due for a card expiring in
+// daysLeft days, or 0 if none is due.
+func reminderStage(daysLeft int, lastReminderDays sql.NullInt32) int {
+	for _, stage := range cardExpiryReminderDays {
+		if daysLeft > stage {
+			continue
+		}
+		if lastReminderDays.Valid && int(lastReminderDays.Int32) <= stage {
+			return 0 // This stage (or a later one) was already sent
+		}
+		return stage
+	}
+	return 0
+}
+
+// SendCardExpiryReminders emails billing contacts whose default card expires
+// within 30 or 7 days. Each stage is sent once per card. Returns the number of
+// reminders sent.
+func (s *Service) SendCardExpiryReminders(ctx context.Context) (int, error) {
+	now := time.Now()
+	maxDays := cardExpiryReminderDays[len(cardExpiryReminderDays)-1]
+	rows, err := s.store.ListExpiringPaymentMethods(ctx, now.AddDate(0, 0, maxDays))
+	if err != nil {
+		return 0, pkg.InternalError{Message: "Error listing expiring payment methods", Err: err}
+	}
+
+	sent := 0
+	for _, row := range rows {
+		daysLeft := int(row.ExpiresAt.Sub(now).Hours()/24) + 1
+		stage := reminderStage(daysLeft, row.LastReminderDays)
+		if stage == 0 {
+			continue
+		}
+		if err := s.sendCardExpiryReminder(ctx, row, daysLeft); err != nil {
+			slog.Error("Error sending card expiry reminder", "error", err, "organisation_id", row.OrganisationID)
+			continue
+		}
+		err := s.store.SetPaymentMethodReminderSent(ctx, query.SetPaymentMethodReminderSentParams{
+			OrganisationID:   row.OrganisationID,
+			LastReminderDays: sql.NullInt32{Int32: int32(stage), Valid: true},
+		})
+		if err != nil {
+			slog.Error("Error recording card expiry reminder", "error", err, "organisation_id", row.OrganisationID)
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *Service) sendCardExpiryReminder(ctx context.Context, row query.ListExpiringPaymentMethodsRow, daysLeft int) error {
+	if s.mailer == nil {
+		return fmt.Errorf("no mailer configured")
+	}
+	contact, err := s.store.GetOrganisationBillingContact(ctx, row.OrganisationID)
+	if err != nil {
+		return err
+	}
+	if contact.Email == "" {
+		return fmt.Errorf("organisation %s has no billing contact", row.OrganisationID)
+	}
+
+	updateURL := s.cfg.ClientURL + "/" + row.Slug + paymentMethodUpdatePath
+	card := "Your card"
+	if row.Last4 != "" {
+		card = fmt.Sprintf("Your %s card ending in %s", cardBrandName(row.Brand), row.Last4)
+	}
+	subject := fmt.Sprintf("Your payment card expires in %d days", daysLeft)
+	if daysLeft == 1 {
+		subject = "Your payment card expires tomorrow"
+	}
+	detail := fmt.Sprintf("%s expires on %s. Update your payment method to avoid any interruption to your subscription.",
+		card, row.ExpiresAt.AddDate(0, 0, -1).Format("2 January 2006"))
+
+	body := `<!DOCTYPE html>
+<html>
+<body style="margin: 0; padding: 24px; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; background-color: #f4f4f5;">
+    <p style="font-size: 16px; line-height: 24px; color: #18181b;">Hi ` + html.EscapeString(contact.Name) + `,</p>
+    <p style="font-size: 16px; line-height: 24px; color: #52525b;">` + html.EscapeString(detail) + `</p>
+    <p style="padding: 8px 0;">
+        <a href="` + html.EscapeString(updateURL) + `" style="display: inline-block; padding: 14px 32px; background-color: #6366f1; color: #ffffff; text-decoration: none; font-size: 16px; font-weight: 600; border-radius: 8px;">Update payment method</a>
+    </p>
+    <p style="font-size: 14px; line-height: 20px; color: #71717a;">If you've already updated your card, you can ignore this email.</p>
+</body>
+</html>`
+
+	return s.mailer.SendEmail(ctx, contact.Email, subject, body)
+}
+
+// cardBrandName formats a Stripe card brand ("visa", "amex") for display
+func cardBrandName(brand string) string {
+	switch brand {
+	case "amex", "American Express":
+		return "American Express"
+	case "mastercard", "MasterCard":
+		return "Mastercard"
+	case "diners", "Diners Club":
+		return "Diners Club"
+	case "unionpay", "UnionPay":
+		return "UnionPay"
+	case "jcb", "JCB":
+		return "JCB"
+	case "":
+		return "payment"
+	}
+	return strings.ToUpper(brand[:1]) + brand[1:]
+}
@@ -0,0 +1,242 @@
+package billing
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/customer"
+)
+
+// Stripe limits for customer invoice settings
+const (
+	maxInvoiceCustomFields     = 4
+	maxInvoiceCustomFieldName  = 40
+	maxInvoiceCustomFieldValue = 140
+	maxInvoiceMemoLength       = 5000
+)
+
+// poNumberFieldName labels the PO number custom field on invoices
+const poNumberFieldName = "PO Number"
+
+// BillingAddress is the postal address printed on invoices
+type BillingAddress struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	PostalCode string `json:"postalCode"`
+	Country    string `json:"country"` // ISO 3166-1 alpha-2
+}
+
+// InvoiceCustomField is a name/value pair printed on every invoice
+type InvoiceCustomField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// BillingContact is who receives an organisation's invoices and billing
+// emails, and what is printed on its invoices. IsDefault is true when no
+// contact has been configured and the fallback (organisation email, then
+// owner) is shown instead.
+type BillingContact struct {
+	Name                string               `json:"name"`
+	Email               string               `json:"email"`
+	PONumber            string               `json:"poNumber"`
+	Address             BillingAddress       `json:"address"`
+	InvoiceMemo         string               `json:"invoiceMemo"`
+	InvoiceCustomFields []InvoiceCustomField `json:"invoiceCustomFields"`
+	IsDefault           bool                 `json:"isDefault"`
+	UpdatedAt           *time.Time           `json:"updatedAt"`
+}
+
+func toBillingContact(row query.OrganisationBillingContact) *BillingContact {
+	contact := &BillingContact{
+		Name:     row.Name,
+		Email:    row.Email,
+		PONumber: row.PoNumber,
+		Address: BillingAddress{
+			Line1:      row.AddressLine1,
+			Line2:      row.AddressLine2,
+			City:       row.City,
+			State:      row.State,
+			PostalCode: row.PostalCode,
+			Country:    row.Country,
+		},
+		InvoiceMemo:         row.InvoiceMemo,
+		InvoiceCustomFields: []InvoiceCustomField{},
+		UpdatedAt:           &row.UpdatedAt,
+	}
+	_ = json.Unmarshal(row.InvoiceCustomFields, &contact.InvoiceCustomFields)
+	return contact
+}
+
+// GetBillingContact returns the organisation's billing contact, or the
+// fallback recipient if none has been configured.
+func (s *Service) GetBillingContact(ctx context.Context, organisationID uuid.UUID) (*BillingContact, error) {
+	row, err := s.store.GetBillingContact(ctx, organisationID)
+	if err == nil {
+		return toBillingContact(row), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.InternalError{Message: "Error getting billing contact", Err: err}
+	}
+
+	fallback, err := s.store.GetOrganisationBillingContact(ctx, organisationID)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Organisation not found"}
+	}
+	return &BillingContact{
+		Name:                fallback.Name,
+		Email:               fallback.Email,
+		InvoiceCustomFields: []InvoiceCustomField{},
+		IsDefault:           true,
+	}, nil
+}
+
+func (c *BillingContact) validate() error {
+	c.Email = strings.TrimSpace(c.Email)
+	if _, err := mail.ParseAddress(c.Email); err != nil || c.Email == "" {
+		return pkg.BadRequestError{Message: "A valid billing email is required"}
+	}
+	c.Address.Country = strings.ToUpper(strings.TrimSpace(c.Address.Country))
+	if c.Address.Country != "" && len(c.Address.Country) != 2 {
+		return pkg.BadRequestError{Message: "country must be a two-letter ISO code"}
+	}
+	if len(c.InvoiceMemo) > maxInvoiceMemoLength {
+		return pkg.BadRequestError{Message: fmt.Sprintf("invoiceMemo must be at most %d characters", maxInvoiceMemoLength)}
+	}
+
+	fields := c.invoiceFields()
+	if len(fields) > maxInvoiceCustomFields {
+		return pkg.BadRequestError{Message: fmt.Sprintf("At most %d invoice custom fields are allowed, including the PO number", maxInvoiceCustomFields)}
+	}
+	for _, f := range fields {
+		if f.Name == "" || len(f.Name) > maxInvoiceCustomFieldName {
+			return pkg.BadRequestError{Message: fmt.Sprintf("Invoice custom field names must be 1-%d characters", maxInvoiceCustomFieldName)}
+		}
+		if f.Value == "" || len(f.Value) > maxInvoiceCustomFieldValue {
+			return pkg.BadRequestError{Message: fmt.Sprintf("Invoice custom field values must be 1-%d characters", maxInvoiceCustomFieldValue)}
+		}
+	}
+	return nil
+}
+
+// invoiceFields returns the custom fields printed on invoices: the PO number
+// (if any) followed by the configured fields.
+func (c *BillingContact) invoiceFields() []InvoiceCustomField {
+	var fields []InvoiceCustomField
+	if c.PONumber != "" {
+		fields = append(fields, InvoiceCustomField{Name: poNumberFieldName, Value: c.PONumber})
+	}
+	return append(fields, c.InvoiceCustomFields...)
+}
+
+// UpdateBillingContact saves the organisation's billing contact and syncs it to
+// the Stripe customer, if one exists yet. Otherwise it is applied when the
+// customer is created at checkout.
+func (s *Service) UpdateBillingContact(ctx context.Context, organisationID uuid.UUID, contact BillingContact) (*BillingContact, error) {
+	if err := contact.validate(); err != nil {
+		return nil, err
+	}
+	if contact.InvoiceCustomFields == nil {
+		contact.InvoiceCustomFields = []InvoiceCustomField{}
+	}
+	customFields, err := json.Marshal(contact.InvoiceCustomFields)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error encoding invoice custom fields", Err: err}
+	}
+
+	row, err := s.store.UpsertBillingContact(ctx, query.UpsertBillingContactParams{
+		OrganisationID:      organisationID,
+		Name:                strings.TrimSpace(contact.Name),
+		Email:               contact.Email,
+		PoNumber:            strings.TrimSpace(contact.PONumber),
+		AddressLine1:        contact.Address.Line1,
+		AddressLine2:        contact.Address.Line2,
+		City:                contact.Address.City,
+		State:               contact.Address.State,
+		PostalCode:          contact.Address.PostalCode,
+		Country:             contact.Address.Country,
+		InvoiceMemo:         contact.InvoiceMemo,
+		InvoiceCustomFields: customFields,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error saving billing contact", Err: err}
+	}
+	saved := toBillingContact(row)
+
+	info, err := s.store.GetOrganisationBillingInfo(ctx, organisationID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error getting organisation billing info", Err: err}
+	}
+	if info.StripeCustomerID != "" {
+		if err := s.applyBillingContact(ctx, info.StripeCustomerID, saved); err != nil {
+			return nil, pkg.InternalError{Message: "Billing contact saved but could not be synced to Stripe", Err: err}
+		}
+	}
+	return saved, nil
+}
+
+// applyBillingContact copies the contact onto the Stripe customer, which
+// controls where invoices are emailed and what they show.
+func (s *Service) applyBillingContact(ctx context.Context, customerID string, contact *BillingContact) error {
+	stripe.Key = s.cfg.StripeAPIKey
+
+	params := &stripe.CustomerParams{
+		Params: stripe.Params{Context: ctx},
+		Email:  stripe.String(contact.Email),
+		Address: &stripe.AddressParams{
+			Line1:      stripe.String(contact.Address.Line1),
+			Line2:      stripe.String(contact.Address.Line2),
+			City:       stripe.String(contact.Address.City),
+			State:      stripe.String(contact.Address.State),
+			PostalCode: stripe.String(contact.Address.PostalCode),
+			Country:    stripe.String(contact.Address.Country),
+		},
+		InvoiceSettings: &stripe.CustomerInvoiceSettingsParams{
+			Footer: stripe.String(contact.InvoiceMemo),
+		},
+	}
+	if contact.Name != "" {
+		params.Name = stripe.String(contact.Name)
+	}
+
+	fields := contact.invoiceFields()
+	if len(fields) == 0 {
+		// An empty value removes previously-defined fields
+		params.AddExtra("invoice_settings[custom_fields]", "")
+	}
+	for _, f := range fields {
+		params.InvoiceSettings.CustomFields = append(params.InvoiceSettings.CustomFields, &stripe.CustomerInvoiceSettingsCustomFieldParams{
+			Name:  stripe.String(f.Name),
+			Value: stripe.String(f.Value),
+		})
+	}
+
+	_, err := customer.Update(customerID, params)
+	return err
+}
+
+// applyStoredBillingContact syncs a configured billing contact onto a newly
+// created Stripe customer. Failures are logged; checkout proceeds regardless.
+func (s *Service) applyStoredBillingContact(ctx context.Context, organisationID uuid.UUID, customerID string) {
+	row, err := s.store.GetBillingContact(ctx, organisationID)
+	if err != nil {
+		return // None configured
+	}
+	if err := s.applyBillingContact(ctx, customerID, toBillingContact(row)); err != nil {
+		slog.Warn("Failed to apply billing contact to new customer", "error", err, "organisation_id", organisationID)
+	}
+}
@@ -0,0 +1,86 @@
+package billing
+
+import (
+	"app/pkg"
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/customer"
+	"github.com/stripe/stripe-go/v82/subscription"
+)
+
+// CancelForDeletion sets a deleted organisation's subscription to cancel at
+// the end of the paid period, so it isn't charged again during the recovery
+// window. Organisations without a subscription are left alone.
+func (s *Service) CancelForDeletion(ctx context.Context, organisationID uuid.UUID) error {
+	return s.setCancelAtPeriodEnd(ctx, organisationID, true)
+}
+
+// ResumeAfterRestore undoes CancelForDeletion when a deleted organisation is
+// restored. A subscription that has already ended stays ended; the
+// organisation has to subscribe again.
+func (s *Service) ResumeAfterRestore(ctx context.Context, organisationID uuid.UUID) error {
+	return s.setCancelAtPeriodEnd(ctx, organisationID, false)
+}
+
+func (s *Service) setCancelAtPeriodEnd(ctx context.Context, organisationID uuid.UUID, cancel bool) error {
+	stripe.Key = s.cfg.StripeAPIKey
+
+	info, err := s.store.GetOrganisationBillingInfo(ctx, organisationID)
+	if err != nil {
+		return pkg.InternalError{Message: "Error getting organisation billing info", Err: err}
+	}
+	if info.SubscriptionID == "" {
+		return nil
+	}
+
+	_, err = subscription.Update(info.SubscriptionID, &stripe.SubscriptionParams{
+		Params:            stripe.Params{Context: ctx},
+		CancelAtPeriodEnd: stripe.Bool(cancel),
+	})
+	if isStripeMissing(err) {
+		return nil
+	}
+	if err != nil {
+		return pkg.InternalError{Message: "Error updating subscription", Err: err}
+	}
+
+	slog.Info("Organisation subscription cancellation updated for deletion",
+		"organisation_id", organisationID,
+		"subscription_id", info.SubscriptionID,
+		"cancel_at_period_end", cancel)
+	return nil
+}
+
+// DeleteCustomer deletes an organisation's Stripe customer, which cancels any
+// remaining subscription straight away and removes its saved payment
+// methods. It's the last billing step of tearing down a deleted organisation
+// and does nothing when there's no customer, or it's already gone.
+func (s *Service) DeleteCustomer(ctx context.Context, organisationID uuid.UUID) error {
+	stripe.Key = s.cfg.StripeAPIKey
+
+	info, err := s.store.GetOrganisationBillingInfo(ctx, organisationID)
+	if err != nil {
+		return pkg.InternalError{Message: "Error getting organisation billing info", Err: err}
+	}
+	if info.StripeCustomerID == "" {
+		return nil
+	}
+
+	_, err = customer.Del(info.StripeCustomerID, &stripe.CustomerParams{Params: stripe.Params{Context: ctx}})
+	if err != nil && !isStripeMissing(err) {
+		return pkg.InternalError{Message: "Error deleting Stripe customer", Err: err}
+	}
+	slog.Info("Deleted Stripe customer", "organisation_id", organisationID, "customer_id", info.StripeCustomerID)
+	return nil
+}
+
+// isStripeMissing reports whether err is Stripe saying the object doesn't
+// exist, e.g. a customer deleted from the dashboard.
+func isStripeMissing(err error) bool {
+	var se *stripe.Error
+	return errors.As(err, &se) && se.Code == stripe.ErrorCodeResourceMissing
+}
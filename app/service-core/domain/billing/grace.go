@@ -0,0 +1,72 @@
+package billing
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// freeTierMaxCourses mirrors TIER_DEFINITIONS.free.maxCourses in the client.
+const freeTierMaxCourses = 5
+
+// Lock reason codes stored on courses and H5P content that exceed the free
+// tier after a subscription lapses. The client maps them to upsell copy.
+const (
+	// LockReasonCourseLimit marks a course beyond the free tier course limit.
+	LockReasonCourseLimit = "course_limit_exceeded"
+	// LockReasonCourseLocked marks H5P content used only by locked courses.
+	LockReasonCourseLocked = "course_locked"
+)
+
+// lockOverLimitContent makes everything above the free tier limits read-only
+// rather than hiding it: the most recently updated courses stay editable, the
+// rest are locked, along with H5P content that only locked courses use.
+// Locked items remain listable but can't be edited or embedded.
+func (s *Service) lockOverLimitContent(ctx context.Context, organisationID uuid.UUID) {
+	courses, err := s.store.LockCoursesOverLimit(ctx, query.LockCoursesOverLimitParams{
+		OrgID:      organisationID,
+		Limit:      freeTierMaxCourses,
+		LockReason: sql.NullString{String: LockReasonCourseLimit, Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to lock courses over free tier limit", "organisation_id", organisationID, "error", err)
+		return
+	}
+	content, err := s.store.LockContentOfLockedCourses(ctx, query.LockContentOfLockedCoursesParams{
+		OrgID:      organisationID,
+		LockReason: sql.NullString{String: LockReasonCourseLocked, Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to lock content of locked courses", "organisation_id", organisationID, "error", err)
+		return
+	}
+	if courses > 0 || content > 0 {
+		slog.Info("Locked content over free tier limits",
+			"organisation_id", organisationID,
+			"courses", courses,
+			"content", content)
+	}
+}
+
+// unlockContent lifts grace-period locks once the organisation is on a paid tier again.
+func (s *Service) unlockContent(ctx context.Context, organisationID uuid.UUID) {
+	if err := s.store.UnlockOrganisationCourses(ctx, organisationID); err != nil {
+		slog.Error("Failed to unlock courses", "organisation_id", organisationID, "error", err)
+	}
+	if err := s.store.UnlockOrganisationContent(ctx, organisationID); err != nil {
+		slog.Error("Failed to unlock content", "organisation_id", organisationID, "error", err)
+	}
+}
+
+// syncContentLocks locks over-limit content on the free tier and unlocks it on any paid tier.
+func (s *Service) syncContentLocks(ctx context.Context, organisationID uuid.UUID, tier string) {
+	if tier == "free" {
+		s.lockOverLimitContent(ctx, organisationID)
+		return
+	}
+	s.unlockContent(ctx, organisationID)
+}
@@ -0,0 +1,198 @@
+package billing
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"service-core/domain/h5p"
+	"service-core/domain/ranktracker"
+	"service-core/domain/ratelimit"
+	"service-core/domain/seo"
+
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/price"
+)
+
+const (
+	// planPriceTTL is how long prices looked up from Stripe are served before
+	// they're looked up again
+	planPriceTTL = time.Hour
+	// planPriceRetry is how long a failed lookup waits before it's retried
+	planPriceRetry = time.Minute
+)
+
+// planTiers lists the tiers in the order the pricing page shows them
+var planTiers = []string{"free", "starter", "growth", "enterprise"}
+
+// tierDefinition is what a tier includes beyond the limits other services
+// enforce. It mirrors TIER_DEFINITIONS in the client; -1 is unlimited.
+type tierDefinition struct {
+	maxMembers               int
+	maxCourses               int
+	maxAIGenerationsPerMonth int
+	maxTemplates             int
+	maxCustomTypes           int
+	aiCredits                int
+	features                 []string
+}
+
+var tierDefinitions = map[string]tierDefinition{
+	"free": {
+		maxMembers:               1,
+		maxCourses:               freeTierMaxCourses,
+		maxAIGenerationsPerMonth: 5,
+		maxTemplates:             3,
+		maxCustomTypes:           0,
+		aiCredits:                0,
+		features:                 []string{"ai_proposal_generation"},
+	},
+	"starter": {
+		maxMembers:               3,
+		maxCourses:               20,
+		maxAIGenerationsPerMonth: 25,
+		maxTemplates:             5,
+		maxCustomTypes:           0,
+		aiCredits:                50,
+		features:                 []string{"ai_proposal_generation"},
+	},
+	"growth": {
+		maxMembers:               10,
+		maxCourses:               100,
+		maxAIGenerationsPerMonth: 100,
+		maxTemplates:             20,
+		maxCustomTypes:           10,
+		aiCredits:                200,
+		features:                 []string{"custom_branding", "analytics", "white_label", "api_access", "ai_proposal_generation"},
+	},
+	"enterprise": {
+		maxMembers:               -1,
+		maxCourses:               -1,
+		maxAIGenerationsPerMonth: -1,
+		maxTemplates:             -1,
+		maxCustomTypes:           -1,
+		aiCredits:                -1,
+		features: []string{"custom_branding", "analytics", "white_label", "api_access", "priority_support",
+			"custom_domain", "sso", "ai_proposal_generation"},
+	},
+}
+
+// Plan is one tier of the pricing page: its prices, limits and features.
+// Prices are nil for the free tier and when Stripe isn't configured or can't
+// be reached.
+type Plan struct {
+	Tier     string     `json:"tier"`
+	Prices   PlanPrices `json:"prices"`
+	Limits   PlanLimits `json:"limits"`
+	Features []string   `json:"features"`
+}
+
+// PlanPrices are a tier's subscription prices per billing interval
+type PlanPrices struct {
+	Monthly *PlanPrice `json:"monthly"`
+	Yearly  *PlanPrice `json:"yearly"`
+}
+
+// PlanPrice is a Stripe price. Amount is in the currency's smallest unit,
+// e.g. cents.
+type PlanPrice struct {
+	PriceID  string `json:"priceId"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// PlanLimits are what a tier allows; -1 is unlimited. MaxLearners is nil
+// when learners aren't limited. ScheduledAudits is how often scheduled SEO
+// audits run, "daily" or "weekly", or empty when the tier doesn't include them.
+type PlanLimits struct {
+	MaxMembers               int    `json:"maxMembers"`
+	MaxCourses               int    `json:"maxCourses"`
+	MaxAIGenerationsPerMonth int    `json:"maxAIGenerationsPerMonth"`
+	MaxTemplates             int    `json:"maxTemplates"`
+	MaxStorageMB             int64  `json:"maxStorageMB"`
+	MaxCustomTypes           int    `json:"maxCustomTypes"`
+	AICredits                int    `json:"aiCredits"`
+	MaxLearners              *int   `json:"maxLearners"`
+	APIRequestsPerMinute     int    `json:"apiRequestsPerMinute"`
+	MaxTrackedKeywords       int64  `json:"maxTrackedKeywords"`
+	ScheduledAudits          string `json:"scheduledAudits"`
+}
+
+// priceCache keeps prices looked up from Stripe, keyed by price ID
+type priceCache struct {
+	mu     sync.Mutex
+	prices map[string]cachedPrice
+}
+
+type cachedPrice struct {
+	price   *PlanPrice
+	expires time.Time
+}
+
+// ListPlans returns every tier's prices, limits and features for the pricing
+// page. Limits come from the services that enforce them, and prices from the
+// Stripe prices configured for each tier.
+func (s *Service) ListPlans(ctx context.Context) []Plan {
+	plans := make([]Plan, len(planTiers))
+	for i, tier := range planTiers {
+		def := tierDefinitions[tier]
+		plans[i] = Plan{
+			Tier: tier,
+			Prices: PlanPrices{
+				Monthly: s.planPrice(ctx, tier, "month"),
+				Yearly:  s.planPrice(ctx, tier, "year"),
+			},
+			Limits: PlanLimits{
+				MaxMembers:               def.maxMembers,
+				MaxCourses:               def.maxCourses,
+				MaxAIGenerationsPerMonth: def.maxAIGenerationsPerMonth,
+				MaxTemplates:             def.maxTemplates,
+				MaxStorageMB:             h5p.TierStorageLimitMB(tier),
+				MaxCustomTypes:           def.maxCustomTypes,
+				AICredits:                def.aiCredits,
+				APIRequestsPerMinute:     ratelimit.TierRequestsPerMinute(tier),
+				MaxTrackedKeywords:       ranktracker.TierKeywordLimit(tier),
+				ScheduledAudits:          seo.TierScheduleInterval(tier),
+			},
+			Features: def.features,
+		}
+	}
+	return plans
+}
+
+// planPrice returns a tier's price for the interval, or nil when it has none
+// or it can't be looked up. A price Stripe can't return is served stale if
+// it was looked up before.
+func (s *Service) planPrice(ctx context.Context, tier, interval string) *PlanPrice {
+	if s.cfg.PaymentProvider != "stripe" {
+		return nil
+	}
+	priceID, err := s.getPriceID(tier, interval)
+	if err != nil {
+		return nil
+	}
+
+	s.prices.mu.Lock()
+	defer s.prices.mu.Unlock()
+	cached, ok := s.prices.prices[priceID]
+	if ok && time.Now().Before(cached.expires) {
+		return cached.price
+	}
+
+	stripe.Key = s.cfg.StripeAPIKey
+	p, err := price.Get(priceID, &stripe.PriceParams{Params: stripe.Params{Context: ctx}})
+	if err != nil {
+		slog.Error("Failed to look up plan price", "tier", tier, "interval", interval, "price_id", priceID, "error", err)
+		s.prices.prices[priceID] = cachedPrice{price: cached.price, expires: time.Now().Add(planPriceRetry)}
+		return cached.price
+	}
+	planPrice := &PlanPrice{
+		PriceID:  p.ID,
+		Amount:   p.UnitAmount,
+		Currency: strings.ToUpper(string(p.Currency)),
+	}
+	s.prices.prices[priceID] = cachedPrice{price: planPrice, expires: time.Now().Add(planPriceTTL)}
+	return planPrice
+}
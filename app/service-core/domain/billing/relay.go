@@ -0,0 +1,77 @@
+package billing
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Billing domain events relayed to organisation webhooks
+const (
+	EventTierChanged           = "billing.tier_changed"
+	EventRenewalUpdated        = "billing.renewal_updated"
+	EventCancellationScheduled = "billing.cancellation_scheduled"
+	EventPaymentFailed         = "billing.payment_failed"
+)
+
+// publisher delivers domain events to an organisation's registered webhooks
+type publisher interface {
+	Publish(ctx context.Context, organisationID uuid.UUID, eventType string, data map[string]any)
+}
+
+// relayFields whitelists the fields each event may carry. Anything else —
+// customer, subscription, invoice or price IDs and other Stripe internals — is dropped.
+var relayFields = map[string][]string{
+	EventTierChanged:           {"previousTier", "tier"},
+	EventRenewalUpdated:        {"tier", "renewsAt"},
+	EventCancellationScheduled: {"tier", "cancelsAt"},
+	EventPaymentFailed:         {"amountDue", "currency", "attemptCount", "nextAttemptAt"},
+}
+
+// stripeIDPattern matches Stripe object IDs (cus_..., sub_..., in_..., pi_...)
+var stripeIDPattern = regexp.MustCompile(`^[a-z]{2,6}_[A-Za-z0-9]{8,}$`)
+
+// redact returns only the whitelisted fields for eventType, dropping any value
+// that still looks like a Stripe object ID.
+func redact(eventType string, data map[string]any) map[string]any {
+	result := make(map[string]any)
+	for _, field := range relayFields[eventType] {
+		value, ok := data[field]
+		if !ok {
+			continue
+		}
+		if str, isString := value.(string); isString && stripeIDPattern.MatchString(str) {
+			continue
+		}
+		result[field] = value
+	}
+	return result
+}
+
+// relay publishes a sanitized billing event to the organisation's webhooks, if any
+func (s *Service) relay(ctx context.Context, organisationID uuid.UUID, eventType string, data map[string]any) {
+	if s.publisher == nil {
+		return
+	}
+	s.publisher.Publish(ctx, organisationID, eventType, redact(eventType, data))
+}
+
+// relaySubscriptionChange relays tier and renewal changes between the previous
+// and current subscription state. A nil end means Stripe didn't report the
+// period end, so no renewal event is sent.
+func (s *Service) relaySubscriptionChange(ctx context.Context, organisationID uuid.UUID, previousTier string, previousEnd *time.Time, tier string, end *time.Time) {
+	if previousTier != tier {
+		s.relay(ctx, organisationID, EventTierChanged, map[string]any{
+			"previousTier": previousTier,
+			"tier":         tier,
+		})
+	}
+	if end != nil && (previousEnd == nil || !previousEnd.Equal(*end)) {
+		s.relay(ctx, organisationID, EventRenewalUpdated, map[string]any{
+			"tier":     tier,
+			"renewsAt": end.UTC(),
+		})
+	}
+}
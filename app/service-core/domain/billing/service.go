@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"service-core/config"
 	"service-core/storage/query"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,19 +28,39 @@ type store interface {
 	UpdateOrganisationSubscription(ctx context.Context, arg query.UpdateOrganisationSubscriptionParams) error
 	GetOrganisationByStripeCustomer(ctx context.Context, stripeCustomerID string) (query.Organisation, error)
 	DowngradeOrganisationToFree(ctx context.Context, id uuid.UUID) error
+	GetOrganisationBillingContact(ctx context.Context, id uuid.UUID) (query.GetOrganisationBillingContactRow, error)
+	InsertOrganisationActivity(ctx context.Context, arg query.InsertOrganisationActivityParams) error
+	UpsertOrganisationPaymentMethod(ctx context.Context, arg query.UpsertOrganisationPaymentMethodParams) error
+	DeleteOrganisationPaymentMethod(ctx context.Context, organisationID uuid.UUID) error
+	ListExpiringPaymentMethods(ctx context.Context, expiresAt time.Time) ([]query.ListExpiringPaymentMethodsRow, error)
+	SetPaymentMethodReminderSent(ctx context.Context, arg query.SetPaymentMethodReminderSentParams) error
+	GetBillingContact(ctx context.Context, organisationID uuid.UUID) (query.OrganisationBillingContact, error)
+	UpsertBillingContact(ctx context.Context, arg query.UpsertBillingContactParams) (query.OrganisationBillingContact, error)
+	LockCoursesOverLimit(ctx context.Context, arg query.LockCoursesOverLimitParams) (int64, error)
+	LockContentOfLockedCourses(ctx context.Context, arg query.LockContentOfLockedCoursesParams) (int64, error)
+	UnlockOrganisationCourses(ctx context.Context, orgID uuid.UUID) error
+	UnlockOrganisationContent(ctx context.Context, orgID uuid.UUID) error
 }
 
 // Service handles organisation billing operations
 type Service struct {
-	cfg   *config.Config
-	store store
+	cfg       *config.Config
+	store     store
+	publisher publisher
+	mailer    mailer
+	prices    *priceCache
 }
 
-// NewService creates a new billing service
-func NewService(cfg *config.Config, store store) *Service {
+// NewService creates a new billing service. Processed Stripe events are relayed
+// to organisation webhooks via publisher, which may be nil to disable relaying.
+// mailer sends refund and credit confirmations to the billing contact.
+func NewService(cfg *config.Config, store store, publisher publisher, mailer mailer) *Service {
 	return &Service{
-		cfg:   cfg,
-		store: store,
+		cfg:       cfg,
+		store:     store,
+		publisher: publisher,
+		mailer:    mailer,
+		prices:    &priceCache{prices: map[string]cachedPrice{}},
 	}
 }
 
@@ -48,16 +69,50 @@ type URLResponse struct {
 	URL string `json:"url"`
 }
 
+// Checkout UI modes. Hosted redirects to a Stripe page; embedded returns a
+// client secret for mounting Checkout in our page with Stripe.js.
+const (
+	CheckoutUIModeHosted   = "hosted"
+	CheckoutUIModeEmbedded = "embedded"
+)
+
+// CheckoutOptions adjusts how a Checkout session is presented.
+type CheckoutOptions struct {
+	// UIMode is CheckoutUIModeHosted or CheckoutUIModeEmbedded. Empty uses
+	// STRIPE_CHECKOUT_UI_MODE, or hosted if that isn't set.
+	UIMode string
+	// ReturnPath is the client path the customer comes back to, e.g. "/pricing".
+	// Empty returns to the organisation's billing settings.
+	ReturnPath string
+}
+
+// CheckoutSessionResponse is a new Checkout session. Hosted sessions have a URL
+// to redirect to; embedded sessions have a client secret instead.
+type CheckoutSessionResponse struct {
+	SessionID    string `json:"sessionId"`
+	UIMode       string `json:"uiMode"`
+	URL          string `json:"url,omitempty"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+}
+
 // BillingInfo represents the billing information for an organisation
 type BillingInfo struct {
-	OrganisationID         uuid.UUID  `json:"organisationId"`
-	Name             string     `json:"name"`
-	Tier             string     `json:"tier"`
-	SubscriptionID   string     `json:"subscriptionId"`
-	SubscriptionEnd  *time.Time `json:"subscriptionEnd"`
-	StripeCustomerID string     `json:"stripeCustomerId"`
-	IsFreemium       bool       `json:"isFreemium"`
-	FreemiumExpires  *time.Time `json:"freemiumExpiresAt"`
+	OrganisationID   uuid.UUID         `json:"organisationId"`
+	Name             string            `json:"name"`
+	Tier             string            `json:"tier"`
+	SubscriptionID   string            `json:"subscriptionId"`
+	SubscriptionEnd  *time.Time        `json:"subscriptionEnd"`
+	StripeCustomerID string            `json:"stripeCustomerId"`
+	IsFreemium       bool              `json:"isFreemium"`
+	FreemiumExpires  *time.Time        `json:"freemiumExpiresAt"`
+	AIGenerations    AIGenerationUsage `json:"aiGenerations"`
+}
+
+// AIGenerationUsage is the AI generation count for the current monthly period,
+// which starts at midnight in the organisation's timezone.
+type AIGenerationUsage struct {
+	Used   int32      `json:"used"`
+	Period pkg.Period `json:"period"`
 }
 
 // getPriceID maps tier + interval to Stripe price ID
@@ -134,6 +189,14 @@ func (s *Service) GetBillingInfo(ctx context.Context, organisationID uuid.UUID,
 		result.FreemiumExpires = &info.FreemiumExpiresAt.Time
 	}
 
+	// The counter is only reset by the next generation, so a count left over
+	// from an earlier period reads as zero
+	period := pkg.MonthPeriod(time.Now(), pkg.LoadLocation(info.Timezone))
+	result.AIGenerations.Period = period
+	if info.AiGenerationsResetAt.Valid && !info.AiGenerationsResetAt.Time.Before(period.Start) {
+		result.AIGenerations.Used = info.AiGenerationsThisMonth
+	}
+
 	return result, nil
 }
 
@@ -273,6 +336,9 @@ func (s *Service) getOrCreateCustomer(ctx context.Context, organisationID uuid.U
 		return "", pkg.InternalError{Message: "Error updating organisation Stripe customer", Err: err}
 	}
 
+	// Invoices go to the billing contact rather than whoever started checkout
+	s.applyStoredBillingContact(ctx, organisationID, cust.ID)
+
 	return cust.ID, nil
 }
 
@@ -285,7 +351,8 @@ func (s *Service) CreateCheckoutSession(
 	organisationName string,
 	tier string,
 	interval string,
-) (*URLResponse, error) {
+	opts CheckoutOptions,
+) (*CheckoutSessionResponse, error) {
 	stripe.Key = s.cfg.StripeAPIKey
 
 	priceID, err := s.getPriceID(tier, interval)
@@ -293,6 +360,25 @@ func (s *Service) CreateCheckoutSession(
 		return nil, pkg.BadRequestError{Message: err.Error()}
 	}
 
+	uiMode := opts.UIMode
+	if uiMode == "" {
+		uiMode = s.cfg.StripeCheckoutUIMode
+	}
+	if uiMode == "" {
+		uiMode = CheckoutUIModeHosted
+	}
+	if uiMode != CheckoutUIModeHosted && uiMode != CheckoutUIModeEmbedded {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("Unsupported checkout UI mode %q", uiMode)}
+	}
+	returnPath := opts.ReturnPath
+	if returnPath == "" {
+		returnPath = fmt.Sprintf("/%s/settings/billing", organisationSlug)
+	}
+	// Only same-site paths, so the session can't be used as an open redirect
+	if !strings.HasPrefix(returnPath, "/") || strings.HasPrefix(returnPath, "//") || strings.ContainsAny(returnPath, "?#") {
+		return nil, pkg.BadRequestError{Message: "returnPath must be a path on this site"}
+	}
+
 	customerID, err := s.getOrCreateCustomer(ctx, organisationID, email, organisationName)
 	if err != nil {
 		return nil, err
@@ -308,8 +394,6 @@ func (s *Service) CreateCheckoutSession(
 				Quantity: stripe.Int64(1),
 			},
 		},
-		SuccessURL: stripe.String(fmt.Sprintf("%s/%s/settings/billing?success=true&session_id={CHECKOUT_SESSION_ID}", s.cfg.ClientURL, organisationSlug)),
-		CancelURL:  stripe.String(fmt.Sprintf("%s/%s/settings/billing?canceled=true", s.cfg.ClientURL, organisationSlug)),
 		Metadata: map[string]string{
 			"organisation_id": organisationID.String(),
 			"tier":      tier,
@@ -322,17 +406,36 @@ func (s *Service) CreateCheckoutSession(
 		},
 		AllowPromotionCodes: stripe.Bool(true),
 	}
+	returnURL := s.cfg.ClientURL + returnPath
+	if uiMode == CheckoutUIModeEmbedded {
+		// Embedded checkout has no cancel page: the customer just leaves ours
+		params.UIMode = stripe.String(string(stripe.CheckoutSessionUIModeEmbedded))
+		params.ReturnURL = stripe.String(returnURL + "?success=true&session_id={CHECKOUT_SESSION_ID}")
+	} else {
+		params.SuccessURL = stripe.String(returnURL + "?success=true&session_id={CHECKOUT_SESSION_ID}")
+		params.CancelURL = stripe.String(returnURL + "?canceled=true")
+	}
 
 	sess, err := checkout_session.New(params)
 	if err != nil {
 		return nil, pkg.InternalError{Message: "Error creating checkout session", Err: err}
 	}
 
-	return &URLResponse{URL: sess.URL}, nil
+	return &CheckoutSessionResponse{
+		SessionID:    sess.ID,
+		UIMode:       uiMode,
+		URL:          sess.URL,
+		ClientSecret: sess.ClientSecret,
+	}, nil
 }
 
-// CreatePortalSession creates a Stripe Billing Portal session
-func (s *Service) CreatePortalSession(ctx context.Context, organisationID uuid.UUID, organisationSlug string) (*URLResponse, error) {
+// PortalFlowPaymentMethodUpdate opens the billing portal directly on the
+// payment method update page
+const PortalFlowPaymentMethodUpdate = "payment_method_update"
+
+// CreatePortalSession creates a Stripe Billing Portal session. A non-empty flow
+// (e.g. PortalFlowPaymentMethodUpdate) deep links into that portal flow.
+func (s *Service) CreatePortalSession(ctx context.Context, organisationID uuid.UUID, organisationSlug, flow string) (*URLResponse, error) {
 	stripe.Key = s.cfg.StripeAPIKey
 
 	info, err := s.store.GetOrganisationBillingInfo(ctx, organisationID)
@@ -349,6 +452,15 @@ func (s *Service) CreatePortalSession(ctx context.Context, organisationID uuid.U
 		Customer:  stripe.String(info.StripeCustomerID),
 		ReturnURL: stripe.String(fmt.Sprintf("%s/%s/settings/billing", s.cfg.ClientURL, organisationSlug)),
 	}
+	switch flow {
+	case "":
+	case PortalFlowPaymentMethodUpdate:
+		params.FlowData = &stripe.BillingPortalSessionFlowDataParams{
+			Type: stripe.String(flow),
+		}
+	default:
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("Unsupported portal flow %q", flow)}
+	}
 
 	sess, err := portal_session.New(params)
 	if err != nil {
@@ -506,6 +618,8 @@ func (s *Service) SyncSubscriptionFromSession(ctx context.Context, sessionID str
 		return pkg.InternalError{Message: "Error updating organisation subscription", Err: err}
 	}
 
+	s.syncContentLocks(ctx, organisationID, tier)
+
 	slog.Info("Organisation subscription synced from session",
 		"organisation_id", organisationID,
 		"tier", tier,
@@ -544,6 +658,15 @@ func (s *Service) HandleWebhook(ctx context.Context, payload []byte, signature s
 		return s.handleSubscriptionDeleted(ctx, event)
 	case "invoice.payment_failed":
 		return s.handlePaymentFailed(ctx, event)
+	case "customer.source.expiring", "payment_method.attached", "payment_method.updated",
+		"payment_method.automatically_updated":
+		return s.handlePaymentMethodEvent(ctx, event)
+	case "customer.updated":
+		var cust stripe.Customer
+		if err := json.Unmarshal(event.Data.Raw, &cust); err != nil {
+			return pkg.InternalError{Message: "Error parsing customer", Err: err}
+		}
+		return s.syncPaymentMethod(ctx, cust.ID)
 	default:
 		// Log but don't error on unhandled events
 		slog.Info("Unhandled billing webhook event", "type", event.Type)
@@ -590,13 +713,18 @@ func (s *Service) handleCheckoutCompleted(ctx context.Context, event stripe.Even
 	_ = json.Unmarshal(subJSON, &rawSub)
 
 	var endDate time.Time
+	var periodEndKnown bool
 	if periodEnd, ok := rawSub["current_period_end"].(float64); ok && periodEnd > 0 {
 		endDate = time.Unix(int64(periodEnd), 0)
+		periodEndKnown = true
 	} else {
 		// Fallback: set end date to 30 days from now
 		endDate = time.Now().AddDate(0, 1, 0)
 	}
 
+	// Previous state, for relaying what changed
+	previous, previousErr := s.store.GetOrganisationBillingInfo(ctx, organisationID)
+
 	err = s.store.UpdateOrganisationSubscription(ctx, query.UpdateOrganisationSubscriptionParams{
 		ID:               organisationID,
 		SubscriptionTier: tier,
@@ -607,12 +735,27 @@ func (s *Service) handleCheckoutCompleted(ctx context.Context, event stripe.Even
 		return pkg.InternalError{Message: "Error updating organisation subscription", Err: err}
 	}
 
+	s.syncContentLocks(ctx, organisationID, tier)
+
+	if previousErr == nil {
+		s.relaySubscriptionChange(ctx, organisationID,
+			previous.SubscriptionTier, nullTimePtr(previous.SubscriptionEnd),
+			tier, optionalTime(endDate, periodEndKnown))
+	}
+
 	slog.Info("Organisation subscription created",
 		"organisation_id", organisationID,
 		"tier", tier,
 		"subscription_id", sub.ID,
 		"ends", endDate)
 
+	// The checkout's card becomes the renewal card; track its expiry
+	if sess.Customer != nil {
+		if err := s.syncPaymentMethod(ctx, sess.Customer.ID); err != nil {
+			slog.Warn("Failed to sync payment method after checkout", "error", err, "organisation_id", organisationID)
+		}
+	}
+
 	return nil
 }
 
@@ -634,6 +777,10 @@ func (s *Service) handleSubscriptionUpdated(ctx context.Context, event stripe.Ev
 		slog.Info("Organisation subscription scheduled for cancellation",
 			"organisation_id", organisation.ID,
 			"cancels_at", time.Unix(sub.CancelAt, 0))
+		s.relay(ctx, organisation.ID, EventCancellationScheduled, map[string]any{
+			"tier":      organisation.SubscriptionTier,
+			"cancelsAt": time.Unix(sub.CancelAt, 0).UTC(),
+		})
 		return nil
 	}
 
@@ -647,8 +794,10 @@ func (s *Service) handleSubscriptionUpdated(ctx context.Context, event stripe.Ev
 	var rawSub map[string]interface{}
 	_ = json.Unmarshal(event.Data.Raw, &rawSub)
 	var endDate time.Time
+	var periodEndKnown bool
 	if periodEnd, ok := rawSub["current_period_end"].(float64); ok && periodEnd > 0 {
 		endDate = time.Unix(int64(periodEnd), 0)
+		periodEndKnown = true
 	} else {
 		// Fallback: set end date to 30 days from now
 		endDate = time.Now().AddDate(0, 1, 0)
@@ -664,12 +813,18 @@ func (s *Service) handleSubscriptionUpdated(ctx context.Context, event stripe.Ev
 		return pkg.InternalError{Message: "Error updating organisation subscription", Err: err}
 	}
 
+	s.syncContentLocks(ctx, organisation.ID, tier)
+
 	slog.Info("Organisation subscription updated",
 		"organisation_id", organisation.ID,
 		"tier", tier,
 		"subscription_id", sub.ID,
 		"ends", endDate)
 
+	s.relaySubscriptionChange(ctx, organisation.ID,
+		organisation.SubscriptionTier, nullTimePtr(organisation.SubscriptionEnd),
+		tier, optionalTime(endDate, periodEndKnown))
+
 	return nil
 }
 
@@ -691,6 +846,8 @@ func (s *Service) handleSubscriptionDeleted(ctx context.Context, event stripe.Ev
 	}
 
 	slog.Info("Organisation downgraded to free tier", "organisation_id", organisation.ID)
+	s.lockOverLimitContent(ctx, organisation.ID)
+	s.relaySubscriptionChange(ctx, organisation.ID, organisation.SubscriptionTier, nil, "free", nil)
 	return nil
 }
 
@@ -707,5 +864,33 @@ func (s *Service) handlePaymentFailed(ctx context.Context, event stripe.Event) e
 		"amount", invoice.AmountDue,
 		"attempt_count", invoice.AttemptCount)
 
+	organisation, err := s.store.GetOrganisationByStripeCustomer(ctx, invoice.Customer.ID)
+	if err != nil {
+		return nil // Not an organisation customer
+	}
+	data := map[string]any{
+		"amountDue":    invoice.AmountDue,
+		"currency":     string(invoice.Currency),
+		"attemptCount": invoice.AttemptCount,
+	}
+	if invoice.NextPaymentAttempt > 0 {
+		data["nextAttemptAt"] = time.Unix(invoice.NextPaymentAttempt, 0).UTC()
+	}
+	s.relay(ctx, organisation.ID, EventPaymentFailed, data)
+
 	return nil
 }
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func optionalTime(t time.Time, ok bool) *time.Time {
+	if !ok {
+		return nil
+	}
+	return &t
+}
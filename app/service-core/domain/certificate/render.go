@@ -0,0 +1,161 @@
+package certificate
+
+import (
+	"app/pkg"
+	"app/pkg/cfbrowser"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"service-core/domain/file"
+	"service-core/storage/query"
+)
+
+// certificateTemplate is a one-page landscape certificate. The worker prints
+// it without a base URL, so styles stay inline and the background is a data
+// URI.
+var certificateTemplate = template.Must(template.New("certificate").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Heading}}</title>
+<style>
+	@page { size: A4 landscape; margin: 0; }
+	html, body { margin: 0; height: 100%; }
+	body { font-family: Georgia, "Times New Roman", serif; color: #1f2937; }
+	.page { position: relative; width: 297mm; height: 210mm; overflow: hidden; }
+	.background { position: absolute; inset: 0; width: 100%; height: 100%; object-fit: cover; }
+	.content { position: relative; box-sizing: border-box; height: 100%; padding: 30mm 25mm; display: flex; flex-direction: column; justify-content: center; align-items: center; text-align: center; }
+	h1 { font-size: 34pt; font-weight: normal; letter-spacing: 0.04em; margin: 0 0 12mm; }
+	.label { font-size: 12pt; font-style: italic; color: #4b5563; margin: 4mm 0 1mm; }
+	.learner { font-size: 28pt; margin: 0 0 4mm; }
+	.course { font-size: 20pt; margin: 0 0 4mm; }
+	.value { font-size: 14pt; margin: 0 0 2mm; }
+	.signatory { margin-top: 14mm; min-width: 70mm; border-top: 1px solid #1f2937; padding-top: 2mm; font-size: 12pt; }
+	.id { position: absolute; bottom: 10mm; left: 0; right: 0; font-family: monospace; font-size: 9pt; color: #6b7280; }
+</style>
+</head>
+<body>
+<div class="page">
+{{if .Background}}<img class="background" src="{{.Background}}" alt="">{{end}}
+<div class="content">
+<h1>{{.Heading}}</h1>
+{{range .Fields}}
+{{if eq . "learner"}}<p class="label">This certifies that</p><p class="learner">{{$.Cert.LearnerName}}</p>
+{{else if eq . "course"}}<p class="label">has completed</p><p class="course">{{$.Cert.CourseTitle}}</p>
+{{else if eq . "completed_at"}}<p class="label">on</p><p class="value">{{$.Cert.CompletedAt.Format "2 January 2006"}}</p>
+{{else if eq . "organisation"}}<p class="label">awarded by</p><p class="value">{{$.Cert.OrganisationName}}</p>
+{{else if eq . "signatory"}}<div class="signatory">{{$.SignatoryName}}{{if $.SignatoryTitle}}<br>{{$.SignatoryTitle}}{{end}}</div>
+{{end}}
+{{end}}
+</div>
+{{if .ShowID}}<p class="id">Certificate {{.Cert.ID}} · verify at {{.VerifyURL}}</p>{{end}}
+</div>
+</body>
+</html>
+`))
+
+// certificatePDFOptions prints the page edge to edge, background included
+var certificatePDFOptions = cfbrowser.PDFOptions{
+	Format:          "A4",
+	Landscape:       true,
+	PrintBackground: true,
+	PageOptions: cfbrowser.PageOptions{
+		DisableJavaScript: true,
+	},
+}
+
+// unsafeFilenameChars are replaced when a course title becomes a filename
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// filename is the download name of a certificate, e.g. "intro-to-h5p-certificate.pdf"
+func filename(cert query.Certificate) string {
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(strings.ToLower(cert.CourseTitle), "-"), "-")
+	if name == "" {
+		return "certificate.pdf"
+	}
+	return name + "-certificate.pdf"
+}
+
+// ensurePDF returns the certificate's PDF, rendering and storing it the first
+// time. It's rendered with the template the organisation has at that point.
+func (s *Service) ensurePDF(ctx context.Context, cert query.Certificate) ([]byte, error) {
+	if cert.StorageKey.Valid {
+		data, err := s.files.Download(ctx, cert.StorageKey.String)
+		if err == nil {
+			return data, nil
+		}
+		slog.Warn("Stored certificate missing; rendering it again", "certificateID", cert.ID, "error", err)
+	}
+	if s.pdf == nil {
+		return nil, pkg.BadRequestError{Message: "Certificates can't be rendered right now; try again later"}
+	}
+
+	doc, err := s.renderHTML(ctx, cert)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.pdf.RenderHTMLPDF(ctx, doc, certificatePDFOptions)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error rendering certificate", Err: err}
+	}
+
+	key := fmt.Sprintf("%s%s.pdf", storagePrefix(cert.OrgID), cert.ID)
+	if err := s.files.Upload(ctx, &file.File{Key: key, ContentType: "application/pdf", Data: data}); err != nil {
+		// The learner still gets their download; it's rendered again next time
+		slog.Error("Failed to store rendered certificate", "certificateID", cert.ID, "error", err)
+		return data, nil
+	}
+	if err := s.store.SetCertificateStorageKey(ctx, query.SetCertificateStorageKeyParams{
+		ID:         cert.ID,
+		StorageKey: sql.NullString{String: key, Valid: true},
+	}); err != nil {
+		slog.Error("Failed to record rendered certificate", "certificateID", cert.ID, "error", err)
+	}
+	slog.Info("Certificate rendered", "certificateID", cert.ID, "bytes", len(data))
+	return data, nil
+}
+
+// renderHTML lays a certificate out with its organisation's template
+func (s *Service) renderHTML(ctx context.Context, cert query.Certificate) (string, error) {
+	t, err := s.template(ctx, cert.OrgID)
+	if err != nil {
+		return "", err
+	}
+	var background template.URL
+	if t.backgroundKey != "" {
+		data, err := s.files.Download(ctx, t.backgroundKey)
+		if err != nil {
+			slog.Warn("Certificate background missing; rendering without it", "orgID", cert.OrgID, "error", err)
+		} else {
+			background = template.URL("data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data))
+		}
+	}
+
+	var buf bytes.Buffer
+	err = certificateTemplate.Execute(&buf, struct {
+		Template
+		Cert       query.Certificate
+		Background template.URL
+		ShowID     bool
+		VerifyURL  string
+	}{
+		Template:   t,
+		Cert:       cert,
+		Background: background,
+		ShowID:     slices.Contains(t.Fields, FieldCertificateID),
+		VerifyURL:  s.verifyURL(cert.ID),
+	})
+	if err != nil {
+		return "", pkg.InternalError{Message: "Error laying out certificate", Err: err}
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,207 @@
+// Package certificate issues course completion certificates, renders them to
+// PDF with the organisation's template and verifies them by ID.
+package certificate
+
+import (
+	"app/pkg"
+	"app/pkg/cfbrowser"
+	"app/pkg/jobs"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"service-core/config"
+	"service-core/domain/file"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// jobRenderCertificate renders a newly issued certificate to PDF. Its payload
+// is the certificate ID.
+const jobRenderCertificate = "certificates.render"
+
+type store interface {
+	GetCertificateTemplate(ctx context.Context, organisationID uuid.UUID) (query.CertificateTemplate, error)
+	UpsertCertificateTemplate(ctx context.Context, arg query.UpsertCertificateTemplateParams) (query.CertificateTemplate, error)
+	SetCertificateTemplateBackground(ctx context.Context, arg query.SetCertificateTemplateBackgroundParams) error
+	IssueCertificate(ctx context.Context, enrolmentID uuid.UUID) (query.Certificate, error)
+	GetCertificate(ctx context.Context, id uuid.UUID) (query.Certificate, error)
+	SetCertificateStorageKey(ctx context.Context, arg query.SetCertificateStorageKeyParams) error
+	RecordCertificateDownload(ctx context.Context, arg query.RecordCertificateDownloadParams) error
+	ListUserCertificates(ctx context.Context, userID uuid.UUID) ([]query.ListUserCertificatesRow, error)
+}
+
+// pdfRenderer prints certificates; satisfied by every cfbrowser.Renderer.
+type pdfRenderer interface {
+	RenderHTMLPDF(ctx context.Context, html string, opts cfbrowser.PDFOptions) ([]byte, error)
+}
+
+// Service issues, renders and verifies certificates
+type Service struct {
+	cfg   *config.Config
+	store store
+	files file.Provider
+	pdf   pdfRenderer // nil without a browser renderer
+	jobs  *jobs.Queue // nil renders certificates on first download only
+}
+
+// NewService creates a new certificate service and registers its render job
+// with queue. Certificates are printed by pdf; either may be nil.
+func NewService(cfg *config.Config, store store, files file.Provider, queue *jobs.Queue, pdf pdfRenderer) *Service {
+	s := &Service{
+		cfg:   cfg,
+		store: store,
+		files: files,
+		pdf:   pdf,
+		jobs:  queue,
+	}
+	if queue != nil {
+		queue.Register(jobRenderCertificate, s.runRender, jobs.MaxAttempts(3))
+	}
+	return s
+}
+
+// Certificate is an issued certificate as its learner sees it
+type Certificate struct {
+	ID               uuid.UUID  `json:"id"`
+	OrganisationID   uuid.UUID  `json:"organisationId"`
+	CourseID         *uuid.UUID `json:"courseId"`
+	LearnerName      string     `json:"learnerName"`
+	CourseTitle      string     `json:"courseTitle"`
+	OrganisationName string     `json:"organisationName"`
+	CompletedAt      time.Time  `json:"completedAt"`
+	IssuedAt         time.Time  `json:"issuedAt"`
+	Downloads        int64      `json:"downloads"`
+	LastDownloadedAt *time.Time `json:"lastDownloadedAt"`
+	VerifyURL        string     `json:"verifyUrl"`
+}
+
+// Verification is what anyone holding a certificate ID can check about it
+type Verification struct {
+	Valid            bool      `json:"valid"`
+	CertificateID    uuid.UUID `json:"certificateId"`
+	LearnerName      string    `json:"learnerName"`
+	CourseTitle      string    `json:"courseTitle"`
+	OrganisationName string    `json:"organisationName"`
+	CompletedAt      time.Time `json:"completedAt"`
+	IssuedAt         time.Time `json:"issuedAt"`
+}
+
+// verifyURL is where a certificate can be checked
+func (s *Service) verifyURL(id uuid.UUID) string {
+	return fmt.Sprintf("%s/verify/%s", s.cfg.CoreURL, id)
+}
+
+// IssueForEnrolment issues the certificate for a completed enrolment and
+// queues it for rendering. An enrolment gets one certificate; issuing again,
+// or for an enrolment that isn't completed, does nothing and returns nil.
+func (s *Service) IssueForEnrolment(ctx context.Context, enrolmentID uuid.UUID) (*query.Certificate, error) {
+	cert, err := s.store.IssueCertificate(ctx, enrolmentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error issuing certificate", Err: err}
+	}
+	slog.Info("Certificate issued", "certificateID", cert.ID, "orgID", cert.OrgID, "enrolmentID", enrolmentID)
+
+	if s.jobs != nil && s.pdf != nil {
+		if _, err := s.jobs.Enqueue(ctx, jobRenderCertificate, cert.ID); err != nil {
+			// Rendered on first download instead
+			slog.Warn("Failed to queue certificate rendering", "certificateID", cert.ID, "error", err)
+		}
+	}
+	return &cert, nil
+}
+
+// runRender renders a certificate ahead of its first download
+func (s *Service) runRender(ctx context.Context, payload json.RawMessage) error {
+	var id uuid.UUID
+	if err := json.Unmarshal(payload, &id); err != nil {
+		return fmt.Errorf("decoding certificate render job: %w", err)
+	}
+	cert, err := s.store.GetCertificate(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil // the learner or organisation is gone
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.ensurePDF(ctx, cert)
+	return err
+}
+
+// Download returns a learner's certificate as a PDF and records the download.
+// Other users' certificates are reported as not found.
+func (s *Service) Download(ctx context.Context, userID, id uuid.UUID) ([]byte, string, error) {
+	cert, err := s.store.GetCertificate(ctx, id)
+	if err != nil || cert.UserID != userID {
+		return nil, "", pkg.NotFoundError{Message: "Certificate not found", Err: err}
+	}
+	data, err := s.ensurePDF(ctx, cert)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.store.RecordCertificateDownload(ctx, query.RecordCertificateDownloadParams{
+		CertificateID: cert.ID,
+		UserID:        userID,
+	}); err != nil {
+		slog.Error("Failed to record certificate download", "certificateID", cert.ID, "error", err)
+	}
+	return data, filename(cert), nil
+}
+
+// ListForUser returns the user's certificates across organisations, newest
+// first, with how often and when each was last downloaded.
+func (s *Service) ListForUser(ctx context.Context, userID uuid.UUID) ([]Certificate, error) {
+	rows, err := s.store.ListUserCertificates(ctx, userID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing certificates", Err: err}
+	}
+	result := make([]Certificate, len(rows))
+	for i, row := range rows {
+		result[i] = Certificate{
+			ID:               row.ID,
+			OrganisationID:   row.OrgID,
+			LearnerName:      row.LearnerName,
+			CourseTitle:      row.CourseTitle,
+			OrganisationName: row.OrganisationName,
+			CompletedAt:      row.CompletedAt,
+			IssuedAt:         row.IssuedAt,
+			Downloads:        row.Downloads,
+			VerifyURL:        s.verifyURL(row.ID),
+		}
+		if row.CourseID.Valid {
+			result[i].CourseID = &row.CourseID.UUID
+		}
+		if row.Downloads > 0 {
+			result[i].LastDownloadedAt = &row.LastDownloadedAt
+		}
+	}
+	return result, nil
+}
+
+// Verify looks a certificate up by ID
+func (s *Service) Verify(ctx context.Context, id uuid.UUID) (*Verification, error) {
+	cert, err := s.store.GetCertificate(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "No certificate was issued with this ID", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading certificate", Err: err}
+	}
+	return &Verification{
+		Valid:            true,
+		CertificateID:    cert.ID,
+		LearnerName:      cert.LearnerName,
+		CourseTitle:      cert.CourseTitle,
+		OrganisationName: cert.OrganisationName,
+		CompletedAt:      cert.CompletedAt,
+		IssuedAt:         cert.IssuedAt,
+	}, nil
+}
@@ -0,0 +1,192 @@
+package certificate
+
+import (
+	"app/pkg"
+	"app/pkg/ids"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"service-core/domain/file"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Fields a template can print, in the order it lists them
+const (
+	FieldLearner       = "learner"
+	FieldCourse        = "course"
+	FieldCompletedAt   = "completed_at"
+	FieldOrganisation  = "organisation"
+	FieldCertificateID = "certificate_id"
+	FieldSignatory     = "signatory"
+)
+
+var allFields = []string{FieldLearner, FieldCourse, FieldCompletedAt, FieldOrganisation, FieldCertificateID, FieldSignatory}
+
+const (
+	// MaxBackgroundSize caps background images; they're inlined into the
+	// document sent to the rendering worker
+	MaxBackgroundSize = 5 << 20
+	maxHeadingLength  = 120
+	maxSignatoryLen   = 120
+)
+
+// defaultTemplate is used until an organisation saves its own
+var defaultTemplate = Template{
+	Heading: "Certificate of Completion",
+	Fields:  []string{FieldLearner, FieldCourse, FieldCompletedAt, FieldOrganisation, FieldCertificateID},
+}
+
+// Template is how an organisation's certificates are laid out
+type Template struct {
+	Heading        string     `json:"heading"`
+	Fields         []string   `json:"fields"`
+	SignatoryName  string     `json:"signatoryName"`
+	SignatoryTitle string     `json:"signatoryTitle"`
+	HasBackground  bool       `json:"hasBackground"`
+	UpdatedAt      *time.Time `json:"updatedAt"`
+
+	backgroundKey string
+}
+
+// TemplateInput is the part of a template an admin edits
+type TemplateInput struct {
+	Heading        string   `json:"heading"`
+	Fields         []string `json:"fields"`
+	SignatoryName  string   `json:"signatoryName"`
+	SignatoryTitle string   `json:"signatoryTitle"`
+}
+
+func toTemplate(t query.CertificateTemplate) Template {
+	result := Template{
+		Heading:        t.Heading,
+		SignatoryName:  t.SignatoryName,
+		SignatoryTitle: t.SignatoryTitle,
+		HasBackground:  t.BackgroundKey.Valid,
+		UpdatedAt:      &t.UpdatedAt,
+		backgroundKey:  t.BackgroundKey.String,
+	}
+	if err := json.Unmarshal(t.Fields, &result.Fields); err != nil {
+		result.Fields = defaultTemplate.Fields
+	}
+	return result
+}
+
+// template returns the organisation's template, or the default one
+func (s *Service) template(ctx context.Context, orgID uuid.UUID) (Template, error) {
+	t, err := s.store.GetCertificateTemplate(ctx, orgID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return defaultTemplate, nil
+	}
+	if err != nil {
+		return Template{}, pkg.InternalError{Message: "Error loading certificate template", Err: err}
+	}
+	return toTemplate(t), nil
+}
+
+// GetTemplate returns the organisation's certificate template
+func (s *Service) GetTemplate(ctx context.Context, orgID uuid.UUID) (*Template, error) {
+	t, err := s.template(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// UpdateTemplate saves the organisation's heading, fields and signatory.
+// Certificates already rendered keep their layout.
+func (s *Service) UpdateTemplate(ctx context.Context, orgID uuid.UUID, input TemplateInput) (*Template, error) {
+	input.Heading = strings.TrimSpace(input.Heading)
+	input.SignatoryName = strings.TrimSpace(input.SignatoryName)
+	input.SignatoryTitle = strings.TrimSpace(input.SignatoryTitle)
+	if input.Heading == "" || len(input.Heading) > maxHeadingLength {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("heading must be 1 to %d characters", maxHeadingLength)}
+	}
+	if len(input.SignatoryName) > maxSignatoryLen || len(input.SignatoryTitle) > maxSignatoryLen {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("signatory name and title must be at most %d characters", maxSignatoryLen)}
+	}
+	if len(input.Fields) == 0 {
+		return nil, pkg.BadRequestError{Message: "fields must list at least one field"}
+	}
+	for i, f := range input.Fields {
+		if !slices.Contains(allFields, f) {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("unknown field %q; fields are %s", f, strings.Join(allFields, ", "))}
+		}
+		if slices.Contains(input.Fields[:i], f) {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("field %q is listed twice", f)}
+		}
+	}
+	if slices.Contains(input.Fields, FieldSignatory) && input.SignatoryName == "" {
+		return nil, pkg.BadRequestError{Message: "signatoryName is required to print the signatory"}
+	}
+
+	fields, err := json.Marshal(input.Fields)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error encoding fields", Err: err}
+	}
+	t, err := s.store.UpsertCertificateTemplate(ctx, query.UpsertCertificateTemplateParams{
+		OrganisationID: orgID,
+		Heading:        input.Heading,
+		Fields:         fields,
+		SignatoryName:  input.SignatoryName,
+		SignatoryTitle: input.SignatoryTitle,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error saving certificate template", Err: err}
+	}
+	result := toTemplate(t)
+	return &result, nil
+}
+
+// SetBackground stores a PNG or JPEG as the background of the organisation's
+// certificates. The image it replaces is queued for deletion.
+func (s *Service) SetBackground(ctx context.Context, orgID uuid.UUID, data []byte) (*Template, error) {
+	if len(data) == 0 || len(data) > MaxBackgroundSize {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("background must be at most %d MB", MaxBackgroundSize>>20)}
+	}
+	var ext string
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/png":
+		ext = "png"
+	case "image/jpeg":
+		ext = "jpg"
+	default:
+		return nil, pkg.BadRequestError{Message: "background must be a PNG or JPEG image"}
+	}
+
+	key := fmt.Sprintf("%sbackground-%s.%s", storagePrefix(orgID), ids.New(), ext)
+	if err := s.files.Upload(ctx, &file.File{Key: key, ContentType: contentType, Data: data}); err != nil {
+		return nil, pkg.InternalError{Message: "Error storing background", Err: err}
+	}
+	return s.setBackgroundKey(ctx, orgID, sql.NullString{String: key, Valid: true})
+}
+
+// RemoveBackground prints the organisation's certificates on plain paper
+func (s *Service) RemoveBackground(ctx context.Context, orgID uuid.UUID) (*Template, error) {
+	return s.setBackgroundKey(ctx, orgID, sql.NullString{})
+}
+
+func (s *Service) setBackgroundKey(ctx context.Context, orgID uuid.UUID, key sql.NullString) (*Template, error) {
+	if err := s.store.SetCertificateTemplateBackground(ctx, query.SetCertificateTemplateBackgroundParams{
+		OrganisationID: orgID,
+		BackgroundKey:  key,
+	}); err != nil {
+		return nil, pkg.InternalError{Message: "Error saving certificate template", Err: err}
+	}
+	return s.GetTemplate(ctx, orgID)
+}
+
+// storagePrefix is where an organisation's certificates and backgrounds are
+// stored.
+func storagePrefix(orgID uuid.UUID) string {
+	return fmt.Sprintf("certificates/%s/", orgID)
+}
@@ -0,0 +1,196 @@
+package competitors
+
+import (
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Alert kinds.
+const (
+	AlertKeywordGap   = "keyword_gap"   // the competitor started ranking for keywords the target doesn't
+	AlertBacklinkGain = "backlink_gain" // the competitor gained many referring domains
+)
+
+// Market identifies one of an organisation's sites in one Google market,
+// which competitors are pinned against.
+type Market struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	Target         string    `json:"target"`
+	LocationCode   int       `json:"locationCode"`
+	LanguageCode   string    `json:"languageCode"`
+}
+
+// PinRequest pins competitor domains against a target.
+type PinRequest struct {
+	Market
+	Domains []string `json:"domains"`
+}
+
+// SuggestRequest asks for the domains competing with a target for its
+// keywords.
+type SuggestRequest struct {
+	Market
+	Limit int `json:"limit"` // default 20, at most MaxSuggestions
+}
+
+// Pin is a competitor domain an organisation monitors against one of its
+// sites.
+type Pin struct {
+	ID             uuid.UUID  `json:"id"`
+	Target         string     `json:"target"`
+	Domain         string     `json:"domain"`
+	LocationCode   int32      `json:"locationCode"`
+	LanguageCode   string     `json:"languageCode"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	RefreshedAt    *time.Time `json:"refreshedAt"` // nil until the first refresh
+	NextRefreshAt  time.Time  `json:"nextRefreshAt"`
+	KeywordGaps    int64      `json:"keywordGaps"`
+	NewKeywordGaps int64      `json:"newKeywordGaps"` // first seen in the latest refresh
+}
+
+// Suggestion is a domain that ranks for many of the target's keywords.
+type Suggestion struct {
+	Domain        string  `json:"domain"`
+	Intersections int     `json:"intersections"` // keywords both rank for
+	AvgPosition   float64 `json:"avgPosition"`
+	OrganicETV    float64 `json:"organicEtv"`
+	Pinned        bool    `json:"pinned"`
+}
+
+// KeywordGap is a keyword the competitor ranks in the top 10 for and the
+// target doesn't rank for at all.
+type KeywordGap struct {
+	Keyword      string    `json:"keyword"`
+	SearchVolume *int32    `json:"searchVolume"`
+	Position     int32     `json:"position"` // the competitor's
+	URL          string    `json:"url"`
+	New          bool      `json:"new"`
+	FirstSeenAt  time.Time `json:"firstSeenAt"`
+}
+
+// Alert flags a competitor's new keyword gaps or backlink gains.
+type Alert struct {
+	ID        uuid.UUID `json:"id"`
+	PinID     uuid.UUID `json:"pinId"`
+	Kind      string    `json:"kind"`
+	Message   string    `json:"message"`
+	Keywords  []string  `json:"keywords"` // the new gaps, for keyword_gap alerts
+	Amount    int32     `json:"amount"`   // gaps or referring domains gained
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Metrics are a domain's organic and backlink metrics at one refresh. Each
+// is nil when DataForSEO has no data for the domain.
+type Metrics struct {
+	CapturedAt       time.Time `json:"capturedAt"`
+	OrganicETV       *float64  `json:"organicEtv"` // estimated monthly organic traffic
+	OrganicKeywords  *int32    `json:"organicKeywords"`
+	Top3Keywords     *int32    `json:"top3Keywords"`
+	Top10Keywords    *int32    `json:"top10Keywords"`
+	DomainRank       *int32    `json:"domainRank"`
+	Backlinks        *int64    `json:"backlinks"`
+	ReferringDomains *int32    `json:"referringDomains"`
+}
+
+// DomainComparison is one column of the side-by-side comparison: a domain's
+// latest metrics and the ones a refresh before, for the trend. PinID is nil
+// for the target.
+type DomainComparison struct {
+	Domain         string     `json:"domain"`
+	PinID          *uuid.UUID `json:"pinId"`
+	Latest         *Metrics   `json:"latest"`
+	Previous       *Metrics   `json:"previous"`
+	KeywordGaps    int64      `json:"keywordGaps"`
+	NewKeywordGaps int64      `json:"newKeywordGaps"`
+}
+
+// Comparison sets a target's metrics beside those of each pinned
+// competitor. The target comes first.
+type Comparison struct {
+	Target       string             `json:"target"`
+	LocationCode int                `json:"locationCode"`
+	LanguageCode string             `json:"languageCode"`
+	Domains      []DomainComparison `json:"domains"`
+}
+
+// RefreshSummary totals one run of the competitor refresh task.
+type RefreshSummary struct {
+	Refreshed int `json:"refreshed"`
+	Failed    int `json:"failed"`
+	Alerts    int `json:"alerts"`
+}
+
+func newPin(p query.CompetitorPin) Pin {
+	pin := Pin{
+		ID:            p.ID,
+		Target:        p.Target,
+		Domain:        p.Domain,
+		LocationCode:  p.LocationCode,
+		LanguageCode:  p.LanguageCode,
+		CreatedAt:     p.CreatedAt,
+		NextRefreshAt: p.NextRefreshAt,
+	}
+	if p.RefreshedAt.Valid {
+		pin.RefreshedAt = &p.RefreshedAt.Time
+	}
+	return pin
+}
+
+func newKeywordGap(g query.CompetitorKeywordGap) KeywordGap {
+	gap := KeywordGap{
+		Keyword:     g.Keyword,
+		Position:    g.Position,
+		URL:         g.Url,
+		New:         g.IsNew,
+		FirstSeenAt: g.FirstSeenAt,
+	}
+	if g.SearchVolume.Valid {
+		gap.SearchVolume = &g.SearchVolume.Int32
+	}
+	return gap
+}
+
+func newAlert(a query.CompetitorAlert) Alert {
+	alert := Alert{
+		ID:        a.ID,
+		PinID:     a.PinID,
+		Kind:      a.Kind,
+		Message:   a.Message,
+		Keywords:  a.Keywords,
+		Amount:    a.Amount,
+		CreatedAt: a.CreatedAt,
+	}
+	if alert.Keywords == nil {
+		alert.Keywords = []string{}
+	}
+	return alert
+}
+
+func newMetrics(s query.CompetitorSnapshot) *Metrics {
+	m := &Metrics{CapturedAt: s.CapturedAt}
+	if s.OrganicEtv.Valid {
+		m.OrganicETV = &s.OrganicEtv.Float64
+	}
+	if s.OrganicKeywords.Valid {
+		m.OrganicKeywords = &s.OrganicKeywords.Int32
+	}
+	if s.Top3Keywords.Valid {
+		m.Top3Keywords = &s.Top3Keywords.Int32
+	}
+	if s.Top10Keywords.Valid {
+		m.Top10Keywords = &s.Top10Keywords.Int32
+	}
+	if s.DomainRank.Valid {
+		m.DomainRank = &s.DomainRank.Int32
+	}
+	if s.Backlinks.Valid {
+		m.Backlinks = &s.Backlinks.Int64
+	}
+	if s.ReferringDomains.Valid {
+		m.ReferringDomains = &s.ReferringDomains.Int32
+	}
+	return m
+}
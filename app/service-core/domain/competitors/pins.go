@@ -0,0 +1,363 @@
+package competitors
+
+import (
+	"app/pkg"
+	"app/pkg/dataforseo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"service-core/domain/membership"
+	"service-core/domain/seo"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxPinsPerTarget is the most competitors pinned against one target in
+	// one market.
+	MaxPinsPerTarget = 10
+	// MaxSuggestions is the most competitor suggestions one call returns.
+	MaxSuggestions = 50
+	// MaxAlerts is the most alerts one call returns.
+	MaxAlerts = 100
+
+	defaultSuggestions = 20
+)
+
+// Pin starts monitoring competitor domains against a target; domains already
+// pinned are ignored. New pins are refreshed by the next run of the refresh
+// task. Only organisation owners and admins can pin competitors.
+func (s *Service) Pin(ctx context.Context, userID uuid.UUID, req PinRequest) ([]Pin, error) {
+	if s.labs == nil {
+		return nil, pkg.BadRequestError{Message: "Competitor monitoring is not configured"}
+	}
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+	market, err := normaliseMarket(req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	var domains []string
+	for _, raw := range req.Domains {
+		domain, err := seo.NormaliseDomain(raw)
+		if err != nil {
+			return nil, err
+		}
+		if domain == market.Target {
+			return nil, pkg.BadRequestError{Message: "A site can't be its own competitor"}
+		}
+		domains = append(domains, domain)
+	}
+	if len(domains) == 0 {
+		return nil, pkg.BadRequestError{Message: "At least one domain is required"}
+	}
+
+	arg := query.ListCompetitorPinsByTargetParams{
+		OrganisationID: market.OrganisationID,
+		Target:         market.Target,
+		LocationCode:   int32(market.LocationCode),
+		LanguageCode:   market.LanguageCode,
+	}
+	existing, err := s.store.ListCompetitorPinsByTarget(ctx, arg)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing competitors", Err: err}
+	}
+	pinned := make(map[string]bool, len(existing)+len(domains))
+	for _, pin := range existing {
+		pinned[pin.Domain] = true
+	}
+	var added []string
+	for _, domain := range domains {
+		if !pinned[domain] {
+			pinned[domain] = true
+			added = append(added, domain)
+		}
+	}
+	if len(existing)+len(added) > MaxPinsPerTarget {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("At most %d competitors can be pinned against a site", MaxPinsPerTarget)}
+	}
+
+	for _, domain := range added {
+		_, err := s.store.CreateCompetitorPin(ctx, query.CreateCompetitorPinParams{
+			OrganisationID: market.OrganisationID,
+			Target:         market.Target,
+			Domain:         domain,
+			LocationCode:   int32(market.LocationCode),
+			LanguageCode:   market.LanguageCode,
+			CreatedBy:      uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		// ErrNoRows: pinned by someone else in the meantime
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.InternalError{Message: "Error pinning competitor", Err: err}
+		}
+	}
+
+	rows, err := s.store.ListCompetitorPinsByTarget(ctx, arg)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing competitors", Err: err}
+	}
+	return s.withGapCounts(ctx, market.OrganisationID, rows)
+}
+
+// ListPins returns all of an organisation's pinned competitors, with their
+// keyword gap counts, to any of its members.
+func (s *Service) ListPins(ctx context.Context, userID, organisationID uuid.UUID) ([]Pin, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.store.ListCompetitorPinsByOrg(ctx, organisationID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing competitors", Err: err}
+	}
+	return s.withGapCounts(ctx, organisationID, rows)
+}
+
+// Unpin stops monitoring a competitor and drops its keyword gaps and alerts.
+// Its snapshots are kept, since they're shared with the target's history.
+// Only organisation owners and admins can unpin competitors.
+func (s *Service) Unpin(ctx context.Context, userID, organisationID, pinID uuid.UUID) error {
+	if err := membership.RequireAdmin(ctx, s.store, userID, organisationID); err != nil {
+		return err
+	}
+
+	deleted, err := s.store.DeleteCompetitorPin(ctx, query.DeleteCompetitorPinParams{ID: pinID, OrganisationID: organisationID})
+	if err != nil {
+		return pkg.InternalError{Message: "Error unpinning competitor", Err: err}
+	}
+	if deleted == 0 {
+		return pkg.NotFoundError{Message: "Competitor not found"}
+	}
+	return nil
+}
+
+// Suggest returns the domains that rank for most of the target's keywords,
+// most overlapping first, flagging those already pinned. Each call is billed,
+// so only organisation owners and admins can ask for suggestions.
+func (s *Service) Suggest(ctx context.Context, userID uuid.UUID, req SuggestRequest) ([]Suggestion, error) {
+	if s.labs == nil {
+		return nil, pkg.BadRequestError{Message: "Competitor monitoring is not configured"}
+	}
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+	market, err := normaliseMarket(req.Market)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSuggestions
+	}
+	if limit < 1 || limit > MaxSuggestions {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("limit must be between 1 and %d", MaxSuggestions)}
+	}
+
+	ctx = dataforseo.WithCostAttribution(ctx, market.OrganisationID.String())
+	// One extra, since the target itself is usually among the results
+	domains, err := s.labs.GetCompetitorDomains(ctx, market.Target, market.LocationCode, market.LanguageCode, dataforseo.LabsOptions{
+		Limit:   limit + 1,
+		OrderBy: []dataforseo.Order{dataforseo.Desc("intersections")},
+	})
+	if err != nil {
+		return nil, lookupError(err)
+	}
+
+	rows, err := s.store.ListCompetitorPinsByTarget(ctx, query.ListCompetitorPinsByTargetParams{
+		OrganisationID: market.OrganisationID,
+		Target:         market.Target,
+		LocationCode:   int32(market.LocationCode),
+		LanguageCode:   market.LanguageCode,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing competitors", Err: err}
+	}
+	pinned := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		pinned[row.Domain] = true
+	}
+
+	suggestions := make([]Suggestion, 0, limit)
+	for _, d := range domains {
+		domain := strings.TrimPrefix(strings.ToLower(d.Domain), "www.")
+		if domain == market.Target || len(suggestions) == limit {
+			continue
+		}
+		suggestion := Suggestion{
+			Domain:        domain,
+			Intersections: d.Intersections,
+			AvgPosition:   d.AvgPosition,
+			Pinned:        pinned[domain],
+		}
+		if organic := d.FullDomainMetrics["organic"]; organic != nil {
+			suggestion.OrganicETV = organic.ETV
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+// KeywordGaps returns the keywords a pinned competitor ranks in the top 10
+// for and the target doesn't rank for, biggest first, to any member of the
+// organisation.
+func (s *Service) KeywordGaps(ctx context.Context, userID, organisationID, pinID uuid.UUID, limit, offset int32) ([]KeywordGap, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.GetCompetitorPin(ctx, query.GetCompetitorPinParams{ID: pinID, OrganisationID: organisationID}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Competitor not found"}
+		}
+		return nil, pkg.InternalError{Message: "Error loading competitor", Err: err}
+	}
+	rows, err := s.store.ListCompetitorKeywordGaps(ctx, query.ListCompetitorKeywordGapsParams{PinID: pinID, Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing keyword gaps", Err: err}
+	}
+	gaps := make([]KeywordGap, len(rows))
+	for i, row := range rows {
+		gaps[i] = newKeywordGap(row)
+	}
+	return gaps, nil
+}
+
+// Alerts returns an organisation's latest competitor alerts, newest first,
+// to any of its members.
+func (s *Service) Alerts(ctx context.Context, userID, organisationID uuid.UUID, limit int) ([]Alert, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.store.ListCompetitorAlerts(ctx, query.ListCompetitorAlertsParams{OrganisationID: organisationID, Limit: int32(limit)})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing alerts", Err: err}
+	}
+	alerts := make([]Alert, len(rows))
+	for i, row := range rows {
+		alerts[i] = newAlert(row)
+	}
+	return alerts, nil
+}
+
+// Compare sets the target's latest metrics beside those of each competitor
+// pinned against it, with the metrics of the refresh before for trends. It's
+// available to any member of the organisation and doesn't call DataForSEO.
+func (s *Service) Compare(ctx context.Context, userID uuid.UUID, m Market) (*Comparison, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, m.OrganisationID); err != nil {
+		return nil, err
+	}
+	market, err := normaliseMarket(m)
+	if err != nil {
+		return nil, err
+	}
+
+	pins, err := s.store.ListCompetitorPinsByTarget(ctx, query.ListCompetitorPinsByTargetParams{
+		OrganisationID: market.OrganisationID,
+		Target:         market.Target,
+		LocationCode:   int32(market.LocationCode),
+		LanguageCode:   market.LanguageCode,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing competitors", Err: err}
+	}
+	snapshots, err := s.store.ListRecentCompetitorSnapshots(ctx, query.ListRecentCompetitorSnapshotsParams{
+		OrganisationID: market.OrganisationID,
+		Target:         market.Target,
+		LocationCode:   int32(market.LocationCode),
+		LanguageCode:   market.LanguageCode,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading metrics", Err: err}
+	}
+	counts, err := s.gapCounts(ctx, market.OrganisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first per domain
+	recent := map[string][]query.CompetitorSnapshot{}
+	for _, snap := range snapshots {
+		recent[snap.Domain] = append(recent[snap.Domain], snap)
+	}
+	column := func(domain string) DomainComparison {
+		c := DomainComparison{Domain: domain}
+		if snaps := recent[domain]; len(snaps) > 0 {
+			c.Latest = newMetrics(snaps[0])
+			if len(snaps) > 1 {
+				c.Previous = newMetrics(snaps[1])
+			}
+		}
+		return c
+	}
+
+	comparison := &Comparison{
+		Target:       market.Target,
+		LocationCode: market.LocationCode,
+		LanguageCode: market.LanguageCode,
+		Domains:      []DomainComparison{column(market.Target)},
+	}
+	for _, pin := range pins {
+		c := column(pin.Domain)
+		c.PinID = &pin.ID
+		c.KeywordGaps = counts[pin.ID].Gaps
+		c.NewKeywordGaps = counts[pin.ID].NewGaps
+		comparison.Domains = append(comparison.Domains, c)
+	}
+	return comparison, nil
+}
+
+func (s *Service) withGapCounts(ctx context.Context, organisationID uuid.UUID, rows []query.CompetitorPin) ([]Pin, error) {
+	counts, err := s.gapCounts(ctx, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	pins := make([]Pin, len(rows))
+	for i, row := range rows {
+		pins[i] = newPin(row)
+		pins[i].KeywordGaps = counts[row.ID].Gaps
+		pins[i].NewKeywordGaps = counts[row.ID].NewGaps
+	}
+	return pins, nil
+}
+
+func (s *Service) gapCounts(ctx context.Context, organisationID uuid.UUID) (map[uuid.UUID]query.CountCompetitorKeywordGapsRow, error) {
+	rows, err := s.store.CountCompetitorKeywordGaps(ctx, organisationID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting keyword gaps", Err: err}
+	}
+	counts := make(map[uuid.UUID]query.CountCompetitorKeywordGapsRow, len(rows))
+	for _, row := range rows {
+		counts[row.PinID] = row
+	}
+	return counts, nil
+}
+
+// lookupError maps a failed DataForSEO call to the error the caller sees.
+func lookupError(err error) error {
+	if errors.Is(err, dataforseo.ErrBudgetExceeded) {
+		return pkg.BadRequestError{Message: "The monthly SEO budget has been reached"}
+	}
+	return pkg.InternalError{Message: "Error looking up competitors", Err: err}
+}
+
+func normaliseMarket(m Market) (Market, error) {
+	target, err := seo.NormaliseDomain(m.Target)
+	if err != nil {
+		return Market{}, pkg.BadRequestError{Message: "target must be a domain name such as example.com"}
+	}
+	if m.LocationCode <= 0 {
+		return Market{}, pkg.BadRequestError{Message: "locationCode is required"}
+	}
+	if m.LanguageCode == "" {
+		return Market{}, pkg.BadRequestError{Message: "languageCode is required"}
+	}
+	m.Target = target
+	return m, nil
+}
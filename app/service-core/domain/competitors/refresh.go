@@ -0,0 +1,219 @@
+package competitors
+
+import (
+	"app/pkg/dataforseo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"service-core/storage/query"
+)
+
+const (
+	// maxGaps is the most keyword gaps kept per competitor, by search volume.
+	maxGaps = 200
+	// A competitor's referring domains must grow by both of these since the
+	// last refresh to raise a backlink alert.
+	minBacklinkGain        = 10
+	minBacklinkGainPercent = 10
+)
+
+// RefreshDue refreshes the metrics and keyword gaps of the pins due their
+// weekly refresh, and of the targets they're pinned against, raising alerts
+// for new keyword gaps and backlink gains. A pin that fails is retried at
+// its next weekly refresh. The run stops when the monthly SEO budget runs
+// out.
+func (s *Service) RefreshDue(ctx context.Context) (*RefreshSummary, error) {
+	summary := &RefreshSummary{}
+	if s.labs == nil {
+		return summary, nil
+	}
+
+	due, err := s.store.ListDueCompetitorPins(ctx, dueBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("listing due competitor pins: %w", err)
+	}
+	captured := map[string]bool{}
+	for _, pin := range due {
+		claimed, err := s.store.ClaimCompetitorPin(ctx, query.ClaimCompetitorPinParams{ID: pin.ID, NextRefreshAt: time.Now().Add(refreshInterval)})
+		if err != nil {
+			slog.Error("Failed to claim competitor pin", "pinID", pin.ID, "error", err)
+			continue
+		}
+		if claimed == 0 {
+			continue // another replica got to it first
+		}
+
+		pinCtx := dataforseo.WithCostAttribution(ctx, pin.OrganisationID.String())
+		// The target once per market, for the comparison
+		market := fmt.Sprintf("%s|%s|%d|%s", pin.OrganisationID, pin.Target, pin.LocationCode, pin.LanguageCode)
+		if !captured[market] {
+			captured[market] = true
+			if _, err := s.captureSnapshot(pinCtx, pin, pin.Target); err != nil {
+				slog.Warn("Failed to snapshot competitor target", "pinID", pin.ID, "target", pin.Target, "error", err)
+			}
+		}
+
+		alerts, err := s.refreshPin(pinCtx, pin)
+		summary.Alerts += alerts
+		if err != nil {
+			slog.Error("Failed to refresh competitor", "pinID", pin.ID, "domain", pin.Domain, "error", err)
+			summary.Failed++
+			if errors.Is(err, dataforseo.ErrBudgetExceeded) {
+				break // the rest would fail the same way
+			}
+			continue
+		}
+		summary.Refreshed++
+	}
+	return summary, nil
+}
+
+// refreshPin snapshots a competitor's metrics and replaces its keyword gaps,
+// returning the number of alerts raised. Gaps found on a pin's first refresh
+// aren't new, so pinning a competitor doesn't raise an alert for all of them.
+func (s *Service) refreshPin(ctx context.Context, pin query.CompetitorPin) (int, error) {
+	previous, err := s.store.GetLatestCompetitorSnapshot(ctx, query.GetLatestCompetitorSnapshotParams{
+		OrganisationID: pin.OrganisationID,
+		Target:         pin.Target,
+		LocationCode:   pin.LocationCode,
+		LanguageCode:   pin.LanguageCode,
+		Domain:         pin.Domain,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("loading previous snapshot: %w", err)
+	}
+	snapshot, err := s.captureSnapshot(ctx, pin, pin.Domain)
+	if err != nil {
+		return 0, err
+	}
+
+	alerts := 0
+	if previous.ReferringDomains.Valid && snapshot.ReferringDomains.Valid {
+		before, after := previous.ReferringDomains.Int32, snapshot.ReferringDomains.Int32
+		gain := after - before
+		if gain >= minBacklinkGain && gain*100 >= before*minBacklinkGainPercent {
+			message := fmt.Sprintf("%s gained %d referring domains since the last refresh (%d to %d)", pin.Domain, gain, before, after)
+			if s.raiseAlert(ctx, pin, AlertBacklinkGain, message, []string{}, gain) {
+				alerts++
+			}
+		}
+	}
+
+	started := time.Now()
+	gaps, err := s.labs.GetMissingKeywords(ctx, pin.Target, pin.Domain, int(pin.LocationCode), pin.LanguageCode, dataforseo.LabsOptions{
+		Limit:   maxGaps,
+		Filter:  dataforseo.Where("first_domain_serp_element.rank_group", dataforseo.OpLessEq, 10),
+		OrderBy: []dataforseo.Order{dataforseo.Desc("keyword_data.keyword_info.search_volume")},
+	})
+	if err != nil {
+		return alerts, fmt.Errorf("looking up keyword gaps: %w", err)
+	}
+
+	arg := query.UpsertCompetitorKeywordGapsParams{PinID: pin.ID, IsNew: pin.RefreshedAt.Valid}
+	seen := make(map[string]bool, len(gaps))
+	for _, gap := range gaps {
+		if gap.KeywordData == nil || gap.FirstDomainSERPElement == nil {
+			continue
+		}
+		keyword := strings.ToLower(strings.TrimSpace(gap.KeywordData.Keyword))
+		if keyword == "" || seen[keyword] {
+			continue
+		}
+		seen[keyword] = true
+		volume := int32(-1) // no data
+		if v := gap.KeywordData.KeywordInfo.SearchVolume; v != nil {
+			volume = int32(*v)
+		}
+		arg.Keywords = append(arg.Keywords, keyword)
+		arg.Volumes = append(arg.Volumes, volume)
+		arg.Positions = append(arg.Positions, int32(gap.FirstDomainSERPElement.RankGroup))
+		arg.Urls = append(arg.Urls, gap.FirstDomainSERPElement.URL)
+	}
+	var added []string
+	if len(arg.Keywords) > 0 {
+		rows, err := s.store.UpsertCompetitorKeywordGaps(ctx, arg)
+		if err != nil {
+			return alerts, fmt.Errorf("saving keyword gaps: %w", err)
+		}
+		for _, row := range rows {
+			if row.IsNew {
+				added = append(added, row.Keyword)
+			}
+		}
+	}
+	if _, err := s.store.DeleteStaleCompetitorKeywordGaps(ctx, query.DeleteStaleCompetitorKeywordGapsParams{PinID: pin.ID, LastSeenAt: started}); err != nil {
+		return alerts, fmt.Errorf("deleting stale keyword gaps: %w", err)
+	}
+	if len(added) > 0 {
+		message := fmt.Sprintf("%s started ranking in the top 10 for %d keywords %s doesn't rank for", pin.Domain, len(added), pin.Target)
+		if s.raiseAlert(ctx, pin, AlertKeywordGap, message, added, int32(len(added))) {
+			alerts++
+		}
+	}
+
+	if err := s.store.MarkCompetitorPinRefreshed(ctx, pin.ID); err != nil {
+		return alerts, fmt.Errorf("marking pin refreshed: %w", err)
+	}
+	return alerts, nil
+}
+
+// captureSnapshot records a domain's organic metrics in the pin's market and
+// its backlink metrics. Organic metrics are left empty when DataForSEO has no
+// data for the domain.
+func (s *Service) captureSnapshot(ctx context.Context, pin query.CompetitorPin, domain string) (query.CreateCompetitorSnapshotParams, error) {
+	arg := query.CreateCompetitorSnapshotParams{
+		OrganisationID: pin.OrganisationID,
+		Target:         pin.Target,
+		Domain:         domain,
+		LocationCode:   pin.LocationCode,
+		LanguageCode:   pin.LanguageCode,
+	}
+
+	overview, err := s.labs.GetDomainRankOverview(ctx, domain, int(pin.LocationCode), pin.LanguageCode)
+	if err != nil {
+		return arg, fmt.Errorf("looking up domain overview: %w", err)
+	}
+	if overview != nil && overview.Metrics.Organic != nil {
+		organic := overview.Metrics.Organic
+		top3 := organic.Pos1 + organic.Pos2_3
+		arg.OrganicEtv = sql.NullFloat64{Float64: organic.ETV, Valid: true}
+		arg.OrganicKeywords = sql.NullInt32{Int32: int32(organic.Count), Valid: true}
+		arg.Top3Keywords = sql.NullInt32{Int32: int32(top3), Valid: true}
+		arg.Top10Keywords = sql.NullInt32{Int32: int32(top3 + organic.Pos4_10), Valid: true}
+	}
+
+	if s.backlinks != nil {
+		summary, err := s.backlinks.GetBacklinksSummary(ctx, domain)
+		if err != nil {
+			return arg, fmt.Errorf("looking up backlinks summary: %w", err)
+		}
+		arg.DomainRank = sql.NullInt32{Int32: int32(summary.Rank), Valid: true}
+		arg.Backlinks = sql.NullInt64{Int64: summary.Backlinks, Valid: true}
+		arg.ReferringDomains = sql.NullInt32{Int32: int32(summary.ReferringDomains), Valid: true}
+	}
+
+	if err := s.store.CreateCompetitorSnapshot(ctx, arg); err != nil {
+		return arg, fmt.Errorf("saving snapshot: %w", err)
+	}
+	return arg, nil
+}
+
+func (s *Service) raiseAlert(ctx context.Context, pin query.CompetitorPin, kind, message string, keywords []string, amount int32) bool {
+	if err := s.store.CreateCompetitorAlert(ctx, query.CreateCompetitorAlertParams{
+		OrganisationID: pin.OrganisationID,
+		PinID:          pin.ID,
+		Kind:           kind,
+		Message:        message,
+		Keywords:       keywords,
+		Amount:         amount,
+	}); err != nil {
+		slog.Error("Failed to save competitor alert", "pinID", pin.ID, "kind", kind, "error", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package competitors
+
+import (
+	"app/pkg/dataforseo"
+	"app/pkg/httprecord"
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"service-core/config"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	refreshInterval = 7 * 24 * time.Hour
+	dueBatchSize    = 10
+)
+
+type store interface {
+	GetOrgMembershipRole(ctx context.Context, arg query.GetOrgMembershipRoleParams) (string, error)
+	CreateCompetitorPin(ctx context.Context, arg query.CreateCompetitorPinParams) (query.CompetitorPin, error)
+	ListCompetitorPinsByOrg(ctx context.Context, organisationID uuid.UUID) ([]query.CompetitorPin, error)
+	ListCompetitorPinsByTarget(ctx context.Context, arg query.ListCompetitorPinsByTargetParams) ([]query.CompetitorPin, error)
+	GetCompetitorPin(ctx context.Context, arg query.GetCompetitorPinParams) (query.CompetitorPin, error)
+	DeleteCompetitorPin(ctx context.Context, arg query.DeleteCompetitorPinParams) (int64, error)
+	ListDueCompetitorPins(ctx context.Context, limit int32) ([]query.CompetitorPin, error)
+	ClaimCompetitorPin(ctx context.Context, arg query.ClaimCompetitorPinParams) (int64, error)
+	MarkCompetitorPinRefreshed(ctx context.Context, id uuid.UUID) error
+	CreateCompetitorSnapshot(ctx context.Context, arg query.CreateCompetitorSnapshotParams) error
+	GetLatestCompetitorSnapshot(ctx context.Context, arg query.GetLatestCompetitorSnapshotParams) (query.CompetitorSnapshot, error)
+	ListRecentCompetitorSnapshots(ctx context.Context, arg query.ListRecentCompetitorSnapshotsParams) ([]query.CompetitorSnapshot, error)
+	UpsertCompetitorKeywordGaps(ctx context.Context, arg query.UpsertCompetitorKeywordGapsParams) ([]query.UpsertCompetitorKeywordGapsRow, error)
+	DeleteStaleCompetitorKeywordGaps(ctx context.Context, arg query.DeleteStaleCompetitorKeywordGapsParams) (int64, error)
+	ListCompetitorKeywordGaps(ctx context.Context, arg query.ListCompetitorKeywordGapsParams) ([]query.CompetitorKeywordGap, error)
+	CountCompetitorKeywordGaps(ctx context.Context, organisationID uuid.UUID) ([]query.CountCompetitorKeywordGapsRow, error)
+	CreateCompetitorAlert(ctx context.Context, arg query.CreateCompetitorAlertParams) error
+	ListCompetitorAlerts(ctx context.Context, arg query.ListCompetitorAlertsParams) ([]query.CompetitorAlert, error)
+}
+
+type labsClient interface {
+	GetCompetitorDomains(ctx context.Context, target string, locationCode int, languageCode string, opts dataforseo.LabsOptions) ([]dataforseo.CompetitorDomain, error)
+	GetMissingKeywords(ctx context.Context, target, competitor string, locationCode int, languageCode string, opts dataforseo.LabsOptions) ([]dataforseo.KeywordGap, error)
+	GetDomainRankOverview(ctx context.Context, target string, locationCode int, languageCode string) (*dataforseo.DomainRankOverview, error)
+}
+
+type backlinksClient interface {
+	GetBacklinksSummary(ctx context.Context, target string) (*dataforseo.BacklinksSummary, error)
+}
+
+type Service struct {
+	store     store
+	labs      labsClient      // nil when DataForSEO isn't configured
+	backlinks backlinksClient // nil when DataForSEO isn't configured
+}
+
+func NewService(cfg *config.Config, store store) *Service {
+	s := &Service{store: store}
+	if cfg.DataForSEOLogin == "" {
+		return s
+	}
+	opts := []dataforseo.Option{dataforseo.WithTransport(httprecord.Transport("dataforseo", nil))}
+	if cfg.DataForSEOMonthlyBudget != "" {
+		budget, err := strconv.ParseFloat(cfg.DataForSEOMonthlyBudget, 64)
+		if err != nil {
+			slog.Warn("Ignoring DataForSEO monthly budget", "error", err)
+		} else {
+			opts = append(opts, dataforseo.WithBudget(budget))
+		}
+	}
+	client := dataforseo.NewClient(cfg.DataForSEOLogin, cfg.DataForSEOPassword, opts...)
+	s.labs = client
+	s.backlinks = client
+	return s
+}
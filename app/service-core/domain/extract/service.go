@@ -0,0 +1,207 @@
+// Package extract converts web pages to markdown on demand, caching results
+// by URL so repeated requests don't refetch the page.
+package extract
+
+import (
+	"app/pkg"
+	"app/pkg/cfbrowser"
+	"app/pkg/contentfetch"
+	"app/pkg/jina"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"service-core/config"
+	"service-core/storage/query"
+)
+
+const (
+	// cacheTTL is how long an extraction is served from the cache
+	cacheTTL = 24 * time.Hour
+	// stepTimeout bounds each provider, so one slow renderer can't hold the
+	// request past the next provider's turn
+	stepTimeout = 20 * time.Second
+	// MaxURLLength is the longest URL that can be extracted.
+	MaxURLLength = 2048
+)
+
+// Cache statuses
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+type store interface {
+	GetContentExtractCache(ctx context.Context, url string) (query.ContentExtractCache, error)
+	UpsertContentExtractCache(ctx context.Context, arg query.UpsertContentExtractCacheParams) error
+	DeleteExpiredContentExtractCache(ctx context.Context) error
+}
+
+// fetcher converts a URL to markdown; satisfied by *contentfetch.Chain.
+type fetcher interface {
+	Fetch(ctx context.Context, targetURL string) (*contentfetch.Result, error)
+}
+
+type Service struct {
+	store store
+	fetch fetcher
+}
+
+// NewService builds the provider chain: the browser renderer when there is
+// one, then Jina Reader, then a plain HTTP fetch. A local Chrome renderer
+// loads pages from inside our network, so it must only be given with a URL
+// check (see cfbrowser.WithChromeURLCheck).
+func NewService(cfg *config.Config, store store, renderer cfbrowser.Renderer) *Service {
+	var steps []contentfetch.Step
+	if renderer != nil {
+		steps = append(steps, contentfetch.Step{
+			Provider: contentfetch.Browser(renderer, cfbrowser.PageOptions{}),
+			Timeout:  stepTimeout,
+		})
+	}
+	var jinaOpts []jina.Option
+	if cfg.JinaAPIKey != "" {
+		jinaOpts = append(jinaOpts, jina.WithAPIKey(cfg.JinaAPIKey))
+	}
+	steps = append(steps,
+		contentfetch.Step{Provider: contentfetch.Jina(jina.NewClient(jinaOpts...)), Timeout: stepTimeout},
+		contentfetch.Step{Provider: contentfetch.HTTP(contentfetch.PublicClient()), Timeout: stepTimeout},
+	)
+	return &Service{store: store, fetch: contentfetch.NewChain(steps...)}
+}
+
+// Extraction is a page converted to markdown, with the links it contains.
+type Extraction struct {
+	URL       string              `json:"url"`
+	FinalURL  string              `json:"finalUrl"`
+	Title     string              `json:"title"`
+	Markdown  string              `json:"markdown"`
+	Links     []contentfetch.Link `json:"links"`
+	Provider  string              `json:"provider"`
+	Cache     string              `json:"cache"`
+	FetchedAt time.Time           `json:"fetchedAt"`
+}
+
+// Extract returns rawURL as markdown, from the cache when it was extracted
+// in the last day unless refresh is set. URLs whose host resolves to a
+// private address are refused.
+func (s *Service) Extract(ctx context.Context, rawURL string, refresh bool) (*Extraction, error) {
+	target, err := normalizeURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := contentfetch.CheckPublic(ctx, target); err != nil {
+		if errors.Is(err, contentfetch.ErrPrivateAddress) {
+			return nil, pkg.BadRequestError{Message: "URL must be on the public internet", Err: err}
+		}
+		return nil, pkg.BadRequestError{Message: "URL's host can't be resolved", Err: err}
+	}
+
+	if !refresh {
+		cached, err := s.store.GetContentExtractCache(ctx, target)
+		switch {
+		case err == nil:
+			return fromCache(cached), nil
+		case !errors.Is(err, sql.ErrNoRows):
+			// A cache miss is only slower; carry on and fetch the page
+			slog.Warn("Failed to read content extract cache", "url", target, "error", err)
+		}
+	}
+
+	result, err := s.fetch.Fetch(ctx, target)
+	if err != nil {
+		var fetchErr *contentfetch.FetchError
+		if errors.As(err, &fetchErr) {
+			return nil, pkg.BadRequestError{Message: "The page couldn't be read", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error extracting page", Err: err}
+	}
+
+	extraction := &Extraction{
+		URL:       target,
+		FinalURL:  result.URL,
+		Title:     result.Title,
+		Markdown:  result.Markdown,
+		Links:     contentfetch.Links(result.Markdown),
+		Provider:  result.Provider,
+		Cache:     CacheMiss,
+		FetchedAt: time.Now().UTC(),
+	}
+	if extraction.FinalURL == "" {
+		extraction.FinalURL = target
+	}
+	s.save(ctx, extraction)
+	return extraction, nil
+}
+
+// save caches an extraction and prunes expired ones. Failures are logged, as
+// the extraction itself succeeded.
+func (s *Service) save(ctx context.Context, e *Extraction) {
+	links, err := json.Marshal(e.Links)
+	if err != nil {
+		slog.Warn("Failed to encode extracted links", "url", e.URL, "error", err)
+		return
+	}
+	err = s.store.UpsertContentExtractCache(ctx, query.UpsertContentExtractCacheParams{
+		Url:       e.URL,
+		FinalUrl:  e.FinalURL,
+		Title:     e.Title,
+		Markdown:  e.Markdown,
+		Links:     links,
+		Provider:  e.Provider,
+		ExpiresAt: e.FetchedAt.Add(cacheTTL),
+	})
+	if err != nil {
+		slog.Warn("Failed to cache content extraction", "url", e.URL, "error", err)
+		return
+	}
+	if err := s.store.DeleteExpiredContentExtractCache(ctx); err != nil {
+		slog.Warn("Failed to prune content extract cache", "error", err)
+	}
+}
+
+func fromCache(c query.ContentExtractCache) *Extraction {
+	var links []contentfetch.Link
+	if err := json.Unmarshal(c.Links, &links); err != nil {
+		slog.Warn("Failed to decode cached links", "url", c.Url, "error", err)
+	}
+	if links == nil {
+		links = []contentfetch.Link{}
+	}
+	return &Extraction{
+		URL:       c.Url,
+		FinalURL:  c.FinalUrl,
+		Title:     c.Title,
+		Markdown:  c.Markdown,
+		Links:     links,
+		Provider:  c.Provider,
+		Cache:     CacheHit,
+		FetchedAt: c.FetchedAt,
+	}
+}
+
+// normalizeURL checks rawURL is an absolute http(s) URL and returns it in the
+// form used as the cache key: lowercase scheme and host, no fragment.
+func normalizeURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", pkg.BadRequestError{Message: "url is required"}
+	}
+	if len(rawURL) > MaxURLLength {
+		return "", pkg.BadRequestError{Message: "url is too long"}
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return "", pkg.BadRequestError{Message: "url must be an absolute http or https URL"}
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String(), nil
+}
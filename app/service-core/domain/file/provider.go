@@ -2,6 +2,7 @@ package file
 
 import (
 	"context"
+	"io"
 	"service-core/config"
 )
 
@@ -13,8 +14,16 @@ type File struct {
 
 type Provider interface {
 	Upload(ctx context.Context, file *File) error
+	// UploadStream stores size bytes read from body without holding them in
+	// memory. size is -1 when unknown.
+	UploadStream(ctx context.Context, key, contentType string, body io.Reader, size int64) error
 	Download(ctx context.Context, fileKey string) ([]byte, error)
+	// DownloadStream opens a file for reading from offset, fetching only the
+	// bytes from there on, with how many there are (-1 when the provider
+	// doesn't report it). The caller closes it.
+	DownloadStream(ctx context.Context, fileKey string, offset int64) (io.ReadCloser, int64, error)
 	Remove(ctx context.Context, fileKey string) error
+	// ListByPrefix returns the keys under prefix, relative to it
 	ListByPrefix(ctx context.Context, prefix string) ([]string, error)
 }
 
@@ -77,6 +77,26 @@ func (p *azblobProvider) Upload(ctx context.Context, file *File) error {
 	return nil
 }
 
+func (p *azblobProvider) UploadStream(ctx context.Context, key, contentType string, body io.Reader, _ int64) error {
+	client, err := p.getClient(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting Azure Blob client for upload: %w", err)
+	}
+
+	uploadOptions := &azblob.UploadStreamOptions{
+		HTTPHeaders: &blob.HTTPHeaders{
+			BlobContentType: &contentType,
+		},
+	}
+
+	_, err = client.UploadStream(ctx, p.cfg.BucketName, key, body, uploadOptions)
+	if err != nil {
+		return fmt.Errorf("error uploading stream to Azure Blob: %w", err)
+	}
+
+	return nil
+}
+
 func (p *azblobProvider) Download(ctx context.Context, fileKey string) ([]byte, error) {
 	client, err := p.getClient(ctx)
 	if err != nil {
@@ -100,6 +120,26 @@ func (p *azblobProvider) Download(ctx context.Context, fileKey string) ([]byte,
 	return downloadedData.Bytes(), nil
 }
 
+func (p *azblobProvider) DownloadStream(ctx context.Context, fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	client, err := p.getClient(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error getting Azure Blob client for download: %w", err)
+	}
+
+	get, err := client.DownloadStream(ctx, p.cfg.BucketName, fileKey, &azblob.DownloadStreamOptions{
+		Range: azblob.HTTPRange{Offset: offset},
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("error initiating download stream from Azure Blob for %s: %w", fileKey, err)
+	}
+
+	size := int64(-1)
+	if get.ContentLength != nil {
+		size = *get.ContentLength
+	}
+	return get.Body, size, nil
+}
+
 func (p *azblobProvider) Remove(ctx context.Context, fileKey string) error {
 	client, err := p.getClient(ctx)
 	if err != nil {
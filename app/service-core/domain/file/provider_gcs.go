@@ -50,16 +50,20 @@ func (p *gcsProvider) getClient(ctx context.Context) (*storage.Client, error) {
 }
 
 func (p *gcsProvider) Upload(ctx context.Context, file *File) error {
+	return p.UploadStream(ctx, file.Key, file.ContentType, bytes.NewReader(file.Data), int64(len(file.Data)))
+}
+
+func (p *gcsProvider) UploadStream(ctx context.Context, key, contentType string, body io.Reader, _ int64) error {
 	client, err := p.getClient(ctx)
 	if err != nil {
 		return fmt.Errorf("error getting GCS client for upload: %w", err)
 	}
 
-	obj := client.Bucket(p.cfg.BucketName).Object(file.Key)
+	obj := client.Bucket(p.cfg.BucketName).Object(key)
 	writer := obj.NewWriter(ctx)
-	writer.ContentType = file.ContentType // Set content type if available
+	writer.ContentType = contentType // Set content type if available
 
-	_, err = io.Copy(writer, bytes.NewReader(file.Data))
+	_, err = io.Copy(writer, body)
 	if err != nil {
 		_ = writer.Close()
 		return fmt.Errorf("error writing data to GCS object: %w", err)
@@ -94,6 +98,19 @@ func (p *gcsProvider) Download(ctx context.Context, fileKey string) ([]byte, err
 	return buf.Bytes(), nil
 }
 
+func (p *gcsProvider) DownloadStream(ctx context.Context, fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	client, err := p.getClient(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error getting GCS client for download: %w", err)
+	}
+
+	reader, err := client.Bucket(p.cfg.BucketName).Object(fileKey).NewRangeReader(ctx, offset, -1)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating GCS reader for object %s: %w", fileKey, err)
+	}
+	return reader, reader.Remain(), nil
+}
+
 func (p *gcsProvider) Remove(ctx context.Context, fileKey string) error {
 	client, err := p.getClient(ctx)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"service-core/config"
 	"strings"
@@ -19,18 +20,22 @@ func newLocalProvider(cfg *config.Config) *localProvider {
 	}
 }
 
-func (p *localProvider) Upload(_ context.Context, file *File) error {
+func (p *localProvider) Upload(ctx context.Context, file *File) error {
+	return p.UploadStream(ctx, file.Key, file.ContentType, bytes.NewReader(file.Data), int64(len(file.Data)))
+}
+
+func (p *localProvider) UploadStream(_ context.Context, key, _ string, body io.Reader, _ int64) error {
 	err := os.MkdirAll(p.cfg.LocalFileDir, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("error creating directory, %w", err)
 	}
-	fileLocation := strings.ReplaceAll(file.Key, "/", "_")
+	fileLocation := strings.ReplaceAll(key, "/", "_")
 	f, err := os.Create(fmt.Sprintf("%s/%s", p.cfg.LocalFileDir, fileLocation))
 	if err != nil {
 		return fmt.Errorf("error creating file, %w", err)
 	}
 	defer f.Close()
-	_, err = f.Write(file.Data)
+	_, err = io.Copy(f, body)
 	if err != nil {
 		return fmt.Errorf("error writing to file, %w", err)
 	}
@@ -52,6 +57,24 @@ func (p *localProvider) Download(_ context.Context, fileKey string) ([]byte, err
 	return buf.Bytes(), nil
 }
 
+func (p *localProvider) DownloadStream(_ context.Context, fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	fileLocation := strings.ReplaceAll(fileKey, "/", "_")
+	f, err := os.Open(fmt.Sprintf("%s/%s", p.cfg.LocalFileDir, fileLocation))
+	if err != nil {
+		return nil, 0, fmt.Errorf("error opening file, %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("error reading file, %w", err)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("error reading file, %w", err)
+	}
+	return f, max(info.Size()-offset, 0), nil
+}
+
 func (p *localProvider) Remove(_ context.Context, fileID string) error {
 	fileLocation := strings.ReplaceAll(fileID, "/", "_")
 	err := os.Remove(fmt.Sprintf("%s/%s", p.cfg.LocalFileDir, fileLocation))
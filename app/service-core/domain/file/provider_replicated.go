@@ -0,0 +1,310 @@
+package file
+
+import (
+	"app/pkg/jobs"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"service-core/config"
+	"slices"
+	"sync"
+	"time"
+)
+
+// jobMirrorFile copies an uploaded file to the secondary, or removes a removed
+// one from it. Its payload is a mirrorOp.
+const jobMirrorFile = "files.mirror"
+
+const (
+	// failoverThreshold is how many reads in a row the primary must fail, and
+	// the secondary serve, before reads go to the secondary first
+	failoverThreshold = 5
+	// failoverCooldown is how long reads go to the secondary first before the
+	// primary is tried again
+	failoverCooldown = time.Minute
+	// maxReconcileChanges caps the copies and removals of one Reconcile run;
+	// the next run carries on
+	maxReconcileChanges = 1000
+)
+
+// replicableProviders can be replicated: reconciling needs ListByPrefix
+var replicableProviders = []string{"r2", "s3"}
+
+// ReplicaConfig returns the configuration of the secondary bucket set with
+// FILE_REPLICA_PROVIDER, or nil when replication is off. The secondary uses
+// the provider's usual credentials, with its own bucket and optionally its own
+// S3 region or R2 endpoint.
+func ReplicaConfig(cfg *config.Config) *config.Config {
+	if cfg.FileReplicaProvider == "" {
+		return nil
+	}
+	if !slices.Contains(replicableProviders, cfg.FileProvider) || !slices.Contains(replicableProviders, cfg.FileReplicaProvider) {
+		panic("File replication needs r2 or s3 for both FILE_PROVIDER and FILE_REPLICA_PROVIDER")
+	}
+	replica := *cfg
+	replica.FileProvider = cfg.FileReplicaProvider
+	replica.BucketName = cfg.FileReplicaBucketName
+	if cfg.FileReplicaRegion != "" {
+		replica.S3Region = cfg.FileReplicaRegion
+	}
+	if cfg.FileReplicaEndpoint != "" {
+		replica.R2Endpoint = cfg.FileReplicaEndpoint
+	}
+	return &replica
+}
+
+// mirrorOp is a change to copy to the secondary
+type mirrorOp struct {
+	Key    string `json:"key"`
+	Remove bool   `json:"remove"`
+}
+
+// ReplicatedProvider writes to a primary provider and mirrors every change to
+// a secondary one in the background. Reads fall back to the secondary when
+// the primary fails, so a storage incident in one region doesn't take down
+// the files already stored. Writes need the primary.
+type ReplicatedProvider struct {
+	primary   Provider
+	secondary Provider
+	jobs      *jobs.Queue // nil mirrors in a goroutine, without retries
+
+	mu         sync.Mutex
+	failures   int       // reads in a row the primary failed and the secondary served
+	failedOver time.Time // when reads last went to the secondary first
+}
+
+// NewReplicatedProvider wraps primary and secondary and registers the mirror
+// job with queue, which may be nil.
+func NewReplicatedProvider(primary, secondary Provider, queue *jobs.Queue) *ReplicatedProvider {
+	p := &ReplicatedProvider{
+		primary:   primary,
+		secondary: secondary,
+		jobs:      queue,
+	}
+	if queue != nil {
+		queue.Register(jobMirrorFile, p.runMirror, jobs.MaxAttempts(8))
+	}
+	return p
+}
+
+// mirror queues a change for the secondary. A change that's lost is put
+// right by the next Reconcile.
+func (p *ReplicatedProvider) mirror(ctx context.Context, op mirrorOp) {
+	if p.jobs == nil {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+			defer cancel()
+			if err := p.applyMirror(ctx, op); err != nil {
+				slog.Warn("Failed to mirror file", "key", op.Key, "remove", op.Remove, "error", err)
+			}
+		}()
+		return
+	}
+	if _, err := p.jobs.Enqueue(ctx, jobMirrorFile, op); err != nil {
+		slog.Warn("Failed to queue file mirror", "key", op.Key, "remove", op.Remove, "error", err)
+	}
+}
+
+func (p *ReplicatedProvider) runMirror(ctx context.Context, payload json.RawMessage) error {
+	var op mirrorOp
+	if err := json.Unmarshal(payload, &op); err != nil {
+		return fmt.Errorf("decoding file mirror job: %w", err)
+	}
+	return p.applyMirror(ctx, op)
+}
+
+func (p *ReplicatedProvider) applyMirror(ctx context.Context, op mirrorOp) error {
+	if op.Remove {
+		return p.secondary.Remove(ctx, op.Key)
+	}
+	return p.copy(ctx, op.Key)
+}
+
+// copy streams a file from the primary to the secondary
+func (p *ReplicatedProvider) copy(ctx context.Context, key string) error {
+	body, size, err := p.primary.DownloadStream(ctx, key, 0)
+	if err != nil {
+		return fmt.Errorf("reading %s from primary: %w", key, err)
+	}
+	defer body.Close()
+
+	r := bufio.NewReader(body)
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		head, _ := r.Peek(512)
+		contentType = http.DetectContentType(head)
+	}
+	return p.secondary.UploadStream(ctx, key, contentType, r, size)
+}
+
+func (p *ReplicatedProvider) Upload(ctx context.Context, file *File) error {
+	if err := p.primary.Upload(ctx, file); err != nil {
+		return err
+	}
+	p.mirror(ctx, mirrorOp{Key: file.Key})
+	return nil
+}
+
+func (p *ReplicatedProvider) UploadStream(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	if err := p.primary.UploadStream(ctx, key, contentType, body, size); err != nil {
+		return err
+	}
+	p.mirror(ctx, mirrorOp{Key: key})
+	return nil
+}
+
+func (p *ReplicatedProvider) Remove(ctx context.Context, fileKey string) error {
+	if err := p.primary.Remove(ctx, fileKey); err != nil {
+		return err
+	}
+	p.mirror(ctx, mirrorOp{Key: fileKey, Remove: true})
+	return nil
+}
+
+func (p *ReplicatedProvider) Download(ctx context.Context, fileKey string) ([]byte, error) {
+	return read(p, func(provider Provider) ([]byte, error) {
+		return provider.Download(ctx, fileKey)
+	})
+}
+
+// stream is an open file and its size, read as one value
+type stream struct {
+	body io.ReadCloser
+	size int64
+}
+
+func (p *ReplicatedProvider) DownloadStream(ctx context.Context, fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	s, err := read(p, func(provider Provider) (stream, error) {
+		body, size, err := provider.DownloadStream(ctx, fileKey, offset)
+		return stream{body: body, size: size}, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.body, s.size, nil
+}
+
+func (p *ReplicatedProvider) ListByPrefix(ctx context.Context, prefix string) ([]string, error) {
+	return read(p, func(provider Provider) ([]string, error) {
+		return provider.ListByPrefix(ctx, prefix)
+	})
+}
+
+// read reads from the primary, falling back to the secondary. While failed
+// over it reads from the secondary first.
+func read[T any](p *ReplicatedProvider, fn func(Provider) (T, error)) (T, error) {
+	if p.failingOver() {
+		if v, err := fn(p.secondary); err == nil {
+			return v, nil
+		}
+		return fn(p.primary)
+	}
+
+	v, primaryErr := fn(p.primary)
+	if primaryErr == nil {
+		p.recordPrimary(true)
+		return v, nil
+	}
+	v, err := fn(p.secondary)
+	if err != nil {
+		// Most likely the file doesn't exist; that says nothing about the primary
+		return v, primaryErr
+	}
+	slog.Warn("Primary file storage failed; served from secondary", "error", primaryErr)
+	p.recordPrimary(false)
+	return v, nil
+}
+
+func (p *ReplicatedProvider) failingOver() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Since(p.failedOver) < failoverCooldown
+}
+
+// recordPrimary tracks whether the primary served a read, failing over after
+// failoverThreshold failures in a row.
+func (p *ReplicatedProvider) recordPrimary(ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ok {
+		p.failures = 0
+		return
+	}
+	p.failures++
+	if p.failures >= failoverThreshold {
+		p.failures = 0
+		p.failedOver = time.Now()
+		slog.Error("Primary file storage is failing; reading from secondary first", "cooldown", failoverCooldown)
+	}
+}
+
+// ReconcileSummary reports a Reconcile run
+type ReconcileSummary struct {
+	Primary   int  `json:"primary"`   // files in the primary
+	Secondary int  `json:"secondary"` // files in the secondary before the run
+	Copied    int  `json:"copied"`
+	Removed   int  `json:"removed"`
+	Failed    int  `json:"failed"`
+	Complete  bool `json:"complete"` // false when maxReconcileChanges cut the run short
+}
+
+// Reconcile makes the secondary match the primary: files the mirror missed
+// are copied and files since removed from the primary are removed. Contents
+// of files in both aren't compared.
+func (p *ReplicatedProvider) Reconcile(ctx context.Context) (*ReconcileSummary, error) {
+	primaryKeys, err := p.primary.ListByPrefix(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("listing primary: %w", err)
+	}
+	secondaryKeys, err := p.secondary.ListByPrefix(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("listing secondary: %w", err)
+	}
+	summary := &ReconcileSummary{Primary: len(primaryKeys), Secondary: len(secondaryKeys), Complete: true}
+	if len(primaryKeys) == 0 && len(secondaryKeys) > 0 {
+		// An empty listing from a failing primary mustn't empty the secondary
+		return nil, errors.New("primary lists no files; not reconciling")
+	}
+
+	inPrimary := make(map[string]bool, len(primaryKeys))
+	for _, key := range primaryKeys {
+		inPrimary[key] = true
+	}
+	inSecondary := make(map[string]bool, len(secondaryKeys))
+	for _, key := range secondaryKeys {
+		inSecondary[key] = true
+	}
+
+	changes := 0
+	apply := func(key string, fn func() error) bool {
+		if changes >= maxReconcileChanges || ctx.Err() != nil {
+			summary.Complete = false
+			return false
+		}
+		changes++
+		if err := fn(); err != nil {
+			summary.Failed++
+			slog.Warn("Error reconciling replicated file", "key", key, "error", err)
+			return false
+		}
+		return true
+	}
+	for _, key := range primaryKeys {
+		if !inSecondary[key] && apply(key, func() error { return p.copy(ctx, key) }) {
+			summary.Copied++
+		}
+	}
+	for _, key := range secondaryKeys {
+		if !inPrimary[key] && apply(key, func() error { return p.secondary.Remove(ctx, key) }) {
+			summary.Removed++
+		}
+	}
+	return summary, nil
+}
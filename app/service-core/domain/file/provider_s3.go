@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"service-core/config"
 	"sync"
 
@@ -57,6 +58,14 @@ func (p *s3Provider) Upload(ctx context.Context, file *File) error {
 	return uploadFileToProvider(ctx, client, p.cfg.BucketName, file)
 }
 
+func (p *s3Provider) UploadStream(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	client, err := p.getClient(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting S3 client for upload: %w", err)
+	}
+	return uploadStreamToProvider(ctx, client, p.cfg.BucketName, key, contentType, body, size)
+}
+
 func (p *s3Provider) Download(ctx context.Context, fileKey string) ([]byte, error) {
 	client, err := p.getClient(ctx)
 	if err != nil {
@@ -65,6 +74,14 @@ func (p *s3Provider) Download(ctx context.Context, fileKey string) ([]byte, erro
 	return downloadFileFromProvider(ctx, client, p.cfg.BucketName, fileKey)
 }
 
+func (p *s3Provider) DownloadStream(ctx context.Context, fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	client, err := p.getClient(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error getting S3 client for download: %w", err)
+	}
+	return downloadStreamFromProvider(ctx, client, p.cfg.BucketName, fileKey, offset)
+}
+
 func (p *s3Provider) Remove(ctx context.Context, fileKey string) error {
 	client, err := p.getClient(ctx)
 	if err != nil {
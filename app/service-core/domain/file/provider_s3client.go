@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -12,12 +13,22 @@ import (
 
 // Helper functions for S3 and R2 providers
 func uploadFileToProvider(ctx context.Context, client *s3.Client, bucketName string, file *File) error {
-	_, err := client.PutObject(ctx, &s3.PutObjectInput{
+	return uploadStreamToProvider(ctx, client, bucketName, file.Key, file.ContentType, bytes.NewReader(file.Data), int64(len(file.Data)))
+}
+
+// uploadStreamToProvider puts body in one request. A body that can't seek is
+// sent with a trailing checksum, which needs its size up front.
+func uploadStreamToProvider(ctx context.Context, client *s3.Client, bucketName, key, contentType string, body io.Reader, size int64) error {
+	input := &s3.PutObjectInput{
 		Bucket:      aws.String(bucketName),
-		Key:         aws.String(file.Key),
-		Body:        bytes.NewReader(file.Data),
-		ContentType: aws.String(file.ContentType),
-	})
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	}
+	if size >= 0 {
+		input.ContentLength = aws.Int64(size)
+	}
+	_, err := client.PutObject(ctx, input)
 	if err != nil {
 		return fmt.Errorf("error uploading file to S3, %w", err)
 	}
@@ -42,6 +53,25 @@ func downloadFileFromProvider(ctx context.Context, client *s3.Client, bucketName
 	return buf.Bytes(), nil
 }
 
+func downloadStreamFromProvider(ctx context.Context, client *s3.Client, bucketName string, fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(fileKey),
+	}
+	if offset > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+	output, err := client.GetObject(ctx, input)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error downloading file from S3, %w", err)
+	}
+	size := int64(-1)
+	if output.ContentLength != nil {
+		size = *output.ContentLength
+	}
+	return output.Body, size, nil
+}
+
 func removeFileFromProvider(ctx context.Context, client *s3.Client, bucketName string, fileKey string) error {
 	_, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(bucketName),
@@ -54,17 +84,22 @@ func removeFileFromProvider(ctx context.Context, client *s3.Client, bucketName s
 	return nil
 }
 
+// listByPrefixFromProvider returns the keys under prefix with the prefix
+// trimmed, following continuation tokens past the 1000 keys one listing returns.
 func listByPrefixFromProvider(ctx context.Context, client *s3.Client, bucketName, prefix string) ([]string, error) {
-	output, err := client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
 		Bucket: aws.String(bucketName),
 		Prefix: aws.String(prefix),
 	})
-	if err != nil {
-		return nil, fmt.Errorf("error listing objects by prefix: %w", err)
-	}
-	keys := make([]string, 0, len(output.Contents))
-	for _, obj := range output.Contents {
-		keys = append(keys, strings.TrimPrefix(*obj.Key, prefix))
+	keys := make([]string, 0)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing objects by prefix: %w", err)
+		}
+		for _, obj := range output.Contents {
+			keys = append(keys, strings.TrimPrefix(*obj.Key, prefix))
+		}
 	}
 	return keys, nil
 }
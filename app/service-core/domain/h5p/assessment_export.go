@@ -0,0 +1,360 @@
+package h5p
+
+import (
+	"app/pkg"
+	"app/pkg/cfbrowser"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Question types an assessment export can carry.
+const (
+	QuestionChoice    = "choice"
+	QuestionTrueFalse = "true_false"
+	QuestionBlanks    = "blanks"
+)
+
+// Export formats.
+const (
+	ExportFormatHTML = "html"
+	ExportFormatPDF  = "pdf"
+	ExportFormatQTI  = "qti"
+	// ExportFormatH5P is the whole content item as an .h5p package
+	ExportFormatH5P = "h5p"
+)
+
+// AssessmentExport is the question content of an H5P item flattened for
+// printing and for IMS QTI 2.1. Questions are numbered in the order a learner
+// meets them; anything that can't be mapped is reported in Warnings instead.
+type AssessmentExport struct {
+	Title     string           `json:"title"`
+	Questions []ExportQuestion `json:"questions"`
+	Warnings  []ExportWarning  `json:"warnings"`
+}
+
+// ExportQuestion is one question. Prompt and choice texts are plain text:
+// authored markup is dropped so the document is safe to render server-side.
+type ExportQuestion struct {
+	Number  int    `json:"number"`
+	Type    string `json:"type"`
+	Library string `json:"library"`
+	Prompt  string `json:"prompt"`
+	// Choice questions
+	Choices  []ExportChoice `json:"choices,omitempty"`
+	Multiple bool           `json:"multiple,omitempty"` // more than one choice may be correct
+	// True/false questions
+	Answer bool `json:"answer,omitempty"`
+	// Fill-in-the-blanks questions, one entry per sentence
+	Lines         [][]BlankSegment `json:"lines,omitempty"`
+	CaseSensitive bool             `json:"caseSensitive,omitempty"`
+}
+
+// ExportChoice is an option of a choice question.
+type ExportChoice struct {
+	Text    string `json:"text"`
+	Correct bool   `json:"correct"`
+}
+
+// BlankSegment is a run of text, or a gap when Answers is set. The first
+// answer is the model answer; the rest are accepted alternatives.
+type BlankSegment struct {
+	Text    string   `json:"text,omitempty"`
+	Answers []string `json:"answers,omitempty"`
+}
+
+// ExportWarning explains why part of the content was left out or changed.
+// Position is the item's place in the content, e.g. "3" for the third
+// question of a set.
+type ExportWarning struct {
+	Position string `json:"position"`
+	Library  string `json:"library"`
+	Message  string `json:"message"`
+}
+
+// maxExportDepth bounds how far container content is descended.
+const maxExportDepth = 4
+
+// BuildAssessmentExport extracts the questions of a content item. Question
+// sets and columns are flattened; other content types in them are skipped
+// with a warning.
+func (s *Service) BuildAssessmentExport(ctx context.Context, contentID, orgID uuid.UUID) (*AssessmentExport, error) {
+	params, err := s.GetContentParams(ctx, contentID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	var meta struct {
+		Title string `json:"title"`
+	}
+	_ = json.Unmarshal(params.H5P, &meta)
+
+	export := &AssessmentExport{
+		Title:     meta.Title,
+		Questions: []ExportQuestion{},
+		Warnings:  []ExportWarning{},
+	}
+	export.add(params.Library, params.Params, "", 0)
+	return export, nil
+}
+
+// add maps one piece of content, library as "H5P.MultiChoice 1.16".
+func (e *AssessmentExport) add(library string, params json.RawMessage, position string, depth int) {
+	machineName, _, _ := strings.Cut(library, " ")
+	warn := func(format string, args ...any) {
+		e.Warnings = append(e.Warnings, ExportWarning{Position: position, Library: machineName, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if hasMedia(params) {
+		warn("Images and video attached to the question are not included")
+	}
+
+	switch machineName {
+	case "H5P.MultiChoice":
+		q, err := choiceQuestion(params)
+		if err != nil {
+			warn("Could not read the question: %v", err)
+			return
+		}
+		if !hasCorrectChoice(q.Choices) {
+			warn("No answer is marked correct, so the answer key is empty")
+		}
+		e.addQuestion(q, machineName)
+	case "H5P.TrueFalse":
+		var p struct {
+			Question string `json:"question"`
+			Correct  string `json:"correct"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			warn("Could not read the question: %v", err)
+			return
+		}
+		e.addQuestion(ExportQuestion{Type: QuestionTrueFalse, Prompt: plainText(p.Question), Answer: p.Correct == "true"}, machineName)
+	case "H5P.Blanks":
+		q, gaps, err := blanksQuestion(params)
+		if err != nil {
+			warn("Could not read the question: %v", err)
+			return
+		}
+		if gaps == 0 {
+			warn("The text has no blanks marked with asterisks")
+			return
+		}
+		e.addQuestion(q, machineName)
+	case "H5P.QuestionSet", "H5P.Column":
+		if depth >= maxExportDepth {
+			warn("Nested too deeply to export")
+			return
+		}
+		children, err := childContent(machineName, params)
+		if err != nil {
+			warn("Could not read the content: %v", err)
+			return
+		}
+		for i, child := range children {
+			childPosition := fmt.Sprint(i + 1)
+			if position != "" {
+				childPosition = position + "." + childPosition
+			}
+			e.add(child.Library, child.Params, childPosition, depth+1)
+		}
+	case "H5P.AdvancedText", "H5P.Image", "H5P.Video", "H5P.Audio":
+		// Presentational content inside a column; nothing to ask
+	default:
+		if machineName == "" {
+			machineName = "unknown"
+		}
+		warn("%s has no printable or QTI mapping and was left out", machineName)
+	}
+}
+
+// hasMedia reports whether a question carries H5P's optional media, which
+// the MultiChoice, TrueFalse and Blanks types all keep under the same key.
+func hasMedia(params json.RawMessage) bool {
+	var p struct {
+		Media struct {
+			Type *subContent `json:"type"`
+		} `json:"media"`
+	}
+	return json.Unmarshal(params, &p) == nil && p.Media.Type != nil && p.Media.Type.Library != ""
+}
+
+func (e *AssessmentExport) addQuestion(q ExportQuestion, library string) {
+	q.Number = len(e.Questions) + 1
+	q.Library = library
+	e.Questions = append(e.Questions, q)
+}
+
+// subContent is a library reference embedded in container params.
+type subContent struct {
+	Library string          `json:"library"`
+	Params  json.RawMessage `json:"params"`
+}
+
+func childContent(machineName string, params json.RawMessage) ([]subContent, error) {
+	if machineName == "H5P.QuestionSet" {
+		var p struct {
+			Questions []subContent `json:"questions"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		return p.Questions, nil
+	}
+
+	var p struct {
+		Content []struct {
+			Content subContent `json:"content"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	children := make([]subContent, 0, len(p.Content))
+	for _, c := range p.Content {
+		children = append(children, c.Content)
+	}
+	return children, nil
+}
+
+func choiceQuestion(params json.RawMessage) (ExportQuestion, error) {
+	var p struct {
+		Question string `json:"question"`
+		Answers  []struct {
+			Text    string `json:"text"`
+			Correct bool   `json:"correct"`
+		} `json:"answers"`
+		Behaviour struct {
+			Type string `json:"type"` // auto, single or multi
+		} `json:"behaviour"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return ExportQuestion{}, err
+	}
+
+	q := ExportQuestion{Type: QuestionChoice, Prompt: plainText(p.Question)}
+	correct := 0
+	for _, a := range p.Answers {
+		q.Choices = append(q.Choices, ExportChoice{Text: plainText(a.Text), Correct: a.Correct})
+		if a.Correct {
+			correct++
+		}
+	}
+	switch p.Behaviour.Type {
+	case "single":
+		q.Multiple = false
+	case "multi":
+		q.Multiple = true
+	default:
+		// H5P's "auto" lets learners pick several only when several are correct
+		q.Multiple = correct > 1
+	}
+	return q, nil
+}
+
+func hasCorrectChoice(choices []ExportChoice) bool {
+	for _, c := range choices {
+		if c.Correct {
+			return true
+		}
+	}
+	return false
+}
+
+// blanksQuestion parses H5P.Blanks, where each sentence marks its gaps as
+// *answer/alternative:tip*. It returns the number of gaps found.
+func blanksQuestion(params json.RawMessage) (ExportQuestion, int, error) {
+	var p struct {
+		Text      string   `json:"text"`
+		Questions []string `json:"questions"`
+		Behaviour struct {
+			CaseSensitive *bool `json:"caseSensitive"`
+		} `json:"behaviour"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return ExportQuestion{}, 0, err
+	}
+
+	q := ExportQuestion{
+		Type:   QuestionBlanks,
+		Prompt: plainText(p.Text),
+		// H5P matches case unless the author turns it off
+		CaseSensitive: p.Behaviour.CaseSensitive == nil || *p.Behaviour.CaseSensitive,
+	}
+	gaps := 0
+	for _, sentence := range p.Questions {
+		var line []BlankSegment
+		// Odd-numbered parts sit between asterisks
+		for i, part := range strings.Split(plainText(sentence), "*") {
+			if i%2 == 0 {
+				if part != "" {
+					line = append(line, BlankSegment{Text: part})
+				}
+				continue
+			}
+			answers, _, _ := strings.Cut(part, ":")
+			var accepted []string
+			for _, a := range strings.Split(answers, "/") {
+				if a = strings.TrimSpace(a); a != "" {
+					accepted = append(accepted, a)
+				}
+			}
+			if len(accepted) == 0 {
+				continue
+			}
+			line = append(line, BlankSegment{Answers: accepted})
+			gaps++
+		}
+		if len(line) > 0 {
+			q.Lines = append(q.Lines, line)
+		}
+	}
+	return q, gaps, nil
+}
+
+var (
+	blockTagPattern = regexp.MustCompile(`(?i)</?(p|div|br|li|h[1-6])\b[^>]*>`)
+	tagPattern      = regexp.MustCompile(`<[^>]*>`)
+	spacePattern    = regexp.MustCompile(`[ \t\r\f\v]+`)
+	newlinePattern  = regexp.MustCompile(`\s*\n\s*`)
+)
+
+// plainText reduces authored HTML to text, keeping paragraph breaks.
+func plainText(s string) string {
+	s = blockTagPattern.ReplaceAllString(s, "\n")
+	s = tagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, " ", " ")
+	s = spacePattern.ReplaceAllString(s, " ")
+	s = newlinePattern.ReplaceAllString(s, "\n")
+	return strings.TrimSpace(s)
+}
+
+// printPDFOptions lays the paper out with room for handwritten answers.
+var printPDFOptions = cfbrowser.PDFOptions{
+	Format:  "A4",
+	Margins: cfbrowser.PDFMargins{Top: "2cm", Right: "2cm", Bottom: "2cm", Left: "2cm"},
+	PageOptions: cfbrowser.PageOptions{
+		DisableJavaScript: true,
+	},
+}
+
+// RenderAssessmentPDF prints the export through the browser renderer.
+func (s *Service) RenderAssessmentPDF(ctx context.Context, export *AssessmentExport, answerKey bool) ([]byte, error) {
+	if s.pdfRenderer == nil {
+		return nil, pkg.BadRequestError{Message: "PDF export is not available; download the HTML and print it instead"}
+	}
+	doc, err := export.PrintHTML(answerKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.pdfRenderer.RenderHTMLPDF(ctx, string(doc), printPDFOptions)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error rendering PDF", Err: err}
+	}
+	return data, nil
+}
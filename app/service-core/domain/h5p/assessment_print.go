@@ -0,0 +1,80 @@
+package h5p
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// printTemplate is a self-contained paper version of an assessment. The
+// worker prints it without a base URL, so styles must stay inline.
+var printTemplate = template.Must(template.New("print").Funcs(template.FuncMap{
+	"letter":  func(i int) string { return string(rune('A' + i%26)) },
+	"answers": func(answers []string) string { return strings.Join(answers, " / ") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+	body { font-family: Georgia, "Times New Roman", serif; font-size: 12pt; line-height: 1.5; color: #000; }
+	h1 { font-size: 18pt; margin: 0 0 0.25em; }
+	.candidate { margin: 0 0 1.5em; }
+	.candidate span { display: inline-block; min-width: 16em; border-bottom: 1px solid #000; }
+	.question { break-inside: avoid; margin: 0 0 1.5em; }
+	.prompt { white-space: pre-line; margin: 0 0 0.5em; }
+	.choices { list-style: none; padding: 0; margin: 0; }
+	.choices li { margin: 0.25em 0; }
+	.box { display: inline-block; width: 0.9em; height: 0.9em; border: 1px solid #000; margin-right: 0.5em; vertical-align: middle; text-align: center; line-height: 0.9em; font-size: 10pt; }
+	.round { border-radius: 50%; }
+	.gap { display: inline-block; min-width: 8em; border-bottom: 1px solid #000; }
+	.key .gap { min-width: 0; font-weight: bold; }
+	.hint { font-size: 10pt; font-style: italic; }
+</style>
+</head>
+<body{{if .AnswerKey}} class="key"{{end}}>
+<h1>{{.Title}}{{if .AnswerKey}} — answer key{{end}}</h1>
+{{if not .AnswerKey}}<p class="candidate">Name: <span></span></p>{{end}}
+{{range .Questions}}
+<section class="question">
+	{{if eq .Type "choice"}}
+	<p class="prompt"><strong>{{.Number}}.</strong> {{.Prompt}}</p>
+	{{if .Multiple}}<p class="hint">Select all that apply.</p>{{end}}
+	<ul class="choices">
+		{{$multiple := .Multiple}}
+		{{range $i, $c := .Choices}}
+		<li><span class="box{{if not $multiple}} round{{end}}">{{if and $.AnswerKey $c.Correct}}✓{{end}}</span>{{letter $i}}. {{$c.Text}}</li>
+		{{end}}
+	</ul>
+	{{else if eq .Type "true_false"}}
+	<p class="prompt"><strong>{{.Number}}.</strong> {{.Prompt}}</p>
+	<ul class="choices">
+		<li><span class="box round">{{if and $.AnswerKey .Answer}}✓{{end}}</span>True</li>
+		<li><span class="box round">{{if and $.AnswerKey (not .Answer)}}✓{{end}}</span>False</li>
+	</ul>
+	{{else if eq .Type "blanks"}}
+	<p class="prompt"><strong>{{.Number}}.</strong> {{if .Prompt}}{{.Prompt}}{{else}}Fill in the blanks.{{end}}</p>
+	{{range .Lines}}
+	<p>{{range .}}{{if .Answers}}<span class="gap">{{if $.AnswerKey}}{{answers .Answers}}{{end}}</span>{{else}}{{.Text}}{{end}}{{end}}</p>
+	{{end}}
+	{{end}}
+</section>
+{{end}}
+</body>
+</html>
+`))
+
+// PrintHTML renders the export as a printable document. With answerKey the
+// correct answers are filled in and the candidate name line is left off.
+func (e *AssessmentExport) PrintHTML(answerKey bool) ([]byte, error) {
+	var buf bytes.Buffer
+	err := printTemplate.Execute(&buf, struct {
+		*AssessmentExport
+		AnswerKey bool
+	}{e, answerKey})
+	if err != nil {
+		return nil, fmt.Errorf("render print template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,212 @@
+package h5p
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// QTI 2.1 namespaces and the response processing templates the items use.
+const (
+	qtiNamespace         = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiSchemaLocation    = "http://www.imsglobal.org/xsd/imsqti_v2p1 http://www.imsglobal.org/xsd/qti/qtiv2p1/imsqti_v2p1.xsd"
+	qtiManifestNamespace = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	qtiMatchCorrect      = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+)
+
+type qtiItem struct {
+	XMLName        xml.Name          `xml:"assessmentItem"`
+	Namespace      string            `xml:"xmlns,attr"`
+	XSI            string            `xml:"xmlns:xsi,attr"`
+	SchemaLocation string            `xml:"xsi:schemaLocation,attr"`
+	Identifier     string            `xml:"identifier,attr"`
+	Title          string            `xml:"title,attr"`
+	Adaptive       bool              `xml:"adaptive,attr"`
+	TimeDependent  bool              `xml:"timeDependent,attr"`
+	Responses      []qtiResponseDecl `xml:"responseDeclaration"`
+	Outcome        qtiOutcomeDecl    `xml:"outcomeDeclaration"`
+	Body           qtiInnerXML       `xml:"itemBody"`
+	Processing     qtiInnerXML       `xml:"responseProcessing"`
+}
+
+type qtiResponseDecl struct {
+	Identifier  string      `xml:"identifier,attr"`
+	Cardinality string      `xml:"cardinality,attr"`
+	BaseType    string      `xml:"baseType,attr"`
+	Correct     *qtiValues  `xml:"correctResponse,omitempty"`
+	Mapping     *qtiMapping `xml:"mapping,omitempty"`
+}
+
+// qtiValues is left out entirely when nil, since QTI requires at least one value.
+type qtiValues struct {
+	Values []string `xml:"value"`
+}
+
+type qtiMapping struct {
+	DefaultValue string        `xml:"defaultValue,attr"`
+	Entries      []qtiMapEntry `xml:"mapEntry"`
+}
+
+type qtiMapEntry struct {
+	Key           string `xml:"mapKey,attr"`
+	Value         string `xml:"mappedValue,attr"`
+	CaseSensitive bool   `xml:"caseSensitive,attr"`
+}
+
+type qtiOutcomeDecl struct {
+	Identifier  string `xml:"identifier,attr"`
+	Cardinality string `xml:"cardinality,attr"`
+	BaseType    string `xml:"baseType,attr"`
+}
+
+// qtiInnerXML holds element content that mixes text and interactions, which
+// encoding/xml can't model; callers escape text with qtiText. An empty
+// Template attribute is omitted.
+type qtiInnerXML struct {
+	Template string `xml:"template,attr,omitempty"`
+	Content  string `xml:",innerxml"`
+}
+
+type qtiManifest struct {
+	XMLName    xml.Name      `xml:"manifest"`
+	Namespace  string        `xml:"xmlns,attr"`
+	Identifier string        `xml:"identifier,attr"`
+	Resources  []qtiResource `xml:"resources>resource"`
+}
+
+type qtiResource struct {
+	Identifier string `xml:"identifier,attr"`
+	Type       string `xml:"type,attr"`
+	Href       string `xml:"href,attr"`
+	File       struct {
+		Href string `xml:"href,attr"`
+	} `xml:"file"`
+}
+
+// QTIPackage returns the export as an IMS QTI 2.1 content package: a zip of
+// one assessmentItem per question plus the imsmanifest.xml that lists them.
+func (e *AssessmentExport) QTIPackage() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	manifest := qtiManifest{Namespace: qtiManifestNamespace, Identifier: "MANIFEST-1"}
+
+	for _, q := range e.Questions {
+		item := q.qtiItem()
+		href := fmt.Sprintf("items/%s.xml", item.Identifier)
+		if err := writeXML(zw, href, item); err != nil {
+			return nil, err
+		}
+		res := qtiResource{Identifier: item.Identifier, Type: "imsqti_item_xmlv2p1", Href: href}
+		res.File.Href = href
+		manifest.Resources = append(manifest.Resources, res)
+	}
+	if err := writeXML(zw, "imsmanifest.xml", manifest); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("close QTI package: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeXML(zw *zip.Writer, name string, v any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	if _, err := f.Write([]byte(xml.Header)); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	enc := xml.NewEncoder(f)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+	return nil
+}
+
+func (q ExportQuestion) qtiItem() qtiItem {
+	item := qtiItem{
+		Namespace:      qtiNamespace,
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: qtiSchemaLocation,
+		Identifier:     fmt.Sprintf("item-%d", q.Number),
+		Title:          fmt.Sprintf("Question %d", q.Number),
+		Outcome:        qtiOutcomeDecl{Identifier: "SCORE", Cardinality: "single", BaseType: "float"},
+	}
+
+	switch q.Type {
+	case QuestionChoice, QuestionTrueFalse:
+		choices := q.Choices
+		if q.Type == QuestionTrueFalse {
+			choices = []ExportChoice{{Text: "True", Correct: q.Answer}, {Text: "False", Correct: !q.Answer}}
+		}
+		decl := qtiResponseDecl{Identifier: "RESPONSE", Cardinality: "single", BaseType: "identifier"}
+		maxChoices := 1
+		if q.Multiple {
+			decl.Cardinality = "multiple"
+			maxChoices = 0
+		}
+		var body strings.Builder
+		fmt.Fprintf(&body, `<choiceInteraction responseIdentifier="RESPONSE" shuffle="false" maxChoices="%d"><prompt>%s</prompt>`, maxChoices, qtiText(q.Prompt))
+		for i, c := range choices {
+			id := fmt.Sprintf("CHOICE_%d", i+1)
+			if c.Correct {
+				if decl.Correct == nil {
+					decl.Correct = &qtiValues{}
+				}
+				decl.Correct.Values = append(decl.Correct.Values, id)
+			}
+			fmt.Fprintf(&body, `<simpleChoice identifier="%s">%s</simpleChoice>`, id, qtiText(c.Text))
+		}
+		body.WriteString(`</choiceInteraction>`)
+		item.Responses = []qtiResponseDecl{decl}
+		item.Body.Content = body.String()
+		item.Processing.Template = qtiMatchCorrect
+
+	case QuestionBlanks:
+		var body, sum strings.Builder
+		if q.Prompt != "" {
+			fmt.Fprintf(&body, `<p>%s</p>`, qtiText(q.Prompt))
+		}
+		gap := 0
+		for _, line := range q.Lines {
+			body.WriteString(`<p>`)
+			for _, seg := range line {
+				if len(seg.Answers) == 0 {
+					body.WriteString(qtiText(seg.Text))
+					continue
+				}
+				gap++
+				id := fmt.Sprintf("RESPONSE_%d", gap)
+				decl := qtiResponseDecl{
+					Identifier:  id,
+					Cardinality: "single",
+					BaseType:    "string",
+					Correct:     &qtiValues{Values: []string{seg.Answers[0]}},
+					Mapping:     &qtiMapping{DefaultValue: "0"},
+				}
+				for _, a := range seg.Answers {
+					decl.Mapping.Entries = append(decl.Mapping.Entries, qtiMapEntry{Key: a, Value: "1", CaseSensitive: q.CaseSensitive})
+				}
+				item.Responses = append(item.Responses, decl)
+				fmt.Fprintf(&body, `<textEntryInteraction responseIdentifier="%s" expectedLength="%d"/>`, id, max(len(seg.Answers[0]), 8))
+				fmt.Fprintf(&sum, `<mapResponse identifier="%s"/>`, id)
+			}
+			body.WriteString(`</p>`)
+		}
+		item.Body.Content = body.String()
+		// One point per gap; the match_correct template only scores RESPONSE
+		item.Processing.Content = `<setOutcomeValue identifier="SCORE"><sum>` + sum.String() + `</sum></setOutcomeValue>`
+	}
+	return item
+}
+
+// qtiText escapes text for inclusion in hand-built element content.
+func qtiText(s string) string {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,143 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// CDNURLWindow is how long a signed CDN redirect stays the same. Redirects
+// expire between one and two windows after they're made, so a redirect
+// cached for up to a window still works.
+const CDNURLWindow = time.Hour
+
+// fullVersionFolder matches a library folder with its patch version, which is
+// the folder name in storage.
+var fullVersionFolder = regexp.MustCompile(`-\d+\.\d+\.\d+$`)
+
+// Asset is a stored file to serve: a library asset or a content file. It's
+// resolved without reading the file, so a request the client has cached can
+// be answered from ETag and ModTime alone.
+type Asset struct {
+	Key         string
+	ContentType string
+	ModTime     time.Time
+	// Private assets need an access check, so they're only redirected to the
+	// CDN with a signed URL
+	Private bool
+}
+
+// ETag identifies the asset's current bytes. Library files only change when
+// their version is reinstalled and content files when the content is saved,
+// both of which move ModTime.
+func (a *Asset) ETag() string {
+	sum := sha256.Sum256([]byte(a.Key + "@" + strconv.FormatInt(a.ModTime.UnixNano(), 10)))
+	return `"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// ResolveLibraryAsset finds the stored file for a library asset path. Paths
+// name the library folder with a full version, major.minor, or no version
+// for the latest one installed.
+func (s *Service) ResolveLibraryAsset(ctx context.Context, assetPath string) (*Asset, error) {
+	folder, filePath, ok := strings.Cut(assetPath, "/")
+	if !ok || filePath == "" {
+		return nil, pkg.NotFoundError{Message: "Asset not found"}
+	}
+	loc := fullVersionFolder.FindStringIndex(folder)
+	if loc == nil {
+		resolved, err := s.resolveLibraryAssetPath(ctx, assetPath)
+		if err != nil {
+			return nil, pkg.NotFoundError{Message: "Asset not found", Err: err}
+		}
+		folder, filePath, _ = strings.Cut(resolved, "/")
+		loc = fullVersionFolder.FindStringIndex(folder)
+	}
+
+	var major, minor, patch int32
+	fmt.Sscanf(folder[loc[0]+1:], "%d.%d.%d", &major, &minor, &patch)
+	lib, err := s.store.GetH5PLibraryByMachineNameVersion(ctx, query.GetH5PLibraryByMachineNameVersionParams{
+		MachineName:  folder[:loc[0]],
+		MajorVersion: major,
+		MinorVersion: minor,
+		PatchVersion: patch,
+	})
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Asset not found", Err: err}
+	}
+
+	return &Asset{
+		Key:         LibraryStorageKey(lib.MachineName, int(lib.MajorVersion), int(lib.MinorVersion), int(lib.PatchVersion), filePath),
+		ContentType: detectContentType(filePath),
+		ModTime:     lib.UpdatedAt,
+	}, nil
+}
+
+// ResolveContentFile finds the stored file of a content item.
+func (s *Service) ResolveContentFile(ctx context.Context, contentID, orgID uuid.UUID, filePath string) (*Asset, error) {
+	content, err := s.store.GetH5PContent(ctx, query.GetH5PContentParams{
+		ID:    contentID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Content not found", Err: err}
+	}
+	return &Asset{
+		Key:         fmt.Sprintf("h5p-content/%s/%s/%s", content.OrgID, content.ID, filePath),
+		ContentType: detectContentType(filePath),
+		ModTime:     content.UpdatedAt,
+		Private:     true,
+	}, nil
+}
+
+// ReadAsset reads an asset's bytes from storage.
+func (s *Service) ReadAsset(ctx context.Context, asset *Asset) ([]byte, error) {
+	data, err := s.fileProvider.Download(ctx, asset.Key)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "File not found", Err: err}
+	}
+	return data, nil
+}
+
+// OpenAsset opens an asset's bytes from offset on for streaming, with how many
+// there are or -1 when storage doesn't report it. The caller closes it.
+func (s *Service) OpenAsset(ctx context.Context, asset *Asset, offset int64) (io.ReadCloser, int64, error) {
+	body, size, err := s.fileProvider.DownloadStream(ctx, asset.Key, offset)
+	if err != nil {
+		return nil, 0, pkg.NotFoundError{Message: "File not found", Err: err}
+	}
+	return body, size, nil
+}
+
+// AssetURL returns the CDN URL to redirect an asset request to, or "" to
+// serve it from storage: when no CDN is configured, or the asset is private
+// and redirects can't be signed. Signed URLs carry expires and signature
+// parameters, the hex HMAC-SHA256 of "<key>.<expires>", for the CDN to check.
+func (s *Service) AssetURL(asset *Asset) string {
+	if s.cfg.AssetCDNURL == "" || (asset.Private && s.cfg.AssetCDNSigningKey == "") {
+		return ""
+	}
+	u := strings.TrimSuffix(s.cfg.AssetCDNURL, "/") + "/" + (&url.URL{Path: asset.Key}).EscapedPath()
+	if s.cfg.AssetCDNSigningKey == "" {
+		return u
+	}
+	expires := time.Now().Truncate(CDNURLWindow).Add(2 * CDNURLWindow).Unix()
+	mac := hmac.New(sha256.New, []byte(s.cfg.AssetCDNSigningKey))
+	fmt.Fprintf(mac, "%s.%d", asset.Key, expires)
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("signature", hex.EncodeToString(mac.Sum(nil)))
+	return u + "?" + q.Encode()
+}
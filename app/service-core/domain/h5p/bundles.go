@@ -0,0 +1,293 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// StableBundle is the editor bundle organisations get when no variant
+	// has taken them
+	StableBundle = "stable"
+	// stableAssetBase is where the stable bundle's core and editor assets
+	// are served, as static files of the client
+	stableAssetBase = "/h5p"
+	// MaxBundleReportDays is the longest window bundle reports cover.
+	MaxBundleReportDays = 90
+)
+
+// Editor bundle report events
+const (
+	BundleEventLoad  = "load"
+	BundleEventError = "error"
+)
+
+var bundleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// BundleVariant is an editor bundle rolled out to a percentage of
+// organisations, e.g. a new aggregation of the core and editor scripts.
+type BundleVariant struct {
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	AssetBase      string    `json:"assetBase"`
+	RolloutPercent int       `json:"rolloutPercent"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// BundleVariantInput creates or replaces a bundle variant. A RolloutPercent
+// of 0 rolls the variant back without deleting it.
+type BundleVariantInput struct {
+	Description    string `json:"description"`
+	AssetBase      string `json:"assetBase"`
+	RolloutPercent int    `json:"rolloutPercent"`
+}
+
+// EditorBundle is the bundle an organisation's editor loads. A variant's
+// asset URLs carry its name in a bundle query parameter, so caches keep
+// variants apart and errors can be traced to the bundle that raised them.
+type EditorBundle struct {
+	Variant   string `json:"variant"`
+	AssetBase string `json:"assetBase"`
+}
+
+// BundleReport compares a bundle's editor loads and errors over a period.
+// RolloutPercent is nil for stable and for variants since deleted.
+// ErrorRateDelta is the bundle's error rate less stable's, in percentage
+// points; a variant well above 0 is a candidate for rolling back.
+type BundleReport struct {
+	Variant        string  `json:"variant"`
+	RolloutPercent *int    `json:"rolloutPercent"`
+	Loads          int64   `json:"loads"`
+	Errors         int64   `json:"errors"`
+	ErrorRate      float64 `json:"errorRate"` // errors per 100 loads
+	ErrorRateDelta float64 `json:"errorRateDelta"`
+}
+
+func toBundleVariant(v query.H5pBundleVariant) BundleVariant {
+	return BundleVariant{
+		Name:           v.Name,
+		Description:    v.Description,
+		AssetBase:      v.AssetBase,
+		RolloutPercent: int(v.RolloutPercent),
+		CreatedAt:      v.CreatedAt,
+		UpdatedAt:      v.UpdatedAt,
+	}
+}
+
+// normalize trims the input and checks the asset base is a path on this
+// site or an https URL.
+func (in *BundleVariantInput) normalize() error {
+	in.Description = strings.TrimSpace(in.Description)
+	in.AssetBase = strings.TrimRight(strings.TrimSpace(in.AssetBase), "/")
+	if in.RolloutPercent < 0 || in.RolloutPercent > 100 {
+		return pkg.BadRequestError{Message: "rolloutPercent must be 0 to 100"}
+	}
+	u, err := url.Parse(in.AssetBase)
+	if err != nil || in.AssetBase == "" || strings.Contains(in.AssetBase, "..") || u.RawQuery != "" || u.Fragment != "" {
+		return pkg.BadRequestError{Message: "Invalid assetBase"}
+	}
+	if u.IsAbs() && (u.Scheme != "https" || u.Host == "") {
+		return pkg.BadRequestError{Message: "assetBase must be an https URL or a path starting with /"}
+	}
+	if !u.IsAbs() && (!strings.HasPrefix(in.AssetBase, "/") || strings.HasPrefix(in.AssetBase, "//")) {
+		return pkg.BadRequestError{Message: "assetBase must be an https URL or a path starting with /"}
+	}
+	return nil
+}
+
+// ListBundleVariants returns every bundle variant, oldest first, which is
+// the order they take organisations in
+func (s *Service) ListBundleVariants(ctx context.Context) ([]BundleVariant, error) {
+	variants, err := s.store.ListH5PBundleVariants(ctx)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing bundle variants", Err: err}
+	}
+	result := make([]BundleVariant, len(variants))
+	for i, v := range variants {
+		result[i] = toBundleVariant(v)
+	}
+	return result, nil
+}
+
+// PutBundleVariant creates or replaces a bundle variant. Variants share the
+// organisations, so their rollout percentages can't add up to more than 100.
+// A change takes effect on each organisation's next editor load.
+func (s *Service) PutBundleVariant(ctx context.Context, userID uuid.UUID, name string, in BundleVariantInput) (*BundleVariant, error) {
+	if !bundleNamePattern.MatchString(name) || name == StableBundle {
+		return nil, pkg.BadRequestError{Message: "Variant names are 1 to 32 lowercase letters, digits and dashes, and can't be \"stable\""}
+	}
+	if err := in.normalize(); err != nil {
+		return nil, err
+	}
+	variants, err := s.store.ListH5PBundleVariants(ctx)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing bundle variants", Err: err}
+	}
+	total := in.RolloutPercent
+	for _, v := range variants {
+		if v.Name != name {
+			total += int(v.RolloutPercent)
+		}
+	}
+	if total > 100 {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("Variants would take %d%% of organisations; lower another variant's rollout first", total)}
+	}
+
+	v, err := s.store.UpsertH5PBundleVariant(ctx, query.UpsertH5PBundleVariantParams{
+		Name:           name,
+		Description:    in.Description,
+		AssetBase:      in.AssetBase,
+		RolloutPercent: int32(in.RolloutPercent),
+		CreatedBy:      uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error saving bundle variant", Err: err}
+	}
+	variant := toBundleVariant(v)
+	return &variant, nil
+}
+
+// DeleteBundleVariant removes a variant, moving its organisations back to
+// stable. Its reports are kept.
+func (s *Service) DeleteBundleVariant(ctx context.Context, name string) error {
+	n, err := s.store.DeleteH5PBundleVariant(ctx, name)
+	if err != nil {
+		return pkg.InternalError{Message: "Error deleting bundle variant", Err: err}
+	}
+	if n == 0 {
+		return pkg.NotFoundError{Message: "Bundle variant not found"}
+	}
+	return nil
+}
+
+// EditorBundle picks the bundle an organisation's editor loads. Each
+// organisation has a fixed bucket from 0 to 99, and variants take buckets in
+// turn, oldest first, as many as their rollout percentage. So raising a
+// variant's percentage keeps the organisations it already has, and an
+// organisation doesn't flip between bundles from one load to the next.
+func (s *Service) EditorBundle(ctx context.Context, orgID uuid.UUID) (*EditorBundle, error) {
+	variants, err := s.store.ListH5PBundleVariants(ctx)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing bundle variants", Err: err}
+	}
+	bucket := rolloutBucket(orgID)
+	start := 0
+	for _, v := range variants {
+		end := start + int(v.RolloutPercent)
+		if bucket >= start && bucket < end {
+			return &EditorBundle{Variant: v.Name, AssetBase: v.AssetBase}, nil
+		}
+		start = end
+	}
+	return &EditorBundle{Variant: StableBundle, AssetBase: stableAssetBase}, nil
+}
+
+// rolloutBucket places an organisation in one of 100 rollout buckets
+func rolloutBucket(orgID uuid.UUID) int {
+	h := fnv.New32a()
+	h.Write(orgID[:])
+	return int(h.Sum32() % 100)
+}
+
+// TagAssets adds the bundle's variant to the library's asset URLs, like the
+// editor does to the core and editor assets it loads. Stable's URLs are left
+// as they are, so what browsers and proxies cached before any rollout is
+// still used.
+func (d *EditorLibraryDetail) TagAssets(variant string) {
+	if variant == StableBundle {
+		return
+	}
+	for i, u := range d.CSS {
+		d.CSS[i] = tagBundleURL(u, variant)
+	}
+	for i, u := range d.JavaScript {
+		d.JavaScript[i] = tagBundleURL(u, variant)
+	}
+}
+
+// tagBundleURL adds a bundle query parameter to an asset URL
+func tagBundleURL(assetURL, variant string) string {
+	sep := "?"
+	if strings.Contains(assetURL, "?") {
+		sep = "&"
+	}
+	return assetURL + sep + "bundle=" + url.QueryEscape(variant)
+}
+
+// RecordBundleEvent counts an editor load or error against the bundle the
+// editor loaded. Variants that were rolled back or deleted still count, as
+// open editors keep reporting against them.
+func (s *Service) RecordBundleEvent(ctx context.Context, variant, event string) error {
+	if variant != StableBundle && !bundleNamePattern.MatchString(variant) {
+		return pkg.BadRequestError{Message: "Invalid variant"}
+	}
+	arg := query.RecordH5PBundleReportParams{Variant: variant}
+	switch event {
+	case BundleEventLoad:
+		arg.Loads = 1
+	case BundleEventError:
+		arg.Errors = 1
+	default:
+		return pkg.BadRequestError{Message: fmt.Sprintf("event must be %q or %q", BundleEventLoad, BundleEventError)}
+	}
+	if err := s.store.RecordH5PBundleReport(ctx, arg); err != nil {
+		return pkg.InternalError{Message: "Error recording bundle report", Err: err}
+	}
+	return nil
+}
+
+// BundleReports compares each bundle's editor error rate with stable's over
+// the last days, listing stable first, then live variants and then any that
+// only have reports.
+func (s *Service) BundleReports(ctx context.Context, days int) ([]BundleReport, error) {
+	if days < 1 || days > MaxBundleReportDays {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("days must be 1 to %d", MaxBundleReportDays)}
+	}
+	variants, err := s.store.ListH5PBundleVariants(ctx)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing bundle variants", Err: err}
+	}
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1)).Truncate(24 * time.Hour)
+	totals, err := s.store.ListH5PBundleReportTotals(ctx, since)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading bundle reports", Err: err}
+	}
+
+	reports := []BundleReport{{Variant: StableBundle}}
+	index := map[string]int{StableBundle: 0}
+	for _, v := range variants {
+		percent := int(v.RolloutPercent)
+		index[v.Name] = len(reports)
+		reports = append(reports, BundleReport{Variant: v.Name, RolloutPercent: &percent})
+	}
+	for _, t := range totals {
+		i, ok := index[t.Variant]
+		if !ok {
+			i = len(reports)
+			index[t.Variant] = i
+			reports = append(reports, BundleReport{Variant: t.Variant})
+		}
+		reports[i].Loads, reports[i].Errors = t.Loads, t.Errors
+	}
+
+	for i := range reports {
+		if reports[i].Loads > 0 {
+			reports[i].ErrorRate = float64(reports[i].Errors) * 100 / float64(reports[i].Loads)
+		}
+	}
+	for i := range reports {
+		reports[i].ErrorRateDelta = reports[i].ErrorRate - reports[0].ErrorRate
+	}
+	return reports, nil
+}
@@ -2,6 +2,7 @@ package h5p
 
 import (
 	"app/pkg"
+	"app/pkg/ids"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -18,6 +19,9 @@ import (
 
 var slugRegexp = regexp.MustCompile(`[^a-z0-9]+`)
 
+// maxContentListLimit caps a single ListContent page.
+const maxContentListLimit = 200
+
 func generateSlug(title string) string {
 	slug := strings.ToLower(title)
 	slug = slugRegexp.ReplaceAllString(slug, "-")
@@ -28,14 +32,29 @@ func generateSlug(title string) string {
 	return slug
 }
 
+// checkNotLocked rejects edits to content made read-only by a grace-period lock.
+func checkNotLocked(content query.H5pContent) error {
+	if content.LockedAt.Valid {
+		return pkg.LockedError{Reason: content.LockReason.String}
+	}
+	return nil
+}
+
 // CreateContent creates a new H5P content item
 func (s *Service) CreateContent(ctx context.Context, orgID, userID uuid.UUID, libraryName, title string, contentJSON json.RawMessage) (*ContentInfo, error) {
 	lib, err := s.store.GetH5PLibraryByMachineName(ctx, libraryName)
 	if err != nil {
 		return nil, pkg.NotFoundError{Message: fmt.Sprintf("Library %s not found", libraryName), Err: err}
 	}
+	return s.createContent(ctx, orgID, userID, lib, title, contentJSON)
+}
 
-	contentID := uuid.New()
+// createContent creates a content item using a specific library version
+func (s *Service) createContent(ctx context.Context, orgID, userID uuid.UUID, lib query.H5pLibrary, title string, contentJSON json.RawMessage) (*ContentInfo, error) {
+	if err := s.checkLibraryAvailable(ctx, orgID, lib.MachineName); err != nil {
+		return nil, err
+	}
+	contentID := ids.New()
 	slug := generateSlug(title)
 	storagePath := fmt.Sprintf("h5p-content/%s/%s/", orgID, contentID)
 
@@ -73,6 +92,7 @@ func (s *Service) CreateContent(ctx context.Context, orgID, userID uuid.UUID, li
 		LibraryVersion: fmt.Sprintf("%d.%d.%d", lib.MajorVersion, lib.MinorVersion, lib.PatchVersion),
 		CreatedAt:      content.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		UpdatedAt:      content.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		LockReason:     content.LockReason.String,
 	}, nil
 }
 
@@ -103,6 +123,7 @@ func (s *Service) GetContent(ctx context.Context, contentID, orgID uuid.UUID) (*
 		LibraryVersion: fmt.Sprintf("%d.%d.%d", lib.MajorVersion, lib.MinorVersion, lib.PatchVersion),
 		CreatedAt:      content.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		UpdatedAt:      content.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		LockReason:     content.LockReason.String,
 	}, nil
 }
 
@@ -115,6 +136,26 @@ func (s *Service) UpdateContent(ctx context.Context, contentID, orgID uuid.UUID,
 		status = "draft"
 	}
 
+	current, err := s.store.GetH5PContent(ctx, query.GetH5PContentParams{
+		ID:    contentID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Content not found", Err: err}
+	}
+	if err := checkNotLocked(current); err != nil {
+		return nil, err
+	}
+	if status == "published" && current.Status != "published" {
+		params := contentJSON
+		if params == nil {
+			params = current.ContentJson
+		}
+		if err := s.checkPublishable(ctx, orgID, contentID, params); err != nil {
+			return nil, err
+		}
+	}
+
 	content, err := s.store.UpdateH5PContent(ctx, query.UpdateH5PContentParams{
 		ID:          contentID,
 		OrgID:       orgID,
@@ -127,6 +168,13 @@ func (s *Service) UpdateContent(ctx context.Context, contentID, orgID uuid.UUID,
 	if err != nil {
 		return nil, pkg.InternalError{Message: "Error updating content", Err: err}
 	}
+	if content.Status == "published" && current.Status != "published" {
+		s.publish(ctx, orgID, EventContentPublished, map[string]any{
+			"contentId": content.ID,
+			"title":     content.Title,
+			"slug":      content.Slug,
+		})
+	}
 
 	lib, err := s.store.GetH5PLibrary(ctx, content.LibraryID)
 	if err != nil {
@@ -145,6 +193,7 @@ func (s *Service) UpdateContent(ctx context.Context, contentID, orgID uuid.UUID,
 		LibraryVersion: fmt.Sprintf("%d.%d.%d", lib.MajorVersion, lib.MinorVersion, lib.PatchVersion),
 		CreatedAt:      content.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		UpdatedAt:      content.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		LockReason:     content.LockReason.String,
 	}, nil
 }
 
@@ -158,6 +207,14 @@ func (s *Service) DeleteContent(ctx context.Context, contentID, orgID uuid.UUID)
 
 // ListContent returns content items for an organisation with pagination
 func (s *Service) ListContent(ctx context.Context, orgID uuid.UUID, limit, offset int32) ([]ContentInfo, int64, error) {
+	// Soft limits so callers bypassing the REST validation can't pull the whole table
+	if limit <= 0 || limit > maxContentListLimit {
+		limit = maxContentListLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
 	rows, err := s.store.ListH5PContentByOrg(ctx, query.ListH5PContentByOrgParams{
 		OrgID:  orgID,
 		Limit:  limit,
@@ -186,6 +243,7 @@ func (s *Service) ListContent(ctx context.Context, orgID uuid.UUID, limit, offse
 			LibraryVersion: fmt.Sprintf("%d.%d.%d", row.LibraryMajor, row.LibraryMinor, row.LibraryPatch),
 			CreatedAt:      row.CreatedAt.Format("2006-01-02T15:04:05Z"),
 			UpdatedAt:      row.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+			LockReason:     row.LockReason.String,
 		})
 	}
 
@@ -238,26 +296,6 @@ func (s *Service) GetContentParams(ctx context.Context, contentID, orgID uuid.UU
 	}, nil
 }
 
-// GetContentFile downloads a file from content storage
-func (s *Service) GetContentFile(ctx context.Context, contentID, orgID uuid.UUID, filePath string) ([]byte, string, error) {
-	content, err := s.store.GetH5PContent(ctx, query.GetH5PContentParams{
-		ID:    contentID,
-		OrgID: orgID,
-	})
-	if err != nil {
-		return nil, "", pkg.NotFoundError{Message: "Content not found", Err: err}
-	}
-
-	key := fmt.Sprintf("h5p-content/%s/%s/%s", content.OrgID, content.ID, filePath)
-	data, err := s.fileProvider.Download(ctx, key)
-	if err != nil {
-		return nil, "", pkg.NotFoundError{Message: "File not found", Err: err}
-	}
-
-	contentType := detectContentType(filePath)
-	return data, contentType, nil
-}
-
 const tempFileSuffix = "#tmp"
 
 // migrateTempFiles scans params for temp file references (paths ending with #tmp),
@@ -341,13 +379,23 @@ func (s *Service) migrateTempFiles(ctx context.Context, orgID, contentID uuid.UU
 	return json.RawMessage(paramsStr), tempKeys, nil
 }
 
-// cleanupTempFiles removes temp files from R2 on a best-effort basis.
-func (s *Service) cleanupTempFiles(ctx context.Context, keys []string) {
+// jobCleanupTempFiles removes the temp files of saved content once they've
+// been copied to permanent storage. Its payload is the list of keys.
+const jobCleanupTempFiles = "h5p.cleanup_temp_files"
+
+// runCleanupTempFiles removes temp files from R2 on a best-effort basis:
+// files that can't be removed are logged and left behind, not retried.
+func (s *Service) runCleanupTempFiles(ctx context.Context, payload json.RawMessage) error {
+	var keys []string
+	if err := json.Unmarshal(payload, &keys); err != nil {
+		return fmt.Errorf("decoding temp file keys: %w", err)
+	}
 	for _, key := range keys {
 		if err := s.fileProvider.Remove(ctx, key); err != nil {
 			slog.Warn("Failed to remove temp file", "key", key, "error", err)
 		}
 	}
+	return nil
 }
 
 // SaveContentFromEditor saves content from the H5P editor, moving temp files to permanent storage
@@ -358,6 +406,19 @@ func (s *Service) SaveContentFromEditor(ctx context.Context, orgID, userID, cont
 		return nil, pkg.NotFoundError{Message: fmt.Sprintf("Library %s not found", libraryName), Err: err}
 	}
 
+	// Check if content already exists (update) or not (create)
+	existing, getErr := s.store.GetH5PContent(ctx, query.GetH5PContentParams{
+		ID:    contentID,
+		OrgID: orgID,
+	})
+	if getErr == nil {
+		if err := checkNotLocked(existing); err != nil {
+			return nil, err
+		}
+	} else if err := s.checkLibraryAvailable(ctx, orgID, lib.MachineName); err != nil {
+		return nil, err
+	}
+
 	// Migrate temp files to permanent storage BEFORE the DB save
 	// so the stored params always contain permanent paths.
 	savedParams, tempKeys, err := s.migrateTempFiles(ctx, orgID, contentID, params)
@@ -365,11 +426,14 @@ func (s *Service) SaveContentFromEditor(ctx context.Context, orgID, userID, cont
 		return nil, pkg.InternalError{Message: "Error migrating temp files", Err: err}
 	}
 
-	// Check if content already exists (update) or not (create)
-	existing, getErr := s.store.GetH5PContent(ctx, query.GetH5PContentParams{
-		ID:    contentID,
-		OrgID: orgID,
-	})
+	// Flag references to files that aren't stored, e.g. temp files that
+	// couldn't be migrated, so the editor can ask for them again
+	var dangling []string
+	if report, err := s.inspectFiles(ctx, orgID, contentID, savedParams); err != nil {
+		slog.Warn("Failed to check content file references", "contentID", contentID, "error", err)
+	} else if dangling = report.Dangling(); len(dangling) > 0 {
+		slog.Warn("Saved content references missing files", "contentID", contentID, "files", dangling)
+	}
 
 	storagePath := fmt.Sprintf("h5p-content/%s/%s/", orgID, contentID)
 
@@ -411,8 +475,10 @@ func (s *Service) SaveContentFromEditor(ctx context.Context, orgID, userID, cont
 	}
 
 	// Clean up temp files after successful save (best-effort)
-	if len(tempKeys) > 0 {
-		go s.cleanupTempFiles(context.Background(), tempKeys)
+	if len(tempKeys) > 0 && s.jobs != nil {
+		if _, err := s.jobs.Enqueue(ctx, jobCleanupTempFiles, tempKeys); err != nil {
+			slog.Warn("Failed to queue temp file cleanup", "error", err)
+		}
 	}
 
 	return &ContentInfo{
@@ -427,5 +493,20 @@ func (s *Service) SaveContentFromEditor(ctx context.Context, orgID, userID, cont
 		LibraryVersion: fmt.Sprintf("%d.%d.%d", lib.MajorVersion, lib.MinorVersion, lib.PatchVersion),
 		CreatedAt:      existing.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		UpdatedAt:      existing.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		LockReason:     existing.LockReason.String,
+		DanglingFiles:  dangling,
 	}, nil
 }
+
+// checkPublishable refuses to publish content whose parameters reference
+// files that aren't stored.
+func (s *Service) checkPublishable(ctx context.Context, orgID, contentID uuid.UUID, params json.RawMessage) error {
+	report, err := s.inspectFiles(ctx, orgID, contentID, params)
+	if err != nil {
+		return err
+	}
+	if dangling := report.Dangling(); len(dangling) > 0 {
+		return pkg.BadRequestError{Message: fmt.Sprintf("Content references %d missing files: %s", len(dangling), strings.Join(dangling, ", "))}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// maxDependencyDepth caps how many packages deep an install fetches missing
+// dependencies from the Hub. Packages bundle nearly all their dependencies, so
+// a chain this long means something is wrong with the Hub's packages.
+const maxDependencyDepth = 8
+
+// installRun tracks one library install and the dependency packages it
+// fetched from the Hub.
+type installRun struct {
+	orgID   uuid.UUID       // charged for the packages; uuid.Nil for platform installs
+	size    int64           // bytes of every package downloaded so far
+	fetched map[string]bool // machine names downloaded, so none is fetched twice
+	chain   []string        // packages being installed, outermost first
+}
+
+func newInstallRun(orgID uuid.UUID, machineName string, size int64) *installRun {
+	return &installRun{
+		orgID:   orgID,
+		size:    size,
+		fetched: map[string]bool{machineName: true},
+	}
+}
+
+// missingDependency is a dependency of an installed library whose version is
+// neither in its package nor installed.
+type missingDependency struct {
+	machineName  string
+	majorVersion int32
+	minorVersion int32
+	dependent    string
+	depType      string
+}
+
+// String names the dependency with the major.minor version it needs.
+func (d missingDependency) String() string {
+	return fmt.Sprintf("%s %d.%d", d.machineName, d.majorVersion, d.minorVersion)
+}
+
+// required reports whether a library can't run or be edited without the
+// dependency. Dynamic dependencies are only loaded when content asks for them.
+func (d missingDependency) required() bool {
+	return d.depType != "dynamic"
+}
+
+// MissingDependenciesError is returned when libraries depend on libraries that
+// aren't installed.
+type MissingDependenciesError struct {
+	Library      string
+	Dependencies []string
+}
+
+func (e MissingDependenciesError) Error() string {
+	return fmt.Sprintf("%s depends on libraries that are not installed: %s", e.Library, strings.Join(e.Dependencies, ", "))
+}
+
+// missingDependencies returns the dependencies of libs whose major.minor
+// version isn't installed, once per version. Another version of the same
+// library doesn't count: H5P loads exactly the version a library asks for.
+func (s *Service) missingDependencies(ctx context.Context, libs []LibraryJSON) ([]missingDependency, error) {
+	var missing []missingDependency
+	seen := make(map[string]bool)
+	for _, lj := range libs {
+		for _, group := range []struct {
+			deps    []LibraryDep
+			depType string
+		}{
+			{lj.PreloadedDependencies, "preloaded"},
+			{lj.EditorDependencies, "editor"},
+			{lj.DynamicDependencies, "dynamic"},
+		} {
+			for _, dep := range group.deps {
+				key := fmt.Sprintf("%s %d.%d", dep.MachineName, dep.MajorVersion, dep.MinorVersion)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				_, err := s.store.GetH5PLibraryByMachineNameMajorMinor(ctx, query.GetH5PLibraryByMachineNameMajorMinorParams{
+					MachineName:  dep.MachineName,
+					MajorVersion: int32(dep.MajorVersion),
+					MinorVersion: int32(dep.MinorVersion),
+				})
+				if err == nil {
+					continue
+				}
+				if !errors.Is(err, sql.ErrNoRows) {
+					return nil, pkg.InternalError{Message: "Error loading installed libraries", Err: err}
+				}
+				missing = append(missing, missingDependency{
+					machineName:  dep.MachineName,
+					majorVersion: int32(dep.MajorVersion),
+					minorVersion: int32(dep.MinorVersion),
+					dependent:    lj.MachineName,
+					depType:      group.depType,
+				})
+			}
+		}
+	}
+	return missing, nil
+}
+
+// resolveDependencies downloads the missing dependencies of libs from the Hub
+// and installs them, along with anything they're missing in turn. A missing
+// preloaded or editor dependency that can't be obtained fails the install;
+// a dynamic one is only logged.
+func (s *Service) resolveDependencies(ctx context.Context, run *installRun, libs []LibraryJSON) error {
+	missing, err := s.missingDependencies(ctx, libs)
+	if err != nil {
+		return err
+	}
+	for _, dep := range missing {
+		err := s.fetchDependency(ctx, run, dep)
+		if err == nil {
+			continue
+		}
+		if !dep.required() {
+			slog.Warn("Dynamic dependency could not be installed", "library", dep.dependent, "dep", dep.String(), "error", err)
+			continue
+		}
+		return err
+	}
+	return nil
+}
+
+// fetchDependency downloads one missing dependency's package from the Hub and
+// installs it, checking it provided the version needed. The Hub only serves
+// each library's latest version, so an older version that isn't bundled or
+// installed can't be obtained.
+func (s *Service) fetchDependency(ctx context.Context, run *installRun, dep missingDependency) error {
+	chain := strings.Join(run.chain, " → ") + " → " + dep.String()
+	fail := func(reason string, err error) error {
+		return pkg.InternalError{
+			Message: fmt.Sprintf("Dependency %s of %s could not be installed: %s (%s)", dep, dep.dependent, reason, chain),
+			Err:     err,
+		}
+	}
+	if run.fetched[dep.machineName] {
+		// Its package was installed earlier in this run without providing it
+		return fail("its package does not contain this version", nil)
+	}
+	if len(run.chain) >= maxDependencyDepth {
+		return fail(fmt.Sprintf("dependencies are nested more than %d packages deep", maxDependencyDepth), nil)
+	}
+	run.fetched[dep.machineName] = true
+
+	slog.Info("Fetching missing dependency from the Hub", "library", dep.dependent, "dep", dep.String(), "type", dep.depType)
+	data, err := s.hubClient.DownloadPackage(dep.machineName)
+	if err != nil {
+		return fail("it is not available from the H5P Hub", err)
+	}
+	if run.orgID != uuid.Nil {
+		if err := s.checkStorageQuota(ctx, run.orgID, run.size+int64(len(data))); err != nil {
+			return err
+		}
+	}
+	run.size += int64(len(data))
+
+	if _, err := s.installPackageRun(ctx, run, dep.machineName, data); err != nil {
+		return err
+	}
+	_, err = s.store.GetH5PLibraryByMachineNameMajorMinor(ctx, query.GetH5PLibraryByMachineNameMajorMinorParams{
+		MachineName:  dep.machineName,
+		MajorVersion: dep.majorVersion,
+		MinorVersion: dep.minorVersion,
+	})
+	if err != nil {
+		return fail("the H5P Hub's package does not contain this version", err)
+	}
+	return nil
+}
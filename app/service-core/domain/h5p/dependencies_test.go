@@ -0,0 +1,52 @@
+package h5p
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"service-core/storage/query"
+)
+
+// versionStore has only the libraries it's given installed.
+type versionStore struct {
+	store
+	libs []query.H5pLibrary
+}
+
+func (s *versionStore) GetH5PLibraryByMachineNameMajorMinor(_ context.Context, arg query.GetH5PLibraryByMachineNameMajorMinorParams) (query.H5pLibrary, error) {
+	for _, lib := range s.libs {
+		if lib.MachineName == arg.MachineName && lib.MajorVersion == arg.MajorVersion && lib.MinorVersion == arg.MinorVersion {
+			return lib, nil
+		}
+	}
+	return query.H5pLibrary{}, sql.ErrNoRows
+}
+
+// TestMissingDependenciesChecksVersion checks another installed version of a
+// library doesn't satisfy a dependency on it.
+func TestMissingDependenciesChecksVersion(t *testing.T) {
+	s := &Service{store: &versionStore{libs: []query.H5pLibrary{
+		{MachineName: "H5P.Foo", MajorVersion: 1, MinorVersion: 0},
+		{MachineName: "H5P.Bar", MajorVersion: 2, MinorVersion: 1},
+	}}}
+
+	missing, err := s.missingDependencies(context.Background(), []LibraryJSON{{
+		MachineName: "H5P.Baz",
+		PreloadedDependencies: []LibraryDep{
+			{MachineName: "H5P.Foo", MajorVersion: 1, MinorVersion: 2},
+			{MachineName: "H5P.Bar", MajorVersion: 2, MinorVersion: 1},
+		},
+		EditorDependencies: []LibraryDep{
+			{MachineName: "H5P.Foo", MajorVersion: 1, MinorVersion: 0},
+		},
+	}})
+	require.NoError(t, err)
+	require.Len(t, missing, 1)
+	assert.Equal(t, "H5P.Foo 1.2", missing[0].String())
+	assert.Equal(t, "H5P.Baz", missing[0].dependent)
+	assert.True(t, missing[0].required())
+}
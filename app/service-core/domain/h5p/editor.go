@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"service-core/storage/query"
+
+	"github.com/google/uuid"
 )
 
 // libraryJSONFull is the full library.json with CSS/JS asset paths
@@ -299,15 +301,15 @@ func (s *Service) GetEditorTranslations(ctx context.Context, libraryStrings []st
 	return translations, nil
 }
 
-// GetEditorContentTypeCache returns the content type cache in editor format
-func (s *Service) GetEditorContentTypeCache(ctx context.Context) (*IHubInfo, error) {
-	entries, err := s.GetContentTypeCache(ctx)
+// GetEditorContentTypeCache returns the organisation's content type cache in editor format
+func (s *Service) GetEditorContentTypeCache(ctx context.Context, orgID uuid.UUID) (*IHubInfo, error) {
+	entries, err := s.GetContentTypeCache(ctx, orgID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &IHubInfo{
-		APIVersion:   HubVersion{Major: 1, Minor: 26},
+		APIVersion:   coreAPIVersion,
 		Details:      []any{},
 		Libraries:    entries,
 		Outdated:     false,
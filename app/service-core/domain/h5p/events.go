@@ -0,0 +1,26 @@
+package h5p
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// H5P domain events published to organisation webhooks
+const (
+	EventContentPublished = "content.published"
+	EventLibraryInstalled = "h5p.library_installed"
+)
+
+// publisher delivers domain events to an organisation's registered webhooks
+type publisher interface {
+	Publish(ctx context.Context, organisationID uuid.UUID, eventType string, data map[string]any)
+}
+
+// publish sends an event to the organisation's webhooks, if any
+func (s *Service) publish(ctx context.Context, organisationID uuid.UUID, eventType string, data map[string]any) {
+	if s.publisher == nil {
+		return
+	}
+	s.publisher.Publish(ctx, organisationID, eventType, data)
+}
@@ -0,0 +1,148 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// FileReport compares the files content parameters reference with the files
+// stored for the content. Missing and unmigrated references are dangling: the
+// player would 404 on them.
+type FileReport struct {
+	ContentID  uuid.UUID `json:"contentId"`
+	Referenced int       `json:"referenced"`
+	// Missing are referenced files stored nowhere; they have to be uploaded
+	// again in the editor
+	Missing []string `json:"missing"`
+	// Unmigrated are references still pointing at temp storage. The temp file
+	// is there, so saving from the editor again moves it.
+	Unmigrated []string `json:"unmigrated"`
+	// Unreferenced are stored files the parameters don't use. Providers that
+	// can't list files never report any.
+	Unreferenced []string `json:"unreferenced"`
+}
+
+// Dangling returns the references the player would 404 on.
+func (r *FileReport) Dangling() []string {
+	return append(append([]string{}, r.Missing...), r.Unmigrated...)
+}
+
+// fileRefs returns the stored files content parameters reference: the path of
+// every file object, sorted. External URLs and data URIs aren't stored files.
+func fileRefs(params json.RawMessage) ([]string, error) {
+	var root any
+	if err := json.Unmarshal(params, &root); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var refs []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if path, ok := v["path"].(string); ok && isStoredPath(path) && !seen[path] {
+				seen[path] = true
+				refs = append(refs, path)
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(root)
+
+	sort.Strings(refs)
+	return refs, nil
+}
+
+func isStoredPath(path string) bool {
+	lower := strings.ToLower(path)
+	for _, prefix := range []string{"http://", "https://", "//", "data:"} {
+		if strings.HasPrefix(lower, prefix) {
+			return false
+		}
+	}
+	return path != ""
+}
+
+// inspectFiles checks every file params reference against the content's
+// storage prefix, and temp references against temp storage.
+func (s *Service) inspectFiles(ctx context.Context, orgID, contentID uuid.UUID, params json.RawMessage) (*FileReport, error) {
+	refs, err := fileRefs(params)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid content parameters"}
+	}
+
+	prefix := fmt.Sprintf("h5p-content/%s/%s/", orgID, contentID)
+	listed, err := s.fileProvider.ListByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing content files", Err: err}
+	}
+	stored := make(map[string]bool, len(listed))
+	for _, name := range listed {
+		stored[name] = true
+	}
+
+	report := &FileReport{
+		ContentID:    contentID,
+		Referenced:   len(refs),
+		Missing:      []string{},
+		Unmigrated:   []string{},
+		Unreferenced: []string{},
+	}
+	referenced := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		if rel, ok := strings.CutSuffix(ref, tempFileSuffix); ok {
+			if strings.Count(rel, "/") >= 2 && s.fileExists(ctx, "h5p-temp/"+rel) {
+				report.Unmigrated = append(report.Unmigrated, ref)
+			} else {
+				report.Missing = append(report.Missing, ref)
+			}
+			continue
+		}
+		referenced[ref] = true
+		if !stored[ref] && !s.fileExists(ctx, prefix+ref) {
+			report.Missing = append(report.Missing, ref)
+		}
+	}
+	for _, name := range listed {
+		if !referenced[name] {
+			report.Unreferenced = append(report.Unreferenced, name)
+		}
+	}
+	sort.Strings(report.Unreferenced)
+	return report, nil
+}
+
+// fileExists checks for a file the listing didn't include, which is every
+// file on providers that can't list.
+func (s *Service) fileExists(ctx context.Context, key string) bool {
+	_, err := s.fileProvider.Download(ctx, key)
+	return err == nil
+}
+
+// GetFileReport reports the content's dangling and unreferenced files so they
+// can be repaired before the content is played.
+func (s *Service) GetFileReport(ctx context.Context, contentID, orgID uuid.UUID) (*FileReport, error) {
+	content, err := s.store.GetH5PContent(ctx, query.GetH5PContentParams{
+		ID:    contentID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Content not found", Err: err}
+	}
+	return s.inspectFiles(ctx, orgID, contentID, content.ContentJson)
+}
@@ -0,0 +1,22 @@
+var H5P = H5P || {};
+
+/**
+ * Dev Note: renders its text. An offline Hub fixture, not a real content type.
+ */
+H5P.DevNote = (function ($) {
+  function DevNote(params, contentId) {
+    H5P.EventDispatcher.call(this);
+    this.params = $.extend({ text: '' }, params);
+    this.contentId = contentId;
+  }
+
+  DevNote.prototype = Object.create(H5P.EventDispatcher.prototype);
+  DevNote.prototype.constructor = DevNote;
+
+  DevNote.prototype.attach = function ($container) {
+    $container.addClass('h5p-dev-note').html(this.params.text);
+    this.triggerXAPICompleted(1, 1);
+  };
+
+  return DevNote;
+})(H5P.jQuery);
@@ -0,0 +1,37 @@
+var H5P = H5P || {};
+
+/**
+ * Dev True/False: a question answered with one of two buttons, reported as an
+ * xAPI "answered" statement. An offline Hub fixture, not a real content type.
+ */
+H5P.DevTrueFalse = (function ($) {
+  function DevTrueFalse(params, contentId) {
+    H5P.EventDispatcher.call(this);
+    this.params = $.extend({ question: '', correct: 'true' }, params);
+    this.contentId = contentId;
+  }
+
+  DevTrueFalse.prototype = Object.create(H5P.EventDispatcher.prototype);
+  DevTrueFalse.prototype.constructor = DevTrueFalse;
+
+  DevTrueFalse.prototype.attach = function ($container) {
+    var self = this;
+    $container.addClass('h5p-dev-styles h5p-dev-true-false');
+    $('<div class="h5p-dev-question"></div>').html(self.params.question).appendTo($container);
+
+    ['true', 'false'].forEach(function (value) {
+      $('<button type="button" class="h5p-dev-button"></button>')
+        .text(value === 'true' ? 'True' : 'False')
+        .on('click', function () {
+          var score = value === self.params.correct ? 1 : 0;
+          var event = self.createXAPIEventTemplate('answered');
+          event.setScoredResult(score, 1, self, true, score === 1);
+          self.trigger(event);
+          $container.find('.h5p-dev-button').prop('disabled', true);
+        })
+        .appendTo($container);
+    });
+  };
+
+  return DevTrueFalse;
+})(H5P.jQuery);
@@ -1,6 +1,7 @@
 package h5p
 
 import (
+	"app/pkg/httprecord"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,12 +13,14 @@ import (
 )
 
 const (
-	defaultHubURL    = "https://hub-api.h5p.org"
-	fallbackHubURL   = "https://api.h5p.org"
-	hubContentTypes  = "/v1/content-types/"
-	hubCacheTTL      = 24 * time.Hour
-	hubCacheKey      = "content-type-cache"
-	downloadTimeout  = 5 * time.Minute
+	defaultHubURL   = "https://hub-api.h5p.org"
+	fallbackHubURL  = "https://api.h5p.org"
+	hubContentTypes = "/v1/content-types/"
+	hubCacheTTL     = 24 * time.Hour
+	hubCacheKey     = "content-type-cache"
+	hubMetadata     = "/v1/metadata"
+	hubMetadataKey  = "content-hub-metadata"
+	downloadTimeout = 5 * time.Minute
 )
 
 // HubClient handles communication with the H5P Hub API.
@@ -35,20 +38,31 @@ func NewHubClient(hubURL string) *HubClient {
 	return &HubClient{
 		hubURL: hubURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: httprecord.Transport("h5p-hub", nil),
 		},
 	}
 }
 
 // hubURLs returns the primary URL followed by the fallback (if different).
+// Offline clients have no fallback.
 func (c *HubClient) hubURLs() []string {
 	urls := []string{c.hubURL}
-	if c.hubURL != fallbackHubURL {
+	if c.hubURL != fallbackHubURL && c.hubURL != offlineHubURL {
 		urls = append(urls, fallbackHubURL)
 	}
 	return urls
 }
 
+// cacheKey keeps hub data cached in offline mode apart from the real Hub's,
+// so switching modes doesn't serve content types the client can't install.
+func (c *HubClient) cacheKey(key string) string {
+	if c.hubURL == offlineHubURL {
+		return key + ":offline"
+	}
+	return key
+}
+
 // FetchContentTypes fetches the content type list from the H5P Hub.
 // Tries the primary hub URL first, falls back to the legacy URL on failure.
 func (c *HubClient) FetchContentTypes() (*HubResponse, error) {
@@ -102,6 +116,46 @@ func (c *HubClient) fetchContentTypesFrom(baseURL string, form url.Values) (*Hub
 	return &hubResp, nil
 }
 
+// FetchContentHubMetadata fetches the levels, disciplines, languages and licenses
+// used by the content hub browser. Tries the primary hub URL first, falls back
+// to the legacy URL on failure.
+func (c *HubClient) FetchContentHubMetadata() (*ContentHubMetadata, error) {
+	var lastErr error
+	for _, baseURL := range c.hubURLs() {
+		resp, err := c.fetchContentHubMetadataFrom(baseURL)
+		if err != nil {
+			log.Printf("[h5p-hub] FetchContentHubMetadata failed for %s: %v", baseURL, err)
+			lastErr = err
+			continue
+		}
+		if baseURL != c.hubURL {
+			log.Printf("[h5p-hub] FetchContentHubMetadata succeeded via fallback %s", baseURL)
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("all hub URLs failed, last error: %w", lastErr)
+}
+
+func (c *HubClient) fetchContentHubMetadataFrom(baseURL string) (*ContentHubMetadata, error) {
+	resp, err := c.httpClient.Get(baseURL + hubMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("fetching metadata from hub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("hub returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var metadata ContentHubMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("decoding hub metadata: %w", err)
+	}
+
+	return &metadata, nil
+}
+
 // DownloadPackage downloads an .h5p package for a given machine name.
 // Tries the primary hub URL first, falls back to the legacy URL on failure.
 func (c *HubClient) DownloadPackage(machineName string) ([]byte, error) {
@@ -122,7 +176,7 @@ func (c *HubClient) DownloadPackage(machineName string) ([]byte, error) {
 }
 
 func (c *HubClient) downloadPackageFrom(baseURL string, machineName string) ([]byte, error) {
-	client := &http.Client{Timeout: downloadTimeout}
+	client := &http.Client{Timeout: downloadTimeout, Transport: c.httpClient.Transport}
 
 	downloadURL := fmt.Sprintf("%s/v1/content-types/%s", baseURL, machineName)
 	resp, err := client.Get(downloadURL)
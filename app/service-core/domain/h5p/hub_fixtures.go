@@ -0,0 +1,119 @@
+package h5p
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// offlineHubURL is the hub URL in offline mode. Requests never leave the
+// process; the fixture transport answers them.
+const offlineHubURL = "http://h5p-hub.offline"
+
+// hubFixtures is a stand-in H5P Hub for offline development and CI: the
+// content type registry, the content hub metadata and one directory per
+// package, zipped into an .h5p file when it's downloaded.
+//
+//go:embed fixtures/hub
+var hubFixtures embed.FS
+
+// NewOfflineHubClient creates a Hub client that serves the embedded fixtures
+// instead of calling the H5P Hub, so content types can be listed and
+// installed without network access.
+func NewOfflineHubClient() *HubClient {
+	return &HubClient{
+		hubURL: offlineHubURL,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: hubFixtureTransport{},
+		},
+	}
+}
+
+// hubFixtureTransport answers Hub API requests from hubFixtures, whatever
+// their host.
+type hubFixtureTransport struct{}
+
+func (hubFixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	var data []byte
+	var err error
+	contentType := "application/json"
+	switch p := req.URL.Path; {
+	case req.Method == http.MethodPost && p == hubContentTypes:
+		data, err = hubFixtures.ReadFile("fixtures/hub/content-types.json")
+	case req.Method == http.MethodGet && p == hubMetadata:
+		data, err = hubFixtures.ReadFile("fixtures/hub/metadata.json")
+	case req.Method == http.MethodGet && strings.HasPrefix(p, hubContentTypes):
+		data, err = fixturePackage(strings.TrimPrefix(p, hubContentTypes))
+		contentType = "application/zip"
+	default:
+		err = fs.ErrNotExist
+	}
+
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusNotFound
+		data = []byte(err.Error())
+		contentType = "text/plain"
+	}
+	return &http.Response{
+		Status:        http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {contentType}},
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// fixturePackage zips a fixture package directory as the Hub would serve it
+func fixturePackage(machineName string) ([]byte, error) {
+	if machineName == "" || strings.ContainsAny(machineName, "/\\") || strings.HasPrefix(machineName, ".") {
+		return nil, fs.ErrNotExist
+	}
+	dir, err := fs.Sub(hubFixtures, "fixtures/hub/packages/"+machineName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(dir, "h5p.json"); err != nil {
+		return nil, fmt.Errorf("no fixture package for %s: %w", machineName, err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	err = fs.WalkDir(dir, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(dir, name)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("zipping fixture package %s: %w", machineName, err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package h5p
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOfflineHubClient checks every content type the offline registry lists
+// can be downloaded and extracted, with its dependencies in the package.
+func TestOfflineHubClient(t *testing.T) {
+	client := NewOfflineHubClient()
+
+	registry, err := client.FetchContentTypes()
+	require.NoError(t, err)
+	require.NotEmpty(t, registry.ContentTypes)
+
+	metadata, err := client.FetchContentHubMetadata()
+	require.NoError(t, err)
+	assert.True(t, json.Valid(metadata.Licenses))
+
+	for _, ct := range registry.ContentTypes {
+		t.Run(ct.ID, func(t *testing.T) {
+			data, err := client.DownloadPackage(ct.ID)
+			require.NoError(t, err)
+			pkg, err := ExtractH5PPackage(data)
+			require.NoError(t, err)
+
+			libs := make(map[string]ExtractedLibrary)
+			for _, lib := range pkg.Libraries {
+				libs[lib.LibraryJSON.MachineName] = lib
+			}
+			main, ok := libs[ct.ID]
+			require.True(t, ok, "package is missing its main library")
+			assert.EqualValues(t, 1, main.LibraryJSON.Runnable)
+			assert.EqualValues(t, ct.Version.Major, main.LibraryJSON.MajorVersion)
+			assert.EqualValues(t, ct.Version.Minor, main.LibraryJSON.MinorVersion)
+			assert.True(t, json.Valid(main.Files["semantics.json"]), "semantics.json")
+
+			for _, lib := range pkg.Libraries {
+				for _, dep := range lib.LibraryJSON.PreloadedDependencies {
+					assert.Contains(t, libs, dep.MachineName, "%s depends on a library the package doesn't carry", lib.LibraryJSON.MachineName)
+				}
+				for _, asset := range append(lib.LibraryJSON.PreloadedJs, lib.LibraryJSON.PreloadedCss...) {
+					assert.Contains(t, lib.Files, asset.Path, "%s preloads a missing file", lib.LibraryJSON.MachineName)
+				}
+			}
+		})
+	}
+
+	_, err = client.DownloadPackage("H5P.NotAFixture")
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"encoding/json"
+	"log/slog"
+)
+
+type hubLicenseVersion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// hubLicense is a license offered by the content hub browser.
+// Versions MUST always be an array — the hub client calls license.versions.length.
+type hubLicense struct {
+	ID       string              `json:"id"`
+	Name     string              `json:"name"`
+	Versions []hubLicenseVersion `json:"versions"`
+}
+
+type hubLevel struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+var (
+	ccLicenseVersions = []hubLicenseVersion{
+		{ID: "4.0", Name: "4.0 International", URL: "https://creativecommons.org/licenses/{{id}}/4.0/"},
+		{ID: "3.0", Name: "3.0 Unported", URL: "https://creativecommons.org/licenses/{{id}}/3.0/"},
+		{ID: "2.5", Name: "2.5 Generic", URL: "https://creativecommons.org/licenses/{{id}}/2.5/"},
+		{ID: "2.0", Name: "2.0 Generic", URL: "https://creativecommons.org/licenses/{{id}}/2.0/"},
+		{ID: "1.0", Name: "1.0 Generic", URL: "https://creativecommons.org/licenses/{{id}}/1.0/"},
+	}
+	gplLicenseVersions = []hubLicenseVersion{
+		{ID: "v3", Name: "Version 3", URL: "https://www.gnu.org/licenses/gpl-3.0.html"},
+		{ID: "v2", Name: "Version 2", URL: "https://www.gnu.org/licenses/gpl-2.0.html"},
+		{ID: "v1", Name: "Version 1", URL: "https://www.gnu.org/licenses/gpl-1.0.html"},
+	}
+
+	// fallbackHubLicenses are used when the hub metadata has no licenses.
+	fallbackHubLicenses = []hubLicense{
+		{ID: "MIT", Name: "MIT License", Versions: []hubLicenseVersion{}},
+		{ID: "CC BY", Name: "Creative Commons Attribution", Versions: ccLicenseVersions},
+		{ID: "CC BY-SA", Name: "Creative Commons Attribution-ShareAlike", Versions: ccLicenseVersions},
+		{ID: "CC BY-ND", Name: "Creative Commons Attribution-NoDerivatives", Versions: ccLicenseVersions},
+		{ID: "CC BY-NC", Name: "Creative Commons Attribution-NonCommercial", Versions: ccLicenseVersions},
+		{ID: "CC BY-NC-SA", Name: "Creative Commons Attribution-NonCommercial-ShareAlike", Versions: ccLicenseVersions},
+		{ID: "CC BY-NC-ND", Name: "Creative Commons Attribution-NonCommercial-NoDerivatives", Versions: ccLicenseVersions},
+		{ID: "CC0 1.0", Name: "CC0 1.0 Universal", Versions: []hubLicenseVersion{}},
+		{ID: "GNU GPL", Name: "GNU General Public License", Versions: gplLicenseVersions},
+		{ID: "PD", Name: "Public Domain", Versions: []hubLicenseVersion{}},
+		{ID: "ODC PDDL", Name: "Public Domain Dedication and Licence", Versions: []hubLicenseVersion{}},
+		{ID: "CC PDM", Name: "Public Domain Mark", Versions: []hubLicenseVersion{}},
+		{ID: "U", Name: "Undisclosed", Versions: []hubLicenseVersion{}},
+		{ID: "C", Name: "Copyright", Versions: []hubLicenseVersion{}},
+	}
+
+	// fallbackHubLevels are used when the hub metadata has no levels.
+	fallbackHubLevels = []hubLevel{
+		{ID: "beginner", Label: "Beginner"},
+		{ID: "intermediate", Label: "Intermediate"},
+		{ID: "advanced", Label: "Advanced"},
+	}
+)
+
+// GetContentHubMetadata returns the content hub metadata, refreshing from the Hub
+// when the cache has expired. If the Hub is unreachable the local fallbacks are
+// served so the editor's hub browser still works.
+func (s *Service) GetContentHubMetadata(ctx context.Context) *ContentHubMetadata {
+	cached, err := s.store.GetH5PHubCache(ctx, s.hubClient.cacheKey(hubMetadataKey))
+	if err == nil {
+		var metadata ContentHubMetadata
+		if err := json.Unmarshal(cached.Data, &metadata); err == nil {
+			return withHubMetadataFallbacks(&metadata)
+		}
+	}
+
+	metadata, err := s.RefreshContentHubMetadata(ctx)
+	if err != nil {
+		slog.Warn("Serving fallback content hub metadata", "error", err)
+		return withHubMetadataFallbacks(&ContentHubMetadata{})
+	}
+	return metadata
+}
+
+// RefreshContentHubMetadata fetches the metadata from the Hub, bypassing and
+// replacing the cached copy.
+func (s *Service) RefreshContentHubMetadata(ctx context.Context) (*ContentHubMetadata, error) {
+	slog.Info("Fetching content hub metadata from H5P Hub")
+	metadata, err := s.hubClient.FetchContentHubMetadata()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error fetching content hub metadata", Err: err}
+	}
+
+	s.cacheHubData(ctx, s.hubClient.cacheKey(hubMetadataKey), metadata)
+	return withHubMetadataFallbacks(metadata), nil
+}
+
+// withHubMetadataFallbacks fills lists the hub left out: our license and level
+// constants, and empty arrays for disciplines and languages.
+func withHubMetadataFallbacks(m *ContentHubMetadata) *ContentHubMetadata {
+	if isEmptyJSONList(m.Licenses) {
+		m.Licenses, _ = json.Marshal(fallbackHubLicenses)
+	}
+	if isEmptyJSONList(m.Levels) {
+		m.Levels, _ = json.Marshal(fallbackHubLevels)
+	}
+	if isEmptyJSONList(m.Disciplines) {
+		m.Disciplines = json.RawMessage(`[]`)
+	}
+	if isEmptyJSONList(m.Languages) {
+		m.Languages = json.RawMessage(`[]`)
+	}
+	return m
+}
+
+// isEmptyJSONList reports whether raw is missing, null or an empty array.
+func isEmptyJSONList(raw json.RawMessage) bool {
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return true
+	}
+	return len(list) == 0
+}
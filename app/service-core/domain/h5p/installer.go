@@ -38,12 +38,13 @@ func (fi *FlexInt) UnmarshalJSON(data []byte) error {
 
 // H5PManifest represents the h5p.json file inside an .h5p package
 type H5PManifest struct {
-	Title          string              `json:"title"`
-	MachineName    string              `json:"machineName,omitempty"`
-	MajorVersion   FlexInt             `json:"majorVersion"`
-	MinorVersion   FlexInt             `json:"minorVersion"`
-	PatchVersion   FlexInt             `json:"patchVersion"`
-	Runnable       FlexInt             `json:"runnable,omitempty"`
+	Title                 string       `json:"title"`
+	MachineName           string       `json:"machineName,omitempty"`
+	MainLibrary           string       `json:"mainLibrary,omitempty"`
+	MajorVersion          FlexInt      `json:"majorVersion"`
+	MinorVersion          FlexInt      `json:"minorVersion"`
+	PatchVersion          FlexInt      `json:"patchVersion"`
+	Runnable              FlexInt      `json:"runnable,omitempty"`
 	PreloadedDependencies []LibraryDep `json:"preloadedDependencies,omitempty"`
 	DynamicDependencies   []LibraryDep `json:"dynamicDependencies,omitempty"`
 	EditorDependencies    []LibraryDep `json:"editorDependencies,omitempty"`
@@ -56,20 +57,27 @@ type AssetPath struct {
 
 // LibraryJSON represents a library.json file inside an .h5p package
 type LibraryJSON struct {
-	Title          string       `json:"title"`
-	MachineName    string       `json:"machineName"`
-	MajorVersion   FlexInt      `json:"majorVersion"`
-	MinorVersion   FlexInt      `json:"minorVersion"`
-	PatchVersion   FlexInt      `json:"patchVersion"`
-	Runnable       FlexInt      `json:"runnable"`
-	Description    string       `json:"description,omitempty"`
-	PreloadedCss   []AssetPath  `json:"preloadedCss,omitempty"`
-	PreloadedJs    []AssetPath  `json:"preloadedJs,omitempty"`
+	Title                 string       `json:"title"`
+	MachineName           string       `json:"machineName"`
+	MajorVersion          FlexInt      `json:"majorVersion"`
+	MinorVersion          FlexInt      `json:"minorVersion"`
+	PatchVersion          FlexInt      `json:"patchVersion"`
+	Runnable              FlexInt      `json:"runnable"`
+	Description           string       `json:"description,omitempty"`
+	CoreAPI               *CoreAPI     `json:"coreApi,omitempty"`
+	PreloadedCss          []AssetPath  `json:"preloadedCss,omitempty"`
+	PreloadedJs           []AssetPath  `json:"preloadedJs,omitempty"`
 	PreloadedDependencies []LibraryDep `json:"preloadedDependencies,omitempty"`
 	DynamicDependencies   []LibraryDep `json:"dynamicDependencies,omitempty"`
 	EditorDependencies    []LibraryDep `json:"editorDependencies,omitempty"`
 }
 
+// CoreAPI is the H5P core API version a library needs
+type CoreAPI struct {
+	MajorVersion FlexInt `json:"majorVersion"`
+	MinorVersion FlexInt `json:"minorVersion"`
+}
+
 // LibraryDep represents a dependency reference in h5p.json or library.json
 type LibraryDep struct {
 	MachineName  string  `json:"machineName"`
@@ -86,8 +94,10 @@ type ExtractedLibrary struct {
 
 // ExtractedPackage represents the full result of extracting an .h5p file
 type ExtractedPackage struct {
-	Manifest  H5PManifest
-	Libraries []ExtractedLibrary
+	Manifest     H5PManifest
+	ManifestJSON json.RawMessage // h5p.json as packaged
+	Libraries    []ExtractedLibrary
+	Content      map[string][]byte // content/ directory: relative path -> file content
 }
 
 // ExtractH5PPackage opens a .h5p zip and extracts the manifest + all libraries
@@ -145,7 +155,9 @@ func ExtractH5PPackage(data []byte) (*ExtractedPackage, error) {
 		if err := json.Unmarshal(manifestData, &result.Manifest); err != nil {
 			return nil, fmt.Errorf("parsing h5p.json: %w", err)
 		}
+		result.ManifestJSON = manifestData
 	}
+	result.Content = dirFiles["content"]
 
 	// Parse each library directory
 	for dirName, files := range dirFiles {
@@ -0,0 +1,126 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+
+	"service-core/storage/query"
+)
+
+const (
+	extractedLibrariesPrefix = "h5p-libraries/extracted/"
+	libraryPackagesPrefix    = "h5p-libraries/packages/"
+	// orphanGracePeriod is how long orphaned library files wait before the
+	// sweeper removes them. An install uploads its files before saving the
+	// library, and saving it cancels the deletion.
+	orphanGracePeriod = time.Hour
+)
+
+// LibraryDeletion is what deleting a library removes: its versions and the
+// storage keys queued for the sweeper. Directories end in "/". A dry run
+// deletes nothing and lists the stored files under the keys instead.
+type LibraryDeletion struct {
+	MachineName string       `json:"machineName"`
+	Deleted     bool         `json:"deleted"`
+	Versions    []HubVersion `json:"versions"`
+	StorageKeys []string     `json:"storageKeys"`
+	Files       []string     `json:"files,omitempty"`
+}
+
+// OrphanedLibraryFiles reports a reconcile of library storage against the
+// installed libraries. Directories end in "/".
+type OrphanedLibraryFiles struct {
+	DryRun      bool     `json:"dryRun"`
+	StorageKeys []string `json:"storageKeys"`
+	Files       int      `json:"files"`  // stored files under the keys
+	Queued      int64    `json:"queued"` // keys newly queued for the sweeper
+}
+
+// libraryStorageKeys are the extracted directory and package of a library
+// version.
+func libraryStorageKeys(machineName string, major, minor, patch int) []string {
+	return []string{
+		LibraryStorageKey(machineName, major, minor, patch, "") + "/",
+		PackageStorageKey(machineName, major, minor, patch),
+	}
+}
+
+// listStoredFiles returns the stored files under keys. A key not ending in
+// "/" is a single file, listed if it exists.
+func (s *Service) listStoredFiles(ctx context.Context, keys []string) ([]string, error) {
+	files := make([]string, 0)
+	for _, key := range keys {
+		names, err := s.fileProvider.ListByPrefix(ctx, key)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error listing library files", Err: err}
+		}
+		for _, name := range names {
+			if strings.HasSuffix(key, "/") || name == "" {
+				files = append(files, key+name)
+			}
+		}
+	}
+	return files, nil
+}
+
+// ReconcileLibraryStorage finds extracted directories and packages in storage
+// that no installed library version owns, left by failed installs or deletes
+// from before deletions were queued, and queues them for the sweeper. A dry
+// run only reports them.
+func (s *Service) ReconcileLibraryStorage(ctx context.Context, dryRun bool) (*OrphanedLibraryFiles, error) {
+	versions, err := s.store.ListH5PLibraryVersions(ctx, sql.NullString{})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing installed libraries", Err: err}
+	}
+	owned := make(map[string]bool, 2*len(versions))
+	for _, v := range versions {
+		for _, key := range libraryStorageKeys(v.MachineName, int(v.MajorVersion), int(v.MinorVersion), int(v.PatchVersion)) {
+			owned[key] = true
+		}
+		if v.PackagePath.Valid {
+			owned[v.PackagePath.String] = true
+		}
+	}
+
+	report := &OrphanedLibraryFiles{DryRun: dryRun, StorageKeys: make([]string, 0)}
+	orphaned := make(map[string]bool)
+	for _, prefix := range []string{extractedLibrariesPrefix, libraryPackagesPrefix} {
+		names, err := s.fileProvider.ListByPrefix(ctx, prefix)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error listing library files", Err: err}
+		}
+		for _, name := range names {
+			// Extracted files are owned by their version's directory
+			key := prefix + name
+			if dir, _, ok := strings.Cut(name, "/"); ok {
+				key = prefix + dir + "/"
+			}
+			if owned[key] {
+				continue
+			}
+			if !orphaned[key] {
+				orphaned[key] = true
+				report.StorageKeys = append(report.StorageKeys, key)
+			}
+			report.Files++
+		}
+	}
+	if dryRun || len(report.StorageKeys) == 0 {
+		return report, nil
+	}
+
+	// Versions installed since the listing are skipped by the query
+	report.Queued, err = s.store.QueueOrphanedH5PLibraryFiles(ctx, query.QueueOrphanedH5PLibraryFilesParams{
+		NextAttemptAt: time.Now().Add(orphanGracePeriod),
+		StorageKeys:   report.StorageKeys,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error queuing orphaned library files", Err: err}
+	}
+	slog.Info("Orphaned library files queued for deletion", "keys", len(report.StorageKeys), "files", report.Files, "queued", report.Queued)
+	return report, nil
+}
@@ -0,0 +1,170 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Outcomes of updating a library from the Hub
+const (
+	LibraryUpdateUpdated  = "updated"
+	LibraryUpdateUpToDate = "up_to_date"
+	LibraryUpdateSkipped  = "skipped"
+	LibraryUpdateFailed   = "failed"
+)
+
+// LibraryUpdate is the outcome of updating one library to its Hub version.
+type LibraryUpdate struct {
+	MachineName string     `json:"machineName"`
+	Title       string     `json:"title"`
+	From        HubVersion `json:"from"`
+	To          HubVersion `json:"to"`
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason,omitempty"` // why it was skipped or failed
+}
+
+// LibraryUpdateSummary is the outcome of updating every installed library.
+type LibraryUpdateSummary struct {
+	Updated   int             `json:"updated"`
+	UpToDate  int             `json:"upToDate"`
+	Skipped   int             `json:"skipped"`
+	Failed    int             `json:"failed"`
+	Libraries []LibraryUpdate `json:"libraries"` // all but the up-to-date ones
+}
+
+// newerThan reports whether v is a later version than other.
+func (v HubVersion) newerThan(other HubVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch > other.Patch
+}
+
+// UpdateLibrary installs the Hub's newer version of an installed library.
+// Versions are installed side by side, so the old version and the content on
+// it are left alone until the content is moved over with the content upgrade
+// pipeline.
+func (s *Service) UpdateLibrary(ctx context.Context, machineName string) (*LibraryUpdate, error) {
+	hubData, err := s.getCachedHubData(ctx)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error fetching content type cache", Err: err}
+	}
+	for _, ct := range hubData.ContentTypes {
+		if ct.ID == machineName {
+			return s.updateLibrary(ctx, ct)
+		}
+	}
+	return nil, pkg.NotFoundError{Message: fmt.Sprintf("%s is not available from the H5P Hub", machineName)}
+}
+
+// UpdateAllLibraries updates every installed content type the Hub has a newer
+// version of. A library that fails to update doesn't stop the others.
+func (s *Service) UpdateAllLibraries(ctx context.Context) (*LibraryUpdateSummary, error) {
+	hubData, err := s.getCachedHubData(ctx)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error fetching content type cache", Err: err}
+	}
+
+	summary := &LibraryUpdateSummary{Libraries: make([]LibraryUpdate, 0)}
+	for _, ct := range hubData.ContentTypes {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		update, err := s.updateLibrary(ctx, ct)
+		var notFound pkg.NotFoundError
+		if errors.As(err, &notFound) {
+			continue // not installed
+		}
+		if err != nil {
+			update = &LibraryUpdate{MachineName: ct.ID, Title: ct.Title, To: ct.Version, Status: LibraryUpdateFailed, Reason: updateErrorMessage(err)}
+		}
+		switch update.Status {
+		case LibraryUpdateUpdated:
+			summary.Updated++
+		case LibraryUpdateUpToDate:
+			summary.UpToDate++
+			continue
+		case LibraryUpdateSkipped:
+			summary.Skipped++
+		case LibraryUpdateFailed:
+			summary.Failed++
+		}
+		summary.Libraries = append(summary.Libraries, *update)
+	}
+	slog.Info("H5P libraries updated from the Hub",
+		"updated", summary.Updated, "upToDate", summary.UpToDate, "skipped", summary.Skipped, "failed", summary.Failed)
+	return summary, nil
+}
+
+// updateLibrary installs ct's Hub version if it's newer than the latest one
+// installed. A failed install is reported in the update rather than returned.
+func (s *Service) updateLibrary(ctx context.Context, ct HubContentType) (*LibraryUpdate, error) {
+	lib, err := s.store.GetH5PLibraryByMachineName(ctx, ct.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: fmt.Sprintf("%s is not installed", ct.ID), Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading installed library", Err: err}
+	}
+
+	update := &LibraryUpdate{
+		MachineName: ct.ID,
+		Title:       lib.Title,
+		From:        libraryVersion(lib),
+		To:          ct.Version,
+		Status:      LibraryUpdateUpToDate,
+	}
+	if !ct.Version.newerThan(update.From) {
+		update.To = update.From
+		return update, nil
+	}
+	if ct.CoreAPIVersionNeeded.newerThan(coreAPIVersion) {
+		update.Status = LibraryUpdateSkipped
+		update.Reason = fmt.Sprintf("version %d.%d.%d needs H5P core API %d.%d; this platform supports %d.%d",
+			ct.Version.Major, ct.Version.Minor, ct.Version.Patch,
+			ct.CoreAPIVersionNeeded.Major, ct.CoreAPIVersionNeeded.Minor, coreAPIVersion.Major, coreAPIVersion.Minor)
+		return update, nil
+	}
+
+	slog.Info("Updating H5P library", "machineName", ct.ID, "from", update.From, "to", ct.Version)
+	installed, err := s.InstallLibrary(ctx, ct.ID)
+	if err != nil {
+		update.Status = LibraryUpdateFailed
+		update.Reason = updateErrorMessage(err)
+		slog.Error("Failed to update H5P library", "machineName", ct.ID, "error", err)
+		return update, nil
+	}
+	update.Status = LibraryUpdateUpdated
+	update.Title = installed.Title
+	update.To = HubVersion{Major: int(installed.MajorVersion), Minor: int(installed.MinorVersion), Patch: int(installed.PatchVersion)}
+	return update, nil
+}
+
+// updateErrorMessage is the reason reported for a failed update. Messages
+// are meant for the admin; wrapped errors are only logged.
+func updateErrorMessage(err error) string {
+	var (
+		badRequest pkg.BadRequestError
+		notFound   pkg.NotFoundError
+		internal   pkg.InternalError
+		missing    MissingDependenciesError
+	)
+	switch {
+	case errors.As(err, &missing):
+		return missing.Error()
+	case errors.As(err, &badRequest):
+		return badRequest.Message
+	case errors.As(err, &notFound):
+		return notFound.Message
+	case errors.As(err, &internal):
+		return internal.Message
+	}
+	return "Internal error"
+}
@@ -0,0 +1,337 @@
+package h5p
+
+import (
+	"app/pkg"
+	"app/pkg/ids"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"service-core/domain/file"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// jobAddMediaAsset adds an editor upload to its organisation's media library.
+// Its payload is a mediaUpload.
+const jobAddMediaAsset = "h5p.add_media_asset"
+
+const (
+	// DefaultMediaPageSize and MaxMediaPageSize bound a media library page
+	DefaultMediaPageSize = 40
+	MaxMediaPageSize     = 100
+)
+
+// Media asset kinds, from the MIME type
+const (
+	MediaKindImage = "image"
+	MediaKindVideo = "video"
+	MediaKindAudio = "audio"
+	MediaKindFile  = "file"
+)
+
+// MediaAsset is a file in an organisation's media library
+type MediaAsset struct {
+	ID           uuid.UUID  `json:"id"`
+	Filename     string     `json:"filename"`
+	Mime         string     `json:"mime"`
+	Kind         string     `json:"kind"`
+	Size         int64      `json:"size"`
+	Width        int        `json:"width,omitempty"`
+	Height       int        `json:"height,omitempty"`
+	ThumbnailURL string     `json:"thumbnailUrl,omitempty"`
+	UploadedBy   *uuid.UUID `json:"uploadedBy"`
+	UseCount     int32      `json:"useCount"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastUsedAt   time.Time  `json:"lastUsedAt"`
+}
+
+// MediaFilter selects a page of the media library, newest first
+type MediaFilter struct {
+	Kind   string    // one of the MediaKind constants; empty for all
+	Search string    // part of the filename
+	Before uuid.UUID // the last asset of the previous page; uuid.Nil for the first
+	Limit  int
+}
+
+// mediaUpload is an editor upload waiting to be added to the media library
+type mediaUpload struct {
+	OrgID    uuid.UUID `json:"orgId"`
+	UserID   uuid.UUID `json:"userId"`
+	TempKey  string    `json:"tempKey"`
+	Filename string    `json:"filename"`
+	Mime     string    `json:"mime"`
+	Width    int       `json:"width"`
+	Height   int       `json:"height"`
+}
+
+// mediaKind classifies a MIME type
+func mediaKind(mime string) string {
+	for _, kind := range []string{MediaKindImage, MediaKindVideo, MediaKindAudio} {
+		if strings.HasPrefix(mime, kind+"/") {
+			return kind
+		}
+	}
+	return MediaKindFile
+}
+
+// mediaStorageKey is where a media asset's files are stored
+func mediaStorageKey(orgID, assetID uuid.UUID, name string) string {
+	return fmt.Sprintf("h5p-media/%s/%s/%s", orgID, assetID, name)
+}
+
+func toMediaAsset(a query.H5pMediaAsset) MediaAsset {
+	asset := MediaAsset{
+		ID:         a.ID,
+		Filename:   a.Filename,
+		Mime:       a.MimeType,
+		Kind:       a.Kind,
+		Size:       a.SizeBytes,
+		Width:      int(a.Width.Int32),
+		Height:     int(a.Height.Int32),
+		UseCount:   a.UseCount,
+		CreatedAt:  a.CreatedAt,
+		LastUsedAt: a.LastUsedAt,
+	}
+	if a.UploadedBy.Valid {
+		asset.UploadedBy = &a.UploadedBy.UUID
+	}
+	if a.Kind == MediaKindImage {
+		asset.ThumbnailURL = "/api/v1/h5p/editor/ajax?" + url.Values{
+			"action": {"media-thumbnail"},
+			"id":     {a.ID.String()},
+			"orgId":  {a.OrgID.String()},
+		}.Encode()
+	}
+	return asset
+}
+
+// queueMediaAsset adds an editor upload to the media library in the
+// background, or straight away without a job queue. Failures are logged; the
+// upload itself has succeeded.
+func (s *Service) queueMediaAsset(ctx context.Context, upload mediaUpload) {
+	if s.jobs == nil {
+		if err := s.addMediaAsset(ctx, upload); err != nil {
+			slog.Warn("Failed to add upload to media library", "orgID", upload.OrgID, "key", upload.TempKey, "error", err)
+		}
+		return
+	}
+	if _, err := s.jobs.Enqueue(ctx, jobAddMediaAsset, upload); err != nil {
+		slog.Warn("Failed to queue media library upload", "orgID", upload.OrgID, "key", upload.TempKey, "error", err)
+	}
+}
+
+// runAddMediaAsset adds a queued editor upload to the media library
+func (s *Service) runAddMediaAsset(ctx context.Context, payload json.RawMessage) error {
+	var upload mediaUpload
+	if err := json.Unmarshal(payload, &upload); err != nil {
+		return fmt.Errorf("decoding media upload: %w", err)
+	}
+	return s.addMediaAsset(ctx, upload)
+}
+
+// addMediaAsset copies an upload from temp storage into the media library. An
+// identical file already in the library is counted as used again instead.
+// Uploads that would take the organisation over its storage quota are left
+// out.
+func (s *Service) addMediaAsset(ctx context.Context, upload mediaUpload) error {
+	data, err := s.fileProvider.Download(ctx, upload.TempKey)
+	if err != nil {
+		// Saved content moves its temp files away; nothing is left to add
+		slog.Info("Upload gone before it reached the media library", "key", upload.TempKey, "error", err)
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	existing, err := s.store.GetH5PMediaAssetByChecksum(ctx, query.GetH5PMediaAssetByChecksumParams{
+		OrgID:    upload.OrgID,
+		Checksum: checksum,
+	})
+	if err == nil {
+		return s.store.TouchH5PMediaAsset(ctx, query.TouchH5PMediaAssetParams{ID: existing.ID, OrgID: upload.OrgID})
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	thumb := thumbnail(data)
+	size := int64(len(data) + len(thumb))
+	if err := s.checkStorageQuota(ctx, upload.OrgID, size); err != nil {
+		slog.Info("Upload left out of media library: storage quota", "orgID", upload.OrgID, "key", upload.TempKey)
+		return nil
+	}
+
+	id := ids.New()
+	key := mediaStorageKey(upload.OrgID, id, upload.Filename)
+	if err := s.fileProvider.Upload(ctx, &file.File{Key: key, ContentType: upload.Mime, Data: data}); err != nil {
+		return fmt.Errorf("storing media asset: %w", err)
+	}
+	var thumbKey sql.NullString
+	if thumb != nil {
+		thumbKey = sql.NullString{String: mediaStorageKey(upload.OrgID, id, "thumbnail.png"), Valid: true}
+		if err := s.fileProvider.Upload(ctx, &file.File{Key: thumbKey.String, ContentType: "image/png", Data: thumb}); err != nil {
+			slog.Warn("Failed to store media thumbnail", "key", thumbKey.String, "error", err)
+			thumbKey = sql.NullString{}
+		}
+	}
+
+	asset, err := s.store.CreateH5PMediaAsset(ctx, query.CreateH5PMediaAssetParams{
+		ID:           id,
+		OrgID:        upload.OrgID,
+		UploadedBy:   uuid.NullUUID{UUID: upload.UserID, Valid: upload.UserID != uuid.Nil},
+		Filename:     upload.Filename,
+		MimeType:     upload.Mime,
+		Kind:         mediaKind(upload.Mime),
+		SizeBytes:    int64(len(data)),
+		Width:        sql.NullInt32{Int32: int32(upload.Width), Valid: upload.Width > 0},
+		Height:       sql.NullInt32{Int32: int32(upload.Height), Valid: upload.Height > 0},
+		Checksum:     checksum,
+		StorageKey:   key,
+		ThumbnailKey: thumbKey,
+	})
+	if err != nil {
+		return fmt.Errorf("saving media asset: %w", err)
+	}
+	if asset.ID != id {
+		// The same file was added concurrently; keep that copy
+		for _, k := range []string{key, thumbKey.String} {
+			if k != "" {
+				if err := s.fileProvider.Remove(ctx, k); err != nil {
+					slog.Warn("Failed to remove duplicate media asset", "key", k, "error", err)
+				}
+			}
+		}
+		return nil
+	}
+	s.recordStorageUsage(ctx, upload.OrgID, size)
+	slog.Info("Added upload to media library", "orgID", upload.OrgID, "assetID", id, "kind", asset.Kind, "bytes", asset.SizeBytes)
+	return nil
+}
+
+// ListMediaAssets returns a page of the organisation's media library
+func (s *Service) ListMediaAssets(ctx context.Context, orgID uuid.UUID, filter MediaFilter) ([]MediaAsset, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultMediaPageSize
+	}
+	filter.Limit = min(filter.Limit, MaxMediaPageSize)
+	switch filter.Kind {
+	case "", MediaKindImage, MediaKindVideo, MediaKindAudio, MediaKindFile:
+	default:
+		return nil, pkg.BadRequestError{Message: "type must be image, video, audio or file"}
+	}
+
+	params := query.ListH5PMediaAssetsParams{
+		OrgID:      orgID,
+		Kind:       sql.NullString{String: filter.Kind, Valid: filter.Kind != ""},
+		MaxResults: int32(filter.Limit),
+		Before:     uuid.NullUUID{UUID: filter.Before, Valid: filter.Before != uuid.Nil},
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		// Match the text literally, not as an ILIKE pattern
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+		params.Search = sql.NullString{String: escaped, Valid: true}
+	}
+	rows, err := s.store.ListH5PMediaAssets(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing media library", Err: err}
+	}
+	assets := make([]MediaAsset, len(rows))
+	for i, row := range rows {
+		assets[i] = toMediaAsset(row)
+	}
+	return assets, nil
+}
+
+func (s *Service) mediaAsset(ctx context.Context, orgID, id uuid.UUID) (query.H5pMediaAsset, error) {
+	asset, err := s.store.GetH5PMediaAsset(ctx, query.GetH5PMediaAssetParams{ID: id, OrgID: orgID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return asset, pkg.NotFoundError{Message: "Media asset not found", Err: err}
+	}
+	if err != nil {
+		return asset, pkg.InternalError{Message: "Error loading media asset", Err: err}
+	}
+	return asset, nil
+}
+
+// GetMediaThumbnail returns an image asset's thumbnail, or the image itself
+// when it's small enough to be its own.
+func (s *Service) GetMediaThumbnail(ctx context.Context, orgID, id uuid.UUID) ([]byte, string, error) {
+	asset, err := s.mediaAsset(ctx, orgID, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if asset.Kind != MediaKindImage {
+		return nil, "", pkg.NotFoundError{Message: "Media asset has no thumbnail"}
+	}
+	key, contentType := asset.StorageKey, asset.MimeType
+	if asset.ThumbnailKey.Valid {
+		key, contentType = asset.ThumbnailKey.String, "image/png"
+	}
+	data, err := s.fileProvider.Download(ctx, key)
+	if err != nil {
+		return nil, "", pkg.NotFoundError{Message: "Thumbnail not found", Err: err}
+	}
+	return data, contentType, nil
+}
+
+// ReuseMediaAsset puts a media library asset into a semantics field as if it
+// had just been uploaded: it's copied into temp storage and moved into the
+// content's storage when the content is saved. The asset must pass the
+// field's whitelist and size limit and fit in the storage quota, like an
+// upload.
+func (s *Service) ReuseMediaAsset(ctx context.Context, orgID, userID, id uuid.UUID, field UploadField) (*TempFileResult, error) {
+	asset, err := s.mediaAsset(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ValidateTempUpload(field, asset.Filename, asset.SizeBytes); err != nil {
+		return nil, err
+	}
+	if err := s.checkStorageQuota(ctx, orgID, asset.SizeBytes); err != nil {
+		return nil, err
+	}
+	data, err := s.fileProvider.Download(ctx, asset.StorageKey)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading media asset", Err: err}
+	}
+
+	tempID := ids.New()
+	key := fmt.Sprintf("h5p-temp/%s/%s/%s", userID, tempID, asset.Filename)
+	if err := s.fileProvider.Upload(ctx, &file.File{Key: key, ContentType: asset.MimeType, Data: data}); err != nil {
+		return nil, pkg.InternalError{Message: "Error copying media asset", Err: err}
+	}
+	s.recordStorageUsage(ctx, orgID, asset.SizeBytes)
+	if err := s.store.TouchH5PMediaAsset(ctx, query.TouchH5PMediaAssetParams{ID: asset.ID, OrgID: orgID}); err != nil {
+		slog.Warn("Failed to record media asset use", "assetID", asset.ID, "error", err)
+	}
+
+	return &TempFileResult{
+		Path:   fmt.Sprintf("%s/%s/%s%s", userID, tempID, asset.Filename, tempFileSuffix),
+		Mime:   asset.MimeType,
+		Width:  int(asset.Width.Int32),
+		Height: int(asset.Height.Int32),
+	}, nil
+}
+
+// DeleteMediaAsset removes an asset from the media library. Content it was
+// used in keeps its own copy.
+func (s *Service) DeleteMediaAsset(ctx context.Context, orgID, id uuid.UUID) error {
+	if _, err := s.mediaAsset(ctx, orgID, id); err != nil {
+		return err
+	}
+	if err := s.store.DeleteH5PMediaAsset(ctx, query.DeleteH5PMediaAssetParams{ID: id, OrgID: orgID}); err != nil {
+		return pkg.InternalError{Message: "Error deleting media asset", Err: err}
+	}
+	slog.Info("Deleted media asset", "orgID", orgID, "assetID", id)
+	return nil
+}
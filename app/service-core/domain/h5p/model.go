@@ -34,6 +34,10 @@ type HubVersion struct {
 	Patch int `json:"patch"`
 }
 
+// coreAPIVersion is the H5P core API version this platform implements.
+// Libraries needing a newer core can't be installed.
+var coreAPIVersion = HubVersion{Major: 1, Minor: 26}
+
 type HubScreenshot struct {
 	URL string `json:"url"`
 	Alt string `json:"alt"`
@@ -59,6 +63,15 @@ type HubResponse struct {
 	ContentTypes []HubContentType `json:"contentTypes"`
 }
 
+// ContentHubMetadata is the response from GET /v1/metadata, served to the
+// editor's content hub browser. Lists are kept raw so new hub fields pass through.
+type ContentHubMetadata struct {
+	Levels      json.RawMessage `json:"levels"`
+	Disciplines json.RawMessage `json:"disciplines"`
+	Languages   json.RawMessage `json:"languages"`
+	Licenses    json.RawMessage `json:"licenses"`
+}
+
 // HubRegistryResponse is the Catharsis-format response served by our Hub endpoints
 type HubRegistryResponse struct {
 	ContentTypes []HubContentType `json:"contentTypes"`
@@ -156,6 +169,11 @@ type ContentInfo struct {
 	LibraryVersion string    `json:"libraryVersion"`
 	CreatedAt      string    `json:"createdAt"`
 	UpdatedAt      string    `json:"updatedAt"`
+	// LockReason is set when the content is read-only, e.g. over the free tier limits
+	LockReason string `json:"lockReason,omitempty"`
+	// DanglingFiles are files the saved parameters reference that aren't
+	// stored; the player would 404 on them. Only set by editor saves.
+	DanglingFiles []string `json:"danglingFiles,omitempty"`
 }
 
 // IHubInfo — content-type-cache in editor format
@@ -0,0 +1,101 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Availability of a library to an organisation
+const (
+	LibraryAvailable  = "available"
+	LibraryDisabled   = "disabled"
+	LibraryRestricted = "restricted"
+)
+
+// LibraryAvailability is whether an organisation can create content with an
+// installed content type.
+type LibraryAvailability struct {
+	LibraryID   uuid.UUID `json:"libraryId"` // the latest installed version
+	MachineName string    `json:"machineName"`
+	Title       string    `json:"title"`
+	Version     string    `json:"version"`
+	Status      string    `json:"status"`
+}
+
+// unavailableLibraries returns the machine names an organisation can't create
+// content with, and why. Rows are per library version, but a library is
+// disabled or restricted as a whole so updates installed side by side don't
+// bring it back.
+func (s *Service) unavailableLibraries(ctx context.Context, orgID uuid.UUID) (map[string]string, error) {
+	rows, err := s.store.ListH5POrgLibraries(ctx, orgID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading organisation libraries", Err: err}
+	}
+	unavailable := make(map[string]string)
+	for _, row := range rows {
+		switch {
+		case row.Restricted:
+			unavailable[row.MachineName] = LibraryRestricted
+		case !row.Enabled && unavailable[row.MachineName] == "":
+			unavailable[row.MachineName] = LibraryDisabled
+		}
+	}
+	return unavailable, nil
+}
+
+// checkLibraryAvailable rejects content on a library disabled or restricted
+// for the organisation.
+func (s *Service) checkLibraryAvailable(ctx context.Context, orgID uuid.UUID, machineName string) error {
+	unavailable, err := s.unavailableLibraries(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if status, ok := unavailable[machineName]; ok {
+		return pkg.ForbiddenError{Err: fmt.Errorf("%s is %s for this organisation", machineName, status)}
+	}
+	return nil
+}
+
+// ListOrgLibraryAvailability lists the installed content types and whether
+// the organisation can use each of them.
+func (s *Service) ListOrgLibraryAvailability(ctx context.Context, orgID uuid.UUID) ([]LibraryAvailability, error) {
+	installed, err := s.store.ListH5PRunnableLibraries(ctx)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing installed libraries", Err: err}
+	}
+	unavailable, err := s.unavailableLibraries(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]LibraryAvailability)
+	versions := make(map[string]HubVersion)
+	for _, lib := range installed {
+		if prev, ok := versions[lib.MachineName]; ok && !libraryVersion(lib).newerThan(prev) {
+			continue
+		}
+		versions[lib.MachineName] = libraryVersion(lib)
+		status := LibraryAvailable
+		if reason, ok := unavailable[lib.MachineName]; ok {
+			status = reason
+		}
+		latest[lib.MachineName] = LibraryAvailability{
+			LibraryID:   lib.ID,
+			MachineName: lib.MachineName,
+			Title:       lib.Title,
+			Version:     fmt.Sprintf("%d.%d.%d", lib.MajorVersion, lib.MinorVersion, lib.PatchVersion),
+			Status:      status,
+		}
+	}
+
+	libraries := make([]LibraryAvailability, 0, len(latest))
+	for _, lib := range latest {
+		libraries = append(libraries, lib)
+	}
+	sort.Slice(libraries, func(i, j int) bool { return libraries[i].Title < libraries[j].Title })
+	return libraries, nil
+}
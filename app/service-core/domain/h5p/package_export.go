@@ -0,0 +1,253 @@
+package h5p
+
+import (
+	"app/pkg"
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"service-core/domain/file"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// subContentLibrary matches the library of sub-content in content parameters,
+// e.g. "H5P.MultiChoice 1.16"
+var subContentLibrary = regexp.MustCompile(`^([\w.]+) (\d+)\.(\d+)$`)
+
+// ContentPackage is a content item packaged as an .h5p file. Write streams
+// it, reading library and content files from storage as they're written.
+type ContentPackage struct {
+	Filename string
+	manifest []byte
+	params   []byte
+	files    []packageFile
+	provider file.Provider
+}
+
+// packageFile is a stored file and where it goes in the package. Optional
+// files are left out if they can't be read.
+type packageFile struct {
+	name     string
+	key      string
+	optional bool
+}
+
+// ExportContent packages content as an .h5p file that other H5P platforms,
+// such as Moodle or WordPress, can import: its h5p.json manifest, its
+// parameters and files, and every library it needs. Content files that are
+// missing from storage are left out, as the player would 404 on them anyway.
+func (s *Service) ExportContent(ctx context.Context, contentID, orgID uuid.UUID) (*ContentPackage, error) {
+	content, err := s.store.GetH5PContent(ctx, query.GetH5PContentParams{
+		ID:    contentID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Content not found", Err: err}
+	}
+	mainLib, err := s.store.GetH5PLibrary(ctx, content.LibraryID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error resolving content library", Err: err}
+	}
+	params, metadata := splitContentJSON(content.ContentJson, content.Title)
+
+	// The content depends on its main library and the libraries of its sub-content
+	roots, err := s.paramLibraries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	roots = append([]query.H5pLibrary{mainLib}, roots...)
+	ids := make([]uuid.UUID, len(roots))
+	deps := make([]LibraryDep, len(roots))
+	for i, lib := range roots {
+		ids[i] = lib.ID
+		deps[i] = LibraryDep{MachineName: lib.MachineName, MajorVersion: FlexInt(lib.MajorVersion), MinorVersion: FlexInt(lib.MinorVersion)}
+	}
+	libs, err := s.store.GetH5PLibraryExportTree(ctx, ids)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error resolving dependency tree", Err: err}
+	}
+
+	manifest, err := exportManifest(metadata, content.Title, mainLib.MachineName, deps)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error building h5p.json", Err: err}
+	}
+	result := &ContentPackage{
+		Filename: content.Slug + ".h5p",
+		manifest: manifest,
+		params:   params,
+		provider: s.fileProvider,
+	}
+	for _, lib := range libs {
+		files, err := s.exportLibraryFiles(ctx, lib)
+		if err != nil {
+			return nil, err
+		}
+		result.files = append(result.files, files...)
+	}
+
+	refs, err := fileRefs(params)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid content parameters"}
+	}
+	prefix := fmt.Sprintf("h5p-content/%s/%s/", orgID, contentID)
+	for _, ref := range refs {
+		// Unmigrated temp files aren't the content's yet
+		if strings.HasSuffix(ref, tempFileSuffix) || path.Clean(ref) != ref || strings.HasPrefix(ref, "../") || path.IsAbs(ref) {
+			slog.Warn("Leaving file out of content export", "contentID", contentID, "path", ref)
+			continue
+		}
+		result.files = append(result.files, packageFile{name: "content/" + ref, key: prefix + ref, optional: true})
+	}
+	return result, nil
+}
+
+// paramLibraries returns the installed libraries that sub-content in params
+// uses. Libraries that aren't installed can't be packaged and are left out.
+func (s *Service) paramLibraries(ctx context.Context, params json.RawMessage) ([]query.H5pLibrary, error) {
+	var root any
+	if err := json.Unmarshal(params, &root); err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid content parameters"}
+	}
+	seen := make(map[string]bool)
+	var names []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if name, ok := v["library"].(string); ok && subContentLibrary.MatchString(name) && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(root)
+
+	libs := make([]query.H5pLibrary, 0, len(names))
+	for _, name := range names {
+		m := subContentLibrary.FindStringSubmatch(name)
+		major, _ := strconv.Atoi(m[2])
+		minor, _ := strconv.Atoi(m[3])
+		lib, err := s.store.GetH5PLibraryByMachineNameMajorMinor(ctx, query.GetH5PLibraryByMachineNameMajorMinorParams{
+			MachineName:  m[1],
+			MajorVersion: int32(major),
+			MinorVersion: int32(minor),
+		})
+		if err != nil {
+			slog.Warn("Sub-content library not installed, leaving it out of the export", "library", name, "error", err)
+			continue
+		}
+		libs = append(libs, lib)
+	}
+	return libs, nil
+}
+
+// exportLibraryFiles lists a library's stored files under its directory in
+// the package, e.g. H5P.MultiChoice-1.16/. Providers that can't list files
+// only give the files library.json names, and semantics.json.
+func (s *Service) exportLibraryFiles(ctx context.Context, lib query.H5pLibrary) ([]packageFile, error) {
+	major, minor, patch := int(lib.MajorVersion), int(lib.MinorVersion), int(lib.PatchVersion)
+	dir := fmt.Sprintf("%s-%d.%d/", lib.MachineName, major, minor)
+	prefix := LibraryStorageKey(lib.MachineName, major, minor, patch, "") + "/"
+	listed, err := s.fileProvider.ListByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing library files", Err: err}
+	}
+
+	var files []packageFile
+	if len(listed) > 0 {
+		for _, rel := range listed {
+			files = append(files, packageFile{name: dir + rel, key: prefix + rel})
+		}
+		return files, nil
+	}
+
+	files = append(files,
+		packageFile{name: dir + "library.json", key: prefix + "library.json"},
+		packageFile{name: dir + "semantics.json", key: prefix + "semantics.json", optional: true},
+	)
+	var meta LibraryJSON
+	if lib.MetadataJson.Valid {
+		if err := json.Unmarshal(lib.MetadataJson.RawMessage, &meta); err != nil {
+			slog.Warn("Failed to parse metadata_json for library", "lib", lib.MachineName, "error", err)
+		}
+	}
+	for _, asset := range append(meta.PreloadedCss, meta.PreloadedJs...) {
+		files = append(files, packageFile{name: dir + asset.Path, key: prefix + asset.Path})
+	}
+	return files, nil
+}
+
+// exportManifest builds h5p.json from the content's metadata: its title,
+// authors, licence and so on, with the main library and direct dependencies.
+func exportManifest(metadata json.RawMessage, title, mainLibrary string, deps []LibraryDep) ([]byte, error) {
+	manifest := make(map[string]any)
+	if err := json.Unmarshal(metadata, &manifest); err != nil || manifest == nil {
+		manifest = make(map[string]any)
+	}
+	if t, _ := manifest["title"].(string); t == "" {
+		manifest["title"] = title
+	}
+	language, _ := manifest["defaultLanguage"].(string)
+	if language == "" {
+		language = "und"
+	}
+	manifest["language"] = language
+	manifest["mainLibrary"] = mainLibrary
+	// Every content type can be embedded in an iframe
+	manifest["embedTypes"] = []string{"iframe"}
+	manifest["preloadedDependencies"] = deps
+	return json.MarshalIndent(manifest, "", "  ")
+}
+
+// Write streams the package to w as a zip. A file that can't be read fails
+// the export part way, unless it's optional.
+func (p *ContentPackage) Write(ctx context.Context, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	if err := writeZipFile(zw, "h5p.json", p.manifest); err != nil {
+		return err
+	}
+	if err := writeZipFile(zw, "content/content.json", p.params); err != nil {
+		return err
+	}
+	for _, f := range p.files {
+		data, err := p.provider.Download(ctx, f.key)
+		if err != nil {
+			if f.optional {
+				slog.Warn("Leaving unreadable file out of content export", "key", f.key, "error", err)
+				continue
+			}
+			return fmt.Errorf("downloading %s: %w", f.key, err)
+		}
+		if err := writeZipFile(zw, f.name, data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("adding %s: %w", name, err)
+	}
+	if _, err := fw.Write(data); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// PlayerContentKey is the key of the played content in H5PIntegration.contents.
+// The player page's .h5p-content element needs data-content-id="1" to match.
+const PlayerContentKey = "cid-1"
+
+// H5P core CSS files (matching h5p-php-library H5PCore::$styles order)
+var playerCoreStyles = []string{
+	"/h5p/core/styles/h5p-fonts.css",
+	"/h5p/core/styles/h5p.css",
+	"/h5p/core/styles/h5p-confirmation-dialog.css",
+	"/h5p/core/styles/h5p-core-button.css",
+	"/h5p/core/styles/h5p-theme.css",
+	"/h5p/core/styles/h5p-theme-variables.css",
+	"/h5p/core/styles/h5p-tooltip.css",
+	"/h5p/core/styles/h5p-table.css",
+	"/h5p/core/styles/font-open-sans.css",
+}
+
+// H5P core JS files (matching Moodle's H5PCore::$scripts)
+var playerCoreScripts = []string{
+	"/h5p/core/js/jquery.js",
+	"/h5p/core/js/h5p.js",
+	"/h5p/core/js/h5p-event-dispatcher.js",
+	"/h5p/core/js/h5p-x-api-event.js",
+	"/h5p/core/js/h5p-x-api.js",
+	"/h5p/core/js/h5p-content-type.js",
+	"/h5p/core/js/h5p-confirmation-dialog.js",
+	"/h5p/core/js/h5p-action-bar.js",
+	"/h5p/core/js/request-queue.js",
+	"/h5p/core/js/h5p-tooltip.js",
+}
+
+// playerL10n holds the core player strings
+var playerL10n = map[string]map[string]string{
+	"H5P": {
+		"fullscreen":            "Fullscreen",
+		"disableFullscreen":     "Disable fullscreen",
+		"download":              "Download",
+		"copyrights":            "Rights of use",
+		"embed":                 "Embed",
+		"size":                  "Size",
+		"showAdvanced":          "Show advanced",
+		"hideAdvanced":          "Hide advanced",
+		"advancedHelp":          "Include this script on your website if you want dynamic sizing of the embedded content:",
+		"copyrightInformation":  "Rights of use",
+		"close":                 "Close",
+		"title":                 "Title",
+		"author":                "Author",
+		"year":                  "Year",
+		"source":                "Source",
+		"license":               "License",
+		"thumbnail":             "Thumbnail",
+		"noCopyrights":          "No copyright information available for this content.",
+		"reuse":                 "Reuse",
+		"reuseContent":          "Reuse Content",
+		"reuseDescription":      "Reuse this content.",
+		"downloadDescription":   "Download this content as a H5P file.",
+		"copyrightsDescription": "View copyright information for this content.",
+		"embedDescription":      "View the embed code for this content.",
+		"h5pDescription":        "Visit H5P.org to check out more cool content.",
+		"contentChanged":        "This content has changed since you last used it.",
+		"startingOver":          "You'll be starting over.",
+		"confirmDialogHeader":   "Confirm action",
+		"confirmDialogBody":     "Please confirm that you wish to proceed. This action is not reversible.",
+		"cancelLabel":           "Cancel",
+		"confirmLabel":          "Confirm",
+		"licenseU":              "Undisclosed",
+	},
+}
+
+// PlayerConfig is everything a page needs to play content with the standalone
+// H5P player: the assets to load, in order, and the H5PIntegration object.
+type PlayerConfig struct {
+	ContentID   uuid.UUID         `json:"contentId"`
+	Title       string            `json:"title"`
+	Library     string            `json:"library"` // e.g. "H5P.Accordion 1.0"
+	CoreStyles  []string          `json:"coreStyles"`
+	CoreScripts []string          `json:"coreScripts"`
+	Styles      []string          `json:"styles"`  // library CSS, dependencies first
+	Scripts     []string          `json:"scripts"` // library JS, dependencies first
+	Integration PlayerIntegration `json:"integration"`
+}
+
+// PlayerIntegration is the H5PIntegration object, matching Moodle's structure
+type PlayerIntegration struct {
+	BaseURL      string                       `json:"baseUrl"`
+	URL          string                       `json:"url"`
+	URLLibraries string                       `json:"urlLibraries"`
+	SaveFreq     int                          `json:"saveFreq"`
+	Ajax         map[string]string            `json:"ajax"`
+	User         PlayerUser                   `json:"user"`
+	Contents     map[string]*PlayerContent    `json:"contents"`
+	Core         PlayerAssets                 `json:"core"`
+	L10n         map[string]map[string]string `json:"l10n"`
+}
+
+// PlayerUser is who H5P attributes xAPI statements to
+type PlayerUser struct {
+	Name string `json:"name"`
+	Mail string `json:"mail"`
+}
+
+// PlayerAssets lists CSS and JS URLs
+type PlayerAssets struct {
+	Styles  []string `json:"styles"`
+	Scripts []string `json:"scripts"`
+}
+
+// PlayerContent is one entry of H5PIntegration.contents
+type PlayerContent struct {
+	Library        string               `json:"library"`
+	JSONContent    string               `json:"jsonContent"`
+	FullScreen     bool                 `json:"fullScreen"`
+	Styles         []string             `json:"styles"`
+	Scripts        []string             `json:"scripts"`
+	DisplayOptions PlayerDisplayOptions `json:"displayOptions"`
+	ContentURL     string               `json:"contentUrl"`
+	Metadata       json.RawMessage      `json:"metadata"`
+	// ContentUserData is saved state by sub-content ID and data type, as H5P
+	// core's getUserData expects it
+	ContentUserData map[string]map[string]string `json:"contentUserData,omitempty"`
+}
+
+// PlayerDisplayOptions toggles the frame and action bar buttons
+type PlayerDisplayOptions struct {
+	Frame     bool `json:"frame"`
+	Copyright bool `json:"copyright"`
+	Export    bool `json:"export"`
+	Embed     bool `json:"embed"`
+	Icon      bool `json:"icon"`
+}
+
+// GetPlayerConfig resolves content's preloaded dependencies and builds the
+// H5PIntegration object for playing it. It carries no user state; callers
+// playing for a user add it to the content entry.
+func (s *Service) GetPlayerConfig(ctx context.Context, contentID, orgID uuid.UUID) (*PlayerConfig, error) {
+	content, err := s.store.GetH5PContent(ctx, query.GetH5PContentParams{
+		ID:    contentID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Content not found", Err: err}
+	}
+	mainLib, err := s.store.GetH5PLibrary(ctx, content.LibraryID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error resolving content library", Err: err}
+	}
+	// Deepest dependencies first, main library excluded
+	deps, err := s.store.GetH5PLibraryFullDependencyTree(ctx, content.LibraryID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error resolving dependency tree", Err: err}
+	}
+
+	// The main library loads last, after everything it depends on
+	styles, scripts := []string{}, []string{}
+	for _, lib := range append(deps, mainLib) {
+		css, js := preloadedAssets(lib)
+		styles = append(styles, css...)
+		scripts = append(scripts, js...)
+	}
+
+	params, metadata := splitContentJSON(content.ContentJson, content.Title)
+	library := fmt.Sprintf("%s %d.%d", mainLib.MachineName, mainLib.MajorVersion, mainLib.MinorVersion)
+
+	return &PlayerConfig{
+		ContentID:   content.ID,
+		Title:       content.Title,
+		Library:     library,
+		CoreStyles:  playerCoreStyles,
+		CoreScripts: playerCoreScripts,
+		Styles:      styles,
+		Scripts:     scripts,
+		Integration: PlayerIntegration{
+			BaseURL:      "",
+			URL:          "/api/h5p",
+			URLLibraries: "/api/h5p/libraries",
+			SaveFreq:     10,
+			Ajax: map[string]string{
+				// H5P core replaces :contentId with the data-content-id attribute (hardcoded "1"),
+				// not the actual UUID. Pre-bake the real UUID so the URL resolves correctly.
+				"contentUserData": fmt.Sprintf("/api/h5p/content-user-data/%s/:dataType/:subContentId", content.ID),
+			},
+			User: PlayerUser{Name: "Learner"},
+			Contents: map[string]*PlayerContent{
+				PlayerContentKey: {
+					Library:     library,
+					JSONContent: string(params),
+					Styles:      styles,
+					Scripts:     scripts,
+					DisplayOptions: PlayerDisplayOptions{
+						Frame:     true,
+						Copyright: true,
+					},
+					ContentURL: fmt.Sprintf("/api/h5p/play/%s/content", content.ID),
+					Metadata:   metadata,
+				},
+			},
+			Core: PlayerAssets{
+				Styles:  playerCoreStyles,
+				Scripts: playerCoreScripts,
+			},
+			L10n: playerL10n,
+		},
+	}, nil
+}
+
+// preloadedAssets returns the URLs of a library's preloaded CSS and JS, read
+// from the library.json kept in its metadata.
+func preloadedAssets(lib query.H5pLibrary) (css, js []string) {
+	if !lib.MetadataJson.Valid || len(lib.MetadataJson.RawMessage) == 0 {
+		slog.Warn("Library has no metadata_json, its CSS/JS can't be resolved", "lib", lib.MachineName)
+		return nil, nil
+	}
+	var meta LibraryJSON
+	if err := json.Unmarshal(lib.MetadataJson.RawMessage, &meta); err != nil {
+		slog.Warn("Failed to parse metadata_json for library", "lib", lib.MachineName, "error", err)
+		return nil, nil
+	}
+
+	base := fmt.Sprintf("/api/h5p/libraries/%s-%d.%d.%d", lib.MachineName, lib.MajorVersion, lib.MinorVersion, lib.PatchVersion)
+	for _, c := range meta.PreloadedCss {
+		css = append(css, base+"/"+c.Path)
+	}
+	for _, j := range meta.PreloadedJs {
+		js = append(js, base+"/"+j.Path)
+	}
+	return css, js
+}
+
+// splitContentJSON separates stored content into the parameters the player
+// runs and its metadata. The editor stores {"params": {...}, "metadata": {...}};
+// content stored otherwise is all parameters, titled after the content.
+func splitContentJSON(contentJSON json.RawMessage, title string) (params, metadata json.RawMessage) {
+	params = contentJSON
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(contentJSON, &wrapper); err == nil {
+		if inner, ok := wrapper["params"]; ok {
+			params = inner
+			metadata = wrapper["metadata"]
+		}
+	}
+
+	var meta map[string]any
+	if json.Unmarshal(metadata, &meta) != nil || meta == nil {
+		meta = make(map[string]any)
+	}
+	if t, _ := meta["title"].(string); t == "" {
+		meta["title"] = title
+	}
+	metadata, _ = json.Marshal(meta)
+	return params, metadata
+}
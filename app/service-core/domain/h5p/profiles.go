@@ -0,0 +1,399 @@
+package h5p
+
+import (
+	"app/pkg"
+	"app/pkg/jobs"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxProfileLibraries     = 100
+	maxProfileNameLength    = 100
+	profileApplicationLimit = 20
+	applyProfileTimeout     = 30 * time.Minute
+)
+
+// jobApplyInstallProfile installs and enables a profile's libraries for an
+// organisation. Its payload is the profile application ID.
+const jobApplyInstallProfile = "h5p.apply_install_profile"
+
+// machineNamePattern matches content type machine names like H5P.MultiChoice
+var machineNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+\.[A-Za-z0-9_.-]+$`)
+
+// InstallProfile is a named list of content types that can be installed and
+// enabled for an organisation in one go. The default profile is applied to
+// every new organisation.
+type InstallProfile struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Libraries   []string  `json:"libraries"` // content type machine names
+	IsDefault   bool      `json:"isDefault"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// InstallProfileInput creates or replaces an install profile
+type InstallProfileInput struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Libraries   []string `json:"libraries"`
+	IsDefault   bool     `json:"isDefault"`
+}
+
+// ProfileApplication is one application of a profile to an organisation. The
+// profile's name and libraries are kept as they were when it was applied.
+type ProfileApplication struct {
+	ID             uuid.UUID      `json:"id"`
+	OrganisationID uuid.UUID      `json:"organisationId"`
+	ProfileID      *uuid.UUID     `json:"profileId"` // nil once the profile is deleted
+	ProfileName    string         `json:"profileName"`
+	Libraries      []string       `json:"libraries"`
+	Status         string         `json:"status"` // pending, completed or failed
+	Summary        ProfileSummary `json:"summary"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	CompletedAt    *time.Time     `json:"completedAt"`
+}
+
+// ProfileSummary is what applying a profile did. Every library that ended up
+// enabled is in Enabled; those downloaded from the Hub first are in Installed
+// as well.
+type ProfileSummary struct {
+	Enabled   []string         `json:"enabled"`
+	Installed []string         `json:"installed"`
+	Failed    []ProfileFailure `json:"failed"`
+}
+
+// ProfileFailure is a library a profile couldn't enable
+type ProfileFailure struct {
+	MachineName string `json:"machineName"`
+	Error       string `json:"error"`
+}
+
+func toInstallProfile(p query.H5pInstallProfile) InstallProfile {
+	return InstallProfile{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Libraries:   p.Libraries,
+		IsDefault:   p.IsDefault,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+func toProfileApplication(a query.H5pProfileApplication) ProfileApplication {
+	result := ProfileApplication{
+		ID:             a.ID,
+		OrganisationID: a.OrganisationID,
+		ProfileName:    a.ProfileName,
+		Libraries:      a.Libraries,
+		Status:         a.Status,
+		CreatedAt:      a.CreatedAt,
+	}
+	if a.ProfileID.Valid {
+		result.ProfileID = &a.ProfileID.UUID
+	}
+	if a.CompletedAt.Valid {
+		result.CompletedAt = &a.CompletedAt.Time
+	}
+	// A pending application has an empty summary
+	_ = json.Unmarshal(a.Summary, &result.Summary)
+	return result
+}
+
+// normalize trims the input and drops duplicate libraries, keeping the order
+// they were listed in.
+func (in *InstallProfileInput) normalize() error {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Description = strings.TrimSpace(in.Description)
+	if in.Name == "" || len(in.Name) > maxProfileNameLength {
+		return pkg.BadRequestError{Message: fmt.Sprintf("Profile name must be 1 to %d characters", maxProfileNameLength)}
+	}
+
+	seen := make(map[string]bool, len(in.Libraries))
+	libraries := make([]string, 0, len(in.Libraries))
+	for _, name := range in.Libraries {
+		name = strings.TrimSpace(name)
+		if !machineNamePattern.MatchString(name) {
+			return pkg.BadRequestError{Message: fmt.Sprintf("Invalid library machine name: %q", name)}
+		}
+		if !seen[name] {
+			seen[name] = true
+			libraries = append(libraries, name)
+		}
+	}
+	if len(libraries) == 0 || len(libraries) > maxProfileLibraries {
+		return pkg.BadRequestError{Message: fmt.Sprintf("A profile must list 1 to %d libraries", maxProfileLibraries)}
+	}
+	in.Libraries = libraries
+	return nil
+}
+
+// checkProfileName rejects a name another profile already uses
+func (s *Service) checkProfileName(ctx context.Context, id uuid.UUID, name string) error {
+	profiles, err := s.store.ListH5PInstallProfiles(ctx)
+	if err != nil {
+		return pkg.InternalError{Message: "Error listing install profiles", Err: err}
+	}
+	for _, p := range profiles {
+		if p.ID != id && strings.EqualFold(p.Name, name) {
+			return pkg.BadRequestError{Message: fmt.Sprintf("A profile named %q already exists", p.Name)}
+		}
+	}
+	return nil
+}
+
+// ListInstallProfiles returns every install profile, by name
+func (s *Service) ListInstallProfiles(ctx context.Context) ([]InstallProfile, error) {
+	profiles, err := s.store.ListH5PInstallProfiles(ctx)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing install profiles", Err: err}
+	}
+	result := make([]InstallProfile, len(profiles))
+	for i, p := range profiles {
+		result[i] = toInstallProfile(p)
+	}
+	return result, nil
+}
+
+// CreateInstallProfile adds a profile. Making it the default takes the
+// default from any other profile.
+func (s *Service) CreateInstallProfile(ctx context.Context, userID uuid.UUID, in InstallProfileInput) (*InstallProfile, error) {
+	if err := in.normalize(); err != nil {
+		return nil, err
+	}
+	if err := s.checkProfileName(ctx, uuid.Nil, in.Name); err != nil {
+		return nil, err
+	}
+	if in.IsDefault {
+		if err := s.store.ClearDefaultH5PInstallProfile(ctx, uuid.Nil); err != nil {
+			return nil, pkg.InternalError{Message: "Error clearing default install profile", Err: err}
+		}
+	}
+	p, err := s.store.CreateH5PInstallProfile(ctx, query.CreateH5PInstallProfileParams{
+		Name:        in.Name,
+		Description: in.Description,
+		Libraries:   in.Libraries,
+		IsDefault:   in.IsDefault,
+		CreatedBy:   uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error creating install profile", Err: err}
+	}
+	profile := toInstallProfile(p)
+	return &profile, nil
+}
+
+// UpdateInstallProfile replaces a profile. Organisations it was already
+// applied to keep what it enabled.
+func (s *Service) UpdateInstallProfile(ctx context.Context, id uuid.UUID, in InstallProfileInput) (*InstallProfile, error) {
+	if err := in.normalize(); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetH5PInstallProfile(ctx, id); err != nil {
+		return nil, pkg.NotFoundError{Message: "Install profile not found", Err: err}
+	}
+	if err := s.checkProfileName(ctx, id, in.Name); err != nil {
+		return nil, err
+	}
+	if in.IsDefault {
+		if err := s.store.ClearDefaultH5PInstallProfile(ctx, id); err != nil {
+			return nil, pkg.InternalError{Message: "Error clearing default install profile", Err: err}
+		}
+	}
+	p, err := s.store.UpdateH5PInstallProfile(ctx, query.UpdateH5PInstallProfileParams{
+		ID:          id,
+		Name:        in.Name,
+		Description: in.Description,
+		Libraries:   in.Libraries,
+		IsDefault:   in.IsDefault,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating install profile", Err: err}
+	}
+	profile := toInstallProfile(p)
+	return &profile, nil
+}
+
+// DeleteInstallProfile removes a profile. Its past applications are kept.
+func (s *Service) DeleteInstallProfile(ctx context.Context, id uuid.UUID) error {
+	n, err := s.store.DeleteH5PInstallProfile(ctx, id)
+	if err != nil {
+		return pkg.InternalError{Message: "Error deleting install profile", Err: err}
+	}
+	if n == 0 {
+		return pkg.NotFoundError{Message: "Install profile not found"}
+	}
+	return nil
+}
+
+// ApplyInstallProfile queues installing and enabling a profile's libraries
+// for the organisation. A nil profileID applies the default profile.
+// requestedBy is unset when the platform applies it on its own.
+func (s *Service) ApplyInstallProfile(ctx context.Context, orgID uuid.UUID, profileID *uuid.UUID, requestedBy uuid.NullUUID) (*ProfileApplication, error) {
+	if s.jobs == nil {
+		return nil, pkg.InternalError{Message: "Install profiles need the job queue"}
+	}
+
+	var p query.H5pInstallProfile
+	var err error
+	if profileID != nil {
+		p, err = s.store.GetH5PInstallProfile(ctx, *profileID)
+	} else {
+		p, err = s.store.GetDefaultH5PInstallProfile(ctx)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Install profile not found", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading install profile", Err: err}
+	}
+
+	a, err := s.store.CreateH5PProfileApplication(ctx, query.CreateH5PProfileApplicationParams{
+		OrganisationID: orgID,
+		ProfileID:      uuid.NullUUID{UUID: p.ID, Valid: true},
+		ProfileName:    p.Name,
+		Libraries:      p.Libraries,
+		RequestedBy:    requestedBy,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error recording profile application", Err: err}
+	}
+	if _, err := s.jobs.Enqueue(ctx, jobApplyInstallProfile, a.ID); err != nil {
+		s.finishApplication(ctx, a.ID, "failed", ProfileSummary{Enabled: []string{}, Installed: []string{}, Failed: []ProfileFailure{}})
+		return nil, pkg.InternalError{Message: "Error queueing profile application", Err: err}
+	}
+	slog.Info("Install profile queued", "profile", p.Name, "orgID", orgID, "applicationID", a.ID)
+
+	application := toProfileApplication(a)
+	return &application, nil
+}
+
+// ListProfileApplications returns the organisation's most recent profile
+// applications, newest first
+func (s *Service) ListProfileApplications(ctx context.Context, orgID uuid.UUID) ([]ProfileApplication, error) {
+	applications, err := s.store.ListH5PProfileApplications(ctx, query.ListH5PProfileApplicationsParams{
+		OrganisationID: orgID,
+		Limit:          profileApplicationLimit,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing profile applications", Err: err}
+	}
+	result := make([]ProfileApplication, len(applications))
+	for i, a := range applications {
+		result[i] = toProfileApplication(a)
+	}
+	return result, nil
+}
+
+func (s *Service) registerProfileJobs(queue *jobs.Queue) {
+	// Installed libraries are skipped on a retry, so retrying only redoes
+	// what failed
+	queue.Register(jobApplyInstallProfile, s.runApplyInstallProfile, jobs.MaxAttempts(3), jobs.Timeout(applyProfileTimeout))
+}
+
+// runApplyInstallProfile enables each of the application's libraries for its
+// organisation, installing those the platform doesn't have from the Hub. One
+// library failing doesn't stop the rest; the application fails only if none
+// could be enabled.
+func (s *Service) runApplyInstallProfile(ctx context.Context, payload json.RawMessage) error {
+	var applicationID uuid.UUID
+	if err := json.Unmarshal(payload, &applicationID); err != nil {
+		return fmt.Errorf("decoding profile application job: %w", err)
+	}
+	a, err := s.store.GetH5PProfileApplication(ctx, applicationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil // the organisation is gone
+	}
+	if err != nil {
+		return fmt.Errorf("loading profile application: %w", err)
+	}
+
+	summary := ProfileSummary{Enabled: []string{}, Installed: []string{}, Failed: []ProfileFailure{}}
+	for _, name := range a.Libraries {
+		installed, err := s.enableProfileLibrary(ctx, a.OrganisationID, name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Warn("Error applying install profile library", "error", err, "machineName", name, "orgID", a.OrganisationID)
+			summary.Failed = append(summary.Failed, ProfileFailure{MachineName: name, Error: profileErrorMessage(err)})
+			continue
+		}
+		if installed {
+			summary.Installed = append(summary.Installed, name)
+		}
+		summary.Enabled = append(summary.Enabled, name)
+	}
+
+	status := "completed"
+	if len(summary.Enabled) == 0 && len(summary.Failed) > 0 {
+		status = "failed"
+	}
+	s.finishApplication(ctx, a.ID, status, summary)
+	slog.Info("Install profile applied", "profile", a.ProfileName, "orgID", a.OrganisationID,
+		"enabled", len(summary.Enabled), "installed", len(summary.Installed), "failed", len(summary.Failed))
+	return nil
+}
+
+// enableProfileLibrary enables a library for the organisation, first
+// installing it from the Hub if the platform doesn't have it. It reports
+// whether it installed it.
+func (s *Service) enableProfileLibrary(ctx context.Context, orgID uuid.UUID, machineName string) (bool, error) {
+	lib, err := s.store.GetH5PLibraryByMachineName(ctx, machineName)
+	if err == nil {
+		return false, s.EnableLibraryForOrg(ctx, orgID, lib.ID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	installed, err := s.InstallLibraryForOrg(ctx, orgID, machineName)
+	if err != nil {
+		return false, err
+	}
+	return true, s.EnableLibraryForOrg(ctx, orgID, installed.ID)
+}
+
+// profileErrorMessage returns the user-facing part of an install error
+func profileErrorMessage(err error) string {
+	var badRequest pkg.BadRequestError
+	var internal pkg.InternalError
+	var quota pkg.QuotaExceededError
+	switch {
+	case errors.As(err, &badRequest):
+		return badRequest.Message
+	case errors.As(err, &quota):
+		return quota.Message
+	case errors.As(err, &internal):
+		return internal.Message
+	}
+	return "Error enabling library"
+}
+
+// finishApplication stores the outcome. It runs after the libraries were
+// enabled, so a failure is logged rather than retried.
+func (s *Service) finishApplication(ctx context.Context, applicationID uuid.UUID, status string, summary ProfileSummary) {
+	data, _ := json.Marshal(summary)
+	err := s.store.FinishH5PProfileApplication(context.WithoutCancel(ctx), query.FinishH5PProfileApplicationParams{
+		ID:      applicationID,
+		Status:  status,
+		Summary: data,
+	})
+	if err != nil {
+		slog.Error("Error recording profile application", "error", err, "applicationID", applicationID)
+	}
+}
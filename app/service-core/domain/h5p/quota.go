@@ -0,0 +1,106 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// tierStorageLimitMB mirrors TIER_DEFINITIONS[tier].maxStorageMB in the client.
+// -1 is unlimited.
+var tierStorageLimitMB = map[string]int64{
+	"free":       2048,
+	"starter":    10240,
+	"growth":     51200,
+	"enterprise": -1,
+}
+
+// EditorOrganisation resolves the organisation an editor upload is charged to:
+// the orgId the editor passed, if the user is a member, else the user's default organisation.
+func (s *Service) EditorOrganisation(ctx context.Context, userID uuid.UUID, orgIDParam string) (uuid.UUID, error) {
+	if orgIDParam != "" {
+		orgID, err := uuid.Parse(orgIDParam)
+		if err != nil {
+			return uuid.Nil, pkg.BadRequestError{Message: "Invalid orgId"}
+		}
+		_, err = s.store.CheckUserOrgMembership(ctx, query.CheckUserOrgMembershipParams{
+			UserID:         userID,
+			OrganisationID: orgID,
+		})
+		if err != nil {
+			return uuid.Nil, pkg.ForbiddenError{Err: errors.New("not a member of this organisation")}
+		}
+		return orgID, nil
+	}
+
+	user, err := s.store.SelectUser(ctx, userID)
+	if err != nil {
+		return uuid.Nil, pkg.InternalError{Message: "Error loading user", Err: err}
+	}
+	if !user.DefaultOrganisationID.Valid {
+		return uuid.Nil, pkg.BadRequestError{Message: "orgId is required"}
+	}
+	return user.DefaultOrganisationID.UUID, nil
+}
+
+// checkStorageQuota rejects an upload of size bytes that would take the
+// organisation over its tier's storage quota.
+func (s *Service) checkStorageQuota(ctx context.Context, orgID uuid.UUID, size int64) error {
+	quota, err := s.store.GetOrganisationStorageQuota(ctx, orgID)
+	if err != nil {
+		return pkg.InternalError{Message: "Error loading storage usage", Err: err}
+	}
+
+	limit := storageLimitBytes(quota)
+	if limit >= 0 && quota.BytesUsed+size > limit {
+		return pkg.QuotaExceededError{
+			Message:    "Storage quota exceeded. Upgrade your plan to upload more files.",
+			UsedBytes:  quota.BytesUsed,
+			LimitBytes: limit,
+		}
+	}
+	return nil
+}
+
+// recordStorageUsage adds an upload to the organisation's usage. Failures are
+// logged only — the file is already stored.
+func (s *Service) recordStorageUsage(ctx context.Context, orgID uuid.UUID, size int64) {
+	err := s.store.AddOrganisationStorageUsage(ctx, query.AddOrganisationStorageUsageParams{
+		OrganisationID: orgID,
+		BytesUsed:      size,
+	})
+	if err != nil {
+		slog.Error("Failed to record storage usage", "organisation_id", orgID, "bytes", size, "error", err)
+	}
+}
+
+// storageLimitBytes returns the organisation's quota in bytes, or -1 for unlimited.
+// Active freemium organisations get enterprise limits, as in the client.
+func storageLimitBytes(quota query.GetOrganisationStorageQuotaRow) int64 {
+	tier := quota.SubscriptionTier
+	if quota.IsFreemium && (!quota.FreemiumExpiresAt.Valid || time.Now().Before(quota.FreemiumExpiresAt.Time)) {
+		tier = "enterprise"
+	}
+
+	limitMB := TierStorageLimitMB(tier)
+	if limitMB < 0 {
+		return -1
+	}
+	return limitMB << 20
+}
+
+// TierStorageLimitMB returns a tier's storage quota in MB, or -1 for
+// unlimited. Unknown tiers get the free tier's quota.
+func TierStorageLimitMB(tier string) int64 {
+	limitMB, ok := tierStorageLimitMB[tier]
+	if !ok {
+		return tierStorageLimitMB["free"]
+	}
+	return limitMB
+}
@@ -0,0 +1,136 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// GetLibrarySemantics returns the semantics.json of the latest patch of a library,
+// with the semantics of its editor dependencies merged in as overrides.
+// Served from the DB cache so external editors can validate params without R2.
+func (s *Service) GetLibrarySemantics(ctx context.Context, machineName string, majorVersion, minorVersion int) (json.RawMessage, error) {
+	lib, err := s.store.GetH5PLibraryByMachineNameMajorMinor(ctx, query.GetH5PLibraryByMachineNameMajorMinorParams{
+		MachineName:  machineName,
+		MajorVersion: int32(majorVersion),
+		MinorVersion: int32(minorVersion),
+	})
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: fmt.Sprintf("Library %s %d.%d not found", machineName, majorVersion, minorVersion), Err: err}
+	}
+
+	semantics, err := s.librarySemantics(ctx, lib)
+	if err != nil {
+		return nil, err
+	}
+
+	editorDeps, err := s.store.GetH5PLibraryEditorDependencyTree(ctx, lib.ID)
+	if err != nil {
+		slog.Debug("Error resolving editor dependencies", "library", machineName, "error", err)
+		return semantics, nil
+	}
+	for _, dep := range editorDeps {
+		overrides, err := s.librarySemantics(ctx, dep)
+		if err != nil {
+			slog.Debug("Skipping editor dependency semantics", "dep", dep.MachineName, "error", err)
+			continue
+		}
+		semantics, err = mergeSemanticsOverrides(semantics, overrides)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error merging library semantics", Err: err}
+		}
+	}
+
+	return semantics, nil
+}
+
+// librarySemantics reads a library's semantics.json from the cache. Libraries
+// installed before the cache existed are read from R2 once and cached.
+func (s *Service) librarySemantics(ctx context.Context, lib query.H5pLibrary) (json.RawMessage, error) {
+	cached, err := s.store.GetH5PLibrarySemanticsCache(ctx, lib.ID)
+	if err == nil {
+		return cached.Semantics, nil
+	}
+
+	key := LibraryStorageKey(
+		lib.MachineName,
+		int(lib.MajorVersion), int(lib.MinorVersion), int(lib.PatchVersion),
+		"semantics.json",
+	)
+	semantics := json.RawMessage(`[]`)
+	data, err := s.fileProvider.Download(ctx, key)
+	if err != nil {
+		// Most editor widgets and plain JS libraries ship no semantics.json
+		slog.Debug("No semantics.json found", "library", lib.MachineName, "error", err)
+	} else {
+		if !json.Valid(data) {
+			return nil, pkg.InternalError{Message: "Error parsing semantics.json"}
+		}
+		semantics = data
+	}
+
+	s.cacheLibrarySemantics(ctx, lib.ID, semantics)
+	return semantics, nil
+}
+
+// cacheLibrarySemantics stores semantics for a library. Failures are logged
+// only — the next read falls back to R2.
+func (s *Service) cacheLibrarySemantics(ctx context.Context, libraryID uuid.UUID, semantics json.RawMessage) {
+	err := s.store.UpsertH5PLibrarySemanticsCache(ctx, query.UpsertH5PLibrarySemanticsCacheParams{
+		LibraryID: libraryID,
+		Semantics: semantics,
+	})
+	if err != nil {
+		slog.Warn("Failed to cache library semantics", "libraryId", libraryID, "error", err)
+	}
+}
+
+// mergeSemanticsOverrides applies override fields onto the top-level semantics
+// fields with the same name. Override keys replace the base keys; fields that
+// only exist in the overrides are ignored so dependencies can't add params.
+func mergeSemanticsOverrides(base, overrides json.RawMessage) (json.RawMessage, error) {
+	var overrideFields []map[string]json.RawMessage
+	if err := json.Unmarshal(overrides, &overrideFields); err != nil || len(overrideFields) == 0 {
+		return base, nil
+	}
+
+	var fields []map[string]json.RawMessage
+	if err := json.Unmarshal(base, &fields); err != nil {
+		return nil, fmt.Errorf("parsing semantics: %w", err)
+	}
+
+	byName := make(map[string]map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		var name string
+		if err := json.Unmarshal(f["name"], &name); err == nil {
+			byName[name] = f
+		}
+	}
+
+	merged := false
+	for _, o := range overrideFields {
+		var name string
+		if err := json.Unmarshal(o["name"], &name); err != nil {
+			continue
+		}
+		field, ok := byName[name]
+		if !ok {
+			continue
+		}
+		for k, v := range o {
+			field[k] = v
+		}
+		merged = true
+	}
+	if !merged {
+		return base, nil
+	}
+
+	return json.Marshal(fields)
+}
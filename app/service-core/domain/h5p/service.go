@@ -2,9 +2,13 @@ package h5p
 
 import (
 	"app/pkg"
+	"app/pkg/cfbrowser"
+	"app/pkg/ids"
+	"app/pkg/jobs"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -35,6 +39,10 @@ type store interface {
 	InsertH5PLibraryDependency(ctx context.Context, arg query.InsertH5PLibraryDependencyParams) error
 	DeleteH5PLibraryDependencies(ctx context.Context, libraryID uuid.UUID) error
 
+	// Semantics cache
+	GetH5PLibrarySemanticsCache(ctx context.Context, libraryID uuid.UUID) (query.H5pLibrarySemanticsCache, error)
+	UpsertH5PLibrarySemanticsCache(ctx context.Context, arg query.UpsertH5PLibrarySemanticsCacheParams) error
+
 	// Hub cache
 	GetH5PHubCache(ctx context.Context, cacheKey string) (query.H5pHubCache, error)
 	UpsertH5PHubCache(ctx context.Context, arg query.UpsertH5PHubCacheParams) (query.H5pHubCache, error)
@@ -42,7 +50,9 @@ type store interface {
 
 	// Org libraries
 	EnableH5POrgLibrary(ctx context.Context, arg query.EnableH5POrgLibraryParams) error
+	EnableH5POrgLibraryVersions(ctx context.Context, arg query.EnableH5POrgLibraryVersionsParams) error
 	DisableH5POrgLibrary(ctx context.Context, arg query.DisableH5POrgLibraryParams) error
+	ListH5POrgLibraries(ctx context.Context, orgID uuid.UUID) ([]query.ListH5POrgLibrariesRow, error)
 
 	// Content
 	CreateH5PContent(ctx context.Context, arg query.CreateH5PContentParams) (query.H5pContent, error)
@@ -58,7 +68,52 @@ type store interface {
 	// Dependency tree (used by editor and player)
 	GetH5PLibraryFullDependencyTree(ctx context.Context, libraryID uuid.UUID) ([]query.H5pLibrary, error)
 	GetH5PLibraryEditorDependencyTree(ctx context.Context, libraryID uuid.UUID) ([]query.H5pLibrary, error)
+	GetH5PLibraryExportTree(ctx context.Context, libraryIds []uuid.UUID) ([]query.H5pLibrary, error)
 	GetH5PLibraryDependencies(ctx context.Context, libraryID uuid.UUID) ([]query.GetH5PLibraryDependenciesRow, error)
+
+	// Storage quotas
+	CheckUserOrgMembership(ctx context.Context, arg query.CheckUserOrgMembershipParams) (uuid.UUID, error)
+	SelectUser(ctx context.Context, id uuid.UUID) (query.User, error)
+	GetOrganisationStorageQuota(ctx context.Context, id uuid.UUID) (query.GetOrganisationStorageQuotaRow, error)
+	AddOrganisationStorageUsage(ctx context.Context, arg query.AddOrganisationStorageUsageParams) error
+
+	// Install profiles
+	ListH5PInstallProfiles(ctx context.Context) ([]query.H5pInstallProfile, error)
+	GetH5PInstallProfile(ctx context.Context, id uuid.UUID) (query.H5pInstallProfile, error)
+	GetDefaultH5PInstallProfile(ctx context.Context) (query.H5pInstallProfile, error)
+	CreateH5PInstallProfile(ctx context.Context, arg query.CreateH5PInstallProfileParams) (query.H5pInstallProfile, error)
+	UpdateH5PInstallProfile(ctx context.Context, arg query.UpdateH5PInstallProfileParams) (query.H5pInstallProfile, error)
+	ClearDefaultH5PInstallProfile(ctx context.Context, id uuid.UUID) error
+	DeleteH5PInstallProfile(ctx context.Context, id uuid.UUID) (int64, error)
+	CreateH5PProfileApplication(ctx context.Context, arg query.CreateH5PProfileApplicationParams) (query.H5pProfileApplication, error)
+	GetH5PProfileApplication(ctx context.Context, id uuid.UUID) (query.H5pProfileApplication, error)
+	ListH5PProfileApplications(ctx context.Context, arg query.ListH5PProfileApplicationsParams) ([]query.H5pProfileApplication, error)
+	FinishH5PProfileApplication(ctx context.Context, arg query.FinishH5PProfileApplicationParams) error
+
+	// Editor bundle variants
+	ListH5PBundleVariants(ctx context.Context) ([]query.H5pBundleVariant, error)
+	UpsertH5PBundleVariant(ctx context.Context, arg query.UpsertH5PBundleVariantParams) (query.H5pBundleVariant, error)
+	DeleteH5PBundleVariant(ctx context.Context, name string) (int64, error)
+	RecordH5PBundleReport(ctx context.Context, arg query.RecordH5PBundleReportParams) error
+	ListH5PBundleReportTotals(ctx context.Context, day time.Time) ([]query.ListH5PBundleReportTotalsRow, error)
+
+	// Content upgrades
+	ListOutdatedH5PContentLibraries(ctx context.Context, orgID uuid.UUID) ([]query.ListOutdatedH5PContentLibrariesRow, error)
+	ListOutdatedH5PContent(ctx context.Context, arg query.ListOutdatedH5PContentParams) ([]query.ListOutdatedH5PContentRow, error)
+	UpgradeH5PContentLibrary(ctx context.Context, arg query.UpgradeH5PContentLibraryParams) (int64, error)
+
+	// Library storage
+	ListH5PLibraryVersions(ctx context.Context, machineName sql.NullString) ([]query.ListH5PLibraryVersionsRow, error)
+	QueueOrphanedH5PLibraryFiles(ctx context.Context, arg query.QueueOrphanedH5PLibraryFilesParams) (int64, error)
+	CancelPendingDeletions(ctx context.Context, storageKeys []string) error
+
+	// Media library
+	CreateH5PMediaAsset(ctx context.Context, arg query.CreateH5PMediaAssetParams) (query.H5pMediaAsset, error)
+	GetH5PMediaAssetByChecksum(ctx context.Context, arg query.GetH5PMediaAssetByChecksumParams) (query.H5pMediaAsset, error)
+	GetH5PMediaAsset(ctx context.Context, arg query.GetH5PMediaAssetParams) (query.H5pMediaAsset, error)
+	TouchH5PMediaAsset(ctx context.Context, arg query.TouchH5PMediaAssetParams) error
+	ListH5PMediaAssets(ctx context.Context, arg query.ListH5PMediaAssetsParams) ([]query.H5pMediaAsset, error)
+	DeleteH5PMediaAsset(ctx context.Context, arg query.DeleteH5PMediaAssetParams) error
 }
 
 // Service handles H5P library management
@@ -67,29 +122,64 @@ type Service struct {
 	store        store
 	fileProvider file.Provider
 	hubClient    *HubClient
+	uploadRules  map[string]uploadRule
+	pdfRenderer  pdfRenderer // nil without a browser renderer
+	publisher    publisher   // nil disables webhook events
+	jobs         *jobs.Queue // nil skips cleaning up promoted temp files and disables install profiles
 }
 
-// NewService creates a new H5P service
-func NewService(cfg *config.Config, store store, fileProvider file.Provider) *Service {
+// pdfRenderer prints generated documents; satisfied by every cfbrowser.Renderer.
+type pdfRenderer interface {
+	RenderHTMLPDF(ctx context.Context, html string, opts cfbrowser.PDFOptions) ([]byte, error)
+}
+
+// NewService creates a new H5P service and registers its background jobs with
+// queue. Content and library events are published to organisation webhooks
+// via publisher, and PDF exports printed by pdf; either may be nil.
+func NewService(cfg *config.Config, store store, fileProvider file.Provider, queue *jobs.Queue, publisher publisher, pdf pdfRenderer) *Service {
 	hubURL := cfg.H5PHubURL
 	if hubURL == "" {
 		hubURL = defaultHubURL
 	}
-	return &Service{
+	hubClient := NewHubClient(hubURL)
+	if cfg.DevOfflineMode {
+		slog.Warn("DEV_OFFLINE_MODE is set: the H5P Hub is served from embedded fixture packages")
+		hubClient = NewOfflineHubClient()
+	}
+	uploadRules, err := uploadRulesFromConfig(cfg.H5PUploadLimits)
+	if err != nil {
+		slog.Warn("Ignoring H5P upload limits", "error", err)
+	}
+	s := &Service{
 		cfg:          cfg,
 		store:        store,
 		fileProvider: fileProvider,
-		hubClient:    NewHubClient(hubURL),
-	}
+		hubClient:    hubClient,
+		uploadRules:  uploadRules,
+		pdfRenderer:  pdf,
+		publisher:    publisher,
+		jobs:         queue,
+	}
+	if queue != nil {
+		queue.Register(jobCleanupTempFiles, s.runCleanupTempFiles)
+		queue.Register(jobAddMediaAsset, s.runAddMediaAsset, jobs.MaxAttempts(3))
+		s.registerProfileJobs(queue)
+	}
+	return s
 }
 
 // GetContentTypeCache returns the cached content type list, refreshing from Hub if expired.
-// Merges hub data with local install status.
-func (s *Service) GetContentTypeCache(ctx context.Context) ([]ContentTypeCacheEntry, error) {
+// Merges hub data with local install status and leaves out the libraries
+// disabled or restricted for the organisation.
+func (s *Service) GetContentTypeCache(ctx context.Context, orgID uuid.UUID) ([]ContentTypeCacheEntry, error) {
 	hubData, err := s.getCachedHubData(ctx)
 	if err != nil {
 		return nil, pkg.InternalError{Message: "Error fetching content type cache", Err: err}
 	}
+	unavailable, err := s.unavailableLibraries(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get all installed libraries to mark install status
 	installed, err := s.store.ListH5PRunnableLibraries(ctx)
@@ -97,13 +187,20 @@ func (s *Service) GetContentTypeCache(ctx context.Context) ([]ContentTypeCacheEn
 		return nil, pkg.InternalError{Message: "Error listing installed libraries", Err: err}
 	}
 
+	// Updates install side by side, so compare against the latest version
 	installedMap := make(map[string]query.H5pLibrary)
 	for _, lib := range installed {
+		if prev, ok := installedMap[lib.MachineName]; ok && !libraryVersion(lib).newerThan(libraryVersion(prev)) {
+			continue
+		}
 		installedMap[lib.MachineName] = lib
 	}
 
 	entries := make([]ContentTypeCacheEntry, 0, len(hubData.ContentTypes))
 	for _, ct := range hubData.ContentTypes {
+		if _, ok := unavailable[ct.ID]; ok {
+			continue
+		}
 		entry := ContentTypeCacheEntry{
 			ID:            ct.ID,
 			MachineName:   ct.ID, // H5P editor JS expects machineName field
@@ -128,12 +225,7 @@ func (s *Service) GetContentTypeCache(ctx context.Context) ([]ContentTypeCacheEn
 			entry.LocalMajorVersion = int(lib.MajorVersion)
 			entry.LocalMinorVersion = int(lib.MinorVersion)
 			entry.LocalPatchVersion = int(lib.PatchVersion)
-			// Check if hub version is newer
-			if ct.Version.Major > int(lib.MajorVersion) ||
-				(ct.Version.Major == int(lib.MajorVersion) && ct.Version.Minor > int(lib.MinorVersion)) ||
-				(ct.Version.Major == int(lib.MajorVersion) && ct.Version.Minor == int(lib.MinorVersion) && ct.Version.Patch > int(lib.PatchVersion)) {
-				entry.UpdateAvailable = true
-			}
+			entry.UpdateAvailable = ct.Version.newerThan(libraryVersion(lib))
 		}
 
 		entries = append(entries, entry)
@@ -145,7 +237,7 @@ func (s *Service) GetContentTypeCache(ctx context.Context) ([]ContentTypeCacheEn
 // getCachedHubData returns hub data from cache or fetches fresh
 func (s *Service) getCachedHubData(ctx context.Context) (*HubResponse, error) {
 	// Try cache first
-	cached, err := s.store.GetH5PHubCache(ctx, hubCacheKey)
+	cached, err := s.store.GetH5PHubCache(ctx, s.hubClient.cacheKey(hubCacheKey))
 	if err == nil {
 		var hubResp HubResponse
 		if err := json.Unmarshal(cached.Data, &hubResp); err == nil {
@@ -160,27 +252,33 @@ func (s *Service) getCachedHubData(ctx context.Context) (*HubResponse, error) {
 		return nil, fmt.Errorf("fetching from hub: %w", err)
 	}
 
-	// Store in cache
-	data, err := json.Marshal(hubResp)
+	// Store in cache (data is returned even if caching fails)
+	s.cacheHubData(ctx, s.hubClient.cacheKey(hubCacheKey), hubResp)
+
+	return hubResp, nil
+}
+
+// cacheHubData stores a Hub response under key for hubCacheTTL and cleans up
+// expired entries. Failures are logged only.
+func (s *Service) cacheHubData(ctx context.Context, key string, v any) {
+	data, err := json.Marshal(v)
 	if err != nil {
-		slog.Warn("Failed to marshal hub response for cache", "error", err)
-		return hubResp, nil // Return data even if caching fails
+		slog.Warn("Failed to marshal hub response for cache", "key", key, "error", err)
+		return
 	}
 
 	_, err = s.store.UpsertH5PHubCache(ctx, query.UpsertH5PHubCacheParams{
-		ID:        uuid.New(),
-		CacheKey:  hubCacheKey,
+		ID:        ids.New(),
+		CacheKey:  key,
 		Data:      data,
 		ExpiresAt: time.Now().Add(hubCacheTTL),
 	})
 	if err != nil {
-		slog.Warn("Failed to cache hub data", "error", err)
+		slog.Warn("Failed to cache hub data", "key", key, "error", err)
 	}
 
 	// Clean up expired entries
 	_ = s.store.DeleteExpiredH5PHubCache(ctx)
-
-	return hubResp, nil
 }
 
 // GetHubRegistry returns the hub data in Catharsis format with local icon URLs.
@@ -201,7 +299,7 @@ func (s *Service) GetHubRegistry(ctx context.Context, baseURL string) (*HubRegis
 
 	return &HubRegistryResponse{
 		ContentTypes: types,
-		APIVersion:   HubVersion{Major: 1, Minor: 26},
+		APIVersion:   coreAPIVersion,
 		Outdated:     false,
 	}, nil
 }
@@ -229,31 +327,15 @@ func (s *Service) GetLibraryPackage(ctx context.Context, machineName string) ([]
 // It resolves h5p-standalone-style paths (which use {machineName}-{major}.{minor} or
 // just {machineName}) into R2 keys (which use {machineName}-{major}.{minor}.{patch}).
 func (s *Service) GetLibraryAsset(ctx context.Context, assetPath string) ([]byte, string, error) {
-	// Try direct path first (already has full version)
-	key := "h5p-libraries/extracted/" + assetPath
-	data, err := s.fileProvider.Download(ctx, key)
-	if err == nil {
-		contentType := detectContentType(assetPath)
-		return data, contentType, nil
-	}
-
-	// Direct path failed — resolve version from DB.
-	// h5p-standalone requests paths like:
-	//   "H5P.Transition-1.0/library.json"  (machineName-major.minor)
-	//   "H5P.MultiChoice/library.json"      (machineName only, no version)
-	resolved, resolveErr := s.resolveLibraryAssetPath(ctx, assetPath)
-	if resolveErr != nil {
-		return nil, "", pkg.NotFoundError{Message: "Asset not found", Err: err}
+	asset, err := s.ResolveLibraryAsset(ctx, assetPath)
+	if err != nil {
+		return nil, "", err
 	}
-
-	key = "h5p-libraries/extracted/" + resolved
-	data, err = s.fileProvider.Download(ctx, key)
+	data, err := s.ReadAsset(ctx, asset)
 	if err != nil {
-		return nil, "", pkg.NotFoundError{Message: "Asset not found", Err: err}
+		return nil, "", err
 	}
-
-	contentType := detectContentType(resolved)
-	return data, contentType, nil
+	return data, asset.ContentType, nil
 }
 
 // BackfillLibraryMetadata reads library.json from R2 for each installed library
@@ -350,7 +432,8 @@ func (s *Service) resolveLibraryAssetPath(ctx context.Context, assetPath string)
 	return fullFolder + "/" + filePath, nil
 }
 
-// InstallLibrary downloads and installs a library from the H5P Hub
+// InstallLibrary downloads and installs a library from the H5P Hub, along
+// with any dependencies its package doesn't bundle.
 func (s *Service) InstallLibrary(ctx context.Context, machineName string) (*LibraryInfo, error) {
 	slog.Info("Installing H5P library", "machineName", machineName)
 
@@ -360,6 +443,48 @@ func (s *Service) InstallLibrary(ctx context.Context, machineName string) (*Libr
 		return nil, pkg.InternalError{Message: "Error downloading H5P package", Err: err}
 	}
 
+	run := newInstallRun(uuid.Nil, machineName, int64(len(packageData)))
+	return s.installPackageRun(ctx, run, machineName, packageData)
+}
+
+// InstallLibraryForOrg installs a library from the H5P Hub on behalf of an
+// organisation, charging the size of its package and of any dependency
+// packages fetched with it to its storage quota.
+func (s *Service) InstallLibraryForOrg(ctx context.Context, orgID uuid.UUID, machineName string) (*LibraryInfo, error) {
+	slog.Info("Installing H5P library", "machineName", machineName, "orgID", orgID)
+
+	packageData, err := s.hubClient.DownloadPackage(machineName)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error downloading H5P package", Err: err}
+	}
+	if err := s.checkStorageQuota(ctx, orgID, int64(len(packageData))); err != nil {
+		return nil, err
+	}
+
+	run := newInstallRun(orgID, machineName, int64(len(packageData)))
+	lib, err := s.installPackageRun(ctx, run, machineName, packageData)
+	if run.size > int64(len(packageData)) {
+		// Dependencies installed before a failure are kept, so they're charged
+		s.recordStorageUsage(ctx, orgID, run.size-int64(len(packageData)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.recordStorageUsage(ctx, orgID, int64(len(packageData)))
+	s.publish(ctx, orgID, EventLibraryInstalled, map[string]any{
+		"libraryId":   lib.ID,
+		"machineName": lib.MachineName,
+		"version":     fmt.Sprintf("%d.%d.%d", lib.MajorVersion, lib.MinorVersion, lib.PatchVersion),
+	})
+	return lib, nil
+}
+
+// installPackageRun extracts a downloaded .h5p package and installs its
+// libraries, fetching dependencies the package doesn't bundle from the Hub.
+func (s *Service) installPackageRun(ctx context.Context, run *installRun, machineName string, packageData []byte) (*LibraryInfo, error) {
+	run.chain = append(run.chain, machineName)
+	defer func() { run.chain = run.chain[:len(run.chain)-1] }()
+
 	// Extract the package
 	extracted, err := ExtractH5PPackage(packageData)
 	if err != nil {
@@ -398,9 +523,23 @@ func (s *Service) InstallLibrary(ctx context.Context, machineName string) (*Libr
 		}
 	}
 
+	// Fetch what the package depends on but doesn't bundle, recursively
+	libJSONs := make([]LibraryJSON, len(installed))
+	for i, il := range installed {
+		libJSONs[i] = il.libJSON
+	}
+	if err := s.resolveDependencies(ctx, run, libJSONs); err != nil {
+		return nil, err
+	}
+
 	// Pass 2: Store dependencies now that all libraries exist in the DB
 	for _, il := range installed {
-		if err := s.storeDependencies(ctx, il.dbLib.ID, il.libJSON); err != nil {
+		err := s.storeDependencies(ctx, il.dbLib.ID, il.libJSON)
+		var missing MissingDependenciesError
+		if errors.As(err, &missing) {
+			return nil, pkg.InternalError{Message: fmt.Sprintf("Error installing %s", machineName), Err: err}
+		}
+		if err != nil {
 			slog.Warn("Failed to store dependencies (pass 2)", "library", il.libJSON.MachineName, "error", err)
 		}
 	}
@@ -443,6 +582,12 @@ func (s *Service) installSingleLibrary(ctx context.Context, extLib ExtractedLibr
 	minor := int(lj.MinorVersion)
 	patch := int(lj.PatchVersion)
 
+	// Reinstalling after a delete: the sweeper mustn't remove the new files
+	storageKeys := libraryStorageKeys(lj.MachineName, major, minor, patch)
+	if err := s.store.CancelPendingDeletions(ctx, storageKeys); err != nil {
+		return nil, fmt.Errorf("cancelling pending deletions: %w", err)
+	}
+
 	// Upload files to R2/S3 concurrently (up to 20 at a time)
 	type uploadItem struct {
 		key         string
@@ -509,7 +654,7 @@ func (s *Service) installSingleLibrary(ctx context.Context, extLib ExtractedLibr
 
 	// Upsert library record
 	lib, err := s.store.UpsertH5PLibrary(ctx, query.UpsertH5PLibraryParams{
-		ID:            uuid.New(),
+		ID:            ids.New(),
 		MachineName:   lj.MachineName,
 		MajorVersion:  int32(major),
 		MinorVersion:  int32(minor),
@@ -530,6 +675,17 @@ func (s *Service) installSingleLibrary(ctx context.Context, extLib ExtractedLibr
 	if err != nil {
 		return nil, fmt.Errorf("upserting library %s: %w", lj.MachineName, err)
 	}
+	// An orphan reconcile may have queued the files while they were uploaded
+	if err := s.store.CancelPendingDeletions(ctx, storageKeys); err != nil {
+		return nil, fmt.Errorf("cancelling pending deletions: %w", err)
+	}
+
+	// Cache semantics.json so the schema endpoint doesn't hit R2 per request
+	semantics := json.RawMessage(`[]`)
+	if data, ok := extLib.Files["semantics.json"]; ok && json.Valid(data) {
+		semantics = data
+	}
+	s.cacheLibrarySemantics(ctx, lib.ID, semantics)
 
 	// Note: dependencies are stored in a second pass by InstallLibrary
 	// after all libraries in the package have been inserted into the DB.
@@ -541,24 +697,36 @@ func (s *Service) installSingleLibrary(ctx context.Context, extLib ExtractedLibr
 	return &lib, nil
 }
 
-// storeDependencies saves library dependency relationships
+// storeDependencies saves library dependency relationships. Dependencies that
+// aren't installed are skipped; if a preloaded or editor one is among them,
+// a MissingDependenciesError naming them is returned once the rest are saved.
 func (s *Service) storeDependencies(ctx context.Context, libraryID uuid.UUID, lj LibraryJSON) error {
 	// Clear existing deps
 	if err := s.store.DeleteH5PLibraryDependencies(ctx, libraryID); err != nil {
 		return err
 	}
 
+	var missing []string
+
 	saveDeps := func(deps []LibraryDep, depType string) error {
 		for _, dep := range deps {
 			// Look up the dependency library (it must already be installed)
-			depLib, err := s.store.GetH5PLibraryByMachineName(ctx, dep.MachineName)
+			depLib, err := s.store.GetH5PLibraryByMachineNameMajorMinor(ctx, query.GetH5PLibraryByMachineNameMajorMinorParams{
+				MachineName:  dep.MachineName,
+				MajorVersion: int32(dep.MajorVersion),
+				MinorVersion: int32(dep.MinorVersion),
+			})
 			if err != nil {
+				name := fmt.Sprintf("%s %d.%d", dep.MachineName, dep.MajorVersion, dep.MinorVersion)
 				slog.Warn("Dependency not found in DB — cannot link",
-					"library", lj.MachineName, "dep", dep.MachineName, "type", depType)
-				continue // Skip deps that aren't installed yet
+					"library", lj.MachineName, "dep", name, "type", depType)
+				if depType != "dynamic" {
+					missing = append(missing, name)
+				}
+				continue
 			}
 			err = s.store.InsertH5PLibraryDependency(ctx, query.InsertH5PLibraryDependencyParams{
-				ID:             uuid.New(),
+				ID:             ids.New(),
 				LibraryID:      libraryID,
 				DependsOnID:    depLib.ID,
 				DependencyType: depType,
@@ -576,7 +744,13 @@ func (s *Service) storeDependencies(ctx context.Context, libraryID uuid.UUID, lj
 	if err := saveDeps(lj.DynamicDependencies, "dynamic"); err != nil {
 		return err
 	}
-	return saveDeps(lj.EditorDependencies, "editor")
+	if err := saveDeps(lj.EditorDependencies, "editor"); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return MissingDependenciesError{Library: lj.MachineName, Dependencies: missing}
+	}
+	return nil
 }
 
 // ListInstalledLibraries returns all installed libraries
@@ -606,42 +780,67 @@ func (s *Service) ListInstalledLibraries(ctx context.Context) ([]LibraryInfo, er
 	return result, nil
 }
 
-// DeleteLibrary removes a library and its files from storage
-func (s *Service) DeleteLibrary(ctx context.Context, machineName string) error {
-	lib, err := s.store.GetH5PLibraryByMachineName(ctx, machineName)
+// DeleteLibrary removes every version of a library. Their packages and
+// extracted files are queued for the storage sweeper along with the delete.
+// A dry run deletes nothing and lists the stored files that would go.
+func (s *Service) DeleteLibrary(ctx context.Context, machineName string, dryRun bool) (*LibraryDeletion, error) {
+	versions, err := s.store.ListH5PLibraryVersions(ctx, sql.NullString{String: machineName, Valid: true})
 	if err != nil {
-		return pkg.NotFoundError{Message: "Library not found", Err: err}
+		return nil, pkg.InternalError{Message: "Error loading library", Err: err}
+	}
+	if len(versions) == 0 {
+		return nil, pkg.NotFoundError{Message: "Library not found"}
 	}
 
-	// Remove files from storage
-	if lib.PackagePath.Valid {
-		if err := s.fileProvider.Remove(ctx, lib.PackagePath.String); err != nil {
-			slog.Warn("Failed to remove package file", "path", lib.PackagePath.String, "error", err)
+	deletion := &LibraryDeletion{MachineName: machineName, StorageKeys: make([]string, 0)}
+	for _, v := range versions {
+		deletion.Versions = append(deletion.Versions, HubVersion{Major: int(v.MajorVersion), Minor: int(v.MinorVersion), Patch: int(v.PatchVersion)})
+		deletion.StorageKeys = append(deletion.StorageKeys, LibraryStorageKey(v.MachineName, int(v.MajorVersion), int(v.MinorVersion), int(v.PatchVersion), "")+"/")
+		if v.PackagePath.Valid {
+			deletion.StorageKeys = append(deletion.StorageKeys, v.PackagePath.String)
 		}
 	}
-	// Note: extracted files could be cleaned up too, but we'd need to list all keys.
-	// For now we just remove the DB record which cascades dependencies.
 
-	if err := s.store.DeleteH5PLibraryByMachineName(ctx, machineName); err != nil {
-		return pkg.InternalError{Message: "Error deleting library", Err: err}
+	if dryRun {
+		files, err := s.listStoredFiles(ctx, deletion.StorageKeys)
+		if err != nil {
+			return nil, err
+		}
+		deletion.Files = files
+		return deletion, nil
 	}
 
-	slog.Info("Deleted library", "machineName", machineName)
-	return nil
+	if err := s.store.DeleteH5PLibraryByMachineName(ctx, machineName); err != nil {
+		return nil, pkg.InternalError{Message: "Error deleting library", Err: err}
+	}
+	deletion.Deleted = true
+	slog.Info("Deleted library", "machineName", machineName, "versions", len(versions))
+	return deletion, nil
 }
 
-// EnableLibraryForOrg enables a platform library for a specific organisation
+// EnableLibraryForOrg enables a platform library for a specific organisation,
+// along with its other installed versions
 func (s *Service) EnableLibraryForOrg(ctx context.Context, orgID, libraryID uuid.UUID) error {
-	return s.store.EnableH5POrgLibrary(ctx, query.EnableH5POrgLibraryParams{
-		ID:        uuid.New(),
+	err := s.store.EnableH5POrgLibrary(ctx, query.EnableH5POrgLibraryParams{
+		ID:        ids.New(),
 		OrgID:     orgID,
 		LibraryID: libraryID,
 	})
+	if err != nil {
+		return err
+	}
+	return s.store.EnableH5POrgLibraryVersions(ctx, query.EnableH5POrgLibraryVersionsParams{
+		OrgID: orgID,
+		ID:    libraryID,
+	})
 }
 
-// DisableLibraryForOrg disables a platform library for a specific organisation
+// DisableLibraryForOrg disables a platform library for a specific
+// organisation. It's hidden from the organisation's editor and new content
+// can't use it; existing content is left alone.
 func (s *Service) DisableLibraryForOrg(ctx context.Context, orgID, libraryID uuid.UUID) error {
 	return s.store.DisableH5POrgLibrary(ctx, query.DisableH5POrgLibraryParams{
+		ID:        ids.New(),
 		OrgID:     orgID,
 		LibraryID: libraryID,
 	})
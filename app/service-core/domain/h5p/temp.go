@@ -2,33 +2,40 @@ package h5p
 
 import (
 	"app/pkg"
-	"bytes"
+	"app/pkg/ids"
 	"context"
 	"fmt"
 	"image"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"strings"
 
-	"service-core/domain/file"
-
 	"github.com/google/uuid"
 )
 
-// UploadTempFile uploads a file to temporary storage and returns metadata
-func (s *Service) UploadTempFile(ctx context.Context, userID uuid.UUID, filename string, data []byte, contentType string) (*TempFileResult, error) {
-	tempID := uuid.New()
+// UploadTempFile streams a file of size bytes for a semantics field to
+// temporary storage and returns metadata. The file must pass the field type's
+// whitelist and size limit, and fit in the organisation's storage quota. It's
+// also added to the organisation's media library for reuse. body is read
+// again from the start for image dimensions.
+func (s *Service) UploadTempFile(ctx context.Context, orgID, userID uuid.UUID, field UploadField, filename string, body io.ReadSeeker, size int64, contentType string) (*TempFileResult, error) {
+	if err := s.ValidateTempUpload(field, filename, size); err != nil {
+		return nil, err
+	}
+	if err := s.checkStorageQuota(ctx, orgID, size); err != nil {
+		return nil, err
+	}
+
+	tempID := ids.New()
 	key := fmt.Sprintf("h5p-temp/%s/%s/%s", userID, tempID, filename)
 
-	err := s.fileProvider.Upload(ctx, &file.File{
-		Key:         key,
-		ContentType: contentType,
-		Data:        data,
-	})
+	err := s.fileProvider.UploadStream(ctx, key, contentType, body, size)
 	if err != nil {
 		return nil, pkg.InternalError{Message: "Error uploading temp file", Err: err}
 	}
+	s.recordStorageUsage(ctx, orgID, size)
 
 	// H5P editor convention: return relative path with #tmp suffix.
 	// H5P.getPath() in h5p.js checks for #tmp suffix to use H5PEditor.filesPath as prefix.
@@ -40,24 +47,34 @@ func (s *Service) UploadTempFile(ctx context.Context, userID uuid.UUID, filename
 
 	// Try to detect image dimensions
 	if strings.HasPrefix(contentType, "image/") {
-		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return nil, pkg.InternalError{Message: "Error reading temp file", Err: err}
+		}
+		cfg, _, err := image.DecodeConfig(body)
 		if err == nil {
 			result.Width = cfg.Width
 			result.Height = cfg.Height
 		}
 	}
 
+	s.queueMediaAsset(ctx, mediaUpload{
+		OrgID:    orgID,
+		UserID:   userID,
+		TempKey:  key,
+		Filename: filename,
+		Mime:     contentType,
+		Width:    result.Width,
+		Height:   result.Height,
+	})
 	return result, nil
 }
 
-// GetTempFile downloads a file from temporary storage
-func (s *Service) GetTempFile(ctx context.Context, filePath string) ([]byte, string, error) {
-	key := "h5p-temp/" + filePath
-	data, err := s.fileProvider.Download(ctx, key)
-	if err != nil {
-		return nil, "", pkg.NotFoundError{Message: "Temp file not found", Err: err}
+// ResolveTempFile finds a file in temporary storage. Temp files aren't changed
+// once uploaded, so the key alone identifies their bytes.
+func (s *Service) ResolveTempFile(filePath string) *Asset {
+	return &Asset{
+		Key:         "h5p-temp/" + filePath,
+		ContentType: detectContentType(filePath),
+		Private:     true,
 	}
-
-	contentType := detectContentType(filePath)
-	return data, contentType, nil
 }
@@ -0,0 +1,64 @@
+package h5p
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const (
+	// thumbnailSize is the longest edge of a media thumbnail, in pixels
+	thumbnailSize = 320
+	// maxThumbnailPixels skips images too large to decode safely
+	maxThumbnailPixels = 40_000_000
+)
+
+// thumbnail scales an image down to fit thumbnailSize, averaging the pixels
+// each thumbnail pixel covers, and encodes it as PNG to keep transparency.
+// It returns nil for images that already fit or can't be decoded.
+func thumbnail(data []byte) []byte {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width*cfg.Height > maxThumbnailPixels {
+		return nil
+	}
+	if cfg.Width <= thumbnailSize && cfg.Height <= thumbnailSize {
+		return nil
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := thumbnailSize, thumbnailSize
+	if w > h {
+		th = max(1, h*thumbnailSize/w)
+	} else {
+		tw = max(1, w*thumbnailSize/h)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, tw, th))
+	for y := range th {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+(y+1)*h/th
+		for x := range tw {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+(x+1)*w/tw
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,305 @@
+package h5p
+
+import (
+	"app/pkg"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxUpgradeBatch caps the content items one UpgradeContent call migrates.
+	MaxUpgradeBatch = 50
+	// MaxUpgradePlanLimit caps the outdated items in one upgrade plan.
+	MaxUpgradePlanLimit = 200
+)
+
+// Per-item outcomes of a content upgrade batch
+const (
+	UpgradeStatusUpgraded = "upgraded"
+	UpgradeStatusFailed   = "failed"
+)
+
+// OutdatedLibrary is a library an organisation has content on older minor or
+// major versions of, with the installed version that content can move to.
+type OutdatedLibrary struct {
+	MachineName    string             `json:"machineName"`
+	Title          string             `json:"title"`
+	Target         HubVersion         `json:"target"`
+	UpgradesScript string             `json:"upgradesScript,omitempty"`
+	Versions       []OutdatedVersions `json:"versions"`
+	ContentCount   int64              `json:"contentCount"`
+}
+
+// OutdatedVersions counts the content on one older version of a library.
+type OutdatedVersions struct {
+	Major        int   `json:"major"`
+	Minor        int   `json:"minor"`
+	ContentCount int64 `json:"contentCount"`
+}
+
+// UpgradePlan is what a client needs to upgrade a library's content: the
+// target version, its semantics and upgrades.js, and the outdated items with
+// their parameters. The client runs upgrades.js, which is JavaScript, on each
+// item and sends the results back to UpgradeContent.
+type UpgradePlan struct {
+	MachineName    string            `json:"machineName"`
+	Target         HubVersion        `json:"target"`
+	UpgradesScript string            `json:"upgradesScript,omitempty"` // empty when the library has none
+	Semantics      json.RawMessage   `json:"semantics"`
+	Items          []UpgradePlanItem `json:"items"`
+}
+
+// UpgradePlanItem is an outdated content item and its current parameters.
+type UpgradePlanItem struct {
+	ContentID uuid.UUID       `json:"contentId"`
+	Title     string          `json:"title"`
+	Version   HubVersion      `json:"version"`
+	Params    json.RawMessage `json:"params"`
+	Metadata  json.RawMessage `json:"metadata"`
+	Locked    bool            `json:"locked"`
+}
+
+// UpgradeItem is one content item's parameters migrated to the target
+// version by upgrades.js.
+type UpgradeItem struct {
+	ContentID uuid.UUID       `json:"contentId"`
+	Params    json.RawMessage `json:"params"`
+	Metadata  json.RawMessage `json:"metadata"`
+}
+
+// UpgradeResult is the outcome for one item of a batch.
+type UpgradeResult struct {
+	ContentID uuid.UUID `json:"contentId"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// UpgradeReport is the outcome of a batch, item by item.
+type UpgradeReport struct {
+	Target   HubVersion      `json:"target"`
+	Upgraded int             `json:"upgraded"`
+	Failed   int             `json:"failed"`
+	Results  []UpgradeResult `json:"results"`
+}
+
+// upgradesScriptURL returns where a library's upgrades.js is served, or ""
+// when the library doesn't have one. Content moves to such a library with its
+// parameters unchanged.
+func (s *Service) upgradesScriptURL(ctx context.Context, lib query.H5pLibrary) string {
+	major, minor, patch := int(lib.MajorVersion), int(lib.MinorVersion), int(lib.PatchVersion)
+	if _, err := s.fileProvider.Download(ctx, LibraryStorageKey(lib.MachineName, major, minor, patch, "upgrades.js")); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("/api/h5p/libraries/%s-%d.%d.%d/upgrades.js", lib.MachineName, major, minor, patch)
+}
+
+func libraryVersion(lib query.H5pLibrary) HubVersion {
+	return HubVersion{Major: int(lib.MajorVersion), Minor: int(lib.MinorVersion), Patch: int(lib.PatchVersion)}
+}
+
+// ListOutdatedLibraries returns the libraries an organisation has content on
+// outdated versions of, by machine name.
+func (s *Service) ListOutdatedLibraries(ctx context.Context, orgID uuid.UUID) ([]OutdatedLibrary, error) {
+	rows, err := s.store.ListOutdatedH5PContentLibraries(ctx, orgID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing outdated content", Err: err}
+	}
+	result := make([]OutdatedLibrary, 0)
+	for _, row := range rows {
+		if n := len(result); n == 0 || result[n-1].MachineName != row.MachineName {
+			target, err := s.store.GetH5PLibraryByMachineName(ctx, row.MachineName)
+			if err != nil {
+				return nil, pkg.InternalError{Message: "Error resolving library", Err: err}
+			}
+			result = append(result, OutdatedLibrary{
+				MachineName:    row.MachineName,
+				Title:          target.Title,
+				Target:         libraryVersion(target),
+				UpgradesScript: s.upgradesScriptURL(ctx, target),
+			})
+		}
+		lib := &result[len(result)-1]
+		lib.Versions = append(lib.Versions, OutdatedVersions{
+			Major:        int(row.MajorVersion),
+			Minor:        int(row.MinorVersion),
+			ContentCount: row.ContentCount,
+		})
+		lib.ContentCount += row.ContentCount
+	}
+	return result, nil
+}
+
+// GetUpgradePlan returns up to limit of the organisation's content items on
+// outdated versions of a library, least recently updated first, with what's
+// needed to upgrade them to the newest installed version.
+func (s *Service) GetUpgradePlan(ctx context.Context, orgID uuid.UUID, machineName string, limit int) (*UpgradePlan, error) {
+	if limit < 1 || limit > MaxUpgradePlanLimit {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("limit must be between 1 and %d", MaxUpgradePlanLimit)}
+	}
+	target, err := s.store.GetH5PLibraryByMachineName(ctx, machineName)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: fmt.Sprintf("Library %s not found", machineName), Err: err}
+	}
+	rows, err := s.store.ListOutdatedH5PContent(ctx, query.ListOutdatedH5PContentParams{
+		OrgID:        orgID,
+		MachineName:  machineName,
+		MajorVersion: target.MajorVersion,
+		MinorVersion: target.MinorVersion,
+		MaxResults:   int32(limit),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing outdated content", Err: err}
+	}
+
+	semantics, err := s.fileProvider.Download(ctx, LibraryStorageKey(machineName, int(target.MajorVersion), int(target.MinorVersion), int(target.PatchVersion), "semantics.json"))
+	if err != nil {
+		slog.Debug("No semantics.json found", "library", machineName, "error", err)
+		semantics = []byte(`[]`)
+	}
+	plan := &UpgradePlan{
+		MachineName:    machineName,
+		Target:         libraryVersion(target),
+		UpgradesScript: s.upgradesScriptURL(ctx, target),
+		Semantics:      semantics,
+		Items:          make([]UpgradePlanItem, len(rows)),
+	}
+	for i, row := range rows {
+		params, metadata := splitContentJSON(row.ContentJson, row.Title)
+		plan.Items[i] = UpgradePlanItem{
+			ContentID: row.ID,
+			Title:     row.Title,
+			Version:   HubVersion{Major: int(row.MajorVersion), Minor: int(row.MinorVersion), Patch: int(row.PatchVersion)},
+			Params:    params,
+			Metadata:  metadata,
+			Locked:    row.LockedAt.Valid,
+		}
+	}
+	return plan, nil
+}
+
+// UpgradeContent moves a batch of content items to the newest installed
+// version of a library with the parameters upgrades.js produced for them.
+// Each item succeeds or fails on its own; an item fails if it isn't on an
+// older version of the library, is locked, or changed since it was listed.
+// target must be the version the parameters were upgraded to, so a library
+// updated in the meantime doesn't get parameters meant for its predecessor.
+func (s *Service) UpgradeContent(ctx context.Context, orgID uuid.UUID, machineName string, target HubVersion, items []UpgradeItem) (*UpgradeReport, error) {
+	if len(items) == 0 || len(items) > MaxUpgradeBatch {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("items must have 1 to %d entries", MaxUpgradeBatch)}
+	}
+	lib, err := s.store.GetH5PLibraryByMachineName(ctx, machineName)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: fmt.Sprintf("Library %s not found", machineName), Err: err}
+	}
+	if current := libraryVersion(lib); current.Major != target.Major || current.Minor != target.Minor {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("%s %d.%d is no longer the newest version; reload the upgrade plan", machineName, target.Major, target.Minor)}
+	}
+
+	report := &UpgradeReport{Target: libraryVersion(lib), Results: make([]UpgradeResult, len(items))}
+	for i, item := range items {
+		result := UpgradeResult{ContentID: item.ContentID, Status: UpgradeStatusUpgraded}
+		if err := s.upgradeItem(ctx, orgID, lib, item); err != nil {
+			result.Status = UpgradeStatusFailed
+			result.Error = upgradeErrorMessage(err)
+			slog.Warn("Content upgrade failed", "contentID", item.ContentID, "library", machineName, "error", err)
+			report.Failed++
+		} else {
+			report.Upgraded++
+		}
+		report.Results[i] = result
+	}
+	slog.Info("Content upgrade batch finished", "orgID", orgID, "library", machineName, "upgraded", report.Upgraded, "failed", report.Failed)
+	return report, nil
+}
+
+// upgradeItem checks one item can move to lib and saves it, keeping the
+// shape of its stored content_json.
+func (s *Service) upgradeItem(ctx context.Context, orgID uuid.UUID, lib query.H5pLibrary, item UpgradeItem) error {
+	if !isJSONObject(item.Params) {
+		return pkg.BadRequestError{Message: "params must be a JSON object"}
+	}
+	if len(item.Metadata) > 0 && !isJSONObject(item.Metadata) && !bytes.Equal(bytes.TrimSpace(item.Metadata), []byte("null")) {
+		return pkg.BadRequestError{Message: "metadata must be a JSON object"}
+	}
+	content, err := s.store.GetH5PContent(ctx, query.GetH5PContentParams{ID: item.ContentID, OrgID: orgID})
+	if err != nil {
+		return pkg.NotFoundError{Message: "Content not found", Err: err}
+	}
+	if err := checkNotLocked(content); err != nil {
+		return err
+	}
+	current, err := s.store.GetH5PLibrary(ctx, content.LibraryID)
+	if err != nil {
+		return pkg.InternalError{Message: "Error resolving content library", Err: err}
+	}
+	if current.MachineName != lib.MachineName {
+		return pkg.BadRequestError{Message: fmt.Sprintf("Content uses %s, not %s", current.MachineName, lib.MachineName)}
+	}
+	if current.MajorVersion > lib.MajorVersion || (current.MajorVersion == lib.MajorVersion && current.MinorVersion >= lib.MinorVersion) {
+		return pkg.BadRequestError{Message: "Content is already up to date"}
+	}
+
+	contentJSON, err := upgradedContentJSON(content.ContentJson, item.Params, item.Metadata)
+	if err != nil {
+		return pkg.InternalError{Message: "Error encoding content", Err: err}
+	}
+	n, err := s.store.UpgradeH5PContentLibrary(ctx, query.UpgradeH5PContentLibraryParams{
+		ID:          content.ID,
+		OrgID:       orgID,
+		LibraryID:   lib.ID,
+		ContentJson: contentJSON,
+		LibraryID_2: current.ID,
+	})
+	if err != nil {
+		return pkg.InternalError{Message: "Error saving upgraded content", Err: err}
+	}
+	if n == 0 {
+		return pkg.BadRequestError{Message: "Content changed while it was being upgraded"}
+	}
+	return nil
+}
+
+// upgradedContentJSON stores upgraded parameters the way the content was
+// stored: wrapped with its metadata, or as bare parameters.
+func upgradedContentJSON(stored, params, metadata json.RawMessage) (json.RawMessage, error) {
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(stored, &wrapper); err != nil || wrapper["params"] == nil {
+		return params, nil
+	}
+	wrapper["params"] = params
+	if len(metadata) > 0 && !bytes.Equal(bytes.TrimSpace(metadata), []byte("null")) {
+		wrapper["metadata"] = metadata
+	}
+	return json.Marshal(wrapper)
+}
+
+func isJSONObject(raw json.RawMessage) bool {
+	var v map[string]any
+	return json.Unmarshal(raw, &v) == nil && v != nil
+}
+
+// upgradeErrorMessage is the message reported for a failed item
+func upgradeErrorMessage(err error) string {
+	var (
+		badRequest pkg.BadRequestError
+		notFound   pkg.NotFoundError
+		locked     pkg.LockedError
+	)
+	switch {
+	case errors.As(err, &badRequest):
+		return badRequest.Message
+	case errors.As(err, &notFound):
+		return notFound.Message
+	case errors.As(err, &locked):
+		return "Content is locked"
+	}
+	return "Internal error"
+}
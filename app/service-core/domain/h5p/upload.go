@@ -0,0 +1,196 @@
+package h5p
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"service-core/domain/file"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// PackageUpload is the editor's response to an uploaded .h5p package: the
+// package's h5p.json and content parameters to open in the editor, and the
+// content type cache with any newly installed libraries. ContentItem is the
+// content item created from the package, if it carried content.
+type PackageUpload struct {
+	H5P          json.RawMessage `json:"h5p"`
+	Content      json.RawMessage `json:"content"`
+	ContentTypes *IHubInfo       `json:"contentTypes"`
+	ContentItem  *ContentInfo    `json:"contentItem,omitempty"`
+}
+
+// UploadPackage installs the libraries of an .h5p package uploaded in the
+// editor and creates a content item from its content, charging the package
+// to the organisation's storage quota. Libraries already installed at the
+// same or a newer patch version are kept as they are, so an upload can't
+// replace an installed library's files.
+func (s *Service) UploadPackage(ctx context.Context, orgID, userID uuid.UUID, data []byte) (*PackageUpload, error) {
+	extracted, err := ExtractH5PPackage(data)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "The file you uploaded is not a valid HTML5 Package", Err: err}
+	}
+	mainDep, err := checkUploadedPackage(extracted)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLibraryAvailable(ctx, orgID, mainDep.MachineName); err != nil {
+		return nil, err
+	}
+	if err := s.checkStorageQuota(ctx, orgID, int64(len(data))); err != nil {
+		return nil, err
+	}
+
+	installed, err := s.installUploadedLibraries(ctx, extracted, data)
+	if err != nil {
+		return nil, err
+	}
+	mainLib, err := s.store.GetH5PLibraryByMachineNameMajorMinor(ctx, query.GetH5PLibraryByMachineNameMajorMinorParams{
+		MachineName:  mainDep.MachineName,
+		MajorVersion: int32(mainDep.MajorVersion),
+		MinorVersion: int32(mainDep.MinorVersion),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("The package's main library %s %d.%d is not installed and not in the package",
+			mainDep.MachineName, mainDep.MajorVersion, mainDep.MinorVersion)}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading main library", Err: err}
+	}
+	for _, lib := range installed {
+		s.publish(ctx, orgID, EventLibraryInstalled, map[string]any{
+			"libraryId":   lib.ID,
+			"machineName": lib.MachineName,
+			"version":     fmt.Sprintf("%d.%d.%d", lib.MajorVersion, lib.MinorVersion, lib.PatchVersion),
+		})
+	}
+
+	result := &PackageUpload{H5P: extracted.ManifestJSON, Content: json.RawMessage(`{}`)}
+	if params, ok := extracted.Content["content.json"]; ok {
+		if !json.Valid(params) {
+			return nil, pkg.BadRequestError{Message: "The package's content/content.json is not valid JSON"}
+		}
+		result.Content = params
+		result.ContentItem, err = s.createUploadedContent(ctx, orgID, userID, mainLib, extracted)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.recordStorageUsage(ctx, orgID, int64(len(data)))
+
+	result.ContentTypes, err = s.GetEditorContentTypeCache(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkUploadedPackage checks the package names a main library with its
+// version, and that none of its libraries need a newer H5P core. It returns
+// the main library's dependency entry.
+func checkUploadedPackage(extracted *ExtractedPackage) (*LibraryDep, error) {
+	manifest := extracted.Manifest
+	if extracted.ManifestJSON == nil || manifest.MainLibrary == "" {
+		return nil, pkg.BadRequestError{Message: "The package's h5p.json is missing or has no mainLibrary"}
+	}
+	var mainDep *LibraryDep
+	for i, dep := range manifest.PreloadedDependencies {
+		if dep.MachineName == manifest.MainLibrary {
+			mainDep = &manifest.PreloadedDependencies[i]
+			break
+		}
+	}
+	if mainDep == nil {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("The package's h5p.json doesn't list the version of its main library %s", manifest.MainLibrary)}
+	}
+
+	for _, lib := range extracted.Libraries {
+		api := lib.LibraryJSON.CoreAPI
+		if api == nil {
+			continue
+		}
+		major, minor := int(api.MajorVersion), int(api.MinorVersion)
+		if major > coreAPIVersion.Major || (major == coreAPIVersion.Major && minor > coreAPIVersion.Minor) {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("%s needs H5P core %d.%d, but this platform supports %d.%d",
+				lib.LibraryJSON.MachineName, major, minor, coreAPIVersion.Major, coreAPIVersion.Minor)}
+		}
+	}
+	return mainDep, nil
+}
+
+// installUploadedLibraries installs the package's libraries that aren't
+// installed yet or are newer patches of installed ones, and returns them.
+// Like installPackage, dependencies are stored once every library exists.
+func (s *Service) installUploadedLibraries(ctx context.Context, extracted *ExtractedPackage, data []byte) ([]query.H5pLibrary, error) {
+	var installed []query.H5pLibrary
+	var installedJSON []LibraryJSON
+	for _, extLib := range extracted.Libraries {
+		lj := extLib.LibraryJSON
+		current, err := s.store.GetH5PLibraryByMachineNameMajorMinor(ctx, query.GetH5PLibraryByMachineNameMajorMinorParams{
+			MachineName:  lj.MachineName,
+			MajorVersion: int32(lj.MajorVersion),
+			MinorVersion: int32(lj.MinorVersion),
+		})
+		if err == nil && current.PatchVersion >= int32(lj.PatchVersion) {
+			continue
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.InternalError{Message: "Error loading installed libraries", Err: err}
+		}
+
+		lib, err := s.installSingleLibrary(ctx, extLib, data, lj.MachineName == extracted.Manifest.MainLibrary)
+		if err != nil {
+			return nil, pkg.InternalError{Message: fmt.Sprintf("Error installing %s", lj.MachineName), Err: err}
+		}
+		installed = append(installed, *lib)
+		installedJSON = append(installedJSON, lj)
+	}
+
+	for i, lib := range installed {
+		if err := s.storeDependencies(ctx, lib.ID, installedJSON[i]); err != nil {
+			slog.Warn("Failed to store dependencies of uploaded library", "library", lib.MachineName, "error", err)
+		}
+	}
+	return installed, nil
+}
+
+// createUploadedContent creates a content item from the package's
+// content.json and stores the content's files alongside it
+func (s *Service) createUploadedContent(ctx context.Context, orgID, userID uuid.UUID, lib query.H5pLibrary, extracted *ExtractedPackage) (*ContentInfo, error) {
+	title := strings.TrimSpace(extracted.Manifest.Title)
+	if title == "" {
+		title = "Untitled"
+	}
+	content, err := s.createContent(ctx, orgID, userID, lib, title, extracted.Content["content.json"])
+	if err != nil {
+		return nil, err
+	}
+
+	for relPath, data := range extracted.Content {
+		if relPath == "content.json" {
+			continue
+		}
+		// Files are stored under the content's prefix, so the path must stay inside it
+		if clean := path.Clean(relPath); clean != relPath || clean == ".." || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+			slog.Warn("Skipping uploaded content file with an unsafe path", "path", relPath, "contentID", content.ID)
+			continue
+		}
+		err := s.fileProvider.Upload(ctx, &file.File{
+			Key:         fmt.Sprintf("h5p-content/%s/%s/%s", orgID, content.ID, relPath),
+			ContentType: detectContentType(relPath),
+			Data:        data,
+		})
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error storing content files", Err: err}
+		}
+	}
+	return content, nil
+}
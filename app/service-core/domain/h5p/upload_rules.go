@@ -0,0 +1,154 @@
+package h5p
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// UploadField is the semantics field a file is uploaded for. The editor's
+// files action posts it as JSON alongside the file.
+type UploadField struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // "image", "video", "audio" or "file"
+}
+
+// ParseUploadField decodes the editor's field JSON. Missing or malformed
+// fields fall back to the generic "file" type, which has the strictest whitelist.
+func ParseUploadField(raw string) UploadField {
+	var field UploadField
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &field)
+	}
+	if _, ok := defaultUploadRules[field.Type]; !ok {
+		field.Type = "file"
+	}
+	return field
+}
+
+// uploadRule is the whitelist and size limit for one field type.
+type uploadRule struct {
+	extensions []string
+	maxSize    int64
+	// invalidMessage is the H5P editor's error for a disallowed file type
+	invalidMessage string
+}
+
+// defaultUploadRules mirror H5P core's per-field whitelists. Sizes can be
+// overridden with H5P_UPLOAD_LIMITS.
+var defaultUploadRules = map[string]uploadRule{
+	"image": {
+		extensions:     []string{"jpg", "jpeg", "png", "gif"},
+		maxSize:        5 << 20,
+		invalidMessage: "Invalid image file format. Use jpg, png or gif.",
+	},
+	"video": {
+		extensions:     []string{"mp4", "webm", "ogv"},
+		maxSize:        2 << 30,
+		invalidMessage: "Invalid video file format. Use mp4 or webm.",
+	},
+	"audio": {
+		extensions:     []string{"mp3", "m4a", "wav", "ogg", "webm"},
+		maxSize:        50 << 20,
+		invalidMessage: "Invalid audio file format. Use mp3 or wav.",
+	},
+	"file": {
+		extensions: strings.Fields("json png jpg jpeg gif bmp tif tiff svg eot ttf woff woff2 otf webm mp4 ogg mp3 m4a wav " +
+			"txt pdf rtf doc docx xls xlsx ppt pptx odt ods odp xml csv diff patch md textile vtt webvtt gltf glb"),
+		maxSize:        100 << 20,
+		invalidMessage: "File type isn't allowed.",
+	},
+}
+
+// MaxUploadSize is the largest size any field type accepts, used to cap the request body.
+func (s *Service) MaxUploadSize() int64 {
+	var largest int64
+	for _, rule := range s.uploadRules {
+		largest = max(largest, rule.maxSize)
+	}
+	return largest
+}
+
+// ValidateTempUpload checks a file against the whitelist and size limit of the
+// field it is uploaded for. Errors carry the H5P editor's message.
+func (s *Service) ValidateTempUpload(field UploadField, filename string, size int64) error {
+	rule, ok := s.uploadRules[field.Type]
+	if !ok {
+		rule = s.uploadRules["file"]
+	}
+
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(filename)), ".")
+	if !slices.Contains(rule.extensions, ext) {
+		return pkg.BadRequestError{Message: rule.invalidMessage}
+	}
+	if size > rule.maxSize {
+		return pkg.BadRequestError{Message: fmt.Sprintf("The file you uploaded is too large. The maximum size is %s.", formatUploadSize(rule.maxSize))}
+	}
+	return nil
+}
+
+// uploadRulesFromConfig applies size overrides in the form "image=5MB,video=2GB"
+// to the default rules. Invalid entries are returned as an error and ignored.
+func uploadRulesFromConfig(spec string) (map[string]uploadRule, error) {
+	rules := make(map[string]uploadRule, len(defaultUploadRules))
+	for fieldType, rule := range defaultUploadRules {
+		rules[fieldType] = rule
+	}
+
+	var invalid []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		fieldType, sizeStr, ok := strings.Cut(entry, "=")
+		rule, known := rules[strings.TrimSpace(fieldType)]
+		size, err := parseUploadSize(sizeStr)
+		if !ok || !known || err != nil {
+			invalid = append(invalid, entry)
+			continue
+		}
+		rule.maxSize = size
+		rules[strings.TrimSpace(fieldType)] = rule
+	}
+	if len(invalid) > 0 {
+		return rules, fmt.Errorf("invalid upload limits: %s", strings.Join(invalid, ", "))
+	}
+	return rules, nil
+}
+
+// parseUploadSize parses sizes such as "500KB", "5MB" or "2GB".
+func parseUploadSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for suffix, multiplier := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(s, suffix) {
+			s = strings.TrimSuffix(s, suffix)
+			unit = multiplier
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * unit, nil
+}
+
+// formatUploadSize renders a byte count in the largest whole unit.
+func formatUploadSize(n int64) string {
+	switch {
+	case n >= 1<<30 && n%(1<<30) == 0:
+		return fmt.Sprintf("%d GB", n>>30)
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KB", n>>10)
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
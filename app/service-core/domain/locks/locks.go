@@ -0,0 +1,171 @@
+package locks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// ErrLockHeld is returned by TryAcquire when another holder has the lock.
+var ErrLockHeld = errors.New("lock is held by another instance")
+
+// ErrLockLost is returned by Extend when the lease expired before it was
+// extended.
+var ErrLockLost = errors.New("lock lease has expired")
+
+// store defines the database interface for job locks
+type store interface {
+	AcquireJobLock(ctx context.Context, arg query.AcquireJobLockParams) (int64, error)
+	ReleaseJobLock(ctx context.Context, arg query.ReleaseJobLockParams) error
+	ExtendJobLock(ctx context.Context, arg query.ExtendJobLockParams) (int64, error)
+	IsJobLockHeld(ctx context.Context, name string) (bool, error)
+}
+
+// Service hands out leases on named locks stored in Postgres, so a scheduled
+// job runs on exactly one replica at a time.
+type Service struct {
+	store    store
+	instance string
+}
+
+// NewService creates a new lock service
+func NewService(store store) *Service {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+	return &Service{store: store, instance: instance}
+}
+
+// Lock is a held lease. Release it when the job finishes; otherwise it
+// expires after its TTL.
+type Lock struct {
+	Name   string
+	holder string
+	store  store
+}
+
+// TryAcquire takes the named lock for ttl without blocking. It returns
+// ErrLockHeld if another holder has an unexpired lease.
+func (s *Service) TryAcquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	// Each acquisition gets its own holder ID, so two runs on one replica also exclude each other
+	holder := fmt.Sprintf("%s/%s", s.instance, uuid.NewString())
+	acquired, err := s.store.AcquireJobLock(ctx, query.AcquireJobLockParams{
+		Name:       name,
+		Holder:     holder,
+		TtlSeconds: ttl.Seconds(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("acquiring lock %s: %w", name, err)
+	}
+	if acquired == 0 {
+		return nil, ErrLockHeld
+	}
+	return &Lock{Name: name, holder: holder, store: s.store}, nil
+}
+
+// Extend renews the lease for ttl from now, so a long job can hold a short
+// lease that lapses soon after its replica dies. It returns ErrLockLost if
+// the lease had already expired.
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	extended, err := l.store.ExtendJobLock(ctx, query.ExtendJobLockParams{
+		Name:       l.Name,
+		Holder:     l.holder,
+		TtlSeconds: ttl.Seconds(),
+	})
+	if err != nil {
+		return fmt.Errorf("extending lock %s: %w", l.Name, err)
+	}
+	if extended == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// IsHeld reports whether anyone holds an unexpired lease on the named lock.
+func (s *Service) IsHeld(ctx context.Context, name string) (bool, error) {
+	held, err := s.store.IsJobLockHeld(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("checking lock %s: %w", name, err)
+	}
+	return held, nil
+}
+
+// Release gives up the lease. Releasing a lease that already expired and was
+// taken by someone else is a no-op.
+func (l *Lock) Release(ctx context.Context) error {
+	err := l.store.ReleaseJobLock(ctx, query.ReleaseJobLockParams{Name: l.Name, Holder: l.holder})
+	if err != nil {
+		return fmt.Errorf("releasing lock %s: %w", l.Name, err)
+	}
+	return nil
+}
+
+// RunExclusive runs fn while holding the named lock, extending the lease
+// every third of ttl until fn returns. ran is false, with a nil error, when
+// another instance holds the lock and fn was skipped. If the lease is lost
+// anyway, e.g. the database was unreachable for longer than ttl, fn's context
+// is cancelled and the error wraps ErrLockLost.
+func (s *Service) RunExclusive(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context) error) (ran bool, err error) {
+	lock, err := s.TryAcquire(ctx, name, ttl)
+	if errors.Is(err, ErrLockHeld) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		// Release even if the request context was cancelled mid-job
+		if err := lock.Release(context.WithoutCancel(ctx)); err != nil {
+			slog.Error("Failed to release job lock", "lock", name, "error", err)
+		}
+	}()
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		lock.renew(runCtx, ttl, cancel)
+	}()
+	err = fn(runCtx)
+	lost := errors.Is(context.Cause(runCtx), ErrLockLost)
+	cancel(nil)
+	<-renewed
+
+	if lost && err != nil {
+		return true, fmt.Errorf("lock %s: %w: %w", name, ErrLockLost, err)
+	}
+	return true, err
+}
+
+// renew extends the lease for ttl every third of ttl until ctx is done. When
+// the lease turns out to be lost it calls lost with ErrLockLost, so the work
+// stops before it overlaps with the new holder's.
+func (l *Lock) renew(ctx context.Context, ttl time.Duration, lost context.CancelCauseFunc) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := l.Extend(ctx, ttl)
+			switch {
+			case errors.Is(err, ErrLockLost):
+				slog.Error("Job lock lost; cancelling the job", "lock", l.Name)
+				lost(ErrLockLost)
+				return
+			case err != nil && ctx.Err() == nil:
+				slog.Warn("Error extending job lock", "lock", l.Name, "error", err)
+			}
+		}
+	}
+}
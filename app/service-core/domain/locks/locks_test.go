@@ -0,0 +1,203 @@
+package locks
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"service-core/storage/query"
+)
+
+// lease is a row of the fake job_locks table.
+type lease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// lockStore keeps job locks in memory with the expiry rules of the queries.
+type lockStore struct {
+	mu     sync.Mutex
+	leases map[string]lease
+}
+
+func newLockStore() *lockStore {
+	return &lockStore{leases: map[string]lease{}}
+}
+
+func ttlFrom(seconds float64) time.Time {
+	return time.Now().Add(time.Duration(seconds * float64(time.Second)))
+}
+
+func (s *lockStore) AcquireJobLock(_ context.Context, arg query.AcquireJobLockParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.leases[arg.Name]; ok && !l.expiresAt.Before(time.Now()) {
+		return 0, nil
+	}
+	s.leases[arg.Name] = lease{holder: arg.Holder, expiresAt: ttlFrom(arg.TtlSeconds)}
+	return 1, nil
+}
+
+func (s *lockStore) ReleaseJobLock(_ context.Context, arg query.ReleaseJobLockParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.leases[arg.Name]; ok && l.holder == arg.Holder {
+		delete(s.leases, arg.Name)
+	}
+	return nil
+}
+
+func (s *lockStore) ExtendJobLock(_ context.Context, arg query.ExtendJobLockParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.leases[arg.Name]
+	if !ok || l.holder != arg.Holder || !l.expiresAt.After(time.Now()) {
+		return 0, nil
+	}
+	s.leases[arg.Name] = lease{holder: arg.Holder, expiresAt: ttlFrom(arg.TtlSeconds)}
+	return 1, nil
+}
+
+func (s *lockStore) IsJobLockHeld(_ context.Context, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.leases[name]
+	return ok && l.expiresAt.After(time.Now()), nil
+}
+
+// steal hands the named lock to another holder, as if its lease had lapsed
+// and another replica took it.
+func (s *lockStore) steal(name string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leases[name] = lease{holder: "other/replica", expiresAt: time.Now().Add(ttl)}
+}
+
+func TestTryAcquire(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(s *Service)
+		wantErr error
+	}{
+		{name: "free", setup: func(*Service) {}},
+		{
+			name: "held",
+			setup: func(s *Service) {
+				_, err := s.TryAcquire(context.Background(), "job", time.Minute)
+				require.NoError(t, err)
+			},
+			wantErr: ErrLockHeld,
+		},
+		{
+			name: "expired",
+			setup: func(s *Service) {
+				_, err := s.TryAcquire(context.Background(), "job", time.Millisecond)
+				require.NoError(t, err)
+				time.Sleep(5 * time.Millisecond)
+			},
+		},
+		{
+			name: "released",
+			setup: func(s *Service) {
+				lock, err := s.TryAcquire(context.Background(), "job", time.Minute)
+				require.NoError(t, err)
+				require.NoError(t, lock.Release(context.Background()))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{store: newLockStore(), instance: "test"}
+			tt.setup(s)
+
+			lock, err := s.TryAcquire(context.Background(), "job", time.Minute)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			held, err := s.IsHeld(context.Background(), "job")
+			require.NoError(t, err)
+			assert.True(t, held)
+			assert.NoError(t, lock.Extend(context.Background(), time.Minute))
+		})
+	}
+}
+
+// TestExtendAfterExpiry checks a lease that lapsed and was taken by another
+// holder can't be extended or released by its old holder.
+func TestExtendAfterExpiry(t *testing.T) {
+	store := newLockStore()
+	s := &Service{store: store, instance: "test"}
+	lock, err := s.TryAcquire(context.Background(), "job", time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	assert.ErrorIs(t, lock.Extend(context.Background(), time.Minute), ErrLockLost)
+
+	other, err := s.TryAcquire(context.Background(), "job", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release(context.Background()))
+	held, err := s.IsHeld(context.Background(), "job")
+	require.NoError(t, err)
+	assert.True(t, held, "the old holder's release freed the new holder's lock")
+	assert.NoError(t, other.Extend(context.Background(), time.Minute))
+}
+
+func TestRunExclusive(t *testing.T) {
+	const ttl = 30 * time.Millisecond
+
+	t.Run("skips when held", func(t *testing.T) {
+		s := &Service{store: newLockStore(), instance: "test"}
+		_, err := s.TryAcquire(context.Background(), "job", time.Minute)
+		require.NoError(t, err)
+
+		ran, err := s.RunExclusive(context.Background(), "job", ttl, func(context.Context) error {
+			t.Fatal("fn ran while the lock was held")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.False(t, ran)
+	})
+
+	t.Run("renews the lease past its ttl", func(t *testing.T) {
+		s := &Service{store: newLockStore(), instance: "test"}
+		ran, err := s.RunExclusive(context.Background(), "job", ttl, func(ctx context.Context) error {
+			time.Sleep(4 * ttl)
+			_, err := s.TryAcquire(ctx, "job", ttl)
+			assert.ErrorIs(t, err, ErrLockHeld)
+			return ctx.Err()
+		})
+		require.NoError(t, err)
+		assert.True(t, ran)
+
+		held, err := s.IsHeld(context.Background(), "job")
+		require.NoError(t, err)
+		assert.False(t, held, "the lock wasn't released")
+	})
+
+	t.Run("cancels fn when the lease is stolen", func(t *testing.T) {
+		store := newLockStore()
+		s := &Service{store: store, instance: "test"}
+		ran, err := s.RunExclusive(context.Background(), "job", ttl, func(ctx context.Context) error {
+			store.steal("job", time.Minute)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return nil
+			}
+		})
+		assert.True(t, ran)
+		assert.ErrorIs(t, err, ErrLockLost)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		held, err := s.IsHeld(context.Background(), "job")
+		require.NoError(t, err)
+		assert.True(t, held, "releasing the lost lease freed the new holder's lock")
+	})
+}
@@ -0,0 +1,50 @@
+// Package membership checks a user's role in an organisation before a
+// service acts on the organisation's behalf.
+package membership
+
+import (
+	"app/pkg"
+	"context"
+	"errors"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Store is the membership lookup the checks need; the generated queries
+// satisfy it.
+type Store interface {
+	GetOrgMembershipRole(ctx context.Context, arg query.GetOrgMembershipRoleParams) (string, error)
+}
+
+// RequireMember returns UnauthorizedError unless the user belongs to the
+// organisation.
+func RequireMember(ctx context.Context, store Store, userID, organisationID uuid.UUID) error {
+	_, err := role(ctx, store, userID, organisationID)
+	return err
+}
+
+// RequireAdmin returns UnauthorizedError unless the user belongs to the
+// organisation, and ForbiddenError unless they own or administer it.
+func RequireAdmin(ctx context.Context, store Store, userID, organisationID uuid.UUID) error {
+	r, err := role(ctx, store, userID, organisationID)
+	if err != nil {
+		return err
+	}
+	if r != "owner" && r != "admin" {
+		return pkg.ForbiddenError{Err: errors.New("organisation admin role required")}
+	}
+	return nil
+}
+
+func role(ctx context.Context, store Store, userID, organisationID uuid.UUID) (string, error) {
+	r, err := store.GetOrgMembershipRole(ctx, query.GetOrgMembershipRoleParams{
+		UserID:         userID,
+		OrganisationID: organisationID,
+	})
+	if err != nil {
+		return "", pkg.UnauthorizedError{Err: errors.New("not a member of this organisation")}
+	}
+	return r, nil
+}
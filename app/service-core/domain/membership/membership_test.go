@@ -0,0 +1,55 @@
+package membership
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"testing"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type roleStore struct {
+	role string
+	err  error
+}
+
+func (s roleStore) GetOrgMembershipRole(context.Context, query.GetOrgMembershipRoleParams) (string, error) {
+	return s.role, s.err
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name      string
+		store     roleStore
+		memberErr error
+		adminErr  error
+	}{
+		{name: "owner", store: roleStore{role: "owner"}},
+		{name: "admin", store: roleStore{role: "admin"}},
+		{name: "member", store: roleStore{role: "member"}, adminErr: pkg.ForbiddenError{}},
+		{name: "not a member", store: roleStore{err: sql.ErrNoRows}, memberErr: pkg.UnauthorizedError{}, adminErr: pkg.UnauthorizedError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			assertKind(t, tt.memberErr, RequireMember(ctx, tt.store, uuid.New(), uuid.New()))
+			assertKind(t, tt.adminErr, RequireAdmin(ctx, tt.store, uuid.New(), uuid.New()))
+		})
+	}
+}
+
+func assertKind(t *testing.T, want, got error) {
+	t.Helper()
+	switch want.(type) {
+	case nil:
+		assert.NoError(t, got)
+	case pkg.ForbiddenError:
+		assert.IsType(t, pkg.ForbiddenError{}, got)
+	case pkg.UnauthorizedError:
+		assert.IsType(t, pkg.UnauthorizedError{}, got)
+	}
+}
@@ -0,0 +1,91 @@
+package notification
+
+import (
+	"app/pkg"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// feedRetention is how long in-app notifications are kept
+const feedRetention = 90 * 24 * time.Hour
+
+// DigestSummary reports a digest run.
+type DigestSummary struct {
+	Sent   int   `json:"sent"`
+	Failed int   `json:"failed"`
+	Items  int   `json:"items"`
+	Pruned int64 `json:"pruned"`
+}
+
+// SendDigests emails each member one digest of the events queued for them
+// since the last run, across all their organisations, then prunes old in-app
+// notifications. Items are only removed once their digest is sent, so a
+// failed email is retried on the next run. Meant to run daily.
+func (s *Service) SendDigests(ctx context.Context) (*DigestSummary, error) {
+	if s.mailer == nil {
+		return nil, pkg.InternalError{Message: "Error sending digests", Err: errors.New("no mailer configured")}
+	}
+	// Items queued while the run is underway wait for the next one
+	cutoff := time.Now()
+	items, err := s.store.ListNotificationDigestItems(ctx, cutoff)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing digest items", Err: err}
+	}
+
+	summary := &DigestSummary{Items: len(items)}
+	// Items come ordered by user, so each user's run is contiguous
+	for start := 0; start < len(items); {
+		end := start
+		for end < len(items) && items[end].UserID == items[start].UserID {
+			end++
+		}
+		if err := s.sendDigest(ctx, items[start:end], cutoff); err != nil {
+			slog.Error("Error sending notification digest", "error", err, "user_id", items[start].UserID)
+			summary.Failed++
+		} else {
+			summary.Sent++
+		}
+		start = end
+	}
+
+	pruned, err := s.store.DeleteOldUserNotifications(ctx, time.Now().Add(-feedRetention))
+	if err != nil {
+		return summary, pkg.InternalError{Message: "Error pruning notifications", Err: err}
+	}
+	summary.Pruned = pruned
+	return summary, nil
+}
+
+// sendDigest emails one user's queued items, prefixed with their organisation
+// when they come from more than one, and clears them.
+func (s *Service) sendDigest(ctx context.Context, items []query.ListNotificationDigestItemsRow, cutoff time.Time) error {
+	orgs := make(map[uuid.UUID]bool)
+	for _, item := range items {
+		orgs[item.OrganisationID] = true
+	}
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = item.Summary
+		if len(orgs) > 1 {
+			lines[i] = item.OrganisationName + ": " + item.Summary
+		}
+	}
+	subject := fmt.Sprintf("Your daily digest: %d notifications", len(items))
+	if len(items) == 1 {
+		subject = "Your daily digest: 1 notification"
+	}
+	if err := s.mailer.SendEmail(ctx, items[0].Email, subject, emailBody(lines, s.cfg.ClientURL)); err != nil {
+		return err
+	}
+	return s.store.DeleteNotificationDigestItems(ctx, query.DeleteNotificationDigestItemsParams{
+		UserID:    items[0].UserID,
+		CreatedAt: cutoff,
+	})
+}
@@ -0,0 +1,86 @@
+package notification
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"service-core/domain/billing"
+	"service-core/domain/h5p"
+	"service-core/domain/seo"
+)
+
+// eventCategories maps each domain event members can be notified of to its
+// category. Other events only go to webhooks.
+var eventCategories = map[string]string{
+	h5p.EventContentPublished:          CategoryContent,
+	h5p.EventLibraryInstalled:          CategoryContent,
+	seo.EventAuditCompleted:            CategorySEO,
+	seo.EventRankCheckCompleted:        CategorySEO,
+	billing.EventTierChanged:           CategoryBilling,
+	billing.EventRenewalUpdated:        CategoryBilling,
+	billing.EventCancellationScheduled: CategoryBilling,
+	billing.EventPaymentFailed:         CategoryBilling,
+}
+
+func eventCategory(eventType string) string {
+	return eventCategories[eventType]
+}
+
+// eventSummary describes an event in one line, for the in-app feed and
+// email subjects and digests.
+func eventSummary(eventType string, data map[string]any) string {
+	switch eventType {
+	case h5p.EventContentPublished:
+		return fmt.Sprintf("%q was published", stringField(data, "title"))
+	case h5p.EventLibraryInstalled:
+		return fmt.Sprintf("%s %s was installed", stringField(data, "machineName"), stringField(data, "version"))
+	case seo.EventAuditCompleted:
+		return fmt.Sprintf("A page experience audit of %s pages finished", stringField(data, "pages"))
+	case seo.EventRankCheckCompleted:
+		return fmt.Sprintf("A rank check of %s keywords finished", stringField(data, "keywords"))
+	case billing.EventTierChanged:
+		return fmt.Sprintf("Your plan changed from %s to %s", stringField(data, "previousTier"), stringField(data, "tier"))
+	case billing.EventRenewalUpdated:
+		return fmt.Sprintf("Your %s plan renews on %s", stringField(data, "tier"), stringField(data, "renewsAt"))
+	case billing.EventCancellationScheduled:
+		return fmt.Sprintf("Your %s plan is set to cancel on %s", stringField(data, "tier"), stringField(data, "cancelsAt"))
+	case billing.EventPaymentFailed:
+		return "A payment for your subscription failed"
+	}
+	return eventType
+}
+
+// stringField formats an event field for a summary; dates are shown as days
+func stringField(data map[string]any, key string) string {
+	switch v := data[key].(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format("2 January 2006")
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// emailBody lays out notification summaries as an email linking to the app
+func emailBody(summaries []string, clientURL string) string {
+	var items strings.Builder
+	for _, summary := range summaries {
+		items.WriteString(`    <li style="font-size: 16px; line-height: 24px; color: #52525b;">` + html.EscapeString(summary) + "</li>\n")
+	}
+	return `<!DOCTYPE html>
+<html>
+<body style="margin: 0; padding: 24px; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; background-color: #f4f4f5;">
+    <ul style="padding-left: 20px;">
+` + items.String() + `    </ul>
+    <p style="padding: 8px 0;">
+        <a href="` + html.EscapeString(clientURL) + `" style="display: inline-block; padding: 14px 32px; background-color: #6366f1; color: #ffffff; text-decoration: none; font-size: 16px; font-weight: 600; border-radius: 8px;">Open your dashboard</a>
+    </p>
+    <p style="font-size: 14px; line-height: 20px; color: #71717a;">You can change which notifications you get in your notification preferences.</p>
+</body>
+</html>`
+}
@@ -0,0 +1,88 @@
+package notification
+
+import (
+	"app/pkg"
+	"context"
+	"encoding/json"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Notification is an entry in a member's in-app feed.
+type Notification struct {
+	ID        uuid.UUID      `json:"id"`
+	Category  string         `json:"category"`
+	EventType string         `json:"eventType"`
+	Summary   string         `json:"summary"`
+	Data      map[string]any `json:"data"`
+	CreatedAt time.Time      `json:"createdAt"`
+	ReadAt    *time.Time     `json:"readAt"`
+}
+
+// Feed is a member's latest in-app notifications in an organisation.
+type Feed struct {
+	Unread        int64          `json:"unread"`
+	Notifications []Notification `json:"notifications"`
+}
+
+func toNotification(n query.UserNotification) Notification {
+	result := Notification{
+		ID:        n.ID,
+		Category:  n.Category,
+		EventType: n.EventType,
+		Summary:   n.Summary,
+		CreatedAt: n.CreatedAt,
+	}
+	_ = json.Unmarshal(n.Data, &result.Data)
+	if n.ReadAt.Valid {
+		result.ReadAt = &n.ReadAt.Time
+	}
+	return result
+}
+
+// ListNotifications returns the user's newest in-app notifications in an
+// organisation, with how many are unread.
+func (s *Service) ListNotifications(ctx context.Context, userID, organisationID uuid.UUID, limit int) (*Feed, error) {
+	if _, err := s.memberRole(ctx, userID, organisationID); err != nil {
+		return nil, err
+	}
+	rows, err := s.store.ListUserNotifications(ctx, query.ListUserNotificationsParams{
+		UserID:         userID,
+		OrganisationID: organisationID,
+		Limit:          int32(limit),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing notifications", Err: err}
+	}
+	unread, err := s.store.CountUnreadUserNotifications(ctx, query.CountUnreadUserNotificationsParams{
+		UserID:         userID,
+		OrganisationID: organisationID,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting notifications", Err: err}
+	}
+	feed := &Feed{Unread: unread, Notifications: make([]Notification, len(rows))}
+	for i, row := range rows {
+		feed.Notifications[i] = toNotification(row)
+	}
+	return feed, nil
+}
+
+// MarkNotificationsRead marks all the user's notifications in an
+// organisation read and returns how many were unread.
+func (s *Service) MarkNotificationsRead(ctx context.Context, userID, organisationID uuid.UUID) (int64, error) {
+	if _, err := s.memberRole(ctx, userID, organisationID); err != nil {
+		return 0, err
+	}
+	n, err := s.store.MarkUserNotificationsRead(ctx, query.MarkUserNotificationsReadParams{
+		UserID:         userID,
+		OrganisationID: organisationID,
+	})
+	if err != nil {
+		return 0, pkg.InternalError{Message: "Error marking notifications read", Err: err}
+	}
+	return n, nil
+}
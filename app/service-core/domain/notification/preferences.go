@@ -0,0 +1,211 @@
+package notification
+
+import (
+	"app/pkg"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Notification categories, each covering a group of domain events
+const (
+	CategoryContent = "content"
+	CategorySEO     = "seo"
+	CategoryBilling = "billing"
+)
+
+// Notification channels
+const (
+	ChannelEmail = "email"
+	ChannelInApp = "in_app"
+)
+
+// Categories and Channels list every category and channel in the order the
+// preferences matrix is returned.
+var (
+	Categories = []string{CategoryContent, CategorySEO, CategoryBilling}
+	Channels   = []string{ChannelEmail, ChannelInApp}
+)
+
+// Preference is whether a member is notified of a category's events on a
+// channel. Digest batches email into a daily digest instead of sending each
+// event straight away; it's always false for in-app notifications. Default
+// is true when the member hasn't changed their role's default.
+type Preference struct {
+	Category string `json:"category"`
+	Channel  string `json:"channel"`
+	Enabled  bool   `json:"enabled"`
+	Digest   bool   `json:"digest"`
+	Default  bool   `json:"default"`
+}
+
+// Preferences is a member's full category × channel matrix in one organisation.
+type Preferences struct {
+	Role        string       `json:"role"`
+	Preferences []Preference `json:"preferences"`
+}
+
+// PreferenceInput changes one cell of the matrix.
+type PreferenceInput struct {
+	Category string `json:"category"`
+	Channel  string `json:"channel"`
+	Enabled  bool   `json:"enabled"`
+	Digest   bool   `json:"digest"`
+}
+
+type prefKey struct {
+	category string
+	channel  string
+}
+
+// setting is one cell of the matrix: on or off, and for email whether digested
+type setting struct {
+	Enabled bool
+	Digest  bool
+}
+
+// roleDefaults is what each role is notified of until they say otherwise.
+// Owners hear about billing straight away; admins, who run the content and
+// SEO work, get a daily email digest of it; members only see content events
+// in the app.
+var roleDefaults = map[string]map[prefKey]setting{
+	"owner": {
+		{CategoryContent, ChannelInApp}: {Enabled: true},
+		{CategorySEO, ChannelEmail}:     {Enabled: true, Digest: true},
+		{CategorySEO, ChannelInApp}:     {Enabled: true},
+		{CategoryBilling, ChannelEmail}: {Enabled: true},
+		{CategoryBilling, ChannelInApp}: {Enabled: true},
+	},
+	"admin": {
+		{CategoryContent, ChannelEmail}: {Enabled: true, Digest: true},
+		{CategoryContent, ChannelInApp}: {Enabled: true},
+		{CategorySEO, ChannelEmail}:     {Enabled: true, Digest: true},
+		{CategorySEO, ChannelInApp}:     {Enabled: true},
+		{CategoryBilling, ChannelInApp}: {Enabled: true},
+	},
+	"member": {
+		{CategoryContent, ChannelInApp}: {Enabled: true},
+	},
+}
+
+// resolve applies a member's stored choices over their role's defaults.
+// Unknown roles get member defaults.
+func resolve(role string, overrides []query.NotificationPreference) map[prefKey]setting {
+	defaults, ok := roleDefaults[role]
+	if !ok {
+		defaults = roleDefaults["member"]
+	}
+	result := make(map[prefKey]setting, len(Categories)*len(Channels))
+	for k, v := range defaults {
+		result[k] = v
+	}
+	for _, o := range overrides {
+		result[prefKey{o.Category, o.Channel}] = setting{Enabled: o.Enabled, Digest: o.Digest}
+	}
+	return result
+}
+
+// memberRole returns the user's role in the organisation, or ForbiddenError
+// if they aren't a member.
+func (s *Service) memberRole(ctx context.Context, userID, organisationID uuid.UUID) (string, error) {
+	role, err := s.store.GetOrgMembershipRole(ctx, query.GetOrgMembershipRoleParams{
+		UserID:         userID,
+		OrganisationID: organisationID,
+	})
+	if err != nil {
+		return "", pkg.ForbiddenError{Err: errors.New("not a member of this organisation")}
+	}
+	return role, nil
+}
+
+// GetPreferences returns the user's notification matrix in an organisation,
+// their stored choices filled in with their role's defaults.
+func (s *Service) GetPreferences(ctx context.Context, userID, organisationID uuid.UUID) (*Preferences, error) {
+	role, err := s.memberRole(ctx, userID, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.store.ListNotificationPreferences(ctx, query.ListNotificationPreferencesParams{
+		UserID:         userID,
+		OrganisationID: organisationID,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading notification preferences", Err: err}
+	}
+	stored := make(map[prefKey]bool, len(rows))
+	for _, row := range rows {
+		stored[prefKey{row.Category, row.Channel}] = true
+	}
+	settings := resolve(role, rows)
+
+	result := &Preferences{Role: role, Preferences: make([]Preference, 0, len(Categories)*len(Channels))}
+	for _, category := range Categories {
+		for _, channel := range Channels {
+			key := prefKey{category, channel}
+			result.Preferences = append(result.Preferences, Preference{
+				Category: category,
+				Channel:  channel,
+				Enabled:  settings[key].Enabled,
+				Digest:   settings[key].Digest,
+				Default:  !stored[key],
+			})
+		}
+	}
+	return result, nil
+}
+
+// UpdatePreferences stores the given cells of the user's matrix, leaving the
+// rest as they are, and returns the whole matrix.
+func (s *Service) UpdatePreferences(ctx context.Context, userID, organisationID uuid.UUID, inputs []PreferenceInput) (*Preferences, error) {
+	if _, err := s.memberRole(ctx, userID, organisationID); err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, pkg.BadRequestError{Message: "preferences must not be empty"}
+	}
+	for _, in := range inputs {
+		if !slices.Contains(Categories, in.Category) {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("Unknown category %q", in.Category)}
+		}
+		if !slices.Contains(Channels, in.Channel) {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("Unknown channel %q", in.Channel)}
+		}
+		if in.Digest && in.Channel != ChannelEmail {
+			return nil, pkg.BadRequestError{Message: "Only email notifications can be sent as a digest"}
+		}
+	}
+	for _, in := range inputs {
+		err := s.store.UpsertNotificationPreference(ctx, query.UpsertNotificationPreferenceParams{
+			UserID:         userID,
+			OrganisationID: organisationID,
+			Category:       in.Category,
+			Channel:        in.Channel,
+			Enabled:        in.Enabled,
+			Digest:         in.Digest,
+		})
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error saving notification preferences", Err: err}
+		}
+	}
+	return s.GetPreferences(ctx, userID, organisationID)
+}
+
+// ResetPreferences returns the user to their role's defaults.
+func (s *Service) ResetPreferences(ctx context.Context, userID, organisationID uuid.UUID) (*Preferences, error) {
+	if _, err := s.memberRole(ctx, userID, organisationID); err != nil {
+		return nil, err
+	}
+	err := s.store.DeleteNotificationPreferences(ctx, query.DeleteNotificationPreferencesParams{
+		UserID:         userID,
+		OrganisationID: organisationID,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error resetting notification preferences", Err: err}
+	}
+	return s.GetPreferences(ctx, userID, organisationID)
+}
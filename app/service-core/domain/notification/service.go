@@ -0,0 +1,188 @@
+package notification
+
+import (
+	"app/pkg/jobs"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"service-core/config"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// jobNotify notifies an organisation's members of one event. Its payload is a
+// notifyJob.
+const jobNotify = "notifications.notify"
+
+type store interface {
+	GetOrgMembershipRole(ctx context.Context, arg query.GetOrgMembershipRoleParams) (string, error)
+	ListNotificationPreferences(ctx context.Context, arg query.ListNotificationPreferencesParams) ([]query.NotificationPreference, error)
+	ListOrganisationNotificationPreferences(ctx context.Context, organisationID uuid.UUID) ([]query.NotificationPreference, error)
+	UpsertNotificationPreference(ctx context.Context, arg query.UpsertNotificationPreferenceParams) error
+	DeleteNotificationPreferences(ctx context.Context, arg query.DeleteNotificationPreferencesParams) error
+	ListOrganisationNotificationRecipients(ctx context.Context, organisationID uuid.UUID) ([]query.ListOrganisationNotificationRecipientsRow, error)
+	CreateNotificationDigestItem(ctx context.Context, arg query.CreateNotificationDigestItemParams) error
+	ListNotificationDigestItems(ctx context.Context, createdAt time.Time) ([]query.ListNotificationDigestItemsRow, error)
+	DeleteNotificationDigestItems(ctx context.Context, arg query.DeleteNotificationDigestItemsParams) error
+	CreateUserNotification(ctx context.Context, arg query.CreateUserNotificationParams) error
+	ListUserNotifications(ctx context.Context, arg query.ListUserNotificationsParams) ([]query.UserNotification, error)
+	CountUnreadUserNotifications(ctx context.Context, arg query.CountUnreadUserNotificationsParams) (int64, error)
+	MarkUserNotificationsRead(ctx context.Context, arg query.MarkUserNotificationsReadParams) (int64, error)
+	DeleteOldUserNotifications(ctx context.Context, createdAt time.Time) (int64, error)
+}
+
+// publisher delivers domain events to an organisation's registered webhooks
+type publisher interface {
+	Publish(ctx context.Context, organisationID uuid.UUID, eventType string, data map[string]any)
+}
+
+// mailer sends notification and digest emails
+type mailer interface {
+	SendEmail(ctx context.Context, emailTo string, emailSubject string, emailBody string) error
+}
+
+// Service fans domain events out to an organisation's webhooks and to its
+// members, by email and in the app, as each member's preferences say.
+type Service struct {
+	cfg      *config.Config
+	store    store
+	webhooks publisher
+	mailer   mailer
+	jobs     *jobs.Queue // nil notifies members before Publish returns
+}
+
+// NewService creates a notification service and registers its notify job
+// with queue. It stands in for the webhook service as the publisher of other
+// services' events, passing every event on to webhooks before notifying
+// members. mailer may be nil to disable email.
+func NewService(cfg *config.Config, store store, webhooks publisher, mailer mailer, queue *jobs.Queue) *Service {
+	s := &Service{
+		cfg:      cfg,
+		store:    store,
+		webhooks: webhooks,
+		mailer:   mailer,
+		jobs:     queue,
+	}
+	if queue != nil {
+		// A retry only happens before any member was notified, so it can't
+		// notify anyone twice
+		queue.Register(jobNotify, s.runNotify, jobs.MaxAttempts(3))
+	}
+	return s
+}
+
+// notifyJob is an event waiting to be sent to an organisation's members.
+type notifyJob struct {
+	OrganisationID uuid.UUID      `json:"organisationId"`
+	Category       string         `json:"category"`
+	EventType      string         `json:"eventType"`
+	Data           map[string]any `json:"data"`
+}
+
+// Publish delivers an event to the organisation's webhooks and notifies its
+// active members. Like webhook delivery it is best-effort: errors are logged,
+// never returned to the caller. Events outside every notification category
+// only go to webhooks.
+func (s *Service) Publish(ctx context.Context, organisationID uuid.UUID, eventType string, data map[string]any) {
+	if s.webhooks != nil {
+		s.webhooks.Publish(ctx, organisationID, eventType, data)
+	}
+	category := eventCategory(eventType)
+	if category == "" {
+		return
+	}
+	if s.jobs == nil {
+		if err := s.notify(ctx, organisationID, category, eventType, data); err != nil {
+			slog.Error("Error notifying members", "error", err, "organisation_id", organisationID, "type", eventType)
+		}
+		return
+	}
+	job := notifyJob{OrganisationID: organisationID, Category: category, EventType: eventType, Data: data}
+	if _, err := s.jobs.Enqueue(ctx, jobNotify, job); err != nil {
+		slog.Error("Error enqueueing notification", "error", err, "organisation_id", organisationID, "type", eventType)
+	}
+}
+
+func (s *Service) runNotify(ctx context.Context, payload json.RawMessage) error {
+	var job notifyJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return fmt.Errorf("decoding notify job: %w", err)
+	}
+	return s.notify(ctx, job.OrganisationID, job.Category, job.EventType, job.Data)
+}
+
+// notify sends an event to each member according to their preferences:
+// straight to their in-app feed and inbox, or queued for their email digest.
+// It only returns an error when no member has been notified yet; failures
+// for one member are logged and the rest are still notified.
+func (s *Service) notify(ctx context.Context, organisationID uuid.UUID, category, eventType string, data map[string]any) error {
+	recipients, err := s.store.ListOrganisationNotificationRecipients(ctx, organisationID)
+	if err != nil {
+		return fmt.Errorf("listing notification recipients: %w", err)
+	}
+	rows, err := s.store.ListOrganisationNotificationPreferences(ctx, organisationID)
+	if err != nil {
+		return fmt.Errorf("loading notification preferences: %w", err)
+	}
+	overrides := make(map[uuid.UUID][]query.NotificationPreference)
+	for _, row := range rows {
+		overrides[row.UserID] = append(overrides[row.UserID], row)
+	}
+	summary := eventSummary(eventType, data)
+	payload, err := json.Marshal(data)
+	if err != nil {
+		slog.Error("Error encoding notification data", "error", err, "type", eventType)
+		payload = []byte("{}")
+	}
+
+	for _, r := range recipients {
+		prefs := resolve(r.Role, overrides[r.UserID])
+		if p := prefs[prefKey{category, ChannelInApp}]; p.Enabled {
+			err := s.store.CreateUserNotification(ctx, query.CreateUserNotificationParams{
+				UserID:         r.UserID,
+				OrganisationID: organisationID,
+				Category:       category,
+				EventType:      eventType,
+				Summary:        summary,
+				Data:           payload,
+			})
+			if err != nil {
+				slog.Error("Error creating in-app notification", "error", err, "user_id", r.UserID, "type", eventType)
+			}
+		}
+		p := prefs[prefKey{category, ChannelEmail}]
+		switch {
+		case !p.Enabled || r.Email == "":
+		case p.Digest:
+			err := s.store.CreateNotificationDigestItem(ctx, query.CreateNotificationDigestItemParams{
+				UserID:         r.UserID,
+				OrganisationID: organisationID,
+				Category:       category,
+				EventType:      eventType,
+				Summary:        summary,
+			})
+			if err != nil {
+				slog.Error("Error queueing digest notification", "error", err, "user_id", r.UserID, "type", eventType)
+			}
+		default:
+			if err := s.sendImmediate(ctx, r.Email, summary); err != nil {
+				slog.Error("Error sending notification email", "error", err, "user_id", r.UserID, "type", eventType)
+			}
+		}
+	}
+	return nil
+}
+
+// sendImmediate emails one notification
+func (s *Service) sendImmediate(ctx context.Context, to, summary string) error {
+	if s.mailer == nil {
+		return errors.New("no mailer configured")
+	}
+	body := emailBody([]string{summary}, s.cfg.ClientURL)
+	return s.mailer.SendEmail(ctx, to, summary, body)
+}
@@ -0,0 +1,50 @@
+package presence
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message types sent by the client.
+const (
+	TypeJoin      = "join"
+	TypeLeave     = "leave"
+	TypeHeartbeat = "heartbeat"
+)
+
+// Message types sent by the server.
+const (
+	TypePresence = "presence"
+	TypeError    = "error"
+)
+
+// ClientMessage is a message from the client. ContentID is unused for heartbeats.
+type ClientMessage struct {
+	Type      string    `json:"type"`
+	ContentID uuid.UUID `json:"contentId"`
+}
+
+// Message is a message to the client: the current viewers of a room, or an
+// error about a single request that leaves the connection open.
+type Message struct {
+	Type      string    `json:"type"`
+	ContentID uuid.UUID `json:"contentId"`
+	Viewers   []Viewer  `json:"viewers,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Viewer is a member viewing a content item. A member with several tabs open
+// is listed once, from when they first joined.
+type Viewer struct {
+	UserID   uuid.UUID `json:"userId"`
+	Name     string    `json:"name"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// member is the identity of a connected session.
+type member struct {
+	userID uuid.UUID
+	orgID  uuid.UUID
+	name   string
+}
@@ -0,0 +1,46 @@
+package presence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxRoomsPerSession caps how many content items one connection can watch.
+	maxRoomsPerSession = 20
+	// outboxSize is how many presence updates may queue for a slow client
+	// before its session is dropped.
+	outboxSize = 16
+)
+
+// store defines the database interface for presence
+type store interface {
+	GetOrgMemberIdentity(ctx context.Context, arg query.GetOrgMemberIdentityParams) (query.GetOrgMemberIdentityRow, error)
+	GetH5PContentOrgId(ctx context.Context, id uuid.UUID) (query.GetH5PContentOrgIdRow, error)
+}
+
+// Service tracks who is viewing each content item and pushes the viewer list
+// to everyone in the room when it changes. State is held in memory, so each
+// replica only sees the sessions connected to it. It is independent of
+// content saving: presence never touches content params.
+type Service struct {
+	store store
+	now   func() time.Time
+
+	mu    sync.Mutex
+	rooms map[uuid.UUID]map[*Session]time.Time // content ID -> session -> joined at
+}
+
+// NewService creates a new presence service
+func NewService(store store) *Service {
+	return &Service{
+		store: store,
+		now:   time.Now,
+		rooms: make(map[uuid.UUID]map[*Session]time.Time),
+	}
+}
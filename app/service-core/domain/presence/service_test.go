@@ -0,0 +1,264 @@
+package presence
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore knows which members belong to which organisation and which
+// organisation owns each content item.
+type fakeStore struct {
+	members map[query.GetOrgMemberIdentityParams]query.GetOrgMemberIdentityRow
+	content map[uuid.UUID]uuid.UUID
+	err     error
+}
+
+func (s *fakeStore) GetOrgMemberIdentity(_ context.Context, arg query.GetOrgMemberIdentityParams) (query.GetOrgMemberIdentityRow, error) {
+	if s.err != nil {
+		return query.GetOrgMemberIdentityRow{}, s.err
+	}
+	identity, ok := s.members[arg]
+	if !ok {
+		return query.GetOrgMemberIdentityRow{}, sql.ErrNoRows
+	}
+	return identity, nil
+}
+
+func (s *fakeStore) GetH5PContentOrgId(_ context.Context, id uuid.UUID) (query.GetH5PContentOrgIdRow, error) {
+	if s.err != nil {
+		return query.GetH5PContentOrgIdRow{}, s.err
+	}
+	orgID, ok := s.content[id]
+	if !ok {
+		return query.GetH5PContentOrgIdRow{}, sql.ErrNoRows
+	}
+	return query.GetH5PContentOrgIdRow{ID: id, OrgID: orgID}, nil
+}
+
+func (s *fakeStore) addMember(userID, orgID uuid.UUID, displayName, email string) {
+	if s.members == nil {
+		s.members = make(map[query.GetOrgMemberIdentityParams]query.GetOrgMemberIdentityRow)
+	}
+	s.members[query.GetOrgMemberIdentityParams{UserID: userID, OrganisationID: orgID}] = query.GetOrgMemberIdentityRow{DisplayName: displayName, Email: email}
+}
+
+func newTestService(store *fakeStore) *Service {
+	s := NewService(store)
+	// Each join is a second after the last, so viewer order is predictable
+	clock := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	return s
+}
+
+// drain returns the messages queued for a session.
+func drain(sess *Session) []Message {
+	var msgs []Message
+	for {
+		select {
+		case msg := <-sess.Messages():
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func TestConnect(t *testing.T) {
+	orgID := uuid.New()
+	userID := uuid.New()
+	noNameID := uuid.New()
+
+	tests := []struct {
+		name     string
+		store    *fakeStore
+		userID   uuid.UUID
+		wantName string
+		wantErr  error
+	}{
+		{name: "display name", userID: userID, wantName: "Ada"},
+		{name: "falls back to email", userID: noNameID, wantName: "grace@example.com"},
+		{name: "not a member", userID: uuid.New(), store: &fakeStore{}, wantErr: pkg.ForbiddenError{}},
+		{name: "store fails", userID: userID, store: &fakeStore{err: errors.New("connection reset")}, wantErr: pkg.InternalError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store
+			if store == nil {
+				store = &fakeStore{}
+				store.addMember(userID, orgID, "Ada", "ada@example.com")
+				store.addMember(noNameID, orgID, "", "grace@example.com")
+			}
+			sess, err := newTestService(store).Connect(context.Background(), tt.userID, orgID)
+			if tt.wantErr != nil {
+				assert.IsType(t, tt.wantErr, err)
+				assert.Nil(t, sess)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, member{userID: tt.userID, orgID: orgID, name: tt.wantName}, sess.member)
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	orgID := uuid.New()
+	otherOrgID := uuid.New()
+	userID := uuid.New()
+	contentID := uuid.New()
+	foreignID := uuid.New()
+
+	store := &fakeStore{content: map[uuid.UUID]uuid.UUID{contentID: orgID, foreignID: otherOrgID}}
+	store.addMember(userID, orgID, "Ada", "ada@example.com")
+	s := newTestService(store)
+
+	tests := []struct {
+		name      string
+		contentID uuid.UUID
+		wantErr   error
+	}{
+		{name: "own organisation", contentID: contentID},
+		{name: "again is a no-op", contentID: contentID},
+		// Content of another organisation looks the same as missing content
+		{name: "another organisation", contentID: foreignID, wantErr: pkg.NotFoundError{}},
+		{name: "missing", contentID: uuid.New(), wantErr: pkg.NotFoundError{}},
+	}
+	sess, err := s.Connect(context.Background(), userID, orgID)
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sess.Join(context.Background(), tt.contentID)
+			if tt.wantErr != nil {
+				assert.IsType(t, tt.wantErr, err)
+				assert.Empty(t, s.Viewers(tt.contentID))
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, s.Viewers(tt.contentID), 1)
+		})
+	}
+	// Only the first join changed the room
+	assert.Len(t, drain(sess), 1)
+}
+
+func TestJoin_RoomLimit(t *testing.T) {
+	orgID := uuid.New()
+	userID := uuid.New()
+	store := &fakeStore{content: make(map[uuid.UUID]uuid.UUID)}
+	store.addMember(userID, orgID, "Ada", "ada@example.com")
+	s := newTestService(store)
+	sess, err := s.Connect(context.Background(), userID, orgID)
+	require.NoError(t, err)
+
+	for range maxRoomsPerSession {
+		contentID := uuid.New()
+		store.content[contentID] = orgID
+		require.NoError(t, sess.Join(context.Background(), contentID))
+		drain(sess)
+	}
+	contentID := uuid.New()
+	store.content[contentID] = orgID
+	assert.IsType(t, pkg.BadRequestError{}, sess.Join(context.Background(), contentID))
+
+	// Leaving a room makes space for another
+	for id := range sess.rooms {
+		sess.Leave(id)
+		break
+	}
+	assert.NoError(t, sess.Join(context.Background(), contentID))
+}
+
+func TestViewers(t *testing.T) {
+	orgID := uuid.New()
+	ada := uuid.New()
+	grace := uuid.New()
+	contentID := uuid.New()
+	store := &fakeStore{content: map[uuid.UUID]uuid.UUID{contentID: orgID}}
+	store.addMember(ada, orgID, "Ada", "ada@example.com")
+	store.addMember(grace, orgID, "Grace", "grace@example.com")
+	s := newTestService(store)
+
+	connect := func(userID uuid.UUID) *Session {
+		t.Helper()
+		sess, err := s.Connect(context.Background(), userID, orgID)
+		require.NoError(t, err)
+		require.NoError(t, sess.Join(context.Background(), contentID))
+		return sess
+	}
+	adaFirstTab := connect(ada)
+	graceTab := connect(grace)
+	adaSecondTab := connect(ada)
+
+	// Ada has two tabs open but is listed once, from her first
+	viewers := s.Viewers(contentID)
+	require.Len(t, viewers, 2)
+	assert.Equal(t, []uuid.UUID{ada, grace}, []uuid.UUID{viewers[0].UserID, viewers[1].UserID})
+	assert.Equal(t, "Ada", viewers[0].Name)
+	firstJoined := viewers[0].JoinedAt
+
+	// Everyone in the room heard about each join after their own
+	assert.Len(t, drain(adaFirstTab), 3)
+	assert.Len(t, drain(graceTab), 2)
+	msgs := drain(adaSecondTab)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, Message{Type: TypePresence, ContentID: contentID, Viewers: viewers}, msgs[0])
+
+	// Closing the first tab keeps Ada listed from the second
+	adaFirstTab.Close()
+	viewers = s.Viewers(contentID)
+	require.Len(t, viewers, 2)
+	assert.Equal(t, grace, viewers[0].UserID)
+	assert.Equal(t, ada, viewers[1].UserID)
+	assert.True(t, viewers[1].JoinedAt.After(firstJoined))
+
+	graceTab.Leave(contentID)
+	adaSecondTab.Close()
+	adaSecondTab.Close()
+	assert.Empty(t, s.Viewers(contentID))
+	assert.Empty(t, s.rooms)
+}
+
+func TestBroadcast_DropsSlowSession(t *testing.T) {
+	orgID := uuid.New()
+	userID := uuid.New()
+	contentID := uuid.New()
+	store := &fakeStore{content: map[uuid.UUID]uuid.UUID{contentID: orgID}}
+	store.addMember(userID, orgID, "Ada", "ada@example.com")
+	s := newTestService(store)
+
+	slow, err := s.Connect(context.Background(), userID, orgID)
+	require.NoError(t, err)
+	require.NoError(t, slow.Join(context.Background(), contentID))
+
+	// Each viewer joining and leaving queues two updates for the slow
+	// session, which never reads them
+	for range outboxSize {
+		sess, err := s.Connect(context.Background(), userID, orgID)
+		require.NoError(t, err)
+		require.NoError(t, sess.Join(context.Background(), contentID))
+		drain(sess)
+		sess.Close()
+	}
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("slow session was not dropped")
+	}
+	assert.Empty(t, s.Viewers(contentID))
+	// A dropped session can't rejoin
+	assert.NoError(t, slow.Join(context.Background(), contentID))
+	assert.Empty(t, s.Viewers(contentID))
+}
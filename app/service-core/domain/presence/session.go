@@ -0,0 +1,195 @@
+package presence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"app/pkg"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Session is one client connection. It can watch several content items of its
+// organisation at once; updates for all of them arrive on Messages.
+type Session struct {
+	service *Service
+	member  member
+	out     chan Message
+	done    chan struct{}
+
+	// guarded by service.mu
+	closed bool
+	rooms  map[uuid.UUID]bool
+}
+
+// Connect starts a session for an active member of the organisation.
+func (s *Service) Connect(ctx context.Context, userID, orgID uuid.UUID) (*Session, error) {
+	identity, err := s.store.GetOrgMemberIdentity(ctx, query.GetOrgMemberIdentityParams{
+		UserID:         userID,
+		OrganisationID: orgID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.ForbiddenError{Err: errors.New("not a member of this organisation")}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading membership", Err: err}
+	}
+
+	name := identity.DisplayName
+	if name == "" {
+		name = identity.Email
+	}
+	return &Session{
+		service: s,
+		member:  member{userID: userID, orgID: orgID, name: name},
+		out:     make(chan Message, outboxSize),
+		done:    make(chan struct{}),
+		rooms:   make(map[uuid.UUID]bool),
+	}, nil
+}
+
+// Messages delivers presence updates for the rooms the session has joined.
+func (sess *Session) Messages() <-chan Message {
+	return sess.out
+}
+
+// Done is closed when the session ends, including when it is dropped for
+// falling too far behind on Messages.
+func (sess *Session) Done() <-chan struct{} {
+	return sess.done
+}
+
+// Join adds the session to a content item's room and broadcasts the new
+// viewer list. The content must belong to the session's organisation.
+func (sess *Session) Join(ctx context.Context, contentID uuid.UUID) error {
+	content, err := sess.service.store.GetH5PContentOrgId(ctx, contentID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && content.OrgID != sess.member.orgID) {
+		return pkg.NotFoundError{Message: "Content not found"}
+	}
+	if err != nil {
+		return pkg.InternalError{Message: "Error loading content", Err: err}
+	}
+
+	s := sess.service
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess.closed || sess.rooms[contentID] {
+		return nil
+	}
+	if len(sess.rooms) >= maxRoomsPerSession {
+		return pkg.BadRequestError{Message: fmt.Sprintf("A connection can watch at most %d content items", maxRoomsPerSession)}
+	}
+
+	room := s.rooms[contentID]
+	if room == nil {
+		room = make(map[*Session]time.Time)
+		s.rooms[contentID] = room
+	}
+	room[sess] = s.now()
+	sess.rooms[contentID] = true
+	s.broadcastLocked(contentID)
+	return nil
+}
+
+// Leave removes the session from a content item's room. Leaving a room the
+// session isn't in is a no-op.
+func (sess *Session) Leave(contentID uuid.UUID) {
+	s := sess.service
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess.rooms[contentID] {
+		sess.leaveLocked(contentID)
+		s.broadcastLocked(contentID)
+	}
+}
+
+// Close leaves every room and ends the session. It is safe to call more than once.
+func (sess *Session) Close() {
+	s := sess.service
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess.closeLocked()
+}
+
+// closeLocked may re-enter itself when closing one slow session drops
+// another, so it marks the session closed before broadcasting.
+func (sess *Session) closeLocked() {
+	if sess.closed {
+		return
+	}
+	sess.closed = true
+	close(sess.done)
+	for contentID := range sess.rooms {
+		sess.leaveLocked(contentID)
+		sess.service.broadcastLocked(contentID)
+	}
+}
+
+func (sess *Session) leaveLocked(contentID uuid.UUID) {
+	s := sess.service
+	delete(sess.rooms, contentID)
+	delete(s.rooms[contentID], sess)
+	if len(s.rooms[contentID]) == 0 {
+		delete(s.rooms, contentID)
+	}
+}
+
+// Viewers returns who is viewing a content item, earliest first.
+func (s *Service) Viewers(contentID uuid.UUID) []Viewer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.viewersLocked(contentID)
+}
+
+func (s *Service) viewersLocked(contentID uuid.UUID) []Viewer {
+	byUser := make(map[uuid.UUID]Viewer)
+	for sess, joinedAt := range s.rooms[contentID] {
+		if sess.closed {
+			continue
+		}
+		v, ok := byUser[sess.member.userID]
+		if !ok || joinedAt.Before(v.JoinedAt) {
+			byUser[sess.member.userID] = Viewer{UserID: sess.member.userID, Name: sess.member.name, JoinedAt: joinedAt}
+		}
+	}
+	viewers := make([]Viewer, 0, len(byUser))
+	for _, v := range byUser {
+		viewers = append(viewers, v)
+	}
+	slices.SortFunc(viewers, func(a, b Viewer) int {
+		if c := a.JoinedAt.Compare(b.JoinedAt); c != 0 {
+			return c
+		}
+		return slices.Compare(a.UserID[:], b.UserID[:])
+	})
+	return viewers
+}
+
+// broadcastLocked sends the room's viewer list to everyone in it. A session
+// whose outbox is full is closed rather than blocking the room.
+func (s *Service) broadcastLocked(contentID uuid.UUID) {
+	room := s.rooms[contentID]
+	if len(room) == 0 {
+		return
+	}
+	msg := Message{Type: TypePresence, ContentID: contentID, Viewers: s.viewersLocked(contentID)}
+	var slow []*Session
+	for sess := range room {
+		if sess.closed {
+			continue
+		}
+		select {
+		case sess.out <- msg:
+		default:
+			slow = append(slow, sess)
+		}
+	}
+	for _, sess := range slow {
+		sess.closeLocked()
+	}
+}
@@ -0,0 +1,49 @@
+package ranktracker
+
+import (
+	"app/pkg/jobs"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// jobRun collects one started tracker run. It's queued so a run started just
+// before a restart still finishes.
+const jobRun = "ranktracker.run"
+
+type runJob struct {
+	Tracker  query.RankTracker          `json:"tracker"`
+	RunID    uuid.UUID                  `json:"runId"`
+	Keywords []query.RankTrackerKeyword `json:"keywords"`
+}
+
+func (s *Service) registerJobs(queue *jobs.Queue) {
+	// A retried run queues its lookups again, so it's only retried when it
+	// was interrupted before finishing
+	queue.Register(jobRun, s.runTrackerJob, jobs.MaxAttempts(2), jobs.Timeout(runTimeout))
+}
+
+func (s *Service) runTrackerJob(ctx context.Context, payload json.RawMessage) error {
+	var job runJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return fmt.Errorf("decoding rank tracker run job: %w", err)
+	}
+	latest, err := s.store.GetLatestRankTrackerRun(ctx, job.Tracker.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil // the tracker was deleted
+	}
+	if err != nil {
+		return fmt.Errorf("loading rank tracker run: %w", err)
+	}
+	if latest.ID != job.RunID || latest.Status != StatusRunning {
+		return nil // finished, or failed as stale, before the job was interrupted
+	}
+	s.runTracker(ctx, job.Tracker, job.RunID, job.Keywords)
+	return nil
+}
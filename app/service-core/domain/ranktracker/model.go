@@ -0,0 +1,208 @@
+package ranktracker
+
+import (
+	"encoding/json"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Devices a tracker can collect SERPs for.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+)
+
+// Run statuses. A partial run has positions for some keywords only.
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusPartial   = "partial"
+	StatusFailed    = "failed"
+)
+
+// Where a position came from: a live SERP, or DataForSEO Labs' ranked
+// keywords when the SERP lookup failed. Labs only knows the tracked domain's
+// positions, not the competitors'.
+const (
+	SourceSERP = "serp"
+	SourceLabs = "labs"
+)
+
+// TrackerRequest registers a domain and its keywords for daily tracking.
+type TrackerRequest struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	Domain         string    `json:"domain"`
+	LocationCode   int       `json:"locationCode"`
+	LanguageCode   string    `json:"languageCode"`
+	Device         string    `json:"device"`      // desktop (default) or mobile
+	Competitors    []string  `json:"competitors"` // up to MaxCompetitors domains
+	Keywords       []string  `json:"keywords"`
+}
+
+// KeywordsRequest adds keywords to or removes them from a tracker.
+type KeywordsRequest struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	Keywords       []string  `json:"keywords"`
+}
+
+// Tracker is a domain whose keywords are looked up every day.
+type Tracker struct {
+	ID           uuid.UUID  `json:"id"`
+	Domain       string     `json:"domain"`
+	LocationCode int32      `json:"locationCode"`
+	LanguageCode string     `json:"languageCode"`
+	Device       string     `json:"device"`
+	Competitors  []string   `json:"competitors"`
+	KeywordCount int64      `json:"keywordCount"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastRunAt    *time.Time `json:"lastRunAt"`
+	NextRunAt    time.Time  `json:"nextRunAt"`
+	LatestRun    *Run       `json:"latestRun"` // nil until a run has finished
+}
+
+// TrackerDetail is a tracker with its keywords and where the domain ranked
+// for each in the latest run.
+type TrackerDetail struct {
+	Tracker
+	Keywords []TrackedKeyword `json:"keywords"`
+}
+
+// TrackedKeyword is one keyword of a tracker. Position is nil when the
+// domain isn't in the top 100, or the keyword hasn't been looked up yet.
+type TrackedKeyword struct {
+	Keyword      string `json:"keyword"`
+	SearchVolume *int32 `json:"searchVolume"`
+	Position     *int32 `json:"position"`
+	URL          string `json:"url,omitempty"`
+}
+
+// Run is one daily lookup of a tracker's keywords.
+type Run struct {
+	ID           uuid.UUID          `json:"id"`
+	Status       string             `json:"status"`
+	KeywordCount int32              `json:"keywordCount"`
+	FailedCount  int32              `json:"failedCount"`
+	Cost         float64            `json:"cost"`         // USD, as billed by DataForSEO
+	ShareOfVoice map[string]float64 `json:"shareOfVoice"` // percent per domain
+	Error        string             `json:"error,omitempty"`
+	CreatedAt    time.Time          `json:"createdAt"`
+	CompletedAt  *time.Time         `json:"completedAt"`
+}
+
+// PositionPoint is where a domain ranked for a keyword in one run.
+type PositionPoint struct {
+	RunID     uuid.UUID `json:"runId"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Position  *int32    `json:"position"`
+	URL       string    `json:"url,omitempty"`
+	Source    string    `json:"source"`
+}
+
+// DomainHistory is one domain's positions for a keyword, oldest first.
+type DomainHistory struct {
+	Domain string          `json:"domain"`
+	Points []PositionPoint `json:"points"`
+}
+
+// KeywordHistory is the position time series of a keyword for the tracked
+// domain and each competitor.
+type KeywordHistory struct {
+	Keyword string          `json:"keyword"`
+	Days    int             `json:"days"`
+	Domains []DomainHistory `json:"domains"`
+}
+
+// Movement is how far the tracked domain moved for a keyword. Change is
+// positive when it moved up; dropping out of the top 100 counts as
+// position 101.
+type Movement struct {
+	Keyword  string `json:"keyword"`
+	Previous *int32 `json:"previous"`
+	Current  *int32 `json:"current"`
+	Change   int    `json:"change"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Movers are the keywords that moved most between the latest run and the
+// run Days before it. From is nil when there's no run that old yet.
+type Movers struct {
+	Days     int        `json:"days"`
+	From     *time.Time `json:"from"`
+	To       *time.Time `json:"to"`
+	Improved []Movement `json:"improved"`
+	Declined []Movement `json:"declined"`
+}
+
+// ShareOfVoicePoint is each domain's share of voice in one run.
+type ShareOfVoicePoint struct {
+	RunID  uuid.UUID          `json:"runId"`
+	Date   time.Time          `json:"date"`
+	Shares map[string]float64 `json:"shares"`
+}
+
+// ShareOfVoice is the share of voice time series of a tracker, oldest first.
+type ShareOfVoice struct {
+	Domains []string            `json:"domains"`
+	Points  []ShareOfVoicePoint `json:"points"`
+}
+
+// TaskSummary totals one run of the rank tracker task.
+type TaskSummary struct {
+	Interrupted int64 `json:"interrupted"` // runs left running by a replica that went away
+	Started     int   `json:"started"`
+	Skipped     int   `json:"skipped"` // due trackers without keywords, or over budget
+}
+
+func newTracker(t query.RankTracker, keywordCount int64) Tracker {
+	tracker := Tracker{
+		ID:           t.ID,
+		Domain:       t.Domain,
+		LocationCode: t.LocationCode,
+		LanguageCode: t.LanguageCode,
+		Device:       t.Device,
+		Competitors:  t.Competitors,
+		KeywordCount: keywordCount,
+		CreatedAt:    t.CreatedAt,
+		NextRunAt:    t.NextRunAt,
+	}
+	if tracker.Competitors == nil {
+		tracker.Competitors = []string{}
+	}
+	if t.LastRunAt.Valid {
+		tracker.LastRunAt = &t.LastRunAt.Time
+	}
+	return tracker
+}
+
+func newRun(r query.RankTrackerRun) (Run, error) {
+	run := Run{
+		ID:           r.ID,
+		Status:       r.Status,
+		KeywordCount: r.KeywordCount,
+		FailedCount:  r.FailedCount,
+		Cost:         r.Cost,
+		ShareOfVoice: map[string]float64{},
+		Error:        r.Error,
+		CreatedAt:    r.CreatedAt,
+	}
+	if r.CompletedAt.Valid {
+		run.CompletedAt = &r.CompletedAt.Time
+	}
+	if len(r.ShareOfVoice) > 0 {
+		if err := json.Unmarshal(r.ShareOfVoice, &run.ShareOfVoice); err != nil {
+			return Run{}, err
+		}
+	}
+	return run, nil
+}
+
+func newPositionPoint(p query.RankPosition) PositionPoint {
+	point := PositionPoint{RunID: p.RunID, CheckedAt: p.CheckedAt, URL: p.Url, Source: p.Source}
+	if p.Position.Valid {
+		point.Position = &p.Position.Int32
+	}
+	return point
+}
@@ -0,0 +1,236 @@
+package ranktracker
+
+import (
+	"app/pkg"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"service-core/domain/membership"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxReportDays is the longest window the reports cover.
+	MaxReportDays = 365
+	// MaxMovers is the most keywords Movers returns in each direction.
+	MaxMovers = 100
+
+	defaultHistoryDays = 30
+	defaultMoversDays  = 7
+	defaultMovers      = 10
+	unranked           = serpDepth + 1
+)
+
+// History returns the positions of the tracked domain and its competitors
+// for one keyword over the last days (30 by default), oldest first, to any
+// member of the tracker's organisation.
+func (s *Service) History(ctx context.Context, userID, organisationID, trackerID uuid.UUID, keyword string, days int) (*KeywordHistory, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	days, err := reportDays(days, defaultHistoryDays)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := trackedKeywords([]string{keyword})
+	if err != nil {
+		return nil, err
+	}
+	if len(keywords) == 0 {
+		return nil, pkg.BadRequestError{Message: "keyword is required"}
+	}
+
+	tracker, err := s.getTracker(ctx, organisationID, trackerID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.store.ListRankPositionHistory(ctx, query.ListRankPositionHistoryParams{
+		TrackerID: trackerID,
+		Keyword:   keywords[0],
+		CheckedAt: time.Now().AddDate(0, 0, -days),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading position history", Err: err}
+	}
+
+	// The tracked domain first, then its competitors, then any domain it no
+	// longer follows
+	history := &KeywordHistory{Keyword: keywords[0], Days: days, Domains: []DomainHistory{}}
+	index := map[string]int{}
+	for _, domain := range append([]string{tracker.Domain}, tracker.Competitors...) {
+		index[domain] = len(history.Domains)
+		history.Domains = append(history.Domains, DomainHistory{Domain: domain, Points: []PositionPoint{}})
+	}
+	for _, row := range rows {
+		i, ok := index[row.Domain]
+		if !ok {
+			i = len(history.Domains)
+			index[row.Domain] = i
+			history.Domains = append(history.Domains, DomainHistory{Domain: row.Domain, Points: []PositionPoint{}})
+		}
+		history.Domains[i].Points = append(history.Domains[i].Points, newPositionPoint(row))
+	}
+	return history, nil
+}
+
+// Movers compares the tracked domain's positions in the latest run with
+// those in the last run at least days (7 by default) before it, and returns
+// up to limit (10 by default) keywords that improved and declined most. It's
+// available to any member of the tracker's organisation.
+func (s *Service) Movers(ctx context.Context, userID, organisationID, trackerID uuid.UUID, days, limit int) (*Movers, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	days, err := reportDays(days, defaultMoversDays)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = defaultMovers
+	}
+	if limit < 1 || limit > MaxMovers {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("limit must be between 1 and %d", MaxMovers)}
+	}
+
+	tracker, err := s.getTracker(ctx, organisationID, trackerID)
+	if err != nil {
+		return nil, err
+	}
+	movers := &Movers{Days: days, Improved: []Movement{}, Declined: []Movement{}}
+	latest, err := s.store.GetLatestRankTrackerRun(ctx, trackerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return movers, nil
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading latest run", Err: err}
+	}
+	movers.To = &latest.CreatedAt
+	previous, err := s.store.GetRankTrackerRunBefore(ctx, query.GetRankTrackerRunBeforeParams{
+		TrackerID: trackerID,
+		CreatedAt: latest.CreatedAt.AddDate(0, 0, -days),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return movers, nil
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading earlier run", Err: err}
+	}
+	movers.From = &previous.CreatedAt
+
+	current, err := s.runPositions(ctx, latest.ID, tracker.Domain)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.runPositions(ctx, previous.ID, tracker.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	for keyword, cur := range current {
+		prev, ok := before[keyword]
+		if !ok {
+			continue // not looked up in the earlier run
+		}
+		m := Movement{Keyword: keyword, URL: cur.Url, Change: rankOf(prev) - rankOf(cur)}
+		if prev.Position.Valid {
+			m.Previous = &prev.Position.Int32
+		}
+		if cur.Position.Valid {
+			m.Current = &cur.Position.Int32
+		}
+		switch {
+		case m.Change > 0:
+			movers.Improved = append(movers.Improved, m)
+		case m.Change < 0:
+			movers.Declined = append(movers.Declined, m)
+		}
+	}
+	// Ties are broken by keyword so the order is stable between calls
+	slices.SortFunc(movers.Improved, func(a, b Movement) int {
+		return cmp.Or(cmp.Compare(b.Change, a.Change), strings.Compare(a.Keyword, b.Keyword))
+	})
+	slices.SortFunc(movers.Declined, func(a, b Movement) int {
+		return cmp.Or(cmp.Compare(a.Change, b.Change), strings.Compare(a.Keyword, b.Keyword))
+	})
+	movers.Improved = movers.Improved[:min(limit, len(movers.Improved))]
+	movers.Declined = movers.Declined[:min(limit, len(movers.Declined))]
+	return movers, nil
+}
+
+// ShareOfVoice returns the share of voice of the tracked domain and its
+// competitors in each run over the last days (30 by default), oldest first,
+// to any member of the tracker's organisation.
+func (s *Service) ShareOfVoice(ctx context.Context, userID, organisationID, trackerID uuid.UUID, days int) (*ShareOfVoice, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	days, err := reportDays(days, defaultHistoryDays)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker, err := s.getTracker(ctx, organisationID, trackerID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.store.ListRankTrackerRuns(ctx, query.ListRankTrackerRunsParams{
+		TrackerID: trackerID,
+		CreatedAt: time.Now().AddDate(0, 0, -days),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading runs", Err: err}
+	}
+
+	sov := &ShareOfVoice{
+		Domains: append([]string{tracker.Domain}, tracker.Competitors...),
+		Points:  []ShareOfVoicePoint{},
+	}
+	for _, row := range rows {
+		if row.Status != StatusCompleted && row.Status != StatusPartial {
+			continue
+		}
+		run, err := newRun(row)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error reading run", Err: err}
+		}
+		sov.Points = append(sov.Points, ShareOfVoicePoint{RunID: run.ID, Date: run.CreatedAt, Shares: run.ShareOfVoice})
+	}
+	return sov, nil
+}
+
+func (s *Service) runPositions(ctx context.Context, runID uuid.UUID, domain string) (map[string]query.RankPosition, error) {
+	rows, err := s.store.ListRankPositionsByRun(ctx, query.ListRankPositionsByRunParams{RunID: runID, Domain: domain})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading positions", Err: err}
+	}
+	positions := make(map[string]query.RankPosition, len(rows))
+	for _, row := range rows {
+		positions[row.Keyword] = row
+	}
+	return positions, nil
+}
+
+func reportDays(days, fallback int) (int, error) {
+	if days == 0 {
+		return fallback, nil
+	}
+	if days < 1 || days > MaxReportDays {
+		return 0, pkg.BadRequestError{Message: fmt.Sprintf("days must be between 1 and %d", MaxReportDays)}
+	}
+	return days, nil
+}
+
+func rankOf(p query.RankPosition) int {
+	if !p.Position.Valid {
+		return unranked
+	}
+	return int(p.Position.Int32)
+}
@@ -0,0 +1,427 @@
+package ranktracker
+
+import (
+	"app/pkg/dataforseo"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	serpDepth = 100
+	// maxLabsKeywords is the most keywords one ranked keywords call returns.
+	maxLabsKeywords = 1000
+)
+
+// ctrByPosition is the share of a SERP's clicks each of the first ten
+// organic positions gets. Positions 11 to 20 get 1% and lower ones none.
+var ctrByPosition = []float64{0, 0.28, 0.15, 0.11, 0.08, 0.07, 0.05, 0.04, 0.03, 0.03, 0.02}
+
+func clickThroughRate(position int32) float64 {
+	switch {
+	case position <= 0:
+		return 0
+	case int(position) < len(ctrByPosition):
+		return ctrByPosition[position]
+	case position <= 20:
+		return 0.01
+	default:
+		return 0
+	}
+}
+
+// position is where one domain ranked for one keyword; 0 means not in the
+// top 100.
+type position struct {
+	keyword  string
+	domain   string
+	position int32
+	url      string
+	source   string
+}
+
+// RunDueTrackers starts the daily run of every due tracker, after failing
+// the runs a replica that went away left running. The runs carry on in the
+// background after it returns. Organisations that dropped to a tier with a
+// lower limit have only their first keywords, alphabetically, looked up.
+func (s *Service) RunDueTrackers(ctx context.Context) (*TaskSummary, error) {
+	summary := &TaskSummary{}
+	if s.serp == nil {
+		return summary, nil
+	}
+
+	interrupted, err := s.store.FailStaleRankTrackerRuns(ctx, time.Now().Add(-runTimeout-time.Minute))
+	if err != nil {
+		return nil, fmt.Errorf("failing stale rank tracker runs: %w", err)
+	}
+	summary.Interrupted = interrupted
+
+	due, err := s.store.ListDueRankTrackers(ctx, dueBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("listing due rank trackers: %w", err)
+	}
+	for _, tracker := range due {
+		claimed, err := s.store.ClaimRankTracker(ctx, query.ClaimRankTrackerParams{ID: tracker.ID, NextRunAt: time.Now().Add(runInterval)})
+		if err != nil {
+			slog.Error("Failed to claim rank tracker", "trackerID", tracker.ID, "error", err)
+			continue
+		}
+		if claimed == 0 {
+			continue // another replica got to it first
+		}
+
+		started, err := s.startRun(ctx, tracker)
+		if err != nil {
+			slog.Error("Failed to start rank tracker run", "trackerID", tracker.ID, "error", err)
+			continue
+		}
+		if started {
+			summary.Started++
+		} else {
+			summary.Skipped++
+		}
+	}
+	return summary, nil
+}
+
+// startRun records a run of a claimed tracker and queues a job collecting
+// it. It skips trackers without keywords and runs that would overrun
+// the monthly budget.
+func (s *Service) startRun(ctx context.Context, tracker query.RankTracker) (bool, error) {
+	keywords, err := s.store.ListRankTrackerKeywords(ctx, tracker.ID)
+	if err != nil {
+		return false, fmt.Errorf("listing keywords: %w", err)
+	}
+	plan, err := s.store.GetOrganisationSeoPlan(ctx, tracker.OrganisationID)
+	if err != nil {
+		return false, fmt.Errorf("loading organisation plan: %w", err)
+	}
+	if limit := keywordLimit(plan); int64(len(keywords)) > limit {
+		slog.Info("Rank tracker is over its tier limit", "trackerID", tracker.ID, "keywords", len(keywords), "limit", limit)
+		keywords = keywords[:limit]
+	}
+	if len(keywords) == 0 {
+		return false, nil
+	}
+
+	report := s.serp.CostReport()
+	if report.Budget > 0 && report.Total+float64(len(keywords))*estimatedTaskCost > report.Budget {
+		slog.Warn("Skipping rank tracker run over the monthly SEO budget", "trackerID", tracker.ID, "keywords", len(keywords))
+		return false, nil
+	}
+
+	run, err := s.store.CreateRankTrackerRun(ctx, query.CreateRankTrackerRunParams{
+		TrackerID:      tracker.ID,
+		OrganisationID: tracker.OrganisationID,
+		KeywordCount:   int32(len(keywords)),
+	})
+	if err != nil {
+		return false, fmt.Errorf("creating run: %w", err)
+	}
+	if _, err := s.jobs.Enqueue(ctx, jobRun, runJob{Tracker: tracker, RunID: run.ID, Keywords: keywords}); err != nil {
+		return false, fmt.Errorf("queueing run: %w", err)
+	}
+	return true, nil
+}
+
+// runTracker refreshes the keywords' search volumes when they're stale,
+// collects the positions of the tracked domain and its competitors, and
+// stores them with the run's share of voice. It runs as a job, outliving the
+// task that started it.
+func (s *Service) runTracker(ctx context.Context, tracker query.RankTracker, runID uuid.UUID, keywords []query.RankTrackerKeyword) {
+	ctx = dataforseo.WithCostAttribution(ctx, tracker.OrganisationID.String())
+
+	volumes, volumeCost := s.refreshVolumes(ctx, tracker, keywords)
+	names := make([]string, len(keywords))
+	for i, k := range keywords {
+		names[i] = k.Keyword
+	}
+
+	params := query.CompleteRankTrackerRunParams{ID: runID, Status: StatusCompleted, ShareOfVoice: json.RawMessage("{}")}
+	positions, serpCost, err := s.collectPositions(ctx, tracker, names)
+	params.Cost = volumeCost + serpCost
+	if err != nil {
+		slog.Error("Rank tracker run failed", "runID", runID, "trackerID", tracker.ID, "error", err)
+		params.Status = StatusFailed
+		params.FailedCount = int32(len(names))
+		params.Error = "The keyword lookups could not be queued"
+		if errors.Is(err, dataforseo.ErrBudgetExceeded) {
+			params.Error = "The monthly SEO budget has been reached"
+		}
+		s.completeRun(params)
+		return
+	}
+
+	ranked := make(map[string]bool, len(names))
+	arg := query.AddRankPositionsParams{RunID: runID, TrackerID: tracker.ID}
+	for _, p := range positions {
+		arg.Keywords = append(arg.Keywords, p.keyword)
+		arg.Domains = append(arg.Domains, p.domain)
+		arg.Positions = append(arg.Positions, p.position)
+		arg.Urls = append(arg.Urls, p.url)
+		arg.Sources = append(arg.Sources, p.source)
+		if p.domain == tracker.Domain {
+			ranked[p.keyword] = true
+		}
+	}
+	if len(positions) > 0 {
+		if err := s.store.AddRankPositions(context.Background(), arg); err != nil {
+			slog.Error("Failed to save rank positions", "runID", runID, "error", err)
+			params.Status = StatusFailed
+			params.FailedCount = int32(len(names))
+			params.Error = "The positions could not be saved"
+			s.completeRun(params)
+			return
+		}
+	}
+
+	params.FailedCount = int32(len(names) - len(ranked))
+	switch {
+	case len(ranked) == 0:
+		params.Status = StatusFailed
+		params.Error = "None of the keywords could be looked up"
+	case params.FailedCount > 0:
+		params.Status = StatusPartial
+	}
+	domains := append([]string{tracker.Domain}, tracker.Competitors...)
+	if params.ShareOfVoice, err = json.Marshal(shareOfVoice(domains, volumes, positions)); err != nil {
+		slog.Error("Failed to encode share of voice", "runID", runID, "error", err)
+		params.ShareOfVoice = json.RawMessage("{}")
+	}
+	s.completeRun(params)
+	slog.Info("Rank tracker run finished", "runID", runID, "trackerID", tracker.ID, "status", params.Status, "keywords", len(names), "failed", params.FailedCount, "cost", params.Cost)
+}
+
+func (s *Service) completeRun(params query.CompleteRankTrackerRunParams) {
+	// The run's context may have run out while waiting for the SERPs
+	if err := s.store.CompleteRankTrackerRun(context.Background(), params); err != nil {
+		slog.Error("Failed to complete rank tracker run", "runID", params.ID, "error", err)
+	}
+}
+
+// collectPositions looks up each keyword's SERP, recording where the tracked
+// domain and each competitor rank. Keywords whose SERP couldn't be looked up
+// fall back to DataForSEO Labs' ranked keywords for the tracked domain, and
+// are left out when that fails too. Only failing to queue any lookup at all
+// is an error. The returned cost covers the SERP tasks; Labs calls are
+// attributed to the organisation but not returned per call.
+func (s *Service) collectPositions(ctx context.Context, tracker query.RankTracker, keywords []string) ([]position, float64, error) {
+	type queuedKeyword struct {
+		keyword string
+		taskID  string
+	}
+	var (
+		queued    []queuedKeyword
+		failed    []string
+		cost      float64
+		postErr   error
+		positions []position
+	)
+
+	for start := 0; start < len(keywords); start += dataforseo.MaxTasksPerPost {
+		chunk := keywords[start:min(start+dataforseo.MaxTasksPerPost, len(keywords))]
+		if postErr != nil {
+			failed = append(failed, chunk...)
+			continue
+		}
+		reqs := make([]dataforseo.SERPOrganicRequest, len(chunk))
+		for i, keyword := range chunk {
+			reqs[i] = dataforseo.SERPOrganicRequest{
+				Keyword:      keyword,
+				LocationCode: int(tracker.LocationCode),
+				LanguageCode: tracker.LanguageCode,
+				Device:       tracker.Device,
+				Depth:        serpDepth,
+				Tag:          tracker.ID.String(),
+			}
+		}
+		tasks, err := s.serp.PostSERPOrganicTasks(ctx, reqs)
+		if err != nil {
+			// Later chunks would fail the same way
+			postErr = err
+			failed = append(failed, chunk...)
+			continue
+		}
+		for i, task := range tasks {
+			if task.Err != nil {
+				slog.Warn("SERP lookup rejected", "keyword", chunk[i], "error", task.Err)
+				failed = append(failed, chunk[i])
+				continue
+			}
+			cost += task.Cost
+			queued = append(queued, queuedKeyword{keyword: chunk[i], taskID: task.ID})
+		}
+	}
+	if len(queued) == 0 && postErr != nil {
+		return nil, cost, postErr
+	}
+
+	for _, q := range queued {
+		var serp *dataforseo.SERPOrganicResult
+		err := s.serp.WaitForTask(ctx, q.taskID, func(ctx context.Context, id string) error {
+			var err error
+			serp, err = s.serp.GetSERPOrganicTask(ctx, id)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("SERP lookup failed", "keyword", q.keyword, "taskID", q.taskID, "error", err)
+			}
+			failed = append(failed, q.keyword)
+			continue
+		}
+		for _, domain := range append([]string{tracker.Domain}, tracker.Competitors...) {
+			p := position{keyword: q.keyword, domain: domain, source: SourceSERP}
+			if found := serp.FindDomain(domain); found != nil {
+				p.position = int32(found.RankGroup)
+				p.url = found.URL
+			}
+			positions = append(positions, p)
+		}
+	}
+
+	if len(failed) > 0 && ctx.Err() == nil {
+		positions = append(positions, s.labsPositions(ctx, tracker, failed)...)
+	}
+	return positions, cost, nil
+}
+
+// labsPositions reads the tracked domain's positions for keywords from
+// DataForSEO Labs' ranked keywords. A keyword Labs doesn't list the domain
+// for counts as not ranking. Keywords are left out when the call fails.
+func (s *Service) labsPositions(ctx context.Context, tracker query.RankTracker, keywords []string) []position {
+	if s.labs == nil {
+		return nil
+	}
+
+	var positions []position
+	for start := 0; start < len(keywords); start += maxLabsKeywords {
+		chunk := keywords[start:min(start+maxLabsKeywords, len(keywords))]
+		ranked, _, err := s.labs.GetDomainRankingKeywords(ctx, tracker.Domain, int(tracker.LocationCode), tracker.LanguageCode, dataforseo.LabsOptions{
+			Limit:  len(chunk),
+			Filter: dataforseo.Where("keyword_data.keyword", dataforseo.OpIn, chunk),
+		})
+		if err != nil {
+			slog.Warn("Ranked keywords fallback failed", "trackerID", tracker.ID, "keywords", len(chunk), "error", err)
+			continue
+		}
+		found := make(map[string]dataforseo.SERPItem, len(ranked))
+		for _, k := range ranked {
+			if k.RankedSERPElement != nil {
+				found[strings.ToLower(k.Keyword)] = k.RankedSERPElement.SERPItem
+			}
+		}
+		for _, keyword := range chunk {
+			p := position{keyword: keyword, domain: tracker.Domain, source: SourceLabs}
+			if item, ok := found[keyword]; ok && item.RankGroup <= serpDepth {
+				p.position = int32(item.RankGroup)
+				p.url = item.URL
+			}
+			positions = append(positions, p)
+		}
+	}
+	return positions
+}
+
+// refreshVolumes returns the keywords' monthly search volumes, looking them
+// up again when they're over a month old or keywords were added since. A
+// failed lookup is logged and the stored volumes are used.
+func (s *Service) refreshVolumes(ctx context.Context, tracker query.RankTracker, keywords []query.RankTrackerKeyword) (map[string]int32, float64) {
+	volumes := make(map[string]int32, len(keywords))
+	stale := !tracker.VolumesRefreshedAt.Valid || time.Since(tracker.VolumesRefreshedAt.Time) > volumeRefreshInterval
+	for _, k := range keywords {
+		if k.SearchVolume.Valid {
+			volumes[k.Keyword] = k.SearchVolume.Int32
+		}
+		if tracker.VolumesRefreshedAt.Valid && k.CreatedAt.After(tracker.VolumesRefreshedAt.Time) {
+			stale = true
+		}
+	}
+	if !stale || s.volumes == nil {
+		return volumes, 0
+	}
+
+	var cost float64
+	refreshed := make(map[string]int32, len(keywords))
+	for start := 0; start < len(keywords); start += dataforseo.MaxSearchVolumeKeywords {
+		chunk := keywords[start:min(start+dataforseo.MaxSearchVolumeKeywords, len(keywords))]
+		names := make([]string, len(chunk))
+		for i, k := range chunk {
+			names[i] = k.Keyword
+		}
+		items, taskCost, err := s.volumes.GetSearchVolumeWithCost(ctx, dataforseo.KeywordSearchVolumeRequest{
+			Keywords:     names,
+			LocationCode: int(tracker.LocationCode),
+			LanguageCode: tracker.LanguageCode,
+		})
+		if err != nil {
+			slog.Warn("Failed to refresh tracked keyword volumes", "trackerID", tracker.ID, "error", err)
+			return volumes, cost
+		}
+		cost += taskCost
+		for _, item := range items {
+			if item.SearchVolume != nil {
+				refreshed[strings.ToLower(item.Keyword)] = int32(*item.SearchVolume)
+			}
+		}
+	}
+
+	arg := query.SetRankTrackerKeywordVolumesParams{TrackerID: tracker.ID}
+	for _, k := range keywords {
+		volume, ok := refreshed[k.Keyword]
+		if !ok {
+			volume = -1 // no data
+		}
+		arg.Keywords = append(arg.Keywords, k.Keyword)
+		arg.Volumes = append(arg.Volumes, volume)
+	}
+	if err := s.store.SetRankTrackerKeywordVolumes(ctx, arg); err != nil {
+		slog.Error("Failed to save tracked keyword volumes", "trackerID", tracker.ID, "error", err)
+		return volumes, cost
+	}
+	if err := s.store.MarkRankTrackerVolumesRefreshed(ctx, tracker.ID); err != nil {
+		slog.Error("Failed to mark tracked keyword volumes refreshed", "trackerID", tracker.ID, "error", err)
+	}
+	return refreshed, cost
+}
+
+// shareOfVoice estimates each domain's share of the clicks on the keywords
+// whose SERPs were looked up: the sum of each keyword's search volume times
+// the click-through rate of the domain's position, over the keywords' total
+// volume, as a percentage. Keywords filled in from Labs are left out, since
+// the competitors' positions aren't known for them. It's empty when none of
+// the keywords has a search volume.
+func shareOfVoice(domains []string, volumes map[string]int32, positions []position) map[string]float64 {
+	var total float64
+	counted := map[string]bool{}
+	clicks := make(map[string]float64, len(domains))
+	for _, p := range positions {
+		if p.source != SourceSERP {
+			continue
+		}
+		volume := float64(volumes[p.keyword])
+		if !counted[p.keyword] {
+			counted[p.keyword] = true
+			total += volume
+		}
+		clicks[p.domain] += volume * clickThroughRate(p.position)
+	}
+
+	shares := make(map[string]float64, len(domains))
+	if total == 0 {
+		return shares
+	}
+	for _, domain := range domains {
+		shares[domain] = math.Round(clicks[domain]/total*1000) / 10
+	}
+	return shares
+}
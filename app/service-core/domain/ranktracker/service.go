@@ -0,0 +1,134 @@
+package ranktracker
+
+import (
+	"app/pkg"
+	"app/pkg/dataforseo"
+	"app/pkg/httprecord"
+	"app/pkg/jobs"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"service-core/config"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	runInterval           = 24 * time.Hour
+	runTimeout            = 30 * time.Minute
+	volumeRefreshInterval = 30 * 24 * time.Hour
+	dueBatchSize          = 20
+	estimatedTaskCost     = 0.002
+)
+
+type store interface {
+	GetOrgMembershipRole(ctx context.Context, arg query.GetOrgMembershipRoleParams) (string, error)
+	GetOrganisationSeoPlan(ctx context.Context, id uuid.UUID) (query.GetOrganisationSeoPlanRow, error)
+	CountRankTrackerKeywordsByOrg(ctx context.Context, organisationID uuid.UUID) (int64, error)
+	CreateRankTracker(ctx context.Context, arg query.CreateRankTrackerParams) (query.RankTracker, error)
+	ListRankTrackersByOrg(ctx context.Context, organisationID uuid.UUID) ([]query.ListRankTrackersByOrgRow, error)
+	GetRankTracker(ctx context.Context, arg query.GetRankTrackerParams) (query.RankTracker, error)
+	DeleteRankTracker(ctx context.Context, arg query.DeleteRankTrackerParams) (int64, error)
+	AddRankTrackerKeywords(ctx context.Context, arg query.AddRankTrackerKeywordsParams) (int64, error)
+	DeleteRankTrackerKeywords(ctx context.Context, arg query.DeleteRankTrackerKeywordsParams) (int64, error)
+	ListRankTrackerKeywords(ctx context.Context, trackerID uuid.UUID) ([]query.RankTrackerKeyword, error)
+	ListDueRankTrackers(ctx context.Context, limit int32) ([]query.RankTracker, error)
+	ClaimRankTracker(ctx context.Context, arg query.ClaimRankTrackerParams) (int64, error)
+	SetRankTrackerKeywordVolumes(ctx context.Context, arg query.SetRankTrackerKeywordVolumesParams) error
+	MarkRankTrackerVolumesRefreshed(ctx context.Context, id uuid.UUID) error
+	CreateRankTrackerRun(ctx context.Context, arg query.CreateRankTrackerRunParams) (query.RankTrackerRun, error)
+	AddRankPositions(ctx context.Context, arg query.AddRankPositionsParams) error
+	CompleteRankTrackerRun(ctx context.Context, arg query.CompleteRankTrackerRunParams) error
+	FailStaleRankTrackerRuns(ctx context.Context, createdAt time.Time) (int64, error)
+	ListRankTrackerRuns(ctx context.Context, arg query.ListRankTrackerRunsParams) ([]query.RankTrackerRun, error)
+	GetLatestRankTrackerRun(ctx context.Context, trackerID uuid.UUID) (query.RankTrackerRun, error)
+	GetRankTrackerRunBefore(ctx context.Context, arg query.GetRankTrackerRunBeforeParams) (query.RankTrackerRun, error)
+	ListRankPositionsByRun(ctx context.Context, arg query.ListRankPositionsByRunParams) ([]query.RankPosition, error)
+	ListRankPositionHistory(ctx context.Context, arg query.ListRankPositionHistoryParams) ([]query.RankPosition, error)
+}
+
+type serpClient interface {
+	PostSERPOrganicTasks(ctx context.Context, reqs []dataforseo.SERPOrganicRequest) ([]dataforseo.QueuedTask, error)
+	GetSERPOrganicTask(ctx context.Context, id string) (*dataforseo.SERPOrganicResult, error)
+	WaitForTask(ctx context.Context, id string, fetch func(ctx context.Context, id string) error) error
+	CostReport() dataforseo.CostReport
+}
+
+type labsClient interface {
+	GetDomainRankingKeywords(ctx context.Context, target string, locationCode int, languageCode string, opts dataforseo.LabsOptions) ([]dataforseo.DomainKeyword, int, error)
+}
+
+type volumeClient interface {
+	GetSearchVolumeWithCost(ctx context.Context, req dataforseo.KeywordSearchVolumeRequest) ([]dataforseo.KeywordData, float64, error)
+}
+
+type Service struct {
+	store   store
+	serp    serpClient   // nil when DataForSEO isn't configured
+	labs    labsClient   // nil when DataForSEO isn't configured
+	volumes volumeClient // nil when DataForSEO isn't configured
+	jobs    *jobs.Queue
+}
+
+func NewService(cfg *config.Config, store store, queue *jobs.Queue) *Service {
+	s := &Service{store: store, jobs: queue}
+	s.registerJobs(queue)
+	if cfg.DataForSEOLogin == "" {
+		return s
+	}
+	opts := []dataforseo.Option{dataforseo.WithTransport(httprecord.Transport("dataforseo", nil))}
+	if cfg.DataForSEOMonthlyBudget != "" {
+		budget, err := strconv.ParseFloat(cfg.DataForSEOMonthlyBudget, 64)
+		if err != nil {
+			slog.Warn("Ignoring DataForSEO monthly budget", "error", err)
+		} else {
+			opts = append(opts, dataforseo.WithBudget(budget))
+		}
+	}
+	client := dataforseo.NewClient(cfg.DataForSEOLogin, cfg.DataForSEOPassword, opts...)
+	s.serp = client
+	s.labs = client
+	s.volumes = client
+	return s
+}
+
+// tierKeywordLimit is how many keywords each tier can track across all of
+// an organisation's trackers.
+var tierKeywordLimit = map[string]int64{
+	"free":       10,
+	"starter":    100,
+	"growth":     500,
+	"enterprise": 2000,
+}
+
+// keywordLimit returns how many keywords an organisation can track. Active
+// freemium organisations get enterprise limits, as elsewhere.
+func keywordLimit(plan query.GetOrganisationSeoPlanRow) int64 {
+	tier := plan.SubscriptionTier
+	if plan.IsFreemium && (!plan.FreemiumExpiresAt.Valid || time.Now().Before(plan.FreemiumExpiresAt.Time)) {
+		tier = "enterprise"
+	}
+	return TierKeywordLimit(tier)
+}
+
+// TierKeywordLimit returns how many keywords a tier can track, 0 for unknown
+// tiers.
+func TierKeywordLimit(tier string) int64 {
+	return tierKeywordLimit[tier]
+}
+
+func (s *Service) orgKeywordLimit(ctx context.Context, organisationID uuid.UUID) (int64, error) {
+	plan, err := s.store.GetOrganisationSeoPlan(ctx, organisationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, pkg.NotFoundError{Message: "Organisation not found"}
+	}
+	if err != nil {
+		return 0, pkg.InternalError{Message: "Error loading organisation plan", Err: err}
+	}
+	return keywordLimit(plan), nil
+}
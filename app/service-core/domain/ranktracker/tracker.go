@@ -0,0 +1,340 @@
+package ranktracker
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"service-core/domain/membership"
+	"service-core/domain/seo"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxCompetitors is the most competitor domains a tracker follows.
+	MaxCompetitors = 5
+)
+
+// CreateTracker registers a domain and its keywords for daily tracking in
+// one market. The first run is due straight away. Only organisation owners
+// and admins can create trackers, and the keywords count towards the
+// organisation's tier limit.
+func (s *Service) CreateTracker(ctx context.Context, userID uuid.UUID, req TrackerRequest) (*TrackerDetail, error) {
+	if s.serp == nil {
+		return nil, pkg.BadRequestError{Message: "Rank tracking is not configured"}
+	}
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+
+	domain, err := seo.NormaliseDomain(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	competitors, err := normaliseCompetitors(domain, req.Competitors)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := trackedKeywords(req.Keywords)
+	if err != nil {
+		return nil, err
+	}
+	if len(keywords) == 0 {
+		return nil, pkg.BadRequestError{Message: "At least one keyword is required"}
+	}
+	if req.LocationCode <= 0 {
+		return nil, pkg.BadRequestError{Message: "locationCode is required"}
+	}
+	if req.LanguageCode == "" {
+		return nil, pkg.BadRequestError{Message: "languageCode is required"}
+	}
+	device := req.Device
+	if device == "" {
+		device = DeviceDesktop
+	}
+	if device != DeviceDesktop && device != DeviceMobile {
+		return nil, pkg.BadRequestError{Message: "device must be desktop or mobile"}
+	}
+	if err := s.checkKeywordLimit(ctx, req.OrganisationID, len(keywords)); err != nil {
+		return nil, err
+	}
+
+	row, err := s.store.CreateRankTracker(ctx, query.CreateRankTrackerParams{
+		OrganisationID: req.OrganisationID,
+		Domain:         domain,
+		LocationCode:   int32(req.LocationCode),
+		LanguageCode:   req.LanguageCode,
+		Device:         device,
+		Competitors:    competitors,
+		CreatedBy:      uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.BadRequestError{Message: "This domain is already tracked for that location, language and device"}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error creating tracker", Err: err}
+	}
+	if _, err := s.store.AddRankTrackerKeywords(ctx, query.AddRankTrackerKeywordsParams{TrackerID: row.ID, Keywords: keywords}); err != nil {
+		return nil, pkg.InternalError{Message: "Error adding keywords", Err: err}
+	}
+
+	return s.loadTrackerDetail(ctx, row)
+}
+
+// ListTrackers returns an organisation's trackers, with the latest run of
+// each, to any of its members.
+func (s *Service) ListTrackers(ctx context.Context, userID, organisationID uuid.UUID) ([]Tracker, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.store.ListRankTrackersByOrg(ctx, organisationID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing trackers", Err: err}
+	}
+	trackers := make([]Tracker, 0, len(rows))
+	for _, row := range rows {
+		tracker := newTracker(query.RankTracker{
+			ID:                 row.ID,
+			OrganisationID:     row.OrganisationID,
+			Domain:             row.Domain,
+			LocationCode:       row.LocationCode,
+			LanguageCode:       row.LanguageCode,
+			Device:             row.Device,
+			Competitors:        row.Competitors,
+			CreatedBy:          row.CreatedBy,
+			CreatedAt:          row.CreatedAt,
+			LastRunAt:          row.LastRunAt,
+			NextRunAt:          row.NextRunAt,
+			VolumesRefreshedAt: row.VolumesRefreshedAt,
+		}, row.KeywordCount)
+		if tracker.LatestRun, err = s.latestRun(ctx, row.ID); err != nil {
+			return nil, err
+		}
+		trackers = append(trackers, tracker)
+	}
+	return trackers, nil
+}
+
+// GetTracker returns a tracker with its keywords and their latest positions
+// to any member of its organisation.
+func (s *Service) GetTracker(ctx context.Context, userID, organisationID, trackerID uuid.UUID) (*TrackerDetail, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+
+	row, err := s.getTracker(ctx, organisationID, trackerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadTrackerDetail(ctx, row)
+}
+
+// DeleteTracker removes a tracker and its history. Only organisation owners
+// and admins can delete trackers.
+func (s *Service) DeleteTracker(ctx context.Context, userID, organisationID, trackerID uuid.UUID) error {
+	if err := membership.RequireAdmin(ctx, s.store, userID, organisationID); err != nil {
+		return err
+	}
+
+	deleted, err := s.store.DeleteRankTracker(ctx, query.DeleteRankTrackerParams{ID: trackerID, OrganisationID: organisationID})
+	if err != nil {
+		return pkg.InternalError{Message: "Error deleting tracker", Err: err}
+	}
+	if deleted == 0 {
+		return pkg.NotFoundError{Message: "Tracker not found"}
+	}
+	return nil
+}
+
+// AddKeywords adds keywords to a tracker; ones it already tracks are
+// ignored. They're looked up from the next daily run. Only organisation
+// owners and admins can add keywords, within the tier limit.
+func (s *Service) AddKeywords(ctx context.Context, userID, trackerID uuid.UUID, req KeywordsRequest) (*TrackerDetail, error) {
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+
+	row, err := s.getTracker(ctx, req.OrganisationID, trackerID)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := trackedKeywords(req.Keywords)
+	if err != nil {
+		return nil, err
+	}
+	if len(keywords) == 0 {
+		return nil, pkg.BadRequestError{Message: "At least one keyword is required"}
+	}
+	existing, err := s.store.ListRankTrackerKeywords(ctx, trackerID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading keywords", Err: err}
+	}
+	tracked := make(map[string]bool, len(existing))
+	for _, k := range existing {
+		tracked[k.Keyword] = true
+	}
+	added := keywords[:0]
+	for _, k := range keywords {
+		if !tracked[k] {
+			added = append(added, k)
+		}
+	}
+
+	if len(added) > 0 {
+		if err := s.checkKeywordLimit(ctx, req.OrganisationID, len(added)); err != nil {
+			return nil, err
+		}
+		if _, err := s.store.AddRankTrackerKeywords(ctx, query.AddRankTrackerKeywordsParams{TrackerID: trackerID, Keywords: added}); err != nil {
+			return nil, pkg.InternalError{Message: "Error adding keywords", Err: err}
+		}
+	}
+	return s.loadTrackerDetail(ctx, row)
+}
+
+// RemoveKeywords stops tracking keywords. Their past positions are kept in
+// the tracker's history. Only organisation owners and admins can remove
+// keywords.
+func (s *Service) RemoveKeywords(ctx context.Context, userID, trackerID uuid.UUID, req KeywordsRequest) (*TrackerDetail, error) {
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+
+	row, err := s.getTracker(ctx, req.OrganisationID, trackerID)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := trackedKeywords(req.Keywords)
+	if err != nil {
+		return nil, err
+	}
+	if len(keywords) == 0 {
+		return nil, pkg.BadRequestError{Message: "At least one keyword is required"}
+	}
+	if _, err := s.store.DeleteRankTrackerKeywords(ctx, query.DeleteRankTrackerKeywordsParams{TrackerID: trackerID, Keywords: keywords}); err != nil {
+		return nil, pkg.InternalError{Message: "Error removing keywords", Err: err}
+	}
+	return s.loadTrackerDetail(ctx, row)
+}
+
+// checkKeywordLimit refuses adding keywords that would take the
+// organisation past its tier's limit across all its trackers.
+func (s *Service) checkKeywordLimit(ctx context.Context, organisationID uuid.UUID, adding int) error {
+	limit, err := s.orgKeywordLimit(ctx, organisationID)
+	if err != nil {
+		return err
+	}
+	tracked, err := s.store.CountRankTrackerKeywordsByOrg(ctx, organisationID)
+	if err != nil {
+		return pkg.InternalError{Message: "Error counting tracked keywords", Err: err}
+	}
+	if tracked+int64(adding) > limit {
+		return pkg.BadRequestError{Message: fmt.Sprintf("Your plan can track up to %d keywords and %d are tracked already", limit, tracked)}
+	}
+	return nil
+}
+
+func (s *Service) getTracker(ctx context.Context, organisationID, trackerID uuid.UUID) (query.RankTracker, error) {
+	row, err := s.store.GetRankTracker(ctx, query.GetRankTrackerParams{ID: trackerID, OrganisationID: organisationID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return query.RankTracker{}, pkg.NotFoundError{Message: "Tracker not found"}
+	}
+	if err != nil {
+		return query.RankTracker{}, pkg.InternalError{Message: "Error loading tracker", Err: err}
+	}
+	return row, nil
+}
+
+// latestRun returns the tracker's latest finished run, or nil before the
+// first one.
+func (s *Service) latestRun(ctx context.Context, trackerID uuid.UUID) (*Run, error) {
+	row, err := s.store.GetLatestRankTrackerRun(ctx, trackerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading latest run", Err: err}
+	}
+	run, err := newRun(row)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error reading run", Err: err}
+	}
+	return &run, nil
+}
+
+func (s *Service) loadTrackerDetail(ctx context.Context, row query.RankTracker) (*TrackerDetail, error) {
+	keywords, err := s.store.ListRankTrackerKeywords(ctx, row.ID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading keywords", Err: err}
+	}
+	detail := &TrackerDetail{
+		Tracker:  newTracker(row, int64(len(keywords))),
+		Keywords: make([]TrackedKeyword, len(keywords)),
+	}
+	if detail.LatestRun, err = s.latestRun(ctx, row.ID); err != nil {
+		return nil, err
+	}
+
+	positions := map[string]query.RankPosition{}
+	if detail.LatestRun != nil {
+		rows, err := s.store.ListRankPositionsByRun(ctx, query.ListRankPositionsByRunParams{RunID: detail.LatestRun.ID, Domain: row.Domain})
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error loading positions", Err: err}
+		}
+		for _, p := range rows {
+			positions[p.Keyword] = p
+		}
+	}
+	for i, k := range keywords {
+		detail.Keywords[i] = TrackedKeyword{Keyword: k.Keyword}
+		if k.SearchVolume.Valid {
+			detail.Keywords[i].SearchVolume = &k.SearchVolume.Int32
+		}
+		if p, ok := positions[k.Keyword]; ok {
+			detail.Keywords[i].Position = newPositionPoint(p).Position
+			detail.Keywords[i].URL = p.Url
+		}
+	}
+	return detail, nil
+}
+
+// normaliseCompetitors normalises the competitor domains, dropping
+// duplicates and the tracked domain itself.
+func normaliseCompetitors(domain string, raw []string) ([]string, error) {
+	competitors := make([]string, 0, len(raw))
+	for _, c := range raw {
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+		c, err := seo.NormaliseDomain(c)
+		if err != nil {
+			return nil, pkg.BadRequestError{Message: "competitors must be domain names such as example.com"}
+		}
+		if c != domain && !slices.Contains(competitors, c) {
+			competitors = append(competitors, c)
+		}
+	}
+	if len(competitors) > MaxCompetitors {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("A tracker can follow at most %d competitors", MaxCompetitors)}
+	}
+	return competitors, nil
+}
+
+// trackedKeywords normalises the keywords and lowercases them, since
+// Google's results don't depend on case and history is kept per keyword.
+func trackedKeywords(raw []string) ([]string, error) {
+	keywords, err := seo.NormaliseKeywords(raw)
+	if err != nil {
+		return nil, err
+	}
+	for i, k := range keywords {
+		keywords[i] = strings.ToLower(k)
+	}
+	return keywords, nil
+}
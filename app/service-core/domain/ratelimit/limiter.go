@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// windowLength is the length of a rate limit window.
+const windowLength = time.Minute
+
+// tierRequestsPerMinute is each tier's API allowance. Tiers with api_access in
+// the client's TIER_DEFINITIONS get headroom for SDK integrations.
+var tierRequestsPerMinute = map[string]int{
+	"free":       300,
+	"starter":    600,
+	"growth":     1500,
+	"enterprise": 6000,
+}
+
+// PlanForUser returns the plan for an authenticated user: their default
+// organisation's tier, or the free tier if they have none. Active freemium
+// organisations get enterprise limits, as elsewhere.
+func (s *Service) PlanForUser(ctx context.Context, userID uuid.UUID) (Plan, error) {
+	now := s.now()
+	s.mu.Lock()
+	cached, ok := s.plans[userID]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.plan, nil
+	}
+
+	var plan Plan
+	row, err := s.store.GetUserRatePlan(ctx, userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		plan = newPlan("free", "user:"+userID.String())
+	case err != nil:
+		return Plan{}, fmt.Errorf("loading rate plan: %w", err)
+	default:
+		tier := row.SubscriptionTier
+		if row.IsFreemium && (!row.FreemiumExpiresAt.Valid || now.Before(row.FreemiumExpiresAt.Time)) {
+			tier = "enterprise"
+		}
+		plan = newPlan(tier, "org:"+row.OrganisationID.String())
+		plan.OrganisationID = &row.OrganisationID
+	}
+
+	s.mu.Lock()
+	s.plans[userID] = cachedPlan{plan: plan, expires: now.Add(planCacheTTL)}
+	s.mu.Unlock()
+	return plan, nil
+}
+
+// TierRequestsPerMinute returns a tier's API allowance. Unknown tiers get the
+// free tier's.
+func TierRequestsPerMinute(tier string) int {
+	return newPlan(tier, "").RequestsPerMinute
+}
+
+func newPlan(tier, key string) Plan {
+	limit, ok := tierRequestsPerMinute[tier]
+	if !ok {
+		tier = "free"
+		limit = tierRequestsPerMinute[tier]
+	}
+	return Plan{Tier: tier, RequestsPerMinute: limit, key: key}
+}
+
+// Allow counts a request against the plan. ok is false, and the request is not
+// counted, when the window's allowance is used up.
+func (s *Service) Allow(plan Plan) (status Status, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+	w := s.current(plan.key, now)
+	if w.count >= plan.RequestsPerMinute {
+		return statusOf(plan, w), false
+	}
+	w.count++
+	return statusOf(plan, w), true
+}
+
+// Peek returns the plan's status without counting a request.
+func (s *Service) Peek(plan Plan) Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return statusOf(plan, s.current(plan.key, s.now()))
+}
+
+// Limits returns the plan and its current status.
+func (s *Service) Limits(plan Plan) Limits {
+	return Limits{Plan: plan, RateLimit: s.Peek(plan)}
+}
+
+// current returns the key's window, starting a new one if the last has ended.
+// Callers must hold s.mu.
+func (s *Service) current(key string, now time.Time) *window {
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.start.Add(windowLength)) {
+		w = &window{start: now}
+		s.windows[key] = w
+	}
+	return w
+}
+
+// prune drops ended windows and expired plans once a minute so the maps
+// don't grow without bound. Callers must hold s.mu.
+func (s *Service) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < windowLength {
+		return
+	}
+	s.lastPrune = now
+	for key, w := range s.windows {
+		if !now.Before(w.start.Add(windowLength)) {
+			delete(s.windows, key)
+		}
+	}
+	for userID, cached := range s.plans {
+		if !now.Before(cached.expires) {
+			delete(s.plans, userID)
+		}
+	}
+}
+
+func statusOf(plan Plan, w *window) Status {
+	return Status{
+		Limit:     plan.RequestsPerMinute,
+		Remaining: max(plan.RequestsPerMinute-w.count, 0),
+		Reset:     w.start.Add(windowLength),
+	}
+}
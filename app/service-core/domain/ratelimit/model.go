@@ -0,0 +1,31 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plan is the request allowance a caller is held to.
+type Plan struct {
+	// OrganisationID is the organisation whose allowance the caller shares.
+	// Nil for users without a default organisation, who are limited on their own.
+	OrganisationID    *uuid.UUID `json:"organisationId"`
+	Tier              string     `json:"tier"`
+	RequestsPerMinute int        `json:"requestsPerMinute"`
+
+	key string
+}
+
+// Status is where a caller stands in the current window.
+type Status struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// Limits is the response for GET /api/v1/limits.
+type Limits struct {
+	Plan      Plan   `json:"plan"`
+	RateLimit Status `json:"rateLimit"`
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// planCacheTTL is how long a user's plan is reused before it is looked up
+// again, so a tier change takes effect within a minute.
+const planCacheTTL = time.Minute
+
+// store defines the database interface for rate plans
+type store interface {
+	GetUserRatePlan(ctx context.Context, id uuid.UUID) (query.GetUserRatePlanRow, error)
+}
+
+// Service counts API requests per organisation in fixed one-minute windows.
+// Counters live in memory, so each replica enforces the limit on its own share
+// of the traffic.
+type Service struct {
+	store store
+	now   func() time.Time
+
+	mu        sync.Mutex
+	windows   map[string]*window
+	plans     map[uuid.UUID]cachedPlan
+	lastPrune time.Time
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+type cachedPlan struct {
+	plan    Plan
+	expires time.Time
+}
+
+// NewService creates a new rate limit service
+func NewService(store store) *Service {
+	return &Service{
+		store:   store,
+		now:     time.Now,
+		windows: make(map[string]*window),
+		plans:   make(map[uuid.UUID]cachedPlan),
+	}
+}
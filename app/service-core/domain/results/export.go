@@ -0,0 +1,170 @@
+package results
+
+import (
+	"app/pkg"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// exportPageSize is how many learners each query of an export reads
+const exportPageSize = 500
+
+var contentResultsCSVHeader = []string{
+	"learner_id", "user_id", "email", "attempts", "finished", "passed",
+	"score_percent", "raw_score", "max_score", "first_activity_at", "last_activity_at",
+}
+
+var gradebookCSVHeader = []string{
+	"learner_id", "user_id", "email", "enrolment_status", "enrolled_at", "enrolment_completed_at",
+	"item_id", "content_id", "item_title", "attempted", "attempts", "finished", "passed",
+	"score_percent", "raw_score", "max_score", "first_activity_at", "last_activity_at",
+}
+
+// ExportContentResultsCSV exports every learner's result on a piece of
+// content as CSV, one row per learner.
+func (s *Service) ExportContentResultsCSV(ctx context.Context, orgID, contentID uuid.UUID) ([]byte, string, error) {
+	content, err := s.store.GetH5PContent(ctx, query.GetH5PContentParams{ID: contentID, OrgID: orgID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", pkg.NotFoundError{Message: "Content not found", Err: err}
+	}
+	if err != nil {
+		return nil, "", pkg.InternalError{Message: "Error loading content", Err: err}
+	}
+
+	records := [][]string{contentResultsCSVHeader}
+	for offset := int32(0); ; offset += exportPageSize {
+		page, err := s.contentResults(ctx, orgID, contentID, exportPageSize, offset)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, lr := range page {
+			records = append(records, append(learnerColumns(lr.Learner),
+				strconv.FormatInt(lr.Attempts, 10),
+				strconv.FormatBool(lr.Finished),
+				strconv.FormatBool(lr.Passed),
+				percent(lr.Score),
+				number(lr.RawScore),
+				number(lr.MaxScore),
+				timestamp(lr.FirstActivityAt),
+				timestamp(lr.LastActivityAt),
+			))
+		}
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+	data, err := writeCSV(records)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, exportFilename("results", content.Slug), nil
+}
+
+// ExportCourseGradebookCSV exports a course's gradebook as CSV, one row per
+// learner per item, so it can be filtered or pivoted in a spreadsheet.
+func (s *Service) ExportCourseGradebookCSV(ctx context.Context, orgID, courseID uuid.UUID) ([]byte, string, error) {
+	course, err := s.store.GetGradebookCourse(ctx, query.GetGradebookCourseParams{ID: courseID, OrgID: orgID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", pkg.NotFoundError{Message: "Course not found", Err: err}
+	}
+	if err != nil {
+		return nil, "", pkg.InternalError{Message: "Error loading course", Err: err}
+	}
+	items, err := s.gradebookItems(ctx, courseID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	records := [][]string{gradebookCSVHeader}
+	for offset := int32(0); ; offset += exportPageSize {
+		page, err := s.gradebookRows(ctx, orgID, courseID, items, exportPageSize, offset)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, row := range page {
+			learner := append(learnerColumns(row.Learner),
+				row.Status,
+				row.EnrolledAt.UTC().Format(time.RFC3339),
+				timestamp(row.CompletedAt),
+			)
+			for i, item := range items {
+				result := row.Results[i]
+				// Cap the learner columns so each record appends to its own copy
+				records = append(records, append(learner[:len(learner):len(learner)],
+					item.ItemID.String(),
+					item.ContentID.String(),
+					item.Title,
+					strconv.FormatBool(result.Attempted),
+					strconv.FormatInt(result.Attempts, 10),
+					strconv.FormatBool(result.Finished),
+					strconv.FormatBool(result.Passed),
+					percent(result.Score),
+					number(result.RawScore),
+					number(result.MaxScore),
+					timestamp(result.FirstActivityAt),
+					timestamp(result.LastActivityAt),
+				))
+			}
+		}
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+	data, err := writeCSV(records)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, exportFilename("gradebook", course.Slug), nil
+}
+
+func writeCSV(records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := csv.NewWriter(&buf).WriteAll(records); err != nil {
+		return nil, pkg.InternalError{Message: "Error writing results", Err: err}
+	}
+	return buf.Bytes(), nil
+}
+
+func exportFilename(kind, slug string) string {
+	return fmt.Sprintf("%s-%s-%s.csv", kind, slug, time.Now().UTC().Format("2006-01-02"))
+}
+
+func learnerColumns(l Learner) []string {
+	userID := ""
+	if l.UserID != nil {
+		userID = l.UserID.String()
+	}
+	return []string{l.LearnerID.String(), userID, l.Email}
+}
+
+// percent formats a scaled score as a percentage, e.g. 0.875 as 87.5
+func percent(scaled *float64) string {
+	if scaled == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*scaled*100, 'f', 1, 64)
+}
+
+func number(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func timestamp(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,191 @@
+package results
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// ContentResults lists a page of learners' results on a piece of content
+func (s *Service) ContentResults(ctx context.Context, orgID, contentID uuid.UUID, limit, offset int32) (*ContentResults, error) {
+	content, err := s.store.GetH5PContent(ctx, query.GetH5PContentParams{ID: contentID, OrgID: orgID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Content not found", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading content", Err: err}
+	}
+	results, err := s.contentResults(ctx, orgID, contentID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &ContentResults{ContentID: contentID, Title: content.Title, Learners: results}, nil
+}
+
+func (s *Service) contentResults(ctx context.Context, orgID, contentID uuid.UUID, limit, offset int32) ([]LearnerResult, error) {
+	rows, err := s.store.ListContentResults(ctx, query.ListContentResultsParams{
+		OrgID:     orgID,
+		ContentID: uuid.NullUUID{UUID: contentID, Valid: true},
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing content results", Err: err}
+	}
+	results := make([]LearnerResult, len(rows))
+	for i, row := range rows {
+		results[i] = LearnerResult{
+			Learner: newLearner(row.LearnerID, row.UserID, row.Email),
+			Result: newResult(row.Attempts, row.Finished, row.Passed,
+				scorePtr(row.Scored, row.BestScore), scorePtr(row.RawScored, row.BestScoreRaw), scorePtr(row.RawScored, row.BestScoreMax),
+				row.FirstActivityAt, row.LastActivityAt),
+		}
+	}
+	return results, nil
+}
+
+// CourseGradebook builds the gradebook for a page of a course's learners
+func (s *Service) CourseGradebook(ctx context.Context, orgID, courseID uuid.UUID, limit, offset int32) (*Gradebook, error) {
+	course, err := s.store.GetGradebookCourse(ctx, query.GetGradebookCourseParams{ID: courseID, OrgID: orgID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Course not found", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading course", Err: err}
+	}
+	items, err := s.gradebookItems(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.store.CountCourseGradebookLearners(ctx, query.CountCourseGradebookLearnersParams{
+		OrgID:    orgID,
+		CourseID: courseID,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting course learners", Err: err}
+	}
+	rows, err := s.gradebookRows(ctx, orgID, courseID, items, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &Gradebook{
+		CourseID:      courseID,
+		Title:         course.Title,
+		Items:         items,
+		TotalLearners: total,
+		Rows:          rows,
+	}, nil
+}
+
+func (s *Service) gradebookItems(ctx context.Context, courseID uuid.UUID) ([]GradebookItem, error) {
+	rows, err := s.store.ListCourseGradebookItems(ctx, courseID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing course items", Err: err}
+	}
+	items := make([]GradebookItem, len(rows))
+	for i, row := range rows {
+		items[i] = GradebookItem{ItemID: row.ID, ContentID: row.ContentID, Title: row.Title}
+	}
+	return items, nil
+}
+
+// gradebookRows lists a page of the course's learners with their results on
+// each item. Learners without statements about an item get an empty result.
+func (s *Service) gradebookRows(ctx context.Context, orgID, courseID uuid.UUID, items []GradebookItem, limit, offset int32) ([]GradebookRow, error) {
+	learners, err := s.store.ListCourseGradebookLearners(ctx, query.ListCourseGradebookLearnersParams{
+		OrgID:    orgID,
+		CourseID: courseID,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing course learners", Err: err}
+	}
+	rows := make([]GradebookRow, len(learners))
+	if len(learners) == 0 {
+		return rows, nil
+	}
+
+	learnerIDs := make([]uuid.UUID, len(learners))
+	rowIndex := make(map[uuid.UUID]int, len(learners))
+	for i, l := range learners {
+		learnerIDs[i] = l.LearnerID
+		rowIndex[l.LearnerID] = i
+		rows[i] = GradebookRow{
+			Learner:    newLearner(l.LearnerID, l.UserID, l.Email),
+			Status:     l.Status,
+			EnrolledAt: l.EnrolledAt,
+			Results:    make([]Result, len(items)),
+		}
+		if l.CompletedAt.Valid {
+			rows[i].CompletedAt = &l.CompletedAt.Time
+		}
+	}
+	if len(items) == 0 {
+		return rows, nil
+	}
+
+	contentIDs := make([]uuid.UUID, len(items))
+	columns := make(map[uuid.UUID][]int, len(items)) // content can appear in more than one item
+	for i, item := range items {
+		contentIDs[i] = item.ContentID
+		columns[item.ContentID] = append(columns[item.ContentID], i)
+	}
+	results, err := s.store.ListCourseGradebookResults(ctx, query.ListCourseGradebookResultsParams{
+		OrgID:      orgID,
+		ContentIds: contentIDs,
+		LearnerIds: learnerIDs,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing course results", Err: err}
+	}
+	for _, r := range results {
+		row := &rows[rowIndex[r.LearnerID]]
+		result := newResult(r.Attempts, r.Finished, r.Passed,
+			scorePtr(r.Scored, r.BestScore), scorePtr(r.RawScored, r.BestScoreRaw), scorePtr(r.RawScored, r.BestScoreMax),
+			r.FirstActivityAt, r.LastActivityAt)
+		for _, col := range columns[r.ContentID] {
+			row.Results[col] = result
+			if result.Finished {
+				row.Finished++
+			}
+		}
+	}
+	return rows, nil
+}
+
+func newLearner(learnerID uuid.UUID, userID uuid.NullUUID, email sql.NullString) Learner {
+	learner := Learner{LearnerID: learnerID, Email: email.String}
+	if userID.Valid {
+		learner.UserID = &userID.UUID
+	}
+	return learner
+}
+
+func newResult(attempts int64, finished, passed bool, score, rawScore, maxScore *float64, first, last time.Time) Result {
+	return Result{
+		Attempted:       true,
+		Attempts:        attempts,
+		Finished:        finished,
+		Passed:          passed,
+		Score:           score,
+		RawScore:        rawScore,
+		MaxScore:        maxScore,
+		FirstActivityAt: &first,
+		LastActivityAt:  &last,
+	}
+}
+
+// scorePtr returns a best score, or nil when none of the statements were scored
+func scorePtr(scored int64, score float64) *float64 {
+	if scored == 0 {
+		return nil
+	}
+	return &score
+}
@@ -0,0 +1,74 @@
+package results
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Result is how far a learner got with one piece of content. A learner
+// attempted the content once they posted any statement about it, finished it
+// once a statement reported completion, and passed it once one reported
+// success. Scores are from the learner's best scored statement.
+type Result struct {
+	Attempted       bool       `json:"attempted"`
+	Attempts        int64      `json:"attempts"`
+	Finished        bool       `json:"finished"`
+	Passed          bool       `json:"passed"`
+	Score           *float64   `json:"score"` // scaled, 0-1
+	RawScore        *float64   `json:"rawScore"`
+	MaxScore        *float64   `json:"maxScore"`
+	FirstActivityAt *time.Time `json:"firstActivityAt"`
+	LastActivityAt  *time.Time `json:"lastActivityAt"`
+}
+
+// Learner identifies a learner in a gradebook. Anonymized learners have no
+// user ID or email.
+type Learner struct {
+	LearnerID uuid.UUID  `json:"learnerId"`
+	UserID    *uuid.UUID `json:"userId"`
+	Email     string     `json:"email,omitempty"`
+}
+
+// LearnerResult is one learner's result on a piece of content
+type LearnerResult struct {
+	Learner
+	Result
+}
+
+// ContentResults is the gradebook for one piece of content: every learner
+// who posted statements about it, ordered by email.
+type ContentResults struct {
+	ContentID uuid.UUID       `json:"contentId"`
+	Title     string          `json:"title"`
+	Learners  []LearnerResult `json:"learners"`
+}
+
+// GradebookItem is a column of a course gradebook: an H5P item of the course
+type GradebookItem struct {
+	ItemID    uuid.UUID `json:"itemId"`
+	ContentID uuid.UUID `json:"contentId"`
+	Title     string    `json:"title"`
+}
+
+// GradebookRow is a learner enrolled in the course with their result on each
+// of the gradebook's items, in the same order.
+type GradebookRow struct {
+	Learner
+	Status      string     `json:"status"`
+	EnrolledAt  time.Time  `json:"enrolledAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+	Finished    int        `json:"finished"` // items finished
+	Results     []Result   `json:"results"`
+}
+
+// Gradebook is the gradebook for a course's cohort: its enrolled learners
+// (withdrawn learners excluded) against its H5P items. Rows are paged;
+// TotalLearners counts the whole cohort.
+type Gradebook struct {
+	CourseID      uuid.UUID       `json:"courseId"`
+	Title         string          `json:"title"`
+	Items         []GradebookItem `json:"items"`
+	TotalLearners int64           `json:"totalLearners"`
+	Rows          []GradebookRow  `json:"rows"`
+}
@@ -0,0 +1,31 @@
+package results
+
+import (
+	"context"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// store defines the database interface for results
+type store interface {
+	GetH5PContent(ctx context.Context, arg query.GetH5PContentParams) (query.H5pContent, error)
+	GetGradebookCourse(ctx context.Context, arg query.GetGradebookCourseParams) (query.GetGradebookCourseRow, error)
+	ListContentResults(ctx context.Context, arg query.ListContentResultsParams) ([]query.ListContentResultsRow, error)
+	ListCourseGradebookItems(ctx context.Context, courseID uuid.UUID) ([]query.ListCourseGradebookItemsRow, error)
+	CountCourseGradebookLearners(ctx context.Context, arg query.CountCourseGradebookLearnersParams) (int64, error)
+	ListCourseGradebookLearners(ctx context.Context, arg query.ListCourseGradebookLearnersParams) ([]query.ListCourseGradebookLearnersRow, error)
+	ListCourseGradebookResults(ctx context.Context, arg query.ListCourseGradebookResultsParams) ([]query.ListCourseGradebookResultsRow, error)
+}
+
+// Service builds gradebooks from the xAPI statements learners post: how
+// far each learner got with each piece of content, and how well they scored.
+type Service struct {
+	store store
+}
+
+// NewService creates a new results service
+func NewService(store store) *Service {
+	return &Service{store: store}
+}
@@ -0,0 +1,172 @@
+package retention
+
+import (
+	"app/pkg"
+	"app/pkg/jobs"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+const (
+	// enforceInterval is how often each policy is enforced. The task runs
+	// daily; the slack keeps a run that started a little late from skipping a
+	// day.
+	enforceInterval = 20 * time.Hour
+	// maxEnforcementsQueued caps the policies one task run queues
+	maxEnforcementsQueued = 1000
+	// purgeBatchSize and xapiBatchSize cap the rows one DELETE removes, so a
+	// large organisation doesn't hold long locks
+	purgeBatchSize = 200
+	xapiBatchSize  = 5000
+	enforceTimeout = 30 * time.Minute
+)
+
+// jobEnforce enforces one organisation's policy. Its payload is the
+// organisation ID.
+const jobEnforce = "retention.enforce"
+
+func (s *Service) registerJobs(queue *jobs.Queue) {
+	// Enforcing is idempotent, so an interrupted run just starts over
+	queue.Register(jobEnforce, s.runEnforceJob, jobs.MaxAttempts(3), jobs.Timeout(enforceTimeout))
+}
+
+func (s *Service) runEnforceJob(ctx context.Context, payload json.RawMessage) error {
+	var organisationID uuid.UUID
+	if err := json.Unmarshal(payload, &organisationID); err != nil {
+		return fmt.Errorf("decoding retention job: %w", err)
+	}
+	_, err := s.Enforce(ctx, organisationID)
+	return err
+}
+
+// Enforcement counts what enforcing a policy archived and removed
+type Enforcement struct {
+	DraftsArchived        int64 `json:"draftsArchived"`
+	DeletedPurged         int64 `json:"deletedPurged"`
+	XAPIStatementsDeleted int64 `json:"xapiStatementsDeleted"`
+}
+
+// QueueDue queues enforcement of every policy not enforced within the last
+// day, and returns how many were queued.
+func (s *Service) QueueDue(ctx context.Context) (int, error) {
+	due, err := s.store.ListDueRetentionPolicies(ctx, query.ListDueRetentionPoliciesParams{
+		EvaluatedAt: sql.NullTime{Time: time.Now().Add(-enforceInterval), Valid: true},
+		Limit:       maxEnforcementsQueued,
+	})
+	if err != nil {
+		return 0, pkg.InternalError{Message: "Error listing due retention policies", Err: err}
+	}
+	queued := 0
+	for _, p := range due {
+		if _, err := s.jobs.Enqueue(ctx, jobEnforce, p.OrganisationID); err != nil {
+			slog.Error("Error queueing retention enforcement", "error", err, "organisation_id", p.OrganisationID)
+			continue
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// Enforce applies the organisation's policy now: it archives stale drafts,
+// purges content deleted long enough ago together with its files, and
+// deletes expired xAPI statements. What it removed is recorded in the
+// activity log.
+func (s *Service) Enforce(ctx context.Context, organisationID uuid.UUID) (*Enforcement, error) {
+	p, err := s.store.GetRetentionPolicy(ctx, organisationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &Enforcement{}, nil // the organisation is gone
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading retention policy: %w", err)
+	}
+
+	now := time.Now()
+	result := &Enforcement{}
+	if p.ArchiveDraftsAfterMonths.Valid {
+		result.DraftsArchived, err = s.store.ArchiveStaleDraftH5PContent(ctx, query.ArchiveStaleDraftH5PContentParams{
+			OrgID:     organisationID,
+			UpdatedAt: archiveCutoff(now, p.ArchiveDraftsAfterMonths.Int32),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("archiving drafts: %w", err)
+		}
+	}
+	if p.PurgeDeletedAfterDays.Valid {
+		if err := s.purgeDeleted(ctx, organisationID, purgeCutoff(now, p.PurgeDeletedAfterDays.Int32), result); err != nil {
+			s.recordEnforcement(ctx, organisationID, result)
+			return nil, err
+		}
+	}
+	if p.RetainXapiYears.Valid {
+		if err := s.deleteExpiredXAPI(ctx, organisationID, xapiCutoff(now, p.RetainXapiYears.Int32), result); err != nil {
+			s.recordEnforcement(ctx, organisationID, result)
+			return nil, err
+		}
+	}
+
+	if err := s.store.MarkRetentionPolicyEvaluated(ctx, organisationID); err != nil {
+		return nil, fmt.Errorf("marking retention policy enforced: %w", err)
+	}
+	s.recordEnforcement(ctx, organisationID, result)
+	slog.Info("Retention policy enforced", "organisation_id", organisationID, "drafts_archived", result.DraftsArchived,
+		"deleted_purged", result.DeletedPurged, "xapi_deleted", result.XAPIStatementsDeleted)
+	return result, nil
+}
+
+// purgeDeleted removes deleted content in batches. The purge queues each
+// item's stored files for the storage sweeper in the same statement, so they
+// go even if removing them fails at first.
+func (s *Service) purgeDeleted(ctx context.Context, organisationID uuid.UUID, cutoff time.Time, result *Enforcement) error {
+	for {
+		ids, err := s.store.PurgeDeletedH5PContent(ctx, query.PurgeDeletedH5PContentParams{
+			OrgID:     organisationID,
+			DeletedAt: sql.NullTime{Time: cutoff, Valid: true},
+			Limit:     purgeBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("purging deleted content: %w", err)
+		}
+		result.DeletedPurged += int64(len(ids))
+		if len(ids) < purgeBatchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) deleteExpiredXAPI(ctx context.Context, organisationID uuid.UUID, cutoff time.Time, result *Enforcement) error {
+	for {
+		n, err := s.store.DeleteExpiredXapiStatements(ctx, query.DeleteExpiredXapiStatementsParams{
+			OrgID:     organisationID,
+			CreatedAt: cutoff,
+			Limit:     xapiBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("deleting expired xAPI statements: %w", err)
+		}
+		result.XAPIStatementsDeleted += n
+		if n < xapiBatchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// recordEnforcement logs what an enforcement changed; runs that changed
+// nothing aren't logged.
+func (s *Service) recordEnforcement(ctx context.Context, organisationID uuid.UUID, result *Enforcement) {
+	if *result == (Enforcement{}) {
+		return
+	}
+	values, _ := json.Marshal(result)
+	s.record(context.WithoutCancel(ctx), organisationID, uuid.NullUUID{}, "retention_policy.enforced",
+		pqtype.NullRawMessage{}, values, json.RawMessage(`{"source":"retention_policy"}`))
+}
@@ -0,0 +1,257 @@
+package retention
+
+import (
+	"app/pkg"
+	"app/pkg/jobs"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+// Limits on each rule's period, so a typo can't archive or purge everything
+const (
+	maxArchiveDraftsMonths = 120
+	maxPurgeDeletedDays    = 3650
+	maxRetainXAPIYears     = 50
+)
+
+// store defines the database interface for retention operations
+type store interface {
+	GetRetentionPolicy(ctx context.Context, organisationID uuid.UUID) (query.OrganisationRetentionPolicy, error)
+	UpsertRetentionPolicy(ctx context.Context, arg query.UpsertRetentionPolicyParams) (query.OrganisationRetentionPolicy, error)
+	ListDueRetentionPolicies(ctx context.Context, arg query.ListDueRetentionPoliciesParams) ([]query.OrganisationRetentionPolicy, error)
+	MarkRetentionPolicyEvaluated(ctx context.Context, organisationID uuid.UUID) error
+	CountStaleDraftH5PContent(ctx context.Context, arg query.CountStaleDraftH5PContentParams) (int64, error)
+	ArchiveStaleDraftH5PContent(ctx context.Context, arg query.ArchiveStaleDraftH5PContentParams) (int64, error)
+	CountPurgeableH5PContent(ctx context.Context, arg query.CountPurgeableH5PContentParams) (int64, error)
+	PurgeDeletedH5PContent(ctx context.Context, arg query.PurgeDeletedH5PContentParams) ([]uuid.UUID, error)
+	CountExpiredXapiStatements(ctx context.Context, arg query.CountExpiredXapiStatementsParams) (int64, error)
+	DeleteExpiredXapiStatements(ctx context.Context, arg query.DeleteExpiredXapiStatementsParams) (int64, error)
+	InsertOrganisationActivityChange(ctx context.Context, arg query.InsertOrganisationActivityChangeParams) error
+}
+
+// Service manages organisation retention policies and enforces them
+type Service struct {
+	store store
+	jobs  *jobs.Queue
+}
+
+// NewService creates a new retention service. Policies are enforced on the
+// job queue.
+func NewService(store store, queue *jobs.Queue) *Service {
+	s := &Service{
+		store: store,
+		jobs:  queue,
+	}
+	s.registerJobs(queue)
+	return s
+}
+
+// Policy is an organisation's retention rules. A nil period turns that rule
+// off; an organisation without a policy keeps everything.
+type Policy struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	// ArchiveDraftsAfterMonths archives drafts nobody has edited for this long
+	ArchiveDraftsAfterMonths *int32 `json:"archiveDraftsAfterMonths"`
+	// PurgeDeletedAfterDays permanently removes deleted content, with its
+	// files, this long after it was deleted
+	PurgeDeletedAfterDays *int32 `json:"purgeDeletedAfterDays"`
+	// RetainXAPIYears deletes xAPI statements older than this
+	RetainXAPIYears *int32     `json:"retainXapiYears"`
+	UpdatedAt       *time.Time `json:"updatedAt"`
+	EvaluatedAt     *time.Time `json:"evaluatedAt"` // when the policy was last enforced
+}
+
+// PolicyUpdate sets all of an organisation's retention rules at once
+type PolicyUpdate struct {
+	ArchiveDraftsAfterMonths *int32 `json:"archiveDraftsAfterMonths"`
+	PurgeDeletedAfterDays    *int32 `json:"purgeDeletedAfterDays"`
+	RetainXAPIYears          *int32 `json:"retainXapiYears"`
+}
+
+// Impact counts the items a policy would archive or remove if it were
+// enforced now
+type Impact struct {
+	DraftsToArchive        int64 `json:"draftsToArchive"`
+	DeletedToPurge         int64 `json:"deletedToPurge"`
+	XAPIStatementsToDelete int64 `json:"xapiStatementsToDelete"`
+}
+
+// PolicyChange is a saved policy and what its next enforcement will affect
+type PolicyChange struct {
+	Policy *Policy `json:"policy"`
+	Impact Impact  `json:"impact"`
+}
+
+func toPolicy(p query.OrganisationRetentionPolicy) *Policy {
+	result := &Policy{
+		OrganisationID:           p.OrganisationID,
+		ArchiveDraftsAfterMonths: nullInt(p.ArchiveDraftsAfterMonths),
+		PurgeDeletedAfterDays:    nullInt(p.PurgeDeletedAfterDays),
+		RetainXAPIYears:          nullInt(p.RetainXapiYears),
+		UpdatedAt:                &p.UpdatedAt,
+	}
+	if p.EvaluatedAt.Valid {
+		result.EvaluatedAt = &p.EvaluatedAt.Time
+	}
+	return result
+}
+
+func nullInt(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+func toNullInt(n *int32) sql.NullInt32 {
+	if n == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *n, Valid: true}
+}
+
+func (u PolicyUpdate) validate() error {
+	checks := []struct {
+		value *int32
+		max   int32
+		name  string
+	}{
+		{u.ArchiveDraftsAfterMonths, maxArchiveDraftsMonths, "archiveDraftsAfterMonths"},
+		{u.PurgeDeletedAfterDays, maxPurgeDeletedDays, "purgeDeletedAfterDays"},
+		{u.RetainXAPIYears, maxRetainXAPIYears, "retainXapiYears"},
+	}
+	for _, c := range checks {
+		if c.value != nil && (*c.value < 1 || *c.value > c.max) {
+			return pkg.BadRequestError{Message: fmt.Sprintf("%s must be between 1 and %d, or null to turn it off", c.name, c.max)}
+		}
+	}
+	return nil
+}
+
+// Get returns the organisation's policy; one that keeps everything if none
+// was set.
+func (s *Service) Get(ctx context.Context, organisationID uuid.UUID) (*Policy, error) {
+	p, err := s.store.GetRetentionPolicy(ctx, organisationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &Policy{OrganisationID: organisationID}, nil
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading retention policy", Err: err}
+	}
+	return toPolicy(p), nil
+}
+
+// Preview counts what the proposed rules would affect if they were enforced
+// now, without saving them.
+func (s *Service) Preview(ctx context.Context, organisationID uuid.UUID, update PolicyUpdate) (*Impact, error) {
+	if err := update.validate(); err != nil {
+		return nil, err
+	}
+	return s.impact(ctx, organisationID, update, time.Now())
+}
+
+// Update replaces the organisation's policy and records the change, with the
+// counts it affects, in the activity log. It takes effect at the next daily
+// enforcement.
+func (s *Service) Update(ctx context.Context, organisationID, userID uuid.UUID, update PolicyUpdate) (*PolicyChange, error) {
+	if err := update.validate(); err != nil {
+		return nil, err
+	}
+	old, err := s.Get(ctx, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	impact, err := s.impact(ctx, organisationID, update, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.store.UpsertRetentionPolicy(ctx, query.UpsertRetentionPolicyParams{
+		OrganisationID:           organisationID,
+		ArchiveDraftsAfterMonths: toNullInt(update.ArchiveDraftsAfterMonths),
+		PurgeDeletedAfterDays:    toNullInt(update.PurgeDeletedAfterDays),
+		RetainXapiYears:          toNullInt(update.RetainXAPIYears),
+		UpdatedBy:                uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error saving retention policy", Err: err}
+	}
+
+	oldValues, _ := json.Marshal(PolicyUpdate{
+		ArchiveDraftsAfterMonths: old.ArchiveDraftsAfterMonths,
+		PurgeDeletedAfterDays:    old.PurgeDeletedAfterDays,
+		RetainXAPIYears:          old.RetainXAPIYears,
+	})
+	newValues, _ := json.Marshal(update)
+	metadata, _ := json.Marshal(map[string]any{"impact": impact})
+	s.record(ctx, organisationID, uuid.NullUUID{UUID: userID, Valid: true}, "retention_policy.updated",
+		pqtype.NullRawMessage{RawMessage: oldValues, Valid: true}, newValues, metadata)
+
+	return &PolicyChange{Policy: toPolicy(saved), Impact: *impact}, nil
+}
+
+// impact counts what the rules would affect if they were enforced at now
+func (s *Service) impact(ctx context.Context, organisationID uuid.UUID, rules PolicyUpdate, now time.Time) (*Impact, error) {
+	impact := &Impact{}
+	var err error
+	if rules.ArchiveDraftsAfterMonths != nil {
+		impact.DraftsToArchive, err = s.store.CountStaleDraftH5PContent(ctx, query.CountStaleDraftH5PContentParams{
+			OrgID:     organisationID,
+			UpdatedAt: archiveCutoff(now, *rules.ArchiveDraftsAfterMonths),
+		})
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error counting drafts to archive", Err: err}
+		}
+	}
+	if rules.PurgeDeletedAfterDays != nil {
+		impact.DeletedToPurge, err = s.store.CountPurgeableH5PContent(ctx, query.CountPurgeableH5PContentParams{
+			OrgID:     organisationID,
+			DeletedAt: sql.NullTime{Time: purgeCutoff(now, *rules.PurgeDeletedAfterDays), Valid: true},
+		})
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error counting deleted content to purge", Err: err}
+		}
+	}
+	if rules.RetainXAPIYears != nil {
+		impact.XAPIStatementsToDelete, err = s.store.CountExpiredXapiStatements(ctx, query.CountExpiredXapiStatementsParams{
+			OrgID:     organisationID,
+			CreatedAt: xapiCutoff(now, *rules.RetainXAPIYears),
+		})
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error counting xAPI statements to delete", Err: err}
+		}
+	}
+	return impact, nil
+}
+
+func archiveCutoff(now time.Time, months int32) time.Time { return now.AddDate(0, -int(months), 0) }
+func purgeCutoff(now time.Time, days int32) time.Time     { return now.AddDate(0, 0, -int(days)) }
+func xapiCutoff(now time.Time, years int32) time.Time     { return now.AddDate(-int(years), 0, 0) }
+
+// record writes an activity log entry. The change has already been made, so
+// a failure here is logged rather than returned.
+func (s *Service) record(ctx context.Context, organisationID uuid.UUID, userID uuid.NullUUID, action string, oldValues pqtype.NullRawMessage, newValues, metadata json.RawMessage) {
+	err := s.store.InsertOrganisationActivityChange(ctx, query.InsertOrganisationActivityChangeParams{
+		OrganisationID: organisationID,
+		UserID:         userID,
+		Action:         action,
+		EntityType:     "retention_policy",
+		EntityID:       uuid.NullUUID{UUID: organisationID, Valid: true},
+		OldValues:      oldValues,
+		NewValues:      pqtype.NullRawMessage{RawMessage: newValues, Valid: true},
+		Metadata:       metadata,
+	})
+	if err != nil {
+		slog.Error("Error recording retention activity", "error", err, "action", action, "organisation_id", organisationID)
+	}
+}
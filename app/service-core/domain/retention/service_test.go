@@ -0,0 +1,254 @@
+package retention
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore serves one organisation's policy and hands out purge and xAPI
+// batches in order. Once the batches run out it returns failPurge or
+// failXAPI if set, or an empty batch.
+type fakeStore struct {
+	store
+
+	policy     *query.OrganisationRetentionPolicy
+	archived   int64
+	purges     [][]uuid.UUID
+	xapi       []int64
+	failPurge  error
+	failXAPI   error
+	purgeCalls []query.PurgeDeletedH5PContentParams
+	xapiCalls  []query.DeleteExpiredXapiStatementsParams
+	evaluated  bool
+	records    []query.InsertOrganisationActivityChangeParams
+}
+
+func (s *fakeStore) GetRetentionPolicy(context.Context, uuid.UUID) (query.OrganisationRetentionPolicy, error) {
+	if s.policy == nil {
+		return query.OrganisationRetentionPolicy{}, sql.ErrNoRows
+	}
+	return *s.policy, nil
+}
+
+func (s *fakeStore) ArchiveStaleDraftH5PContent(context.Context, query.ArchiveStaleDraftH5PContentParams) (int64, error) {
+	return s.archived, nil
+}
+
+func (s *fakeStore) PurgeDeletedH5PContent(_ context.Context, arg query.PurgeDeletedH5PContentParams) ([]uuid.UUID, error) {
+	s.purgeCalls = append(s.purgeCalls, arg)
+	if len(s.purges) == 0 {
+		if s.failPurge != nil {
+			return nil, s.failPurge
+		}
+		return nil, nil
+	}
+	batch := s.purges[0]
+	s.purges = s.purges[1:]
+	return batch, nil
+}
+
+func (s *fakeStore) DeleteExpiredXapiStatements(_ context.Context, arg query.DeleteExpiredXapiStatementsParams) (int64, error) {
+	s.xapiCalls = append(s.xapiCalls, arg)
+	if len(s.xapi) == 0 {
+		if s.failXAPI != nil {
+			return 0, s.failXAPI
+		}
+		return 0, nil
+	}
+	n := s.xapi[0]
+	s.xapi = s.xapi[1:]
+	return n, nil
+}
+
+func (s *fakeStore) MarkRetentionPolicyEvaluated(context.Context, uuid.UUID) error {
+	s.evaluated = true
+	return nil
+}
+
+func (s *fakeStore) InsertOrganisationActivityChange(_ context.Context, arg query.InsertOrganisationActivityChangeParams) error {
+	s.records = append(s.records, arg)
+	return nil
+}
+
+func ids(n int) []uuid.UUID {
+	out := make([]uuid.UUID, n)
+	for i := range out {
+		out[i] = uuid.New()
+	}
+	return out
+}
+
+func nullInt32(n int32) sql.NullInt32 { return sql.NullInt32{Int32: n, Valid: true} }
+
+func TestEnforce(t *testing.T) {
+	orgID := uuid.New()
+	errStore := errors.New("connection reset")
+
+	tests := []struct {
+		name          string
+		store         *fakeStore
+		want          *Enforcement
+		wantErr       bool
+		purgeCalls    int
+		xapiCalls     int
+		wantEvaluated bool
+		wantRecorded  bool
+	}{
+		{
+			name:  "no policy",
+			store: &fakeStore{},
+			want:  &Enforcement{},
+		},
+		{
+			name:          "nothing due",
+			store:         &fakeStore{policy: &query.OrganisationRetentionPolicy{PurgeDeletedAfterDays: nullInt32(30)}},
+			want:          &Enforcement{},
+			purgeCalls:    1,
+			wantEvaluated: true,
+		},
+		{
+			name: "purges in batches until one comes back short",
+			store: &fakeStore{
+				policy: &query.OrganisationRetentionPolicy{PurgeDeletedAfterDays: nullInt32(30)},
+				purges: [][]uuid.UUID{ids(purgeBatchSize), ids(purgeBatchSize), ids(7)},
+			},
+			want:          &Enforcement{DeletedPurged: 2*purgeBatchSize + 7},
+			purgeCalls:    3,
+			wantEvaluated: true,
+			wantRecorded:  true,
+		},
+		{
+			name: "every rule",
+			store: &fakeStore{
+				policy: &query.OrganisationRetentionPolicy{
+					ArchiveDraftsAfterMonths: nullInt32(12),
+					PurgeDeletedAfterDays:    nullInt32(30),
+					RetainXapiYears:          nullInt32(7),
+				},
+				archived: 4,
+				purges:   [][]uuid.UUID{ids(2)},
+				xapi:     []int64{xapiBatchSize, 10},
+			},
+			want:          &Enforcement{DraftsArchived: 4, DeletedPurged: 2, XAPIStatementsDeleted: xapiBatchSize + 10},
+			purgeCalls:    1,
+			xapiCalls:     2,
+			wantEvaluated: true,
+			wantRecorded:  true,
+		},
+		{
+			// What was purged before the failure is still recorded, and the
+			// policy stays due so the next run picks up where this one stopped
+			name: "purge fails part way",
+			store: &fakeStore{
+				policy:    &query.OrganisationRetentionPolicy{PurgeDeletedAfterDays: nullInt32(30), RetainXapiYears: nullInt32(7)},
+				purges:    [][]uuid.UUID{ids(purgeBatchSize)},
+				failPurge: errStore,
+			},
+			wantErr:      true,
+			purgeCalls:   2,
+			wantRecorded: true,
+		},
+		{
+			name: "xAPI delete fails",
+			store: &fakeStore{
+				policy:   &query.OrganisationRetentionPolicy{RetainXapiYears: nullInt32(7)},
+				failXAPI: errStore,
+			},
+			wantErr:   true,
+			xapiCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{store: tt.store}
+			got, err := s.Enforce(context.Background(), orgID)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errStore)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.Len(t, tt.store.purgeCalls, tt.purgeCalls)
+			assert.Len(t, tt.store.xapiCalls, tt.xapiCalls)
+			assert.Equal(t, tt.wantEvaluated, tt.store.evaluated)
+
+			if !tt.wantRecorded {
+				assert.Empty(t, tt.store.records)
+				return
+			}
+			require.Len(t, tt.store.records, 1)
+			record := tt.store.records[0]
+			assert.Equal(t, "retention_policy.enforced", record.Action)
+			assert.False(t, record.UserID.Valid)
+			var logged Enforcement
+			require.NoError(t, json.Unmarshal(record.NewValues.RawMessage, &logged))
+			if tt.want != nil {
+				assert.Equal(t, *tt.want, logged)
+			} else {
+				assert.Equal(t, int64(purgeBatchSize), logged.DeletedPurged)
+			}
+		})
+	}
+}
+
+func TestEnforce_Cutoffs(t *testing.T) {
+	store := &fakeStore{
+		policy: &query.OrganisationRetentionPolicy{PurgeDeletedAfterDays: nullInt32(30), RetainXapiYears: nullInt32(2)},
+		purges: [][]uuid.UUID{ids(purgeBatchSize)},
+		xapi:   []int64{1},
+	}
+	s := &Service{store: store}
+	before := time.Now()
+	_, err := s.Enforce(context.Background(), uuid.New())
+	require.NoError(t, err)
+	after := time.Now()
+
+	for _, call := range store.purgeCalls {
+		assert.Equal(t, int32(purgeBatchSize), call.Limit)
+		assert.True(t, call.DeletedAt.Valid)
+		assert.WithinRange(t, call.DeletedAt.Time, before.AddDate(0, 0, -30), after.AddDate(0, 0, -30))
+	}
+	require.Len(t, store.xapiCalls, 1)
+	assert.Equal(t, int32(xapiBatchSize), store.xapiCalls[0].Limit)
+	assert.WithinRange(t, store.xapiCalls[0].CreatedAt, before.AddDate(-2, 0, 0), after.AddDate(-2, 0, 0))
+}
+
+func TestPolicyUpdate_Validate(t *testing.T) {
+	n := func(v int32) *int32 { return &v }
+	tests := []struct {
+		name   string
+		update PolicyUpdate
+		valid  bool
+	}{
+		{name: "all off", update: PolicyUpdate{}, valid: true},
+		{name: "at the limits", update: PolicyUpdate{
+			ArchiveDraftsAfterMonths: n(maxArchiveDraftsMonths),
+			PurgeDeletedAfterDays:    n(1),
+			RetainXAPIYears:          n(maxRetainXAPIYears),
+		}, valid: true},
+		{name: "zero days", update: PolicyUpdate{PurgeDeletedAfterDays: n(0)}},
+		{name: "negative months", update: PolicyUpdate{ArchiveDraftsAfterMonths: n(-1)}},
+		{name: "too many years", update: PolicyUpdate{RetainXAPIYears: n(maxRetainXAPIYears + 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.update.validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.IsType(t, pkg.BadRequestError{}, err)
+			}
+		})
+	}
+}
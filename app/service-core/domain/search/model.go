@@ -0,0 +1,41 @@
+package search
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Result types, also used as group names.
+const (
+	TypeContent     = "content"
+	TypeCourse      = "course"
+	TypeFolder      = "folder"
+	TypeKeywordList = "keyword_list"
+)
+
+// Results is a search response with one group per result type that matched.
+type Results struct {
+	Query  string  `json:"query"`
+	Groups []Group `json:"groups"`
+}
+
+// Group holds the best matches of one type, best first.
+type Group struct {
+	Type    string   `json:"type"`
+	Results []Result `json:"results"`
+}
+
+// Result is a single match. Rank runs from 0 (exact title match) to 4 (matched
+// a field other than the title); lower is better.
+type Result struct {
+	Type      string     `json:"type"`
+	ID        uuid.UUID  `json:"id"`
+	Title     string     `json:"title"`
+	Subtitle  string     `json:"subtitle,omitempty"` // content type name for H5P content
+	Status    string     `json:"status,omitempty"`
+	Locked    bool       `json:"locked"`
+	ParentID  *uuid.UUID `json:"parentId,omitempty"` // folders only
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Rank      int32      `json:"rank"`
+}
@@ -0,0 +1,153 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Options controls what a search returns.
+type Options struct {
+	// IncludeDrafts also matches unpublished content and courses
+	IncludeDrafts bool
+	// IncludeFolders also matches content folders
+	IncludeFolders bool
+	// Limit caps the results in each group
+	Limit int32
+}
+
+// OptionsForRole returns the search options for an organisation role. Owners
+// and admins manage content so they see drafts and folders; members only see
+// what is published.
+func OptionsForRole(role string, limit int32) Options {
+	manager := role == "owner" || role == "admin"
+	return Options{IncludeDrafts: manager, IncludeFolders: manager, Limit: limit}
+}
+
+// Search matches term against an organisation's H5P content, courses,
+// folders and SEO keyword lists. Groups are ordered by their best match, so an
+// exact course title comes before a content item that only matched on its
+// description. Keyword lists also match on the keywords in them.
+func (s *Service) Search(ctx context.Context, orgID uuid.UUID, term string, opts Options) (*Results, error) {
+	pattern := escapeLike(term)
+	results := &Results{Query: term, Groups: []Group{}}
+
+	contentRows, err := s.store.SearchH5PContent(ctx, query.SearchH5PContentParams{
+		Pattern:       pattern,
+		OrgID:         orgID,
+		IncludeDrafts: opts.IncludeDrafts,
+		MaxResults:    opts.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching content: %w", err)
+	}
+	if len(contentRows) > 0 {
+		group := Group{Type: TypeContent}
+		for _, row := range contentRows {
+			group.Results = append(group.Results, Result{
+				Type:      TypeContent,
+				ID:        row.ID,
+				Title:     row.Title,
+				Subtitle:  row.LibraryTitle,
+				Status:    row.Status,
+				Locked:    row.Locked,
+				UpdatedAt: row.UpdatedAt,
+				Rank:      row.Rank,
+			})
+		}
+		results.Groups = append(results.Groups, group)
+	}
+
+	courseRows, err := s.store.SearchCourses(ctx, query.SearchCoursesParams{
+		Pattern:       pattern,
+		OrgID:         orgID,
+		IncludeDrafts: opts.IncludeDrafts,
+		MaxResults:    opts.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching courses: %w", err)
+	}
+	if len(courseRows) > 0 {
+		group := Group{Type: TypeCourse}
+		for _, row := range courseRows {
+			group.Results = append(group.Results, Result{
+				Type:      TypeCourse,
+				ID:        row.ID,
+				Title:     row.Title,
+				Status:    row.Status,
+				Locked:    row.Locked,
+				UpdatedAt: row.UpdatedAt,
+				Rank:      row.Rank,
+			})
+		}
+		results.Groups = append(results.Groups, group)
+	}
+
+	if opts.IncludeFolders {
+		folderRows, err := s.store.SearchH5PContentFolders(ctx, query.SearchH5PContentFoldersParams{
+			Pattern:    pattern,
+			OrgID:      orgID,
+			MaxResults: opts.Limit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("searching folders: %w", err)
+		}
+		if len(folderRows) > 0 {
+			group := Group{Type: TypeFolder}
+			for _, row := range folderRows {
+				result := Result{
+					Type:      TypeFolder,
+					ID:        row.ID,
+					Title:     row.Name,
+					UpdatedAt: row.UpdatedAt,
+					Rank:      row.Rank,
+				}
+				if row.ParentID.Valid {
+					result.ParentID = &row.ParentID.UUID
+				}
+				group.Results = append(group.Results, result)
+			}
+			results.Groups = append(results.Groups, group)
+		}
+	}
+
+	// Any member can see keyword lists
+	listRows, err := s.store.SearchSeoKeywordLists(ctx, query.SearchSeoKeywordListsParams{
+		Pattern:    pattern,
+		OrgID:      orgID,
+		MaxResults: opts.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching keyword lists: %w", err)
+	}
+	if len(listRows) > 0 {
+		group := Group{Type: TypeKeywordList}
+		for _, row := range listRows {
+			group.Results = append(group.Results, Result{
+				Type:      TypeKeywordList,
+				ID:        row.ID,
+				Title:     row.Name,
+				UpdatedAt: row.UpdatedAt,
+				Rank:      row.Rank,
+			})
+		}
+		results.Groups = append(results.Groups, group)
+	}
+
+	// Each group is already sorted by rank; a stable sort on the first result
+	// keeps content, courses, folders, keyword lists order for ties
+	sort.SliceStable(results.Groups, func(i, j int) bool {
+		return results.Groups[i].Results[0].Rank < results.Groups[j].Results[0].Rank
+	})
+	return results, nil
+}
+
+// escapeLike escapes LIKE wildcards so the term matches literally.
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
@@ -0,0 +1,25 @@
+package search
+
+import (
+	"context"
+
+	"service-core/storage/query"
+)
+
+// store defines the database interface for search
+type store interface {
+	SearchCourses(ctx context.Context, arg query.SearchCoursesParams) ([]query.SearchCoursesRow, error)
+	SearchH5PContent(ctx context.Context, arg query.SearchH5PContentParams) ([]query.SearchH5PContentRow, error)
+	SearchH5PContentFolders(ctx context.Context, arg query.SearchH5PContentFoldersParams) ([]query.SearchH5PContentFoldersRow, error)
+	SearchSeoKeywordLists(ctx context.Context, arg query.SearchSeoKeywordListsParams) ([]query.SearchSeoKeywordListsRow, error)
+}
+
+// Service searches an organisation's content for the command palette
+type Service struct {
+	store store
+}
+
+// NewService creates a new search service
+func NewService(store store) *Service {
+	return &Service{store: store}
+}
@@ -0,0 +1,69 @@
+package seo
+
+import (
+	"app/pkg"
+	"app/pkg/dataforseo"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"service-core/domain/membership"
+
+	"github.com/google/uuid"
+)
+
+// maxAnchors is how many of a target's anchors an analysis covers: a single
+// request's worth, which reaches well into the long tail for most sites.
+const maxAnchors = 1000
+
+// backlinksClient is the part of the DataForSEO client backlink reports and
+// prospects use.
+type backlinksClient interface {
+	GetBacklinksSummary(ctx context.Context, target string) (*dataforseo.BacklinksSummary, error)
+	GetReferringDomains(ctx context.Context, target string, limit, offset int) ([]dataforseo.ReferringDomain, error)
+	GetAnchors(ctx context.Context, target string, limit, offset int) ([]dataforseo.AnchorText, error)
+}
+
+// AnalyzeAnchorText builds the anchor text section of a domain's backlink
+// report: its anchors bucketed by the request's rules, the share of
+// referring domains in each bucket, and warnings where the profile looks
+// over-optimised. Only organisation owners and admins can run it, since the
+// lookup is billed.
+func (s *Service) AnalyzeAnchorText(ctx context.Context, userID uuid.UUID, req AnchorTextRequest) (*AnchorTextReport, error) {
+	if s.backlinks == nil {
+		return nil, pkg.BadRequestError{Message: "Backlink reports are not configured"}
+	}
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+
+	domain, err := NormaliseDomain(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	brand := req.Brand
+	if len(brand) == 0 {
+		// The domain's name is the brand more often than not
+		name, _, _ := strings.Cut(strings.TrimPrefix(domain, "www."), ".")
+		brand = []string{name}
+	}
+
+	ctx = dataforseo.WithCostAttribution(ctx, req.OrganisationID.String())
+	anchors, err := s.backlinks.GetAnchors(ctx, domain, maxAnchors, 0)
+	if err != nil {
+		slog.Error("Anchor lookup failed", "organisationID", req.OrganisationID, "domain", domain, "error", err)
+		if errors.Is(err, dataforseo.ErrBudgetExceeded) {
+			return nil, pkg.BadRequestError{Message: "The monthly SEO budget has been reached"}
+		}
+		return nil, pkg.InternalError{Message: "Error looking up anchors", Err: err}
+	}
+
+	analysis := dataforseo.AnalyzeAnchors(anchors, dataforseo.AnchorRules{
+		Brand:      brand,
+		Keywords:   req.Keywords,
+		Generic:    req.Generic,
+		Thresholds: req.Thresholds,
+	})
+	return &AnchorTextReport{Domain: domain, Brand: brand, AnchorAnalysis: analysis}, nil
+}
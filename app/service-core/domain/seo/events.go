@@ -0,0 +1,27 @@
+package seo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// SEO domain events published to organisation webhooks when long-running jobs
+// finish
+const (
+	EventAuditCompleted     = "audit.completed"
+	EventRankCheckCompleted = "rank_check.completed"
+)
+
+// publisher delivers domain events to an organisation's registered webhooks
+type publisher interface {
+	Publish(ctx context.Context, organisationID uuid.UUID, eventType string, data map[string]any)
+}
+
+// publish sends an event to the organisation's webhooks, if any
+func (s *Service) publish(ctx context.Context, organisationID uuid.UUID, eventType string, data map[string]any) {
+	if s.publisher == nil {
+		return
+	}
+	s.publisher.Publish(ctx, organisationID, eventType, data)
+}
@@ -0,0 +1,384 @@
+package seo
+
+import (
+	"app/pkg"
+	"app/pkg/dataforseo"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"service-core/domain/membership"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxRecheckURLs is the most pages one recheck verifies. Rechecks answer
+	// within the request, so they're meant for the handful of pages a
+	// customer just fixed.
+	MaxRecheckURLs = 5
+	// recheckTimeout bounds a recheck, within the server's write timeout.
+	recheckTimeout = 8 * time.Second
+	// onPageCheckTimeout bounds the on-page checks an audit makes per page.
+	onPageCheckTimeout = 30 * time.Second
+	// maxCheckedPageSize caps the HTML the local checker reads.
+	maxCheckedPageSize = 5 << 20
+	// maxTitleLength is DataForSEO's title_too_long threshold.
+	maxTitleLength       = 65
+	checkedPageUserAgent = "LeapLearn-PageCheck/1.0"
+)
+
+// On-page checks an audit tracks as issues, named as DataForSEO names them.
+// Each is true when the page has the issue.
+const (
+	CheckHTTP          = "is_http"
+	Check4xx           = "is_4xx_code"
+	Check5xx           = "is_5xx_code"
+	CheckNoTitle       = "no_title"
+	CheckTitleTooLong  = "title_too_long"
+	CheckNoDescription = "no_description"
+	CheckNoH1          = "no_h1_tag"
+	CheckNoImageAlt    = "no_image_alt"
+	CheckNoDoctype     = "no_doctype"
+	CheckMetaRefresh   = "has_meta_refresh_redirect"
+)
+
+// trackedChecks lists the tracked checks in the order issues are reported.
+var trackedChecks = []string{
+	Check4xx, Check5xx, CheckHTTP, CheckMetaRefresh, CheckNoTitle, CheckTitleTooLong,
+	CheckNoDescription, CheckNoH1, CheckNoImageAlt, CheckNoDoctype,
+}
+
+// Issue statuses.
+const (
+	IssueOpen     = "open"
+	IssueResolved = "resolved"
+)
+
+// PageIssue is an on-page issue found on an audited page. Resolved issues
+// stay on the page so the fix is on record.
+type PageIssue struct {
+	Check       string     `json:"check"`
+	Status      string     `json:"status"`
+	FirstSeenAt time.Time  `json:"firstSeenAt"`
+	ResolvedAt  *time.Time `json:"resolvedAt"`
+	VerifiedAt  time.Time  `json:"verifiedAt"` // when the check last ran
+}
+
+// RecheckRequest asks for the issues of some pages of an audit to be checked
+// again.
+type RecheckRequest struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	URLs           []string  `json:"urls"`
+}
+
+// PageRecheck is the outcome of rechecking one page. Verified is false when
+// the page couldn't be checked; its issues are then left as they were.
+type PageRecheck struct {
+	URL      string      `json:"url"`
+	Verified bool        `json:"verified"`
+	Error    string      `json:"error,omitempty"`
+	Issues   []PageIssue `json:"issues"`
+	Resolved int         `json:"resolved"` // issues this recheck found fixed
+	Opened   int         `json:"opened"`   // issues this recheck found new or back
+}
+
+// onPageChecker runs the tracked on-page checks against a live page. raw is
+// the provider's undecoded result, or nil when the checker has none.
+type onPageChecker interface {
+	CheckPage(ctx context.Context, pageURL string) (checks map[string]bool, raw json.RawMessage, err error)
+}
+
+// instantPageChecker checks pages with DataForSEO's instant pages endpoint.
+type instantPageChecker struct {
+	client *dataforseo.Client
+}
+
+func (c instantPageChecker) CheckPage(ctx context.Context, pageURL string) (map[string]bool, json.RawMessage, error) {
+	page, raw, err := c.client.GetInstantPageWithRaw(ctx, dataforseo.InstantPagesRequest{URL: pageURL})
+	if err != nil {
+		return nil, nil, err
+	}
+	checks := make(map[string]bool, len(trackedChecks))
+	for _, name := range trackedChecks {
+		if failed, ok := page.Checks[name]; ok {
+			checks[name] = failed
+		}
+	}
+	// DataForSEO reports status codes as fields rather than checks
+	checks[Check4xx] = page.StatusCode >= 400 && page.StatusCode < 500
+	checks[Check5xx] = page.StatusCode >= 500
+	return checks, raw, nil
+}
+
+// The local checker's markup tests, deliberately loose.
+var (
+	reDoctype     = regexp.MustCompile(`(?i)^\s*(<\?xml[^>]*>\s*)?(<!--.*?-->\s*)*<!doctype\s+html`)
+	reTitleTag    = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	reDescription = regexp.MustCompile(`(?is)<meta\s[^>]*name\s*=\s*["']description["'][^>]*content\s*=\s*["']\s*[^"'\s][^"']*["']|<meta\s[^>]*content\s*=\s*["']\s*[^"'\s][^"']*["'][^>]*name\s*=\s*["']description["']`)
+	reH1          = regexp.MustCompile(`(?i)<h1[\s>]`)
+	reImg         = regexp.MustCompile(`(?is)<img\s[^>]*>`)
+	reImgAlt      = regexp.MustCompile(`(?i)\salt\s*=`)
+	reMetaRefresh = regexp.MustCompile(`(?i)<meta\s[^>]*http-equiv\s*=\s*["']?refresh`)
+	reSpace       = regexp.MustCompile(`\s+`)
+)
+
+// httpPageChecker fetches pages itself and checks their markup. It's the
+// fallback when DataForSEO isn't configured; it doesn't run JavaScript.
+type httpPageChecker struct {
+	client *http.Client
+}
+
+func (c httpPageChecker) CheckPage(ctx context.Context, pageURL string) (map[string]bool, json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", checkedPageUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	checks := map[string]bool{
+		CheckHTTP: resp.Request.URL.Scheme == "http",
+		Check4xx:  resp.StatusCode >= 400 && resp.StatusCode < 500,
+		Check5xx:  resp.StatusCode >= 500,
+	}
+	if resp.StatusCode >= 400 {
+		// An error page's markup says nothing about the page
+		return checks, nil, nil
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "html") {
+		return nil, nil, fmt.Errorf("unsupported content type %q", ct)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCheckedPageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, failed := range markupChecks(string(body)) {
+		checks[name] = failed
+	}
+	return checks, nil, nil
+}
+
+// markupChecks runs the tracked checks that only need the page's HTML.
+func markupChecks(doc string) map[string]bool {
+	title := ""
+	if m := reTitleTag.FindStringSubmatch(doc); m != nil {
+		title = strings.TrimSpace(reSpace.ReplaceAllString(m[1], " "))
+	}
+	missingAlt := false
+	for _, img := range reImg.FindAllString(doc, -1) {
+		if !reImgAlt.MatchString(img) {
+			missingAlt = true
+			break
+		}
+	}
+	return map[string]bool{
+		CheckNoDoctype:     !reDoctype.MatchString(doc),
+		CheckNoTitle:       title == "",
+		CheckTitleTooLong:  len([]rune(title)) > maxTitleLength,
+		CheckNoDescription: !reDescription.MatchString(doc),
+		CheckNoH1:          !reH1.MatchString(doc),
+		CheckNoImageAlt:    missingAlt,
+		CheckMetaRefresh:   reMetaRefresh.MatchString(doc),
+	}
+}
+
+// mergeIssues applies a fresh set of check results to a page's issues. Only
+// the checks that ran are touched: a failing check opens its issue (keeping
+// when it was first seen if it was already open), a passing one resolves it.
+// It returns how many issues were resolved and opened.
+func mergeIssues(prior []PageIssue, checks map[string]bool, now time.Time) (issues []PageIssue, resolved, opened int) {
+	byCheck := make(map[string]PageIssue, len(prior))
+	for _, issue := range prior {
+		byCheck[issue.Check] = issue
+	}
+	for name, failed := range checks {
+		issue, seen := byCheck[name]
+		switch {
+		case failed && (!seen || issue.Status == IssueResolved):
+			issue = PageIssue{Check: name, Status: IssueOpen, FirstSeenAt: now}
+			opened++
+		case !failed && seen && issue.Status == IssueOpen:
+			issue.Status, issue.ResolvedAt = IssueResolved, &now
+			resolved++
+		case !failed && !seen:
+			continue
+		}
+		issue.VerifiedAt = now
+		byCheck[name] = issue
+	}
+
+	issues = make([]PageIssue, 0, len(byCheck))
+	for _, issue := range byCheck {
+		issues = append(issues, issue)
+	}
+	slices.SortFunc(issues, func(a, b PageIssue) int {
+		return checkOrder(a.Check) - checkOrder(b.Check)
+	})
+	return issues, resolved, opened
+}
+
+// checkOrder places tracked checks in report order and anything else after.
+func checkOrder(name string) int {
+	if i := slices.Index(trackedChecks, name); i >= 0 {
+		return i
+	}
+	return len(trackedChecks)
+}
+
+func parseIssues(raw json.RawMessage) ([]PageIssue, error) {
+	issues := []PageIssue{}
+	if len(raw) == 0 {
+		return issues, nil
+	}
+	if err := json.Unmarshal(raw, &issues); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// checkPageIssues runs the on-page checks for an audited page and merges them
+// into the issues it had. A check that can't run leaves them as they were.
+// The checker's raw result, if any, is kept as the page's on-page artifact.
+func (s *Service) checkPageIssues(ctx context.Context, row query.SeoPageExperience) json.RawMessage {
+	prior, err := parseIssues(row.Issues)
+	if err != nil {
+		slog.Error("Failed to read page issues", "url", row.PageUrl, "error", err)
+		prior = []PageIssue{}
+	}
+	ctx, cancel := context.WithTimeout(ctx, onPageCheckTimeout)
+	defer cancel()
+	checks, raw, err := s.pageChecker.CheckPage(ctx, row.PageUrl)
+	if err != nil {
+		slog.Warn("On-page checks failed", "url", row.PageUrl, "error", err)
+	} else {
+		prior, _, _ = mergeIssues(prior, checks, time.Now())
+		if raw != nil {
+			s.saveRawArtifact(ctx, row, RawSectionOnPage, raw)
+		}
+	}
+	issues, err := json.Marshal(prior)
+	if err != nil {
+		return json.RawMessage("[]")
+	}
+	return issues
+}
+
+// RecheckAuditPages checks the on-page issues of some pages of an audit again,
+// without re-running the audit, so a customer can confirm a fix. Every
+// strategy's assessment of a page gets the refreshed issues and is marked
+// re-verified. Only organisation owners and admins can recheck pages.
+func (s *Service) RecheckAuditPages(ctx context.Context, userID, auditID uuid.UUID, req RecheckRequest) ([]PageRecheck, error) {
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+	if len(req.URLs) == 0 {
+		return nil, pkg.BadRequestError{Message: "At least one URL is required"}
+	}
+	if len(req.URLs) > MaxRecheckURLs {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("At most %d URLs can be rechecked at a time", MaxRecheckURLs)}
+	}
+
+	rows, err := s.store.ListSeoPageExperienceByAudit(ctx, query.ListSeoPageExperienceByAuditParams{
+		OrganisationID: req.OrganisationID,
+		AuditID:        auditID,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading audit", Err: err}
+	}
+	if len(rows) == 0 {
+		return nil, pkg.NotFoundError{Message: "Audit not found"}
+	}
+
+	var urls []string
+	for _, raw := range req.URLs {
+		pageURL, err := normalisePageURL(raw)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(rows, func(row query.SeoPageExperience) bool { return row.PageUrl == pageURL }) {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("%s is not part of this audit", pageURL)}
+		}
+		if !slices.Contains(urls, pageURL) {
+			urls = append(urls, pageURL)
+		}
+	}
+
+	// The runner would overwrite the rechecked issues when it saves the page.
+	// Pending pages may be queued for a runner that hasn't started yet.
+	running, err := s.locks.IsHeld(ctx, auditLockName(req.OrganisationID, auditID))
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading audit", Err: err}
+	}
+	running = running || slices.ContainsFunc(rows, queued)
+	if running {
+		return nil, pkg.BadRequestError{Message: "This audit is still running; wait for it to finish"}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, recheckTimeout)
+	defer cancel()
+	results := make([]map[string]bool, len(urls))
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, pageURL := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _, errs[i] = s.pageChecker.CheckPage(checkCtx, pageURL)
+		}()
+	}
+	wg.Wait()
+
+	now := time.Now()
+	rechecks := make([]PageRecheck, len(urls))
+	for i, pageURL := range urls {
+		recheck := PageRecheck{URL: pageURL, Issues: []PageIssue{}}
+		if errs[i] != nil {
+			slog.Warn("Page recheck failed", "url", pageURL, "error", errs[i])
+			recheck.Error = "The page could not be checked"
+			if checkCtx.Err() != nil {
+				recheck.Error = "The page did not respond in time"
+			}
+		}
+		for _, row := range rows {
+			if row.PageUrl != pageURL {
+				continue
+			}
+			prior, err := parseIssues(row.Issues)
+			if err != nil {
+				return nil, pkg.InternalError{Message: "Error reading page issues", Err: err}
+			}
+			if errs[i] != nil {
+				recheck.Issues = prior
+				continue
+			}
+			issues, resolved, opened := mergeIssues(prior, results[i], now)
+			raw, err := json.Marshal(issues)
+			if err != nil {
+				return nil, pkg.InternalError{Message: "Error saving page issues", Err: err}
+			}
+			if err := s.store.RecheckSeoPageIssues(ctx, query.RecheckSeoPageIssuesParams{ID: row.ID, Issues: raw}); err != nil {
+				return nil, pkg.InternalError{Message: "Error saving page issues", Err: err}
+			}
+			// Strategies share the page's markup, so they agree; report one
+			recheck.Verified, recheck.Issues = true, issues
+			recheck.Resolved, recheck.Opened = resolved, opened
+		}
+		rechecks[i] = recheck
+	}
+	return rechecks, nil
+}
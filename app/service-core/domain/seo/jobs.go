@@ -0,0 +1,94 @@
+package seo
+
+import (
+	"app/pkg/dataforseo"
+	"app/pkg/jobs"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Background jobs, queued so a check or audit started just before a restart
+// still runs.
+const (
+	jobRankCheck           = "seo.rank_check"
+	jobPageExperienceAudit = "seo.page_experience_audit"
+)
+
+type rankCheckJob struct {
+	CheckID        uuid.UUID                       `json:"checkId"`
+	OrganisationID uuid.UUID                       `json:"organisationId"`
+	Domain         string                          `json:"domain"`
+	Requests       []dataforseo.SERPOrganicRequest `json:"requests"`
+}
+
+type pageExperienceAuditJob struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	AuditID        uuid.UUID `json:"auditId"`
+}
+
+func (s *Service) registerJobs(queue *jobs.Queue) {
+	// A retried check queues its lookups again, so it's only retried when it
+	// was interrupted before finishing
+	queue.Register(jobRankCheck, s.runRankCheckJob, jobs.MaxAttempts(2), jobs.Timeout(rankCheckTimeout))
+	// Retries pick up the pages left pending, e.g. while another runner held
+	// the audit
+	queue.Register(jobPageExperienceAudit, s.runPageExperienceAuditJob, jobs.MaxAttempts(4),
+		jobs.Timeout(pageExperienceTimeout(MaxPageExperiencePages)))
+}
+
+func (s *Service) runRankCheckJob(ctx context.Context, payload json.RawMessage) error {
+	var job rankCheckJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return fmt.Errorf("decoding rank check job: %w", err)
+	}
+	row, err := s.store.GetSeoRankCheck(ctx, query.GetSeoRankCheckParams{ID: job.CheckID, OrganisationID: job.OrganisationID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil // its organisation was deleted
+	}
+	if err != nil {
+		return fmt.Errorf("loading rank check: %w", err)
+	}
+	if row.Status != StatusPending {
+		return nil // finished before the job was interrupted
+	}
+	s.runRankCheck(ctx, job.CheckID, job.OrganisationID, job.Domain, job.Requests)
+	return nil
+}
+
+func (s *Service) runPageExperienceAuditJob(ctx context.Context, payload json.RawMessage) error {
+	var job pageExperienceAuditJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return fmt.Errorf("decoding page experience audit job: %w", err)
+	}
+	lock, err := s.lockAudit(ctx, job.OrganisationID, job.AuditID)
+	if err != nil {
+		return err
+	}
+	rows, err := s.store.ListSeoPageExperienceByAudit(ctx, query.ListSeoPageExperienceByAuditParams{
+		OrganisationID: job.OrganisationID,
+		AuditID:        job.AuditID,
+	})
+	if err != nil {
+		releaseAudit(lock)
+		return fmt.Errorf("loading page experience audit: %w", err)
+	}
+	var pending []query.SeoPageExperience
+	for _, row := range rows {
+		if row.Status == StatusPending {
+			pending = append(pending, row)
+		}
+	}
+	if len(pending) == 0 {
+		releaseAudit(lock)
+		return nil
+	}
+	s.runPageExperienceAudit(ctx, lock, job.AuditID, pending)
+	return nil
+}
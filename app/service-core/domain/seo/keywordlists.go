@@ -0,0 +1,363 @@
+package seo
+
+import (
+	"app/pkg"
+	"app/pkg/dataforseo"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"service-core/domain/membership"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxKeywordListKeywords is the most keywords a list holds, so a list
+	// never needs more than one search volume task on its own.
+	MaxKeywordListKeywords = dataforseo.MaxSearchVolumeKeywords
+	// Google Ads rejects longer keywords from search volume lookups.
+	maxVolumeKeywordLength = 80
+	maxVolumeKeywordWords  = 10
+	// keywordRefreshHistory is how many refreshes a list returns.
+	keywordRefreshHistory = 12
+	// keywordRefreshAttribution is the cost report key for the scheduler's
+	// batches, which are shared between organisations. Each organisation's
+	// share is stored with its lists' refreshes.
+	keywordRefreshAttribution = "keyword-volume-refresh"
+)
+
+// volumeClient is the part of the DataForSEO client keyword lists use.
+type volumeClient interface {
+	GetSearchVolumeWithCost(ctx context.Context, req dataforseo.KeywordSearchVolumeRequest) ([]dataforseo.KeywordData, float64, error)
+}
+
+// CreateKeywordList saves a keyword list. It is due straight away, so its
+// volumes arrive with the scheduler's next run, then monthly. Only
+// organisation owners and admins can create lists, since refreshes are
+// billed.
+func (s *Service) CreateKeywordList(ctx context.Context, userID uuid.UUID, req KeywordListRequest) (*KeywordList, error) {
+	if s.volumes == nil {
+		return nil, pkg.BadRequestError{Message: "Keyword lists are not configured"}
+	}
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, pkg.BadRequestError{Message: "name is required"}
+	}
+	if req.LocationCode <= 0 {
+		return nil, pkg.BadRequestError{Message: "locationCode is required"}
+	}
+	if req.LanguageCode == "" {
+		return nil, pkg.BadRequestError{Message: "languageCode is required"}
+	}
+	keywords, err := normaliseVolumeKeywords(req.Keywords)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := s.store.CreateSeoKeywordList(ctx, query.CreateSeoKeywordListParams{
+		OrganisationID: req.OrganisationID,
+		Name:           name,
+		LocationCode:   int32(req.LocationCode),
+		LanguageCode:   req.LanguageCode,
+		CreatedBy:      uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error creating keyword list", Err: err}
+	}
+	if err := s.store.AddSeoKeywords(ctx, query.AddSeoKeywordsParams{ListID: row.ID, Keywords: keywords}); err != nil {
+		return nil, pkg.InternalError{Message: "Error saving keywords", Err: err}
+	}
+
+	list := newKeywordList(row, int64(len(keywords)))
+	return &list, nil
+}
+
+// ListKeywordLists returns the organisation's keyword lists to any of its
+// members, without their keywords.
+func (s *Service) ListKeywordLists(ctx context.Context, userID, organisationID uuid.UUID) ([]KeywordList, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.store.ListSeoKeywordListsByOrg(ctx, organisationID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading keyword lists", Err: err}
+	}
+	lists := make([]KeywordList, len(rows))
+	for i, r := range rows {
+		lists[i] = newKeywordList(query.SeoKeywordList{
+			ID:              r.ID,
+			OrganisationID:  r.OrganisationID,
+			Name:            r.Name,
+			LocationCode:    r.LocationCode,
+			LanguageCode:    r.LanguageCode,
+			CreatedBy:       r.CreatedBy,
+			CreatedAt:       r.CreatedAt,
+			LastRefreshedAt: r.LastRefreshedAt,
+			NextRefreshAt:   r.NextRefreshAt,
+		}, r.KeywordCount)
+	}
+	return lists, nil
+}
+
+// GetKeywordList returns a keyword list with its volumes and recent
+// refreshes to any member of the organisation.
+func (s *Service) GetKeywordList(ctx context.Context, userID, organisationID, listID uuid.UUID) (*KeywordList, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+
+	row, err := s.store.GetSeoKeywordList(ctx, query.GetSeoKeywordListParams{ID: listID, OrganisationID: organisationID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Keyword list not found"}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading keyword list", Err: err}
+	}
+	keywords, err := s.store.ListSeoKeywords(ctx, listID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading keywords", Err: err}
+	}
+	refreshes, err := s.store.ListSeoKeywordRefreshes(ctx, query.ListSeoKeywordRefreshesParams{ListID: listID, Limit: keywordRefreshHistory})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading keyword refreshes", Err: err}
+	}
+
+	list := newKeywordList(row, int64(len(keywords)))
+	list.Keywords = make([]KeywordVolume, len(keywords))
+	for i, k := range keywords {
+		if list.Keywords[i], err = newKeywordVolume(k); err != nil {
+			return nil, pkg.InternalError{Message: "Error reading keywords", Err: err}
+		}
+	}
+	list.Refreshes = make([]KeywordRefresh, len(refreshes))
+	for i, r := range refreshes {
+		list.Refreshes[i] = KeywordRefresh{
+			Status:       r.Status,
+			KeywordCount: r.KeywordCount,
+			UpdatedCount: r.UpdatedCount,
+			Cost:         r.Cost,
+			Error:        r.Error,
+			CreatedAt:    r.CreatedAt,
+		}
+	}
+	return &list, nil
+}
+
+// volumeMarket is the location and language a search volume task is for;
+// only keywords of the same market can share a task.
+type volumeMarket struct {
+	locationCode int32
+	languageCode string
+}
+
+// listRefresh tallies one list's part in a scheduler run.
+type listRefresh struct {
+	organisationID uuid.UUID
+	keywords       int32
+	updated        int32
+	cost           float64
+	err            string
+}
+
+// RefreshDueKeywordLists refreshes the search volumes of every list that is
+// due, across all organisations. Keywords are pooled by market and looked
+// up in as few tasks as the API's per-task limit allows, since a task costs
+// the same however many keywords it holds. Each task's cost is split evenly
+// between its keywords, and each keyword's share between the lists that
+// hold it.
+//
+// Every list gets a refresh recorded with its share of the cost. Lists whose
+// batches all came back are rescheduled a month out; the rest stay due and
+// are retried on the next run.
+func (s *Service) RefreshDueKeywordLists(ctx context.Context) (*KeywordRefreshSummary, error) {
+	summary := &KeywordRefreshSummary{}
+	if s.volumes == nil {
+		return summary, nil
+	}
+
+	due, err := s.store.ListDueSeoKeywords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing due keywords: %w", err)
+	}
+
+	// The rows come sorted by market then keyword, so each market's keywords
+	// are already in order
+	markets := make(map[volumeMarket][]string)
+	var order []volumeMarket
+	holders := make(map[volumeMarket]map[string][]uuid.UUID)
+	lists := make(map[uuid.UUID]*listRefresh)
+	for _, row := range due {
+		m := volumeMarket{locationCode: row.LocationCode, languageCode: row.LanguageCode}
+		if _, ok := holders[m]; !ok {
+			holders[m] = make(map[string][]uuid.UUID)
+			order = append(order, m)
+		}
+		if len(holders[m][row.Keyword]) == 0 {
+			markets[m] = append(markets[m], row.Keyword)
+		}
+		holders[m][row.Keyword] = append(holders[m][row.Keyword], row.ListID)
+
+		l, ok := lists[row.ListID]
+		if !ok {
+			l = &listRefresh{organisationID: row.OrganisationID}
+			lists[row.ListID] = l
+		}
+		l.keywords++
+	}
+
+	ctx = dataforseo.WithCostAttribution(ctx, keywordRefreshAttribution)
+	for _, m := range order {
+		keywords := markets[m]
+		for start := 0; start < len(keywords); start += dataforseo.MaxSearchVolumeKeywords {
+			batch := keywords[start:min(start+dataforseo.MaxSearchVolumeKeywords, len(keywords))]
+			s.refreshVolumeBatch(ctx, m, batch, holders[m], lists, summary)
+		}
+	}
+
+	next := time.Now().AddDate(0, 1, 0)
+	for listID, l := range lists {
+		params := query.CreateSeoKeywordRefreshParams{
+			ListID:         listID,
+			OrganisationID: l.organisationID,
+			Status:         RefreshCompleted,
+			KeywordCount:   l.keywords,
+			UpdatedCount:   l.updated,
+			Cost:           l.cost,
+			Error:          l.err,
+		}
+		switch {
+		case l.err == "":
+		case l.updated == 0:
+			params.Status = RefreshFailed
+		default:
+			params.Status = RefreshPartial
+		}
+		if err := s.store.CreateSeoKeywordRefresh(ctx, params); err != nil {
+			slog.Error("Failed to record keyword refresh", "listID", listID, "error", err)
+		}
+		if params.Status != RefreshCompleted {
+			continue
+		}
+		if err := s.store.ScheduleSeoKeywordList(ctx, query.ScheduleSeoKeywordListParams{ID: listID, NextRefreshAt: next}); err != nil {
+			slog.Error("Failed to reschedule keyword list", "listID", listID, "error", err)
+		}
+	}
+
+	summary.Lists = len(lists)
+	return summary, nil
+}
+
+// refreshVolumeBatch looks up one task's worth of keywords and stores the
+// volumes in every list that holds them. A failed lookup is recorded
+// against those lists rather than stopping the run.
+func (s *Service) refreshVolumeBatch(ctx context.Context, m volumeMarket, batch []string, holders map[string][]uuid.UUID, lists map[uuid.UUID]*listRefresh, summary *KeywordRefreshSummary) {
+	summary.Batches++
+	summary.Keywords += len(batch)
+
+	items, cost, err := s.volumes.GetSearchVolumeWithCost(ctx, dataforseo.KeywordSearchVolumeRequest{
+		Keywords:     batch,
+		LocationCode: int(m.locationCode),
+		LanguageCode: m.languageCode,
+	})
+	if err != nil {
+		slog.Error("Keyword volume batch failed", "locationCode", m.locationCode, "languageCode", m.languageCode, "keywords", len(batch), "error", err)
+		summary.Failed++
+		message := "The search volume lookup failed"
+		if errors.Is(err, dataforseo.ErrBudgetExceeded) {
+			message = "The monthly SEO budget has been reached"
+		}
+		for _, keyword := range batch {
+			for _, listID := range holders[keyword] {
+				lists[listID].err = message
+			}
+		}
+		return
+	}
+	summary.Cost += cost
+
+	// Google Ads may return keywords in a different case than asked
+	byKeyword := make(map[string]dataforseo.KeywordData, len(items))
+	for _, item := range items {
+		byKeyword[strings.ToLower(item.Keyword)] = item
+	}
+
+	perKeyword := cost / float64(len(batch))
+	for _, keyword := range batch {
+		listIDs := holders[keyword]
+		item, found := byKeyword[keyword]
+		for _, listID := range listIDs {
+			l := lists[listID]
+			l.cost += perKeyword / float64(len(listIDs))
+			if !found {
+				continue
+			}
+			if err := s.store.UpdateSeoKeywordVolume(ctx, volumeParams(listID, keyword, item)); err != nil {
+				slog.Error("Failed to save keyword volume", "listID", listID, "keyword", keyword, "error", err)
+				l.err = "Some volumes could not be saved"
+				continue
+			}
+			l.updated++
+		}
+	}
+}
+
+func volumeParams(listID uuid.UUID, keyword string, item dataforseo.KeywordData) query.UpdateSeoKeywordVolumeParams {
+	params := query.UpdateSeoKeywordVolumeParams{
+		ListID:          listID,
+		Keyword:         keyword,
+		Competition:     item.Competition,
+		MonthlySearches: json.RawMessage("[]"),
+	}
+	if item.SearchVolume != nil {
+		params.SearchVolume = sql.NullInt32{Int32: int32(*item.SearchVolume), Valid: true}
+	}
+	if item.CPC != nil {
+		params.Cpc = sql.NullFloat64{Float64: *item.CPC, Valid: true}
+	}
+	if len(item.MonthlySearches) > 0 {
+		if b, err := json.Marshal(item.MonthlySearches); err == nil {
+			params.MonthlySearches = b
+		}
+	}
+	return params
+}
+
+// normaliseVolumeKeywords lowercases the keywords, collapses their spaces and
+// drops blanks and duplicates. Search volumes don't depend on case, and
+// lowercase keeps the same keyword from being looked up twice when several
+// lists hold it.
+func normaliseVolumeKeywords(raw []string) ([]string, error) {
+	seen := make(map[string]bool, len(raw))
+	keywords := make([]string, 0, len(raw))
+	for _, k := range raw {
+		words := strings.Fields(strings.ToLower(k))
+		k = strings.Join(words, " ")
+		if k == "" || seen[k] {
+			continue
+		}
+		if len(k) > maxVolumeKeywordLength || len(words) > maxVolumeKeywordWords {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("Keywords can be at most %d characters and %d words", maxVolumeKeywordLength, maxVolumeKeywordWords)}
+		}
+		seen[k] = true
+		keywords = append(keywords, k)
+	}
+	if len(keywords) == 0 {
+		return nil, pkg.BadRequestError{Message: "At least one keyword is required"}
+	}
+	if len(keywords) > MaxKeywordListKeywords {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("A list can include at most %d keywords", MaxKeywordListKeywords)}
+	}
+	return keywords, nil
+}
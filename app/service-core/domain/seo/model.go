@@ -0,0 +1,663 @@
+package seo
+
+import (
+	"app/pkg/dataforseo"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Rank check statuses.
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Devices a SERP can be collected for.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+)
+
+// PageSpeed strategies a page experience can be assessed with.
+const (
+	StrategyMobile  = "mobile"
+	StrategyDesktop = "desktop"
+)
+
+// RankCheckRequest asks where a domain ranks for a list of keywords.
+type RankCheckRequest struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	Domain         string    `json:"domain"`
+	Keywords       []string  `json:"keywords"`
+	LocationCode   int       `json:"locationCode"` // DataForSEO location, e.g. 2036 for Australia
+	LanguageCode   string    `json:"languageCode"` // e.g. "en"
+	Device         string    `json:"device"`       // desktop (default) or mobile
+}
+
+// RankCheck is a queued or finished rank check. Results are empty until the
+// check completes.
+type RankCheck struct {
+	ID           uuid.UUID     `json:"id"`
+	Domain       string        `json:"domain"`
+	LocationCode int32         `json:"locationCode"`
+	LanguageCode string        `json:"languageCode"`
+	Device       string        `json:"device"`
+	KeywordCount int32         `json:"keywordCount"`
+	Status       string        `json:"status"`
+	Cost         float64       `json:"cost"` // USD, as billed by DataForSEO
+	Error        string        `json:"error,omitempty"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	CompletedAt  *time.Time    `json:"completedAt"`
+	Results      []KeywordRank `json:"results"`
+}
+
+// KeywordRank is where the domain ranks for one keyword. Position is nil when
+// the domain isn't in the top 100 organic results, or when the lookup failed.
+type KeywordRank struct {
+	Keyword  string `json:"keyword"`
+	Position *int   `json:"position"`
+	URL      string `json:"url,omitempty"` // the ranking page
+	// SERPFeatures lists the non-organic elements on the page, e.g.
+	// "featured_snippet" or "people_also_ask"
+	SERPFeatures []string `json:"serpFeatures"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// PageExperienceRequest asks for the page experience of a set of pages. An
+// empty AuditID starts a new audit; passing one re-runs or adds pages to it.
+type PageExperienceRequest struct {
+	OrganisationID uuid.UUID   `json:"organisationId"`
+	AuditID        uuid.UUID   `json:"auditId"`
+	Strategy       string      `json:"strategy"` // mobile (default) or desktop
+	Pages          []PageInput `json:"pages"`
+}
+
+// PageInput is a page to assess, with the on-page checks PageSpeed can't make.
+type PageInput struct {
+	URL string `json:"url"`
+	// IntrusiveInterstitial reports whether the page covers its content with
+	// a popup or overlay on load; nil when it wasn't checked
+	IntrusiveInterstitial *bool `json:"intrusiveInterstitial"`
+}
+
+// Page experience audit statuses, summarising its pages.
+const (
+	AuditRunning   = "running"   // some pages are pending
+	AuditCompleted = "completed" // every page completed
+	AuditPartial   = "partial"   // finished with some pages failed; resume to retry them
+	AuditFailed    = "failed"    // every page failed
+)
+
+// PageExperienceAudit is the pages assessed in one audit, with a count of
+// their statuses.
+type PageExperienceAudit struct {
+	AuditID   uuid.UUID        `json:"auditId"`
+	Status    string           `json:"status"`
+	Pending   int              `json:"pending"`
+	Completed int              `json:"completed"`
+	Failed    int              `json:"failed"`
+	Pages     []PageExperience `json:"pages"`
+}
+
+// summarise counts the page statuses and sets the audit's status from them.
+func (a *PageExperienceAudit) summarise() {
+	a.Pending, a.Completed, a.Failed = 0, 0, 0
+	for _, p := range a.Pages {
+		switch p.Status {
+		case StatusPending:
+			a.Pending++
+		case StatusCompleted:
+			a.Completed++
+		default:
+			a.Failed++
+		}
+	}
+	switch {
+	case a.Pending > 0:
+		a.Status = AuditRunning
+	case a.Failed == 0:
+		a.Status = AuditCompleted
+	case a.Completed == 0:
+		a.Status = AuditFailed
+	default:
+		a.Status = AuditPartial
+	}
+}
+
+// PageExperience is the scored page experience of one page. The scores and
+// signals are empty until the assessment completes.
+type PageExperience struct {
+	ID                    uuid.UUID       `json:"id"`
+	URL                   string          `json:"url"`
+	Strategy              string          `json:"strategy"`
+	Status                string          `json:"status"`
+	Error                 string          `json:"error,omitempty"`
+	IntrusiveInterstitial *bool           `json:"intrusiveInterstitial"`
+	Score                 int32           `json:"score"` // 0-100
+	Passed                bool            `json:"passed"`
+	CWVSource             string          `json:"cwvSource,omitempty"` // field, origin or lab
+	LCPMs                 *float64        `json:"lcpMs"`
+	INPMs                 *float64        `json:"inpMs"`
+	CLS                   *float64        `json:"cls"`
+	Signals               []Signal        `json:"signals"`
+	PageSpeed             json.RawMessage `json:"pagespeed,omitempty"`
+	Issues                []PageIssue     `json:"issues"`
+	CreatedAt             time.Time       `json:"createdAt"`
+	CompletedAt           *time.Time      `json:"completedAt"`
+	RecheckedAt           *time.Time      `json:"recheckedAt"` // when the issues were last re-verified on their own
+}
+
+// PageExperienceTrendPoint is one completed assessment of a page over time.
+type PageExperienceTrendPoint struct {
+	AuditID    uuid.UUID `json:"auditId"`
+	Score      int32     `json:"score"`
+	Passed     bool      `json:"passed"`
+	CWVSource  string    `json:"cwvSource"`
+	LCPMs      *float64  `json:"lcpMs"`
+	INPMs      *float64  `json:"inpMs"`
+	CLS        *float64  `json:"cls"`
+	MeasuredAt time.Time `json:"measuredAt"`
+}
+
+func newPageExperience(p query.SeoPageExperience) (PageExperience, error) {
+	page := PageExperience{
+		ID:        p.ID,
+		URL:       p.PageUrl,
+		Strategy:  p.Strategy,
+		Status:    p.Status,
+		Error:     p.Error,
+		Score:     p.Score,
+		Passed:    p.Passed,
+		CWVSource: p.CwvSource,
+		LCPMs:     nullFloat(p.LcpMs),
+		INPMs:     nullFloat(p.InpMs),
+		CLS:       nullFloat(p.Cls),
+		Signals:   []Signal{},
+		CreatedAt: p.CreatedAt,
+	}
+	if p.IntrusiveInterstitial.Valid {
+		page.IntrusiveInterstitial = &p.IntrusiveInterstitial.Bool
+	}
+	if p.CompletedAt.Valid {
+		page.CompletedAt = &p.CompletedAt.Time
+	}
+	if p.Status == StatusCompleted {
+		page.PageSpeed = p.Pagespeed
+	}
+	if p.RecheckedAt.Valid {
+		page.RecheckedAt = &p.RecheckedAt.Time
+	}
+	if err := json.Unmarshal(p.Signals, &page.Signals); err != nil {
+		return PageExperience{}, err
+	}
+	issues, err := parseIssues(p.Issues)
+	if err != nil {
+		return PageExperience{}, err
+	}
+	page.Issues = issues
+	return page, nil
+}
+
+func nullFloat(f sql.NullFloat64) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	return &f.Float64
+}
+
+func newRankCheck(c query.SeoRankCheck) (RankCheck, error) {
+	check := RankCheck{
+		ID:           c.ID,
+		Domain:       c.Domain,
+		LocationCode: c.LocationCode,
+		LanguageCode: c.LanguageCode,
+		Device:       c.Device,
+		KeywordCount: c.KeywordCount,
+		Status:       c.Status,
+		Cost:         c.Cost,
+		Error:        c.Error,
+		CreatedAt:    c.CreatedAt,
+		Results:      []KeywordRank{},
+	}
+	if c.CompletedAt.Valid {
+		check.CompletedAt = &c.CompletedAt.Time
+	}
+	if err := json.Unmarshal(c.Results, &check.Results); err != nil {
+		return RankCheck{}, err
+	}
+	return check, nil
+}
+
+// Keyword list refresh statuses.
+const (
+	RefreshCompleted = "completed" // every batch the list was in came back
+	RefreshPartial   = "partial"   // some batches failed; the list stays due
+	RefreshFailed    = "failed"    // no volumes were updated
+)
+
+// KeywordListRequest creates a keyword list whose search volumes refresh
+// monthly.
+type KeywordListRequest struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	Name           string    `json:"name"`
+	LocationCode   int       `json:"locationCode"` // DataForSEO location, e.g. 2036 for Australia
+	LanguageCode   string    `json:"languageCode"` // e.g. "en"
+	Keywords       []string  `json:"keywords"`
+}
+
+// KeywordList is a saved list of keywords. Keywords and Refreshes are only
+// filled in when a single list is requested.
+type KeywordList struct {
+	ID              uuid.UUID        `json:"id"`
+	Name            string           `json:"name"`
+	LocationCode    int32            `json:"locationCode"`
+	LanguageCode    string           `json:"languageCode"`
+	KeywordCount    int64            `json:"keywordCount"`
+	CreatedAt       time.Time        `json:"createdAt"`
+	LastRefreshedAt *time.Time       `json:"lastRefreshedAt"`
+	NextRefreshAt   time.Time        `json:"nextRefreshAt"`
+	Keywords        []KeywordVolume  `json:"keywords,omitempty"`
+	Refreshes       []KeywordRefresh `json:"refreshes,omitempty"` // newest first
+}
+
+// KeywordVolume is a keyword's Google Ads search volume as of its last
+// refresh. SearchVolume is nil before the first refresh, or when Google Ads
+// has no data for the keyword.
+type KeywordVolume struct {
+	Keyword         string                     `json:"keyword"`
+	SearchVolume    *int32                     `json:"searchVolume"`
+	CPC             *float64                   `json:"cpc"`
+	Competition     string                     `json:"competition,omitempty"` // LOW, MEDIUM or HIGH
+	MonthlySearches []dataforseo.MonthlySearch `json:"monthlySearches"`
+	RefreshedAt     *time.Time                 `json:"refreshedAt"`
+}
+
+// KeywordRefresh is one scheduled refresh of a list. Cost is the list's share
+// of the search volume tasks it was batched into with other lists.
+type KeywordRefresh struct {
+	Status       string    `json:"status"`
+	KeywordCount int32     `json:"keywordCount"`
+	UpdatedCount int32     `json:"updatedCount"`
+	Cost         float64   `json:"cost"` // USD
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// KeywordRefreshSummary totals one run of the keyword volume scheduler.
+type KeywordRefreshSummary struct {
+	Lists    int     `json:"lists"`
+	Keywords int     `json:"keywords"` // distinct keywords looked up
+	Batches  int     `json:"batches"`  // search volume tasks posted
+	Failed   int     `json:"failed"`   // batches that failed
+	Cost     float64 `json:"cost"`     // USD
+}
+
+func newKeywordList(l query.SeoKeywordList, keywordCount int64) KeywordList {
+	list := KeywordList{
+		ID:            l.ID,
+		Name:          l.Name,
+		LocationCode:  l.LocationCode,
+		LanguageCode:  l.LanguageCode,
+		KeywordCount:  keywordCount,
+		CreatedAt:     l.CreatedAt,
+		NextRefreshAt: l.NextRefreshAt,
+	}
+	if l.LastRefreshedAt.Valid {
+		list.LastRefreshedAt = &l.LastRefreshedAt.Time
+	}
+	return list
+}
+
+func newKeywordVolume(k query.SeoKeyword) (KeywordVolume, error) {
+	v := KeywordVolume{
+		Keyword:         k.Keyword,
+		CPC:             nullFloat(k.Cpc),
+		Competition:     k.Competition,
+		MonthlySearches: []dataforseo.MonthlySearch{},
+	}
+	if k.SearchVolume.Valid {
+		v.SearchVolume = &k.SearchVolume.Int32
+	}
+	if k.RefreshedAt.Valid {
+		v.RefreshedAt = &k.RefreshedAt.Time
+	}
+	if err := json.Unmarshal(k.MonthlySearches, &v.MonthlySearches); err != nil {
+		return KeywordVolume{}, err
+	}
+	return v, nil
+}
+
+// AnchorTextRequest asks for the anchor text section of a domain's backlink
+// report. Brand defaults to the domain's name, e.g. "acme" for acme.com.
+type AnchorTextRequest struct {
+	OrganisationID uuid.UUID                   `json:"organisationId"`
+	Domain         string                      `json:"domain"`
+	Brand          []string                    `json:"brand"`
+	Keywords       []string                    `json:"keywords"` // the keywords the domain targets
+	Generic        []string                    `json:"generic"`  // replaces the default generic anchors when set
+	Thresholds     dataforseo.AnchorThresholds `json:"thresholds"`
+}
+
+// AnchorTextReport is a domain's anchor text profile.
+type AnchorTextReport struct {
+	Domain string   `json:"domain"`
+	Brand  []string `json:"brand"`
+	dataforseo.AnchorAnalysis
+}
+
+// Intervals a schedule runs at, set by the organisation's tier.
+const (
+	IntervalDaily  = "daily"
+	IntervalWeekly = "weekly"
+)
+
+// Schedule run statuses. A run is running until its audit and rank check
+// have both finished.
+const (
+	RunRunning   = "running"
+	RunCompleted = "completed"
+	RunPartial   = "partial" // some pages or lookups failed
+	RunFailed    = "failed"
+)
+
+// ScheduleRequest registers a domain for recurring page experience audits
+// and rank checks, or replaces the pages and keywords of one already
+// registered.
+type ScheduleRequest struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	Domain         string    `json:"domain"`
+	Pages          []string  `json:"pages"`        // page URLs on the domain to audit
+	Keywords       []string  `json:"keywords"`     // keywords to check the domain's rank for
+	LocationCode   int       `json:"locationCode"` // required with keywords
+	LanguageCode   string    `json:"languageCode"` // required with keywords
+	Device         string    `json:"device"`       // rank checks: desktop (default) or mobile
+	Strategy       string    `json:"strategy"`     // audits: mobile (default) or desktop
+}
+
+// Schedule is a registered domain. Interval is empty when the organisation's
+// tier no longer includes scheduled audits; the schedule is kept but
+// doesn't run.
+type Schedule struct {
+	ID           uuid.UUID    `json:"id"`
+	Domain       string       `json:"domain"`
+	Pages        []string     `json:"pages"`
+	Keywords     []string     `json:"keywords"`
+	LocationCode int32        `json:"locationCode"`
+	LanguageCode string       `json:"languageCode"`
+	Device       string       `json:"device"`
+	Strategy     string       `json:"strategy"`
+	Interval     string       `json:"interval"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	LastRunAt    *time.Time   `json:"lastRunAt"`
+	NextRunAt    time.Time    `json:"nextRunAt"`
+	LatestRun    *ScheduleRun `json:"latestRun"`
+}
+
+// ScheduleRun is one scheduled audit and rank check of a domain. Deltas is
+// nil for a domain's first run and until the run finishes.
+type ScheduleRun struct {
+	ID          uuid.UUID          `json:"id"`
+	Status      string             `json:"status"`
+	AuditID     *uuid.UUID         `json:"auditId"`
+	RankCheckID *uuid.UUID         `json:"rankCheckId"`
+	Summary     ScheduleRunSummary `json:"summary"`
+	Deltas      *ScheduleRunDeltas `json:"deltas"`
+	Error       string             `json:"error,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	CompletedAt *time.Time         `json:"completedAt"`
+}
+
+// ScheduleRunSummary condenses a finished run for the dashboard. Audit and
+// Ranks are nil when the run had no pages or keywords.
+type ScheduleRunSummary struct {
+	Audit *AuditSummary `json:"audit,omitempty"`
+	Ranks *RankSummary  `json:"ranks,omitempty"`
+}
+
+// AuditSummary condenses a page experience audit.
+type AuditSummary struct {
+	Pages        int              `json:"pages"`
+	Completed    int              `json:"completed"`
+	Failed       int              `json:"failed"`
+	Passed       int              `json:"passed"`
+	AverageScore *float64         `json:"averageScore"` // over completed pages
+	Scores       map[string]int32 `json:"scores"`       // by URL, completed pages only
+}
+
+// RankSummary condenses a rank check. Positions holds every keyword that
+// was looked up, with nil for those not in the top 100.
+type RankSummary struct {
+	Keywords        int             `json:"keywords"`
+	Failed          int             `json:"failed"` // lookups that failed
+	Ranked          int             `json:"ranked"`
+	Top3            int             `json:"top3"`
+	Top10           int             `json:"top10"`
+	AveragePosition *float64        `json:"averagePosition"` // over ranked keywords
+	Positions       map[string]*int `json:"positions"`
+}
+
+// ScheduleRunDeltas compares a run with the previous finished run of the
+// same domain. A field is nil when either run lacks what it compares.
+type ScheduleRunDeltas struct {
+	PreviousRunID uuid.UUID `json:"previousRunId"`
+	AverageScore  *float64  `json:"averageScore"`
+	Passed        *int      `json:"passed"`
+	Ranked        *int      `json:"ranked"`
+	Top10         *int      `json:"top10"`
+	// AveragePosition is the change in average position, so negative means
+	// the domain climbed
+	AveragePosition *float64          `json:"averagePosition"`
+	Pages           []PageScoreChange `json:"pages"`    // pages whose score changed
+	Keywords        []KeywordMovement `json:"keywords"` // keywords whose position changed
+}
+
+// PageScoreChange is a page's score in two runs.
+type PageScoreChange struct {
+	URL      string `json:"url"`
+	Previous int32  `json:"previous"`
+	Current  int32  `json:"current"`
+	Change   int32  `json:"change"`
+}
+
+// KeywordMovement is a keyword's position in two runs. Change is positive
+// when the domain climbed, and nil when it entered or left the top 100.
+type KeywordMovement struct {
+	Keyword  string `json:"keyword"`
+	Previous *int   `json:"previous"`
+	Current  *int   `json:"current"`
+	Change   *int   `json:"change"`
+}
+
+// ScheduleTaskSummary totals one run of the schedule task.
+type ScheduleTaskSummary struct {
+	Finalised int `json:"finalised"` // runs whose results were summarised
+	Started   int `json:"started"`
+	Deferred  int `json:"deferred"` // due schedules of tiers without scheduled audits
+}
+
+func newSchedule(sch query.SeoSchedule, interval string) Schedule {
+	schedule := Schedule{
+		ID:           sch.ID,
+		Domain:       sch.Domain,
+		Pages:        sch.Pages,
+		Keywords:     sch.Keywords,
+		LocationCode: sch.LocationCode,
+		LanguageCode: sch.LanguageCode,
+		Device:       sch.Device,
+		Strategy:     sch.Strategy,
+		Interval:     interval,
+		CreatedAt:    sch.CreatedAt,
+		NextRunAt:    sch.NextRunAt,
+	}
+	if sch.LastRunAt.Valid {
+		schedule.LastRunAt = &sch.LastRunAt.Time
+	}
+	return schedule
+}
+
+func newScheduleRun(r query.SeoScheduleRun) (ScheduleRun, error) {
+	run := ScheduleRun{
+		ID:        r.ID,
+		Status:    r.Status,
+		Error:     r.Error,
+		CreatedAt: r.CreatedAt,
+	}
+	if r.AuditID.Valid {
+		run.AuditID = &r.AuditID.UUID
+	}
+	if r.RankCheckID.Valid {
+		run.RankCheckID = &r.RankCheckID.UUID
+	}
+	if r.CompletedAt.Valid {
+		run.CompletedAt = &r.CompletedAt.Time
+	}
+	if err := json.Unmarshal(r.Summary, &run.Summary); err != nil {
+		return ScheduleRun{}, err
+	}
+	// Stored as {} until the run finishes and on a domain's first run
+	var deltas ScheduleRunDeltas
+	if err := json.Unmarshal(r.Deltas, &deltas); err != nil {
+		return ScheduleRun{}, err
+	}
+	if deltas.PreviousRunID != uuid.Nil {
+		run.Deltas = &deltas
+	}
+	return run, nil
+}
+
+// Backlink prospect statuses, in pipeline order.
+const (
+	ProspectIdentified = "identified"
+	ProspectContacted  = "contacted"
+	ProspectLinked     = "linked"
+)
+
+// Where a backlink prospect came from.
+const (
+	ProspectSourceManual        = "manual"
+	ProspectSourceCSV           = "csv"
+	ProspectSourceCompetitorGap = "competitor_gap"
+)
+
+// ProspectRequest adds prospect domains by hand for links to Target, one of
+// the organisation's sites. Contact and notes apply to every domain.
+type ProspectRequest struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	Target         string    `json:"target"`
+	Domains        []string  `json:"domains"`
+	Contact        string    `json:"contact"`
+	Notes          string    `json:"notes"`
+}
+
+// ProspectGapRequest adds the domains that link to any of Competitors but not
+// to Target as prospects. Limit caps how many are added, strongest first.
+type ProspectGapRequest struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	Target         string    `json:"target"`
+	Competitors    []string  `json:"competitors"`
+	Limit          int       `json:"limit"`
+}
+
+// ProspectUpdate moves a prospect along the pipeline or edits its contact
+// details. Nil fields are left as they are.
+type ProspectUpdate struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	Status         *string   `json:"status"`
+	Contact        *string   `json:"contact"`
+	Notes          *string   `json:"notes"`
+}
+
+// ProspectEnrichRequest asks for the backlink summaries of a target's
+// prospects that don't have one yet.
+type ProspectEnrichRequest struct {
+	OrganisationID uuid.UUID `json:"organisationId"`
+	Target         string    `json:"target"`
+}
+
+// Prospect is a domain being approached for a link to the target. The
+// backlink figures are the domain's own and are nil until it's enriched.
+type Prospect struct {
+	ID               uuid.UUID  `json:"id"`
+	Target           string     `json:"target"`
+	Domain           string     `json:"domain"`
+	Status           string     `json:"status"`
+	Source           string     `json:"source"`
+	Competitors      []string   `json:"competitors"` // the competitors it links to, for competitor gap prospects
+	Contact          string     `json:"contact"`
+	Notes            string     `json:"notes"`
+	Rank             *int32     `json:"rank"`
+	Backlinks        *int64     `json:"backlinks"`
+	ReferringDomains *int32     `json:"referringDomains"`
+	SpamScore        *int32     `json:"spamScore"`
+	EnrichedAt       *time.Time `json:"enrichedAt"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+	ContactedAt      *time.Time `json:"contactedAt"`
+	LinkedAt         *time.Time `json:"linkedAt"`
+}
+
+// ProspectImport reports what an import did with each domain it was given.
+// AlreadyLinking domains already link to the target and weren't added;
+// CheckedLinking is false when that couldn't be checked because backlink
+// data isn't configured.
+type ProspectImport struct {
+	Added          int      `json:"added"`
+	Duplicates     int      `json:"duplicates"` // already prospects for the target
+	AlreadyLinking []string `json:"alreadyLinking"`
+	Invalid        []string `json:"invalid"`
+	CheckedLinking bool     `json:"checkedLinking"`
+}
+
+// ProspectEnrichment totals one enrichment request. Remaining is true when
+// more prospects are waiting for a backlink summary.
+type ProspectEnrichment struct {
+	Enriched  int  `json:"enriched"`
+	Failed    int  `json:"failed"`
+	Remaining bool `json:"remaining"`
+}
+
+func newProspect(p query.SeoBacklinkProspect) Prospect {
+	prospect := Prospect{
+		ID:          p.ID,
+		Target:      p.Target,
+		Domain:      p.Domain,
+		Status:      p.Status,
+		Source:      p.Source,
+		Competitors: p.Competitors,
+		Contact:     p.Contact,
+		Notes:       p.Notes,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+	if prospect.Competitors == nil {
+		prospect.Competitors = []string{}
+	}
+	if p.Rank.Valid {
+		prospect.Rank = &p.Rank.Int32
+	}
+	if p.Backlinks.Valid {
+		prospect.Backlinks = &p.Backlinks.Int64
+	}
+	if p.ReferringDomains.Valid {
+		prospect.ReferringDomains = &p.ReferringDomains.Int32
+	}
+	if p.SpamScore.Valid {
+		prospect.SpamScore = &p.SpamScore.Int32
+	}
+	if p.EnrichedAt.Valid {
+		prospect.EnrichedAt = &p.EnrichedAt.Time
+	}
+	if p.ContactedAt.Valid {
+		prospect.ContactedAt = &p.ContactedAt.Time
+	}
+	if p.LinkedAt.Valid {
+		prospect.LinkedAt = &p.LinkedAt.Time
+	}
+	return prospect
+}
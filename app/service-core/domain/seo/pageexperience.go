@@ -0,0 +1,611 @@
+package seo
+
+import (
+	"app/pkg"
+	"app/pkg/ids"
+	"app/pkg/pagespeed"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"slices"
+	"time"
+
+	"service-core/domain/locks"
+	"service-core/domain/membership"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxPageExperiencePages is the most pages one request assesses. PageSpeed
+	// takes up to a minute a page and the pages run one after another.
+	MaxPageExperiencePages = 50
+	// MaxPageExperienceTrend is the most assessments a trend returns.
+	MaxPageExperienceTrend = 100
+)
+
+// Page experience signals, as named in Signal.Name.
+const (
+	SignalLCP             = "lcp"
+	SignalINP             = "inp"
+	SignalCLS             = "cls"
+	SignalHTTPS           = "https"
+	SignalMobileFriendly  = "mobile_friendly"
+	SignalNoInterstitials = "no_intrusive_interstitials"
+)
+
+// Signal outcomes.
+const (
+	SignalPass    = "pass"
+	SignalFail    = "fail"
+	SignalUnknown = "unknown" // not measured; left out of the score
+)
+
+// Where a signal's value came from.
+const (
+	SourceField  = "field"  // Chrome UX Report data for the page
+	SourceOrigin = "origin" // Chrome UX Report data for the whole origin
+	SourceLab    = "lab"    // the Lighthouse run
+)
+
+// Signal is one scored page experience signal. Core Web Vitals carry their
+// p75 (field) or lab value and Google's thresholds; they pass when good.
+type Signal struct {
+	Name   string   `json:"name"`
+	Status string   `json:"status"`
+	Rating string   `json:"rating,omitempty"` // good, needs-improvement or poor; Core Web Vitals only
+	Value  *float64 `json:"value,omitempty"`  // ms for LCP and INP, unitless for CLS
+	Good   float64  `json:"good,omitempty"`   // the value must be at most this to be good
+	Poor   float64  `json:"poor,omitempty"`   // and is poor above this
+	Source string   `json:"source,omitempty"`
+	// Proxy names the lab metric standing in for the signal: Lighthouse
+	// can't measure INP, so the lab assessment uses Total Blocking Time
+	Proxy  string `json:"proxy,omitempty"`
+	Points int    `json:"points"`
+	Max    int    `json:"max"`
+}
+
+// vital is a Core Web Vital with Google's good and poor boundaries, and the
+// pagespeed metrics it's read from.
+type vital struct {
+	name       string
+	field, lab string // pagespeed metric names
+	good, poor float64
+	// labGood and labPoor replace good and poor for a proxy lab metric
+	labGood, labPoor float64
+}
+
+// The Core Web Vitals, see https://web.dev/articles/vitals. TBT's thresholds
+// are Lighthouse's.
+var vitals = []vital{
+	{name: SignalLCP, field: "LCP", lab: "LCP", good: 2500, poor: 4000},
+	{name: SignalINP, field: "INP", lab: "TBT", good: 200, poor: 500, labGood: 200, labPoor: 600},
+	{name: SignalCLS, field: "CLS", lab: "CLS", good: 0.1, poor: 0.25},
+}
+
+// Points per signal. The Core Web Vitals score half their points when they
+// need improvement.
+const (
+	vitalPoints          = 20
+	httpsPoints          = 15
+	mobileFriendlyPoints = 15
+	interstitialPoints   = 10
+)
+
+// assessment is a scored page, ready to store.
+type assessment struct {
+	score     int32
+	passed    bool
+	cwvSource string
+	lcp       *float64
+	inp       *float64 // nil when only the lab proxy was available
+	cls       *float64
+	signals   []Signal
+}
+
+// assessPageExperience scores a PageSpeed result and the caller's on-page
+// checks. The Core Web Vitals come from field data when the Chrome UX Report
+// has the page or its origin, falling back to the lab run per metric. The
+// page passes when every measured signal passes.
+func assessPageExperience(result *pagespeed.Result, interstitial *bool) assessment {
+	a := assessment{cwvSource: SourceLab}
+	if result.Field != nil {
+		a.cwvSource = SourceField
+		if result.Field.Origin {
+			a.cwvSource = SourceOrigin
+		}
+	}
+
+	for _, v := range vitals {
+		s := assessVital(v, result, a.cwvSource)
+		a.signals = append(a.signals, s)
+		switch v.name {
+		case SignalLCP:
+			a.lcp = s.Value
+		case SignalINP:
+			if s.Proxy == "" {
+				a.inp = s.Value
+			}
+		case SignalCLS:
+			a.cls = s.Value
+		}
+	}
+
+	check := func(name string, ok *bool, points int) Signal {
+		s := Signal{Name: name, Status: SignalUnknown, Max: points}
+		if ok != nil {
+			s.Status = SignalFail
+			if *ok {
+				s.Status, s.Points = SignalPass, points
+			}
+		}
+		return s
+	}
+	a.signals = append(a.signals,
+		check(SignalHTTPS, lighthouseCheck(result, "is-on-https"), httpsPoints),
+		check(SignalMobileFriendly, lighthouseCheck(result, "viewport"), mobileFriendlyPoints),
+	)
+	var noInterstitial *bool
+	if interstitial != nil {
+		noInterstitial = new(bool)
+		*noInterstitial = !*interstitial
+	}
+	a.signals = append(a.signals, check(SignalNoInterstitials, noInterstitial, interstitialPoints))
+
+	var points, maxPoints int
+	a.passed = true
+	for _, s := range a.signals {
+		if s.Status == SignalUnknown {
+			continue
+		}
+		points += s.Points
+		maxPoints += s.Max
+		if s.Status == SignalFail {
+			a.passed = false
+		}
+	}
+	if maxPoints == 0 {
+		a.passed = false
+	} else {
+		a.score = int32(math.Round(100 * float64(points) / float64(maxPoints)))
+	}
+	return a
+}
+
+// assessVital rates one Core Web Vital from field data when source has it,
+// else from the lab run.
+func assessVital(v vital, result *pagespeed.Result, source string) Signal {
+	s := Signal{Name: v.name, Status: SignalUnknown, Good: v.good, Poor: v.poor, Max: vitalPoints}
+
+	if source != SourceLab {
+		if m, ok := result.Field.Metrics[v.field]; ok {
+			value := m.Percentile
+			s.Value, s.Source = &value, source
+		}
+	}
+	if s.Value == nil {
+		m, ok := result.Metrics[v.lab]
+		if !ok || m.Numeric == nil {
+			return s
+		}
+		value := *m.Numeric
+		s.Value, s.Source = &value, SourceLab
+		if v.lab != v.field {
+			s.Proxy, s.Good, s.Poor = v.lab, v.labGood, v.labPoor
+		}
+	}
+
+	switch {
+	case *s.Value <= s.Good:
+		s.Status, s.Rating, s.Points = SignalPass, "good", vitalPoints
+	case *s.Value <= s.Poor:
+		s.Status, s.Rating, s.Points = SignalFail, "needs-improvement", vitalPoints/2
+	default:
+		s.Status, s.Rating = SignalFail, "poor"
+	}
+	return s
+}
+
+func lighthouseCheck(result *pagespeed.Result, auditID string) *bool {
+	ok, found := result.Checks[auditID]
+	if !found {
+		return nil
+	}
+	return &ok
+}
+
+// StartPageExperienceAudit queues a PageSpeed run for each page and returns
+// the pending pages; poll GetPageExperienceAudit for the scores. Only
+// organisation owners and admins can start audits, since they draw on the
+// shared PageSpeed quota. Pages can't be added to an audit while it runs.
+func (s *Service) StartPageExperienceAudit(ctx context.Context, userID uuid.UUID, req PageExperienceRequest) (*PageExperienceAudit, error) {
+	if s.pageSpeed == nil {
+		return nil, pkg.BadRequestError{Message: "Page experience audits are not configured"}
+	}
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = StrategyMobile
+	}
+	if strategy != StrategyMobile && strategy != StrategyDesktop {
+		return nil, pkg.BadRequestError{Message: "strategy must be mobile or desktop"}
+	}
+	if len(req.Pages) == 0 {
+		return nil, pkg.BadRequestError{Message: "At least one page is required"}
+	}
+	if len(req.Pages) > MaxPageExperiencePages {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("An audit can include at most %d pages per request", MaxPageExperiencePages)}
+	}
+	pages := make([]PageInput, 0, len(req.Pages))
+	for _, p := range req.Pages {
+		pageURL, err := normalisePageURL(p.URL)
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(pages, func(q PageInput) bool { return q.URL == pageURL }) {
+			continue
+		}
+		pages = append(pages, PageInput{URL: pageURL, IntrusiveInterstitial: p.IntrusiveInterstitial})
+	}
+
+	return s.startAudit(ctx, req.OrganisationID, uuid.NullUUID{UUID: userID, Valid: true}, req.AuditID, strategy, pages)
+}
+
+// startAudit creates the pending pages of an audit, a new one when auditID
+// is nil, and queues a job assessing them. The pages must already be
+// normalised.
+func (s *Service) startAudit(ctx context.Context, organisationID uuid.UUID, createdBy uuid.NullUUID, auditID uuid.UUID, strategy string, pages []PageInput) (*PageExperienceAudit, error) {
+	if auditID == uuid.Nil {
+		auditID = ids.New()
+	}
+	lock, err := s.lockAudit(ctx, organisationID, auditID)
+	if err != nil {
+		return nil, err
+	}
+	audit := &PageExperienceAudit{AuditID: auditID, Pages: make([]PageExperience, 0, len(pages))}
+	for _, p := range pages {
+		params := query.CreateSeoPageExperienceParams{
+			OrganisationID: organisationID,
+			AuditID:        auditID,
+			PageUrl:        p.URL,
+			Strategy:       strategy,
+			CreatedBy:      createdBy,
+		}
+		if p.IntrusiveInterstitial != nil {
+			params.IntrusiveInterstitial = sql.NullBool{Bool: *p.IntrusiveInterstitial, Valid: true}
+		}
+		row, err := s.store.CreateSeoPageExperience(ctx, params)
+		if err != nil {
+			releaseAudit(lock)
+			return nil, pkg.InternalError{Message: "Error creating page experience audit", Err: err}
+		}
+		page, err := newPageExperience(row)
+		if err != nil {
+			releaseAudit(lock)
+			return nil, pkg.InternalError{Message: "Error reading page experience", Err: err}
+		}
+		audit.Pages = append(audit.Pages, page)
+	}
+	// The job takes the lease again when it runs
+	releaseAudit(lock)
+	if err := s.queueAudit(ctx, organisationID, auditID); err != nil {
+		return nil, err
+	}
+
+	audit.summarise()
+	return audit, nil
+}
+
+// ResumePageExperienceAudit re-runs the pages of an audit that failed or were
+// interrupted, keeping the completed ones, and returns the audit with those
+// pages pending again. An audit with nothing left to run is returned as is.
+func (s *Service) ResumePageExperienceAudit(ctx context.Context, userID, organisationID, auditID uuid.UUID) (*PageExperienceAudit, error) {
+	if s.pageSpeed == nil {
+		return nil, pkg.BadRequestError{Message: "Page experience audits are not configured"}
+	}
+	if err := membership.RequireAdmin(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	lock, err := s.lockAudit(ctx, organisationID, auditID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.store.ListSeoPageExperienceByAudit(ctx, query.ListSeoPageExperienceByAuditParams{
+		OrganisationID: organisationID,
+		AuditID:        auditID,
+	})
+	if err != nil {
+		releaseAudit(lock)
+		return nil, pkg.InternalError{Message: "Error loading page experience audit", Err: err}
+	}
+	if len(rows) == 0 {
+		releaseAudit(lock)
+		return nil, pkg.NotFoundError{Message: "Page experience audit not found"}
+	}
+
+	audit := &PageExperienceAudit{AuditID: auditID, Pages: make([]PageExperience, 0, len(rows))}
+	var resumed []query.SeoPageExperience
+	for _, row := range rows {
+		if row.Status != StatusCompleted {
+			row, err = s.store.ResetSeoPageExperience(ctx, row.ID)
+			if err != nil {
+				releaseAudit(lock)
+				return nil, pkg.InternalError{Message: "Error resuming page experience audit", Err: err}
+			}
+			resumed = append(resumed, row)
+		}
+		page, err := newPageExperience(row)
+		if err != nil {
+			releaseAudit(lock)
+			return nil, pkg.InternalError{Message: "Error reading page experience", Err: err}
+		}
+		audit.Pages = append(audit.Pages, page)
+	}
+
+	releaseAudit(lock)
+	if len(resumed) > 0 {
+		if err := s.queueAudit(ctx, organisationID, auditID); err != nil {
+			return nil, err
+		}
+	}
+	audit.summarise()
+	return audit, nil
+}
+
+// GetPageExperienceAudit returns the pages of an audit to any member of the
+// organisation.
+func (s *Service) GetPageExperienceAudit(ctx context.Context, userID, organisationID, auditID uuid.UUID) (*PageExperienceAudit, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+
+	return s.loadAudit(ctx, organisationID, auditID)
+}
+
+// loadAudit reads an audit's pages, marking pending pages whose runner went
+// away as failed.
+func (s *Service) loadAudit(ctx context.Context, organisationID, auditID uuid.UUID) (*PageExperienceAudit, error) {
+	rows, err := s.store.ListSeoPageExperienceByAudit(ctx, query.ListSeoPageExperienceByAuditParams{
+		OrganisationID: organisationID,
+		AuditID:        auditID,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading page experience audit", Err: err}
+	}
+	if len(rows) == 0 {
+		return nil, pkg.NotFoundError{Message: "Page experience audit not found"}
+	}
+
+	// Pending pages without a runner holding the audit's lease, and no longer
+	// waiting for their job to start, were left behind by a replica that went
+	// away
+	interrupted := false
+	if slices.ContainsFunc(rows, func(row query.SeoPageExperience) bool { return row.Status == StatusPending }) {
+		running, err := s.locks.IsHeld(ctx, auditLockName(organisationID, auditID))
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error loading page experience audit", Err: err}
+		}
+		interrupted = !running
+	}
+
+	audit := &PageExperienceAudit{AuditID: auditID, Pages: make([]PageExperience, 0, len(rows))}
+	for _, row := range rows {
+		page, err := newPageExperience(row)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error reading page experience", Err: err}
+		}
+		if page.Status == StatusPending && interrupted && !queued(row) {
+			page.Status = StatusFailed
+			page.Error = "The audit was interrupted; resume it to assess this page"
+		}
+		audit.Pages = append(audit.Pages, page)
+	}
+	audit.summarise()
+	return audit, nil
+}
+
+// PageExperienceTrend returns the completed assessments of a page, oldest
+// first, up to limit (default and at most MaxPageExperienceTrend).
+func (s *Service) PageExperienceTrend(ctx context.Context, userID, organisationID uuid.UUID, rawURL, strategy string, limit int) ([]PageExperienceTrendPoint, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	pageURL, err := normalisePageURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if strategy == "" {
+		strategy = StrategyMobile
+	}
+	if limit <= 0 || limit > MaxPageExperienceTrend {
+		limit = MaxPageExperienceTrend
+	}
+
+	rows, err := s.store.ListSeoPageExperienceTrend(ctx, query.ListSeoPageExperienceTrendParams{
+		OrganisationID: organisationID,
+		PageUrl:        pageURL,
+		Strategy:       strategy,
+		Limit:          int32(limit),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading page experience trend", Err: err}
+	}
+
+	points := make([]PageExperienceTrendPoint, len(rows))
+	for i, row := range rows {
+		// Newest first from the query
+		points[len(rows)-1-i] = PageExperienceTrendPoint{
+			AuditID:    row.AuditID,
+			Score:      row.Score,
+			Passed:     row.Passed,
+			CWVSource:  row.CwvSource,
+			LCPMs:      nullFloat(row.LcpMs),
+			INPMs:      nullFloat(row.InpMs),
+			CLS:        nullFloat(row.Cls),
+			MeasuredAt: row.CompletedAt.Time,
+		}
+	}
+	return points, nil
+}
+
+// queueAudit queues a job assessing the audit's pending pages.
+func (s *Service) queueAudit(ctx context.Context, organisationID, auditID uuid.UUID) error {
+	_, err := s.jobs.Enqueue(ctx, jobPageExperienceAudit, pageExperienceAuditJob{
+		OrganisationID: organisationID,
+		AuditID:        auditID,
+	})
+	if err != nil {
+		return pkg.InternalError{Message: "Error starting page experience audit", Err: err}
+	}
+	return nil
+}
+
+// runPageExperienceAudit runs PageSpeed for each page in turn and stores each
+// scored result as soon as it's in, so a run that dies part way keeps the
+// pages it finished and can be resumed from the rest. It holds the audit's
+// lease, renewed per page, and runs as a job that outlives the request that
+// started it.
+func (s *Service) runPageExperienceAudit(ctx context.Context, lock *locks.Lock, auditID uuid.UUID, rows []query.SeoPageExperience) {
+	defer releaseAudit(lock)
+	ctx, cancel := context.WithTimeout(ctx, pageExperienceTimeout(len(rows)))
+	defer cancel()
+
+	var failed int
+	for _, row := range rows {
+		if err := lock.Extend(context.Background(), auditLease); err != nil {
+			// Another runner may have taken over; leave the rest to it
+			slog.Error("Lost page experience audit lease", "auditID", auditID, "error", err)
+			return
+		}
+		params := s.assessPage(ctx, row)
+		if params.Status == StatusFailed {
+			failed++
+		}
+		// ctx may have run out during the PageSpeed run
+		if err := s.store.CompleteSeoPageExperience(context.Background(), params); err != nil {
+			slog.Error("Failed to save page experience", "auditID", auditID, "url", row.PageUrl, "error", err)
+		}
+	}
+	slog.Info("Page experience audit finished", "auditID", auditID, "organisationID", rows[0].OrganisationID, "pages", len(rows), "failed", failed)
+	s.publish(context.Background(), rows[0].OrganisationID, EventAuditCompleted, map[string]any{
+		"auditId": auditID,
+		"pages":   len(rows),
+		"failed":  failed,
+	})
+}
+
+// assessPage runs PageSpeed and the on-page checks for one page and scores it.
+func (s *Service) assessPage(ctx context.Context, row query.SeoPageExperience) query.CompleteSeoPageExperienceParams {
+	params := query.CompleteSeoPageExperienceParams{
+		ID:        row.ID,
+		Status:    StatusFailed,
+		Signals:   json.RawMessage("[]"),
+		Pagespeed: json.RawMessage("{}"),
+		Issues:    row.Issues,
+	}
+	if ctx.Err() != nil {
+		params.Error = "The audit did not finish in time"
+		return params
+	}
+	params.Issues = s.checkPageIssues(ctx, row)
+
+	pageCtx, cancel := context.WithTimeout(ctx, pageSpeedTimeout)
+	defer cancel()
+	result, err := s.pageSpeed.Run(pageCtx, row.PageUrl, row.Strategy)
+	if err != nil {
+		slog.Warn("PageSpeed run failed", "url", row.PageUrl, "strategy", row.Strategy, "error", err)
+		params.Error = "PageSpeed could not assess the page"
+		if pageCtx.Err() != nil {
+			params.Error = "PageSpeed did not finish in time"
+		}
+		return params
+	}
+
+	var interstitial *bool
+	if row.IntrusiveInterstitial.Valid {
+		interstitial = &row.IntrusiveInterstitial.Bool
+	}
+	a := assessPageExperience(result, interstitial)
+
+	signals, err := json.Marshal(a.signals)
+	if err != nil {
+		params.Error = "The results could not be stored"
+		return params
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		params.Error = "The results could not be stored"
+		return params
+	}
+	params.Status = StatusCompleted
+	params.Score = a.score
+	params.Passed = a.passed
+	params.CwvSource = a.cwvSource
+	params.LcpMs = sqlFloat(a.lcp)
+	params.InpMs = sqlFloat(a.inp)
+	params.Cls = sqlFloat(a.cls)
+	params.Signals = signals
+	params.Pagespeed = raw
+	return params
+}
+
+// pageExperienceTimeout bounds an audit of n pages run one after another.
+func pageExperienceTimeout(n int) time.Duration {
+	return time.Duration(n) * pageSpeedTimeout
+}
+
+// lockAudit takes the lease that marks an audit as running, so only one
+// runner assesses its pages at a time.
+func (s *Service) lockAudit(ctx context.Context, organisationID, auditID uuid.UUID) (*locks.Lock, error) {
+	lock, err := s.locks.TryAcquire(ctx, auditLockName(organisationID, auditID), auditLease)
+	if errors.Is(err, locks.ErrLockHeld) {
+		return nil, pkg.BadRequestError{Message: "This audit is still running; wait for it to finish"}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error locking page experience audit", Err: err}
+	}
+	return lock, nil
+}
+
+// queued reports whether a pending page may still be waiting for its job to
+// start.
+func queued(row query.SeoPageExperience) bool {
+	return row.Status == StatusPending && time.Since(row.CreatedAt) < auditQueueWait
+}
+
+func releaseAudit(lock *locks.Lock) {
+	if err := lock.Release(context.Background()); err != nil {
+		slog.Error("Failed to release page experience audit lease", "lock", lock.Name, "error", err)
+	}
+}
+
+func auditLockName(organisationID, auditID uuid.UUID) string {
+	return "seo-page-experience:" + organisationID.String() + ":" + auditID.String()
+}
+
+// normalisePageURL accepts an absolute http(s) URL and drops its fragment.
+func normalisePageURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", pkg.BadRequestError{Message: "Page URLs must be absolute http or https URLs"}
+	}
+	u.Fragment = ""
+	return u.String(), nil
+}
+
+func sqlFloat(f *float64) sql.NullFloat64 {
+	if f == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *f, Valid: true}
+}
@@ -0,0 +1,563 @@
+package seo
+
+import (
+	"app/pkg"
+	"app/pkg/dataforseo"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"service-core/domain/membership"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxProspectsPerTarget is how many prospects an organisation can track
+	// for one of its sites.
+	maxProspectsPerTarget = 5000
+	// MaxProspectImport is the most domains one import adds, by hand, CSV or
+	// competitor gap.
+	MaxProspectImport = 1000
+	// maxGapCompetitors is how many competitors a gap import compares.
+	maxGapCompetitors = 5
+	// defaultGapLimit is how many gap prospects an import adds by default.
+	defaultGapLimit = 100
+	// maxReferringDomains is how many of a site's referring domains are
+	// looked up: a single request's worth, strongest first. Prospects are
+	// checked against these, so a weak existing link to a site with more
+	// referring domains than this can go unnoticed.
+	maxReferringDomains = 1000
+	// maxProspectEnrich is how many backlink summaries one enrichment request
+	// looks up; each is billed on its own.
+	maxProspectEnrich = 25
+)
+
+var prospectStatuses = []string{ProspectIdentified, ProspectContacted, ProspectLinked}
+
+// prospectInput is one domain to add as a prospect.
+type prospectInput struct {
+	domain      string
+	status      string
+	contact     string
+	notes       string
+	competitors []string
+}
+
+// AddProspects adds prospect domains by hand. Domains already linking to the
+// target are left out when backlink data is configured. Only organisation
+// owners and admins can add prospects, since that lookup is billed.
+func (s *Service) AddProspects(ctx context.Context, userID uuid.UUID, req ProspectRequest) (*ProspectImport, error) {
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+	target, err := NormaliseDomain(req.Target)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Domains) == 0 {
+		return nil, pkg.BadRequestError{Message: "At least one domain is required"}
+	}
+	if len(req.Domains) > MaxProspectImport {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("At most %d domains can be added at once", MaxProspectImport)}
+	}
+
+	inputs := make([]prospectInput, len(req.Domains))
+	for i, d := range req.Domains {
+		inputs[i] = prospectInput{domain: d, status: ProspectIdentified, contact: req.Contact, notes: req.Notes}
+	}
+	return s.importProspects(ctx, userID, req.OrganisationID, target, ProspectSourceManual, inputs, nil)
+}
+
+// ImportProspectsCSV adds the prospects in a CSV file. The file needs a
+// header row with a domain column; status, contact and notes columns are
+// optional, so a file from ExportProspectsCSV imports as it is. Other
+// columns are ignored.
+func (s *Service) ImportProspectsCSV(ctx context.Context, userID, organisationID uuid.UUID, target string, r io.Reader) (*ProspectImport, error) {
+	if err := membership.RequireAdmin(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	target, err := NormaliseDomain(target)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := parseProspectsCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.importProspects(ctx, userID, organisationID, target, ProspectSourceCSV, inputs, nil)
+}
+
+func parseProspectsCSV(r io.Reader) ([]prospectInput, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "The CSV file needs a header row"}
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["domain"]; !ok {
+		return nil, pkg.BadRequestError{Message: "The CSV file needs a domain column"}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var inputs []prospectInput
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("Invalid CSV: %v", err)}
+		}
+		in := prospectInput{
+			domain:  field(record, "domain"),
+			status:  strings.ToLower(field(record, "status")),
+			contact: field(record, "contact"),
+			notes:   field(record, "notes"),
+		}
+		if in.domain == "" {
+			continue
+		}
+		if in.status == "" {
+			in.status = ProspectIdentified
+		}
+		if !slices.Contains(prospectStatuses, in.status) {
+			line, _ := cr.FieldPos(0)
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("Line %d: status must be identified, contacted or linked", line)}
+		}
+		if len(inputs) == MaxProspectImport {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("At most %d domains can be imported at once", MaxProspectImport)}
+		}
+		inputs = append(inputs, in)
+	}
+	if len(inputs) == 0 {
+		return nil, pkg.BadRequestError{Message: "The CSV file has no domains"}
+	}
+	return inputs, nil
+}
+
+// ImportGapProspects adds the domains that link to any of the competitors
+// but not to the target, those linking to the most competitors first and
+// then the strongest. Only organisation owners and admins can run it, since
+// the lookups are billed.
+func (s *Service) ImportGapProspects(ctx context.Context, userID uuid.UUID, req ProspectGapRequest) (*ProspectImport, error) {
+	if s.backlinks == nil {
+		return nil, pkg.BadRequestError{Message: "Backlink reports are not configured"}
+	}
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+	target, err := NormaliseDomain(req.Target)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Competitors) == 0 || len(req.Competitors) > maxGapCompetitors {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("Between 1 and %d competitors are required", maxGapCompetitors)}
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultGapLimit
+	}
+	if limit > MaxProspectImport {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("limit must be at most %d", MaxProspectImport)}
+	}
+	var competitors []string
+	for _, raw := range req.Competitors {
+		c, err := NormaliseDomain(raw)
+		if err != nil {
+			return nil, err
+		}
+		if c != target && !slices.Contains(competitors, c) {
+			competitors = append(competitors, c)
+		}
+	}
+	if len(competitors) == 0 {
+		return nil, pkg.BadRequestError{Message: "Competitors must differ from the target"}
+	}
+	excluded := map[string]bool{prospectDomain(target): true}
+	for _, c := range competitors {
+		excluded[prospectDomain(c)] = true
+	}
+
+	ctx = dataforseo.WithCostAttribution(ctx, req.OrganisationID.String())
+	linking, err := s.referringDomains(ctx, req.OrganisationID, target)
+	if err != nil {
+		return nil, err
+	}
+	type gap struct {
+		domain      string
+		rank        int
+		competitors []string
+	}
+	gaps := make(map[string]*gap)
+	for _, c := range competitors {
+		domains, err := s.backlinks.GetReferringDomains(ctx, c, maxReferringDomains, 0)
+		if err != nil {
+			return nil, lookupError(req.OrganisationID, c, err)
+		}
+		for _, rd := range domains {
+			d := prospectDomain(rd.Domain)
+			if d == "" || excluded[d] || linking[d] {
+				continue
+			}
+			g, ok := gaps[d]
+			if !ok {
+				g = &gap{domain: d}
+				gaps[d] = g
+			}
+			if !slices.Contains(g.competitors, c) {
+				g.competitors = append(g.competitors, c)
+			}
+			g.rank = max(g.rank, rd.Rank)
+		}
+	}
+
+	sorted := make([]*gap, 0, len(gaps))
+	for _, g := range gaps {
+		sorted = append(sorted, g)
+	}
+	slices.SortFunc(sorted, func(a, b *gap) int {
+		if n := len(b.competitors) - len(a.competitors); n != 0 {
+			return n
+		}
+		if n := b.rank - a.rank; n != 0 {
+			return n
+		}
+		return strings.Compare(a.domain, b.domain)
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	inputs := make([]prospectInput, len(sorted))
+	for i, g := range sorted {
+		inputs[i] = prospectInput{domain: g.domain, status: ProspectIdentified, competitors: g.competitors}
+	}
+	return s.importProspects(ctx, userID, req.OrganisationID, target, ProspectSourceCompetitorGap, inputs, linking)
+}
+
+// importProspects adds prospects for the target, skipping invalid domains,
+// those already tracked and those already linking to it. linking is the
+// target's referring domains; nil looks them up when backlink data is
+// configured.
+func (s *Service) importProspects(ctx context.Context, userID, organisationID uuid.UUID, target, source string, inputs []prospectInput, linking map[string]bool) (*ProspectImport, error) {
+	result := &ProspectImport{AlreadyLinking: []string{}, Invalid: []string{}}
+	if linking == nil && s.backlinks != nil {
+		var err error
+		ctx = dataforseo.WithCostAttribution(ctx, organisationID.String())
+		if linking, err = s.referringDomains(ctx, organisationID, target); err != nil {
+			return nil, err
+		}
+	}
+	result.CheckedLinking = linking != nil
+
+	count, err := s.store.CountSeoBacklinkProspects(ctx, query.CountSeoBacklinkProspectsParams{OrganisationID: organisationID, Target: target})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting prospects", Err: err}
+	}
+	seen := make(map[string]bool, len(inputs))
+	for _, in := range inputs {
+		domain, err := NormaliseDomain(in.domain)
+		if err != nil || prospectDomain(domain) == prospectDomain(target) {
+			result.Invalid = append(result.Invalid, in.domain)
+			continue
+		}
+		domain = prospectDomain(domain)
+		if seen[domain] {
+			result.Duplicates++
+			continue
+		}
+		seen[domain] = true
+		if linking[domain] {
+			result.AlreadyLinking = append(result.AlreadyLinking, domain)
+			continue
+		}
+		if count >= maxProspectsPerTarget {
+			return nil, pkg.BadRequestError{Message: fmt.Sprintf("A site can have at most %d prospects; %d were added before the limit was reached", maxProspectsPerTarget, result.Added)}
+		}
+
+		competitors := in.competitors
+		if competitors == nil {
+			competitors = []string{}
+		}
+		n, err := s.store.CreateSeoBacklinkProspect(ctx, query.CreateSeoBacklinkProspectParams{
+			OrganisationID: organisationID,
+			Target:         target,
+			Domain:         domain,
+			Status:         in.status,
+			Source:         source,
+			Competitors:    competitors,
+			Contact:        in.contact,
+			Notes:          in.notes,
+			CreatedBy:      uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error saving prospect", Err: err}
+		}
+		if n == 0 {
+			result.Duplicates++
+			continue
+		}
+		result.Added++
+		count++
+	}
+	slog.Info("Backlink prospects imported", "organisationID", organisationID, "target", target, "source", source,
+		"added", result.Added, "duplicates", result.Duplicates, "alreadyLinking", len(result.AlreadyLinking), "invalid", len(result.Invalid))
+	return result, nil
+}
+
+// referringDomains returns the set of domains linking to the target, keyed
+// by prospectDomain.
+func (s *Service) referringDomains(ctx context.Context, organisationID uuid.UUID, target string) (map[string]bool, error) {
+	domains, err := s.backlinks.GetReferringDomains(ctx, target, maxReferringDomains, 0)
+	if err != nil {
+		return nil, lookupError(organisationID, target, err)
+	}
+	linking := make(map[string]bool, len(domains))
+	for _, rd := range domains {
+		linking[prospectDomain(rd.Domain)] = true
+	}
+	return linking, nil
+}
+
+func lookupError(organisationID uuid.UUID, domain string, err error) error {
+	slog.Error("Referring domain lookup failed", "organisationID", organisationID, "domain", domain, "error", err)
+	if errors.Is(err, dataforseo.ErrBudgetExceeded) {
+		return pkg.BadRequestError{Message: "The monthly SEO budget has been reached"}
+	}
+	return pkg.InternalError{Message: "Error looking up referring domains", Err: err}
+}
+
+// prospectDomain is the form prospects are stored and compared in: lowercase
+// and without a leading www.
+func prospectDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+}
+
+// ListProspects returns the target's prospects, optionally only those with
+// one status, to any member of the organisation.
+func (s *Service) ListProspects(ctx context.Context, userID, organisationID uuid.UUID, target, status string, limit, offset int32) ([]Prospect, error) {
+	rows, err := s.listProspects(ctx, userID, organisationID, target, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	prospects := make([]Prospect, len(rows))
+	for i, row := range rows {
+		prospects[i] = newProspect(row)
+	}
+	return prospects, nil
+}
+
+func (s *Service) listProspects(ctx context.Context, userID, organisationID uuid.UUID, target, status string, limit, offset int32) ([]query.SeoBacklinkProspect, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	target, err := NormaliseDomain(target)
+	if err != nil {
+		return nil, err
+	}
+	if status != "" && !slices.Contains(prospectStatuses, status) {
+		return nil, pkg.BadRequestError{Message: "status must be identified, contacted or linked"}
+	}
+	rows, err := s.store.ListSeoBacklinkProspects(ctx, query.ListSeoBacklinkProspectsParams{
+		OrganisationID: organisationID,
+		Target:         target,
+		Status:         status,
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading prospects", Err: err}
+	}
+	return rows, nil
+}
+
+// prospectsCSVHeader is the layout of the CSV export. Its domain, status,
+// contact and notes columns are the ones ImportProspectsCSV reads.
+var prospectsCSVHeader = []string{"domain", "status", "source", "competitors", "contact", "notes", "rank", "backlinks", "referring_domains", "spam_score", "created_at", "contacted_at", "linked_at"}
+
+// ExportProspectsCSV renders the target's prospects as CSV for any member
+// of the organisation, returning the file and a name for it.
+func (s *Service) ExportProspectsCSV(ctx context.Context, userID, organisationID uuid.UUID, target, status string) ([]byte, string, error) {
+	rows, err := s.listProspects(ctx, userID, organisationID, target, status, maxProspectsPerTarget, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	records := [][]string{prospectsCSVHeader}
+	for _, p := range rows {
+		records = append(records, []string{
+			p.Domain,
+			p.Status,
+			p.Source,
+			strings.Join(p.Competitors, " "),
+			p.Contact,
+			p.Notes,
+			nullInt(p.Rank.Int32, p.Rank.Valid),
+			nullInt(p.Backlinks.Int64, p.Backlinks.Valid),
+			nullInt(p.ReferringDomains.Int32, p.ReferringDomains.Valid),
+			nullInt(p.SpamScore.Int32, p.SpamScore.Valid),
+			p.CreatedAt.UTC().Format(time.RFC3339),
+			nullTime(p.ContactedAt),
+			nullTime(p.LinkedAt),
+		})
+	}
+	if err := w.WriteAll(records); err != nil {
+		return nil, "", pkg.InternalError{Message: "Error writing prospects", Err: err}
+	}
+	target, _ = NormaliseDomain(target)
+	return buf.Bytes(), fmt.Sprintf("prospects-%s-%s.csv", target, time.Now().UTC().Format("2006-01-02")), nil
+}
+
+func nullInt[T int32 | int64](v T, valid bool) string {
+	if !valid {
+		return ""
+	}
+	return strconv.FormatInt(int64(v), 10)
+}
+
+func nullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+// UpdateProspect moves a prospect along the pipeline or edits its contact
+// and notes, for any member of the organisation. Moving a prospect to
+// contacted or linked records when it first got there; moving it back
+// clears that.
+func (s *Service) UpdateProspect(ctx context.Context, userID, prospectID uuid.UUID, req ProspectUpdate) (*Prospect, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+	if req.Status != nil && !slices.Contains(prospectStatuses, *req.Status) {
+		return nil, pkg.BadRequestError{Message: "status must be identified, contacted or linked"}
+	}
+
+	params := query.UpdateSeoBacklinkProspectParams{ID: prospectID, OrganisationID: req.OrganisationID}
+	if req.Status != nil {
+		params.Status = sql.NullString{String: *req.Status, Valid: true}
+	}
+	if req.Contact != nil {
+		params.Contact = sql.NullString{String: strings.TrimSpace(*req.Contact), Valid: true}
+	}
+	if req.Notes != nil {
+		params.Notes = sql.NullString{String: *req.Notes, Valid: true}
+	}
+	row, err := s.store.UpdateSeoBacklinkProspect(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Prospect not found"}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating prospect", Err: err}
+	}
+	prospect := newProspect(row)
+	return &prospect, nil
+}
+
+// DeleteProspect stops tracking a prospect.
+func (s *Service) DeleteProspect(ctx context.Context, userID, organisationID, prospectID uuid.UUID) error {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return err
+	}
+	n, err := s.store.DeleteSeoBacklinkProspect(ctx, query.DeleteSeoBacklinkProspectParams{ID: prospectID, OrganisationID: organisationID})
+	if err != nil {
+		return pkg.InternalError{Message: "Error deleting prospect", Err: err}
+	}
+	if n == 0 {
+		return pkg.NotFoundError{Message: "Prospect not found"}
+	}
+	return nil
+}
+
+// EnrichProspects looks up the backlink summaries of up to
+// maxProspectEnrich of the target's prospects that don't have one yet,
+// oldest first; call it again while Remaining is true. A prospect whose
+// lookup fails is left for the next call. Only organisation owners and
+// admins can run it, since each lookup is billed.
+func (s *Service) EnrichProspects(ctx context.Context, userID uuid.UUID, req ProspectEnrichRequest) (*ProspectEnrichment, error) {
+	if s.backlinks == nil {
+		return nil, pkg.BadRequestError{Message: "Backlink reports are not configured"}
+	}
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+	target, err := NormaliseDomain(req.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.store.ListUnenrichedSeoBacklinkProspects(ctx, query.ListUnenrichedSeoBacklinkProspectsParams{
+		OrganisationID: req.OrganisationID,
+		Target:         target,
+		Limit:          maxProspectEnrich + 1,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading prospects", Err: err}
+	}
+	result := &ProspectEnrichment{Remaining: len(rows) > maxProspectEnrich}
+	if result.Remaining {
+		rows = rows[:maxProspectEnrich]
+	}
+
+	ctx = dataforseo.WithCostAttribution(ctx, req.OrganisationID.String())
+	for _, p := range rows {
+		summary, err := s.backlinks.GetBacklinksSummary(ctx, p.Domain)
+		if errors.Is(err, dataforseo.ErrBudgetExceeded) {
+			if result.Enriched == 0 {
+				return nil, pkg.BadRequestError{Message: "The monthly SEO budget has been reached"}
+			}
+			result.Remaining = true
+			break
+		}
+		if err != nil {
+			slog.Warn("Prospect backlink summary failed", "prospectID", p.ID, "domain", p.Domain, "error", err)
+			result.Failed++
+			continue
+		}
+		spamScore := summary.BacklinksSpamScore
+		if summary.Info != nil {
+			spamScore = summary.Info.SpamScore
+		}
+		if err := s.store.EnrichSeoBacklinkProspect(ctx, query.EnrichSeoBacklinkProspectParams{
+			ID:               p.ID,
+			Rank:             sql.NullInt32{Int32: int32(summary.Rank), Valid: true},
+			Backlinks:        sql.NullInt64{Int64: summary.Backlinks, Valid: true},
+			ReferringDomains: sql.NullInt32{Int32: int32(summary.ReferringDomains), Valid: true},
+			SpamScore:        sql.NullInt32{Int32: int32(spamScore), Valid: true},
+		}); err != nil {
+			return nil, pkg.InternalError{Message: "Error saving prospect", Err: err}
+		}
+		result.Enriched++
+	}
+	if result.Failed > 0 {
+		result.Remaining = true
+	}
+	return result, nil
+}
@@ -0,0 +1,274 @@
+package seo
+
+import (
+	"app/pkg"
+	"app/pkg/dataforseo"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"service-core/domain/membership"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxRankCheckKeywords is the most keywords one check accepts, which is
+	// also what fits in a single DataForSEO task_post call.
+	MaxRankCheckKeywords = dataforseo.MaxTasksPerPost
+	serpDepth            = 100
+)
+
+// StartRankCheck queues a SERP lookup per keyword and returns the pending
+// check; poll GetRankCheck for the results. Only organisation owners and
+// admins can start checks, since each one is billed.
+func (s *Service) StartRankCheck(ctx context.Context, userID uuid.UUID, req RankCheckRequest) (*RankCheck, error) {
+	if s.serp == nil {
+		return nil, pkg.BadRequestError{Message: "Rank checks are not configured"}
+	}
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+
+	domain, err := NormaliseDomain(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := rankCheckKeywords(req.Keywords)
+	if err != nil {
+		return nil, err
+	}
+	if req.LocationCode <= 0 {
+		return nil, pkg.BadRequestError{Message: "locationCode is required"}
+	}
+	if req.LanguageCode == "" {
+		return nil, pkg.BadRequestError{Message: "languageCode is required"}
+	}
+	device := req.Device
+	if device == "" {
+		device = DeviceDesktop
+	}
+	if device != DeviceDesktop && device != DeviceMobile {
+		return nil, pkg.BadRequestError{Message: "device must be desktop or mobile"}
+	}
+
+	row, err := s.startRankCheck(ctx, req.OrganisationID, uuid.NullUUID{UUID: userID, Valid: true}, domain, keywords, req.LocationCode, req.LanguageCode, device)
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := newRankCheck(row)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error reading rank check", Err: err}
+	}
+	return &check, nil
+}
+
+// startRankCheck queues the lookups of an already validated check and
+// collects them in the background. It refuses checks that would overrun the
+// monthly budget.
+func (s *Service) startRankCheck(ctx context.Context, organisationID uuid.UUID, createdBy uuid.NullUUID, domain string, keywords []string, locationCode int, languageCode, device string) (query.SeoRankCheck, error) {
+	// Refuse up front rather than fail halfway through the lookups
+	report := s.serp.CostReport()
+	estimate := float64(len(keywords)) * estimatedTaskCost
+	if report.Budget > 0 && report.Total+estimate > report.Budget {
+		return query.SeoRankCheck{}, pkg.BadRequestError{Message: fmt.Sprintf("This check would exceed the monthly SEO budget; $%.2f of $%.2f is left", max(report.Budget-report.Total, 0), report.Budget)}
+	}
+
+	row, err := s.store.CreateSeoRankCheck(ctx, query.CreateSeoRankCheckParams{
+		OrganisationID: organisationID,
+		CreatedBy:      createdBy,
+		Domain:         domain,
+		LocationCode:   int32(locationCode),
+		LanguageCode:   languageCode,
+		Device:         device,
+		KeywordCount:   int32(len(keywords)),
+	})
+	if err != nil {
+		return query.SeoRankCheck{}, pkg.InternalError{Message: "Error creating rank check", Err: err}
+	}
+
+	reqs := make([]dataforseo.SERPOrganicRequest, len(keywords))
+	for i, keyword := range keywords {
+		reqs[i] = dataforseo.SERPOrganicRequest{
+			Keyword:      keyword,
+			LocationCode: locationCode,
+			LanguageCode: languageCode,
+			Device:       device,
+			Depth:        serpDepth,
+			Tag:          row.ID.String(),
+		}
+	}
+	_, err = s.jobs.Enqueue(ctx, jobRankCheck, rankCheckJob{
+		CheckID:        row.ID,
+		OrganisationID: organisationID,
+		Domain:         domain,
+		Requests:       reqs,
+	})
+	if err != nil {
+		return query.SeoRankCheck{}, pkg.InternalError{Message: "Error starting rank check", Err: err}
+	}
+
+	return row, nil
+}
+
+// GetRankCheck returns a rank check of the organisation to any of its members.
+func (s *Service) GetRankCheck(ctx context.Context, userID, organisationID, checkID uuid.UUID) (*RankCheck, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+
+	return s.loadRankCheck(ctx, organisationID, checkID)
+}
+
+// loadRankCheck reads a rank check, marking it failed when the replica
+// running it went away.
+func (s *Service) loadRankCheck(ctx context.Context, organisationID, checkID uuid.UUID) (*RankCheck, error) {
+	row, err := s.store.GetSeoRankCheck(ctx, query.GetSeoRankCheckParams{ID: checkID, OrganisationID: organisationID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Rank check not found"}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading rank check", Err: err}
+	}
+	check, err := newRankCheck(row)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error reading rank check", Err: err}
+	}
+	// The replica running it went away before it finished
+	if check.Status == StatusPending && time.Since(check.CreatedAt) > rankCheckTimeout+time.Minute {
+		check.Status = StatusFailed
+		check.Error = "The check was interrupted; please run it again"
+	}
+	return &check, nil
+}
+
+// runRankCheck collects the SERPs of a started check and stores the results.
+// It runs as a job, outliving the request that started the check.
+func (s *Service) runRankCheck(ctx context.Context, checkID, organisationID uuid.UUID, domain string, reqs []dataforseo.SERPOrganicRequest) {
+	ctx = dataforseo.WithCostAttribution(ctx, organisationID.String())
+
+	params := query.CompleteSeoRankCheckParams{ID: checkID, Status: StatusCompleted}
+	results, cost, err := s.collectRanks(ctx, domain, reqs)
+	params.Cost = cost
+	if err != nil {
+		slog.Error("Rank check failed", "checkID", checkID, "organisationID", organisationID, "error", err)
+		params.Status = StatusFailed
+		params.Error = "The keyword lookups could not be queued"
+		if errors.Is(err, dataforseo.ErrBudgetExceeded) {
+			params.Error = "The monthly SEO budget has been reached"
+		}
+		results = []KeywordRank{}
+	}
+	params.Results, err = json.Marshal(results)
+	if err != nil {
+		slog.Error("Failed to encode rank check results", "checkID", checkID, "error", err)
+		return
+	}
+
+	// ctx may have run out while waiting for the SERPs
+	if err := s.store.CompleteSeoRankCheck(context.Background(), params); err != nil {
+		slog.Error("Failed to save rank check results", "checkID", checkID, "error", err)
+		return
+	}
+	slog.Info("Rank check finished", "checkID", checkID, "organisationID", organisationID, "status", params.Status, "keywords", len(reqs), "cost", cost)
+	s.publish(context.Background(), organisationID, EventRankCheckCompleted, map[string]any{
+		"checkId":  checkID,
+		"status":   params.Status,
+		"keywords": len(reqs),
+	})
+}
+
+// collectRanks queues every lookup in one call, then waits for each SERP in
+// turn. The tasks run in parallel on DataForSEO's side, so the total wait is
+// about that of the slowest. Individual keywords that fail are reported in
+// their result; only failing to queue at all is an error.
+func (s *Service) collectRanks(ctx context.Context, domain string, reqs []dataforseo.SERPOrganicRequest) ([]KeywordRank, float64, error) {
+	queued, err := s.serp.PostSERPOrganicTasks(ctx, reqs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var cost float64
+	results := make([]KeywordRank, len(reqs))
+	for i, task := range queued {
+		results[i] = KeywordRank{Keyword: reqs[i].Keyword, SERPFeatures: []string{}}
+		if task.Err != nil {
+			slog.Warn("SERP lookup rejected", "keyword", reqs[i].Keyword, "error", task.Err)
+			results[i].Error = "The lookup was rejected"
+			continue
+		}
+		cost += task.Cost
+
+		var serp *dataforseo.SERPOrganicResult
+		err := s.serp.WaitForTask(ctx, task.ID, func(ctx context.Context, id string) error {
+			var err error
+			serp, err = s.serp.GetSERPOrganicTask(ctx, id)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				results[i].Error = "The lookup did not finish in time"
+			} else {
+				slog.Warn("SERP lookup failed", "keyword", reqs[i].Keyword, "taskID", task.ID, "error", err)
+				results[i].Error = "The lookup failed"
+			}
+			continue
+		}
+
+		for _, itemType := range serp.ItemTypes {
+			if itemType != "organic" && !slices.Contains(results[i].SERPFeatures, itemType) {
+				results[i].SERPFeatures = append(results[i].SERPFeatures, itemType)
+			}
+		}
+		if found := serp.FindDomain(domain); found != nil {
+			position := found.RankGroup
+			results[i].Position = &position
+			results[i].URL = found.URL
+		}
+	}
+	return results, cost, nil
+}
+
+// NormaliseDomain accepts a bare domain or a URL and returns the lowercase
+// host without "www.", the form DataForSEO's domain targets take. The rank
+// tracker and competitor services share it.
+func NormaliseDomain(raw string) (string, error) {
+	domain, err := dataforseo.NormalizeTarget(raw, dataforseo.TargetDomain)
+	if err != nil {
+		return "", pkg.BadRequestError{Message: "domain must be a domain name such as example.com", Err: err}
+	}
+	return domain, nil
+}
+
+// NormaliseKeywords trims the keywords and drops blanks and duplicates,
+// keeping the first spelling of each. The rank tracker shares it.
+func NormaliseKeywords(raw []string) ([]string, error) {
+	keywords, err := dataforseo.NormalizeKeywords(raw)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("Keywords can be at most %d characters", dataforseo.MaxKeywordLength), Err: err}
+	}
+	return keywords, nil
+}
+
+// rankCheckKeywords normalises the keywords of a rank check, which takes
+// between one and MaxRankCheckKeywords of them.
+func rankCheckKeywords(raw []string) ([]string, error) {
+	keywords, err := NormaliseKeywords(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(keywords) == 0 {
+		return nil, pkg.BadRequestError{Message: "At least one keyword is required"}
+	}
+	if len(keywords) > MaxRankCheckKeywords {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("A check can include at most %d keywords", MaxRankCheckKeywords)}
+	}
+	return keywords, nil
+}
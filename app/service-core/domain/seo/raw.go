@@ -0,0 +1,205 @@
+package seo
+
+import (
+	"app/pkg"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"service-core/domain/file"
+	"service-core/domain/membership"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Raw artifact sections: the parts of an audit whose raw DataForSEO results
+// are kept.
+const (
+	// RawSectionOnPage is each page's on_page/instant_pages task result
+	RawSectionOnPage = "onpage"
+)
+
+var rawSections = []string{RawSectionOnPage}
+
+// rawURLExpiry is how long a signed raw artifact URL works
+const rawURLExpiry = 15 * time.Minute
+
+// RawArtifactsURL is a link to an audit section's raw results that works
+// without an access token until it expires.
+type RawArtifactsURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// RawArtifacts are the stored raw results of an audit section, one per
+// audited page and strategy, ordered by page.
+type RawArtifacts struct {
+	files file.Provider
+	rows  []query.ListSeoRawArtifactsRow
+}
+
+// saveRawArtifact stores a page's raw result for a section, gzipped. The
+// audit goes on without it if it can't be stored.
+func (s *Service) saveRawArtifact(ctx context.Context, row query.SeoPageExperience, section string, raw json.RawMessage) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		slog.Error("Failed to compress raw artifact", "pageID", row.ID, "section", section, "error", err)
+		return
+	}
+	if err := zw.Close(); err != nil {
+		slog.Error("Failed to compress raw artifact", "pageID", row.ID, "section", section, "error", err)
+		return
+	}
+
+	key := fmt.Sprintf("seo-raw/%s/%s/%s/%s.json.gz", row.OrganisationID, row.AuditID, section, row.ID)
+	// ctx may have run out during the check
+	if err := s.files.Upload(context.Background(), &file.File{Key: key, ContentType: "application/gzip", Data: buf.Bytes()}); err != nil {
+		slog.Error("Failed to store raw artifact", "pageID", row.ID, "section", section, "error", err)
+		return
+	}
+	err := s.store.UpsertSeoRawArtifact(context.Background(), query.UpsertSeoRawArtifactParams{
+		PageID:    row.ID,
+		Section:   section,
+		ObjectKey: key,
+		SizeBytes: int64(buf.Len()),
+	})
+	if err != nil {
+		slog.Error("Failed to save raw artifact", "pageID", row.ID, "section", section, "error", err)
+	}
+}
+
+// GetRawArtifacts returns an audit section's raw results to any member of the
+// organisation.
+func (s *Service) GetRawArtifacts(ctx context.Context, userID, organisationID, auditID uuid.UUID, section string) (*RawArtifacts, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	return s.rawArtifacts(ctx, organisationID, auditID, section)
+}
+
+// GetSignedRawArtifacts returns an audit section's raw results for a signed
+// URL made by SignRawArtifactsURL.
+func (s *Service) GetSignedRawArtifacts(ctx context.Context, organisationID, auditID uuid.UUID, section, expires, signature string) (*RawArtifacts, error) {
+	if s.signingKey == "" {
+		return nil, pkg.BadRequestError{Message: "Signed URLs are not enabled"}
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid expires"}
+	}
+	want := s.rawArtifactsSignature(organisationID, auditID, section, unix)
+	if !hmac.Equal([]byte(signature), []byte(want)) {
+		return nil, pkg.UnauthorizedError{Err: errors.New("invalid signature")}
+	}
+	if time.Now().Unix() > unix {
+		return nil, pkg.UnauthorizedError{Err: errors.New("signed URL expired")}
+	}
+	return s.rawArtifacts(ctx, organisationID, auditID, section)
+}
+
+// SignRawArtifactsURL makes a link to an audit section's raw results that
+// any member of the organisation can hand to a tool without an access token.
+func (s *Service) SignRawArtifactsURL(ctx context.Context, userID, organisationID, auditID uuid.UUID, section string) (*RawArtifactsURL, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	if s.signingKey == "" {
+		return nil, pkg.BadRequestError{Message: "Signed URLs are not enabled"}
+	}
+	if _, err := s.rawArtifacts(ctx, organisationID, auditID, section); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(rawURLExpiry).Truncate(time.Second)
+	q := url.Values{}
+	q.Set("organisationId", organisationID.String())
+	q.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	q.Set("signature", s.rawArtifactsSignature(organisationID, auditID, section, expiresAt.Unix()))
+	return &RawArtifactsURL{
+		URL:       fmt.Sprintf("%s/api/v1/audits/%s/raw/%s?%s", s.coreURL, auditID, url.PathEscape(section), q.Encode()),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *Service) rawArtifacts(ctx context.Context, organisationID, auditID uuid.UUID, section string) (*RawArtifacts, error) {
+	if !slices.Contains(rawSections, section) {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("Unknown section %q", section)}
+	}
+	rows, err := s.store.ListSeoRawArtifacts(ctx, query.ListSeoRawArtifactsParams{
+		OrganisationID: organisationID,
+		AuditID:        auditID,
+		Section:        section,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading raw results", Err: err}
+	}
+	if len(rows) == 0 {
+		return nil, pkg.NotFoundError{Message: "No raw results stored for this audit section"}
+	}
+	return &RawArtifacts{files: s.files, rows: rows}, nil
+}
+
+// rawArtifactsSignature signs "<org>.<audit>.<section>.<expires>"
+func (s *Service) rawArtifactsSignature(organisationID, auditID uuid.UUID, section string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(s.signingKey))
+	fmt.Fprintf(mac, "%s.%s.%s.%d", organisationID, auditID, section, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Stream writes the artifacts to w as a JSON array of
+// {"pageUrl", "strategy", "fetchedAt", "result"} objects, where result is the
+// task result as DataForSEO returned it. Each artifact is read from storage
+// as it's written, so a failure part way leaves w holding incomplete JSON.
+func (a *RawArtifacts) Stream(ctx context.Context, w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, row := range a.rows {
+		data, err := a.files.Download(ctx, row.ObjectKey)
+		if err != nil {
+			return fmt.Errorf("downloading %s: %w", row.ObjectKey, err)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", row.ObjectKey, err)
+		}
+		head, err := json.Marshal(map[string]any{
+			"pageUrl":   row.PageUrl,
+			"strategy":  row.Strategy,
+			"fetchedAt": row.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		sep := ","
+		if i == 0 {
+			sep = ""
+		}
+		// Splice the result into the object in place of its closing brace
+		if _, err := fmt.Fprintf(w, `%s%s,"result":`, sep, head[:len(head)-1]); err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, zr); err != nil {
+			return fmt.Errorf("reading %s: %w", row.ObjectKey, err)
+		}
+		if _, err := io.WriteString(w, "}"); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
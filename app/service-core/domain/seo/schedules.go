@@ -0,0 +1,579 @@
+package seo
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"service-core/domain/membership"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxSchedulePages is the most pages a schedule audits each run.
+	MaxSchedulePages = 10
+	// MaxScheduleRuns is the most runs a schedule's history returns.
+	MaxScheduleRuns = 90
+	// maxSchedulesPerOrg is how many domains an organisation can register.
+	maxSchedulesPerOrg = 10
+	// dueScheduleBatch is the most schedules one task run starts; the rest
+	// stay due for the next.
+	dueScheduleBatch = 50
+	// deferredScheduleRecheck is how long a schedule of a tier without
+	// scheduled audits waits before it's looked at again, so it resumes soon
+	// after an upgrade.
+	deferredScheduleRecheck = 24 * time.Hour
+)
+
+// tierScheduleInterval is how often each tier's schedules run. Tiers left
+// out, such as free, don't include scheduled audits.
+var tierScheduleInterval = map[string]time.Duration{
+	"starter":    7 * 24 * time.Hour,
+	"growth":     24 * time.Hour,
+	"enterprise": 24 * time.Hour,
+}
+
+// scheduleInterval returns how often an organisation's schedules run, or 0
+// when its tier doesn't include them. Active freemium organisations get
+// enterprise limits, as elsewhere.
+func scheduleInterval(tier string, isFreemium bool, freemiumExpiresAt sql.NullTime) time.Duration {
+	if isFreemium && (!freemiumExpiresAt.Valid || time.Now().Before(freemiumExpiresAt.Time)) {
+		tier = "enterprise"
+	}
+	return tierScheduleInterval[tier]
+}
+
+// TierScheduleInterval returns how often a tier's scheduled audits run,
+// IntervalDaily or IntervalWeekly, or "" when the tier doesn't include them.
+func TierScheduleInterval(tier string) string {
+	return intervalName(tierScheduleInterval[tier])
+}
+
+func intervalName(d time.Duration) string {
+	switch {
+	case d == 0:
+		return ""
+	case d < 7*24*time.Hour:
+		return IntervalDaily
+	default:
+		return IntervalWeekly
+	}
+}
+
+// orgScheduleInterval looks up how often the organisation's schedules run.
+func (s *Service) orgScheduleInterval(ctx context.Context, organisationID uuid.UUID) (time.Duration, error) {
+	plan, err := s.store.GetOrganisationSeoPlan(ctx, organisationID)
+	if err != nil {
+		return 0, pkg.InternalError{Message: "Error loading organisation plan", Err: err}
+	}
+	return scheduleInterval(plan.SubscriptionTier, plan.IsFreemium, plan.FreemiumExpiresAt), nil
+}
+
+// SaveSchedule registers a domain for recurring page experience audits and
+// rank checks, or replaces the pages and keywords of a registered one. A new
+// schedule is due straight away. Only organisation owners and admins can
+// save schedules, and only on tiers that include them.
+func (s *Service) SaveSchedule(ctx context.Context, userID uuid.UUID, req ScheduleRequest) (*Schedule, error) {
+	if err := membership.RequireAdmin(ctx, s.store, userID, req.OrganisationID); err != nil {
+		return nil, err
+	}
+	interval, err := s.orgScheduleInterval(ctx, req.OrganisationID)
+	if err != nil {
+		return nil, err
+	}
+	if interval == 0 {
+		return nil, pkg.ForbiddenError{Err: errors.New("scheduled SEO audits need the starter plan or above")}
+	}
+
+	params, err := s.scheduleParams(req)
+	if err != nil {
+		return nil, err
+	}
+	params.CreatedBy = uuid.NullUUID{UUID: userID, Valid: true}
+
+	existing, err := s.store.ListSeoSchedulesByOrg(ctx, req.OrganisationID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading schedules", Err: err}
+	}
+	registered := slices.ContainsFunc(existing, func(sch query.SeoSchedule) bool { return sch.Domain == params.Domain })
+	if !registered && len(existing) >= maxSchedulesPerOrg {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("An organisation can schedule at most %d domains", maxSchedulesPerOrg)}
+	}
+
+	row, err := s.store.UpsertSeoSchedule(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error saving schedule", Err: err}
+	}
+	schedule := newSchedule(row, intervalName(interval))
+	return &schedule, nil
+}
+
+// scheduleParams validates and normalises a schedule request.
+func (s *Service) scheduleParams(req ScheduleRequest) (query.UpsertSeoScheduleParams, error) {
+	domain, err := NormaliseDomain(req.Domain)
+	if err != nil {
+		return query.UpsertSeoScheduleParams{}, err
+	}
+	params := query.UpsertSeoScheduleParams{
+		OrganisationID: req.OrganisationID,
+		Domain:         domain,
+		Pages:          []string{},
+		Keywords:       []string{},
+		LocationCode:   int32(req.LocationCode),
+		LanguageCode:   req.LanguageCode,
+		Device:         req.Device,
+		Strategy:       req.Strategy,
+	}
+	if params.Device == "" {
+		params.Device = DeviceDesktop
+	}
+	if params.Device != DeviceDesktop && params.Device != DeviceMobile {
+		return query.UpsertSeoScheduleParams{}, pkg.BadRequestError{Message: "device must be desktop or mobile"}
+	}
+	if params.Strategy == "" {
+		params.Strategy = StrategyMobile
+	}
+	if params.Strategy != StrategyMobile && params.Strategy != StrategyDesktop {
+		return query.UpsertSeoScheduleParams{}, pkg.BadRequestError{Message: "strategy must be mobile or desktop"}
+	}
+
+	if len(req.Pages) > MaxSchedulePages {
+		return query.UpsertSeoScheduleParams{}, pkg.BadRequestError{Message: fmt.Sprintf("A schedule can include at most %d pages", MaxSchedulePages)}
+	}
+	if len(req.Pages) > 0 && s.pageSpeed == nil {
+		return query.UpsertSeoScheduleParams{}, pkg.BadRequestError{Message: "Page experience audits are not configured"}
+	}
+	for _, raw := range req.Pages {
+		pageURL, err := normalisePageURL(raw)
+		if err != nil {
+			return query.UpsertSeoScheduleParams{}, err
+		}
+		u, _ := url.Parse(pageURL)
+		// Subdomains count, and www. is optional either way
+		host, bare := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."), strings.TrimPrefix(domain, "www.")
+		if host != bare && !strings.HasSuffix(host, "."+bare) {
+			return query.UpsertSeoScheduleParams{}, pkg.BadRequestError{Message: "Pages must be on the scheduled domain"}
+		}
+		if !slices.Contains(params.Pages, pageURL) {
+			params.Pages = append(params.Pages, pageURL)
+		}
+	}
+
+	if len(req.Keywords) > 0 {
+		if s.serp == nil {
+			return query.UpsertSeoScheduleParams{}, pkg.BadRequestError{Message: "Rank checks are not configured"}
+		}
+		if params.Keywords, err = rankCheckKeywords(req.Keywords); err != nil {
+			return query.UpsertSeoScheduleParams{}, err
+		}
+		if req.LocationCode <= 0 {
+			return query.UpsertSeoScheduleParams{}, pkg.BadRequestError{Message: "locationCode is required"}
+		}
+		if req.LanguageCode == "" {
+			return query.UpsertSeoScheduleParams{}, pkg.BadRequestError{Message: "languageCode is required"}
+		}
+	}
+	if len(params.Pages) == 0 && len(params.Keywords) == 0 {
+		return query.UpsertSeoScheduleParams{}, pkg.BadRequestError{Message: "A schedule needs at least one page or keyword"}
+	}
+	return params, nil
+}
+
+// ListSchedules returns the organisation's schedules, each with its latest
+// run, to any of its members.
+func (s *Service) ListSchedules(ctx context.Context, userID, organisationID uuid.UUID) ([]Schedule, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	interval, err := s.orgScheduleInterval(ctx, organisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.store.ListSeoSchedulesByOrg(ctx, organisationID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading schedules", Err: err}
+	}
+	schedules := make([]Schedule, len(rows))
+	for i, row := range rows {
+		schedules[i] = newSchedule(row, intervalName(interval))
+		runs, err := s.store.ListSeoScheduleRuns(ctx, query.ListSeoScheduleRunsParams{ScheduleID: row.ID, Limit: 1})
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error loading schedule runs", Err: err}
+		}
+		if len(runs) == 0 {
+			continue
+		}
+		run, err := newScheduleRun(runs[0])
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error reading schedule run", Err: err}
+		}
+		schedules[i].LatestRun = &run
+	}
+	return schedules, nil
+}
+
+// ListScheduleRuns returns a schedule's runs, newest first, up to limit
+// (default and at most MaxScheduleRuns), to any member of the organisation.
+func (s *Service) ListScheduleRuns(ctx context.Context, userID, organisationID, scheduleID uuid.UUID, limit int) ([]ScheduleRun, error) {
+	if err := membership.RequireMember(ctx, s.store, userID, organisationID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > MaxScheduleRuns {
+		limit = MaxScheduleRuns
+	}
+
+	_, err := s.store.GetSeoSchedule(ctx, query.GetSeoScheduleParams{ID: scheduleID, OrganisationID: organisationID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Schedule not found"}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading schedule", Err: err}
+	}
+	rows, err := s.store.ListSeoScheduleRuns(ctx, query.ListSeoScheduleRunsParams{ScheduleID: scheduleID, Limit: int32(limit)})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading schedule runs", Err: err}
+	}
+	runs := make([]ScheduleRun, len(rows))
+	for i, row := range rows {
+		if runs[i], err = newScheduleRun(row); err != nil {
+			return nil, pkg.InternalError{Message: "Error reading schedule run", Err: err}
+		}
+	}
+	return runs, nil
+}
+
+// DeleteSchedule unregisters a domain along with its run history. The
+// audits and rank checks its runs started are kept.
+func (s *Service) DeleteSchedule(ctx context.Context, userID, organisationID, scheduleID uuid.UUID) error {
+	if err := membership.RequireAdmin(ctx, s.store, userID, organisationID); err != nil {
+		return err
+	}
+	n, err := s.store.DeleteSeoSchedule(ctx, query.DeleteSeoScheduleParams{ID: scheduleID, OrganisationID: organisationID})
+	if err != nil {
+		return pkg.InternalError{Message: "Error deleting schedule", Err: err}
+	}
+	if n == 0 {
+		return pkg.NotFoundError{Message: "Schedule not found"}
+	}
+	return nil
+}
+
+// RunSchedules is the scheduled task behind recurring audits. It first
+// finalises the runs whose audit and rank check have finished, summarising
+// them and comparing them with the domain's previous run, then starts the
+// runs that are due. Audits and rank checks run in the background as they
+// do when started by hand, so a run is finalised by a later call.
+func (s *Service) RunSchedules(ctx context.Context) (*ScheduleTaskSummary, error) {
+	summary := &ScheduleTaskSummary{}
+
+	running, err := s.store.ListRunningSeoScheduleRuns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing running schedule runs: %w", err)
+	}
+	for _, run := range running {
+		finalised, err := s.finaliseScheduleRun(ctx, run)
+		if err != nil {
+			slog.Error("Failed to finalise schedule run", "runID", run.ID, "error", err)
+			continue
+		}
+		if finalised {
+			summary.Finalised++
+		}
+	}
+
+	due, err := s.store.ListDueSeoSchedules(ctx, dueScheduleBatch)
+	if err != nil {
+		return nil, fmt.Errorf("listing due schedules: %w", err)
+	}
+	for _, sch := range due {
+		interval := scheduleInterval(sch.SubscriptionTier, sch.IsFreemium, sch.FreemiumExpiresAt)
+		if interval == 0 {
+			if err := s.store.DeferSeoSchedule(ctx, query.DeferSeoScheduleParams{ID: sch.ID, NextRunAt: time.Now().Add(deferredScheduleRecheck)}); err != nil {
+				slog.Error("Failed to defer schedule", "scheduleID", sch.ID, "error", err)
+			}
+			summary.Deferred++
+			continue
+		}
+		// Claiming moves next_run_at on, so an overlapping call can't start
+		// the same run twice
+		n, err := s.store.ClaimSeoSchedule(ctx, query.ClaimSeoScheduleParams{ID: sch.ID, NextRunAt: time.Now().Add(interval)})
+		if err != nil {
+			slog.Error("Failed to claim schedule", "scheduleID", sch.ID, "error", err)
+			continue
+		}
+		if n == 0 {
+			continue
+		}
+		if err := s.startScheduleRun(ctx, sch); err != nil {
+			slog.Error("Failed to start schedule run", "scheduleID", sch.ID, "error", err)
+			continue
+		}
+		summary.Started++
+	}
+	return summary, nil
+}
+
+// startScheduleRun records a run and starts its audit and rank check. A run
+// that could start neither is failed straight away.
+func (s *Service) startScheduleRun(ctx context.Context, sch query.ListDueSeoSchedulesRow) error {
+	run, err := s.store.CreateSeoScheduleRun(ctx, query.CreateSeoScheduleRunParams{ScheduleID: sch.ID, OrganisationID: sch.OrganisationID})
+	if err != nil {
+		return err
+	}
+
+	targets := query.SetSeoScheduleRunTargetsParams{ID: run.ID}
+	var problems []string
+	if len(sch.Pages) > 0 && s.pageSpeed != nil {
+		pages := make([]PageInput, len(sch.Pages))
+		for i, p := range sch.Pages {
+			pages[i] = PageInput{URL: p}
+		}
+		audit, err := s.startAudit(ctx, sch.OrganisationID, uuid.NullUUID{}, uuid.Nil, sch.Strategy, pages)
+		if err != nil {
+			slog.Error("Scheduled audit could not start", "scheduleID", sch.ID, "error", err)
+			problems = append(problems, "The page experience audit could not start")
+		} else {
+			targets.AuditID = uuid.NullUUID{UUID: audit.AuditID, Valid: true}
+		}
+	}
+	if len(sch.Keywords) > 0 && s.serp != nil {
+		check, err := s.startRankCheck(ctx, sch.OrganisationID, uuid.NullUUID{}, sch.Domain, sch.Keywords, int(sch.LocationCode), sch.LanguageCode, sch.Device)
+		if err != nil {
+			slog.Error("Scheduled rank check could not start", "scheduleID", sch.ID, "error", err)
+			problems = append(problems, "The rank check could not start")
+			var badRequest pkg.BadRequestError
+			if errors.As(err, &badRequest) {
+				problems[len(problems)-1] = badRequest.Message
+			}
+		} else {
+			targets.RankCheckID = uuid.NullUUID{UUID: check.ID, Valid: true}
+		}
+	}
+
+	if !targets.AuditID.Valid && !targets.RankCheckID.Valid {
+		if len(problems) == 0 {
+			problems = append(problems, "Audits and rank checks are not configured")
+		}
+		return s.store.CompleteSeoScheduleRun(ctx, query.CompleteSeoScheduleRunParams{
+			ID:      run.ID,
+			Status:  RunFailed,
+			Summary: json.RawMessage("{}"),
+			Deltas:  json.RawMessage("{}"),
+			Error:   strings.Join(problems, "; "),
+		})
+	}
+	if err := s.store.SetSeoScheduleRunTargets(ctx, targets); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		slog.Warn("Schedule run started in part", "scheduleID", sch.ID, "runID", run.ID, "problems", problems)
+	}
+	slog.Info("Schedule run started", "scheduleID", sch.ID, "runID", run.ID, "organisationID", sch.OrganisationID, "domain", sch.Domain)
+	return nil
+}
+
+// finaliseScheduleRun summarises a run once its audit and rank check have
+// both finished, and compares it with the previous finished run. It
+// reports false, leaving the run for a later call, while either is still
+// going.
+func (s *Service) finaliseScheduleRun(ctx context.Context, run query.SeoScheduleRun) (bool, error) {
+	var (
+		summary       ScheduleRunSummary
+		parts, failed int
+		partial       bool
+		problems      []string
+		notFoundErr   pkg.NotFoundError
+	)
+
+	if run.AuditID.Valid {
+		parts++
+		audit, err := s.loadAudit(ctx, run.OrganisationID, run.AuditID.UUID)
+		switch {
+		case errors.As(err, &notFoundErr):
+			failed++
+			problems = append(problems, "The page experience audit no longer exists")
+		case err != nil:
+			return false, err
+		case audit.Status == AuditRunning:
+			return false, nil
+		default:
+			summary.Audit = summariseAudit(audit)
+			switch audit.Status {
+			case AuditFailed:
+				failed++
+				problems = append(problems, "Every page failed to be assessed")
+			case AuditPartial:
+				partial = true
+			}
+		}
+	}
+	if run.RankCheckID.Valid {
+		parts++
+		check, err := s.loadRankCheck(ctx, run.OrganisationID, run.RankCheckID.UUID)
+		switch {
+		case errors.As(err, &notFoundErr):
+			failed++
+			problems = append(problems, "The rank check no longer exists")
+		case err != nil:
+			return false, err
+		case check.Status == StatusPending:
+			return false, nil
+		case check.Status == StatusFailed:
+			failed++
+			problems = append(problems, check.Error)
+		default:
+			summary.Ranks = summariseRanks(check)
+			if summary.Ranks.Failed > 0 {
+				partial = true
+			}
+		}
+	}
+
+	params := query.CompleteSeoScheduleRunParams{ID: run.ID, Status: RunCompleted, Deltas: json.RawMessage("{}"), Error: strings.Join(problems, "; ")}
+	switch {
+	case failed == parts:
+		params.Status = RunFailed
+	case failed > 0 || partial:
+		params.Status = RunPartial
+	}
+
+	prev, err := s.store.GetPreviousSeoScheduleRun(ctx, query.GetPreviousSeoScheduleRunParams{ScheduleID: run.ScheduleID, CreatedAt: run.CreatedAt})
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return false, err
+	case params.Status != RunFailed:
+		var prevSummary ScheduleRunSummary
+		if err := json.Unmarshal(prev.Summary, &prevSummary); err != nil {
+			return false, err
+		}
+		if params.Deltas, err = json.Marshal(compareRuns(prev.ID, prevSummary, summary)); err != nil {
+			return false, err
+		}
+	}
+
+	if params.Summary, err = json.Marshal(summary); err != nil {
+		return false, err
+	}
+	if err := s.store.CompleteSeoScheduleRun(ctx, params); err != nil {
+		return false, err
+	}
+	slog.Info("Schedule run finished", "runID", run.ID, "scheduleID", run.ScheduleID, "status", params.Status)
+	return true, nil
+}
+
+func summariseAudit(audit *PageExperienceAudit) *AuditSummary {
+	summary := &AuditSummary{
+		Pages:     len(audit.Pages),
+		Completed: audit.Completed,
+		Failed:    audit.Failed,
+		Scores:    make(map[string]int32),
+	}
+	var total int32
+	for _, page := range audit.Pages {
+		if page.Status != StatusCompleted {
+			continue
+		}
+		summary.Scores[page.URL] = page.Score
+		total += page.Score
+		if page.Passed {
+			summary.Passed++
+		}
+	}
+	if summary.Completed > 0 {
+		avg := float64(total) / float64(summary.Completed)
+		summary.AverageScore = &avg
+	}
+	return summary
+}
+
+func summariseRanks(check *RankCheck) *RankSummary {
+	summary := &RankSummary{Keywords: len(check.Results), Positions: make(map[string]*int)}
+	var total int
+	for _, r := range check.Results {
+		if r.Error != "" {
+			summary.Failed++
+			continue
+		}
+		summary.Positions[r.Keyword] = r.Position
+		if r.Position == nil {
+			continue
+		}
+		summary.Ranked++
+		total += *r.Position
+		if *r.Position <= 3 {
+			summary.Top3++
+		}
+		if *r.Position <= 10 {
+			summary.Top10++
+		}
+	}
+	if summary.Ranked > 0 {
+		avg := float64(total) / float64(summary.Ranked)
+		summary.AveragePosition = &avg
+	}
+	return summary
+}
+
+// compareRuns computes the deltas of cur against prev. Pages and keywords
+// only present in one of the runs are left out.
+func compareRuns(prevID uuid.UUID, prev, cur ScheduleRunSummary) ScheduleRunDeltas {
+	d := ScheduleRunDeltas{PreviousRunID: prevID, Pages: []PageScoreChange{}, Keywords: []KeywordMovement{}}
+
+	if prev.Audit != nil && cur.Audit != nil {
+		passed := cur.Audit.Passed - prev.Audit.Passed
+		d.Passed = &passed
+		if prev.Audit.AverageScore != nil && cur.Audit.AverageScore != nil {
+			score := *cur.Audit.AverageScore - *prev.Audit.AverageScore
+			d.AverageScore = &score
+		}
+		for pageURL, score := range cur.Audit.Scores {
+			before, ok := prev.Audit.Scores[pageURL]
+			if ok && before != score {
+				d.Pages = append(d.Pages, PageScoreChange{URL: pageURL, Previous: before, Current: score, Change: score - before})
+			}
+		}
+		slices.SortFunc(d.Pages, func(a, b PageScoreChange) int { return strings.Compare(a.URL, b.URL) })
+	}
+
+	if prev.Ranks != nil && cur.Ranks != nil {
+		ranked := cur.Ranks.Ranked - prev.Ranks.Ranked
+		top10 := cur.Ranks.Top10 - prev.Ranks.Top10
+		d.Ranked, d.Top10 = &ranked, &top10
+		if prev.Ranks.AveragePosition != nil && cur.Ranks.AveragePosition != nil {
+			position := *cur.Ranks.AveragePosition - *prev.Ranks.AveragePosition
+			d.AveragePosition = &position
+		}
+		for keyword, position := range cur.Ranks.Positions {
+			before, ok := prev.Ranks.Positions[keyword]
+			if !ok {
+				continue
+			}
+			m := KeywordMovement{Keyword: keyword, Previous: before, Current: position}
+			switch {
+			case before == nil && position == nil:
+				continue
+			case before != nil && position != nil:
+				if *before == *position {
+					continue
+				}
+				change := *before - *position
+				m.Change = &change
+			}
+			d.Keywords = append(d.Keywords, m)
+		}
+		slices.SortFunc(d.Keywords, func(a, b KeywordMovement) int { return strings.Compare(a.Keyword, b.Keyword) })
+	}
+	return d
+}
@@ -0,0 +1,165 @@
+package seo
+
+import (
+	"app/pkg/dataforseo"
+	"app/pkg/httprecord"
+	"app/pkg/jobs"
+	"app/pkg/pagespeed"
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"service-core/config"
+	"service-core/domain/file"
+	"service-core/domain/locks"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// rankCheckTimeout bounds how long a check waits for its SERPs. Standard
+	// queue tasks usually finish within a few minutes.
+	rankCheckTimeout = 15 * time.Minute
+	// estimatedTaskCost is a conservative price in USD for one standard-queue
+	// SERP of 100 results, used to refuse checks that would overrun the
+	// budget. The cost stored with a check is what the API billed.
+	estimatedTaskCost = 0.002
+	// pageSpeedTimeout bounds one PageSpeed run; most take 10-40 seconds.
+	pageSpeedTimeout = 2 * time.Minute
+	// auditLease is how long a page experience audit's lease lasts without
+	// renewal: the runner renews it before each page, so it lapses within
+	// minutes of the runner's replica going away.
+	auditLease = pageSpeedTimeout + time.Minute
+	// auditQueueWait is how long pending pages may wait for their job to
+	// start, including retries while another runner holds the audit, before
+	// they count as interrupted.
+	auditQueueWait = 10 * time.Minute
+)
+
+// store defines the database interface for SEO rank checks, page
+// experience audits and their raw artifacts, keyword lists, scheduled audits
+// and backlink prospects
+type store interface {
+	GetOrgMembershipRole(ctx context.Context, arg query.GetOrgMembershipRoleParams) (string, error)
+	CreateSeoRankCheck(ctx context.Context, arg query.CreateSeoRankCheckParams) (query.SeoRankCheck, error)
+	GetSeoRankCheck(ctx context.Context, arg query.GetSeoRankCheckParams) (query.SeoRankCheck, error)
+	CompleteSeoRankCheck(ctx context.Context, arg query.CompleteSeoRankCheckParams) error
+	CreateSeoPageExperience(ctx context.Context, arg query.CreateSeoPageExperienceParams) (query.SeoPageExperience, error)
+	CompleteSeoPageExperience(ctx context.Context, arg query.CompleteSeoPageExperienceParams) error
+	ListSeoPageExperienceByAudit(ctx context.Context, arg query.ListSeoPageExperienceByAuditParams) ([]query.SeoPageExperience, error)
+	ListSeoPageExperienceTrend(ctx context.Context, arg query.ListSeoPageExperienceTrendParams) ([]query.SeoPageExperience, error)
+	ResetSeoPageExperience(ctx context.Context, id uuid.UUID) (query.SeoPageExperience, error)
+	RecheckSeoPageIssues(ctx context.Context, arg query.RecheckSeoPageIssuesParams) error
+	CreateSeoKeywordList(ctx context.Context, arg query.CreateSeoKeywordListParams) (query.SeoKeywordList, error)
+	AddSeoKeywords(ctx context.Context, arg query.AddSeoKeywordsParams) error
+	GetSeoKeywordList(ctx context.Context, arg query.GetSeoKeywordListParams) (query.SeoKeywordList, error)
+	ListSeoKeywordListsByOrg(ctx context.Context, organisationID uuid.UUID) ([]query.ListSeoKeywordListsByOrgRow, error)
+	ListSeoKeywords(ctx context.Context, listID uuid.UUID) ([]query.SeoKeyword, error)
+	ListSeoKeywordRefreshes(ctx context.Context, arg query.ListSeoKeywordRefreshesParams) ([]query.SeoKeywordRefresh, error)
+	ListDueSeoKeywords(ctx context.Context) ([]query.ListDueSeoKeywordsRow, error)
+	UpdateSeoKeywordVolume(ctx context.Context, arg query.UpdateSeoKeywordVolumeParams) error
+	CreateSeoKeywordRefresh(ctx context.Context, arg query.CreateSeoKeywordRefreshParams) error
+	ScheduleSeoKeywordList(ctx context.Context, arg query.ScheduleSeoKeywordListParams) error
+	GetOrganisationSeoPlan(ctx context.Context, id uuid.UUID) (query.GetOrganisationSeoPlanRow, error)
+	UpsertSeoSchedule(ctx context.Context, arg query.UpsertSeoScheduleParams) (query.SeoSchedule, error)
+	ListSeoSchedulesByOrg(ctx context.Context, organisationID uuid.UUID) ([]query.SeoSchedule, error)
+	GetSeoSchedule(ctx context.Context, arg query.GetSeoScheduleParams) (query.SeoSchedule, error)
+	DeleteSeoSchedule(ctx context.Context, arg query.DeleteSeoScheduleParams) (int64, error)
+	ListDueSeoSchedules(ctx context.Context, limit int32) ([]query.ListDueSeoSchedulesRow, error)
+	ClaimSeoSchedule(ctx context.Context, arg query.ClaimSeoScheduleParams) (int64, error)
+	DeferSeoSchedule(ctx context.Context, arg query.DeferSeoScheduleParams) error
+	CreateSeoScheduleRun(ctx context.Context, arg query.CreateSeoScheduleRunParams) (query.SeoScheduleRun, error)
+	SetSeoScheduleRunTargets(ctx context.Context, arg query.SetSeoScheduleRunTargetsParams) error
+	CompleteSeoScheduleRun(ctx context.Context, arg query.CompleteSeoScheduleRunParams) error
+	ListRunningSeoScheduleRuns(ctx context.Context) ([]query.SeoScheduleRun, error)
+	GetPreviousSeoScheduleRun(ctx context.Context, arg query.GetPreviousSeoScheduleRunParams) (query.SeoScheduleRun, error)
+	ListSeoScheduleRuns(ctx context.Context, arg query.ListSeoScheduleRunsParams) ([]query.SeoScheduleRun, error)
+	CreateSeoBacklinkProspect(ctx context.Context, arg query.CreateSeoBacklinkProspectParams) (int64, error)
+	CountSeoBacklinkProspects(ctx context.Context, arg query.CountSeoBacklinkProspectsParams) (int64, error)
+	ListSeoBacklinkProspects(ctx context.Context, arg query.ListSeoBacklinkProspectsParams) ([]query.SeoBacklinkProspect, error)
+	UpdateSeoBacklinkProspect(ctx context.Context, arg query.UpdateSeoBacklinkProspectParams) (query.SeoBacklinkProspect, error)
+	DeleteSeoBacklinkProspect(ctx context.Context, arg query.DeleteSeoBacklinkProspectParams) (int64, error)
+	ListUnenrichedSeoBacklinkProspects(ctx context.Context, arg query.ListUnenrichedSeoBacklinkProspectsParams) ([]query.SeoBacklinkProspect, error)
+	EnrichSeoBacklinkProspect(ctx context.Context, arg query.EnrichSeoBacklinkProspectParams) error
+	UpsertSeoRawArtifact(ctx context.Context, arg query.UpsertSeoRawArtifactParams) error
+	ListSeoRawArtifacts(ctx context.Context, arg query.ListSeoRawArtifactsParams) ([]query.ListSeoRawArtifactsRow, error)
+}
+
+// serpClient is the part of the DataForSEO client rank checks use.
+type serpClient interface {
+	PostSERPOrganicTasks(ctx context.Context, reqs []dataforseo.SERPOrganicRequest) ([]dataforseo.QueuedTask, error)
+	GetSERPOrganicTask(ctx context.Context, id string) (*dataforseo.SERPOrganicResult, error)
+	WaitForTask(ctx context.Context, id string, fetch func(ctx context.Context, id string) error) error
+	CostReport() dataforseo.CostReport
+}
+
+// pageSpeedClient runs PageSpeed Insights for page experience audits.
+type pageSpeedClient interface {
+	Run(ctx context.Context, targetURL, strategy string) (*pagespeed.Result, error)
+}
+
+// Service runs ad-hoc keyword rank checks against Google SERPs, page
+// experience audits, keyword lists' monthly volume refreshes, backlink
+// reports and prospects, and recurring scheduled audits of registered
+// domains.
+type Service struct {
+	store       store
+	files       file.Provider
+	locks       *locks.Service
+	serp        serpClient      // nil when DataForSEO isn't configured
+	volumes     volumeClient    // nil when DataForSEO isn't configured
+	backlinks   backlinksClient // nil when DataForSEO isn't configured
+	pageSpeed   pageSpeedClient // nil when there's no PageSpeed API key
+	pageChecker onPageChecker
+	publisher   publisher // nil disables webhook events
+	jobs        *jobs.Queue
+	coreURL     string
+	signingKey  string // empty disables signed raw artifact URLs
+}
+
+// NewService creates a new SEO service. Rank checks, keyword lists and
+// backlink reports are unavailable unless DataForSEO credentials are configured, and page
+// experience audits unless a PageSpeed API key is.
+//
+// Audited pages' on-page issues are checked with DataForSEO when it's
+// configured, and by fetching the page directly otherwise. Rank checks and
+// audits run as jobs on queue. Finished ones are published to organisation
+// webhooks via publisher, which may be nil. The raw DataForSEO results behind
+// audits are kept in files.
+func NewService(cfg *config.Config, store store, files file.Provider, lockService *locks.Service, queue *jobs.Queue, publisher publisher) *Service {
+	s := &Service{
+		store:       store,
+		files:       files,
+		locks:       lockService,
+		publisher:   publisher,
+		jobs:        queue,
+		pageChecker: httpPageChecker{client: &http.Client{Timeout: onPageCheckTimeout}},
+		coreURL:     cfg.CoreURL,
+		signingKey:  cfg.SignedURLKey,
+	}
+	s.registerJobs(queue)
+	if cfg.PageSpeedAPIKey != "" {
+		s.pageSpeed = pagespeed.NewClient(cfg.PageSpeedAPIKey, pagespeed.WithTransport(httprecord.Transport("pagespeed", nil)))
+	}
+	if cfg.DataForSEOLogin == "" {
+		return s
+	}
+	opts := []dataforseo.Option{dataforseo.WithTransport(httprecord.Transport("dataforseo", nil))}
+	if cfg.DataForSEOMonthlyBudget != "" {
+		budget, err := strconv.ParseFloat(cfg.DataForSEOMonthlyBudget, 64)
+		if err != nil {
+			slog.Warn("Ignoring DataForSEO monthly budget", "error", err)
+		} else {
+			opts = append(opts, dataforseo.WithBudget(budget))
+		}
+	}
+	client := dataforseo.NewClient(cfg.DataForSEOLogin, cfg.DataForSEOPassword, opts...)
+	s.serp = client
+	s.volumes = client
+	s.backlinks = client
+	s.pageChecker = instantPageChecker{client: client}
+	return s
+}
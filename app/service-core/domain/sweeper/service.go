@@ -0,0 +1,229 @@
+// Package sweeper removes stored files whose rows have been purged. Purges
+// queue the files' keys in pending_deletions in the same statement that
+// deletes the rows, so a failed removal is retried instead of leaking the
+// files.
+package sweeper
+
+import (
+	"app/pkg"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"time"
+
+	"service-core/domain/file"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// sweepBatchSize caps the deletions one Sweep run claims
+	sweepBatchSize = 200
+	// sweepLease is how long a run holds the deletions it claimed before
+	// another run may take them over
+	sweepLease = 15 * time.Minute
+	// retryBackoff is the wait after the first failed attempt. It doubles with
+	// each further failure, up to maxRetryBackoff. Deletions are never given
+	// up on; they're reported as stuck instead.
+	retryBackoff    = 5 * time.Minute
+	maxRetryBackoff = 24 * time.Hour
+	// StuckAttempts is how many failed attempts make a deletion stuck.
+	StuckAttempts = 8
+	// stuckSampleSize caps the stuck deletions listed in the backlog
+	stuckSampleSize = 20
+)
+
+type store interface {
+	ClaimDuePendingDeletions(ctx context.Context, arg query.ClaimDuePendingDeletionsParams) ([]query.PendingDeletion, error)
+	DeletePendingDeletion(ctx context.Context, id uuid.UUID) error
+	RecordPendingDeletionFailure(ctx context.Context, arg query.RecordPendingDeletionFailureParams) error
+	GetPendingDeletionBacklog(ctx context.Context, attempts int32) ([]query.GetPendingDeletionBacklogRow, error)
+	ListStuckPendingDeletions(ctx context.Context, arg query.ListStuckPendingDeletionsParams) ([]query.PendingDeletion, error)
+}
+
+// Service sweeps pending deletions from storage
+type Service struct {
+	store store
+	files file.Provider
+}
+
+// NewService creates a new sweeper service
+func NewService(store store, files file.Provider) *Service {
+	return &Service{
+		store: store,
+		files: files,
+	}
+}
+
+// SweepSummary reports a Sweep run
+type SweepSummary struct {
+	Claimed int   `json:"claimed"`
+	Removed int   `json:"removed"` // storage objects removed
+	Swept   int   `json:"swept"`   // deletions completed
+	Failed  int   `json:"failed"`
+	Backlog int64 `json:"backlog"` // deletions still pending after the run
+}
+
+// ReasonBacklog is the pending deletions queued for one reason
+type ReasonBacklog struct {
+	Reason          string    `json:"reason"`
+	Pending         int64     `json:"pending"`
+	Due             int64     `json:"due"`
+	Stuck           int64     `json:"stuck"`
+	OldestCreatedAt time.Time `json:"oldestCreatedAt"`
+}
+
+// StuckDeletion is a deletion that has failed at least StuckAttempts times
+type StuckDeletion struct {
+	ID            uuid.UUID `json:"id"`
+	StorageKey    string    `json:"storageKey"`
+	Reason        string    `json:"reason"`
+	Attempts      int32     `json:"attempts"`
+	LastError     string    `json:"lastError"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// Backlog is the size of the pending deletion queue, in total and per reason,
+// with the oldest stuck deletions.
+type Backlog struct {
+	Pending int64           `json:"pending"`
+	Due     int64           `json:"due"`
+	Stuck   int64           `json:"stuck"`
+	Reasons []ReasonBacklog `json:"reasons"`
+	Oldest  []StuckDeletion `json:"oldestStuck"`
+}
+
+// backoff returns how long to wait before retrying a deletion that has failed
+// attempts times.
+func backoff(attempts int32) time.Duration {
+	if attempts < 1 {
+		return retryBackoff
+	}
+	d := retryBackoff << (attempts - 1)
+	if d <= 0 || d > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return d
+}
+
+// Sweep removes the storage objects of the pending deletions that are due.
+// A deletion is done once nothing is left under its key; one that fails is
+// retried with backoff. The backlog left afterwards is logged, so it can be
+// alerted on.
+func (s *Service) Sweep(ctx context.Context) (*SweepSummary, error) {
+	due, err := s.store.ClaimDuePendingDeletions(ctx, query.ClaimDuePendingDeletionsParams{
+		Limit:         sweepBatchSize,
+		NextAttemptAt: time.Now().Add(sweepLease),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error claiming pending deletions", Err: err}
+	}
+
+	summary := &SweepSummary{Claimed: len(due)}
+	for _, d := range due {
+		if ctx.Err() != nil {
+			// Unattempted deletions are taken up again once the lease is over
+			break
+		}
+		removed, err := s.remove(ctx, d.StorageKey)
+		summary.Removed += removed
+		if err != nil {
+			summary.Failed++
+			slog.Warn("Error removing pending deletion", "error", err, "key", d.StorageKey, "attempts", d.Attempts+1)
+			if err := s.store.RecordPendingDeletionFailure(ctx, query.RecordPendingDeletionFailureParams{
+				ID:            d.ID,
+				LastError:     err.Error(),
+				NextAttemptAt: time.Now().Add(backoff(d.Attempts + 1)),
+			}); err != nil {
+				slog.Error("Error recording pending deletion failure", "error", err, "id", d.ID)
+			}
+			continue
+		}
+		if err := s.store.DeletePendingDeletion(ctx, d.ID); err != nil {
+			slog.Error("Error completing pending deletion", "error", err, "id", d.ID)
+			continue
+		}
+		summary.Swept++
+	}
+
+	backlog, err := s.Backlog(ctx)
+	if err != nil {
+		return summary, err
+	}
+	summary.Backlog = backlog.Pending
+	slog.Info("Pending deletion backlog", "pending", backlog.Pending, "due", backlog.Due, "stuck", backlog.Stuck)
+	return summary, nil
+}
+
+// remove deletes a key, or everything under it when it ends in "/", and
+// returns how many objects it removed. Objects already gone count as removed.
+func (s *Service) remove(ctx context.Context, key string) (int, error) {
+	if !strings.HasSuffix(key, "/") {
+		if err := s.files.Remove(ctx, key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return 0, err
+		}
+		return 1, nil
+	}
+	names, err := s.files.ListByPrefix(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("listing %s: %w", key, err)
+	}
+	removed := 0
+	for _, name := range names {
+		if err := s.files.Remove(ctx, key+name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, fmt.Errorf("removing %s: %w", key+name, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Backlog reports how many deletions are pending, due and stuck, per reason
+// and in total, with the oldest stuck ones.
+func (s *Service) Backlog(ctx context.Context) (*Backlog, error) {
+	rows, err := s.store.GetPendingDeletionBacklog(ctx, StuckAttempts)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading pending deletion backlog", Err: err}
+	}
+	backlog := &Backlog{Reasons: make([]ReasonBacklog, len(rows)), Oldest: []StuckDeletion{}}
+	for i, row := range rows {
+		backlog.Reasons[i] = ReasonBacklog{
+			Reason:          row.Reason,
+			Pending:         row.Pending,
+			Due:             row.Due,
+			Stuck:           row.Stuck,
+			OldestCreatedAt: row.OldestCreatedAt,
+		}
+		backlog.Pending += row.Pending
+		backlog.Due += row.Due
+		backlog.Stuck += row.Stuck
+	}
+	if backlog.Stuck == 0 {
+		return backlog, nil
+	}
+
+	stuck, err := s.store.ListStuckPendingDeletions(ctx, query.ListStuckPendingDeletionsParams{
+		Attempts: StuckAttempts,
+		Limit:    stuckSampleSize,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing stuck deletions", Err: err}
+	}
+	for _, d := range stuck {
+		backlog.Oldest = append(backlog.Oldest, StuckDeletion{
+			ID:            d.ID,
+			StorageKey:    d.StorageKey,
+			Reason:        d.Reason,
+			Attempts:      d.Attempts,
+			LastError:     d.LastError,
+			NextAttemptAt: d.NextAttemptAt,
+			CreatedAt:     d.CreatedAt,
+		})
+	}
+	return backlog, nil
+}
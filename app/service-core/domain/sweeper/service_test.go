@@ -0,0 +1,276 @@
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"service-core/domain/file"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	store
+
+	due      []query.PendingDeletion
+	backlog  []query.GetPendingDeletionBacklogRow
+	stuck    []query.PendingDeletion
+	claimed  query.ClaimDuePendingDeletionsParams
+	deleted  []uuid.UUID
+	failures []query.RecordPendingDeletionFailureParams
+	listed   bool
+}
+
+func (s *fakeStore) ClaimDuePendingDeletions(_ context.Context, arg query.ClaimDuePendingDeletionsParams) ([]query.PendingDeletion, error) {
+	s.claimed = arg
+	return s.due, nil
+}
+
+func (s *fakeStore) DeletePendingDeletion(_ context.Context, id uuid.UUID) error {
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func (s *fakeStore) RecordPendingDeletionFailure(_ context.Context, arg query.RecordPendingDeletionFailureParams) error {
+	s.failures = append(s.failures, arg)
+	return nil
+}
+
+func (s *fakeStore) GetPendingDeletionBacklog(context.Context, int32) ([]query.GetPendingDeletionBacklogRow, error) {
+	return s.backlog, nil
+}
+
+func (s *fakeStore) ListStuckPendingDeletions(context.Context, query.ListStuckPendingDeletionsParams) ([]query.PendingDeletion, error) {
+	s.listed = true
+	return s.stuck, nil
+}
+
+// fakeFiles holds keys in memory. Removing a key in failing fails; removing
+// a missing key returns fs.ErrNotExist, as the local provider does.
+type fakeFiles struct {
+	file.Provider
+
+	keys    map[string]bool
+	failing map[string]bool
+	listErr error
+}
+
+func (f *fakeFiles) Remove(_ context.Context, key string) error {
+	if f.failing[key] {
+		return errors.New("storage unavailable")
+	}
+	if !f.keys[key] {
+		return fs.ErrNotExist
+	}
+	delete(f.keys, key)
+	return nil
+}
+
+func (f *fakeFiles) ListByPrefix(_ context.Context, prefix string) ([]string, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	var names []string
+	for key := range f.keys {
+		if strings.HasPrefix(key, prefix) {
+			names = append(names, strings.TrimPrefix(key, prefix))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{0, retryBackoff},
+		{1, retryBackoff},
+		{2, 2 * retryBackoff},
+		{4, 8 * retryBackoff},
+		{9, 256 * retryBackoff},
+		{10, maxRetryBackoff},
+		// Shifting far enough overflows; it must still be capped
+		{80, maxRetryBackoff},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, backoff(tt.attempts), "attempts %d", tt.attempts)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		attempts  int32
+		keys      []string
+		failing   []string
+		listErr   error
+		want      SweepSummary
+		wantLeft  []string
+		wantRetry time.Duration // backoff of the recorded failure; 0 for none
+	}{
+		{
+			name:     "single file",
+			key:      "h5p-content/org/1/image.png",
+			keys:     []string{"h5p-content/org/1/image.png", "h5p-content/org/2/image.png"},
+			want:     SweepSummary{Claimed: 1, Removed: 1, Swept: 1},
+			wantLeft: []string{"h5p-content/org/2/image.png"},
+		},
+		{
+			// Removed by an earlier attempt that failed to complete the row
+			name: "file already gone",
+			key:  "h5p-content/org/1/image.png",
+			want: SweepSummary{Claimed: 1, Removed: 1, Swept: 1},
+		},
+		{
+			name:     "prefix",
+			key:      "h5p-content/org/1/",
+			keys:     []string{"h5p-content/org/1/a.png", "h5p-content/org/1/content.json", "h5p-content/org/10/a.png"},
+			want:     SweepSummary{Claimed: 1, Removed: 2, Swept: 1},
+			wantLeft: []string{"h5p-content/org/10/a.png"},
+		},
+		{
+			name:      "removal fails",
+			key:       "h5p-content/org/1/image.png",
+			keys:      []string{"h5p-content/org/1/image.png"},
+			failing:   []string{"h5p-content/org/1/image.png"},
+			want:      SweepSummary{Claimed: 1, Failed: 1},
+			wantLeft:  []string{"h5p-content/org/1/image.png"},
+			wantRetry: retryBackoff,
+		},
+		{
+			// The files removed before the failure stay removed; the retry
+			// lists only what's left
+			name:      "prefix fails part way",
+			key:       "h5p-content/org/1/",
+			attempts:  3,
+			keys:      []string{"h5p-content/org/1/a.png", "h5p-content/org/1/b.png", "h5p-content/org/1/c.png"},
+			failing:   []string{"h5p-content/org/1/b.png"},
+			want:      SweepSummary{Claimed: 1, Removed: 1, Failed: 1},
+			wantLeft:  []string{"h5p-content/org/1/b.png", "h5p-content/org/1/c.png"},
+			wantRetry: 8 * retryBackoff,
+		},
+		{
+			name:      "listing fails",
+			key:       "h5p-content/org/1/",
+			keys:      []string{"h5p-content/org/1/a.png"},
+			listErr:   errors.New("storage unavailable"),
+			want:      SweepSummary{Claimed: 1, Failed: 1},
+			wantLeft:  []string{"h5p-content/org/1/a.png"},
+			wantRetry: retryBackoff,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := query.PendingDeletion{ID: uuid.New(), StorageKey: tt.key, Attempts: tt.attempts}
+			store := &fakeStore{due: []query.PendingDeletion{d}}
+			files := &fakeFiles{keys: map[string]bool{}, failing: map[string]bool{}, listErr: tt.listErr}
+			for _, key := range tt.keys {
+				files.keys[key] = true
+			}
+			for _, key := range tt.failing {
+				files.failing[key] = true
+			}
+
+			start := time.Now()
+			summary, err := NewService(store, files).Sweep(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *summary)
+			assert.Equal(t, int32(sweepBatchSize), store.claimed.Limit)
+			assert.WithinRange(t, store.claimed.NextAttemptAt, start.Add(sweepLease), time.Now().Add(sweepLease))
+
+			var left []string
+			for key := range files.keys {
+				left = append(left, key)
+			}
+			sort.Strings(left)
+			assert.Equal(t, tt.wantLeft, left)
+
+			if tt.wantRetry == 0 {
+				assert.Equal(t, []uuid.UUID{d.ID}, store.deleted)
+				assert.Empty(t, store.failures)
+				return
+			}
+			assert.Empty(t, store.deleted)
+			require.Len(t, store.failures, 1)
+			failure := store.failures[0]
+			assert.Equal(t, d.ID, failure.ID)
+			assert.Contains(t, failure.LastError, "storage unavailable")
+			assert.WithinRange(t, failure.NextAttemptAt, start.Add(tt.wantRetry), time.Now().Add(tt.wantRetry))
+		})
+	}
+}
+
+func TestSweep_StopsWhenCancelled(t *testing.T) {
+	store := &fakeStore{due: []query.PendingDeletion{
+		{ID: uuid.New(), StorageKey: "a"},
+		{ID: uuid.New(), StorageKey: "b"},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	summary, err := NewService(store, &fakeFiles{keys: map[string]bool{"a": true, "b": true}}).Sweep(ctx)
+	require.NoError(t, err)
+	// Neither was attempted, so both are taken up again once the lease is over
+	assert.Equal(t, SweepSummary{Claimed: 2}, *summary)
+	assert.Empty(t, store.deleted)
+	assert.Empty(t, store.failures)
+}
+
+func TestBacklog(t *testing.T) {
+	oldest := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		rows       []query.GetPendingDeletionBacklogRow
+		want       Backlog
+		wantListed bool
+	}{
+		{
+			name: "empty",
+			want: Backlog{Reasons: []ReasonBacklog{}, Oldest: []StuckDeletion{}},
+		},
+		{
+			name: "nothing stuck",
+			rows: []query.GetPendingDeletionBacklogRow{
+				{Reason: "content_purged", Pending: 5, Due: 2, OldestCreatedAt: oldest},
+				{Reason: "library_removed", Pending: 1, Due: 1, OldestCreatedAt: oldest},
+			},
+			want: Backlog{Pending: 6, Due: 3, Oldest: []StuckDeletion{}, Reasons: []ReasonBacklog{
+				{Reason: "content_purged", Pending: 5, Due: 2, OldestCreatedAt: oldest},
+				{Reason: "library_removed", Pending: 1, Due: 1, OldestCreatedAt: oldest},
+			}},
+		},
+		{
+			name: "stuck deletions are listed",
+			rows: []query.GetPendingDeletionBacklogRow{
+				{Reason: "content_purged", Pending: 5, Due: 2, Stuck: 1, OldestCreatedAt: oldest},
+			},
+			want: Backlog{Pending: 5, Due: 2, Stuck: 1,
+				Reasons: []ReasonBacklog{{Reason: "content_purged", Pending: 5, Due: 2, Stuck: 1, OldestCreatedAt: oldest}},
+				Oldest:  []StuckDeletion{{StorageKey: "h5p-content/org/1/", Reason: "content_purged", Attempts: StuckAttempts, LastError: "denied"}},
+			},
+			wantListed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{backlog: tt.rows, stuck: []query.PendingDeletion{
+				{StorageKey: "h5p-content/org/1/", Reason: "content_purged", Attempts: StuckAttempts, LastError: "denied"},
+			}}
+			backlog, err := NewService(store, &fakeFiles{}).Backlog(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *backlog)
+			assert.Equal(t, tt.wantListed, store.listed)
+		})
+	}
+}
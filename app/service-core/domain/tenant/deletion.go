@@ -0,0 +1,312 @@
+package tenant
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryWindow is how long a deleted organisation can be restored before
+// it's torn down.
+const RecoveryWindow = 30 * 24 * time.Hour
+
+const deletionColumns = `id, organisation_id, organisation_name, organisation_slug, requested_by, requested_at,
+	scheduled_for, status, stage, progress, attempts, error, confirmed_by, cancelled_at, started_at, completed_at`
+
+// DeleteOrganisation soft-deletes an organisation: members lose access
+// straight away, its subscription is set to cancel, and teardown is scheduled
+// for the end of the recovery window. confirm must be the organisation's
+// slug. Owner only.
+func (s *Service) DeleteOrganisation(ctx context.Context, userID, orgID uuid.UUID, confirm string) (*Deletion, error) {
+	if err := s.requireOwner(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting deletion", Err: err}
+	}
+	defer tx.Rollback()
+
+	var name, slug string
+	var deletedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, "SELECT name, slug, deleted_at FROM organisations WHERE id = $1 FOR UPDATE", orgID).
+		Scan(&name, &slug, &deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Organisation not found", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading organisation", Err: err}
+	}
+	if deletedAt.Valid {
+		return nil, pkg.BadRequestError{Message: "Organisation is already scheduled for deletion"}
+	}
+	if confirm != slug {
+		return nil, pkg.BadRequestError{Message: "Confirm by entering the organisation's slug"}
+	}
+
+	scheduledFor := time.Now().Add(RecoveryWindow)
+	_, err = tx.ExecContext(ctx, `UPDATE organisations
+		SET deleted_at = now(), deletion_scheduled_for = $2, updated_at = now()
+		WHERE id = $1`, orgID, scheduledFor)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error deleting organisation", Err: err}
+	}
+	d, err := scanDeletion(tx.QueryRowContext(ctx, `INSERT INTO organisation_deletions
+		(organisation_id, organisation_name, organisation_slug, requested_by, scheduled_for)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+deletionColumns, orgID, name, slug, userID, scheduledFor))
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error scheduling deletion", Err: err}
+	}
+
+	// Cancel before committing, so a Stripe failure leaves the organisation
+	// as it was rather than deleted but still billed
+	if err := s.billing.CancelForDeletion(ctx, orgID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, pkg.InternalError{Message: "Error deleting organisation", Err: err}
+	}
+
+	slog.Info("Organisation scheduled for deletion", "organisationID", orgID, "userID", userID, "scheduledFor", scheduledFor)
+	return d, nil
+}
+
+// RestoreOrganisation cancels a scheduled deletion, restoring members'
+// access and resuming the subscription if it hasn't ended yet. It fails once
+// teardown has started. Owner only.
+func (s *Service) RestoreOrganisation(ctx context.Context, userID, orgID uuid.UUID) (*Deletion, error) {
+	if err := s.requireOwner(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting restore", Err: err}
+	}
+	defer tx.Rollback()
+
+	d, err := s.activeDeletion(ctx, tx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != DeletionScheduled {
+		return nil, pkg.BadRequestError{Message: "Teardown has started; the organisation can no longer be restored"}
+	}
+
+	d, err = scanDeletion(tx.QueryRowContext(ctx, `UPDATE organisation_deletions
+		SET status = 'cancelled', cancelled_by = $2, cancelled_at = now()
+		WHERE id = $1
+		RETURNING `+deletionColumns, d.ID, userID))
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error cancelling deletion", Err: err}
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE organisations
+		SET deleted_at = NULL, deletion_scheduled_for = NULL, updated_at = now()
+		WHERE id = $1`, orgID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error restoring organisation", Err: err}
+	}
+
+	if err := s.billing.ResumeAfterRestore(ctx, orgID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, pkg.InternalError{Message: "Error restoring organisation", Err: err}
+	}
+
+	slog.Info("Organisation restored", "organisationID", orgID, "userID", userID)
+	return d, nil
+}
+
+// GetDeletion returns an organisation's latest deletion, including its
+// teardown progress. Owner only.
+func (s *Service) GetDeletion(ctx context.Context, userID, orgID uuid.UUID) (*Deletion, error) {
+	if err := s.requireOwner(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+	d, err := scanDeletion(s.db.QueryRowContext(ctx, `SELECT `+deletionColumns+`
+		FROM organisation_deletions
+		WHERE organisation_id = $1
+		ORDER BY requested_at DESC
+		LIMIT 1`, orgID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Organisation has not been deleted", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading deletion", Err: err}
+	}
+	return d, nil
+}
+
+// ListDeletions returns organisation deletions, newest first, optionally only
+// those with the given status. For super admins.
+func (s *Service) ListDeletions(ctx context.Context, status string, limit int) ([]*Deletion, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+deletionColumns+`
+		FROM organisation_deletions
+		WHERE $1 = '' OR status = $1
+		ORDER BY requested_at DESC
+		LIMIT $2`, status, limit)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing deletions", Err: err}
+	}
+	defer rows.Close()
+
+	deletions := []*Deletion{}
+	for rows.Next() {
+		d, err := scanDeletion(rows)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error reading deletion", Err: err}
+		}
+		deletions = append(deletions, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pkg.InternalError{Message: "Error listing deletions", Err: err}
+	}
+	return deletions, nil
+}
+
+// ConfirmTeardown is the irreversible step: it ends a deleted organisation's
+// recovery window now, so the next teardown run removes it, or retries a
+// teardown that has run out of attempts. confirm must be the organisation's
+// slug. For super admins.
+func (s *Service) ConfirmTeardown(ctx context.Context, adminID, orgID uuid.UUID, confirm string) (*Deletion, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting confirmation", Err: err}
+	}
+	defer tx.Rollback()
+
+	d, err := s.activeDeletion(ctx, tx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if confirm != d.OrganisationSlug {
+		return nil, pkg.BadRequestError{Message: "Confirm by entering the organisation's slug"}
+	}
+	if d.Status == DeletionRunning {
+		return nil, pkg.BadRequestError{Message: "Teardown is already running"}
+	}
+
+	d, err = scanDeletion(tx.QueryRowContext(ctx, `UPDATE organisation_deletions
+		SET scheduled_for = LEAST(scheduled_for, now()), confirmed_by = $2, attempts = 0
+		WHERE id = $1
+		RETURNING `+deletionColumns, d.ID, adminID))
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error confirming teardown", Err: err}
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE organisations SET deletion_scheduled_for = $2 WHERE id = $1`, orgID, d.ScheduledFor)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error confirming teardown", Err: err}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, pkg.InternalError{Message: "Error confirming teardown", Err: err}
+	}
+
+	slog.Warn("Organisation teardown confirmed", "organisationID", orgID, "adminID", adminID)
+	return d, nil
+}
+
+// activeDeletion locks and returns the organisation's deletion that hasn't
+// completed or been cancelled.
+func (s *Service) activeDeletion(ctx context.Context, tx *sql.Tx, orgID uuid.UUID) (*Deletion, error) {
+	d, err := scanDeletion(tx.QueryRowContext(ctx, `SELECT `+deletionColumns+`
+		FROM organisation_deletions
+		WHERE organisation_id = $1 AND status IN ('scheduled', 'running', 'failed')
+		FOR UPDATE`, orgID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Organisation is not scheduled for deletion", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading deletion", Err: err}
+	}
+	return d, nil
+}
+
+// requireOwner checks the user owns the organisation. Unlike the membership
+// checks elsewhere, it still passes while the organisation is deleted, so the
+// owner can follow and cancel the deletion.
+func (s *Service) requireOwner(ctx context.Context, userID, orgID uuid.UUID) error {
+	var role string
+	err := s.db.QueryRowContext(ctx, `SELECT role FROM organisation_memberships
+		WHERE user_id = $1 AND organisation_id = $2 AND status = 'active'`, userID, orgID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pkg.UnauthorizedError{Err: errors.New("not a member of this organisation")}
+	}
+	if err != nil {
+		return pkg.InternalError{Message: "Error checking membership", Err: err}
+	}
+	if role != "owner" {
+		return pkg.ForbiddenError{Err: errors.New("organisation owner role required")}
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDeletion(row scanner) (*Deletion, error) {
+	var d Deletion
+	var requestedBy, confirmedBy uuid.NullUUID
+	var cancelledAt, startedAt, completedAt sql.NullTime
+	var progress []byte
+	err := row.Scan(&d.ID, &d.OrganisationID, &d.OrganisationName, &d.OrganisationSlug, &requestedBy, &d.RequestedAt,
+		&d.ScheduledFor, &d.Status, &d.Stage, &progress, &d.Attempts, &d.Error, &confirmedBy, &cancelledAt, &startedAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(progress, &d.progress); err != nil {
+		return nil, fmt.Errorf("decoding progress: %w", err)
+	}
+	if d.progress == nil {
+		d.progress = make(map[string]*Stage)
+	}
+	d.RequestedBy = nullUUID(requestedBy)
+	d.ConfirmedBy = nullUUID(confirmedBy)
+	d.CancelledAt = nullTime(cancelledAt)
+	d.StartedAt = nullTime(startedAt)
+	d.CompletedAt = nullTime(completedAt)
+	d.summarise()
+	return &d, nil
+}
+
+// summarise fills Stages and Percent from the stored progress.
+func (d *Deletion) summarise() {
+	d.Stages = make([]Stage, 0, len(teardownStages))
+	done := 0
+	for _, stage := range teardownStages {
+		p := Stage{Name: stage.name}
+		if stored := d.progress[stage.name]; stored != nil {
+			p = *stored
+			p.Name = stage.name
+		}
+		if p.Done {
+			done++
+		}
+		d.Stages = append(d.Stages, p)
+	}
+	d.Percent = done * 100 / len(teardownStages)
+}
+
+func nullUUID(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,138 @@
+package tenant
+
+import (
+	"app/pkg"
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportOrganisation writes a portable archive of an organisation to w: one
+// JSON file per table under tables/, its content files under files/, and a
+// manifest.json. Rows are read in a single repeatable-read transaction so the
+// archive is consistent.
+//
+// The archive holds member emails and webhook secrets; treat it as confidential.
+func (s *Service) ExportOrganisation(ctx context.Context, orgID uuid.UUID, w io.Writer) (*Manifest, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting export", Err: err}
+	}
+	defer tx.Rollback()
+
+	manifest := &Manifest{
+		Format:         archiveFormat,
+		Version:        archiveVersion,
+		ExportedAt:     time.Now().UTC(),
+		Source:         s.cfg.CoreURL,
+		OrganisationID: orgID,
+		Tables:         make(map[string]int, len(exportTables)),
+	}
+	err = tx.QueryRowContext(ctx, "SELECT name FROM organisations WHERE id = $1", orgID).Scan(&manifest.OrganisationName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Organisation not found", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error loading organisation", Err: err}
+	}
+
+	zw := zip.NewWriter(w)
+	for _, table := range exportTables {
+		count, err := exportTable(ctx, tx, zw, table, orgID)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error exporting " + table.name, Err: err}
+		}
+		manifest.Tables[table.name] = count
+	}
+
+	if err := s.exportFiles(ctx, zw, orgID, manifest); err != nil {
+		return nil, pkg.InternalError{Message: "Error exporting files", Err: err}
+	}
+
+	mw, err := zw.Create("manifest.json")
+	if err == nil {
+		err = json.NewEncoder(mw).Encode(manifest)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error writing archive", Err: err}
+	}
+	return manifest, nil
+}
+
+// exportTable writes the organisation's rows of one table to tables/<name>.json
+// as a JSON array and returns the row count.
+func exportTable(ctx context.Context, tx *sql.Tx, zw *zip.Writer, table tableSpec, orgID uuid.UUID) (int, error) {
+	// Table names and filters come from exportTables, never from input
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT row_to_json(t) FROM %s t WHERE %s", table.name, table.where), orgID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	fw, err := zw.Create("tables/" + table.name + ".json")
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.WriteString(fw, "["); err != nil {
+		return 0, err
+	}
+	count := 0
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return 0, err
+		}
+		if count > 0 {
+			if _, err := io.WriteString(fw, ","); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := fw.Write(row); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	_, err = io.WriteString(fw, "]")
+	return count, err
+}
+
+// exportFiles bundles every storage object under the organisation's content
+// prefix into files/ and lists them in the manifest.
+func (s *Service) exportFiles(ctx context.Context, zw *zip.Writer, orgID uuid.UUID, manifest *Manifest) error {
+	prefix := contentPrefix(orgID)
+	keys, err := s.files.ListByPrefix(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, rel := range keys {
+		data, err := s.files.Download(ctx, prefix+rel)
+		if err != nil {
+			return fmt.Errorf("downloading %s: %w", prefix+rel, err)
+		}
+		fw, err := zw.Create("files/" + rel)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(data); err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, ManifestFile{Path: rel, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+	}
+	return nil
+}
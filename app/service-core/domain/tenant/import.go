@@ -0,0 +1,380 @@
+package tenant
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"path"
+	"strings"
+
+	"service-core/domain/file"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// maxArchiveEntrySize bounds each decompressed file in an archive: the
+// largest file the H5P upload rules accept. Entries are read into memory, so
+// a small, highly compressed archive mustn't inflate without limit.
+const maxArchiveEntrySize = 2 << 30
+
+// idMap records the new ID given to each exported row, per table.
+type idMap map[string]map[string]string
+
+func (m idMap) set(table, oldID, newID string) {
+	if m[table] == nil {
+		m[table] = make(map[string]string)
+	}
+	m[table][oldID] = newID
+}
+
+// ImportOrganisation recreates an exported organisation on this instance.
+// Every row gets a new ID and references are re-mapped, so the archive can be
+// imported next to the original or more than once. Conflicts are resolved as
+// follows:
+//   - the slug gets a numeric suffix if it is taken;
+//   - users are matched to existing accounts by email, otherwise created
+//     without billing identifiers, API keys or super admin access;
+//   - H5P libraries must already be installed (matched by machine name and
+//     major/minor version); the import fails listing any that are missing;
+//   - Stripe identifiers are cleared, as billing doesn't carry over.
+//
+// Rows are inserted in one transaction, so a failed import leaves nothing behind.
+func (s *Service) ImportOrganisation(ctx context.Context, r io.ReaderAt, size int64) (*ImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid archive", Err: err}
+	}
+	var manifest Manifest
+	if err := readJSON(zr, "manifest.json", &manifest); err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid archive manifest", Err: err}
+	}
+	if manifest.Format != archiveFormat || manifest.Version != archiveVersion {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("Unsupported archive format %s v%d", manifest.Format, manifest.Version)}
+	}
+
+	tables := make(map[string][]map[string]any, len(exportTables))
+	for _, table := range exportTables {
+		var rows []map[string]any
+		if err := readJSON(zr, "tables/"+table.name+".json", &rows); err != nil {
+			return nil, pkg.BadRequestError{Message: "Invalid archive table " + table.name, Err: err}
+		}
+		tables[table.name] = rows
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting import", Err: err}
+	}
+	defer tx.Rollback()
+
+	ids := idMap{}
+	result := &ImportResult{Inserted: map[string]int{}, Skipped: map[string]int{}}
+	if err := matchLibraries(ctx, tx, tables["h5p_libraries"], ids); err != nil {
+		return nil, err
+	}
+
+	for _, table := range exportTables {
+		if table.name == "h5p_libraries" {
+			continue // matched above, never inserted
+		}
+		rows := tables[table.name]
+		if table.name == "h5p_content_folders" {
+			rows = parentsFirst(rows)
+		}
+		for _, row := range rows {
+			if err := s.importRow(ctx, tx, table, row, ids, result); err != nil {
+				var badRequest pkg.BadRequestError
+				if errors.As(err, &badRequest) {
+					return nil, err
+				}
+				return nil, pkg.InternalError{Message: "Error importing " + table.name, Err: err}
+			}
+		}
+	}
+	if result.OrganisationID == uuid.Nil {
+		return nil, pkg.BadRequestError{Message: "Archive contains no organisation"}
+	}
+
+	uploaded, err := s.importFiles(ctx, tx, zr, &manifest, ids, result)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		// Don't leave orphaned files behind a rolled-back import
+		for _, key := range uploaded {
+			if removeErr := s.files.Remove(context.WithoutCancel(ctx), key); removeErr != nil {
+				slog.Error("Failed to remove imported file", "key", key, "error", removeErr)
+			}
+		}
+		var badRequest pkg.BadRequestError
+		if errors.As(err, &badRequest) {
+			return nil, err
+		}
+		return nil, pkg.InternalError{Message: "Error importing files", Err: err}
+	}
+	return result, nil
+}
+
+// importRow re-keys one row and inserts it. Users matched by email and rows
+// with unresolved required references are not inserted.
+func (s *Service) importRow(ctx context.Context, tx *sql.Tx, table tableSpec, row map[string]any, ids idMap, result *ImportResult) error {
+	oldID, _ := row["id"].(string)
+
+	switch table.name {
+	case "organisations":
+		if _, err := uuidColumn(table.name, row, "id"); err != nil {
+			return err
+		}
+		if err := prepareOrganisation(ctx, tx, row); err != nil {
+			return err
+		}
+		result.Slug, _ = row["slug"].(string)
+	case "users":
+		email, _ := row["email"].(string)
+		var existing string
+		err := tx.QueryRowContext(ctx, "SELECT id FROM users WHERE lower(email) = lower($1) ORDER BY created LIMIT 1", email).Scan(&existing)
+		if err == nil {
+			ids.set(table.name, oldID, existing)
+			result.MatchedUsers++
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		prepareUser(row)
+	}
+
+	for column, target := range table.refs {
+		old, ok := row[column].(string)
+		if !ok {
+			continue // NULL
+		}
+		if mapped, ok := ids[target.table][old]; ok {
+			row[column] = mapped
+			continue
+		}
+		if target.required {
+			result.Skipped[table.name]++
+			return nil
+		}
+		row[column] = nil
+	}
+
+	var newID uuid.UUID
+	if oldID != "" {
+		newID = uuid.New()
+		ids.set(table.name, oldID, newID.String())
+		row["id"] = newID.String()
+	}
+	if table.name == "h5p_content" {
+		if _, ok := row["storage_path"].(string); ok {
+			orgID, err := uuidColumn(table.name, row, "org_id")
+			if err != nil {
+				return err
+			}
+			row["storage_path"] = fmt.Sprintf("%s%s/", contentPrefix(orgID), row["id"])
+		}
+	}
+	if table.name == "organisations" {
+		result.OrganisationID = newID
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	// Table names come from exportTables, never from the archive
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %[1]s SELECT * FROM json_populate_record(NULL::%[1]s, $1::json)", table.name), data); err != nil {
+		return fmt.Errorf("inserting %s %s: %w", table.name, oldID, err)
+	}
+	result.Inserted[table.name]++
+	return nil
+}
+
+// uuidColumn reads a UUID column of an archive row, which may be missing,
+// null or malformed in a hand-edited or corrupt archive.
+func uuidColumn(table string, row map[string]any, column string) (uuid.UUID, error) {
+	value, _ := row[column].(string)
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, pkg.BadRequestError{Message: fmt.Sprintf("Invalid %s in archive table %s", column, table), Err: err}
+	}
+	return id, nil
+}
+
+// prepareOrganisation picks a free slug and clears this instance-specific billing state.
+func prepareOrganisation(ctx context.Context, tx *sql.Tx, row map[string]any) error {
+	base, _ := row["slug"].(string)
+	slug := base
+	for n := 2; ; n++ {
+		var taken bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM organisations WHERE slug = $1)", slug).Scan(&taken); err != nil {
+			return err
+		}
+		if !taken {
+			break
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	row["slug"] = slug
+	row["subscription_id"] = ""
+	row["subscription_end"] = nil
+	row["stripe_customer_id"] = ""
+	row["deleted_at"] = nil
+	row["deletion_scheduled_for"] = nil
+	return nil
+}
+
+// prepareUser clears account state that must not carry over to a new instance.
+func prepareUser(row map[string]any) {
+	row["customer_id"] = ""
+	row["subscription_id"] = ""
+	row["api_key"] = ""
+	if access, ok := row["access"].(json.Number); ok {
+		if n, err := access.Int64(); err == nil {
+			row["access"] = n &^ auth.SuperAdmin
+		}
+	}
+}
+
+// matchLibraries maps the archive's libraries to the installed version with the
+// same machine name and major/minor version, preferring the newest patch.
+func matchLibraries(ctx context.Context, tx *sql.Tx, libraries []map[string]any, ids idMap) error {
+	var missing []string
+	for _, lib := range libraries {
+		name, _ := lib["machine_name"].(string)
+		major, minor := lib["major_version"], lib["minor_version"]
+		var id string
+		err := tx.QueryRowContext(ctx, `SELECT id FROM h5p_libraries
+			WHERE machine_name = $1 AND major_version = $2 AND minor_version = $3
+			ORDER BY patch_version DESC LIMIT 1`, name, fmt.Sprint(major), fmt.Sprint(minor)).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			missing = append(missing, fmt.Sprintf("%s %v.%v", name, major, minor))
+			continue
+		}
+		if err != nil {
+			return pkg.InternalError{Message: "Error matching H5P libraries", Err: err}
+		}
+		oldID, _ := lib["id"].(string)
+		ids.set("h5p_libraries", oldID, id)
+	}
+	if len(missing) > 0 {
+		return pkg.BadRequestError{Message: "Install these H5P libraries before importing: " + strings.Join(missing, ", ")}
+	}
+	return nil
+}
+
+// parentsFirst orders folders so each one comes after its parent.
+func parentsFirst(rows []map[string]any) []map[string]any {
+	placed := make(map[string]bool, len(rows))
+	ordered := make([]map[string]any, 0, len(rows))
+	for len(ordered) < len(rows) {
+		progress := false
+		for _, row := range rows {
+			id, _ := row["id"].(string)
+			parent, _ := row["parent_id"].(string)
+			if placed[id] || (parent != "" && !placed[parent] && hasRow(rows, parent)) {
+				continue
+			}
+			placed[id] = true
+			ordered = append(ordered, row)
+			progress = true
+		}
+		if !progress {
+			break // cycle; the remaining rows' parents stay unresolved and become NULL
+		}
+	}
+	for _, row := range rows {
+		if id, _ := row["id"].(string); !placed[id] {
+			ordered = append(ordered, row)
+		}
+	}
+	return ordered
+}
+
+func hasRow(rows []map[string]any, id string) bool {
+	for _, row := range rows {
+		if row["id"] == id {
+			return true
+		}
+	}
+	return false
+}
+
+// importFiles uploads the bundled content files under the new organisation and
+// content IDs, and charges them to the organisation's storage usage. It returns
+// the uploaded keys so they can be removed if the import fails.
+func (s *Service) importFiles(ctx context.Context, tx *sql.Tx, zr *zip.Reader, manifest *Manifest, ids idMap, result *ImportResult) ([]string, error) {
+	var uploaded []string
+	prefix := contentPrefix(result.OrganisationID)
+	for _, f := range manifest.Files {
+		rel := path.Clean(f.Path)
+		oldContentID, rest, ok := strings.Cut(rel, "/")
+		newContentID, mapped := ids["h5p_content"][oldContentID]
+		if !ok || !mapped || strings.HasPrefix(rel, "..") {
+			continue // file of a skipped content item, or a path outside the prefix
+		}
+		data, err := readFile(zr, "files/"+rel)
+		if err != nil {
+			return uploaded, err
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != f.SHA256 {
+			return uploaded, fmt.Errorf("checksum mismatch for %s", f.Path)
+		}
+		key := prefix + newContentID + "/" + rest
+		if err := s.files.Upload(ctx, &file.File{Key: key, ContentType: mime.TypeByExtension(path.Ext(rest)), Data: data}); err != nil {
+			return uploaded, fmt.Errorf("uploading %s: %w", key, err)
+		}
+		uploaded = append(uploaded, key)
+		result.Files++
+		result.Bytes += int64(len(data))
+	}
+
+	err := query.New(tx).AddOrganisationStorageUsage(ctx, query.AddOrganisationStorageUsageParams{
+		OrganisationID: result.OrganisationID,
+		BytesUsed:      result.Bytes,
+	})
+	return uploaded, err
+}
+
+// readJSON decodes a JSON file from the archive, keeping numbers exact.
+func readJSON(zr *zip.Reader, name string, dst any) error {
+	data, err := readFile(zr, name)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(dst)
+}
+
+// readFile reads a file from the archive, refusing to inflate it past
+// maxArchiveEntrySize whatever size its header claims.
+func readFile(zr *zip.Reader, name string) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxArchiveEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArchiveEntrySize {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("Archive file %s is larger than %d bytes", name, maxArchiveEntrySize)}
+	}
+	return data, nil
+}
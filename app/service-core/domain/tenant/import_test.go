@@ -0,0 +1,350 @@
+package tenant
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importTables stands in for the tables an import reads, and records the
+// rows it inserts.
+type importTables struct {
+	libraries    map[string]string // "<machine name> <major>.<minor>" -> installed ID
+	slugs        map[string]bool
+	users        map[string]string // lower-case email -> ID
+	inserted     map[string][]map[string]any
+	storageBytes []driver.Value
+}
+
+func (d *importTables) handle(query string, args []driver.Value) (*fakeResult, error) {
+	switch {
+	case strings.Contains(query, "FROM h5p_libraries"):
+		id, ok := d.libraries[fmt.Sprintf("%s %s.%s", args[0], args[1], args[2])]
+		if !ok {
+			return nil, nil
+		}
+		return &fakeResult{rows: [][]driver.Value{{id}}}, nil
+	case strings.Contains(query, "FROM organisations WHERE slug"):
+		return &fakeResult{rows: [][]driver.Value{{d.slugs[args[0].(string)]}}}, nil
+	case strings.Contains(query, "FROM users WHERE lower(email)"):
+		id, ok := d.users[strings.ToLower(args[0].(string))]
+		if !ok {
+			return nil, nil
+		}
+		return &fakeResult{rows: [][]driver.Value{{id}}}, nil
+	case strings.Contains(query, "json_populate_record"):
+		var row map[string]any
+		dec := json.NewDecoder(bytes.NewReader(args[0].([]byte)))
+		dec.UseNumber()
+		if err := dec.Decode(&row); err != nil {
+			return nil, err
+		}
+		table := strings.Fields(query)[2]
+		d.inserted[table] = append(d.inserted[table], row)
+		return &fakeResult{affected: 1}, nil
+	case strings.Contains(query, "INSERT INTO organisation_storage_usage"):
+		d.storageBytes = args
+		return &fakeResult{affected: 1}, nil
+	}
+	return nil, fmt.Errorf("unexpected statement: %s", query)
+}
+
+type archiveFile struct {
+	path string
+	data []byte
+	sum  string // SHA-256 in the manifest; empty for the data's own
+}
+
+// buildArchive zips an export of tables, with an empty file for every other
+// exported table.
+func buildArchive(t *testing.T, manifest Manifest, tables map[string][]map[string]any, files ...archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, v any) {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+	for _, table := range exportTables {
+		rows := tables[table.name]
+		if rows == nil {
+			rows = []map[string]any{}
+		}
+		write("tables/"+table.name+".json", rows)
+	}
+	for _, f := range files {
+		sum := f.sum
+		if sum == "" {
+			s := sha256.Sum256(f.data)
+			sum = hex.EncodeToString(s[:])
+		}
+		manifest.Files = append(manifest.Files, ManifestFile{Path: f.path, Size: int64(len(f.data)), SHA256: sum})
+		w, err := zw.Create("files/" + f.path)
+		require.NoError(t, err)
+		_, err = w.Write(f.data)
+		require.NoError(t, err)
+	}
+	write("manifest.json", manifest)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// Exported IDs of the test archive
+var (
+	oldOrgID      = uuid.NewString()
+	oldAdaID      = uuid.NewString()
+	oldBobID      = uuid.NewString()
+	oldLibraryID  = uuid.NewString()
+	oldContentID  = uuid.NewString()
+	oldCourseID   = uuid.NewString()
+	oldEnrolment  = uuid.NewString()
+	installedLib  = uuid.NewString()
+	existingAdaID = uuid.NewString()
+)
+
+// testArchiveTables is an organisation whose owner, Ada, already has an
+// account on this instance, and whose member Bob is a super admin on the
+// exporting instance.
+func testArchiveTables() map[string][]map[string]any {
+	missingUser := uuid.NewString()
+	return map[string][]map[string]any{
+		"organisations": {{"id": oldOrgID, "name": "Acme", "slug": "acme", "stripe_customer_id": "cus_acme", "subscription_id": "sub_acme"}},
+		"users": {
+			{"id": oldAdaID, "email": "ADA@Example.com", "access": 16335},
+			{"id": oldBobID, "email": "bob@example.com", "access": auth.SuperAdmin | 16335, "customer_id": "cus_bob", "api_key": "secret"},
+		},
+		"organisation_memberships": {
+			{"id": uuid.NewString(), "organisation_id": oldOrgID, "user_id": oldAdaID, "role": "owner", "invited_by": nil},
+			// Invited by a user who left; the reference is dropped
+			{"id": uuid.NewString(), "organisation_id": oldOrgID, "user_id": oldBobID, "role": "member", "invited_by": missingUser},
+			// A member missing from the users table can't be imported
+			{"id": uuid.NewString(), "organisation_id": oldOrgID, "user_id": missingUser, "role": "member"},
+		},
+		"h5p_libraries": {{"id": oldLibraryID, "machine_name": "H5P.MultiChoice", "major_version": 1, "minor_version": 16}},
+		"h5p_content": {{
+			"id": oldContentID, "org_id": oldOrgID, "library_id": oldLibraryID, "created_by": oldBobID,
+			"storage_path": fmt.Sprintf("h5p-content/%s/%s/", oldOrgID, oldContentID),
+		}},
+		"courses":      {{"id": oldCourseID, "org_id": oldOrgID, "created_by": oldAdaID}},
+		"course_items": {{"id": uuid.NewString(), "course_id": oldCourseID, "content_id": oldContentID}},
+		"enrolments":   {{"id": oldEnrolment, "org_id": oldOrgID, "course_id": oldCourseID, "user_id": oldBobID}},
+		"progress_records": {
+			{"id": uuid.NewString(), "org_id": oldOrgID, "enrolment_id": oldEnrolment, "content_id": oldContentID, "user_id": oldBobID},
+			{"id": uuid.NewString(), "org_id": oldOrgID, "enrolment_id": uuid.NewString(), "content_id": oldContentID, "user_id": oldBobID},
+		},
+	}
+}
+
+func newImportFixture(t *testing.T) (*Service, *importTables, *fakeDB, *fakeFiles) {
+	t.Helper()
+	tables := &importTables{
+		libraries: map[string]string{"H5P.MultiChoice 1.16": installedLib},
+		slugs:     map[string]bool{"acme": true},
+		users:     map[string]string{"ada@example.com": existingAdaID},
+		inserted:  map[string][]map[string]any{},
+	}
+	db, fake := openFakeDB(t, tables.handle)
+	files := &fakeFiles{files: map[string][]byte{}}
+	return &Service{db: db, files: files}, tables, fake, files
+}
+
+var testManifest = Manifest{Format: archiveFormat, Version: archiveVersion}
+
+func TestImportOrganisation(t *testing.T) {
+	s, tables, db, files := newImportFixture(t)
+	contentJSON := []byte(`{"answers":["yes","no"]}`)
+	archive := buildArchive(t, testManifest, testArchiveTables(), archiveFile{path: oldContentID + "/content.json", data: contentJSON})
+
+	result, err := s.ImportOrganisation(context.Background(), bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"COMMIT"}, db.statements("COMMIT"))
+
+	inserted := func(table string) []map[string]any {
+		t.Helper()
+		require.Len(t, tables.inserted[table], result.Inserted[table], table)
+		return tables.inserted[table]
+	}
+
+	// A taken slug gets a suffix, and billing doesn't carry over
+	org := inserted("organisations")[0]
+	newOrgID := result.OrganisationID.String()
+	assert.Equal(t, newOrgID, org["id"])
+	assert.NotEqual(t, oldOrgID, newOrgID)
+	assert.Equal(t, "acme-2", result.Slug)
+	assert.Equal(t, "acme-2", org["slug"])
+	assert.Equal(t, "", org["stripe_customer_id"])
+	assert.Equal(t, "", org["subscription_id"])
+
+	// Ada is matched by email whatever its case; only Bob is created, without
+	// super admin access or billing identifiers
+	assert.Equal(t, 1, result.MatchedUsers)
+	users := inserted("users")
+	require.Len(t, users, 1)
+	bob := users[0]
+	newBobID := bob["id"].(string)
+	assert.NotEqual(t, oldBobID, newBobID)
+	assert.Equal(t, "bob@example.com", bob["email"])
+	assert.Equal(t, json.Number("16335"), bob["access"])
+	assert.Equal(t, "", bob["customer_id"])
+	assert.Equal(t, "", bob["api_key"])
+
+	members := inserted("organisation_memberships")
+	require.Len(t, members, 2)
+	assert.Equal(t, 1, result.Skipped["organisation_memberships"])
+	assert.Equal(t, map[string]any{"owner": existingAdaID, "member": newBobID},
+		map[string]any{members[0]["role"].(string): members[0]["user_id"], members[1]["role"].(string): members[1]["user_id"]})
+	for _, m := range members {
+		assert.Equal(t, newOrgID, m["organisation_id"])
+		assert.Nil(t, m["invited_by"])
+	}
+
+	// Content uses the installed library and is stored under its new IDs
+	content := inserted("h5p_content")[0]
+	newContentID := content["id"].(string)
+	assert.Equal(t, installedLib, content["library_id"])
+	assert.Equal(t, newBobID, content["created_by"])
+	assert.Equal(t, contentPrefix(result.OrganisationID)+newContentID+"/", content["storage_path"])
+	assert.Empty(t, tables.inserted["h5p_libraries"])
+
+	course := inserted("courses")[0]
+	assert.Equal(t, existingAdaID, course["created_by"])
+	assert.Equal(t, newContentID, inserted("course_items")[0]["content_id"])
+	assert.Equal(t, course["id"], inserted("course_items")[0]["course_id"])
+	enrolment := inserted("enrolments")[0]
+	assert.Equal(t, newBobID, enrolment["user_id"])
+	progress := inserted("progress_records")
+	require.Len(t, progress, 1)
+	assert.Equal(t, enrolment["id"], progress[0]["enrolment_id"])
+	assert.Equal(t, 1, result.Skipped["progress_records"])
+
+	assert.Equal(t, map[string][]byte{contentPrefix(result.OrganisationID) + newContentID + "/content.json": contentJSON}, files.files)
+	assert.Equal(t, 1, result.Files)
+	assert.Equal(t, int64(len(contentJSON)), result.Bytes)
+	assert.Equal(t, []driver.Value{newOrgID, int64(len(contentJSON))}, tables.storageBytes)
+}
+
+func TestImportOrganisation_Twice(t *testing.T) {
+	s, tables, _, _ := newImportFixture(t)
+	archive := buildArchive(t, testManifest, testArchiveTables())
+
+	first, err := s.ImportOrganisation(context.Background(), bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	tables.slugs[first.Slug] = true
+	second, err := s.ImportOrganisation(context.Background(), bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first.OrganisationID, second.OrganisationID)
+	assert.Equal(t, "acme-3", second.Slug)
+	contents := tables.inserted["h5p_content"]
+	require.Len(t, contents, 2)
+	assert.NotEqual(t, contents[0]["id"], contents[1]["id"])
+}
+
+func TestImportOrganisation_Rejected(t *testing.T) {
+	withTables := func(edit func(tables map[string][]map[string]any)) map[string][]map[string]any {
+		tables := testArchiveTables()
+		edit(tables)
+		return tables
+	}
+
+	tests := []struct {
+		name         string
+		archive      func(t *testing.T) []byte
+		wantErr      error
+		wantMessage  string
+		wantRollback bool
+	}{
+		{
+			name:        "not a zip",
+			archive:     func(*testing.T) []byte { return []byte("not a zip") },
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "Invalid archive",
+		},
+		{
+			name: "another format",
+			archive: func(t *testing.T) []byte {
+				return buildArchive(t, Manifest{Format: archiveFormat, Version: archiveVersion + 1}, testArchiveTables())
+			},
+			wantErr:     pkg.BadRequestError{},
+			wantMessage: "Unsupported archive format leaplearn-organisation v2",
+		},
+		{
+			name: "library not installed",
+			archive: func(t *testing.T) []byte {
+				return buildArchive(t, testManifest, withTables(func(tables map[string][]map[string]any) {
+					tables["h5p_libraries"] = append(tables["h5p_libraries"], map[string]any{
+						"id": uuid.NewString(), "machine_name": "H5P.Timeline", "major_version": 1, "minor_version": 1,
+					})
+				}))
+			},
+			wantErr:      pkg.BadRequestError{},
+			wantMessage:  "Install these H5P libraries before importing: H5P.Timeline 1.1",
+			wantRollback: true,
+		},
+		{
+			name: "no organisation",
+			archive: func(t *testing.T) []byte {
+				return buildArchive(t, testManifest, withTables(func(tables map[string][]map[string]any) {
+					delete(tables, "organisations")
+				}))
+			},
+			wantErr:      pkg.BadRequestError{},
+			wantMessage:  "Archive contains no organisation",
+			wantRollback: true,
+		},
+		{
+			name: "malformed organisation ID",
+			archive: func(t *testing.T) []byte {
+				return buildArchive(t, testManifest, withTables(func(tables map[string][]map[string]any) {
+					tables["organisations"][0]["id"] = "acme"
+				}))
+			},
+			wantErr:      pkg.BadRequestError{},
+			wantMessage:  "Invalid id in archive table organisations",
+			wantRollback: true,
+		},
+		{
+			name: "file checksum mismatch",
+			archive: func(t *testing.T) []byte {
+				return buildArchive(t, testManifest, testArchiveTables(),
+					archiveFile{path: oldContentID + "/content.json", data: []byte("{}")},
+					archiveFile{path: oldContentID + "/images/a.png", data: []byte("png"), sum: strings.Repeat("0", 64)},
+				)
+			},
+			wantErr:      pkg.InternalError{},
+			wantMessage:  "checksum mismatch",
+			wantRollback: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, db, files := newImportFixture(t)
+			archive := tt.archive(t)
+
+			result, err := s.ImportOrganisation(context.Background(), bytes.NewReader(archive), int64(len(archive)))
+			assert.Nil(t, result)
+			assert.IsType(t, tt.wantErr, err)
+			assert.Contains(t, err.Error(), tt.wantMessage)
+			assert.Empty(t, db.statements("COMMIT"))
+			if tt.wantRollback {
+				assert.NotEmpty(t, db.statements("ROLLBACK"))
+			}
+			// Files uploaded before the failure are removed again
+			assert.Empty(t, files.files)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package tenant
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Archive format identifiers written to manifest.json.
+const (
+	archiveFormat  = "leaplearn-organisation"
+	archiveVersion = 1
+)
+
+// Manifest is the archive's table of contents, stored as manifest.json.
+type Manifest struct {
+	Format           string         `json:"format"`
+	Version          int            `json:"version"`
+	ExportedAt       time.Time      `json:"exportedAt"`
+	Source           string         `json:"source"` // CoreURL of the exporting instance
+	OrganisationID   uuid.UUID      `json:"organisationId"`
+	OrganisationName string         `json:"organisationName"`
+	Tables           map[string]int `json:"tables"` // row count per table
+	Files            []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a bundled storage object. Path is relative to the
+// organisation's content prefix, i.e. "<contentId>/<file>".
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ImportResult reports what ImportOrganisation created.
+type ImportResult struct {
+	OrganisationID uuid.UUID      `json:"organisationId"`
+	Slug           string         `json:"slug"`
+	Inserted       map[string]int `json:"inserted"`
+	Skipped        map[string]int `json:"skipped"`      // rows whose required references didn't resolve
+	MatchedUsers   int            `json:"matchedUsers"` // users that already existed, matched by email
+	Files          int            `json:"files"`
+	Bytes          int64          `json:"bytes"`
+}
+
+// Organisation deletion statuses. A scheduled deletion can still be cancelled
+// by restoring the organisation; once teardown has started it can't.
+const (
+	DeletionScheduled = "scheduled"
+	DeletionRunning   = "running"
+	DeletionFailed    = "failed"
+	DeletionCompleted = "completed"
+	DeletionCancelled = "cancelled"
+)
+
+// Deletion is an organisation's deletion request and its teardown progress.
+type Deletion struct {
+	ID               uuid.UUID  `json:"id"`
+	OrganisationID   uuid.UUID  `json:"organisationId"`
+	OrganisationName string     `json:"organisationName"`
+	OrganisationSlug string     `json:"organisationSlug"`
+	RequestedBy      *uuid.UUID `json:"requestedBy"`
+	RequestedAt      time.Time  `json:"requestedAt"`
+	ScheduledFor     time.Time  `json:"scheduledFor"` // end of the recovery window
+	Status           string     `json:"status"`
+	Stage            string     `json:"stage"` // stage in progress, or the one that failed
+	Stages           []Stage    `json:"stages"`
+	Percent          int        `json:"percent"` // share of stages done
+	Attempts         int        `json:"attempts"`
+	Error            string     `json:"error,omitempty"`
+	ConfirmedBy      *uuid.UUID `json:"confirmedBy"` // super admin who skipped the recovery window
+	CancelledAt      *time.Time `json:"cancelledAt"`
+	StartedAt        *time.Time `json:"startedAt"`
+	CompletedAt      *time.Time `json:"completedAt"`
+
+	progress map[string]*Stage
+}
+
+// Stage is one teardown stage's progress. Removed counts rows per table, or
+// files for the storage stage.
+type Stage struct {
+	Name    string           `json:"name"`
+	Done    bool             `json:"done"`
+	Removed map[string]int64 `json:"removed"`
+}
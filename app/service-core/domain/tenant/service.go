@@ -0,0 +1,41 @@
+package tenant
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"service-core/config"
+	"service-core/domain/file"
+
+	"github.com/google/uuid"
+)
+
+// billing cancels a deleted organisation's subscription and removes its
+// Stripe customer.
+type billing interface {
+	CancelForDeletion(ctx context.Context, organisationID uuid.UUID) error
+	ResumeAfterRestore(ctx context.Context, organisationID uuid.UUID) error
+	DeleteCustomer(ctx context.Context, organisationID uuid.UUID) error
+}
+
+// Service manages an organisation as a whole: moving it between instances,
+// e.g. from the cloud to a self-hosted deployment, and deleting it. It works
+// on whole tables rather than sqlc queries, so it needs the Postgres
+// connection itself.
+type Service struct {
+	cfg     *config.Config
+	db      *sql.DB
+	files   file.Provider
+	billing billing
+}
+
+// NewService creates a new tenant service
+func NewService(cfg *config.Config, db *sql.DB, files file.Provider, billing billing) *Service {
+	return &Service{cfg: cfg, db: db, files: files, billing: billing}
+}
+
+// contentPrefix is where an organisation's H5P content files are stored.
+func contentPrefix(orgID uuid.UUID) string {
+	return fmt.Sprintf("h5p-content/%s/", orgID)
+}
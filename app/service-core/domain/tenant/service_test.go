@@ -0,0 +1,192 @@
+package tenant
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"service-core/domain/file"
+
+	"github.com/google/uuid"
+)
+
+// fakeDB is a database/sql driver that hands every statement to handle. The
+// service runs raw SQL against Postgres, so tests stand in for the tables it
+// touches with handlers over in-memory state.
+type fakeDB struct {
+	mu     sync.Mutex
+	handle func(query string, args []driver.Value) (*fakeResult, error)
+	log    []string // statements in order, with BEGIN, COMMIT and ROLLBACK
+}
+
+// fakeResult is a statement's outcome: rows for a query, or how many rows an
+// exec affected. A nil result is an exec affecting nothing.
+type fakeResult struct {
+	rows     [][]driver.Value
+	affected int64
+}
+
+func openFakeDB(t *testing.T, handle func(query string, args []driver.Value) (*fakeResult, error)) (*sql.DB, *fakeDB) {
+	t.Helper()
+	f := &fakeDB{handle: handle}
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { db.Close() })
+	return db, f
+}
+
+// statements returns the logged statements that start with prefix.
+func (f *fakeDB) statements(prefix string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, q := range f.log {
+		if strings.HasPrefix(strings.TrimSpace(q), prefix) {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+func (f *fakeDB) record(query string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, query)
+}
+
+func (f *fakeDB) run(query string, named []driver.NamedValue) (*fakeResult, error) {
+	f.record(query)
+	args := make([]driver.Value, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+	res, err := f.handle(query, args)
+	if err == nil && res == nil {
+		res = &fakeResult{}
+	}
+	return res, err
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return f }
+func (f *fakeDB) Open(string) (driver.Conn, error)             { return &fakeConn{db: f}, nil }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeDB: prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.record("BEGIN")
+	return fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(res.affected), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{rows: res.rows}, nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx fakeTx) Commit() error   { tx.db.record("COMMIT"); return nil }
+func (tx fakeTx) Rollback() error { tx.db.record("ROLLBACK"); return nil }
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// fakeFiles holds storage keys in memory. Listing failList fails.
+type fakeFiles struct {
+	file.Provider
+
+	files    map[string][]byte
+	listed   []string
+	failList string
+}
+
+func (f *fakeFiles) Upload(_ context.Context, fl *file.File) error {
+	f.files[fl.Key] = fl.Data
+	return nil
+}
+
+func (f *fakeFiles) Remove(_ context.Context, key string) error {
+	delete(f.files, key)
+	return nil
+}
+
+func (f *fakeFiles) ListByPrefix(_ context.Context, prefix string) ([]string, error) {
+	f.listed = append(f.listed, prefix)
+	if prefix == f.failList {
+		return nil, errors.New("storage unavailable")
+	}
+	var keys []string
+	for key := range f.files {
+		if rel, ok := strings.CutPrefix(key, prefix); ok {
+			keys = append(keys, rel)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// fakeBilling records the Stripe customers removed.
+type fakeBilling struct {
+	billing
+
+	deleted []uuid.UUID
+	err     error
+}
+
+func (b *fakeBilling) DeleteCustomer(_ context.Context, organisationID uuid.UUID) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.deleted = append(b.deleted, organisationID)
+	return nil
+}
@@ -0,0 +1,145 @@
+package tenant
+
+// ref is a foreign key column and the table whose IDs it holds. Rows whose
+// required refs can't be resolved on import are skipped; optional refs that
+// can't be resolved are set to NULL.
+type ref struct {
+	table    string
+	required bool
+}
+
+// tableSpec describes how one table is exported and re-keyed on import.
+type tableSpec struct {
+	name string
+	// where selects the organisation's rows; $1 is the organisation ID
+	where string
+	refs  map[string]ref
+}
+
+// exportTables lists the exported tables in insert order, so every table comes
+// after the tables it references. Instance-specific tables (hub registration,
+// Stripe payment methods, storage usage, locks) are left out; storage usage
+// is recomputed from the imported files.
+var exportTables = []tableSpec{
+	{
+		name:  "organisations",
+		where: "id = $1",
+	},
+	{
+		name: "users",
+		where: `id IN (SELECT user_id FROM organisation_memberships WHERE organisation_id = $1
+			UNION SELECT user_id FROM enrolments WHERE org_id = $1)`,
+		refs: map[string]ref{"default_organisation_id": {table: "organisations"}},
+	},
+	{
+		name:  "organisation_memberships",
+		where: "organisation_id = $1",
+		refs: map[string]ref{
+			"organisation_id": {table: "organisations", required: true},
+			"user_id":         {table: "users", required: true},
+			"invited_by":      {table: "users"},
+		},
+	},
+	{
+		name:  "organisation_activity_log",
+		where: "organisation_id = $1",
+		refs: map[string]ref{
+			"organisation_id": {table: "organisations", required: true},
+			"user_id":         {table: "users"},
+		},
+	},
+	{
+		name:  "h5p_libraries",
+		where: "id IN (SELECT library_id FROM h5p_org_libraries WHERE org_id = $1 UNION SELECT library_id FROM h5p_content WHERE org_id = $1)",
+	},
+	{
+		name:  "h5p_org_libraries",
+		where: "org_id = $1",
+		refs: map[string]ref{
+			"org_id":     {table: "organisations", required: true},
+			"library_id": {table: "h5p_libraries", required: true},
+		},
+	},
+	{
+		name:  "h5p_content_folders",
+		where: "org_id = $1",
+		refs: map[string]ref{
+			"org_id":    {table: "organisations", required: true},
+			"parent_id": {table: "h5p_content_folders"},
+		},
+	},
+	{
+		name:  "h5p_content",
+		where: "org_id = $1",
+		refs: map[string]ref{
+			"org_id":     {table: "organisations", required: true},
+			"library_id": {table: "h5p_libraries", required: true},
+			"created_by": {table: "users"},
+		},
+	},
+	{
+		name:  "courses",
+		where: "org_id = $1",
+		refs: map[string]ref{
+			"org_id":     {table: "organisations", required: true},
+			"created_by": {table: "users"},
+		},
+	},
+	{
+		name:  "course_items",
+		where: "course_id IN (SELECT id FROM courses WHERE org_id = $1)",
+		refs: map[string]ref{
+			"course_id":  {table: "courses", required: true},
+			"content_id": {table: "h5p_content"},
+		},
+	},
+	{
+		name:  "enrolments",
+		where: "org_id = $1",
+		refs: map[string]ref{
+			"org_id":    {table: "organisations", required: true},
+			"course_id": {table: "courses", required: true},
+			"user_id":   {table: "users"},
+		},
+	},
+	{
+		name:  "progress_records",
+		where: "org_id = $1",
+		refs: map[string]ref{
+			"org_id":       {table: "organisations", required: true},
+			"enrolment_id": {table: "enrolments", required: true},
+			"content_id":   {table: "h5p_content", required: true},
+			"user_id":      {table: "users"},
+		},
+	},
+	{
+		name:  "xapi_statements",
+		where: "org_id = $1",
+		refs: map[string]ref{
+			"org_id":     {table: "organisations", required: true},
+			"user_id":    {table: "users"},
+			"content_id": {table: "h5p_content"},
+		},
+	},
+	{
+		name:  "h5p_content_user_state",
+		where: "content_id IN (SELECT id FROM h5p_content WHERE org_id = $1)",
+		refs: map[string]ref{
+			"content_id": {table: "h5p_content", required: true},
+			"user_id":    {table: "users", required: true},
+		},
+	},
+	{
+		name:  "organisation_webhooks",
+		where: "organisation_id = $1",
+		refs:  map[string]ref{"organisation_id": {table: "organisations", required: true}},
+	},
+	{
+		name:  "organisation_retention_policies",
+		where: "organisation_id = $1",
+		refs: map[string]ref{
+			"organisation_id": {table: "organisations", required: true},
+			"updated_by":      {table: "users"},
+		},
+	},
+}
@@ -0,0 +1,285 @@
+package tenant
+
+import (
+	"app/pkg"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// teardownBatchSize is the most rows one DELETE removes, so a large
+	// organisation doesn't hold long locks or build a huge transaction
+	teardownBatchSize = 5000
+	// maxTeardownAttempts is how often a failing teardown is retried before it
+	// waits for a super admin to confirm it again
+	maxTeardownAttempts = 5
+	// maxTeardownsPerRun caps the deletions one run works through
+	maxTeardownsPerRun = 10
+)
+
+// teardownStage is one step of tearing down a deleted organisation. Stages
+// run in order and each is safe to re-run, so a failed teardown resumes at the
+// stage that failed.
+type teardownStage struct {
+	name string
+	run  func(s *Service, ctx context.Context, t *teardown) error
+}
+
+var teardownStages = []teardownStage{
+	{name: "content", run: func(s *Service, ctx context.Context, t *teardown) error {
+		return s.purgeTables(ctx, t, contentTables)
+	}},
+	{name: "storage", run: (*Service).purgeFiles},
+	{name: "audits", run: func(s *Service, ctx context.Context, t *teardown) error {
+		return s.purgeTables(ctx, t, auditTables)
+	}},
+	{name: "usage", run: func(s *Service, ctx context.Context, t *teardown) error {
+		return s.purgeTables(ctx, t, usageTables)
+	}},
+	{name: "billing", run: func(s *Service, ctx context.Context, t *teardown) error {
+		if err := s.billing.DeleteCustomer(ctx, t.OrganisationID); err != nil {
+			return err
+		}
+		return s.purgeTables(ctx, t, billingTables)
+	}},
+	{name: "organisation", run: func(s *Service, ctx context.Context, t *teardown) error {
+		// Whatever is left, e.g. memberships and rows added since the
+		// earlier stages ran, goes with the organisation
+		return s.purgeTables(ctx, t, []tableSpec{
+			{name: "organisation_memberships", where: "organisation_id = $1"},
+			{name: "organisations", where: "id = $1"},
+		})
+	}},
+}
+
+// The tables each stage empties, children before parents. Tables left out go
+// with their parent through ON DELETE CASCADE.
+var (
+	contentTables = []tableSpec{
+		{name: "h5p_content_user_state", where: "content_id IN (SELECT id FROM h5p_content WHERE org_id = $1)"},
+		{name: "xapi_statements", where: "org_id = $1"},
+		{name: "progress_records", where: "org_id = $1"},
+		{name: "enrolments", where: "org_id = $1"},
+		{name: "course_items", where: "course_id IN (SELECT id FROM courses WHERE org_id = $1)"},
+		{name: "courses", where: "org_id = $1"},
+		{name: "h5p_content", where: "org_id = $1"},
+		{name: "h5p_content_folders", where: "org_id = $1"},
+		{name: "h5p_org_libraries", where: "org_id = $1"},
+		{name: "h5p_hub_registrations", where: "org_id = $1"},
+	}
+	auditTables = []tableSpec{
+		{name: "seo_page_experience", where: "organisation_id = $1"},
+		{name: "seo_schedule_runs", where: "organisation_id = $1"},
+		{name: "seo_schedules", where: "organisation_id = $1"},
+		{name: "seo_rank_checks", where: "organisation_id = $1"},
+		{name: "seo_keyword_refreshes", where: "organisation_id = $1"},
+		{name: "seo_keywords", where: "list_id IN (SELECT id FROM seo_keyword_lists WHERE organisation_id = $1)"},
+		{name: "seo_keyword_lists", where: "organisation_id = $1"},
+		{name: "seo_backlink_prospects", where: "organisation_id = $1"},
+		{name: "rank_positions", where: "tracker_id IN (SELECT id FROM rank_trackers WHERE organisation_id = $1)"},
+		{name: "rank_tracker_runs", where: "organisation_id = $1"},
+		{name: "rank_tracker_keywords", where: "tracker_id IN (SELECT id FROM rank_trackers WHERE organisation_id = $1)"},
+		{name: "rank_trackers", where: "organisation_id = $1"},
+		{name: "competitor_alerts", where: "organisation_id = $1"},
+		{name: "competitor_keyword_gaps", where: "pin_id IN (SELECT id FROM competitor_pins WHERE organisation_id = $1)"},
+		{name: "competitor_snapshots", where: "organisation_id = $1"},
+		{name: "competitor_pins", where: "organisation_id = $1"},
+	}
+	usageTables = []tableSpec{
+		{name: "organisation_storage_usage", where: "organisation_id = $1"},
+		{name: "organisation_activity_log", where: "organisation_id = $1"},
+		{name: "access_reviews", where: "organisation_id = $1"},
+		{name: "webhook_deliveries", where: "organisation_id = $1"},
+		{name: "organisation_webhooks", where: "organisation_id = $1"},
+	}
+	billingTables = []tableSpec{
+		{name: "organisation_payment_methods", where: "organisation_id = $1"},
+		{name: "organisation_billing_contacts", where: "organisation_id = $1"},
+	}
+)
+
+// storagePrefixes are where an organisation's files are stored.
+func storagePrefixes(orgID uuid.UUID) []string {
+	return []string{
+		contentPrefix(orgID),
+		fmt.Sprintf("h5p-media/%s/", orgID),
+		fmt.Sprintf("access-reviews/%s/", orgID),
+		fmt.Sprintf("certificates/%s/", orgID),
+		fmt.Sprintf("seo-raw/%s/", orgID),
+	}
+}
+
+// teardown tracks one deletion's progress through the stages.
+type teardown struct {
+	*Deletion
+	stage *Stage // the stage running
+}
+
+// RunDueTeardowns tears down organisations whose recovery window is over,
+// resuming those interrupted or failed earlier. It returns how many were
+// removed completely.
+func (s *Service) RunDueTeardowns(ctx context.Context) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM organisation_deletions
+		WHERE scheduled_for <= now()
+			AND (status IN ('scheduled', 'running') OR status = 'failed' AND attempts < $1)
+		ORDER BY scheduled_for
+		LIMIT $2`, maxTeardownAttempts, maxTeardownsPerRun)
+	if err != nil {
+		return 0, pkg.InternalError{Message: "Error listing due deletions", Err: err}
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, pkg.InternalError{Message: "Error listing due deletions", Err: err}
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, pkg.InternalError{Message: "Error listing due deletions", Err: err}
+	}
+
+	completed := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		d, err := scanDeletion(s.db.QueryRowContext(ctx, `UPDATE organisation_deletions
+			SET status = 'running', attempts = attempts + 1, error = '', started_at = COALESCE(started_at, now())
+			WHERE id = $1 AND status IN ('scheduled', 'running', 'failed')
+			RETURNING `+deletionColumns, id))
+		if err != nil {
+			// Restored since it was listed, or a database error; either way
+			// there's nothing to tear down now
+			slog.Warn("Skipping organisation teardown", "deletionID", id, "error", err)
+			continue
+		}
+		if err := s.runTeardown(ctx, d); err != nil {
+			slog.Error("Organisation teardown failed", "deletionID", d.ID, "organisationID", d.OrganisationID,
+				"stage", d.Stage, "attempt", d.Attempts, "error", err)
+			s.failTeardown(ctx, d, err)
+			continue
+		}
+		completed++
+	}
+	return completed, nil
+}
+
+func (s *Service) runTeardown(ctx context.Context, d *Deletion) error {
+	t := &teardown{Deletion: d}
+	for _, stage := range teardownStages {
+		p := d.progress[stage.name]
+		if p == nil {
+			p = &Stage{Name: stage.name}
+			d.progress[stage.name] = p
+		}
+		if p.Done {
+			continue
+		}
+		if p.Removed == nil {
+			p.Removed = make(map[string]int64)
+		}
+		t.stage = p
+		d.Stage = stage.name
+		if err := s.saveProgress(ctx, d); err != nil {
+			return err
+		}
+		if err := stage.run(s, ctx, t); err != nil {
+			return err
+		}
+		p.Done = true
+		if err := s.saveProgress(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.db.ExecContext(ctx, `UPDATE organisation_deletions
+		SET status = 'completed', stage = '', completed_at = now()
+		WHERE id = $1`, d.ID)
+	if err != nil {
+		return fmt.Errorf("completing deletion: %w", err)
+	}
+	slog.Info("Organisation torn down", "deletionID", d.ID, "organisationID", d.OrganisationID)
+	return nil
+}
+
+// purgeTables empties the organisation's rows from each table in batches,
+// counting them in the stage's progress as it goes.
+func (s *Service) purgeTables(ctx context.Context, t *teardown, tables []tableSpec) error {
+	for _, table := range tables {
+		q := fmt.Sprintf("DELETE FROM %[1]s WHERE ctid IN (SELECT ctid FROM %[1]s WHERE %[2]s LIMIT %[3]d)",
+			table.name, table.where, teardownBatchSize)
+		for {
+			res, err := s.db.ExecContext(ctx, q, t.OrganisationID)
+			if err != nil {
+				return fmt.Errorf("deleting from %s: %w", table.name, err)
+			}
+			n, _ := res.RowsAffected()
+			if n > 0 {
+				t.stage.Removed[table.name] += n
+				if err := s.saveProgress(ctx, t.Deletion); err != nil {
+					return err
+				}
+			}
+			if n < teardownBatchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// purgeFiles removes the organisation's stored files, counting them per
+// prefix.
+func (s *Service) purgeFiles(ctx context.Context, t *teardown) error {
+	for _, prefix := range storagePrefixes(t.OrganisationID) {
+		keys, err := s.files.ListByPrefix(ctx, prefix)
+		if err != nil {
+			return fmt.Errorf("listing %s: %w", prefix, err)
+		}
+		for i, rel := range keys {
+			key := prefix + rel
+			if err := s.files.Remove(ctx, key); err != nil {
+				return fmt.Errorf("removing %s: %w", key, err)
+			}
+			t.stage.Removed[prefix]++
+			if (i+1)%teardownBatchSize == 0 {
+				if err := s.saveProgress(ctx, t.Deletion); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) saveProgress(ctx context.Context, d *Deletion) error {
+	progress, err := json.Marshal(d.progress)
+	if err != nil {
+		return fmt.Errorf("encoding progress: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `UPDATE organisation_deletions SET stage = $2, progress = $3 WHERE id = $1`,
+		d.ID, d.Stage, progress)
+	if err != nil {
+		return fmt.Errorf("saving progress: %w", err)
+	}
+	return nil
+}
+
+// failTeardown records the error; the next run retries from the failed stage
+// until the attempts run out.
+func (s *Service) failTeardown(ctx context.Context, d *Deletion, cause error) {
+	progress, _ := json.Marshal(d.progress)
+	_, err := s.db.ExecContext(context.WithoutCancel(ctx), `UPDATE organisation_deletions
+		SET status = 'failed', stage = $2, progress = $3, error = $4
+		WHERE id = $1`, d.ID, d.Stage, progress, cause.Error())
+	if err != nil {
+		slog.Error("Error recording failed teardown", "deletionID", d.ID, "error", err)
+	}
+}
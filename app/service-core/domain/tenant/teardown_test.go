@@ -0,0 +1,333 @@
+package tenant
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deletionRow is a row of organisation_deletions.
+type deletionRow struct {
+	id       uuid.UUID
+	orgID    uuid.UUID
+	status   string
+	stage    string
+	progress []byte
+	attempts int64
+	err      string
+}
+
+// teardownTables stands in for organisation_deletions and counts the rows
+// each organisation table has left. DELETEs from a table in failing fail.
+type teardownTables struct {
+	deletions []*deletionRow
+	rows      map[string]int64
+	failing   map[string]error
+	// afterList runs once the due deletions are listed, e.g. to restore one
+	afterList func()
+	listArgs  []driver.Value
+}
+
+func (d *teardownTables) deletion(id driver.Value) *deletionRow {
+	for _, del := range d.deletions {
+		if del.id.String() == id {
+			return del
+		}
+	}
+	return nil
+}
+
+func (d *teardownTables) handle(query string, args []driver.Value) (*fakeResult, error) {
+	switch {
+	case strings.HasPrefix(query, "SELECT id FROM organisation_deletions"):
+		d.listArgs = args
+		maxAttempts := args[0].(int64)
+		res := &fakeResult{}
+		for _, del := range d.deletions {
+			if del.status == DeletionScheduled || del.status == DeletionRunning || del.status == DeletionFailed && del.attempts < maxAttempts {
+				res.rows = append(res.rows, []driver.Value{del.id.String()})
+			}
+		}
+		if d.afterList != nil {
+			d.afterList()
+		}
+		return res, nil
+	case strings.Contains(query, "SET status = 'running'"):
+		del := d.deletion(args[0])
+		if del == nil || del.status != DeletionScheduled && del.status != DeletionRunning && del.status != DeletionFailed {
+			return &fakeResult{}, nil
+		}
+		del.status = DeletionRunning
+		del.attempts++
+		del.err = ""
+		now := time.Now()
+		return &fakeResult{rows: [][]driver.Value{{
+			del.id.String(), del.orgID.String(), "Acme", "acme", nil, now, now,
+			del.status, del.stage, del.progress, del.attempts, del.err, nil, nil, now, nil,
+		}}}, nil
+	case strings.Contains(query, "SET stage = $2, progress = $3"):
+		del := d.deletion(args[0])
+		del.stage, del.progress = args[1].(string), args[2].([]byte)
+		return nil, nil
+	case strings.HasPrefix(query, "DELETE FROM "):
+		table := strings.Fields(query)[2]
+		if err := d.failing[table]; err != nil {
+			return nil, err
+		}
+		n := min(d.rows[table], teardownBatchSize)
+		d.rows[table] -= n
+		return &fakeResult{affected: n}, nil
+	case strings.Contains(query, "SET status = 'completed'"):
+		del := d.deletion(args[0])
+		del.status, del.stage = DeletionCompleted, ""
+		return nil, nil
+	case strings.Contains(query, "SET status = 'failed'"):
+		del := d.deletion(args[0])
+		del.status, del.stage, del.progress, del.err = DeletionFailed, args[1].(string), args[2].([]byte), args[3].(string)
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected statement: %s", query)
+}
+
+// stages decodes the stored progress of a deletion.
+func (del *deletionRow) stages(t *testing.T) map[string]*Stage {
+	t.Helper()
+	var progress map[string]*Stage
+	require.NoError(t, json.Unmarshal(del.progress, &progress))
+	return progress
+}
+
+type teardownFixture struct {
+	service *Service
+	db      *fakeDB
+	tables  *teardownTables
+	files   *fakeFiles
+	billing *fakeBilling
+	del     *deletionRow
+}
+
+// newTeardownFixture has one organisation due for teardown, with rows in
+// tables of every stage and files under two of its storage prefixes.
+func newTeardownFixture(t *testing.T) *teardownFixture {
+	t.Helper()
+	del := &deletionRow{id: uuid.New(), orgID: uuid.New(), status: DeletionScheduled, progress: []byte("{}")}
+	tables := &teardownTables{
+		deletions: []*deletionRow{del},
+		rows: map[string]int64{
+			"h5p_content":                  3,
+			"xapi_statements":              2*teardownBatchSize + 20,
+			"seo_page_experience":          6,
+			"seo_keywords":                 2,
+			"organisation_activity_log":    4,
+			"organisation_payment_methods": 1,
+			"organisation_memberships":     3,
+			"organisations":                1,
+		},
+		failing: map[string]error{},
+	}
+	db, fake := openFakeDB(t, tables.handle)
+	files := &fakeFiles{files: map[string][]byte{
+		contentPrefix(del.orgID) + "c1/content.json":              nil,
+		contentPrefix(del.orgID) + "c1/images/a.png":              nil,
+		fmt.Sprintf("certificates/%s/cert.pdf", del.orgID):        nil,
+		fmt.Sprintf("certificates/%s/cert.pdf", uuid.New()):       nil,
+		fmt.Sprintf("h5p-content/%s/c2/content.json", uuid.New()): nil,
+	}}
+	billing := &fakeBilling{}
+	return &teardownFixture{
+		service: &Service{db: db, files: files, billing: billing},
+		db:      fake,
+		tables:  tables,
+		files:   files,
+		billing: billing,
+		del:     del,
+	}
+}
+
+func TestRunDueTeardowns(t *testing.T) {
+	errStripe := errors.New("stripe unavailable")
+
+	tests := []struct {
+		name          string
+		setup         func(f *teardownFixture)
+		wantCompleted int
+		wantStatus    string
+		wantStage     string
+		wantAttempts  int64
+		wantError     string
+		wantDone      []string // stages done once the run is over
+	}{
+		{
+			name:          "tears down every stage",
+			wantCompleted: 1,
+			wantStatus:    DeletionCompleted,
+			wantAttempts:  1,
+			wantDone:      []string{"content", "storage", "audits", "usage", "billing", "organisation"},
+		},
+		{
+			// The claim only takes deletions that are still due, so a
+			// restore that commits after the list leaves it alone
+			name: "restored since it was listed",
+			setup: func(f *teardownFixture) {
+				f.tables.afterList = func() { f.del.status = DeletionCancelled }
+			},
+			wantStatus: DeletionCancelled,
+		},
+		{
+			name: "failed earlier and has attempts left",
+			setup: func(f *teardownFixture) {
+				f.del.status, f.del.attempts = DeletionFailed, maxTeardownAttempts-1
+			},
+			wantCompleted: 1,
+			wantStatus:    DeletionCompleted,
+			wantAttempts:  maxTeardownAttempts,
+			wantDone:      []string{"content", "storage", "audits", "usage", "billing", "organisation"},
+		},
+		{
+			// Waits for a super admin to confirm it again
+			name: "out of attempts",
+			setup: func(f *teardownFixture) {
+				f.del.status, f.del.attempts, f.del.err = DeletionFailed, maxTeardownAttempts, "stripe unavailable"
+			},
+			wantStatus:   DeletionFailed,
+			wantAttempts: maxTeardownAttempts,
+			wantError:    "stripe unavailable",
+		},
+		{
+			name: "a stage fails",
+			setup: func(f *teardownFixture) {
+				f.billing.err = errStripe
+			},
+			wantStatus:   DeletionFailed,
+			wantStage:    "billing",
+			wantAttempts: 1,
+			wantError:    "stripe unavailable",
+			wantDone:     []string{"content", "storage", "audits", "usage"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTeardownFixture(t)
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			completed, err := f.service.RunDueTeardowns(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCompleted, completed)
+			assert.Equal(t, []driver.Value{int64(maxTeardownAttempts), int64(maxTeardownsPerRun)}, f.tables.listArgs)
+			assert.Equal(t, tt.wantStatus, f.del.status)
+			assert.Equal(t, tt.wantStage, f.del.stage)
+			assert.Equal(t, tt.wantAttempts, f.del.attempts)
+			assert.Equal(t, tt.wantError, f.del.err)
+
+			var done []string
+			for _, stage := range teardownStages {
+				if p := f.del.stages(t)[stage.name]; p != nil && p.Done {
+					done = append(done, stage.name)
+				}
+			}
+			assert.Equal(t, tt.wantDone, done)
+
+			if len(tt.wantDone) == 0 {
+				assert.Empty(t, f.db.statements("DELETE"))
+				assert.Empty(t, f.files.listed)
+			}
+			if tt.wantStatus == DeletionCompleted {
+				for table, left := range f.tables.rows {
+					assert.Zero(t, left, table)
+				}
+				assert.Equal(t, []uuid.UUID{f.del.orgID}, f.billing.deleted)
+				// Other organisations' files are left alone
+				assert.Len(t, f.files.files, 2)
+			} else {
+				assert.Empty(t, f.billing.deleted)
+			}
+		})
+	}
+}
+
+func TestRunDueTeardowns_Progress(t *testing.T) {
+	f := newTeardownFixture(t)
+	_, err := f.service.RunDueTeardowns(context.Background())
+	require.NoError(t, err)
+
+	progress := f.del.stages(t)
+	assert.Equal(t, map[string]int64{"h5p_content": 3, "xapi_statements": 2*teardownBatchSize + 20}, progress["content"].Removed)
+	assert.Equal(t, map[string]int64{
+		contentPrefix(f.del.orgID):                   2,
+		fmt.Sprintf("certificates/%s/", f.del.orgID): 1,
+	}, progress["storage"].Removed)
+	assert.Equal(t, map[string]int64{"organisation_memberships": 3, "organisations": 1}, progress["organisation"].Removed)
+
+	// Large tables go in batches, and every table is asked until a batch
+	// comes back short
+	xapi := 0
+	for _, q := range f.db.statements("DELETE FROM xapi_statements ") {
+		assert.Contains(t, q, fmt.Sprintf("LIMIT %d", teardownBatchSize))
+		xapi++
+	}
+	assert.Equal(t, 3, xapi)
+	assert.Len(t, f.db.statements("DELETE FROM h5p_content "), 1)
+}
+
+func TestRunDueTeardowns_Resume(t *testing.T) {
+	f := newTeardownFixture(t)
+	f.tables.failing["seo_keywords"] = errors.New("lock timeout")
+
+	completed, err := f.service.RunDueTeardowns(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, completed)
+	assert.Equal(t, DeletionFailed, f.del.status)
+	assert.Equal(t, "audits", f.del.stage)
+	assert.Equal(t, "deleting from seo_keywords: lock timeout", f.del.err)
+	progress := f.del.stages(t)
+	assert.True(t, progress["content"].Done)
+	assert.True(t, progress["storage"].Done)
+	assert.False(t, progress["audits"].Done)
+	// What the failed stage removed before the failure is kept
+	assert.Equal(t, map[string]int64{"seo_page_experience": 6}, progress["audits"].Removed)
+
+	// The next run starts at the stage that failed
+	delete(f.tables.failing, "seo_keywords")
+	f.db.log = nil
+	f.files.listed = nil
+	completed, err = f.service.RunDueTeardowns(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, completed)
+	assert.Equal(t, DeletionCompleted, f.del.status)
+	assert.Equal(t, int64(2), f.del.attempts)
+	assert.Empty(t, f.db.statements("DELETE FROM h5p_content "))
+	assert.Empty(t, f.files.listed)
+	progress = f.del.stages(t)
+	assert.Equal(t, map[string]int64{"seo_page_experience": 6, "seo_keywords": 2}, progress["audits"].Removed)
+	assert.Equal(t, map[string]int64{"h5p_content": 3, "xapi_statements": 2*teardownBatchSize + 20}, progress["content"].Removed)
+}
+
+func TestRunDueTeardowns_GivesUp(t *testing.T) {
+	f := newTeardownFixture(t)
+	f.files.failList = contentPrefix(f.del.orgID)
+
+	for range maxTeardownAttempts + 2 {
+		completed, err := f.service.RunDueTeardowns(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, completed)
+	}
+	// Every attempt failed at storage, and once they ran out the deletion
+	// was no longer claimed
+	assert.Equal(t, DeletionFailed, f.del.status)
+	assert.Equal(t, "storage", f.del.stage)
+	assert.Equal(t, int64(maxTeardownAttempts), f.del.attempts)
+	assert.Len(t, f.files.listed, maxTeardownAttempts)
+	assert.Len(t, f.db.statements("DELETE FROM h5p_content "), 1)
+	assert.Contains(t, f.del.err, "storage unavailable")
+}
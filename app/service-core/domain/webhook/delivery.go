@@ -0,0 +1,269 @@
+package webhook
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Delivery statuses
+const (
+	DeliveryPending   = "pending"   // waiting for its next attempt
+	DeliveryDelivered = "delivered" // the endpoint answered 2xx
+	DeliveryFailed    = "failed"    // given up after maxDeliveryAttempts
+)
+
+const (
+	// maxDeliveryAttempts is how often a delivery is tried before it's given up
+	maxDeliveryAttempts = 8
+	// retryBackoff is the wait after the first failed attempt. It doubles with
+	// each further failure, up to maxRetryBackoff.
+	retryBackoff    = time.Minute
+	maxRetryBackoff = 4 * time.Hour
+	// deliveryLease is how long an attempt holds a delivery before RetryDue may
+	// take it over
+	deliveryLease = 2 * time.Minute
+	// retryBatchSize caps the deliveries one RetryDue run attempts
+	retryBatchSize = 200
+	// retryConcurrency is how many deliveries RetryDue attempts at once
+	retryConcurrency = 8
+	// deliveryRetention is how long finished deliveries stay in the log
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+// Delivery is the API representation of one event sent to a webhook
+type Delivery struct {
+	ID             uuid.UUID  `json:"id"`
+	EventID        uuid.UUID  `json:"eventId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	ResponseStatus *int32     `json:"responseStatus"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt"` // only while pending
+	DeliveredAt    *time.Time `json:"deliveredAt"`
+}
+
+func toDelivery(d query.WebhookDelivery) Delivery {
+	result := Delivery{
+		ID:        d.ID,
+		EventID:   d.EventID,
+		EventType: d.EventType,
+		Status:    d.Status,
+		Attempts:  d.Attempts,
+		Error:     d.Error,
+		CreatedAt: d.CreatedAt,
+	}
+	if d.ResponseStatus.Valid {
+		result.ResponseStatus = &d.ResponseStatus.Int32
+	}
+	if d.LastAttemptAt.Valid {
+		result.LastAttemptAt = &d.LastAttemptAt.Time
+	}
+	if d.Status == DeliveryPending {
+		result.NextAttemptAt = &d.NextAttemptAt
+	}
+	if d.DeliveredAt.Valid {
+		result.DeliveredAt = &d.DeliveredAt.Time
+	}
+	return result
+}
+
+// DeliverySummary reports what a RetryDue run did
+type DeliverySummary struct {
+	Attempted int   `json:"attempted"`
+	Delivered int   `json:"delivered"`
+	Failed    int   `json:"failed"` // given up for good
+	Pruned    int64 `json:"pruned"`
+}
+
+// backoff returns how long to wait before retrying a delivery that has failed
+// attempts times.
+func backoff(attempts int32) time.Duration {
+	if attempts < 1 {
+		return retryBackoff
+	}
+	d := retryBackoff << (attempts - 1)
+	if d <= 0 || d > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return d
+}
+
+// attempt sends a delivery once and records the outcome, scheduling a retry
+// with backoff if it failed and attempts remain. It returns the delivery's new
+// status.
+func (s *Service) attempt(ctx context.Context, hook query.OrganisationWebhook, d query.WebhookDelivery) string {
+	code, err := s.send(ctx, hook, d)
+	now := time.Now()
+
+	params := query.RecordWebhookDeliveryAttemptParams{
+		ID:             d.ID,
+		Status:         DeliveryDelivered,
+		ResponseStatus: sql.NullInt32{Int32: int32(code), Valid: code > 0},
+		NextAttemptAt:  now,
+	}
+	if err != nil || code < 200 || code >= 300 {
+		if err != nil {
+			params.Error = err.Error()
+		} else {
+			params.Error = fmt.Sprintf("endpoint answered %d", code)
+		}
+		attempts := d.Attempts + 1
+		if attempts >= maxDeliveryAttempts {
+			params.Status = DeliveryFailed
+		} else {
+			params.Status = DeliveryPending
+			params.NextAttemptAt = now.Add(backoff(attempts))
+		}
+		slog.Warn("Webhook delivery failed", "webhook_id", hook.ID, "delivery_id", d.ID, "type", d.EventType,
+			"attempt", attempts, "status", code, "error", params.Error, "final", params.Status == DeliveryFailed)
+	}
+
+	if err := s.store.RecordWebhookDeliveryAttempt(ctx, params); err != nil {
+		slog.Error("Error recording webhook delivery attempt", "error", err, "delivery_id", d.ID)
+	}
+	if err := s.store.UpdateOrganisationWebhookDelivery(ctx, query.UpdateOrganisationWebhookDeliveryParams{
+		ID:                 hook.ID,
+		LastDeliveryStatus: sql.NullInt32{Int32: int32(code), Valid: true},
+	}); err != nil {
+		slog.Error("Error recording webhook delivery", "error", err, "webhook_id", hook.ID)
+	}
+	return params.Status
+}
+
+// RetryDue attempts the pending deliveries whose retry time has come, including
+// first attempts lost when an instance stopped, and prunes old finished
+// deliveries from the log. Deliveries to webhooks deactivated since are given up.
+func (s *Service) RetryDue(ctx context.Context) (*DeliverySummary, error) {
+	summary := &DeliverySummary{}
+
+	pruned, err := s.store.DeleteOldWebhookDeliveries(ctx, time.Now().Add(-deliveryRetention))
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error pruning webhook deliveries", Err: err}
+	}
+	summary.Pruned = pruned
+
+	due, err := s.store.ClaimDueWebhookDeliveries(ctx, query.ClaimDueWebhookDeliveriesParams{
+		Limit:         retryBatchSize,
+		NextAttemptAt: time.Now().Add(deliveryLease),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error claiming webhook deliveries", Err: err}
+	}
+
+	hooks := make(map[uuid.UUID]*query.OrganisationWebhook)
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, retryConcurrency)
+	)
+	for _, d := range due {
+		hook, ok := hooks[d.WebhookID]
+		if !ok {
+			h, err := s.getWebhook(ctx, d.OrganisationID, d.WebhookID)
+			if err != nil {
+				slog.Error("Error loading webhook for retry", "error", err, "webhook_id", d.WebhookID)
+				continue
+			}
+			hook = &h
+			hooks[d.WebhookID] = hook
+		}
+
+		if !hook.Active {
+			if err := s.store.RecordWebhookDeliveryAttempt(ctx, query.RecordWebhookDeliveryAttemptParams{
+				ID:            d.ID,
+				Status:        DeliveryFailed,
+				Error:         "webhook deactivated",
+				NextAttemptAt: time.Now(),
+			}); err != nil {
+				slog.Error("Error recording webhook delivery attempt", "error", err, "delivery_id", d.ID)
+			}
+			summary.Failed++
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(hook query.OrganisationWebhook, d query.WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			status := s.attempt(ctx, hook, d)
+			mu.Lock()
+			defer mu.Unlock()
+			summary.Attempted++
+			switch status {
+			case DeliveryDelivered:
+				summary.Delivered++
+			case DeliveryFailed:
+				summary.Failed++
+			}
+		}(*hook, d)
+	}
+	wg.Wait()
+
+	return summary, nil
+}
+
+// ListDeliveries returns a webhook's most recent deliveries, newest first
+func (s *Service) ListDeliveries(ctx context.Context, organisationID, webhookID uuid.UUID, limit int) ([]Delivery, error) {
+	if _, err := s.getWebhook(ctx, organisationID, webhookID); err != nil {
+		return nil, err
+	}
+	rows, err := s.store.ListWebhookDeliveries(ctx, query.ListWebhookDeliveriesParams{
+		WebhookID:      webhookID,
+		OrganisationID: organisationID,
+		Limit:          int32(limit),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing webhook deliveries", Err: err}
+	}
+	result := make([]Delivery, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, toDelivery(row))
+	}
+	return result, nil
+}
+
+// Redeliver sends a finished delivery again with the same payload and delivery
+// ID, so receivers can recognise it. Its attempts start over.
+func (s *Service) Redeliver(ctx context.Context, organisationID, webhookID, deliveryID uuid.UUID) (*Delivery, error) {
+	hook, err := s.getWebhook(ctx, organisationID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if !hook.Active {
+		return nil, pkg.BadRequestError{Message: "Webhook is deactivated"}
+	}
+
+	d, err := s.store.RequeueWebhookDelivery(ctx, query.RequeueWebhookDeliveryParams{
+		ID:             deliveryID,
+		WebhookID:      webhookID,
+		OrganisationID: organisationID,
+		NextAttemptAt:  time.Now().Add(deliveryLease),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Delivery not found or still pending", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error requeueing webhook delivery", Err: err}
+	}
+
+	go s.attempt(context.WithoutCancel(ctx), hook, d)
+
+	result := toDelivery(d)
+	return &result, nil
+}
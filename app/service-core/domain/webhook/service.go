@@ -0,0 +1,366 @@
+package webhook
+
+import (
+	"app/pkg"
+	"app/pkg/contentfetch"
+	"app/pkg/ids"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"service-core/config"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Header names sent with every delivery.
+const (
+	SignatureHeader = "X-LeapLearn-Signature" // t=<unix>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+	EventHeader     = "X-LeapLearn-Event"
+	DeliveryHeader  = "X-LeapLearn-Delivery" // the same on every attempt at one delivery
+)
+
+const (
+	maxWebhooksPerOrg = 10
+	deliveryTimeout   = 10 * time.Second
+)
+
+// store defines the database interface for webhook operations
+type store interface {
+	ListOrganisationWebhooks(ctx context.Context, organisationID uuid.UUID) ([]query.OrganisationWebhook, error)
+	ListActiveOrganisationWebhooks(ctx context.Context, organisationID uuid.UUID) ([]query.OrganisationWebhook, error)
+	CreateOrganisationWebhook(ctx context.Context, arg query.CreateOrganisationWebhookParams) (query.OrganisationWebhook, error)
+	DeleteOrganisationWebhook(ctx context.Context, arg query.DeleteOrganisationWebhookParams) (int64, error)
+	UpdateOrganisationWebhookDelivery(ctx context.Context, arg query.UpdateOrganisationWebhookDeliveryParams) error
+	GetOrganisationWebhook(ctx context.Context, arg query.GetOrganisationWebhookParams) (query.OrganisationWebhook, error)
+	UpdateOrganisationWebhook(ctx context.Context, arg query.UpdateOrganisationWebhookParams) (query.OrganisationWebhook, error)
+	CreateWebhookDelivery(ctx context.Context, arg query.CreateWebhookDeliveryParams) (query.WebhookDelivery, error)
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg query.RecordWebhookDeliveryAttemptParams) error
+	ClaimDueWebhookDeliveries(ctx context.Context, arg query.ClaimDueWebhookDeliveriesParams) ([]query.WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, arg query.ListWebhookDeliveriesParams) ([]query.WebhookDelivery, error)
+	RequeueWebhookDelivery(ctx context.Context, arg query.RequeueWebhookDeliveryParams) (query.WebhookDelivery, error)
+	DeleteOldWebhookDeliveries(ctx context.Context, createdAt time.Time) (int64, error)
+}
+
+// Service manages organisation webhooks and delivers signed domain events to them
+type Service struct {
+	cfg    *config.Config
+	store  store
+	client *http.Client
+}
+
+// NewService creates a new webhook service
+func NewService(cfg *config.Config, store store) *Service {
+	return &Service{
+		cfg:    cfg,
+		store:  store,
+		client: newDeliveryClient(),
+	}
+}
+
+// Event is the envelope delivered to organisation webhooks
+type Event struct {
+	ID             uuid.UUID      `json:"id"`
+	Type           string         `json:"type"`
+	OrganisationID uuid.UUID      `json:"organisationId"`
+	OccurredAt     time.Time      `json:"occurredAt"`
+	Data           map[string]any `json:"data"`
+}
+
+// Webhook is the API representation of a registered webhook (secret omitted)
+type Webhook struct {
+	ID                 uuid.UUID  `json:"id"`
+	URL                string     `json:"url"`
+	Events             []string   `json:"events"`
+	Active             bool       `json:"active"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	LastDeliveryAt     *time.Time `json:"lastDeliveryAt"`
+	LastDeliveryStatus *int32     `json:"lastDeliveryStatus"`
+}
+
+// CreatedWebhook is returned once on creation and includes the signing secret
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+func toWebhook(w query.OrganisationWebhook) Webhook {
+	result := Webhook{
+		ID:        w.ID,
+		URL:       w.Url,
+		Events:    w.Events,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+	}
+	if result.Events == nil {
+		result.Events = []string{}
+	}
+	if w.LastDeliveryAt.Valid {
+		result.LastDeliveryAt = &w.LastDeliveryAt.Time
+	}
+	if w.LastDeliveryStatus.Valid {
+		result.LastDeliveryStatus = &w.LastDeliveryStatus.Int32
+	}
+	return result
+}
+
+// List returns the organisation's registered webhooks
+func (s *Service) List(ctx context.Context, organisationID uuid.UUID) ([]Webhook, error) {
+	rows, err := s.store.ListOrganisationWebhooks(ctx, organisationID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing webhooks", Err: err}
+	}
+	result := make([]Webhook, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, toWebhook(row))
+	}
+	return result, nil
+}
+
+// Create registers a webhook. An empty events list subscribes to all events.
+func (s *Service) Create(ctx context.Context, organisationID uuid.UUID, rawURL string, events []string) (*CreatedWebhook, error) {
+	if err := validateURL(ctx, rawURL); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.ListOrganisationWebhooks(ctx, organisationID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing webhooks", Err: err}
+	}
+	if len(existing) >= maxWebhooksPerOrg {
+		return nil, pkg.BadRequestError{Message: fmt.Sprintf("An organisation can register at most %d webhooks", maxWebhooksPerOrg)}
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating webhook secret", Err: err}
+	}
+
+	if events == nil {
+		events = []string{}
+	}
+	row, err := s.store.CreateOrganisationWebhook(ctx, query.CreateOrganisationWebhookParams{
+		OrganisationID: organisationID,
+		Url:            rawURL,
+		Secret:         secret,
+		Events:         events,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error creating webhook", Err: err}
+	}
+
+	return &CreatedWebhook{Webhook: toWebhook(row), Secret: secret}, nil
+}
+
+// WebhookUpdate changes a webhook's settings. Fields left nil are kept.
+type WebhookUpdate struct {
+	URL    *string   `json:"url"`
+	Events *[]string `json:"events"` // empty = all events
+	Active *bool     `json:"active"`
+}
+
+// Update changes one of the organisation's webhooks. Deactivated webhooks
+// receive no new events, and their pending deliveries are given up.
+func (s *Service) Update(ctx context.Context, organisationID, webhookID uuid.UUID, update WebhookUpdate) (*Webhook, error) {
+	current, err := s.getWebhook(ctx, organisationID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := query.UpdateOrganisationWebhookParams{
+		ID:             webhookID,
+		OrganisationID: organisationID,
+		Url:            current.Url,
+		Events:         current.Events,
+		Active:         current.Active,
+	}
+	if update.URL != nil {
+		if err := validateURL(ctx, *update.URL); err != nil {
+			return nil, err
+		}
+		params.Url = *update.URL
+	}
+	if update.Events != nil {
+		params.Events = *update.Events
+		if params.Events == nil {
+			params.Events = []string{}
+		}
+	}
+	if update.Active != nil {
+		params.Active = *update.Active
+	}
+
+	row, err := s.store.UpdateOrganisationWebhook(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating webhook", Err: err}
+	}
+	result := toWebhook(row)
+	return &result, nil
+}
+
+func (s *Service) getWebhook(ctx context.Context, organisationID, webhookID uuid.UUID) (query.OrganisationWebhook, error) {
+	hook, err := s.store.GetOrganisationWebhook(ctx, query.GetOrganisationWebhookParams{
+		ID:             webhookID,
+		OrganisationID: organisationID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return hook, pkg.NotFoundError{Message: "Webhook not found", Err: err}
+	}
+	if err != nil {
+		return hook, pkg.InternalError{Message: "Error loading webhook", Err: err}
+	}
+	return hook, nil
+}
+
+// Delete removes one of the organisation's webhooks
+func (s *Service) Delete(ctx context.Context, organisationID, webhookID uuid.UUID) error {
+	n, err := s.store.DeleteOrganisationWebhook(ctx, query.DeleteOrganisationWebhookParams{
+		ID:             webhookID,
+		OrganisationID: organisationID,
+	})
+	if err != nil {
+		return pkg.InternalError{Message: "Error deleting webhook", Err: err}
+	}
+	if n == 0 {
+		return pkg.NotFoundError{Message: "Webhook not found"}
+	}
+	return nil
+}
+
+// Publish delivers an event to every active webhook of the organisation that is
+// subscribed to eventType. Each delivery is logged and first attempted
+// straight away; failures are retried by RetryDue. Publishing is
+// best-effort: errors are logged, never returned to the caller.
+func (s *Service) Publish(ctx context.Context, organisationID uuid.UUID, eventType string, data map[string]any) {
+	hooks, err := s.store.ListActiveOrganisationWebhooks(ctx, organisationID)
+	if err != nil {
+		slog.Error("Error listing webhooks for event", "error", err, "organisation_id", organisationID, "type", eventType)
+		return
+	}
+
+	event := Event{
+		ID:             ids.New(),
+		Type:           eventType,
+		OrganisationID: organisationID,
+		OccurredAt:     time.Now().UTC(),
+		Data:           data,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("Error encoding webhook event", "error", err, "type", eventType)
+		return
+	}
+
+	// Detach from the request so deliveries outlive the inbound call
+	ctx = context.WithoutCancel(ctx)
+	for _, hook := range hooks {
+		if len(hook.Events) > 0 && !slices.Contains(hook.Events, eventType) {
+			continue
+		}
+		// The first attempt holds the delivery for a lease; if this instance
+		// dies before it's made, RetryDue picks it up once the lease is over
+		delivery, err := s.store.CreateWebhookDelivery(ctx, query.CreateWebhookDeliveryParams{
+			WebhookID:      hook.ID,
+			OrganisationID: organisationID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			Payload:        body,
+			NextAttemptAt:  time.Now().Add(deliveryLease),
+		})
+		if err != nil {
+			slog.Error("Error logging webhook delivery", "error", err, "webhook_id", hook.ID, "type", eventType)
+			continue
+		}
+		go s.attempt(ctx, hook, delivery)
+	}
+}
+
+func (s *Service) send(ctx context.Context, hook query.OrganisationWebhook, delivery query.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LeapLearn-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, time.Now(), delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value for body. Receivers recompute the
+// HMAC over "<t>.<body>" with their secret and compare it to v1.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// validateURL checks a webhook URL is https without credentials and, through
+// the same guard delivery dials with, that its host resolves to public
+// addresses only.
+func validateURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return pkg.BadRequestError{Message: "Invalid webhook URL"}
+	}
+	if u.Scheme != "https" {
+		return pkg.BadRequestError{Message: "Webhook URL must use https"}
+	}
+	if u.User != nil {
+		return pkg.BadRequestError{Message: "Webhook URL must not contain credentials"}
+	}
+	if err := contentfetch.CheckPublic(ctx, rawURL); err != nil {
+		if errors.Is(err, contentfetch.ErrPrivateAddress) {
+			return pkg.BadRequestError{Message: "Webhook URL must resolve to a public address", Err: err}
+		}
+		return pkg.BadRequestError{Message: "Webhook URL host could not be resolved", Err: err}
+	}
+	return nil
+}
+
+// newDeliveryClient returns an HTTP client that refuses to connect to loopback,
+// private or link-local addresses, so org-supplied URLs can't reach internal services.
+func newDeliveryClient() *http.Client {
+	return &http.Client{
+		Transport: contentfetch.PublicTransport(),
+		Timeout:   deliveryTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
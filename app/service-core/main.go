@@ -3,24 +3,50 @@ package main
 import (
 	"app/pkg"
 	"app/pkg/auth"
+	"app/pkg/cfbrowser"
+	"app/pkg/contentfetch"
+	"app/pkg/httprecord"
+	"app/pkg/jobs"
 	"context"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"service-core/config"
+	"service-core/domain/accessreview"
+	"service-core/domain/analytics"
+	"service-core/domain/announcement"
 	"service-core/domain/billing"
+	"service-core/domain/certificate"
+	"service-core/domain/competitors"
 	"service-core/domain/email"
+	"service-core/domain/extract"
 	"service-core/domain/file"
 	"service-core/domain/h5p"
+	"service-core/domain/locks"
 	"service-core/domain/login"
+	"service-core/domain/notification"
+	"service-core/domain/presence"
+	"service-core/domain/ranktracker"
+	"service-core/domain/ratelimit"
+	"service-core/domain/results"
+	"service-core/domain/retention"
+	"service-core/domain/search"
+	"service-core/domain/seo"
+	"service-core/domain/sweeper"
+	"service-core/domain/tenant"
 	"service-core/domain/user"
+	"service-core/domain/webhook"
 	"service-core/grpc"
 	"service-core/rest"
 	"service-core/storage"
 	"service-core/storage/query"
+
+	"github.com/stripe/stripe-go/v82"
 )
 
 func main() {
@@ -30,6 +56,9 @@ func main() {
 	// Set up the logger
 	pkg.InitLogger(cfg.LogLevel)
 
+	// Record external API calls for the admin debug endpoint, if enabled
+	setupCallRecording(cfg)
+
 	// Connect to the database
 	s, clean, err := storage.NewStorage(cfg)
 	defer clean()
@@ -44,10 +73,26 @@ func main() {
 	}
 	slog.Info("Database connected")
 
-	// Set up the REST handlers
-	restHandler := setupRESTHandlers(cfg, s)
+	// Set up the browser renderer: the Cloudflare worker, or a local Chrome
+	// that may only load public addresses, as it runs inside our network
+	renderer, closeRenderer, err := cfbrowser.NewRenderer(cfbrowser.RendererConfig{
+		Provider:  cfg.BrowserProvider,
+		WorkerURL: cfg.BrowserWorkerURL,
+		CheckURL:  contentfetch.CheckPublic,
+	})
+	if err != nil {
+		slog.Error("Error setting up browser rendering", "error", err)
+		panic(err)
+	}
+	defer closeRenderer()
+
+	// Set up the REST handlers; the services register their background jobs
+	jobQueue := jobs.New(s.Conn)
+	restHandler := setupRESTHandlers(cfg, s, jobQueue, renderer)
 	// Run the REST server
 	restServer := rest.Run(restHandler)
+	// Run the background job workers
+	jobQueue.Start(jobWorkers(cfg))
 
 	// Set up the gRPC handlers
 	grpcHandler := setupGRPCHandlers(cfg, s)
@@ -69,18 +114,77 @@ func main() {
 
 	grpcServer.GracefulStop()
 
+	// Jobs still running at the deadline are released to run again
+	jobQueue.Stop(ctx)
+
 	slog.Info("Servers stopped gracefully")
 }
 
-func setupRESTHandlers(cfg *config.Config, storage *storage.Storage) *rest.Handler {
-	store := query.New(storage.Conn)
+// setupCallRecording starts recording sanitized external API calls when
+// DEBUG_RECORD_CALLS is set. The Stripe client is global, so its backend is
+// replaced with one using the recording transport.
+func setupCallRecording(cfg *config.Config) {
+	if cfg.DebugRecordCalls == "" {
+		return
+	}
+	size, err := strconv.Atoi(cfg.DebugRecordCalls)
+	if err != nil || size < 1 {
+		slog.Warn("Ignoring DEBUG_RECORD_CALLS, it must be a positive number", "value", cfg.DebugRecordCalls)
+		return
+	}
+	httprecord.Enable(httprecord.NewRecorder(size, 0))
+	stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		HTTPClient: &http.Client{Timeout: 80 * time.Second, Transport: httprecord.Transport("stripe", nil)},
+	}))
+	slog.Warn("Recording external API calls for debugging", "calls", size)
+}
+
+// jobWorkers returns how many background jobs to run at once, from
+// JOB_WORKERS.
+func jobWorkers(cfg *config.Config) int {
+	if cfg.JobWorkers == "" {
+		return 4
+	}
+	n, err := strconv.Atoi(cfg.JobWorkers)
+	if err != nil || n < 1 {
+		slog.Warn("Ignoring JOB_WORKERS, it must be a positive number", "value", cfg.JobWorkers)
+		return 4
+	}
+	return n
+}
+
+func setupRESTHandlers(cfg *config.Config, storage *storage.Storage, jobQueue *jobs.Queue, renderer cfbrowser.Renderer) *rest.Handler {
+	store := storage.Queries()
 	authService := auth.NewService()
 	emailProvider := email.NewProvider(cfg)
 	emailService := email.NewService(cfg, emailProvider)
 	loginService := login.NewService(cfg, store, authService, emailService)
-	billingService := billing.NewService(cfg, store)
-	fileProvider := file.NewProvider(cfg)
-	h5pService := h5p.NewService(cfg, store, fileProvider)
+	webhookService := webhook.NewService(cfg, store)
+	notificationService := notification.NewService(cfg, store, webhookService, emailService, jobQueue)
+	billingService := billing.NewService(cfg, store, notificationService, emailService)
+	var fileProvider file.Provider = file.NewProvider(cfg)
+	var fileReplication *file.ReplicatedProvider
+	if replicaCfg := file.ReplicaConfig(cfg); replicaCfg != nil {
+		fileReplication = file.NewReplicatedProvider(fileProvider, file.NewProvider(replicaCfg), jobQueue)
+		fileProvider = fileReplication
+	}
+	h5pService := h5p.NewService(cfg, store, fileProvider, jobQueue, notificationService, renderer)
+	lockService := locks.NewService(store)
+	analyticsService := analytics.NewService(cfg, store)
+	tenantService := tenant.NewService(cfg, storage.Conn, fileProvider, billingService)
+	searchService := search.NewService(store)
+	rateLimitService := ratelimit.NewService(store)
+	accessReviewService := accessreview.NewService(store, fileProvider)
+	announcementService := announcement.NewService(store)
+	presenceService := presence.NewService(store)
+	seoService := seo.NewService(cfg, store, fileProvider, lockService, jobQueue, notificationService)
+	rankTrackerService := ranktracker.NewService(cfg, store, jobQueue)
+	competitorService := competitors.NewService(cfg, store)
+	retentionService := retention.NewService(store, jobQueue)
+	resultsService := results.NewService(store)
+	extractService := extract.NewService(cfg, store, renderer)
+	sweeperService := sweeper.NewService(store, fileProvider)
+	certificateService := certificate.NewService(cfg, store, fileProvider, jobQueue, renderer)
 
 	apiHandler := rest.NewHandler(
 		cfg,
@@ -89,6 +193,26 @@ func setupRESTHandlers(cfg *config.Config, storage *storage.Storage) *rest.Handl
 		loginService,
 		billingService,
 		h5pService,
+		webhookService,
+		lockService,
+		analyticsService,
+		tenantService,
+		searchService,
+		rateLimitService,
+		accessReviewService,
+		announcementService,
+		presenceService,
+		seoService,
+		rankTrackerService,
+		competitorService,
+		retentionService,
+		resultsService,
+		extractService,
+		notificationService,
+		sweeperService,
+		certificateService,
+		fileReplication,
+		jobQueue,
 	)
 	return apiHandler
 }
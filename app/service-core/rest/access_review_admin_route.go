@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"app/pkg"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// handleAdminOrganisationAccessReviews queues an access review (POST) or lists
+// an organisation's reviews (GET).
+// URL pattern: /api/v1/admin/organisations/{orgId}/access-reviews
+func (h *Handler) handleAdminOrganisationAccessReviews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		reviews, err := h.accessReviewService.List(r.Context(), organisationID)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		writeResponseWithMeta(h.cfg, w, r, reviews, h.organisationMeta(r.Context(), organisationID), nil)
+		return
+	}
+
+	review, err := h.accessReviewService.Request(r.Context(), organisationID, adminID)
+	if err == nil {
+		slog.Info("Access review requested", "organisationID", organisationID, "adminID", adminID, "reviewID", review.ID)
+	}
+	writeResponse(h.cfg, w, r, review, err)
+}
+
+// handleAdminAccessReviews queues an access review of every active organisation.
+// URL pattern: /api/v1/admin/access-reviews
+func (h *Handler) handleAdminAccessReviews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	queued, err := h.accessReviewService.RequestAll(r.Context(), adminID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	slog.Info("Access reviews requested for all organisations", "adminID", adminID, "count", queued)
+	writeResponse(h.cfg, w, r, map[string]int64{"queued": queued}, nil)
+}
+
+// handleAdminAccessReviewDownload downloads a generated review as CSV or JSON.
+// URL pattern: /api/v1/admin/access-reviews/{reviewId}/download?format=csv|json
+func (h *Handler) handleAdminAccessReviewDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	reviewID, err := uuid.Parse(r.PathValue("reviewId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid reviewId"})
+		return
+	}
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	data, filename, err := h.accessReviewService.Download(r.Context(), reviewID, format)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	slog.Info("Access review downloaded", "reviewID", reviewID, "adminID", adminID, "format", format)
+
+	contentType := "text/csv"
+	if format == "json" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	w.Write(data)
+}
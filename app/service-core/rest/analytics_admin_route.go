@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"app/pkg"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// handleAdminUserAnonymize detaches a user's xAPI statements, progress and
+// enrolments from their account ahead of its deletion. Safe to repeat.
+// URL pattern: /api/v1/admin/users/{userId}/anonymize
+func (h *Handler) handleAdminUserAnonymize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	userID, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid userId"})
+		return
+	}
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	result, err := h.analyticsService.AnonymizeUser(r.Context(), userID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.InternalError{Message: "Failed to anonymize analytics", Err: err})
+		return
+	}
+	slog.Info("Anonymized user analytics",
+		"userID", userID,
+		"adminID", adminID,
+		"enrolments", result.Enrolments,
+		"progressRecords", result.ProgressRecords,
+		"xapiStatements", result.XapiStatements,
+	)
+	writeResponse(h.cfg, w, r, result, nil)
+}
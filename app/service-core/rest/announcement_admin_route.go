@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"service-core/domain/announcement"
+
+	"github.com/google/uuid"
+)
+
+// handleAdminAnnouncements lists (GET) or creates (POST) platform announcements.
+// URL pattern: /api/v1/admin/announcements
+func (h *Handler) handleAdminAnnouncements(w http.ResponseWriter, r *http.Request) {
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		announcements, err := h.announcementService.List(r.Context())
+		writeResponse(h.cfg, w, r, announcements, err)
+	case http.MethodPost:
+		var req announcement.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		created, err := h.announcementService.Create(r.Context(), adminID, req)
+		if err == nil {
+			slog.Info("Announcement created", "announcementID", created.ID, "adminID", adminID)
+		}
+		writeResponse(h.cfg, w, r, created, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleAdminAnnouncement updates (PUT) or deletes (DELETE) an announcement.
+// URL pattern: /api/v1/admin/announcements/{announcementId}
+func (h *Handler) handleAdminAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := uuid.Parse(r.PathValue("announcementId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid announcementId"})
+		return
+	}
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req announcement.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		updated, err := h.announcementService.Update(r.Context(), announcementID, req)
+		if err == nil {
+			slog.Info("Announcement updated", "announcementID", announcementID, "adminID", adminID)
+		}
+		writeResponse(h.cfg, w, r, updated, err)
+	case http.MethodDelete:
+		if err := h.announcementService.Delete(r.Context(), announcementID); err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		slog.Info("Announcement deleted", "announcementID", announcementID, "adminID", adminID)
+		writeResponse(h.cfg, w, r, map[string]bool{"success": true}, nil)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"app/pkg"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// handleAnnouncements lists the caller's unread announcements for an organisation.
+// URL pattern: /api/v1/announcements?organisationId=
+func (h *Handler) handleAnnouncements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	announcements, err := h.announcementService.Unread(r.Context(), claims.ID, organisationID)
+	writeResponse(h.cfg, w, r, announcements, err)
+}
+
+// handleAnnouncementRead marks an announcement read for the caller.
+// URL pattern: /api/v1/announcements/{announcementId}/read
+func (h *Handler) handleAnnouncementRead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	announcementID, err := uuid.Parse(r.PathValue("announcementId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid announcementId"})
+		return
+	}
+
+	if err := h.announcementService.MarkRead(r.Context(), claims.ID, announcementID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	writeResponse(h.cfg, w, r, map[string]bool{"success": true}, nil)
+}
@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"service-core/domain/billing"
+
+	"github.com/google/uuid"
+)
+
+// requireSuperAdmin authenticates the request and checks the user holds the
+// platform super admin flag. It returns the admin's user ID for audit logging.
+func (h *Handler) requireSuperAdmin(r *http.Request) (uuid.UUID, error) {
+	token := extractAccessToken(r)
+	if token == "" {
+		return uuid.Nil, pkg.UnauthorizedError{Err: errors.New("missing access token")}
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		return uuid.Nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")}
+	}
+	if !h.authService.HasAccess(auth.SuperAdmin, claims.Access) {
+		return uuid.Nil, pkg.ForbiddenError{Err: errors.New("super admin access required")}
+	}
+	return claims.ID, nil
+}
+
+// handleAdminOrganisationRefunds issues a refund against one of an organisation's payments.
+// URL pattern: /api/v1/admin/organisations/{orgId}/refunds
+func (h *Handler) handleAdminOrganisationRefunds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req billing.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+
+	adjustment, err := h.billingService.IssueRefund(r.Context(), organisationID, adminID, req)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	writeResponseWithMeta(h.cfg, w, r, adjustment, h.organisationMeta(r.Context(), organisationID), nil)
+}
+
+// handleAdminOrganisationCredits applies a credit to an organisation's customer balance.
+// URL pattern: /api/v1/admin/organisations/{orgId}/credits
+func (h *Handler) handleAdminOrganisationCredits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req billing.CreditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+
+	adjustment, err := h.billingService.IssueCredit(r.Context(), organisationID, adminID, req)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	writeResponseWithMeta(h.cfg, w, r, adjustment, h.organisationMeta(r.Context(), organisationID), nil)
+}
@@ -6,6 +6,8 @@ import (
 	"io"
 	"net/http"
 
+	"service-core/domain/billing"
+
 	"github.com/google/uuid"
 )
 
@@ -14,9 +16,13 @@ type BillingCheckoutRequest struct {
 	OrganisationID   string `json:"organisationId"`
 	OrganisationSlug string `json:"organisationSlug"`
 	OrganisationName string `json:"organisationName"`
-	Email      string `json:"email"`
-	Tier       string `json:"tier"`
-	Interval   string `json:"interval"` // "month" or "year"
+	Email            string `json:"email"`
+	Tier             string `json:"tier"`
+	Interval         string `json:"interval"` // "month" or "year"
+	// Optional: "hosted" or "embedded"; defaults to STRIPE_CHECKOUT_UI_MODE
+	UIMode string `json:"uiMode"`
+	// Optional: client path to return to after checkout, e.g. "/pricing"
+	ReturnPath string `json:"returnPath"`
 }
 
 // BillingUpgradeRequest represents the request body for upgrading a subscription
@@ -55,7 +61,19 @@ func (h *Handler) handleBillingInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeResponse(h.cfg, w, r, info, nil)
+	writeResponseWithMeta(h.cfg, w, r, info, h.organisationMeta(r.Context(), organisationID), nil)
+}
+
+// handleBillingPlans returns every tier's prices, limits and features for the
+// pricing page. It's public, so shared caches may keep it.
+// URL pattern: GET /api/v1/billing/plans
+func (h *Handler) handleBillingPlans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	cachePublic.apply(w)
+	writeResponse(h.cfg, w, r, h.billingService.ListPlans(r.Context()), nil)
 }
 
 // handleBillingCheckout creates a Stripe Checkout session for subscription
@@ -91,6 +109,7 @@ func (h *Handler) handleBillingCheckout(w http.ResponseWriter, r *http.Request)
 		req.OrganisationName,
 		req.Tier,
 		req.Interval,
+		billing.CheckoutOptions{UIMode: req.UIMode, ReturnPath: req.ReturnPath},
 	)
 	if err != nil {
 		writeResponse(h.cfg, w, r, nil, err)
@@ -121,7 +140,10 @@ func (h *Handler) handleBillingPortal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.billingService.CreatePortalSession(r.Context(), organisationID, organisationSlug)
+	// Optional: flow=payment_method_update deep links into the card update page
+	flow := r.URL.Query().Get("flow")
+
+	response, err := h.billingService.CreatePortalSession(r.Context(), organisationID, organisationSlug, flow)
 	if err != nil {
 		writeResponse(h.cfg, w, r, nil, err)
 		return
@@ -242,3 +264,34 @@ func (h *Handler) handleBillingWebhook(w http.ResponseWriter, r *http.Request) {
 	// Return 200 OK to acknowledge receipt
 	w.WriteHeader(http.StatusOK)
 }
+
+// handleOrganisationBillingContact gets (GET) or updates (PUT) an organisation's
+// billing contact and invoice settings.
+// URL pattern: /api/v1/organisations/{orgId}/billing-contact
+func (h *Handler) handleOrganisationBillingContact(w http.ResponseWriter, r *http.Request) {
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		contact, err := h.billingService.GetBillingContact(r.Context(), organisationID)
+		writeResponse(h.cfg, w, r, contact, err)
+	case http.MethodPut:
+		var req billing.BillingContact
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		contact, err := h.billingService.UpdateBillingContact(r.Context(), organisationID, req)
+		writeResponse(h.cfg, w, r, contact, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
@@ -0,0 +1,204 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"service-core/domain/h5p"
+)
+
+// cachePolicy is how browsers and the CDN may cache one class of response.
+// Handlers apply the policy for what they serve instead of writing
+// Cache-Control themselves, so every resource of a class is cached the same.
+type cachePolicy struct {
+	control string
+	// vary lists the request headers the response depends on
+	vary []string
+}
+
+// authVary marks responses that depend on who is asking. The access token
+// arrives as a cookie or an Authorization header.
+var authVary = []string{"Authorization", "Cookie"}
+
+var (
+	// cacheImmutable is for assets whose URL changes whenever they do, e.g.
+	// library files under a versioned path.
+	cacheImmutable = cachePolicy{control: "public, max-age=31536000, immutable"}
+	// cachePublic is for shared resources that can change under the same URL,
+	// e.g. library semantics.
+	cachePublic = cachePolicy{control: "public, max-age=3600"}
+	// cachePrivate is for a user's stored files, which rarely change once
+	// uploaded. Only the browser may keep them.
+	cachePrivate = cachePolicy{control: "private, max-age=3600", vary: authVary}
+	// cacheListing is for a user's data that changes as they edit, such as
+	// content parameters, kept briefly to spare repeat loads.
+	cacheListing = cachePolicy{control: "private, max-age=60", vary: authVary}
+	// cacheRevalidate is for pages the browser may keep but must check before
+	// each use.
+	cacheRevalidate = cachePolicy{control: "private, no-cache", vary: authVary}
+	// cacheNoStore is for API responses and errors, which are never cached.
+	// It's the default for every response.
+	cacheNoStore = cachePolicy{control: "no-store"}
+)
+
+// apply sets the policy's headers on the response. It must be called before
+// the response is written.
+func (p cachePolicy) apply(w http.ResponseWriter) {
+	h := w.Header()
+	h.Set("Cache-Control", p.control)
+	for _, name := range p.vary {
+		addVary(h, name)
+	}
+}
+
+// addVary adds name to the Vary header unless it's already there.
+func addVary(h http.Header, name string) {
+	for _, value := range h.Values("Vary") {
+		for _, existing := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), name) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", name)
+}
+
+// cacheMiddleware makes no-store the default, so neither the CDN nor the
+// browser keeps a response its handler didn't choose a policy for.
+func cacheMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cacheNoStore.apply(w)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// redirectPolicy is for a redirect to an asset on the CDN. Signed CDN URLs
+// expire, so the redirect is kept no longer than one stays valid, however
+// long the asset itself may be cached.
+func redirectPolicy(asset *h5p.Asset) cachePolicy {
+	maxAge := strconv.Itoa(int(h5p.CDNURLWindow.Seconds()))
+	if asset.Private {
+		return cachePolicy{control: "private, max-age=" + maxAge, vary: authVary}
+	}
+	return cachePolicy{control: "public, max-age=" + maxAge}
+}
+
+// serveAsset serves a stored H5P file under policy. With a CDN configured
+// the request is redirected there under redirectPolicy; otherwise the file is
+// streamed from storage with an ETag and Last-Modified, answering conditional
+// requests without opening it and range requests (video seeking) with part of
+// it.
+func (h *Handler) serveAsset(w http.ResponseWriter, r *http.Request, asset *h5p.Asset, policy cachePolicy) {
+	if target := h.h5pService.AssetURL(asset); target != "" {
+		redirectPolicy(asset).apply(w)
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
+
+	etag := asset.ETag()
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", asset.ContentType)
+	policy.apply(w)
+	if notModified(r, etag, asset.ModTime) {
+		w.Header().Set("Last-Modified", asset.ModTime.UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, size, err := h.h5pService.OpenAsset(r.Context(), asset, 0)
+	if err != nil {
+		slog.Debug("Asset not found", "key", asset.Key, "error", err)
+		cacheNoStore.apply(w)
+		w.Header().Del("ETag")
+		http.NotFound(w, r)
+		return
+	}
+	if size < 0 {
+		// Without a size there are no ranges; send the whole file
+		defer body.Close()
+		if !asset.ModTime.IsZero() {
+			w.Header().Set("Last-Modified", asset.ModTime.UTC().Format(http.TimeFormat))
+		}
+		io.Copy(w, body)
+		return
+	}
+
+	content := &assetReader{body: body, size: size, open: func(offset int64) (io.ReadCloser, error) {
+		body, _, err := h.h5pService.OpenAsset(r.Context(), asset, offset)
+		return body, err
+	}}
+	defer content.Close()
+	http.ServeContent(w, r, "", asset.ModTime, content)
+}
+
+// assetReader is the io.ReadSeeker http.ServeContent needs, over a file
+// opened as a stream. ServeContent seeks to learn the size and then to the
+// start of the range it serves; reads from anywhere but where the stream is
+// reopen the file there, so storage only sends the bytes from the range on.
+type assetReader struct {
+	open   func(offset int64) (io.ReadCloser, error)
+	body   io.ReadCloser
+	size   int64
+	pos    int64 // where body is
+	offset int64 // where the next read starts
+}
+
+func (a *assetReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += a.offset
+	case io.SeekEnd:
+		offset += a.size
+	}
+	if offset < 0 {
+		return 0, errors.New("seek before start of file")
+	}
+	a.offset = offset
+	return offset, nil
+}
+
+func (a *assetReader) Read(p []byte) (int, error) {
+	if a.offset != a.pos {
+		if a.offset >= a.size {
+			return 0, io.EOF
+		}
+		body, err := a.open(a.offset)
+		if err != nil {
+			return 0, err
+		}
+		a.body.Close()
+		a.body, a.pos = body, a.offset
+	}
+	n, err := a.body.Read(p)
+	a.pos += int64(n)
+	a.offset = a.pos
+	return n, err
+}
+
+func (a *assetReader) Close() error {
+	return a.body.Close()
+}
+
+// notModified reports whether the client's cached copy, named by
+// If-None-Match or else dated by If-Modified-Since, is current.
+func notModified(r *http.Request, etag string, modTime time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if modTime.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modTime.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,184 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const multiChoiceJS = "/api/v1/h5p/libraries/H5P.MultiChoice-1.16.4/js/multichoice.js"
+
+func TestServeAsset(t *testing.T) {
+	h, store, _ := newEditorTestHandler(t)
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.libs[0].UpdatedAt = modTime
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, values := range header {
+			r.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		h.handleH5PLibraryAsset(rec, r)
+		return rec
+	}
+
+	first := get(multiChoiceJS, nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	tests := []struct {
+		name         string
+		path         string
+		header       http.Header
+		status       int
+		body         string
+		contentRange string
+		control      string
+	}{
+		{
+			name:    "whole file",
+			path:    multiChoiceJS,
+			status:  http.StatusOK,
+			body:    "H5P.MultiChoice = function () {};",
+			control: cacheImmutable.control,
+		},
+		{
+			name:    "matching etag",
+			path:    multiChoiceJS,
+			header:  http.Header{"If-None-Match": {etag}},
+			status:  http.StatusNotModified,
+			control: cacheImmutable.control,
+		},
+		{
+			name:    "one of several etags",
+			path:    multiChoiceJS,
+			header:  http.Header{"If-None-Match": {`"stale", W/` + etag}},
+			status:  http.StatusNotModified,
+			control: cacheImmutable.control,
+		},
+		{
+			name:    "stale etag",
+			path:    multiChoiceJS,
+			header:  http.Header{"If-None-Match": {`"stale"`}},
+			status:  http.StatusOK,
+			body:    "H5P.MultiChoice = function () {};",
+			control: cacheImmutable.control,
+		},
+		{
+			name:    "not modified since",
+			path:    multiChoiceJS,
+			header:  http.Header{"If-Modified-Since": {modTime.Format(http.TimeFormat)}},
+			status:  http.StatusNotModified,
+			control: cacheImmutable.control,
+		},
+		{
+			name:    "modified since",
+			path:    multiChoiceJS,
+			header:  http.Header{"If-Modified-Since": {modTime.Add(-time.Hour).Format(http.TimeFormat)}},
+			status:  http.StatusOK,
+			body:    "H5P.MultiChoice = function () {};",
+			control: cacheImmutable.control,
+		},
+		{
+			name:         "range",
+			path:         multiChoiceJS,
+			header:       http.Header{"Range": {"bytes=4-14"}},
+			status:       http.StatusPartialContent,
+			body:         "MultiChoice",
+			contentRange: "bytes 4-14/33",
+			control:      cacheImmutable.control,
+		},
+		{
+			name:    "missing file",
+			path:    "/api/v1/h5p/libraries/H5P.MultiChoice-1.16.4/js/missing.js",
+			status:  http.StatusNotFound,
+			control: cacheNoStore.control,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(tt.path, tt.header)
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.control, rec.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.contentRange, rec.Header().Get("Content-Range"))
+			if tt.body != "" {
+				assert.Equal(t, tt.body, rec.Body.String())
+			}
+			switch tt.status {
+			case http.StatusNotFound:
+				assert.Empty(t, rec.Header().Get("ETag"))
+			case http.StatusNotModified:
+				assert.Empty(t, rec.Body.Bytes())
+				assert.Equal(t, etag, rec.Header().Get("ETag"))
+				assert.Equal(t, modTime.Format(http.TimeFormat), rec.Header().Get("Last-Modified"))
+			default:
+				assert.Equal(t, etag, rec.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestServeAsset_CDNRedirect(t *testing.T) {
+	h, _, _ := newEditorTestHandler(t)
+	h.cfg.AssetCDNURL = "https://cdn.example.com/"
+
+	tests := []struct {
+		name       string
+		signingKey string
+		serve      func(w http.ResponseWriter, r *http.Request)
+		path       string
+		location   string
+		control    string
+		vary       []string
+	}{
+		{
+			// The asset is cached for a year under its versioned URL, but the
+			// redirect only as long as a CDN URL is valid
+			name:     "library asset",
+			serve:    h.handleH5PLibraryAsset,
+			path:     multiChoiceJS,
+			location: "https://cdn.example.com/h5p-libraries/extracted/H5P.MultiChoice-1.16.4/js/multichoice.js",
+			control:  "public, max-age=3600",
+		},
+		{
+			name:       "signed library asset",
+			signingKey: "secret",
+			serve:      h.handleH5PLibraryAsset,
+			path:       multiChoiceJS,
+			location:   "https://cdn.example.com/h5p-libraries/extracted/H5P.MultiChoice-1.16.4/js/multichoice.js?expires=",
+			control:    "public, max-age=3600",
+		},
+		{
+			name:       "private file",
+			signingKey: "secret",
+			serve:      h.handleTempFile,
+			path:       "/api/v1/h5p/temp-files/files/notes.txt",
+			location:   "https://cdn.example.com/h5p-temp/files/notes.txt?expires=",
+			control:    "private, max-age=3600",
+			vary:       authVary,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.cfg.AssetCDNSigningKey = tt.signingKey
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.AddCookie(&http.Cookie{Name: "access_token", Value: editorTestToken})
+			r.Header.Set("If-None-Match", "*")
+			rec := httptest.NewRecorder()
+			tt.serve(rec, r)
+
+			assert.Equal(t, http.StatusFound, rec.Code)
+			assert.True(t, strings.HasPrefix(rec.Header().Get("Location"), tt.location), rec.Header().Get("Location"))
+			assert.Equal(t, tt.control, rec.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.vary, rec.Header().Values("Vary"))
+			assert.Empty(t, rec.Header().Get("ETag"))
+		})
+	}
+}
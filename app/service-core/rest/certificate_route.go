@@ -0,0 +1,137 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/certificate"
+)
+
+// handleOrganisationCertificateTemplate shows (GET) or changes (PUT) how the
+// organisation's completion certificates are laid out. Org admins only.
+// URL pattern: /api/v1/organisations/{orgId}/certificate-template
+func (h *Handler) handleOrganisationCertificateTemplate(w http.ResponseWriter, r *http.Request) {
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		t, err := h.certificateService.GetTemplate(r.Context(), organisationID)
+		writeResponse(h.cfg, w, r, t, err)
+	case http.MethodPut:
+		var req certificate.TemplateInput
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		t, err := h.certificateService.UpdateTemplate(r.Context(), organisationID, req)
+		writeResponse(h.cfg, w, r, t, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleOrganisationCertificateBackground sets (PUT, the PNG or JPEG as the
+// request body) or removes (DELETE) the background of the organisation's
+// certificates. Org admins only.
+// URL pattern: /api/v1/organisations/{orgId}/certificate-template/background
+func (h *Handler) handleOrganisationCertificateBackground(w http.ResponseWriter, r *http.Request) {
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, certificate.MaxBackgroundSize+1))
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Background image is too large", Err: err})
+			return
+		}
+		t, err := h.certificateService.SetBackground(r.Context(), organisationID, data)
+		writeResponse(h.cfg, w, r, t, err)
+	case http.MethodDelete:
+		t, err := h.certificateService.RemoveBackground(r.Context(), organisationID)
+		writeResponse(h.cfg, w, r, t, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleCertificates lists the caller's certificates across organisations,
+// with their download history.
+// URL pattern: GET /api/v1/certificates
+func (h *Handler) handleCertificates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	userID, err := h.requireUser(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	list, err := h.certificateService.ListForUser(r.Context(), userID)
+	writeResponse(h.cfg, w, r, list, err)
+}
+
+// handleCertificateDownload downloads one of the caller's certificates as a
+// PDF.
+// URL pattern: GET /api/v1/certificates/{id}/download
+func (h *Handler) handleCertificateDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	userID, err := h.requireUser(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid certificate ID"})
+		return
+	}
+	data, name, err := h.certificateService.Download(r.Context(), userID, id)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+name+"\"")
+	w.Write(data)
+}
+
+// handleVerifyCertificate confirms a certificate was issued and shows who
+// for. Public: the ID printed on a certificate is all that's needed.
+// URL pattern: GET /verify/{id}
+func (h *Handler) handleVerifyCertificate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.NotFoundError{Message: "No certificate was issued with this ID"})
+		return
+	}
+	v, err := h.certificateService.Verify(r.Context(), id)
+	writeResponse(h.cfg, w, r, v, err)
+}
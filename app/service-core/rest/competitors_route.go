@@ -0,0 +1,215 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/competitors"
+)
+
+// handleCompetitors lists an organisation's pinned competitors (GET) or pins
+// competitor domains against one of its sites (POST). Pins are refreshed
+// weekly by the competitor refresh task.
+// URL pattern: /api/v1/competitors?organisationId=...
+func (h *Handler) handleCompetitors(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.competitorClaims(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+			return
+		}
+		pins, err := h.competitorService.ListPins(r.Context(), userID, organisationID)
+		writeResponse(h.cfg, w, r, pins, err)
+	case http.MethodPost:
+		var req competitors.PinRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		if req.OrganisationID == uuid.Nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+			return
+		}
+		pins, err := h.competitorService.Pin(r.Context(), userID, req)
+		writeResponse(h.cfg, w, r, pins, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleCompetitor unpins a competitor, dropping its keyword gaps and alerts.
+// URL pattern: DELETE /api/v1/competitors/{pinId}?organisationId=...
+func (h *Handler) handleCompetitor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	userID, organisationID, pinID, ok := h.competitorPinRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.competitorService.Unpin(r.Context(), userID, organisationID, pinID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	writeResponse(h.cfg, w, r, map[string]bool{"deleted": true}, nil)
+}
+
+// handleCompetitorGaps returns the keywords a pinned competitor ranks in the
+// top 10 for and the target doesn't rank for, a page at a time.
+// URL pattern: GET /api/v1/competitors/{pinId}/gaps?organisationId=...&limit=...&offset=...
+func (h *Handler) handleCompetitorGaps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	userID, organisationID, pinID, ok := h.competitorPinRequest(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageParams(r, proxiedPageLimits)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	gaps, err := h.competitorService.KeywordGaps(r.Context(), userID, organisationID, pinID, page.Limit, page.Offset)
+	writeResponse(h.cfg, w, r, gaps, err)
+}
+
+// handleCompetitorSuggestions returns the domains competing with a site for
+// its keywords, to pick competitors from. Each call is billed.
+// URL pattern: POST /api/v1/competitors/suggestions
+func (h *Handler) handleCompetitorSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	userID, ok := h.competitorClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req competitors.SuggestRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	if req.OrganisationID == uuid.Nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+		return
+	}
+	suggestions, err := h.competitorService.Suggest(r.Context(), userID, req)
+	writeResponse(h.cfg, w, r, suggestions, err)
+}
+
+// handleCompetitorAlerts returns an organisation's latest alerts about new
+// keyword gaps and backlink gains, newest first.
+// URL pattern: GET /api/v1/competitors/alerts?organisationId=...&limit=...
+func (h *Handler) handleCompetitorAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	userID, ok := h.competitorClaims(w, r)
+	if !ok {
+		return
+	}
+
+	organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	limit, err := parseIntParam(r, "limit", 50, 1, competitors.MaxAlerts)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	alerts, err := h.competitorService.Alerts(r.Context(), userID, organisationID, limit)
+	writeResponse(h.cfg, w, r, alerts, err)
+}
+
+// handleCompetitorComparison returns a site's latest metrics side by side
+// with those of each competitor pinned against it in one market.
+// URL pattern: GET /api/v1/competitors/compare?organisationId=...&target=...&locationCode=...&languageCode=...
+func (h *Handler) handleCompetitorComparison(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	userID, ok := h.competitorClaims(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	organisationID, err := uuid.Parse(q.Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	locationCode, err := strconv.Atoi(q.Get("locationCode"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid locationCode"})
+		return
+	}
+	comparison, err := h.competitorService.Compare(r.Context(), userID, competitors.Market{
+		OrganisationID: organisationID,
+		Target:         q.Get("target"),
+		LocationCode:   locationCode,
+		LanguageCode:   q.Get("languageCode"),
+	})
+	writeResponse(h.cfg, w, r, comparison, err)
+}
+
+// competitorClaims validates the request's access token and returns the
+// caller's user ID, writing the error response and returning false when it's
+// missing or invalid.
+func (h *Handler) competitorClaims(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return uuid.Nil, false
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return uuid.Nil, false
+	}
+	return claims.ID, true
+}
+
+// competitorPinRequest validates the access token of a request for one pin
+// and reads its pin and organisation, writing the error response and
+// returning false when any is missing or invalid.
+func (h *Handler) competitorPinRequest(w http.ResponseWriter, r *http.Request) (userID, organisationID, pinID uuid.UUID, ok bool) {
+	userID, ok = h.competitorClaims(w, r)
+	if !ok {
+		return
+	}
+
+	pinID, err := uuid.Parse(r.PathValue("pinId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid pinId"})
+		return userID, uuid.Nil, uuid.Nil, false
+	}
+	organisationID, err = uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return userID, uuid.Nil, uuid.Nil, false
+	}
+	return userID, organisationID, pinID, true
+}
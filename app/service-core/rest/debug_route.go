@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/httprecord"
+	"net/http"
+	"strconv"
+)
+
+// maxRecordedCalls is the most recorded calls one request lists.
+const maxRecordedCalls = 500
+
+var errCallRecordingDisabled = pkg.BadRequestError{Message: "External call recording is disabled; set DEBUG_RECORD_CALLS to enable it"}
+
+// handleAdminDebugCalls lists the recorded external API calls, newest first,
+// optionally of one service (GET), or clears them (DELETE). Super admin only.
+// URL pattern: /api/v1/admin/debug/calls?service=dataforseo&limit=50
+func (h *Handler) handleAdminDebugCalls(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	rec := httprecord.Active()
+	if rec == nil {
+		writeResponse(h.cfg, w, r, nil, errCallRecordingDisabled)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		rec.Clear()
+		writeResponse(h.cfg, w, r, map[string]bool{"deleted": true}, nil)
+		return
+	}
+	limit, err := parseIntParam(r, "limit", 50, 1, maxRecordedCalls)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	writeResponse(h.cfg, w, r, rec.List(r.URL.Query().Get("service"), limit), nil)
+}
+
+// handleAdminDebugCall returns one recorded external API call. Super admin
+// only.
+// URL pattern: GET /api/v1/admin/debug/calls/{callId}
+func (h *Handler) handleAdminDebugCall(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	rec := httprecord.Active()
+	if rec == nil {
+		writeResponse(h.cfg, w, r, nil, errCallRecordingDisabled)
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("callId"), 10, 64)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid callId"})
+		return
+	}
+	call, ok := rec.Get(id)
+	if !ok {
+		writeResponse(h.cfg, w, r, nil, pkg.NotFoundError{Message: "Call not found; it may have been evicted"})
+		return
+	}
+	writeResponse(h.cfg, w, r, call, nil)
+}
@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/tenant"
+)
+
+// DeletionConfirmRequest confirms an organisation deletion or teardown by
+// repeating the organisation's slug.
+type DeletionConfirmRequest struct {
+	Confirm string `json:"confirm"`
+}
+
+// handleOrganisationDeletion deletes an organisation (POST), shows the
+// deletion and its teardown progress (GET), or restores the organisation
+// within the recovery window (DELETE). Owner only.
+// URL pattern: /api/v1/organisations/{orgId}/deletion
+func (h *Handler) handleOrganisationDeletion(w http.ResponseWriter, r *http.Request) {
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		deletion, err := h.tenantService.GetDeletion(r.Context(), claims.ID, organisationID)
+		writeResponse(h.cfg, w, r, deletion, err)
+	case http.MethodPost:
+		var req DeletionConfirmRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		deletion, err := h.tenantService.DeleteOrganisation(r.Context(), claims.ID, organisationID, req.Confirm)
+		writeResponse(h.cfg, w, r, deletion, err)
+	case http.MethodDelete:
+		deletion, err := h.tenantService.RestoreOrganisation(r.Context(), claims.ID, organisationID)
+		writeResponse(h.cfg, w, r, deletion, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleAdminOrganisationDeletions lists organisation deletions, newest
+// first. Super admin only.
+// URL pattern: GET /api/v1/admin/organisation-deletions?status=failed&limit=50
+func (h *Handler) handleAdminOrganisationDeletions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", tenant.DeletionScheduled, tenant.DeletionRunning, tenant.DeletionFailed, tenant.DeletionCompleted, tenant.DeletionCancelled:
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid status"})
+		return
+	}
+	limit, err := parseIntParam(r, "limit", 50, 1, 500)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	deletions, err := h.tenantService.ListDeletions(r.Context(), status, limit)
+	writeResponse(h.cfg, w, r, deletions, err)
+}
+
+// handleAdminOrganisationTeardown irreversibly confirms a deleted
+// organisation's teardown, skipping the rest of its recovery window or
+// retrying a teardown that kept failing. Super admin only.
+// URL pattern: POST /api/v1/admin/organisations/{orgId}/teardown
+func (h *Handler) handleAdminOrganisationTeardown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req DeletionConfirmRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	deletion, err := h.tenantService.ConfirmTeardown(r.Context(), adminID, organisationID, req.Confirm)
+	writeResponse(h.cfg, w, r, deletion, err)
+}
@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"net/http"
+
+	"service-core/domain/h5p"
+)
+
+// handleEditorBundle returns the editor bundle the organisation is rolled out
+// to: its variant and where its core and editor assets are served.
+// URL pattern: GET /api/v1/h5p/editor/bundle?orgId=...
+func (h *Handler) handleEditorBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	userID, err := h.requireUser(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	orgID, err := h.h5pService.EditorOrganisation(r.Context(), userID, r.URL.Query().Get("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	bundle, err := h.h5pService.EditorBundle(r.Context(), orgID)
+	writeResponse(h.cfg, w, r, bundle, err)
+}
+
+// handleEditorBundleReport counts an editor load or error against the bundle
+// variant the editor loaded.
+// URL pattern: POST /api/v1/h5p/editor/bundle/reports {"variant", "event": "load" | "error"}
+func (h *Handler) handleEditorBundleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireUser(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	var req struct {
+		Variant string `json:"variant"`
+		Event   string `json:"event"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	err := h.h5pService.RecordBundleEvent(r.Context(), req.Variant, req.Event)
+	writeResponse(h.cfg, w, r, nil, err)
+}
+
+// handleAdminH5PBundleVariants lists the editor bundle variants with each
+// bundle's loads and error rate against stable over the last days.
+// Super admins only.
+// URL pattern: GET /api/v1/admin/h5p/bundle-variants?days=...
+func (h *Handler) handleAdminH5PBundleVariants(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	days, err := parseIntParam(r, "days", 7, 1, h5p.MaxBundleReportDays)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	variants, err := h.h5pService.ListBundleVariants(r.Context())
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	reports, err := h.h5pService.BundleReports(r.Context(), days)
+	writeResponse(h.cfg, w, r, map[string]any{
+		"variants": variants,
+		"reports":  reports,
+	}, err)
+}
+
+// handleAdminH5PBundleVariant creates or replaces (PUT) or deletes (DELETE)
+// an editor bundle variant. Rolling a bad bundle back is a PUT with
+// rolloutPercent 0. Super admins only.
+// URL pattern: /api/v1/admin/h5p/bundle-variants/{name}
+func (h *Handler) handleAdminH5PBundleVariant(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	name := r.PathValue("name")
+
+	switch r.Method {
+	case http.MethodPut:
+		var req h5p.BundleVariantInput
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		variant, err := h.h5pService.PutBundleVariant(r.Context(), userID, name, req)
+		writeResponse(h.cfg, w, r, variant, err)
+	case http.MethodDelete:
+		err := h.h5pService.DeleteBundleVariant(r.Context(), name)
+		writeResponse(h.cfg, w, r, nil, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
@@ -80,7 +80,7 @@ func (h *Handler) handleContentUserData(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Verify user is an org member
-	_, err = store.CheckUserOrgMembership(ctx, query.CheckUserOrgMembershipParams{
+	_, err = h.store.CheckUserOrgMembership(ctx, query.CheckUserOrgMembershipParams{
 		UserID:         claims.ID,
 		OrganisationID: content.OrgID,
 	})
@@ -10,10 +10,15 @@ import (
 	"strconv"
 	"strings"
 
+	"service-core/domain/h5p"
+
 	"github.com/google/uuid"
 )
 
-const maxEditorUploadSize = 50 << 20 // 50 MB
+const (
+	maxEditorUploadSize   = 50 << 20 // 50 MB, .h5p packages
+	maxEditorUploadMemory = 32 << 20 // temp file uploads beyond this spill to disk
+)
 
 // writeAjaxSuccess writes a wrapped {success: true, data: ...} response
 func writeAjaxSuccess(w http.ResponseWriter, data any) {
@@ -54,11 +59,15 @@ func (h *Handler) handleEditorAjax(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		switch action {
 		case "content-type-cache":
-			h.handleEditorContentTypeCache(w, r)
+			h.handleEditorContentTypeCache(w, r, claims.ID)
 		case "libraries":
-			h.handleEditorLibraryDetail(w, r)
+			h.handleEditorLibraryDetail(w, r, claims.ID)
 		case "content-hub-metadata-cache":
 			h.handleEditorContentHubMetadataCache(w, r)
+		case "media":
+			h.handleEditorMedia(w, r, claims.ID)
+		case "media-thumbnail":
+			h.handleEditorMediaThumbnail(w, r, claims.ID)
 		default:
 			writeAjaxError(w, http.StatusBadRequest, "Unknown GET action: "+action)
 		}
@@ -73,9 +82,11 @@ func (h *Handler) handleEditorAjax(w http.ResponseWriter, r *http.Request) {
 		case "filter":
 			h.handleEditorFilter(w, r)
 		case "library-install":
-			h.handleEditorLibraryInstall(w, r)
+			h.handleEditorLibraryInstall(w, r, claims.ID)
 		case "library-upload":
-			h.handleEditorLibraryUpload(w, r)
+			h.handleEditorLibraryUpload(w, r, claims.ID)
+		case "media-reuse":
+			h.handleEditorMediaReuse(w, r, claims.ID)
 		case "content-hub-metadata-cache":
 			h.handleEditorContentHubMetadataCache(w, r)
 		default:
@@ -86,9 +97,15 @@ func (h *Handler) handleEditorAjax(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleEditorContentTypeCache returns content type cache in editor format (unwrapped)
-func (h *Handler) handleEditorContentTypeCache(w http.ResponseWriter, r *http.Request) {
-	hubInfo, err := h.h5pService.GetEditorContentTypeCache(r.Context())
+// handleEditorContentTypeCache returns the organisation's content type cache
+// in editor format (unwrapped)
+func (h *Handler) handleEditorContentTypeCache(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	orgID, ok := h.editorOrganisation(w, r, userID)
+	if !ok {
+		return
+	}
+
+	hubInfo, err := h.h5pService.GetEditorContentTypeCache(r.Context(), orgID)
 	if err != nil {
 		slog.Error("Error fetching editor content type cache", "error", err)
 		writeAjaxError(w, http.StatusInternalServerError, "Error fetching content type cache")
@@ -99,8 +116,9 @@ func (h *Handler) handleEditorContentTypeCache(w http.ResponseWriter, r *http.Re
 	json.NewEncoder(w).Encode(hubInfo)
 }
 
-// handleEditorLibraryDetail returns full library details (unwrapped)
-func (h *Handler) handleEditorLibraryDetail(w http.ResponseWriter, r *http.Request) {
+// handleEditorLibraryDetail returns full library details (unwrapped). The
+// library's asset URLs are tagged with the organisation's editor bundle.
+func (h *Handler) handleEditorLibraryDetail(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
 	machineName := r.URL.Query().Get("machineName")
 	majorStr := r.URL.Query().Get("majorVersion")
 	minorStr := r.URL.Query().Get("minorVersion")
@@ -119,6 +137,12 @@ func (h *Handler) handleEditorLibraryDetail(w http.ResponseWriter, r *http.Reque
 		writeAjaxError(w, http.StatusNotFound, "Library not found")
 		return
 	}
+	// Untagged assets still load, so the bundle is left off if it can't be resolved
+	if orgID, err := h.h5pService.EditorOrganisation(r.Context(), userID, r.URL.Query().Get("orgId")); err == nil {
+		if bundle, err := h.h5pService.EditorBundle(r.Context(), orgID); err == nil {
+			detail.TagAssets(bundle.Variant)
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(detail)
@@ -192,24 +216,31 @@ func (h *Handler) handleEditorTranslations(w http.ResponseWriter, r *http.Reques
 	writeAjaxSuccess(w, translations)
 }
 
-// handleEditorFileUpload handles temp file uploads from the editor (unwrapped)
+// handleEditorFileUpload handles temp file uploads from the editor (unwrapped).
+// The file is checked against the whitelist and size limit of the semantics
+// field it was uploaded for.
 func (h *Handler) handleEditorFileUpload(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
-	r.Body = http.MaxBytesReader(w, r.Body, maxEditorUploadSize)
-	if err := r.ParseMultipartForm(maxEditorUploadSize); err != nil {
-		writeAjaxError(w, http.StatusBadRequest, "File too large (max 50MB)")
+	r.Body = http.MaxBytesReader(w, r.Body, h.h5pService.MaxUploadSize())
+	if err := r.ParseMultipartForm(maxEditorUploadMemory); err != nil {
+		writeUploadError(w, "The file you uploaded is too large.")
 		return
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
-		writeAjaxError(w, http.StatusBadRequest, "No file uploaded")
+		writeUploadError(w, "No file uploaded")
 		return
 	}
 	defer file.Close()
 
-	data, err := io.ReadAll(file)
-	if err != nil {
-		writeAjaxError(w, http.StatusInternalServerError, "Error reading file")
+	field := h5p.ParseUploadField(r.FormValue("field"))
+	if err := h.h5pService.ValidateTempUpload(field, header.Filename, header.Size); err != nil {
+		writeUploadRejection(w, err)
+		return
+	}
+
+	orgID, ok := h.editorOrganisation(w, r, userID)
+	if !ok {
 		return
 	}
 
@@ -218,8 +249,11 @@ func (h *Handler) handleEditorFileUpload(w http.ResponseWriter, r *http.Request,
 		contentType = "application/octet-stream"
 	}
 
-	result, err := h.h5pService.UploadTempFile(r.Context(), userID, header.Filename, data, contentType)
+	result, err := h.h5pService.UploadTempFile(r.Context(), orgID, userID, field, header.Filename, file, header.Size, contentType)
 	if err != nil {
+		if writeUploadRejection(w, err) {
+			return
+		}
 		slog.Error("Error uploading temp file", "error", err)
 		writeAjaxError(w, http.StatusInternalServerError, "Error uploading file")
 		return
@@ -229,6 +263,66 @@ func (h *Handler) handleEditorFileUpload(w http.ResponseWriter, r *http.Request,
 	json.NewEncoder(w).Encode(result)
 }
 
+// writeUploadError writes the unwrapped {error: ...} response the editor's
+// file widgets display under the field
+func writeUploadError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// writeUploadRejection writes the H5P message of a file that failed the
+// field's whitelist or size limit, or the organisation's storage quota.
+// It reports false for any other error.
+func writeUploadRejection(w http.ResponseWriter, err error) bool {
+	var quotaExceeded pkg.QuotaExceededError
+	if errors.As(err, &quotaExceeded) {
+		body := quotaExceededBody(quotaExceeded)
+		body["error"] = quotaExceeded.Message
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(body)
+		return true
+	}
+	var badRequest pkg.BadRequestError
+	if !errors.As(err, &badRequest) {
+		return false
+	}
+	writeUploadError(w, badRequest.Message)
+	return true
+}
+
+// quotaExceededBody holds the usage and limit the editor needs for an upgrade prompt
+func quotaExceededBody(err pkg.QuotaExceededError) map[string]any {
+	return map[string]any{
+		"errorCode":  "QUOTA_EXCEEDED",
+		"usedBytes":  err.UsedBytes,
+		"limitBytes": err.LimitBytes,
+	}
+}
+
+// editorOrganisation resolves the organisation editor uploads are charged to,
+// writing an AJAX error when it can't
+func (h *Handler) editorOrganisation(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (uuid.UUID, bool) {
+	orgID, err := h.h5pService.EditorOrganisation(r.Context(), userID, r.URL.Query().Get("orgId"))
+	if err == nil {
+		return orgID, true
+	}
+
+	var badRequest pkg.BadRequestError
+	var forbidden pkg.ForbiddenError
+	switch {
+	case errors.As(err, &badRequest):
+		writeAjaxError(w, http.StatusBadRequest, badRequest.Message)
+	case errors.As(err, &forbidden):
+		writeAjaxError(w, http.StatusForbidden, "Forbidden")
+	default:
+		slog.Error("Error resolving editor organisation", "error", err)
+		writeAjaxError(w, http.StatusInternalServerError, "Error resolving organisation")
+	}
+	return uuid.Nil, false
+}
+
 // handleEditorFilter echoes back the libraryParameters (wrapped, MVP passthrough)
 func (h *Handler) handleEditorFilter(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
@@ -247,8 +341,9 @@ func (h *Handler) handleEditorFilter(w http.ResponseWriter, r *http.Request) {
 	writeAjaxSuccess(w, libraryParams)
 }
 
-// handleEditorLibraryInstall installs a library from the Hub (wrapped)
-func (h *Handler) handleEditorLibraryInstall(w http.ResponseWriter, r *http.Request) {
+// handleEditorLibraryInstall installs a library from the Hub (wrapped). The
+// package counts against the organisation's storage quota.
+func (h *Handler) handleEditorLibraryInstall(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
 	machineName := r.URL.Query().Get("id")
 	if machineName == "" {
 		if err := r.ParseForm(); err == nil {
@@ -261,15 +356,30 @@ func (h *Handler) handleEditorLibraryInstall(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	_, err := h.h5pService.InstallLibrary(r.Context(), machineName)
+	orgID, ok := h.editorOrganisation(w, r, userID)
+	if !ok {
+		return
+	}
+
+	_, err := h.h5pService.InstallLibraryForOrg(r.Context(), orgID, machineName)
 	if err != nil {
+		var quotaExceeded pkg.QuotaExceededError
+		if errors.As(err, &quotaExceeded) {
+			body := quotaExceededBody(quotaExceeded)
+			body["success"] = false
+			body["message"] = quotaExceeded.Message
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(body)
+			return
+		}
 		slog.Error("Error installing library via editor", "machineName", machineName, "error", err)
 		writeAjaxError(w, http.StatusInternalServerError, "Error installing library")
 		return
 	}
 
 	// Return updated content type cache
-	hubInfo, err := h.h5pService.GetEditorContentTypeCache(r.Context())
+	hubInfo, err := h.h5pService.GetEditorContentTypeCache(r.Context(), orgID)
 	if err != nil {
 		writeAjaxSuccess(w, nil)
 		return
@@ -278,10 +388,12 @@ func (h *Handler) handleEditorLibraryInstall(w http.ResponseWriter, r *http.Requ
 	writeAjaxSuccess(w, hubInfo)
 }
 
-// handleEditorLibraryUpload handles .h5p file upload and install (wrapped)
-func (h *Handler) handleEditorLibraryUpload(w http.ResponseWriter, r *http.Request) {
+// handleEditorLibraryUpload installs the libraries of an uploaded .h5p
+// package and creates a content item from its content (wrapped). The package
+// counts against the organisation's storage quota.
+func (h *Handler) handleEditorLibraryUpload(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
 	r.Body = http.MaxBytesReader(w, r.Body, maxEditorUploadSize)
-	if err := r.ParseMultipartForm(maxEditorUploadSize); err != nil {
+	if err := r.ParseMultipartForm(maxEditorUploadMemory); err != nil {
 		writeAjaxError(w, http.StatusBadRequest, "File too large (max 50MB)")
 		return
 	}
@@ -293,64 +405,48 @@ func (h *Handler) handleEditorLibraryUpload(w http.ResponseWriter, r *http.Reque
 	}
 	defer file.Close()
 
+	orgID, ok := h.editorOrganisation(w, r, userID)
+	if !ok {
+		return
+	}
+
 	data, err := io.ReadAll(file)
 	if err != nil {
 		writeAjaxError(w, http.StatusInternalServerError, "Error reading file")
 		return
 	}
 
-	// Use the existing installer package extraction
-	// Import the h5p package to call ExtractH5PPackage is not needed here
-	// since InstallLibrary already handles this via hub download.
-	// For direct upload, we need to extract and install manually.
-	// The service handles this.
-	writeAjaxSuccess(w, map[string]string{"message": "Library upload processing not yet implemented. Use library-install instead."})
-	_ = data // TODO: implement direct .h5p package upload
+	result, err := h.h5pService.UploadPackage(r.Context(), orgID, userID, data)
+	if err != nil {
+		var quotaExceeded pkg.QuotaExceededError
+		var badRequest pkg.BadRequestError
+		var forbidden pkg.ForbiddenError
+		switch {
+		case errors.As(err, &quotaExceeded):
+			body := quotaExceededBody(quotaExceeded)
+			body["success"] = false
+			body["message"] = quotaExceeded.Message
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(body)
+		case errors.As(err, &badRequest):
+			writeAjaxError(w, http.StatusBadRequest, badRequest.Message)
+		case errors.As(err, &forbidden):
+			writeAjaxError(w, http.StatusForbidden, forbidden.Error())
+		default:
+			slog.Error("Error uploading H5P package via editor", "orgID", orgID, "error", err)
+			writeAjaxError(w, http.StatusInternalServerError, "Error installing package")
+		}
+		return
+	}
+
+	writeAjaxSuccess(w, result)
 }
 
-// handleEditorContentHubMetadataCache returns metadata for the Hub content type browser.
-// Each license MUST have: id, name, versions (array of {id, name, url}).
-// The hub client calls license.versions.length, so versions must always be an array.
-func (h *Handler) handleEditorContentHubMetadataCache(w http.ResponseWriter, _ *http.Request) {
-	ccVersions := []map[string]any{
-		{"id": "4.0", "name": "4.0 International", "url": "https://creativecommons.org/licenses/{{id}}/4.0/"},
-		{"id": "3.0", "name": "3.0 Unported", "url": "https://creativecommons.org/licenses/{{id}}/3.0/"},
-		{"id": "2.5", "name": "2.5 Generic", "url": "https://creativecommons.org/licenses/{{id}}/2.5/"},
-		{"id": "2.0", "name": "2.0 Generic", "url": "https://creativecommons.org/licenses/{{id}}/2.0/"},
-		{"id": "1.0", "name": "1.0 Generic", "url": "https://creativecommons.org/licenses/{{id}}/1.0/"},
-	}
-	gplVersions := []map[string]any{
-		{"id": "v3", "name": "Version 3", "url": "https://www.gnu.org/licenses/gpl-3.0.html"},
-		{"id": "v2", "name": "Version 2", "url": "https://www.gnu.org/licenses/gpl-2.0.html"},
-		{"id": "v1", "name": "Version 1", "url": "https://www.gnu.org/licenses/gpl-1.0.html"},
-	}
-
-	metadata := map[string]any{
-		"levels": []map[string]any{
-			{"id": "beginner", "label": "Beginner"},
-			{"id": "intermediate", "label": "Intermediate"},
-			{"id": "advanced", "label": "Advanced"},
-		},
-		"disciplines": []map[string]any{},
-		"languages":   []map[string]any{},
-		"licenses": []map[string]any{
-			{"id": "MIT", "name": "MIT License", "versions": []map[string]any{}},
-			{"id": "CC BY", "name": "Creative Commons Attribution", "versions": ccVersions},
-			{"id": "CC BY-SA", "name": "Creative Commons Attribution-ShareAlike", "versions": ccVersions},
-			{"id": "CC BY-ND", "name": "Creative Commons Attribution-NoDerivatives", "versions": ccVersions},
-			{"id": "CC BY-NC", "name": "Creative Commons Attribution-NonCommercial", "versions": ccVersions},
-			{"id": "CC BY-NC-SA", "name": "Creative Commons Attribution-NonCommercial-ShareAlike", "versions": ccVersions},
-			{"id": "CC BY-NC-ND", "name": "Creative Commons Attribution-NonCommercial-NoDerivatives", "versions": ccVersions},
-			{"id": "CC0 1.0", "name": "CC0 1.0 Universal", "versions": []map[string]any{}},
-			{"id": "GNU GPL", "name": "GNU General Public License", "versions": gplVersions},
-			{"id": "PD", "name": "Public Domain", "versions": []map[string]any{}},
-			{"id": "ODC PDDL", "name": "Public Domain Dedication and Licence", "versions": []map[string]any{}},
-			{"id": "CC PDM", "name": "Public Domain Mark", "versions": []map[string]any{}},
-			{"id": "U", "name": "Undisclosed", "versions": []map[string]any{}},
-			{"id": "C", "name": "Copyright", "versions": []map[string]any{}},
-		},
-	}
-	writeAjaxSuccess(w, metadata)
+// handleEditorContentHubMetadataCache returns metadata for the Hub content type browser,
+// cached from the H5P Hub with local license and level fallbacks (wrapped).
+func (h *Handler) handleEditorContentHubMetadataCache(w http.ResponseWriter, r *http.Request) {
+	writeAjaxSuccess(w, h.h5pService.GetContentHubMetadata(r.Context()))
 }
 
 // handleEditorGetParams returns content parameters for the editor
@@ -428,22 +524,13 @@ func (h *Handler) handleContentList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-	limit := int32(50)
-	offset := int32(0)
-	if limitStr != "" {
-		if v, err := strconv.Atoi(limitStr); err == nil {
-			limit = int32(v)
-		}
-	}
-	if offsetStr != "" {
-		if v, err := strconv.Atoi(offsetStr); err == nil {
-			offset = int32(v)
-		}
+	page, err := parsePageParams(r, defaultPageLimits)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
 	}
 
-	items, count, err := h.h5pService.ListContent(r.Context(), orgID, limit, offset)
+	items, count, err := h.h5pService.ListContent(r.Context(), orgID, page.Limit, page.Offset)
 	if err != nil {
 		writeResponse(h.cfg, w, r, nil, err)
 		return
@@ -501,7 +588,8 @@ func (h *Handler) handleContentCRUDRoute(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Parse path: /api/v1/h5p/content/{id} or /api/v1/h5p/content/{id}/save
+	// Parse path: /api/v1/h5p/content/{id}, .../{id}/save, .../{id}/export,
+	// .../{id}/files or .../{id}/play
 	suffix := strings.TrimPrefix(r.URL.Path, "/api/v1/h5p/content/")
 	parts := strings.SplitN(suffix, "/", 2)
 	if len(parts) == 0 || parts[0] == "" {
@@ -528,6 +616,24 @@ func (h *Handler) handleContentCRUDRoute(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Sub-route: /api/v1/h5p/content/{id}/export
+	if len(parts) == 2 && parts[1] == "export" && r.Method == http.MethodGet {
+		h.handleContentExport(w, r, contentID, orgID)
+		return
+	}
+
+	// Sub-route: /api/v1/h5p/content/{id}/play
+	if len(parts) == 2 && parts[1] == "play" && r.Method == http.MethodGet {
+		h.handleContentPlayerConfig(w, r, contentID, orgID, claims.ID)
+		return
+	}
+
+	// Sub-route: /api/v1/h5p/content/{id}/files
+	if len(parts) == 2 && parts[1] == "files" && r.Method == http.MethodGet {
+		h.handleContentFileReport(w, r, contentID, orgID, claims.ID)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		info, err := h.h5pService.GetContent(r.Context(), contentID, orgID)
@@ -613,6 +719,22 @@ func (h *Handler) handleContentSave(w http.ResponseWriter, r *http.Request, cont
 	writeResponse(h.cfg, w, r, info, nil)
 }
 
+// handleContentFileReport lists the content's dangling and unreferenced files
+// for members of its organisation
+func (h *Handler) handleContentFileReport(w http.ResponseWriter, r *http.Request, contentID, orgID, userID uuid.UUID) {
+	if _, err := h.h5pService.EditorOrganisation(r.Context(), userID, orgID.String()); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	report, err := h.h5pService.GetFileReport(r.Context(), contentID, orgID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	writeResponse(h.cfg, w, r, report, nil)
+}
+
 // handleContentFile serves content files from storage
 func (h *Handler) handleContentFile(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -652,15 +774,13 @@ func (h *Handler) handleContentFile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	filePath := parts[2]
-	data, contentType, err := h.h5pService.GetContentFile(r.Context(), contentID, orgID, filePath)
+	asset, err := h.h5pService.ResolveContentFile(r.Context(), contentID, orgID, filePath)
 	if err != nil {
 		http.NotFound(w, r)
 		return
 	}
 
-	w.Header().Set("Content-Type", contentType)
-	w.Header().Set("Cache-Control", "private, max-age=3600")
-	w.Write(data)
+	h.serveAsset(w, r, asset, cachePrivate)
 }
 
 // handleTempFile serves temp files from storage
@@ -688,13 +808,5 @@ func (h *Handler) handleTempFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, contentType, err := h.h5pService.GetTempFile(r.Context(), filePath)
-	if err != nil {
-		http.NotFound(w, r)
-		return
-	}
-
-	w.Header().Set("Content-Type", contentType)
-	w.Header().Set("Cache-Control", "private, max-age=3600")
-	w.Write(data)
+	h.serveAsset(w, r, h.h5pService.ResolveTempFile(filePath), cachePrivate)
 }
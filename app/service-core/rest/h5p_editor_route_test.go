@@ -0,0 +1,642 @@
+package rest
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"app/pkg/auth"
+	"service-core/config"
+	"service-core/domain/file"
+	"service-core/domain/h5p"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Editor AJAX conformance tests. Requests mirror the payloads the official H5P
+// editor (h5peditor.js) sends for each action, and testdata/h5p_editor holds
+// the exact response bytes it expects — including whether an action is wrapped
+// in {success, data} or returned bare. A mismatch here means the JS client
+// would break, so update the golden files only when the H5P spec changes.
+
+const editorTestToken = "editor-test-token"
+
+var (
+	editorTestUserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	editorTestOrgID  = uuid.MustParse("00000000-0000-0000-0000-0000000000a1") // the user's default organisation, on the free tier
+)
+
+// Installed libraries and their extracted files, as the installer stores them.
+var (
+	multiChoiceID = uuid.MustParse("10000000-0000-0000-0000-000000000001")
+	questionID    = uuid.MustParse("10000000-0000-0000-0000-000000000002")
+
+	editorTestFiles = map[string]string{
+		"h5p-libraries/extracted/H5P.MultiChoice-1.16.4/library.json":      `{"title":"Multiple Choice","machineName":"H5P.MultiChoice","majorVersion":1,"minorVersion":16,"patchVersion":4,"runnable":1,"preloadedJs":[{"path":"js/multichoice.js"}],"preloadedCss":[{"path":"css/multichoice.css"}],"preloadedDependencies":[{"machineName":"H5P.Question","majorVersion":1,"minorVersion":5}]}`,
+		"h5p-libraries/extracted/H5P.MultiChoice-1.16.4/semantics.json":    `[{"name":"question","type":"text","label":"Question","importance":"high"},{"name":"answers","type":"list","label":"Available options","min":1,"entity":"option","field":{"name":"answer","type":"group","fields":[{"name":"text","type":"text","label":"Text"},{"name":"correct","type":"boolean","label":"Correct","default":false}]}}]`,
+		"h5p-libraries/extracted/H5P.MultiChoice-1.16.4/language/en.json":  `{"semantics":[{"label":"Question"},{"label":"Available options","entity":"option"}]}`,
+		"h5p-libraries/extracted/H5P.MultiChoice-1.16.4/language/nb.json":  `{"semantics":[{"label":"Sporsmal"},{"label":"Tilgjengelige alternativer","entity":"alternativ"}]}`,
+		"h5p-libraries/extracted/H5P.MultiChoice-1.16.4/js/multichoice.js": `H5P.MultiChoice = function () {};`,
+		"h5p-libraries/extracted/H5P.Question-1.5.10/library.json":         `{"title":"Question","machineName":"H5P.Question","majorVersion":1,"minorVersion":5,"patchVersion":10,"runnable":0,"preloadedJs":[{"path":"scripts/question.js"}],"preloadedCss":[{"path":"styles/question.css"}]}`,
+		"h5p-libraries/extracted/H5P.Question-1.5.10/language/en.json":     `{"libraryStrings":{"tipLabel":"Tip"}}`,
+	}
+)
+
+// ---------------------------------------------------------------------------
+// fakes
+// ---------------------------------------------------------------------------
+
+// fakeAuth accepts editorTestToken only.
+type fakeAuth struct {
+	auth.AuthService
+}
+
+func (fakeAuth) ValidateAccessToken(token string) (*auth.AccessTokenClaims, error) {
+	if token != editorTestToken {
+		return nil, errors.New("invalid token")
+	}
+	return &auth.AccessTokenClaims{ID: editorTestUserID}, nil
+}
+
+// fakeH5PStore keeps libraries and the hub cache in memory. Queries the editor
+// doesn't use are left to the embedded nil Querier and panic if called.
+type fakeH5PStore struct {
+	query.Querier
+
+	mu       sync.Mutex
+	libs     []query.H5pLibrary
+	hubCache map[string]json.RawMessage
+	deps     map[uuid.UUID][]uuid.UUID // library ID -> preloaded dependency IDs
+	used     int64                     // editorTestOrgID storage usage in bytes
+	media    []query.H5pMediaAsset     // editorTestOrgID media library
+}
+
+func (s *fakeH5PStore) find(match func(query.H5pLibrary) bool) (query.H5pLibrary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, lib := range s.libs {
+		if match(lib) {
+			return lib, nil
+		}
+	}
+	return query.H5pLibrary{}, sql.ErrNoRows
+}
+
+func (s *fakeH5PStore) GetH5PHubCache(_ context.Context, cacheKey string) (query.H5pHubCache, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.hubCache[cacheKey]
+	if !ok {
+		return query.H5pHubCache{}, sql.ErrNoRows
+	}
+	return query.H5pHubCache{CacheKey: cacheKey, Data: data}, nil
+}
+
+func (s *fakeH5PStore) UpsertH5PHubCache(_ context.Context, arg query.UpsertH5PHubCacheParams) (query.H5pHubCache, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hubCache[arg.CacheKey] = arg.Data
+	return query.H5pHubCache{ID: arg.ID, CacheKey: arg.CacheKey, Data: arg.Data, ExpiresAt: arg.ExpiresAt}, nil
+}
+
+func (s *fakeH5PStore) DeleteExpiredH5PHubCache(context.Context) error {
+	return nil
+}
+
+func (s *fakeH5PStore) ListH5PLibraries(context.Context) ([]query.H5pLibrary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]query.H5pLibrary(nil), s.libs...), nil
+}
+
+func (s *fakeH5PStore) ListH5PRunnableLibraries(context.Context) ([]query.H5pLibrary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var runnable []query.H5pLibrary
+	for _, lib := range s.libs {
+		if lib.Runnable {
+			runnable = append(runnable, lib)
+		}
+	}
+	return runnable, nil
+}
+
+func (s *fakeH5PStore) GetH5PLibraryByMachineName(_ context.Context, machineName string) (query.H5pLibrary, error) {
+	return s.find(func(lib query.H5pLibrary) bool { return lib.MachineName == machineName })
+}
+
+func (s *fakeH5PStore) GetH5PLibraryByMachineNameVersion(_ context.Context, arg query.GetH5PLibraryByMachineNameVersionParams) (query.H5pLibrary, error) {
+	return s.find(func(lib query.H5pLibrary) bool {
+		return lib.MachineName == arg.MachineName && lib.MajorVersion == arg.MajorVersion &&
+			lib.MinorVersion == arg.MinorVersion && lib.PatchVersion == arg.PatchVersion
+	})
+}
+
+func (s *fakeH5PStore) GetH5PLibraryByMachineNameMajorMinor(_ context.Context, arg query.GetH5PLibraryByMachineNameMajorMinorParams) (query.H5pLibrary, error) {
+	return s.find(func(lib query.H5pLibrary) bool {
+		return lib.MachineName == arg.MachineName && lib.MajorVersion == arg.MajorVersion && lib.MinorVersion == arg.MinorVersion
+	})
+}
+
+func (s *fakeH5PStore) GetH5PLibraryFullDependencyTree(_ context.Context, libraryID uuid.UUID) ([]query.H5pLibrary, error) {
+	s.mu.Lock()
+	depIDs := s.deps[libraryID]
+	s.mu.Unlock()
+	tree := make([]query.H5pLibrary, 0, len(depIDs))
+	for _, id := range depIDs {
+		dep, err := s.find(func(lib query.H5pLibrary) bool { return lib.ID == id })
+		if err != nil {
+			return nil, err
+		}
+		tree = append(tree, dep)
+	}
+	return tree, nil
+}
+
+func (s *fakeH5PStore) GetH5PLibraryEditorDependencyTree(context.Context, uuid.UUID) ([]query.H5pLibrary, error) {
+	return nil, nil
+}
+
+func (s *fakeH5PStore) GetH5PLibraryExportTree(context.Context, []uuid.UUID) ([]query.H5pLibrary, error) {
+	return nil, nil
+}
+
+func (s *fakeH5PStore) UpsertH5PLibrary(_ context.Context, arg query.UpsertH5PLibraryParams) (query.H5pLibrary, error) {
+	lib := query.H5pLibrary{
+		ID:            arg.ID,
+		MachineName:   arg.MachineName,
+		MajorVersion:  arg.MajorVersion,
+		MinorVersion:  arg.MinorVersion,
+		PatchVersion:  arg.PatchVersion,
+		Title:         arg.Title,
+		Origin:        arg.Origin,
+		MetadataJson:  arg.MetadataJson,
+		Description:   arg.Description,
+		PackagePath:   arg.PackagePath,
+		ExtractedPath: arg.ExtractedPath,
+		Runnable:      arg.Runnable,
+		Restricted:    arg.Restricted,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.libs = append(s.libs, lib)
+	return lib, nil
+}
+
+func (s *fakeH5PStore) UpsertH5PLibrarySemanticsCache(context.Context, query.UpsertH5PLibrarySemanticsCacheParams) error {
+	return nil
+}
+
+func (s *fakeH5PStore) DeleteH5PLibraryDependencies(_ context.Context, libraryID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.deps, libraryID)
+	return nil
+}
+
+func (s *fakeH5PStore) InsertH5PLibraryDependency(_ context.Context, arg query.InsertH5PLibraryDependencyParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if arg.DependencyType == "preloaded" {
+		s.deps[arg.LibraryID] = append(s.deps[arg.LibraryID], arg.DependsOnID)
+	}
+	return nil
+}
+
+func (s *fakeH5PStore) CancelPendingDeletions(context.Context, []string) error {
+	return nil
+}
+
+func (s *fakeH5PStore) GetH5PMediaAssetByChecksum(_ context.Context, arg query.GetH5PMediaAssetByChecksumParams) (query.H5pMediaAsset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, asset := range s.media {
+		if asset.OrgID == arg.OrgID && asset.Checksum == arg.Checksum {
+			return asset, nil
+		}
+	}
+	return query.H5pMediaAsset{}, sql.ErrNoRows
+}
+
+func (s *fakeH5PStore) TouchH5PMediaAsset(context.Context, query.TouchH5PMediaAssetParams) error {
+	return nil
+}
+
+func (s *fakeH5PStore) CreateH5PMediaAsset(_ context.Context, arg query.CreateH5PMediaAssetParams) (query.H5pMediaAsset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	asset := query.H5pMediaAsset{ID: arg.ID, OrgID: arg.OrgID, Filename: arg.Filename, MimeType: arg.MimeType, Kind: arg.Kind,
+		SizeBytes: arg.SizeBytes, Checksum: arg.Checksum, StorageKey: arg.StorageKey, ThumbnailKey: arg.ThumbnailKey, UseCount: 1}
+	s.media = append(s.media, asset)
+	return asset, nil
+}
+
+func (s *fakeH5PStore) ListH5POrgLibraries(context.Context, uuid.UUID) ([]query.ListH5POrgLibrariesRow, error) {
+	return nil, nil
+}
+
+func (s *fakeH5PStore) ListH5PBundleVariants(context.Context) ([]query.H5pBundleVariant, error) {
+	return nil, nil
+}
+
+func (s *fakeH5PStore) SelectUser(_ context.Context, id uuid.UUID) (query.User, error) {
+	return query.User{ID: id, DefaultOrganisationID: uuid.NullUUID{UUID: editorTestOrgID, Valid: true}}, nil
+}
+
+func (s *fakeH5PStore) CheckUserOrgMembership(_ context.Context, arg query.CheckUserOrgMembershipParams) (uuid.UUID, error) {
+	if arg.UserID != editorTestUserID || arg.OrganisationID != editorTestOrgID {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	return uuid.New(), nil
+}
+
+func (s *fakeH5PStore) GetOrganisationStorageQuota(context.Context, uuid.UUID) (query.GetOrganisationStorageQuotaRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return query.GetOrganisationStorageQuotaRow{SubscriptionTier: "free", BytesUsed: s.used}, nil
+}
+
+func (s *fakeH5PStore) AddOrganisationStorageUsage(_ context.Context, arg query.AddOrganisationStorageUsageParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used += arg.BytesUsed
+	return nil
+}
+
+// fakeFileProvider is an in-memory file.Provider that lists keys like R2 does:
+// sorted and relative to the prefix.
+type fakeFileProvider struct {
+	mu      sync.Mutex
+	files   map[string][]byte
+	offsets []int64 // where each DownloadStream started
+}
+
+func (p *fakeFileProvider) Upload(_ context.Context, f *file.File) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files[f.Key] = f.Data
+	return nil
+}
+
+func (p *fakeFileProvider) UploadStream(_ context.Context, key, _ string, body io.Reader, _ int64) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files[key] = data
+	return nil
+}
+
+func (p *fakeFileProvider) Download(_ context.Context, fileKey string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data, ok := p.files[fileKey]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (p *fakeFileProvider) DownloadStream(ctx context.Context, fileKey string, offset int64) (io.ReadCloser, int64, error) {
+	data, err := p.Download(ctx, fileKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	p.mu.Lock()
+	p.offsets = append(p.offsets, offset)
+	p.mu.Unlock()
+	data = data[min(offset, int64(len(data))):]
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+func (p *fakeFileProvider) Remove(_ context.Context, fileKey string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.files, fileKey)
+	return nil
+}
+
+func (p *fakeFileProvider) ListByPrefix(_ context.Context, prefix string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var keys []string
+	for key := range p.files {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, strings.TrimPrefix(key, prefix))
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// ---------------------------------------------------------------------------
+// helpers
+// ---------------------------------------------------------------------------
+
+// newEditorTestHandler wires the editor handler to in-memory fakes and a fake
+// H5P Hub that serves the H5P.TrueFalse package.
+func newEditorTestHandler(t *testing.T) (*Handler, *fakeH5PStore, *fakeFileProvider) {
+	t.Helper()
+
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/v1/content-types/H5P.TrueFalse" {
+			w.Write(trueFalsePackage(t))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(hub.Close)
+
+	hubTypes, err := os.ReadFile(filepath.Join("testdata", "h5p_editor", "hub_content_types.json"))
+	require.NoError(t, err)
+
+	store := &fakeH5PStore{
+		libs: []query.H5pLibrary{
+			{ID: multiChoiceID, MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 16, PatchVersion: 4, Title: "Multiple Choice", Origin: "official", Runnable: true},
+			{ID: questionID, MachineName: "H5P.Question", MajorVersion: 1, MinorVersion: 5, PatchVersion: 10, Title: "Question", Origin: "official"},
+		},
+		hubCache: map[string]json.RawMessage{"content-type-cache": hubTypes},
+		deps:     map[uuid.UUID][]uuid.UUID{multiChoiceID: {questionID}},
+	}
+	files := &fakeFileProvider{files: make(map[string][]byte)}
+	for key, content := range editorTestFiles {
+		files.files[key] = []byte(content)
+	}
+
+	cfg := config.LoadTestConfig()
+	cfg.H5PHubURL = hub.URL
+	return &Handler{
+		cfg:         cfg,
+		authService: fakeAuth{},
+		h5pService:  h5p.NewService(cfg, store, files, nil, nil, nil),
+	}, store, files
+}
+
+// trueFalsePackage builds a minimal .h5p package as the Hub serves it.
+func trueFalsePackage(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"h5p.json":                            `{"title":"True/False","language":"und","mainLibrary":"H5P.TrueFalse","embedTypes":["div"],"preloadedDependencies":[{"machineName":"H5P.TrueFalse","majorVersion":"1","minorVersion":"8"}]}`,
+		"H5P.TrueFalse-1.8/library.json":      `{"title":"True/False Question","machineName":"H5P.TrueFalse","majorVersion":1,"minorVersion":8,"patchVersion":2,"runnable":1,"preloadedJs":[{"path":"h5p-true-false.js"}],"preloadedDependencies":[{"machineName":"H5P.Question","majorVersion":1,"minorVersion":5}]}`,
+		"H5P.TrueFalse-1.8/semantics.json":    `[{"name":"question","type":"text","label":"Question"},{"name":"correct","type":"select","label":"Correct answer","options":[{"value":"true","label":"True"},{"value":"false","label":"False"}]}]`,
+		"H5P.TrueFalse-1.8/h5p-true-false.js": `H5P.TrueFalse = function () {};`,
+	} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// serveEditorAjax sends r through the editor AJAX dispatcher as an authenticated user.
+func serveEditorAjax(t *testing.T, h *Handler, r *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	r.AddCookie(&http.Cookie{Name: "access_token", Value: editorTestToken})
+	rec := httptest.NewRecorder()
+	h.handleEditorAjax(rec, r)
+	return rec
+}
+
+// formRequest builds an application/x-www-form-urlencoded POST, as h5peditor.js sends them.
+func formRequest(action string, form url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/h5p/editor/ajax?action="+action, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	return r
+}
+
+// assertGolden compares body byte-for-byte with testdata/h5p_editor/name.
+func assertGolden(t *testing.T, name string, body []byte) {
+	t.Helper()
+	want, err := os.ReadFile(filepath.Join("testdata", "h5p_editor", name))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(body))
+}
+
+// ---------------------------------------------------------------------------
+// conformance tests
+// ---------------------------------------------------------------------------
+
+func TestEditorAjax_Conformance(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func() *http.Request
+		golden  string
+	}{
+		{
+			name: "content-type-cache",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/api/v1/h5p/editor/ajax?action=content-type-cache", nil)
+			},
+			golden: "content_type_cache.golden.json",
+		},
+		{
+			name: "libraries GET",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/api/v1/h5p/editor/ajax?action=libraries&machineName=H5P.MultiChoice&majorVersion=1&minorVersion=16", nil)
+			},
+			golden: "libraries_get.golden.json",
+		},
+		{
+			name: "libraries POST",
+			request: func() *http.Request {
+				return formRequest("libraries", url.Values{"libraries[]": {"H5P.MultiChoice 1.16", "H5P.Question 1.5", "H5P.Missing 1.0"}})
+			},
+			golden: "libraries_post.golden.json",
+		},
+		{
+			name: "translations",
+			request: func() *http.Request {
+				return formRequest("translations", url.Values{"libraries[]": {"H5P.MultiChoice 1.16"}, "language": {"en"}})
+			},
+			golden: "translations.golden.json",
+		},
+		{
+			name: "filter",
+			request: func() *http.Request {
+				return formRequest("filter", url.Values{"libraryParameters": {`{"params":{"question":"<p>2 + 2?</p>"},"metadata":{"title":"Sums"}}`}})
+			},
+			golden: "filter.golden.json",
+		},
+		{
+			name: "library-install",
+			request: func() *http.Request {
+				// h5peditor.js passes the machine name in the query string
+				return formRequest("library-install&id=H5P.TrueFalse", url.Values{})
+			},
+			golden: "library_install.golden.json",
+		},
+		{
+			name: "unknown action",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/api/v1/h5p/editor/ajax?action=nope", nil)
+			},
+			golden: "unknown_action.golden.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, _ := newEditorTestHandler(t)
+			rec := serveEditorAjax(t, h, tt.request())
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			assertGolden(t, tt.golden, rec.Body.Bytes())
+		})
+	}
+}
+
+func TestEditorAjax_FilesUpload(t *testing.T) {
+	h, store, _ := newEditorTestHandler(t)
+
+	var pixel bytes.Buffer
+	require.NoError(t, png.Encode(&pixel, image.NewRGBA(image.Rect(0, 0, 1, 1))))
+
+	// h5peditor.js posts the field's semantics and content ID alongside the file
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("field", `{"name":"file","type":"image","label":"Image"}`))
+	require.NoError(t, mw.WriteField("contentId", "0"))
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="pixel.png"`)
+	header.Set("Content-Type", "image/png")
+	part, err := mw.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(pixel.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/h5p/editor/ajax?action=files", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := serveEditorAjax(t, h, r)
+
+	// The temp file ID is random; everything else must match exactly
+	tempID := regexp.MustCompile(`^(\{"path":"[0-9a-f-]{36}/)[0-9a-f-]{36}/`)
+	got := tempID.ReplaceAll(rec.Body.Bytes(), []byte("${1}TEMP_ID/"))
+	assertGolden(t, "files.golden.json", got)
+	require.Len(t, store.media, 1, "upload is kept in the media library")
+	assert.Equal(t, "pixel.png", store.media[0].Filename)
+	assert.Equal(t, int64(2*pixel.Len()), store.used, "upload and its media library copy are charged to the default organisation")
+}
+
+func TestEditorAjax_FilesUploadQuotaExceeded(t *testing.T) {
+	h, store, _ := newEditorTestHandler(t)
+	store.used = 2048<<20 - 8 // free tier quota nearly used up
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("field", `{"name":"file","type":"file","label":"File"}`))
+	part, err := mw.CreateFormFile("file", "notes.txt")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("sixteen bytes!!!"))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/h5p/editor/ajax?action=files&orgId="+editorTestOrgID.String(), &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := serveEditorAjax(t, h, r)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assertGolden(t, "files_quota_exceeded.golden.json", rec.Body.Bytes())
+	assert.Equal(t, int64(2048<<20-8), store.used)
+}
+
+func TestEditorAjax_FilesUploadRejected(t *testing.T) {
+	h, _, _ := newEditorTestHandler(t)
+
+	// A text file posted for an image field fails the image whitelist
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("field", `{"name":"file","type":"image","label":"Image"}`))
+	part, err := mw.CreateFormFile("file", "notes.txt")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("not an image"))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/h5p/editor/ajax?action=files", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := serveEditorAjax(t, h, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assertGolden(t, "files_rejected.golden.json", rec.Body.Bytes())
+}
+
+func TestEditorAjax_Unauthorized(t *testing.T) {
+	h, _, _ := newEditorTestHandler(t)
+	rec := httptest.NewRecorder()
+	h.handleEditorAjax(rec, httptest.NewRequest(http.MethodGet, "/api/v1/h5p/editor/ajax?action=content-type-cache", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assertGolden(t, "unauthorized.golden.json", rec.Body.Bytes())
+}
+
+func TestTempFile_RangeAndRevalidation(t *testing.T) {
+	h, _, files := newEditorTestHandler(t)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("field", `{"name":"file","type":"file","label":"File"}`))
+	part, err := mw.CreateFormFile("file", "notes.txt")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("sixteen bytes!!!"))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/h5p/editor/ajax?action=files", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := serveEditorAjax(t, h, r)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var uploaded struct {
+		Path string `json:"path"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &uploaded))
+
+	get := func(header, value string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/h5p/temp-files/"+strings.TrimSuffix(uploaded.Path, "#tmp"), nil)
+		r.AddCookie(&http.Cookie{Name: "access_token", Value: editorTestToken})
+		r.Header.Set(header, value)
+		rec := httptest.NewRecorder()
+		h.handleTempFile(rec, r)
+		return rec
+	}
+
+	// Seeking a video asks for part of the file, which is read from storage
+	// from the start of the range
+	rec = get("Range", "bytes=8-12")
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "bytes", rec.Body.String())
+	assert.Equal(t, "bytes 8-12/16", rec.Header().Get("Content-Range"))
+	assert.Equal(t, []int64{0, 8}, files.offsets)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rec = get("If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.Bytes())
+}
@@ -0,0 +1,113 @@
+package rest
+
+import (
+	"app/pkg"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/h5p"
+)
+
+// unsafeFilenameChars are replaced when a content title becomes a filename.
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// handleContentExport exports the questions of a content item for exams run
+// on paper or in another assessment system.
+// Without format it returns the questions and mapping warnings as JSON, so the
+// author can review what will be lost before downloading. format=html or pdf
+// downloads a printable paper, with answers filled in when answers=true;
+// format=qti downloads an IMS QTI 2.1 package. Every download reports the
+// number of mapping warnings in X-Export-Warnings.
+// format=h5p instead downloads the content item with its libraries as an .h5p
+// package that other H5P platforms can import.
+// URL pattern: GET /api/v1/h5p/content/{id}/export?orgId=...&format=...&answers=...
+func (h *Handler) handleContentExport(w http.ResponseWriter, r *http.Request, contentID, orgID uuid.UUID) {
+	format := r.URL.Query().Get("format")
+	answerKey := r.URL.Query().Get("answers") == "true"
+	switch format {
+	case "", h5p.ExportFormatHTML, h5p.ExportFormatPDF, h5p.ExportFormatQTI:
+	case h5p.ExportFormatH5P:
+		h.handleContentPackageExport(w, r, contentID, orgID)
+		return
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "format must be html, pdf, qti or h5p"})
+		return
+	}
+
+	export, err := h.h5pService.BuildAssessmentExport(r.Context(), contentID, orgID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	if format == "" {
+		writeResponse(h.cfg, w, r, export, nil)
+		return
+	}
+	if len(export.Questions) == 0 {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Content has no questions that can be exported"})
+		return
+	}
+
+	var data []byte
+	var contentType, ext string
+	switch format {
+	case h5p.ExportFormatHTML:
+		data, err = export.PrintHTML(answerKey)
+		contentType, ext = "text/html; charset=utf-8", "html"
+	case h5p.ExportFormatPDF:
+		data, err = h.h5pService.RenderAssessmentPDF(r.Context(), export, answerKey)
+		contentType, ext = "application/pdf", "pdf"
+	case h5p.ExportFormatQTI:
+		data, err = export.QTIPackage()
+		contentType, ext = "application/zip", "zip"
+	}
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	slog.Info("Content exported", "contentID", contentID, "orgID", orgID, "format", format, "questions", len(export.Questions), "warnings", len(export.Warnings))
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+exportFilename(export.Title, format, answerKey, ext)+"\"")
+	w.Header().Set("X-Export-Warnings", strconv.Itoa(len(export.Warnings)))
+	w.Write(data)
+}
+
+// handleContentPackageExport streams a content item as an .h5p package. The
+// zip is written as its files are read, so a storage failure part way through
+// can only be logged and leaves the client with a truncated download.
+func (h *Handler) handleContentPackageExport(w http.ResponseWriter, r *http.Request, contentID, orgID uuid.UUID) {
+	export, err := h.h5pService.ExportContent(r.Context(), contentID, orgID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+export.Filename+"\"")
+	if err := export.Write(r.Context(), w); err != nil {
+		slog.Error("Failed to stream content package", "contentID", contentID, "orgID", orgID, "error", err)
+		return
+	}
+	slog.Info("Content exported", "contentID", contentID, "orgID", orgID, "format", h5p.ExportFormatH5P)
+}
+
+// exportFilename builds a download name such as "unit-3-quiz-answers.pdf".
+func exportFilename(title, format string, answerKey bool, ext string) string {
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if name == "" {
+		name = "assessment"
+	}
+	switch {
+	case format == h5p.ExportFormatQTI:
+		name += "-qti"
+	case answerKey:
+		name += "-answers"
+	}
+	return fmt.Sprintf("%s.%s", name, ext)
+}
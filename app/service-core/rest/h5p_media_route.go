@@ -0,0 +1,153 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"service-core/domain/h5p"
+
+	"github.com/google/uuid"
+)
+
+// handleEditorMedia browses and searches the organisation's media library
+// (wrapped). Query: type (image, video, audio or file), q (part of the
+// filename), before (the last asset ID of the previous page) and limit.
+// GET /api/v1/h5p/editor/ajax?action=media
+func (h *Handler) handleEditorMedia(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	orgID, ok := h.editorOrganisation(w, r, userID)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := h5p.MediaFilter{Kind: q.Get("type"), Search: q.Get("q")}
+	if before := q.Get("before"); before != "" {
+		id, err := uuid.Parse(before)
+		if err != nil {
+			writeAjaxError(w, http.StatusBadRequest, "Invalid before")
+			return
+		}
+		filter.Before = id
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > h5p.MaxMediaPageSize {
+			writeAjaxError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		filter.Limit = n
+	}
+
+	assets, err := h.h5pService.ListMediaAssets(r.Context(), orgID, filter)
+	if err != nil {
+		var badRequest pkg.BadRequestError
+		if errors.As(err, &badRequest) {
+			writeAjaxError(w, http.StatusBadRequest, badRequest.Message)
+			return
+		}
+		slog.Error("Error listing media library", "orgID", orgID, "error", err)
+		writeAjaxError(w, http.StatusInternalServerError, "Error listing media library")
+		return
+	}
+	writeAjaxSuccess(w, assets)
+}
+
+// handleEditorMediaThumbnail serves the thumbnail of an image in the media
+// library.
+// GET /api/v1/h5p/editor/ajax?action=media-thumbnail&id={assetId}
+func (h *Handler) handleEditorMediaThumbnail(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	orgID, ok := h.editorOrganisation(w, r, userID)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, contentType, err := h.h5pService.GetMediaThumbnail(r.Context(), orgID, id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	cachePrivate.apply(w)
+	w.Write(data)
+}
+
+// handleEditorMediaReuse puts a media library asset into a file field
+// without uploading it again (unwrapped, like a file upload). Form or JSON:
+// id (the asset ID) and field (the semantics field JSON).
+// POST /api/v1/h5p/editor/ajax?action=media-reuse
+func (h *Handler) handleEditorMediaReuse(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	id, fieldJSON := r.FormValue("id"), r.FormValue("field")
+	if id == "" {
+		var body struct {
+			ID    string          `json:"id"`
+			Field json.RawMessage `json:"field"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err == nil {
+			id, fieldJSON = body.ID, string(body.Field)
+		}
+	}
+	assetID, err := uuid.Parse(id)
+	if err != nil {
+		writeUploadError(w, "Invalid media asset")
+		return
+	}
+
+	orgID, ok := h.editorOrganisation(w, r, userID)
+	if !ok {
+		return
+	}
+
+	result, err := h.h5pService.ReuseMediaAsset(r.Context(), orgID, userID, assetID, h5p.ParseUploadField(fieldJSON))
+	if err != nil {
+		if writeUploadRejection(w, err) {
+			return
+		}
+		var notFound pkg.NotFoundError
+		if errors.As(err, &notFound) {
+			writeUploadError(w, notFound.Message)
+			return
+		}
+		slog.Error("Error reusing media asset", "assetID", assetID, "error", err)
+		writeAjaxError(w, http.StatusInternalServerError, "Error reusing media asset")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleOrganisationMediaAsset removes an asset from the organisation's media
+// library. Content it was used in keeps its copy. Org admins only.
+// URL pattern: DELETE /api/v1/organisations/{orgId}/h5p/media/{assetId}
+func (h *Handler) handleOrganisationMediaAsset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	assetID, err := uuid.Parse(r.PathValue("assetId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid media asset ID"})
+		return
+	}
+
+	err = h.h5pService.DeleteMediaAsset(r.Context(), organisationID, assetID)
+	writeResponse(h.cfg, w, r, map[string]bool{"deleted": err == nil}, err)
+}
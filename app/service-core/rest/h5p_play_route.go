@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"github.com/google/uuid"
 
 	"app/pkg"
+	"service-core/domain/h5p"
 	"service-core/storage/query"
 )
 
@@ -89,7 +91,7 @@ func (h *Handler) getPlayContext(r *http.Request, contentIdStr string) (*playCon
 	}
 
 	// Verify org membership
-	_, err = store.CheckUserOrgMembership(ctx, query.CheckUserOrgMembershipParams{
+	_, err = h.store.CheckUserOrgMembership(ctx, query.CheckUserOrgMembershipParams{
 		UserID:         claims.ID,
 		OrganisationID: orgID,
 	})
@@ -227,7 +229,7 @@ func (h *Handler) handleH5PPlayH5PJson(w http.ResponseWriter, r *http.Request, c
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "private, max-age=60")
+	cacheListing.apply(w)
 	json.NewEncoder(w).Encode(h5pJson)
 }
 
@@ -254,7 +256,7 @@ func (h *Handler) handleH5PPlayContentJson(w http.ResponseWriter, r *http.Reques
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "private, max-age=60")
+	cacheListing.apply(w)
 	w.Write(contentData)
 }
 
@@ -294,7 +296,7 @@ func (h *Handler) handleH5PPlayContentFile(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Verify org membership
-	_, err = store.CheckUserOrgMembership(ctx, query.CheckUserOrgMembershipParams{
+	_, err = h.store.CheckUserOrgMembership(ctx, query.CheckUserOrgMembershipParams{
 		UserID:         claims.ID,
 		OrganisationID: contentRef.OrgID,
 	})
@@ -303,47 +305,18 @@ func (h *Handler) handleH5PPlayContentFile(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	data, contentType, err := h.h5pService.GetContentFile(ctx, contentID, contentRef.OrgID, filePath)
+	asset, err := h.h5pService.ResolveContentFile(ctx, contentID, contentRef.OrgID, filePath)
 	if err != nil {
 		slog.Debug("Play content file not found", "contentId", contentIdStr, "path", filePath, "error", err)
 		http.NotFound(w, r)
 		return
 	}
 
-	w.Header().Set("Content-Type", contentType)
-	w.Header().Set("Cache-Control", "private, max-age=3600")
-	w.Write(data)
+	h.serveAsset(w, r, asset, cachePrivate)
 }
 
 // --- Embed endpoint (Moodle-style server-rendered player) ---
 
-// H5P core CSS files (matching h5p-php-library H5PCore::$styles order)
-var h5pCoreCss = []string{
-	"/h5p/core/styles/h5p-fonts.css",
-	"/h5p/core/styles/h5p.css",
-	"/h5p/core/styles/h5p-confirmation-dialog.css",
-	"/h5p/core/styles/h5p-core-button.css",
-	"/h5p/core/styles/h5p-theme.css",
-	"/h5p/core/styles/h5p-theme-variables.css",
-	"/h5p/core/styles/h5p-tooltip.css",
-	"/h5p/core/styles/h5p-table.css",
-	"/h5p/core/styles/font-open-sans.css",
-}
-
-// H5P core JS files (matching Moodle's H5PCore::$scripts)
-var h5pCoreJs = []string{
-	"/h5p/core/js/jquery.js",
-	"/h5p/core/js/h5p.js",
-	"/h5p/core/js/h5p-event-dispatcher.js",
-	"/h5p/core/js/h5p-x-api-event.js",
-	"/h5p/core/js/h5p-x-api.js",
-	"/h5p/core/js/h5p-content-type.js",
-	"/h5p/core/js/h5p-confirmation-dialog.js",
-	"/h5p/core/js/h5p-action-bar.js",
-	"/h5p/core/js/request-queue.js",
-	"/h5p/core/js/h5p-tooltip.js",
-}
-
 // embedData holds all template variables for the embed HTML page
 type embedData struct {
 	Title           string
@@ -482,194 +455,91 @@ func (h *Handler) handleH5PPlayEmbed(w http.ResponseWriter, r *http.Request, con
 		writeResponse(h.cfg, w, r, nil, err)
 		return
 	}
+	// Grace-period locked content stays playable in the app but can't be embedded
+	if pc.content.LockedAt.Valid {
+		writeResponse(h.cfg, w, r, nil, pkg.LockedError{Reason: pc.content.LockReason.String})
+		return
+	}
 
-	deps := buildDependencyList(pc.deps, &pc.mainLib)
-
-	// Build library CSS/JS URL lists from dependency tree
-	var libCss, libJs []string
-	for _, dep := range deps {
-		libDir := fmt.Sprintf("%s-%d.%d.%d", dep.MachineName, dep.MajorVersion, dep.MinorVersion, dep.PatchVersion)
-		slog.Info("Embed: dependency",
-			"lib", dep.MachineName,
-			"version", fmt.Sprintf("%d.%d.%d", dep.MajorVersion, dep.MinorVersion, dep.PatchVersion),
-			"css_count", len(dep.PreloadedCss),
-			"js_count", len(dep.PreloadedJs),
-			"css_paths", func() []string {
-				paths := make([]string, len(dep.PreloadedCss))
-				for i, c := range dep.PreloadedCss {
-					paths[i] = c.Path
-				}
-				return paths
-			}())
-		for _, css := range dep.PreloadedCss {
-			libCss = append(libCss, fmt.Sprintf("/api/h5p/libraries/%s/%s", libDir, css.Path))
-		}
-		for _, js := range dep.PreloadedJs {
-			libJs = append(libJs, fmt.Sprintf("/api/h5p/libraries/%s/%s", libDir, js.Path))
-		}
+	config, err := h.playerConfig(r.Context(), pc.content.ID, pc.orgID, pc.userID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
 	}
 	slog.Info("Embed: resolved assets",
 		"contentId", contentIdStr,
-		"dep_count", len(deps),
-		"lib_css_count", len(libCss),
-		"lib_js_count", len(libJs),
-		"all_css_urls", libCss)
-
-	// Unwrap content.json params
-	contentData := pc.content.ContentJson
-	rawPreview := string(contentData)
-	if len(rawPreview) > 500 {
-		rawPreview = rawPreview[:500]
-	}
-	slog.Info("Embed: raw content_json from DB", "preview", rawPreview)
-
-	var wrapper map[string]json.RawMessage
-	if err := json.Unmarshal(contentData, &wrapper); err == nil {
-		slog.Info("Embed: content_json top-level keys", "keys", func() []string {
-			keys := make([]string, 0, len(wrapper))
-			for k := range wrapper {
-				keys = append(keys, k)
-			}
-			return keys
-		}())
-		if inner, ok := wrapper["params"]; ok {
-			contentData = inner
-			innerPreview := string(contentData)
-			if len(innerPreview) > 500 {
-				innerPreview = innerPreview[:500]
-			}
-			slog.Info("Embed: unwrapped params", "preview", innerPreview)
-		} else {
-			slog.Info("Embed: no 'params' key found, using raw content_json")
-		}
-	} else {
-		slog.Warn("Embed: content_json is not a JSON object", "error", err)
-	}
+		"dep_count", len(pc.deps)+1,
+		"lib_css_count", len(config.Styles),
+		"lib_js_count", len(config.Scripts))
 
-	libString := fmt.Sprintf("%s %d.%d", pc.mainLib.MachineName, pc.mainLib.MajorVersion, pc.mainLib.MinorVersion)
-
-	// Preload saved user state (for resume functionality)
-	store := query.New(h.storage.Conn)
-	savedStates, _ := store.GetContentUserStatesForContent(r.Context(), query.GetContentUserStatesForContentParams{
-		UserID:    pc.userID,
-		ContentID: pc.content.ID,
-	})
-
-	// Build contentUserData as nested object: {subContentId: {dataType: "json string"}}
-	// H5P core expects this exact structure (h5p.js getUserData line 2438-2439)
-	contentUserData := make(map[string]map[string]string)
-	for _, s := range savedStates {
-		if contentUserData[s.SubContentID] == nil {
-			contentUserData[s.SubContentID] = make(map[string]string)
-		}
-		contentUserData[s.SubContentID][s.DataType] = string(s.Data)
-	}
-
-	// Build cid-1 content object
-	cidContent := map[string]interface{}{
-		"library":     libString,
-		"jsonContent": string(contentData),
-		"fullScreen":  false,
-		"styles":      libCss,
-		"scripts":     libJs,
-		"displayOptions": map[string]interface{}{
-			"frame":     true,
-			"copyright": true,
-			"export":    false,
-			"embed":     false,
-			"icon":      false,
-		},
-		"contentUrl": fmt.Sprintf("/api/h5p/play/%s/content", pc.content.ID.String()),
-		"metadata":   map[string]interface{}{"title": pc.content.Title},
-	}
-
-	// Attach preloaded state if any exists
-	if len(contentUserData) > 0 {
-		cidContent["contentUserData"] = contentUserData
-	}
-
-	// Build H5PIntegration object (matching Moodle's structure)
-	integration := map[string]interface{}{
-		"baseUrl":      "",
-		"url":          "/api/h5p",
-		"urlLibraries": "/api/h5p/libraries",
-		"saveFreq":     10,
-		"ajax": map[string]interface{}{
-			// H5P core replaces :contentId with the data-content-id attribute (hardcoded "1"),
-			// not the actual UUID. Pre-bake the real UUID so the URL resolves correctly.
-			"contentUserData": fmt.Sprintf("/api/h5p/content-user-data/%s/:dataType/:subContentId", pc.content.ID.String()),
-		},
-		"user": map[string]interface{}{
-			"name": "Learner",
-			"mail": "",
-		},
-		"contents": map[string]interface{}{
-			"cid-1": cidContent,
-		},
-		"core": map[string]interface{}{
-			"styles":  h5pCoreCss,
-			"scripts": h5pCoreJs,
-		},
-		"l10n": map[string]interface{}{
-			"H5P": map[string]string{
-				"fullscreen":            "Fullscreen",
-				"disableFullscreen":     "Disable fullscreen",
-				"download":              "Download",
-				"copyrights":            "Rights of use",
-				"embed":                 "Embed",
-				"size":                  "Size",
-				"showAdvanced":          "Show advanced",
-				"hideAdvanced":          "Hide advanced",
-				"advancedHelp":          "Include this script on your website if you want dynamic sizing of the embedded content:",
-				"copyrightInformation":  "Rights of use",
-				"close":                 "Close",
-				"title":                 "Title",
-				"author":               "Author",
-				"year":                  "Year",
-				"source":               "Source",
-				"license":              "License",
-				"thumbnail":            "Thumbnail",
-				"noCopyrights":         "No copyright information available for this content.",
-				"reuse":                "Reuse",
-				"reuseContent":         "Reuse Content",
-				"reuseDescription":     "Reuse this content.",
-				"downloadDescription":  "Download this content as a H5P file.",
-				"copyrightsDescription": "View copyright information for this content.",
-				"embedDescription":     "View the embed code for this content.",
-				"h5pDescription":       "Visit H5P.org to check out more cool content.",
-				"contentChanged":       "This content has changed since you last used it.",
-				"startingOver":         "You'll be starting over.",
-				"confirmDialogHeader":  "Confirm action",
-				"confirmDialogBody":    "Please confirm that you wish to proceed. This action is not reversible.",
-				"cancelLabel":          "Cancel",
-				"confirmLabel":         "Confirm",
-				"licenseU":             "Undisclosed",
-			},
-		},
-	}
-
-	integrationJSON, err := json.Marshal(integration)
+	integrationJSON, err := json.Marshal(config.Integration)
 	if err != nil {
 		writeResponse(h.cfg, w, r, nil, pkg.InternalError{Message: "Failed to build H5PIntegration"})
 		return
 	}
 
 	data := embedData{
-		Title:           pc.content.Title,
-		CoreCss:         h5pCoreCss,
-		CoreJs:          h5pCoreJs,
-		LibraryCss:      libCss,
-		LibraryJs:       libJs,
+		Title:           config.Title,
+		CoreCss:         config.CoreStyles,
+		CoreJs:          config.CoreScripts,
+		LibraryCss:      config.Styles,
+		LibraryJs:       config.Scripts,
 		IntegrationJSON: template.JS(integrationJSON),
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Header().Set("Cache-Control", "private, no-cache")
+	cacheRevalidate.apply(w)
 	if err := embedTemplate.Execute(w, data); err != nil {
 		slog.Error("Failed to render embed template", "error", err)
 	}
 }
 
+// handleContentPlayerConfig returns the player config for embedding content
+// with the standalone H5P player, with the caller's saved state preloaded.
+// GET /api/v1/h5p/content/{id}/play?orgId=
+func (h *Handler) handleContentPlayerConfig(w http.ResponseWriter, r *http.Request, contentID, orgID, userID uuid.UUID) {
+	if _, err := h.h5pService.EditorOrganisation(r.Context(), userID, orgID.String()); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	config, err := h.playerConfig(r.Context(), contentID, orgID, userID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	writeResponse(h.cfg, w, r, config, nil)
+}
+
+// playerConfig builds the player config with the user's saved state attached,
+// so resuming works before H5P's async getUserData call returns.
+func (h *Handler) playerConfig(ctx context.Context, contentID, orgID, userID uuid.UUID) (*h5p.PlayerConfig, error) {
+	config, err := h.h5pService.GetPlayerConfig(ctx, contentID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	store := query.New(h.storage.Conn)
+	savedStates, _ := store.GetContentUserStatesForContent(ctx, query.GetContentUserStatesForContentParams{
+		UserID:    userID,
+		ContentID: contentID,
+	})
+
+	// Build contentUserData as nested object: {subContentId: {dataType: "json string"}}
+	// H5P core expects this exact structure (h5p.js getUserData line 2438-2439)
+	if len(savedStates) > 0 {
+		contentUserData := make(map[string]map[string]string)
+		for _, s := range savedStates {
+			if contentUserData[s.SubContentID] == nil {
+				contentUserData[s.SubContentID] = make(map[string]string)
+			}
+			contentUserData[s.SubContentID][s.DataType] = string(s.Data)
+		}
+		config.Integration.Contents[h5p.PlayerContentKey].ContentUserData = contentUserData
+	}
+	return config, nil
+}
+
 // --- Helpers ---
 
 // buildDependencyList converts DB library rows to play dependencies.
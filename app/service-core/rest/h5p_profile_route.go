@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/h5p"
+)
+
+// handleAdminH5PInstallProfiles lists (GET) or creates (POST) H5P install
+// profiles. Super admins only.
+// URL pattern: /api/v1/admin/h5p/install-profiles
+func (h *Handler) handleAdminH5PInstallProfiles(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profiles, err := h.h5pService.ListInstallProfiles(r.Context())
+		writeResponse(h.cfg, w, r, profiles, err)
+	case http.MethodPost:
+		var req h5p.InstallProfileInput
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		profile, err := h.h5pService.CreateInstallProfile(r.Context(), userID, req)
+		writeResponse(h.cfg, w, r, profile, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleAdminH5PInstallProfile replaces (PUT) or deletes (DELETE) an H5P
+// install profile. Super admins only.
+// URL pattern: /api/v1/admin/h5p/install-profiles/{profileId}
+func (h *Handler) handleAdminH5PInstallProfile(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	profileID, err := uuid.Parse(r.PathValue("profileId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid profileId"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req h5p.InstallProfileInput
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		profile, err := h.h5pService.UpdateInstallProfile(r.Context(), profileID, req)
+		writeResponse(h.cfg, w, r, profile, err)
+	case http.MethodDelete:
+		err := h.h5pService.DeleteInstallProfile(r.Context(), profileID)
+		writeResponse(h.cfg, w, r, nil, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleOrganisationLibraryProfile shows the install profiles and the
+// organisation's recent applications of them (GET), or applies one (POST).
+// Org admins only. A POST without a profileId applies the default profile,
+// which is how new organisations get their starter libraries.
+// URL pattern: /api/v1/organisations/{orgId}/library-profile
+func (h *Handler) handleOrganisationLibraryProfile(w http.ResponseWriter, r *http.Request) {
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	userID, err := h.requireOrgAdmin(r, organisationID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profiles, err := h.h5pService.ListInstallProfiles(r.Context())
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		applications, err := h.h5pService.ListProfileApplications(r.Context(), organisationID)
+		writeResponse(h.cfg, w, r, map[string]any{
+			"profiles":     profiles,
+			"applications": applications,
+		}, err)
+	case http.MethodPost:
+		var req struct {
+			ProfileID *uuid.UUID `json:"profileId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		application, err := h.h5pService.ApplyInstallProfile(r.Context(), organisationID, req.ProfileID,
+			uuid.NullUUID{UUID: userID, Valid: true})
+		writeResponse(h.cfg, w, r, application, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
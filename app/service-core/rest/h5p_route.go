@@ -6,12 +6,15 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
 )
 
-// handleH5PContentTypeCache returns the cached content type list from H5P Hub
+// handleH5PContentTypeCache returns the cached content type list from H5P Hub,
+// without the libraries disabled for the organisation (orgId, else the
+// user's default organisation)
 func (h *Handler) handleH5PContentTypeCache(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
@@ -24,13 +27,18 @@ func (h *Handler) handleH5PContentTypeCache(w http.ResponseWriter, r *http.Reque
 		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("unauthorized")})
 		return
 	}
-	_, err := h.authService.ValidateAccessToken(token)
+	claims, err := h.authService.ValidateAccessToken(token)
 	if err != nil {
 		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("unauthorized")})
 		return
 	}
+	orgID, err := h.h5pService.EditorOrganisation(r.Context(), claims.ID, r.URL.Query().Get("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
 
-	entries, err := h.h5pService.GetContentTypeCache(r.Context())
+	entries, err := h.h5pService.GetContentTypeCache(r.Context(), orgID)
 	if err != nil {
 		writeResponse(h.cfg, w, r, nil, err)
 		return
@@ -109,11 +117,16 @@ func (h *Handler) handleH5PLibraries(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleH5PLibraryRoute dispatches /api/v1/h5p/libraries/ by HTTP method:
+// GET  (…/semantics) → library semantics schema (unauthenticated)
 // GET  → serve extracted library assets (unauthenticated)
 // DELETE → delete a library (authenticated)
 func (h *Handler) handleH5PLibraryRoute(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
+		if strings.HasSuffix(r.URL.Path, "/semantics") {
+			h.handleH5PLibrarySemantics(w, r)
+			return
+		}
 		h.handleH5PLibraryAsset(w, r)
 	case http.MethodDelete:
 		h.handleH5PDeleteLibrary(w, r)
@@ -122,7 +135,8 @@ func (h *Handler) handleH5PLibraryRoute(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// handleH5PDeleteLibrary deletes a library by machine name (authenticated)
+// handleH5PDeleteLibrary deletes a library by machine name (authenticated).
+// With ?dryRun=true it only lists the stored files that would be removed.
 func (h *Handler) handleH5PDeleteLibrary(w http.ResponseWriter, r *http.Request) {
 	// Require authentication
 	token := extractAccessToken(r)
@@ -143,13 +157,70 @@ func (h *Handler) handleH5PDeleteLibrary(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.h5pService.DeleteLibrary(r.Context(), machineName)
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	deletion, err := h.h5pService.DeleteLibrary(r.Context(), machineName, dryRun)
+	writeResponse(h.cfg, w, r, deletion, err)
+}
+
+// handleH5PUpdateLibrary installs the Hub's newer version of a library next
+// to the installed one (super admin only).
+// POST /api/v1/h5p/libraries/{machineName}/update
+func (h *Handler) handleH5PUpdateLibrary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	update, err := h.h5pService.UpdateLibrary(r.Context(), r.PathValue("machineName"))
+	writeResponse(h.cfg, w, r, update, err)
+}
+
+// handleH5PUpdateAllLibraries updates every installed library the Hub has a
+// newer version of and summarises what changed (super admin only).
+// POST /api/v1/h5p/libraries/update-all
+func (h *Handler) handleH5PUpdateAllLibraries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	summary, err := h.h5pService.UpdateAllLibraries(r.Context())
+	writeResponse(h.cfg, w, r, summary, err)
+}
+
+// handleH5PLibrarySemantics serves a library's semantics.json, with editor
+// dependency overrides merged in, for external editors (unauthenticated).
+// GET /api/v1/h5p/libraries/{machineName}/{major}/{minor}/semantics
+func (h *Handler) handleH5PLibrarySemantics(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/h5p/libraries/"), "/")
+	if len(parts) != 4 || parts[0] == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.NotFoundError{Message: "Library not found"})
+		return
+	}
+	major, errMajor := strconv.Atoi(parts[1])
+	minor, errMinor := strconv.Atoi(parts[2])
+	if errMajor != nil || errMinor != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid library version"})
+		return
+	}
+
+	semantics, err := h.h5pService.GetLibrarySemantics(r.Context(), parts[0], major, minor)
 	if err != nil {
 		writeResponse(h.cfg, w, r, nil, err)
 		return
 	}
 
-	writeResponse(h.cfg, w, r, map[string]bool{"deleted": true}, nil)
+	w.Header().Set("Content-Type", "application/json")
+	cachePublic.apply(w)
+	w.Write(semantics)
 }
 
 // handleH5PLibraryAsset serves files from extracted libraries (unauthenticated).
@@ -161,16 +232,49 @@ func (h *Handler) handleH5PLibraryAsset(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	data, contentType, err := h.h5pService.GetLibraryAsset(r.Context(), assetPath)
+	asset, err := h.h5pService.ResolveLibraryAsset(r.Context(), assetPath)
 	if err != nil {
 		slog.Debug("Library asset not found", "path", assetPath, "error", err)
 		http.NotFound(w, r)
 		return
 	}
 
-	w.Header().Set("Content-Type", contentType)
-	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
-	w.Write(data)
+	h.serveAsset(w, r, asset, cacheImmutable)
+}
+
+// handleAdminH5PHubMetadataRefresh refetches the content hub metadata from the
+// H5P Hub and replaces the cached copy (super admin only).
+// POST /api/v1/admin/h5p/hub-metadata/refresh
+func (h *Handler) handleAdminH5PHubMetadataRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	metadata, err := h.h5pService.RefreshContentHubMetadata(r.Context())
+	writeResponse(h.cfg, w, r, metadata, err)
+}
+
+// handleAdminH5POrphanedFiles reconciles library storage against the
+// installed libraries. GET reports the files no library owns; POST queues
+// them for the storage sweeper (super admin only).
+// GET|POST /api/v1/admin/h5p/orphaned-files
+func (h *Handler) handleAdminH5POrphanedFiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	report, err := h.h5pService.ReconcileLibraryStorage(r.Context(), r.Method == http.MethodGet)
+	writeResponse(h.cfg, w, r, report, err)
 }
 
 // handleH5PHubContentTypesRoute dispatches /api/v1/h5p/hub/content-types/ by method:
@@ -240,7 +344,7 @@ func (h *Handler) handleH5PHubContentTypeDownload(w http.ResponseWriter, r *http
 	w.Write(data)
 }
 
-// handleH5POrgLibraryEnable enables a library for an organisation
+// handleH5POrgLibraryEnable enables a library for an organisation. Org admins only.
 func (h *Handler) handleH5POrgLibraryEnable(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
@@ -277,6 +381,10 @@ func (h *Handler) handleH5POrgLibraryEnable(w http.ResponseWriter, r *http.Reque
 		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid libraryId"})
 		return
 	}
+	if _, err := h.requireOrgAdmin(r, orgID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
 
 	err = h.h5pService.EnableLibraryForOrg(r.Context(), orgID, libraryID)
 	if err != nil {
@@ -287,7 +395,8 @@ func (h *Handler) handleH5POrgLibraryEnable(w http.ResponseWriter, r *http.Reque
 	writeResponse(h.cfg, w, r, map[string]bool{"enabled": true}, nil)
 }
 
-// handleH5POrgLibraryDisable disables a library for an organisation
+// handleH5POrgLibraryDisable disables a library for an organisation, hiding
+// it from the organisation's editor. Org admins only.
 func (h *Handler) handleH5POrgLibraryDisable(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
@@ -324,6 +433,10 @@ func (h *Handler) handleH5POrgLibraryDisable(w http.ResponseWriter, r *http.Requ
 		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid libraryId"})
 		return
 	}
+	if _, err := h.requireOrgAdmin(r, orgID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
 
 	err = h.h5pService.DisableLibraryForOrg(r.Context(), orgID, libraryID)
 	if err != nil {
@@ -333,3 +446,25 @@ func (h *Handler) handleH5POrgLibraryDisable(w http.ResponseWriter, r *http.Requ
 
 	writeResponse(h.cfg, w, r, map[string]bool{"disabled": true}, nil)
 }
+
+// handleOrganisationH5PLibraries lists the installed content types and
+// whether the organisation can use each one: available, disabled or
+// restricted. Org admins only.
+// URL pattern: GET /api/v1/organisations/{orgId}/h5p/libraries
+func (h *Handler) handleOrganisationH5PLibraries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	libraries, err := h.h5pService.ListOrgLibraryAvailability(r.Context(), organisationID)
+	writeResponse(h.cfg, w, r, libraries, err)
+}
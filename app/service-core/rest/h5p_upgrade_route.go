@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/h5p"
+)
+
+const contentUpgradesDefaultLimit = 50
+
+// handleOrganisationContentUpgrades lists the libraries the organisation has
+// content on outdated versions of, with the version it can be upgraded to.
+// URL pattern: GET /api/v1/organisations/{orgId}/h5p/content-upgrades
+func (h *Handler) handleOrganisationContentUpgrades(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	libraries, err := h.h5pService.ListOutdatedLibraries(r.Context(), organisationID)
+	writeResponse(h.cfg, w, r, libraries, err)
+}
+
+// handleOrganisationContentUpgrade returns the upgrade plan for one library
+// (GET): the outdated items with their parameters, and the target version's
+// semantics and upgrades.js for the browser to migrate them with. POST saves
+// a batch of migrated items and reports how each one went.
+// URL pattern: /api/v1/organisations/{orgId}/h5p/content-upgrades/{machineName}?limit=...
+func (h *Handler) handleOrganisationContentUpgrade(w http.ResponseWriter, r *http.Request) {
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	machineName := r.PathValue("machineName")
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		limit, err := parseIntParam(r, "limit", contentUpgradesDefaultLimit, 1, h5p.MaxUpgradePlanLimit)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		plan, err := h.h5pService.GetUpgradePlan(r.Context(), organisationID, machineName, limit)
+		writeResponse(h.cfg, w, r, plan, err)
+	case http.MethodPost:
+		var req struct {
+			Target h5p.HubVersion    `json:"target"`
+			Items  []h5p.UpgradeItem `json:"items"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		report, err := h.h5pService.UpgradeContent(r.Context(), organisationID, machineName, req.Target, req.Items)
+		writeResponse(h.cfg, w, r, report, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
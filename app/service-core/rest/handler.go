@@ -2,20 +2,62 @@ package rest
 
 import (
 	"app/pkg/auth"
+	"app/pkg/jobs"
 	"service-core/config"
+	"service-core/domain/accessreview"
+	"service-core/domain/analytics"
+	"service-core/domain/announcement"
 	"service-core/domain/billing"
+	"service-core/domain/certificate"
+	"service-core/domain/competitors"
+	"service-core/domain/extract"
+	"service-core/domain/file"
 	"service-core/domain/h5p"
+	"service-core/domain/locks"
 	"service-core/domain/login"
+	"service-core/domain/notification"
+	"service-core/domain/presence"
+	"service-core/domain/ranktracker"
+	"service-core/domain/ratelimit"
+	"service-core/domain/results"
+	"service-core/domain/retention"
+	"service-core/domain/search"
+	"service-core/domain/seo"
+	"service-core/domain/sweeper"
+	"service-core/domain/tenant"
+	"service-core/domain/webhook"
 	"service-core/storage"
+	"service-core/storage/query"
 )
 
 type Handler struct {
-	cfg            *config.Config
-	storage        *storage.Storage
-	authService    auth.AuthService
-	loginService   *login.Service
-	billingService *billing.Service
-	h5pService     *h5p.Service
+	cfg                 *config.Config
+	storage             *storage.Storage
+	store               query.Querier // memoizes membership and billing reads per request
+	authService         auth.AuthService
+	loginService        *login.Service
+	billingService      *billing.Service
+	h5pService          *h5p.Service
+	webhookService      *webhook.Service
+	lockService         *locks.Service
+	analyticsService    *analytics.Service
+	tenantService       *tenant.Service
+	searchService       *search.Service
+	rateLimitService    *ratelimit.Service
+	accessReviewService *accessreview.Service
+	announcementService *announcement.Service
+	presenceService     *presence.Service
+	seoService          *seo.Service
+	rankTrackerService  *ranktracker.Service
+	competitorService   *competitors.Service
+	retentionService    *retention.Service
+	resultsService      *results.Service
+	extractService      *extract.Service
+	notificationService *notification.Service
+	sweeperService      *sweeper.Service
+	certificateService  *certificate.Service
+	fileReplication     *file.ReplicatedProvider // nil when files aren't replicated
+	jobQueue            *jobs.Queue
 }
 
 func NewHandler(
@@ -25,13 +67,54 @@ func NewHandler(
 	loginService *login.Service,
 	billingService *billing.Service,
 	h5pService *h5p.Service,
+	webhookService *webhook.Service,
+	lockService *locks.Service,
+	analyticsService *analytics.Service,
+	tenantService *tenant.Service,
+	searchService *search.Service,
+	rateLimitService *ratelimit.Service,
+	accessReviewService *accessreview.Service,
+	announcementService *announcement.Service,
+	presenceService *presence.Service,
+	seoService *seo.Service,
+	rankTrackerService *ranktracker.Service,
+	competitorService *competitors.Service,
+	retentionService *retention.Service,
+	resultsService *results.Service,
+	extractService *extract.Service,
+	notificationService *notification.Service,
+	sweeperService *sweeper.Service,
+	certificateService *certificate.Service,
+	fileReplication *file.ReplicatedProvider,
+	jobQueue *jobs.Queue,
 ) *Handler {
 	return &Handler{
-		cfg:            config,
-		storage:        storage,
-		authService:    authService,
-		loginService:   loginService,
-		billingService: billingService,
-		h5pService:     h5pService,
+		cfg:                 config,
+		storage:             storage,
+		store:               storage.Queries(),
+		authService:         authService,
+		loginService:        loginService,
+		billingService:      billingService,
+		h5pService:          h5pService,
+		webhookService:      webhookService,
+		lockService:         lockService,
+		analyticsService:    analyticsService,
+		tenantService:       tenantService,
+		searchService:       searchService,
+		rateLimitService:    rateLimitService,
+		accessReviewService: accessReviewService,
+		announcementService: announcementService,
+		presenceService:     presenceService,
+		seoService:          seoService,
+		rankTrackerService:  rankTrackerService,
+		competitorService:   competitorService,
+		retentionService:    retentionService,
+		resultsService:      resultsService,
+		extractService:      extractService,
+		notificationService: notificationService,
+		sweeperService:      sweeperService,
+		certificateService:  certificateService,
+		fileReplication:     fileReplication,
+		jobQueue:            jobQueue,
 	}
 }
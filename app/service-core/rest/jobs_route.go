@@ -0,0 +1,99 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/jobs"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// handleAdminJobs lists background jobs, newest first, optionally with one
+// status and of one kind. Pass the last job ID of a page as before to get the
+// next one. Super admin only.
+// URL pattern: GET /api/v1/admin/jobs?status=dead&kind=seo.rank_check&limit=50&before=...
+func (h *Handler) handleAdminJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", jobs.StatusPending, jobs.StatusRunning, jobs.StatusCompleted, jobs.StatusDead:
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid status"})
+		return
+	}
+	limit, err := parseIntParam(r, "limit", 50, 1, 500)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	var before uuid.UUID
+	if v := r.URL.Query().Get("before"); v != "" {
+		if before, err = uuid.Parse(v); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid before"})
+			return
+		}
+	}
+	list, err := h.jobQueue.List(r.Context(), status, r.URL.Query().Get("kind"), before, limit)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.InternalError{Message: "Error listing jobs", Err: err})
+		return
+	}
+	writeResponse(h.cfg, w, r, list, nil)
+}
+
+// handleAdminJobRetry gives a dead job a fresh set of attempts. Super admin
+// only.
+// URL pattern: POST /api/v1/admin/jobs/{jobId}/retry
+func (h *Handler) handleAdminJobRetry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	jobID, err := uuid.Parse(r.PathValue("jobId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid jobId"})
+		return
+	}
+	err = h.jobQueue.Retry(r.Context(), jobID)
+	if errors.Is(err, jobs.ErrNotFound) {
+		writeResponse(h.cfg, w, r, nil, pkg.NotFoundError{Message: "Dead job not found", Err: err})
+		return
+	}
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.InternalError{Message: "Error retrying job", Err: err})
+		return
+	}
+	writeResponse(h.cfg, w, r, map[string]bool{"success": true}, nil)
+}
+
+// handleAdminPendingDeletions reports the backlog of stored files waiting to
+// be removed after their rows were purged: how many are pending, due and
+// stuck, per reason, with the oldest stuck ones. Super admin only.
+// URL pattern: GET /api/v1/admin/pending-deletions
+func (h *Handler) handleAdminPendingDeletions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireSuperAdmin(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	backlog, err := h.sweeperService.Backlog(r.Context())
+	writeResponse(h.cfg, w, r, backlog, err)
+}
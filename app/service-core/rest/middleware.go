@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"service-core/storage"
 	"strings"
 	"sync"
 	"time"
@@ -41,6 +42,14 @@ func AuthMiddleware(authService *auth.Service, requiredAccess int64) Middleware
 	}
 }
 
+// requestCacheMiddleware gives each request its own read cache, so repeated
+// membership and billing lookups while serving it hit the database once.
+func requestCacheMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(storage.WithRequestCache(r.Context())))
+	})
+}
+
 // RateLimitMiddleware implements basic rate limiting
 func RateLimitMiddleware(requestsPerMinute int) Middleware {
 	// Simple in-memory rate limiter
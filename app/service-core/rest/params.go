@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"app/pkg"
+)
+
+// pageLimits describes the bounds for a paginated list endpoint.
+type pageLimits struct {
+	DefaultLimit int
+	MaxLimit     int
+	MaxOffset    int
+}
+
+// Default bounds for list endpoints backed by our own tables.
+var defaultPageLimits = pageLimits{
+	DefaultLimit: 50,
+	MaxLimit:     200,
+	MaxOffset:    100000,
+}
+
+// Bounds for endpoints over third-party API data (DataForSEO backlinks,
+// keyword gaps etc.), where every row fetched costs money.
+var proxiedPageLimits = pageLimits{
+	DefaultLimit: 100,
+	MaxLimit:     1000,
+	MaxOffset:    20000,
+}
+
+// pageParams holds validated limit/offset values.
+type pageParams struct {
+	Limit  int32
+	Offset int32
+}
+
+// parsePageParams reads limit and offset from the query string, applying
+// defaults when absent and rejecting malformed or out-of-range values.
+func parsePageParams(r *http.Request, limits pageLimits) (pageParams, error) {
+	limit, err := parseIntParam(r, "limit", limits.DefaultLimit, 1, limits.MaxLimit)
+	if err != nil {
+		return pageParams{}, err
+	}
+	offset, err := parseIntParam(r, "offset", 0, 0, limits.MaxOffset)
+	if err != nil {
+		return pageParams{}, err
+	}
+	return pageParams{Limit: int32(limit), Offset: int32(offset)}, nil
+}
+
+// parseIntParam reads an integer query parameter, returning def when it is
+// absent and a BadRequestError when it is not a number or outside [min, max].
+func parseIntParam(r *http.Request, name string, def, min, max int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, pkg.BadRequestError{Message: fmt.Sprintf("%s must be an integer", name), Err: err}
+	}
+	if v < min || v > max {
+		return 0, pkg.BadRequestError{Message: fmt.Sprintf("%s must be between %d and %d", name, min, max)}
+	}
+	return v, nil
+}
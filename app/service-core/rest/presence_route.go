@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"app/pkg"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+
+	"service-core/domain/presence"
+)
+
+const (
+	// presenceIdleTimeout drops a connection that sends nothing, not even a
+	// heartbeat, for this long. Clients should heartbeat every 20 seconds.
+	presenceIdleTimeout  = 60 * time.Second
+	presenceWriteTimeout = 10 * time.Second
+	// presenceMaxMessageSize bounds client frames; every client message is tiny.
+	presenceMaxMessageSize = 1 << 10
+)
+
+// handleOrganisationPresence upgrades to a WebSocket reporting who is viewing
+// the organisation's content items. Clients send join, leave and heartbeat
+// messages; the server pushes the viewer list of a room whenever it changes.
+// Browsers authenticate with the access_token cookie.
+// URL pattern: /api/v1/organisations/{orgId}/presence
+func (h *Handler) handleOrganisationPresence(w http.ResponseWriter, r *http.Request) {
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	session, err := h.presenceService.Connect(r.Context(), claims.ID, organisationID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	// Also covers a failed handshake, where the handler never runs
+	defer session.Close()
+
+	server := websocket.Server{
+		Handshake: h.checkPresenceOrigin,
+		Handler: func(conn *websocket.Conn) {
+			h.servePresence(conn, session)
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// checkPresenceOrigin rejects cross-site WebSocket connections, which browsers
+// would otherwise make with the user's cookies. Non-browser clients send no Origin.
+func (h *Handler) checkPresenceOrigin(_ *websocket.Config, r *http.Request) error {
+	if origin := r.Header.Get("Origin"); origin != "" && origin != h.cfg.ClientURL {
+		return errors.New("origin not allowed")
+	}
+	return nil
+}
+
+// servePresence relays client messages to the session and session updates to
+// the client until either side goes away.
+func (h *Handler) servePresence(conn *websocket.Conn, session *presence.Session) {
+	defer conn.Close()
+	defer session.Close()
+	conn.MaxPayloadBytes = presenceMaxMessageSize
+
+	// Hijacked connections keep the server's deadlines, so every read and
+	// write sets its own
+	go func() {
+		for {
+			select {
+			case msg := <-session.Messages():
+				if err := sendPresence(conn, msg); err != nil {
+					conn.Close()
+					return
+				}
+			case <-session.Done():
+				// Unblocks the read loop when the session is dropped as too slow
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	ctx := conn.Request().Context()
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(presenceIdleTimeout)); err != nil {
+			return
+		}
+		var msg presence.ClientMessage
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			// Closed, idle past the heartbeat timeout, or not JSON
+			return
+		}
+
+		switch msg.Type {
+		case presence.TypeJoin:
+			if err := session.Join(ctx, msg.ContentID); err != nil {
+				if err := sendPresence(conn, presence.Message{Type: presence.TypeError, ContentID: msg.ContentID, Error: presenceErrorMessage(err)}); err != nil {
+					return
+				}
+			}
+		case presence.TypeLeave:
+			session.Leave(msg.ContentID)
+		case presence.TypeHeartbeat:
+			// The read deadline was already extended
+		default:
+			if err := sendPresence(conn, presence.Message{Type: presence.TypeError, Error: "Unknown message type"}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sendPresence writes one message to the client.
+func sendPresence(conn *websocket.Conn, msg presence.Message) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(presenceWriteTimeout)); err != nil {
+		return err
+	}
+	return websocket.JSON.Send(conn, msg)
+}
+
+// presenceErrorMessage is the client-facing text for a failed join.
+func presenceErrorMessage(err error) string {
+	var notFound pkg.NotFoundError
+	var badRequest pkg.BadRequestError
+	switch {
+	case errors.As(err, &notFound):
+		return notFound.Message
+	case errors.As(err, &badRequest):
+		return badRequest.Message
+	default:
+		slog.Error("Failed to join presence room", "error", err)
+		return "Could not join"
+	}
+}
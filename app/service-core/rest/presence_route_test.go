@@ -0,0 +1,166 @@
+package rest
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"service-core/config"
+	"service-core/domain/presence"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+var (
+	presenceTestOrgID     = uuid.MustParse("00000000-0000-0000-0000-0000000000a1")
+	presenceTestContentID = uuid.MustParse("00000000-0000-0000-0000-0000000000c1")
+)
+
+// fakePresenceStore has editorTestUserID as the only member of
+// presenceTestOrgID, which owns presenceTestContentID.
+type fakePresenceStore struct{}
+
+func (fakePresenceStore) GetOrgMemberIdentity(_ context.Context, arg query.GetOrgMemberIdentityParams) (query.GetOrgMemberIdentityRow, error) {
+	if arg.UserID != editorTestUserID || arg.OrganisationID != presenceTestOrgID {
+		return query.GetOrgMemberIdentityRow{}, sql.ErrNoRows
+	}
+	return query.GetOrgMemberIdentityRow{DisplayName: "Ada", Email: "ada@example.com"}, nil
+}
+
+func (fakePresenceStore) GetH5PContentOrgId(_ context.Context, id uuid.UUID) (query.GetH5PContentOrgIdRow, error) {
+	if id != presenceTestContentID {
+		return query.GetH5PContentOrgIdRow{}, sql.ErrNoRows
+	}
+	return query.GetH5PContentOrgIdRow{ID: id, OrgID: presenceTestOrgID}, nil
+}
+
+func newPresenceTestServer(t *testing.T) (*httptest.Server, *Handler) {
+	t.Helper()
+	h := &Handler{
+		cfg:             config.LoadTestConfig(),
+		authService:     fakeAuth{},
+		presenceService: presence.NewService(fakePresenceStore{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/organisations/{orgId}/presence", h.handleOrganisationPresence)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, h
+}
+
+func TestOrganisationPresence_Rejected(t *testing.T) {
+	srv, _ := newPresenceTestServer(t)
+
+	tests := []struct {
+		name   string
+		orgID  string
+		token  string
+		status int
+	}{
+		{name: "invalid organisation", orgID: "not-a-uuid", token: editorTestToken, status: http.StatusBadRequest},
+		{name: "missing token", orgID: presenceTestOrgID.String(), status: http.StatusUnauthorized},
+		{name: "invalid token", orgID: presenceTestOrgID.String(), token: "forged", status: http.StatusUnauthorized},
+		{name: "not a member", orgID: uuid.NewString(), token: editorTestToken, status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/organisations/"+tt.orgID+"/presence", nil)
+			require.NoError(t, err)
+			if tt.token != "" {
+				req.AddCookie(&http.Cookie{Name: "access_token", Value: tt.token})
+			}
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			res.Body.Close()
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
+
+func TestCheckPresenceOrigin(t *testing.T) {
+	h := &Handler{cfg: config.LoadTestConfig()}
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{name: "client app", origin: h.cfg.ClientURL, allowed: true},
+		{name: "no origin", allowed: true},
+		{name: "another site", origin: "https://evil.example.com"},
+		{name: "client host on another port", origin: h.cfg.ClientURL + "0"},
+		{name: "null origin", origin: "null"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/organisations/"+presenceTestOrgID.String()+"/presence", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			err := h.checkPresenceOrigin(nil, r)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestOrganisationPresence_Connection(t *testing.T) {
+	srv, h := newPresenceTestServer(t)
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/organisations/" + presenceTestOrgID.String() + "/presence"
+
+	dial := func(origin string) (*websocket.Conn, error) {
+		t.Helper()
+		wsCfg, err := websocket.NewConfig(wsURL, origin)
+		require.NoError(t, err)
+		wsCfg.Header.Set("Cookie", "access_token="+editorTestToken)
+		return websocket.DialConfig(wsCfg)
+	}
+	receive := func(conn *websocket.Conn) presence.Message {
+		t.Helper()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		var msg presence.Message
+		require.NoError(t, websocket.JSON.Receive(conn, &msg))
+		return msg
+	}
+
+	t.Run("cross-site origin", func(t *testing.T) {
+		_, err := dial("https://evil.example.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("client app", func(t *testing.T) {
+		conn, err := dial(h.cfg.ClientURL)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, websocket.JSON.Send(conn, presence.ClientMessage{Type: presence.TypeJoin, ContentID: presenceTestContentID}))
+		msg := receive(conn)
+		assert.Equal(t, presence.TypePresence, msg.Type)
+		assert.Equal(t, presenceTestContentID, msg.ContentID)
+		require.Len(t, msg.Viewers, 1)
+		assert.Equal(t, editorTestUserID, msg.Viewers[0].UserID)
+		assert.Equal(t, "Ada", msg.Viewers[0].Name)
+
+		// A failed join is reported without closing the connection
+		missing := uuid.New()
+		require.NoError(t, websocket.JSON.Send(conn, presence.ClientMessage{Type: presence.TypeJoin, ContentID: missing}))
+		assert.Equal(t, presence.Message{Type: presence.TypeError, ContentID: missing, Error: "Content not found"}, receive(conn))
+
+		require.NoError(t, websocket.JSON.Send(conn, presence.ClientMessage{Type: "shout"}))
+		assert.Equal(t, presence.Message{Type: presence.TypeError, Error: "Unknown message type"}, receive(conn))
+
+		require.NoError(t, websocket.JSON.Send(conn, presence.ClientMessage{Type: presence.TypeLeave, ContentID: presenceTestContentID}))
+		assert.Eventually(t, func() bool { return len(h.presenceService.Viewers(presenceTestContentID)) == 0 }, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,231 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/ranktracker"
+)
+
+// handleRankTrackers lists an organisation's rank trackers with their latest
+// runs (GET) or registers a domain and its keywords for daily tracking
+// (POST). Runs are started by the rank tracker task.
+// URL pattern: /api/v1/rank-tracker/trackers?organisationId=...
+func (h *Handler) handleRankTrackers(w http.ResponseWriter, r *http.Request) {
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+			return
+		}
+		trackers, err := h.rankTrackerService.ListTrackers(r.Context(), claims.ID, organisationID)
+		writeResponse(h.cfg, w, r, trackers, err)
+	case http.MethodPost:
+		var req ranktracker.TrackerRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 256<<10)).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		if req.OrganisationID == uuid.Nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+			return
+		}
+		tracker, err := h.rankTrackerService.CreateTracker(r.Context(), claims.ID, req)
+		writeResponse(h.cfg, w, r, tracker, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleRankTracker returns a tracker with its keywords and their latest
+// positions (GET) or deletes it with its history (DELETE).
+// URL pattern: /api/v1/rank-tracker/trackers/{trackerId}?organisationId=...
+func (h *Handler) handleRankTracker(w http.ResponseWriter, r *http.Request) {
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	trackerID, err := uuid.Parse(r.PathValue("trackerId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid trackerId"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		tracker, err := h.rankTrackerService.GetTracker(r.Context(), claims.ID, organisationID, trackerID)
+		writeResponse(h.cfg, w, r, tracker, err)
+	case http.MethodDelete:
+		if err := h.rankTrackerService.DeleteTracker(r.Context(), claims.ID, organisationID, trackerID); err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		writeResponse(h.cfg, w, r, map[string]bool{"deleted": true}, nil)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleRankTrackerKeywords adds keywords to a tracker (POST) or stops
+// tracking them (DELETE), returning the updated tracker.
+// URL pattern: /api/v1/rank-tracker/trackers/{trackerId}/keywords
+func (h *Handler) handleRankTrackerKeywords(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	trackerID, err := uuid.Parse(r.PathValue("trackerId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid trackerId"})
+		return
+	}
+	var req ranktracker.KeywordsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 256<<10)).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	if req.OrganisationID == uuid.Nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+		return
+	}
+
+	var tracker *ranktracker.TrackerDetail
+	if r.Method == http.MethodPost {
+		tracker, err = h.rankTrackerService.AddKeywords(r.Context(), claims.ID, trackerID, req)
+	} else {
+		tracker, err = h.rankTrackerService.RemoveKeywords(r.Context(), claims.ID, trackerID, req)
+	}
+	writeResponse(h.cfg, w, r, tracker, err)
+}
+
+// handleRankTrackerHistory returns one keyword's positions for the tracked
+// domain and each competitor over the last days.
+// URL pattern: GET /api/v1/rank-tracker/trackers/{trackerId}/history?organisationId=...&keyword=...&days=...
+func (h *Handler) handleRankTrackerHistory(w http.ResponseWriter, r *http.Request) {
+	userID, organisationID, trackerID, ok := h.rankTrackerReportRequest(w, r)
+	if !ok {
+		return
+	}
+	days, err := parseIntParam(r, "days", 30, 1, ranktracker.MaxReportDays)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	history, err := h.rankTrackerService.History(r.Context(), userID, organisationID, trackerID, r.URL.Query().Get("keyword"), days)
+	writeResponse(h.cfg, w, r, history, err)
+}
+
+// handleRankTrackerMovers returns the keywords whose positions improved and
+// declined most between the latest run and the run days before it.
+// URL pattern: GET /api/v1/rank-tracker/trackers/{trackerId}/movers?organisationId=...&days=...&limit=...
+func (h *Handler) handleRankTrackerMovers(w http.ResponseWriter, r *http.Request) {
+	userID, organisationID, trackerID, ok := h.rankTrackerReportRequest(w, r)
+	if !ok {
+		return
+	}
+	days, err := parseIntParam(r, "days", 7, 1, ranktracker.MaxReportDays)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	limit, err := parseIntParam(r, "limit", 10, 1, ranktracker.MaxMovers)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	movers, err := h.rankTrackerService.Movers(r.Context(), userID, organisationID, trackerID, days, limit)
+	writeResponse(h.cfg, w, r, movers, err)
+}
+
+// handleRankTrackerShareOfVoice returns the share of voice of the tracked
+// domain and each competitor in every run over the last days.
+// URL pattern: GET /api/v1/rank-tracker/trackers/{trackerId}/share-of-voice?organisationId=...&days=...
+func (h *Handler) handleRankTrackerShareOfVoice(w http.ResponseWriter, r *http.Request) {
+	userID, organisationID, trackerID, ok := h.rankTrackerReportRequest(w, r)
+	if !ok {
+		return
+	}
+	days, err := parseIntParam(r, "days", 30, 1, ranktracker.MaxReportDays)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	sov, err := h.rankTrackerService.ShareOfVoice(r.Context(), userID, organisationID, trackerID, days)
+	writeResponse(h.cfg, w, r, sov, err)
+}
+
+// rankTrackerReportRequest checks the method and access token of a report
+// request and reads its tracker and organisation, writing the error response
+// and returning false when any is missing or invalid.
+func (h *Handler) rankTrackerReportRequest(w http.ResponseWriter, r *http.Request) (userID, organisationID, trackerID uuid.UUID, ok bool) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	trackerID, err = uuid.Parse(r.PathValue("trackerId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid trackerId"})
+		return
+	}
+	organisationID, err = uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	return claims.ID, organisationID, trackerID, true
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"app/pkg"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"service-core/domain/ratelimit"
+)
+
+// rateLimitMiddleware holds authenticated API requests to their organisation's
+// rate plan and reports where the caller stands in X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset (Unix seconds) headers.
+// Unauthenticated requests pass through; the handler rejects them if needed.
+func (h *Handler) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := extractAccessToken(r)
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		claims, err := h.authService.ValidateAccessToken(token)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		plan, err := h.rateLimitService.PlanForUser(r.Context(), claims.ID)
+		if err != nil {
+			// Fail open: a database hiccup shouldn't take the API down with it
+			slog.Error("Failed to load rate plan", "userID", claims.ID, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		status, ok := h.rateLimitService.Allow(plan)
+		setRateLimitHeaders(w, status)
+		if !ok {
+			writeResponse(h.cfg, w, r, nil, pkg.RateLimitedError{
+				Limit:      status.Limit,
+				RetryAfter: time.Until(status.Reset),
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, status ratelimit.Status) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(status.Reset.Unix(), 10))
+}
+
+// handleLimits returns the caller's rate plan and remaining allowance, so SDK
+// clients can throttle themselves instead of running into 429s.
+// URL pattern: /api/v1/limits
+func (h *Handler) handleLimits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	plan, err := h.rateLimitService.PlanForUser(r.Context(), claims.ID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.InternalError{Message: "Failed to load rate plan", Err: err})
+		return
+	}
+	writeResponse(h.cfg, w, r, h.rateLimitService.Limits(plan), nil)
+}
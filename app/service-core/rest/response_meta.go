@@ -0,0 +1,28 @@
+package rest
+
+import (
+	"context"
+	"log/slog"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// responseMeta tells clients how to present a response's numbers and dates.
+// Timestamps in the data stay RFC 3339; clients render them in Timezone.
+type responseMeta struct {
+	Locale   string `json:"locale"`   // BCP 47 tag, e.g. "en-AU"
+	Timezone string `json:"timezone"` // IANA name, e.g. "Australia/Sydney"
+}
+
+// organisationMeta loads an organisation's locale settings for the meta block.
+// A failed lookup is logged and returns nil, so the response is still served.
+func (h *Handler) organisationMeta(ctx context.Context, organisationID uuid.UUID) *responseMeta {
+	settings, err := query.New(h.storage.Conn).GetOrganisationLocale(ctx, organisationID)
+	if err != nil {
+		slog.Warn("Failed to load organisation locale", "organisation_id", organisationID, "error", err)
+		return nil
+	}
+	return &responseMeta{Locale: settings.Locale, Timezone: settings.Timezone}
+}
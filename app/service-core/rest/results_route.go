@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"app/pkg"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"service-core/config"
+)
+
+// handleOrganisationContentResults shows the gradebook for a piece of
+// content: each learner's attempts, completion and best score. With
+// format=csv every learner is exported instead of a page. Org admins only.
+// URL pattern: GET /api/v1/organisations/{orgId}/results/content/{contentId}?limit=&offset=&format=
+func (h *Handler) handleOrganisationContentResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	contentID, err := uuid.Parse(r.PathValue("contentId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid contentId"})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		data, filename, err := h.resultsService.ExportContentResultsCSV(r.Context(), organisationID, contentID)
+		writeCSVResponse(h.cfg, w, r, data, filename, err)
+		return
+	}
+	page, err := parsePageParams(r, defaultPageLimits)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	results, err := h.resultsService.ContentResults(r.Context(), organisationID, contentID, page.Limit, page.Offset)
+	writeResponse(h.cfg, w, r, results, err)
+}
+
+// handleOrganisationCourseGradebook shows the gradebook for a course's
+// cohort: its enrolled learners' results on each of its H5P items. With
+// format=csv the whole cohort is exported instead of a page. Org admins only.
+// URL pattern: GET /api/v1/organisations/{orgId}/results/courses/{courseId}?limit=&offset=&format=
+func (h *Handler) handleOrganisationCourseGradebook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	courseID, err := uuid.Parse(r.PathValue("courseId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid courseId"})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		data, filename, err := h.resultsService.ExportCourseGradebookCSV(r.Context(), organisationID, courseID)
+		writeCSVResponse(h.cfg, w, r, data, filename, err)
+		return
+	}
+	page, err := parsePageParams(r, defaultPageLimits)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	gradebook, err := h.resultsService.CourseGradebook(r.Context(), organisationID, courseID, page.Limit, page.Offset)
+	writeResponse(h.cfg, w, r, gradebook, err)
+}
+
+// writeCSVResponse sends an export as a CSV download, or the error as JSON
+func writeCSVResponse(cfg *config.Config, w http.ResponseWriter, r *http.Request, data []byte, filename string, err error) {
+	if err != nil {
+		writeResponse(cfg, w, r, nil, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	w.Write(data)
+}
@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/retention"
+)
+
+// handleOrganisationRetentionPolicy shows (GET) or replaces (PUT) an
+// organisation's retention policy. Org admins only.
+// URL pattern: /api/v1/organisations/{orgId}/retention-policy
+func (h *Handler) handleOrganisationRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	userID, err := h.requireOrgAdmin(r, organisationID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		policy, err := h.retentionService.Get(r.Context(), organisationID)
+		writeResponse(h.cfg, w, r, policy, err)
+	case http.MethodPut:
+		var req retention.PolicyUpdate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		change, err := h.retentionService.Update(r.Context(), organisationID, userID, req)
+		writeResponse(h.cfg, w, r, change, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleOrganisationRetentionPreview counts what proposed retention rules
+// would archive or remove, without saving them.
+// URL pattern: /api/v1/organisations/{orgId}/retention-policy/preview
+func (h *Handler) handleOrganisationRetentionPreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req retention.PolicyUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	impact, err := h.retentionService.Preview(r.Context(), organisationID, req)
+	writeResponse(h.cfg, w, r, impact, err)
+}
@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"app/pkg"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/search"
+	"service-core/storage/query"
+)
+
+const (
+	searchMinQueryLength = 2
+	searchMaxQueryLength = 100
+	searchDefaultLimit   = 5
+	searchMaxLimit       = 20
+)
+
+// handleSearch searches an organisation's content, courses, folders and
+// keyword lists for the command palette. Members only see published items.
+// URL pattern: /api/v1/search?organisationId=...&q=...&limit=...
+func (h *Handler) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	term := strings.TrimSpace(r.URL.Query().Get("q"))
+	if n := utf8.RuneCountInString(term); n < searchMinQueryLength || n > searchMaxQueryLength {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "q must be between 2 and 100 characters"})
+		return
+	}
+
+	limit, err := parseIntParam(r, "limit", searchDefaultLimit, 1, searchMaxLimit)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	role, err := h.store.GetOrgMembershipRole(r.Context(), query.GetOrgMembershipRoleParams{
+		UserID:         claims.ID,
+		OrganisationID: organisationID,
+	})
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("not a member of this organisation")})
+		return
+	}
+
+	results, err := h.searchService.Search(r.Context(), organisationID, term, search.OptionsForRole(role, int32(limit)))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.InternalError{Message: "Failed to search", Err: err})
+		return
+	}
+	writeResponse(h.cfg, w, r, results, nil)
+}
@@ -0,0 +1,795 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/seo"
+)
+
+// handleSEORankCheck starts a rank check: where a domain ranks on Google for
+// up to 100 keywords, outside of any tracked campaign. The lookups are queued
+// with DataForSEO, so the check is returned pending; poll
+// handleSEORankCheckResult for the results.
+// URL pattern: POST /api/v1/seo/rank-check
+func (h *Handler) handleSEORankCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	var req seo.RankCheckRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	if req.OrganisationID == uuid.Nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+		return
+	}
+
+	check, err := h.seoService.StartRankCheck(r.Context(), claims.ID, req)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	writeResponse(h.cfg, w, r, check, nil)
+}
+
+// handleSEORankCheckResult returns a rank check, with its results once every
+// lookup is back.
+// URL pattern: GET /api/v1/seo/rank-check/{checkId}?organisationId=...
+func (h *Handler) handleSEORankCheckResult(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	checkID, err := uuid.Parse(r.PathValue("checkId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid checkId"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	check, err := h.seoService.GetRankCheck(r.Context(), claims.ID, organisationID, checkID)
+	writeResponse(h.cfg, w, r, check, err)
+}
+
+// handleSEOPageExperience starts a page experience audit: PageSpeed's Core
+// Web Vitals and the on-page checks of up to 50 pages, scored against
+// Google's thresholds. The runs take up to a minute a page, so the pages are
+// returned pending; poll handleSEOPageExperienceResult for the scores. Each
+// page is saved as it completes, so an audit that stops part way can be
+// resumed with handleSEOPageExperienceResume.
+// URL pattern: POST /api/v1/seo/page-experience
+func (h *Handler) handleSEOPageExperience(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	var req seo.PageExperienceRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	if req.OrganisationID == uuid.Nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+		return
+	}
+
+	audit, err := h.seoService.StartPageExperienceAudit(r.Context(), claims.ID, req)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	writeResponse(h.cfg, w, r, audit, nil)
+}
+
+// handleSEOPageExperienceResult returns the pages of a page experience audit.
+// URL pattern: GET /api/v1/seo/page-experience/{auditId}?organisationId=...
+func (h *Handler) handleSEOPageExperienceResult(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	auditID, err := uuid.Parse(r.PathValue("auditId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid auditId"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	audit, err := h.seoService.GetPageExperienceAudit(r.Context(), claims.ID, organisationID, auditID)
+	writeResponse(h.cfg, w, r, audit, err)
+}
+
+// handleSEOPageExperienceResume re-runs the pages of an audit that failed or
+// were interrupted; completed pages are kept.
+// URL pattern: POST /api/v1/seo/page-experience/{auditId}/resume?organisationId=...
+func (h *Handler) handleSEOPageExperienceResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	auditID, err := uuid.Parse(r.PathValue("auditId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid auditId"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	audit, err := h.seoService.ResumePageExperienceAudit(r.Context(), claims.ID, organisationID, auditID)
+	writeResponse(h.cfg, w, r, audit, err)
+}
+
+// handleSEOPageExperienceTrend returns the completed page experience
+// assessments of one page over time, oldest first.
+// URL pattern: GET /api/v1/seo/page-experience/trend?organisationId=...&url=...&strategy=...&limit=...
+func (h *Handler) handleSEOPageExperienceTrend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	q := r.URL.Query()
+	organisationID, err := uuid.Parse(q.Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	strategy := q.Get("strategy")
+	if strategy != "" && strategy != seo.StrategyMobile && strategy != seo.StrategyDesktop {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "strategy must be mobile or desktop"})
+		return
+	}
+	limit := seo.MaxPageExperienceTrend
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > seo.MaxPageExperienceTrend {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "limit must be between 1 and 100"})
+			return
+		}
+	}
+
+	trend, err := h.seoService.PageExperienceTrend(r.Context(), claims.ID, organisationID, q.Get("url"), strategy, limit)
+	writeResponse(h.cfg, w, r, trend, err)
+}
+
+// handleAuditRecheck checks the on-page issues of some pages of an audit
+// again and returns them, marked re-verified.
+// URL pattern: POST /api/v1/audits/{id}/recheck
+func (h *Handler) handleAuditRecheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	auditID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid audit id"})
+		return
+	}
+	var req seo.RecheckRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16<<10)).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	if req.OrganisationID == uuid.Nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+		return
+	}
+
+	rechecks, err := h.seoService.RecheckAuditPages(r.Context(), claims.ID, auditID, req)
+	writeResponse(h.cfg, w, r, rechecks, err)
+}
+
+// handleAuditRawArtifacts streams the raw DataForSEO results behind a section
+// of an audit as a JSON array, one per page. With signed=true it returns a
+// short-lived URL to them instead, which works without an access token.
+// URL pattern: GET /api/v1/audits/{id}/raw/{section}?organisationId=...&signed=
+// Signed: GET /api/v1/audits/{id}/raw/{section}?organisationId=...&expires=...&signature=...
+func (h *Handler) handleAuditRawArtifacts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	auditID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid audit id"})
+		return
+	}
+	section := r.PathValue("section")
+	organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	var artifacts *seo.RawArtifacts
+	if signature := r.URL.Query().Get("signature"); signature != "" {
+		artifacts, err = h.seoService.GetSignedRawArtifacts(r.Context(), organisationID, auditID, section, r.URL.Query().Get("expires"), signature)
+	} else {
+		token := extractAccessToken(r)
+		if token == "" {
+			writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+			return
+		}
+		claims, authErr := h.authService.ValidateAccessToken(token)
+		if authErr != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+			return
+		}
+		if r.URL.Query().Get("signed") == "true" {
+			signed, err := h.seoService.SignRawArtifactsURL(r.Context(), claims.ID, organisationID, auditID, section)
+			writeResponse(h.cfg, w, r, signed, err)
+			return
+		}
+		artifacts, err = h.seoService.GetRawArtifacts(r.Context(), claims.ID, organisationID, auditID, section)
+	}
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"audit-%s-%s.json\"", auditID, section))
+	if err := artifacts.Stream(r.Context(), w); err != nil {
+		// The response has started, so all that's left is to cut it short
+		slog.Error("Failed to stream raw audit results", "auditID", auditID, "section", section, "error", err)
+	}
+}
+
+// handleSEOKeywordLists lists an organisation's keyword lists (GET) or saves
+// a new one (POST). Search volumes aren't looked up here: a new list is due
+// straight away and gets them on the next keyword volume refresh task.
+// URL pattern: /api/v1/seo/keyword-lists?organisationId=...
+func (h *Handler) handleSEOKeywordLists(w http.ResponseWriter, r *http.Request) {
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+			return
+		}
+		lists, err := h.seoService.ListKeywordLists(r.Context(), claims.ID, organisationID)
+		writeResponse(h.cfg, w, r, lists, err)
+	case http.MethodPost:
+		var req seo.KeywordListRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 256<<10)).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		if req.OrganisationID == uuid.Nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+			return
+		}
+		list, err := h.seoService.CreateKeywordList(r.Context(), claims.ID, req)
+		writeResponse(h.cfg, w, r, list, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleSEOKeywordList returns a keyword list with its search volumes and
+// refresh history.
+// URL pattern: GET /api/v1/seo/keyword-lists/{listId}?organisationId=...
+func (h *Handler) handleSEOKeywordList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	listID, err := uuid.Parse(r.PathValue("listId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid listId"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	list, err := h.seoService.GetKeywordList(r.Context(), claims.ID, organisationID, listID)
+	writeResponse(h.cfg, w, r, list, err)
+}
+
+// handleSEOBacklinkAnchors returns the anchor text section of a domain's
+// backlink report: anchors bucketed as branded, exact match, partial match,
+// naked URL or generic, with over-optimisation warnings. The brand, target
+// keywords, generic anchors and thresholds come from the request.
+// URL pattern: POST /api/v1/seo/backlinks/anchors
+func (h *Handler) handleSEOBacklinkAnchors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	var req seo.AnchorTextRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	if req.OrganisationID == uuid.Nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+		return
+	}
+
+	report, err := h.seoService.AnalyzeAnchorText(r.Context(), claims.ID, req)
+	writeResponse(h.cfg, w, r, report, err)
+}
+
+// handleSEOSchedules lists an organisation's scheduled domains with their
+// latest runs (GET) or registers a domain, replacing its pages and keywords
+// if it's already registered (POST). Runs are started and finalised by the
+// SEO schedules task.
+// URL pattern: /api/v1/seo/schedules?organisationId=...
+func (h *Handler) handleSEOSchedules(w http.ResponseWriter, r *http.Request) {
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+			return
+		}
+		schedules, err := h.seoService.ListSchedules(r.Context(), claims.ID, organisationID)
+		writeResponse(h.cfg, w, r, schedules, err)
+	case http.MethodPost:
+		var req seo.ScheduleRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		if req.OrganisationID == uuid.Nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+			return
+		}
+		schedule, err := h.seoService.SaveSchedule(r.Context(), claims.ID, req)
+		writeResponse(h.cfg, w, r, schedule, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleSEOSchedule unregisters a scheduled domain.
+// URL pattern: DELETE /api/v1/seo/schedules/{scheduleId}?organisationId=...
+func (h *Handler) handleSEOSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	scheduleID, err := uuid.Parse(r.PathValue("scheduleId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid scheduleId"})
+		return
+	}
+	organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	if err := h.seoService.DeleteSchedule(r.Context(), claims.ID, organisationID, scheduleID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	writeResponse(h.cfg, w, r, map[string]bool{"deleted": true}, nil)
+}
+
+// handleSEOScheduleRuns returns a scheduled domain's run history, newest
+// first, with each run's summary and its changes since the run before.
+// URL pattern: GET /api/v1/seo/schedules/{scheduleId}/runs?organisationId=...&limit=...
+func (h *Handler) handleSEOScheduleRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	scheduleID, err := uuid.Parse(r.PathValue("scheduleId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid scheduleId"})
+		return
+	}
+	q := r.URL.Query()
+	organisationID, err := uuid.Parse(q.Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	limit := seo.MaxScheduleRuns
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > seo.MaxScheduleRuns {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "limit must be between 1 and 90"})
+			return
+		}
+	}
+
+	runs, err := h.seoService.ListScheduleRuns(r.Context(), claims.ID, organisationID, scheduleID, limit)
+	writeResponse(h.cfg, w, r, runs, err)
+}
+
+// handleSEOProspects lists a site's backlink prospects, optionally filtered
+// by status a page at a time (GET), or adds prospect domains by hand (POST).
+// URL pattern: /api/v1/seo/prospects?organisationId=...&target=...&status=...&limit=...&offset=...
+func (h *Handler) handleSEOProspects(w http.ResponseWriter, r *http.Request) {
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		organisationID, err := uuid.Parse(q.Get("organisationId"))
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+			return
+		}
+		page, err := parsePageParams(r, proxiedPageLimits)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		prospects, err := h.seoService.ListProspects(r.Context(), claims.ID, organisationID, q.Get("target"), q.Get("status"), page.Limit, page.Offset)
+		writeResponse(h.cfg, w, r, prospects, err)
+	case http.MethodPost:
+		var req seo.ProspectRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 256<<10)).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		if req.OrganisationID == uuid.Nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+			return
+		}
+		result, err := h.seoService.AddProspects(r.Context(), claims.ID, req)
+		writeResponse(h.cfg, w, r, result, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleSEOProspectGap adds the domains linking to competitors but not to
+// the target as prospects.
+// URL pattern: POST /api/v1/seo/prospects/gap
+func (h *Handler) handleSEOProspectGap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	var req seo.ProspectGapRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	if req.OrganisationID == uuid.Nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+		return
+	}
+
+	result, err := h.seoService.ImportGapProspects(r.Context(), claims.ID, req)
+	writeResponse(h.cfg, w, r, result, err)
+}
+
+// handleSEOProspectEnrich looks up backlink summaries for a batch of the
+// target's prospects that don't have one yet.
+// URL pattern: POST /api/v1/seo/prospects/enrich
+func (h *Handler) handleSEOProspectEnrich(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	var req seo.ProspectEnrichRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	if req.OrganisationID == uuid.Nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+		return
+	}
+
+	result, err := h.seoService.EnrichProspects(r.Context(), claims.ID, req)
+	writeResponse(h.cfg, w, r, result, err)
+}
+
+// handleSEOProspectsCSV exports a site's prospects as CSV (GET) or imports
+// prospects from an uploaded CSV file, sent as the multipart field "file"
+// (POST).
+// URL pattern: /api/v1/seo/prospects/csv?organisationId=...&target=...&status=...
+func (h *Handler) handleSEOProspectsCSV(w http.ResponseWriter, r *http.Request) {
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	q := r.URL.Query()
+	organisationID, err := uuid.Parse(q.Get("organisationId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		data, filename, err := h.seoService.ExportProspectsCSV(r.Context(), claims.ID, organisationID, q.Get("target"), q.Get("status"))
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+		w.Write(data)
+	case http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, 2<<20)
+		if err := r.ParseMultipartForm(2 << 20); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "The CSV file must be under 2MB"})
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "No file uploaded"})
+			return
+		}
+		defer file.Close()
+
+		result, err := h.seoService.ImportProspectsCSV(r.Context(), claims.ID, organisationID, q.Get("target"), file)
+		writeResponse(h.cfg, w, r, result, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleSEOProspect moves a prospect along the pipeline or edits its
+// contact and notes (PUT), or stops tracking it (DELETE).
+// URL pattern: /api/v1/seo/prospects/{prospectId}?organisationId=...
+func (h *Handler) handleSEOProspect(w http.ResponseWriter, r *http.Request) {
+	token := extractAccessToken(r)
+	if token == "" {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("missing access token")})
+		return
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")})
+		return
+	}
+
+	prospectID, err := uuid.Parse(r.PathValue("prospectId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid prospectId"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req seo.ProspectUpdate
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		if req.OrganisationID == uuid.Nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "organisationId is required"})
+			return
+		}
+		prospect, err := h.seoService.UpdateProspect(r.Context(), claims.ID, prospectID, req)
+		writeResponse(h.cfg, w, r, prospect, err)
+	case http.MethodDelete:
+		organisationID, err := uuid.Parse(r.URL.Query().Get("organisationId"))
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+			return
+		}
+		if err := h.seoService.DeleteProspect(r.Context(), claims.ID, organisationID, prospectID); err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		writeResponse(h.cfg, w, r, map[string]bool{"deleted": true}, nil)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
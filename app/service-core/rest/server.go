@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"math"
 	"net/http"
 	"service-core/config"
+	"strconv"
 )
 
 func Run(apiHandler *Handler) *http.Server {
@@ -31,18 +33,117 @@ func Run(apiHandler *Handler) *http.Server {
 
 	// Billing (organisation subscriptions)
 	mux.HandleFunc("/api/v1/billing/info", apiHandler.handleBillingInfo)
+	mux.HandleFunc("/api/v1/billing/plans", apiHandler.handleBillingPlans)
 	mux.HandleFunc("/api/v1/billing/checkout", apiHandler.handleBillingCheckout)
 	mux.HandleFunc("/api/v1/billing/portal", apiHandler.handleBillingPortal)
 	mux.HandleFunc("/api/v1/billing/upgrade", apiHandler.handleBillingUpgrade)
 	mux.HandleFunc("/api/v1/billing/session-status", apiHandler.handleBillingSessionStatus)
 	mux.HandleFunc("/api/v1/billing/sync-session", apiHandler.handleBillingSyncSession)
 	mux.HandleFunc("/api/v1/billing/webhook", apiHandler.handleBillingWebhook)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/billing-contact", apiHandler.handleOrganisationBillingContact)
+
+	// Organisation webhooks (domain event relay, owner/admin only)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/webhooks", apiHandler.handleOrganisationWebhooks)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/webhooks/{webhookId}", apiHandler.handleOrganisationWebhook)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/webhooks/{webhookId}/deliveries", apiHandler.handleOrganisationWebhookDeliveries)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver", apiHandler.handleOrganisationWebhookRedeliver)
+
+	// Billing adjustments (refunds & credits, super admin only)
+	mux.HandleFunc("/api/v1/admin/organisations/{orgId}/refunds", apiHandler.handleAdminOrganisationRefunds)
+	mux.HandleFunc("/api/v1/admin/organisations/{orgId}/credits", apiHandler.handleAdminOrganisationCredits)
+
+	// Organisation export/import (self-hosted migrations, super admin only)
+	mux.HandleFunc("/api/v1/admin/organisations/{orgId}/export", apiHandler.handleAdminOrganisationExport)
+	mux.HandleFunc("/api/v1/admin/organisations/import", apiHandler.handleAdminOrganisationImport)
+
+	// Organisation deletion (owner deletes and restores, super admin confirms teardown)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/deletion", apiHandler.handleOrganisationDeletion)
+	mux.HandleFunc("/api/v1/admin/organisations/{orgId}/teardown", apiHandler.handleAdminOrganisationTeardown)
+	mux.HandleFunc("/api/v1/admin/organisation-deletions", apiHandler.handleAdminOrganisationDeletions)
+
+	// Retention policies (org admins)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/retention-policy", apiHandler.handleOrganisationRetentionPolicy)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/retention-policy/preview", apiHandler.handleOrganisationRetentionPreview)
+
+	// Access reviews (SOC 2 evidence, super admin only)
+	mux.HandleFunc("/api/v1/admin/organisations/{orgId}/access-reviews", apiHandler.handleAdminOrganisationAccessReviews)
+	mux.HandleFunc("/api/v1/admin/access-reviews", apiHandler.handleAdminAccessReviews)
+	mux.HandleFunc("/api/v1/admin/access-reviews/{reviewId}/download", apiHandler.handleAdminAccessReviewDownload)
+
+	// Announcements (changelog and notices; publishing is super admin only)
+	mux.HandleFunc("/api/v1/announcements", apiHandler.handleAnnouncements)
+	mux.HandleFunc("/api/v1/announcements/{announcementId}/read", apiHandler.handleAnnouncementRead)
+	mux.HandleFunc("/api/v1/admin/announcements", apiHandler.handleAdminAnnouncements)
+	mux.HandleFunc("/api/v1/admin/announcements/{announcementId}", apiHandler.handleAdminAnnouncement)
+
+	// Analytics anonymization (GDPR user deletion, super admin only)
+	mux.HandleFunc("/api/v1/admin/users/{userId}/anonymize", apiHandler.handleAdminUserAnonymize)
+
+	// Global search (command palette)
+	mux.HandleFunc("/api/v1/search", apiHandler.handleSearch)
+
+	// SEO rank checks (ad-hoc SERP positions, org admins start them)
+	mux.HandleFunc("/api/v1/seo/rank-check", apiHandler.handleSEORankCheck)
+	mux.HandleFunc("/api/v1/seo/rank-check/{checkId}", apiHandler.handleSEORankCheckResult)
+
+	// SEO page experience audits (scored Core Web Vitals and on-page checks)
+	mux.HandleFunc("/api/v1/seo/page-experience", apiHandler.handleSEOPageExperience)
+	mux.HandleFunc("/api/v1/seo/page-experience/trend", apiHandler.handleSEOPageExperienceTrend)
+	mux.HandleFunc("/api/v1/seo/page-experience/{auditId}", apiHandler.handleSEOPageExperienceResult)
+	mux.HandleFunc("/api/v1/seo/page-experience/{auditId}/resume", apiHandler.handleSEOPageExperienceResume)
+
+	// SEO keyword lists (search volumes refreshed monthly by a task)
+	mux.HandleFunc("/api/v1/seo/keyword-lists", apiHandler.handleSEOKeywordLists)
+	mux.HandleFunc("/api/v1/seo/keyword-lists/{listId}", apiHandler.handleSEOKeywordList)
+
+	// SEO backlink reports (anchor text profile and over-optimisation warnings)
+	mux.HandleFunc("/api/v1/seo/backlinks/anchors", apiHandler.handleSEOBacklinkAnchors)
+
+	// SEO backlink prospects (outreach pipeline, competitor gaps, CSV import/export)
+	mux.HandleFunc("/api/v1/seo/prospects", apiHandler.handleSEOProspects)
+	mux.HandleFunc("/api/v1/seo/prospects/gap", apiHandler.handleSEOProspectGap)
+	mux.HandleFunc("/api/v1/seo/prospects/enrich", apiHandler.handleSEOProspectEnrich)
+	mux.HandleFunc("/api/v1/seo/prospects/csv", apiHandler.handleSEOProspectsCSV)
+	mux.HandleFunc("/api/v1/seo/prospects/{prospectId}", apiHandler.handleSEOProspect)
+
+	// SEO schedules (recurring audits and rank checks, run by a task)
+	mux.HandleFunc("/api/v1/seo/schedules", apiHandler.handleSEOSchedules)
+	mux.HandleFunc("/api/v1/seo/schedules/{scheduleId}", apiHandler.handleSEOSchedule)
+	mux.HandleFunc("/api/v1/seo/schedules/{scheduleId}/runs", apiHandler.handleSEOScheduleRuns)
+
+	// Rank tracker (daily keyword positions, run by a task)
+	mux.HandleFunc("/api/v1/rank-tracker/trackers", apiHandler.handleRankTrackers)
+	mux.HandleFunc("/api/v1/rank-tracker/trackers/{trackerId}", apiHandler.handleRankTracker)
+	mux.HandleFunc("/api/v1/rank-tracker/trackers/{trackerId}/keywords", apiHandler.handleRankTrackerKeywords)
+	mux.HandleFunc("/api/v1/rank-tracker/trackers/{trackerId}/history", apiHandler.handleRankTrackerHistory)
+	mux.HandleFunc("/api/v1/rank-tracker/trackers/{trackerId}/movers", apiHandler.handleRankTrackerMovers)
+	mux.HandleFunc("/api/v1/rank-tracker/trackers/{trackerId}/share-of-voice", apiHandler.handleRankTrackerShareOfVoice)
+
+	// Competitor monitoring (pinned competitors, refreshed weekly by a task)
+	mux.HandleFunc("/api/v1/competitors", apiHandler.handleCompetitors)
+	mux.HandleFunc("/api/v1/competitors/suggestions", apiHandler.handleCompetitorSuggestions)
+	mux.HandleFunc("/api/v1/competitors/alerts", apiHandler.handleCompetitorAlerts)
+	mux.HandleFunc("/api/v1/competitors/compare", apiHandler.handleCompetitorComparison)
+	mux.HandleFunc("/api/v1/competitors/{pinId}", apiHandler.handleCompetitor)
+	mux.HandleFunc("/api/v1/competitors/{pinId}/gaps", apiHandler.handleCompetitorGaps)
+
+	// Re-verify the on-page issues of a few audited pages after a fix
+	mux.HandleFunc("/api/v1/audits/{id}/recheck", apiHandler.handleAuditRecheck)
+	mux.HandleFunc("/api/v1/audits/{id}/raw/{section}", apiHandler.handleAuditRawArtifacts)
+
+	// Rate plan and remaining allowance for the caller
+	mux.HandleFunc("/api/v1/limits", apiHandler.handleLimits)
+
+	// Web page to markdown extraction (cached by URL)
+	mux.HandleFunc("/api/v1/tools/extract", apiHandler.handleToolsExtract)
 
 	// H5P Library Management
 	mux.HandleFunc("/api/v1/h5p/content-type-cache", apiHandler.handleH5PContentTypeCache)
 	mux.HandleFunc("/api/v1/h5p/install", apiHandler.handleH5PInstall)
 	mux.HandleFunc("/api/v1/h5p/libraries", apiHandler.handleH5PLibraries)
 	mux.HandleFunc("/api/v1/h5p/libraries/", apiHandler.handleH5PLibraryRoute)
+	mux.HandleFunc("/api/v1/h5p/libraries/update-all", apiHandler.handleH5PUpdateAllLibraries)
+	mux.HandleFunc("/api/v1/h5p/libraries/{machineName}/update", apiHandler.handleH5PUpdateLibrary)
 	mux.HandleFunc("/api/v1/h5p/org-libraries/enable", apiHandler.handleH5POrgLibraryEnable)
 	mux.HandleFunc("/api/v1/h5p/org-libraries/disable", apiHandler.handleH5POrgLibraryDisable)
 
@@ -53,11 +154,27 @@ func Run(apiHandler *Handler) *http.Server {
 	// H5P Editor AJAX (authenticated)
 	mux.HandleFunc("/api/v1/h5p/editor/ajax", apiHandler.handleEditorAjax)
 	mux.HandleFunc("/api/v1/h5p/editor/params/", apiHandler.handleEditorGetParams)
+	mux.HandleFunc("/api/v1/h5p/editor/bundle", apiHandler.handleEditorBundle)
+	mux.HandleFunc("/api/v1/h5p/editor/bundle/reports", apiHandler.handleEditorBundleReport)
 
 	// H5P Content CRUD (authenticated)
 	mux.HandleFunc("/api/v1/h5p/content", apiHandler.handleContentRoute)
 	mux.HandleFunc("/api/v1/h5p/content/", apiHandler.handleContentCRUDRoute)
 
+	// H5P content upgrades (org admins move content to newer library versions)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/h5p/content-upgrades", apiHandler.handleOrganisationContentUpgrades)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/h5p/content-upgrades/{machineName}", apiHandler.handleOrganisationContentUpgrade)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/h5p/media/{assetId}", apiHandler.handleOrganisationMediaAsset)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/h5p/libraries", apiHandler.handleOrganisationH5PLibraries)
+
+	// Content presence (WebSocket: who is viewing which content item)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/presence", apiHandler.handleOrganisationPresence)
+
+	// Notifications (per-member preferences and in-app feed; digests emailed by a task)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/notification-preferences", apiHandler.handleOrganisationNotificationPreferences)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/notifications", apiHandler.handleOrganisationNotifications)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/notifications/read", apiHandler.handleOrganisationNotificationsRead)
+
 	// H5P Content + Temp File Serving (authenticated)
 	mux.HandleFunc("/api/v1/h5p/content-files/", apiHandler.handleContentFile)
 	mux.HandleFunc("/api/v1/h5p/temp-files/", apiHandler.handleTempFile)
@@ -67,15 +184,65 @@ func Run(apiHandler *Handler) *http.Server {
 
 	// H5P xAPI statement capture (authenticated)
 	mux.HandleFunc("/api/v1/h5p/xapi", apiHandler.handleXapiStatement)
+	mux.HandleFunc("/api/v1/xapi/statements", apiHandler.handleXapiStatements)
+	mux.HandleFunc("/api/v1/xapi/content/{contentId}/summary", apiHandler.handleXapiContentSummary)
+	mux.HandleFunc("/api/v1/xapi/learners/{userId}/summary", apiHandler.handleXapiLearnerSummary)
+
+	// Results and gradebooks (org admins)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/results/content/{contentId}", apiHandler.handleOrganisationContentResults)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/results/courses/{courseId}", apiHandler.handleOrganisationCourseGradebook)
 
 	// H5P Content User State (save/resume progress)
 	mux.HandleFunc("/api/v1/h5p/content-user-data/", apiHandler.handleContentUserData)
 
 	// H5P Maintenance (admin)
 	mux.HandleFunc("/api/v1/h5p/backfill-metadata", apiHandler.handleH5PBackfillMetadata)
+	mux.HandleFunc("/api/v1/admin/h5p/hub-metadata/refresh", apiHandler.handleAdminH5PHubMetadataRefresh)
+
+	// H5P install profiles (admin defines them; org admins apply them)
+	mux.HandleFunc("/api/v1/admin/h5p/orphaned-files", apiHandler.handleAdminH5POrphanedFiles)
+	mux.HandleFunc("/api/v1/admin/h5p/install-profiles", apiHandler.handleAdminH5PInstallProfiles)
+	mux.HandleFunc("/api/v1/admin/h5p/install-profiles/{profileId}", apiHandler.handleAdminH5PInstallProfile)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/library-profile", apiHandler.handleOrganisationLibraryProfile)
+
+	// H5P editor bundle rollout (admin sets each variant's share of organisations)
+	mux.HandleFunc("/api/v1/admin/h5p/bundle-variants", apiHandler.handleAdminH5PBundleVariants)
+	mux.HandleFunc("/api/v1/admin/h5p/bundle-variants/{name}", apiHandler.handleAdminH5PBundleVariant)
+
+	// Recorded external API calls (admin, only while DEBUG_RECORD_CALLS is set)
+	mux.HandleFunc("/api/v1/admin/debug/calls", apiHandler.handleAdminDebugCalls)
+	mux.HandleFunc("/api/v1/admin/debug/calls/{callId}", apiHandler.handleAdminDebugCall)
+
+	// Background jobs (admin: inspect and retry dead jobs)
+	mux.HandleFunc("/api/v1/admin/jobs", apiHandler.handleAdminJobs)
+	mux.HandleFunc("/api/v1/admin/jobs/{jobId}/retry", apiHandler.handleAdminJobRetry)
+
+	// Stored files queued for removal after their rows were purged (admin: backlog)
+	mux.HandleFunc("/api/v1/admin/pending-deletions", apiHandler.handleAdminPendingDeletions)
+
+	// Course completion certificates (org admins lay them out, learners download,
+	// anyone verifies by ID)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/certificate-template", apiHandler.handleOrganisationCertificateTemplate)
+	mux.HandleFunc("/api/v1/organisations/{orgId}/certificate-template/background", apiHandler.handleOrganisationCertificateBackground)
+	mux.HandleFunc("/api/v1/certificates", apiHandler.handleCertificates)
+	mux.HandleFunc("/api/v1/certificates/{id}/download", apiHandler.handleCertificateDownload)
+	mux.HandleFunc("/verify/{id}", apiHandler.handleVerifyCertificate)
 
 	// Cron jobs
 	mux.HandleFunc("/tasks/delete-tokens", apiHandler.handleTasksDeleteTokens)
+	mux.HandleFunc("/tasks/card-expiry-reminders", apiHandler.handleTasksCardExpiryReminders)
+	mux.HandleFunc("/tasks/access-reviews", apiHandler.handleTasksAccessReviews)
+	mux.HandleFunc("/tasks/keyword-volume-refresh", apiHandler.handleTasksKeywordVolumeRefresh)
+	mux.HandleFunc("/tasks/seo-schedules", apiHandler.handleTasksSeoSchedules)
+	mux.HandleFunc("/tasks/rank-tracker", apiHandler.handleTasksRankTracker)
+	mux.HandleFunc("/tasks/competitor-refresh", apiHandler.handleTasksCompetitorRefresh)
+	mux.HandleFunc("/tasks/organisation-teardown", apiHandler.handleTasksOrganisationTeardown)
+	mux.HandleFunc("/tasks/webhook-deliveries", apiHandler.handleTasksWebhookDeliveries)
+	mux.HandleFunc("/tasks/retention", apiHandler.handleTasksRetention)
+	mux.HandleFunc("/tasks/notification-digest", apiHandler.handleTasksNotificationDigest)
+	mux.HandleFunc("/tasks/pending-deletions", apiHandler.handleTasksPendingDeletions)
+	mux.HandleFunc("/tasks/library-orphans", apiHandler.handleTasksLibraryOrphans)
+	mux.HandleFunc("/tasks/file-replication", apiHandler.handleTasksFileReplication)
 
 	// Health checks
 	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
@@ -110,8 +277,12 @@ func Run(apiHandler *Handler) *http.Server {
 		}
 	})
 
-	// Apply CORS middleware globally
-	corsHandler := corsMiddleware(cfg, mux)
+	// API v2 shares the v1 handlers with a v2 response envelope
+	mux.HandleFunc("/api/v2/", v2Router(mux))
+
+	// Apply CORS, the request read cache, the default cache policy and rate
+	// limiting globally
+	corsHandler := corsMiddleware(cfg, requestCacheMiddleware(cacheMiddleware(apiHandler.rateLimitMiddleware(deprecationMiddleware(mux)))))
 	handler := loggingMiddleware(corsHandler)
 
 	server := &http.Server{Addr: ":" + cfg.HTTPPort, Handler: handler, ReadHeaderTimeout: cfg.HTTPTimeout, WriteTimeout: cfg.HTTPTimeout}
@@ -133,6 +304,7 @@ func corsMiddleware(cfg *config.Config, next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Api-Key, X-User-Id")
+		w.Header().Set("Access-Control-Expose-Headers", "X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After, X-Export-Warnings, Content-Disposition")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		// Handle preflight requests
@@ -158,13 +330,26 @@ func extractAccessToken(r *http.Request) string {
 }
 
 func writeResponse(cfg *config.Config, w http.ResponseWriter, r *http.Request, data any, err error) {
+	writeResponseWithMeta(cfg, w, r, data, nil, err)
+}
+
+// writeResponseWithMeta is writeResponse with a meta block in the success
+// envelope, telling clients how to format the data. Errors carry no meta.
+func writeResponseWithMeta(cfg *config.Config, w http.ResponseWriter, r *http.Request, data any, meta *responseMeta, err error) {
 	w.Header().Set("Access-Control-Allow-Origin", cfg.ClientURL)
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Api-Key, X-User-Id")
 
+	serializer := serializerFor(r)
 	if err != nil {
+		// A handler may have chosen a policy for the resource it failed to serve
+		cacheNoStore.apply(w)
 		var unauthorizedError pkg.UnauthorizedError
+		var forbiddenError pkg.ForbiddenError
+		var lockedError pkg.LockedError
+		var quotaExceededError pkg.QuotaExceededError
+		var rateLimitedError pkg.RateLimitedError
 		var internalError pkg.InternalError
 		var badRequestError pkg.BadRequestError
 		var notFoundError pkg.NotFoundError
@@ -177,64 +362,65 @@ func writeResponse(cfg *config.Config, w http.ResponseWriter, r *http.Request, d
 				// Return JSON error for API requests
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(map[string]interface{}{
-					"success": false,
-					"message": "Unauthorized",
-					"code":    401,
-				})
+				json.NewEncoder(w).Encode(serializer.failure(401, "Unauthorized"))
 			} else {
 				http.Redirect(w, r, returnURL+"/login?error=unauthorized", http.StatusSeeOther)
 			}
 			return
+		case errors.As(err, &forbiddenError):
+			slog.Error("Forbidden", "error", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(serializer.failure(403, "Forbidden"))
+			return
+		case errors.As(err, &lockedError):
+			slog.Error("Locked", "error", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusLocked)
+			json.NewEncoder(w).Encode(serializer.failure(423, lockedError.Reason))
+			return
+		case errors.As(err, &quotaExceededError):
+			slog.Error("Quota exceeded", "error", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(serializer.failure(413, quotaExceededError.Message))
+			return
+		case errors.As(err, &rateLimitedError):
+			slog.Warn("Rate limited", "error", err)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitedError.RetryAfter.Seconds()))))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(serializer.failure(429, "Rate limit exceeded"))
+			return
 		case errors.As(err, &internalError):
 			slog.Error("Internal error", "error", internalError)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": internalError.Message,
-				"code":    500,
-			})
+			json.NewEncoder(w).Encode(serializer.failure(500, internalError.Message))
 			return
 		case errors.As(err, &badRequestError):
 			slog.Error("Bad request error", "error", badRequestError)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": badRequestError.Message,
-				"code":    400,
-			})
+			json.NewEncoder(w).Encode(serializer.failure(400, badRequestError.Message))
 			return
 		case errors.As(err, &notFoundError):
 			slog.Error("Not found error", "error", notFoundError)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": notFoundError.Message,
-				"code":    404,
-			})
+			json.NewEncoder(w).Encode(serializer.failure(404, notFoundError.Message))
 			return
 		case errors.As(err, &validationErrors):
 			slog.Error("Validation error", "error", validationErrors)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusUnprocessableEntity)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": validationErrors.Error(),
-				"code":    422,
-			})
+			json.NewEncoder(w).Encode(serializer.failure(422, validationErrors.Error()))
 			return
 		default:
 			slog.Error("Error", "error", err)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": "An internal error occurred",
-				"code":    500,
-			})
+			json.NewEncoder(w).Encode(serializer.failure(500, "An internal error occurred"))
 			return
 		}
 	}
@@ -243,15 +429,10 @@ func writeResponse(cfg *config.Config, w http.ResponseWriter, r *http.Request, d
 		return
 	}
 
-	// Wrap successful responses in Safe<T> format expected by frontend
+	// Wrap successful responses in the version's envelope (Safe<T> for v1)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	safeResponse := map[string]interface{}{
-		"success": true,
-		"data":    data,
-		"message": "Operation completed successfully",
-	}
-	err = json.NewEncoder(w).Encode(safeResponse)
+	err = json.NewEncoder(w).Encode(serializer.success(data, meta))
 	if err != nil {
 		slog.Error("Error writing response", "error", err)
 		http.Error(w, "Error writing response", http.StatusInternalServerError)
@@ -1,11 +1,29 @@
 package rest
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"service-core/storage/query"
+	"time"
 )
 
+// runExclusiveTask runs a scheduled task under a job lock so it runs on one
+// replica only. When another replica holds the lock the task is skipped and
+// the scheduler still gets a 200, so it doesn't retry.
+func (h *Handler) runExclusiveTask(w http.ResponseWriter, r *http.Request, name string, ttl time.Duration, task func(ctx context.Context) error) {
+	ran, err := h.lockService.RunExclusive(r.Context(), "task:"+name, ttl, task)
+	if err != nil {
+		slog.Error("Error running task", "task", name, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !ran {
+		slog.Info("Skipping task, already running on another instance", "task", name)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 func (h *Handler) handleTasksDeleteTokens(w http.ResponseWriter, r *http.Request) {
 	slog.Info("Running Task: Delete Tokens")
 	apiKey := r.Header.Get("X-Api-Key")
@@ -15,11 +33,261 @@ func (h *Handler) handleTasksDeleteTokens(w http.ResponseWriter, r *http.Request
 		return
 	}
 	store := query.New(h.storage.Conn)
-	err := store.DeleteTokens(r.Context())
-	if err != nil {
-		slog.Error("Error deleting tokens", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	h.runExclusiveTask(w, r, "delete-tokens", 5*time.Minute, store.DeleteTokens)
+}
+
+func (h *Handler) handleTasksCardExpiryReminders(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Card Expiry Reminders")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	h.runExclusiveTask(w, r, "card-expiry-reminders", 15*time.Minute, func(ctx context.Context) error {
+		sent, err := h.billingService.SendCardExpiryReminders(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Card expiry reminders sent", "count", sent)
+		return nil
+	})
+}
+
+func (h *Handler) handleTasksAccessReviews(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Access Reviews")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "access-reviews", 30*time.Minute, func(ctx context.Context) error {
+		generated, err := h.accessReviewService.ProcessPending(ctx)
+		if err != nil {
+			return err
+		}
+		purged, err := h.accessReviewService.PurgeExpired(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Access reviews processed", "generated", generated, "purged", purged)
+		return nil
+	})
+}
+
+func (h *Handler) handleTasksKeywordVolumeRefresh(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Keyword Volume Refresh")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "keyword-volume-refresh", 30*time.Minute, func(ctx context.Context) error {
+		summary, err := h.seoService.RefreshDueKeywordLists(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Keyword volumes refreshed", "lists", summary.Lists, "keywords", summary.Keywords, "batches", summary.Batches, "failed", summary.Failed, "cost", summary.Cost)
+		return nil
+	})
+}
+
+func (h *Handler) handleTasksSeoSchedules(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: SEO Schedules")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "seo-schedules", 10*time.Minute, func(ctx context.Context) error {
+		summary, err := h.seoService.RunSchedules(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("SEO schedules run", "finalised", summary.Finalised, "started", summary.Started, "deferred", summary.Deferred)
+		return nil
+	})
+}
+
+func (h *Handler) handleTasksRankTracker(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Rank Tracker")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "rank-tracker", 10*time.Minute, func(ctx context.Context) error {
+		summary, err := h.rankTrackerService.RunDueTrackers(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Rank trackers run", "interrupted", summary.Interrupted, "started", summary.Started, "skipped", summary.Skipped)
+		return nil
+	})
+}
+
+func (h *Handler) handleTasksCompetitorRefresh(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Competitor Refresh")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "competitor-refresh", 10*time.Minute, func(ctx context.Context) error {
+		summary, err := h.competitorService.RefreshDue(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Competitors refreshed", "refreshed", summary.Refreshed, "failed", summary.Failed, "alerts", summary.Alerts)
+		return nil
+	})
+}
+
+func (h *Handler) handleTasksOrganisationTeardown(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Organisation Teardown")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "organisation-teardown", 30*time.Minute, func(ctx context.Context) error {
+		completed, err := h.tenantService.RunDueTeardowns(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Organisations torn down", "completed", completed)
+		return nil
+	})
+}
+
+func (h *Handler) handleTasksWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Webhook Deliveries")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "webhook-deliveries", 5*time.Minute, func(ctx context.Context) error {
+		summary, err := h.webhookService.RetryDue(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Webhook deliveries retried", "attempted", summary.Attempted, "delivered", summary.Delivered,
+			"failed", summary.Failed, "pruned", summary.Pruned)
+		return nil
+	})
+}
+
+// handleTasksRetention queues enforcement of the organisation retention
+// policies due today. Meant to run daily.
+func (h *Handler) handleTasksRetention(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Retention")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "retention", 5*time.Minute, func(ctx context.Context) error {
+		queued, err := h.retentionService.QueueDue(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Retention enforcement queued", "organisations", queued)
+		return nil
+	})
+}
+
+// handleTasksNotificationDigest emails each member the digest of notifications
+// queued since the last run. Meant to run daily.
+func (h *Handler) handleTasksNotificationDigest(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Notification Digest")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "notification-digest", 30*time.Minute, func(ctx context.Context) error {
+		summary, err := h.notificationService.SendDigests(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Notification digests sent", "sent", summary.Sent, "failed", summary.Failed, "items", summary.Items, "pruned", summary.Pruned)
+		return nil
+	})
+}
+
+// handleTasksPendingDeletions removes the stored files queued by purges,
+// retrying earlier failures that are due. Meant to run every few minutes.
+func (h *Handler) handleTasksPendingDeletions(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Pending Deletions")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "pending-deletions", 15*time.Minute, func(ctx context.Context) error {
+		summary, err := h.sweeperService.Sweep(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Pending deletions swept", "claimed", summary.Claimed, "swept", summary.Swept,
+			"removed", summary.Removed, "failed", summary.Failed, "backlog", summary.Backlog)
+		return nil
+	})
+}
+
+// handleTasksLibraryOrphans queues library files in storage that no installed
+// library owns for the storage sweeper. Meant to run daily.
+func (h *Handler) handleTasksLibraryOrphans(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Library Orphans")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	h.runExclusiveTask(w, r, "library-orphans", 30*time.Minute, func(ctx context.Context) error {
+		report, err := h.h5pService.ReconcileLibraryStorage(ctx, false)
+		if err != nil {
+			return err
+		}
+		slog.Info("Library storage reconciled", "orphaned", len(report.StorageKeys), "files", report.Files, "queued", report.Queued)
+		return nil
+	})
+}
+
+// handleTasksFileReplication copies files the replica missed and removes ones
+// since removed from the primary. Does nothing unless FILE_REPLICA_PROVIDER
+// is set. Meant to run hourly.
+func (h *Handler) handleTasksFileReplication(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: File Replication")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	if h.fileReplication == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	h.runExclusiveTask(w, r, "file-replication", time.Hour, func(ctx context.Context) error {
+		summary, err := h.fileReplication.Reconcile(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("File replica reconciled", "primary", summary.Primary, "secondary", summary.Secondary,
+			"copied", summary.Copied, "removed", summary.Removed, "failed", summary.Failed, "complete", summary.Complete)
+		return nil
+	})
 }
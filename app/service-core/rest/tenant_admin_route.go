@@ -0,0 +1,113 @@
+package rest
+
+import (
+	"app/pkg"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxOrganisationImportSize caps the archive accepted by the import endpoint.
+const maxOrganisationImportSize = 20 << 30
+
+// handleAdminOrganisationExport downloads a portable archive of an organisation,
+// for moving it to a self-hosted instance.
+// URL pattern: /api/v1/admin/organisations/{orgId}/export
+func (h *Handler) handleAdminOrganisationExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	// Build the archive on disk first, so a failure still gets a JSON error
+	// instead of a truncated download
+	archive, err := os.CreateTemp("", "org-export-*.zip")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.InternalError{Message: "Error creating export", Err: err})
+		return
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	manifest, err := h.tenantService.ExportOrganisation(r.Context(), organisationID, archive)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.InternalError{Message: "Error reading export", Err: err})
+		return
+	}
+	slog.Info("Exported organisation", "organisationID", organisationID, "adminID", adminID, "files", len(manifest.Files))
+
+	// Large archives outlast the server's write timeout
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	filename := fmt.Sprintf("organisation-%s-%s.zip", organisationID, manifest.ExportedAt.Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	if _, err := io.Copy(w, archive); err != nil {
+		slog.Error("Error sending organisation export", "organisationID", organisationID, "error", err)
+	}
+}
+
+// handleAdminOrganisationImport recreates an organisation from an export
+// archive sent as the request body.
+// URL pattern: /api/v1/admin/organisations/import
+func (h *Handler) handleAdminOrganisationImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+
+	adminID, err := h.requireSuperAdmin(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	// zip needs random access, so spool the body to disk
+	_ = http.NewResponseController(w).SetReadDeadline(time.Time{})
+	archive, err := os.CreateTemp("", "org-import-*.zip")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.InternalError{Message: "Error receiving import", Err: err})
+		return
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	size, err := io.Copy(archive, http.MaxBytesReader(w, r.Body, maxOrganisationImportSize))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Error reading archive", Err: err})
+		return
+	}
+
+	result, err := h.tenantService.ImportOrganisation(r.Context(), archive, size)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	slog.Info("Imported organisation",
+		"organisationID", result.OrganisationID,
+		"adminID", adminID,
+		"inserted", result.Inserted,
+		"skipped", result.Skipped,
+		"files", result.Files,
+	)
+	writeResponse(h.cfg, w, r, result, nil)
+}
@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"net/http"
+)
+
+// extractRequest is the body of a markdown extraction request
+type extractRequest struct {
+	URL     string `json:"url"`
+	Refresh bool   `json:"refresh"`
+}
+
+// handleToolsExtract converts a web page to markdown, returning its title,
+// markdown and links, the provider that read it and whether it came from the
+// cache. Refresh skips the cache.
+// URL pattern: POST /api/v1/tools/extract
+func (h *Handler) handleToolsExtract(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	if _, err := h.requireUser(r); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req extractRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 8<<10)).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+	extraction, err := h.extractService.Extract(r.Context(), req.URL, req.Refresh)
+	writeResponse(h.cfg, w, r, extraction, err)
+}
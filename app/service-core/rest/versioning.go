@@ -0,0 +1,141 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// apiVersion identifies the public API version a request was routed through.
+type apiVersion int
+
+const (
+	apiV1 apiVersion = 1
+	apiV2 apiVersion = 2
+)
+
+const apiVersionContextKey contextKey = "apiVersion"
+
+// v1Deprecation describes a v1 endpoint slated for removal.
+type v1Deprecation struct {
+	Sunset    time.Time // when the v1 route stops being served
+	Successor string    // v2 path clients should migrate to (optional)
+}
+
+// deprecatedV1Routes lists v1 paths (exact match, or prefix when ending in "/")
+// that emit Deprecation/Sunset/Link headers. Add entries here once the v2
+// replacement has shipped and mobile clients have been given a date.
+var deprecatedV1Routes = map[string]v1Deprecation{}
+
+// apiVersionFromContext returns the API version stored on the request, defaulting to v1.
+func apiVersionFromContext(ctx context.Context) apiVersion {
+	if v, ok := ctx.Value(apiVersionContextKey).(apiVersion); ok {
+		return v
+	}
+	return apiV1
+}
+
+// v2Router serves /api/v2/... by rewriting the path onto the shared v1 handlers
+// and tagging the request so writeResponse picks the v2 serializer.
+// Handlers parse sub-routes with "/api/v1/" prefixes, so the rewrite keeps them
+// version-agnostic until a route needs a genuinely different implementation.
+func v2Router(mux *http.ServeMux) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), apiVersionContextKey, apiV2)
+		r2 := r.Clone(ctx)
+		r2.URL.Path = "/api/v1/" + strings.TrimPrefix(r.URL.Path, "/api/v2/")
+		r2.URL.RawPath = ""
+		mux.ServeHTTP(w, r2)
+	}
+}
+
+// deprecationMiddleware adds RFC 8594 Sunset and RFC 9745 Deprecation headers
+// (plus a successor-version Link) to v1 endpoints listed in deprecatedV1Routes.
+func deprecationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if dep, ok := lookupV1Deprecation(r.URL.Path); ok {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Sunset", dep.Sunset.UTC().Format(http.TimeFormat))
+			if dep.Successor != "" {
+				w.Header().Add("Link", "<"+dep.Successor+`>; rel="successor-version"`)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func lookupV1Deprecation(path string) (v1Deprecation, bool) {
+	if !strings.HasPrefix(path, "/api/v1/") {
+		return v1Deprecation{}, false
+	}
+	if dep, ok := deprecatedV1Routes[path]; ok {
+		return dep, true
+	}
+	for route, dep := range deprecatedV1Routes {
+		if strings.HasSuffix(route, "/") && strings.HasPrefix(path, route) {
+			return dep, true
+		}
+	}
+	return v1Deprecation{}, false
+}
+
+// responseSerializer shapes the JSON envelope for a given API version.
+// meta is omitted from the envelope when nil.
+type responseSerializer interface {
+	success(data any, meta *responseMeta) any
+	failure(code int, message string) any
+}
+
+// v1Serializer is the original Safe<T> envelope the SvelteKit frontend expects.
+type v1Serializer struct{}
+
+func (v1Serializer) success(data any, meta *responseMeta) any {
+	body := map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"message": "Operation completed successfully",
+	}
+	if meta != nil {
+		body["meta"] = meta
+	}
+	return body
+}
+
+func (v1Serializer) failure(code int, message string) any {
+	return map[string]interface{}{
+		"success": false,
+		"message": message,
+		"code":    code,
+	}
+}
+
+// v2Serializer drops the redundant success flag and nests errors under "error".
+type v2Serializer struct{}
+
+func (v2Serializer) success(data any, meta *responseMeta) any {
+	body := map[string]interface{}{
+		"data": data,
+	}
+	if meta != nil {
+		body["meta"] = meta
+	}
+	return body
+}
+
+func (v2Serializer) failure(code int, message string) any {
+	return map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	}
+}
+
+// serializerFor picks the response envelope for the request's API version.
+func serializerFor(r *http.Request) responseSerializer {
+	if apiVersionFromContext(r.Context()) == apiV2 {
+		return v2Serializer{}
+	}
+	return v1Serializer{}
+}
@@ -0,0 +1,168 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"service-core/domain/membership"
+	"service-core/domain/webhook"
+)
+
+// WebhookCreateRequest represents the request body for registering a webhook
+type WebhookCreateRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"` // empty = all events
+}
+
+// requireUser authenticates the request and returns the user's ID.
+func (h *Handler) requireUser(r *http.Request) (uuid.UUID, error) {
+	token := extractAccessToken(r)
+	if token == "" {
+		return uuid.Nil, pkg.UnauthorizedError{Err: errors.New("missing access token")}
+	}
+	claims, err := h.authService.ValidateAccessToken(token)
+	if err != nil {
+		return uuid.Nil, pkg.UnauthorizedError{Err: errors.New("invalid access token")}
+	}
+	return claims.ID, nil
+}
+
+// requireOrgAdmin authenticates the request and checks the user is an owner or
+// admin of the organisation. It returns the user's ID.
+func (h *Handler) requireOrgAdmin(r *http.Request, organisationID uuid.UUID) (uuid.UUID, error) {
+	userID, err := h.requireUser(r)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := membership.RequireAdmin(r.Context(), h.store, userID, organisationID); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
+
+// handleOrganisationWebhooks lists (GET) or registers (POST) an organisation's webhooks.
+// URL pattern: /api/v1/organisations/{orgId}/webhooks
+func (h *Handler) handleOrganisationWebhooks(w http.ResponseWriter, r *http.Request) {
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		webhooks, err := h.webhookService.List(r.Context(), organisationID)
+		writeResponse(h.cfg, w, r, webhooks, err)
+	case http.MethodPost:
+		var req WebhookCreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		created, err := h.webhookService.Create(r.Context(), organisationID, req.URL, req.Events)
+		writeResponse(h.cfg, w, r, created, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleOrganisationWebhook updates (PUT) or deletes (DELETE) one of an
+// organisation's webhooks.
+// URL pattern: /api/v1/organisations/{orgId}/webhooks/{webhookId}
+func (h *Handler) handleOrganisationWebhook(w http.ResponseWriter, r *http.Request) {
+	organisationID, webhookID, ok := h.webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req webhook.WebhookUpdate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+			return
+		}
+		updated, err := h.webhookService.Update(r.Context(), organisationID, webhookID, req)
+		writeResponse(h.cfg, w, r, updated, err)
+	case http.MethodDelete:
+		err := h.webhookService.Delete(r.Context(), organisationID, webhookID)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		writeResponse(h.cfg, w, r, map[string]bool{"success": true}, nil)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+	}
+}
+
+// handleOrganisationWebhookDeliveries lists a webhook's recent deliveries, newest first.
+// URL pattern: GET /api/v1/organisations/{orgId}/webhooks/{webhookId}/deliveries?limit=50
+func (h *Handler) handleOrganisationWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	organisationID, webhookID, ok := h.webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	limit, err := parseIntParam(r, "limit", 50, 1, 200)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), organisationID, webhookID, limit)
+	writeResponse(h.cfg, w, r, deliveries, err)
+}
+
+// handleOrganisationWebhookRedeliver sends a delivered or failed delivery again.
+// URL pattern: POST /api/v1/organisations/{orgId}/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver
+func (h *Handler) handleOrganisationWebhookRedeliver(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	organisationID, webhookID, ok := h.webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := uuid.Parse(r.PathValue("deliveryId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid deliveryId"})
+		return
+	}
+	delivery, err := h.webhookService.Redeliver(r.Context(), organisationID, webhookID, deliveryID)
+	writeResponse(h.cfg, w, r, delivery, err)
+}
+
+// webhookPath parses the organisation and webhook IDs from the path and checks
+// the caller is an organisation admin, writing the error response if not.
+func (h *Handler) webhookPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	organisationID, err := uuid.Parse(r.PathValue("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid organisationId"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	webhookID, err := uuid.Parse(r.PathValue("webhookId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid webhookId"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	if _, err := h.requireOrgAdmin(r, organisationID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return organisationID, webhookID, true
+}
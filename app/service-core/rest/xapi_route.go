@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 
 	"app/pkg"
+	"service-core/domain/analytics"
 	"service-core/storage/query"
 )
 
@@ -72,7 +73,7 @@ func (h *Handler) handleXapiStatement(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify user is an org member
-	_, err = store.CheckUserOrgMembership(ctx, query.CheckUserOrgMembershipParams{
+	_, err = h.store.CheckUserOrgMembership(ctx, query.CheckUserOrgMembershipParams{
 		UserID:         userID,
 		OrganisationID: content.OrgID,
 	})
@@ -91,7 +92,7 @@ func (h *Handler) handleXapiStatement(w http.ResponseWriter, r *http.Request) {
 	// Insert xAPI statement (append-only)
 	_, err = store.InsertXapiStatement(ctx, query.InsertXapiStatementParams{
 		OrgID:     content.OrgID,
-		UserID:    userID,
+		UserID:    uuid.NullUUID{UUID: userID, Valid: true},
 		ContentID: uuid.NullUUID{UUID: contentUUID, Valid: true},
 		Verb:      req.Verb,
 		Statement: statementJSON,
@@ -112,7 +113,7 @@ func (h *Handler) handleXapiStatement(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) updateProgress(ctx context.Context, store *query.Queries, userID uuid.UUID, contentID uuid.UUID, orgID uuid.UUID, req xapiRequest) {
 	// Find all enrolments where this user is enrolled in a course containing this content
 	enrolmentRows, err := store.GetEnrolmentsByUserAndContentId(ctx, query.GetEnrolmentsByUserAndContentIdParams{
-		UserID:    userID,
+		UserID:    uuid.NullUUID{UUID: userID, Valid: true},
 		ContentID: uuid.NullUUID{UUID: contentID, Valid: true},
 	})
 	if err != nil || len(enrolmentRows) == 0 {
@@ -160,7 +161,7 @@ func (h *Handler) updateProgress(ctx context.Context, store *query.Queries, user
 			OrgID:       orgID,
 			EnrolmentID: enrolment.ID,
 			ContentID:   contentID,
-			UserID:      userID,
+			UserID:      uuid.NullUUID{UUID: userID, Valid: true},
 			Score:       sql.NullString{String: score, Valid: scoreValid},
 			MaxScore:    sql.NullString{String: maxScore, Valid: maxScoreValid},
 			Completion:  completionStr,
@@ -183,7 +184,13 @@ func (h *Handler) updateProgress(ctx context.Context, store *query.Queries, user
 		}
 
 		if completedCount >= activeCount && activeCount > 0 {
-			_ = store.CompleteEnrolment(ctx, enrolment.ID)
+			if err := store.CompleteEnrolment(ctx, enrolment.ID); err != nil {
+				slog.Error("failed to complete enrolment", "error", err, "enrolmentId", enrolment.ID)
+				continue
+			}
+			if _, err := h.certificateService.IssueForEnrolment(ctx, enrolment.ID); err != nil {
+				slog.Error("failed to issue certificate", "error", err, "enrolmentId", enrolment.ID)
+			}
 		}
 	}
 }
@@ -215,3 +222,149 @@ func parseDuration(iso string) int32 {
 	}
 	return total
 }
+
+// handleXapiStatements records xAPI statements a learner posts about H5P
+// content: one statement or an array of them. Each is validated, and its
+// verb, object and result are stored alongside the raw statement. Responds
+// with the statement IDs, assigned to statements posted without one.
+// URL pattern: POST /api/v1/xapi/statements?contentId=
+func (h *Handler) handleXapiStatements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	userID, err := h.requireUser(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	contentID, err := uuid.Parse(r.URL.Query().Get("contentId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid contentId"})
+		return
+	}
+
+	ctx := r.Context()
+	store := query.New(h.storage.Conn)
+	content, err := store.GetH5PContentOrgId(ctx, contentID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.NotFoundError{Message: "Content not found", Err: err})
+		return
+	}
+	if _, err := h.store.CheckUserOrgMembership(ctx, query.CheckUserOrgMembershipParams{
+		UserID:         userID,
+		OrganisationID: content.OrgID,
+	}); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("not a member of this organisation")})
+		return
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&body); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body"})
+		return
+	}
+	statements, err := analytics.ParseStatements(body)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	if err := h.analyticsService.RecordStatements(ctx, content.OrgID, userID, contentID, statements); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	ids := make([]uuid.UUID, len(statements))
+	for i, st := range statements {
+		ids[i] = st.ID
+		if st.Verb == "completed" || st.Verb == "scored" {
+			var decoded interface{}
+			_ = json.Unmarshal(st.Raw, &decoded)
+			h.updateProgress(ctx, store, userID, contentID, content.OrgID, xapiRequest{Verb: st.Verb, Statement: decoded})
+		}
+	}
+	writeResponse(h.cfg, w, r, map[string]any{"ids": ids}, nil)
+}
+
+// handleXapiContentSummary summarises learners' completion and scores on
+// content, with a page of per-learner results. Org admins only.
+// URL pattern: GET /api/v1/xapi/content/{contentId}/summary?limit=&offset=
+func (h *Handler) handleXapiContentSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	contentID, err := uuid.Parse(r.PathValue("contentId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid contentId"})
+		return
+	}
+	page, err := parsePageParams(r, defaultPageLimits)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	content, err := query.New(h.storage.Conn).GetH5PContentOrgId(r.Context(), contentID)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.NotFoundError{Message: "Content not found", Err: err})
+		return
+	}
+	if _, err := h.requireOrgAdmin(r, content.OrgID); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	summary, err := h.analyticsService.ContentSummary(r.Context(), content.OrgID, contentID, page.Limit, page.Offset)
+	writeResponse(h.cfg, w, r, summary, err)
+}
+
+// handleXapiLearnerSummary lists a learner's completion and scores on each
+// piece of the organisation's content. Learners can see their own; org
+// admins can see anyone's.
+// URL pattern: GET /api/v1/xapi/learners/{userId}/summary?orgId=&limit=&offset=
+func (h *Handler) handleXapiLearnerSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		return
+	}
+	learnerID, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid userId"})
+		return
+	}
+	orgID, err := uuid.Parse(r.URL.Query().Get("orgId"))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid orgId"})
+		return
+	}
+	page, err := parsePageParams(r, defaultPageLimits)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	userID, err := h.requireUser(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	if userID == learnerID {
+		_, err = h.store.CheckUserOrgMembership(r.Context(), query.CheckUserOrgMembershipParams{
+			UserID:         userID,
+			OrganisationID: orgID,
+		})
+		if err != nil {
+			err = pkg.UnauthorizedError{Err: errors.New("not a member of this organisation")}
+		}
+	} else {
+		_, err = h.requireOrgAdmin(r, orgID)
+	}
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	results, err := h.analyticsService.LearnerSummary(r.Context(), orgID, learnerID, page.Limit, page.Offset)
+	writeResponse(h.cfg, w, r, results, err)
+}
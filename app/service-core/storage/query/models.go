@@ -13,6 +13,131 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
+type AccessReview struct {
+	ID             uuid.UUID     `json:"id"`
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	RequestedBy    uuid.NullUUID `json:"requested_by"`
+	Status         string        `json:"status"`
+	CreatedAt      time.Time     `json:"created_at"`
+	CompletedAt    sql.NullTime  `json:"completed_at"`
+	ExpiresAt      sql.NullTime  `json:"expires_at"`
+	Error          string        `json:"error"`
+}
+
+type Announcement struct {
+	ID          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Title       string        `json:"title"`
+	Body        string        `json:"body"`
+	LinkUrl     string        `json:"link_url"`
+	Tiers       []string      `json:"tiers"`
+	Flags       []string      `json:"flags"`
+	PublishedAt sql.NullTime  `json:"published_at"`
+	ExpiresAt   sql.NullTime  `json:"expires_at"`
+	CreatedBy   uuid.NullUUID `json:"created_by"`
+}
+
+type AnnouncementRead struct {
+	AnnouncementID uuid.UUID `json:"announcement_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	ReadAt         time.Time `json:"read_at"`
+}
+
+type Certificate struct {
+	ID               uuid.UUID      `json:"id"`
+	OrgID            uuid.UUID      `json:"org_id"`
+	UserID           uuid.UUID      `json:"user_id"`
+	CourseID         uuid.NullUUID  `json:"course_id"`
+	EnrolmentID      uuid.NullUUID  `json:"enrolment_id"`
+	LearnerName      string         `json:"learner_name"`
+	CourseTitle      string         `json:"course_title"`
+	OrganisationName string         `json:"organisation_name"`
+	CompletedAt      time.Time      `json:"completed_at"`
+	IssuedAt         time.Time      `json:"issued_at"`
+	StorageKey       sql.NullString `json:"storage_key"`
+}
+
+type CertificateDownload struct {
+	ID            uuid.UUID `json:"id"`
+	CertificateID uuid.UUID `json:"certificate_id"`
+	UserID        uuid.UUID `json:"user_id"`
+	DownloadedAt  time.Time `json:"downloaded_at"`
+}
+
+type CertificateTemplate struct {
+	OrganisationID uuid.UUID       `json:"organisation_id"`
+	Heading        string          `json:"heading"`
+	Fields         json.RawMessage `json:"fields"`
+	SignatoryName  string          `json:"signatory_name"`
+	SignatoryTitle string          `json:"signatory_title"`
+	BackgroundKey  sql.NullString  `json:"background_key"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type CompetitorAlert struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	PinID          uuid.UUID `json:"pin_id"`
+	Kind           string    `json:"kind"`
+	Message        string    `json:"message"`
+	Keywords       []string  `json:"keywords"`
+	Amount         int32     `json:"amount"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type CompetitorKeywordGap struct {
+	PinID        uuid.UUID     `json:"pin_id"`
+	Keyword      string        `json:"keyword"`
+	SearchVolume sql.NullInt32 `json:"search_volume"`
+	Position     int32         `json:"position"`
+	Url          string        `json:"url"`
+	IsNew        bool          `json:"is_new"`
+	FirstSeenAt  time.Time     `json:"first_seen_at"`
+	LastSeenAt   time.Time     `json:"last_seen_at"`
+}
+
+type CompetitorPin struct {
+	ID             uuid.UUID     `json:"id"`
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	Target         string        `json:"target"`
+	Domain         string        `json:"domain"`
+	LocationCode   int32         `json:"location_code"`
+	LanguageCode   string        `json:"language_code"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+	CreatedAt      time.Time     `json:"created_at"`
+	RefreshedAt    sql.NullTime  `json:"refreshed_at"`
+	NextRefreshAt  time.Time     `json:"next_refresh_at"`
+}
+
+type CompetitorSnapshot struct {
+	ID               uuid.UUID       `json:"id"`
+	OrganisationID   uuid.UUID       `json:"organisation_id"`
+	Target           string          `json:"target"`
+	Domain           string          `json:"domain"`
+	LocationCode     int32           `json:"location_code"`
+	LanguageCode     string          `json:"language_code"`
+	CapturedAt       time.Time       `json:"captured_at"`
+	OrganicEtv       sql.NullFloat64 `json:"organic_etv"`
+	OrganicKeywords  sql.NullInt32   `json:"organic_keywords"`
+	Top3Keywords     sql.NullInt32   `json:"top3_keywords"`
+	Top10Keywords    sql.NullInt32   `json:"top10_keywords"`
+	DomainRank       sql.NullInt32   `json:"domain_rank"`
+	Backlinks        sql.NullInt64   `json:"backlinks"`
+	ReferringDomains sql.NullInt32   `json:"referring_domains"`
+}
+
+type ContentExtractCache struct {
+	Url       string          `json:"url"`
+	FinalUrl  string          `json:"final_url"`
+	Title     string          `json:"title"`
+	Markdown  string          `json:"markdown"`
+	Links     json.RawMessage `json:"links"`
+	Provider  string          `json:"provider"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
 type Course struct {
 	ID          uuid.UUID      `json:"id"`
 	CreatedAt   time.Time      `json:"created_at"`
@@ -27,6 +152,8 @@ type Course struct {
 	PublishedAt sql.NullTime   `json:"published_at"`
 	ArchivedAt  sql.NullTime   `json:"archived_at"`
 	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	LockedAt    sql.NullTime   `json:"locked_at"`
+	LockReason  sql.NullString `json:"lock_reason"`
 }
 
 type CourseItem struct {
@@ -43,15 +170,33 @@ type CourseItem struct {
 }
 
 type Enrolment struct {
-	ID          uuid.UUID    `json:"id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
-	OrgID       uuid.UUID    `json:"org_id"`
-	CourseID    uuid.UUID    `json:"course_id"`
-	UserID      uuid.UUID    `json:"user_id"`
-	Status      string       `json:"status"`
-	EnrolledAt  time.Time    `json:"enrolled_at"`
-	CompletedAt sql.NullTime `json:"completed_at"`
+	ID          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	OrgID       uuid.UUID     `json:"org_id"`
+	CourseID    uuid.UUID     `json:"course_id"`
+	UserID      uuid.NullUUID `json:"user_id"`
+	ActorID     uuid.NullUUID `json:"actor_id"`
+	Status      string        `json:"status"`
+	EnrolledAt  time.Time     `json:"enrolled_at"`
+	CompletedAt sql.NullTime  `json:"completed_at"`
+}
+
+type H5pBundleReport struct {
+	Variant string    `json:"variant"`
+	Day     time.Time `json:"day"`
+	Loads   int64     `json:"loads"`
+	Errors  int64     `json:"errors"`
+}
+
+type H5pBundleVariant struct {
+	Name           string        `json:"name"`
+	Description    string        `json:"description"`
+	AssetBase      string        `json:"asset_base"`
+	RolloutPercent int32         `json:"rollout_percent"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
 }
 
 type H5pContent struct {
@@ -70,6 +215,8 @@ type H5pContent struct {
 	StoragePath sql.NullString  `json:"storage_path"`
 	Status      string          `json:"status"`
 	DeletedAt   sql.NullTime    `json:"deleted_at"`
+	LockedAt    sql.NullTime    `json:"locked_at"`
+	LockReason  sql.NullString  `json:"lock_reason"`
 }
 
 type H5pContentFolder struct {
@@ -110,6 +257,17 @@ type H5pHubRegistration struct {
 	HubUrl     string    `json:"hub_url"`
 }
 
+type H5pInstallProfile struct {
+	ID          uuid.UUID     `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Libraries   []string      `json:"libraries"`
+	IsDefault   bool          `json:"is_default"`
+	CreatedBy   uuid.NullUUID `json:"created_by"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
 type H5pLibrary struct {
 	ID            uuid.UUID             `json:"id"`
 	CreatedAt     time.Time             `json:"created_at"`
@@ -139,6 +297,30 @@ type H5pLibraryDependency struct {
 	DependencyType string    `json:"dependency_type"`
 }
 
+type H5pLibrarySemanticsCache struct {
+	LibraryID uuid.UUID       `json:"library_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Semantics json.RawMessage `json:"semantics"`
+}
+
+type H5pMediaAsset struct {
+	ID           uuid.UUID      `json:"id"`
+	OrgID        uuid.UUID      `json:"org_id"`
+	UploadedBy   uuid.NullUUID  `json:"uploaded_by"`
+	Filename     string         `json:"filename"`
+	MimeType     string         `json:"mime_type"`
+	Kind         string         `json:"kind"`
+	SizeBytes    int64          `json:"size_bytes"`
+	Width        sql.NullInt32  `json:"width"`
+	Height       sql.NullInt32  `json:"height"`
+	Checksum     string         `json:"checksum"`
+	StorageKey   string         `json:"storage_key"`
+	ThumbnailKey sql.NullString `json:"thumbnail_key"`
+	UseCount     int32          `json:"use_count"`
+	CreatedAt    time.Time      `json:"created_at"`
+	LastUsedAt   time.Time      `json:"last_used_at"`
+}
+
 type H5pOrgLibrary struct {
 	ID         uuid.UUID `json:"id"`
 	CreatedAt  time.Time `json:"created_at"`
@@ -148,6 +330,61 @@ type H5pOrgLibrary struct {
 	Restricted bool      `json:"restricted"`
 }
 
+type H5pProfileApplication struct {
+	ID             uuid.UUID       `json:"id"`
+	OrganisationID uuid.UUID       `json:"organisation_id"`
+	ProfileID      uuid.NullUUID   `json:"profile_id"`
+	ProfileName    string          `json:"profile_name"`
+	Libraries      []string        `json:"libraries"`
+	Status         string          `json:"status"`
+	Summary        json.RawMessage `json:"summary"`
+	RequestedBy    uuid.NullUUID   `json:"requested_by"`
+	CreatedAt      time.Time       `json:"created_at"`
+	CompletedAt    sql.NullTime    `json:"completed_at"`
+}
+
+type Job struct {
+	ID          uuid.UUID       `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"max_attempts"`
+	LastError   string          `json:"last_error"`
+	RunAt       time.Time       `json:"run_at"`
+	LockedUntil sql.NullTime    `json:"locked_until"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	CompletedAt sql.NullTime    `json:"completed_at"`
+}
+
+type JobLock struct {
+	Name       string    `json:"name"`
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type NotificationDigestItem struct {
+	ID             uuid.UUID `json:"id"`
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Category       string    `json:"category"`
+	EventType      string    `json:"event_type"`
+	Summary        string    `json:"summary"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type NotificationPreference struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Category       string    `json:"category"`
+	Channel        string    `json:"channel"`
+	Enabled        bool      `json:"enabled"`
+	Digest         bool      `json:"digest"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
 type Organisation struct {
 	ID                     uuid.UUID      `json:"id"`
 	CreatedAt              time.Time      `json:"created_at"`
@@ -177,6 +414,59 @@ type Organisation struct {
 	FreemiumGrantedBy      sql.NullString `json:"freemium_granted_by"`
 	DeletedAt              sql.NullTime   `json:"deleted_at"`
 	DeletionScheduledFor   sql.NullTime   `json:"deletion_scheduled_for"`
+	Locale                 string         `json:"locale"`
+	Timezone               string         `json:"timezone"`
+}
+
+type OrganisationActivityLog struct {
+	ID             uuid.UUID             `json:"id"`
+	CreatedAt      time.Time             `json:"created_at"`
+	OrganisationID uuid.UUID             `json:"organisation_id"`
+	UserID         uuid.NullUUID         `json:"user_id"`
+	Action         string                `json:"action"`
+	EntityType     string                `json:"entity_type"`
+	EntityID       uuid.NullUUID         `json:"entity_id"`
+	OldValues      pqtype.NullRawMessage `json:"old_values"`
+	NewValues      pqtype.NullRawMessage `json:"new_values"`
+	IpAddress      sql.NullString        `json:"ip_address"`
+	UserAgent      sql.NullString        `json:"user_agent"`
+	Metadata       json.RawMessage       `json:"metadata"`
+}
+
+type OrganisationBillingContact struct {
+	OrganisationID      uuid.UUID       `json:"organisation_id"`
+	Name                string          `json:"name"`
+	Email               string          `json:"email"`
+	PoNumber            string          `json:"po_number"`
+	AddressLine1        string          `json:"address_line1"`
+	AddressLine2        string          `json:"address_line2"`
+	City                string          `json:"city"`
+	State               string          `json:"state"`
+	PostalCode          string          `json:"postal_code"`
+	Country             string          `json:"country"`
+	InvoiceMemo         string          `json:"invoice_memo"`
+	InvoiceCustomFields json.RawMessage `json:"invoice_custom_fields"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+}
+
+type OrganisationDeletion struct {
+	ID               uuid.UUID       `json:"id"`
+	OrganisationID   uuid.UUID       `json:"organisation_id"`
+	OrganisationName string          `json:"organisation_name"`
+	OrganisationSlug string          `json:"organisation_slug"`
+	RequestedBy      uuid.NullUUID   `json:"requested_by"`
+	RequestedAt      time.Time       `json:"requested_at"`
+	ScheduledFor     time.Time       `json:"scheduled_for"`
+	Status           string          `json:"status"`
+	Stage            string          `json:"stage"`
+	Progress         json.RawMessage `json:"progress"`
+	Attempts         int32           `json:"attempts"`
+	Error            string          `json:"error"`
+	ConfirmedBy      uuid.NullUUID   `json:"confirmed_by"`
+	CancelledBy      uuid.NullUUID   `json:"cancelled_by"`
+	CancelledAt      sql.NullTime    `json:"cancelled_at"`
+	StartedAt        sql.NullTime    `json:"started_at"`
+	CompletedAt      sql.NullTime    `json:"completed_at"`
 }
 
 type OrganisationMembership struct {
@@ -193,6 +483,58 @@ type OrganisationMembership struct {
 	AcceptedAt     sql.NullTime  `json:"accepted_at"`
 }
 
+type OrganisationPaymentMethod struct {
+	OrganisationID   uuid.UUID     `json:"organisation_id"`
+	PaymentMethodID  string        `json:"payment_method_id"`
+	Brand            string        `json:"brand"`
+	Last4            string        `json:"last4"`
+	ExpMonth         int32         `json:"exp_month"`
+	ExpYear          int32         `json:"exp_year"`
+	ExpiresAt        time.Time     `json:"expires_at"`
+	LastReminderDays sql.NullInt32 `json:"last_reminder_days"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+type OrganisationRetentionPolicy struct {
+	OrganisationID           uuid.UUID     `json:"organisation_id"`
+	ArchiveDraftsAfterMonths sql.NullInt32 `json:"archive_drafts_after_months"`
+	PurgeDeletedAfterDays    sql.NullInt32 `json:"purge_deleted_after_days"`
+	RetainXapiYears          sql.NullInt32 `json:"retain_xapi_years"`
+	UpdatedBy                uuid.NullUUID `json:"updated_by"`
+	CreatedAt                time.Time     `json:"created_at"`
+	UpdatedAt                time.Time     `json:"updated_at"`
+	EvaluatedAt              sql.NullTime  `json:"evaluated_at"`
+}
+
+type OrganisationStorageUsage struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	BytesUsed      int64     `json:"bytes_used"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type OrganisationWebhook struct {
+	ID                 uuid.UUID     `json:"id"`
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+	OrganisationID     uuid.UUID     `json:"organisation_id"`
+	Url                string        `json:"url"`
+	Secret             string        `json:"secret"`
+	Events             []string      `json:"events"`
+	Active             bool          `json:"active"`
+	LastDeliveryAt     sql.NullTime  `json:"last_delivery_at"`
+	LastDeliveryStatus sql.NullInt32 `json:"last_delivery_status"`
+}
+
+type PendingDeletion struct {
+	ID            uuid.UUID `json:"id"`
+	StorageKey    string    `json:"storage_key"`
+	Reason        string    `json:"reason"`
+	Attempts      int32     `json:"attempts"`
+	LastError     string    `json:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
 type ProgressRecord struct {
 	ID          uuid.UUID      `json:"id"`
 	CreatedAt   time.Time      `json:"created_at"`
@@ -200,7 +542,8 @@ type ProgressRecord struct {
 	OrgID       uuid.UUID      `json:"org_id"`
 	EnrolmentID uuid.UUID      `json:"enrolment_id"`
 	ContentID   uuid.UUID      `json:"content_id"`
-	UserID      uuid.UUID      `json:"user_id"`
+	UserID      uuid.NullUUID  `json:"user_id"`
+	ActorID     uuid.NullUUID  `json:"actor_id"`
 	Score       sql.NullString `json:"score"`
 	MaxScore    sql.NullString `json:"max_score"`
 	Completion  string         `json:"completion"`
@@ -209,6 +552,188 @@ type ProgressRecord struct {
 	TimeSpent   int32          `json:"time_spent"`
 }
 
+type RankPosition struct {
+	RunID     uuid.UUID     `json:"run_id"`
+	TrackerID uuid.UUID     `json:"tracker_id"`
+	Keyword   string        `json:"keyword"`
+	Domain    string        `json:"domain"`
+	Position  sql.NullInt32 `json:"position"`
+	Url       string        `json:"url"`
+	Source    string        `json:"source"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+type RankTracker struct {
+	ID                 uuid.UUID     `json:"id"`
+	OrganisationID     uuid.UUID     `json:"organisation_id"`
+	Domain             string        `json:"domain"`
+	LocationCode       int32         `json:"location_code"`
+	LanguageCode       string        `json:"language_code"`
+	Device             string        `json:"device"`
+	Competitors        []string      `json:"competitors"`
+	CreatedBy          uuid.NullUUID `json:"created_by"`
+	CreatedAt          time.Time     `json:"created_at"`
+	LastRunAt          sql.NullTime  `json:"last_run_at"`
+	NextRunAt          time.Time     `json:"next_run_at"`
+	VolumesRefreshedAt sql.NullTime  `json:"volumes_refreshed_at"`
+}
+
+type RankTrackerKeyword struct {
+	TrackerID    uuid.UUID     `json:"tracker_id"`
+	Keyword      string        `json:"keyword"`
+	SearchVolume sql.NullInt32 `json:"search_volume"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type RankTrackerRun struct {
+	ID             uuid.UUID       `json:"id"`
+	TrackerID      uuid.UUID       `json:"tracker_id"`
+	OrganisationID uuid.UUID       `json:"organisation_id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	CompletedAt    sql.NullTime    `json:"completed_at"`
+	Status         string          `json:"status"`
+	KeywordCount   int32           `json:"keyword_count"`
+	FailedCount    int32           `json:"failed_count"`
+	Cost           float64         `json:"cost"`
+	ShareOfVoice   json.RawMessage `json:"share_of_voice"`
+	Error          string          `json:"error"`
+}
+
+type SeoBacklinkProspect struct {
+	ID               uuid.UUID     `json:"id"`
+	OrganisationID   uuid.UUID     `json:"organisation_id"`
+	Target           string        `json:"target"`
+	Domain           string        `json:"domain"`
+	Status           string        `json:"status"`
+	Source           string        `json:"source"`
+	Competitors      []string      `json:"competitors"`
+	Contact          string        `json:"contact"`
+	Notes            string        `json:"notes"`
+	Rank             sql.NullInt32 `json:"rank"`
+	Backlinks        sql.NullInt64 `json:"backlinks"`
+	ReferringDomains sql.NullInt32 `json:"referring_domains"`
+	SpamScore        sql.NullInt32 `json:"spam_score"`
+	EnrichedAt       sql.NullTime  `json:"enriched_at"`
+	CreatedBy        uuid.NullUUID `json:"created_by"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+	ContactedAt      sql.NullTime  `json:"contacted_at"`
+	LinkedAt         sql.NullTime  `json:"linked_at"`
+}
+
+type SeoKeyword struct {
+	ListID          uuid.UUID       `json:"list_id"`
+	Keyword         string          `json:"keyword"`
+	SearchVolume    sql.NullInt32   `json:"search_volume"`
+	Cpc             sql.NullFloat64 `json:"cpc"`
+	Competition     string          `json:"competition"`
+	MonthlySearches json.RawMessage `json:"monthly_searches"`
+	RefreshedAt     sql.NullTime    `json:"refreshed_at"`
+}
+
+type SeoKeywordList struct {
+	ID              uuid.UUID     `json:"id"`
+	OrganisationID  uuid.UUID     `json:"organisation_id"`
+	Name            string        `json:"name"`
+	LocationCode    int32         `json:"location_code"`
+	LanguageCode    string        `json:"language_code"`
+	CreatedBy       uuid.NullUUID `json:"created_by"`
+	CreatedAt       time.Time     `json:"created_at"`
+	LastRefreshedAt sql.NullTime  `json:"last_refreshed_at"`
+	NextRefreshAt   time.Time     `json:"next_refresh_at"`
+}
+
+type SeoKeywordRefresh struct {
+	ID             uuid.UUID `json:"id"`
+	ListID         uuid.UUID `json:"list_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Status         string    `json:"status"`
+	KeywordCount   int32     `json:"keyword_count"`
+	UpdatedCount   int32     `json:"updated_count"`
+	Cost           float64   `json:"cost"`
+	Error          string    `json:"error"`
+}
+
+type SeoPageExperience struct {
+	ID                    uuid.UUID       `json:"id"`
+	OrganisationID        uuid.UUID       `json:"organisation_id"`
+	AuditID               uuid.UUID       `json:"audit_id"`
+	PageUrl               string          `json:"page_url"`
+	Strategy              string          `json:"strategy"`
+	CreatedBy             uuid.NullUUID   `json:"created_by"`
+	CreatedAt             time.Time       `json:"created_at"`
+	CompletedAt           sql.NullTime    `json:"completed_at"`
+	Status                string          `json:"status"`
+	Error                 string          `json:"error"`
+	IntrusiveInterstitial sql.NullBool    `json:"intrusive_interstitial"`
+	Score                 int32           `json:"score"`
+	Passed                bool            `json:"passed"`
+	CwvSource             string          `json:"cwv_source"`
+	LcpMs                 sql.NullFloat64 `json:"lcp_ms"`
+	InpMs                 sql.NullFloat64 `json:"inp_ms"`
+	Cls                   sql.NullFloat64 `json:"cls"`
+	Signals               json.RawMessage `json:"signals"`
+	Pagespeed             json.RawMessage `json:"pagespeed"`
+	Issues                json.RawMessage `json:"issues"`
+	RecheckedAt           sql.NullTime    `json:"rechecked_at"`
+}
+
+type SeoRankCheck struct {
+	ID             uuid.UUID       `json:"id"`
+	OrganisationID uuid.UUID       `json:"organisation_id"`
+	CreatedBy      uuid.NullUUID   `json:"created_by"`
+	CreatedAt      time.Time       `json:"created_at"`
+	CompletedAt    sql.NullTime    `json:"completed_at"`
+	Domain         string          `json:"domain"`
+	LocationCode   int32           `json:"location_code"`
+	LanguageCode   string          `json:"language_code"`
+	Device         string          `json:"device"`
+	KeywordCount   int32           `json:"keyword_count"`
+	Status         string          `json:"status"`
+	Cost           float64         `json:"cost"`
+	Error          string          `json:"error"`
+	Results        json.RawMessage `json:"results"`
+}
+
+type SeoRawArtifact struct {
+	PageID    uuid.UUID `json:"page_id"`
+	Section   string    `json:"section"`
+	ObjectKey string    `json:"object_key"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SeoSchedule struct {
+	ID             uuid.UUID     `json:"id"`
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	Domain         string        `json:"domain"`
+	Pages          []string      `json:"pages"`
+	Keywords       []string      `json:"keywords"`
+	LocationCode   int32         `json:"location_code"`
+	LanguageCode   string        `json:"language_code"`
+	Device         string        `json:"device"`
+	Strategy       string        `json:"strategy"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+	CreatedAt      time.Time     `json:"created_at"`
+	LastRunAt      sql.NullTime  `json:"last_run_at"`
+	NextRunAt      time.Time     `json:"next_run_at"`
+}
+
+type SeoScheduleRun struct {
+	ID             uuid.UUID       `json:"id"`
+	ScheduleID     uuid.UUID       `json:"schedule_id"`
+	OrganisationID uuid.UUID       `json:"organisation_id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	CompletedAt    sql.NullTime    `json:"completed_at"`
+	Status         string          `json:"status"`
+	AuditID        uuid.NullUUID   `json:"audit_id"`
+	RankCheckID    uuid.NullUUID   `json:"rank_check_id"`
+	Summary        json.RawMessage `json:"summary"`
+	Deltas         json.RawMessage `json:"deltas"`
+	Error          string          `json:"error"`
+}
+
 type Token struct {
 	ID       string    `json:"id"`
 	Expires  time.Time `json:"expires"`
@@ -235,12 +760,51 @@ type User struct {
 	SuspendedReason       sql.NullString `json:"suspended_reason"`
 }
 
+type UserNotification struct {
+	ID             uuid.UUID       `json:"id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	OrganisationID uuid.UUID       `json:"organisation_id"`
+	Category       string          `json:"category"`
+	EventType      string          `json:"event_type"`
+	Summary        string          `json:"summary"`
+	Data           json.RawMessage `json:"data"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ReadAt         sql.NullTime    `json:"read_at"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	WebhookID      uuid.UUID       `json:"webhook_id"`
+	OrganisationID uuid.UUID       `json:"organisation_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus sql.NullInt32   `json:"response_status"`
+	Error          string          `json:"error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	LastAttemptAt  sql.NullTime    `json:"last_attempt_at"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	DeliveredAt    sql.NullTime    `json:"delivered_at"`
+}
+
 type XapiStatement struct {
-	ID        uuid.UUID       `json:"id"`
-	CreatedAt time.Time       `json:"created_at"`
-	OrgID     uuid.UUID       `json:"org_id"`
-	UserID    uuid.UUID       `json:"user_id"`
-	ContentID uuid.NullUUID   `json:"content_id"`
-	Verb      string          `json:"verb"`
-	Statement json.RawMessage `json:"statement"`
+	ID                uuid.UUID       `json:"id"`
+	CreatedAt         time.Time       `json:"created_at"`
+	OrgID             uuid.UUID       `json:"org_id"`
+	UserID            uuid.NullUUID   `json:"user_id"`
+	ActorID           uuid.NullUUID   `json:"actor_id"`
+	ContentID         uuid.NullUUID   `json:"content_id"`
+	Verb              string          `json:"verb"`
+	Statement         json.RawMessage `json:"statement"`
+	StatementID       uuid.NullUUID   `json:"statement_id"`
+	VerbID            sql.NullString  `json:"verb_id"`
+	ObjectID          sql.NullString  `json:"object_id"`
+	ResultScoreRaw    sql.NullFloat64 `json:"result_score_raw"`
+	ResultScoreMax    sql.NullFloat64 `json:"result_score_max"`
+	ResultScoreScaled sql.NullFloat64 `json:"result_score_scaled"`
+	ResultSuccess     sql.NullBool    `json:"result_success"`
+	ResultCompletion  sql.NullBool    `json:"result_completion"`
+	ResultDuration    sql.NullInt32   `json:"result_duration"`
 }
@@ -6,44 +6,180 @@ package query
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AcceptPendingMemberships(ctx context.Context, userID uuid.UUID) error
+	// =============================================================================
+	// Job Locks
+	// =============================================================================
+	AcquireJobLock(ctx context.Context, arg AcquireJobLockParams) (int64, error)
+	AddOrganisationStorageUsage(ctx context.Context, arg AddOrganisationStorageUsageParams) error
+	AddRankPositions(ctx context.Context, arg AddRankPositionsParams) error
+	AddRankTrackerKeywords(ctx context.Context, arg AddRankTrackerKeywordsParams) (int64, error)
+	AddSeoKeywords(ctx context.Context, arg AddSeoKeywordsParams) error
+	AnonymizeUserEnrolments(ctx context.Context, arg AnonymizeUserEnrolmentsParams) (int64, error)
+	AnonymizeUserProgressRecords(ctx context.Context, arg AnonymizeUserProgressRecordsParams) (int64, error)
+	// =============================================================================
+	// Analytics Anonymization
+	// =============================================================================
+	AnonymizeUserXapiStatements(ctx context.Context, arg AnonymizeUserXapiStatementsParams) (int64, error)
+	ArchiveStaleDraftH5PContent(ctx context.Context, arg ArchiveStaleDraftH5PContentParams) (int64, error)
+	CancelPendingDeletions(ctx context.Context, storageKeys []string) error
 	CheckUserOrgMembership(ctx context.Context, arg CheckUserOrgMembershipParams) (uuid.UUID, error)
+	ClaimCompetitorPin(ctx context.Context, arg ClaimCompetitorPinParams) (int64, error)
+	// =============================================================================
+	// Pending Deletions
+	// =============================================================================
+	ClaimDuePendingDeletions(ctx context.Context, arg ClaimDuePendingDeletionsParams) ([]PendingDeletion, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ClaimRankTracker(ctx context.Context, arg ClaimRankTrackerParams) (int64, error)
+	ClaimSeoSchedule(ctx context.Context, arg ClaimSeoScheduleParams) (int64, error)
+	ClearDefaultH5PInstallProfile(ctx context.Context, id uuid.UUID) error
+	CompleteAccessReview(ctx context.Context, arg CompleteAccessReviewParams) error
 	CompleteEnrolment(ctx context.Context, id uuid.UUID) error
+	CompleteRankTrackerRun(ctx context.Context, arg CompleteRankTrackerRunParams) error
+	CompleteSeoPageExperience(ctx context.Context, arg CompleteSeoPageExperienceParams) error
+	CompleteSeoRankCheck(ctx context.Context, arg CompleteSeoRankCheckParams) error
+	CompleteSeoScheduleRun(ctx context.Context, arg CompleteSeoScheduleRunParams) error
 	CountActiveItemsInCourse(ctx context.Context, courseID uuid.UUID) (int64, error)
+	CountCompetitorKeywordGaps(ctx context.Context, organisationID uuid.UUID) ([]CountCompetitorKeywordGapsRow, error)
 	CountCompletedItemsInEnrolment(ctx context.Context, enrolmentID uuid.UUID) (int64, error)
+	CountCourseGradebookLearners(ctx context.Context, arg CountCourseGradebookLearnersParams) (int64, error)
+	CountExpiredXapiStatements(ctx context.Context, arg CountExpiredXapiStatementsParams) (int64, error)
 	CountH5PContentByOrg(ctx context.Context, orgID uuid.UUID) (int64, error)
 	CountH5PLibraries(ctx context.Context) (int64, error)
+	CountPurgeableH5PContent(ctx context.Context, arg CountPurgeableH5PContentParams) (int64, error)
+	// =============================================================================
+	// Rank Tracker
+	// =============================================================================
+	CountRankTrackerKeywordsByOrg(ctx context.Context, organisationID uuid.UUID) (int64, error)
+	CountSeoBacklinkProspects(ctx context.Context, arg CountSeoBacklinkProspectsParams) (int64, error)
+	CountStaleDraftH5PContent(ctx context.Context, arg CountStaleDraftH5PContentParams) (int64, error)
+	CountUnreadUserNotifications(ctx context.Context, arg CountUnreadUserNotificationsParams) (int64, error)
+	// =============================================================================
+	// Access Reviews
+	// =============================================================================
+	CreateAccessReview(ctx context.Context, arg CreateAccessReviewParams) (AccessReview, error)
+	CreateAccessReviewsForActiveOrganisations(ctx context.Context, requestedBy uuid.NullUUID) (int64, error)
+	// =============================================================================
+	// Announcements
+	// =============================================================================
+	CreateAnnouncement(ctx context.Context, arg CreateAnnouncementParams) (Announcement, error)
+	CreateCompetitorAlert(ctx context.Context, arg CreateCompetitorAlertParams) error
+	// =============================================================================
+	// Competitor Monitoring
+	// =============================================================================
+	CreateCompetitorPin(ctx context.Context, arg CreateCompetitorPinParams) (CompetitorPin, error)
+	CreateCompetitorSnapshot(ctx context.Context, arg CreateCompetitorSnapshotParams) error
 	// =============================================================================
 	// H5P Content (Organisation-scoped)
 	// =============================================================================
 	CreateH5PContent(ctx context.Context, arg CreateH5PContentParams) (H5pContent, error)
+	CreateH5PInstallProfile(ctx context.Context, arg CreateH5PInstallProfileParams) (H5pInstallProfile, error)
+	// =============================================================================
+	// H5P Media Library
+	// =============================================================================
+	CreateH5PMediaAsset(ctx context.Context, arg CreateH5PMediaAssetParams) (H5pMediaAsset, error)
+	CreateH5PProfileApplication(ctx context.Context, arg CreateH5PProfileApplicationParams) (H5pProfileApplication, error)
+	CreateNotificationDigestItem(ctx context.Context, arg CreateNotificationDigestItemParams) error
+	CreateOrganisationWebhook(ctx context.Context, arg CreateOrganisationWebhookParams) (OrganisationWebhook, error)
+	CreateRankTracker(ctx context.Context, arg CreateRankTrackerParams) (RankTracker, error)
+	CreateRankTrackerRun(ctx context.Context, arg CreateRankTrackerRunParams) (RankTrackerRun, error)
+	// =============================================================================
+	// SEO Backlink Prospects
+	// =============================================================================
+	CreateSeoBacklinkProspect(ctx context.Context, arg CreateSeoBacklinkProspectParams) (int64, error)
+	// =============================================================================
+	// SEO Keyword Lists
+	// =============================================================================
+	CreateSeoKeywordList(ctx context.Context, arg CreateSeoKeywordListParams) (SeoKeywordList, error)
+	CreateSeoKeywordRefresh(ctx context.Context, arg CreateSeoKeywordRefreshParams) error
+	// =============================================================================
+	// SEO Page Experience
+	// =============================================================================
+	CreateSeoPageExperience(ctx context.Context, arg CreateSeoPageExperienceParams) (SeoPageExperience, error)
+	// =============================================================================
+	// SEO Rank Checks
+	// =============================================================================
+	CreateSeoRankCheck(ctx context.Context, arg CreateSeoRankCheckParams) (SeoRankCheck, error)
+	CreateSeoScheduleRun(ctx context.Context, arg CreateSeoScheduleRunParams) (SeoScheduleRun, error)
+	CreateUserNotification(ctx context.Context, arg CreateUserNotificationParams) error
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	DeferSeoSchedule(ctx context.Context, arg DeferSeoScheduleParams) error
+	DeleteAccessReview(ctx context.Context, id uuid.UUID) error
+	DeleteAnnouncement(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteCompetitorPin(ctx context.Context, arg DeleteCompetitorPinParams) (int64, error)
 	DeleteContentUserState(ctx context.Context, arg DeleteContentUserStateParams) error
+	DeleteExpiredContentExtractCache(ctx context.Context) error
 	DeleteExpiredH5PHubCache(ctx context.Context) error
+	DeleteExpiredXapiStatements(ctx context.Context, arg DeleteExpiredXapiStatementsParams) (int64, error)
+	DeleteH5PBundleVariant(ctx context.Context, name string) (int64, error)
+	DeleteH5PInstallProfile(ctx context.Context, id uuid.UUID) (int64, error)
 	DeleteH5PLibrary(ctx context.Context, id uuid.UUID) error
 	DeleteH5PLibraryByMachineName(ctx context.Context, machineName string) error
 	DeleteH5PLibraryDependencies(ctx context.Context, libraryID uuid.UUID) error
+	DeleteH5PMediaAsset(ctx context.Context, arg DeleteH5PMediaAssetParams) error
 	DeleteH5POrgLibrary(ctx context.Context, arg DeleteH5POrgLibraryParams) error
+	DeleteNotificationDigestItems(ctx context.Context, arg DeleteNotificationDigestItemsParams) error
+	DeleteNotificationPreferences(ctx context.Context, arg DeleteNotificationPreferencesParams) error
+	DeleteOldUserNotifications(ctx context.Context, createdAt time.Time) (int64, error)
+	DeleteOldWebhookDeliveries(ctx context.Context, createdAt time.Time) (int64, error)
+	DeleteOrganisationPaymentMethod(ctx context.Context, organisationID uuid.UUID) error
+	DeleteOrganisationWebhook(ctx context.Context, arg DeleteOrganisationWebhookParams) (int64, error)
+	DeletePendingDeletion(ctx context.Context, id uuid.UUID) error
+	DeleteRankTracker(ctx context.Context, arg DeleteRankTrackerParams) (int64, error)
+	DeleteRankTrackerKeywords(ctx context.Context, arg DeleteRankTrackerKeywordsParams) (int64, error)
+	DeleteSeoBacklinkProspect(ctx context.Context, arg DeleteSeoBacklinkProspectParams) (int64, error)
+	DeleteSeoSchedule(ctx context.Context, arg DeleteSeoScheduleParams) (int64, error)
+	DeleteStaleCompetitorKeywordGaps(ctx context.Context, arg DeleteStaleCompetitorKeywordGapsParams) (int64, error)
 	DeleteTokens(ctx context.Context) error
 	DisableH5POrgLibrary(ctx context.Context, arg DisableH5POrgLibraryParams) error
 	DowngradeOrganisationToFree(ctx context.Context, id uuid.UUID) error
 	EnableH5POrgLibrary(ctx context.Context, arg EnableH5POrgLibraryParams) error
+	EnableH5POrgLibraryVersions(ctx context.Context, arg EnableH5POrgLibraryVersionsParams) error
+	EnrichSeoBacklinkProspect(ctx context.Context, arg EnrichSeoBacklinkProspectParams) error
+	ExtendJobLock(ctx context.Context, arg ExtendJobLockParams) (int64, error)
+	FailAccessReview(ctx context.Context, arg FailAccessReviewParams) error
+	FailStaleRankTrackerRuns(ctx context.Context, createdAt time.Time) (int64, error)
+	FinishH5PProfileApplication(ctx context.Context, arg FinishH5PProfileApplicationParams) error
+	GetAccessReview(ctx context.Context, id uuid.UUID) (AccessReview, error)
+	// =============================================================================
+	// Organisation Billing Contacts
+	// =============================================================================
+	GetBillingContact(ctx context.Context, organisationID uuid.UUID) (OrganisationBillingContact, error)
+	GetCertificate(ctx context.Context, id uuid.UUID) (Certificate, error)
+	// =============================================================================
+	// Certificates
+	// =============================================================================
+	GetCertificateTemplate(ctx context.Context, organisationID uuid.UUID) (CertificateTemplate, error)
+	GetCompetitorPin(ctx context.Context, arg GetCompetitorPinParams) (CompetitorPin, error)
+	// =============================================================================
+	// Content Extract Cache
+	// =============================================================================
+	GetContentExtractCache(ctx context.Context, url string) (ContentExtractCache, error)
 	// =============================================================================
 	// H5P Content User State (Save/Resume)
 	// =============================================================================
 	GetContentUserState(ctx context.Context, arg GetContentUserStateParams) (H5pContentUserState, error)
 	GetContentUserStatesForContent(ctx context.Context, arg GetContentUserStatesForContentParams) ([]H5pContentUserState, error)
+	GetDefaultH5PInstallProfile(ctx context.Context) (H5pInstallProfile, error)
 	GetEnrolmentsByUserAndContentId(ctx context.Context, arg GetEnrolmentsByUserAndContentIdParams) ([]GetEnrolmentsByUserAndContentIdRow, error)
+	// =============================================================================
+	// Results (gradebook)
+	// =============================================================================
+	GetGradebookCourse(ctx context.Context, arg GetGradebookCourseParams) (GetGradebookCourseRow, error)
 	GetH5PContent(ctx context.Context, arg GetH5PContentParams) (H5pContent, error)
 	GetH5PContentOrgId(ctx context.Context, id uuid.UUID) (GetH5PContentOrgIdRow, error)
 	// =============================================================================
 	// H5P Hub Cache
 	// =============================================================================
 	GetH5PHubCache(ctx context.Context, cacheKey string) (H5pHubCache, error)
+	GetH5PInstallProfile(ctx context.Context, id uuid.UUID) (H5pInstallProfile, error)
 	// =============================================================================
 	// H5P Libraries (Platform-wide)
 	// =============================================================================
@@ -57,6 +193,9 @@ type Querier interface {
 	// 'preloaded' deps of those editor libraries (since editor libs can have preloaded deps).
 	// Used by the editor to load widget JS/CSS (e.g. H5PEditor.ShowWhen, H5PEditor.RangeList).
 	GetH5PLibraryEditorDependencyTree(ctx context.Context, libraryID uuid.UUID) ([]H5pLibrary, error)
+	// Returns the given libraries and everything they depend on, transitively and
+	// over every dependency type, as an exported .h5p package carries them.
+	GetH5PLibraryExportTree(ctx context.Context, libraryIds []uuid.UUID) ([]H5pLibrary, error)
 	// Returns all transitive PRELOADED dependencies ordered deepest-first (topological).
 	// This ensures leaf dependencies (e.g. H5P.EventDispatcher) load before
 	// libraries that extend them (e.g. H5P.Question).
@@ -64,39 +203,204 @@ type Querier interface {
 	// so that playback doesn't try to load editor-only libraries (H5PEditor.*).
 	GetH5PLibraryFullDependencyTree(ctx context.Context, libraryID uuid.UUID) ([]H5pLibrary, error)
 	// =============================================================================
+	// H5P Library Semantics Cache
+	// =============================================================================
+	GetH5PLibrarySemanticsCache(ctx context.Context, libraryID uuid.UUID) (H5pLibrarySemanticsCache, error)
+	GetH5PMediaAsset(ctx context.Context, arg GetH5PMediaAssetParams) (H5pMediaAsset, error)
+	GetH5PMediaAssetByChecksum(ctx context.Context, arg GetH5PMediaAssetByChecksumParams) (H5pMediaAsset, error)
+	GetH5PProfileApplication(ctx context.Context, id uuid.UUID) (H5pProfileApplication, error)
+	GetLatestCompetitorSnapshot(ctx context.Context, arg GetLatestCompetitorSnapshotParams) (CompetitorSnapshot, error)
+	GetLatestRankTrackerRun(ctx context.Context, trackerID uuid.UUID) (RankTrackerRun, error)
+	GetOrgMemberIdentity(ctx context.Context, arg GetOrgMemberIdentityParams) (GetOrgMemberIdentityRow, error)
+	GetOrgMembershipRole(ctx context.Context, arg GetOrgMembershipRoleParams) (string, error)
+	// =============================================================================
+	// Billing Admin (Refunds & Credits)
+	// =============================================================================
+	GetOrganisationBillingContact(ctx context.Context, id uuid.UUID) (GetOrganisationBillingContactRow, error)
+	// =============================================================================
 	// Organisation Billing Queries (Platform Subscriptions)
 	// =============================================================================
 	GetOrganisationBillingInfo(ctx context.Context, id uuid.UUID) (GetOrganisationBillingInfoRow, error)
 	GetOrganisationByStripeCustomer(ctx context.Context, stripeCustomerID string) (Organisation, error)
+	GetOrganisationLocale(ctx context.Context, id uuid.UUID) (GetOrganisationLocaleRow, error)
+	// =============================================================================
+	// SEO Schedules
+	// =============================================================================
+	GetOrganisationSeoPlan(ctx context.Context, id uuid.UUID) (GetOrganisationSeoPlanRow, error)
+	// =============================================================================
+	// Organisation Storage Usage
+	// =============================================================================
+	GetOrganisationStorageQuota(ctx context.Context, id uuid.UUID) (GetOrganisationStorageQuotaRow, error)
+	GetOrganisationWebhook(ctx context.Context, arg GetOrganisationWebhookParams) (OrganisationWebhook, error)
+	GetPendingDeletionBacklog(ctx context.Context, attempts int32) ([]GetPendingDeletionBacklogRow, error)
+	GetPreviousSeoScheduleRun(ctx context.Context, arg GetPreviousSeoScheduleRunParams) (SeoScheduleRun, error)
+	GetRankTracker(ctx context.Context, arg GetRankTrackerParams) (RankTracker, error)
+	GetRankTrackerRunBefore(ctx context.Context, arg GetRankTrackerRunBeforeParams) (RankTrackerRun, error)
+	// =============================================================================
+	// Retention Policies
+	// =============================================================================
+	GetRetentionPolicy(ctx context.Context, organisationID uuid.UUID) (OrganisationRetentionPolicy, error)
+	GetSeoKeywordList(ctx context.Context, arg GetSeoKeywordListParams) (SeoKeywordList, error)
+	GetSeoRankCheck(ctx context.Context, arg GetSeoRankCheckParams) (SeoRankCheck, error)
+	GetSeoSchedule(ctx context.Context, arg GetSeoScheduleParams) (SeoSchedule, error)
+	// =============================================================================
+	// API Rate Limits
+	// =============================================================================
+	GetUserRatePlan(ctx context.Context, id uuid.UUID) (GetUserRatePlanRow, error)
+	GetXapiContentSummary(ctx context.Context, arg GetXapiContentSummaryParams) (GetXapiContentSummaryRow, error)
 	// =============================================================================
 	// H5P Library Dependencies
 	// =============================================================================
 	InsertH5PLibraryDependency(ctx context.Context, arg InsertH5PLibraryDependencyParams) error
+	InsertOrganisationActivity(ctx context.Context, arg InsertOrganisationActivityParams) error
+	InsertOrganisationActivityChange(ctx context.Context, arg InsertOrganisationActivityChangeParams) error
 	InsertToken(ctx context.Context, arg InsertTokenParams) (Token, error)
 	InsertUser(ctx context.Context, arg InsertUserParams) (User, error)
 	// =============================================================================
 	// XAPI & PROGRESS QUERIES (Phase 3)
 	// =============================================================================
 	InsertXapiStatement(ctx context.Context, arg InsertXapiStatementParams) (XapiStatement, error)
+	IsJobLockHeld(ctx context.Context, name string) (bool, error)
+	IssueCertificate(ctx context.Context, id uuid.UUID) (Certificate, error)
+	ListAccessReviewMembers(ctx context.Context, organisationID uuid.UUID) ([]ListAccessReviewMembersRow, error)
+	ListAccessReviews(ctx context.Context, organisationID uuid.UUID) ([]AccessReview, error)
+	ListActiveOrganisationWebhooks(ctx context.Context, organisationID uuid.UUID) ([]OrganisationWebhook, error)
+	ListAnnouncements(ctx context.Context) ([]Announcement, error)
+	ListCompetitorAlerts(ctx context.Context, arg ListCompetitorAlertsParams) ([]CompetitorAlert, error)
+	ListCompetitorKeywordGaps(ctx context.Context, arg ListCompetitorKeywordGapsParams) ([]CompetitorKeywordGap, error)
+	ListCompetitorPinsByOrg(ctx context.Context, organisationID uuid.UUID) ([]CompetitorPin, error)
+	ListCompetitorPinsByTarget(ctx context.Context, arg ListCompetitorPinsByTargetParams) ([]CompetitorPin, error)
+	ListContentResults(ctx context.Context, arg ListContentResultsParams) ([]ListContentResultsRow, error)
+	ListCourseGradebookItems(ctx context.Context, courseID uuid.UUID) ([]ListCourseGradebookItemsRow, error)
+	ListCourseGradebookLearners(ctx context.Context, arg ListCourseGradebookLearnersParams) ([]ListCourseGradebookLearnersRow, error)
+	ListCourseGradebookResults(ctx context.Context, arg ListCourseGradebookResultsParams) ([]ListCourseGradebookResultsRow, error)
+	ListDueCompetitorPins(ctx context.Context, limit int32) ([]CompetitorPin, error)
+	ListDueRankTrackers(ctx context.Context, limit int32) ([]RankTracker, error)
+	ListDueRetentionPolicies(ctx context.Context, arg ListDueRetentionPoliciesParams) ([]OrganisationRetentionPolicy, error)
+	ListDueSeoKeywords(ctx context.Context) ([]ListDueSeoKeywordsRow, error)
+	ListDueSeoSchedules(ctx context.Context, limit int32) ([]ListDueSeoSchedulesRow, error)
+	ListExpiredAccessReviews(ctx context.Context, limit int32) ([]AccessReview, error)
+	ListExpiringPaymentMethods(ctx context.Context, expiresAt time.Time) ([]ListExpiringPaymentMethodsRow, error)
+	ListH5PBundleReportTotals(ctx context.Context, day time.Time) ([]ListH5PBundleReportTotalsRow, error)
+	// =============================================================================
+	// H5P Editor Bundle Variants (Percentage rollout of editor assets)
+	// =============================================================================
+	ListH5PBundleVariants(ctx context.Context) ([]H5pBundleVariant, error)
 	ListH5PContentByOrg(ctx context.Context, arg ListH5PContentByOrgParams) ([]ListH5PContentByOrgRow, error)
+	// =============================================================================
+	// H5P Install Profiles (Library bundles applied to organisations)
+	// =============================================================================
+	ListH5PInstallProfiles(ctx context.Context) ([]H5pInstallProfile, error)
 	ListH5PLibraries(ctx context.Context) ([]H5pLibrary, error)
+	// =============================================================================
+	// H5P Library Storage
+	// =============================================================================
+	ListH5PLibraryVersions(ctx context.Context, machineName sql.NullString) ([]ListH5PLibraryVersionsRow, error)
+	ListH5PMediaAssets(ctx context.Context, arg ListH5PMediaAssetsParams) ([]H5pMediaAsset, error)
 	ListH5POrgEnabledLibraries(ctx context.Context, orgID uuid.UUID) ([]ListH5POrgEnabledLibrariesRow, error)
 	// =============================================================================
 	// H5P Org Libraries (Per-organisation enablement)
 	// =============================================================================
 	ListH5POrgLibraries(ctx context.Context, orgID uuid.UUID) ([]ListH5POrgLibrariesRow, error)
+	ListH5PProfileApplications(ctx context.Context, arg ListH5PProfileApplicationsParams) ([]H5pProfileApplication, error)
 	ListH5PRunnableLibraries(ctx context.Context) ([]H5pLibrary, error)
+	ListNotificationDigestItems(ctx context.Context, createdAt time.Time) ([]ListNotificationDigestItemsRow, error)
+	// =============================================================================
+	// Notifications
+	// =============================================================================
+	ListNotificationPreferences(ctx context.Context, arg ListNotificationPreferencesParams) ([]NotificationPreference, error)
+	ListOrganisationNotificationPreferences(ctx context.Context, organisationID uuid.UUID) ([]NotificationPreference, error)
+	ListOrganisationNotificationRecipients(ctx context.Context, organisationID uuid.UUID) ([]ListOrganisationNotificationRecipientsRow, error)
+	// =============================================================================
+	// Organisation Webhooks
+	// =============================================================================
+	ListOrganisationWebhooks(ctx context.Context, organisationID uuid.UUID) ([]OrganisationWebhook, error)
+	ListOutdatedH5PContent(ctx context.Context, arg ListOutdatedH5PContentParams) ([]ListOutdatedH5PContentRow, error)
+	// =============================================================================
+	// H5P Content Upgrades
+	// =============================================================================
+	ListOutdatedH5PContentLibraries(ctx context.Context, orgID uuid.UUID) ([]ListOutdatedH5PContentLibrariesRow, error)
+	ListPendingAccessReviews(ctx context.Context, limit int32) ([]AccessReview, error)
+	ListRankPositionHistory(ctx context.Context, arg ListRankPositionHistoryParams) ([]RankPosition, error)
+	ListRankPositionsByRun(ctx context.Context, arg ListRankPositionsByRunParams) ([]RankPosition, error)
+	ListRankTrackerKeywords(ctx context.Context, trackerID uuid.UUID) ([]RankTrackerKeyword, error)
+	ListRankTrackerRuns(ctx context.Context, arg ListRankTrackerRunsParams) ([]RankTrackerRun, error)
+	ListRankTrackersByOrg(ctx context.Context, organisationID uuid.UUID) ([]ListRankTrackersByOrgRow, error)
+	ListRecentCompetitorSnapshots(ctx context.Context, arg ListRecentCompetitorSnapshotsParams) ([]CompetitorSnapshot, error)
+	ListRunningSeoScheduleRuns(ctx context.Context) ([]SeoScheduleRun, error)
+	ListSeoBacklinkProspects(ctx context.Context, arg ListSeoBacklinkProspectsParams) ([]SeoBacklinkProspect, error)
+	ListSeoKeywordListsByOrg(ctx context.Context, organisationID uuid.UUID) ([]ListSeoKeywordListsByOrgRow, error)
+	ListSeoKeywordRefreshes(ctx context.Context, arg ListSeoKeywordRefreshesParams) ([]SeoKeywordRefresh, error)
+	ListSeoKeywords(ctx context.Context, listID uuid.UUID) ([]SeoKeyword, error)
+	ListSeoPageExperienceByAudit(ctx context.Context, arg ListSeoPageExperienceByAuditParams) ([]SeoPageExperience, error)
+	ListSeoPageExperienceTrend(ctx context.Context, arg ListSeoPageExperienceTrendParams) ([]SeoPageExperience, error)
+	ListSeoRawArtifacts(ctx context.Context, arg ListSeoRawArtifactsParams) ([]ListSeoRawArtifactsRow, error)
+	ListSeoScheduleRuns(ctx context.Context, arg ListSeoScheduleRunsParams) ([]SeoScheduleRun, error)
+	ListSeoSchedulesByOrg(ctx context.Context, organisationID uuid.UUID) ([]SeoSchedule, error)
+	ListStuckPendingDeletions(ctx context.Context, arg ListStuckPendingDeletionsParams) ([]PendingDeletion, error)
+	ListUnenrichedSeoBacklinkProspects(ctx context.Context, arg ListUnenrichedSeoBacklinkProspectsParams) ([]SeoBacklinkProspect, error)
+	ListUnreadAnnouncements(ctx context.Context, userID uuid.UUID) ([]Announcement, error)
+	ListUserCertificates(ctx context.Context, userID uuid.UUID) ([]ListUserCertificatesRow, error)
+	ListUserNotifications(ctx context.Context, arg ListUserNotificationsParams) ([]UserNotification, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListXapiContentLearners(ctx context.Context, arg ListXapiContentLearnersParams) ([]ListXapiContentLearnersRow, error)
+	ListXapiLearnerSummary(ctx context.Context, arg ListXapiLearnerSummaryParams) ([]ListXapiLearnerSummaryRow, error)
+	LockContentOfLockedCourses(ctx context.Context, arg LockContentOfLockedCoursesParams) (int64, error)
+	// =============================================================================
+	// Grace-Period Content Locks
+	// =============================================================================
+	LockCoursesOverLimit(ctx context.Context, arg LockCoursesOverLimitParams) (int64, error)
+	MarkAnnouncementRead(ctx context.Context, arg MarkAnnouncementReadParams) error
+	MarkCompetitorPinRefreshed(ctx context.Context, id uuid.UUID) error
+	MarkRankTrackerVolumesRefreshed(ctx context.Context, id uuid.UUID) error
+	MarkRetentionPolicyEvaluated(ctx context.Context, organisationID uuid.UUID) error
+	MarkUserNotificationsRead(ctx context.Context, arg MarkUserNotificationsReadParams) (int64, error)
+	PurgeDeletedH5PContent(ctx context.Context, arg PurgeDeletedH5PContentParams) ([]uuid.UUID, error)
+	QueueOrphanedH5PLibraryFiles(ctx context.Context, arg QueueOrphanedH5PLibraryFilesParams) (int64, error)
+	RecheckSeoPageIssues(ctx context.Context, arg RecheckSeoPageIssuesParams) error
+	RecordCertificateDownload(ctx context.Context, arg RecordCertificateDownloadParams) error
+	RecordH5PBundleReport(ctx context.Context, arg RecordH5PBundleReportParams) error
+	RecordPendingDeletionFailure(ctx context.Context, arg RecordPendingDeletionFailureParams) error
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) error
+	RecordXapiStatement(ctx context.Context, arg RecordXapiStatementParams) (int64, error)
+	ReleaseJobLock(ctx context.Context, arg ReleaseJobLockParams) error
+	RequeueWebhookDelivery(ctx context.Context, arg RequeueWebhookDeliveryParams) (WebhookDelivery, error)
+	ResetSeoPageExperience(ctx context.Context, id uuid.UUID) (SeoPageExperience, error)
+	ScheduleSeoKeywordList(ctx context.Context, arg ScheduleSeoKeywordListParams) error
+	SearchCourses(ctx context.Context, arg SearchCoursesParams) ([]SearchCoursesRow, error)
+	// =============================================================================
+	// Search (command palette)
+	// =============================================================================
+	// pattern is the search term with LIKE wildcards escaped. Rank: 0 exact title,
+	// 1 title prefix, 2 word prefix, 3 title substring, 4 other field match.
+	SearchH5PContent(ctx context.Context, arg SearchH5PContentParams) ([]SearchH5PContentRow, error)
+	SearchH5PContentFolders(ctx context.Context, arg SearchH5PContentFoldersParams) ([]SearchH5PContentFoldersRow, error)
+	SearchSeoKeywordLists(ctx context.Context, arg SearchSeoKeywordListsParams) ([]SearchSeoKeywordListsRow, error)
 	SelectToken(ctx context.Context, id string) (Token, error)
 	SelectUser(ctx context.Context, id uuid.UUID) (User, error)
 	SelectUserByCustomerID(ctx context.Context, customerID string) (User, error)
 	SelectUserByEmail(ctx context.Context, email string) (User, error)
 	SelectUserByEmailAndSub(ctx context.Context, arg SelectUserByEmailAndSubParams) (User, error)
 	SelectUsers(ctx context.Context) ([]User, error)
+	SetCertificateStorageKey(ctx context.Context, arg SetCertificateStorageKeyParams) error
+	SetCertificateTemplateBackground(ctx context.Context, arg SetCertificateTemplateBackgroundParams) error
+	SetPaymentMethodReminderSent(ctx context.Context, arg SetPaymentMethodReminderSentParams) error
+	SetRankTrackerKeywordVolumes(ctx context.Context, arg SetRankTrackerKeywordVolumesParams) error
+	SetSeoScheduleRunTargets(ctx context.Context, arg SetSeoScheduleRunTargetsParams) error
 	SoftDeleteH5PContent(ctx context.Context, arg SoftDeleteH5PContentParams) error
+	TouchH5PMediaAsset(ctx context.Context, arg TouchH5PMediaAssetParams) error
+	UnlockOrganisationContent(ctx context.Context, orgID uuid.UUID) error
+	UnlockOrganisationCourses(ctx context.Context, orgID uuid.UUID) error
+	UpdateAnnouncement(ctx context.Context, arg UpdateAnnouncementParams) (Announcement, error)
 	UpdateH5PContent(ctx context.Context, arg UpdateH5PContentParams) (H5pContent, error)
+	UpdateH5PInstallProfile(ctx context.Context, arg UpdateH5PInstallProfileParams) (H5pInstallProfile, error)
 	UpdateH5PLibraryMetadataJson(ctx context.Context, arg UpdateH5PLibraryMetadataJsonParams) error
 	UpdateOrganisationStripeCustomer(ctx context.Context, arg UpdateOrganisationStripeCustomerParams) error
 	UpdateOrganisationSubscription(ctx context.Context, arg UpdateOrganisationSubscriptionParams) error
+	UpdateOrganisationWebhook(ctx context.Context, arg UpdateOrganisationWebhookParams) (OrganisationWebhook, error)
+	UpdateOrganisationWebhookDelivery(ctx context.Context, arg UpdateOrganisationWebhookDeliveryParams) error
+	UpdateSeoBacklinkProspect(ctx context.Context, arg UpdateSeoBacklinkProspectParams) (SeoBacklinkProspect, error)
+	UpdateSeoKeywordVolume(ctx context.Context, arg UpdateSeoKeywordVolumeParams) error
 	UpdateToken(ctx context.Context, arg UpdateTokenParams) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserAccess(ctx context.Context, arg UpdateUserAccessParams) (User, error)
@@ -105,10 +409,25 @@ type Querier interface {
 	UpdateUserPhone(ctx context.Context, arg UpdateUserPhoneParams) error
 	UpdateUserSub(ctx context.Context, arg UpdateUserSubParams) error
 	UpdateUserSubscription(ctx context.Context, arg UpdateUserSubscriptionParams) error
+	UpgradeH5PContentLibrary(ctx context.Context, arg UpgradeH5PContentLibraryParams) (int64, error)
+	UpsertBillingContact(ctx context.Context, arg UpsertBillingContactParams) (OrganisationBillingContact, error)
+	UpsertCertificateTemplate(ctx context.Context, arg UpsertCertificateTemplateParams) (CertificateTemplate, error)
+	UpsertCompetitorKeywordGaps(ctx context.Context, arg UpsertCompetitorKeywordGapsParams) ([]UpsertCompetitorKeywordGapsRow, error)
+	UpsertContentExtractCache(ctx context.Context, arg UpsertContentExtractCacheParams) error
 	UpsertContentUserState(ctx context.Context, arg UpsertContentUserStateParams) (H5pContentUserState, error)
+	UpsertH5PBundleVariant(ctx context.Context, arg UpsertH5PBundleVariantParams) (H5pBundleVariant, error)
 	UpsertH5PHubCache(ctx context.Context, arg UpsertH5PHubCacheParams) (H5pHubCache, error)
 	UpsertH5PLibrary(ctx context.Context, arg UpsertH5PLibraryParams) (H5pLibrary, error)
+	UpsertH5PLibrarySemanticsCache(ctx context.Context, arg UpsertH5PLibrarySemanticsCacheParams) error
+	UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) error
+	// =============================================================================
+	// Organisation Payment Methods (Card Expiry Reminders)
+	// =============================================================================
+	UpsertOrganisationPaymentMethod(ctx context.Context, arg UpsertOrganisationPaymentMethodParams) error
 	UpsertProgressRecord(ctx context.Context, arg UpsertProgressRecordParams) error
+	UpsertRetentionPolicy(ctx context.Context, arg UpsertRetentionPolicyParams) (OrganisationRetentionPolicy, error)
+	UpsertSeoRawArtifact(ctx context.Context, arg UpsertSeoRawArtifactParams) error
+	UpsertSeoSchedule(ctx context.Context, arg UpsertSeoScheduleParams) (SeoSchedule, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -25,339 +25,293 @@ func (q *Queries) AcceptPendingMemberships(ctx context.Context, userID uuid.UUID
 	return err
 }
 
-const checkUserOrgMembership = `-- name: CheckUserOrgMembership :one
-SELECT id FROM organisation_memberships
-WHERE user_id = $1 AND organisation_id = $2 AND status = 'active'
-LIMIT 1
+const acquireJobLock = `-- name: AcquireJobLock :execrows
+
+INSERT INTO job_locks (name, holder, acquired_at, expires_at)
+VALUES ($1, $2, now(), now() + make_interval(secs => $3::float8))
+ON CONFLICT (name) DO UPDATE SET
+    holder = EXCLUDED.holder,
+    acquired_at = EXCLUDED.acquired_at,
+    expires_at = EXCLUDED.expires_at
+WHERE job_locks.expires_at < now()
 `
 
-type CheckUserOrgMembershipParams struct {
-	UserID         uuid.UUID `json:"user_id"`
-	OrganisationID uuid.UUID `json:"organisation_id"`
+type AcquireJobLockParams struct {
+	Name       string  `json:"name"`
+	Holder     string  `json:"holder"`
+	TtlSeconds float64 `json:"ttl_seconds"`
 }
 
-func (q *Queries) CheckUserOrgMembership(ctx context.Context, arg CheckUserOrgMembershipParams) (uuid.UUID, error) {
-	row := q.db.QueryRowContext(ctx, checkUserOrgMembership, arg.UserID, arg.OrganisationID)
-	var id uuid.UUID
-	err := row.Scan(&id)
-	return id, err
+// =============================================================================
+// Job Locks
+// =============================================================================
+func (q *Queries) AcquireJobLock(ctx context.Context, arg AcquireJobLockParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, acquireJobLock, arg.Name, arg.Holder, arg.TtlSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const completeEnrolment = `-- name: CompleteEnrolment :exec
-UPDATE enrolments
-SET status = 'completed', completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
+const addOrganisationStorageUsage = `-- name: AddOrganisationStorageUsage :exec
+INSERT INTO organisation_storage_usage (organisation_id, bytes_used)
+VALUES ($1, $2)
+ON CONFLICT (organisation_id) DO UPDATE SET
+    bytes_used = organisation_storage_usage.bytes_used + EXCLUDED.bytes_used,
+    updated_at = now()
 `
 
-func (q *Queries) CompleteEnrolment(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, completeEnrolment, id)
-	return err
+type AddOrganisationStorageUsageParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	BytesUsed      int64     `json:"bytes_used"`
 }
 
-const countActiveItemsInCourse = `-- name: CountActiveItemsInCourse :one
-SELECT COUNT(*) as active_count
-FROM course_items ci
-WHERE ci.course_id = $1 AND ci.removed_at IS NULL
-`
-
-func (q *Queries) CountActiveItemsInCourse(ctx context.Context, courseID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countActiveItemsInCourse, courseID)
-	var active_count int64
-	err := row.Scan(&active_count)
-	return active_count, err
+func (q *Queries) AddOrganisationStorageUsage(ctx context.Context, arg AddOrganisationStorageUsageParams) error {
+	_, err := q.db.ExecContext(ctx, addOrganisationStorageUsage, arg.OrganisationID, arg.BytesUsed)
+	return err
 }
 
-const countCompletedItemsInEnrolment = `-- name: CountCompletedItemsInEnrolment :one
-SELECT COUNT(*) as completed_count
-FROM progress_records pr
-WHERE pr.enrolment_id = $1 AND pr.completed = true
+const addRankPositions = `-- name: AddRankPositions :exec
+INSERT INTO rank_positions (run_id, tracker_id, keyword, domain, position, url, source)
+SELECT $1::uuid, $2::uuid, p.keyword, p.domain, NULLIF(p.position, 0), p.url, p.source
+FROM (
+    SELECT unnest($3::text[]) AS keyword, unnest($4::text[]) AS domain,
+        unnest($5::int[]) AS position, unnest($6::text[]) AS url,
+        unnest($7::text[]) AS source
+) AS p
 `
 
-func (q *Queries) CountCompletedItemsInEnrolment(ctx context.Context, enrolmentID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countCompletedItemsInEnrolment, enrolmentID)
-	var completed_count int64
-	err := row.Scan(&completed_count)
-	return completed_count, err
+type AddRankPositionsParams struct {
+	RunID     uuid.UUID `json:"run_id"`
+	TrackerID uuid.UUID `json:"tracker_id"`
+	Keywords  []string  `json:"keywords"`
+	Domains   []string  `json:"domains"`
+	Positions []int32   `json:"positions"`
+	Urls      []string  `json:"urls"`
+	Sources   []string  `json:"sources"`
 }
 
-const countH5PContentByOrg = `-- name: CountH5PContentByOrg :one
-SELECT count(*) FROM h5p_content WHERE org_id = $1 AND deleted_at IS NULL
-`
-
-func (q *Queries) CountH5PContentByOrg(ctx context.Context, orgID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countH5PContentByOrg, orgID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+func (q *Queries) AddRankPositions(ctx context.Context, arg AddRankPositionsParams) error {
+	_, err := q.db.ExecContext(ctx, addRankPositions,
+		arg.RunID,
+		arg.TrackerID,
+		pq.Array(arg.Keywords),
+		pq.Array(arg.Domains),
+		pq.Array(arg.Positions),
+		pq.Array(arg.Urls),
+		pq.Array(arg.Sources),
+	)
+	return err
 }
 
-const countH5PLibraries = `-- name: CountH5PLibraries :one
-SELECT count(*) FROM h5p_libraries
+const addRankTrackerKeywords = `-- name: AddRankTrackerKeywords :execrows
+INSERT INTO rank_tracker_keywords (tracker_id, keyword)
+SELECT $1::uuid, unnest($2::text[])
+ON CONFLICT DO NOTHING
 `
 
-func (q *Queries) CountH5PLibraries(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countH5PLibraries)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type AddRankTrackerKeywordsParams struct {
+	TrackerID uuid.UUID `json:"tracker_id"`
+	Keywords  []string  `json:"keywords"`
 }
 
-const createH5PContent = `-- name: CreateH5PContent :one
+func (q *Queries) AddRankTrackerKeywords(ctx context.Context, arg AddRankTrackerKeywordsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, addRankTrackerKeywords, arg.TrackerID, pq.Array(arg.Keywords))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
 
-INSERT INTO h5p_content (id, org_id, library_id, created_by, title, slug,
-    description, content_json, tags, folder_path, storage_path, status)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-RETURNING id, created_at, updated_at, org_id, library_id, created_by, title, slug, description, content_json, tags, folder_path, storage_path, status, deleted_at
+const addSeoKeywords = `-- name: AddSeoKeywords :exec
+INSERT INTO seo_keywords (list_id, keyword)
+SELECT $1::uuid, unnest($2::text[])
+ON CONFLICT DO NOTHING
 `
 
-type CreateH5PContentParams struct {
-	ID          uuid.UUID       `json:"id"`
-	OrgID       uuid.UUID       `json:"org_id"`
-	LibraryID   uuid.UUID       `json:"library_id"`
-	CreatedBy   uuid.NullUUID   `json:"created_by"`
-	Title       string          `json:"title"`
-	Slug        string          `json:"slug"`
-	Description string          `json:"description"`
-	ContentJson json.RawMessage `json:"content_json"`
-	Tags        []string        `json:"tags"`
-	FolderPath  sql.NullString  `json:"folder_path"`
-	StoragePath sql.NullString  `json:"storage_path"`
-	Status      string          `json:"status"`
+type AddSeoKeywordsParams struct {
+	ListID   uuid.UUID `json:"list_id"`
+	Keywords []string  `json:"keywords"`
 }
 
-// =============================================================================
-// H5P Content (Organisation-scoped)
-// =============================================================================
-func (q *Queries) CreateH5PContent(ctx context.Context, arg CreateH5PContentParams) (H5pContent, error) {
-	row := q.db.QueryRowContext(ctx, createH5PContent,
-		arg.ID,
-		arg.OrgID,
-		arg.LibraryID,
-		arg.CreatedBy,
-		arg.Title,
-		arg.Slug,
-		arg.Description,
-		arg.ContentJson,
-		pq.Array(arg.Tags),
-		arg.FolderPath,
-		arg.StoragePath,
-		arg.Status,
-	)
-	var i H5pContent
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OrgID,
-		&i.LibraryID,
-		&i.CreatedBy,
-		&i.Title,
-		&i.Slug,
-		&i.Description,
-		&i.ContentJson,
-		pq.Array(&i.Tags),
-		&i.FolderPath,
-		&i.StoragePath,
-		&i.Status,
-		&i.DeletedAt,
-	)
-	return i, err
+func (q *Queries) AddSeoKeywords(ctx context.Context, arg AddSeoKeywordsParams) error {
+	_, err := q.db.ExecContext(ctx, addSeoKeywords, arg.ListID, pq.Array(arg.Keywords))
+	return err
 }
 
-const deleteContentUserState = `-- name: DeleteContentUserState :exec
-DELETE FROM h5p_content_user_state
-WHERE user_id = $1 AND content_id = $2 AND sub_content_id = $3 AND data_type = $4
+const anonymizeUserEnrolments = `-- name: AnonymizeUserEnrolments :execrows
+UPDATE enrolments
+SET user_id = NULL, actor_id = $1::uuid, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2::uuid
 `
 
-type DeleteContentUserStateParams struct {
-	UserID       uuid.UUID `json:"user_id"`
-	ContentID    uuid.UUID `json:"content_id"`
-	SubContentID string    `json:"sub_content_id"`
-	DataType     string    `json:"data_type"`
+type AnonymizeUserEnrolmentsParams struct {
+	ActorID uuid.UUID `json:"actor_id"`
+	UserID  uuid.UUID `json:"user_id"`
 }
 
-func (q *Queries) DeleteContentUserState(ctx context.Context, arg DeleteContentUserStateParams) error {
-	_, err := q.db.ExecContext(ctx, deleteContentUserState,
-		arg.UserID,
-		arg.ContentID,
-		arg.SubContentID,
-		arg.DataType,
-	)
-	return err
+func (q *Queries) AnonymizeUserEnrolments(ctx context.Context, arg AnonymizeUserEnrolmentsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeUserEnrolments, arg.ActorID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const deleteExpiredH5PHubCache = `-- name: DeleteExpiredH5PHubCache :exec
-DELETE FROM h5p_hub_cache WHERE expires_at < CURRENT_TIMESTAMP
+const anonymizeUserProgressRecords = `-- name: AnonymizeUserProgressRecords :execrows
+UPDATE progress_records
+SET user_id = NULL, actor_id = $1::uuid, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2::uuid
 `
 
-func (q *Queries) DeleteExpiredH5PHubCache(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, deleteExpiredH5PHubCache)
-	return err
+type AnonymizeUserProgressRecordsParams struct {
+	ActorID uuid.UUID `json:"actor_id"`
+	UserID  uuid.UUID `json:"user_id"`
 }
 
-const deleteH5PLibrary = `-- name: DeleteH5PLibrary :exec
-DELETE FROM h5p_libraries WHERE id = $1
-`
-
-func (q *Queries) DeleteH5PLibrary(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteH5PLibrary, id)
-	return err
+func (q *Queries) AnonymizeUserProgressRecords(ctx context.Context, arg AnonymizeUserProgressRecordsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeUserProgressRecords, arg.ActorID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const deleteH5PLibraryByMachineName = `-- name: DeleteH5PLibraryByMachineName :exec
-DELETE FROM h5p_libraries WHERE machine_name = $1
+const anonymizeUserXapiStatements = `-- name: AnonymizeUserXapiStatements :execrows
+
+UPDATE xapi_statements
+SET user_id = NULL,
+    actor_id = $1::uuid,
+    statement = jsonb_set(
+        CASE WHEN jsonb_typeof(statement) = 'object'
+            THEN statement #- '{authority}' #- '{context,instructor}' #- '{context,team}' #- '{result,response}'
+            ELSE '{}'::jsonb
+        END,
+        '{actor}', $2::jsonb)
+WHERE user_id = $3::uuid
 `
 
-func (q *Queries) DeleteH5PLibraryByMachineName(ctx context.Context, machineName string) error {
-	_, err := q.db.ExecContext(ctx, deleteH5PLibraryByMachineName, machineName)
-	return err
+type AnonymizeUserXapiStatementsParams struct {
+	ActorID uuid.UUID       `json:"actor_id"`
+	Actor   json.RawMessage `json:"actor"`
+	UserID  uuid.UUID       `json:"user_id"`
 }
 
-const deleteH5PLibraryDependencies = `-- name: DeleteH5PLibraryDependencies :exec
-DELETE FROM h5p_library_dependencies WHERE library_id = $1
-`
-
-func (q *Queries) DeleteH5PLibraryDependencies(ctx context.Context, libraryID uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteH5PLibraryDependencies, libraryID)
-	return err
+// =============================================================================
+// Analytics Anonymization
+// =============================================================================
+func (q *Queries) AnonymizeUserXapiStatements(ctx context.Context, arg AnonymizeUserXapiStatementsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeUserXapiStatements, arg.ActorID, arg.Actor, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const deleteH5POrgLibrary = `-- name: DeleteH5POrgLibrary :exec
-DELETE FROM h5p_org_libraries WHERE org_id = $1 AND library_id = $2
+const archiveStaleDraftH5PContent = `-- name: ArchiveStaleDraftH5PContent :execrows
+UPDATE h5p_content SET status = 'archived'
+WHERE org_id = $1 AND status = 'draft' AND deleted_at IS NULL AND updated_at < $2
 `
 
-type DeleteH5POrgLibraryParams struct {
+type ArchiveStaleDraftH5PContentParams struct {
 	OrgID     uuid.UUID `json:"org_id"`
-	LibraryID uuid.UUID `json:"library_id"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
-func (q *Queries) DeleteH5POrgLibrary(ctx context.Context, arg DeleteH5POrgLibraryParams) error {
-	_, err := q.db.ExecContext(ctx, deleteH5POrgLibrary, arg.OrgID, arg.LibraryID)
-	return err
+func (q *Queries) ArchiveStaleDraftH5PContent(ctx context.Context, arg ArchiveStaleDraftH5PContentParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, archiveStaleDraftH5PContent, arg.OrgID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const deleteTokens = `-- name: DeleteTokens :exec
-delete from tokens where expires < current_timestamp
+const cancelPendingDeletions = `-- name: CancelPendingDeletions :exec
+DELETE FROM pending_deletions WHERE storage_key = ANY($1::text[])
 `
 
-func (q *Queries) DeleteTokens(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, deleteTokens)
+func (q *Queries) CancelPendingDeletions(ctx context.Context, storageKeys []string) error {
+	_, err := q.db.ExecContext(ctx, cancelPendingDeletions, pq.Array(storageKeys))
 	return err
 }
 
-const disableH5POrgLibrary = `-- name: DisableH5POrgLibrary :exec
-UPDATE h5p_org_libraries SET enabled = false
-WHERE org_id = $1 AND library_id = $2
+const checkUserOrgMembership = `-- name: CheckUserOrgMembership :one
+SELECT m.id FROM organisation_memberships m
+JOIN organisations o ON o.id = m.organisation_id
+WHERE m.user_id = $1 AND m.organisation_id = $2 AND m.status = 'active' AND o.deleted_at IS NULL
+LIMIT 1
 `
 
-type DisableH5POrgLibraryParams struct {
-	OrgID     uuid.UUID `json:"org_id"`
-	LibraryID uuid.UUID `json:"library_id"`
-}
-
-func (q *Queries) DisableH5POrgLibrary(ctx context.Context, arg DisableH5POrgLibraryParams) error {
-	_, err := q.db.ExecContext(ctx, disableH5POrgLibrary, arg.OrgID, arg.LibraryID)
-	return err
+type CheckUserOrgMembershipParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
 }
 
-const downgradeOrganisationToFree = `-- name: DowngradeOrganisationToFree :exec
-UPDATE organisations
-SET
-    subscription_tier = 'free',
-    subscription_id = '',
-    subscription_end = NULL,
-    updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-`
-
-func (q *Queries) DowngradeOrganisationToFree(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, downgradeOrganisationToFree, id)
-	return err
+func (q *Queries) CheckUserOrgMembership(ctx context.Context, arg CheckUserOrgMembershipParams) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, checkUserOrgMembership, arg.UserID, arg.OrganisationID)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
 }
 
-const enableH5POrgLibrary = `-- name: EnableH5POrgLibrary :exec
-INSERT INTO h5p_org_libraries (id, org_id, library_id, enabled)
-VALUES ($1, $2, $3, true)
-ON CONFLICT (org_id, library_id)
-DO UPDATE SET enabled = true
+const claimCompetitorPin = `-- name: ClaimCompetitorPin :execrows
+UPDATE competitor_pins
+SET next_refresh_at = $2
+WHERE id = $1 AND next_refresh_at <= now()
 `
 
-type EnableH5POrgLibraryParams struct {
-	ID        uuid.UUID `json:"id"`
-	OrgID     uuid.UUID `json:"org_id"`
-	LibraryID uuid.UUID `json:"library_id"`
+type ClaimCompetitorPinParams struct {
+	ID            uuid.UUID `json:"id"`
+	NextRefreshAt time.Time `json:"next_refresh_at"`
 }
 
-func (q *Queries) EnableH5POrgLibrary(ctx context.Context, arg EnableH5POrgLibraryParams) error {
-	_, err := q.db.ExecContext(ctx, enableH5POrgLibrary, arg.ID, arg.OrgID, arg.LibraryID)
-	return err
+func (q *Queries) ClaimCompetitorPin(ctx context.Context, arg ClaimCompetitorPinParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimCompetitorPin, arg.ID, arg.NextRefreshAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const getContentUserState = `-- name: GetContentUserState :one
+const claimDuePendingDeletions = `-- name: ClaimDuePendingDeletions :many
 
-SELECT id, user_id, content_id, sub_content_id, data_type, data, preload, updated_at FROM h5p_content_user_state
-WHERE user_id = $1 AND content_id = $2 AND sub_content_id = $3 AND data_type = $4
+UPDATE pending_deletions
+SET next_attempt_at = $2
+WHERE id IN (
+    SELECT id FROM pending_deletions
+    WHERE next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, storage_key, reason, attempts, last_error, next_attempt_at, created_at
 `
 
-type GetContentUserStateParams struct {
-	UserID       uuid.UUID `json:"user_id"`
-	ContentID    uuid.UUID `json:"content_id"`
-	SubContentID string    `json:"sub_content_id"`
-	DataType     string    `json:"data_type"`
+type ClaimDuePendingDeletionsParams struct {
+	Limit         int32     `json:"limit"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
 }
 
 // =============================================================================
-// H5P Content User State (Save/Resume)
+// Pending Deletions
 // =============================================================================
-func (q *Queries) GetContentUserState(ctx context.Context, arg GetContentUserStateParams) (H5pContentUserState, error) {
-	row := q.db.QueryRowContext(ctx, getContentUserState,
-		arg.UserID,
-		arg.ContentID,
-		arg.SubContentID,
-		arg.DataType,
-	)
-	var i H5pContentUserState
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.ContentID,
-		&i.SubContentID,
-		&i.DataType,
-		&i.Data,
-		&i.Preload,
-		&i.UpdatedAt,
-	)
-	return i, err
-}
-
-const getContentUserStatesForContent = `-- name: GetContentUserStatesForContent :many
-SELECT id, user_id, content_id, sub_content_id, data_type, data, preload, updated_at FROM h5p_content_user_state
-WHERE user_id = $1 AND content_id = $2 AND preload = true
-`
-
-type GetContentUserStatesForContentParams struct {
-	UserID    uuid.UUID `json:"user_id"`
-	ContentID uuid.UUID `json:"content_id"`
-}
-
-func (q *Queries) GetContentUserStatesForContent(ctx context.Context, arg GetContentUserStatesForContentParams) ([]H5pContentUserState, error) {
-	rows, err := q.db.QueryContext(ctx, getContentUserStatesForContent, arg.UserID, arg.ContentID)
+func (q *Queries) ClaimDuePendingDeletions(ctx context.Context, arg ClaimDuePendingDeletionsParams) ([]PendingDeletion, error) {
+	rows, err := q.db.QueryContext(ctx, claimDuePendingDeletions, arg.Limit, arg.NextAttemptAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []H5pContentUserState
+	var items []PendingDeletion
 	for rows.Next() {
-		var i H5pContentUserState
+		var i PendingDeletion
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
-			&i.ContentID,
-			&i.SubContentID,
-			&i.DataType,
-			&i.Data,
-			&i.Preload,
-			&i.UpdatedAt,
+			&i.StorageKey,
+			&i.Reason,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -372,44 +326,48 @@ func (q *Queries) GetContentUserStatesForContent(ctx context.Context, arg GetCon
 	return items, nil
 }
 
-const getEnrolmentsByUserAndContentId = `-- name: GetEnrolmentsByUserAndContentId :many
-SELECT e.id, e.org_id, e.course_id, e.user_id, e.status
-FROM enrolments e
-JOIN course_items ci ON ci.course_id = e.course_id
-WHERE e.user_id = $1
-  AND ci.content_id = $2
-  AND e.status = 'active'
-  AND ci.removed_at IS NULL
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = $2
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, webhook_id, organisation_id, event_id, event_type, payload, status, attempts, response_status, error, created_at, last_attempt_at, next_attempt_at, delivered_at
 `
 
-type GetEnrolmentsByUserAndContentIdParams struct {
-	UserID    uuid.UUID     `json:"user_id"`
-	ContentID uuid.NullUUID `json:"content_id"`
-}
-
-type GetEnrolmentsByUserAndContentIdRow struct {
-	ID       uuid.UUID `json:"id"`
-	OrgID    uuid.UUID `json:"org_id"`
-	CourseID uuid.UUID `json:"course_id"`
-	UserID   uuid.UUID `json:"user_id"`
-	Status   string    `json:"status"`
+type ClaimDueWebhookDeliveriesParams struct {
+	Limit         int32     `json:"limit"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
 }
 
-func (q *Queries) GetEnrolmentsByUserAndContentId(ctx context.Context, arg GetEnrolmentsByUserAndContentIdParams) ([]GetEnrolmentsByUserAndContentIdRow, error) {
-	rows, err := q.db.QueryContext(ctx, getEnrolmentsByUserAndContentId, arg.UserID, arg.ContentID)
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueWebhookDeliveries, arg.Limit, arg.NextAttemptAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetEnrolmentsByUserAndContentIdRow
+	var items []WebhookDelivery
 	for rows.Next() {
-		var i GetEnrolmentsByUserAndContentIdRow
+		var i WebhookDelivery
 		if err := rows.Scan(
 			&i.ID,
-			&i.OrgID,
-			&i.CourseID,
-			&i.UserID,
+			&i.WebhookID,
+			&i.OrganisationID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
 			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.Error,
+			&i.CreatedAt,
+			&i.LastAttemptAt,
+			&i.NextAttemptAt,
+			&i.DeliveredAt,
 		); err != nil {
 			return nil, err
 		}
@@ -424,272 +382,6724 @@ func (q *Queries) GetEnrolmentsByUserAndContentId(ctx context.Context, arg GetEn
 	return items, nil
 }
 
-const getH5PContent = `-- name: GetH5PContent :one
-SELECT id, created_at, updated_at, org_id, library_id, created_by, title, slug, description, content_json, tags, folder_path, storage_path, status, deleted_at FROM h5p_content WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+const claimRankTracker = `-- name: ClaimRankTracker :execrows
+UPDATE rank_trackers
+SET last_run_at = now(), next_run_at = $2
+WHERE id = $1 AND next_run_at <= now()
 `
 
-type GetH5PContentParams struct {
-	ID    uuid.UUID `json:"id"`
-	OrgID uuid.UUID `json:"org_id"`
+type ClaimRankTrackerParams struct {
+	ID        uuid.UUID `json:"id"`
+	NextRunAt time.Time `json:"next_run_at"`
 }
 
-func (q *Queries) GetH5PContent(ctx context.Context, arg GetH5PContentParams) (H5pContent, error) {
-	row := q.db.QueryRowContext(ctx, getH5PContent, arg.ID, arg.OrgID)
-	var i H5pContent
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OrgID,
-		&i.LibraryID,
-		&i.CreatedBy,
-		&i.Title,
-		&i.Slug,
-		&i.Description,
-		&i.ContentJson,
-		pq.Array(&i.Tags),
-		&i.FolderPath,
-		&i.StoragePath,
-		&i.Status,
-		&i.DeletedAt,
-	)
-	return i, err
+func (q *Queries) ClaimRankTracker(ctx context.Context, arg ClaimRankTrackerParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimRankTracker, arg.ID, arg.NextRunAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const getH5PContentOrgId = `-- name: GetH5PContentOrgId :one
-SELECT id, org_id FROM h5p_content WHERE id = $1 AND deleted_at IS NULL
+const claimSeoSchedule = `-- name: ClaimSeoSchedule :execrows
+UPDATE seo_schedules
+SET last_run_at = now(), next_run_at = $2
+WHERE id = $1 AND next_run_at <= now()
 `
 
-type GetH5PContentOrgIdRow struct {
-	ID    uuid.UUID `json:"id"`
-	OrgID uuid.UUID `json:"org_id"`
+type ClaimSeoScheduleParams struct {
+	ID        uuid.UUID `json:"id"`
+	NextRunAt time.Time `json:"next_run_at"`
 }
 
-func (q *Queries) GetH5PContentOrgId(ctx context.Context, id uuid.UUID) (GetH5PContentOrgIdRow, error) {
-	row := q.db.QueryRowContext(ctx, getH5PContentOrgId, id)
-	var i GetH5PContentOrgIdRow
-	err := row.Scan(&i.ID, &i.OrgID)
-	return i, err
+func (q *Queries) ClaimSeoSchedule(ctx context.Context, arg ClaimSeoScheduleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimSeoSchedule, arg.ID, arg.NextRunAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const getH5PHubCache = `-- name: GetH5PHubCache :one
+const clearDefaultH5PInstallProfile = `-- name: ClearDefaultH5PInstallProfile :exec
+UPDATE h5p_install_profiles SET is_default = false, updated_at = now()
+WHERE is_default AND id <> $1
+`
 
-SELECT id, created_at, cache_key, data, expires_at FROM h5p_hub_cache
-WHERE cache_key = $1 AND expires_at > CURRENT_TIMESTAMP
+func (q *Queries) ClearDefaultH5PInstallProfile(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clearDefaultH5PInstallProfile, id)
+	return err
+}
+
+const completeAccessReview = `-- name: CompleteAccessReview :exec
+UPDATE access_reviews
+SET status = 'ready', completed_at = now(), expires_at = $1::timestamptz
+WHERE id = $2
 `
 
-// =============================================================================
-// H5P Hub Cache
-// =============================================================================
-func (q *Queries) GetH5PHubCache(ctx context.Context, cacheKey string) (H5pHubCache, error) {
-	row := q.db.QueryRowContext(ctx, getH5PHubCache, cacheKey)
-	var i H5pHubCache
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.CacheKey,
-		&i.Data,
-		&i.ExpiresAt,
-	)
-	return i, err
+type CompleteAccessReviewParams struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	ID        uuid.UUID `json:"id"`
 }
 
-const getH5PLibrary = `-- name: GetH5PLibrary :one
+func (q *Queries) CompleteAccessReview(ctx context.Context, arg CompleteAccessReviewParams) error {
+	_, err := q.db.ExecContext(ctx, completeAccessReview, arg.ExpiresAt, arg.ID)
+	return err
+}
 
-SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries WHERE id = $1
+const completeEnrolment = `-- name: CompleteEnrolment :exec
+UPDATE enrolments
+SET status = 'completed', completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
 `
 
-// =============================================================================
-// H5P Libraries (Platform-wide)
-// =============================================================================
-func (q *Queries) GetH5PLibrary(ctx context.Context, id uuid.UUID) (H5pLibrary, error) {
-	row := q.db.QueryRowContext(ctx, getH5PLibrary, id)
-	var i H5pLibrary
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.MachineName,
-		&i.MajorVersion,
-		&i.MinorVersion,
-		&i.PatchVersion,
-		&i.Title,
-		&i.Origin,
-		&i.MetadataJson,
-		pq.Array(&i.Categories),
-		pq.Array(&i.Keywords),
-		pq.Array(&i.Screenshots),
-		&i.Description,
-		&i.IconPath,
-		&i.PackagePath,
-		&i.ExtractedPath,
-		&i.Runnable,
-		&i.Restricted,
+func (q *Queries) CompleteEnrolment(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, completeEnrolment, id)
+	return err
+}
+
+const completeRankTrackerRun = `-- name: CompleteRankTrackerRun :exec
+UPDATE rank_tracker_runs
+SET status = $2, failed_count = $3, cost = $4, share_of_voice = $5, error = $6, completed_at = now()
+WHERE id = $1
+`
+
+type CompleteRankTrackerRunParams struct {
+	ID           uuid.UUID       `json:"id"`
+	Status       string          `json:"status"`
+	FailedCount  int32           `json:"failed_count"`
+	Cost         float64         `json:"cost"`
+	ShareOfVoice json.RawMessage `json:"share_of_voice"`
+	Error        string          `json:"error"`
+}
+
+func (q *Queries) CompleteRankTrackerRun(ctx context.Context, arg CompleteRankTrackerRunParams) error {
+	_, err := q.db.ExecContext(ctx, completeRankTrackerRun,
+		arg.ID,
+		arg.Status,
+		arg.FailedCount,
+		arg.Cost,
+		arg.ShareOfVoice,
+		arg.Error,
 	)
-	return i, err
+	return err
 }
 
-const getH5PLibraryByMachineName = `-- name: GetH5PLibraryByMachineName :one
-SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries
-WHERE machine_name = $1
-ORDER BY major_version DESC, minor_version DESC, patch_version DESC
-LIMIT 1
+const completeSeoPageExperience = `-- name: CompleteSeoPageExperience :exec
+UPDATE seo_page_experience
+SET status = $2, error = $3, score = $4, passed = $5, cwv_source = $6,
+    lcp_ms = $7, inp_ms = $8, cls = $9, signals = $10, pagespeed = $11, issues = $12, completed_at = now()
+WHERE id = $1 AND status = 'pending'
 `
 
-func (q *Queries) GetH5PLibraryByMachineName(ctx context.Context, machineName string) (H5pLibrary, error) {
-	row := q.db.QueryRowContext(ctx, getH5PLibraryByMachineName, machineName)
-	var i H5pLibrary
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.MachineName,
-		&i.MajorVersion,
-		&i.MinorVersion,
-		&i.PatchVersion,
-		&i.Title,
-		&i.Origin,
-		&i.MetadataJson,
-		pq.Array(&i.Categories),
-		pq.Array(&i.Keywords),
-		pq.Array(&i.Screenshots),
-		&i.Description,
-		&i.IconPath,
-		&i.PackagePath,
-		&i.ExtractedPath,
-		&i.Runnable,
-		&i.Restricted,
+type CompleteSeoPageExperienceParams struct {
+	ID        uuid.UUID       `json:"id"`
+	Status    string          `json:"status"`
+	Error     string          `json:"error"`
+	Score     int32           `json:"score"`
+	Passed    bool            `json:"passed"`
+	CwvSource string          `json:"cwv_source"`
+	LcpMs     sql.NullFloat64 `json:"lcp_ms"`
+	InpMs     sql.NullFloat64 `json:"inp_ms"`
+	Cls       sql.NullFloat64 `json:"cls"`
+	Signals   json.RawMessage `json:"signals"`
+	Pagespeed json.RawMessage `json:"pagespeed"`
+	Issues    json.RawMessage `json:"issues"`
+}
+
+func (q *Queries) CompleteSeoPageExperience(ctx context.Context, arg CompleteSeoPageExperienceParams) error {
+	_, err := q.db.ExecContext(ctx, completeSeoPageExperience,
+		arg.ID,
+		arg.Status,
+		arg.Error,
+		arg.Score,
+		arg.Passed,
+		arg.CwvSource,
+		arg.LcpMs,
+		arg.InpMs,
+		arg.Cls,
+		arg.Signals,
+		arg.Pagespeed,
+		arg.Issues,
 	)
-	return i, err
+	return err
 }
 
-const getH5PLibraryByMachineNameMajorMinor = `-- name: GetH5PLibraryByMachineNameMajorMinor :one
-SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries
-WHERE machine_name = $1
-  AND major_version = $2
-  AND minor_version = $3
-ORDER BY patch_version DESC
-LIMIT 1
+const completeSeoRankCheck = `-- name: CompleteSeoRankCheck :exec
+UPDATE seo_rank_checks
+SET status = $2, results = $3, cost = $4, error = $5, completed_at = now()
+WHERE id = $1 AND status = 'pending'
 `
 
-type GetH5PLibraryByMachineNameMajorMinorParams struct {
-	MachineName  string `json:"machine_name"`
-	MajorVersion int32  `json:"major_version"`
-	MinorVersion int32  `json:"minor_version"`
+type CompleteSeoRankCheckParams struct {
+	ID      uuid.UUID       `json:"id"`
+	Status  string          `json:"status"`
+	Results json.RawMessage `json:"results"`
+	Cost    float64         `json:"cost"`
+	Error   string          `json:"error"`
 }
 
-func (q *Queries) GetH5PLibraryByMachineNameMajorMinor(ctx context.Context, arg GetH5PLibraryByMachineNameMajorMinorParams) (H5pLibrary, error) {
-	row := q.db.QueryRowContext(ctx, getH5PLibraryByMachineNameMajorMinor, arg.MachineName, arg.MajorVersion, arg.MinorVersion)
-	var i H5pLibrary
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.MachineName,
-		&i.MajorVersion,
-		&i.MinorVersion,
-		&i.PatchVersion,
-		&i.Title,
-		&i.Origin,
-		&i.MetadataJson,
-		pq.Array(&i.Categories),
-		pq.Array(&i.Keywords),
-		pq.Array(&i.Screenshots),
-		&i.Description,
-		&i.IconPath,
-		&i.PackagePath,
-		&i.ExtractedPath,
-		&i.Runnable,
-		&i.Restricted,
+func (q *Queries) CompleteSeoRankCheck(ctx context.Context, arg CompleteSeoRankCheckParams) error {
+	_, err := q.db.ExecContext(ctx, completeSeoRankCheck,
+		arg.ID,
+		arg.Status,
+		arg.Results,
+		arg.Cost,
+		arg.Error,
 	)
-	return i, err
+	return err
 }
 
-const getH5PLibraryByMachineNameVersion = `-- name: GetH5PLibraryByMachineNameVersion :one
-SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries
-WHERE machine_name = $1
-  AND major_version = $2
-  AND minor_version = $3
-  AND patch_version = $4
+const completeSeoScheduleRun = `-- name: CompleteSeoScheduleRun :exec
+UPDATE seo_schedule_runs
+SET status = $2, summary = $3, deltas = $4, error = $5, completed_at = now()
+WHERE id = $1
 `
 
-type GetH5PLibraryByMachineNameVersionParams struct {
-	MachineName  string `json:"machine_name"`
-	MajorVersion int32  `json:"major_version"`
-	MinorVersion int32  `json:"minor_version"`
-	PatchVersion int32  `json:"patch_version"`
+type CompleteSeoScheduleRunParams struct {
+	ID      uuid.UUID       `json:"id"`
+	Status  string          `json:"status"`
+	Summary json.RawMessage `json:"summary"`
+	Deltas  json.RawMessage `json:"deltas"`
+	Error   string          `json:"error"`
 }
 
-func (q *Queries) GetH5PLibraryByMachineNameVersion(ctx context.Context, arg GetH5PLibraryByMachineNameVersionParams) (H5pLibrary, error) {
-	row := q.db.QueryRowContext(ctx, getH5PLibraryByMachineNameVersion,
-		arg.MachineName,
-		arg.MajorVersion,
-		arg.MinorVersion,
-		arg.PatchVersion,
+func (q *Queries) CompleteSeoScheduleRun(ctx context.Context, arg CompleteSeoScheduleRunParams) error {
+	_, err := q.db.ExecContext(ctx, completeSeoScheduleRun,
+		arg.ID,
+		arg.Status,
+		arg.Summary,
+		arg.Deltas,
+		arg.Error,
 	)
-	var i H5pLibrary
-	err := row.Scan(
+	return err
+}
+
+const countActiveItemsInCourse = `-- name: CountActiveItemsInCourse :one
+SELECT COUNT(*) as active_count
+FROM course_items ci
+WHERE ci.course_id = $1 AND ci.removed_at IS NULL
+`
+
+func (q *Queries) CountActiveItemsInCourse(ctx context.Context, courseID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveItemsInCourse, courseID)
+	var active_count int64
+	err := row.Scan(&active_count)
+	return active_count, err
+}
+
+const countCompetitorKeywordGaps = `-- name: CountCompetitorKeywordGaps :many
+SELECT g.pin_id, count(*) AS gaps,
+    count(*) FILTER (WHERE g.is_new) AS new_gaps
+FROM competitor_keyword_gaps g
+JOIN competitor_pins p ON p.id = g.pin_id
+WHERE p.organisation_id = $1
+GROUP BY g.pin_id
+`
+
+type CountCompetitorKeywordGapsRow struct {
+	PinID   uuid.UUID `json:"pin_id"`
+	Gaps    int64     `json:"gaps"`
+	NewGaps int64     `json:"new_gaps"`
+}
+
+func (q *Queries) CountCompetitorKeywordGaps(ctx context.Context, organisationID uuid.UUID) ([]CountCompetitorKeywordGapsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countCompetitorKeywordGaps, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountCompetitorKeywordGapsRow
+	for rows.Next() {
+		var i CountCompetitorKeywordGapsRow
+		if err := rows.Scan(&i.PinID, &i.Gaps, &i.NewGaps); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countCompletedItemsInEnrolment = `-- name: CountCompletedItemsInEnrolment :one
+SELECT COUNT(*) as completed_count
+FROM progress_records pr
+WHERE pr.enrolment_id = $1 AND pr.completed = true
+`
+
+func (q *Queries) CountCompletedItemsInEnrolment(ctx context.Context, enrolmentID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCompletedItemsInEnrolment, enrolmentID)
+	var completed_count int64
+	err := row.Scan(&completed_count)
+	return completed_count, err
+}
+
+const countCourseGradebookLearners = `-- name: CountCourseGradebookLearners :one
+SELECT count(*) FROM enrolments
+WHERE org_id = $1 AND course_id = $2 AND status <> 'withdrawn'
+`
+
+type CountCourseGradebookLearnersParams struct {
+	OrgID    uuid.UUID `json:"org_id"`
+	CourseID uuid.UUID `json:"course_id"`
+}
+
+func (q *Queries) CountCourseGradebookLearners(ctx context.Context, arg CountCourseGradebookLearnersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCourseGradebookLearners, arg.OrgID, arg.CourseID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countExpiredXapiStatements = `-- name: CountExpiredXapiStatements :one
+SELECT count(*) FROM xapi_statements
+WHERE org_id = $1 AND created_at < $2
+`
+
+type CountExpiredXapiStatementsParams struct {
+	OrgID     uuid.UUID `json:"org_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) CountExpiredXapiStatements(ctx context.Context, arg CountExpiredXapiStatementsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countExpiredXapiStatements, arg.OrgID, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countH5PContentByOrg = `-- name: CountH5PContentByOrg :one
+SELECT count(*) FROM h5p_content WHERE org_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountH5PContentByOrg(ctx context.Context, orgID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countH5PContentByOrg, orgID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countH5PLibraries = `-- name: CountH5PLibraries :one
+SELECT count(*) FROM h5p_libraries
+`
+
+func (q *Queries) CountH5PLibraries(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countH5PLibraries)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPurgeableH5PContent = `-- name: CountPurgeableH5PContent :one
+SELECT count(*) FROM h5p_content
+WHERE org_id = $1 AND deleted_at < $2
+`
+
+type CountPurgeableH5PContentParams struct {
+	OrgID     uuid.UUID    `json:"org_id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+}
+
+func (q *Queries) CountPurgeableH5PContent(ctx context.Context, arg CountPurgeableH5PContentParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPurgeableH5PContent, arg.OrgID, arg.DeletedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRankTrackerKeywordsByOrg = `-- name: CountRankTrackerKeywordsByOrg :one
+
+SELECT COUNT(*) FROM rank_tracker_keywords k
+JOIN rank_trackers t ON t.id = k.tracker_id
+WHERE t.organisation_id = $1
+`
+
+// =============================================================================
+// Rank Tracker
+// =============================================================================
+func (q *Queries) CountRankTrackerKeywordsByOrg(ctx context.Context, organisationID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRankTrackerKeywordsByOrg, organisationID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSeoBacklinkProspects = `-- name: CountSeoBacklinkProspects :one
+SELECT COUNT(*) FROM seo_backlink_prospects
+WHERE organisation_id = $1 AND target = $2
+`
+
+type CountSeoBacklinkProspectsParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Target         string    `json:"target"`
+}
+
+func (q *Queries) CountSeoBacklinkProspects(ctx context.Context, arg CountSeoBacklinkProspectsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSeoBacklinkProspects, arg.OrganisationID, arg.Target)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countStaleDraftH5PContent = `-- name: CountStaleDraftH5PContent :one
+SELECT count(*) FROM h5p_content
+WHERE org_id = $1 AND status = 'draft' AND deleted_at IS NULL AND updated_at < $2
+`
+
+type CountStaleDraftH5PContentParams struct {
+	OrgID     uuid.UUID `json:"org_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) CountStaleDraftH5PContent(ctx context.Context, arg CountStaleDraftH5PContentParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countStaleDraftH5PContent, arg.OrgID, arg.UpdatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUnreadUserNotifications = `-- name: CountUnreadUserNotifications :one
+SELECT COUNT(*) FROM user_notifications
+WHERE user_id = $1 AND organisation_id = $2 AND read_at IS NULL
+`
+
+type CountUnreadUserNotificationsParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) CountUnreadUserNotifications(ctx context.Context, arg CountUnreadUserNotificationsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnreadUserNotifications, arg.UserID, arg.OrganisationID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAccessReview = `-- name: CreateAccessReview :one
+
+INSERT INTO access_reviews (organisation_id, requested_by)
+VALUES ($1, $2)
+RETURNING id, organisation_id, requested_by, status, created_at, completed_at, expires_at, error
+`
+
+type CreateAccessReviewParams struct {
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	RequestedBy    uuid.NullUUID `json:"requested_by"`
+}
+
+// =============================================================================
+// Access Reviews
+// =============================================================================
+func (q *Queries) CreateAccessReview(ctx context.Context, arg CreateAccessReviewParams) (AccessReview, error) {
+	row := q.db.QueryRowContext(ctx, createAccessReview, arg.OrganisationID, arg.RequestedBy)
+	var i AccessReview
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.RequestedBy,
+		&i.Status,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ExpiresAt,
+		&i.Error,
+	)
+	return i, err
+}
+
+const createAccessReviewsForActiveOrganisations = `-- name: CreateAccessReviewsForActiveOrganisations :execrows
+INSERT INTO access_reviews (organisation_id, requested_by)
+SELECT o.id, $1::uuid
+FROM organisations o
+WHERE o.status = 'active' AND o.deleted_at IS NULL
+`
+
+func (q *Queries) CreateAccessReviewsForActiveOrganisations(ctx context.Context, requestedBy uuid.NullUUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createAccessReviewsForActiveOrganisations, requestedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createAnnouncement = `-- name: CreateAnnouncement :one
+
+INSERT INTO announcements (title, body, link_url, tiers, flags, published_at, expires_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at, updated_at, title, body, link_url, tiers, flags, published_at, expires_at, created_by
+`
+
+type CreateAnnouncementParams struct {
+	Title       string        `json:"title"`
+	Body        string        `json:"body"`
+	LinkUrl     string        `json:"link_url"`
+	Tiers       []string      `json:"tiers"`
+	Flags       []string      `json:"flags"`
+	PublishedAt sql.NullTime  `json:"published_at"`
+	ExpiresAt   sql.NullTime  `json:"expires_at"`
+	CreatedBy   uuid.NullUUID `json:"created_by"`
+}
+
+// =============================================================================
+// Announcements
+// =============================================================================
+func (q *Queries) CreateAnnouncement(ctx context.Context, arg CreateAnnouncementParams) (Announcement, error) {
+	row := q.db.QueryRowContext(ctx, createAnnouncement,
+		arg.Title,
+		arg.Body,
+		arg.LinkUrl,
+		pq.Array(arg.Tiers),
+		pq.Array(arg.Flags),
+		arg.PublishedAt,
+		arg.ExpiresAt,
+		arg.CreatedBy,
+	)
+	var i Announcement
+	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.MachineName,
-		&i.MajorVersion,
-		&i.MinorVersion,
-		&i.PatchVersion,
 		&i.Title,
-		&i.Origin,
-		&i.MetadataJson,
-		pq.Array(&i.Categories),
-		pq.Array(&i.Keywords),
-		pq.Array(&i.Screenshots),
-		&i.Description,
-		&i.IconPath,
-		&i.PackagePath,
-		&i.ExtractedPath,
-		&i.Runnable,
-		&i.Restricted,
+		&i.Body,
+		&i.LinkUrl,
+		pq.Array(&i.Tiers),
+		pq.Array(&i.Flags),
+		&i.PublishedAt,
+		&i.ExpiresAt,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const createCompetitorAlert = `-- name: CreateCompetitorAlert :exec
+INSERT INTO competitor_alerts (organisation_id, pin_id, kind, message, keywords, amount)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateCompetitorAlertParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	PinID          uuid.UUID `json:"pin_id"`
+	Kind           string    `json:"kind"`
+	Message        string    `json:"message"`
+	Keywords       []string  `json:"keywords"`
+	Amount         int32     `json:"amount"`
+}
+
+func (q *Queries) CreateCompetitorAlert(ctx context.Context, arg CreateCompetitorAlertParams) error {
+	_, err := q.db.ExecContext(ctx, createCompetitorAlert,
+		arg.OrganisationID,
+		arg.PinID,
+		arg.Kind,
+		arg.Message,
+		pq.Array(arg.Keywords),
+		arg.Amount,
+	)
+	return err
+}
+
+const createCompetitorPin = `-- name: CreateCompetitorPin :one
+
+INSERT INTO competitor_pins (organisation_id, target, domain, location_code, language_code, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (organisation_id, target, domain, location_code, language_code) DO NOTHING
+RETURNING id, organisation_id, target, domain, location_code, language_code, created_by, created_at, refreshed_at, next_refresh_at
+`
+
+type CreateCompetitorPinParams struct {
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	Target         string        `json:"target"`
+	Domain         string        `json:"domain"`
+	LocationCode   int32         `json:"location_code"`
+	LanguageCode   string        `json:"language_code"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+}
+
+// =============================================================================
+// Competitor Monitoring
+// =============================================================================
+func (q *Queries) CreateCompetitorPin(ctx context.Context, arg CreateCompetitorPinParams) (CompetitorPin, error) {
+	row := q.db.QueryRowContext(ctx, createCompetitorPin,
+		arg.OrganisationID,
+		arg.Target,
+		arg.Domain,
+		arg.LocationCode,
+		arg.LanguageCode,
+		arg.CreatedBy,
+	)
+	var i CompetitorPin
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Target,
+		&i.Domain,
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.RefreshedAt,
+		&i.NextRefreshAt,
 	)
 	return i, err
 }
 
-const getH5PLibraryDependencies = `-- name: GetH5PLibraryDependencies :many
-SELECT d.id, d.library_id, d.depends_on_id, d.dependency_type, l.machine_name, l.major_version, l.minor_version, l.patch_version, l.title
-FROM h5p_library_dependencies d
-JOIN h5p_libraries l ON l.id = d.depends_on_id
-WHERE d.library_id = $1
+const createCompetitorSnapshot = `-- name: CreateCompetitorSnapshot :exec
+INSERT INTO competitor_snapshots (organisation_id, target, domain, location_code, language_code, organic_etv, organic_keywords, top3_keywords, top10_keywords, domain_rank, backlinks, referring_domains)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+`
+
+type CreateCompetitorSnapshotParams struct {
+	OrganisationID   uuid.UUID       `json:"organisation_id"`
+	Target           string          `json:"target"`
+	Domain           string          `json:"domain"`
+	LocationCode     int32           `json:"location_code"`
+	LanguageCode     string          `json:"language_code"`
+	OrganicEtv       sql.NullFloat64 `json:"organic_etv"`
+	OrganicKeywords  sql.NullInt32   `json:"organic_keywords"`
+	Top3Keywords     sql.NullInt32   `json:"top3_keywords"`
+	Top10Keywords    sql.NullInt32   `json:"top10_keywords"`
+	DomainRank       sql.NullInt32   `json:"domain_rank"`
+	Backlinks        sql.NullInt64   `json:"backlinks"`
+	ReferringDomains sql.NullInt32   `json:"referring_domains"`
+}
+
+func (q *Queries) CreateCompetitorSnapshot(ctx context.Context, arg CreateCompetitorSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, createCompetitorSnapshot,
+		arg.OrganisationID,
+		arg.Target,
+		arg.Domain,
+		arg.LocationCode,
+		arg.LanguageCode,
+		arg.OrganicEtv,
+		arg.OrganicKeywords,
+		arg.Top3Keywords,
+		arg.Top10Keywords,
+		arg.DomainRank,
+		arg.Backlinks,
+		arg.ReferringDomains,
+	)
+	return err
+}
+
+const createH5PContent = `-- name: CreateH5PContent :one
+
+INSERT INTO h5p_content (id, org_id, library_id, created_by, title, slug,
+    description, content_json, tags, folder_path, storage_path, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, created_at, updated_at, org_id, library_id, created_by, title, slug, description, content_json, tags, folder_path, storage_path, status, deleted_at, locked_at, lock_reason
+`
+
+type CreateH5PContentParams struct {
+	ID          uuid.UUID       `json:"id"`
+	OrgID       uuid.UUID       `json:"org_id"`
+	LibraryID   uuid.UUID       `json:"library_id"`
+	CreatedBy   uuid.NullUUID   `json:"created_by"`
+	Title       string          `json:"title"`
+	Slug        string          `json:"slug"`
+	Description string          `json:"description"`
+	ContentJson json.RawMessage `json:"content_json"`
+	Tags        []string        `json:"tags"`
+	FolderPath  sql.NullString  `json:"folder_path"`
+	StoragePath sql.NullString  `json:"storage_path"`
+	Status      string          `json:"status"`
+}
+
+// =============================================================================
+// H5P Content (Organisation-scoped)
+// =============================================================================
+func (q *Queries) CreateH5PContent(ctx context.Context, arg CreateH5PContentParams) (H5pContent, error) {
+	row := q.db.QueryRowContext(ctx, createH5PContent,
+		arg.ID,
+		arg.OrgID,
+		arg.LibraryID,
+		arg.CreatedBy,
+		arg.Title,
+		arg.Slug,
+		arg.Description,
+		arg.ContentJson,
+		pq.Array(arg.Tags),
+		arg.FolderPath,
+		arg.StoragePath,
+		arg.Status,
+	)
+	var i H5pContent
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrgID,
+		&i.LibraryID,
+		&i.CreatedBy,
+		&i.Title,
+		&i.Slug,
+		&i.Description,
+		&i.ContentJson,
+		pq.Array(&i.Tags),
+		&i.FolderPath,
+		&i.StoragePath,
+		&i.Status,
+		&i.DeletedAt,
+		&i.LockedAt,
+		&i.LockReason,
+	)
+	return i, err
+}
+
+const createH5PInstallProfile = `-- name: CreateH5PInstallProfile :one
+INSERT INTO h5p_install_profiles (name, description, libraries, is_default, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, description, libraries, is_default, created_by, created_at, updated_at
+`
+
+type CreateH5PInstallProfileParams struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Libraries   []string      `json:"libraries"`
+	IsDefault   bool          `json:"is_default"`
+	CreatedBy   uuid.NullUUID `json:"created_by"`
+}
+
+func (q *Queries) CreateH5PInstallProfile(ctx context.Context, arg CreateH5PInstallProfileParams) (H5pInstallProfile, error) {
+	row := q.db.QueryRowContext(ctx, createH5PInstallProfile,
+		arg.Name,
+		arg.Description,
+		pq.Array(arg.Libraries),
+		arg.IsDefault,
+		arg.CreatedBy,
+	)
+	var i H5pInstallProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		pq.Array(&i.Libraries),
+		&i.IsDefault,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createH5PMediaAsset = `-- name: CreateH5PMediaAsset :one
+
+INSERT INTO h5p_media_assets (
+    id, org_id, uploaded_by, filename, mime_type, kind, size_bytes, width, height, checksum, storage_key, thumbnail_key
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (org_id, checksum) DO UPDATE SET
+    use_count = h5p_media_assets.use_count + 1,
+    last_used_at = NOW()
+RETURNING id, org_id, uploaded_by, filename, mime_type, kind, size_bytes, width, height, checksum, storage_key, thumbnail_key, use_count, created_at, last_used_at
+`
+
+type CreateH5PMediaAssetParams struct {
+	ID           uuid.UUID      `json:"id"`
+	OrgID        uuid.UUID      `json:"org_id"`
+	UploadedBy   uuid.NullUUID  `json:"uploaded_by"`
+	Filename     string         `json:"filename"`
+	MimeType     string         `json:"mime_type"`
+	Kind         string         `json:"kind"`
+	SizeBytes    int64          `json:"size_bytes"`
+	Width        sql.NullInt32  `json:"width"`
+	Height       sql.NullInt32  `json:"height"`
+	Checksum     string         `json:"checksum"`
+	StorageKey   string         `json:"storage_key"`
+	ThumbnailKey sql.NullString `json:"thumbnail_key"`
+}
+
+// =============================================================================
+// H5P Media Library
+// =============================================================================
+func (q *Queries) CreateH5PMediaAsset(ctx context.Context, arg CreateH5PMediaAssetParams) (H5pMediaAsset, error) {
+	row := q.db.QueryRowContext(ctx, createH5PMediaAsset,
+		arg.ID,
+		arg.OrgID,
+		arg.UploadedBy,
+		arg.Filename,
+		arg.MimeType,
+		arg.Kind,
+		arg.SizeBytes,
+		arg.Width,
+		arg.Height,
+		arg.Checksum,
+		arg.StorageKey,
+		arg.ThumbnailKey,
+	)
+	var i H5pMediaAsset
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.UploadedBy,
+		&i.Filename,
+		&i.MimeType,
+		&i.Kind,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.Checksum,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+		&i.UseCount,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const createH5PProfileApplication = `-- name: CreateH5PProfileApplication :one
+INSERT INTO h5p_profile_applications (organisation_id, profile_id, profile_name, libraries, requested_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, organisation_id, profile_id, profile_name, libraries, status, summary, requested_by, created_at, completed_at
+`
+
+type CreateH5PProfileApplicationParams struct {
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	ProfileID      uuid.NullUUID `json:"profile_id"`
+	ProfileName    string        `json:"profile_name"`
+	Libraries      []string      `json:"libraries"`
+	RequestedBy    uuid.NullUUID `json:"requested_by"`
+}
+
+func (q *Queries) CreateH5PProfileApplication(ctx context.Context, arg CreateH5PProfileApplicationParams) (H5pProfileApplication, error) {
+	row := q.db.QueryRowContext(ctx, createH5PProfileApplication,
+		arg.OrganisationID,
+		arg.ProfileID,
+		arg.ProfileName,
+		pq.Array(arg.Libraries),
+		arg.RequestedBy,
+	)
+	var i H5pProfileApplication
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.ProfileID,
+		&i.ProfileName,
+		pq.Array(&i.Libraries),
+		&i.Status,
+		&i.Summary,
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const createNotificationDigestItem = `-- name: CreateNotificationDigestItem :exec
+INSERT INTO notification_digest_items (user_id, organisation_id, category, event_type, summary)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateNotificationDigestItemParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Category       string    `json:"category"`
+	EventType      string    `json:"event_type"`
+	Summary        string    `json:"summary"`
+}
+
+func (q *Queries) CreateNotificationDigestItem(ctx context.Context, arg CreateNotificationDigestItemParams) error {
+	_, err := q.db.ExecContext(ctx, createNotificationDigestItem,
+		arg.UserID,
+		arg.OrganisationID,
+		arg.Category,
+		arg.EventType,
+		arg.Summary,
+	)
+	return err
+}
+
+const createOrganisationWebhook = `-- name: CreateOrganisationWebhook :one
+INSERT INTO organisation_webhooks (organisation_id, url, secret, events)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at, updated_at, organisation_id, url, secret, events, active, last_delivery_at, last_delivery_status
+`
+
+type CreateOrganisationWebhookParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Url            string    `json:"url"`
+	Secret         string    `json:"secret"`
+	Events         []string  `json:"events"`
+}
+
+func (q *Queries) CreateOrganisationWebhook(ctx context.Context, arg CreateOrganisationWebhookParams) (OrganisationWebhook, error) {
+	row := q.db.QueryRowContext(ctx, createOrganisationWebhook,
+		arg.OrganisationID,
+		arg.Url,
+		arg.Secret,
+		pq.Array(arg.Events),
+	)
+	var i OrganisationWebhook
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganisationID,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.Active,
+		&i.LastDeliveryAt,
+		&i.LastDeliveryStatus,
+	)
+	return i, err
+}
+
+const createRankTracker = `-- name: CreateRankTracker :one
+INSERT INTO rank_trackers (organisation_id, domain, location_code, language_code, device, competitors, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (organisation_id, domain, location_code, language_code, device) DO NOTHING
+RETURNING id, organisation_id, domain, location_code, language_code, device, competitors, created_by, created_at, last_run_at, next_run_at, volumes_refreshed_at
+`
+
+type CreateRankTrackerParams struct {
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	Domain         string        `json:"domain"`
+	LocationCode   int32         `json:"location_code"`
+	LanguageCode   string        `json:"language_code"`
+	Device         string        `json:"device"`
+	Competitors    []string      `json:"competitors"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+}
+
+func (q *Queries) CreateRankTracker(ctx context.Context, arg CreateRankTrackerParams) (RankTracker, error) {
+	row := q.db.QueryRowContext(ctx, createRankTracker,
+		arg.OrganisationID,
+		arg.Domain,
+		arg.LocationCode,
+		arg.LanguageCode,
+		arg.Device,
+		pq.Array(arg.Competitors),
+		arg.CreatedBy,
+	)
+	var i RankTracker
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Domain,
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.Device,
+		pq.Array(&i.Competitors),
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LastRunAt,
+		&i.NextRunAt,
+		&i.VolumesRefreshedAt,
+	)
+	return i, err
+}
+
+const createRankTrackerRun = `-- name: CreateRankTrackerRun :one
+INSERT INTO rank_tracker_runs (tracker_id, organisation_id, keyword_count)
+VALUES ($1, $2, $3)
+RETURNING id, tracker_id, organisation_id, created_at, completed_at, status, keyword_count, failed_count, cost, share_of_voice, error
+`
+
+type CreateRankTrackerRunParams struct {
+	TrackerID      uuid.UUID `json:"tracker_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	KeywordCount   int32     `json:"keyword_count"`
+}
+
+func (q *Queries) CreateRankTrackerRun(ctx context.Context, arg CreateRankTrackerRunParams) (RankTrackerRun, error) {
+	row := q.db.QueryRowContext(ctx, createRankTrackerRun, arg.TrackerID, arg.OrganisationID, arg.KeywordCount)
+	var i RankTrackerRun
+	err := row.Scan(
+		&i.ID,
+		&i.TrackerID,
+		&i.OrganisationID,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.Status,
+		&i.KeywordCount,
+		&i.FailedCount,
+		&i.Cost,
+		&i.ShareOfVoice,
+		&i.Error,
+	)
+	return i, err
+}
+
+const createSeoBacklinkProspect = `-- name: CreateSeoBacklinkProspect :execrows
+
+INSERT INTO seo_backlink_prospects (organisation_id, target, domain, status, source, competitors, contact, notes, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (organisation_id, target, domain) DO NOTHING
+`
+
+type CreateSeoBacklinkProspectParams struct {
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	Target         string        `json:"target"`
+	Domain         string        `json:"domain"`
+	Status         string        `json:"status"`
+	Source         string        `json:"source"`
+	Competitors    []string      `json:"competitors"`
+	Contact        string        `json:"contact"`
+	Notes          string        `json:"notes"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+}
+
+// =============================================================================
+// SEO Backlink Prospects
+// =============================================================================
+func (q *Queries) CreateSeoBacklinkProspect(ctx context.Context, arg CreateSeoBacklinkProspectParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createSeoBacklinkProspect,
+		arg.OrganisationID,
+		arg.Target,
+		arg.Domain,
+		arg.Status,
+		arg.Source,
+		pq.Array(arg.Competitors),
+		arg.Contact,
+		arg.Notes,
+		arg.CreatedBy,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createSeoKeywordList = `-- name: CreateSeoKeywordList :one
+
+INSERT INTO seo_keyword_lists (organisation_id, name, location_code, language_code, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, organisation_id, name, location_code, language_code, created_by, created_at, last_refreshed_at, next_refresh_at
+`
+
+type CreateSeoKeywordListParams struct {
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	Name           string        `json:"name"`
+	LocationCode   int32         `json:"location_code"`
+	LanguageCode   string        `json:"language_code"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+}
+
+// =============================================================================
+// SEO Keyword Lists
+// =============================================================================
+func (q *Queries) CreateSeoKeywordList(ctx context.Context, arg CreateSeoKeywordListParams) (SeoKeywordList, error) {
+	row := q.db.QueryRowContext(ctx, createSeoKeywordList,
+		arg.OrganisationID,
+		arg.Name,
+		arg.LocationCode,
+		arg.LanguageCode,
+		arg.CreatedBy,
+	)
+	var i SeoKeywordList
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Name,
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LastRefreshedAt,
+		&i.NextRefreshAt,
+	)
+	return i, err
+}
+
+const createSeoKeywordRefresh = `-- name: CreateSeoKeywordRefresh :exec
+INSERT INTO seo_keyword_refreshes (list_id, organisation_id, status, keyword_count, updated_count, cost, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type CreateSeoKeywordRefreshParams struct {
+	ListID         uuid.UUID `json:"list_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Status         string    `json:"status"`
+	KeywordCount   int32     `json:"keyword_count"`
+	UpdatedCount   int32     `json:"updated_count"`
+	Cost           float64   `json:"cost"`
+	Error          string    `json:"error"`
+}
+
+func (q *Queries) CreateSeoKeywordRefresh(ctx context.Context, arg CreateSeoKeywordRefreshParams) error {
+	_, err := q.db.ExecContext(ctx, createSeoKeywordRefresh,
+		arg.ListID,
+		arg.OrganisationID,
+		arg.Status,
+		arg.KeywordCount,
+		arg.UpdatedCount,
+		arg.Cost,
+		arg.Error,
+	)
+	return err
+}
+
+const createSeoPageExperience = `-- name: CreateSeoPageExperience :one
+
+INSERT INTO seo_page_experience (organisation_id, audit_id, page_url, strategy, created_by, intrusive_interstitial)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (organisation_id, audit_id, page_url, strategy) DO UPDATE
+SET created_by = EXCLUDED.created_by, intrusive_interstitial = EXCLUDED.intrusive_interstitial,
+    created_at = now(), completed_at = NULL, status = 'pending', error = ''
+RETURNING id, organisation_id, audit_id, page_url, strategy, created_by, created_at, completed_at, status, error, intrusive_interstitial, score, passed, cwv_source, lcp_ms, inp_ms, cls, signals, pagespeed, issues, rechecked_at
+`
+
+type CreateSeoPageExperienceParams struct {
+	OrganisationID        uuid.UUID     `json:"organisation_id"`
+	AuditID               uuid.UUID     `json:"audit_id"`
+	PageUrl               string        `json:"page_url"`
+	Strategy              string        `json:"strategy"`
+	CreatedBy             uuid.NullUUID `json:"created_by"`
+	IntrusiveInterstitial sql.NullBool  `json:"intrusive_interstitial"`
+}
+
+// =============================================================================
+// SEO Page Experience
+// =============================================================================
+func (q *Queries) CreateSeoPageExperience(ctx context.Context, arg CreateSeoPageExperienceParams) (SeoPageExperience, error) {
+	row := q.db.QueryRowContext(ctx, createSeoPageExperience,
+		arg.OrganisationID,
+		arg.AuditID,
+		arg.PageUrl,
+		arg.Strategy,
+		arg.CreatedBy,
+		arg.IntrusiveInterstitial,
+	)
+	var i SeoPageExperience
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.AuditID,
+		&i.PageUrl,
+		&i.Strategy,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.Status,
+		&i.Error,
+		&i.IntrusiveInterstitial,
+		&i.Score,
+		&i.Passed,
+		&i.CwvSource,
+		&i.LcpMs,
+		&i.InpMs,
+		&i.Cls,
+		&i.Signals,
+		&i.Pagespeed,
+		&i.Issues,
+		&i.RecheckedAt,
+	)
+	return i, err
+}
+
+const createSeoRankCheck = `-- name: CreateSeoRankCheck :one
+
+INSERT INTO seo_rank_checks (organisation_id, created_by, domain, location_code, language_code, device, keyword_count)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, organisation_id, created_by, created_at, completed_at, domain, location_code, language_code, device, keyword_count, status, cost, error, results
+`
+
+type CreateSeoRankCheckParams struct {
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+	Domain         string        `json:"domain"`
+	LocationCode   int32         `json:"location_code"`
+	LanguageCode   string        `json:"language_code"`
+	Device         string        `json:"device"`
+	KeywordCount   int32         `json:"keyword_count"`
+}
+
+// =============================================================================
+// SEO Rank Checks
+// =============================================================================
+func (q *Queries) CreateSeoRankCheck(ctx context.Context, arg CreateSeoRankCheckParams) (SeoRankCheck, error) {
+	row := q.db.QueryRowContext(ctx, createSeoRankCheck,
+		arg.OrganisationID,
+		arg.CreatedBy,
+		arg.Domain,
+		arg.LocationCode,
+		arg.LanguageCode,
+		arg.Device,
+		arg.KeywordCount,
+	)
+	var i SeoRankCheck
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.Domain,
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.Device,
+		&i.KeywordCount,
+		&i.Status,
+		&i.Cost,
+		&i.Error,
+		&i.Results,
+	)
+	return i, err
+}
+
+const createSeoScheduleRun = `-- name: CreateSeoScheduleRun :one
+INSERT INTO seo_schedule_runs (schedule_id, organisation_id)
+VALUES ($1, $2)
+RETURNING id, schedule_id, organisation_id, created_at, completed_at, status, audit_id, rank_check_id, summary, deltas, error
+`
+
+type CreateSeoScheduleRunParams struct {
+	ScheduleID     uuid.UUID `json:"schedule_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) CreateSeoScheduleRun(ctx context.Context, arg CreateSeoScheduleRunParams) (SeoScheduleRun, error) {
+	row := q.db.QueryRowContext(ctx, createSeoScheduleRun, arg.ScheduleID, arg.OrganisationID)
+	var i SeoScheduleRun
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduleID,
+		&i.OrganisationID,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.Status,
+		&i.AuditID,
+		&i.RankCheckID,
+		&i.Summary,
+		&i.Deltas,
+		&i.Error,
+	)
+	return i, err
+}
+
+const createUserNotification = `-- name: CreateUserNotification :exec
+INSERT INTO user_notifications (user_id, organisation_id, category, event_type, summary, data)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateUserNotificationParams struct {
+	UserID         uuid.UUID       `json:"user_id"`
+	OrganisationID uuid.UUID       `json:"organisation_id"`
+	Category       string          `json:"category"`
+	EventType      string          `json:"event_type"`
+	Summary        string          `json:"summary"`
+	Data           json.RawMessage `json:"data"`
+}
+
+func (q *Queries) CreateUserNotification(ctx context.Context, arg CreateUserNotificationParams) error {
+	_, err := q.db.ExecContext(ctx, createUserNotification,
+		arg.UserID,
+		arg.OrganisationID,
+		arg.Category,
+		arg.EventType,
+		arg.Summary,
+		arg.Data,
+	)
+	return err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, organisation_id, event_id, event_type, payload, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, webhook_id, organisation_id, event_id, event_type, payload, status, attempts, response_status, error, created_at, last_attempt_at, next_attempt_at, delivered_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	WebhookID      uuid.UUID       `json:"webhook_id"`
+	OrganisationID uuid.UUID       `json:"organisation_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, createWebhookDelivery,
+		arg.WebhookID,
+		arg.OrganisationID,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+		arg.NextAttemptAt,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.OrganisationID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.Error,
+		&i.CreatedAt,
+		&i.LastAttemptAt,
+		&i.NextAttemptAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const deferSeoSchedule = `-- name: DeferSeoSchedule :exec
+UPDATE seo_schedules
+SET next_run_at = $2
+WHERE id = $1
+`
+
+type DeferSeoScheduleParams struct {
+	ID        uuid.UUID `json:"id"`
+	NextRunAt time.Time `json:"next_run_at"`
+}
+
+func (q *Queries) DeferSeoSchedule(ctx context.Context, arg DeferSeoScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, deferSeoSchedule, arg.ID, arg.NextRunAt)
+	return err
+}
+
+const deleteAccessReview = `-- name: DeleteAccessReview :exec
+DELETE FROM access_reviews
+WHERE id = $1
+`
+
+func (q *Queries) DeleteAccessReview(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteAccessReview, id)
+	return err
+}
+
+const deleteAnnouncement = `-- name: DeleteAnnouncement :execrows
+DELETE FROM announcements
+WHERE id = $1
+`
+
+func (q *Queries) DeleteAnnouncement(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAnnouncement, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteCompetitorPin = `-- name: DeleteCompetitorPin :execrows
+DELETE FROM competitor_pins
+WHERE id = $1 AND organisation_id = $2
+`
+
+type DeleteCompetitorPinParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) DeleteCompetitorPin(ctx context.Context, arg DeleteCompetitorPinParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteCompetitorPin, arg.ID, arg.OrganisationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteContentUserState = `-- name: DeleteContentUserState :exec
+DELETE FROM h5p_content_user_state
+WHERE user_id = $1 AND content_id = $2 AND sub_content_id = $3 AND data_type = $4
+`
+
+type DeleteContentUserStateParams struct {
+	UserID       uuid.UUID `json:"user_id"`
+	ContentID    uuid.UUID `json:"content_id"`
+	SubContentID string    `json:"sub_content_id"`
+	DataType     string    `json:"data_type"`
+}
+
+func (q *Queries) DeleteContentUserState(ctx context.Context, arg DeleteContentUserStateParams) error {
+	_, err := q.db.ExecContext(ctx, deleteContentUserState,
+		arg.UserID,
+		arg.ContentID,
+		arg.SubContentID,
+		arg.DataType,
+	)
+	return err
+}
+
+const deleteExpiredContentExtractCache = `-- name: DeleteExpiredContentExtractCache :exec
+DELETE FROM content_extract_cache WHERE expires_at < now()
+`
+
+func (q *Queries) DeleteExpiredContentExtractCache(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredContentExtractCache)
+	return err
+}
+
+const deleteExpiredH5PHubCache = `-- name: DeleteExpiredH5PHubCache :exec
+DELETE FROM h5p_hub_cache WHERE expires_at < CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredH5PHubCache(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredH5PHubCache)
+	return err
+}
+
+const deleteExpiredXapiStatements = `-- name: DeleteExpiredXapiStatements :execrows
+DELETE FROM xapi_statements
+WHERE id IN (
+    SELECT s.id FROM xapi_statements s
+    WHERE s.org_id = $1 AND s.created_at < $2
+    LIMIT $3
+)
+`
+
+type DeleteExpiredXapiStatementsParams struct {
+	OrgID     uuid.UUID `json:"org_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Limit     int32     `json:"limit"`
+}
+
+func (q *Queries) DeleteExpiredXapiStatements(ctx context.Context, arg DeleteExpiredXapiStatementsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredXapiStatements, arg.OrgID, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteH5PBundleVariant = `-- name: DeleteH5PBundleVariant :execrows
+DELETE FROM h5p_bundle_variants WHERE name = $1
+`
+
+func (q *Queries) DeleteH5PBundleVariant(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteH5PBundleVariant, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteH5PInstallProfile = `-- name: DeleteH5PInstallProfile :execrows
+DELETE FROM h5p_install_profiles WHERE id = $1
+`
+
+func (q *Queries) DeleteH5PInstallProfile(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteH5PInstallProfile, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteH5PLibrary = `-- name: DeleteH5PLibrary :exec
+DELETE FROM h5p_libraries WHERE id = $1
+`
+
+func (q *Queries) DeleteH5PLibrary(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteH5PLibrary, id)
+	return err
+}
+
+const deleteH5PLibraryByMachineName = `-- name: DeleteH5PLibraryByMachineName :exec
+WITH deleted AS (
+    DELETE FROM h5p_libraries WHERE machine_name = $1
+    RETURNING machine_name, major_version, minor_version, patch_version, package_path
+)
+INSERT INTO pending_deletions (storage_key, reason)
+SELECT 'h5p-libraries/extracted/' || machine_name || '-' || major_version || '.' || minor_version || '.' || patch_version || '/', 'library'
+FROM deleted
+UNION ALL
+SELECT package_path, 'library' FROM deleted WHERE package_path IS NOT NULL
+ON CONFLICT (storage_key) DO NOTHING
+`
+
+func (q *Queries) DeleteH5PLibraryByMachineName(ctx context.Context, machineName string) error {
+	_, err := q.db.ExecContext(ctx, deleteH5PLibraryByMachineName, machineName)
+	return err
+}
+
+const deleteH5PLibraryDependencies = `-- name: DeleteH5PLibraryDependencies :exec
+DELETE FROM h5p_library_dependencies WHERE library_id = $1
+`
+
+func (q *Queries) DeleteH5PLibraryDependencies(ctx context.Context, libraryID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteH5PLibraryDependencies, libraryID)
+	return err
+}
+
+const deleteH5PMediaAsset = `-- name: DeleteH5PMediaAsset :exec
+WITH deleted AS (
+    DELETE FROM h5p_media_assets m WHERE m.id = $1 AND m.org_id = $2
+    RETURNING m.storage_key, m.thumbnail_key
+)
+INSERT INTO pending_deletions (storage_key, reason)
+SELECT storage_key, 'media' FROM deleted
+UNION ALL
+SELECT thumbnail_key, 'media' FROM deleted WHERE thumbnail_key IS NOT NULL
+ON CONFLICT (storage_key) DO NOTHING
+`
+
+type DeleteH5PMediaAssetParams struct {
+	ID    uuid.UUID `json:"id"`
+	OrgID uuid.UUID `json:"org_id"`
+}
+
+func (q *Queries) DeleteH5PMediaAsset(ctx context.Context, arg DeleteH5PMediaAssetParams) error {
+	_, err := q.db.ExecContext(ctx, deleteH5PMediaAsset, arg.ID, arg.OrgID)
+	return err
+}
+
+const deleteH5POrgLibrary = `-- name: DeleteH5POrgLibrary :exec
+DELETE FROM h5p_org_libraries WHERE org_id = $1 AND library_id = $2
+`
+
+type DeleteH5POrgLibraryParams struct {
+	OrgID     uuid.UUID `json:"org_id"`
+	LibraryID uuid.UUID `json:"library_id"`
+}
+
+func (q *Queries) DeleteH5POrgLibrary(ctx context.Context, arg DeleteH5POrgLibraryParams) error {
+	_, err := q.db.ExecContext(ctx, deleteH5POrgLibrary, arg.OrgID, arg.LibraryID)
+	return err
+}
+
+const deleteNotificationDigestItems = `-- name: DeleteNotificationDigestItems :exec
+DELETE FROM notification_digest_items
+WHERE user_id = $1 AND created_at < $2
+`
+
+type DeleteNotificationDigestItemsParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) DeleteNotificationDigestItems(ctx context.Context, arg DeleteNotificationDigestItemsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteNotificationDigestItems, arg.UserID, arg.CreatedAt)
+	return err
+}
+
+const deleteNotificationPreferences = `-- name: DeleteNotificationPreferences :exec
+DELETE FROM notification_preferences
+WHERE user_id = $1 AND organisation_id = $2
+`
+
+type DeleteNotificationPreferencesParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) DeleteNotificationPreferences(ctx context.Context, arg DeleteNotificationPreferencesParams) error {
+	_, err := q.db.ExecContext(ctx, deleteNotificationPreferences, arg.UserID, arg.OrganisationID)
+	return err
+}
+
+const deleteOldUserNotifications = `-- name: DeleteOldUserNotifications :execrows
+DELETE FROM user_notifications WHERE created_at < $1
+`
+
+func (q *Queries) DeleteOldUserNotifications(ctx context.Context, createdAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOldUserNotifications, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOldWebhookDeliveries = `-- name: DeleteOldWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE created_at < $1 AND status <> 'pending'
+`
+
+func (q *Queries) DeleteOldWebhookDeliveries(ctx context.Context, createdAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOldWebhookDeliveries, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOrganisationPaymentMethod = `-- name: DeleteOrganisationPaymentMethod :exec
+DELETE FROM organisation_payment_methods
+WHERE organisation_id = $1
+`
+
+func (q *Queries) DeleteOrganisationPaymentMethod(ctx context.Context, organisationID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOrganisationPaymentMethod, organisationID)
+	return err
+}
+
+const deleteOrganisationWebhook = `-- name: DeleteOrganisationWebhook :execrows
+DELETE FROM organisation_webhooks
+WHERE id = $1 AND organisation_id = $2
+`
+
+type DeleteOrganisationWebhookParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) DeleteOrganisationWebhook(ctx context.Context, arg DeleteOrganisationWebhookParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOrganisationWebhook, arg.ID, arg.OrganisationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePendingDeletion = `-- name: DeletePendingDeletion :exec
+DELETE FROM pending_deletions WHERE id = $1
+`
+
+func (q *Queries) DeletePendingDeletion(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deletePendingDeletion, id)
+	return err
+}
+
+const deleteRankTracker = `-- name: DeleteRankTracker :execrows
+DELETE FROM rank_trackers
+WHERE id = $1 AND organisation_id = $2
+`
+
+type DeleteRankTrackerParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) DeleteRankTracker(ctx context.Context, arg DeleteRankTrackerParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRankTracker, arg.ID, arg.OrganisationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteRankTrackerKeywords = `-- name: DeleteRankTrackerKeywords :execrows
+DELETE FROM rank_tracker_keywords
+WHERE tracker_id = $1 AND keyword = ANY($2::text[])
+`
+
+type DeleteRankTrackerKeywordsParams struct {
+	TrackerID uuid.UUID `json:"tracker_id"`
+	Keywords  []string  `json:"keywords"`
+}
+
+func (q *Queries) DeleteRankTrackerKeywords(ctx context.Context, arg DeleteRankTrackerKeywordsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRankTrackerKeywords, arg.TrackerID, pq.Array(arg.Keywords))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSeoBacklinkProspect = `-- name: DeleteSeoBacklinkProspect :execrows
+DELETE FROM seo_backlink_prospects
+WHERE id = $1 AND organisation_id = $2
+`
+
+type DeleteSeoBacklinkProspectParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) DeleteSeoBacklinkProspect(ctx context.Context, arg DeleteSeoBacklinkProspectParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSeoBacklinkProspect, arg.ID, arg.OrganisationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSeoSchedule = `-- name: DeleteSeoSchedule :execrows
+DELETE FROM seo_schedules
+WHERE id = $1 AND organisation_id = $2
+`
+
+type DeleteSeoScheduleParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) DeleteSeoSchedule(ctx context.Context, arg DeleteSeoScheduleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSeoSchedule, arg.ID, arg.OrganisationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteStaleCompetitorKeywordGaps = `-- name: DeleteStaleCompetitorKeywordGaps :execrows
+DELETE FROM competitor_keyword_gaps
+WHERE pin_id = $1 AND last_seen_at < $2
+`
+
+type DeleteStaleCompetitorKeywordGapsParams struct {
+	PinID      uuid.UUID `json:"pin_id"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+func (q *Queries) DeleteStaleCompetitorKeywordGaps(ctx context.Context, arg DeleteStaleCompetitorKeywordGapsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteStaleCompetitorKeywordGaps, arg.PinID, arg.LastSeenAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTokens = `-- name: DeleteTokens :exec
+delete from tokens where expires < current_timestamp
+`
+
+func (q *Queries) DeleteTokens(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteTokens)
+	return err
+}
+
+const disableH5POrgLibrary = `-- name: DisableH5POrgLibrary :exec
+INSERT INTO h5p_org_libraries (id, org_id, library_id, enabled)
+VALUES ($1, $2, $3, false)
+ON CONFLICT (org_id, library_id)
+DO UPDATE SET enabled = false
+`
+
+type DisableH5POrgLibraryParams struct {
+	ID        uuid.UUID `json:"id"`
+	OrgID     uuid.UUID `json:"org_id"`
+	LibraryID uuid.UUID `json:"library_id"`
+}
+
+func (q *Queries) DisableH5POrgLibrary(ctx context.Context, arg DisableH5POrgLibraryParams) error {
+	_, err := q.db.ExecContext(ctx, disableH5POrgLibrary, arg.ID, arg.OrgID, arg.LibraryID)
+	return err
+}
+
+const downgradeOrganisationToFree = `-- name: DowngradeOrganisationToFree :exec
+UPDATE organisations
+SET
+    subscription_tier = 'free',
+    subscription_id = '',
+    subscription_end = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) DowngradeOrganisationToFree(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, downgradeOrganisationToFree, id)
+	return err
+}
+
+const enableH5POrgLibrary = `-- name: EnableH5POrgLibrary :exec
+INSERT INTO h5p_org_libraries (id, org_id, library_id, enabled)
+VALUES ($1, $2, $3, true)
+ON CONFLICT (org_id, library_id)
+DO UPDATE SET enabled = true
+`
+
+type EnableH5POrgLibraryParams struct {
+	ID        uuid.UUID `json:"id"`
+	OrgID     uuid.UUID `json:"org_id"`
+	LibraryID uuid.UUID `json:"library_id"`
+}
+
+func (q *Queries) EnableH5POrgLibrary(ctx context.Context, arg EnableH5POrgLibraryParams) error {
+	_, err := q.db.ExecContext(ctx, enableH5POrgLibrary, arg.ID, arg.OrgID, arg.LibraryID)
+	return err
+}
+
+const enableH5POrgLibraryVersions = `-- name: EnableH5POrgLibraryVersions :exec
+UPDATE h5p_org_libraries ol SET enabled = true
+FROM h5p_libraries l, h5p_libraries target
+WHERE ol.org_id = $1 AND ol.library_id = l.id
+  AND target.id = $2 AND l.machine_name = target.machine_name
+`
+
+type EnableH5POrgLibraryVersionsParams struct {
+	OrgID uuid.UUID `json:"org_id"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func (q *Queries) EnableH5POrgLibraryVersions(ctx context.Context, arg EnableH5POrgLibraryVersionsParams) error {
+	_, err := q.db.ExecContext(ctx, enableH5POrgLibraryVersions, arg.OrgID, arg.ID)
+	return err
+}
+
+const enrichSeoBacklinkProspect = `-- name: EnrichSeoBacklinkProspect :exec
+UPDATE seo_backlink_prospects
+SET rank = $2, backlinks = $3, referring_domains = $4, spam_score = $5, enriched_at = now()
+WHERE id = $1
+`
+
+type EnrichSeoBacklinkProspectParams struct {
+	ID               uuid.UUID     `json:"id"`
+	Rank             sql.NullInt32 `json:"rank"`
+	Backlinks        sql.NullInt64 `json:"backlinks"`
+	ReferringDomains sql.NullInt32 `json:"referring_domains"`
+	SpamScore        sql.NullInt32 `json:"spam_score"`
+}
+
+func (q *Queries) EnrichSeoBacklinkProspect(ctx context.Context, arg EnrichSeoBacklinkProspectParams) error {
+	_, err := q.db.ExecContext(ctx, enrichSeoBacklinkProspect,
+		arg.ID,
+		arg.Rank,
+		arg.Backlinks,
+		arg.ReferringDomains,
+		arg.SpamScore,
+	)
+	return err
+}
+
+const extendJobLock = `-- name: ExtendJobLock :execrows
+UPDATE job_locks
+SET expires_at = now() + make_interval(secs => $3::float8)
+WHERE name = $1 AND holder = $2 AND expires_at > now()
+`
+
+type ExtendJobLockParams struct {
+	Name       string  `json:"name"`
+	Holder     string  `json:"holder"`
+	TtlSeconds float64 `json:"ttl_seconds"`
+}
+
+func (q *Queries) ExtendJobLock(ctx context.Context, arg ExtendJobLockParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, extendJobLock, arg.Name, arg.Holder, arg.TtlSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const failAccessReview = `-- name: FailAccessReview :exec
+UPDATE access_reviews
+SET status = 'failed', completed_at = now(), error = $1, expires_at = $2::timestamptz
+WHERE id = $3
+`
+
+type FailAccessReviewParams struct {
+	Error     string    `json:"error"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func (q *Queries) FailAccessReview(ctx context.Context, arg FailAccessReviewParams) error {
+	_, err := q.db.ExecContext(ctx, failAccessReview, arg.Error, arg.ExpiresAt, arg.ID)
+	return err
+}
+
+const failStaleRankTrackerRuns = `-- name: FailStaleRankTrackerRuns :execrows
+UPDATE rank_tracker_runs
+SET status = 'failed', error = 'The run was interrupted', completed_at = now()
+WHERE status = 'running' AND created_at < $1
+`
+
+func (q *Queries) FailStaleRankTrackerRuns(ctx context.Context, createdAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, failStaleRankTrackerRuns, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const finishH5PProfileApplication = `-- name: FinishH5PProfileApplication :exec
+UPDATE h5p_profile_applications
+SET status = $2, summary = $3, completed_at = now()
+WHERE id = $1
+`
+
+type FinishH5PProfileApplicationParams struct {
+	ID      uuid.UUID       `json:"id"`
+	Status  string          `json:"status"`
+	Summary json.RawMessage `json:"summary"`
+}
+
+func (q *Queries) FinishH5PProfileApplication(ctx context.Context, arg FinishH5PProfileApplicationParams) error {
+	_, err := q.db.ExecContext(ctx, finishH5PProfileApplication, arg.ID, arg.Status, arg.Summary)
+	return err
+}
+
+const getAccessReview = `-- name: GetAccessReview :one
+SELECT id, organisation_id, requested_by, status, created_at, completed_at, expires_at, error FROM access_reviews
+WHERE id = $1
+`
+
+func (q *Queries) GetAccessReview(ctx context.Context, id uuid.UUID) (AccessReview, error) {
+	row := q.db.QueryRowContext(ctx, getAccessReview, id)
+	var i AccessReview
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.RequestedBy,
+		&i.Status,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ExpiresAt,
+		&i.Error,
+	)
+	return i, err
+}
+
+const getBillingContact = `-- name: GetBillingContact :one
+
+SELECT organisation_id, name, email, po_number, address_line1, address_line2, city, state, postal_code, country, invoice_memo, invoice_custom_fields, updated_at FROM organisation_billing_contacts
+WHERE organisation_id = $1
+`
+
+// =============================================================================
+// Organisation Billing Contacts
+// =============================================================================
+func (q *Queries) GetBillingContact(ctx context.Context, organisationID uuid.UUID) (OrganisationBillingContact, error) {
+	row := q.db.QueryRowContext(ctx, getBillingContact, organisationID)
+	var i OrganisationBillingContact
+	err := row.Scan(
+		&i.OrganisationID,
+		&i.Name,
+		&i.Email,
+		&i.PoNumber,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.City,
+		&i.State,
+		&i.PostalCode,
+		&i.Country,
+		&i.InvoiceMemo,
+		&i.InvoiceCustomFields,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCertificate = `-- name: GetCertificate :one
+SELECT id, org_id, user_id, course_id, enrolment_id, learner_name, course_title, organisation_name, completed_at, issued_at, storage_key FROM certificates WHERE id = $1
+`
+
+func (q *Queries) GetCertificate(ctx context.Context, id uuid.UUID) (Certificate, error) {
+	row := q.db.QueryRowContext(ctx, getCertificate, id)
+	var i Certificate
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.UserID,
+		&i.CourseID,
+		&i.EnrolmentID,
+		&i.LearnerName,
+		&i.CourseTitle,
+		&i.OrganisationName,
+		&i.CompletedAt,
+		&i.IssuedAt,
+		&i.StorageKey,
+	)
+	return i, err
+}
+
+const getCertificateTemplate = `-- name: GetCertificateTemplate :one
+
+SELECT organisation_id, heading, fields, signatory_name, signatory_title, background_key, updated_at FROM certificate_templates WHERE organisation_id = $1
+`
+
+// =============================================================================
+// Certificates
+// =============================================================================
+func (q *Queries) GetCertificateTemplate(ctx context.Context, organisationID uuid.UUID) (CertificateTemplate, error) {
+	row := q.db.QueryRowContext(ctx, getCertificateTemplate, organisationID)
+	var i CertificateTemplate
+	err := row.Scan(
+		&i.OrganisationID,
+		&i.Heading,
+		&i.Fields,
+		&i.SignatoryName,
+		&i.SignatoryTitle,
+		&i.BackgroundKey,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCompetitorPin = `-- name: GetCompetitorPin :one
+SELECT id, organisation_id, target, domain, location_code, language_code, created_by, created_at, refreshed_at, next_refresh_at FROM competitor_pins
+WHERE id = $1 AND organisation_id = $2
+`
+
+type GetCompetitorPinParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) GetCompetitorPin(ctx context.Context, arg GetCompetitorPinParams) (CompetitorPin, error) {
+	row := q.db.QueryRowContext(ctx, getCompetitorPin, arg.ID, arg.OrganisationID)
+	var i CompetitorPin
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Target,
+		&i.Domain,
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.RefreshedAt,
+		&i.NextRefreshAt,
+	)
+	return i, err
+}
+
+const getContentExtractCache = `-- name: GetContentExtractCache :one
+
+SELECT url, final_url, title, markdown, links, provider, fetched_at, expires_at FROM content_extract_cache
+WHERE url = $1 AND expires_at > now()
+`
+
+// =============================================================================
+// Content Extract Cache
+// =============================================================================
+func (q *Queries) GetContentExtractCache(ctx context.Context, url string) (ContentExtractCache, error) {
+	row := q.db.QueryRowContext(ctx, getContentExtractCache, url)
+	var i ContentExtractCache
+	err := row.Scan(
+		&i.Url,
+		&i.FinalUrl,
+		&i.Title,
+		&i.Markdown,
+		&i.Links,
+		&i.Provider,
+		&i.FetchedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getContentUserState = `-- name: GetContentUserState :one
+
+SELECT id, user_id, content_id, sub_content_id, data_type, data, preload, updated_at FROM h5p_content_user_state
+WHERE user_id = $1 AND content_id = $2 AND sub_content_id = $3 AND data_type = $4
+`
+
+type GetContentUserStateParams struct {
+	UserID       uuid.UUID `json:"user_id"`
+	ContentID    uuid.UUID `json:"content_id"`
+	SubContentID string    `json:"sub_content_id"`
+	DataType     string    `json:"data_type"`
+}
+
+// =============================================================================
+// H5P Content User State (Save/Resume)
+// =============================================================================
+func (q *Queries) GetContentUserState(ctx context.Context, arg GetContentUserStateParams) (H5pContentUserState, error) {
+	row := q.db.QueryRowContext(ctx, getContentUserState,
+		arg.UserID,
+		arg.ContentID,
+		arg.SubContentID,
+		arg.DataType,
+	)
+	var i H5pContentUserState
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ContentID,
+		&i.SubContentID,
+		&i.DataType,
+		&i.Data,
+		&i.Preload,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getContentUserStatesForContent = `-- name: GetContentUserStatesForContent :many
+SELECT id, user_id, content_id, sub_content_id, data_type, data, preload, updated_at FROM h5p_content_user_state
+WHERE user_id = $1 AND content_id = $2 AND preload = true
+`
+
+type GetContentUserStatesForContentParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	ContentID uuid.UUID `json:"content_id"`
+}
+
+func (q *Queries) GetContentUserStatesForContent(ctx context.Context, arg GetContentUserStatesForContentParams) ([]H5pContentUserState, error) {
+	rows, err := q.db.QueryContext(ctx, getContentUserStatesForContent, arg.UserID, arg.ContentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []H5pContentUserState
+	for rows.Next() {
+		var i H5pContentUserState
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ContentID,
+			&i.SubContentID,
+			&i.DataType,
+			&i.Data,
+			&i.Preload,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDefaultH5PInstallProfile = `-- name: GetDefaultH5PInstallProfile :one
+SELECT id, name, description, libraries, is_default, created_by, created_at, updated_at FROM h5p_install_profiles WHERE is_default
+`
+
+func (q *Queries) GetDefaultH5PInstallProfile(ctx context.Context) (H5pInstallProfile, error) {
+	row := q.db.QueryRowContext(ctx, getDefaultH5PInstallProfile)
+	var i H5pInstallProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		pq.Array(&i.Libraries),
+		&i.IsDefault,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEnrolmentsByUserAndContentId = `-- name: GetEnrolmentsByUserAndContentId :many
+SELECT e.id, e.org_id, e.course_id, e.user_id, e.status
+FROM enrolments e
+JOIN course_items ci ON ci.course_id = e.course_id
+WHERE e.user_id = $1
+  AND ci.content_id = $2
+  AND e.status = 'active'
+  AND ci.removed_at IS NULL
+`
+
+type GetEnrolmentsByUserAndContentIdParams struct {
+	UserID    uuid.NullUUID `json:"user_id"`
+	ContentID uuid.NullUUID `json:"content_id"`
+}
+
+type GetEnrolmentsByUserAndContentIdRow struct {
+	ID       uuid.UUID     `json:"id"`
+	OrgID    uuid.UUID     `json:"org_id"`
+	CourseID uuid.UUID     `json:"course_id"`
+	UserID   uuid.NullUUID `json:"user_id"`
+	Status   string        `json:"status"`
+}
+
+func (q *Queries) GetEnrolmentsByUserAndContentId(ctx context.Context, arg GetEnrolmentsByUserAndContentIdParams) ([]GetEnrolmentsByUserAndContentIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEnrolmentsByUserAndContentId, arg.UserID, arg.ContentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEnrolmentsByUserAndContentIdRow
+	for rows.Next() {
+		var i GetEnrolmentsByUserAndContentIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrgID,
+			&i.CourseID,
+			&i.UserID,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGradebookCourse = `-- name: GetGradebookCourse :one
+
+SELECT id, title, slug FROM courses
+WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+`
+
+type GetGradebookCourseParams struct {
+	ID    uuid.UUID `json:"id"`
+	OrgID uuid.UUID `json:"org_id"`
+}
+
+type GetGradebookCourseRow struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	Slug  string    `json:"slug"`
+}
+
+// =============================================================================
+// Results (gradebook)
+// =============================================================================
+func (q *Queries) GetGradebookCourse(ctx context.Context, arg GetGradebookCourseParams) (GetGradebookCourseRow, error) {
+	row := q.db.QueryRowContext(ctx, getGradebookCourse, arg.ID, arg.OrgID)
+	var i GetGradebookCourseRow
+	err := row.Scan(&i.ID, &i.Title, &i.Slug)
+	return i, err
+}
+
+const getH5PContent = `-- name: GetH5PContent :one
+SELECT id, created_at, updated_at, org_id, library_id, created_by, title, slug, description, content_json, tags, folder_path, storage_path, status, deleted_at, locked_at, lock_reason FROM h5p_content WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+`
+
+type GetH5PContentParams struct {
+	ID    uuid.UUID `json:"id"`
+	OrgID uuid.UUID `json:"org_id"`
+}
+
+func (q *Queries) GetH5PContent(ctx context.Context, arg GetH5PContentParams) (H5pContent, error) {
+	row := q.db.QueryRowContext(ctx, getH5PContent, arg.ID, arg.OrgID)
+	var i H5pContent
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrgID,
+		&i.LibraryID,
+		&i.CreatedBy,
+		&i.Title,
+		&i.Slug,
+		&i.Description,
+		&i.ContentJson,
+		pq.Array(&i.Tags),
+		&i.FolderPath,
+		&i.StoragePath,
+		&i.Status,
+		&i.DeletedAt,
+		&i.LockedAt,
+		&i.LockReason,
+	)
+	return i, err
+}
+
+const getH5PContentOrgId = `-- name: GetH5PContentOrgId :one
+SELECT id, org_id FROM h5p_content WHERE id = $1 AND deleted_at IS NULL
+`
+
+type GetH5PContentOrgIdRow struct {
+	ID    uuid.UUID `json:"id"`
+	OrgID uuid.UUID `json:"org_id"`
+}
+
+func (q *Queries) GetH5PContentOrgId(ctx context.Context, id uuid.UUID) (GetH5PContentOrgIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getH5PContentOrgId, id)
+	var i GetH5PContentOrgIdRow
+	err := row.Scan(&i.ID, &i.OrgID)
+	return i, err
+}
+
+const getH5PHubCache = `-- name: GetH5PHubCache :one
+
+SELECT id, created_at, cache_key, data, expires_at FROM h5p_hub_cache
+WHERE cache_key = $1 AND expires_at > CURRENT_TIMESTAMP
+`
+
+// =============================================================================
+// H5P Hub Cache
+// =============================================================================
+func (q *Queries) GetH5PHubCache(ctx context.Context, cacheKey string) (H5pHubCache, error) {
+	row := q.db.QueryRowContext(ctx, getH5PHubCache, cacheKey)
+	var i H5pHubCache
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.CacheKey,
+		&i.Data,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getH5PInstallProfile = `-- name: GetH5PInstallProfile :one
+SELECT id, name, description, libraries, is_default, created_by, created_at, updated_at FROM h5p_install_profiles WHERE id = $1
+`
+
+func (q *Queries) GetH5PInstallProfile(ctx context.Context, id uuid.UUID) (H5pInstallProfile, error) {
+	row := q.db.QueryRowContext(ctx, getH5PInstallProfile, id)
+	var i H5pInstallProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		pq.Array(&i.Libraries),
+		&i.IsDefault,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getH5PLibrary = `-- name: GetH5PLibrary :one
+
+SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries WHERE id = $1
+`
+
+// =============================================================================
+// H5P Libraries (Platform-wide)
+// =============================================================================
+func (q *Queries) GetH5PLibrary(ctx context.Context, id uuid.UUID) (H5pLibrary, error) {
+	row := q.db.QueryRowContext(ctx, getH5PLibrary, id)
+	var i H5pLibrary
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MachineName,
+		&i.MajorVersion,
+		&i.MinorVersion,
+		&i.PatchVersion,
+		&i.Title,
+		&i.Origin,
+		&i.MetadataJson,
+		pq.Array(&i.Categories),
+		pq.Array(&i.Keywords),
+		pq.Array(&i.Screenshots),
+		&i.Description,
+		&i.IconPath,
+		&i.PackagePath,
+		&i.ExtractedPath,
+		&i.Runnable,
+		&i.Restricted,
+	)
+	return i, err
+}
+
+const getH5PLibraryByMachineName = `-- name: GetH5PLibraryByMachineName :one
+SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries
+WHERE machine_name = $1
+ORDER BY major_version DESC, minor_version DESC, patch_version DESC
+LIMIT 1
+`
+
+func (q *Queries) GetH5PLibraryByMachineName(ctx context.Context, machineName string) (H5pLibrary, error) {
+	row := q.db.QueryRowContext(ctx, getH5PLibraryByMachineName, machineName)
+	var i H5pLibrary
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MachineName,
+		&i.MajorVersion,
+		&i.MinorVersion,
+		&i.PatchVersion,
+		&i.Title,
+		&i.Origin,
+		&i.MetadataJson,
+		pq.Array(&i.Categories),
+		pq.Array(&i.Keywords),
+		pq.Array(&i.Screenshots),
+		&i.Description,
+		&i.IconPath,
+		&i.PackagePath,
+		&i.ExtractedPath,
+		&i.Runnable,
+		&i.Restricted,
+	)
+	return i, err
+}
+
+const getH5PLibraryByMachineNameMajorMinor = `-- name: GetH5PLibraryByMachineNameMajorMinor :one
+SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries
+WHERE machine_name = $1
+  AND major_version = $2
+  AND minor_version = $3
+ORDER BY patch_version DESC
+LIMIT 1
+`
+
+type GetH5PLibraryByMachineNameMajorMinorParams struct {
+	MachineName  string `json:"machine_name"`
+	MajorVersion int32  `json:"major_version"`
+	MinorVersion int32  `json:"minor_version"`
+}
+
+func (q *Queries) GetH5PLibraryByMachineNameMajorMinor(ctx context.Context, arg GetH5PLibraryByMachineNameMajorMinorParams) (H5pLibrary, error) {
+	row := q.db.QueryRowContext(ctx, getH5PLibraryByMachineNameMajorMinor, arg.MachineName, arg.MajorVersion, arg.MinorVersion)
+	var i H5pLibrary
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MachineName,
+		&i.MajorVersion,
+		&i.MinorVersion,
+		&i.PatchVersion,
+		&i.Title,
+		&i.Origin,
+		&i.MetadataJson,
+		pq.Array(&i.Categories),
+		pq.Array(&i.Keywords),
+		pq.Array(&i.Screenshots),
+		&i.Description,
+		&i.IconPath,
+		&i.PackagePath,
+		&i.ExtractedPath,
+		&i.Runnable,
+		&i.Restricted,
+	)
+	return i, err
+}
+
+const getH5PLibraryByMachineNameVersion = `-- name: GetH5PLibraryByMachineNameVersion :one
+SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries
+WHERE machine_name = $1
+  AND major_version = $2
+  AND minor_version = $3
+  AND patch_version = $4
+`
+
+type GetH5PLibraryByMachineNameVersionParams struct {
+	MachineName  string `json:"machine_name"`
+	MajorVersion int32  `json:"major_version"`
+	MinorVersion int32  `json:"minor_version"`
+	PatchVersion int32  `json:"patch_version"`
+}
+
+func (q *Queries) GetH5PLibraryByMachineNameVersion(ctx context.Context, arg GetH5PLibraryByMachineNameVersionParams) (H5pLibrary, error) {
+	row := q.db.QueryRowContext(ctx, getH5PLibraryByMachineNameVersion,
+		arg.MachineName,
+		arg.MajorVersion,
+		arg.MinorVersion,
+		arg.PatchVersion,
+	)
+	var i H5pLibrary
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MachineName,
+		&i.MajorVersion,
+		&i.MinorVersion,
+		&i.PatchVersion,
+		&i.Title,
+		&i.Origin,
+		&i.MetadataJson,
+		pq.Array(&i.Categories),
+		pq.Array(&i.Keywords),
+		pq.Array(&i.Screenshots),
+		&i.Description,
+		&i.IconPath,
+		&i.PackagePath,
+		&i.ExtractedPath,
+		&i.Runnable,
+		&i.Restricted,
+	)
+	return i, err
+}
+
+const getH5PLibraryDependencies = `-- name: GetH5PLibraryDependencies :many
+SELECT d.id, d.library_id, d.depends_on_id, d.dependency_type, l.machine_name, l.major_version, l.minor_version, l.patch_version, l.title
+FROM h5p_library_dependencies d
+JOIN h5p_libraries l ON l.id = d.depends_on_id
+WHERE d.library_id = $1
+`
+
+type GetH5PLibraryDependenciesRow struct {
+	ID             uuid.UUID `json:"id"`
+	LibraryID      uuid.UUID `json:"library_id"`
+	DependsOnID    uuid.UUID `json:"depends_on_id"`
+	DependencyType string    `json:"dependency_type"`
+	MachineName    string    `json:"machine_name"`
+	MajorVersion   int32     `json:"major_version"`
+	MinorVersion   int32     `json:"minor_version"`
+	PatchVersion   int32     `json:"patch_version"`
+	Title          string    `json:"title"`
+}
+
+func (q *Queries) GetH5PLibraryDependencies(ctx context.Context, libraryID uuid.UUID) ([]GetH5PLibraryDependenciesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getH5PLibraryDependencies, libraryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetH5PLibraryDependenciesRow
+	for rows.Next() {
+		var i GetH5PLibraryDependenciesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LibraryID,
+			&i.DependsOnID,
+			&i.DependencyType,
+			&i.MachineName,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.PatchVersion,
+			&i.Title,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getH5PLibraryEditorDependencyTree = `-- name: GetH5PLibraryEditorDependencyTree :many
+WITH RECURSIVE dep_tree AS (
+    SELECT d.depends_on_id AS library_id, 0 AS depth
+    FROM h5p_library_dependencies d
+    WHERE d.library_id = $1
+      AND d.dependency_type = 'editor'
+    UNION
+    SELECT d.depends_on_id, dt.depth + 1
+    FROM h5p_library_dependencies d
+    JOIN dep_tree dt ON dt.library_id = d.library_id
+    WHERE dt.depth < 20
+      AND d.dependency_type IN ('preloaded', 'editor')
+),
+dep_max_depth AS (
+    SELECT library_id, MAX(depth) AS max_depth
+    FROM dep_tree
+    GROUP BY library_id
+)
+SELECT l.id, l.created_at, l.updated_at, l.machine_name, l.major_version, l.minor_version, l.patch_version, l.title, l.origin, l.metadata_json, l.categories, l.keywords, l.screenshots, l.description, l.icon_path, l.package_path, l.extracted_path, l.runnable, l.restricted
+FROM dep_max_depth dmd
+JOIN h5p_libraries l ON l.id = dmd.library_id
+ORDER BY dmd.max_depth DESC
+`
+
+// Returns all transitive EDITOR dependencies ordered deepest-first (topological).
+// Starts from 'editor' deps of the root library, then follows both 'editor' and
+// 'preloaded' deps of those editor libraries (since editor libs can have preloaded deps).
+// Used by the editor to load widget JS/CSS (e.g. H5PEditor.ShowWhen, H5PEditor.RangeList).
+func (q *Queries) GetH5PLibraryEditorDependencyTree(ctx context.Context, libraryID uuid.UUID) ([]H5pLibrary, error) {
+	rows, err := q.db.QueryContext(ctx, getH5PLibraryEditorDependencyTree, libraryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []H5pLibrary
+	for rows.Next() {
+		var i H5pLibrary
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.MachineName,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.PatchVersion,
+			&i.Title,
+			&i.Origin,
+			&i.MetadataJson,
+			pq.Array(&i.Categories),
+			pq.Array(&i.Keywords),
+			pq.Array(&i.Screenshots),
+			&i.Description,
+			&i.IconPath,
+			&i.PackagePath,
+			&i.ExtractedPath,
+			&i.Runnable,
+			&i.Restricted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getH5PLibraryExportTree = `-- name: GetH5PLibraryExportTree :many
+WITH RECURSIVE lib_tree AS (
+    SELECT unnest($1::uuid[]) AS library_id, 0 AS depth
+    UNION
+    SELECT d.depends_on_id, lt.depth + 1
+    FROM h5p_library_dependencies d
+    JOIN lib_tree lt ON lt.library_id = d.library_id
+    WHERE lt.depth < 20
+)
+SELECT l.id, l.created_at, l.updated_at, l.machine_name, l.major_version, l.minor_version, l.patch_version, l.title, l.origin, l.metadata_json, l.categories, l.keywords, l.screenshots, l.description, l.icon_path, l.package_path, l.extracted_path, l.runnable, l.restricted
+FROM h5p_libraries l
+WHERE l.id IN (SELECT library_id FROM lib_tree)
+ORDER BY l.machine_name, l.major_version, l.minor_version
+`
+
+// Returns the given libraries and everything they depend on, transitively and
+// over every dependency type, as an exported .h5p package carries them.
+func (q *Queries) GetH5PLibraryExportTree(ctx context.Context, libraryIds []uuid.UUID) ([]H5pLibrary, error) {
+	rows, err := q.db.QueryContext(ctx, getH5PLibraryExportTree, pq.Array(libraryIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []H5pLibrary
+	for rows.Next() {
+		var i H5pLibrary
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.MachineName,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.PatchVersion,
+			&i.Title,
+			&i.Origin,
+			&i.MetadataJson,
+			pq.Array(&i.Categories),
+			pq.Array(&i.Keywords),
+			pq.Array(&i.Screenshots),
+			&i.Description,
+			&i.IconPath,
+			&i.PackagePath,
+			&i.ExtractedPath,
+			&i.Runnable,
+			&i.Restricted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getH5PLibraryFullDependencyTree = `-- name: GetH5PLibraryFullDependencyTree :many
+WITH RECURSIVE dep_tree AS (
+    SELECT d.depends_on_id AS library_id, 0 AS depth
+    FROM h5p_library_dependencies d
+    WHERE d.library_id = $1
+      AND d.dependency_type = 'preloaded'
+    UNION
+    SELECT d.depends_on_id, dt.depth + 1
+    FROM h5p_library_dependencies d
+    JOIN dep_tree dt ON dt.library_id = d.library_id
+    WHERE dt.depth < 20
+      AND d.dependency_type = 'preloaded'
+),
+dep_max_depth AS (
+    SELECT library_id, MAX(depth) AS max_depth
+    FROM dep_tree
+    GROUP BY library_id
+)
+SELECT l.id, l.created_at, l.updated_at, l.machine_name, l.major_version, l.minor_version, l.patch_version, l.title, l.origin, l.metadata_json, l.categories, l.keywords, l.screenshots, l.description, l.icon_path, l.package_path, l.extracted_path, l.runnable, l.restricted
+FROM dep_max_depth dmd
+JOIN h5p_libraries l ON l.id = dmd.library_id
+ORDER BY dmd.max_depth DESC
+`
+
+// Returns all transitive PRELOADED dependencies ordered deepest-first (topological).
+// This ensures leaf dependencies (e.g. H5P.EventDispatcher) load before
+// libraries that extend them (e.g. H5P.Question).
+// Only follows 'preloaded' dependencies — editor and dynamic deps are excluded
+// so that playback doesn't try to load editor-only libraries (H5PEditor.*).
+func (q *Queries) GetH5PLibraryFullDependencyTree(ctx context.Context, libraryID uuid.UUID) ([]H5pLibrary, error) {
+	rows, err := q.db.QueryContext(ctx, getH5PLibraryFullDependencyTree, libraryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []H5pLibrary
+	for rows.Next() {
+		var i H5pLibrary
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.MachineName,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.PatchVersion,
+			&i.Title,
+			&i.Origin,
+			&i.MetadataJson,
+			pq.Array(&i.Categories),
+			pq.Array(&i.Keywords),
+			pq.Array(&i.Screenshots),
+			&i.Description,
+			&i.IconPath,
+			&i.PackagePath,
+			&i.ExtractedPath,
+			&i.Runnable,
+			&i.Restricted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getH5PLibrarySemanticsCache = `-- name: GetH5PLibrarySemanticsCache :one
+
+SELECT library_id, created_at, semantics FROM h5p_library_semantics_cache WHERE library_id = $1
+`
+
+// =============================================================================
+// H5P Library Semantics Cache
+// =============================================================================
+func (q *Queries) GetH5PLibrarySemanticsCache(ctx context.Context, libraryID uuid.UUID) (H5pLibrarySemanticsCache, error) {
+	row := q.db.QueryRowContext(ctx, getH5PLibrarySemanticsCache, libraryID)
+	var i H5pLibrarySemanticsCache
+	err := row.Scan(&i.LibraryID, &i.CreatedAt, &i.Semantics)
+	return i, err
+}
+
+const getH5PMediaAsset = `-- name: GetH5PMediaAsset :one
+SELECT id, org_id, uploaded_by, filename, mime_type, kind, size_bytes, width, height, checksum, storage_key, thumbnail_key, use_count, created_at, last_used_at FROM h5p_media_assets WHERE id = $1 AND org_id = $2
+`
+
+type GetH5PMediaAssetParams struct {
+	ID    uuid.UUID `json:"id"`
+	OrgID uuid.UUID `json:"org_id"`
+}
+
+func (q *Queries) GetH5PMediaAsset(ctx context.Context, arg GetH5PMediaAssetParams) (H5pMediaAsset, error) {
+	row := q.db.QueryRowContext(ctx, getH5PMediaAsset, arg.ID, arg.OrgID)
+	var i H5pMediaAsset
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.UploadedBy,
+		&i.Filename,
+		&i.MimeType,
+		&i.Kind,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.Checksum,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+		&i.UseCount,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getH5PMediaAssetByChecksum = `-- name: GetH5PMediaAssetByChecksum :one
+SELECT id, org_id, uploaded_by, filename, mime_type, kind, size_bytes, width, height, checksum, storage_key, thumbnail_key, use_count, created_at, last_used_at FROM h5p_media_assets WHERE org_id = $1 AND checksum = $2
+`
+
+type GetH5PMediaAssetByChecksumParams struct {
+	OrgID    uuid.UUID `json:"org_id"`
+	Checksum string    `json:"checksum"`
+}
+
+func (q *Queries) GetH5PMediaAssetByChecksum(ctx context.Context, arg GetH5PMediaAssetByChecksumParams) (H5pMediaAsset, error) {
+	row := q.db.QueryRowContext(ctx, getH5PMediaAssetByChecksum, arg.OrgID, arg.Checksum)
+	var i H5pMediaAsset
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.UploadedBy,
+		&i.Filename,
+		&i.MimeType,
+		&i.Kind,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.Checksum,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+		&i.UseCount,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getH5PProfileApplication = `-- name: GetH5PProfileApplication :one
+SELECT id, organisation_id, profile_id, profile_name, libraries, status, summary, requested_by, created_at, completed_at FROM h5p_profile_applications WHERE id = $1
+`
+
+func (q *Queries) GetH5PProfileApplication(ctx context.Context, id uuid.UUID) (H5pProfileApplication, error) {
+	row := q.db.QueryRowContext(ctx, getH5PProfileApplication, id)
+	var i H5pProfileApplication
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.ProfileID,
+		&i.ProfileName,
+		pq.Array(&i.Libraries),
+		&i.Status,
+		&i.Summary,
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getLatestCompetitorSnapshot = `-- name: GetLatestCompetitorSnapshot :one
+SELECT id, organisation_id, target, domain, location_code, language_code, captured_at, organic_etv, organic_keywords, top3_keywords, top10_keywords, domain_rank, backlinks, referring_domains FROM competitor_snapshots
+WHERE organisation_id = $1 AND target = $2 AND location_code = $3 AND language_code = $4 AND domain = $5
+ORDER BY captured_at DESC
+LIMIT 1
+`
+
+type GetLatestCompetitorSnapshotParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Target         string    `json:"target"`
+	LocationCode   int32     `json:"location_code"`
+	LanguageCode   string    `json:"language_code"`
+	Domain         string    `json:"domain"`
+}
+
+func (q *Queries) GetLatestCompetitorSnapshot(ctx context.Context, arg GetLatestCompetitorSnapshotParams) (CompetitorSnapshot, error) {
+	row := q.db.QueryRowContext(ctx, getLatestCompetitorSnapshot,
+		arg.OrganisationID,
+		arg.Target,
+		arg.LocationCode,
+		arg.LanguageCode,
+		arg.Domain,
+	)
+	var i CompetitorSnapshot
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Target,
+		&i.Domain,
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.CapturedAt,
+		&i.OrganicEtv,
+		&i.OrganicKeywords,
+		&i.Top3Keywords,
+		&i.Top10Keywords,
+		&i.DomainRank,
+		&i.Backlinks,
+		&i.ReferringDomains,
+	)
+	return i, err
+}
+
+const getLatestRankTrackerRun = `-- name: GetLatestRankTrackerRun :one
+SELECT id, tracker_id, organisation_id, created_at, completed_at, status, keyword_count, failed_count, cost, share_of_voice, error FROM rank_tracker_runs
+WHERE tracker_id = $1 AND status IN ('completed', 'partial')
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestRankTrackerRun(ctx context.Context, trackerID uuid.UUID) (RankTrackerRun, error) {
+	row := q.db.QueryRowContext(ctx, getLatestRankTrackerRun, trackerID)
+	var i RankTrackerRun
+	err := row.Scan(
+		&i.ID,
+		&i.TrackerID,
+		&i.OrganisationID,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.Status,
+		&i.KeywordCount,
+		&i.FailedCount,
+		&i.Cost,
+		&i.ShareOfVoice,
+		&i.Error,
+	)
+	return i, err
+}
+
+const getOrgMemberIdentity = `-- name: GetOrgMemberIdentity :one
+SELECT m.display_name, u.email FROM organisation_memberships m
+JOIN users u ON u.id = m.user_id
+WHERE m.user_id = $1 AND m.organisation_id = $2 AND m.status = 'active'
+LIMIT 1
+`
+
+type GetOrgMemberIdentityParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+type GetOrgMemberIdentityRow struct {
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email"`
+}
+
+func (q *Queries) GetOrgMemberIdentity(ctx context.Context, arg GetOrgMemberIdentityParams) (GetOrgMemberIdentityRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrgMemberIdentity, arg.UserID, arg.OrganisationID)
+	var i GetOrgMemberIdentityRow
+	err := row.Scan(&i.DisplayName, &i.Email)
+	return i, err
+}
+
+const getOrgMembershipRole = `-- name: GetOrgMembershipRole :one
+SELECT m.role FROM organisation_memberships m
+JOIN organisations o ON o.id = m.organisation_id
+WHERE m.user_id = $1 AND m.organisation_id = $2 AND m.status = 'active' AND o.deleted_at IS NULL
+LIMIT 1
+`
+
+type GetOrgMembershipRoleParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) GetOrgMembershipRole(ctx context.Context, arg GetOrgMembershipRoleParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getOrgMembershipRole, arg.UserID, arg.OrganisationID)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getOrganisationBillingContact = `-- name: GetOrganisationBillingContact :one
+
+SELECT
+    COALESCE(NULLIF(bc.name, ''), o.name)::text AS name,
+    COALESCE(NULLIF(bc.email, ''), NULLIF(o.email, ''), (
+        SELECT u.email FROM organisation_memberships m
+        JOIN users u ON u.id = m.user_id
+        WHERE m.organisation_id = o.id AND m.role = 'owner' AND m.status = 'active'
+        ORDER BY m.created_at
+        LIMIT 1
+    ), '')::text AS email
+FROM organisations o
+LEFT JOIN organisation_billing_contacts bc ON bc.organisation_id = o.id
+WHERE o.id = $1
+`
+
+type GetOrganisationBillingContactRow struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// =============================================================================
+// Billing Admin (Refunds & Credits)
+// =============================================================================
+func (q *Queries) GetOrganisationBillingContact(ctx context.Context, id uuid.UUID) (GetOrganisationBillingContactRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrganisationBillingContact, id)
+	var i GetOrganisationBillingContactRow
+	err := row.Scan(&i.Name, &i.Email)
+	return i, err
+}
+
+const getOrganisationBillingInfo = `-- name: GetOrganisationBillingInfo :one
+
+SELECT
+    id,
+    name,
+    slug,
+    subscription_tier,
+    subscription_id,
+    subscription_end,
+    stripe_customer_id,
+    ai_generations_this_month,
+    ai_generations_reset_at,
+    is_freemium,
+    freemium_expires_at,
+    locale,
+    timezone
+FROM organisations
+WHERE id = $1
+`
+
+type GetOrganisationBillingInfoRow struct {
+	ID                     uuid.UUID    `json:"id"`
+	Name                   string       `json:"name"`
+	Slug                   string       `json:"slug"`
+	SubscriptionTier       string       `json:"subscription_tier"`
+	SubscriptionID         string       `json:"subscription_id"`
+	SubscriptionEnd        sql.NullTime `json:"subscription_end"`
+	StripeCustomerID       string       `json:"stripe_customer_id"`
+	AiGenerationsThisMonth int32        `json:"ai_generations_this_month"`
+	AiGenerationsResetAt   sql.NullTime `json:"ai_generations_reset_at"`
+	IsFreemium             bool         `json:"is_freemium"`
+	FreemiumExpiresAt      sql.NullTime `json:"freemium_expires_at"`
+	Locale                 string       `json:"locale"`
+	Timezone               string       `json:"timezone"`
+}
+
+// =============================================================================
+// Organisation Billing Queries (Platform Subscriptions)
+// =============================================================================
+func (q *Queries) GetOrganisationBillingInfo(ctx context.Context, id uuid.UUID) (GetOrganisationBillingInfoRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrganisationBillingInfo, id)
+	var i GetOrganisationBillingInfoRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Slug,
+		&i.SubscriptionTier,
+		&i.SubscriptionID,
+		&i.SubscriptionEnd,
+		&i.StripeCustomerID,
+		&i.AiGenerationsThisMonth,
+		&i.AiGenerationsResetAt,
+		&i.IsFreemium,
+		&i.FreemiumExpiresAt,
+		&i.Locale,
+		&i.Timezone,
+	)
+	return i, err
+}
+
+const getOrganisationByStripeCustomer = `-- name: GetOrganisationByStripeCustomer :one
+SELECT id, created_at, updated_at, name, slug, logo_url, logo_avatar_url, primary_color, secondary_color, accent_color, accent_gradient, email, phone, website, status, subscription_tier, subscription_id, subscription_end, stripe_customer_id, ai_generations_this_month, ai_generations_reset_at, is_freemium, freemium_reason, freemium_expires_at, freemium_granted_at, freemium_granted_by, deleted_at, deletion_scheduled_for, locale, timezone FROM organisations
+WHERE stripe_customer_id = $1
+`
+
+func (q *Queries) GetOrganisationByStripeCustomer(ctx context.Context, stripeCustomerID string) (Organisation, error) {
+	row := q.db.QueryRowContext(ctx, getOrganisationByStripeCustomer, stripeCustomerID)
+	var i Organisation
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Slug,
+		&i.LogoUrl,
+		&i.LogoAvatarUrl,
+		&i.PrimaryColor,
+		&i.SecondaryColor,
+		&i.AccentColor,
+		&i.AccentGradient,
+		&i.Email,
+		&i.Phone,
+		&i.Website,
+		&i.Status,
+		&i.SubscriptionTier,
+		&i.SubscriptionID,
+		&i.SubscriptionEnd,
+		&i.StripeCustomerID,
+		&i.AiGenerationsThisMonth,
+		&i.AiGenerationsResetAt,
+		&i.IsFreemium,
+		&i.FreemiumReason,
+		&i.FreemiumExpiresAt,
+		&i.FreemiumGrantedAt,
+		&i.FreemiumGrantedBy,
+		&i.DeletedAt,
+		&i.DeletionScheduledFor,
+		&i.Locale,
+		&i.Timezone,
+	)
+	return i, err
+}
+
+const getOrganisationLocale = `-- name: GetOrganisationLocale :one
+SELECT locale, timezone FROM organisations
+WHERE id = $1
+`
+
+type GetOrganisationLocaleRow struct {
+	Locale   string `json:"locale"`
+	Timezone string `json:"timezone"`
+}
+
+func (q *Queries) GetOrganisationLocale(ctx context.Context, id uuid.UUID) (GetOrganisationLocaleRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrganisationLocale, id)
+	var i GetOrganisationLocaleRow
+	err := row.Scan(&i.Locale, &i.Timezone)
+	return i, err
+}
+
+const getOrganisationSeoPlan = `-- name: GetOrganisationSeoPlan :one
+
+SELECT subscription_tier, is_freemium, freemium_expires_at
+FROM organisations
+WHERE id = $1
+`
+
+type GetOrganisationSeoPlanRow struct {
+	SubscriptionTier  string       `json:"subscription_tier"`
+	IsFreemium        bool         `json:"is_freemium"`
+	FreemiumExpiresAt sql.NullTime `json:"freemium_expires_at"`
+}
+
+// =============================================================================
+// SEO Schedules
+// =============================================================================
+func (q *Queries) GetOrganisationSeoPlan(ctx context.Context, id uuid.UUID) (GetOrganisationSeoPlanRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrganisationSeoPlan, id)
+	var i GetOrganisationSeoPlanRow
+	err := row.Scan(&i.SubscriptionTier, &i.IsFreemium, &i.FreemiumExpiresAt)
+	return i, err
+}
+
+const getOrganisationStorageQuota = `-- name: GetOrganisationStorageQuota :one
+
+SELECT
+    o.subscription_tier,
+    o.is_freemium,
+    o.freemium_expires_at,
+    COALESCE(su.bytes_used, 0)::bigint AS bytes_used
+FROM organisations o
+LEFT JOIN organisation_storage_usage su ON su.organisation_id = o.id
+WHERE o.id = $1
+`
+
+type GetOrganisationStorageQuotaRow struct {
+	SubscriptionTier  string       `json:"subscription_tier"`
+	IsFreemium        bool         `json:"is_freemium"`
+	FreemiumExpiresAt sql.NullTime `json:"freemium_expires_at"`
+	BytesUsed         int64        `json:"bytes_used"`
+}
+
+// =============================================================================
+// Organisation Storage Usage
+// =============================================================================
+func (q *Queries) GetOrganisationStorageQuota(ctx context.Context, id uuid.UUID) (GetOrganisationStorageQuotaRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrganisationStorageQuota, id)
+	var i GetOrganisationStorageQuotaRow
+	err := row.Scan(
+		&i.SubscriptionTier,
+		&i.IsFreemium,
+		&i.FreemiumExpiresAt,
+		&i.BytesUsed,
+	)
+	return i, err
+}
+
+const getOrganisationWebhook = `-- name: GetOrganisationWebhook :one
+SELECT id, created_at, updated_at, organisation_id, url, secret, events, active, last_delivery_at, last_delivery_status FROM organisation_webhooks
+WHERE id = $1 AND organisation_id = $2
+`
+
+type GetOrganisationWebhookParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) GetOrganisationWebhook(ctx context.Context, arg GetOrganisationWebhookParams) (OrganisationWebhook, error) {
+	row := q.db.QueryRowContext(ctx, getOrganisationWebhook, arg.ID, arg.OrganisationID)
+	var i OrganisationWebhook
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganisationID,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.Active,
+		&i.LastDeliveryAt,
+		&i.LastDeliveryStatus,
+	)
+	return i, err
+}
+
+const getPendingDeletionBacklog = `-- name: GetPendingDeletionBacklog :many
+SELECT reason,
+    COUNT(*) AS pending,
+    COUNT(*) FILTER (WHERE next_attempt_at <= now()) AS due,
+    COUNT(*) FILTER (WHERE attempts >= $1) AS stuck,
+    MIN(created_at)::timestamptz AS oldest_created_at
+FROM pending_deletions
+GROUP BY reason
+ORDER BY reason
+`
+
+type GetPendingDeletionBacklogRow struct {
+	Reason          string    `json:"reason"`
+	Pending         int64     `json:"pending"`
+	Due             int64     `json:"due"`
+	Stuck           int64     `json:"stuck"`
+	OldestCreatedAt time.Time `json:"oldest_created_at"`
+}
+
+func (q *Queries) GetPendingDeletionBacklog(ctx context.Context, attempts int32) ([]GetPendingDeletionBacklogRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingDeletionBacklog, attempts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPendingDeletionBacklogRow
+	for rows.Next() {
+		var i GetPendingDeletionBacklogRow
+		if err := rows.Scan(
+			&i.Reason,
+			&i.Pending,
+			&i.Due,
+			&i.Stuck,
+			&i.OldestCreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPreviousSeoScheduleRun = `-- name: GetPreviousSeoScheduleRun :one
+SELECT id, schedule_id, organisation_id, created_at, completed_at, status, audit_id, rank_check_id, summary, deltas, error FROM seo_schedule_runs
+WHERE schedule_id = $1 AND created_at < $2 AND status IN ('completed', 'partial')
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetPreviousSeoScheduleRunParams struct {
+	ScheduleID uuid.UUID `json:"schedule_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetPreviousSeoScheduleRun(ctx context.Context, arg GetPreviousSeoScheduleRunParams) (SeoScheduleRun, error) {
+	row := q.db.QueryRowContext(ctx, getPreviousSeoScheduleRun, arg.ScheduleID, arg.CreatedAt)
+	var i SeoScheduleRun
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduleID,
+		&i.OrganisationID,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.Status,
+		&i.AuditID,
+		&i.RankCheckID,
+		&i.Summary,
+		&i.Deltas,
+		&i.Error,
+	)
+	return i, err
+}
+
+const getRankTracker = `-- name: GetRankTracker :one
+SELECT id, organisation_id, domain, location_code, language_code, device, competitors, created_by, created_at, last_run_at, next_run_at, volumes_refreshed_at FROM rank_trackers
+WHERE id = $1 AND organisation_id = $2
+`
+
+type GetRankTrackerParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) GetRankTracker(ctx context.Context, arg GetRankTrackerParams) (RankTracker, error) {
+	row := q.db.QueryRowContext(ctx, getRankTracker, arg.ID, arg.OrganisationID)
+	var i RankTracker
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Domain,
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.Device,
+		pq.Array(&i.Competitors),
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LastRunAt,
+		&i.NextRunAt,
+		&i.VolumesRefreshedAt,
+	)
+	return i, err
+}
+
+const getRankTrackerRunBefore = `-- name: GetRankTrackerRunBefore :one
+SELECT id, tracker_id, organisation_id, created_at, completed_at, status, keyword_count, failed_count, cost, share_of_voice, error FROM rank_tracker_runs
+WHERE tracker_id = $1 AND created_at <= $2 AND status IN ('completed', 'partial')
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetRankTrackerRunBeforeParams struct {
+	TrackerID uuid.UUID `json:"tracker_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetRankTrackerRunBefore(ctx context.Context, arg GetRankTrackerRunBeforeParams) (RankTrackerRun, error) {
+	row := q.db.QueryRowContext(ctx, getRankTrackerRunBefore, arg.TrackerID, arg.CreatedAt)
+	var i RankTrackerRun
+	err := row.Scan(
+		&i.ID,
+		&i.TrackerID,
+		&i.OrganisationID,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.Status,
+		&i.KeywordCount,
+		&i.FailedCount,
+		&i.Cost,
+		&i.ShareOfVoice,
+		&i.Error,
+	)
+	return i, err
+}
+
+const getRetentionPolicy = `-- name: GetRetentionPolicy :one
+
+SELECT organisation_id, archive_drafts_after_months, purge_deleted_after_days, retain_xapi_years, updated_by, created_at, updated_at, evaluated_at FROM organisation_retention_policies
+WHERE organisation_id = $1
+`
+
+// =============================================================================
+// Retention Policies
+// =============================================================================
+func (q *Queries) GetRetentionPolicy(ctx context.Context, organisationID uuid.UUID) (OrganisationRetentionPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getRetentionPolicy, organisationID)
+	var i OrganisationRetentionPolicy
+	err := row.Scan(
+		&i.OrganisationID,
+		&i.ArchiveDraftsAfterMonths,
+		&i.PurgeDeletedAfterDays,
+		&i.RetainXapiYears,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EvaluatedAt,
+	)
+	return i, err
+}
+
+const getSeoKeywordList = `-- name: GetSeoKeywordList :one
+SELECT id, organisation_id, name, location_code, language_code, created_by, created_at, last_refreshed_at, next_refresh_at FROM seo_keyword_lists
+WHERE id = $1 AND organisation_id = $2
+`
+
+type GetSeoKeywordListParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) GetSeoKeywordList(ctx context.Context, arg GetSeoKeywordListParams) (SeoKeywordList, error) {
+	row := q.db.QueryRowContext(ctx, getSeoKeywordList, arg.ID, arg.OrganisationID)
+	var i SeoKeywordList
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Name,
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LastRefreshedAt,
+		&i.NextRefreshAt,
+	)
+	return i, err
+}
+
+const getSeoRankCheck = `-- name: GetSeoRankCheck :one
+SELECT id, organisation_id, created_by, created_at, completed_at, domain, location_code, language_code, device, keyword_count, status, cost, error, results FROM seo_rank_checks
+WHERE id = $1 AND organisation_id = $2
+`
+
+type GetSeoRankCheckParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) GetSeoRankCheck(ctx context.Context, arg GetSeoRankCheckParams) (SeoRankCheck, error) {
+	row := q.db.QueryRowContext(ctx, getSeoRankCheck, arg.ID, arg.OrganisationID)
+	var i SeoRankCheck
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.Domain,
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.Device,
+		&i.KeywordCount,
+		&i.Status,
+		&i.Cost,
+		&i.Error,
+		&i.Results,
+	)
+	return i, err
+}
+
+const getSeoSchedule = `-- name: GetSeoSchedule :one
+SELECT id, organisation_id, domain, pages, keywords, location_code, language_code, device, strategy, created_by, created_at, last_run_at, next_run_at FROM seo_schedules
+WHERE id = $1 AND organisation_id = $2
+`
+
+type GetSeoScheduleParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) GetSeoSchedule(ctx context.Context, arg GetSeoScheduleParams) (SeoSchedule, error) {
+	row := q.db.QueryRowContext(ctx, getSeoSchedule, arg.ID, arg.OrganisationID)
+	var i SeoSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Domain,
+		pq.Array(&i.Pages),
+		pq.Array(&i.Keywords),
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.Device,
+		&i.Strategy,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LastRunAt,
+		&i.NextRunAt,
+	)
+	return i, err
+}
+
+const getUserRatePlan = `-- name: GetUserRatePlan :one
+
+SELECT o.id AS organisation_id, o.subscription_tier, o.is_freemium, o.freemium_expires_at
+FROM users u
+JOIN organisations o ON o.id = u.default_organisation_id
+WHERE u.id = $1
+`
+
+type GetUserRatePlanRow struct {
+	OrganisationID    uuid.UUID    `json:"organisation_id"`
+	SubscriptionTier  string       `json:"subscription_tier"`
+	IsFreemium        bool         `json:"is_freemium"`
+	FreemiumExpiresAt sql.NullTime `json:"freemium_expires_at"`
+}
+
+// =============================================================================
+// API Rate Limits
+// =============================================================================
+func (q *Queries) GetUserRatePlan(ctx context.Context, id uuid.UUID) (GetUserRatePlanRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserRatePlan, id)
+	var i GetUserRatePlanRow
+	err := row.Scan(
+		&i.OrganisationID,
+		&i.SubscriptionTier,
+		&i.IsFreemium,
+		&i.FreemiumExpiresAt,
+	)
+	return i, err
+}
+
+const getXapiContentSummary = `-- name: GetXapiContentSummary :one
+WITH learners AS (
+    SELECT COALESCE(user_id, actor_id) AS learner,
+        count(*) AS statements,
+        bool_or(result_completion IS TRUE OR verb = 'completed') AS completed,
+        bool_or(result_success IS TRUE) AS passed,
+        max(result_score_scaled) AS best_score
+    FROM xapi_statements
+    WHERE org_id = $1 AND content_id = $2
+    GROUP BY 1
+)
+SELECT count(*) AS learners,
+    COALESCE(sum(statements), 0)::bigint AS statements,
+    count(*) FILTER (WHERE completed) AS completed,
+    count(*) FILTER (WHERE passed) AS passed,
+    count(best_score) AS scored,
+    COALESCE(avg(best_score), 0)::float8 AS average_best_score
+FROM learners
+`
+
+type GetXapiContentSummaryParams struct {
+	OrgID     uuid.UUID     `json:"org_id"`
+	ContentID uuid.NullUUID `json:"content_id"`
+}
+
+type GetXapiContentSummaryRow struct {
+	Learners         int64   `json:"learners"`
+	Statements       int64   `json:"statements"`
+	Completed        int64   `json:"completed"`
+	Passed           int64   `json:"passed"`
+	Scored           int64   `json:"scored"`
+	AverageBestScore float64 `json:"average_best_score"`
+}
+
+func (q *Queries) GetXapiContentSummary(ctx context.Context, arg GetXapiContentSummaryParams) (GetXapiContentSummaryRow, error) {
+	row := q.db.QueryRowContext(ctx, getXapiContentSummary, arg.OrgID, arg.ContentID)
+	var i GetXapiContentSummaryRow
+	err := row.Scan(
+		&i.Learners,
+		&i.Statements,
+		&i.Completed,
+		&i.Passed,
+		&i.Scored,
+		&i.AverageBestScore,
+	)
+	return i, err
+}
+
+const insertH5PLibraryDependency = `-- name: InsertH5PLibraryDependency :exec
+
+INSERT INTO h5p_library_dependencies (id, library_id, depends_on_id, dependency_type)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (library_id, depends_on_id, dependency_type) DO NOTHING
+`
+
+type InsertH5PLibraryDependencyParams struct {
+	ID             uuid.UUID `json:"id"`
+	LibraryID      uuid.UUID `json:"library_id"`
+	DependsOnID    uuid.UUID `json:"depends_on_id"`
+	DependencyType string    `json:"dependency_type"`
+}
+
+// =============================================================================
+// H5P Library Dependencies
+// =============================================================================
+func (q *Queries) InsertH5PLibraryDependency(ctx context.Context, arg InsertH5PLibraryDependencyParams) error {
+	_, err := q.db.ExecContext(ctx, insertH5PLibraryDependency,
+		arg.ID,
+		arg.LibraryID,
+		arg.DependsOnID,
+		arg.DependencyType,
+	)
+	return err
+}
+
+const insertOrganisationActivity = `-- name: InsertOrganisationActivity :exec
+INSERT INTO organisation_activity_log (organisation_id, user_id, action, entity_type, entity_id, new_values, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type InsertOrganisationActivityParams struct {
+	OrganisationID uuid.UUID             `json:"organisation_id"`
+	UserID         uuid.NullUUID         `json:"user_id"`
+	Action         string                `json:"action"`
+	EntityType     string                `json:"entity_type"`
+	EntityID       uuid.NullUUID         `json:"entity_id"`
+	NewValues      pqtype.NullRawMessage `json:"new_values"`
+	Metadata       json.RawMessage       `json:"metadata"`
+}
+
+func (q *Queries) InsertOrganisationActivity(ctx context.Context, arg InsertOrganisationActivityParams) error {
+	_, err := q.db.ExecContext(ctx, insertOrganisationActivity,
+		arg.OrganisationID,
+		arg.UserID,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.NewValues,
+		arg.Metadata,
+	)
+	return err
+}
+
+const insertOrganisationActivityChange = `-- name: InsertOrganisationActivityChange :exec
+INSERT INTO organisation_activity_log (organisation_id, user_id, action, entity_type, entity_id, old_values, new_values, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type InsertOrganisationActivityChangeParams struct {
+	OrganisationID uuid.UUID             `json:"organisation_id"`
+	UserID         uuid.NullUUID         `json:"user_id"`
+	Action         string                `json:"action"`
+	EntityType     string                `json:"entity_type"`
+	EntityID       uuid.NullUUID         `json:"entity_id"`
+	OldValues      pqtype.NullRawMessage `json:"old_values"`
+	NewValues      pqtype.NullRawMessage `json:"new_values"`
+	Metadata       json.RawMessage       `json:"metadata"`
+}
+
+func (q *Queries) InsertOrganisationActivityChange(ctx context.Context, arg InsertOrganisationActivityChangeParams) error {
+	_, err := q.db.ExecContext(ctx, insertOrganisationActivityChange,
+		arg.OrganisationID,
+		arg.UserID,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.OldValues,
+		arg.NewValues,
+		arg.Metadata,
+	)
+	return err
+}
+
+const insertToken = `-- name: InsertToken :one
+insert into tokens (id, expires, target, callback) values ($1, $2, $3, $4) returning id, expires, target, callback
+`
+
+type InsertTokenParams struct {
+	ID       string    `json:"id"`
+	Expires  time.Time `json:"expires"`
+	Target   string    `json:"target"`
+	Callback string    `json:"callback"`
+}
+
+func (q *Queries) InsertToken(ctx context.Context, arg InsertTokenParams) (Token, error) {
+	row := q.db.QueryRowContext(ctx, insertToken,
+		arg.ID,
+		arg.Expires,
+		arg.Target,
+		arg.Callback,
+	)
+	var i Token
+	err := row.Scan(
+		&i.ID,
+		&i.Expires,
+		&i.Target,
+		&i.Callback,
+	)
+	return i, err
+}
+
+const insertUser = `-- name: InsertUser :one
+insert into users (id, email, access, sub, avatar, api_key) values ($1, $2, $3, $4, $5, $6) returning id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_organisation_id, suspended, suspended_at, suspended_reason
+`
+
+type InsertUserParams struct {
+	ID     uuid.UUID `json:"id"`
+	Email  string    `json:"email"`
+	Access int64     `json:"access"`
+	Sub    string    `json:"sub"`
+	Avatar string    `json:"avatar"`
+	ApiKey string    `json:"api_key"`
+}
+
+func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, insertUser,
+		arg.ID,
+		arg.Email,
+		arg.Access,
+		arg.Sub,
+		arg.Avatar,
+		arg.ApiKey,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.Email,
+		&i.Phone,
+		&i.Access,
+		&i.Sub,
+		&i.Avatar,
+		&i.CustomerID,
+		&i.SubscriptionID,
+		&i.SubscriptionEnd,
+		&i.ApiKey,
+		&i.DefaultOrganisationID,
+		&i.Suspended,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+	)
+	return i, err
+}
+
+const insertXapiStatement = `-- name: InsertXapiStatement :one
+
+INSERT INTO xapi_statements (org_id, user_id, content_id, verb, statement)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, org_id, user_id, actor_id, content_id, verb, statement, statement_id, verb_id, object_id, result_score_raw, result_score_max, result_score_scaled, result_success, result_completion, result_duration
+`
+
+type InsertXapiStatementParams struct {
+	OrgID     uuid.UUID       `json:"org_id"`
+	UserID    uuid.NullUUID   `json:"user_id"`
+	ContentID uuid.NullUUID   `json:"content_id"`
+	Verb      string          `json:"verb"`
+	Statement json.RawMessage `json:"statement"`
+}
+
+// =============================================================================
+// XAPI & PROGRESS QUERIES (Phase 3)
+// =============================================================================
+func (q *Queries) InsertXapiStatement(ctx context.Context, arg InsertXapiStatementParams) (XapiStatement, error) {
+	row := q.db.QueryRowContext(ctx, insertXapiStatement,
+		arg.OrgID,
+		arg.UserID,
+		arg.ContentID,
+		arg.Verb,
+		arg.Statement,
+	)
+	var i XapiStatement
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.OrgID,
+		&i.UserID,
+		&i.ActorID,
+		&i.ContentID,
+		&i.Verb,
+		&i.Statement,
+		&i.StatementID,
+		&i.VerbID,
+		&i.ObjectID,
+		&i.ResultScoreRaw,
+		&i.ResultScoreMax,
+		&i.ResultScoreScaled,
+		&i.ResultSuccess,
+		&i.ResultCompletion,
+		&i.ResultDuration,
+	)
+	return i, err
+}
+
+const isJobLockHeld = `-- name: IsJobLockHeld :one
+SELECT EXISTS (
+    SELECT 1 FROM job_locks WHERE name = $1 AND expires_at > now()
+)::boolean AS held
+`
+
+func (q *Queries) IsJobLockHeld(ctx context.Context, name string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isJobLockHeld, name)
+	var held bool
+	err := row.Scan(&held)
+	return held, err
+}
+
+const issueCertificate = `-- name: IssueCertificate :one
+INSERT INTO certificates (org_id, user_id, course_id, enrolment_id, learner_name, course_title, organisation_name, completed_at)
+SELECT e.org_id, e.user_id, e.course_id, e.id,
+    COALESCE(NULLIF(m.display_name, ''), u.email), c.title, o.name, COALESCE(e.completed_at, NOW())
+FROM enrolments e
+JOIN courses c ON c.id = e.course_id
+JOIN organisations o ON o.id = e.org_id
+JOIN users u ON u.id = e.user_id
+LEFT JOIN organisation_memberships m ON m.user_id = e.user_id AND m.organisation_id = e.org_id
+WHERE e.id = $1 AND e.status = 'completed'
+ON CONFLICT (enrolment_id) DO NOTHING
+RETURNING id, org_id, user_id, course_id, enrolment_id, learner_name, course_title, organisation_name, completed_at, issued_at, storage_key
+`
+
+func (q *Queries) IssueCertificate(ctx context.Context, id uuid.UUID) (Certificate, error) {
+	row := q.db.QueryRowContext(ctx, issueCertificate, id)
+	var i Certificate
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.UserID,
+		&i.CourseID,
+		&i.EnrolmentID,
+		&i.LearnerName,
+		&i.CourseTitle,
+		&i.OrganisationName,
+		&i.CompletedAt,
+		&i.IssuedAt,
+		&i.StorageKey,
+	)
+	return i, err
+}
+
+const listAccessReviewMembers = `-- name: ListAccessReviewMembers :many
+SELECT m.user_id, u.email, m.display_name, m.role, m.status, m.created_at, m.invited_at, m.accepted_at,
+    inviter.email AS invited_by_email, u.sub, u.suspended, (u.api_key <> '')::boolean AS has_api_key
+FROM organisation_memberships m
+JOIN users u ON u.id = m.user_id
+LEFT JOIN users inviter ON inviter.id = m.invited_by
+WHERE m.organisation_id = $1
+ORDER BY m.status, m.role, u.email
+`
+
+type ListAccessReviewMembersRow struct {
+	UserID         uuid.UUID      `json:"user_id"`
+	Email          string         `json:"email"`
+	DisplayName    string         `json:"display_name"`
+	Role           string         `json:"role"`
+	Status         string         `json:"status"`
+	CreatedAt      time.Time      `json:"created_at"`
+	InvitedAt      sql.NullTime   `json:"invited_at"`
+	AcceptedAt     sql.NullTime   `json:"accepted_at"`
+	InvitedByEmail sql.NullString `json:"invited_by_email"`
+	Sub            string         `json:"sub"`
+	Suspended      bool           `json:"suspended"`
+	HasApiKey      bool           `json:"has_api_key"`
+}
+
+func (q *Queries) ListAccessReviewMembers(ctx context.Context, organisationID uuid.UUID) ([]ListAccessReviewMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccessReviewMembers, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccessReviewMembersRow
+	for rows.Next() {
+		var i ListAccessReviewMembersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Email,
+			&i.DisplayName,
+			&i.Role,
+			&i.Status,
+			&i.CreatedAt,
+			&i.InvitedAt,
+			&i.AcceptedAt,
+			&i.InvitedByEmail,
+			&i.Sub,
+			&i.Suspended,
+			&i.HasApiKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccessReviews = `-- name: ListAccessReviews :many
+SELECT id, organisation_id, requested_by, status, created_at, completed_at, expires_at, error FROM access_reviews
+WHERE organisation_id = $1
+ORDER BY created_at DESC
+LIMIT 100
+`
+
+func (q *Queries) ListAccessReviews(ctx context.Context, organisationID uuid.UUID) ([]AccessReview, error) {
+	rows, err := q.db.QueryContext(ctx, listAccessReviews, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccessReview
+	for rows.Next() {
+		var i AccessReview
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.RequestedBy,
+			&i.Status,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.ExpiresAt,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveOrganisationWebhooks = `-- name: ListActiveOrganisationWebhooks :many
+SELECT id, created_at, updated_at, organisation_id, url, secret, events, active, last_delivery_at, last_delivery_status FROM organisation_webhooks
+WHERE organisation_id = $1 AND active = true
+`
+
+func (q *Queries) ListActiveOrganisationWebhooks(ctx context.Context, organisationID uuid.UUID) ([]OrganisationWebhook, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveOrganisationWebhooks, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganisationWebhook
+	for rows.Next() {
+		var i OrganisationWebhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrganisationID,
+			&i.Url,
+			&i.Secret,
+			pq.Array(&i.Events),
+			&i.Active,
+			&i.LastDeliveryAt,
+			&i.LastDeliveryStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAnnouncements = `-- name: ListAnnouncements :many
+SELECT id, created_at, updated_at, title, body, link_url, tiers, flags, published_at, expires_at, created_by FROM announcements
+ORDER BY created_at DESC
+LIMIT 200
+`
+
+func (q *Queries) ListAnnouncements(ctx context.Context) ([]Announcement, error) {
+	rows, err := q.db.QueryContext(ctx, listAnnouncements)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Announcement
+	for rows.Next() {
+		var i Announcement
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Body,
+			&i.LinkUrl,
+			pq.Array(&i.Tiers),
+			pq.Array(&i.Flags),
+			&i.PublishedAt,
+			&i.ExpiresAt,
+			&i.CreatedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompetitorAlerts = `-- name: ListCompetitorAlerts :many
+SELECT id, organisation_id, pin_id, kind, message, keywords, amount, created_at FROM competitor_alerts
+WHERE organisation_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListCompetitorAlertsParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Limit          int32     `json:"limit"`
+}
+
+func (q *Queries) ListCompetitorAlerts(ctx context.Context, arg ListCompetitorAlertsParams) ([]CompetitorAlert, error) {
+	rows, err := q.db.QueryContext(ctx, listCompetitorAlerts, arg.OrganisationID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CompetitorAlert
+	for rows.Next() {
+		var i CompetitorAlert
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.PinID,
+			&i.Kind,
+			&i.Message,
+			pq.Array(&i.Keywords),
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompetitorKeywordGaps = `-- name: ListCompetitorKeywordGaps :many
+SELECT pin_id, keyword, search_volume, position, url, is_new, first_seen_at, last_seen_at FROM competitor_keyword_gaps
+WHERE pin_id = $1
+ORDER BY search_volume DESC NULLS LAST, keyword
+LIMIT $2 OFFSET $3
+`
+
+type ListCompetitorKeywordGapsParams struct {
+	PinID  uuid.UUID `json:"pin_id"`
+	Limit  int32     `json:"limit"`
+	Offset int32     `json:"offset"`
+}
+
+func (q *Queries) ListCompetitorKeywordGaps(ctx context.Context, arg ListCompetitorKeywordGapsParams) ([]CompetitorKeywordGap, error) {
+	rows, err := q.db.QueryContext(ctx, listCompetitorKeywordGaps, arg.PinID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CompetitorKeywordGap
+	for rows.Next() {
+		var i CompetitorKeywordGap
+		if err := rows.Scan(
+			&i.PinID,
+			&i.Keyword,
+			&i.SearchVolume,
+			&i.Position,
+			&i.Url,
+			&i.IsNew,
+			&i.FirstSeenAt,
+			&i.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompetitorPinsByOrg = `-- name: ListCompetitorPinsByOrg :many
+SELECT id, organisation_id, target, domain, location_code, language_code, created_by, created_at, refreshed_at, next_refresh_at FROM competitor_pins
+WHERE organisation_id = $1
+ORDER BY target, location_code, language_code, domain
+`
+
+func (q *Queries) ListCompetitorPinsByOrg(ctx context.Context, organisationID uuid.UUID) ([]CompetitorPin, error) {
+	rows, err := q.db.QueryContext(ctx, listCompetitorPinsByOrg, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CompetitorPin
+	for rows.Next() {
+		var i CompetitorPin
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Target,
+			&i.Domain,
+			&i.LocationCode,
+			&i.LanguageCode,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.RefreshedAt,
+			&i.NextRefreshAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompetitorPinsByTarget = `-- name: ListCompetitorPinsByTarget :many
+SELECT id, organisation_id, target, domain, location_code, language_code, created_by, created_at, refreshed_at, next_refresh_at FROM competitor_pins
+WHERE organisation_id = $1 AND target = $2 AND location_code = $3 AND language_code = $4
+ORDER BY domain
+`
+
+type ListCompetitorPinsByTargetParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Target         string    `json:"target"`
+	LocationCode   int32     `json:"location_code"`
+	LanguageCode   string    `json:"language_code"`
+}
+
+func (q *Queries) ListCompetitorPinsByTarget(ctx context.Context, arg ListCompetitorPinsByTargetParams) ([]CompetitorPin, error) {
+	rows, err := q.db.QueryContext(ctx, listCompetitorPinsByTarget,
+		arg.OrganisationID,
+		arg.Target,
+		arg.LocationCode,
+		arg.LanguageCode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CompetitorPin
+	for rows.Next() {
+		var i CompetitorPin
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Target,
+			&i.Domain,
+			&i.LocationCode,
+			&i.LanguageCode,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.RefreshedAt,
+			&i.NextRefreshAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContentResults = `-- name: ListContentResults :many
+SELECT COALESCE(x.user_id, x.actor_id)::uuid AS learner_id,
+    x.user_id,
+    u.email,
+    count(*) FILTER (WHERE x.verb = 'attempted') AS attempts,
+    bool_or(x.result_completion IS TRUE OR x.verb = 'completed')::bool AS finished,
+    bool_or(x.result_success IS TRUE)::bool AS passed,
+    count(x.result_score_scaled) AS scored,
+    COALESCE(max(x.result_score_scaled), 0)::float8 AS best_score,
+    count(*) FILTER (WHERE x.result_score_scaled IS NOT NULL
+        AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL) AS raw_scored,
+    COALESCE((array_agg(x.result_score_raw ORDER BY x.result_score_scaled DESC, x.created_at DESC)
+        FILTER (WHERE x.result_score_scaled IS NOT NULL
+            AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL))[1], 0)::float8 AS best_score_raw,
+    COALESCE((array_agg(x.result_score_max ORDER BY x.result_score_scaled DESC, x.created_at DESC)
+        FILTER (WHERE x.result_score_scaled IS NOT NULL
+            AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL))[1], 0)::float8 AS best_score_max,
+    min(x.created_at)::timestamptz AS first_activity_at,
+    max(x.created_at)::timestamptz AS last_activity_at
+FROM xapi_statements x
+LEFT JOIN users u ON u.id = x.user_id
+WHERE x.org_id = $1 AND x.content_id = $2
+GROUP BY COALESCE(x.user_id, x.actor_id), x.user_id, u.email
+ORDER BY u.email NULLS LAST, learner_id
+LIMIT $3 OFFSET $4
+`
+
+type ListContentResultsParams struct {
+	OrgID     uuid.UUID     `json:"org_id"`
+	ContentID uuid.NullUUID `json:"content_id"`
+	Limit     int32         `json:"limit"`
+	Offset    int32         `json:"offset"`
+}
+
+type ListContentResultsRow struct {
+	LearnerID       uuid.UUID      `json:"learner_id"`
+	UserID          uuid.NullUUID  `json:"user_id"`
+	Email           sql.NullString `json:"email"`
+	Attempts        int64          `json:"attempts"`
+	Finished        bool           `json:"finished"`
+	Passed          bool           `json:"passed"`
+	Scored          int64          `json:"scored"`
+	BestScore       float64        `json:"best_score"`
+	RawScored       int64          `json:"raw_scored"`
+	BestScoreRaw    float64        `json:"best_score_raw"`
+	BestScoreMax    float64        `json:"best_score_max"`
+	FirstActivityAt time.Time      `json:"first_activity_at"`
+	LastActivityAt  time.Time      `json:"last_activity_at"`
+}
+
+func (q *Queries) ListContentResults(ctx context.Context, arg ListContentResultsParams) ([]ListContentResultsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listContentResults,
+		arg.OrgID,
+		arg.ContentID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListContentResultsRow
+	for rows.Next() {
+		var i ListContentResultsRow
+		if err := rows.Scan(
+			&i.LearnerID,
+			&i.UserID,
+			&i.Email,
+			&i.Attempts,
+			&i.Finished,
+			&i.Passed,
+			&i.Scored,
+			&i.BestScore,
+			&i.RawScored,
+			&i.BestScoreRaw,
+			&i.BestScoreMax,
+			&i.FirstActivityAt,
+			&i.LastActivityAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCourseGradebookItems = `-- name: ListCourseGradebookItems :many
+SELECT ci.id, ci.content_id::uuid AS content_id,
+    COALESCE(NULLIF(ci.title, ''), c.title)::text AS title
+FROM course_items ci
+JOIN h5p_content c ON c.id = ci.content_id
+WHERE ci.course_id = $1 AND ci.item_type = 'h5p' AND ci.removed_at IS NULL AND c.deleted_at IS NULL
+ORDER BY ci.sort_order, ci.created_at
+`
+
+type ListCourseGradebookItemsRow struct {
+	ID        uuid.UUID `json:"id"`
+	ContentID uuid.UUID `json:"content_id"`
+	Title     string    `json:"title"`
+}
+
+func (q *Queries) ListCourseGradebookItems(ctx context.Context, courseID uuid.UUID) ([]ListCourseGradebookItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCourseGradebookItems, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCourseGradebookItemsRow
+	for rows.Next() {
+		var i ListCourseGradebookItemsRow
+		if err := rows.Scan(&i.ID, &i.ContentID, &i.Title); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCourseGradebookLearners = `-- name: ListCourseGradebookLearners :many
+SELECT COALESCE(e.user_id, e.actor_id)::uuid AS learner_id,
+    e.user_id,
+    u.email,
+    e.status,
+    e.enrolled_at,
+    e.completed_at
+FROM enrolments e
+LEFT JOIN users u ON u.id = e.user_id
+WHERE e.org_id = $1 AND e.course_id = $2 AND e.status <> 'withdrawn'
+ORDER BY u.email NULLS LAST, learner_id
+LIMIT $3 OFFSET $4
+`
+
+type ListCourseGradebookLearnersParams struct {
+	OrgID    uuid.UUID `json:"org_id"`
+	CourseID uuid.UUID `json:"course_id"`
+	Limit    int32     `json:"limit"`
+	Offset   int32     `json:"offset"`
+}
+
+type ListCourseGradebookLearnersRow struct {
+	LearnerID   uuid.UUID      `json:"learner_id"`
+	UserID      uuid.NullUUID  `json:"user_id"`
+	Email       sql.NullString `json:"email"`
+	Status      string         `json:"status"`
+	EnrolledAt  time.Time      `json:"enrolled_at"`
+	CompletedAt sql.NullTime   `json:"completed_at"`
+}
+
+func (q *Queries) ListCourseGradebookLearners(ctx context.Context, arg ListCourseGradebookLearnersParams) ([]ListCourseGradebookLearnersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCourseGradebookLearners,
+		arg.OrgID,
+		arg.CourseID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCourseGradebookLearnersRow
+	for rows.Next() {
+		var i ListCourseGradebookLearnersRow
+		if err := rows.Scan(
+			&i.LearnerID,
+			&i.UserID,
+			&i.Email,
+			&i.Status,
+			&i.EnrolledAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCourseGradebookResults = `-- name: ListCourseGradebookResults :many
+SELECT COALESCE(x.user_id, x.actor_id)::uuid AS learner_id,
+    x.content_id::uuid AS content_id,
+    count(*) FILTER (WHERE x.verb = 'attempted') AS attempts,
+    bool_or(x.result_completion IS TRUE OR x.verb = 'completed')::bool AS finished,
+    bool_or(x.result_success IS TRUE)::bool AS passed,
+    count(x.result_score_scaled) AS scored,
+    COALESCE(max(x.result_score_scaled), 0)::float8 AS best_score,
+    count(*) FILTER (WHERE x.result_score_scaled IS NOT NULL
+        AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL) AS raw_scored,
+    COALESCE((array_agg(x.result_score_raw ORDER BY x.result_score_scaled DESC, x.created_at DESC)
+        FILTER (WHERE x.result_score_scaled IS NOT NULL
+            AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL))[1], 0)::float8 AS best_score_raw,
+    COALESCE((array_agg(x.result_score_max ORDER BY x.result_score_scaled DESC, x.created_at DESC)
+        FILTER (WHERE x.result_score_scaled IS NOT NULL
+            AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL))[1], 0)::float8 AS best_score_max,
+    min(x.created_at)::timestamptz AS first_activity_at,
+    max(x.created_at)::timestamptz AS last_activity_at
+FROM xapi_statements x
+WHERE x.org_id = $1
+    AND x.content_id = ANY($2::uuid[])
+    AND COALESCE(x.user_id, x.actor_id) = ANY($3::uuid[])
+GROUP BY 1, 2
+`
+
+type ListCourseGradebookResultsParams struct {
+	OrgID      uuid.UUID   `json:"org_id"`
+	ContentIds []uuid.UUID `json:"content_ids"`
+	LearnerIds []uuid.UUID `json:"learner_ids"`
+}
+
+type ListCourseGradebookResultsRow struct {
+	LearnerID       uuid.UUID `json:"learner_id"`
+	ContentID       uuid.UUID `json:"content_id"`
+	Attempts        int64     `json:"attempts"`
+	Finished        bool      `json:"finished"`
+	Passed          bool      `json:"passed"`
+	Scored          int64     `json:"scored"`
+	BestScore       float64   `json:"best_score"`
+	RawScored       int64     `json:"raw_scored"`
+	BestScoreRaw    float64   `json:"best_score_raw"`
+	BestScoreMax    float64   `json:"best_score_max"`
+	FirstActivityAt time.Time `json:"first_activity_at"`
+	LastActivityAt  time.Time `json:"last_activity_at"`
+}
+
+func (q *Queries) ListCourseGradebookResults(ctx context.Context, arg ListCourseGradebookResultsParams) ([]ListCourseGradebookResultsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCourseGradebookResults, arg.OrgID, pq.Array(arg.ContentIds), pq.Array(arg.LearnerIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCourseGradebookResultsRow
+	for rows.Next() {
+		var i ListCourseGradebookResultsRow
+		if err := rows.Scan(
+			&i.LearnerID,
+			&i.ContentID,
+			&i.Attempts,
+			&i.Finished,
+			&i.Passed,
+			&i.Scored,
+			&i.BestScore,
+			&i.RawScored,
+			&i.BestScoreRaw,
+			&i.BestScoreMax,
+			&i.FirstActivityAt,
+			&i.LastActivityAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueCompetitorPins = `-- name: ListDueCompetitorPins :many
+SELECT id, organisation_id, target, domain, location_code, language_code, created_by, created_at, refreshed_at, next_refresh_at FROM competitor_pins
+WHERE next_refresh_at <= now()
+    AND organisation_id IN (SELECT id FROM organisations WHERE deleted_at IS NULL)
+ORDER BY next_refresh_at
+LIMIT $1
+`
+
+func (q *Queries) ListDueCompetitorPins(ctx context.Context, limit int32) ([]CompetitorPin, error) {
+	rows, err := q.db.QueryContext(ctx, listDueCompetitorPins, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CompetitorPin
+	for rows.Next() {
+		var i CompetitorPin
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Target,
+			&i.Domain,
+			&i.LocationCode,
+			&i.LanguageCode,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.RefreshedAt,
+			&i.NextRefreshAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueRankTrackers = `-- name: ListDueRankTrackers :many
+SELECT id, organisation_id, domain, location_code, language_code, device, competitors, created_by, created_at, last_run_at, next_run_at, volumes_refreshed_at FROM rank_trackers
+WHERE next_run_at <= now()
+    AND organisation_id IN (SELECT id FROM organisations WHERE deleted_at IS NULL)
+ORDER BY next_run_at
+LIMIT $1
+`
+
+func (q *Queries) ListDueRankTrackers(ctx context.Context, limit int32) ([]RankTracker, error) {
+	rows, err := q.db.QueryContext(ctx, listDueRankTrackers, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RankTracker
+	for rows.Next() {
+		var i RankTracker
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Domain,
+			&i.LocationCode,
+			&i.LanguageCode,
+			&i.Device,
+			pq.Array(&i.Competitors),
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.LastRunAt,
+			&i.NextRunAt,
+			&i.VolumesRefreshedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueRetentionPolicies = `-- name: ListDueRetentionPolicies :many
+SELECT organisation_id, archive_drafts_after_months, purge_deleted_after_days, retain_xapi_years, updated_by, created_at, updated_at, evaluated_at FROM organisation_retention_policies
+WHERE evaluated_at IS NULL OR evaluated_at < $1
+ORDER BY evaluated_at NULLS FIRST
+LIMIT $2
+`
+
+type ListDueRetentionPoliciesParams struct {
+	EvaluatedAt sql.NullTime `json:"evaluated_at"`
+	Limit       int32        `json:"limit"`
+}
+
+func (q *Queries) ListDueRetentionPolicies(ctx context.Context, arg ListDueRetentionPoliciesParams) ([]OrganisationRetentionPolicy, error) {
+	rows, err := q.db.QueryContext(ctx, listDueRetentionPolicies, arg.EvaluatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganisationRetentionPolicy
+	for rows.Next() {
+		var i OrganisationRetentionPolicy
+		if err := rows.Scan(
+			&i.OrganisationID,
+			&i.ArchiveDraftsAfterMonths,
+			&i.PurgeDeletedAfterDays,
+			&i.RetainXapiYears,
+			&i.UpdatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EvaluatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueSeoKeywords = `-- name: ListDueSeoKeywords :many
+SELECT k.list_id, l.organisation_id, l.location_code, l.language_code, k.keyword
+FROM seo_keywords k
+JOIN seo_keyword_lists l ON l.id = k.list_id
+JOIN organisations o ON o.id = l.organisation_id
+WHERE l.next_refresh_at <= now() AND o.deleted_at IS NULL
+ORDER BY l.location_code, l.language_code, k.keyword
+`
+
+type ListDueSeoKeywordsRow struct {
+	ListID         uuid.UUID `json:"list_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	LocationCode   int32     `json:"location_code"`
+	LanguageCode   string    `json:"language_code"`
+	Keyword        string    `json:"keyword"`
+}
+
+func (q *Queries) ListDueSeoKeywords(ctx context.Context) ([]ListDueSeoKeywordsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDueSeoKeywords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDueSeoKeywordsRow
+	for rows.Next() {
+		var i ListDueSeoKeywordsRow
+		if err := rows.Scan(
+			&i.ListID,
+			&i.OrganisationID,
+			&i.LocationCode,
+			&i.LanguageCode,
+			&i.Keyword,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueSeoSchedules = `-- name: ListDueSeoSchedules :many
+SELECT s.id, s.organisation_id, s.domain, s.pages, s.keywords, s.location_code, s.language_code, s.device, s.strategy, s.created_by, s.created_at, s.last_run_at, s.next_run_at,
+    o.subscription_tier, o.is_freemium, o.freemium_expires_at
+FROM seo_schedules s
+JOIN organisations o ON o.id = s.organisation_id
+WHERE s.next_run_at <= now() AND o.deleted_at IS NULL
+ORDER BY s.next_run_at
+LIMIT $1
+`
+
+type ListDueSeoSchedulesRow struct {
+	ID                uuid.UUID     `json:"id"`
+	OrganisationID    uuid.UUID     `json:"organisation_id"`
+	Domain            string        `json:"domain"`
+	Pages             []string      `json:"pages"`
+	Keywords          []string      `json:"keywords"`
+	LocationCode      int32         `json:"location_code"`
+	LanguageCode      string        `json:"language_code"`
+	Device            string        `json:"device"`
+	Strategy          string        `json:"strategy"`
+	CreatedBy         uuid.NullUUID `json:"created_by"`
+	CreatedAt         time.Time     `json:"created_at"`
+	LastRunAt         sql.NullTime  `json:"last_run_at"`
+	NextRunAt         time.Time     `json:"next_run_at"`
+	SubscriptionTier  string        `json:"subscription_tier"`
+	IsFreemium        bool          `json:"is_freemium"`
+	FreemiumExpiresAt sql.NullTime  `json:"freemium_expires_at"`
+}
+
+func (q *Queries) ListDueSeoSchedules(ctx context.Context, limit int32) ([]ListDueSeoSchedulesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDueSeoSchedules, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDueSeoSchedulesRow
+	for rows.Next() {
+		var i ListDueSeoSchedulesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Domain,
+			pq.Array(&i.Pages),
+			pq.Array(&i.Keywords),
+			&i.LocationCode,
+			&i.LanguageCode,
+			&i.Device,
+			&i.Strategy,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.LastRunAt,
+			&i.NextRunAt,
+			&i.SubscriptionTier,
+			&i.IsFreemium,
+			&i.FreemiumExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredAccessReviews = `-- name: ListExpiredAccessReviews :many
+SELECT id, organisation_id, requested_by, status, created_at, completed_at, expires_at, error FROM access_reviews
+WHERE expires_at < now()
+ORDER BY expires_at
+LIMIT $1
+`
+
+func (q *Queries) ListExpiredAccessReviews(ctx context.Context, limit int32) ([]AccessReview, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredAccessReviews, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccessReview
+	for rows.Next() {
+		var i AccessReview
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.RequestedBy,
+			&i.Status,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.ExpiresAt,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiringPaymentMethods = `-- name: ListExpiringPaymentMethods :many
+SELECT pm.organisation_id, pm.brand, pm.last4, pm.expires_at, pm.last_reminder_days, o.slug
+FROM organisation_payment_methods pm
+JOIN organisations o ON o.id = pm.organisation_id
+WHERE pm.expires_at > now() AND pm.expires_at <= $1
+    AND o.subscription_id <> '' AND o.deleted_at IS NULL
+`
+
+type ListExpiringPaymentMethodsRow struct {
+	OrganisationID   uuid.UUID     `json:"organisation_id"`
+	Brand            string        `json:"brand"`
+	Last4            string        `json:"last4"`
+	ExpiresAt        time.Time     `json:"expires_at"`
+	LastReminderDays sql.NullInt32 `json:"last_reminder_days"`
+	Slug             string        `json:"slug"`
+}
+
+func (q *Queries) ListExpiringPaymentMethods(ctx context.Context, expiresAt time.Time) ([]ListExpiringPaymentMethodsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiringPaymentMethods, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListExpiringPaymentMethodsRow
+	for rows.Next() {
+		var i ListExpiringPaymentMethodsRow
+		if err := rows.Scan(
+			&i.OrganisationID,
+			&i.Brand,
+			&i.Last4,
+			&i.ExpiresAt,
+			&i.LastReminderDays,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5PBundleReportTotals = `-- name: ListH5PBundleReportTotals :many
+SELECT variant, SUM(loads)::bigint AS loads, SUM(errors)::bigint AS errors
+FROM h5p_bundle_reports
+WHERE day >= $1
+GROUP BY variant
+ORDER BY variant
+`
+
+type ListH5PBundleReportTotalsRow struct {
+	Variant string `json:"variant"`
+	Loads   int64  `json:"loads"`
+	Errors  int64  `json:"errors"`
+}
+
+func (q *Queries) ListH5PBundleReportTotals(ctx context.Context, day time.Time) ([]ListH5PBundleReportTotalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listH5PBundleReportTotals, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListH5PBundleReportTotalsRow
+	for rows.Next() {
+		var i ListH5PBundleReportTotalsRow
+		if err := rows.Scan(&i.Variant, &i.Loads, &i.Errors); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5PBundleVariants = `-- name: ListH5PBundleVariants :many
+
+SELECT name, description, asset_base, rollout_percent, created_by, created_at, updated_at FROM h5p_bundle_variants ORDER BY created_at, name
+`
+
+// =============================================================================
+// H5P Editor Bundle Variants (Percentage rollout of editor assets)
+// =============================================================================
+func (q *Queries) ListH5PBundleVariants(ctx context.Context) ([]H5pBundleVariant, error) {
+	rows, err := q.db.QueryContext(ctx, listH5PBundleVariants)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []H5pBundleVariant
+	for rows.Next() {
+		var i H5pBundleVariant
+		if err := rows.Scan(
+			&i.Name,
+			&i.Description,
+			&i.AssetBase,
+			&i.RolloutPercent,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5PContentByOrg = `-- name: ListH5PContentByOrg :many
+SELECT c.id, c.created_at, c.updated_at, c.org_id, c.library_id, c.created_by, c.title, c.slug, c.description, c.content_json, c.tags, c.folder_path, c.storage_path, c.status, c.deleted_at, c.locked_at, c.lock_reason, l.machine_name, l.title as library_title,
+    l.major_version as library_major, l.minor_version as library_minor,
+    l.patch_version as library_patch
+FROM h5p_content c JOIN h5p_libraries l ON c.library_id = l.id
+WHERE c.org_id = $1 AND c.deleted_at IS NULL
+ORDER BY c.updated_at DESC LIMIT $2 OFFSET $3
+`
+
+type ListH5PContentByOrgParams struct {
+	OrgID  uuid.UUID `json:"org_id"`
+	Limit  int32     `json:"limit"`
+	Offset int32     `json:"offset"`
+}
+
+type ListH5PContentByOrgRow struct {
+	ID           uuid.UUID       `json:"id"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	OrgID        uuid.UUID       `json:"org_id"`
+	LibraryID    uuid.UUID       `json:"library_id"`
+	CreatedBy    uuid.NullUUID   `json:"created_by"`
+	Title        string          `json:"title"`
+	Slug         string          `json:"slug"`
+	Description  string          `json:"description"`
+	ContentJson  json.RawMessage `json:"content_json"`
+	Tags         []string        `json:"tags"`
+	FolderPath   sql.NullString  `json:"folder_path"`
+	StoragePath  sql.NullString  `json:"storage_path"`
+	Status       string          `json:"status"`
+	DeletedAt    sql.NullTime    `json:"deleted_at"`
+	LockedAt     sql.NullTime    `json:"locked_at"`
+	LockReason   sql.NullString  `json:"lock_reason"`
+	MachineName  string          `json:"machine_name"`
+	LibraryTitle string          `json:"library_title"`
+	LibraryMajor int32           `json:"library_major"`
+	LibraryMinor int32           `json:"library_minor"`
+	LibraryPatch int32           `json:"library_patch"`
+}
+
+func (q *Queries) ListH5PContentByOrg(ctx context.Context, arg ListH5PContentByOrgParams) ([]ListH5PContentByOrgRow, error) {
+	rows, err := q.db.QueryContext(ctx, listH5PContentByOrg, arg.OrgID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListH5PContentByOrgRow
+	for rows.Next() {
+		var i ListH5PContentByOrgRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrgID,
+			&i.LibraryID,
+			&i.CreatedBy,
+			&i.Title,
+			&i.Slug,
+			&i.Description,
+			&i.ContentJson,
+			pq.Array(&i.Tags),
+			&i.FolderPath,
+			&i.StoragePath,
+			&i.Status,
+			&i.DeletedAt,
+			&i.LockedAt,
+			&i.LockReason,
+			&i.MachineName,
+			&i.LibraryTitle,
+			&i.LibraryMajor,
+			&i.LibraryMinor,
+			&i.LibraryPatch,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5PInstallProfiles = `-- name: ListH5PInstallProfiles :many
+
+SELECT id, name, description, libraries, is_default, created_by, created_at, updated_at FROM h5p_install_profiles ORDER BY name
+`
+
+// =============================================================================
+// H5P Install Profiles (Library bundles applied to organisations)
+// =============================================================================
+func (q *Queries) ListH5PInstallProfiles(ctx context.Context) ([]H5pInstallProfile, error) {
+	rows, err := q.db.QueryContext(ctx, listH5PInstallProfiles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []H5pInstallProfile
+	for rows.Next() {
+		var i H5pInstallProfile
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			pq.Array(&i.Libraries),
+			&i.IsDefault,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5PLibraries = `-- name: ListH5PLibraries :many
+SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries
+ORDER BY machine_name ASC, major_version DESC, minor_version DESC, patch_version DESC
+`
+
+func (q *Queries) ListH5PLibraries(ctx context.Context) ([]H5pLibrary, error) {
+	rows, err := q.db.QueryContext(ctx, listH5PLibraries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []H5pLibrary
+	for rows.Next() {
+		var i H5pLibrary
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.MachineName,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.PatchVersion,
+			&i.Title,
+			&i.Origin,
+			&i.MetadataJson,
+			pq.Array(&i.Categories),
+			pq.Array(&i.Keywords),
+			pq.Array(&i.Screenshots),
+			&i.Description,
+			&i.IconPath,
+			&i.PackagePath,
+			&i.ExtractedPath,
+			&i.Runnable,
+			&i.Restricted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5PLibraryVersions = `-- name: ListH5PLibraryVersions :many
+
+SELECT id, machine_name, major_version, minor_version, patch_version, package_path
+FROM h5p_libraries
+WHERE $1::text IS NULL OR machine_name = $1::text
+ORDER BY machine_name, major_version, minor_version, patch_version
+`
+
+type ListH5PLibraryVersionsRow struct {
+	ID           uuid.UUID      `json:"id"`
+	MachineName  string         `json:"machine_name"`
+	MajorVersion int32          `json:"major_version"`
+	MinorVersion int32          `json:"minor_version"`
+	PatchVersion int32          `json:"patch_version"`
+	PackagePath  sql.NullString `json:"package_path"`
+}
+
+// =============================================================================
+// H5P Library Storage
+// =============================================================================
+func (q *Queries) ListH5PLibraryVersions(ctx context.Context, machineName sql.NullString) ([]ListH5PLibraryVersionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listH5PLibraryVersions, machineName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListH5PLibraryVersionsRow
+	for rows.Next() {
+		var i ListH5PLibraryVersionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MachineName,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.PatchVersion,
+			&i.PackagePath,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5PMediaAssets = `-- name: ListH5PMediaAssets :many
+SELECT id, org_id, uploaded_by, filename, mime_type, kind, size_bytes, width, height, checksum, storage_key, thumbnail_key, use_count, created_at, last_used_at FROM h5p_media_assets
+WHERE org_id = $1
+  AND ($2::text IS NULL OR kind = $2::text)
+  AND ($3::text IS NULL OR filename ILIKE '%' || $3::text || '%')
+  AND ($4::uuid IS NULL OR id < $4::uuid)
+ORDER BY id DESC
+LIMIT $5
+`
+
+type ListH5PMediaAssetsParams struct {
+	OrgID      uuid.UUID      `json:"org_id"`
+	Kind       sql.NullString `json:"kind"`
+	Search     sql.NullString `json:"search"`
+	Before     uuid.NullUUID  `json:"before"`
+	MaxResults int32          `json:"max_results"`
+}
+
+func (q *Queries) ListH5PMediaAssets(ctx context.Context, arg ListH5PMediaAssetsParams) ([]H5pMediaAsset, error) {
+	rows, err := q.db.QueryContext(ctx, listH5PMediaAssets,
+		arg.OrgID,
+		arg.Kind,
+		arg.Search,
+		arg.Before,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []H5pMediaAsset
+	for rows.Next() {
+		var i H5pMediaAsset
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrgID,
+			&i.UploadedBy,
+			&i.Filename,
+			&i.MimeType,
+			&i.Kind,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+			&i.Checksum,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+			&i.UseCount,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5POrgEnabledLibraries = `-- name: ListH5POrgEnabledLibraries :many
+SELECT ol.id, ol.created_at, ol.org_id, ol.library_id, ol.enabled, ol.restricted, l.machine_name, l.major_version, l.minor_version, l.patch_version,
+       l.title, l.description, l.icon_path, l.runnable, l.origin
+FROM h5p_org_libraries ol
+JOIN h5p_libraries l ON l.id = ol.library_id
+WHERE ol.org_id = $1 AND ol.enabled = true
+ORDER BY l.title ASC
+`
+
+type ListH5POrgEnabledLibrariesRow struct {
+	ID           uuid.UUID      `json:"id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	OrgID        uuid.UUID      `json:"org_id"`
+	LibraryID    uuid.UUID      `json:"library_id"`
+	Enabled      bool           `json:"enabled"`
+	Restricted   bool           `json:"restricted"`
+	MachineName  string         `json:"machine_name"`
+	MajorVersion int32          `json:"major_version"`
+	MinorVersion int32          `json:"minor_version"`
+	PatchVersion int32          `json:"patch_version"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	IconPath     sql.NullString `json:"icon_path"`
+	Runnable     bool           `json:"runnable"`
+	Origin       string         `json:"origin"`
+}
+
+func (q *Queries) ListH5POrgEnabledLibraries(ctx context.Context, orgID uuid.UUID) ([]ListH5POrgEnabledLibrariesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listH5POrgEnabledLibraries, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListH5POrgEnabledLibrariesRow
+	for rows.Next() {
+		var i ListH5POrgEnabledLibrariesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.OrgID,
+			&i.LibraryID,
+			&i.Enabled,
+			&i.Restricted,
+			&i.MachineName,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.PatchVersion,
+			&i.Title,
+			&i.Description,
+			&i.IconPath,
+			&i.Runnable,
+			&i.Origin,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5POrgLibraries = `-- name: ListH5POrgLibraries :many
+
+SELECT ol.id, ol.created_at, ol.org_id, ol.library_id, ol.enabled, ol.restricted, l.machine_name, l.major_version, l.minor_version, l.patch_version,
+       l.title, l.description, l.icon_path, l.runnable, l.origin
+FROM h5p_org_libraries ol
+JOIN h5p_libraries l ON l.id = ol.library_id
+WHERE ol.org_id = $1
+ORDER BY l.title ASC
+`
+
+type ListH5POrgLibrariesRow struct {
+	ID           uuid.UUID      `json:"id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	OrgID        uuid.UUID      `json:"org_id"`
+	LibraryID    uuid.UUID      `json:"library_id"`
+	Enabled      bool           `json:"enabled"`
+	Restricted   bool           `json:"restricted"`
+	MachineName  string         `json:"machine_name"`
+	MajorVersion int32          `json:"major_version"`
+	MinorVersion int32          `json:"minor_version"`
+	PatchVersion int32          `json:"patch_version"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	IconPath     sql.NullString `json:"icon_path"`
+	Runnable     bool           `json:"runnable"`
+	Origin       string         `json:"origin"`
+}
+
+// =============================================================================
+// H5P Org Libraries (Per-organisation enablement)
+// =============================================================================
+func (q *Queries) ListH5POrgLibraries(ctx context.Context, orgID uuid.UUID) ([]ListH5POrgLibrariesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listH5POrgLibraries, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListH5POrgLibrariesRow
+	for rows.Next() {
+		var i ListH5POrgLibrariesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.OrgID,
+			&i.LibraryID,
+			&i.Enabled,
+			&i.Restricted,
+			&i.MachineName,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.PatchVersion,
+			&i.Title,
+			&i.Description,
+			&i.IconPath,
+			&i.Runnable,
+			&i.Origin,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5PProfileApplications = `-- name: ListH5PProfileApplications :many
+SELECT id, organisation_id, profile_id, profile_name, libraries, status, summary, requested_by, created_at, completed_at FROM h5p_profile_applications
+WHERE organisation_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListH5PProfileApplicationsParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Limit          int32     `json:"limit"`
+}
+
+func (q *Queries) ListH5PProfileApplications(ctx context.Context, arg ListH5PProfileApplicationsParams) ([]H5pProfileApplication, error) {
+	rows, err := q.db.QueryContext(ctx, listH5PProfileApplications, arg.OrganisationID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []H5pProfileApplication
+	for rows.Next() {
+		var i H5pProfileApplication
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.ProfileID,
+			&i.ProfileName,
+			pq.Array(&i.Libraries),
+			&i.Status,
+			&i.Summary,
+			&i.RequestedBy,
+			&i.CreatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listH5PRunnableLibraries = `-- name: ListH5PRunnableLibraries :many
+SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries
+WHERE runnable = true
+ORDER BY title ASC
+`
+
+func (q *Queries) ListH5PRunnableLibraries(ctx context.Context) ([]H5pLibrary, error) {
+	rows, err := q.db.QueryContext(ctx, listH5PRunnableLibraries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []H5pLibrary
+	for rows.Next() {
+		var i H5pLibrary
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.MachineName,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.PatchVersion,
+			&i.Title,
+			&i.Origin,
+			&i.MetadataJson,
+			pq.Array(&i.Categories),
+			pq.Array(&i.Keywords),
+			pq.Array(&i.Screenshots),
+			&i.Description,
+			&i.IconPath,
+			&i.PackagePath,
+			&i.ExtractedPath,
+			&i.Runnable,
+			&i.Restricted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotificationDigestItems = `-- name: ListNotificationDigestItems :many
+SELECT d.id, d.user_id, u.email, d.organisation_id, o.name AS organisation_name, d.category, d.event_type, d.summary, d.created_at
+FROM notification_digest_items d
+JOIN users u ON u.id = d.user_id
+JOIN organisations o ON o.id = d.organisation_id
+WHERE d.created_at < $1
+ORDER BY d.user_id, o.name, d.created_at
+`
+
+type ListNotificationDigestItemsRow struct {
+	ID               uuid.UUID `json:"id"`
+	UserID           uuid.UUID `json:"user_id"`
+	Email            string    `json:"email"`
+	OrganisationID   uuid.UUID `json:"organisation_id"`
+	OrganisationName string    `json:"organisation_name"`
+	Category         string    `json:"category"`
+	EventType        string    `json:"event_type"`
+	Summary          string    `json:"summary"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListNotificationDigestItems(ctx context.Context, createdAt time.Time) ([]ListNotificationDigestItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listNotificationDigestItems, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNotificationDigestItemsRow
+	for rows.Next() {
+		var i ListNotificationDigestItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Email,
+			&i.OrganisationID,
+			&i.OrganisationName,
+			&i.Category,
+			&i.EventType,
+			&i.Summary,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotificationPreferences = `-- name: ListNotificationPreferences :many
+
+SELECT user_id, organisation_id, category, channel, enabled, digest, updated_at FROM notification_preferences
+WHERE user_id = $1 AND organisation_id = $2
+ORDER BY category, channel
+`
+
+type ListNotificationPreferencesParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+// =============================================================================
+// Notifications
+// =============================================================================
+func (q *Queries) ListNotificationPreferences(ctx context.Context, arg ListNotificationPreferencesParams) ([]NotificationPreference, error) {
+	rows, err := q.db.QueryContext(ctx, listNotificationPreferences, arg.UserID, arg.OrganisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NotificationPreference
+	for rows.Next() {
+		var i NotificationPreference
+		if err := rows.Scan(
+			&i.UserID,
+			&i.OrganisationID,
+			&i.Category,
+			&i.Channel,
+			&i.Enabled,
+			&i.Digest,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganisationNotificationPreferences = `-- name: ListOrganisationNotificationPreferences :many
+SELECT user_id, organisation_id, category, channel, enabled, digest, updated_at FROM notification_preferences
+WHERE organisation_id = $1
+`
+
+func (q *Queries) ListOrganisationNotificationPreferences(ctx context.Context, organisationID uuid.UUID) ([]NotificationPreference, error) {
+	rows, err := q.db.QueryContext(ctx, listOrganisationNotificationPreferences, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NotificationPreference
+	for rows.Next() {
+		var i NotificationPreference
+		if err := rows.Scan(
+			&i.UserID,
+			&i.OrganisationID,
+			&i.Category,
+			&i.Channel,
+			&i.Enabled,
+			&i.Digest,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganisationNotificationRecipients = `-- name: ListOrganisationNotificationRecipients :many
+SELECT m.user_id, u.email, m.display_name, m.role
+FROM organisation_memberships m
+JOIN users u ON u.id = m.user_id
+WHERE m.organisation_id = $1 AND m.status = 'active' AND NOT u.suspended
+`
+
+type ListOrganisationNotificationRecipientsRow struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	Role        string    `json:"role"`
+}
+
+func (q *Queries) ListOrganisationNotificationRecipients(ctx context.Context, organisationID uuid.UUID) ([]ListOrganisationNotificationRecipientsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrganisationNotificationRecipients, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrganisationNotificationRecipientsRow
+	for rows.Next() {
+		var i ListOrganisationNotificationRecipientsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Email,
+			&i.DisplayName,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganisationWebhooks = `-- name: ListOrganisationWebhooks :many
+
+SELECT id, created_at, updated_at, organisation_id, url, secret, events, active, last_delivery_at, last_delivery_status FROM organisation_webhooks
+WHERE organisation_id = $1
+ORDER BY created_at
+`
+
+// =============================================================================
+// Organisation Webhooks
+// =============================================================================
+func (q *Queries) ListOrganisationWebhooks(ctx context.Context, organisationID uuid.UUID) ([]OrganisationWebhook, error) {
+	rows, err := q.db.QueryContext(ctx, listOrganisationWebhooks, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganisationWebhook
+	for rows.Next() {
+		var i OrganisationWebhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrganisationID,
+			&i.Url,
+			&i.Secret,
+			pq.Array(&i.Events),
+			&i.Active,
+			&i.LastDeliveryAt,
+			&i.LastDeliveryStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutdatedH5PContent = `-- name: ListOutdatedH5PContent :many
+SELECT c.id, c.title, c.content_json, c.library_id, c.locked_at,
+    l.major_version, l.minor_version, l.patch_version
+FROM h5p_content c
+JOIN h5p_libraries l ON l.id = c.library_id
+WHERE c.org_id = $1 AND c.deleted_at IS NULL
+  AND l.machine_name = $2
+  AND (l.major_version < $3
+    OR (l.major_version = $3 AND l.minor_version < $4))
+ORDER BY c.updated_at
+LIMIT $5
+`
+
+type ListOutdatedH5PContentParams struct {
+	OrgID        uuid.UUID `json:"org_id"`
+	MachineName  string    `json:"machine_name"`
+	MajorVersion int32     `json:"major_version"`
+	MinorVersion int32     `json:"minor_version"`
+	MaxResults   int32     `json:"max_results"`
+}
+
+type ListOutdatedH5PContentRow struct {
+	ID           uuid.UUID       `json:"id"`
+	Title        string          `json:"title"`
+	ContentJson  json.RawMessage `json:"content_json"`
+	LibraryID    uuid.UUID       `json:"library_id"`
+	LockedAt     sql.NullTime    `json:"locked_at"`
+	MajorVersion int32           `json:"major_version"`
+	MinorVersion int32           `json:"minor_version"`
+	PatchVersion int32           `json:"patch_version"`
+}
+
+func (q *Queries) ListOutdatedH5PContent(ctx context.Context, arg ListOutdatedH5PContentParams) ([]ListOutdatedH5PContentRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOutdatedH5PContent,
+		arg.OrgID,
+		arg.MachineName,
+		arg.MajorVersion,
+		arg.MinorVersion,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOutdatedH5PContentRow
+	for rows.Next() {
+		var i ListOutdatedH5PContentRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ContentJson,
+			&i.LibraryID,
+			&i.LockedAt,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.PatchVersion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutdatedH5PContentLibraries = `-- name: ListOutdatedH5PContentLibraries :many
+
+SELECT l.machine_name, l.major_version, l.minor_version, COUNT(*) AS content_count
+FROM h5p_content c
+JOIN h5p_libraries l ON l.id = c.library_id
+WHERE c.org_id = $1 AND c.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM h5p_libraries n
+    WHERE n.machine_name = l.machine_name
+      AND (n.major_version, n.minor_version) > (l.major_version, l.minor_version)
+  )
+GROUP BY l.machine_name, l.major_version, l.minor_version
+ORDER BY l.machine_name, l.major_version, l.minor_version
+`
+
+type ListOutdatedH5PContentLibrariesRow struct {
+	MachineName  string `json:"machine_name"`
+	MajorVersion int32  `json:"major_version"`
+	MinorVersion int32  `json:"minor_version"`
+	ContentCount int64  `json:"content_count"`
+}
+
+// =============================================================================
+// H5P Content Upgrades
+// =============================================================================
+func (q *Queries) ListOutdatedH5PContentLibraries(ctx context.Context, orgID uuid.UUID) ([]ListOutdatedH5PContentLibrariesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOutdatedH5PContentLibraries, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOutdatedH5PContentLibrariesRow
+	for rows.Next() {
+		var i ListOutdatedH5PContentLibrariesRow
+		if err := rows.Scan(
+			&i.MachineName,
+			&i.MajorVersion,
+			&i.MinorVersion,
+			&i.ContentCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingAccessReviews = `-- name: ListPendingAccessReviews :many
+SELECT id, organisation_id, requested_by, status, created_at, completed_at, expires_at, error FROM access_reviews
+WHERE status = 'pending'
+ORDER BY created_at
+LIMIT $1
+`
+
+func (q *Queries) ListPendingAccessReviews(ctx context.Context, limit int32) ([]AccessReview, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingAccessReviews, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccessReview
+	for rows.Next() {
+		var i AccessReview
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.RequestedBy,
+			&i.Status,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.ExpiresAt,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRankPositionHistory = `-- name: ListRankPositionHistory :many
+SELECT run_id, tracker_id, keyword, domain, position, url, source, checked_at FROM rank_positions
+WHERE tracker_id = $1 AND keyword = $2 AND checked_at >= $3
+ORDER BY checked_at, domain
+`
+
+type ListRankPositionHistoryParams struct {
+	TrackerID uuid.UUID `json:"tracker_id"`
+	Keyword   string    `json:"keyword"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+func (q *Queries) ListRankPositionHistory(ctx context.Context, arg ListRankPositionHistoryParams) ([]RankPosition, error) {
+	rows, err := q.db.QueryContext(ctx, listRankPositionHistory, arg.TrackerID, arg.Keyword, arg.CheckedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RankPosition
+	for rows.Next() {
+		var i RankPosition
+		if err := rows.Scan(
+			&i.RunID,
+			&i.TrackerID,
+			&i.Keyword,
+			&i.Domain,
+			&i.Position,
+			&i.Url,
+			&i.Source,
+			&i.CheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRankPositionsByRun = `-- name: ListRankPositionsByRun :many
+SELECT run_id, tracker_id, keyword, domain, position, url, source, checked_at FROM rank_positions
+WHERE run_id = $1 AND domain = $2
+ORDER BY keyword
+`
+
+type ListRankPositionsByRunParams struct {
+	RunID  uuid.UUID `json:"run_id"`
+	Domain string    `json:"domain"`
+}
+
+func (q *Queries) ListRankPositionsByRun(ctx context.Context, arg ListRankPositionsByRunParams) ([]RankPosition, error) {
+	rows, err := q.db.QueryContext(ctx, listRankPositionsByRun, arg.RunID, arg.Domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RankPosition
+	for rows.Next() {
+		var i RankPosition
+		if err := rows.Scan(
+			&i.RunID,
+			&i.TrackerID,
+			&i.Keyword,
+			&i.Domain,
+			&i.Position,
+			&i.Url,
+			&i.Source,
+			&i.CheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRankTrackerKeywords = `-- name: ListRankTrackerKeywords :many
+SELECT tracker_id, keyword, search_volume, created_at FROM rank_tracker_keywords
+WHERE tracker_id = $1
+ORDER BY keyword
+`
+
+func (q *Queries) ListRankTrackerKeywords(ctx context.Context, trackerID uuid.UUID) ([]RankTrackerKeyword, error) {
+	rows, err := q.db.QueryContext(ctx, listRankTrackerKeywords, trackerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RankTrackerKeyword
+	for rows.Next() {
+		var i RankTrackerKeyword
+		if err := rows.Scan(
+			&i.TrackerID,
+			&i.Keyword,
+			&i.SearchVolume,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRankTrackerRuns = `-- name: ListRankTrackerRuns :many
+SELECT id, tracker_id, organisation_id, created_at, completed_at, status, keyword_count, failed_count, cost, share_of_voice, error FROM rank_tracker_runs
+WHERE tracker_id = $1 AND created_at >= $2
+ORDER BY created_at
+`
+
+type ListRankTrackerRunsParams struct {
+	TrackerID uuid.UUID `json:"tracker_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListRankTrackerRuns(ctx context.Context, arg ListRankTrackerRunsParams) ([]RankTrackerRun, error) {
+	rows, err := q.db.QueryContext(ctx, listRankTrackerRuns, arg.TrackerID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RankTrackerRun
+	for rows.Next() {
+		var i RankTrackerRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.TrackerID,
+			&i.OrganisationID,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.Status,
+			&i.KeywordCount,
+			&i.FailedCount,
+			&i.Cost,
+			&i.ShareOfVoice,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRankTrackersByOrg = `-- name: ListRankTrackersByOrg :many
+SELECT t.id, t.organisation_id, t.domain, t.location_code, t.language_code, t.device, t.competitors, t.created_by, t.created_at, t.last_run_at, t.next_run_at, t.volumes_refreshed_at,
+    (SELECT count(*) FROM rank_tracker_keywords k WHERE k.tracker_id = t.id) AS keyword_count
+FROM rank_trackers t
+WHERE t.organisation_id = $1
+ORDER BY t.domain, t.created_at
+`
+
+type ListRankTrackersByOrgRow struct {
+	ID                 uuid.UUID     `json:"id"`
+	OrganisationID     uuid.UUID     `json:"organisation_id"`
+	Domain             string        `json:"domain"`
+	LocationCode       int32         `json:"location_code"`
+	LanguageCode       string        `json:"language_code"`
+	Device             string        `json:"device"`
+	Competitors        []string      `json:"competitors"`
+	CreatedBy          uuid.NullUUID `json:"created_by"`
+	CreatedAt          time.Time     `json:"created_at"`
+	LastRunAt          sql.NullTime  `json:"last_run_at"`
+	NextRunAt          time.Time     `json:"next_run_at"`
+	VolumesRefreshedAt sql.NullTime  `json:"volumes_refreshed_at"`
+	KeywordCount       int64         `json:"keyword_count"`
+}
+
+func (q *Queries) ListRankTrackersByOrg(ctx context.Context, organisationID uuid.UUID) ([]ListRankTrackersByOrgRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRankTrackersByOrg, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRankTrackersByOrgRow
+	for rows.Next() {
+		var i ListRankTrackersByOrgRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Domain,
+			&i.LocationCode,
+			&i.LanguageCode,
+			&i.Device,
+			pq.Array(&i.Competitors),
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.LastRunAt,
+			&i.NextRunAt,
+			&i.VolumesRefreshedAt,
+			&i.KeywordCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecentCompetitorSnapshots = `-- name: ListRecentCompetitorSnapshots :many
+SELECT id, organisation_id, target, domain, location_code, language_code, captured_at, organic_etv, organic_keywords, top3_keywords, top10_keywords, domain_rank, backlinks, referring_domains FROM (
+    SELECT id, organisation_id, target, domain, location_code, language_code, captured_at, organic_etv, organic_keywords, top3_keywords, top10_keywords, domain_rank, backlinks, referring_domains,
+        row_number() OVER (PARTITION BY domain ORDER BY captured_at DESC) AS recency
+    FROM competitor_snapshots
+    WHERE organisation_id = $1 AND target = $2 AND location_code = $3 AND language_code = $4
+) recent
+WHERE recency <= 2
+ORDER BY domain, captured_at DESC
+`
+
+type ListRecentCompetitorSnapshotsParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Target         string    `json:"target"`
+	LocationCode   int32     `json:"location_code"`
+	LanguageCode   string    `json:"language_code"`
+}
+
+func (q *Queries) ListRecentCompetitorSnapshots(ctx context.Context, arg ListRecentCompetitorSnapshotsParams) ([]CompetitorSnapshot, error) {
+	rows, err := q.db.QueryContext(ctx, listRecentCompetitorSnapshots,
+		arg.OrganisationID,
+		arg.Target,
+		arg.LocationCode,
+		arg.LanguageCode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CompetitorSnapshot
+	for rows.Next() {
+		var i CompetitorSnapshot
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Target,
+			&i.Domain,
+			&i.LocationCode,
+			&i.LanguageCode,
+			&i.CapturedAt,
+			&i.OrganicEtv,
+			&i.OrganicKeywords,
+			&i.Top3Keywords,
+			&i.Top10Keywords,
+			&i.DomainRank,
+			&i.Backlinks,
+			&i.ReferringDomains,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRunningSeoScheduleRuns = `-- name: ListRunningSeoScheduleRuns :many
+SELECT id, schedule_id, organisation_id, created_at, completed_at, status, audit_id, rank_check_id, summary, deltas, error FROM seo_schedule_runs
+WHERE status = 'running'
+ORDER BY created_at
+`
+
+func (q *Queries) ListRunningSeoScheduleRuns(ctx context.Context) ([]SeoScheduleRun, error) {
+	rows, err := q.db.QueryContext(ctx, listRunningSeoScheduleRuns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeoScheduleRun
+	for rows.Next() {
+		var i SeoScheduleRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduleID,
+			&i.OrganisationID,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.Status,
+			&i.AuditID,
+			&i.RankCheckID,
+			&i.Summary,
+			&i.Deltas,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeoBacklinkProspects = `-- name: ListSeoBacklinkProspects :many
+SELECT id, organisation_id, target, domain, status, source, competitors, contact, notes, rank, backlinks, referring_domains, spam_score, enriched_at, created_by, created_at, updated_at, contacted_at, linked_at FROM seo_backlink_prospects
+WHERE organisation_id = $1 AND target = $2
+    AND ($3::text = '' OR status = $3::text)
+ORDER BY cardinality(competitors) DESC, rank DESC NULLS LAST, domain
+LIMIT $5 OFFSET $4
+`
+
+type ListSeoBacklinkProspectsParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Target         string    `json:"target"`
+	Status         string    `json:"status"`
+	Offset         int32     `json:"offset"`
+	Limit          int32     `json:"limit"`
+}
+
+func (q *Queries) ListSeoBacklinkProspects(ctx context.Context, arg ListSeoBacklinkProspectsParams) ([]SeoBacklinkProspect, error) {
+	rows, err := q.db.QueryContext(ctx, listSeoBacklinkProspects,
+		arg.OrganisationID,
+		arg.Target,
+		arg.Status,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeoBacklinkProspect
+	for rows.Next() {
+		var i SeoBacklinkProspect
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Target,
+			&i.Domain,
+			&i.Status,
+			&i.Source,
+			pq.Array(&i.Competitors),
+			&i.Contact,
+			&i.Notes,
+			&i.Rank,
+			&i.Backlinks,
+			&i.ReferringDomains,
+			&i.SpamScore,
+			&i.EnrichedAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ContactedAt,
+			&i.LinkedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeoKeywordListsByOrg = `-- name: ListSeoKeywordListsByOrg :many
+SELECT l.id, l.organisation_id, l.name, l.location_code, l.language_code, l.created_by, l.created_at, l.last_refreshed_at, l.next_refresh_at,
+    (SELECT count(*) FROM seo_keywords k WHERE k.list_id = l.id) AS keyword_count
+FROM seo_keyword_lists l
+WHERE l.organisation_id = $1
+ORDER BY l.name
+`
+
+type ListSeoKeywordListsByOrgRow struct {
+	ID              uuid.UUID     `json:"id"`
+	OrganisationID  uuid.UUID     `json:"organisation_id"`
+	Name            string        `json:"name"`
+	LocationCode    int32         `json:"location_code"`
+	LanguageCode    string        `json:"language_code"`
+	CreatedBy       uuid.NullUUID `json:"created_by"`
+	CreatedAt       time.Time     `json:"created_at"`
+	LastRefreshedAt sql.NullTime  `json:"last_refreshed_at"`
+	NextRefreshAt   time.Time     `json:"next_refresh_at"`
+	KeywordCount    int64         `json:"keyword_count"`
+}
+
+func (q *Queries) ListSeoKeywordListsByOrg(ctx context.Context, organisationID uuid.UUID) ([]ListSeoKeywordListsByOrgRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSeoKeywordListsByOrg, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSeoKeywordListsByOrgRow
+	for rows.Next() {
+		var i ListSeoKeywordListsByOrgRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Name,
+			&i.LocationCode,
+			&i.LanguageCode,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.LastRefreshedAt,
+			&i.NextRefreshAt,
+			&i.KeywordCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeoKeywordRefreshes = `-- name: ListSeoKeywordRefreshes :many
+SELECT id, list_id, organisation_id, created_at, status, keyword_count, updated_count, cost, error FROM seo_keyword_refreshes
+WHERE list_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListSeoKeywordRefreshesParams struct {
+	ListID uuid.UUID `json:"list_id"`
+	Limit  int32     `json:"limit"`
+}
+
+func (q *Queries) ListSeoKeywordRefreshes(ctx context.Context, arg ListSeoKeywordRefreshesParams) ([]SeoKeywordRefresh, error) {
+	rows, err := q.db.QueryContext(ctx, listSeoKeywordRefreshes, arg.ListID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeoKeywordRefresh
+	for rows.Next() {
+		var i SeoKeywordRefresh
+		if err := rows.Scan(
+			&i.ID,
+			&i.ListID,
+			&i.OrganisationID,
+			&i.CreatedAt,
+			&i.Status,
+			&i.KeywordCount,
+			&i.UpdatedCount,
+			&i.Cost,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeoKeywords = `-- name: ListSeoKeywords :many
+SELECT list_id, keyword, search_volume, cpc, competition, monthly_searches, refreshed_at FROM seo_keywords
+WHERE list_id = $1
+ORDER BY keyword
+`
+
+func (q *Queries) ListSeoKeywords(ctx context.Context, listID uuid.UUID) ([]SeoKeyword, error) {
+	rows, err := q.db.QueryContext(ctx, listSeoKeywords, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeoKeyword
+	for rows.Next() {
+		var i SeoKeyword
+		if err := rows.Scan(
+			&i.ListID,
+			&i.Keyword,
+			&i.SearchVolume,
+			&i.Cpc,
+			&i.Competition,
+			&i.MonthlySearches,
+			&i.RefreshedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeoPageExperienceByAudit = `-- name: ListSeoPageExperienceByAudit :many
+SELECT id, organisation_id, audit_id, page_url, strategy, created_by, created_at, completed_at, status, error, intrusive_interstitial, score, passed, cwv_source, lcp_ms, inp_ms, cls, signals, pagespeed, issues, rechecked_at FROM seo_page_experience
+WHERE organisation_id = $1 AND audit_id = $2
+ORDER BY page_url, strategy
+`
+
+type ListSeoPageExperienceByAuditParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	AuditID        uuid.UUID `json:"audit_id"`
+}
+
+func (q *Queries) ListSeoPageExperienceByAudit(ctx context.Context, arg ListSeoPageExperienceByAuditParams) ([]SeoPageExperience, error) {
+	rows, err := q.db.QueryContext(ctx, listSeoPageExperienceByAudit, arg.OrganisationID, arg.AuditID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeoPageExperience
+	for rows.Next() {
+		var i SeoPageExperience
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.AuditID,
+			&i.PageUrl,
+			&i.Strategy,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.Status,
+			&i.Error,
+			&i.IntrusiveInterstitial,
+			&i.Score,
+			&i.Passed,
+			&i.CwvSource,
+			&i.LcpMs,
+			&i.InpMs,
+			&i.Cls,
+			&i.Signals,
+			&i.Pagespeed,
+			&i.Issues,
+			&i.RecheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeoPageExperienceTrend = `-- name: ListSeoPageExperienceTrend :many
+SELECT id, organisation_id, audit_id, page_url, strategy, created_by, created_at, completed_at, status, error, intrusive_interstitial, score, passed, cwv_source, lcp_ms, inp_ms, cls, signals, pagespeed, issues, rechecked_at FROM seo_page_experience
+WHERE organisation_id = $1 AND page_url = $2 AND strategy = $3 AND status = 'completed'
+ORDER BY created_at DESC
+LIMIT $4
+`
+
+type ListSeoPageExperienceTrendParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	PageUrl        string    `json:"page_url"`
+	Strategy       string    `json:"strategy"`
+	Limit          int32     `json:"limit"`
+}
+
+func (q *Queries) ListSeoPageExperienceTrend(ctx context.Context, arg ListSeoPageExperienceTrendParams) ([]SeoPageExperience, error) {
+	rows, err := q.db.QueryContext(ctx, listSeoPageExperienceTrend,
+		arg.OrganisationID,
+		arg.PageUrl,
+		arg.Strategy,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeoPageExperience
+	for rows.Next() {
+		var i SeoPageExperience
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.AuditID,
+			&i.PageUrl,
+			&i.Strategy,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.Status,
+			&i.Error,
+			&i.IntrusiveInterstitial,
+			&i.Score,
+			&i.Passed,
+			&i.CwvSource,
+			&i.LcpMs,
+			&i.InpMs,
+			&i.Cls,
+			&i.Signals,
+			&i.Pagespeed,
+			&i.Issues,
+			&i.RecheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeoRawArtifacts = `-- name: ListSeoRawArtifacts :many
+SELECT p.page_url, p.strategy, a.object_key, a.size_bytes, a.created_at
+FROM seo_raw_artifacts a
+JOIN seo_page_experience p ON p.id = a.page_id
+WHERE p.organisation_id = $1 AND p.audit_id = $2 AND a.section = $3
+ORDER BY p.page_url, p.strategy
+`
+
+type ListSeoRawArtifactsParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	AuditID        uuid.UUID `json:"audit_id"`
+	Section        string    `json:"section"`
+}
+
+type ListSeoRawArtifactsRow struct {
+	PageUrl   string    `json:"page_url"`
+	Strategy  string    `json:"strategy"`
+	ObjectKey string    `json:"object_key"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListSeoRawArtifacts(ctx context.Context, arg ListSeoRawArtifactsParams) ([]ListSeoRawArtifactsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSeoRawArtifacts, arg.OrganisationID, arg.AuditID, arg.Section)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSeoRawArtifactsRow
+	for rows.Next() {
+		var i ListSeoRawArtifactsRow
+		if err := rows.Scan(
+			&i.PageUrl,
+			&i.Strategy,
+			&i.ObjectKey,
+			&i.SizeBytes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeoScheduleRuns = `-- name: ListSeoScheduleRuns :many
+SELECT id, schedule_id, organisation_id, created_at, completed_at, status, audit_id, rank_check_id, summary, deltas, error FROM seo_schedule_runs
+WHERE schedule_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListSeoScheduleRunsParams struct {
+	ScheduleID uuid.UUID `json:"schedule_id"`
+	Limit      int32     `json:"limit"`
+}
+
+func (q *Queries) ListSeoScheduleRuns(ctx context.Context, arg ListSeoScheduleRunsParams) ([]SeoScheduleRun, error) {
+	rows, err := q.db.QueryContext(ctx, listSeoScheduleRuns, arg.ScheduleID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeoScheduleRun
+	for rows.Next() {
+		var i SeoScheduleRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduleID,
+			&i.OrganisationID,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.Status,
+			&i.AuditID,
+			&i.RankCheckID,
+			&i.Summary,
+			&i.Deltas,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeoSchedulesByOrg = `-- name: ListSeoSchedulesByOrg :many
+SELECT id, organisation_id, domain, pages, keywords, location_code, language_code, device, strategy, created_by, created_at, last_run_at, next_run_at FROM seo_schedules
+WHERE organisation_id = $1
+ORDER BY domain
+`
+
+func (q *Queries) ListSeoSchedulesByOrg(ctx context.Context, organisationID uuid.UUID) ([]SeoSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, listSeoSchedulesByOrg, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeoSchedule
+	for rows.Next() {
+		var i SeoSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Domain,
+			pq.Array(&i.Pages),
+			pq.Array(&i.Keywords),
+			&i.LocationCode,
+			&i.LanguageCode,
+			&i.Device,
+			&i.Strategy,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.LastRunAt,
+			&i.NextRunAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStuckPendingDeletions = `-- name: ListStuckPendingDeletions :many
+SELECT id, storage_key, reason, attempts, last_error, next_attempt_at, created_at FROM pending_deletions
+WHERE attempts >= $1
+ORDER BY created_at
+LIMIT $2
+`
+
+type ListStuckPendingDeletionsParams struct {
+	Attempts int32 `json:"attempts"`
+	Limit    int32 `json:"limit"`
+}
+
+func (q *Queries) ListStuckPendingDeletions(ctx context.Context, arg ListStuckPendingDeletionsParams) ([]PendingDeletion, error) {
+	rows, err := q.db.QueryContext(ctx, listStuckPendingDeletions, arg.Attempts, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PendingDeletion
+	for rows.Next() {
+		var i PendingDeletion
+		if err := rows.Scan(
+			&i.ID,
+			&i.StorageKey,
+			&i.Reason,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnenrichedSeoBacklinkProspects = `-- name: ListUnenrichedSeoBacklinkProspects :many
+SELECT id, organisation_id, target, domain, status, source, competitors, contact, notes, rank, backlinks, referring_domains, spam_score, enriched_at, created_by, created_at, updated_at, contacted_at, linked_at FROM seo_backlink_prospects
+WHERE organisation_id = $1 AND target = $2 AND enriched_at IS NULL
+ORDER BY created_at, domain
+LIMIT $3
+`
+
+type ListUnenrichedSeoBacklinkProspectsParams struct {
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Target         string    `json:"target"`
+	Limit          int32     `json:"limit"`
+}
+
+func (q *Queries) ListUnenrichedSeoBacklinkProspects(ctx context.Context, arg ListUnenrichedSeoBacklinkProspectsParams) ([]SeoBacklinkProspect, error) {
+	rows, err := q.db.QueryContext(ctx, listUnenrichedSeoBacklinkProspects, arg.OrganisationID, arg.Target, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeoBacklinkProspect
+	for rows.Next() {
+		var i SeoBacklinkProspect
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Target,
+			&i.Domain,
+			&i.Status,
+			&i.Source,
+			pq.Array(&i.Competitors),
+			&i.Contact,
+			&i.Notes,
+			&i.Rank,
+			&i.Backlinks,
+			&i.ReferringDomains,
+			&i.SpamScore,
+			&i.EnrichedAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ContactedAt,
+			&i.LinkedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnreadAnnouncements = `-- name: ListUnreadAnnouncements :many
+SELECT a.id, a.created_at, a.updated_at, a.title, a.body, a.link_url, a.tiers, a.flags, a.published_at, a.expires_at, a.created_by FROM announcements a
+WHERE a.published_at <= now()
+    AND (a.expires_at IS NULL OR a.expires_at > now())
+    AND NOT EXISTS (
+        SELECT 1 FROM announcement_reads r
+        WHERE r.announcement_id = a.id AND r.user_id = $1
+    )
+ORDER BY a.published_at DESC
+`
+
+func (q *Queries) ListUnreadAnnouncements(ctx context.Context, userID uuid.UUID) ([]Announcement, error) {
+	rows, err := q.db.QueryContext(ctx, listUnreadAnnouncements, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Announcement
+	for rows.Next() {
+		var i Announcement
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Body,
+			&i.LinkUrl,
+			pq.Array(&i.Tiers),
+			pq.Array(&i.Flags),
+			&i.PublishedAt,
+			&i.ExpiresAt,
+			&i.CreatedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserCertificates = `-- name: ListUserCertificates :many
+SELECT c.id, c.org_id, c.course_id, c.learner_name, c.course_title, c.organisation_name, c.completed_at, c.issued_at,
+    COUNT(d.id) AS downloads,
+    COALESCE(MAX(d.downloaded_at), c.issued_at)::timestamptz AS last_downloaded_at
+FROM certificates c
+LEFT JOIN certificate_downloads d ON d.certificate_id = c.id
+WHERE c.user_id = $1
+GROUP BY c.id
+ORDER BY c.issued_at DESC
+`
+
+type ListUserCertificatesRow struct {
+	ID               uuid.UUID     `json:"id"`
+	OrgID            uuid.UUID     `json:"org_id"`
+	CourseID         uuid.NullUUID `json:"course_id"`
+	LearnerName      string        `json:"learner_name"`
+	CourseTitle      string        `json:"course_title"`
+	OrganisationName string        `json:"organisation_name"`
+	CompletedAt      time.Time     `json:"completed_at"`
+	IssuedAt         time.Time     `json:"issued_at"`
+	Downloads        int64         `json:"downloads"`
+	LastDownloadedAt time.Time     `json:"last_downloaded_at"`
+}
+
+func (q *Queries) ListUserCertificates(ctx context.Context, userID uuid.UUID) ([]ListUserCertificatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserCertificates, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserCertificatesRow
+	for rows.Next() {
+		var i ListUserCertificatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrgID,
+			&i.CourseID,
+			&i.LearnerName,
+			&i.CourseTitle,
+			&i.OrganisationName,
+			&i.CompletedAt,
+			&i.IssuedAt,
+			&i.Downloads,
+			&i.LastDownloadedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserNotifications = `-- name: ListUserNotifications :many
+SELECT id, user_id, organisation_id, category, event_type, summary, data, created_at, read_at FROM user_notifications
+WHERE user_id = $1 AND organisation_id = $2
+ORDER BY created_at DESC
+LIMIT $3
+`
+
+type ListUserNotificationsParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Limit          int32     `json:"limit"`
+}
+
+func (q *Queries) ListUserNotifications(ctx context.Context, arg ListUserNotificationsParams) ([]UserNotification, error) {
+	rows, err := q.db.QueryContext(ctx, listUserNotifications, arg.UserID, arg.OrganisationID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserNotification
+	for rows.Next() {
+		var i UserNotification
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrganisationID,
+			&i.Category,
+			&i.EventType,
+			&i.Summary,
+			&i.Data,
+			&i.CreatedAt,
+			&i.ReadAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, webhook_id, organisation_id, event_id, event_type, payload, status, attempts, response_status, error, created_at, last_attempt_at, next_attempt_at, delivered_at FROM webhook_deliveries
+WHERE webhook_id = $1 AND organisation_id = $2
+ORDER BY created_at DESC
+LIMIT $3
 `
 
-type GetH5PLibraryDependenciesRow struct {
-	ID             uuid.UUID `json:"id"`
-	LibraryID      uuid.UUID `json:"library_id"`
-	DependsOnID    uuid.UUID `json:"depends_on_id"`
-	DependencyType string    `json:"dependency_type"`
-	MachineName    string    `json:"machine_name"`
-	MajorVersion   int32     `json:"major_version"`
-	MinorVersion   int32     `json:"minor_version"`
-	PatchVersion   int32     `json:"patch_version"`
-	Title          string    `json:"title"`
+type ListWebhookDeliveriesParams struct {
+	WebhookID      uuid.UUID `json:"webhook_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Limit          int32     `json:"limit"`
 }
 
-func (q *Queries) GetH5PLibraryDependencies(ctx context.Context, libraryID uuid.UUID) ([]GetH5PLibraryDependenciesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getH5PLibraryDependencies, libraryID)
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveries, arg.WebhookID, arg.OrganisationID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetH5PLibraryDependenciesRow
+	var items []WebhookDelivery
 	for rows.Next() {
-		var i GetH5PLibraryDependenciesRow
+		var i WebhookDelivery
 		if err := rows.Scan(
 			&i.ID,
-			&i.LibraryID,
-			&i.DependsOnID,
-			&i.DependencyType,
-			&i.MachineName,
-			&i.MajorVersion,
-			&i.MinorVersion,
-			&i.PatchVersion,
-			&i.Title,
+			&i.WebhookID,
+			&i.OrganisationID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.Error,
+			&i.CreatedAt,
+			&i.LastAttemptAt,
+			&i.NextAttemptAt,
+			&i.DeliveredAt,
 		); err != nil {
 			return nil, err
 		}
@@ -704,63 +7114,67 @@ func (q *Queries) GetH5PLibraryDependencies(ctx context.Context, libraryID uuid.
 	return items, nil
 }
 
-const getH5PLibraryEditorDependencyTree = `-- name: GetH5PLibraryEditorDependencyTree :many
-WITH RECURSIVE dep_tree AS (
-    SELECT d.depends_on_id AS library_id, 0 AS depth
-    FROM h5p_library_dependencies d
-    WHERE d.library_id = $1
-      AND d.dependency_type = 'editor'
-    UNION
-    SELECT d.depends_on_id, dt.depth + 1
-    FROM h5p_library_dependencies d
-    JOIN dep_tree dt ON dt.library_id = d.library_id
-    WHERE dt.depth < 20
-      AND d.dependency_type IN ('preloaded', 'editor')
-),
-dep_max_depth AS (
-    SELECT library_id, MAX(depth) AS max_depth
-    FROM dep_tree
-    GROUP BY library_id
-)
-SELECT l.id, l.created_at, l.updated_at, l.machine_name, l.major_version, l.minor_version, l.patch_version, l.title, l.origin, l.metadata_json, l.categories, l.keywords, l.screenshots, l.description, l.icon_path, l.package_path, l.extracted_path, l.runnable, l.restricted
-FROM dep_max_depth dmd
-JOIN h5p_libraries l ON l.id = dmd.library_id
-ORDER BY dmd.max_depth DESC
+const listXapiContentLearners = `-- name: ListXapiContentLearners :many
+SELECT COALESCE(x.user_id, x.actor_id)::uuid AS learner_id,
+    x.user_id,
+    u.email,
+    count(*) AS statements,
+    bool_or(x.result_completion IS TRUE OR x.verb = 'completed')::bool AS completed,
+    bool_or(x.result_success IS TRUE)::bool AS passed,
+    count(x.result_score_scaled) AS scored,
+    COALESCE(max(x.result_score_scaled), 0)::float8 AS best_score,
+    max(x.created_at)::timestamptz AS last_activity_at
+FROM xapi_statements x
+LEFT JOIN users u ON u.id = x.user_id
+WHERE x.org_id = $1 AND x.content_id = $2
+GROUP BY COALESCE(x.user_id, x.actor_id), x.user_id, u.email
+ORDER BY last_activity_at DESC
+LIMIT $3 OFFSET $4
 `
 
-// Returns all transitive EDITOR dependencies ordered deepest-first (topological).
-// Starts from 'editor' deps of the root library, then follows both 'editor' and
-// 'preloaded' deps of those editor libraries (since editor libs can have preloaded deps).
-// Used by the editor to load widget JS/CSS (e.g. H5PEditor.ShowWhen, H5PEditor.RangeList).
-func (q *Queries) GetH5PLibraryEditorDependencyTree(ctx context.Context, libraryID uuid.UUID) ([]H5pLibrary, error) {
-	rows, err := q.db.QueryContext(ctx, getH5PLibraryEditorDependencyTree, libraryID)
+type ListXapiContentLearnersParams struct {
+	OrgID     uuid.UUID     `json:"org_id"`
+	ContentID uuid.NullUUID `json:"content_id"`
+	Limit     int32         `json:"limit"`
+	Offset    int32         `json:"offset"`
+}
+
+type ListXapiContentLearnersRow struct {
+	LearnerID      uuid.UUID      `json:"learner_id"`
+	UserID         uuid.NullUUID  `json:"user_id"`
+	Email          sql.NullString `json:"email"`
+	Statements     int64          `json:"statements"`
+	Completed      bool           `json:"completed"`
+	Passed         bool           `json:"passed"`
+	Scored         int64          `json:"scored"`
+	BestScore      float64        `json:"best_score"`
+	LastActivityAt time.Time      `json:"last_activity_at"`
+}
+
+func (q *Queries) ListXapiContentLearners(ctx context.Context, arg ListXapiContentLearnersParams) ([]ListXapiContentLearnersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listXapiContentLearners,
+		arg.OrgID,
+		arg.ContentID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []H5pLibrary
+	var items []ListXapiContentLearnersRow
 	for rows.Next() {
-		var i H5pLibrary
+		var i ListXapiContentLearnersRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.MachineName,
-			&i.MajorVersion,
-			&i.MinorVersion,
-			&i.PatchVersion,
-			&i.Title,
-			&i.Origin,
-			&i.MetadataJson,
-			pq.Array(&i.Categories),
-			pq.Array(&i.Keywords),
-			pq.Array(&i.Screenshots),
-			&i.Description,
-			&i.IconPath,
-			&i.PackagePath,
-			&i.ExtractedPath,
-			&i.Runnable,
-			&i.Restricted,
+			&i.LearnerID,
+			&i.UserID,
+			&i.Email,
+			&i.Statements,
+			&i.Completed,
+			&i.Passed,
+			&i.Scored,
+			&i.BestScore,
+			&i.LastActivityAt,
 		); err != nil {
 			return nil, err
 		}
@@ -775,64 +7189,64 @@ func (q *Queries) GetH5PLibraryEditorDependencyTree(ctx context.Context, library
 	return items, nil
 }
 
-const getH5PLibraryFullDependencyTree = `-- name: GetH5PLibraryFullDependencyTree :many
-WITH RECURSIVE dep_tree AS (
-    SELECT d.depends_on_id AS library_id, 0 AS depth
-    FROM h5p_library_dependencies d
-    WHERE d.library_id = $1
-      AND d.dependency_type = 'preloaded'
-    UNION
-    SELECT d.depends_on_id, dt.depth + 1
-    FROM h5p_library_dependencies d
-    JOIN dep_tree dt ON dt.library_id = d.library_id
-    WHERE dt.depth < 20
-      AND d.dependency_type = 'preloaded'
-),
-dep_max_depth AS (
-    SELECT library_id, MAX(depth) AS max_depth
-    FROM dep_tree
-    GROUP BY library_id
-)
-SELECT l.id, l.created_at, l.updated_at, l.machine_name, l.major_version, l.minor_version, l.patch_version, l.title, l.origin, l.metadata_json, l.categories, l.keywords, l.screenshots, l.description, l.icon_path, l.package_path, l.extracted_path, l.runnable, l.restricted
-FROM dep_max_depth dmd
-JOIN h5p_libraries l ON l.id = dmd.library_id
-ORDER BY dmd.max_depth DESC
+const listXapiLearnerSummary = `-- name: ListXapiLearnerSummary :many
+SELECT x.content_id::uuid AS content_id,
+    c.title,
+    count(*) AS statements,
+    bool_or(x.result_completion IS TRUE OR x.verb = 'completed')::bool AS completed,
+    bool_or(x.result_success IS TRUE)::bool AS passed,
+    count(x.result_score_scaled) AS scored,
+    COALESCE(max(x.result_score_scaled), 0)::float8 AS best_score,
+    max(x.created_at)::timestamptz AS last_activity_at
+FROM xapi_statements x
+JOIN h5p_content c ON c.id = x.content_id
+WHERE x.org_id = $1 AND x.user_id = $2
+GROUP BY x.content_id, c.title
+ORDER BY last_activity_at DESC
+LIMIT $3 OFFSET $4
 `
 
-// Returns all transitive PRELOADED dependencies ordered deepest-first (topological).
-// This ensures leaf dependencies (e.g. H5P.EventDispatcher) load before
-// libraries that extend them (e.g. H5P.Question).
-// Only follows 'preloaded' dependencies — editor and dynamic deps are excluded
-// so that playback doesn't try to load editor-only libraries (H5PEditor.*).
-func (q *Queries) GetH5PLibraryFullDependencyTree(ctx context.Context, libraryID uuid.UUID) ([]H5pLibrary, error) {
-	rows, err := q.db.QueryContext(ctx, getH5PLibraryFullDependencyTree, libraryID)
+type ListXapiLearnerSummaryParams struct {
+	OrgID  uuid.UUID     `json:"org_id"`
+	UserID uuid.NullUUID `json:"user_id"`
+	Limit  int32         `json:"limit"`
+	Offset int32         `json:"offset"`
+}
+
+type ListXapiLearnerSummaryRow struct {
+	ContentID      uuid.UUID `json:"content_id"`
+	Title          string    `json:"title"`
+	Statements     int64     `json:"statements"`
+	Completed      bool      `json:"completed"`
+	Passed         bool      `json:"passed"`
+	Scored         int64     `json:"scored"`
+	BestScore      float64   `json:"best_score"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+func (q *Queries) ListXapiLearnerSummary(ctx context.Context, arg ListXapiLearnerSummaryParams) ([]ListXapiLearnerSummaryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listXapiLearnerSummary,
+		arg.OrgID,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []H5pLibrary
+	var items []ListXapiLearnerSummaryRow
 	for rows.Next() {
-		var i H5pLibrary
+		var i ListXapiLearnerSummaryRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.MachineName,
-			&i.MajorVersion,
-			&i.MinorVersion,
-			&i.PatchVersion,
+			&i.ContentID,
 			&i.Title,
-			&i.Origin,
-			&i.MetadataJson,
-			pq.Array(&i.Categories),
-			pq.Array(&i.Keywords),
-			pq.Array(&i.Screenshots),
-			&i.Description,
-			&i.IconPath,
-			&i.PackagePath,
-			&i.ExtractedPath,
-			&i.Runnable,
-			&i.Restricted,
+			&i.Statements,
+			&i.Completed,
+			&i.Passed,
+			&i.Scored,
+			&i.BestScore,
+			&i.LastActivityAt,
 		); err != nil {
 			return nil, err
 		}
@@ -847,355 +7261,512 @@ func (q *Queries) GetH5PLibraryFullDependencyTree(ctx context.Context, libraryID
 	return items, nil
 }
 
-const getOrganisationBillingInfo = `-- name: GetOrganisationBillingInfo :one
+const lockContentOfLockedCourses = `-- name: LockContentOfLockedCourses :execrows
+UPDATE h5p_content c SET locked_at = current_timestamp, lock_reason = $2
+WHERE c.org_id = $1 AND c.deleted_at IS NULL AND c.locked_at IS NULL
+    AND EXISTS (
+        SELECT 1 FROM course_items ci JOIN courses co ON co.id = ci.course_id
+        WHERE ci.content_id = c.id AND ci.removed_at IS NULL
+            AND co.deleted_at IS NULL AND co.locked_at IS NOT NULL
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM course_items ci JOIN courses co ON co.id = ci.course_id
+        WHERE ci.content_id = c.id AND ci.removed_at IS NULL
+            AND co.deleted_at IS NULL AND co.locked_at IS NULL
+    )
+`
 
-SELECT
-    id,
-    name,
-    slug,
-    subscription_tier,
-    subscription_id,
-    subscription_end,
-    stripe_customer_id,
-    ai_generations_this_month,
-    ai_generations_reset_at,
-    is_freemium,
-    freemium_expires_at
-FROM organisations
+type LockContentOfLockedCoursesParams struct {
+	OrgID      uuid.UUID      `json:"org_id"`
+	LockReason sql.NullString `json:"lock_reason"`
+}
+
+func (q *Queries) LockContentOfLockedCourses(ctx context.Context, arg LockContentOfLockedCoursesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, lockContentOfLockedCourses, arg.OrgID, arg.LockReason)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const lockCoursesOverLimit = `-- name: LockCoursesOverLimit :execrows
+
+UPDATE courses c SET locked_at = current_timestamp, lock_reason = $3
+WHERE c.org_id = $1 AND c.deleted_at IS NULL AND c.locked_at IS NULL
+    AND c.id NOT IN (
+        SELECT keep.id FROM courses keep
+        WHERE keep.org_id = $1 AND keep.deleted_at IS NULL
+        ORDER BY keep.updated_at DESC
+        LIMIT $2
+    )
+`
+
+type LockCoursesOverLimitParams struct {
+	OrgID      uuid.UUID      `json:"org_id"`
+	Limit      int32          `json:"limit"`
+	LockReason sql.NullString `json:"lock_reason"`
+}
+
+// =============================================================================
+// Grace-Period Content Locks
+// =============================================================================
+func (q *Queries) LockCoursesOverLimit(ctx context.Context, arg LockCoursesOverLimitParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, lockCoursesOverLimit, arg.OrgID, arg.Limit, arg.LockReason)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markAnnouncementRead = `-- name: MarkAnnouncementRead :exec
+INSERT INTO announcement_reads (announcement_id, user_id)
+SELECT a.id, $1::uuid
+FROM announcements a
+WHERE a.id = $2 AND a.published_at <= now()
+ON CONFLICT DO NOTHING
+`
+
+type MarkAnnouncementReadParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	AnnouncementID uuid.UUID `json:"announcement_id"`
+}
+
+func (q *Queries) MarkAnnouncementRead(ctx context.Context, arg MarkAnnouncementReadParams) error {
+	_, err := q.db.ExecContext(ctx, markAnnouncementRead, arg.UserID, arg.AnnouncementID)
+	return err
+}
+
+const markCompetitorPinRefreshed = `-- name: MarkCompetitorPinRefreshed :exec
+UPDATE competitor_pins
+SET refreshed_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) MarkCompetitorPinRefreshed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markCompetitorPinRefreshed, id)
+	return err
+}
+
+const markRankTrackerVolumesRefreshed = `-- name: MarkRankTrackerVolumesRefreshed :exec
+UPDATE rank_trackers
+SET volumes_refreshed_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) MarkRankTrackerVolumesRefreshed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markRankTrackerVolumesRefreshed, id)
+	return err
+}
+
+const markRetentionPolicyEvaluated = `-- name: MarkRetentionPolicyEvaluated :exec
+UPDATE organisation_retention_policies SET evaluated_at = now()
+WHERE organisation_id = $1
+`
+
+func (q *Queries) MarkRetentionPolicyEvaluated(ctx context.Context, organisationID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markRetentionPolicyEvaluated, organisationID)
+	return err
+}
+
+const markUserNotificationsRead = `-- name: MarkUserNotificationsRead :execrows
+UPDATE user_notifications SET read_at = NOW()
+WHERE user_id = $1 AND organisation_id = $2 AND read_at IS NULL
+`
+
+type MarkUserNotificationsReadParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+}
+
+func (q *Queries) MarkUserNotificationsRead(ctx context.Context, arg MarkUserNotificationsReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markUserNotificationsRead, arg.UserID, arg.OrganisationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeDeletedH5PContent = `-- name: PurgeDeletedH5PContent :many
+WITH purged AS (
+    DELETE FROM h5p_content
+    WHERE id IN (
+        SELECT c.id FROM h5p_content c
+        WHERE c.org_id = $1 AND c.deleted_at < $2
+        ORDER BY c.deleted_at
+        LIMIT $3
+    )
+    RETURNING id, org_id
+), queued AS (
+    INSERT INTO pending_deletions (storage_key, reason)
+    SELECT 'h5p-content/' || org_id || '/' || id || '/', 'retention' FROM purged
+    ON CONFLICT (storage_key) DO NOTHING
+)
+SELECT id FROM purged
+`
+
+type PurgeDeletedH5PContentParams struct {
+	OrgID     uuid.UUID    `json:"org_id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	Limit     int32        `json:"limit"`
+}
+
+func (q *Queries) PurgeDeletedH5PContent(ctx context.Context, arg PurgeDeletedH5PContentParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedH5PContent, arg.OrgID, arg.DeletedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const queueOrphanedH5PLibraryFiles = `-- name: QueueOrphanedH5PLibraryFiles :execrows
+INSERT INTO pending_deletions (storage_key, reason, next_attempt_at)
+SELECT k.storage_key, 'orphan', $1::timestamptz
+FROM unnest($2::text[]) AS k(storage_key)
+WHERE NOT EXISTS (
+    SELECT 1 FROM h5p_libraries l
+    WHERE k.storage_key = 'h5p-libraries/extracted/' || l.machine_name || '-' || l.major_version || '.' || l.minor_version || '.' || l.patch_version || '/'
+       OR k.storage_key = l.package_path
+)
+ON CONFLICT (storage_key) DO NOTHING
+`
+
+type QueueOrphanedH5PLibraryFilesParams struct {
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	StorageKeys   []string  `json:"storage_keys"`
+}
+
+func (q *Queries) QueueOrphanedH5PLibraryFiles(ctx context.Context, arg QueueOrphanedH5PLibraryFilesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, queueOrphanedH5PLibraryFiles, arg.NextAttemptAt, pq.Array(arg.StorageKeys))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recheckSeoPageIssues = `-- name: RecheckSeoPageIssues :exec
+UPDATE seo_page_experience
+SET issues = $2, rechecked_at = now()
 WHERE id = $1
 `
 
-type GetOrganisationBillingInfoRow struct {
-	ID                     uuid.UUID    `json:"id"`
-	Name                   string       `json:"name"`
-	Slug                   string       `json:"slug"`
-	SubscriptionTier       string       `json:"subscription_tier"`
-	SubscriptionID         string       `json:"subscription_id"`
-	SubscriptionEnd        sql.NullTime `json:"subscription_end"`
-	StripeCustomerID       string       `json:"stripe_customer_id"`
-	AiGenerationsThisMonth int32        `json:"ai_generations_this_month"`
-	AiGenerationsResetAt   sql.NullTime `json:"ai_generations_reset_at"`
-	IsFreemium             bool         `json:"is_freemium"`
-	FreemiumExpiresAt      sql.NullTime `json:"freemium_expires_at"`
+type RecheckSeoPageIssuesParams struct {
+	ID     uuid.UUID       `json:"id"`
+	Issues json.RawMessage `json:"issues"`
 }
 
-// =============================================================================
-// Organisation Billing Queries (Platform Subscriptions)
-// =============================================================================
-func (q *Queries) GetOrganisationBillingInfo(ctx context.Context, id uuid.UUID) (GetOrganisationBillingInfoRow, error) {
-	row := q.db.QueryRowContext(ctx, getOrganisationBillingInfo, id)
-	var i GetOrganisationBillingInfoRow
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Slug,
-		&i.SubscriptionTier,
-		&i.SubscriptionID,
-		&i.SubscriptionEnd,
-		&i.StripeCustomerID,
-		&i.AiGenerationsThisMonth,
-		&i.AiGenerationsResetAt,
-		&i.IsFreemium,
-		&i.FreemiumExpiresAt,
-	)
-	return i, err
+func (q *Queries) RecheckSeoPageIssues(ctx context.Context, arg RecheckSeoPageIssuesParams) error {
+	_, err := q.db.ExecContext(ctx, recheckSeoPageIssues, arg.ID, arg.Issues)
+	return err
 }
 
-const getOrganisationByStripeCustomer = `-- name: GetOrganisationByStripeCustomer :one
-SELECT id, created_at, updated_at, name, slug, logo_url, logo_avatar_url, primary_color, secondary_color, accent_color, accent_gradient, email, phone, website, status, subscription_tier, subscription_id, subscription_end, stripe_customer_id, ai_generations_this_month, ai_generations_reset_at, is_freemium, freemium_reason, freemium_expires_at, freemium_granted_at, freemium_granted_by, deleted_at, deletion_scheduled_for FROM organisations
-WHERE stripe_customer_id = $1
+const recordCertificateDownload = `-- name: RecordCertificateDownload :exec
+INSERT INTO certificate_downloads (certificate_id, user_id) VALUES ($1, $2)
 `
 
-func (q *Queries) GetOrganisationByStripeCustomer(ctx context.Context, stripeCustomerID string) (Organisation, error) {
-	row := q.db.QueryRowContext(ctx, getOrganisationByStripeCustomer, stripeCustomerID)
-	var i Organisation
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Slug,
-		&i.LogoUrl,
-		&i.LogoAvatarUrl,
-		&i.PrimaryColor,
-		&i.SecondaryColor,
-		&i.AccentColor,
-		&i.AccentGradient,
-		&i.Email,
-		&i.Phone,
-		&i.Website,
-		&i.Status,
-		&i.SubscriptionTier,
-		&i.SubscriptionID,
-		&i.SubscriptionEnd,
-		&i.StripeCustomerID,
-		&i.AiGenerationsThisMonth,
-		&i.AiGenerationsResetAt,
-		&i.IsFreemium,
-		&i.FreemiumReason,
-		&i.FreemiumExpiresAt,
-		&i.FreemiumGrantedAt,
-		&i.FreemiumGrantedBy,
-		&i.DeletedAt,
-		&i.DeletionScheduledFor,
-	)
-	return i, err
+type RecordCertificateDownloadParams struct {
+	CertificateID uuid.UUID `json:"certificate_id"`
+	UserID        uuid.UUID `json:"user_id"`
 }
 
-const insertH5PLibraryDependency = `-- name: InsertH5PLibraryDependency :exec
+func (q *Queries) RecordCertificateDownload(ctx context.Context, arg RecordCertificateDownloadParams) error {
+	_, err := q.db.ExecContext(ctx, recordCertificateDownload, arg.CertificateID, arg.UserID)
+	return err
+}
 
-INSERT INTO h5p_library_dependencies (id, library_id, depends_on_id, dependency_type)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (library_id, depends_on_id, dependency_type) DO NOTHING
+const recordH5PBundleReport = `-- name: RecordH5PBundleReport :exec
+INSERT INTO h5p_bundle_reports (variant, day, loads, errors)
+VALUES ($1, CURRENT_DATE, $2, $3)
+ON CONFLICT (variant, day) DO UPDATE
+SET loads = h5p_bundle_reports.loads + EXCLUDED.loads,
+    errors = h5p_bundle_reports.errors + EXCLUDED.errors
 `
 
-type InsertH5PLibraryDependencyParams struct {
-	ID             uuid.UUID `json:"id"`
-	LibraryID      uuid.UUID `json:"library_id"`
-	DependsOnID    uuid.UUID `json:"depends_on_id"`
-	DependencyType string    `json:"dependency_type"`
+type RecordH5PBundleReportParams struct {
+	Variant string `json:"variant"`
+	Loads   int64  `json:"loads"`
+	Errors  int64  `json:"errors"`
 }
 
-// =============================================================================
-// H5P Library Dependencies
-// =============================================================================
-func (q *Queries) InsertH5PLibraryDependency(ctx context.Context, arg InsertH5PLibraryDependencyParams) error {
-	_, err := q.db.ExecContext(ctx, insertH5PLibraryDependency,
-		arg.ID,
-		arg.LibraryID,
-		arg.DependsOnID,
-		arg.DependencyType,
-	)
+func (q *Queries) RecordH5PBundleReport(ctx context.Context, arg RecordH5PBundleReportParams) error {
+	_, err := q.db.ExecContext(ctx, recordH5PBundleReport, arg.Variant, arg.Loads, arg.Errors)
 	return err
 }
 
-const insertToken = `-- name: InsertToken :one
-insert into tokens (id, expires, target, callback) values ($1, $2, $3, $4) returning id, expires, target, callback
+const recordPendingDeletionFailure = `-- name: RecordPendingDeletionFailure :exec
+UPDATE pending_deletions
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+WHERE id = $1
 `
 
-type InsertTokenParams struct {
-	ID       string    `json:"id"`
-	Expires  time.Time `json:"expires"`
-	Target   string    `json:"target"`
-	Callback string    `json:"callback"`
+type RecordPendingDeletionFailureParams struct {
+	ID            uuid.UUID `json:"id"`
+	LastError     string    `json:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
 }
 
-func (q *Queries) InsertToken(ctx context.Context, arg InsertTokenParams) (Token, error) {
-	row := q.db.QueryRowContext(ctx, insertToken,
-		arg.ID,
-		arg.Expires,
-		arg.Target,
-		arg.Callback,
-	)
-	var i Token
-	err := row.Scan(
-		&i.ID,
-		&i.Expires,
-		&i.Target,
-		&i.Callback,
-	)
-	return i, err
+func (q *Queries) RecordPendingDeletionFailure(ctx context.Context, arg RecordPendingDeletionFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordPendingDeletionFailure, arg.ID, arg.LastError, arg.NextAttemptAt)
+	return err
 }
 
-const insertUser = `-- name: InsertUser :one
-insert into users (id, email, access, sub, avatar, api_key) values ($1, $2, $3, $4, $5, $6) returning id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_organisation_id, suspended, suspended_at, suspended_reason
+const recordWebhookDeliveryAttempt = `-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    response_status = $3,
+    error = $4,
+    last_attempt_at = now(),
+    next_attempt_at = $5,
+    delivered_at = CASE WHEN $2 = 'delivered' THEN now() END
+WHERE id = $1
 `
 
-type InsertUserParams struct {
-	ID     uuid.UUID `json:"id"`
-	Email  string    `json:"email"`
-	Access int64     `json:"access"`
-	Sub    string    `json:"sub"`
-	Avatar string    `json:"avatar"`
-	ApiKey string    `json:"api_key"`
+type RecordWebhookDeliveryAttemptParams struct {
+	ID             uuid.UUID     `json:"id"`
+	Status         string        `json:"status"`
+	ResponseStatus sql.NullInt32 `json:"response_status"`
+	Error          string        `json:"error"`
+	NextAttemptAt  time.Time     `json:"next_attempt_at"`
 }
 
-func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, insertUser,
+func (q *Queries) RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, recordWebhookDeliveryAttempt,
 		arg.ID,
-		arg.Email,
-		arg.Access,
-		arg.Sub,
-		arg.Avatar,
-		arg.ApiKey,
-	)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.Created,
-		&i.Updated,
-		&i.Email,
-		&i.Phone,
-		&i.Access,
-		&i.Sub,
-		&i.Avatar,
-		&i.CustomerID,
-		&i.SubscriptionID,
-		&i.SubscriptionEnd,
-		&i.ApiKey,
-		&i.DefaultOrganisationID,
-		&i.Suspended,
-		&i.SuspendedAt,
-		&i.SuspendedReason,
+		arg.Status,
+		arg.ResponseStatus,
+		arg.Error,
+		arg.NextAttemptAt,
 	)
-	return i, err
+	return err
 }
 
-const insertXapiStatement = `-- name: InsertXapiStatement :one
-
-INSERT INTO xapi_statements (org_id, user_id, content_id, verb, statement)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, org_id, user_id, content_id, verb, statement
+const recordXapiStatement = `-- name: RecordXapiStatement :execrows
+INSERT INTO xapi_statements (
+    org_id, user_id, content_id, verb, statement, statement_id, verb_id, object_id,
+    result_score_raw, result_score_max, result_score_scaled, result_success, result_completion, result_duration
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (statement_id) DO NOTHING
 `
 
-type InsertXapiStatementParams struct {
-	OrgID     uuid.UUID       `json:"org_id"`
-	UserID    uuid.UUID       `json:"user_id"`
-	ContentID uuid.NullUUID   `json:"content_id"`
-	Verb      string          `json:"verb"`
-	Statement json.RawMessage `json:"statement"`
+type RecordXapiStatementParams struct {
+	OrgID             uuid.UUID       `json:"org_id"`
+	UserID            uuid.NullUUID   `json:"user_id"`
+	ContentID         uuid.NullUUID   `json:"content_id"`
+	Verb              string          `json:"verb"`
+	Statement         json.RawMessage `json:"statement"`
+	StatementID       uuid.NullUUID   `json:"statement_id"`
+	VerbID            sql.NullString  `json:"verb_id"`
+	ObjectID          sql.NullString  `json:"object_id"`
+	ResultScoreRaw    sql.NullFloat64 `json:"result_score_raw"`
+	ResultScoreMax    sql.NullFloat64 `json:"result_score_max"`
+	ResultScoreScaled sql.NullFloat64 `json:"result_score_scaled"`
+	ResultSuccess     sql.NullBool    `json:"result_success"`
+	ResultCompletion  sql.NullBool    `json:"result_completion"`
+	ResultDuration    sql.NullInt32   `json:"result_duration"`
 }
 
-// =============================================================================
-// XAPI & PROGRESS QUERIES (Phase 3)
-// =============================================================================
-func (q *Queries) InsertXapiStatement(ctx context.Context, arg InsertXapiStatementParams) (XapiStatement, error) {
-	row := q.db.QueryRowContext(ctx, insertXapiStatement,
+func (q *Queries) RecordXapiStatement(ctx context.Context, arg RecordXapiStatementParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, recordXapiStatement,
 		arg.OrgID,
 		arg.UserID,
 		arg.ContentID,
 		arg.Verb,
 		arg.Statement,
+		arg.StatementID,
+		arg.VerbID,
+		arg.ObjectID,
+		arg.ResultScoreRaw,
+		arg.ResultScoreMax,
+		arg.ResultScoreScaled,
+		arg.ResultSuccess,
+		arg.ResultCompletion,
+		arg.ResultDuration,
 	)
-	var i XapiStatement
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.OrgID,
-		&i.UserID,
-		&i.ContentID,
-		&i.Verb,
-		&i.Statement,
-	)
-	return i, err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const listH5PContentByOrg = `-- name: ListH5PContentByOrg :many
-SELECT c.id, c.created_at, c.updated_at, c.org_id, c.library_id, c.created_by, c.title, c.slug, c.description, c.content_json, c.tags, c.folder_path, c.storage_path, c.status, c.deleted_at, l.machine_name, l.title as library_title,
-    l.major_version as library_major, l.minor_version as library_minor,
-    l.patch_version as library_patch
-FROM h5p_content c JOIN h5p_libraries l ON c.library_id = l.id
-WHERE c.org_id = $1 AND c.deleted_at IS NULL
-ORDER BY c.updated_at DESC LIMIT $2 OFFSET $3
+const releaseJobLock = `-- name: ReleaseJobLock :exec
+DELETE FROM job_locks
+WHERE name = $1 AND holder = $2
 `
 
-type ListH5PContentByOrgParams struct {
-	OrgID  uuid.UUID `json:"org_id"`
-	Limit  int32     `json:"limit"`
-	Offset int32     `json:"offset"`
+type ReleaseJobLockParams struct {
+	Name   string `json:"name"`
+	Holder string `json:"holder"`
 }
 
-type ListH5PContentByOrgRow struct {
-	ID           uuid.UUID       `json:"id"`
-	CreatedAt    time.Time       `json:"created_at"`
-	UpdatedAt    time.Time       `json:"updated_at"`
-	OrgID        uuid.UUID       `json:"org_id"`
-	LibraryID    uuid.UUID       `json:"library_id"`
-	CreatedBy    uuid.NullUUID   `json:"created_by"`
-	Title        string          `json:"title"`
-	Slug         string          `json:"slug"`
-	Description  string          `json:"description"`
-	ContentJson  json.RawMessage `json:"content_json"`
-	Tags         []string        `json:"tags"`
-	FolderPath   sql.NullString  `json:"folder_path"`
-	StoragePath  sql.NullString  `json:"storage_path"`
-	Status       string          `json:"status"`
-	DeletedAt    sql.NullTime    `json:"deleted_at"`
-	MachineName  string          `json:"machine_name"`
-	LibraryTitle string          `json:"library_title"`
-	LibraryMajor int32           `json:"library_major"`
-	LibraryMinor int32           `json:"library_minor"`
-	LibraryPatch int32           `json:"library_patch"`
+func (q *Queries) ReleaseJobLock(ctx context.Context, arg ReleaseJobLockParams) error {
+	_, err := q.db.ExecContext(ctx, releaseJobLock, arg.Name, arg.Holder)
+	return err
 }
 
-func (q *Queries) ListH5PContentByOrg(ctx context.Context, arg ListH5PContentByOrgParams) ([]ListH5PContentByOrgRow, error) {
-	rows, err := q.db.QueryContext(ctx, listH5PContentByOrg, arg.OrgID, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ListH5PContentByOrgRow
-	for rows.Next() {
-		var i ListH5PContentByOrgRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.OrgID,
-			&i.LibraryID,
-			&i.CreatedBy,
-			&i.Title,
-			&i.Slug,
-			&i.Description,
-			&i.ContentJson,
-			pq.Array(&i.Tags),
-			&i.FolderPath,
-			&i.StoragePath,
-			&i.Status,
-			&i.DeletedAt,
-			&i.MachineName,
-			&i.LibraryTitle,
-			&i.LibraryMajor,
-			&i.LibraryMinor,
-			&i.LibraryPatch,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+const requeueWebhookDelivery = `-- name: RequeueWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, error = '', next_attempt_at = $4
+WHERE id = $1 AND webhook_id = $2 AND organisation_id = $3 AND status <> 'pending'
+RETURNING id, webhook_id, organisation_id, event_id, event_type, payload, status, attempts, response_status, error, created_at, last_attempt_at, next_attempt_at, delivered_at
+`
+
+type RequeueWebhookDeliveryParams struct {
+	ID             uuid.UUID `json:"id"`
+	WebhookID      uuid.UUID `json:"webhook_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
 }
 
-const listH5PLibraries = `-- name: ListH5PLibraries :many
-SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries
-ORDER BY machine_name ASC, major_version DESC, minor_version DESC, patch_version DESC
+func (q *Queries) RequeueWebhookDelivery(ctx context.Context, arg RequeueWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, requeueWebhookDelivery,
+		arg.ID,
+		arg.WebhookID,
+		arg.OrganisationID,
+		arg.NextAttemptAt,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.OrganisationID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.Error,
+		&i.CreatedAt,
+		&i.LastAttemptAt,
+		&i.NextAttemptAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const resetSeoPageExperience = `-- name: ResetSeoPageExperience :one
+UPDATE seo_page_experience
+SET status = 'pending', error = '', created_at = now(), completed_at = NULL
+WHERE id = $1 AND status <> 'completed'
+RETURNING id, organisation_id, audit_id, page_url, strategy, created_by, created_at, completed_at, status, error, intrusive_interstitial, score, passed, cwv_source, lcp_ms, inp_ms, cls, signals, pagespeed, issues, rechecked_at
 `
 
-func (q *Queries) ListH5PLibraries(ctx context.Context) ([]H5pLibrary, error) {
-	rows, err := q.db.QueryContext(ctx, listH5PLibraries)
+func (q *Queries) ResetSeoPageExperience(ctx context.Context, id uuid.UUID) (SeoPageExperience, error) {
+	row := q.db.QueryRowContext(ctx, resetSeoPageExperience, id)
+	var i SeoPageExperience
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.AuditID,
+		&i.PageUrl,
+		&i.Strategy,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.Status,
+		&i.Error,
+		&i.IntrusiveInterstitial,
+		&i.Score,
+		&i.Passed,
+		&i.CwvSource,
+		&i.LcpMs,
+		&i.InpMs,
+		&i.Cls,
+		&i.Signals,
+		&i.Pagespeed,
+		&i.Issues,
+		&i.RecheckedAt,
+	)
+	return i, err
+}
+
+const scheduleSeoKeywordList = `-- name: ScheduleSeoKeywordList :exec
+UPDATE seo_keyword_lists
+SET last_refreshed_at = now(), next_refresh_at = $2
+WHERE id = $1
+`
+
+type ScheduleSeoKeywordListParams struct {
+	ID            uuid.UUID `json:"id"`
+	NextRefreshAt time.Time `json:"next_refresh_at"`
+}
+
+func (q *Queries) ScheduleSeoKeywordList(ctx context.Context, arg ScheduleSeoKeywordListParams) error {
+	_, err := q.db.ExecContext(ctx, scheduleSeoKeywordList, arg.ID, arg.NextRefreshAt)
+	return err
+}
+
+const searchCourses = `-- name: SearchCourses :many
+SELECT id, title, status, updated_at, (locked_at IS NOT NULL)::boolean AS locked,
+    (CASE
+        WHEN title ILIKE $1::text THEN 0
+        WHEN title ILIKE $1::text || '%' THEN 1
+        WHEN title ILIKE '% ' || $1::text || '%' THEN 2
+        WHEN title ILIKE '%' || $1::text || '%' THEN 3
+        ELSE 4
+    END)::int AS rank
+FROM courses
+WHERE org_id = $2 AND deleted_at IS NULL
+    AND ($3::boolean OR status = 'published')
+    AND (title ILIKE '%' || $1::text || '%'
+        OR description ILIKE '%' || $1::text || '%')
+ORDER BY rank, updated_at DESC
+LIMIT $4
+`
+
+type SearchCoursesParams struct {
+	Pattern       string    `json:"pattern"`
+	OrgID         uuid.UUID `json:"org_id"`
+	IncludeDrafts bool      `json:"include_drafts"`
+	MaxResults    int32     `json:"max_results"`
+}
+
+type SearchCoursesRow struct {
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Locked    bool      `json:"locked"`
+	Rank      int32     `json:"rank"`
+}
+
+func (q *Queries) SearchCourses(ctx context.Context, arg SearchCoursesParams) ([]SearchCoursesRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchCourses,
+		arg.Pattern,
+		arg.OrgID,
+		arg.IncludeDrafts,
+		arg.MaxResults,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []H5pLibrary
+	var items []SearchCoursesRow
 	for rows.Next() {
-		var i H5pLibrary
+		var i SearchCoursesRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.MachineName,
-			&i.MajorVersion,
-			&i.MinorVersion,
-			&i.PatchVersion,
 			&i.Title,
-			&i.Origin,
-			&i.MetadataJson,
-			pq.Array(&i.Categories),
-			pq.Array(&i.Keywords),
-			pq.Array(&i.Screenshots),
-			&i.Description,
-			&i.IconPath,
-			&i.PackagePath,
-			&i.ExtractedPath,
-			&i.Runnable,
-			&i.Restricted,
+			&i.Status,
+			&i.UpdatedAt,
+			&i.Locked,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -1210,58 +7781,71 @@ func (q *Queries) ListH5PLibraries(ctx context.Context) ([]H5pLibrary, error) {
 	return items, nil
 }
 
-const listH5POrgEnabledLibraries = `-- name: ListH5POrgEnabledLibraries :many
-SELECT ol.id, ol.created_at, ol.org_id, ol.library_id, ol.enabled, ol.restricted, l.machine_name, l.major_version, l.minor_version, l.patch_version,
-       l.title, l.description, l.icon_path, l.runnable, l.origin
-FROM h5p_org_libraries ol
-JOIN h5p_libraries l ON l.id = ol.library_id
-WHERE ol.org_id = $1 AND ol.enabled = true
-ORDER BY l.title ASC
+const searchH5PContent = `-- name: SearchH5PContent :many
+
+SELECT c.id, c.title, c.status, c.updated_at, (c.locked_at IS NOT NULL)::boolean AS locked,
+    l.title AS library_title,
+    (CASE
+        WHEN c.title ILIKE $1::text THEN 0
+        WHEN c.title ILIKE $1::text || '%' THEN 1
+        WHEN c.title ILIKE '% ' || $1::text || '%' THEN 2
+        WHEN c.title ILIKE '%' || $1::text || '%' THEN 3
+        ELSE 4
+    END)::int AS rank
+FROM h5p_content c JOIN h5p_libraries l ON c.library_id = l.id
+WHERE c.org_id = $2 AND c.deleted_at IS NULL
+    AND ($3::boolean OR c.status = 'published')
+    AND (c.title ILIKE '%' || $1::text || '%'
+        OR c.description ILIKE '%' || $1::text || '%'
+        OR array_to_string(c.tags, ' ') ILIKE '%' || $1::text || '%')
+ORDER BY rank, c.updated_at DESC
+LIMIT $4
 `
 
-type ListH5POrgEnabledLibrariesRow struct {
-	ID           uuid.UUID      `json:"id"`
-	CreatedAt    time.Time      `json:"created_at"`
-	OrgID        uuid.UUID      `json:"org_id"`
-	LibraryID    uuid.UUID      `json:"library_id"`
-	Enabled      bool           `json:"enabled"`
-	Restricted   bool           `json:"restricted"`
-	MachineName  string         `json:"machine_name"`
-	MajorVersion int32          `json:"major_version"`
-	MinorVersion int32          `json:"minor_version"`
-	PatchVersion int32          `json:"patch_version"`
-	Title        string         `json:"title"`
-	Description  string         `json:"description"`
-	IconPath     sql.NullString `json:"icon_path"`
-	Runnable     bool           `json:"runnable"`
-	Origin       string         `json:"origin"`
+type SearchH5PContentParams struct {
+	Pattern       string    `json:"pattern"`
+	OrgID         uuid.UUID `json:"org_id"`
+	IncludeDrafts bool      `json:"include_drafts"`
+	MaxResults    int32     `json:"max_results"`
 }
 
-func (q *Queries) ListH5POrgEnabledLibraries(ctx context.Context, orgID uuid.UUID) ([]ListH5POrgEnabledLibrariesRow, error) {
-	rows, err := q.db.QueryContext(ctx, listH5POrgEnabledLibraries, orgID)
+type SearchH5PContentRow struct {
+	ID           uuid.UUID `json:"id"`
+	Title        string    `json:"title"`
+	Status       string    `json:"status"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Locked       bool      `json:"locked"`
+	LibraryTitle string    `json:"library_title"`
+	Rank         int32     `json:"rank"`
+}
+
+// =============================================================================
+// Search (command palette)
+// =============================================================================
+// pattern is the search term with LIKE wildcards escaped. Rank: 0 exact title,
+// 1 title prefix, 2 word prefix, 3 title substring, 4 other field match.
+func (q *Queries) SearchH5PContent(ctx context.Context, arg SearchH5PContentParams) ([]SearchH5PContentRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchH5PContent,
+		arg.Pattern,
+		arg.OrgID,
+		arg.IncludeDrafts,
+		arg.MaxResults,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListH5POrgEnabledLibrariesRow
+	var items []SearchH5PContentRow
 	for rows.Next() {
-		var i ListH5POrgEnabledLibrariesRow
+		var i SearchH5PContentRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.CreatedAt,
-			&i.OrgID,
-			&i.LibraryID,
-			&i.Enabled,
-			&i.Restricted,
-			&i.MachineName,
-			&i.MajorVersion,
-			&i.MinorVersion,
-			&i.PatchVersion,
 			&i.Title,
-			&i.Description,
-			&i.IconPath,
-			&i.Runnable,
-			&i.Origin,
+			&i.Status,
+			&i.UpdatedAt,
+			&i.Locked,
+			&i.LibraryTitle,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -1276,62 +7860,49 @@ func (q *Queries) ListH5POrgEnabledLibraries(ctx context.Context, orgID uuid.UUI
 	return items, nil
 }
 
-const listH5POrgLibraries = `-- name: ListH5POrgLibraries :many
-
-SELECT ol.id, ol.created_at, ol.org_id, ol.library_id, ol.enabled, ol.restricted, l.machine_name, l.major_version, l.minor_version, l.patch_version,
-       l.title, l.description, l.icon_path, l.runnable, l.origin
-FROM h5p_org_libraries ol
-JOIN h5p_libraries l ON l.id = ol.library_id
-WHERE ol.org_id = $1
-ORDER BY l.title ASC
+const searchH5PContentFolders = `-- name: SearchH5PContentFolders :many
+SELECT id, name, parent_id, updated_at,
+    (CASE
+        WHEN name ILIKE $1::text THEN 0
+        WHEN name ILIKE $1::text || '%' THEN 1
+        WHEN name ILIKE '% ' || $1::text || '%' THEN 2
+        ELSE 3
+    END)::int AS rank
+FROM h5p_content_folders
+WHERE org_id = $2 AND name ILIKE '%' || $1::text || '%'
+ORDER BY rank, updated_at DESC
+LIMIT $3
 `
 
-type ListH5POrgLibrariesRow struct {
-	ID           uuid.UUID      `json:"id"`
-	CreatedAt    time.Time      `json:"created_at"`
-	OrgID        uuid.UUID      `json:"org_id"`
-	LibraryID    uuid.UUID      `json:"library_id"`
-	Enabled      bool           `json:"enabled"`
-	Restricted   bool           `json:"restricted"`
-	MachineName  string         `json:"machine_name"`
-	MajorVersion int32          `json:"major_version"`
-	MinorVersion int32          `json:"minor_version"`
-	PatchVersion int32          `json:"patch_version"`
-	Title        string         `json:"title"`
-	Description  string         `json:"description"`
-	IconPath     sql.NullString `json:"icon_path"`
-	Runnable     bool           `json:"runnable"`
-	Origin       string         `json:"origin"`
+type SearchH5PContentFoldersParams struct {
+	Pattern    string    `json:"pattern"`
+	OrgID      uuid.UUID `json:"org_id"`
+	MaxResults int32     `json:"max_results"`
 }
 
-// =============================================================================
-// H5P Org Libraries (Per-organisation enablement)
-// =============================================================================
-func (q *Queries) ListH5POrgLibraries(ctx context.Context, orgID uuid.UUID) ([]ListH5POrgLibrariesRow, error) {
-	rows, err := q.db.QueryContext(ctx, listH5POrgLibraries, orgID)
+type SearchH5PContentFoldersRow struct {
+	ID        uuid.UUID     `json:"id"`
+	Name      string        `json:"name"`
+	ParentID  uuid.NullUUID `json:"parent_id"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Rank      int32         `json:"rank"`
+}
+
+func (q *Queries) SearchH5PContentFolders(ctx context.Context, arg SearchH5PContentFoldersParams) ([]SearchH5PContentFoldersRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchH5PContentFolders, arg.Pattern, arg.OrgID, arg.MaxResults)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListH5POrgLibrariesRow
+	var items []SearchH5PContentFoldersRow
 	for rows.Next() {
-		var i ListH5POrgLibrariesRow
+		var i SearchH5PContentFoldersRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.CreatedAt,
-			&i.OrgID,
-			&i.LibraryID,
-			&i.Enabled,
-			&i.Restricted,
-			&i.MachineName,
-			&i.MajorVersion,
-			&i.MinorVersion,
-			&i.PatchVersion,
-			&i.Title,
-			&i.Description,
-			&i.IconPath,
-			&i.Runnable,
-			&i.Origin,
+			&i.Name,
+			&i.ParentID,
+			&i.UpdatedAt,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -1346,41 +7917,51 @@ func (q *Queries) ListH5POrgLibraries(ctx context.Context, orgID uuid.UUID) ([]L
 	return items, nil
 }
 
-const listH5PRunnableLibraries = `-- name: ListH5PRunnableLibraries :many
-SELECT id, created_at, updated_at, machine_name, major_version, minor_version, patch_version, title, origin, metadata_json, categories, keywords, screenshots, description, icon_path, package_path, extracted_path, runnable, restricted FROM h5p_libraries
-WHERE runnable = true
-ORDER BY title ASC
+const searchSeoKeywordLists = `-- name: SearchSeoKeywordLists :many
+SELECT l.id, l.name, COALESCE(l.last_refreshed_at, l.created_at)::timestamptz AS updated_at,
+    (CASE
+        WHEN l.name ILIKE $1::text THEN 0
+        WHEN l.name ILIKE $1::text || '%' THEN 1
+        WHEN l.name ILIKE '% ' || $1::text || '%' THEN 2
+        WHEN l.name ILIKE '%' || $1::text || '%' THEN 3
+        ELSE 4
+    END)::int AS rank
+FROM seo_keyword_lists l
+WHERE l.organisation_id = $2
+    AND (l.name ILIKE '%' || $1::text || '%'
+        OR EXISTS (SELECT 1 FROM seo_keywords k
+            WHERE k.list_id = l.id AND k.keyword ILIKE '%' || $1::text || '%'))
+ORDER BY rank, updated_at DESC
+LIMIT $3
 `
 
-func (q *Queries) ListH5PRunnableLibraries(ctx context.Context) ([]H5pLibrary, error) {
-	rows, err := q.db.QueryContext(ctx, listH5PRunnableLibraries)
+type SearchSeoKeywordListsParams struct {
+	Pattern    string    `json:"pattern"`
+	OrgID      uuid.UUID `json:"org_id"`
+	MaxResults int32     `json:"max_results"`
+}
+
+type SearchSeoKeywordListsRow struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Rank      int32     `json:"rank"`
+}
+
+func (q *Queries) SearchSeoKeywordLists(ctx context.Context, arg SearchSeoKeywordListsParams) ([]SearchSeoKeywordListsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchSeoKeywordLists, arg.Pattern, arg.OrgID, arg.MaxResults)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []H5pLibrary
+	var items []SearchSeoKeywordListsRow
 	for rows.Next() {
-		var i H5pLibrary
+		var i SearchSeoKeywordListsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.CreatedAt,
+			&i.Name,
 			&i.UpdatedAt,
-			&i.MachineName,
-			&i.MajorVersion,
-			&i.MinorVersion,
-			&i.PatchVersion,
-			&i.Title,
-			&i.Origin,
-			&i.MetadataJson,
-			pq.Array(&i.Categories),
-			pq.Array(&i.Keywords),
-			pq.Array(&i.Screenshots),
-			&i.Description,
-			&i.IconPath,
-			&i.PackagePath,
-			&i.ExtractedPath,
-			&i.Runnable,
-			&i.Restricted,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -1572,6 +8153,96 @@ func (q *Queries) SelectUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const setCertificateStorageKey = `-- name: SetCertificateStorageKey :exec
+UPDATE certificates SET storage_key = $2 WHERE id = $1
+`
+
+type SetCertificateStorageKeyParams struct {
+	ID         uuid.UUID      `json:"id"`
+	StorageKey sql.NullString `json:"storage_key"`
+}
+
+func (q *Queries) SetCertificateStorageKey(ctx context.Context, arg SetCertificateStorageKeyParams) error {
+	_, err := q.db.ExecContext(ctx, setCertificateStorageKey, arg.ID, arg.StorageKey)
+	return err
+}
+
+const setCertificateTemplateBackground = `-- name: SetCertificateTemplateBackground :exec
+WITH replaced AS (
+    INSERT INTO pending_deletions (storage_key, reason)
+    SELECT background_key, 'certificate'
+    FROM certificate_templates
+    WHERE organisation_id = $1 AND background_key IS NOT NULL AND background_key IS DISTINCT FROM $2
+    ON CONFLICT (storage_key) DO NOTHING
+)
+INSERT INTO certificate_templates (organisation_id, background_key)
+VALUES ($1, $2)
+ON CONFLICT (organisation_id) DO UPDATE SET background_key = EXCLUDED.background_key, updated_at = NOW()
+`
+
+type SetCertificateTemplateBackgroundParams struct {
+	OrganisationID uuid.UUID      `json:"organisation_id"`
+	BackgroundKey  sql.NullString `json:"background_key"`
+}
+
+func (q *Queries) SetCertificateTemplateBackground(ctx context.Context, arg SetCertificateTemplateBackgroundParams) error {
+	_, err := q.db.ExecContext(ctx, setCertificateTemplateBackground, arg.OrganisationID, arg.BackgroundKey)
+	return err
+}
+
+const setPaymentMethodReminderSent = `-- name: SetPaymentMethodReminderSent :exec
+UPDATE organisation_payment_methods
+SET last_reminder_days = $2
+WHERE organisation_id = $1
+`
+
+type SetPaymentMethodReminderSentParams struct {
+	OrganisationID   uuid.UUID     `json:"organisation_id"`
+	LastReminderDays sql.NullInt32 `json:"last_reminder_days"`
+}
+
+func (q *Queries) SetPaymentMethodReminderSent(ctx context.Context, arg SetPaymentMethodReminderSentParams) error {
+	_, err := q.db.ExecContext(ctx, setPaymentMethodReminderSent, arg.OrganisationID, arg.LastReminderDays)
+	return err
+}
+
+const setRankTrackerKeywordVolumes = `-- name: SetRankTrackerKeywordVolumes :exec
+UPDATE rank_tracker_keywords k
+SET search_volume = NULLIF(v.volume, -1)
+FROM (
+    SELECT unnest($2::text[]) AS keyword, unnest($3::int[]) AS volume
+) AS v
+WHERE k.tracker_id = $1 AND k.keyword = v.keyword
+`
+
+type SetRankTrackerKeywordVolumesParams struct {
+	TrackerID uuid.UUID `json:"tracker_id"`
+	Keywords  []string  `json:"keywords"`
+	Volumes   []int32   `json:"volumes"`
+}
+
+func (q *Queries) SetRankTrackerKeywordVolumes(ctx context.Context, arg SetRankTrackerKeywordVolumesParams) error {
+	_, err := q.db.ExecContext(ctx, setRankTrackerKeywordVolumes, arg.TrackerID, pq.Array(arg.Keywords), pq.Array(arg.Volumes))
+	return err
+}
+
+const setSeoScheduleRunTargets = `-- name: SetSeoScheduleRunTargets :exec
+UPDATE seo_schedule_runs
+SET audit_id = $2, rank_check_id = $3
+WHERE id = $1
+`
+
+type SetSeoScheduleRunTargetsParams struct {
+	ID          uuid.UUID     `json:"id"`
+	AuditID     uuid.NullUUID `json:"audit_id"`
+	RankCheckID uuid.NullUUID `json:"rank_check_id"`
+}
+
+func (q *Queries) SetSeoScheduleRunTargets(ctx context.Context, arg SetSeoScheduleRunTargetsParams) error {
+	_, err := q.db.ExecContext(ctx, setSeoScheduleRunTargets, arg.ID, arg.AuditID, arg.RankCheckID)
+	return err
+}
+
 const softDeleteH5PContent = `-- name: SoftDeleteH5PContent :exec
 UPDATE h5p_content SET deleted_at = current_timestamp
 WHERE id = $1 AND org_id = $2
@@ -1587,10 +8258,93 @@ func (q *Queries) SoftDeleteH5PContent(ctx context.Context, arg SoftDeleteH5PCon
 	return err
 }
 
+const touchH5PMediaAsset = `-- name: TouchH5PMediaAsset :exec
+UPDATE h5p_media_assets
+SET use_count = use_count + 1, last_used_at = NOW()
+WHERE id = $1 AND org_id = $2
+`
+
+type TouchH5PMediaAssetParams struct {
+	ID    uuid.UUID `json:"id"`
+	OrgID uuid.UUID `json:"org_id"`
+}
+
+func (q *Queries) TouchH5PMediaAsset(ctx context.Context, arg TouchH5PMediaAssetParams) error {
+	_, err := q.db.ExecContext(ctx, touchH5PMediaAsset, arg.ID, arg.OrgID)
+	return err
+}
+
+const unlockOrganisationContent = `-- name: UnlockOrganisationContent :exec
+UPDATE h5p_content SET locked_at = NULL, lock_reason = NULL
+WHERE org_id = $1 AND locked_at IS NOT NULL
+`
+
+func (q *Queries) UnlockOrganisationContent(ctx context.Context, orgID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, unlockOrganisationContent, orgID)
+	return err
+}
+
+const unlockOrganisationCourses = `-- name: UnlockOrganisationCourses :exec
+UPDATE courses SET locked_at = NULL, lock_reason = NULL
+WHERE org_id = $1 AND locked_at IS NOT NULL
+`
+
+func (q *Queries) UnlockOrganisationCourses(ctx context.Context, orgID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, unlockOrganisationCourses, orgID)
+	return err
+}
+
+const updateAnnouncement = `-- name: UpdateAnnouncement :one
+UPDATE announcements
+SET title = $2, body = $3, link_url = $4, tiers = $5, flags = $6,
+    published_at = $7, expires_at = $8, updated_at = now()
+WHERE id = $1
+RETURNING id, created_at, updated_at, title, body, link_url, tiers, flags, published_at, expires_at, created_by
+`
+
+type UpdateAnnouncementParams struct {
+	ID          uuid.UUID    `json:"id"`
+	Title       string       `json:"title"`
+	Body        string       `json:"body"`
+	LinkUrl     string       `json:"link_url"`
+	Tiers       []string     `json:"tiers"`
+	Flags       []string     `json:"flags"`
+	PublishedAt sql.NullTime `json:"published_at"`
+	ExpiresAt   sql.NullTime `json:"expires_at"`
+}
+
+func (q *Queries) UpdateAnnouncement(ctx context.Context, arg UpdateAnnouncementParams) (Announcement, error) {
+	row := q.db.QueryRowContext(ctx, updateAnnouncement,
+		arg.ID,
+		arg.Title,
+		arg.Body,
+		arg.LinkUrl,
+		pq.Array(arg.Tiers),
+		pq.Array(arg.Flags),
+		arg.PublishedAt,
+		arg.ExpiresAt,
+	)
+	var i Announcement
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Body,
+		&i.LinkUrl,
+		pq.Array(&i.Tiers),
+		pq.Array(&i.Flags),
+		&i.PublishedAt,
+		&i.ExpiresAt,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
 const updateH5PContent = `-- name: UpdateH5PContent :one
 UPDATE h5p_content SET title = $3, description = $4, content_json = $5,
     tags = $6, status = $7, updated_at = current_timestamp
-WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL RETURNING id, created_at, updated_at, org_id, library_id, created_by, title, slug, description, content_json, tags, folder_path, storage_path, status, deleted_at
+WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL RETURNING id, created_at, updated_at, org_id, library_id, created_by, title, slug, description, content_json, tags, folder_path, storage_path, status, deleted_at, locked_at, lock_reason
 `
 
 type UpdateH5PContentParams struct {
@@ -1630,6 +8384,45 @@ func (q *Queries) UpdateH5PContent(ctx context.Context, arg UpdateH5PContentPara
 		&i.StoragePath,
 		&i.Status,
 		&i.DeletedAt,
+		&i.LockedAt,
+		&i.LockReason,
+	)
+	return i, err
+}
+
+const updateH5PInstallProfile = `-- name: UpdateH5PInstallProfile :one
+UPDATE h5p_install_profiles
+SET name = $2, description = $3, libraries = $4, is_default = $5, updated_at = now()
+WHERE id = $1
+RETURNING id, name, description, libraries, is_default, created_by, created_at, updated_at
+`
+
+type UpdateH5PInstallProfileParams struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Libraries   []string  `json:"libraries"`
+	IsDefault   bool      `json:"is_default"`
+}
+
+func (q *Queries) UpdateH5PInstallProfile(ctx context.Context, arg UpdateH5PInstallProfileParams) (H5pInstallProfile, error) {
+	row := q.db.QueryRowContext(ctx, updateH5PInstallProfile,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		pq.Array(arg.Libraries),
+		arg.IsDefault,
+	)
+	var i H5pInstallProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		pq.Array(&i.Libraries),
+		&i.IsDefault,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -1691,6 +8484,141 @@ func (q *Queries) UpdateOrganisationSubscription(ctx context.Context, arg Update
 	return err
 }
 
+const updateOrganisationWebhook = `-- name: UpdateOrganisationWebhook :one
+UPDATE organisation_webhooks
+SET url = $3, events = $4, active = $5, updated_at = now()
+WHERE id = $1 AND organisation_id = $2
+RETURNING id, created_at, updated_at, organisation_id, url, secret, events, active, last_delivery_at, last_delivery_status
+`
+
+type UpdateOrganisationWebhookParams struct {
+	ID             uuid.UUID `json:"id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Url            string    `json:"url"`
+	Events         []string  `json:"events"`
+	Active         bool      `json:"active"`
+}
+
+func (q *Queries) UpdateOrganisationWebhook(ctx context.Context, arg UpdateOrganisationWebhookParams) (OrganisationWebhook, error) {
+	row := q.db.QueryRowContext(ctx, updateOrganisationWebhook,
+		arg.ID,
+		arg.OrganisationID,
+		arg.Url,
+		pq.Array(arg.Events),
+		arg.Active,
+	)
+	var i OrganisationWebhook
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganisationID,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.Active,
+		&i.LastDeliveryAt,
+		&i.LastDeliveryStatus,
+	)
+	return i, err
+}
+
+const updateOrganisationWebhookDelivery = `-- name: UpdateOrganisationWebhookDelivery :exec
+UPDATE organisation_webhooks
+SET last_delivery_at = now(), last_delivery_status = $2, updated_at = now()
+WHERE id = $1
+`
+
+type UpdateOrganisationWebhookDeliveryParams struct {
+	ID                 uuid.UUID     `json:"id"`
+	LastDeliveryStatus sql.NullInt32 `json:"last_delivery_status"`
+}
+
+func (q *Queries) UpdateOrganisationWebhookDelivery(ctx context.Context, arg UpdateOrganisationWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, updateOrganisationWebhookDelivery, arg.ID, arg.LastDeliveryStatus)
+	return err
+}
+
+const updateSeoBacklinkProspect = `-- name: UpdateSeoBacklinkProspect :one
+UPDATE seo_backlink_prospects
+SET status = COALESCE($1::text, status),
+    contact = COALESCE($2::text, contact),
+    notes = COALESCE($3::text, notes),
+    updated_at = now(),
+    contacted_at = CASE WHEN COALESCE($1::text, status) IN ('contacted', 'linked') THEN COALESCE(contacted_at, now()) END,
+    linked_at = CASE WHEN COALESCE($1::text, status) = 'linked' THEN COALESCE(linked_at, now()) END
+WHERE id = $4 AND organisation_id = $5
+RETURNING id, organisation_id, target, domain, status, source, competitors, contact, notes, rank, backlinks, referring_domains, spam_score, enriched_at, created_by, created_at, updated_at, contacted_at, linked_at
+`
+
+type UpdateSeoBacklinkProspectParams struct {
+	Status         sql.NullString `json:"status"`
+	Contact        sql.NullString `json:"contact"`
+	Notes          sql.NullString `json:"notes"`
+	ID             uuid.UUID      `json:"id"`
+	OrganisationID uuid.UUID      `json:"organisation_id"`
+}
+
+func (q *Queries) UpdateSeoBacklinkProspect(ctx context.Context, arg UpdateSeoBacklinkProspectParams) (SeoBacklinkProspect, error) {
+	row := q.db.QueryRowContext(ctx, updateSeoBacklinkProspect,
+		arg.Status,
+		arg.Contact,
+		arg.Notes,
+		arg.ID,
+		arg.OrganisationID,
+	)
+	var i SeoBacklinkProspect
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Target,
+		&i.Domain,
+		&i.Status,
+		&i.Source,
+		pq.Array(&i.Competitors),
+		&i.Contact,
+		&i.Notes,
+		&i.Rank,
+		&i.Backlinks,
+		&i.ReferringDomains,
+		&i.SpamScore,
+		&i.EnrichedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ContactedAt,
+		&i.LinkedAt,
+	)
+	return i, err
+}
+
+const updateSeoKeywordVolume = `-- name: UpdateSeoKeywordVolume :exec
+UPDATE seo_keywords
+SET search_volume = $3, cpc = $4, competition = $5, monthly_searches = $6, refreshed_at = now()
+WHERE list_id = $1 AND keyword = $2
+`
+
+type UpdateSeoKeywordVolumeParams struct {
+	ListID          uuid.UUID       `json:"list_id"`
+	Keyword         string          `json:"keyword"`
+	SearchVolume    sql.NullInt32   `json:"search_volume"`
+	Cpc             sql.NullFloat64 `json:"cpc"`
+	Competition     string          `json:"competition"`
+	MonthlySearches json.RawMessage `json:"monthly_searches"`
+}
+
+func (q *Queries) UpdateSeoKeywordVolume(ctx context.Context, arg UpdateSeoKeywordVolumeParams) error {
+	_, err := q.db.ExecContext(ctx, updateSeoKeywordVolume,
+		arg.ListID,
+		arg.Keyword,
+		arg.SearchVolume,
+		arg.Cpc,
+		arg.Competition,
+		arg.MonthlySearches,
+	)
+	return err
+}
+
 const updateToken = `-- name: UpdateToken :exec
 update tokens set expires = $1 where id = $2 returning id, expires, target, callback
 `
@@ -1867,6 +8795,233 @@ func (q *Queries) UpdateUserSubscription(ctx context.Context, arg UpdateUserSubs
 	return err
 }
 
+const upgradeH5PContentLibrary = `-- name: UpgradeH5PContentLibrary :execrows
+UPDATE h5p_content SET library_id = $3, content_json = $4, updated_at = current_timestamp
+WHERE id = $1 AND org_id = $2 AND library_id = $5 AND deleted_at IS NULL AND locked_at IS NULL
+`
+
+type UpgradeH5PContentLibraryParams struct {
+	ID          uuid.UUID       `json:"id"`
+	OrgID       uuid.UUID       `json:"org_id"`
+	LibraryID   uuid.UUID       `json:"library_id"`
+	ContentJson json.RawMessage `json:"content_json"`
+	LibraryID_2 uuid.UUID       `json:"library_id_2"`
+}
+
+func (q *Queries) UpgradeH5PContentLibrary(ctx context.Context, arg UpgradeH5PContentLibraryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, upgradeH5PContentLibrary,
+		arg.ID,
+		arg.OrgID,
+		arg.LibraryID,
+		arg.ContentJson,
+		arg.LibraryID_2,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertBillingContact = `-- name: UpsertBillingContact :one
+INSERT INTO organisation_billing_contacts (
+    organisation_id, name, email, po_number, address_line1, address_line2,
+    city, state, postal_code, country, invoice_memo, invoice_custom_fields
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (organisation_id) DO UPDATE SET
+    name = EXCLUDED.name,
+    email = EXCLUDED.email,
+    po_number = EXCLUDED.po_number,
+    address_line1 = EXCLUDED.address_line1,
+    address_line2 = EXCLUDED.address_line2,
+    city = EXCLUDED.city,
+    state = EXCLUDED.state,
+    postal_code = EXCLUDED.postal_code,
+    country = EXCLUDED.country,
+    invoice_memo = EXCLUDED.invoice_memo,
+    invoice_custom_fields = EXCLUDED.invoice_custom_fields,
+    updated_at = now()
+RETURNING organisation_id, name, email, po_number, address_line1, address_line2, city, state, postal_code, country, invoice_memo, invoice_custom_fields, updated_at
+`
+
+type UpsertBillingContactParams struct {
+	OrganisationID      uuid.UUID       `json:"organisation_id"`
+	Name                string          `json:"name"`
+	Email               string          `json:"email"`
+	PoNumber            string          `json:"po_number"`
+	AddressLine1        string          `json:"address_line1"`
+	AddressLine2        string          `json:"address_line2"`
+	City                string          `json:"city"`
+	State               string          `json:"state"`
+	PostalCode          string          `json:"postal_code"`
+	Country             string          `json:"country"`
+	InvoiceMemo         string          `json:"invoice_memo"`
+	InvoiceCustomFields json.RawMessage `json:"invoice_custom_fields"`
+}
+
+func (q *Queries) UpsertBillingContact(ctx context.Context, arg UpsertBillingContactParams) (OrganisationBillingContact, error) {
+	row := q.db.QueryRowContext(ctx, upsertBillingContact,
+		arg.OrganisationID,
+		arg.Name,
+		arg.Email,
+		arg.PoNumber,
+		arg.AddressLine1,
+		arg.AddressLine2,
+		arg.City,
+		arg.State,
+		arg.PostalCode,
+		arg.Country,
+		arg.InvoiceMemo,
+		arg.InvoiceCustomFields,
+	)
+	var i OrganisationBillingContact
+	err := row.Scan(
+		&i.OrganisationID,
+		&i.Name,
+		&i.Email,
+		&i.PoNumber,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.City,
+		&i.State,
+		&i.PostalCode,
+		&i.Country,
+		&i.InvoiceMemo,
+		&i.InvoiceCustomFields,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertCertificateTemplate = `-- name: UpsertCertificateTemplate :one
+INSERT INTO certificate_templates (organisation_id, heading, fields, signatory_name, signatory_title)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (organisation_id) DO UPDATE SET
+    heading = EXCLUDED.heading,
+    fields = EXCLUDED.fields,
+    signatory_name = EXCLUDED.signatory_name,
+    signatory_title = EXCLUDED.signatory_title,
+    updated_at = NOW()
+RETURNING organisation_id, heading, fields, signatory_name, signatory_title, background_key, updated_at
+`
+
+type UpsertCertificateTemplateParams struct {
+	OrganisationID uuid.UUID       `json:"organisation_id"`
+	Heading        string          `json:"heading"`
+	Fields         json.RawMessage `json:"fields"`
+	SignatoryName  string          `json:"signatory_name"`
+	SignatoryTitle string          `json:"signatory_title"`
+}
+
+func (q *Queries) UpsertCertificateTemplate(ctx context.Context, arg UpsertCertificateTemplateParams) (CertificateTemplate, error) {
+	row := q.db.QueryRowContext(ctx, upsertCertificateTemplate,
+		arg.OrganisationID,
+		arg.Heading,
+		arg.Fields,
+		arg.SignatoryName,
+		arg.SignatoryTitle,
+	)
+	var i CertificateTemplate
+	err := row.Scan(
+		&i.OrganisationID,
+		&i.Heading,
+		&i.Fields,
+		&i.SignatoryName,
+		&i.SignatoryTitle,
+		&i.BackgroundKey,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertCompetitorKeywordGaps = `-- name: UpsertCompetitorKeywordGaps :many
+INSERT INTO competitor_keyword_gaps (pin_id, keyword, search_volume, position, url, is_new)
+SELECT $1::uuid, g.keyword, NULLIF(g.volume, -1), g.position, g.url, $2::boolean
+FROM (
+    SELECT unnest($3::text[]) AS keyword, unnest($4::int[]) AS volume,
+        unnest($5::int[]) AS position, unnest($6::text[]) AS url
+) AS g
+ON CONFLICT (pin_id, keyword) DO UPDATE
+SET search_volume = EXCLUDED.search_volume, position = EXCLUDED.position, url = EXCLUDED.url, is_new = false, last_seen_at = now()
+RETURNING keyword, is_new
+`
+
+type UpsertCompetitorKeywordGapsParams struct {
+	PinID     uuid.UUID `json:"pin_id"`
+	IsNew     bool      `json:"is_new"`
+	Keywords  []string  `json:"keywords"`
+	Volumes   []int32   `json:"volumes"`
+	Positions []int32   `json:"positions"`
+	Urls      []string  `json:"urls"`
+}
+
+type UpsertCompetitorKeywordGapsRow struct {
+	Keyword string `json:"keyword"`
+	IsNew   bool   `json:"is_new"`
+}
+
+func (q *Queries) UpsertCompetitorKeywordGaps(ctx context.Context, arg UpsertCompetitorKeywordGapsParams) ([]UpsertCompetitorKeywordGapsRow, error) {
+	rows, err := q.db.QueryContext(ctx, upsertCompetitorKeywordGaps,
+		arg.PinID,
+		arg.IsNew,
+		pq.Array(arg.Keywords),
+		pq.Array(arg.Volumes),
+		pq.Array(arg.Positions),
+		pq.Array(arg.Urls),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UpsertCompetitorKeywordGapsRow
+	for rows.Next() {
+		var i UpsertCompetitorKeywordGapsRow
+		if err := rows.Scan(&i.Keyword, &i.IsNew); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertContentExtractCache = `-- name: UpsertContentExtractCache :exec
+INSERT INTO content_extract_cache (url, final_url, title, markdown, links, provider, fetched_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, now(), $7)
+ON CONFLICT (url) DO UPDATE
+SET final_url = EXCLUDED.final_url, title = EXCLUDED.title, markdown = EXCLUDED.markdown,
+    links = EXCLUDED.links, provider = EXCLUDED.provider,
+    fetched_at = EXCLUDED.fetched_at, expires_at = EXCLUDED.expires_at
+`
+
+type UpsertContentExtractCacheParams struct {
+	Url       string          `json:"url"`
+	FinalUrl  string          `json:"final_url"`
+	Title     string          `json:"title"`
+	Markdown  string          `json:"markdown"`
+	Links     json.RawMessage `json:"links"`
+	Provider  string          `json:"provider"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+func (q *Queries) UpsertContentExtractCache(ctx context.Context, arg UpsertContentExtractCacheParams) error {
+	_, err := q.db.ExecContext(ctx, upsertContentExtractCache,
+		arg.Url,
+		arg.FinalUrl,
+		arg.Title,
+		arg.Markdown,
+		arg.Links,
+		arg.Provider,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
 const upsertContentUserState = `-- name: UpsertContentUserState :one
 INSERT INTO h5p_content_user_state (user_id, content_id, sub_content_id, data_type, data, preload, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, now())
@@ -1907,6 +9062,44 @@ func (q *Queries) UpsertContentUserState(ctx context.Context, arg UpsertContentU
 	return i, err
 }
 
+const upsertH5PBundleVariant = `-- name: UpsertH5PBundleVariant :one
+INSERT INTO h5p_bundle_variants (name, description, asset_base, rollout_percent, created_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (name) DO UPDATE
+SET description = EXCLUDED.description, asset_base = EXCLUDED.asset_base,
+    rollout_percent = EXCLUDED.rollout_percent, updated_at = now()
+RETURNING name, description, asset_base, rollout_percent, created_by, created_at, updated_at
+`
+
+type UpsertH5PBundleVariantParams struct {
+	Name           string        `json:"name"`
+	Description    string        `json:"description"`
+	AssetBase      string        `json:"asset_base"`
+	RolloutPercent int32         `json:"rollout_percent"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+}
+
+func (q *Queries) UpsertH5PBundleVariant(ctx context.Context, arg UpsertH5PBundleVariantParams) (H5pBundleVariant, error) {
+	row := q.db.QueryRowContext(ctx, upsertH5PBundleVariant,
+		arg.Name,
+		arg.Description,
+		arg.AssetBase,
+		arg.RolloutPercent,
+		arg.CreatedBy,
+	)
+	var i H5pBundleVariant
+	err := row.Scan(
+		&i.Name,
+		&i.Description,
+		&i.AssetBase,
+		&i.RolloutPercent,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const upsertH5PHubCache = `-- name: UpsertH5PHubCache :one
 INSERT INTO h5p_hub_cache (id, cache_key, data, expires_at)
 VALUES ($1, $2, $3, $4)
@@ -2038,6 +9231,98 @@ func (q *Queries) UpsertH5PLibrary(ctx context.Context, arg UpsertH5PLibraryPara
 	return i, err
 }
 
+const upsertH5PLibrarySemanticsCache = `-- name: UpsertH5PLibrarySemanticsCache :exec
+INSERT INTO h5p_library_semantics_cache (library_id, semantics)
+VALUES ($1, $2)
+ON CONFLICT (library_id)
+DO UPDATE SET
+    semantics = EXCLUDED.semantics,
+    created_at = CURRENT_TIMESTAMP
+`
+
+type UpsertH5PLibrarySemanticsCacheParams struct {
+	LibraryID uuid.UUID       `json:"library_id"`
+	Semantics json.RawMessage `json:"semantics"`
+}
+
+func (q *Queries) UpsertH5PLibrarySemanticsCache(ctx context.Context, arg UpsertH5PLibrarySemanticsCacheParams) error {
+	_, err := q.db.ExecContext(ctx, upsertH5PLibrarySemanticsCache, arg.LibraryID, arg.Semantics)
+	return err
+}
+
+const upsertNotificationPreference = `-- name: UpsertNotificationPreference :exec
+INSERT INTO notification_preferences (user_id, organisation_id, category, channel, enabled, digest)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, organisation_id, category, channel) DO UPDATE
+SET enabled = EXCLUDED.enabled, digest = EXCLUDED.digest, updated_at = NOW()
+`
+
+type UpsertNotificationPreferenceParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	Category       string    `json:"category"`
+	Channel        string    `json:"channel"`
+	Enabled        bool      `json:"enabled"`
+	Digest         bool      `json:"digest"`
+}
+
+func (q *Queries) UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) error {
+	_, err := q.db.ExecContext(ctx, upsertNotificationPreference,
+		arg.UserID,
+		arg.OrganisationID,
+		arg.Category,
+		arg.Channel,
+		arg.Enabled,
+		arg.Digest,
+	)
+	return err
+}
+
+const upsertOrganisationPaymentMethod = `-- name: UpsertOrganisationPaymentMethod :exec
+
+INSERT INTO organisation_payment_methods (organisation_id, payment_method_id, brand, last4, exp_month, exp_year, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (organisation_id) DO UPDATE SET
+    payment_method_id = EXCLUDED.payment_method_id,
+    brand = EXCLUDED.brand,
+    last4 = EXCLUDED.last4,
+    exp_month = EXCLUDED.exp_month,
+    exp_year = EXCLUDED.exp_year,
+    expires_at = EXCLUDED.expires_at,
+    last_reminder_days = CASE
+        WHEN organisation_payment_methods.payment_method_id = EXCLUDED.payment_method_id
+            AND organisation_payment_methods.expires_at = EXCLUDED.expires_at
+        THEN organisation_payment_methods.last_reminder_days
+    END,
+    updated_at = now()
+`
+
+type UpsertOrganisationPaymentMethodParams struct {
+	OrganisationID  uuid.UUID `json:"organisation_id"`
+	PaymentMethodID string    `json:"payment_method_id"`
+	Brand           string    `json:"brand"`
+	Last4           string    `json:"last4"`
+	ExpMonth        int32     `json:"exp_month"`
+	ExpYear         int32     `json:"exp_year"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+// =============================================================================
+// Organisation Payment Methods (Card Expiry Reminders)
+// =============================================================================
+func (q *Queries) UpsertOrganisationPaymentMethod(ctx context.Context, arg UpsertOrganisationPaymentMethodParams) error {
+	_, err := q.db.ExecContext(ctx, upsertOrganisationPaymentMethod,
+		arg.OrganisationID,
+		arg.PaymentMethodID,
+		arg.Brand,
+		arg.Last4,
+		arg.ExpMonth,
+		arg.ExpYear,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
 const upsertProgressRecord = `-- name: UpsertProgressRecord :exec
 INSERT INTO progress_records (org_id, enrolment_id, content_id, user_id, score, max_score, completion, completed, attempts, time_spent)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 1, $9)
@@ -2056,7 +9341,7 @@ type UpsertProgressRecordParams struct {
 	OrgID       uuid.UUID      `json:"org_id"`
 	EnrolmentID uuid.UUID      `json:"enrolment_id"`
 	ContentID   uuid.UUID      `json:"content_id"`
-	UserID      uuid.UUID      `json:"user_id"`
+	UserID      uuid.NullUUID  `json:"user_id"`
 	Score       sql.NullString `json:"score"`
 	MaxScore    sql.NullString `json:"max_score"`
 	Completion  string         `json:"completion"`
@@ -2078,3 +9363,125 @@ func (q *Queries) UpsertProgressRecord(ctx context.Context, arg UpsertProgressRe
 	)
 	return err
 }
+
+const upsertRetentionPolicy = `-- name: UpsertRetentionPolicy :one
+INSERT INTO organisation_retention_policies (organisation_id, archive_drafts_after_months, purge_deleted_after_days, retain_xapi_years, updated_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (organisation_id) DO UPDATE
+SET archive_drafts_after_months = EXCLUDED.archive_drafts_after_months,
+    purge_deleted_after_days = EXCLUDED.purge_deleted_after_days,
+    retain_xapi_years = EXCLUDED.retain_xapi_years,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING organisation_id, archive_drafts_after_months, purge_deleted_after_days, retain_xapi_years, updated_by, created_at, updated_at, evaluated_at
+`
+
+type UpsertRetentionPolicyParams struct {
+	OrganisationID           uuid.UUID     `json:"organisation_id"`
+	ArchiveDraftsAfterMonths sql.NullInt32 `json:"archive_drafts_after_months"`
+	PurgeDeletedAfterDays    sql.NullInt32 `json:"purge_deleted_after_days"`
+	RetainXapiYears          sql.NullInt32 `json:"retain_xapi_years"`
+	UpdatedBy                uuid.NullUUID `json:"updated_by"`
+}
+
+func (q *Queries) UpsertRetentionPolicy(ctx context.Context, arg UpsertRetentionPolicyParams) (OrganisationRetentionPolicy, error) {
+	row := q.db.QueryRowContext(ctx, upsertRetentionPolicy,
+		arg.OrganisationID,
+		arg.ArchiveDraftsAfterMonths,
+		arg.PurgeDeletedAfterDays,
+		arg.RetainXapiYears,
+		arg.UpdatedBy,
+	)
+	var i OrganisationRetentionPolicy
+	err := row.Scan(
+		&i.OrganisationID,
+		&i.ArchiveDraftsAfterMonths,
+		&i.PurgeDeletedAfterDays,
+		&i.RetainXapiYears,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EvaluatedAt,
+	)
+	return i, err
+}
+
+const upsertSeoRawArtifact = `-- name: UpsertSeoRawArtifact :exec
+INSERT INTO seo_raw_artifacts (page_id, section, object_key, size_bytes)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (page_id, section) DO UPDATE
+SET object_key = EXCLUDED.object_key, size_bytes = EXCLUDED.size_bytes, created_at = now()
+`
+
+type UpsertSeoRawArtifactParams struct {
+	PageID    uuid.UUID `json:"page_id"`
+	Section   string    `json:"section"`
+	ObjectKey string    `json:"object_key"`
+	SizeBytes int64     `json:"size_bytes"`
+}
+
+func (q *Queries) UpsertSeoRawArtifact(ctx context.Context, arg UpsertSeoRawArtifactParams) error {
+	_, err := q.db.ExecContext(ctx, upsertSeoRawArtifact,
+		arg.PageID,
+		arg.Section,
+		arg.ObjectKey,
+		arg.SizeBytes,
+	)
+	return err
+}
+
+const upsertSeoSchedule = `-- name: UpsertSeoSchedule :one
+INSERT INTO seo_schedules (organisation_id, domain, pages, keywords, location_code, language_code, device, strategy, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (organisation_id, domain) DO UPDATE
+SET pages = EXCLUDED.pages,
+    keywords = EXCLUDED.keywords,
+    location_code = EXCLUDED.location_code,
+    language_code = EXCLUDED.language_code,
+    device = EXCLUDED.device,
+    strategy = EXCLUDED.strategy
+RETURNING id, organisation_id, domain, pages, keywords, location_code, language_code, device, strategy, created_by, created_at, last_run_at, next_run_at
+`
+
+type UpsertSeoScheduleParams struct {
+	OrganisationID uuid.UUID     `json:"organisation_id"`
+	Domain         string        `json:"domain"`
+	Pages          []string      `json:"pages"`
+	Keywords       []string      `json:"keywords"`
+	LocationCode   int32         `json:"location_code"`
+	LanguageCode   string        `json:"language_code"`
+	Device         string        `json:"device"`
+	Strategy       string        `json:"strategy"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+}
+
+func (q *Queries) UpsertSeoSchedule(ctx context.Context, arg UpsertSeoScheduleParams) (SeoSchedule, error) {
+	row := q.db.QueryRowContext(ctx, upsertSeoSchedule,
+		arg.OrganisationID,
+		arg.Domain,
+		pq.Array(arg.Pages),
+		pq.Array(arg.Keywords),
+		arg.LocationCode,
+		arg.LanguageCode,
+		arg.Device,
+		arg.Strategy,
+		arg.CreatedBy,
+	)
+	var i SeoSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Domain,
+		pq.Array(&i.Pages),
+		pq.Array(&i.Keywords),
+		&i.LocationCode,
+		&i.LanguageCode,
+		&i.Device,
+		&i.Strategy,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LastRunAt,
+		&i.NextRunAt,
+	)
+	return i, err
+}
@@ -77,10 +77,16 @@ SELECT
     ai_generations_this_month,
     ai_generations_reset_at,
     is_freemium,
-    freemium_expires_at
+    freemium_expires_at,
+    locale,
+    timezone
 FROM organisations
 WHERE id = $1;
 
+-- name: GetOrganisationLocale :one
+SELECT locale, timezone FROM organisations
+WHERE id = $1;
+
 -- name: UpdateOrganisationStripeCustomer :exec
 UPDATE organisations
 SET stripe_customer_id = $2, updated_at = CURRENT_TIMESTAMP
@@ -181,7 +187,16 @@ UPDATE h5p_libraries SET metadata_json = $2, updated_at = CURRENT_TIMESTAMP WHER
 DELETE FROM h5p_libraries WHERE id = $1;
 
 -- name: DeleteH5PLibraryByMachineName :exec
-DELETE FROM h5p_libraries WHERE machine_name = $1;
+WITH deleted AS (
+    DELETE FROM h5p_libraries WHERE machine_name = $1
+    RETURNING machine_name, major_version, minor_version, patch_version, package_path
+)
+INSERT INTO pending_deletions (storage_key, reason)
+SELECT 'h5p-libraries/extracted/' || machine_name || '-' || major_version || '.' || minor_version || '.' || patch_version || '/', 'library'
+FROM deleted
+UNION ALL
+SELECT package_path, 'library' FROM deleted WHERE package_path IS NOT NULL
+ON CONFLICT (storage_key) DO NOTHING;
 
 -- name: CountH5PLibraries :one
 SELECT count(*) FROM h5p_libraries;
@@ -259,6 +274,37 @@ FROM dep_max_depth dmd
 JOIN h5p_libraries l ON l.id = dmd.library_id
 ORDER BY dmd.max_depth DESC;
 
+-- name: GetH5PLibraryExportTree :many
+-- Returns the given libraries and everything they depend on, transitively and
+-- over every dependency type, as an exported .h5p package carries them.
+WITH RECURSIVE lib_tree AS (
+    SELECT unnest(sqlc.arg(library_ids)::uuid[]) AS library_id, 0 AS depth
+    UNION
+    SELECT d.depends_on_id, lt.depth + 1
+    FROM h5p_library_dependencies d
+    JOIN lib_tree lt ON lt.library_id = d.library_id
+    WHERE lt.depth < 20
+)
+SELECT l.*
+FROM h5p_libraries l
+WHERE l.id IN (SELECT library_id FROM lib_tree)
+ORDER BY l.machine_name, l.major_version, l.minor_version;
+
+-- =============================================================================
+-- H5P Library Semantics Cache
+-- =============================================================================
+
+-- name: GetH5PLibrarySemanticsCache :one
+SELECT * FROM h5p_library_semantics_cache WHERE library_id = $1;
+
+-- name: UpsertH5PLibrarySemanticsCache :exec
+INSERT INTO h5p_library_semantics_cache (library_id, semantics)
+VALUES ($1, $2)
+ON CONFLICT (library_id)
+DO UPDATE SET
+    semantics = EXCLUDED.semantics,
+    created_at = CURRENT_TIMESTAMP;
+
 -- =============================================================================
 -- H5P Hub Cache
 -- =============================================================================
@@ -306,13 +352,103 @@ VALUES ($1, $2, $3, true)
 ON CONFLICT (org_id, library_id)
 DO UPDATE SET enabled = true;
 
+-- name: EnableH5POrgLibraryVersions :exec
+UPDATE h5p_org_libraries ol SET enabled = true
+FROM h5p_libraries l, h5p_libraries target
+WHERE ol.org_id = $1 AND ol.library_id = l.id
+  AND target.id = $2 AND l.machine_name = target.machine_name;
+
 -- name: DisableH5POrgLibrary :exec
-UPDATE h5p_org_libraries SET enabled = false
-WHERE org_id = $1 AND library_id = $2;
+INSERT INTO h5p_org_libraries (id, org_id, library_id, enabled)
+VALUES ($1, $2, $3, false)
+ON CONFLICT (org_id, library_id)
+DO UPDATE SET enabled = false;
 
 -- name: DeleteH5POrgLibrary :exec
 DELETE FROM h5p_org_libraries WHERE org_id = $1 AND library_id = $2;
 
+-- =============================================================================
+-- H5P Install Profiles (Library bundles applied to organisations)
+-- =============================================================================
+
+-- name: ListH5PInstallProfiles :many
+SELECT * FROM h5p_install_profiles ORDER BY name;
+
+-- name: GetH5PInstallProfile :one
+SELECT * FROM h5p_install_profiles WHERE id = $1;
+
+-- name: GetDefaultH5PInstallProfile :one
+SELECT * FROM h5p_install_profiles WHERE is_default;
+
+-- name: CreateH5PInstallProfile :one
+INSERT INTO h5p_install_profiles (name, description, libraries, is_default, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpdateH5PInstallProfile :one
+UPDATE h5p_install_profiles
+SET name = $2, description = $3, libraries = $4, is_default = $5, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ClearDefaultH5PInstallProfile :exec
+UPDATE h5p_install_profiles SET is_default = false, updated_at = now()
+WHERE is_default AND id <> $1;
+
+-- name: DeleteH5PInstallProfile :execrows
+DELETE FROM h5p_install_profiles WHERE id = $1;
+
+-- name: CreateH5PProfileApplication :one
+INSERT INTO h5p_profile_applications (organisation_id, profile_id, profile_name, libraries, requested_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetH5PProfileApplication :one
+SELECT * FROM h5p_profile_applications WHERE id = $1;
+
+-- name: ListH5PProfileApplications :many
+SELECT * FROM h5p_profile_applications
+WHERE organisation_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: FinishH5PProfileApplication :exec
+UPDATE h5p_profile_applications
+SET status = $2, summary = $3, completed_at = now()
+WHERE id = $1;
+
+-- =============================================================================
+-- H5P Editor Bundle Variants (Percentage rollout of editor assets)
+-- =============================================================================
+
+-- name: ListH5PBundleVariants :many
+SELECT * FROM h5p_bundle_variants ORDER BY created_at, name;
+
+-- name: UpsertH5PBundleVariant :one
+INSERT INTO h5p_bundle_variants (name, description, asset_base, rollout_percent, created_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (name) DO UPDATE
+SET description = EXCLUDED.description, asset_base = EXCLUDED.asset_base,
+    rollout_percent = EXCLUDED.rollout_percent, updated_at = now()
+RETURNING *;
+
+-- name: DeleteH5PBundleVariant :execrows
+DELETE FROM h5p_bundle_variants WHERE name = $1;
+
+-- name: RecordH5PBundleReport :exec
+INSERT INTO h5p_bundle_reports (variant, day, loads, errors)
+VALUES ($1, CURRENT_DATE, $2, $3)
+ON CONFLICT (variant, day) DO UPDATE
+SET loads = h5p_bundle_reports.loads + EXCLUDED.loads,
+    errors = h5p_bundle_reports.errors + EXCLUDED.errors;
+
+-- name: ListH5PBundleReportTotals :many
+SELECT variant, SUM(loads)::bigint AS loads, SUM(errors)::bigint AS errors
+FROM h5p_bundle_reports
+WHERE day >= $1
+GROUP BY variant
+ORDER BY variant;
+
 -- =============================================================================
 -- H5P Content (Organisation-scoped)
 -- =============================================================================
@@ -355,6 +491,66 @@ INSERT INTO xapi_statements (org_id, user_id, content_id, verb, statement)
 VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
+-- name: RecordXapiStatement :execrows
+INSERT INTO xapi_statements (
+    org_id, user_id, content_id, verb, statement, statement_id, verb_id, object_id,
+    result_score_raw, result_score_max, result_score_scaled, result_success, result_completion, result_duration
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (statement_id) DO NOTHING;
+
+-- name: GetXapiContentSummary :one
+WITH learners AS (
+    SELECT COALESCE(user_id, actor_id) AS learner,
+        count(*) AS statements,
+        bool_or(result_completion IS TRUE OR verb = 'completed') AS completed,
+        bool_or(result_success IS TRUE) AS passed,
+        max(result_score_scaled) AS best_score
+    FROM xapi_statements
+    WHERE org_id = $1 AND content_id = $2
+    GROUP BY 1
+)
+SELECT count(*) AS learners,
+    COALESCE(sum(statements), 0)::bigint AS statements,
+    count(*) FILTER (WHERE completed) AS completed,
+    count(*) FILTER (WHERE passed) AS passed,
+    count(best_score) AS scored,
+    COALESCE(avg(best_score), 0)::float8 AS average_best_score
+FROM learners;
+
+-- name: ListXapiContentLearners :many
+SELECT COALESCE(x.user_id, x.actor_id)::uuid AS learner_id,
+    x.user_id,
+    u.email,
+    count(*) AS statements,
+    bool_or(x.result_completion IS TRUE OR x.verb = 'completed')::bool AS completed,
+    bool_or(x.result_success IS TRUE)::bool AS passed,
+    count(x.result_score_scaled) AS scored,
+    COALESCE(max(x.result_score_scaled), 0)::float8 AS best_score,
+    max(x.created_at)::timestamptz AS last_activity_at
+FROM xapi_statements x
+LEFT JOIN users u ON u.id = x.user_id
+WHERE x.org_id = $1 AND x.content_id = $2
+GROUP BY COALESCE(x.user_id, x.actor_id), x.user_id, u.email
+ORDER BY last_activity_at DESC
+LIMIT $3 OFFSET $4;
+
+-- name: ListXapiLearnerSummary :many
+SELECT x.content_id::uuid AS content_id,
+    c.title,
+    count(*) AS statements,
+    bool_or(x.result_completion IS TRUE OR x.verb = 'completed')::bool AS completed,
+    bool_or(x.result_success IS TRUE)::bool AS passed,
+    count(x.result_score_scaled) AS scored,
+    COALESCE(max(x.result_score_scaled), 0)::float8 AS best_score,
+    max(x.created_at)::timestamptz AS last_activity_at
+FROM xapi_statements x
+JOIN h5p_content c ON c.id = x.content_id
+WHERE x.org_id = $1 AND x.user_id = $2
+GROUP BY x.content_id, c.title
+ORDER BY last_activity_at DESC
+LIMIT $3 OFFSET $4;
+
 -- name: UpsertProgressRecord :exec
 INSERT INTO progress_records (org_id, enrolment_id, content_id, user_id, score, max_score, completion, completed, attempts, time_spent)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 1, $9)
@@ -396,8 +592,9 @@ WHERE id = $1;
 SELECT id, org_id FROM h5p_content WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: CheckUserOrgMembership :one
-SELECT id FROM organisation_memberships
-WHERE user_id = $1 AND organisation_id = $2 AND status = 'active'
+SELECT m.id FROM organisation_memberships m
+JOIN organisations o ON o.id = m.organisation_id
+WHERE m.user_id = $1 AND m.organisation_id = $2 AND m.status = 'active' AND o.deleted_at IS NULL
 LIMIT 1;
 
 -- =============================================================================
@@ -422,3 +619,1407 @@ RETURNING *;
 -- name: DeleteContentUserState :exec
 DELETE FROM h5p_content_user_state
 WHERE user_id = $1 AND content_id = $2 AND sub_content_id = $3 AND data_type = $4;
+
+-- name: GetOrgMembershipRole :one
+SELECT m.role FROM organisation_memberships m
+JOIN organisations o ON o.id = m.organisation_id
+WHERE m.user_id = $1 AND m.organisation_id = $2 AND m.status = 'active' AND o.deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetOrgMemberIdentity :one
+SELECT m.display_name, u.email FROM organisation_memberships m
+JOIN users u ON u.id = m.user_id
+WHERE m.user_id = $1 AND m.organisation_id = $2 AND m.status = 'active'
+LIMIT 1;
+
+-- =============================================================================
+-- Organisation Webhooks
+-- =============================================================================
+
+-- name: ListOrganisationWebhooks :many
+SELECT * FROM organisation_webhooks
+WHERE organisation_id = $1
+ORDER BY created_at;
+
+-- name: ListActiveOrganisationWebhooks :many
+SELECT * FROM organisation_webhooks
+WHERE organisation_id = $1 AND active = true;
+
+-- name: CreateOrganisationWebhook :one
+INSERT INTO organisation_webhooks (organisation_id, url, secret, events)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: DeleteOrganisationWebhook :execrows
+DELETE FROM organisation_webhooks
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: UpdateOrganisationWebhookDelivery :exec
+UPDATE organisation_webhooks
+SET last_delivery_at = now(), last_delivery_status = $2, updated_at = now()
+WHERE id = $1;
+
+-- name: GetOrganisationWebhook :one
+SELECT * FROM organisation_webhooks
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: UpdateOrganisationWebhook :one
+UPDATE organisation_webhooks
+SET url = $3, events = $4, active = $5, updated_at = now()
+WHERE id = $1 AND organisation_id = $2
+RETURNING *;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, organisation_id, event_id, event_type, payload, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    response_status = $3,
+    error = $4,
+    last_attempt_at = now(),
+    next_attempt_at = $5,
+    delivered_at = CASE WHEN $2 = 'delivered' THEN now() END
+WHERE id = $1;
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = $2
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1 AND organisation_id = $2
+ORDER BY created_at DESC
+LIMIT $3;
+
+-- name: RequeueWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, error = '', next_attempt_at = $4
+WHERE id = $1 AND webhook_id = $2 AND organisation_id = $3 AND status <> 'pending'
+RETURNING *;
+
+-- name: DeleteOldWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE created_at < $1 AND status <> 'pending';
+
+-- =============================================================================
+-- Billing Admin (Refunds & Credits)
+-- =============================================================================
+
+-- name: GetOrganisationBillingContact :one
+SELECT
+    COALESCE(NULLIF(bc.name, ''), o.name)::text AS name,
+    COALESCE(NULLIF(bc.email, ''), NULLIF(o.email, ''), (
+        SELECT u.email FROM organisation_memberships m
+        JOIN users u ON u.id = m.user_id
+        WHERE m.organisation_id = o.id AND m.role = 'owner' AND m.status = 'active'
+        ORDER BY m.created_at
+        LIMIT 1
+    ), '')::text AS email
+FROM organisations o
+LEFT JOIN organisation_billing_contacts bc ON bc.organisation_id = o.id
+WHERE o.id = $1;
+
+-- name: InsertOrganisationActivity :exec
+INSERT INTO organisation_activity_log (organisation_id, user_id, action, entity_type, entity_id, new_values, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- =============================================================================
+-- Organisation Payment Methods (Card Expiry Reminders)
+-- =============================================================================
+
+-- name: UpsertOrganisationPaymentMethod :exec
+INSERT INTO organisation_payment_methods (organisation_id, payment_method_id, brand, last4, exp_month, exp_year, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (organisation_id) DO UPDATE SET
+    payment_method_id = EXCLUDED.payment_method_id,
+    brand = EXCLUDED.brand,
+    last4 = EXCLUDED.last4,
+    exp_month = EXCLUDED.exp_month,
+    exp_year = EXCLUDED.exp_year,
+    expires_at = EXCLUDED.expires_at,
+    last_reminder_days = CASE
+        WHEN organisation_payment_methods.payment_method_id = EXCLUDED.payment_method_id
+            AND organisation_payment_methods.expires_at = EXCLUDED.expires_at
+        THEN organisation_payment_methods.last_reminder_days
+    END,
+    updated_at = now();
+
+-- name: DeleteOrganisationPaymentMethod :exec
+DELETE FROM organisation_payment_methods
+WHERE organisation_id = $1;
+
+-- name: ListExpiringPaymentMethods :many
+SELECT pm.organisation_id, pm.brand, pm.last4, pm.expires_at, pm.last_reminder_days, o.slug
+FROM organisation_payment_methods pm
+JOIN organisations o ON o.id = pm.organisation_id
+WHERE pm.expires_at > now() AND pm.expires_at <= $1
+    AND o.subscription_id <> '' AND o.deleted_at IS NULL;
+
+-- name: SetPaymentMethodReminderSent :exec
+UPDATE organisation_payment_methods
+SET last_reminder_days = $2
+WHERE organisation_id = $1;
+
+-- =============================================================================
+-- Organisation Billing Contacts
+-- =============================================================================
+
+-- name: GetBillingContact :one
+SELECT * FROM organisation_billing_contacts
+WHERE organisation_id = $1;
+
+-- name: UpsertBillingContact :one
+INSERT INTO organisation_billing_contacts (
+    organisation_id, name, email, po_number, address_line1, address_line2,
+    city, state, postal_code, country, invoice_memo, invoice_custom_fields
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (organisation_id) DO UPDATE SET
+    name = EXCLUDED.name,
+    email = EXCLUDED.email,
+    po_number = EXCLUDED.po_number,
+    address_line1 = EXCLUDED.address_line1,
+    address_line2 = EXCLUDED.address_line2,
+    city = EXCLUDED.city,
+    state = EXCLUDED.state,
+    postal_code = EXCLUDED.postal_code,
+    country = EXCLUDED.country,
+    invoice_memo = EXCLUDED.invoice_memo,
+    invoice_custom_fields = EXCLUDED.invoice_custom_fields,
+    updated_at = now()
+RETURNING *;
+
+-- =============================================================================
+-- Grace-Period Content Locks
+-- =============================================================================
+
+-- name: LockCoursesOverLimit :execrows
+UPDATE courses c SET locked_at = current_timestamp, lock_reason = $3
+WHERE c.org_id = $1 AND c.deleted_at IS NULL AND c.locked_at IS NULL
+    AND c.id NOT IN (
+        SELECT keep.id FROM courses keep
+        WHERE keep.org_id = $1 AND keep.deleted_at IS NULL
+        ORDER BY keep.updated_at DESC
+        LIMIT $2
+    );
+
+-- name: LockContentOfLockedCourses :execrows
+UPDATE h5p_content c SET locked_at = current_timestamp, lock_reason = $2
+WHERE c.org_id = $1 AND c.deleted_at IS NULL AND c.locked_at IS NULL
+    AND EXISTS (
+        SELECT 1 FROM course_items ci JOIN courses co ON co.id = ci.course_id
+        WHERE ci.content_id = c.id AND ci.removed_at IS NULL
+            AND co.deleted_at IS NULL AND co.locked_at IS NOT NULL
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM course_items ci JOIN courses co ON co.id = ci.course_id
+        WHERE ci.content_id = c.id AND ci.removed_at IS NULL
+            AND co.deleted_at IS NULL AND co.locked_at IS NULL
+    );
+
+-- name: UnlockOrganisationCourses :exec
+UPDATE courses SET locked_at = NULL, lock_reason = NULL
+WHERE org_id = $1 AND locked_at IS NOT NULL;
+
+-- name: UnlockOrganisationContent :exec
+UPDATE h5p_content SET locked_at = NULL, lock_reason = NULL
+WHERE org_id = $1 AND locked_at IS NOT NULL;
+
+-- =============================================================================
+-- Organisation Storage Usage
+-- =============================================================================
+
+-- name: GetOrganisationStorageQuota :one
+SELECT
+    o.subscription_tier,
+    o.is_freemium,
+    o.freemium_expires_at,
+    COALESCE(su.bytes_used, 0)::bigint AS bytes_used
+FROM organisations o
+LEFT JOIN organisation_storage_usage su ON su.organisation_id = o.id
+WHERE o.id = $1;
+
+-- name: AddOrganisationStorageUsage :exec
+INSERT INTO organisation_storage_usage (organisation_id, bytes_used)
+VALUES ($1, $2)
+ON CONFLICT (organisation_id) DO UPDATE SET
+    bytes_used = organisation_storage_usage.bytes_used + EXCLUDED.bytes_used,
+    updated_at = now();
+
+-- =============================================================================
+-- Job Locks
+-- =============================================================================
+
+-- name: AcquireJobLock :execrows
+INSERT INTO job_locks (name, holder, acquired_at, expires_at)
+VALUES ($1, $2, now(), now() + make_interval(secs => sqlc.arg(ttl_seconds)::float8))
+ON CONFLICT (name) DO UPDATE SET
+    holder = EXCLUDED.holder,
+    acquired_at = EXCLUDED.acquired_at,
+    expires_at = EXCLUDED.expires_at
+WHERE job_locks.expires_at < now();
+
+-- name: ReleaseJobLock :exec
+DELETE FROM job_locks
+WHERE name = $1 AND holder = $2;
+
+-- name: ExtendJobLock :execrows
+UPDATE job_locks
+SET expires_at = now() + make_interval(secs => sqlc.arg(ttl_seconds)::float8)
+WHERE name = $1 AND holder = $2 AND expires_at > now();
+
+-- name: IsJobLockHeld :one
+SELECT EXISTS (
+    SELECT 1 FROM job_locks WHERE name = $1 AND expires_at > now()
+)::boolean AS held;
+
+-- =============================================================================
+-- Analytics Anonymization
+-- =============================================================================
+
+-- name: AnonymizeUserXapiStatements :execrows
+UPDATE xapi_statements
+SET user_id = NULL,
+    actor_id = sqlc.arg(actor_id)::uuid,
+    statement = jsonb_set(
+        CASE WHEN jsonb_typeof(statement) = 'object'
+            THEN statement #- '{authority}' #- '{context,instructor}' #- '{context,team}' #- '{result,response}'
+            ELSE '{}'::jsonb
+        END,
+        '{actor}', sqlc.arg(actor)::jsonb)
+WHERE user_id = sqlc.arg(user_id)::uuid;
+
+-- name: AnonymizeUserProgressRecords :execrows
+UPDATE progress_records
+SET user_id = NULL, actor_id = sqlc.arg(actor_id)::uuid, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id)::uuid;
+
+-- name: AnonymizeUserEnrolments :execrows
+UPDATE enrolments
+SET user_id = NULL, actor_id = sqlc.arg(actor_id)::uuid, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id)::uuid;
+
+-- =============================================================================
+-- Search (command palette)
+-- =============================================================================
+-- pattern is the search term with LIKE wildcards escaped. Rank: 0 exact title,
+-- 1 title prefix, 2 word prefix, 3 title substring, 4 other field match.
+
+-- name: SearchH5PContent :many
+SELECT c.id, c.title, c.status, c.updated_at, (c.locked_at IS NOT NULL)::boolean AS locked,
+    l.title AS library_title,
+    (CASE
+        WHEN c.title ILIKE sqlc.arg(pattern)::text THEN 0
+        WHEN c.title ILIKE sqlc.arg(pattern)::text || '%' THEN 1
+        WHEN c.title ILIKE '% ' || sqlc.arg(pattern)::text || '%' THEN 2
+        WHEN c.title ILIKE '%' || sqlc.arg(pattern)::text || '%' THEN 3
+        ELSE 4
+    END)::int AS rank
+FROM h5p_content c JOIN h5p_libraries l ON c.library_id = l.id
+WHERE c.org_id = sqlc.arg(org_id) AND c.deleted_at IS NULL
+    AND (sqlc.arg(include_drafts)::boolean OR c.status = 'published')
+    AND (c.title ILIKE '%' || sqlc.arg(pattern)::text || '%'
+        OR c.description ILIKE '%' || sqlc.arg(pattern)::text || '%'
+        OR array_to_string(c.tags, ' ') ILIKE '%' || sqlc.arg(pattern)::text || '%')
+ORDER BY rank, c.updated_at DESC
+LIMIT sqlc.arg(max_results);
+
+-- name: SearchCourses :many
+SELECT id, title, status, updated_at, (locked_at IS NOT NULL)::boolean AS locked,
+    (CASE
+        WHEN title ILIKE sqlc.arg(pattern)::text THEN 0
+        WHEN title ILIKE sqlc.arg(pattern)::text || '%' THEN 1
+        WHEN title ILIKE '% ' || sqlc.arg(pattern)::text || '%' THEN 2
+        WHEN title ILIKE '%' || sqlc.arg(pattern)::text || '%' THEN 3
+        ELSE 4
+    END)::int AS rank
+FROM courses
+WHERE org_id = sqlc.arg(org_id) AND deleted_at IS NULL
+    AND (sqlc.arg(include_drafts)::boolean OR status = 'published')
+    AND (title ILIKE '%' || sqlc.arg(pattern)::text || '%'
+        OR description ILIKE '%' || sqlc.arg(pattern)::text || '%')
+ORDER BY rank, updated_at DESC
+LIMIT sqlc.arg(max_results);
+
+-- name: SearchH5PContentFolders :many
+SELECT id, name, parent_id, updated_at,
+    (CASE
+        WHEN name ILIKE sqlc.arg(pattern)::text THEN 0
+        WHEN name ILIKE sqlc.arg(pattern)::text || '%' THEN 1
+        WHEN name ILIKE '% ' || sqlc.arg(pattern)::text || '%' THEN 2
+        ELSE 3
+    END)::int AS rank
+FROM h5p_content_folders
+WHERE org_id = sqlc.arg(org_id) AND name ILIKE '%' || sqlc.arg(pattern)::text || '%'
+ORDER BY rank, updated_at DESC
+LIMIT sqlc.arg(max_results);
+
+-- name: SearchSeoKeywordLists :many
+SELECT l.id, l.name, COALESCE(l.last_refreshed_at, l.created_at)::timestamptz AS updated_at,
+    (CASE
+        WHEN l.name ILIKE sqlc.arg(pattern)::text THEN 0
+        WHEN l.name ILIKE sqlc.arg(pattern)::text || '%' THEN 1
+        WHEN l.name ILIKE '% ' || sqlc.arg(pattern)::text || '%' THEN 2
+        WHEN l.name ILIKE '%' || sqlc.arg(pattern)::text || '%' THEN 3
+        ELSE 4
+    END)::int AS rank
+FROM seo_keyword_lists l
+WHERE l.organisation_id = sqlc.arg(org_id)
+    AND (l.name ILIKE '%' || sqlc.arg(pattern)::text || '%'
+        OR EXISTS (SELECT 1 FROM seo_keywords k
+            WHERE k.list_id = l.id AND k.keyword ILIKE '%' || sqlc.arg(pattern)::text || '%'))
+ORDER BY rank, updated_at DESC
+LIMIT sqlc.arg(max_results);
+
+-- =============================================================================
+-- API Rate Limits
+-- =============================================================================
+
+-- name: GetUserRatePlan :one
+SELECT o.id AS organisation_id, o.subscription_tier, o.is_freemium, o.freemium_expires_at
+FROM users u
+JOIN organisations o ON o.id = u.default_organisation_id
+WHERE u.id = $1;
+
+-- =============================================================================
+-- Access Reviews
+-- =============================================================================
+
+-- name: CreateAccessReview :one
+INSERT INTO access_reviews (organisation_id, requested_by)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: CreateAccessReviewsForActiveOrganisations :execrows
+INSERT INTO access_reviews (organisation_id, requested_by)
+SELECT o.id, sqlc.narg(requested_by)::uuid
+FROM organisations o
+WHERE o.status = 'active' AND o.deleted_at IS NULL;
+
+-- name: GetAccessReview :one
+SELECT * FROM access_reviews
+WHERE id = $1;
+
+-- name: ListAccessReviews :many
+SELECT * FROM access_reviews
+WHERE organisation_id = $1
+ORDER BY created_at DESC
+LIMIT 100;
+
+-- name: ListPendingAccessReviews :many
+SELECT * FROM access_reviews
+WHERE status = 'pending'
+ORDER BY created_at
+LIMIT $1;
+
+-- name: CompleteAccessReview :exec
+UPDATE access_reviews
+SET status = 'ready', completed_at = now(), expires_at = sqlc.arg(expires_at)::timestamptz
+WHERE id = sqlc.arg(id);
+
+-- name: FailAccessReview :exec
+UPDATE access_reviews
+SET status = 'failed', completed_at = now(), error = sqlc.arg(error), expires_at = sqlc.arg(expires_at)::timestamptz
+WHERE id = sqlc.arg(id);
+
+-- name: ListExpiredAccessReviews :many
+SELECT * FROM access_reviews
+WHERE expires_at < now()
+ORDER BY expires_at
+LIMIT $1;
+
+-- name: DeleteAccessReview :exec
+DELETE FROM access_reviews
+WHERE id = $1;
+
+-- name: ListAccessReviewMembers :many
+SELECT m.user_id, u.email, m.display_name, m.role, m.status, m.created_at, m.invited_at, m.accepted_at,
+    inviter.email AS invited_by_email, u.sub, u.suspended, (u.api_key <> '')::boolean AS has_api_key
+FROM organisation_memberships m
+JOIN users u ON u.id = m.user_id
+LEFT JOIN users inviter ON inviter.id = m.invited_by
+WHERE m.organisation_id = $1
+ORDER BY m.status, m.role, u.email;
+
+-- =============================================================================
+-- Announcements
+-- =============================================================================
+
+-- name: CreateAnnouncement :one
+INSERT INTO announcements (title, body, link_url, tiers, flags, published_at, expires_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: UpdateAnnouncement :one
+UPDATE announcements
+SET title = $2, body = $3, link_url = $4, tiers = $5, flags = $6,
+    published_at = $7, expires_at = $8, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteAnnouncement :execrows
+DELETE FROM announcements
+WHERE id = $1;
+
+-- name: ListAnnouncements :many
+SELECT * FROM announcements
+ORDER BY created_at DESC
+LIMIT 200;
+
+-- name: ListUnreadAnnouncements :many
+SELECT a.* FROM announcements a
+WHERE a.published_at <= now()
+    AND (a.expires_at IS NULL OR a.expires_at > now())
+    AND NOT EXISTS (
+        SELECT 1 FROM announcement_reads r
+        WHERE r.announcement_id = a.id AND r.user_id = $1
+    )
+ORDER BY a.published_at DESC;
+
+-- name: MarkAnnouncementRead :exec
+INSERT INTO announcement_reads (announcement_id, user_id)
+SELECT a.id, sqlc.arg(user_id)::uuid
+FROM announcements a
+WHERE a.id = sqlc.arg(announcement_id) AND a.published_at <= now()
+ON CONFLICT DO NOTHING;
+
+-- =============================================================================
+-- SEO Rank Checks
+-- =============================================================================
+
+-- name: CreateSeoRankCheck :one
+INSERT INTO seo_rank_checks (organisation_id, created_by, domain, location_code, language_code, device, keyword_count)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetSeoRankCheck :one
+SELECT * FROM seo_rank_checks
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: CompleteSeoRankCheck :exec
+UPDATE seo_rank_checks
+SET status = $2, results = $3, cost = $4, error = $5, completed_at = now()
+WHERE id = $1 AND status = 'pending';
+
+-- =============================================================================
+-- SEO Page Experience
+-- =============================================================================
+
+-- name: CreateSeoPageExperience :one
+INSERT INTO seo_page_experience (organisation_id, audit_id, page_url, strategy, created_by, intrusive_interstitial)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (organisation_id, audit_id, page_url, strategy) DO UPDATE
+SET created_by = EXCLUDED.created_by, intrusive_interstitial = EXCLUDED.intrusive_interstitial,
+    created_at = now(), completed_at = NULL, status = 'pending', error = ''
+RETURNING *;
+
+-- name: CompleteSeoPageExperience :exec
+UPDATE seo_page_experience
+SET status = $2, error = $3, score = $4, passed = $5, cwv_source = $6,
+    lcp_ms = $7, inp_ms = $8, cls = $9, signals = $10, pagespeed = $11, issues = $12, completed_at = now()
+WHERE id = $1 AND status = 'pending';
+
+-- name: RecheckSeoPageIssues :exec
+UPDATE seo_page_experience
+SET issues = $2, rechecked_at = now()
+WHERE id = $1;
+
+-- name: ResetSeoPageExperience :one
+UPDATE seo_page_experience
+SET status = 'pending', error = '', created_at = now(), completed_at = NULL
+WHERE id = $1 AND status <> 'completed'
+RETURNING *;
+
+-- name: ListSeoPageExperienceByAudit :many
+SELECT * FROM seo_page_experience
+WHERE organisation_id = $1 AND audit_id = $2
+ORDER BY page_url, strategy;
+
+-- name: ListSeoPageExperienceTrend :many
+SELECT * FROM seo_page_experience
+WHERE organisation_id = $1 AND page_url = $2 AND strategy = $3 AND status = 'completed'
+ORDER BY created_at DESC
+LIMIT $4;
+
+-- name: UpsertSeoRawArtifact :exec
+INSERT INTO seo_raw_artifacts (page_id, section, object_key, size_bytes)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (page_id, section) DO UPDATE
+SET object_key = EXCLUDED.object_key, size_bytes = EXCLUDED.size_bytes, created_at = now();
+
+-- name: ListSeoRawArtifacts :many
+SELECT p.page_url, p.strategy, a.object_key, a.size_bytes, a.created_at
+FROM seo_raw_artifacts a
+JOIN seo_page_experience p ON p.id = a.page_id
+WHERE p.organisation_id = $1 AND p.audit_id = $2 AND a.section = $3
+ORDER BY p.page_url, p.strategy;
+
+-- =============================================================================
+-- SEO Keyword Lists
+-- =============================================================================
+
+-- name: CreateSeoKeywordList :one
+INSERT INTO seo_keyword_lists (organisation_id, name, location_code, language_code, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: AddSeoKeywords :exec
+INSERT INTO seo_keywords (list_id, keyword)
+SELECT sqlc.arg(list_id)::uuid, unnest(sqlc.arg(keywords)::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: GetSeoKeywordList :one
+SELECT * FROM seo_keyword_lists
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: ListSeoKeywordListsByOrg :many
+SELECT l.id, l.organisation_id, l.name, l.location_code, l.language_code, l.created_by, l.created_at, l.last_refreshed_at, l.next_refresh_at,
+    (SELECT count(*) FROM seo_keywords k WHERE k.list_id = l.id) AS keyword_count
+FROM seo_keyword_lists l
+WHERE l.organisation_id = $1
+ORDER BY l.name;
+
+-- name: ListSeoKeywords :many
+SELECT * FROM seo_keywords
+WHERE list_id = $1
+ORDER BY keyword;
+
+-- name: ListDueSeoKeywords :many
+SELECT k.list_id, l.organisation_id, l.location_code, l.language_code, k.keyword
+FROM seo_keywords k
+JOIN seo_keyword_lists l ON l.id = k.list_id
+JOIN organisations o ON o.id = l.organisation_id
+WHERE l.next_refresh_at <= now() AND o.deleted_at IS NULL
+ORDER BY l.location_code, l.language_code, k.keyword;
+
+-- name: UpdateSeoKeywordVolume :exec
+UPDATE seo_keywords
+SET search_volume = $3, cpc = $4, competition = $5, monthly_searches = $6, refreshed_at = now()
+WHERE list_id = $1 AND keyword = $2;
+
+-- name: CreateSeoKeywordRefresh :exec
+INSERT INTO seo_keyword_refreshes (list_id, organisation_id, status, keyword_count, updated_count, cost, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ScheduleSeoKeywordList :exec
+UPDATE seo_keyword_lists
+SET last_refreshed_at = now(), next_refresh_at = $2
+WHERE id = $1;
+
+-- name: ListSeoKeywordRefreshes :many
+SELECT * FROM seo_keyword_refreshes
+WHERE list_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- =============================================================================
+-- SEO Schedules
+-- =============================================================================
+
+-- name: GetOrganisationSeoPlan :one
+SELECT subscription_tier, is_freemium, freemium_expires_at
+FROM organisations
+WHERE id = $1;
+
+-- name: UpsertSeoSchedule :one
+INSERT INTO seo_schedules (organisation_id, domain, pages, keywords, location_code, language_code, device, strategy, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (organisation_id, domain) DO UPDATE
+SET pages = EXCLUDED.pages,
+    keywords = EXCLUDED.keywords,
+    location_code = EXCLUDED.location_code,
+    language_code = EXCLUDED.language_code,
+    device = EXCLUDED.device,
+    strategy = EXCLUDED.strategy
+RETURNING *;
+
+-- name: ListSeoSchedulesByOrg :many
+SELECT * FROM seo_schedules
+WHERE organisation_id = $1
+ORDER BY domain;
+
+-- name: GetSeoSchedule :one
+SELECT * FROM seo_schedules
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: DeleteSeoSchedule :execrows
+DELETE FROM seo_schedules
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: ListDueSeoSchedules :many
+SELECT s.id, s.organisation_id, s.domain, s.pages, s.keywords, s.location_code, s.language_code, s.device, s.strategy, s.created_by, s.created_at, s.last_run_at, s.next_run_at,
+    o.subscription_tier, o.is_freemium, o.freemium_expires_at
+FROM seo_schedules s
+JOIN organisations o ON o.id = s.organisation_id
+WHERE s.next_run_at <= now() AND o.deleted_at IS NULL
+ORDER BY s.next_run_at
+LIMIT $1;
+
+-- name: ClaimSeoSchedule :execrows
+UPDATE seo_schedules
+SET last_run_at = now(), next_run_at = $2
+WHERE id = $1 AND next_run_at <= now();
+
+-- name: DeferSeoSchedule :exec
+UPDATE seo_schedules
+SET next_run_at = $2
+WHERE id = $1;
+
+-- name: CreateSeoScheduleRun :one
+INSERT INTO seo_schedule_runs (schedule_id, organisation_id)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: SetSeoScheduleRunTargets :exec
+UPDATE seo_schedule_runs
+SET audit_id = $2, rank_check_id = $3
+WHERE id = $1;
+
+-- name: CompleteSeoScheduleRun :exec
+UPDATE seo_schedule_runs
+SET status = $2, summary = $3, deltas = $4, error = $5, completed_at = now()
+WHERE id = $1;
+
+-- name: ListRunningSeoScheduleRuns :many
+SELECT * FROM seo_schedule_runs
+WHERE status = 'running'
+ORDER BY created_at;
+
+-- name: GetPreviousSeoScheduleRun :one
+SELECT * FROM seo_schedule_runs
+WHERE schedule_id = $1 AND created_at < $2 AND status IN ('completed', 'partial')
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ListSeoScheduleRuns :many
+SELECT * FROM seo_schedule_runs
+WHERE schedule_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- =============================================================================
+-- SEO Backlink Prospects
+-- =============================================================================
+
+-- name: CreateSeoBacklinkProspect :execrows
+INSERT INTO seo_backlink_prospects (organisation_id, target, domain, status, source, competitors, contact, notes, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (organisation_id, target, domain) DO NOTHING;
+
+-- name: CountSeoBacklinkProspects :one
+SELECT COUNT(*) FROM seo_backlink_prospects
+WHERE organisation_id = $1 AND target = $2;
+
+-- name: ListSeoBacklinkProspects :many
+SELECT * FROM seo_backlink_prospects
+WHERE organisation_id = sqlc.arg(organisation_id) AND target = sqlc.arg(target)
+    AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY cardinality(competitors) DESC, rank DESC NULLS LAST, domain
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: UpdateSeoBacklinkProspect :one
+UPDATE seo_backlink_prospects
+SET status = COALESCE(sqlc.narg(status)::text, status),
+    contact = COALESCE(sqlc.narg(contact)::text, contact),
+    notes = COALESCE(sqlc.narg(notes)::text, notes),
+    updated_at = now(),
+    contacted_at = CASE WHEN COALESCE(sqlc.narg(status)::text, status) IN ('contacted', 'linked') THEN COALESCE(contacted_at, now()) END,
+    linked_at = CASE WHEN COALESCE(sqlc.narg(status)::text, status) = 'linked' THEN COALESCE(linked_at, now()) END
+WHERE id = sqlc.arg(id) AND organisation_id = sqlc.arg(organisation_id)
+RETURNING *;
+
+-- name: DeleteSeoBacklinkProspect :execrows
+DELETE FROM seo_backlink_prospects
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: ListUnenrichedSeoBacklinkProspects :many
+SELECT * FROM seo_backlink_prospects
+WHERE organisation_id = $1 AND target = $2 AND enriched_at IS NULL
+ORDER BY created_at, domain
+LIMIT $3;
+
+-- name: EnrichSeoBacklinkProspect :exec
+UPDATE seo_backlink_prospects
+SET rank = $2, backlinks = $3, referring_domains = $4, spam_score = $5, enriched_at = now()
+WHERE id = $1;
+
+-- =============================================================================
+-- Rank Tracker
+-- =============================================================================
+
+-- name: CountRankTrackerKeywordsByOrg :one
+SELECT COUNT(*) FROM rank_tracker_keywords k
+JOIN rank_trackers t ON t.id = k.tracker_id
+WHERE t.organisation_id = $1;
+
+-- name: CreateRankTracker :one
+INSERT INTO rank_trackers (organisation_id, domain, location_code, language_code, device, competitors, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (organisation_id, domain, location_code, language_code, device) DO NOTHING
+RETURNING *;
+
+-- name: ListRankTrackersByOrg :many
+SELECT t.id, t.organisation_id, t.domain, t.location_code, t.language_code, t.device, t.competitors, t.created_by, t.created_at, t.last_run_at, t.next_run_at, t.volumes_refreshed_at,
+    (SELECT count(*) FROM rank_tracker_keywords k WHERE k.tracker_id = t.id) AS keyword_count
+FROM rank_trackers t
+WHERE t.organisation_id = $1
+ORDER BY t.domain, t.created_at;
+
+-- name: GetRankTracker :one
+SELECT * FROM rank_trackers
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: DeleteRankTracker :execrows
+DELETE FROM rank_trackers
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: AddRankTrackerKeywords :execrows
+INSERT INTO rank_tracker_keywords (tracker_id, keyword)
+SELECT sqlc.arg(tracker_id)::uuid, unnest(sqlc.arg(keywords)::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteRankTrackerKeywords :execrows
+DELETE FROM rank_tracker_keywords
+WHERE tracker_id = sqlc.arg(tracker_id) AND keyword = ANY(sqlc.arg(keywords)::text[]);
+
+-- name: ListRankTrackerKeywords :many
+SELECT * FROM rank_tracker_keywords
+WHERE tracker_id = $1
+ORDER BY keyword;
+
+-- name: ListDueRankTrackers :many
+SELECT * FROM rank_trackers
+WHERE next_run_at <= now()
+    AND organisation_id IN (SELECT id FROM organisations WHERE deleted_at IS NULL)
+ORDER BY next_run_at
+LIMIT $1;
+
+-- name: ClaimRankTracker :execrows
+UPDATE rank_trackers
+SET last_run_at = now(), next_run_at = $2
+WHERE id = $1 AND next_run_at <= now();
+
+-- name: SetRankTrackerKeywordVolumes :exec
+UPDATE rank_tracker_keywords k
+SET search_volume = NULLIF(v.volume, -1)
+FROM (
+    SELECT unnest(sqlc.arg(keywords)::text[]) AS keyword, unnest(sqlc.arg(volumes)::int[]) AS volume
+) AS v
+WHERE k.tracker_id = sqlc.arg(tracker_id) AND k.keyword = v.keyword;
+
+-- name: MarkRankTrackerVolumesRefreshed :exec
+UPDATE rank_trackers
+SET volumes_refreshed_at = now()
+WHERE id = $1;
+
+-- name: CreateRankTrackerRun :one
+INSERT INTO rank_tracker_runs (tracker_id, organisation_id, keyword_count)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: AddRankPositions :exec
+INSERT INTO rank_positions (run_id, tracker_id, keyword, domain, position, url, source)
+SELECT sqlc.arg(run_id)::uuid, sqlc.arg(tracker_id)::uuid, p.keyword, p.domain, NULLIF(p.position, 0), p.url, p.source
+FROM (
+    SELECT unnest(sqlc.arg(keywords)::text[]) AS keyword, unnest(sqlc.arg(domains)::text[]) AS domain,
+        unnest(sqlc.arg(positions)::int[]) AS position, unnest(sqlc.arg(urls)::text[]) AS url,
+        unnest(sqlc.arg(sources)::text[]) AS source
+) AS p;
+
+-- name: CompleteRankTrackerRun :exec
+UPDATE rank_tracker_runs
+SET status = $2, failed_count = $3, cost = $4, share_of_voice = $5, error = $6, completed_at = now()
+WHERE id = $1;
+
+-- name: FailStaleRankTrackerRuns :execrows
+UPDATE rank_tracker_runs
+SET status = 'failed', error = 'The run was interrupted', completed_at = now()
+WHERE status = 'running' AND created_at < $1;
+
+-- name: ListRankTrackerRuns :many
+SELECT * FROM rank_tracker_runs
+WHERE tracker_id = $1 AND created_at >= $2
+ORDER BY created_at;
+
+-- name: GetLatestRankTrackerRun :one
+SELECT * FROM rank_tracker_runs
+WHERE tracker_id = $1 AND status IN ('completed', 'partial')
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetRankTrackerRunBefore :one
+SELECT * FROM rank_tracker_runs
+WHERE tracker_id = $1 AND created_at <= $2 AND status IN ('completed', 'partial')
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ListRankPositionsByRun :many
+SELECT * FROM rank_positions
+WHERE run_id = $1 AND domain = $2
+ORDER BY keyword;
+
+-- name: ListRankPositionHistory :many
+SELECT * FROM rank_positions
+WHERE tracker_id = $1 AND keyword = $2 AND checked_at >= $3
+ORDER BY checked_at, domain;
+
+-- =============================================================================
+-- Competitor Monitoring
+-- =============================================================================
+
+-- name: CreateCompetitorPin :one
+INSERT INTO competitor_pins (organisation_id, target, domain, location_code, language_code, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (organisation_id, target, domain, location_code, language_code) DO NOTHING
+RETURNING *;
+
+-- name: ListCompetitorPinsByOrg :many
+SELECT * FROM competitor_pins
+WHERE organisation_id = $1
+ORDER BY target, location_code, language_code, domain;
+
+-- name: ListCompetitorPinsByTarget :many
+SELECT * FROM competitor_pins
+WHERE organisation_id = $1 AND target = $2 AND location_code = $3 AND language_code = $4
+ORDER BY domain;
+
+-- name: GetCompetitorPin :one
+SELECT * FROM competitor_pins
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: DeleteCompetitorPin :execrows
+DELETE FROM competitor_pins
+WHERE id = $1 AND organisation_id = $2;
+
+-- name: ListDueCompetitorPins :many
+SELECT * FROM competitor_pins
+WHERE next_refresh_at <= now()
+    AND organisation_id IN (SELECT id FROM organisations WHERE deleted_at IS NULL)
+ORDER BY next_refresh_at
+LIMIT $1;
+
+-- name: ClaimCompetitorPin :execrows
+UPDATE competitor_pins
+SET next_refresh_at = $2
+WHERE id = $1 AND next_refresh_at <= now();
+
+-- name: MarkCompetitorPinRefreshed :exec
+UPDATE competitor_pins
+SET refreshed_at = now()
+WHERE id = $1;
+
+-- name: CreateCompetitorSnapshot :exec
+INSERT INTO competitor_snapshots (organisation_id, target, domain, location_code, language_code, organic_etv, organic_keywords, top3_keywords, top10_keywords, domain_rank, backlinks, referring_domains)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: GetLatestCompetitorSnapshot :one
+SELECT * FROM competitor_snapshots
+WHERE organisation_id = $1 AND target = $2 AND location_code = $3 AND language_code = $4 AND domain = $5
+ORDER BY captured_at DESC
+LIMIT 1;
+
+-- name: ListRecentCompetitorSnapshots :many
+SELECT id, organisation_id, target, domain, location_code, language_code, captured_at, organic_etv, organic_keywords, top3_keywords, top10_keywords, domain_rank, backlinks, referring_domains FROM (
+    SELECT *,
+        row_number() OVER (PARTITION BY domain ORDER BY captured_at DESC) AS recency
+    FROM competitor_snapshots
+    WHERE organisation_id = $1 AND target = $2 AND location_code = $3 AND language_code = $4
+) recent
+WHERE recency <= 2
+ORDER BY domain, captured_at DESC;
+
+-- name: UpsertCompetitorKeywordGaps :many
+INSERT INTO competitor_keyword_gaps (pin_id, keyword, search_volume, position, url, is_new)
+SELECT sqlc.arg(pin_id)::uuid, g.keyword, NULLIF(g.volume, -1), g.position, g.url, sqlc.arg(is_new)::boolean
+FROM (
+    SELECT unnest(sqlc.arg(keywords)::text[]) AS keyword, unnest(sqlc.arg(volumes)::int[]) AS volume,
+        unnest(sqlc.arg(positions)::int[]) AS position, unnest(sqlc.arg(urls)::text[]) AS url
+) AS g
+ON CONFLICT (pin_id, keyword) DO UPDATE
+SET search_volume = EXCLUDED.search_volume, position = EXCLUDED.position, url = EXCLUDED.url, is_new = false, last_seen_at = now()
+RETURNING keyword, is_new;
+
+-- name: DeleteStaleCompetitorKeywordGaps :execrows
+DELETE FROM competitor_keyword_gaps
+WHERE pin_id = $1 AND last_seen_at < $2;
+
+-- name: ListCompetitorKeywordGaps :many
+SELECT * FROM competitor_keyword_gaps
+WHERE pin_id = $1
+ORDER BY search_volume DESC NULLS LAST, keyword
+LIMIT $2 OFFSET $3;
+
+-- name: CountCompetitorKeywordGaps :many
+SELECT g.pin_id, count(*) AS gaps,
+    count(*) FILTER (WHERE g.is_new) AS new_gaps
+FROM competitor_keyword_gaps g
+JOIN competitor_pins p ON p.id = g.pin_id
+WHERE p.organisation_id = $1
+GROUP BY g.pin_id;
+
+-- name: CreateCompetitorAlert :exec
+INSERT INTO competitor_alerts (organisation_id, pin_id, kind, message, keywords, amount)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListCompetitorAlerts :many
+SELECT * FROM competitor_alerts
+WHERE organisation_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- =============================================================================
+-- Retention Policies
+-- =============================================================================
+
+-- name: GetRetentionPolicy :one
+SELECT * FROM organisation_retention_policies
+WHERE organisation_id = $1;
+
+-- name: UpsertRetentionPolicy :one
+INSERT INTO organisation_retention_policies (organisation_id, archive_drafts_after_months, purge_deleted_after_days, retain_xapi_years, updated_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (organisation_id) DO UPDATE
+SET archive_drafts_after_months = EXCLUDED.archive_drafts_after_months,
+    purge_deleted_after_days = EXCLUDED.purge_deleted_after_days,
+    retain_xapi_years = EXCLUDED.retain_xapi_years,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListDueRetentionPolicies :many
+SELECT * FROM organisation_retention_policies
+WHERE evaluated_at IS NULL OR evaluated_at < $1
+ORDER BY evaluated_at NULLS FIRST
+LIMIT $2;
+
+-- name: MarkRetentionPolicyEvaluated :exec
+UPDATE organisation_retention_policies SET evaluated_at = now()
+WHERE organisation_id = $1;
+
+-- name: CountStaleDraftH5PContent :one
+SELECT count(*) FROM h5p_content
+WHERE org_id = $1 AND status = 'draft' AND deleted_at IS NULL AND updated_at < $2;
+
+-- name: ArchiveStaleDraftH5PContent :execrows
+UPDATE h5p_content SET status = 'archived'
+WHERE org_id = $1 AND status = 'draft' AND deleted_at IS NULL AND updated_at < $2;
+
+-- name: CountPurgeableH5PContent :one
+SELECT count(*) FROM h5p_content
+WHERE org_id = $1 AND deleted_at < $2;
+
+-- name: PurgeDeletedH5PContent :many
+WITH purged AS (
+    DELETE FROM h5p_content
+    WHERE id IN (
+        SELECT c.id FROM h5p_content c
+        WHERE c.org_id = $1 AND c.deleted_at < $2
+        ORDER BY c.deleted_at
+        LIMIT $3
+    )
+    RETURNING id, org_id
+), queued AS (
+    INSERT INTO pending_deletions (storage_key, reason)
+    SELECT 'h5p-content/' || org_id || '/' || id || '/', 'retention' FROM purged
+    ON CONFLICT (storage_key) DO NOTHING
+)
+SELECT id FROM purged;
+
+-- name: CountExpiredXapiStatements :one
+SELECT count(*) FROM xapi_statements
+WHERE org_id = $1 AND created_at < $2;
+
+-- name: DeleteExpiredXapiStatements :execrows
+DELETE FROM xapi_statements
+WHERE id IN (
+    SELECT s.id FROM xapi_statements s
+    WHERE s.org_id = $1 AND s.created_at < $2
+    LIMIT $3
+);
+
+-- name: InsertOrganisationActivityChange :exec
+INSERT INTO organisation_activity_log (organisation_id, user_id, action, entity_type, entity_id, old_values, new_values, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- =============================================================================
+-- Results (gradebook)
+-- =============================================================================
+
+-- name: GetGradebookCourse :one
+SELECT id, title, slug FROM courses
+WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL;
+
+-- name: ListContentResults :many
+SELECT COALESCE(x.user_id, x.actor_id)::uuid AS learner_id,
+    x.user_id,
+    u.email,
+    count(*) FILTER (WHERE x.verb = 'attempted') AS attempts,
+    bool_or(x.result_completion IS TRUE OR x.verb = 'completed')::bool AS finished,
+    bool_or(x.result_success IS TRUE)::bool AS passed,
+    count(x.result_score_scaled) AS scored,
+    COALESCE(max(x.result_score_scaled), 0)::float8 AS best_score,
+    count(*) FILTER (WHERE x.result_score_scaled IS NOT NULL
+        AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL) AS raw_scored,
+    COALESCE((array_agg(x.result_score_raw ORDER BY x.result_score_scaled DESC, x.created_at DESC)
+        FILTER (WHERE x.result_score_scaled IS NOT NULL
+            AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL))[1], 0)::float8 AS best_score_raw,
+    COALESCE((array_agg(x.result_score_max ORDER BY x.result_score_scaled DESC, x.created_at DESC)
+        FILTER (WHERE x.result_score_scaled IS NOT NULL
+            AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL))[1], 0)::float8 AS best_score_max,
+    min(x.created_at)::timestamptz AS first_activity_at,
+    max(x.created_at)::timestamptz AS last_activity_at
+FROM xapi_statements x
+LEFT JOIN users u ON u.id = x.user_id
+WHERE x.org_id = $1 AND x.content_id = $2
+GROUP BY COALESCE(x.user_id, x.actor_id), x.user_id, u.email
+ORDER BY u.email NULLS LAST, learner_id
+LIMIT $3 OFFSET $4;
+
+-- name: ListCourseGradebookItems :many
+SELECT ci.id, ci.content_id::uuid AS content_id,
+    COALESCE(NULLIF(ci.title, ''), c.title)::text AS title
+FROM course_items ci
+JOIN h5p_content c ON c.id = ci.content_id
+WHERE ci.course_id = $1 AND ci.item_type = 'h5p' AND ci.removed_at IS NULL AND c.deleted_at IS NULL
+ORDER BY ci.sort_order, ci.created_at;
+
+-- name: CountCourseGradebookLearners :one
+SELECT count(*) FROM enrolments
+WHERE org_id = $1 AND course_id = $2 AND status <> 'withdrawn';
+
+-- name: ListCourseGradebookLearners :many
+SELECT COALESCE(e.user_id, e.actor_id)::uuid AS learner_id,
+    e.user_id,
+    u.email,
+    e.status,
+    e.enrolled_at,
+    e.completed_at
+FROM enrolments e
+LEFT JOIN users u ON u.id = e.user_id
+WHERE e.org_id = $1 AND e.course_id = $2 AND e.status <> 'withdrawn'
+ORDER BY u.email NULLS LAST, learner_id
+LIMIT $3 OFFSET $4;
+
+-- name: ListCourseGradebookResults :many
+SELECT COALESCE(x.user_id, x.actor_id)::uuid AS learner_id,
+    x.content_id::uuid AS content_id,
+    count(*) FILTER (WHERE x.verb = 'attempted') AS attempts,
+    bool_or(x.result_completion IS TRUE OR x.verb = 'completed')::bool AS finished,
+    bool_or(x.result_success IS TRUE)::bool AS passed,
+    count(x.result_score_scaled) AS scored,
+    COALESCE(max(x.result_score_scaled), 0)::float8 AS best_score,
+    count(*) FILTER (WHERE x.result_score_scaled IS NOT NULL
+        AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL) AS raw_scored,
+    COALESCE((array_agg(x.result_score_raw ORDER BY x.result_score_scaled DESC, x.created_at DESC)
+        FILTER (WHERE x.result_score_scaled IS NOT NULL
+            AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL))[1], 0)::float8 AS best_score_raw,
+    COALESCE((array_agg(x.result_score_max ORDER BY x.result_score_scaled DESC, x.created_at DESC)
+        FILTER (WHERE x.result_score_scaled IS NOT NULL
+            AND x.result_score_raw IS NOT NULL AND x.result_score_max IS NOT NULL))[1], 0)::float8 AS best_score_max,
+    min(x.created_at)::timestamptz AS first_activity_at,
+    max(x.created_at)::timestamptz AS last_activity_at
+FROM xapi_statements x
+WHERE x.org_id = sqlc.arg(org_id)
+    AND x.content_id = ANY(sqlc.arg(content_ids)::uuid[])
+    AND COALESCE(x.user_id, x.actor_id) = ANY(sqlc.arg(learner_ids)::uuid[])
+GROUP BY 1, 2;
+
+-- =============================================================================
+-- Content Extract Cache
+-- =============================================================================
+
+-- name: GetContentExtractCache :one
+SELECT * FROM content_extract_cache
+WHERE url = $1 AND expires_at > now();
+
+-- name: UpsertContentExtractCache :exec
+INSERT INTO content_extract_cache (url, final_url, title, markdown, links, provider, fetched_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, now(), $7)
+ON CONFLICT (url) DO UPDATE
+SET final_url = EXCLUDED.final_url, title = EXCLUDED.title, markdown = EXCLUDED.markdown,
+    links = EXCLUDED.links, provider = EXCLUDED.provider,
+    fetched_at = EXCLUDED.fetched_at, expires_at = EXCLUDED.expires_at;
+
+-- name: DeleteExpiredContentExtractCache :exec
+DELETE FROM content_extract_cache WHERE expires_at < now();
+
+-- =============================================================================
+-- Notifications
+-- =============================================================================
+
+-- name: ListNotificationPreferences :many
+SELECT * FROM notification_preferences
+WHERE user_id = $1 AND organisation_id = $2
+ORDER BY category, channel;
+
+-- name: ListOrganisationNotificationPreferences :many
+SELECT * FROM notification_preferences
+WHERE organisation_id = $1;
+
+-- name: UpsertNotificationPreference :exec
+INSERT INTO notification_preferences (user_id, organisation_id, category, channel, enabled, digest)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, organisation_id, category, channel) DO UPDATE
+SET enabled = EXCLUDED.enabled, digest = EXCLUDED.digest, updated_at = NOW();
+
+-- name: DeleteNotificationPreferences :exec
+DELETE FROM notification_preferences
+WHERE user_id = $1 AND organisation_id = $2;
+
+-- name: ListOrganisationNotificationRecipients :many
+SELECT m.user_id, u.email, m.display_name, m.role
+FROM organisation_memberships m
+JOIN users u ON u.id = m.user_id
+WHERE m.organisation_id = $1 AND m.status = 'active' AND NOT u.suspended;
+
+-- name: CreateNotificationDigestItem :exec
+INSERT INTO notification_digest_items (user_id, organisation_id, category, event_type, summary)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListNotificationDigestItems :many
+SELECT d.id, d.user_id, u.email, d.organisation_id, o.name AS organisation_name, d.category, d.event_type, d.summary, d.created_at
+FROM notification_digest_items d
+JOIN users u ON u.id = d.user_id
+JOIN organisations o ON o.id = d.organisation_id
+WHERE d.created_at < $1
+ORDER BY d.user_id, o.name, d.created_at;
+
+-- name: DeleteNotificationDigestItems :exec
+DELETE FROM notification_digest_items
+WHERE user_id = $1 AND created_at < $2;
+
+-- name: CreateUserNotification :exec
+INSERT INTO user_notifications (user_id, organisation_id, category, event_type, summary, data)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListUserNotifications :many
+SELECT * FROM user_notifications
+WHERE user_id = $1 AND organisation_id = $2
+ORDER BY created_at DESC
+LIMIT $3;
+
+-- name: CountUnreadUserNotifications :one
+SELECT COUNT(*) FROM user_notifications
+WHERE user_id = $1 AND organisation_id = $2 AND read_at IS NULL;
+
+-- name: MarkUserNotificationsRead :execrows
+UPDATE user_notifications SET read_at = NOW()
+WHERE user_id = $1 AND organisation_id = $2 AND read_at IS NULL;
+
+-- name: DeleteOldUserNotifications :execrows
+DELETE FROM user_notifications WHERE created_at < $1;
+
+-- =============================================================================
+-- Pending Deletions
+-- =============================================================================
+
+-- name: ClaimDuePendingDeletions :many
+UPDATE pending_deletions
+SET next_attempt_at = $2
+WHERE id IN (
+    SELECT id FROM pending_deletions
+    WHERE next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: DeletePendingDeletion :exec
+DELETE FROM pending_deletions WHERE id = $1;
+
+-- name: RecordPendingDeletionFailure :exec
+UPDATE pending_deletions
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+WHERE id = $1;
+
+-- name: GetPendingDeletionBacklog :many
+SELECT reason,
+    COUNT(*) AS pending,
+    COUNT(*) FILTER (WHERE next_attempt_at <= now()) AS due,
+    COUNT(*) FILTER (WHERE attempts >= $1) AS stuck,
+    MIN(created_at)::timestamptz AS oldest_created_at
+FROM pending_deletions
+GROUP BY reason
+ORDER BY reason;
+
+-- name: ListStuckPendingDeletions :many
+SELECT * FROM pending_deletions
+WHERE attempts >= $1
+ORDER BY created_at
+LIMIT $2;
+
+-- =============================================================================
+-- H5P Content Upgrades
+-- =============================================================================
+
+-- name: ListOutdatedH5PContentLibraries :many
+SELECT l.machine_name, l.major_version, l.minor_version, COUNT(*) AS content_count
+FROM h5p_content c
+JOIN h5p_libraries l ON l.id = c.library_id
+WHERE c.org_id = $1 AND c.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM h5p_libraries n
+    WHERE n.machine_name = l.machine_name
+      AND (n.major_version, n.minor_version) > (l.major_version, l.minor_version)
+  )
+GROUP BY l.machine_name, l.major_version, l.minor_version
+ORDER BY l.machine_name, l.major_version, l.minor_version;
+
+-- name: ListOutdatedH5PContent :many
+SELECT c.id, c.title, c.content_json, c.library_id, c.locked_at,
+    l.major_version, l.minor_version, l.patch_version
+FROM h5p_content c
+JOIN h5p_libraries l ON l.id = c.library_id
+WHERE c.org_id = sqlc.arg(org_id) AND c.deleted_at IS NULL
+  AND l.machine_name = sqlc.arg(machine_name)
+  AND (l.major_version < sqlc.arg(major_version)
+    OR (l.major_version = sqlc.arg(major_version) AND l.minor_version < sqlc.arg(minor_version)))
+ORDER BY c.updated_at
+LIMIT sqlc.arg(max_results);
+
+-- name: UpgradeH5PContentLibrary :execrows
+UPDATE h5p_content SET library_id = $3, content_json = $4, updated_at = current_timestamp
+WHERE id = $1 AND org_id = $2 AND library_id = $5 AND deleted_at IS NULL AND locked_at IS NULL;
+
+-- =============================================================================
+-- Certificates
+-- =============================================================================
+
+-- name: GetCertificateTemplate :one
+SELECT * FROM certificate_templates WHERE organisation_id = $1;
+
+-- name: UpsertCertificateTemplate :one
+INSERT INTO certificate_templates (organisation_id, heading, fields, signatory_name, signatory_title)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (organisation_id) DO UPDATE SET
+    heading = EXCLUDED.heading,
+    fields = EXCLUDED.fields,
+    signatory_name = EXCLUDED.signatory_name,
+    signatory_title = EXCLUDED.signatory_title,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: SetCertificateTemplateBackground :exec
+WITH replaced AS (
+    INSERT INTO pending_deletions (storage_key, reason)
+    SELECT background_key, 'certificate'
+    FROM certificate_templates
+    WHERE organisation_id = $1 AND background_key IS NOT NULL AND background_key IS DISTINCT FROM $2
+    ON CONFLICT (storage_key) DO NOTHING
+)
+INSERT INTO certificate_templates (organisation_id, background_key)
+VALUES ($1, $2)
+ON CONFLICT (organisation_id) DO UPDATE SET background_key = EXCLUDED.background_key, updated_at = NOW();
+
+-- name: IssueCertificate :one
+INSERT INTO certificates (org_id, user_id, course_id, enrolment_id, learner_name, course_title, organisation_name, completed_at)
+SELECT e.org_id, e.user_id, e.course_id, e.id,
+    COALESCE(NULLIF(m.display_name, ''), u.email), c.title, o.name, COALESCE(e.completed_at, NOW())
+FROM enrolments e
+JOIN courses c ON c.id = e.course_id
+JOIN organisations o ON o.id = e.org_id
+JOIN users u ON u.id = e.user_id
+LEFT JOIN organisation_memberships m ON m.user_id = e.user_id AND m.organisation_id = e.org_id
+WHERE e.id = $1 AND e.status = 'completed'
+ON CONFLICT (enrolment_id) DO NOTHING
+RETURNING *;
+
+-- name: GetCertificate :one
+SELECT * FROM certificates WHERE id = $1;
+
+-- name: SetCertificateStorageKey :exec
+UPDATE certificates SET storage_key = $2 WHERE id = $1;
+
+-- name: RecordCertificateDownload :exec
+INSERT INTO certificate_downloads (certificate_id, user_id) VALUES ($1, $2);
+
+-- name: ListUserCertificates :many
+SELECT c.id, c.org_id, c.course_id, c.learner_name, c.course_title, c.organisation_name, c.completed_at, c.issued_at,
+    COUNT(d.id) AS downloads,
+    COALESCE(MAX(d.downloaded_at), c.issued_at)::timestamptz AS last_downloaded_at
+FROM certificates c
+LEFT JOIN certificate_downloads d ON d.certificate_id = c.id
+WHERE c.user_id = $1
+GROUP BY c.id
+ORDER BY c.issued_at DESC;
+
+-- =============================================================================
+-- H5P Library Storage
+-- =============================================================================
+
+-- name: ListH5PLibraryVersions :many
+SELECT id, machine_name, major_version, minor_version, patch_version, package_path
+FROM h5p_libraries
+WHERE sqlc.narg(machine_name)::text IS NULL OR machine_name = sqlc.narg(machine_name)::text
+ORDER BY machine_name, major_version, minor_version, patch_version;
+
+-- name: QueueOrphanedH5PLibraryFiles :execrows
+INSERT INTO pending_deletions (storage_key, reason, next_attempt_at)
+SELECT k.storage_key, 'orphan', sqlc.arg(next_attempt_at)::timestamptz
+FROM unnest(sqlc.arg(storage_keys)::text[]) AS k(storage_key)
+WHERE NOT EXISTS (
+    SELECT 1 FROM h5p_libraries l
+    WHERE k.storage_key = 'h5p-libraries/extracted/' || l.machine_name || '-' || l.major_version || '.' || l.minor_version || '.' || l.patch_version || '/'
+       OR k.storage_key = l.package_path
+)
+ON CONFLICT (storage_key) DO NOTHING;
+
+-- name: CancelPendingDeletions :exec
+DELETE FROM pending_deletions WHERE storage_key = ANY(sqlc.arg(storage_keys)::text[]);
+
+-- =============================================================================
+-- H5P Media Library
+-- =============================================================================
+
+-- name: CreateH5PMediaAsset :one
+INSERT INTO h5p_media_assets (
+    id, org_id, uploaded_by, filename, mime_type, kind, size_bytes, width, height, checksum, storage_key, thumbnail_key
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (org_id, checksum) DO UPDATE SET
+    use_count = h5p_media_assets.use_count + 1,
+    last_used_at = NOW()
+RETURNING *;
+
+-- name: GetH5PMediaAssetByChecksum :one
+SELECT * FROM h5p_media_assets WHERE org_id = $1 AND checksum = $2;
+
+-- name: GetH5PMediaAsset :one
+SELECT * FROM h5p_media_assets WHERE id = $1 AND org_id = $2;
+
+-- name: TouchH5PMediaAsset :exec
+UPDATE h5p_media_assets
+SET use_count = use_count + 1, last_used_at = NOW()
+WHERE id = $1 AND org_id = $2;
+
+-- name: ListH5PMediaAssets :many
+SELECT * FROM h5p_media_assets
+WHERE org_id = sqlc.arg(org_id)
+  AND (sqlc.narg(kind)::text IS NULL OR kind = sqlc.narg(kind)::text)
+  AND (sqlc.narg(search)::text IS NULL OR filename ILIKE '%' || sqlc.narg(search)::text || '%')
+  AND (sqlc.narg(before)::uuid IS NULL OR id < sqlc.narg(before)::uuid)
+ORDER BY id DESC
+LIMIT sqlc.arg(max_results);
+
+-- name: DeleteH5PMediaAsset :exec
+WITH deleted AS (
+    DELETE FROM h5p_media_assets m WHERE m.id = $1 AND m.org_id = $2
+    RETURNING m.storage_key, m.thumbnail_key
+)
+INSERT INTO pending_deletions (storage_key, reason)
+SELECT storage_key, 'media' FROM deleted
+UNION ALL
+SELECT thumbnail_key, 'media' FROM deleted WHERE thumbnail_key IS NOT NULL
+ON CONFLICT (storage_key) DO NOTHING;
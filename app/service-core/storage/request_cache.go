@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+type requestCacheKey struct{}
+
+// requestCache holds the reads memoized for one request, keyed by query and
+// arguments.
+type requestCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedRead
+}
+
+type cachedRead struct {
+	value any
+	err   error
+}
+
+// WithRequestCache returns a context in which reads through a CachedQuerier
+// are memoized. It's made once per HTTP request, so nothing outlives the
+// request it was read for.
+func WithRequestCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestCacheKey{}, &requestCache{entries: make(map[string]cachedRead)})
+}
+
+// CachedQuerier memoizes organisation billing and membership lookups, which
+// one request often makes several times over (access checks, entitlements,
+// billing sync). Writes to the same data through it drop the copies, so a
+// request reads its own changes. Without a request cache in the context, as
+// in background jobs, every read goes to the database.
+type CachedQuerier struct {
+	query.Querier
+}
+
+// NewCachedQuerier wraps q with request-scoped memoization.
+func NewCachedQuerier(q query.Querier) *CachedQuerier {
+	return &CachedQuerier{Querier: q}
+}
+
+// Queries returns the store for the connection, with request-scoped
+// memoization.
+func (s *Storage) Queries() query.Querier {
+	return NewCachedQuerier(query.New(s.Conn))
+}
+
+func (q *CachedQuerier) GetOrganisationBillingInfo(ctx context.Context, id uuid.UUID) (query.GetOrganisationBillingInfoRow, error) {
+	return cached(ctx, billingKey(id), func() (query.GetOrganisationBillingInfoRow, error) {
+		return q.Querier.GetOrganisationBillingInfo(ctx, id)
+	})
+}
+
+func (q *CachedQuerier) CheckUserOrgMembership(ctx context.Context, arg query.CheckUserOrgMembershipParams) (uuid.UUID, error) {
+	return cached(ctx, membershipKey(arg.UserID)+"member:"+arg.OrganisationID.String(), func() (uuid.UUID, error) {
+		return q.Querier.CheckUserOrgMembership(ctx, arg)
+	})
+}
+
+func (q *CachedQuerier) GetOrgMembershipRole(ctx context.Context, arg query.GetOrgMembershipRoleParams) (string, error) {
+	return cached(ctx, membershipKey(arg.UserID)+"role:"+arg.OrganisationID.String(), func() (string, error) {
+		return q.Querier.GetOrgMembershipRole(ctx, arg)
+	})
+}
+
+func (q *CachedQuerier) UpdateOrganisationStripeCustomer(ctx context.Context, arg query.UpdateOrganisationStripeCustomerParams) error {
+	defer invalidate(ctx, billingKey(arg.ID))
+	return q.Querier.UpdateOrganisationStripeCustomer(ctx, arg)
+}
+
+func (q *CachedQuerier) UpdateOrganisationSubscription(ctx context.Context, arg query.UpdateOrganisationSubscriptionParams) error {
+	defer invalidate(ctx, billingKey(arg.ID))
+	return q.Querier.UpdateOrganisationSubscription(ctx, arg)
+}
+
+func (q *CachedQuerier) DowngradeOrganisationToFree(ctx context.Context, id uuid.UUID) error {
+	defer invalidate(ctx, billingKey(id))
+	return q.Querier.DowngradeOrganisationToFree(ctx, id)
+}
+
+func (q *CachedQuerier) AcceptPendingMemberships(ctx context.Context, userID uuid.UUID) error {
+	defer invalidate(ctx, membershipKey(userID))
+	return q.Querier.AcceptPendingMemberships(ctx, userID)
+}
+
+func billingKey(orgID uuid.UUID) string {
+	return "billing:" + orgID.String()
+}
+
+// membershipKey prefixes the keys of a user's membership reads, so they're
+// dropped together.
+func membershipKey(userID uuid.UUID) string {
+	return "membership:" + userID.String() + ":"
+}
+
+// cached returns the request's copy of the read named key, making the read
+// the first time. Not-found results are kept too, since access checks repeat
+// them; other errors aren't.
+func cached[T any](ctx context.Context, key string, read func() (T, error)) (T, error) {
+	c, _ := ctx.Value(requestCacheKey{}).(*requestCache)
+	if c == nil {
+		return read()
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return entry.value.(T), entry.err
+	}
+
+	value, err := read()
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		c.mu.Lock()
+		c.entries[key] = cachedRead{value: value, err: err}
+		c.mu.Unlock()
+	}
+	return value, err
+}
+
+// invalidate drops the request's reads whose keys start with prefix.
+func invalidate(ctx context.Context, prefix string) {
+	c, _ := ctx.Value(requestCacheKey{}).(*requestCache)
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
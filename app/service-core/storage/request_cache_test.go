@@ -0,0 +1,87 @@
+package storage_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"service-core/storage"
+	"service-core/storage/query"
+)
+
+// countingStore counts the reads that reach the database.
+type countingStore struct {
+	query.Querier
+	billingReads int
+	memberReads  int
+	tier         string
+}
+
+func (s *countingStore) GetOrganisationBillingInfo(ctx context.Context, id uuid.UUID) (query.GetOrganisationBillingInfoRow, error) {
+	s.billingReads++
+	return query.GetOrganisationBillingInfoRow{ID: id, SubscriptionTier: s.tier}, nil
+}
+
+func (s *countingStore) UpdateOrganisationSubscription(ctx context.Context, arg query.UpdateOrganisationSubscriptionParams) error {
+	s.tier = arg.SubscriptionTier
+	return nil
+}
+
+func (s *countingStore) CheckUserOrgMembership(ctx context.Context, arg query.CheckUserOrgMembershipParams) (uuid.UUID, error) {
+	s.memberReads++
+	return uuid.Nil, sql.ErrNoRows
+}
+
+func TestCachedQuerierMemoizesWithinRequest(t *testing.T) {
+	db := &countingStore{tier: "free"}
+	store := storage.NewCachedQuerier(db)
+	ctx := storage.WithRequestCache(context.Background())
+	orgID := uuid.New()
+
+	for i := 0; i < 3; i++ {
+		info, err := store.GetOrganisationBillingInfo(ctx, orgID)
+		require.NoError(t, err)
+		assert.Equal(t, "free", info.SubscriptionTier)
+	}
+	assert.Equal(t, 1, db.billingReads)
+
+	// Not-found membership checks are kept too
+	arg := query.CheckUserOrgMembershipParams{UserID: uuid.New(), OrganisationID: orgID}
+	for i := 0; i < 2; i++ {
+		_, err := store.CheckUserOrgMembership(ctx, arg)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	}
+	assert.Equal(t, 1, db.memberReads)
+}
+
+func TestCachedQuerierInvalidatesOnWrite(t *testing.T) {
+	db := &countingStore{tier: "free"}
+	store := storage.NewCachedQuerier(db)
+	ctx := storage.WithRequestCache(context.Background())
+	orgID := uuid.New()
+
+	_, err := store.GetOrganisationBillingInfo(ctx, orgID)
+	require.NoError(t, err)
+	require.NoError(t, store.UpdateOrganisationSubscription(ctx, query.UpdateOrganisationSubscriptionParams{ID: orgID, SubscriptionTier: "pro"}))
+
+	info, err := store.GetOrganisationBillingInfo(ctx, orgID)
+	require.NoError(t, err)
+	assert.Equal(t, "pro", info.SubscriptionTier)
+	assert.Equal(t, 2, db.billingReads)
+}
+
+func TestCachedQuerierWithoutRequestCache(t *testing.T) {
+	db := &countingStore{tier: "free"}
+	store := storage.NewCachedQuerier(db)
+	orgID := uuid.New()
+
+	for i := 0; i < 2; i++ {
+		_, err := store.GetOrganisationBillingInfo(context.Background(), orgID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, db.billingReads)
+}
@@ -50,7 +50,7 @@ create table if not exists users (
 -- =============================================================================
 
 create table if not exists organisations (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
     name text not null,
@@ -78,11 +78,13 @@ create table if not exists organisations (
     freemium_granted_by varchar(255),
     deleted_at timestamptz,
     deletion_scheduled_for timestamptz,
+    locale text not null default 'en-AU',
+    timezone text not null default 'UTC',
     constraint valid_organisation_status check (status in ('active', 'suspended', 'cancelled'))
 );
 
 create table if not exists organisation_memberships (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
     user_id uuid not null references users(id) on delete cascade,
@@ -98,12 +100,27 @@ create table if not exists organisation_memberships (
     constraint valid_membership_status check (status in ('active', 'invited', 'suspended'))
 );
 
+create table if not exists organisation_activity_log (
+    id uuid primary key not null default uuid_generate_v7(),
+    created_at timestamptz not null default current_timestamp,
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    user_id uuid references users(id) on delete set null,
+    action varchar(100) not null,
+    entity_type varchar(50) not null,
+    entity_id uuid,
+    old_values jsonb,
+    new_values jsonb,
+    ip_address text,
+    user_agent text,
+    metadata jsonb not null default '{}'
+);
+
 -- =============================================================================
 -- H5P LIBRARIES (Platform-wide — Go Hub API serves these)
 -- =============================================================================
 
 create table if not exists h5p_libraries (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
     machine_name varchar(255) not null,
@@ -127,7 +144,7 @@ create table if not exists h5p_libraries (
 );
 
 create table if not exists h5p_library_dependencies (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     library_id uuid not null references h5p_libraries(id) on delete cascade,
     depends_on_id uuid not null references h5p_libraries(id) on delete cascade,
     dependency_type varchar(20) not null,
@@ -135,8 +152,14 @@ create table if not exists h5p_library_dependencies (
     constraint valid_dependency_type check (dependency_type in ('preloaded', 'dynamic', 'editor'))
 );
 
+create table if not exists h5p_library_semantics_cache (
+    library_id uuid primary key not null references h5p_libraries(id) on delete cascade,
+    created_at timestamptz not null default current_timestamp,
+    semantics jsonb not null default '[]'
+);
+
 create table if not exists h5p_org_libraries (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     org_id uuid not null references organisations(id) on delete cascade,
     library_id uuid not null references h5p_libraries(id) on delete cascade,
@@ -150,7 +173,7 @@ create table if not exists h5p_org_libraries (
 -- =============================================================================
 
 create table if not exists h5p_content (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
     org_id uuid not null references organisations(id) on delete cascade,
@@ -165,12 +188,14 @@ create table if not exists h5p_content (
     storage_path text,
     status varchar(20) not null default 'draft',
     deleted_at timestamptz,
+    locked_at timestamptz,
+    lock_reason varchar(40),
     unique (org_id, slug),
     constraint valid_content_status check (status in ('draft', 'published', 'archived'))
 );
 
 create table if not exists h5p_content_folders (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
     org_id uuid not null references organisations(id) on delete cascade,
@@ -184,7 +209,7 @@ create table if not exists h5p_content_folders (
 -- =============================================================================
 
 create table if not exists h5p_hub_registrations (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
     org_id uuid not null unique references organisations(id) on delete cascade,
@@ -194,7 +219,7 @@ create table if not exists h5p_hub_registrations (
 );
 
 create table if not exists h5p_hub_cache (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     cache_key text not null unique,
     data jsonb not null default '{}',
@@ -206,7 +231,7 @@ create table if not exists h5p_hub_cache (
 -- =============================================================================
 
 create table if not exists courses (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
     org_id uuid not null references organisations(id) on delete cascade,
@@ -219,12 +244,14 @@ create table if not exists courses (
     published_at timestamptz,
     archived_at timestamptz,
     deleted_at timestamptz,
+    locked_at timestamptz,
+    lock_reason varchar(40),
     unique (org_id, slug),
     constraint valid_course_status check (status in ('draft', 'published', 'archived'))
 );
 
 create table if not exists course_items (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
     course_id uuid not null references courses(id) on delete cascade,
@@ -242,12 +269,13 @@ create table if not exists course_items (
 -- =============================================================================
 
 create table if not exists enrolments (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
     org_id uuid not null references organisations(id) on delete cascade,
     course_id uuid not null references courses(id) on delete cascade,
-    user_id uuid not null references users(id) on delete cascade,
+    user_id uuid references users(id) on delete cascade,
+    actor_id uuid,
     status varchar(20) not null default 'active',
     enrolled_at timestamptz not null default current_timestamp,
     completed_at timestamptz,
@@ -256,13 +284,14 @@ create table if not exists enrolments (
 );
 
 create table if not exists progress_records (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
     org_id uuid not null references organisations(id) on delete cascade,
     enrolment_id uuid not null references enrolments(id) on delete cascade,
     content_id uuid not null references h5p_content(id) on delete cascade,
-    user_id uuid not null references users(id) on delete cascade,
+    user_id uuid references users(id) on delete cascade,
+    actor_id uuid,
     score numeric(5,2),
     max_score numeric(5,2),
     completion numeric(5,4) not null default 0,
@@ -273,13 +302,23 @@ create table if not exists progress_records (
 );
 
 create table if not exists xapi_statements (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     created_at timestamptz not null default current_timestamp,
     org_id uuid not null references organisations(id) on delete cascade,
-    user_id uuid not null references users(id) on delete cascade,
+    user_id uuid references users(id) on delete cascade,
+    actor_id uuid,
     content_id uuid references h5p_content(id) on delete set null,
     verb varchar(255) not null,
-    statement jsonb not null
+    statement jsonb not null,
+    statement_id uuid,
+    verb_id text,
+    object_id text,
+    result_score_raw double precision,
+    result_score_max double precision,
+    result_score_scaled double precision,
+    result_success boolean,
+    result_completion boolean,
+    result_duration integer
 );
 
 -- =============================================================================
@@ -287,7 +326,7 @@ create table if not exists xapi_statements (
 -- =============================================================================
 
 create table if not exists h5p_content_user_state (
-    id uuid primary key not null default gen_random_uuid(),
+    id uuid primary key not null default uuid_generate_v7(),
     user_id uuid not null references users(id) on delete cascade,
     content_id uuid not null references h5p_content(id) on delete cascade,
     sub_content_id text not null default '0',
@@ -297,3 +336,599 @@ create table if not exists h5p_content_user_state (
     updated_at timestamptz not null default now(),
     unique(user_id, content_id, sub_content_id, data_type)
 );
+
+-- =============================================================================
+-- ORGANISATION WEBHOOKS (Domain event relay)
+-- =============================================================================
+
+create table if not exists organisation_webhooks (
+    id uuid primary key not null default uuid_generate_v7(),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    url text not null,
+    secret text not null,
+    events text[] not null default '{}',
+    active boolean not null default true,
+    last_delivery_at timestamptz,
+    last_delivery_status integer
+);
+
+create table if not exists webhook_deliveries (
+    id uuid primary key not null default uuid_generate_v7(),
+    webhook_id uuid not null references organisation_webhooks(id) on delete cascade,
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    event_id uuid not null,
+    event_type text not null,
+    payload jsonb not null,
+    status text not null default 'pending' check (status in ('pending', 'delivered', 'failed')),
+    attempts integer not null default 0,
+    response_status integer,
+    error text not null default '',
+    created_at timestamptz not null default now(),
+    last_attempt_at timestamptz,
+    next_attempt_at timestamptz not null default now(),
+    delivered_at timestamptz
+);
+
+-- =============================================================================
+-- ORGANISATION PAYMENT METHODS (Card expiry reminders)
+-- =============================================================================
+
+create table if not exists organisation_payment_methods (
+    organisation_id uuid primary key not null references organisations(id) on delete cascade,
+    payment_method_id text not null,
+    brand text not null default '',
+    last4 text not null default '',
+    exp_month integer not null,
+    exp_year integer not null,
+    expires_at timestamptz not null,
+    last_reminder_days integer,
+    updated_at timestamptz not null default now()
+);
+
+-- =============================================================================
+-- ORGANISATION BILLING CONTACTS (Invoice recipient & invoice settings)
+-- =============================================================================
+
+create table if not exists organisation_billing_contacts (
+    organisation_id uuid primary key not null references organisations(id) on delete cascade,
+    name text not null default '',
+    email text not null,
+    po_number text not null default '',
+    address_line1 text not null default '',
+    address_line2 text not null default '',
+    city text not null default '',
+    state text not null default '',
+    postal_code text not null default '',
+    country text not null default '',
+    invoice_memo text not null default '',
+    invoice_custom_fields jsonb not null default '[]',
+    updated_at timestamptz not null default now()
+);
+
+-- =============================================================================
+-- ORGANISATION STORAGE USAGE (Tier storage quotas)
+-- =============================================================================
+
+create table if not exists organisation_storage_usage (
+    organisation_id uuid primary key not null references organisations(id) on delete cascade,
+    bytes_used bigint not null default 0,
+    updated_at timestamptz not null default now()
+);
+
+-- =============================================================================
+-- JOB LOCKS (Singleton scheduled jobs across replicas)
+-- =============================================================================
+
+create table if not exists job_locks (
+    name text primary key not null,
+    holder text not null,
+    acquired_at timestamptz not null default now(),
+    expires_at timestamptz not null
+);
+
+-- =============================================================================
+-- ACCESS REVIEWS (SOC 2 access review reports)
+-- =============================================================================
+
+create table if not exists access_reviews (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    requested_by uuid references users(id) on delete set null,
+    status varchar(20) not null default 'pending',
+    created_at timestamptz not null default now(),
+    completed_at timestamptz,
+    expires_at timestamptz,
+    error text not null default '',
+    constraint valid_access_review_status check (status in ('pending', 'ready', 'failed'))
+);
+
+-- =============================================================================
+-- ANNOUNCEMENTS (in-product changelog and notices)
+-- =============================================================================
+
+create table if not exists announcements (
+    id uuid primary key not null default uuid_generate_v7(),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    title text not null,
+    body text not null default '',
+    link_url text not null default '',
+    tiers text[] not null default '{}',
+    flags text[] not null default '{}',
+    published_at timestamptz,
+    expires_at timestamptz,
+    created_by uuid references users(id) on delete set null
+);
+
+create table if not exists announcement_reads (
+    announcement_id uuid not null references announcements(id) on delete cascade,
+    user_id uuid not null references users(id) on delete cascade,
+    read_at timestamptz not null default now(),
+    primary key (announcement_id, user_id)
+);
+
+-- =============================================================================
+-- SEO RANK CHECKS (ad-hoc SERP position lookups)
+-- =============================================================================
+
+create table if not exists seo_rank_checks (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    created_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    completed_at timestamptz,
+    domain text not null,
+    location_code integer not null,
+    language_code text not null,
+    device text not null default 'desktop',
+    keyword_count integer not null,
+    status text not null default 'pending' check (status in ('pending', 'completed', 'failed')),
+    cost double precision not null default 0,
+    error text not null default '',
+    results jsonb not null default '[]'
+);
+
+-- =============================================================================
+-- SEO PAGE EXPERIENCE (scored page experience signals, per page per audit)
+-- =============================================================================
+
+create table if not exists seo_page_experience (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    audit_id uuid not null,
+    page_url text not null,
+    strategy text not null default 'mobile' check (strategy in ('mobile', 'desktop')),
+    created_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    completed_at timestamptz,
+    status text not null default 'pending' check (status in ('pending', 'completed', 'failed')),
+    error text not null default '',
+    intrusive_interstitial boolean,
+    score integer not null default 0,
+    passed boolean not null default false,
+    cwv_source text not null default '',
+    lcp_ms double precision,
+    inp_ms double precision,
+    cls double precision,
+    signals jsonb not null default '[]',
+    pagespeed jsonb not null default '{}',
+    issues jsonb not null default '[]',
+    rechecked_at timestamptz,
+    unique (organisation_id, audit_id, page_url, strategy)
+);
+
+create table if not exists seo_raw_artifacts (
+    page_id uuid not null references seo_page_experience(id) on delete cascade,
+    section text not null,
+    object_key text not null,
+    size_bytes bigint not null,
+    created_at timestamptz not null default now(),
+    primary key (page_id, section)
+);
+
+-- =============================================================================
+-- SEO KEYWORD LISTS (saved keywords with monthly search volume refreshes)
+-- =============================================================================
+
+create table if not exists seo_keyword_lists (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    name text not null,
+    location_code integer not null,
+    language_code text not null,
+    created_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    last_refreshed_at timestamptz,
+    next_refresh_at timestamptz not null default now()
+);
+
+create table if not exists seo_keywords (
+    list_id uuid not null references seo_keyword_lists(id) on delete cascade,
+    keyword text not null,
+    search_volume integer,
+    cpc double precision,
+    competition text not null default '',
+    monthly_searches jsonb not null default '[]',
+    refreshed_at timestamptz,
+    primary key (list_id, keyword)
+);
+
+create table if not exists seo_keyword_refreshes (
+    id uuid primary key not null default uuid_generate_v7(),
+    list_id uuid not null references seo_keyword_lists(id) on delete cascade,
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    created_at timestamptz not null default now(),
+    status text not null check (status in ('completed', 'partial', 'failed')),
+    keyword_count integer not null,
+    updated_count integer not null,
+    cost double precision not null default 0,
+    error text not null default ''
+);
+
+-- =============================================================================
+-- SEO SCHEDULES (recurring audits and rank checks per registered domain)
+-- =============================================================================
+
+create table if not exists seo_schedules (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    domain text not null,
+    pages text[] not null default '{}',
+    keywords text[] not null default '{}',
+    location_code integer not null,
+    language_code text not null,
+    device text not null default 'desktop' check (device in ('desktop', 'mobile')),
+    strategy text not null default 'mobile' check (strategy in ('mobile', 'desktop')),
+    created_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    last_run_at timestamptz,
+    next_run_at timestamptz not null default now(),
+    unique (organisation_id, domain)
+);
+
+create table if not exists seo_schedule_runs (
+    id uuid primary key not null default uuid_generate_v7(),
+    schedule_id uuid not null references seo_schedules(id) on delete cascade,
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    created_at timestamptz not null default now(),
+    completed_at timestamptz,
+    status text not null default 'running' check (status in ('running', 'completed', 'partial', 'failed')),
+    audit_id uuid,
+    rank_check_id uuid references seo_rank_checks(id) on delete set null,
+    summary jsonb not null default '{}',
+    deltas jsonb not null default '{}',
+    error text not null default ''
+);
+
+create table if not exists seo_backlink_prospects (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    target text not null,
+    domain text not null,
+    status text not null default 'identified' check (status in ('identified', 'contacted', 'linked')),
+    source text not null check (source in ('manual', 'csv', 'competitor_gap')),
+    competitors text[] not null default '{}',
+    contact text not null default '',
+    notes text not null default '',
+    rank integer,
+    backlinks bigint,
+    referring_domains integer,
+    spam_score integer,
+    enriched_at timestamptz,
+    created_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    contacted_at timestamptz,
+    linked_at timestamptz,
+    unique (organisation_id, target, domain)
+);
+
+create table if not exists rank_trackers (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    domain text not null,
+    location_code integer not null,
+    language_code text not null,
+    device text not null default 'desktop' check (device in ('desktop', 'mobile')),
+    competitors text[] not null default '{}',
+    created_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    last_run_at timestamptz,
+    next_run_at timestamptz not null default now(),
+    volumes_refreshed_at timestamptz,
+    unique (organisation_id, domain, location_code, language_code, device)
+);
+
+create table if not exists rank_tracker_keywords (
+    tracker_id uuid not null references rank_trackers(id) on delete cascade,
+    keyword text not null,
+    search_volume integer,
+    created_at timestamptz not null default now(),
+    primary key (tracker_id, keyword)
+);
+
+create table if not exists rank_tracker_runs (
+    id uuid primary key not null default uuid_generate_v7(),
+    tracker_id uuid not null references rank_trackers(id) on delete cascade,
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    created_at timestamptz not null default now(),
+    completed_at timestamptz,
+    status text not null default 'running' check (status in ('running', 'completed', 'partial', 'failed')),
+    keyword_count integer not null,
+    failed_count integer not null default 0,
+    cost double precision not null default 0,
+    share_of_voice jsonb not null default '{}',
+    error text not null default ''
+);
+
+create table if not exists rank_positions (
+    run_id uuid not null references rank_tracker_runs(id) on delete cascade,
+    tracker_id uuid not null references rank_trackers(id) on delete cascade,
+    keyword text not null,
+    domain text not null,
+    position integer,
+    url text not null default '',
+    source text not null check (source in ('serp', 'labs')),
+    checked_at timestamptz not null default now(),
+    primary key (run_id, keyword, domain)
+);
+
+create table if not exists competitor_pins (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    target text not null,
+    domain text not null,
+    location_code integer not null,
+    language_code text not null,
+    created_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    refreshed_at timestamptz,
+    next_refresh_at timestamptz not null default now(),
+    unique (organisation_id, target, domain, location_code, language_code)
+);
+
+create table if not exists competitor_snapshots (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    target text not null,
+    domain text not null,
+    location_code integer not null,
+    language_code text not null,
+    captured_at timestamptz not null default now(),
+    organic_etv double precision,
+    organic_keywords integer,
+    top3_keywords integer,
+    top10_keywords integer,
+    domain_rank integer,
+    backlinks bigint,
+    referring_domains integer
+);
+
+create table if not exists competitor_keyword_gaps (
+    pin_id uuid not null references competitor_pins(id) on delete cascade,
+    keyword text not null,
+    search_volume integer,
+    position integer not null,
+    url text not null default '',
+    is_new boolean not null default false,
+    first_seen_at timestamptz not null default now(),
+    last_seen_at timestamptz not null default now(),
+    primary key (pin_id, keyword)
+);
+
+create table if not exists competitor_alerts (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    pin_id uuid not null references competitor_pins(id) on delete cascade,
+    kind text not null check (kind in ('keyword_gap', 'backlink_gain')),
+    message text not null,
+    keywords text[] not null default '{}',
+    amount integer not null default 0,
+    created_at timestamptz not null default now()
+);
+
+-- =============================================================================
+-- ORGANISATION DELETIONS (recovery window, then staged teardown)
+-- =============================================================================
+
+create table if not exists organisation_deletions (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null,
+    organisation_name text not null,
+    organisation_slug text not null,
+    requested_by uuid references users(id) on delete set null,
+    requested_at timestamptz not null default now(),
+    scheduled_for timestamptz not null,
+    status text not null default 'scheduled' check (status in ('scheduled', 'running', 'failed', 'completed', 'cancelled')),
+    stage text not null default '',
+    progress jsonb not null default '{}',
+    attempts integer not null default 0,
+    error text not null default '',
+    confirmed_by uuid references users(id) on delete set null,
+    cancelled_by uuid references users(id) on delete set null,
+    cancelled_at timestamptz,
+    started_at timestamptz,
+    completed_at timestamptz
+);
+
+create table if not exists jobs (
+    id uuid primary key not null default uuid_generate_v7(),
+    kind text not null,
+    payload jsonb not null default '{}',
+    status text not null default 'pending' check (status in ('pending', 'running', 'completed', 'dead')),
+    attempts integer not null default 0,
+    max_attempts integer not null default 5,
+    last_error text not null default '',
+    run_at timestamptz not null default now(),
+    locked_until timestamptz,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    completed_at timestamptz
+);
+
+-- =============================================================================
+-- RETENTION POLICIES (per-organisation records retention)
+-- =============================================================================
+
+create table if not exists organisation_retention_policies (
+    organisation_id uuid primary key not null references organisations(id) on delete cascade,
+    archive_drafts_after_months integer check (archive_drafts_after_months > 0),
+    purge_deleted_after_days integer check (purge_deleted_after_days > 0),
+    retain_xapi_years integer check (retain_xapi_years > 0),
+    updated_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    evaluated_at timestamptz
+);
+
+-- =============================================================================
+-- H5P INSTALL PROFILES (library bundles enabled for new organisations)
+-- =============================================================================
+
+create table if not exists h5p_install_profiles (
+    id uuid primary key not null default uuid_generate_v7(),
+    name text not null unique,
+    description text not null default '',
+    libraries text[] not null default '{}',
+    is_default boolean not null default false,
+    created_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create table if not exists h5p_profile_applications (
+    id uuid primary key not null default uuid_generate_v7(),
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    profile_id uuid references h5p_install_profiles(id) on delete set null,
+    profile_name text not null,
+    libraries text[] not null,
+    status text not null default 'pending' check (status in ('pending', 'completed', 'failed')),
+    summary jsonb not null default '{}',
+    requested_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    completed_at timestamptz
+);
+
+create table if not exists h5p_bundle_variants (
+    name text primary key not null check (name ~ '^[a-z0-9][a-z0-9-]{0,31}$' and name <> 'stable'),
+    description text not null default '',
+    asset_base text not null,
+    rollout_percent integer not null default 0 check (rollout_percent between 0 and 100),
+    created_by uuid references users(id) on delete set null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create table if not exists h5p_bundle_reports (
+    variant text not null,
+    day date not null,
+    loads bigint not null default 0,
+    errors bigint not null default 0,
+    primary key (variant, day)
+);
+
+create table if not exists content_extract_cache (
+    url text primary key not null,
+    final_url text not null,
+    title text not null default '',
+    markdown text not null,
+    links jsonb not null default '[]',
+    provider text not null,
+    fetched_at timestamptz not null default now(),
+    expires_at timestamptz not null
+);
+
+create table if not exists notification_preferences (
+    user_id uuid not null references users(id) on delete cascade,
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    category text not null check (category in ('content', 'seo', 'billing')),
+    channel text not null check (channel in ('email', 'in_app')),
+    enabled boolean not null,
+    digest boolean not null default false check (not digest or channel = 'email'),
+    updated_at timestamptz not null default now(),
+    primary key (user_id, organisation_id, category, channel)
+);
+
+create table if not exists notification_digest_items (
+    id uuid primary key not null default uuid_generate_v7(),
+    user_id uuid not null references users(id) on delete cascade,
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    category text not null,
+    event_type text not null,
+    summary text not null,
+    created_at timestamptz not null default now()
+);
+
+create table if not exists user_notifications (
+    id uuid primary key not null default uuid_generate_v7(),
+    user_id uuid not null references users(id) on delete cascade,
+    organisation_id uuid not null references organisations(id) on delete cascade,
+    category text not null,
+    event_type text not null,
+    summary text not null,
+    data jsonb not null default '{}',
+    created_at timestamptz not null default now(),
+    read_at timestamptz
+);
+
+create table if not exists pending_deletions (
+    id uuid primary key not null default uuid_generate_v7(),
+    storage_key text not null unique,
+    reason text not null,
+    attempts integer not null default 0,
+    last_error text not null default '',
+    next_attempt_at timestamptz not null default now(),
+    created_at timestamptz not null default now()
+);
+
+create table if not exists certificate_templates (
+    organisation_id uuid primary key references organisations(id) on delete cascade,
+    heading text not null default 'Certificate of Completion',
+    fields jsonb not null default '["learner", "course", "completed_at", "organisation", "certificate_id"]',
+    signatory_name text not null default '',
+    signatory_title text not null default '',
+    background_key text,
+    updated_at timestamptz not null default now()
+);
+
+create table if not exists certificates (
+    id uuid primary key not null default uuid_generate_v7(),
+    org_id uuid not null references organisations(id) on delete cascade,
+    user_id uuid not null references users(id) on delete cascade,
+    course_id uuid references courses(id) on delete set null,
+    enrolment_id uuid unique references enrolments(id) on delete set null,
+    learner_name text not null,
+    course_title text not null,
+    organisation_name text not null,
+    completed_at timestamptz not null,
+    issued_at timestamptz not null default now(),
+    storage_key text
+);
+
+create table if not exists certificate_downloads (
+    id uuid primary key not null default uuid_generate_v7(),
+    certificate_id uuid not null references certificates(id) on delete cascade,
+    user_id uuid not null references users(id) on delete cascade,
+    downloaded_at timestamptz not null default now()
+);
+
+create table if not exists h5p_media_assets (
+    id uuid primary key not null default uuid_generate_v7(),
+    org_id uuid not null references organisations(id) on delete cascade,
+    uploaded_by uuid references users(id) on delete set null,
+    filename text not null,
+    mime_type text not null,
+    kind text not null,
+    size_bytes bigint not null,
+    width integer,
+    height integer,
+    checksum text not null,
+    storage_key text not null,
+    thumbnail_key text,
+    use_count integer not null default 1,
+    created_at timestamptz not null default now(),
+    last_used_at timestamptz not null default now(),
+    unique (org_id, checksum)
+);
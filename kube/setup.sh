@@ -77,7 +77,9 @@ kubectl create secret generic regcred \
 
 echo "Creating a CronJob secret..."
 kubectl create secret generic api-secrets \
-  --from-literal=task-token=$TASK_TOKEN
+  --from-literal=task-token=$TASK_TOKEN \
+  --from-literal=anonymization-key=$ANONYMIZATION_KEY \
+  --from-literal=signed-url-key=$SIGNED_URL_KEY
 
 # Uncomment if using Google Cloud SQL
 # echo "Creating a PostgreSQL secret..."
@@ -0,0 +1,22 @@
+-- =============================================================================
+-- 011: Organisation Webhooks
+-- =============================================================================
+-- Endpoints registered by an organisation to receive domain events (e.g.
+-- billing.tier_changed). Payloads are signed with the per-webhook secret.
+-- An empty events array subscribes the webhook to all events.
+
+CREATE TABLE IF NOT EXISTS organisation_webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT true,
+    last_delivery_at TIMESTAMPTZ,
+    last_delivery_status INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS organisation_webhooks_org_idx
+    ON organisation_webhooks(organisation_id);
@@ -0,0 +1,22 @@
+-- =============================================================================
+-- 012: Organisation Payment Methods
+-- =============================================================================
+-- Expiry details of each organisation's default Stripe payment method, kept in
+-- sync from billing webhooks so card expiry reminders can be sent before a
+-- renewal fails. last_reminder_days records the most recent reminder stage
+-- (30 or 7 days before expiry) and resets when the card changes.
+
+CREATE TABLE IF NOT EXISTS organisation_payment_methods (
+    organisation_id UUID PRIMARY KEY REFERENCES organisations(id) ON DELETE CASCADE,
+    payment_method_id TEXT NOT NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    last4 TEXT NOT NULL DEFAULT '',
+    exp_month INTEGER NOT NULL,
+    exp_year INTEGER NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_reminder_days INTEGER,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS organisation_payment_methods_expires_idx
+    ON organisation_payment_methods(expires_at);
@@ -0,0 +1,23 @@
+-- =============================================================================
+-- 013: Organisation Billing Contacts
+-- =============================================================================
+-- Who receives invoices and billing emails for an organisation, and what is
+-- printed on its invoices. Synced to the Stripe customer (email, name, address,
+-- invoice custom fields and footer) whenever it changes. When no row exists,
+-- billing emails fall back to the organisation email, then the owner.
+
+CREATE TABLE IF NOT EXISTS organisation_billing_contacts (
+    organisation_id UUID PRIMARY KEY REFERENCES organisations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL,
+    po_number TEXT NOT NULL DEFAULT '',
+    address_line1 TEXT NOT NULL DEFAULT '',
+    address_line2 TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    state TEXT NOT NULL DEFAULT '',
+    postal_code TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT '',
+    invoice_memo TEXT NOT NULL DEFAULT '',
+    invoice_custom_fields JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,16 @@
+-- =============================================================================
+-- 014: Grace-Period Content Locks
+-- =============================================================================
+-- When a subscription lapses to the free tier, courses and H5P content above
+-- the free tier limits become read-only instead of disappearing. locked_at is
+-- set while locked; lock_reason is a code the client uses to explain the lock
+-- and upsell. Both are cleared when the organisation upgrades again.
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS locked_at TIMESTAMPTZ;
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS lock_reason VARCHAR(40);
+
+ALTER TABLE h5p_content ADD COLUMN IF NOT EXISTS locked_at TIMESTAMPTZ;
+ALTER TABLE h5p_content ADD COLUMN IF NOT EXISTS lock_reason VARCHAR(40);
+
+CREATE INDEX IF NOT EXISTS idx_courses_locked ON courses(org_id) WHERE locked_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_h5p_content_locked ON h5p_content(org_id) WHERE locked_at IS NOT NULL;
@@ -0,0 +1,12 @@
+-- =============================================================================
+-- 015: H5P Library Semantics Cache
+-- =============================================================================
+-- semantics.json is cached per library at install time so the editor schema
+-- endpoint can serve it without a round-trip to R2 on every request.
+
+CREATE TABLE IF NOT EXISTS h5p_library_semantics_cache (
+    library_id  UUID PRIMARY KEY NOT NULL REFERENCES h5p_libraries(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+
+    semantics   JSONB NOT NULL DEFAULT '[]'
+);
@@ -0,0 +1,12 @@
+-- =============================================================================
+-- 016: Organisation Storage Usage
+-- =============================================================================
+-- Running total of bytes each organisation has uploaded: editor temp files and
+-- H5P packages installed from the editor. Uploads that would take the total
+-- over the tier's storage quota are rejected.
+
+CREATE TABLE IF NOT EXISTS organisation_storage_usage (
+    organisation_id UUID PRIMARY KEY NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    bytes_used BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
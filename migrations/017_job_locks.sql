@@ -0,0 +1,13 @@
+-- =============================================================================
+-- 017: Job Locks
+-- =============================================================================
+-- Leases that keep scheduled jobs (token cleanup, card expiry reminders, ...)
+-- to a single replica. A lock is held until released or until expires_at, so a
+-- crashed holder can't block the job forever.
+
+CREATE TABLE IF NOT EXISTS job_locks (
+    name TEXT PRIMARY KEY NOT NULL,
+    holder TEXT NOT NULL,
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
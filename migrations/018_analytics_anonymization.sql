@@ -0,0 +1,20 @@
+-- =============================================================================
+-- 018: Analytics Anonymization
+-- =============================================================================
+-- When a user is deleted their xAPI statements, progress and enrolments are
+-- kept for aggregate analytics but detached from the account. user_id becomes
+-- NULL and actor_id holds a stable pseudonym, so per-learner aggregates (e.g.
+-- unique learners per course) still count the same person once.
+--
+-- Rows that were not anonymized still cascade with the user.
+
+ALTER TABLE enrolments ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE enrolments ADD COLUMN IF NOT EXISTS actor_id UUID;
+
+ALTER TABLE progress_records ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE progress_records ADD COLUMN IF NOT EXISTS actor_id UUID;
+
+ALTER TABLE xapi_statements ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE xapi_statements ADD COLUMN IF NOT EXISTS actor_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_xapi_actor ON xapi_statements(actor_id) WHERE actor_id IS NOT NULL;
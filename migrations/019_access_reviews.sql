@@ -0,0 +1,22 @@
+-- =============================================================================
+-- 019: Access Reviews
+-- =============================================================================
+-- Periodic access review reports for SOC 2. A super admin queues a review for
+-- one organisation (or all of them); the access-reviews task generates its
+-- JSON and CSV files and deletes them, with the row, once expires_at passes.
+
+CREATE TABLE IF NOT EXISTS access_reviews (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    error TEXT NOT NULL DEFAULT '',
+    CONSTRAINT valid_access_review_status CHECK (status IN ('pending', 'ready', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_reviews_org ON access_reviews(organisation_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_access_reviews_pending ON access_reviews(created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_access_reviews_expires ON access_reviews(expires_at);
@@ -0,0 +1,31 @@
+-- =============================================================================
+-- 020: Announcements
+-- =============================================================================
+-- Platform announcements (changelog entries, maintenance notices) published by
+-- super admins. An announcement is a draft until published_at, and each user
+-- sees it until they mark it read. Empty tiers or flags target everyone.
+
+CREATE TABLE IF NOT EXISTS announcements (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    link_url TEXT NOT NULL DEFAULT '',
+    tiers TEXT[] NOT NULL DEFAULT '{}',
+    flags TEXT[] NOT NULL DEFAULT '{}',
+    published_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_published ON announcements(published_at DESC) WHERE published_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS announcement_reads (
+    announcement_id UUID NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    read_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (announcement_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcement_reads_user ON announcement_reads(user_id);
@@ -0,0 +1,10 @@
+-- =============================================================================
+-- 021: Organisation Locale
+-- =============================================================================
+-- Each organisation picks the locale (BCP 47 tag) and IANA timezone its
+-- reports and billing pages are presented in. API responses carry both in a
+-- meta block so every client formats numbers and dates the same way, and
+-- monthly usage periods start at midnight in the organisation's timezone.
+
+ALTER TABLE organisations ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en-AU';
+ALTER TABLE organisations ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
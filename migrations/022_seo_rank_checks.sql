@@ -0,0 +1,26 @@
+-- =============================================================================
+-- 022: SEO Rank Checks
+-- =============================================================================
+-- Ad-hoc "where do we rank" checks: a list of keywords looked up on Google for
+-- one domain, outside of any tracked campaign. The lookups run through the
+-- DataForSEO task queue, so a check stays pending until every SERP is back;
+-- the per-keyword results are then stored as one JSON array.
+
+CREATE TABLE IF NOT EXISTS seo_rank_checks (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    domain TEXT NOT NULL,
+    location_code INTEGER NOT NULL,
+    language_code TEXT NOT NULL,
+    device TEXT NOT NULL DEFAULT 'desktop',
+    keyword_count INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    results JSONB NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS idx_seo_rank_checks_org ON seo_rank_checks(organisation_id, created_at DESC);
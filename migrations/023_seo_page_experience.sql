@@ -0,0 +1,36 @@
+-- =============================================================================
+-- 023: SEO Page Experience
+-- =============================================================================
+-- One row per page, strategy and audit: Google's page experience signals
+-- (Core Web Vitals, HTTPS, mobile friendliness, intrusive interstitials)
+-- scored against Google's thresholds. An audit is a run over a set of pages,
+-- identified by audit_id; re-running a page within the same audit replaces
+-- its row. The headline metrics are kept in columns for trend queries, the
+-- per-signal breakdown and the PageSpeed result as JSON.
+
+CREATE TABLE IF NOT EXISTS seo_page_experience (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    audit_id UUID NOT NULL,
+    page_url TEXT NOT NULL,
+    strategy TEXT NOT NULL DEFAULT 'mobile' CHECK (strategy IN ('mobile', 'desktop')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    -- Reported by the caller; NULL when the page wasn't checked for them
+    intrusive_interstitial BOOLEAN,
+    score INTEGER NOT NULL DEFAULT 0,
+    passed BOOLEAN NOT NULL DEFAULT false,
+    -- Where the Core Web Vitals came from: 'field', 'origin' or 'lab'
+    cwv_source TEXT NOT NULL DEFAULT '',
+    lcp_ms DOUBLE PRECISION,
+    inp_ms DOUBLE PRECISION,
+    cls DOUBLE PRECISION,
+    signals JSONB NOT NULL DEFAULT '[]',
+    pagespeed JSONB NOT NULL DEFAULT '{}',
+    UNIQUE (organisation_id, audit_id, page_url, strategy)
+);
+
+CREATE INDEX IF NOT EXISTS idx_seo_page_experience_trend ON seo_page_experience(organisation_id, page_url, strategy, created_at DESC);
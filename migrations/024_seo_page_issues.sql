@@ -0,0 +1,10 @@
+-- =============================================================================
+-- 024: SEO Page Issues
+-- =============================================================================
+-- The on-page issues found on each audited page (missing title, broken
+-- status, missing alt text, ...), each open or resolved. A page can be
+-- re-checked on its own after a fix without re-running the audit:
+-- rechecked_at records when its issues were last re-verified.
+
+ALTER TABLE seo_page_experience ADD COLUMN IF NOT EXISTS issues JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE seo_page_experience ADD COLUMN IF NOT EXISTS rechecked_at TIMESTAMPTZ;
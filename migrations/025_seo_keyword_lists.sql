@@ -0,0 +1,52 @@
+-- =============================================================================
+-- 025: SEO Keyword Lists
+-- =============================================================================
+-- Saved keyword lists whose Google Ads search volumes refresh monthly on
+-- their own. A scheduled task gathers every organisation's due keywords into
+-- as few search volume tasks as the API allows (each is billed the same
+-- however many keywords it holds), splits each task's cost between the lists
+-- that shared it, and records one refresh per list with its share.
+
+CREATE TABLE IF NOT EXISTS seo_keyword_lists (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    location_code INTEGER NOT NULL,
+    language_code TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_refreshed_at TIMESTAMPTZ,
+    -- New lists are due straight away
+    next_refresh_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_seo_keyword_lists_org ON seo_keyword_lists(organisation_id, name);
+CREATE INDEX IF NOT EXISTS idx_seo_keyword_lists_due ON seo_keyword_lists(next_refresh_at);
+
+CREATE TABLE IF NOT EXISTS seo_keywords (
+    list_id UUID NOT NULL REFERENCES seo_keyword_lists(id) ON DELETE CASCADE,
+    keyword TEXT NOT NULL,
+    -- NULL until the first refresh, or when Google Ads has no data
+    search_volume INTEGER,
+    cpc DOUBLE PRECISION,
+    competition TEXT NOT NULL DEFAULT '',
+    monthly_searches JSONB NOT NULL DEFAULT '[]',
+    refreshed_at TIMESTAMPTZ,
+    PRIMARY KEY (list_id, keyword)
+);
+
+CREATE TABLE IF NOT EXISTS seo_keyword_refreshes (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    list_id UUID NOT NULL REFERENCES seo_keyword_lists(id) ON DELETE CASCADE,
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    status TEXT NOT NULL CHECK (status IN ('completed', 'partial', 'failed')),
+    keyword_count INTEGER NOT NULL,
+    updated_count INTEGER NOT NULL,
+    -- The list's share of the tasks it was batched into, in USD
+    cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_seo_keyword_refreshes_list ON seo_keyword_refreshes(list_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_seo_keyword_refreshes_org ON seo_keyword_refreshes(organisation_id, created_at DESC);
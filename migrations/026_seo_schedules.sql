@@ -0,0 +1,47 @@
+-- =============================================================================
+-- 026: Scheduled SEO Audits
+-- =============================================================================
+-- Domains an organisation registers for recurring page experience audits and
+-- rank checks. A scheduled task starts the runs that are due, at a cadence set
+-- by the organisation's tier, and finalises them once their audit and rank
+-- check are done: a run's summary and its deltas against the previous run
+-- are what the dashboard shows.
+
+CREATE TABLE IF NOT EXISTS seo_schedules (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    domain TEXT NOT NULL,
+    -- Pages to audit and keywords to check; either may be empty
+    pages TEXT[] NOT NULL DEFAULT '{}',
+    keywords TEXT[] NOT NULL DEFAULT '{}',
+    location_code INTEGER NOT NULL,
+    language_code TEXT NOT NULL,
+    device TEXT NOT NULL DEFAULT 'desktop' CHECK (device IN ('desktop', 'mobile')),
+    strategy TEXT NOT NULL DEFAULT 'mobile' CHECK (strategy IN ('mobile', 'desktop')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_run_at TIMESTAMPTZ,
+    next_run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organisation_id, domain)
+);
+
+CREATE INDEX IF NOT EXISTS idx_seo_schedules_due ON seo_schedules(next_run_at);
+
+CREATE TABLE IF NOT EXISTS seo_schedule_runs (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES seo_schedules(id) ON DELETE CASCADE,
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'partial', 'failed')),
+    -- The page experience audit (seo_page_experience.audit_id) and rank
+    -- check the run started; NULL when there was nothing to start
+    audit_id UUID,
+    rank_check_id UUID REFERENCES seo_rank_checks(id) ON DELETE SET NULL,
+    summary JSONB NOT NULL DEFAULT '{}',
+    deltas JSONB NOT NULL DEFAULT '{}',
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_seo_schedule_runs_schedule ON seo_schedule_runs(schedule_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_seo_schedule_runs_running ON seo_schedule_runs(created_at) WHERE status = 'running';
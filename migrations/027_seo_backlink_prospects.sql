@@ -0,0 +1,35 @@
+-- =============================================================================
+-- 027: SEO Backlink Prospects
+-- =============================================================================
+-- Domains a link-building team is reaching out to for links to one of the
+-- organisation's sites (the target), and how far outreach has got. Prospects
+-- are added by hand, from a CSV or from the domains linking to competitors
+-- but not to the target, and can be enriched with each domain's backlink
+-- summary. A domain is a prospect at most once per target.
+
+CREATE TABLE IF NOT EXISTS seo_backlink_prospects (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    target TEXT NOT NULL,
+    domain TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'identified' CHECK (status IN ('identified', 'contacted', 'linked')),
+    source TEXT NOT NULL CHECK (source IN ('manual', 'csv', 'competitor_gap')),
+    -- The competitors the domain links to, for competitor gap prospects
+    competitors TEXT[] NOT NULL DEFAULT '{}',
+    contact TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    -- From the domain's backlink summary; NULL until enriched
+    rank INTEGER,
+    backlinks BIGINT,
+    referring_domains INTEGER,
+    spam_score INTEGER,
+    enriched_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    contacted_at TIMESTAMPTZ,
+    linked_at TIMESTAMPTZ,
+    UNIQUE (organisation_id, target, domain)
+);
+
+CREATE INDEX IF NOT EXISTS idx_seo_backlink_prospects_status ON seo_backlink_prospects(organisation_id, target, status);
@@ -0,0 +1,72 @@
+-- =============================================================================
+-- 028: Rank Tracker
+-- =============================================================================
+-- Keywords an organisation tracks for one of its domains in one market. A
+-- scheduled task looks up each tracker's Google SERPs once a day, keeping
+-- the positions of the domain and of up to five competitors as a time
+-- series. A run's share of voice, each domain's estimated share of the
+-- tracked keywords' clicks, is computed when it finishes, using search
+-- volumes refreshed monthly.
+
+CREATE TABLE IF NOT EXISTS rank_trackers (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    domain TEXT NOT NULL,
+    location_code INTEGER NOT NULL,
+    language_code TEXT NOT NULL,
+    device TEXT NOT NULL DEFAULT 'desktop' CHECK (device IN ('desktop', 'mobile')),
+    competitors TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_run_at TIMESTAMPTZ,
+    -- New trackers are due straight away
+    next_run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    volumes_refreshed_at TIMESTAMPTZ,
+    UNIQUE (organisation_id, domain, location_code, language_code, device)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rank_trackers_due ON rank_trackers(next_run_at);
+
+CREATE TABLE IF NOT EXISTS rank_tracker_keywords (
+    tracker_id UUID NOT NULL REFERENCES rank_trackers(id) ON DELETE CASCADE,
+    keyword TEXT NOT NULL,
+    -- Google Ads monthly searches; NULL until looked up or when there's no data
+    search_volume INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tracker_id, keyword)
+);
+
+CREATE TABLE IF NOT EXISTS rank_tracker_runs (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    tracker_id UUID NOT NULL REFERENCES rank_trackers(id) ON DELETE CASCADE,
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'partial', 'failed')),
+    keyword_count INTEGER NOT NULL,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    -- USD, as billed by DataForSEO
+    cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    -- Percent per domain, e.g. {"example.com": 12.5}
+    share_of_voice JSONB NOT NULL DEFAULT '{}',
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_rank_tracker_runs_tracker ON rank_tracker_runs(tracker_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS rank_positions (
+    run_id UUID NOT NULL REFERENCES rank_tracker_runs(id) ON DELETE CASCADE,
+    tracker_id UUID NOT NULL REFERENCES rank_trackers(id) ON DELETE CASCADE,
+    keyword TEXT NOT NULL,
+    domain TEXT NOT NULL,
+    -- NULL when the domain isn't in the top 100
+    position INTEGER,
+    url TEXT NOT NULL DEFAULT '',
+    -- serp, or labs when the SERP lookup failed and DataForSEO Labs' ranked
+    -- keywords filled in
+    source TEXT NOT NULL CHECK (source IN ('serp', 'labs')),
+    checked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (run_id, keyword, domain)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rank_positions_history ON rank_positions(tracker_id, keyword, checked_at);
@@ -0,0 +1,75 @@
+-- =============================================================================
+-- 029: Competitor Monitoring
+-- =============================================================================
+-- Competitor domains an organisation pins against one of its sites (the
+-- target) in one market. A scheduled task refreshes each pin weekly: it
+-- snapshots the organic and backlink metrics of the target and the
+-- competitor, records the keywords the competitor ranks in the top 10 for
+-- that the target doesn't rank for at all, and raises alerts for new keyword
+-- gaps and for sharp gains in referring domains.
+
+CREATE TABLE IF NOT EXISTS competitor_pins (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    target TEXT NOT NULL,
+    domain TEXT NOT NULL,
+    location_code INTEGER NOT NULL,
+    language_code TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    refreshed_at TIMESTAMPTZ,
+    -- New pins are due straight away
+    next_refresh_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organisation_id, target, domain, location_code, language_code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_competitor_pins_due ON competitor_pins(next_refresh_at);
+
+-- One row per domain per refresh; the target's rows sit alongside its
+-- competitors'. Metrics are NULL when DataForSEO has no data for the domain.
+CREATE TABLE IF NOT EXISTS competitor_snapshots (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    target TEXT NOT NULL,
+    domain TEXT NOT NULL,
+    location_code INTEGER NOT NULL,
+    language_code TEXT NOT NULL,
+    captured_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    organic_etv DOUBLE PRECISION,
+    organic_keywords INTEGER,
+    top3_keywords INTEGER,
+    top10_keywords INTEGER,
+    domain_rank INTEGER,
+    backlinks BIGINT,
+    referring_domains INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_competitor_snapshots_domain ON competitor_snapshots(organisation_id, target, location_code, language_code, domain, captured_at DESC);
+
+CREATE TABLE IF NOT EXISTS competitor_keyword_gaps (
+    pin_id UUID NOT NULL REFERENCES competitor_pins(id) ON DELETE CASCADE,
+    keyword TEXT NOT NULL,
+    search_volume INTEGER,
+    -- The competitor's position
+    position INTEGER NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    -- First seen in the latest refresh, and not in the pin's first one
+    is_new BOOLEAN NOT NULL DEFAULT false,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (pin_id, keyword)
+);
+
+CREATE TABLE IF NOT EXISTS competitor_alerts (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    pin_id UUID NOT NULL REFERENCES competitor_pins(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('keyword_gap', 'backlink_gain')),
+    message TEXT NOT NULL,
+    -- The new gap keywords, or the referring domains gained
+    keywords TEXT[] NOT NULL DEFAULT '{}',
+    amount INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_competitor_alerts_org ON competitor_alerts(organisation_id, created_at DESC);
@@ -0,0 +1,38 @@
+-- =============================================================================
+-- 030: Organisation Deletions
+-- =============================================================================
+-- An owner deleting an organisation soft-deletes it: members lose access and
+-- the subscription is set to cancel, but everything can be restored for 30
+-- days. Once the recovery window is over, or a super admin confirms it
+-- early, a scheduled task tears the organisation down in stages, recording
+-- its progress here. The row outlives the organisation as the record of the
+-- deletion, so it has no foreign key to it.
+
+CREATE TABLE IF NOT EXISTS organisation_deletions (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL,
+    organisation_name TEXT NOT NULL,
+    organisation_slug TEXT NOT NULL,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- End of the recovery window; teardown starts after it
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'running', 'failed', 'completed', 'cancelled')),
+    -- Stage in progress, or the one that failed
+    stage TEXT NOT NULL DEFAULT '',
+    -- Per stage: whether it's done and what it removed, e.g.
+    -- {"content": {"done": true, "removed": {"courses": 12}}}
+    progress JSONB NOT NULL DEFAULT '{}',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    -- Super admin who skipped the rest of the recovery window
+    confirmed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    cancelled_at TIMESTAMPTZ,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+
+-- At most one deletion in flight per organisation
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organisation_deletions_active ON organisation_deletions(organisation_id) WHERE status IN ('scheduled', 'running', 'failed');
+CREATE INDEX IF NOT EXISTS idx_organisation_deletions_due ON organisation_deletions(scheduled_for) WHERE status IN ('scheduled', 'running', 'failed');
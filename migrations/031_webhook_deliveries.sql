@@ -0,0 +1,32 @@
+-- =============================================================================
+-- 031: Webhook Deliveries
+-- =============================================================================
+-- One row per event per subscribed webhook. The first attempt is made as
+-- soon as the event is published; failed deliveries are retried by a
+-- scheduled task with exponential backoff until they succeed or run out of
+-- attempts. Rows are kept for 30 days as the webhook's delivery log.
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    webhook_id UUID NOT NULL REFERENCES organisation_webhooks(id) ON DELETE CASCADE,
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    -- Shared by every webhook the event went to, so receivers can deduplicate
+    event_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    -- The signed request body
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- HTTP status of the last attempt; NULL when it got no response
+    response_status INTEGER,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_attempt_at TIMESTAMPTZ,
+    -- When a pending delivery is next tried
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created ON webhook_deliveries(created_at);
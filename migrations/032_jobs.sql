@@ -0,0 +1,29 @@
+-- =============================================================================
+-- 032: Background Jobs
+-- =============================================================================
+-- The durable work queue behind app/pkg/jobs. A worker leases a job by
+-- setting locked_until and keeps renewing it while the job runs, so a job
+-- whose instance died is picked up again once its lease runs out. Failed jobs
+-- are retried with backoff; those out of attempts are kept as 'dead' until
+-- retried by hand. Completed jobs are pruned after 7 days.
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    kind TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    last_error TEXT NOT NULL DEFAULT '',
+    -- When a pending job is next due
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- The lease of a running job
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs(run_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_jobs_leased ON jobs(locked_until) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status, created_at DESC);
@@ -0,0 +1,24 @@
+-- =============================================================================
+-- 033: Retention Policies
+-- =============================================================================
+-- Each organisation can set records-retention rules: archive drafts nobody
+-- has edited for a number of months, purge soft-deleted content a number of
+-- days after deletion, and delete xAPI statements older than a number of
+-- years. A NULL period turns that rule off. A daily task enforces every
+-- policy; changes to a policy and what each run removed are recorded in the
+-- organisation's activity log.
+
+CREATE TABLE IF NOT EXISTS organisation_retention_policies (
+    organisation_id UUID PRIMARY KEY NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    archive_drafts_after_months INTEGER CHECK (archive_drafts_after_months > 0),
+    purge_deleted_after_days INTEGER CHECK (purge_deleted_after_days > 0),
+    retain_xapi_years INTEGER CHECK (retain_xapi_years > 0),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- When the policy was last enforced; NULL until its first run
+    evaluated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_policies_evaluated ON organisation_retention_policies(evaluated_at NULLS FIRST);
+CREATE INDEX IF NOT EXISTS idx_h5p_content_org_updated ON h5p_content(org_id, updated_at) WHERE status = 'draft' AND deleted_at IS NULL;
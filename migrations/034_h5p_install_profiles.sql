@@ -0,0 +1,41 @@
+-- =============================================================================
+-- 034: H5P Install Profiles
+-- =============================================================================
+-- Platform admins define install profiles ("K-12 starter", "Corporate
+-- training") as lists of content type machine names. Applying a profile to an
+-- organisation installs any missing libraries from the H5P Hub and enables
+-- them all for the organisation, as a background job. The default profile is
+-- applied to every new organisation. Each application is kept with a summary
+-- of what it enabled.
+
+CREATE TABLE IF NOT EXISTS h5p_install_profiles (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    -- Content type machine names, e.g. H5P.MultiChoice
+    libraries TEXT[] NOT NULL DEFAULT '{}',
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- At most one profile is applied to new organisations
+CREATE UNIQUE INDEX IF NOT EXISTS idx_h5p_install_profiles_default ON h5p_install_profiles(is_default) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS h5p_profile_applications (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    profile_id UUID REFERENCES h5p_install_profiles(id) ON DELETE SET NULL,
+    -- The profile's name and libraries when it was applied
+    profile_name TEXT NOT NULL,
+    libraries TEXT[] NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    -- {"enabled": [...], "installed": [...], "failed": [{"machineName", "error"}]}
+    summary JSONB NOT NULL DEFAULT '{}',
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_h5p_profile_applications_org ON h5p_profile_applications(organisation_id, created_at DESC);
@@ -0,0 +1,27 @@
+-- =============================================================================
+-- 035: xAPI Statement Columns
+-- =============================================================================
+-- Statements posted to the xAPI endpoint are validated and their verb, object
+-- and result are copied out of the raw statement into columns, so completion
+-- and score summaries don't have to unpack JSONB. The actor is the
+-- authenticated learner, kept in user_id (actor_id once anonymized).
+--
+-- statement_id is the statement's own xAPI id; re-posting a statement with
+-- the same id is ignored. Statements stored before this migration keep NULL
+-- in the new columns.
+
+ALTER TABLE xapi_statements ADD COLUMN IF NOT EXISTS statement_id UUID;
+-- Verb and activity IRIs, e.g. http://adlnet.gov/expapi/verbs/completed
+ALTER TABLE xapi_statements ADD COLUMN IF NOT EXISTS verb_id TEXT;
+ALTER TABLE xapi_statements ADD COLUMN IF NOT EXISTS object_id TEXT;
+ALTER TABLE xapi_statements ADD COLUMN IF NOT EXISTS result_score_raw DOUBLE PRECISION;
+ALTER TABLE xapi_statements ADD COLUMN IF NOT EXISTS result_score_max DOUBLE PRECISION;
+ALTER TABLE xapi_statements ADD COLUMN IF NOT EXISTS result_score_scaled DOUBLE PRECISION;
+ALTER TABLE xapi_statements ADD COLUMN IF NOT EXISTS result_success BOOLEAN;
+ALTER TABLE xapi_statements ADD COLUMN IF NOT EXISTS result_completion BOOLEAN;
+-- result.duration in whole seconds
+ALTER TABLE xapi_statements ADD COLUMN IF NOT EXISTS result_duration INTEGER;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_xapi_statement_id ON xapi_statements(statement_id);
+CREATE INDEX IF NOT EXISTS idx_xapi_org_content ON xapi_statements(org_id, content_id);
+CREATE INDEX IF NOT EXISTS idx_xapi_org_user ON xapi_statements(org_id, user_id, content_id);
@@ -0,0 +1,18 @@
+-- =============================================================================
+-- 036: SEO Raw Artifacts
+-- =============================================================================
+-- The raw DataForSEO task result behind each audited page's summary is kept,
+-- gzipped, in object storage, so customers can run their own analysis
+-- without paying for the same tasks again. Each row points at one page's
+-- result for one section of the audit (e.g. "onpage" for the on-page checks).
+-- A page audited again replaces its artifact.
+
+CREATE TABLE IF NOT EXISTS seo_raw_artifacts (
+    page_id UUID NOT NULL REFERENCES seo_page_experience(id) ON DELETE CASCADE,
+    section TEXT NOT NULL,
+    object_key TEXT NOT NULL,
+    -- Compressed size
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (page_id, section)
+);
@@ -0,0 +1,31 @@
+-- =============================================================================
+-- 037: H5P Editor Bundle Variants
+-- =============================================================================
+-- A change to the editor's aggregated core/editor assets or library versions
+-- is rolled out as a bundle variant served from its own asset base, e.g.
+-- /h5p-canary. Each variant takes a percentage of organisations; the rest
+-- stay on the built-in "stable" bundle. Setting a variant's percentage to 0
+-- rolls it back on the next editor load.
+--
+-- Editors report each load and error against the variant they loaded, counted
+-- per day, so a variant's error rate can be compared with stable's. Reports
+-- are kept after a variant is deleted.
+
+CREATE TABLE IF NOT EXISTS h5p_bundle_variants (
+    name TEXT PRIMARY KEY NOT NULL CHECK (name ~ '^[a-z0-9][a-z0-9-]{0,31}$' AND name <> 'stable'),
+    description TEXT NOT NULL DEFAULT '',
+    -- Where the variant's core and editor assets are served, e.g. /h5p-canary
+    asset_base TEXT NOT NULL,
+    rollout_percent INTEGER NOT NULL DEFAULT 0 CHECK (rollout_percent BETWEEN 0 AND 100),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS h5p_bundle_reports (
+    variant TEXT NOT NULL,
+    day DATE NOT NULL,
+    loads BIGINT NOT NULL DEFAULT 0,
+    errors BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (variant, day)
+);
@@ -0,0 +1,24 @@
+-- =============================================================================
+-- 038: Content Extract Cache
+-- =============================================================================
+-- Pages extracted to markdown through the content fetch chain (POST
+-- /api/v1/tools/extract) are cached by URL, so the briefs UI and content
+-- imports reading the same page don't pay a provider for it again. Entries
+-- expire after a TTL and are cleaned up as new ones are written.
+
+CREATE TABLE IF NOT EXISTS content_extract_cache (
+    -- The requested URL without its fragment
+    url TEXT PRIMARY KEY NOT NULL,
+    -- Where the page ended up after redirects
+    final_url TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    markdown TEXT NOT NULL,
+    -- [{"url", "text"}]
+    links JSONB NOT NULL DEFAULT '[]',
+    -- The content fetch provider that produced the page, e.g. jina
+    provider TEXT NOT NULL,
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_extract_cache_expires ON content_extract_cache(expires_at);
@@ -0,0 +1,53 @@
+-- =============================================================================
+-- 039: Notification Preferences
+-- =============================================================================
+-- Domain events published to an organisation (content published, audits
+-- finished, payments failed, ...) also notify its members, by email and in the
+-- app. Each member chooses, per event category and channel, whether they're
+-- notified and, for email, whether straight away or in a daily digest. Only
+-- choices that differ from their role's defaults are stored.
+--
+-- Events waiting for a member's digest are queued in notification_digest_items
+-- until the daily digest task emails them. In-app notifications are kept in
+-- user_notifications for 90 days.
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    category TEXT NOT NULL CHECK (category IN ('content', 'seo', 'billing')),
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'in_app')),
+    enabled BOOLEAN NOT NULL,
+    -- Only email can be sent as a digest
+    digest BOOLEAN NOT NULL DEFAULT FALSE CHECK (NOT digest OR channel = 'email'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, organisation_id, category, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_preferences_org ON notification_preferences(organisation_id);
+
+CREATE TABLE IF NOT EXISTS notification_digest_items (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_digest_items_user ON notification_digest_items(user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS user_notifications (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organisation_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_notifications_user_org ON user_notifications(user_id, organisation_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_notifications_created ON user_notifications(created_at);
@@ -0,0 +1,23 @@
+-- =============================================================================
+-- 040: Pending Deletions
+-- =============================================================================
+-- Purging rows whose files live in object storage used to delete the rows and
+-- then remove the files; when the removal failed the files were left behind
+-- with nothing pointing at them. Now the purge queues the storage keys here in
+-- the same statement that deletes the rows, and a sweeper task removes them,
+-- retrying failures with backoff until they go.
+--
+-- A storage_key ending in "/" is a prefix: everything under it is removed.
+
+CREATE TABLE IF NOT EXISTS pending_deletions (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    storage_key TEXT NOT NULL UNIQUE,
+    -- What queued the deletion, e.g. retention or library
+    reason TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_deletions_next_attempt ON pending_deletions(next_attempt_at);
@@ -0,0 +1,49 @@
+-- =============================================================================
+-- 041: Time-Ordered IDs
+-- =============================================================================
+-- New IDs are UUIDv7, whose leading 48 bits are the creation time in
+-- milliseconds, so rows are appended to the end of primary key indexes instead
+-- of scattered across them, and lists can page on ID alone. The service makes
+-- them with app/pkg/ids; uuid_generate_v7() does the same for rows whose ID is
+-- left to the column default. (Postgres 18 has uuidv7() built in; this runs on
+-- older versions too.) Existing UUIDv4 IDs are kept.
+
+-- A random UUIDv4 with the timestamp written over its first 6 bytes and the
+-- version bits turned from 4 (0100) into 7 (0111).
+CREATE OR REPLACE FUNCTION uuid_generate_v7(ts TIMESTAMPTZ DEFAULT clock_timestamp())
+RETURNS UUID
+LANGUAGE sql VOLATILE
+AS $$
+    SELECT encode(
+        set_bit(set_bit(
+            overlay(uuid_send(gen_random_uuid())
+                PLACING substring(int8send(floor(extract(epoch FROM ts) * 1000)::bigint) FROM 3)
+                FROM 1 FOR 6),
+            52, 1), 53, 1),
+        'hex')::uuid;
+$$;
+
+-- Every ID column that defaulted to a random UUID now defaults to a
+-- time-ordered one.
+DO $$
+DECLARE
+    col RECORD;
+BEGIN
+    FOR col IN
+        SELECT table_name, column_name
+        FROM information_schema.columns
+        WHERE table_schema = current_schema()
+          AND column_default = 'gen_random_uuid()'
+    LOOP
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN %I SET DEFAULT uuid_generate_v7()', col.table_name, col.column_name);
+    END LOOP;
+END $$;
+
+-- The admin job list pages on ID. Nothing references job IDs, so finished
+-- jobs are rekeyed from their creation time. Pending and running ones keep
+-- their IDs, since a worker may hold them.
+UPDATE jobs SET id = uuid_generate_v7(created_at)
+WHERE status IN ('completed', 'dead') AND substring(id::text, 15, 1) <> '7';
+
+DROP INDEX IF EXISTS idx_jobs_status;
+CREATE INDEX IF NOT EXISTS idx_jobs_status_id ON jobs(status, id DESC);
@@ -0,0 +1,50 @@
+-- =============================================================================
+-- 042: Course Completion Certificates
+-- =============================================================================
+-- A learner who completes a course is issued a certificate. Its ID is printed
+-- on the PDF and anyone can check it at /verify/{id}. The learner, course and
+-- organisation names are copied onto the certificate when it is issued, so a
+-- renamed course doesn't change certificates already handed out.
+--
+-- Each organisation lays its certificates out with one template: a heading,
+-- which fields are printed in which order, a signatory and an optional
+-- background image.
+
+CREATE TABLE IF NOT EXISTS certificate_templates (
+    organisation_id UUID PRIMARY KEY REFERENCES organisations(id) ON DELETE CASCADE,
+    heading TEXT NOT NULL DEFAULT 'Certificate of Completion',
+    -- Field keys in print order, e.g. ["learner", "course", "completed_at"]
+    fields JSONB NOT NULL DEFAULT '["learner", "course", "completed_at", "organisation", "certificate_id"]',
+    signatory_name TEXT NOT NULL DEFAULT '',
+    signatory_title TEXT NOT NULL DEFAULT '',
+    -- Storage key of the background image; replaced images are queued in
+    -- pending_deletions
+    background_key TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS certificates (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v7(),
+    org_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    course_id UUID REFERENCES courses(id) ON DELETE SET NULL,
+    enrolment_id UUID UNIQUE REFERENCES enrolments(id) ON DELETE SET NULL,
+    learner_name TEXT NOT NULL,
+    course_title TEXT NOT NULL,
+    organisation_name TEXT NOT NULL,
+    completed_at TIMESTAMPTZ NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- The rendered PDF; NULL until it has been rendered
+    storage_key TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_certificates_user ON certificates(user_id, issued_at DESC);
+
+CREATE TABLE IF NOT EXISTS certificate_downloads (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v7(),
+    certificate_id UUID NOT NULL REFERENCES certificates(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    downloaded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_certificate_downloads_certificate ON certificate_downloads(certificate_id, downloaded_at DESC);
@@ -0,0 +1,35 @@
+-- =============================================================================
+-- 043: H5P Media Library
+-- =============================================================================
+-- Files uploaded in the editor are kept in the organisation's media library,
+-- so authors can reuse a logo or clip instead of uploading it again. Assets
+-- are deduplicated by checksum: uploading the same file twice records one
+-- asset used twice.
+--
+-- Reusing an asset copies it into the editor's temp storage; saving the
+-- content then moves it into content storage like any upload, so deleting an
+-- asset never breaks content that used it.
+
+CREATE TABLE IF NOT EXISTS h5p_media_assets (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v7(),
+    org_id UUID NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    filename TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    -- image, video, audio or file, from the MIME type
+    kind TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    width INTEGER,
+    height INTEGER,
+    -- Hex SHA-256 of the file
+    checksum TEXT NOT NULL,
+    storage_key TEXT NOT NULL,
+    -- PNG preview of images larger than a thumbnail
+    thumbnail_key TEXT,
+    use_count INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, checksum)
+);
+
+CREATE INDEX IF NOT EXISTS idx_h5p_media_assets_org_kind ON h5p_media_assets(org_id, kind, id DESC);
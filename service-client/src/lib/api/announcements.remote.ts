@@ -0,0 +1,100 @@
+/**
+ * Announcements Remote Functions
+ *
+ * Platform announcements (changelog entries and notices) for the notification
+ * center. Calls the Go service-core announcements endpoints.
+ */
+
+import { query, command } from "$app/server";
+import { getRequestEvent } from "$app/server";
+import * as v from "valibot";
+import { env } from "$env/dynamic/private";
+import { getOrganisationContext } from "$lib/server/organisation";
+import { error } from "@sveltejs/kit";
+
+// =============================================================================
+// Types
+// =============================================================================
+
+export type Announcement = {
+	id: string;
+	title: string;
+	body: string; // markdown
+	linkUrl: string; // app path or https URL; empty when there's nothing to open
+	tiers: string[];
+	flags: string[];
+	publishedAt: string | null;
+	expiresAt: string | null;
+	createdAt: string;
+	updatedAt: string;
+};
+
+type SafeResponse<T> = {
+	success: boolean;
+	data?: T;
+	message?: string;
+	code?: number;
+};
+
+// =============================================================================
+// Helper to call Go service
+// =============================================================================
+
+async function callAnnouncementsAPI<T>(
+	path: string,
+	options: RequestInit = {},
+): Promise<SafeResponse<T>> {
+	const event = getRequestEvent();
+	const accessToken = event.cookies.get("access_token");
+
+	const response = await fetch(`${env.CORE_URL}/api/v1/announcements${path}`, {
+		...options,
+		headers: {
+			"Content-Type": "application/json",
+			...(accessToken ? { Authorization: `Bearer ${accessToken}` } : {}),
+			...options.headers,
+		},
+	});
+
+	if (!response.ok) {
+		const errorBody = await response.json().catch(() => ({ message: "Unknown error" }));
+		throw error(response.status, errorBody.message || `Announcements API error: ${response.status}`);
+	}
+
+	return response.json();
+}
+
+// =============================================================================
+// Announcements
+// =============================================================================
+
+/**
+ * Get the unread announcements that target the current user in the current
+ * organisation, newest first.
+ */
+export const getAnnouncements = query(async () => {
+	const context = await getOrganisationContext();
+
+	const response = await callAnnouncementsAPI<Announcement[]>(
+		`?organisationId=${context.organisationId}`,
+	);
+	if (!response.success) {
+		throw error(500, response.message || "Failed to get announcements");
+	}
+
+	return response.data ?? [];
+});
+
+/**
+ * Mark an announcement read so it stops appearing for the current user.
+ */
+export const markAnnouncementRead = command(v.pipe(v.string(), v.uuid()), async (announcementId) => {
+	const response = await callAnnouncementsAPI<{ success: boolean }>(`/${announcementId}/read`, {
+		method: "POST",
+	});
+	if (!response.success) {
+		throw error(500, response.message || "Failed to mark announcement read");
+	}
+
+	return { success: true };
+});
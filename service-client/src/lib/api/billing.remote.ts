@@ -35,6 +35,33 @@ type URLResponse = {
 	url: string;
 };
 
+// Hosted sessions carry a url to redirect to; embedded sessions carry a
+// clientSecret for mounting Stripe's embedded Checkout.
+type CheckoutSessionResponse = {
+	sessionId: string;
+	uiMode: "hosted" | "embedded";
+	url?: string;
+	clientSecret?: string;
+};
+
+type BillingContact = {
+	name: string;
+	email: string;
+	poNumber: string;
+	address: {
+		line1: string;
+		line2: string;
+		city: string;
+		state: string;
+		postalCode: string;
+		country: string;
+	};
+	invoiceMemo: string;
+	invoiceCustomFields: { name: string; value: string }[];
+	isDefault: boolean;
+	updatedAt: string | null;
+};
+
 type SafeResponse<T> = {
 	success: boolean;
 	data?: T;
@@ -49,6 +76,10 @@ type SafeResponse<T> = {
 const CreateCheckoutSchema = v.object({
 	tier: v.picklist(["starter", "growth", "enterprise"]),
 	interval: v.picklist(["month", "year"]),
+	// Defaults to STRIPE_CHECKOUT_UI_MODE on the server
+	uiMode: v.optional(v.picklist(["hosted", "embedded"])),
+	// Client path to come back to after checkout; defaults to billing settings
+	returnPath: v.optional(v.pipe(v.string(), v.regex(/^\/(?!\/)[^?#]*$/))),
 });
 
 const UpgradeSubscriptionSchema = v.object({
@@ -56,6 +87,30 @@ const UpgradeSubscriptionSchema = v.object({
 	interval: v.picklist(["month", "year"]),
 });
 
+const UpdateBillingContactSchema = v.object({
+	name: v.pipe(v.string(), v.maxLength(200)),
+	email: v.pipe(v.string(), v.email()),
+	poNumber: v.pipe(v.string(), v.maxLength(140)),
+	address: v.object({
+		line1: v.string(),
+		line2: v.string(),
+		city: v.string(),
+		state: v.string(),
+		postalCode: v.string(),
+		country: v.pipe(v.string(), v.maxLength(2)),
+	}),
+	invoiceMemo: v.pipe(v.string(), v.maxLength(5000)),
+	invoiceCustomFields: v.pipe(
+		v.array(
+			v.object({
+				name: v.pipe(v.string(), v.minLength(1), v.maxLength(40)),
+				value: v.pipe(v.string(), v.minLength(1), v.maxLength(140)),
+			}),
+		),
+		v.maxLength(4),
+	),
+});
+
 // =============================================================================
 // Helper to call Go service
 // =============================================================================
@@ -63,11 +118,18 @@ const UpgradeSubscriptionSchema = v.object({
 async function callBillingAPI<T>(
 	endpoint: string,
 	options: RequestInit = {},
+): Promise<SafeResponse<T>> {
+	return callCoreAPI<T>(`/api/v1/billing${endpoint}`, options);
+}
+
+async function callCoreAPI<T>(
+	path: string,
+	options: RequestInit = {},
 ): Promise<SafeResponse<T>> {
 	const event = getRequestEvent();
 	const accessToken = event.cookies.get("access_token");
 
-	const response = await fetch(`${env.CORE_URL}/api/v1/billing${endpoint}`, {
+	const response = await fetch(`${env.CORE_URL}${path}`, {
 		...options,
 		headers: {
 			"Content-Type": "application/json",
@@ -159,7 +221,8 @@ export const getCheckoutSessionStatus = query(GetSessionStatusSchema, async (dat
 
 /**
  * Create a Stripe Checkout session for upgrading the organisation subscription.
- * Returns a URL to redirect the user to Stripe's hosted checkout page.
+ * Hosted sessions return a URL to redirect the user to Stripe's checkout page;
+ * embedded sessions return a client secret for Stripe.js.
  */
 export const createCheckoutSession = command(CreateCheckoutSchema, async (data) => {
 	const context = await getOrganisationContext();
@@ -179,7 +242,7 @@ export const createCheckoutSession = command(CreateCheckoutSchema, async (data)
 		throw error(500, "User not found");
 	}
 
-	const response = await callBillingAPI<URLResponse>("/checkout", {
+	const response = await callBillingAPI<CheckoutSessionResponse>("/checkout", {
 		method: "POST",
 		body: JSON.stringify({
 			organisationId: context.organisationId,
@@ -188,6 +251,8 @@ export const createCheckoutSession = command(CreateCheckoutSchema, async (data)
 			email: user.email,
 			tier: data.tier,
 			interval: data.interval,
+			uiMode: data.uiMode,
+			returnPath: data.returnPath,
 		}),
 	});
 
@@ -227,6 +292,31 @@ export const createPortalSession = command(async () => {
 	return response.data;
 });
 
+/**
+ * Create a Billing Portal session that opens directly on the payment method
+ * update page. Used by the card expiry reminder email deep link.
+ */
+export const createPaymentMethodUpdateSession = command(async () => {
+	const context = await getOrganisationContext();
+
+	if (!hasPermission(context.role, "billing:manage")) {
+		throw error(403, "Permission denied: billing:manage");
+	}
+
+	const response = await callBillingAPI<URLResponse>(
+		`/portal?organisationId=${context.organisationId}&organisationSlug=${context.organisation.slug}&flow=payment_method_update`,
+		{
+			method: "POST",
+		},
+	);
+
+	if (!response.success || !response.data) {
+		throw error(500, response.message || "Failed to create portal session");
+	}
+
+	return response.data;
+});
+
 // =============================================================================
 // Sync Subscription from Session
 // =============================================================================
@@ -292,3 +382,55 @@ export const upgradeSubscription = command(UpgradeSubscriptionSchema, async (dat
 
 	return { success: true };
 });
+
+// =============================================================================
+// Billing Contact & Invoice Settings
+// =============================================================================
+
+/**
+ * Get the organisation's billing contact (invoice recipient, PO number,
+ * address and invoice memo/custom fields).
+ */
+export const getBillingContact = query(async () => {
+	const context = await getOrganisationContext();
+
+	if (!hasPermission(context.role, "billing:manage")) {
+		throw error(403, "Permission denied: billing:manage");
+	}
+
+	const response = await callCoreAPI<BillingContact>(
+		`/api/v1/organisations/${context.organisationId}/billing-contact`,
+	);
+
+	if (!response.success || !response.data) {
+		throw error(500, response.message || "Failed to get billing contact");
+	}
+
+	return response.data;
+});
+
+/**
+ * Update the organisation's billing contact. Synced to the Stripe customer so
+ * future invoices go to this contact and show the PO number and memo.
+ */
+export const updateBillingContact = command(UpdateBillingContactSchema, async (data) => {
+	const context = await getOrganisationContext();
+
+	if (!hasPermission(context.role, "billing:manage")) {
+		throw error(403, "Permission denied: billing:manage");
+	}
+
+	const response = await callCoreAPI<BillingContact>(
+		`/api/v1/organisations/${context.organisationId}/billing-contact`,
+		{
+			method: "PUT",
+			body: JSON.stringify(data),
+		},
+	);
+
+	if (!response.success || !response.data) {
+		throw error(500, response.message || "Failed to update billing contact");
+	}
+
+	return response.data;
+});
@@ -20,7 +20,7 @@ import {
 } from "$lib/server/organisation";
 import { getUserId } from "$lib/server/auth";
 import { eq, and, count, sql, asc, desc, isNull, ilike, inArray } from "drizzle-orm";
-import { enforceCourseLimit } from "$lib/server/subscription";
+import { enforceCourseLimit, enforceNotLocked } from "$lib/server/subscription";
 import { logActivity } from "$lib/server/db-helpers";
 import type { CourseListItem, CourseWithItems, CourseItemWithContent, CourseSection } from "./courses.types";
 
@@ -169,6 +169,7 @@ export const getCourses = query(GetCoursesFiltersSchema, async (filters) => {
 			archivedAt: courses.archivedAt,
 			createdAt: courses.createdAt,
 			updatedAt: courses.updatedAt,
+			lockReason: courses.lockReason,
 			itemCount: sql<number>`COALESCE(${itemCountSq.itemCount}, 0)`.mapWith(Number),
 			enrolmentCount: sql<number>`COALESCE(${enrolmentCountSq.enrolmentCount}, 0)`.mapWith(Number),
 			totalDurationMinutes: sql<number>`COALESCE(${durationSq.totalDuration}, 0)`.mapWith(Number),
@@ -262,6 +263,7 @@ export const getCourse = query(v.pipe(v.string(), v.uuid()), async (courseId) =>
 		createdAt: course.createdAt,
 		updatedAt: course.updatedAt,
 		createdBy: course.createdBy,
+		lockReason: course.lockReason,
 		items: items as CourseItemWithContent[],
 		sections: sections as CourseSection[],
 	};
@@ -387,6 +389,7 @@ export const updateCourse = command(UpdateCourseSchema, async (data) => {
 	if (!course) {
 		throw error(404, "Course not found");
 	}
+	enforceNotLocked(course);
 
 	const updates: Record<string, unknown> = { updatedAt: new Date() };
 
@@ -473,7 +476,7 @@ export const addCourseItem = command(AddCourseItemSchema, async (data) => {
 
 	// Verify course belongs to org
 	const [course] = await db
-		.select({ id: courses.id })
+		.select({ id: courses.id, lockReason: courses.lockReason })
 		.from(courses)
 		.where(
 			and(
@@ -487,6 +490,7 @@ export const addCourseItem = command(AddCourseItemSchema, async (data) => {
 	if (!course) {
 		throw error(404, "Course not found");
 	}
+	enforceNotLocked(course);
 
 	// Get max sort_order for this course
 	const [maxSort] = await db
@@ -554,7 +558,7 @@ export const removeCourseItem = command(v.pipe(v.string(), v.uuid()), async (ite
 	// Verify the course belongs to the current org
 	const context = await getOrganisationContext();
 	const [course] = await db
-		.select({ id: courses.id })
+		.select({ id: courses.id, lockReason: courses.lockReason })
 		.from(courses)
 		.where(
 			and(
@@ -568,6 +572,7 @@ export const removeCourseItem = command(v.pipe(v.string(), v.uuid()), async (ite
 	if (!course) {
 		throw error(404, "Course not found");
 	}
+	enforceNotLocked(course);
 
 	await db
 		.update(courseItems)
@@ -609,7 +614,7 @@ export const updateCourseItem = command(UpdateCourseItemSchema, async (data) =>
 	// Verify the course belongs to the current org
 	const context = await getOrganisationContext();
 	const [course] = await db
-		.select({ id: courses.id })
+		.select({ id: courses.id, lockReason: courses.lockReason })
 		.from(courses)
 		.where(
 			and(
@@ -623,6 +628,7 @@ export const updateCourseItem = command(UpdateCourseItemSchema, async (data) =>
 	if (!course) {
 		throw error(404, "Course not found");
 	}
+	enforceNotLocked(course);
 
 	const updates: Record<string, unknown> = { updatedAt: new Date() };
 
@@ -653,7 +659,7 @@ export const reorderCourseItems = command(ReorderCourseItemsSchema, async (data)
 
 	// Verify course belongs to org
 	const [course] = await db
-		.select({ id: courses.id })
+		.select({ id: courses.id, lockReason: courses.lockReason })
 		.from(courses)
 		.where(
 			and(
@@ -667,6 +673,7 @@ export const reorderCourseItems = command(ReorderCourseItemsSchema, async (data)
 	if (!course) {
 		throw error(404, "Course not found");
 	}
+	enforceNotLocked(course);
 
 	// Batch update each item's sort_order and section
 	for (const item of data.items) {
@@ -718,6 +725,7 @@ export const publishCourse = command(v.pipe(v.string(), v.uuid()), async (course
 	if (!course) {
 		throw error(404, "Course not found");
 	}
+	enforceNotLocked(course);
 
 	if (course.status !== "draft") {
 		throw error(400, "Only draft courses can be published");
@@ -760,7 +768,7 @@ export const createSection = command(CreateSectionSchema, async (data) => {
 
 	// Verify course belongs to org
 	const [course] = await db
-		.select({ id: courses.id })
+		.select({ id: courses.id, lockReason: courses.lockReason })
 		.from(courses)
 		.where(
 			and(
@@ -774,6 +782,7 @@ export const createSection = command(CreateSectionSchema, async (data) => {
 	if (!course) {
 		throw error(404, "Course not found");
 	}
+	enforceNotLocked(course);
 
 	// Get max sort_order for sections in this course
 	const [maxSort] = await db
@@ -834,7 +843,7 @@ export const updateSection = command(UpdateSectionSchema, async (data) => {
 
 	const context = await getOrganisationContext();
 	const [course] = await db
-		.select({ id: courses.id })
+		.select({ id: courses.id, lockReason: courses.lockReason })
 		.from(courses)
 		.where(
 			and(
@@ -848,6 +857,7 @@ export const updateSection = command(UpdateSectionSchema, async (data) => {
 	if (!course) {
 		throw error(404, "Course not found");
 	}
+	enforceNotLocked(course);
 
 	const updates: Record<string, unknown> = { updatedAt: new Date() };
 	if (data.title !== undefined) updates["title"] = data.title;
@@ -887,7 +897,7 @@ export const deleteSection = command(v.pipe(v.string(), v.uuid()), async (sectio
 
 	const context = await getOrganisationContext();
 	const [course] = await db
-		.select({ id: courses.id })
+		.select({ id: courses.id, lockReason: courses.lockReason })
 		.from(courses)
 		.where(
 			and(
@@ -901,6 +911,7 @@ export const deleteSection = command(v.pipe(v.string(), v.uuid()), async (sectio
 	if (!course) {
 		throw error(404, "Course not found");
 	}
+	enforceNotLocked(course);
 
 	// Hard delete — items get section_id = NULL via ON DELETE SET NULL
 	await db.delete(courseSections).where(eq(courseSections.id, sectionId));
@@ -922,7 +933,7 @@ export const reorderSections = command(ReorderSectionsSchema, async (data) => {
 	const context = await requireOrganisationRole(["owner", "admin"]);
 
 	const [course] = await db
-		.select({ id: courses.id })
+		.select({ id: courses.id, lockReason: courses.lockReason })
 		.from(courses)
 		.where(
 			and(
@@ -936,6 +947,7 @@ export const reorderSections = command(ReorderSectionsSchema, async (data) => {
 	if (!course) {
 		throw error(404, "Course not found");
 	}
+	enforceNotLocked(course);
 
 	for (const section of data.sections) {
 		await db
@@ -16,6 +16,7 @@ export interface CourseListItem {
 	archivedAt: Date | null;
 	createdAt: Date;
 	updatedAt: Date;
+	lockReason: string | null; // set while read-only after a downgrade, see $lib/utils/locks
 	itemCount: number;
 	enrolmentCount: number;
 	totalDurationMinutes: number;
@@ -68,6 +69,7 @@ export interface CourseWithItems {
 	createdAt: Date;
 	updatedAt: Date;
 	createdBy: string | null;
+	lockReason: string | null;
 	items: CourseItemWithContent[];
 	sections: CourseSection[];
 }
@@ -299,7 +299,7 @@ export const getCourseEnrolments = query(
 				status: enrolments.status,
 				enrolledAt: enrolments.enrolledAt,
 				completedAt: enrolments.completedAt,
-				userId: enrolments.userId,
+				userId: users.id,
 				userEmail: users.email,
 				userAvatar: users.avatar,
 			})
@@ -17,6 +17,7 @@ export type ContentInfo = {
 	libraryVersion: string;
 	createdAt: string;
 	updatedAt: string;
+	lockReason?: string; // set while read-only after a downgrade, see $lib/utils/locks
 };
 
 export type ContentListResponse = {
@@ -100,10 +100,14 @@ async function callH5PAPI<T>(
 
 /**
  * Get cached content type list from the H5P Hub.
- * Includes install status for each content type.
+ * Includes install status for each content type and leaves out the
+ * libraries disabled for the organisation.
  */
 export const getContentTypeCache = query(async () => {
-	const response = await callH5PAPI<ContentTypeCacheEntry[]>("/content-type-cache");
+	const context = await getOrganisationContext();
+	const response = await callH5PAPI<ContentTypeCacheEntry[]>(
+		`/content-type-cache?orgId=${context.organisationId}`,
+	);
 
 	if (!response.success || !response.data) {
 		throw new Error(response.message || "Failed to get content type cache");
@@ -18,6 +18,7 @@ import { requireOrganisationRole, getOrganisationContext } from "$lib/server/org
 import { logActivity } from "$lib/server/db-helpers";
 import { encrypt, decryptProfileFields } from "$lib/server/crypto";
 import { eq } from "drizzle-orm";
+import { isValidTimezone } from "$lib/utils/periods";
 
 // =============================================================================
 // Validation Schemas
@@ -65,6 +66,25 @@ const UpdateProfileSchema = v.object({
 	defaultPaymentTerms: v.optional(v.picklist(["DUE_ON_RECEIPT", "NET_7", "NET_14", "NET_30"])),
 });
 
+const UpdateLocaleSchema = v.object({
+	locale: v.pipe(
+		v.string(),
+		v.maxLength(35),
+		v.check((tag) => {
+			try {
+				return Intl.getCanonicalLocales(tag).length === 1;
+			} catch {
+				return false;
+			}
+		}, "Locale must be a language tag such as en-AU"),
+	),
+	timezone: v.pipe(
+		v.string(),
+		v.maxLength(64),
+		v.check(isValidTimezone, "Timezone must be an IANA name such as Australia/Sydney"),
+	),
+});
+
 // =============================================================================
 // Query Functions (Read Operations)
 // =============================================================================
@@ -96,6 +116,8 @@ export const getOrganisationProfile = query(async () => {
 			secondaryColor: organisations.secondaryColor,
 			accentColor: organisations.accentColor,
 			accentGradient: organisations.accentGradient,
+			locale: organisations.locale,
+			timezone: organisations.timezone,
 		})
 		.from(organisations)
 		.where(eq(organisations.id, context.organisationId))
@@ -237,6 +259,29 @@ export const updateOrganisationProfile = command(UpdateProfileSchema, async (dat
 	return profile;
 });
 
+/**
+ * Update the organisation's locale and timezone (admin/owner only).
+ * These control how reports and billing are formatted and when monthly
+ * usage periods start.
+ */
+export const updateOrganisationLocale = command(UpdateLocaleSchema, async (data) => {
+	const context = await requireOrganisationRole(["owner", "admin"]);
+
+	const [locale] = Intl.getCanonicalLocales(data.locale);
+
+	const [organisation] = await db
+		.update(organisations)
+		.set({ locale, timezone: data.timezone, updatedAt: new Date() })
+		.where(eq(organisations.id, context.organisationId))
+		.returning({ locale: organisations.locale, timezone: organisations.timezone });
+
+	await logActivity("organisation.locale_updated", "organisation", context.organisationId, {
+		newValues: { locale, timezone: data.timezone },
+	});
+
+	return organisation;
+});
+
 // =============================================================================
 // Setup Status Query
 // =============================================================================
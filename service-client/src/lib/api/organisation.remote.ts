@@ -8,8 +8,9 @@
  * - Uses Valibot for validation (NOT Zod)
  */
 
-import { query, command } from "$app/server";
+import { query, command, getRequestEvent } from "$app/server";
 import { env } from "$env/dynamic/public";
+import { env as privateEnv } from "$env/dynamic/private";
 import * as v from "valibot";
 import { db } from "$lib/server/db";
 import {
@@ -401,6 +402,9 @@ export const createOrganisation = command(CreateOrganisationSchema, async (data)
 		// Non-critical: organisation still works without auto-seeded forms
 	}
 
+	// Install and enable the default H5P library profile in the background
+	await applyDefaultLibraryProfile(organisation.id);
+
 	// Mark invite as used
 	if (validInvite) {
 		await db
@@ -1044,3 +1048,31 @@ export const updateMyAvatar = command(UpdateAvatarSchema, async (data) => {
 		newValues: { hasAvatar: data.avatarUrl.length > 0 },
 	});
 });
+
+/**
+ * Ask service-core to apply the default H5P install profile to a new
+ * organisation. Non-critical: without a default profile, or if the request
+ * fails, the organisation starts with no content types enabled.
+ */
+async function applyDefaultLibraryProfile(organisationId: string): Promise<void> {
+	const accessToken = getRequestEvent().cookies.get("access_token");
+	try {
+		const response = await fetch(
+			`${privateEnv.CORE_URL}/api/v1/organisations/${organisationId}/library-profile`,
+			{
+				method: "POST",
+				headers: {
+					"Content-Type": "application/json",
+					...(accessToken ? { Authorization: `Bearer ${accessToken}` } : {}),
+				},
+				body: "{}",
+			},
+		);
+		// 404: no default profile is set
+		if (!response.ok && response.status !== 404) {
+			console.error("Applying default library profile failed:", response.status, await response.text());
+		}
+	} catch (err) {
+		console.error("Applying default library profile failed:", err);
+	}
+}
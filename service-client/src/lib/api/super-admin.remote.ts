@@ -5,8 +5,9 @@
  * All functions require super admin access.
  */
 
-import { query, command } from "$app/server";
+import { query, command, getRequestEvent } from "$app/server";
 import * as v from "valibot";
+import { env } from "$env/dynamic/private";
 import { db } from "$lib/server/db";
 import {
 	organisations,
@@ -871,7 +872,9 @@ export const unsuspendUser = command(v.pipe(v.string(), v.uuid()), async (userId
 
 /**
  * Permanently delete a user account
- * Removes the user and all their organisation memberships
+ * Removes the user and all their organisation memberships. Their xAPI
+ * statements, progress and enrolments are anonymized first and kept for
+ * aggregate analytics.
  */
 export const deleteUser = command(v.pipe(v.string(), v.uuid()), async (userId) => {
 	const admin = await requireSuperAdmin();
@@ -914,6 +917,12 @@ export const deleteUser = command(v.pipe(v.string(), v.uuid()), async (userId) =
 		}
 	}
 
+	// Anonymize analytics before the delete cascades them away
+	const anonymized = await anonymizeUserAnalytics(userId);
+	if (!anonymized) {
+		return { success: false, error: "Failed to anonymize the user's learning analytics. Please try again." };
+	}
+
 	// Delete all memberships first
 	await db.delete(organisationMemberships).where(eq(organisationMemberships.userId, userId));
 
@@ -922,3 +931,40 @@ export const deleteUser = command(v.pipe(v.string(), v.uuid()), async (userId) =
 
 	return { success: true };
 });
+
+/**
+ * Anonymize a user's xAPI statements, progress and enrolments without deleting
+ * the account. Used by deleteUser, and available on its own for GDPR erasure
+ * requests handled outside account deletion.
+ */
+export const anonymizeUser = command(v.pipe(v.string(), v.uuid()), async (userId) => {
+	await requireSuperAdmin();
+
+	const anonymized = await anonymizeUserAnalytics(userId);
+	if (!anonymized) {
+		return { success: false, error: "Failed to anonymize the user's learning analytics" };
+	}
+	return { success: true };
+});
+
+/**
+ * Ask service-core to detach a user's analytics from their account.
+ * Returns false if the request failed; the call is safe to retry.
+ */
+async function anonymizeUserAnalytics(userId: string): Promise<boolean> {
+	const accessToken = getRequestEvent().cookies.get("access_token");
+	try {
+		const response = await fetch(`${env.CORE_URL}/api/v1/admin/users/${userId}/anonymize`, {
+			method: "POST",
+			headers: accessToken ? { Authorization: `Bearer ${accessToken}` } : {},
+		});
+		if (!response.ok) {
+			console.error("Analytics anonymization failed:", response.status, await response.text());
+			return false;
+		}
+		return true;
+	} catch (err) {
+		console.error("Analytics anonymization failed:", err);
+		return false;
+	}
+}
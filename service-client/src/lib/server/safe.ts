@@ -1,8 +1,17 @@
+/**
+ * Formatting settings some core endpoints attach to successful responses.
+ */
+export type ResponseMeta = {
+	locale: string;
+	timezone: string;
+};
+
 export type Safe<T> =
 	| {
 			success: true;
 			data: T;
 			message: string;
+			meta?: ResponseMeta;
 	  }
 	| {
 			success: false;
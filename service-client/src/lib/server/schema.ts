@@ -96,6 +96,10 @@ export const organisations = pgTable("organisations", {
 	// Soft delete (GDPR compliance)
 	deletedAt: timestamp("deleted_at", { withTimezone: true }),
 	deletionScheduledFor: timestamp("deletion_scheduled_for", { withTimezone: true }),
+
+	// Locale: how reports and billing are formatted, and where usage periods start
+	locale: text("locale").notNull().default("en-AU"),
+	timezone: text("timezone").notNull().default("UTC"),
 });
 
 // Organisation Memberships table - User-Organisation relationships
@@ -609,6 +613,10 @@ export const h5pContent = pgTable(
 		// Status
 		status: varchar("status", { length: 20 }).notNull().default("draft"),
 		deletedAt: timestamp("deleted_at", { withTimezone: true }),
+
+		// Grace-period lock (read-only after downgrade to free tier)
+		lockedAt: timestamp("locked_at", { withTimezone: true }),
+		lockReason: varchar("lock_reason", { length: 40 }),
 	},
 	(table) => ({
 		uniqueOrgSlug: unique().on(table.orgId, table.slug),
@@ -696,6 +704,10 @@ export const courses = pgTable(
 		publishedAt: timestamp("published_at", { withTimezone: true }),
 		archivedAt: timestamp("archived_at", { withTimezone: true }),
 		deletedAt: timestamp("deleted_at", { withTimezone: true }),
+
+		// Grace-period lock (read-only after downgrade to free tier)
+		lockedAt: timestamp("locked_at", { withTimezone: true }),
+		lockReason: varchar("lock_reason", { length: 40 }),
 	},
 	(table) => ({
 		uniqueOrgSlug: unique().on(table.orgId, table.slug),
@@ -772,9 +784,9 @@ export const enrolments = pgTable(
 		courseId: uuid("course_id")
 			.notNull()
 			.references(() => courses.id, { onDelete: "cascade" }),
-		userId: uuid("user_id")
-			.notNull()
-			.references(() => users.id, { onDelete: "cascade" }),
+		// NULL once anonymized; actorId then holds the user's pseudonym
+		userId: uuid("user_id").references(() => users.id, { onDelete: "cascade" }),
+		actorId: uuid("actor_id"),
 
 		status: varchar("status", { length: 20 }).notNull().default("active"),
 		enrolledAt: timestamp("enrolled_at", { withTimezone: true }).notNull().defaultNow(),
@@ -806,9 +818,9 @@ export const progressRecords = pgTable(
 		contentId: uuid("content_id")
 			.notNull()
 			.references(() => h5pContent.id, { onDelete: "cascade" }),
-		userId: uuid("user_id")
-			.notNull()
-			.references(() => users.id, { onDelete: "cascade" }),
+		// NULL once anonymized; actorId then holds the user's pseudonym
+		userId: uuid("user_id").references(() => users.id, { onDelete: "cascade" }),
+		actorId: uuid("actor_id"),
 
 		score: numeric("score", { precision: 5, scale: 2 }),
 		maxScore: numeric("max_score", { precision: 5, scale: 2 }),
@@ -837,9 +849,9 @@ export const xapiStatements = pgTable(
 		orgId: uuid("org_id")
 			.notNull()
 			.references(() => organisations.id, { onDelete: "cascade" }),
-		userId: uuid("user_id")
-			.notNull()
-			.references(() => users.id, { onDelete: "cascade" }),
+		// NULL once anonymized; actorId then holds the user's pseudonym
+		userId: uuid("user_id").references(() => users.id, { onDelete: "cascade" }),
+		actorId: uuid("actor_id"),
 		contentId: uuid("content_id").references(() => h5pContent.id, { onDelete: "set null" }),
 
 		verb: varchar("verb", { length: 255 }).notNull(),
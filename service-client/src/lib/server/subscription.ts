@@ -14,6 +14,8 @@ import { eq, and, count, sql, isNull } from "drizzle-orm";
 import { error } from "@sveltejs/kit";
 import { getOrganisationContext } from "$lib/server/organisation";
 import { formatDate } from "$lib/utils/formatting";
+import { startOfMonth, startOfNextMonth } from "$lib/utils/periods";
+import { lockReasonMessage } from "$lib/utils/locks";
 
 // =============================================================================
 // Tier Definitions
@@ -221,15 +223,30 @@ export async function canAddMember(organisationId?: string): Promise<{
 }
 
 
+/**
+ * Get an organisation's IANA timezone, used for monthly usage periods.
+ */
+async function getOrganisationTimezone(organisationId: string): Promise<string> {
+	const [organisation] = await db
+		.select({ timezone: organisations.timezone })
+		.from(organisations)
+		.where(eq(organisations.id, organisationId))
+		.limit(1);
+
+	return organisation?.timezone ?? "UTC";
+}
+
 /**
  * Get AI generation count for current month.
  * Uses the aiGenerationsThisMonth counter on the organisations table.
+ * Months start at midnight in the organisation's timezone.
  */
 export async function getMonthlyAIGenerationCount(organisationId: string): Promise<number> {
 	const [organisation] = await db
 		.select({
 			aiGenerationsThisMonth: organisations.aiGenerationsThisMonth,
 			aiGenerationsResetAt: organisations.aiGenerationsResetAt,
+			timezone: organisations.timezone,
 		})
 		.from(organisations)
 		.where(eq(organisations.id, organisationId))
@@ -239,9 +256,9 @@ export async function getMonthlyAIGenerationCount(organisationId: string): Promi
 
 	// Check if counter needs to be reset (new month)
 	const now = new Date();
-	const startOfMonth = new Date(now.getFullYear(), now.getMonth(), 1);
+	const periodStart = startOfMonth(now, organisation.timezone);
 
-	if (!organisation.aiGenerationsResetAt || organisation.aiGenerationsResetAt < startOfMonth) {
+	if (!organisation.aiGenerationsResetAt || organisation.aiGenerationsResetAt < periodStart) {
 		// Reset counter for new month
 		await db
 			.update(organisations)
@@ -272,11 +289,8 @@ export async function canGenerateWithAI(organisationId?: string): Promise<{
 	const { limits } = await getOrganisationTierLimits();
 	const currentCount = await getMonthlyAIGenerationCount(targetOrganisationId);
 
-	// Calculate when limit resets (start of next month)
-	const resetsAt = new Date();
-	resetsAt.setMonth(resetsAt.getMonth() + 1);
-	resetsAt.setDate(1);
-	resetsAt.setHours(0, 0, 0, 0);
+	// Calculate when limit resets (start of next month in the organisation's timezone)
+	const resetsAt = startOfNextMonth(new Date(), await getOrganisationTimezone(targetOrganisationId));
 
 	if (limits.maxAIGenerationsPerMonth === -1) {
 		return { allowed: true, current: currentCount, limit: -1, unlimited: true, resetsAt };
@@ -298,17 +312,17 @@ export async function canGenerateWithAI(organisationId?: string): Promise<{
  */
 export async function incrementAIGenerationCount(organisationId: string): Promise<void> {
 	const now = new Date();
-	const startOfMonth = new Date(now.getFullYear(), now.getMonth(), 1);
+	const periodStart = startOfMonth(now, await getOrganisationTimezone(organisationId));
 
 	await db.execute(sql`
 		UPDATE organisations
 		SET ai_generations_this_month = CASE
-			WHEN ai_generations_reset_at IS NULL OR ai_generations_reset_at < ${startOfMonth}
+			WHEN ai_generations_reset_at IS NULL OR ai_generations_reset_at < ${periodStart}
 			THEN 1
 			ELSE ai_generations_this_month + 1
 		END,
 		ai_generations_reset_at = CASE
-			WHEN ai_generations_reset_at IS NULL OR ai_generations_reset_at < ${startOfMonth}
+			WHEN ai_generations_reset_at IS NULL OR ai_generations_reset_at < ${periodStart}
 			THEN ${now}
 			ELSE ai_generations_reset_at
 		END
@@ -409,6 +423,17 @@ export async function enforceAIGenerationLimit(organisationId?: string): Promise
 	}
 }
 
+/**
+ * Enforce grace-period read-only mode - throws if the resource is locked.
+ * Locked courses stay listable and viewable but can't be modified until the
+ * organisation upgrades; lock reasons are set when a subscription lapses.
+ */
+export function enforceNotLocked(resource: { lockReason: string | null }): void {
+	if (resource.lockReason) {
+		throw error(423, lockReasonMessage(resource.lockReason));
+	}
+}
+
 /**
  * Require a specific feature - throws if not available.
  */
@@ -1,18 +1,25 @@
 /**
  * Shared formatting utilities for currency, dates, and relative time.
  * Replaces scattered Intl.NumberFormat and toLocaleDateString calls.
+ * Pass the locale and timezone from a response's meta block to format core
+ * API data the way the organisation has configured.
  */
 
 /**
  * Format a number as currency.
  * @param amount - The amount to format (number or string from decimal columns)
  * @param currency - ISO currency code (default: 'AUD')
+ * @param locale - BCP 47 locale tag (default: 'en-AU')
  */
-export function formatCurrency(amount: number | string | null | undefined, currency = 'AUD'): string {
+export function formatCurrency(
+	amount: number | string | null | undefined,
+	currency = 'AUD',
+	locale = 'en-AU'
+): string {
 	if (amount == null) return '$0.00';
 	const num = typeof amount === 'string' ? parseFloat(amount) : amount;
 	if (isNaN(num)) return '$0.00';
-	return new Intl.NumberFormat('en-AU', {
+	return new Intl.NumberFormat(locale, {
 		style: 'currency',
 		currency,
 		minimumFractionDigits: 2,
@@ -24,10 +31,14 @@ export function formatCurrency(amount: number | string | null | undefined, curre
  * Format a date for display.
  * @param date - Date object, ISO string, or null/undefined
  * @param style - 'short' (1/2/25), 'medium' (1 Feb 2025), 'long' (1 February 2025)
+ * @param locale - BCP 47 locale tag (default: 'en-AU')
+ * @param timeZone - IANA timezone (default: the runtime's)
  */
 export function formatDate(
 	date: Date | string | null | undefined,
-	style: 'short' | 'medium' | 'long' = 'medium'
+	style: 'short' | 'medium' | 'long' = 'medium',
+	locale = 'en-AU',
+	timeZone?: string
 ): string {
 	if (!date) return '';
 	const d = typeof date === 'string' ? new Date(date) : date;
@@ -39,17 +50,22 @@ export function formatDate(
 		long: { day: 'numeric', month: 'long', year: 'numeric' } as const,
 	}[style];
 
-	return d.toLocaleDateString('en-AU', options);
+	return d.toLocaleDateString(locale, { ...options, timeZone });
 }
 
 /**
  * Format a date with time for display.
  */
-export function formatDateTime(date: Date | string | null | undefined): string {
+export function formatDateTime(
+	date: Date | string | null | undefined,
+	locale = 'en-AU',
+	timeZone?: string
+): string {
 	if (!date) return '';
 	const d = typeof date === 'string' ? new Date(date) : date;
 	if (isNaN(d.getTime())) return '';
-	return d.toLocaleDateString('en-AU', {
+	return d.toLocaleDateString(locale, {
+		timeZone,
 		day: 'numeric',
 		month: 'short',
 		year: 'numeric',
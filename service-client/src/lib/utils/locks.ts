@@ -0,0 +1,27 @@
+/**
+ * Grace-period lock reasons.
+ * When a subscription lapses to the free tier, courses and H5P content above the
+ * free limits become read-only. The service layer stores one of these codes on
+ * each locked row so the UI can explain the lock and point to an upgrade.
+ */
+
+export type LockReason = 'course_limit_exceeded' | 'course_locked';
+
+const LOCK_REASON_MESSAGES: Record<LockReason, string> = {
+	course_limit_exceeded:
+		'This course is over the Free plan course limit and is read-only. Upgrade your plan to edit it again.',
+	course_locked:
+		'This content is only used by read-only courses. Upgrade your plan to edit or embed it again.',
+};
+
+/**
+ * Explain a lock reason to the user.
+ * @param reason - Lock reason code from the database or API
+ */
+export function lockReasonMessage(reason: string | null | undefined): string {
+	if (!reason) return '';
+	return (
+		LOCK_REASON_MESSAGES[reason as LockReason] ??
+		'This item is read-only on your current plan. Upgrade to edit it again.'
+	);
+}
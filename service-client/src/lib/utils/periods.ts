@@ -0,0 +1,69 @@
+/**
+ * Calendar period boundaries in an organisation's timezone.
+ * Monthly usage limits reset at midnight on the 1st where the organisation is,
+ * not where the server runs. Mirrors pkg.MonthPeriod in service-core.
+ */
+
+/**
+ * Offset of a timezone from UTC at the given instant, in milliseconds.
+ */
+function timezoneOffset(date: Date, timeZone: string): number {
+	const parts = new Intl.DateTimeFormat('en-US', {
+		timeZone,
+		hourCycle: 'h23',
+		year: 'numeric',
+		month: 'numeric',
+		day: 'numeric',
+		hour: 'numeric',
+		minute: 'numeric',
+		second: 'numeric',
+	}).formatToParts(date);
+	const get = (type: string) => Number(parts.find((p) => p.type === type)?.value ?? 0);
+	const asUTC = Date.UTC(get('year'), get('month') - 1, get('day'), get('hour'), get('minute'), get('second'));
+	return asUTC - Math.floor(date.getTime() / 1000) * 1000;
+}
+
+/**
+ * Check whether a string is an IANA timezone the runtime knows.
+ */
+export function isValidTimezone(timeZone: string): boolean {
+	try {
+		new Intl.DateTimeFormat('en-US', { timeZone });
+		return true;
+	} catch {
+		return false;
+	}
+}
+
+/**
+ * The instant of midnight on the given day in a timezone.
+ * @param month - 0-based, as in Date; out-of-range values roll over
+ */
+function zonedMidnight(year: number, month: number, day: number, timeZone: string): Date {
+	const guess = Date.UTC(year, month, day);
+	const offset = timezoneOffset(new Date(guess), timeZone);
+	let result = guess - offset;
+	// Correct when a DST change falls between the guess and the real instant
+	const actual = timezoneOffset(new Date(result), timeZone);
+	if (actual !== offset) result = guess - actual;
+	return new Date(result);
+}
+
+/**
+ * Start of the calendar month containing `date` in `timeZone`.
+ * Unknown timezones fall back to UTC.
+ */
+export function startOfMonth(date: Date, timeZone = 'UTC'): Date {
+	const tz = isValidTimezone(timeZone) ? timeZone : 'UTC';
+	const local = new Date(date.getTime() + timezoneOffset(date, tz));
+	return zonedMidnight(local.getUTCFullYear(), local.getUTCMonth(), 1, tz);
+}
+
+/**
+ * Start of the calendar month after the one containing `date` in `timeZone`.
+ */
+export function startOfNextMonth(date: Date, timeZone = 'UTC'): Date {
+	const tz = isValidTimezone(timeZone) ? timeZone : 'UTC';
+	const local = new Date(date.getTime() + timezoneOffset(date, tz));
+	return zonedMidnight(local.getUTCFullYear(), local.getUTCMonth() + 1, 1, tz);
+}
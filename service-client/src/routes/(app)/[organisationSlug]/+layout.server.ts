@@ -34,6 +34,8 @@ export const load: LayoutServerLoad = async ({ locals, params, cookies, url }) =
 			primaryColor: organisations.primaryColor,
 			secondaryColor: organisations.secondaryColor,
 			accentColor: organisations.accentColor,
+			locale: organisations.locale,
+			timezone: organisations.timezone,
 			status: organisations.status,
 			deletedAt: organisations.deletedAt,
 		})
@@ -183,6 +185,8 @@ export const load: LayoutServerLoad = async ({ locals, params, cookies, url }) =
 			primaryColor: organisation.primaryColor,
 			secondaryColor: organisation.secondaryColor,
 			accentColor: organisation.accentColor,
+			locale: organisation.locale,
+			timezone: organisation.timezone,
 		},
 		membership: {
 			id: effectiveMembership.id,
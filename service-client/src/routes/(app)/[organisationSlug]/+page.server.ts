@@ -19,16 +19,15 @@ import {
 	formSubmissions,
 } from "$lib/server/schema";
 import { eq, count, and, gte, desc } from "drizzle-orm";
+import { startOfMonth } from "$lib/utils/periods";
 
 export const load: PageServerLoad = async ({ parent }) => {
 	// Get organisation context from layout
 	const { organisation } = await parent();
 	const organisationId = organisation.id;
 
-	// First of current month for time-scoped queries
-	const firstOfMonth = new Date();
-	firstOfMonth.setDate(1);
-	firstOfMonth.setHours(0, 0, 0, 0);
+	// First of current month, in the organisation's timezone, for time-scoped queries
+	const firstOfMonth = startOfMonth(new Date(), organisation.timezone);
 
 	// Run all stat queries in parallel
 	const [
@@ -22,12 +22,12 @@ async function fetchH5P<T>(endpoint: string, accessToken: string | undefined): P
 }
 
 export const load: PageServerLoad = async ({ parent, cookies }) => {
-	const { membership } = await parent();
+	const { membership, organisation } = await parent();
 	const isAdmin = hasPermission(membership.role, "settings:edit");
 	const accessToken = cookies.get("access_token");
 
 	const [contentTypes, installedLibraries] = await Promise.all([
-		fetchH5P<ContentTypeCacheEntry>("/content-type-cache", accessToken),
+		fetchH5P<ContentTypeCacheEntry>(`/content-type-cache?orgId=${organisation.id}`, accessToken),
 		fetchH5P<LibraryInfo>("/libraries", accessToken),
 	]);
 
@@ -1,10 +1,32 @@
 import puppeteer from "@cloudflare/puppeteer";
+import type { PaperFormat } from "@cloudflare/puppeteer";
 
 interface Env {
 	BROWSER: Fetcher;
+	AI: Ai;
 }
 
 const NAVIGATION_TIMEOUT = 30000;
+const SELECTOR_TIMEOUT = 10000;
+
+// Reported by GET /health so clients can negotiate which endpoints to use.
+const WORKER_VERSION = "1.6.0";
+const FEATURES = ["markdown", "links", "scrape", "pdf", "pdf-html", "html", "json", "page-options", "screenshot"];
+const SCREENSHOT_FORMATS = ["png", "jpeg"];
+const PDF_FORMATS = ["A3", "A4", "A5", "Letter", "Legal", "Tabloid"];
+const WAIT_UNTIL_EVENTS = ["load", "domcontentloaded", "networkidle0", "networkidle2"];
+// Resource types a request may block. The page document itself can't be.
+const BLOCKABLE_RESOURCES = [
+	"stylesheet", "image", "media", "font", "script", "texttrack",
+	"xhr", "fetch", "eventsource", "websocket", "manifest", "other",
+];
+const MAX_VIEWPORT_SIZE = 8192;
+
+// Workers AI model used by /json; it must support JSON Schema response formats.
+const EXTRACTION_MODEL = "@cf/meta/llama-3.3-70b-instruct-fp8-fast";
+// Page text beyond this many characters is dropped before extraction.
+const EXTRACTION_MAX_CHARS = 24000;
+const MAX_CONCURRENCY = 2; // Browser Rendering concurrent session limit per account
 
 export default {
 	async fetch(request: Request, env: Env): Promise<Response> {
@@ -12,7 +34,12 @@ export default {
 		const path = url.pathname;
 
 		if (request.method === "GET" && path === "/health") {
-			return Response.json({ status: "ok" });
+			return Response.json({
+				status: "ok",
+				version: WORKER_VERSION,
+				features: FEATURES,
+				maxConcurrency: MAX_CONCURRENCY,
+			});
 		}
 
 		if (request.method !== "POST") {
@@ -29,17 +56,90 @@ export default {
 					return await handleLinks(env, body);
 				case "/scrape":
 					return await handleScrape(env, body);
+				case "/pdf":
+					return await handlePdf(env, body);
+				case "/screenshot":
+					return await handleScreenshot(env, body);
+				case "/html":
+					return await handleHtml(env, body);
+				case "/json":
+					return await handleJson(env, body);
 				default:
 					return Response.json({ error: "Not found" }, { status: 404 });
 			}
 		} catch (err) {
+			if (err instanceof BadRequestError) {
+				return Response.json({ error: err.message }, { status: 400 });
+			}
 			const message = err instanceof Error ? err.message : "Internal server error";
 			return Response.json({ error: message }, { status: 500 });
 		}
 	},
 } satisfies ExportedHandler<Env>;
 
-async function withBrowser<T>(env: Env, targetUrl: string, fn: (page: puppeteer.Page) => Promise<T>): Promise<T> {
+// BadRequestError is thrown for invalid request fields and answered with a 400.
+class BadRequestError extends Error {}
+
+interface Viewport {
+	width: number;
+	height: number;
+	deviceScaleFactor?: number;
+	isMobile?: boolean;
+}
+
+interface PageOptions {
+	waitUntil?: puppeteer.PuppeteerLifeCycleEvent;
+	waitForSelector?: string;
+	headers?: Record<string, string>;
+	cookies?: { name: string; value: string; domain?: string; path?: string }[];
+	userAgent?: string;
+	viewport?: Viewport;
+	blockResources?: string[];
+	timeoutMs?: number;
+	disableJavaScript?: boolean;
+}
+
+// pageOptions reads the optional page loading settings every endpoint accepts.
+function pageOptions(body: Record<string, unknown>): PageOptions {
+	const waitUntil = body.waitUntil as string | undefined;
+	if (waitUntil && !WAIT_UNTIL_EVENTS.includes(waitUntil)) {
+		throw new BadRequestError(`waitUntil must be one of ${WAIT_UNTIL_EVENTS.join(", ")}`);
+	}
+	const viewport = body.viewport as Viewport | undefined;
+	if (viewport) {
+		const inRange = (n: unknown) => typeof n === "number" && n > 0 && n <= MAX_VIEWPORT_SIZE;
+		if (!inRange(viewport.width) || !inRange(viewport.height)) {
+			throw new BadRequestError(`viewport width and height must be between 1 and ${MAX_VIEWPORT_SIZE}`);
+		}
+	}
+	const blockResources = body.blockResources as string[] | undefined;
+	const unblockable = blockResources?.find((r) => !BLOCKABLE_RESOURCES.includes(r));
+	if (unblockable) {
+		throw new BadRequestError(`cannot block resource type "${unblockable}"`);
+	}
+	const timeoutMs = body.timeoutMs as number | undefined;
+	if (timeoutMs !== undefined && (typeof timeoutMs !== "number" || timeoutMs < 0)) {
+		throw new BadRequestError("timeoutMs must be a non-negative number");
+	}
+	return {
+		waitUntil: waitUntil as puppeteer.PuppeteerLifeCycleEvent | undefined,
+		waitForSelector: body.waitForSelector as string | undefined,
+		headers: body.headers as Record<string, string> | undefined,
+		cookies: body.cookies as PageOptions["cookies"],
+		userAgent: body.userAgent as string | undefined,
+		viewport,
+		blockResources,
+		timeoutMs,
+		disableJavaScript: body.disableJavaScript === true,
+	};
+}
+
+async function withBrowser<T>(
+	env: Env,
+	targetUrl: string,
+	fn: (page: puppeteer.Page) => Promise<T>,
+	options: PageOptions = {},
+): Promise<T> {
 	const parsed = new URL(targetUrl);
 	if (!["http:", "https:"].includes(parsed.protocol)) {
 		throw new Error("URL must use http or https protocol");
@@ -48,7 +148,72 @@ async function withBrowser<T>(env: Env, targetUrl: string, fn: (page: puppeteer.
 	const browser = await puppeteer.launch(env.BROWSER);
 	const page = await browser.newPage();
 	try {
-		await page.goto(targetUrl, { waitUntil: "networkidle0", timeout: NAVIGATION_TIMEOUT });
+		if (options.userAgent) {
+			await page.setUserAgent(options.userAgent);
+		}
+		if (options.viewport) {
+			await page.setViewport(options.viewport);
+		}
+		if (options.disableJavaScript) {
+			await page.setJavaScriptEnabled(false);
+		}
+		if (options.blockResources?.length) {
+			const blocked = new Set(options.blockResources);
+			await page.setRequestInterception(true);
+			page.on("request", (req) => {
+				if (blocked.has(req.resourceType())) {
+					void req.abort("blockedbyclient");
+				} else {
+					void req.continue();
+				}
+			});
+		}
+		if (options.headers) {
+			await page.setExtraHTTPHeaders(options.headers);
+		}
+		if (options.cookies?.length) {
+			await page.setCookie(
+				...options.cookies.map((c) => ({ ...c, domain: c.domain || parsed.hostname, path: c.path || "/" })),
+			);
+		}
+		await page.goto(targetUrl, {
+			waitUntil: options.waitUntil ?? "networkidle0",
+			timeout: options.timeoutMs || NAVIGATION_TIMEOUT,
+		});
+		if (options.waitForSelector) {
+			await page.waitForSelector(options.waitForSelector, { timeout: SELECTOR_TIMEOUT });
+		}
+		return await fn(page);
+	} finally {
+		await page.close();
+		await browser.close();
+	}
+}
+
+// withContent is withBrowser for a document supplied inline rather than
+// fetched. Only options that apply without navigation are honoured.
+async function withContent<T>(
+	env: Env,
+	html: string,
+	fn: (page: puppeteer.Page) => Promise<T>,
+	options: PageOptions = {},
+): Promise<T> {
+	const browser = await puppeteer.launch(env.BROWSER);
+	const page = await browser.newPage();
+	try {
+		if (options.viewport) {
+			await page.setViewport(options.viewport);
+		}
+		if (options.disableJavaScript) {
+			await page.setJavaScriptEnabled(false);
+		}
+		await page.setContent(html, {
+			waitUntil: options.waitUntil ?? "networkidle0",
+			timeout: options.timeoutMs || NAVIGATION_TIMEOUT,
+		});
+		if (options.waitForSelector) {
+			await page.waitForSelector(options.waitForSelector, { timeout: SELECTOR_TIMEOUT });
+		}
 		return await fn(page);
 	} finally {
 		await page.close();
@@ -61,6 +226,7 @@ async function handleMarkdown(env: Env, body: Record<string, unknown>): Promise<
 	if (!targetUrl) {
 		return Response.json({ error: "url is required" }, { status: 400 });
 	}
+	const options = pageOptions(body);
 
 	const result = await withBrowser(env, targetUrl, async (page) => {
 		const title = await page.title();
@@ -134,7 +300,7 @@ async function handleMarkdown(env: Env, body: Record<string, unknown>): Promise<
 		});
 
 		return { title, content };
-	});
+	}, options);
 
 	return Response.json({
 		content: result.title ? `# ${result.title}\n\n${result.content}` : result.content,
@@ -148,6 +314,7 @@ async function handleLinks(env: Env, body: Record<string, unknown>): Promise<Res
 	if (!targetUrl) {
 		return Response.json({ error: "url is required" }, { status: 400 });
 	}
+	const options = pageOptions(body);
 
 	const links = await withBrowser(env, targetUrl, async (page) => {
 		return await page.evaluate((baseUrl: string) => {
@@ -179,7 +346,7 @@ async function handleLinks(env: Env, body: Record<string, unknown>): Promise<Res
 
 			return results;
 		}, targetUrl);
-	});
+	}, options);
 
 	return Response.json({ links, url: targetUrl });
 }
@@ -193,6 +360,7 @@ async function handleScrape(env: Env, body: Record<string, unknown>): Promise<Re
 	if (!selectors || typeof selectors !== "object") {
 		return Response.json({ error: "selectors object is required" }, { status: 400 });
 	}
+	const options = pageOptions(body);
 
 	const data = await withBrowser(env, targetUrl, async (page) => {
 		return await page.evaluate((sels: Record<string, string>) => {
@@ -203,7 +371,136 @@ async function handleScrape(env: Env, body: Record<string, unknown>): Promise<Re
 			}
 			return result;
 		}, selectors);
-	});
+	}, options);
+
+	return Response.json({ data, url: targetUrl });
+}
+
+async function handlePdf(env: Env, body: Record<string, unknown>): Promise<Response> {
+	const targetUrl = body.url as string;
+	const html = body.html as string;
+	if (!targetUrl && !html) {
+		return Response.json({ error: "url or html is required" }, { status: 400 });
+	}
+	if (targetUrl && html) {
+		return Response.json({ error: "url and html are mutually exclusive" }, { status: 400 });
+	}
+	const format = (body.format as string | undefined) || "A4";
+	if (!PDF_FORMATS.includes(format)) {
+		return Response.json({ error: `format must be one of ${PDF_FORMATS.join(", ")}` }, { status: 400 });
+	}
+	const margin = (body.margin as Record<string, string> | undefined) ?? {};
+	const options = pageOptions(body);
+
+	const print = async (page: puppeteer.Page) => {
+		return await page.pdf({
+			format: format as PaperFormat,
+			landscape: body.landscape === true,
+			printBackground: body.printBackground === true,
+			margin: {
+				top: margin.top,
+				right: margin.right,
+				bottom: margin.bottom,
+				left: margin.left,
+			},
+		});
+	};
+	const pdf = html ? await withContent(env, html, print, options) : await withBrowser(env, targetUrl, print, options);
+
+	return new Response(pdf, { headers: { "Content-Type": "application/pdf" } });
+}
+
+async function handleScreenshot(env: Env, body: Record<string, unknown>): Promise<Response> {
+	const targetUrl = body.url as string;
+	if (!targetUrl) {
+		return Response.json({ error: "url is required" }, { status: 400 });
+	}
+	const format = (body.format as string | undefined) || "png";
+	if (!SCREENSHOT_FORMATS.includes(format)) {
+		return Response.json({ error: `format must be one of ${SCREENSHOT_FORMATS.join(", ")}` }, { status: 400 });
+	}
+	const quality = body.quality as number | undefined;
+	if (quality !== undefined && (format !== "jpeg" || typeof quality !== "number" || quality < 1 || quality > 100)) {
+		return Response.json({ error: "quality must be between 1 and 100, for jpeg only" }, { status: 400 });
+	}
+	const options = pageOptions(body);
+
+	const image = await withBrowser(
+		env,
+		targetUrl,
+		async (page) =>
+			await page.screenshot({
+				type: format as "png" | "jpeg",
+				quality,
+				fullPage: body.fullPage === true,
+			}),
+		options,
+	);
+
+	return new Response(image, { headers: { "Content-Type": `image/${format}` } });
+}
+
+async function handleHtml(env: Env, body: Record<string, unknown>): Promise<Response> {
+	const targetUrl = body.url as string;
+	if (!targetUrl) {
+		return Response.json({ error: "url is required" }, { status: 400 });
+	}
+	const options = pageOptions(body);
+
+	const result = await withBrowser(
+		env,
+		targetUrl,
+		async (page) => ({ html: await page.content(), title: await page.title() }),
+		options,
+	);
+
+	return Response.json({ html: result.html, title: result.title, url: targetUrl });
+}
+
+async function handleJson(env: Env, body: Record<string, unknown>): Promise<Response> {
+	const targetUrl = body.url as string;
+	const schema = body.schema as Record<string, unknown> | undefined;
+	if (!targetUrl) {
+		return Response.json({ error: "url is required" }, { status: 400 });
+	}
+	if (!schema || typeof schema !== "object" || Array.isArray(schema)) {
+		return Response.json({ error: "schema object is required" }, { status: 400 });
+	}
+	const options = pageOptions(body);
+
+	const content = await withBrowser(
+		env,
+		targetUrl,
+		async (page) => ({
+			title: await page.title(),
+			text: await page.evaluate(() => document.body?.innerText ?? ""),
+		}),
+		options,
+	);
+
+	const result = (await env.AI.run(EXTRACTION_MODEL, {
+		messages: [
+			{
+				role: "system",
+				content: "Extract data from the web page the user provides. Reply only with JSON matching the schema. Use null for values the page doesn't contain.",
+			},
+			{
+				role: "user",
+				content: `URL: ${targetUrl}\nTitle: ${content.title}\n\n${content.text.slice(0, EXTRACTION_MAX_CHARS)}`,
+			},
+		],
+		response_format: { type: "json_schema", json_schema: schema },
+	})) as { response?: unknown };
+
+	// The model returns the JSON either parsed or as a string, depending on the model
+	let data = result.response;
+	if (typeof data === "string") {
+		try {
+			data = JSON.parse(data);
+		} catch {
+			return Response.json({ error: "Extraction did not return valid JSON" }, { status: 502 });
+		}
+	}
 
 	return Response.json({ data, url: targetUrl });
 }